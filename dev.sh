@@ -124,9 +124,7 @@ start_services() {
     fi
     
     echo ""
-    print_info "Default credentials:"
-    echo "  Username: admin"
-    echo "  Password: admin123"
+    print_info "First admin: open the web UI with the one-time setup token from the server logs"
     echo ""
     print_info "View logs: ./dev.sh logs $db"
 }
@@ -21,7 +21,7 @@ import {
   ExclamationTriangleIcon
 } from '@heroicons/react/24/outline'
 
-type LoginStep = 'credentials' | 'mfa' | 'mfa-setup'
+type LoginStep = 'credentials' | 'mfa' | 'mfa-setup' | 'password-expired'
 
 // SSO Provider types
 interface SSOProvider {
@@ -42,6 +42,8 @@ export default function Login() {
   const [darkMode, setDarkMode] = useState(false)
   const [loginStep, setLoginStep] = useState<LoginStep>('credentials')
   const [mfaError, setMfaError] = useState('')
+  const [newPassword, setNewPassword] = useState('')
+  const [confirmPassword, setConfirmPassword] = useState('')
   const [showMFASetup, setShowMFASetup] = useState(false)
   
   // OAuth callback handling state
@@ -127,7 +129,7 @@ export default function Login() {
         redirect_uri: window.location.origin + '/login',
       })
       
-      const { token, user, is_new_user } = response.data
+      const { token, refresh_token, user, is_new_user } = response.data
       
       if (!token || !user) {
         throw new Error('Invalid response from server')
@@ -136,7 +138,7 @@ export default function Login() {
       console.log('[Login] OAuth login successful:', { email: user.email, isNewUser: is_new_user })
       
       // 5. Store auth
-      login(token, user)
+      login(token, user, refresh_token)
       
       // 6. Clear URL params and show success popup
       navigate('/login', { replace: true })
@@ -205,7 +207,7 @@ export default function Login() {
         setShowMFASetup(true)
       } else {
         // Login successful
-        login(data.token, data.user)
+        login(data.token, data.user, data.refresh_token)
         
         // Get intended destination from redirect parameter
         const destination = getSafeRedirectUrl(searchParams)
@@ -215,10 +217,48 @@ export default function Login() {
       }
     },
     onError: (error: any) => {
+      if (error.response?.data?.password_expired) {
+        // Password is older than the password policy allows
+        setLoginStep('password-expired')
+        setMfaError('')
+        return
+      }
       setMfaError(error.response?.data?.error || 'Login failed')
     }
   })
 
+  const changeExpiredPasswordMutation = useMutation({
+    mutationFn: async () => {
+      const response = await api.post('/auth/password/expired', {
+        email,
+        current_password: password,
+        new_password: newPassword,
+      })
+      return response.data
+    },
+    onSuccess: () => {
+      // Sign in again with the new password
+      setPassword(newPassword)
+      setNewPassword('')
+      setConfirmPassword('')
+      setLoginStep('credentials')
+      loginMutation.mutate({ email, password: newPassword })
+    },
+    onError: (error: any) => {
+      setMfaError(error.response?.data?.error || 'Failed to change password')
+    }
+  })
+
+  const handleExpiredPasswordSubmit = (e: React.FormEvent) => {
+    e.preventDefault()
+    if (newPassword !== confirmPassword) {
+      setMfaError('Passwords do not match')
+      return
+    }
+    setMfaError('')
+    changeExpiredPasswordMutation.mutate()
+  }
+
   const handleCredentialsSubmit = (e: React.FormEvent) => {
     e.preventDefault()
     setMfaError('')
@@ -412,7 +452,7 @@ export default function Login() {
                   {/* Email Field */}
                   <div>
                     <label htmlFor="email" className="block text-sm font-medium text-gray-700 dark:text-gray-300 mb-2">
-                      Email address
+                      Email or username
                     </label>
                     <div className="relative">
                       <div className="absolute inset-y-0 left-0 pl-3 flex items-center pointer-events-none">
@@ -420,14 +460,14 @@ export default function Login() {
                       </div>
                       <input
                         id="email"
-                        type="email"
+                        type="text"
                         value={email}
                         onChange={(e) => setEmail(e.target.value)}
                         placeholder="you@example.com"
                         className="block w-full pl-10 pr-3 py-3 border border-gray-300 dark:border-gray-600 rounded-lg bg-white dark:bg-gray-700 text-gray-900 dark:text-white placeholder-gray-400 focus:outline-none focus:ring-2 focus:ring-primary-500 focus:border-transparent transition-all"
                         required
                         disabled={loginMutation.isPending}
-                        autoComplete="email"
+                        autoComplete="username"
                       />
                     </div>
                   </div>
@@ -531,6 +571,72 @@ export default function Login() {
                   </>
                 )}
               </>
+            ) : loginStep === 'password-expired' ? (
+              <>
+                <div className="mb-8">
+                  <h2 className="text-2xl font-bold text-gray-900 dark:text-white mb-2">
+                    Password expired
+                  </h2>
+                  <p className="text-gray-600 dark:text-gray-400">
+                    Your password has expired. Choose a new one to continue.
+                  </p>
+                </div>
+
+                {mfaError && (
+                  <div className="mb-6 bg-red-50 dark:bg-red-900/20 border border-red-200 dark:border-red-800 rounded-lg p-4">
+                    <p className="text-sm text-red-800 dark:text-red-200">{mfaError}</p>
+                  </div>
+                )}
+
+                <form onSubmit={handleExpiredPasswordSubmit} className="space-y-5">
+                  <div>
+                    <label htmlFor="new-password" className="block text-sm font-medium text-gray-700 dark:text-gray-300 mb-2">
+                      New password
+                    </label>
+                    <input
+                      id="new-password"
+                      type="password"
+                      value={newPassword}
+                      onChange={(e) => setNewPassword(e.target.value)}
+                      className="block w-full px-3 py-3 border border-gray-300 dark:border-gray-600 rounded-lg bg-white dark:bg-gray-700 text-gray-900 dark:text-white focus:outline-none focus:ring-2 focus:ring-primary-500 focus:border-transparent"
+                      required
+                      disabled={changeExpiredPasswordMutation.isPending}
+                      autoComplete="new-password"
+                    />
+                  </div>
+                  <div>
+                    <label htmlFor="confirm-password" className="block text-sm font-medium text-gray-700 dark:text-gray-300 mb-2">
+                      Confirm new password
+                    </label>
+                    <input
+                      id="confirm-password"
+                      type="password"
+                      value={confirmPassword}
+                      onChange={(e) => setConfirmPassword(e.target.value)}
+                      className="block w-full px-3 py-3 border border-gray-300 dark:border-gray-600 rounded-lg bg-white dark:bg-gray-700 text-gray-900 dark:text-white focus:outline-none focus:ring-2 focus:ring-primary-500 focus:border-transparent"
+                      required
+                      disabled={changeExpiredPasswordMutation.isPending}
+                      autoComplete="new-password"
+                    />
+                  </div>
+                  <div className="flex gap-3">
+                    <button
+                      type="button"
+                      onClick={() => { setLoginStep('credentials'); setMfaError('') }}
+                      className="flex-1 py-3 px-4 border border-gray-300 dark:border-gray-600 text-gray-700 dark:text-gray-200 font-medium rounded-lg hover:bg-gray-50 dark:hover:bg-gray-700 transition-colors"
+                    >
+                      Back
+                    </button>
+                    <button
+                      type="submit"
+                      disabled={changeExpiredPasswordMutation.isPending}
+                      className="flex-1 bg-primary-600 hover:bg-primary-700 text-white font-medium py-3 px-4 rounded-lg transition-colors disabled:opacity-50 disabled:cursor-not-allowed"
+                    >
+                      {changeExpiredPasswordMutation.isPending ? 'Saving...' : 'Change password'}
+                    </button>
+                  </div>
+                </form>
+              </>
             ) : (
               <MFAVerification 
                 onVerify={handleMFAVerify}
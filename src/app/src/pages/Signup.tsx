@@ -23,7 +23,7 @@ export default function Signup() {
     },
     onSuccess: (data) => {
       // Use auth store to handle login
-      login(data.token, data.user)
+      login(data.token, data.user, data.refresh_token)
       
       // Redirect to dashboard
       navigate('/dashboard')
@@ -25,15 +25,52 @@ api.interceptors.request.use(
   }
 )
 
+// Access tokens are short-lived; a single refresh is shared by all requests that hit a 401
+let refreshPromise: Promise<string> | null = null
+
+export const refreshAccessToken = (): Promise<string> => {
+  const refreshToken = localStorage.getItem('refresh_token')
+  if (!refreshToken) {
+    return Promise.reject(new Error('No refresh token'))
+  }
+  if (!refreshPromise) {
+    // Plain axios so the refresh call does not go through the interceptors below
+    refreshPromise = axios
+      .post(`${api.defaults.baseURL}/auth/refresh`, { refresh_token: refreshToken })
+      .then(({ data }) => {
+        localStorage.setItem('token', data.token)
+        localStorage.setItem('refresh_token', data.refresh_token)
+        return data.token as string
+      })
+      .finally(() => {
+        refreshPromise = null
+      })
+  }
+  return refreshPromise
+}
+
 // Handle 401 responses (unauthorized/session expired)
 api.interceptors.response.use(
   (response) => response,
-  (error) => {
+  async (error) => {
+    const original = error.config
+    if (error.response?.status === 401 && original && !original._retried && localStorage.getItem('refresh_token')) {
+      original._retried = true
+      try {
+        const token = await refreshAccessToken()
+        original.headers.Authorization = `Bearer ${token}`
+        return api(original)
+      } catch (refreshError) {
+        console.log('[API Interceptor] Token refresh failed', refreshError)
+      }
+    }
+
     if (error.response?.status === 401) {
       console.log('[API Interceptor] 🔒 401 Unauthorized - Session expired')
       
       // Clear auth data from storage
       localStorage.removeItem('token')
+      localStorage.removeItem('refresh_token')
       localStorage.removeItem('user')
       
       // Only redirect if not already on login page
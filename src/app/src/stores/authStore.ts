@@ -24,7 +24,7 @@ interface AuthState {
   user: User | null
   token: string | null
   isAuthenticated: boolean
-  login: (token: string, user: User) => void
+  login: (token: string, user: User, refreshToken?: string) => void
   logout: (currentPath?: string) => Promise<void>
   updateUser: (user: User) => void
   initializeAuth: () => void
@@ -53,14 +53,18 @@ export const useAuthStore = create<AuthState>((set) => ({
       } catch (error) {
         console.error('Failed to parse user from localStorage:', error)
         localStorage.removeItem('token')
+        localStorage.removeItem('refresh_token')
         localStorage.removeItem('user')
       }
     }
   },
 
   // Login
-  login: (token: string, user: User) => {
+  login: (token: string, user: User, refreshToken?: string) => {
     localStorage.setItem('token', token)
+    if (refreshToken) {
+      localStorage.setItem('refresh_token', refreshToken)
+    }
     localStorage.setItem('user', JSON.stringify(user))
     set({ token, user, isAuthenticated: true })
     
@@ -73,14 +77,15 @@ export const useAuthStore = create<AuthState>((set) => ({
   // Logout
   logout: async (currentPath?: string) => {
     try {
-      // Call logout API
-      await api.post('/auth/logout')
+      // Call logout API (revokes the refresh token of this session)
+      await api.post('/auth/logout', { refresh_token: localStorage.getItem('refresh_token') || undefined })
     } catch (error) {
       console.error('Logout API error:', error)
       // Continue with logout even if API call fails
     } finally {
       // Clear localStorage
       localStorage.removeItem('token')
+      localStorage.removeItem('refresh_token')
       localStorage.removeItem('user')
       
       // Clear state
@@ -127,10 +132,12 @@ export const useAuthStore = create<AuthState>((set) => ({
         const payload = JSON.parse(atob(tokenParts[1]))
         const now = Math.floor(Date.now() / 1000)
         
-        if (payload.exp && payload.exp < now) {
+        // With a refresh token the API call below renews an expired access token
+        if (payload.exp && payload.exp < now && !localStorage.getItem('refresh_token')) {
           console.log('[AuthStore] Token expired (client-side check)')
           // Clear storage
           localStorage.removeItem('token')
+          localStorage.removeItem('refresh_token')
           localStorage.removeItem('user')
           set({ isAuthenticated: false, user: null, token: null })
           return { authenticated: false, expired: true }
@@ -194,6 +201,7 @@ export const useAuthStore = create<AuthState>((set) => ({
         console.log('[AuthStore] ⚠️  Session expired (401 from API)')
         // Clear storage
         localStorage.removeItem('token')
+        localStorage.removeItem('refresh_token')
         localStorage.removeItem('user')
         set({ isAuthenticated: false, user: null, token: null })
         return { authenticated: false, expired: true }
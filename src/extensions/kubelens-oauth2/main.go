@@ -253,6 +253,8 @@ func (e *OAuth2Extension) GetMetadata() (kbplugin.Metadata, error) {
 		Author:           "Kubelens Team",
 		MinServerVersion: "1.0.0",
 		Permissions:      []string{"manage_auth", "manage_users"},
+		// The OIDC endpoints serve the login flow, before there is a session
+		ProxyRoutes: []kbplugin.ProxyRoute{{PathPrefix: "/", Auth: "public"}},
 	}, nil
 }
 
@@ -39,6 +39,7 @@ type SyncResponse struct {
 	IsNewUser    bool     `json:"is_new_user"`
 	Groups       []string `json:"groups"`
 	SessionToken string   `json:"session_token"`
+	RefreshToken string   `json:"refresh_token"`
 	ExpiresAt    int64    `json:"expires_at"`
 }
 
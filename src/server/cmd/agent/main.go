@@ -0,0 +1,46 @@
+// Command agent runs in a cluster kubelens cannot reach and opens a reverse tunnel to
+// kubelens, through which kubelens talks to the cluster's API server with the agent's
+// ServiceAccount. It is configured through the environment:
+//
+//	KUBELENS_URL          base URL of kubelens (required)
+//	KUBELENS_CLUSTER      name of the agent cluster in kubelens (required)
+//	KUBELENS_AGENT_TOKEN  agent token shown when the cluster was added (required)
+//	KUBELENS_AGENT_SERVER API server URL as kubelens should verify it (default https://kubernetes.default.svc)
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/sonnguyen/kubelens/internal/tunnel"
+)
+
+// version is set at build time with -ldflags "-X main.version=..."
+var version = "dev"
+
+func main() {
+	log.SetFormatter(&log.TextFormatter{FullTimestamp: true})
+
+	agent, err := tunnel.NewAgent(tunnel.AgentConfig{
+		URL:     os.Getenv("KUBELENS_URL"),
+		Cluster: os.Getenv("KUBELENS_CLUSTER"),
+		Token:   os.Getenv("KUBELENS_AGENT_TOKEN"),
+		Server:  os.Getenv("KUBELENS_AGENT_SERVER"),
+		Version: version,
+	})
+	if err != nil {
+		log.Fatalf("Invalid agent configuration: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	log.Infof("Starting kubelens agent %s for cluster %s", version, os.Getenv("KUBELENS_CLUSTER"))
+	if err := agent.Run(ctx); err != nil {
+		log.Fatalf("Agent stopped: %v", err)
+	}
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/sonnguyen/kubelens/internal/openapi"
+)
+
+// TestGeneratedClientUpToDate checks that pkg/client/zz_generated.go was regenerated from
+// pkg/client/openapi.json
+func TestGeneratedClientUpToDate(t *testing.T) {
+	data, err := os.ReadFile("../../pkg/client/openapi.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc openapi.Document
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	want, err := generate(&doc, "openapi.json", "client")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile("../../pkg/client/zz_generated.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Error("pkg/client/zz_generated.go is out of date, run go generate ./pkg/client")
+	}
+}
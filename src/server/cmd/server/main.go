@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"net/http"
 	"os"
@@ -13,14 +14,34 @@ import (
 	"github.com/gin-gonic/gin"
 	log "github.com/sirupsen/logrus"
 
+	"github.com/sonnguyen/kubelens/internal/alerting"
 	"github.com/sonnguyen/kubelens/internal/api"
 	"github.com/sonnguyen/kubelens/internal/audit"
 	"github.com/sonnguyen/kubelens/internal/auth"
+	"github.com/sonnguyen/kubelens/internal/backup"
+	"github.com/sonnguyen/kubelens/internal/cache"
 	"github.com/sonnguyen/kubelens/internal/cluster"
 	"github.com/sonnguyen/kubelens/internal/middleware"
 	"github.com/sonnguyen/kubelens/internal/config"
+	"github.com/sonnguyen/kubelens/internal/crashreport"
 	"github.com/sonnguyen/kubelens/internal/db"
+	"github.com/sonnguyen/kubelens/internal/diagnostics"
+	"github.com/sonnguyen/kubelens/internal/drain"
+	"github.com/sonnguyen/kubelens/internal/logarchive"
+	"github.com/sonnguyen/kubelens/internal/events"
 	"github.com/sonnguyen/kubelens/internal/extension"
+	"github.com/sonnguyen/kubelens/internal/health"
+	"github.com/sonnguyen/kubelens/internal/helm"
+	"github.com/sonnguyen/kubelens/internal/metrics"
+	"github.com/sonnguyen/kubelens/internal/notify"
+	"github.com/sonnguyen/kubelens/internal/openapi"
+	"github.com/sonnguyen/kubelens/internal/policy"
+	"github.com/sonnguyen/kubelens/internal/search"
+	"github.com/sonnguyen/kubelens/internal/secrets"
+	"github.com/sonnguyen/kubelens/internal/sse"
+	"github.com/sonnguyen/kubelens/internal/tunnel"
+	"github.com/sonnguyen/kubelens/internal/upgrade"
+	"github.com/sonnguyen/kubelens/internal/usage"
 	"github.com/sonnguyen/kubelens/internal/ws"
 
 	// Import all client-go auth plugins
@@ -28,6 +49,14 @@ import (
 )
 
 func main() {
+	migrateOnly := flag.Bool("migrate-only", false, "apply pending database migrations and exit")
+	migrateTo := flag.Int("migrate-to", -1, "migrate the database schema up or down to this version and exit")
+	backupFile := flag.String("backup", "", "write an encrypted database backup to this file and exit (passphrase from KUBELENS_BACKUP_PASSPHRASE)")
+	backupCredentials := flag.Bool("backup-credentials", false, "include cluster credentials and system settings in the -backup archive")
+	restoreFile := flag.String("restore", "", "restore an encrypted database backup from this file and exit (passphrase from KUBELENS_BACKUP_PASSPHRASE)")
+	openapiFile := flag.String("openapi", "", "write the OpenAPI document of the routes to this file and exit (see pkg/client)")
+	flag.Parse()
+
 	// Load configuration
 	cfg, err := config.Load()
 	if err != nil {
@@ -39,6 +68,41 @@ func main() {
 
 	log.Info("Starting kubelens server...")
 
+	// Vault: resolve vault:<path>#<key> references before anything uses them
+	var vault *secrets.Vault
+	if cfg.VaultAddr != "" {
+		vault, err = secrets.NewVault(secrets.VaultOptions{
+			Address:   cfg.VaultAddr,
+			Token:     cfg.VaultToken,
+			Role:      cfg.VaultRole,
+			AuthMount: cfg.VaultAuthMount,
+			KVMount:   cfg.VaultKVMount,
+			Namespace: cfg.VaultNamespace,
+			CACert:    cfg.VaultCACert,
+		})
+		if err != nil {
+			log.Fatalf("Failed to connect to Vault: %v", err)
+		}
+		vault.Start()
+		defer vault.Stop()
+		log.Infof("🔐 Using Vault at %s for secrets", cfg.VaultAddr)
+
+		if cfg.DatabasePassword, err = vault.Resolve(context.Background(), cfg.DatabasePassword); err != nil {
+			log.Fatalf("Failed to resolve database password: %v", err)
+		}
+		if cfg.RedisPassword, err = vault.Resolve(context.Background(), cfg.RedisPassword); err != nil {
+			log.Fatalf("Failed to resolve Redis password: %v", err)
+		}
+		if err := vault.ResolveEnv(context.Background(), "JWT_SECRET"); err != nil {
+			log.Fatalf("Failed to resolve JWT secret: %v", err)
+		}
+		if cfg.AuditChainKey, err = vault.Resolve(context.Background(), cfg.AuditChainKey); err != nil {
+			log.Fatalf("Failed to resolve audit chain key: %v", err)
+		}
+	} else if cfg.VaultClusterCredentials {
+		log.Fatal("KUBELENS_VAULT_CLUSTER_CREDENTIALS requires KUBELENS_VAULT_ADDR")
+	}
+
 	// Initialize database
 	dbConnectionString := cfg.GetDatabaseConnectionString()
 	dbType := cfg.DatabaseType
@@ -59,21 +123,98 @@ func main() {
 		log.Infof("💾 Using SQLite database: %s", dbPath)
 	}
 	
+	// Operator-run migrations: the server is not started
+	if *migrateTo >= 0 {
+		database, err := db.Open(dbConnectionString)
+		if err != nil {
+			log.Fatalf("Failed to connect to database: %v", err)
+		}
+		defer database.Close()
+		if err := database.MigrateTo(*migrateTo); err != nil {
+			log.Fatalf("Failed to migrate database: %v", err)
+		}
+		log.Infof("✅ Database schema is at version %d", *migrateTo)
+		return
+	}
+
 	database, err := db.New(dbConnectionString)
 	if err != nil {
 		log.Fatalf("Failed to initialize database: %v", err)
 	}
 	defer database.Close()
 
-	// Initialize default admin user and groups
-	if err := database.InitializeDefaultData(cfg.AdminPassword); err != nil {
-		log.Warnf("Failed to initialize default data: %v", err)
+	if *migrateOnly {
+		log.Infof("✅ Database schema is at version %d", db.LatestSchemaVersion())
+		return
+	}
+
+	// Keep cluster credentials in Vault, moving those still in the database
+	if vault != nil && cfg.VaultClusterCredentials {
+		db.SetCredentialStore(secrets.NewClusterCredentialStore(vault, cfg.VaultPrefix))
+		moved, err := database.MoveClusterCredentials()
+		if err != nil {
+			log.Fatalf("Failed to move cluster credentials to Vault: %v", err)
+		}
+		if moved > 0 {
+			log.Infof("🔐 Moved the credentials of %d clusters to Vault", moved)
+		}
+	}
+
+	// Operator-run backup and restore, e.g. to move from SQLite to PostgreSQL
+	if *backupFile != "" {
+		if err := backup.WriteFile(database, *backupFile, os.Getenv("KUBELENS_BACKUP_PASSPHRASE"), *backupCredentials); err != nil {
+			log.Fatalf("Failed to back up the database: %v", err)
+		}
+		log.Infof("✅ Database backup written to %s", *backupFile)
+		return
+	}
+	if *restoreFile != "" {
+		if err := backup.RestoreFile(database, *restoreFile, os.Getenv("KUBELENS_BACKUP_PASSPHRASE")); err != nil {
+			log.Fatalf("Failed to restore the database: %v", err)
+		}
+		log.Infof("✅ Database restored from %s", *restoreFile)
+		return
+	}
+
+	// Redis: state replicas behind a load balancer must share
+	var sharedCache cache.Store
+	if cfg.RedisAddr != "" {
+		redis, err := cache.NewRedis(cache.RedisOptions{
+			Addr:     cfg.RedisAddr,
+			Username: cfg.RedisUsername,
+			Password: cfg.RedisPassword,
+			DB:       cfg.RedisDB,
+			TLS:      cfg.RedisTLS,
+			Prefix:   cfg.RedisPrefix,
+		})
+		if err != nil {
+			log.Fatalf("Failed to connect to Redis: %v", err)
+		}
+		defer redis.Close()
+		sharedCache = redis
+		db.SetSessionCache(redis)
+		log.Infof("🧰 Using Redis at %s for rate limits, sessions and cluster summaries", cfg.RedisAddr)
+	}
+
+	if cfg.MetricsEnabled {
+		if err := metrics.InstrumentGorm(database.GormDB.DB); err != nil {
+			log.Warnf("Failed to instrument database for metrics: %v", err)
+		}
+	}
+
+	// The first admin is only created through the setup token flow
+	if os.Getenv("KUBELENS_ADMIN_PASSWORD") != "" {
+		log.Warn("⚠️  KUBELENS_ADMIN_PASSWORD is no longer supported and is ignored: create the first admin with the setup token printed at startup")
 	}
 
 	// Initialize cluster manager
 	clusterManager := cluster.NewManager(database)
 
+	// Agent tunnels for clusters that dial in (auth_type agent)
+	tunnelServer := tunnel.NewServer(database, clusterManager)
+
 	// Load clusters from configuration
+	clusterManager.SetExecCommands(cfg.ExecAuthCommands)
 	if err := clusterManager.LoadFromConfig(cfg); err != nil {
 		log.Warnf("Failed to load clusters from config: %v", err)
 	}
@@ -81,15 +222,148 @@ func main() {
 	// Initialize WebSocket hub
 	wsHub := ws.NewHub()
 	go wsHub.Run()
+	metrics.RegisterWebSocketConnections(wsHub.ClientCount)
 
 	// Initialize audit logger and retention manager
 	auditLogger := audit.NewLogger(database)
 	audit.InitGlobalLogger(database) // Initialize global logger for package-level Log() function
+	if len(cfg.AuditSinks) > 0 {
+		auditSinks, err := audit.NewSinks(cfg.AuditSinks)
+		if err != nil {
+			log.Fatalf("Failed to configure audit sinks: %v", err)
+		}
+		audit.SetSinks(auditSinks)
+		defer func() {
+			// Entries still queued are sent before exiting
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			auditSinks.Close(ctx)
+		}()
+		log.Infof("✅ Shipping audit logs to %d sinks", len(cfg.AuditSinks))
+	}
+	if cfg.AuditHashChain {
+		anchorInterval, err := time.ParseDuration(cfg.AuditChainAnchorInterval)
+		if err != nil || anchorInterval <= 0 {
+			log.Fatalf("Invalid audit chain anchor interval %q", cfg.AuditChainAnchorInterval)
+		}
+		if cfg.AuditChainKey == "" {
+			log.Warn("⚠️  Audit log hash chain has no key: anyone able to write the database can recompute it")
+		}
+		chainDone := make(chan struct{})
+		defer close(chainDone)
+		audit.EnableHashChain(cfg.AuditChainKey).StartAnchoring(database, auditLogger, anchorInterval, chainDone)
+		log.Infof("✅ Audit log hash chain enabled (anchored every %s)", anchorInterval)
+	}
 	retentionPolicy := audit.DefaultRetentionPolicy()
 	retentionManager := audit.NewRetentionManager(database, retentionPolicy)
 	retentionManager.Start()
 	defer retentionManager.Stop()
 
+	// Initialize crash report collector (captures CrashLoopBackOff containers)
+	if cfg.CrashReportsEnabled {
+		scanInterval, err := time.ParseDuration(cfg.CrashReportScanInterval)
+		if err != nil {
+			log.Warnf("Invalid crash report scan interval %q, using 30s", cfg.CrashReportScanInterval)
+			scanInterval = 30 * time.Second
+		}
+		crashCollector := crashreport.NewCollector(database, clusterManager, crashreport.Options{
+			ScanInterval:    scanInterval,
+			LogLines:        int64(cfg.CrashReportLogLines),
+			RetentionDays:   cfg.CrashReportRetentionDays,
+			MaxPerContainer: cfg.CrashReportMaxPerContainer,
+		})
+		crashCollector.Start()
+		defer crashCollector.Stop()
+	}
+
+	// Initialize Helm repository indexer (chart catalog)
+	helmIndexInterval, err := time.ParseDuration(cfg.HelmIndexInterval)
+	if err != nil {
+		log.Warnf("Invalid Helm index interval %q, using 1h", cfg.HelmIndexInterval)
+		helmIndexInterval = time.Hour
+	}
+	helmIndexer := helm.NewIndexer(database, helm.NewFetcher(nil), helmIndexInterval)
+	helmIndexer.Start()
+	defer helmIndexer.Stop()
+
+	// Outbound notification channels (webhooks, email)
+	notifier := notify.NewDispatcher(database)
+
+	// Initialize alert rule evaluator (workload health alerts)
+	alertInterval, err := time.ParseDuration(cfg.AlertEvaluationInterval)
+	if err != nil {
+		log.Warnf("Invalid alert evaluation interval %q, using 30s", cfg.AlertEvaluationInterval)
+		alertInterval = 30 * time.Second
+	}
+	alertEvaluator := alerting.NewEvaluator(database, clusterManager, notifier, alertInterval)
+	alertEvaluator.Start()
+	defer alertEvaluator.Stop()
+
+	// Initialize cluster event watcher (event history and live event stream)
+	if cfg.EventWatchEnabled {
+		eventRetention, err := time.ParseDuration(cfg.EventHistoryRetention)
+		if err != nil {
+			log.Warnf("Invalid event history retention %q, using 72h", cfg.EventHistoryRetention)
+			eventRetention = 72 * time.Hour
+		}
+		eventWatcher := events.NewWatcher(database, clusterManager, wsHub, notifier, eventRetention)
+		eventWatcher.Start()
+		defer eventWatcher.Stop()
+	}
+
+	// Initialize cluster health prober (status updates and reconnects)
+	probeInterval, err := time.ParseDuration(cfg.ClusterProbeInterval)
+	if err != nil {
+		log.Warnf("Invalid cluster probe interval %q, using 30s", cfg.ClusterProbeInterval)
+		probeInterval = 30 * time.Second
+	}
+	clusterProber := cluster.NewProber(database, clusterManager, wsHub, probeInterval)
+	clusterProber.Start()
+	defer clusterProber.Stop()
+
+	// Initialize kubeconfig directory watcher (clusters managed by external tooling)
+	if cfg.KubeconfigDir != "" {
+		dirWatcher := cluster.NewDirWatcher(database, clusterManager, cfg.KubeconfigDir)
+		if err := dirWatcher.Start(); err != nil {
+			log.Warnf("Failed to watch kubeconfig directory: %v", err)
+		} else {
+			defer dirWatcher.Stop()
+		}
+	}
+
+	// Initialize credential rotator (renews ServiceAccount tokens minted by credential rotation)
+	if cfg.CredentialRotationInterval != "" {
+		rotationInterval, err := time.ParseDuration(cfg.CredentialRotationInterval)
+		if err != nil || rotationInterval <= 0 {
+			log.Warnf("Invalid credential rotation interval %q, scheduled rotation disabled", cfg.CredentialRotationInterval)
+		} else {
+			credentialRotator := cluster.NewRotator(database, clusterManager, auditLogger, rotationInterval, cluster.DefaultCredentialLifetime)
+			credentialRotator.Start()
+			defer credentialRotator.Stop()
+		}
+	}
+
+	// Initialize usage tracker (daily per-user API usage rollups)
+	usageTracker := usage.NewTracker(database, time.Minute, cfg.UsageRetentionDays)
+	usageTracker.Start()
+	defer usageTracker.Stop()
+
+	// Initialize log archiver (scheduled export of pod logs to object storage)
+	var logArchiver *logarchive.Archiver
+	if cfg.LogArchiveEnabled {
+		logArchiver = logarchive.NewArchiver(database, clusterManager)
+		logArchiver.Start()
+		defer logArchiver.Stop()
+	}
+
+	// Initialize search indexer (global search served from informer caches)
+	var searchIndexer *search.Indexer
+	if cfg.SearchIndexEnabled {
+		searchIndexer = search.NewIndexer(clusterManager)
+		searchIndexer.Start()
+		defer searchIndexer.Stop()
+	}
+
 	// Setup Gin router
 	if cfg.ReleaseMode {
 		gin.SetMode(gin.ReleaseMode)
@@ -97,6 +371,13 @@ func main() {
 
 	router := gin.Default()
 
+	// Prometheus metrics for kubelens itself
+	if cfg.MetricsEnabled {
+		router.Use(metrics.Middleware())
+		router.GET("/metrics", metrics.Handler(cfg.MetricsToken))
+		log.Info("📈 Prometheus metrics enabled on /metrics")
+	}
+
 	// Security headers middleware
 	router.Use(middleware.SecurityHeaders())
 
@@ -129,6 +410,9 @@ func main() {
 		globalRequestsPerMin, globalRateInterval, globalBurst)
 	
 	globalRateLimiter := middleware.NewRateLimiter(globalRateInterval, globalBurst)
+	if sharedCache != nil {
+		globalRateLimiter.Share(sharedCache, "global")
+	}
 	router.Use(globalRateLimiter.Middleware())
 
 	// Health check endpoint
@@ -139,6 +423,24 @@ func main() {
 		})
 	})
 
+	// Kubernetes probes: /livez for process health, /readyz for the database, clusters and
+	// extensions (checks are registered below once those are initialized)
+	healthChecker := health.NewChecker()
+	router.GET("/livez", healthChecker.Livez)
+	router.GET("/readyz", healthChecker.Readyz)
+	healthChecker.Add("database", health.DatabaseCheck(database.GetConn()))
+	healthChecker.Add("clusters", health.ClustersCheck(func() ([]string, error) {
+		clusters, err := database.ListEnabledClusters()
+		if err != nil {
+			return nil, err
+		}
+		names := make([]string, 0, len(clusters))
+		for _, cl := range clusters {
+			names = append(names, cl.Name)
+		}
+		return names, nil
+	}, clusterManager.ClusterNames))
+
 	// Initialize extension manager
 	// Use KUBELENS_EXTENSIONS_DIR or default to /app/extensions (bundled extensions)
 	extensionDir := os.Getenv("KUBELENS_EXTENSIONS_DIR")
@@ -155,25 +457,122 @@ func main() {
 		} else {
 			log.Infof("🧩 Extension manager initialized")
 		}
-
-		// Register extension HTTP proxies (e.g., /api/v1/auth/oauth for OAuth2)
-		extensionManager.RegisterHTTPProxies(router)
+		healthChecker.Add("extensions", extensionManager.ReadinessCheck)
+	}
+	if extensionManager != nil {
+		metrics.RegisterExtensionHealth(func() map[string]bool {
+			running := map[string]bool{}
+			for name, status := range extensionManager.Statuses() {
+				running[name] = status == extension.StatusRunning
+			}
+			return running
+		})
 	}
 
 	// Initialize auth handler
-	jwtSecret := os.Getenv("JWT_SECRET")
-	if jwtSecret == "" {
-		jwtSecret = "kubelens-secret-change-in-production" // Default for development
-		log.Warn("⚠️  JWT_SECRET not set, using default (not secure for production!)")
+	jwtSecret, err := auth.ResolveJWTSecret(database, cfg.ReleaseMode)
+	if err != nil {
+		log.Fatalf("Failed to resolve JWT secret: %v", err)
 	}
 	authHandler := auth.NewHandler(database, jwtSecret, auditLogger)
+	authHandler.SetPublicURL(cfg.PublicURL)
+	accessTokenTTL, err := time.ParseDuration(cfg.AccessTokenTTL)
+	if err != nil {
+		log.Warnf("Invalid access token TTL %q, using 15m", cfg.AccessTokenTTL)
+	}
+	refreshTokenTTL, err := time.ParseDuration(cfg.RefreshTokenTTL)
+	if err != nil {
+		log.Warnf("Invalid refresh token TTL %q, using 720h", cfg.RefreshTokenTTL)
+	}
+	authHandler.SetTokenTTLs(accessTokenTTL, refreshTokenTTL)
+
+	// Delete expired sessions and refresh tokens at startup and then daily
+	go func() {
+		for {
+			authHandler.CleanupExpiredSessions()
+			time.Sleep(24 * time.Hour)
+		}
+	}()
 	
 	// Set database for auth middleware (for user status checking)
 	auth.SetMiddlewareDB(database)
 
+	// First-run setup (one-time token to create the first admin)
+	setupHandler, err := auth.NewSetupHandler(database, cfg.SetupTokenLocalAccess)
+	if err != nil {
+		log.Fatalf("Failed to initialize setup: %v", err)
+	}
+
+	// Fleet-wide endpoint policy (configurable via KUBELENS_DISABLED_ENDPOINTS or the admin API)
+	endpointPolicy, err := policy.NewEndpointPolicy(database, cfg.DisabledEndpoints)
+	if err != nil {
+		log.Fatalf("Invalid endpoint policy: %v", err)
+	}
+
+	// Read-only maintenance mode (KUBELENS_MAINTENANCE_MODE or the admin API)
+	maintenanceMode := policy.NewMaintenanceMode(database, cfg.MaintenanceMode, cfg.MaintenanceMessage)
+
+	// Initialize node drain runner (scheduled and resumable drain jobs, held in maintenance mode)
+	drainRunner := drain.NewRunner(database, clusterManager)
+	drainRunner.SetMaintenanceMode(maintenanceMode)
+	drainRunner.Start()
+	defer drainRunner.Stop()
+
+	// Shell policy restricting pod and node shells (admin API)
+	shellPolicy := policy.NewShellPolicy(database, endpointPolicy, database, jwtSecret)
+	shellPolicyHandler := policy.NewShellPolicyHandler(shellPolicy)
+
+	// Register extension HTTP proxies (e.g., /api/v1/auth/oauth for OAuth2)
+	if extensionManager != nil {
+		extensionManager.RegisterHTTPProxies(router, auth.AuthMiddleware(jwtSecret), authHandler.PermissionChecker)
+	}
+
+	// Runtime diagnostics (pprof, goroutines, heap, open watches) for admins
+	if cfg.DebugEndpoints {
+		diagnosticsHandler := diagnostics.NewHandler(wsHub.ClientCount, clusterManager.ClusterNames)
+		debugRoutes := router.Group("/debug")
+		debugRoutes.Use(auth.AuthMiddleware(jwtSecret), authHandler.PermissionChecker("settings", "manage"))
+		{
+			debugRoutes.GET("/status", diagnosticsHandler.GetStatus)
+			debugRoutes.GET("/pprof/*profile", diagnosticsHandler.Pprof)
+			debugRoutes.POST("/pprof/symbol", diagnosticsHandler.Pprof)
+		}
+		log.Warn("🐞 Debug endpoints enabled on /debug/pprof and /debug/status")
+	}
+
+	// SCIM 2.0 provisioning for identity providers (Okta, Azure AD). Clients authenticate with
+	// a personal access token of a user who can manage users.
+	scimRoutes := router.Group(auth.SCIMBasePath)
+	scimRoutes.Use(auth.AuthMiddleware(jwtSecret), authHandler.PermissionChecker("users", "manage"))
+	{
+		scimRoutes.GET("/ServiceProviderConfig", authHandler.SCIMServiceProviderConfig)
+		scimRoutes.GET("/ResourceTypes", authHandler.SCIMResourceTypes)
+		scimRoutes.GET("/Users", authHandler.SCIMListUsers)
+		scimRoutes.POST("/Users", authHandler.SCIMCreateUser)
+		scimRoutes.GET("/Users/:id", authHandler.SCIMGetUser)
+		scimRoutes.PUT("/Users/:id", authHandler.SCIMReplaceUser)
+		scimRoutes.PATCH("/Users/:id", authHandler.SCIMPatchUser)
+		scimRoutes.DELETE("/Users/:id", authHandler.SCIMDeleteUser)
+		scimRoutes.GET("/Groups", authHandler.SCIMListGroups)
+		scimRoutes.POST("/Groups", authHandler.SCIMCreateGroup)
+		scimRoutes.GET("/Groups/:id", authHandler.SCIMGetGroup)
+		scimRoutes.PUT("/Groups/:id", authHandler.SCIMReplaceGroup)
+		scimRoutes.PATCH("/Groups/:id", authHandler.SCIMPatchGroup)
+		scimRoutes.DELETE("/Groups/:id", authHandler.SCIMDeleteGroup)
+	}
+
 	// API routes
 	apiHandler := api.NewHandler(clusterManager, database, wsHub)
+	if sharedCache != nil {
+		apiHandler.SetCache(sharedCache)
+	}
+	apiHandler.SetShellCommandAudit(cfg.ShellCommandAudit)
+	apiHandler.SetShellPolicy(shellPolicy)
+	if searchIndexer != nil {
+		apiHandler.SetSearchIndex(searchIndexer)
+	}
 	v1 := router.Group("/api/v1")
+	v1.Use(usageTracker.Middleware())
 	{
 		// Login rate limiter (configurable via KUBELENS_LOGIN_RATE_LIMIT_PER_MIN, default: 5 req/min)
 		loginRequestsPerMin := cfg.LoginRateLimitPerMin
@@ -186,13 +585,33 @@ func main() {
 			loginRequestsPerMin, loginRateInterval, loginBurst)
 		
 		loginRateLimiter := middleware.NewRateLimiter(loginRateInterval, loginBurst)
+		if sharedCache != nil {
+			loginRateLimiter.Share(sharedCache, "login")
+		}
 		
+		// Agent tunnels (authenticated with the agent token of the cluster)
+		agentRoutes := v1.Group("/agent")
+		{
+			agentRoutes.GET("/connect", tunnelServer.Connect)
+			agentRoutes.GET("/tunnel", tunnelServer.Tunnel)
+		}
+
+		// First-run setup routes (public, guarded by the one-time setup token)
+		setupRoutes := v1.Group("/setup")
+		{
+			setupRoutes.GET("/status", setupHandler.GetStatus)
+			setupRoutes.GET("/token", setupHandler.GetToken)
+			setupRoutes.POST("", loginRateLimiter.Middleware(), setupHandler.CompleteSetup)
+		}
+
 		// Authentication routes (public)
 		authRoutes := v1.Group("/auth")
 		{
 			// Signup disabled
 			// authRoutes.POST("/signup", authHandler.Signup)
 			authRoutes.POST("/signin", loginRateLimiter.Middleware(), authHandler.Signin)
+			authRoutes.POST("/password/expired", loginRateLimiter.Middleware(), authHandler.ChangeExpiredPassword)
+			authRoutes.POST("/refresh", authHandler.RefreshToken)
 			
 			// SSO providers endpoint (public - no auth required for login page)
 			if extensionManager != nil {
@@ -203,17 +622,41 @@ func main() {
 			authRoutes.PATCH("/profile", auth.AuthMiddleware(jwtSecret), authHandler.UpdateProfile)
 			authRoutes.POST("/change-password", auth.AuthMiddleware(jwtSecret), authHandler.ChangePassword)
 			authRoutes.POST("/logout", auth.AuthMiddleware(jwtSecret), authHandler.Logout)
+			authRoutes.POST("/organization", auth.AuthMiddleware(jwtSecret), authHandler.SwitchOrganization)
+
+			// Login sessions (devices the user is signed in on)
+			authRoutes.GET("/sessions", auth.AuthMiddleware(jwtSecret), authHandler.ListMySessions)
+			authRoutes.DELETE("/sessions/:id", auth.AuthMiddleware(jwtSecret), authHandler.RevokeMySession)
+
+			// Personal access tokens for automation
+			authRoutes.GET("/tokens", auth.AuthMiddleware(jwtSecret), authHandler.ListMyAPITokens)
+			authRoutes.POST("/tokens", auth.AuthMiddleware(jwtSecret), authHandler.CreateAPIToken)
+			authRoutes.DELETE("/tokens/:id", auth.AuthMiddleware(jwtSecret), authHandler.RevokeMyAPIToken)
+
+			// Invitation redemption (public - the one-time token is the credential)
+			authRoutes.GET("/invitations/:token", loginRateLimiter.Middleware(), authHandler.GetInvitation)
+			authRoutes.POST("/invitations/:token/accept", loginRateLimiter.Middleware(), authHandler.AcceptInvitation)
+
+			// Password reset links emailed by an admin (public, single-use)
+			authRoutes.GET("/password-reset/:token", loginRateLimiter.Middleware(), authHandler.GetPasswordReset)
+			authRoutes.POST("/password-reset/:token", loginRateLimiter.Middleware(), authHandler.CompletePasswordReset)
 
 			// MFA routes
 			mfaHandler := auth.NewMFAHandler(database)
+			// Enrolling also takes the temporary token of a sign-in that requires MFA first
+			mfaSetupRoutes := authRoutes.Group("/mfa")
+			mfaSetupRoutes.Use(auth.MFASetupMiddleware(jwtSecret))
+			{
+				mfaSetupRoutes.POST("/setup", mfaHandler.SetupMFA)
+				mfaSetupRoutes.POST("/enable", mfaHandler.VerifyAndEnableMFA)
+			}
 			mfaRoutes := authRoutes.Group("/mfa")
 			mfaRoutes.Use(auth.AuthMiddleware(jwtSecret))
 			{
-				mfaRoutes.POST("/setup", mfaHandler.SetupMFA)
-				mfaRoutes.POST("/enable", mfaHandler.VerifyAndEnableMFA)
 				mfaRoutes.POST("/disable", mfaHandler.DisableMFA)
 				mfaRoutes.GET("/status", mfaHandler.GetMFAStatus)
 				mfaRoutes.POST("/regenerate-codes", mfaHandler.RegenerateBackupCodes)
+				mfaRoutes.POST("/step-up", shellPolicyHandler.StepUp)
 			}
 		}
 		
@@ -228,6 +671,7 @@ func main() {
 			userRoutes.GET("/:id", authHandler.GetUser)
 			userRoutes.GET("/:id/avatar", authHandler.GetUserAvatar) // Serve cached avatar
 			userRoutes.GET("/:id/groups", authHandler.GetUserGroups)
+			userRoutes.GET("/:id/sessions", authHandler.ListUserSessions)
 			
 			// Write operations require specific permissions
 			userRoutes.POST("", authHandler.PermissionChecker("users", "create"), authHandler.CreateUser)
@@ -235,12 +679,31 @@ func main() {
 			userRoutes.DELETE("/:id", authHandler.PermissionChecker("users", "delete"), authHandler.DeleteUser)
 			userRoutes.PUT("/:id/groups", authHandler.PermissionChecker("users", "update"), authHandler.UpdateUserGroups)
 			userRoutes.POST("/:id/reset-password", authHandler.PermissionChecker("users", "update"), authHandler.ResetUserPassword)
+			userRoutes.POST("/:id/reset-password/email", authHandler.PermissionChecker("users", "update"), authHandler.SendPasswordResetEmail)
+			userRoutes.DELETE("/:id/sessions", authHandler.PermissionChecker("users", "update"), authHandler.ForceLogoutUser)
 			
 			// MFA admin routes - manage permission
 			mfaHandler := auth.NewMFAHandler(database)
 			userRoutes.POST("/:id/reset-mfa", authHandler.PermissionChecker("users", "manage"), mfaHandler.AdminResetMFA)
 		}
 
+		// Invitation management routes - requires "users" permission
+		invitationRoutes := v1.Group("/invitations")
+		invitationRoutes.Use(auth.AuthMiddleware(jwtSecret), authHandler.PermissionChecker("users", "read"))
+		{
+			invitationRoutes.GET("", authHandler.ListInvitations)
+			invitationRoutes.POST("", authHandler.PermissionChecker("users", "create"), authHandler.CreateInvitation)
+			invitationRoutes.DELETE("/:id", authHandler.PermissionChecker("users", "delete"), authHandler.RevokeInvitation)
+		}
+
+		// API token administration - requires "users" permission
+		apiTokenRoutes := v1.Group("/api-tokens")
+		apiTokenRoutes.Use(auth.AuthMiddleware(jwtSecret), authHandler.PermissionChecker("users", "read"))
+		{
+			apiTokenRoutes.GET("", authHandler.ListAPITokens)
+			apiTokenRoutes.DELETE("/:id", authHandler.PermissionChecker("users", "update"), authHandler.RevokeAPIToken)
+		}
+
 		// Permission options route - requires settings permission
 		v1.GET("/permissions/options", auth.AuthMiddleware(jwtSecret), authHandler.PermissionChecker("settings", "read"), authHandler.GetPermissionOptions)
 
@@ -260,6 +723,22 @@ func main() {
 			groupRoutes.DELETE("/:id/users/:user_id", authHandler.PermissionChecker("groups", "update"), authHandler.RemoveUserFromGroupHandler)
 		}
 
+		// Organization routes - members manage their organization by role, admins create them
+		orgRoutes := v1.Group("/organizations")
+		orgRoutes.Use(auth.AuthMiddleware(jwtSecret))
+		{
+			orgRoutes.GET("", authHandler.ListOrganizations)
+			orgRoutes.POST("", auth.AdminOnly(), authHandler.CreateOrganization)
+			orgRoutes.GET("/:id", authHandler.GetOrganization)
+			orgRoutes.PATCH("/:id", authHandler.UpdateOrganization)
+			orgRoutes.DELETE("/:id", auth.AdminOnly(), authHandler.DeleteOrganization)
+			orgRoutes.GET("/:id/members", authHandler.ListOrganizationMembers)
+			orgRoutes.POST("/:id/members", authHandler.AddOrganizationMember)
+			orgRoutes.PUT("/:id/members/:user_id", authHandler.UpdateOrganizationMember)
+			orgRoutes.DELETE("/:id/members/:user_id", authHandler.RemoveOrganizationMember)
+			orgRoutes.PUT("/:id/clusters/:name", auth.AdminOnly(), authHandler.MoveClusterToOrganization)
+		}
+
 		// User session routes (authenticated users)
 		sessionRoutes := v1.Group("/session")
 		sessionRoutes.Use(auth.AuthMiddleware(jwtSecret))
@@ -282,6 +761,139 @@ func main() {
 			notificationRoutes.DELETE("", authHandler.ClearAllNotifications)
 		}
 
+		// System routes
+		systemRoutes := v1.Group("/system")
+		systemRoutes.Use(auth.AuthMiddleware(jwtSecret))
+		{
+			// Feature flags enabled for the current user (used by the frontend)
+			systemRoutes.GET("/features", authHandler.GetEnabledFeatures)
+
+			// Feature flag management - requires settings permission
+			systemRoutes.GET("/feature-flags", authHandler.PermissionChecker("settings", "read"), authHandler.ListFeatureFlags)
+			systemRoutes.PUT("/feature-flags/:key", authHandler.PermissionChecker("settings", "update"), authHandler.UpsertFeatureFlag)
+			systemRoutes.DELETE("/feature-flags/:key", authHandler.PermissionChecker("settings", "update"), authHandler.DeleteFeatureFlag)
+
+			// Endpoint policy - requires settings permission
+			policyHandler := policy.NewHandler(endpointPolicy)
+			systemRoutes.GET("/endpoint-policy", authHandler.PermissionChecker("settings", "read"), policyHandler.GetEndpointPolicy)
+			systemRoutes.PUT("/endpoint-policy", authHandler.PermissionChecker("settings", "update"), policyHandler.UpdateEndpointPolicy)
+			systemRoutes.GET("/shell-policy", authHandler.PermissionChecker("settings", "read"), shellPolicyHandler.GetShellPolicy)
+			systemRoutes.PUT("/shell-policy", authHandler.PermissionChecker("settings", "update"), shellPolicyHandler.UpdateShellPolicy)
+
+			// Maintenance mode state is readable by every user so clients can show it
+			maintenanceHandler := policy.NewMaintenanceHandler(maintenanceMode)
+			systemRoutes.GET("/maintenance", maintenanceHandler.GetMaintenanceMode)
+			systemRoutes.PUT("/maintenance", authHandler.PermissionChecker("settings", "update"), maintenanceHandler.UpdateMaintenanceMode)
+
+			// LDAP / Active Directory sign-in - requires settings permission
+			systemRoutes.GET("/ldap", authHandler.PermissionChecker("settings", "read"), authHandler.GetLDAPSettings)
+			systemRoutes.PUT("/ldap", authHandler.PermissionChecker("settings", "update"), authHandler.UpdateLDAPSettings)
+			systemRoutes.POST("/ldap/test", authHandler.PermissionChecker("settings", "update"), authHandler.TestLDAPSettings)
+			systemRoutes.GET("/smtp", authHandler.PermissionChecker("settings", "read"), authHandler.GetSMTPSettings)
+			systemRoutes.PUT("/smtp", authHandler.PermissionChecker("settings", "update"), authHandler.UpdateSMTPSettings)
+			systemRoutes.POST("/smtp/test", authHandler.PermissionChecker("settings", "update"), authHandler.TestSMTPSettings)
+			systemRoutes.GET("/password-policy", authHandler.PermissionChecker("settings", "read"), authHandler.GetPasswordPolicy)
+			systemRoutes.PUT("/password-policy", authHandler.PermissionChecker("settings", "update"), authHandler.UpdatePasswordPolicy)
+
+			// Database backup and restore - requires settings permission
+			backupHandler := backup.NewHandler(database, clusterManager)
+			systemRoutes.POST("/backup", authHandler.PermissionChecker("settings", "update"), backupHandler.CreateBackup)
+			systemRoutes.POST("/restore", authHandler.PermissionChecker("settings", "update"), backupHandler.RestoreBackup)
+		}
+
+		// Crash report routes - requires "pods" permission
+		crashReportHandler := crashreport.NewHandler(database)
+		crashReportRoutes := v1.Group("/crash-reports")
+		crashReportRoutes.Use(auth.AuthMiddleware(jwtSecret), authHandler.PermissionChecker("pods", "read"), authHandler.ResourceScope())
+		{
+			crashReportRoutes.GET("", crashReportHandler.ListCrashReports)
+			crashReportRoutes.GET("/:id", crashReportHandler.GetCrashReport)
+			crashReportRoutes.DELETE("/:id", authHandler.PermissionChecker("pods", "delete"), crashReportHandler.DeleteCrashReport)
+		}
+
+		// Helm repositories and chart catalog - any user can search, admins manage repositories
+		helmHandler := helm.NewHandler(database, helmIndexer)
+		helmRoutes := v1.Group("/helm")
+		helmRoutes.Use(auth.AuthMiddleware(jwtSecret))
+		{
+			helmRoutes.GET("/repositories", helmHandler.ListRepositories)
+			helmRoutes.POST("/repositories", authHandler.PermissionChecker("settings", "update"), helmHandler.CreateRepository)
+			helmRoutes.PUT("/repositories/:id", authHandler.PermissionChecker("settings", "update"), helmHandler.UpdateRepository)
+			helmRoutes.DELETE("/repositories/:id", authHandler.PermissionChecker("settings", "update"), helmHandler.DeleteRepository)
+			helmRoutes.POST("/repositories/:id/refresh", authHandler.PermissionChecker("settings", "update"), helmHandler.RefreshRepository)
+			helmRoutes.GET("/charts", helmHandler.SearchCharts)
+			helmRoutes.GET("/charts/:repository/:chart", helmHandler.GetChartVersions)
+		}
+
+		// Notification channels - admins manage where kubelens events are delivered
+		notifyHandler := notify.NewHandler(database, notifier)
+		notifyRoutes := v1.Group("/notification-channels")
+		notifyRoutes.Use(auth.AuthMiddleware(jwtSecret), authHandler.PermissionChecker("settings", "update"))
+		{
+			notifyRoutes.GET("", notifyHandler.ListChannels)
+			notifyRoutes.POST("", notifyHandler.CreateChannel)
+			notifyRoutes.PUT("/:id", notifyHandler.UpdateChannel)
+			notifyRoutes.DELETE("/:id", notifyHandler.DeleteChannel)
+			notifyRoutes.POST("/:id/test", notifyHandler.TestChannel)
+		}
+
+		// Alert rules - admins define workload health conditions; alerts are readable with clusters read
+		alertingHandler := alerting.NewHandler(database)
+		alertRuleRoutes := v1.Group("/alert-rules")
+		alertRuleRoutes.Use(auth.AuthMiddleware(jwtSecret), authHandler.PermissionChecker("settings", "read"))
+		{
+			alertRuleRoutes.GET("", alertingHandler.ListRules)
+			alertRuleRoutes.GET("/conditions", alertingHandler.ListConditions)
+			alertRuleRoutes.POST("", authHandler.PermissionChecker("settings", "update"), alertingHandler.CreateRule)
+			alertRuleRoutes.PUT("/:id", authHandler.PermissionChecker("settings", "update"), alertingHandler.UpdateRule)
+			alertRuleRoutes.DELETE("/:id", authHandler.PermissionChecker("settings", "update"), alertingHandler.DeleteRule)
+		}
+		v1.GET("/alerts", auth.AuthMiddleware(jwtSecret), authHandler.PermissionChecker("clusters", "read"), authHandler.ResourceScope(), alertingHandler.ListAlerts)
+
+		// Cluster upgrade assistant routes - requires "nodes" permission
+		upgradeRunner := upgrade.NewRunner(database, clusterManager)
+		upgradeRunner.SetNodePoolUpgrader(upgrade.NewEKSNodeGroupUpgrader(database, clusterManager))
+		upgradeRunner.SetMaintenanceMode(maintenanceMode)
+		upgradeRunner.StartMonitor()
+		defer upgradeRunner.StopMonitor()
+		upgradeHandler := upgrade.NewHandler(database, clusterManager, upgradeRunner)
+		upgradeRoutes := v1.Group("/upgrade")
+		upgradeRoutes.Use(auth.AuthMiddleware(jwtSecret), authHandler.PermissionChecker("nodes", "read"), authHandler.ResourceScope(), maintenanceMode.Middleware())
+		{
+			upgradeRoutes.POST("/prechecks", upgradeHandler.RunPreChecks)
+			upgradeRoutes.GET("/plans", upgradeHandler.ListPlans)
+			upgradeRoutes.GET("/plans/:id", upgradeHandler.GetPlan)
+			upgradeRoutes.POST("/plans", authHandler.PermissionChecker("nodes", "update"), upgradeHandler.CreatePlan)
+			upgradeRoutes.POST("/plans/:id/start", authHandler.PermissionChecker("nodes", "update"), upgradeHandler.StartPlan)
+			upgradeRoutes.POST("/plans/:id/pause", authHandler.PermissionChecker("nodes", "update"), upgradeHandler.PausePlan)
+			upgradeRoutes.POST("/plans/:id/cancel", authHandler.PermissionChecker("nodes", "update"), upgradeHandler.CancelPlan)
+		}
+
+		// Node drain job routes - requires "nodes" permission
+		drainHandler := drain.NewHandler(database, clusterManager, drainRunner)
+		drainRoutes := v1.Group("/drains")
+		drainRoutes.Use(auth.AuthMiddleware(jwtSecret), authHandler.PermissionChecker("nodes", "read"), authHandler.ResourceScope(), maintenanceMode.Middleware())
+		{
+			drainRoutes.GET("", drainHandler.ListJobs)
+			drainRoutes.GET("/:id", drainHandler.GetJob)
+			drainRoutes.POST("", authHandler.PermissionChecker("nodes", "update"), drainHandler.CreateJob)
+			drainRoutes.POST("/:id/pause", authHandler.PermissionChecker("nodes", "update"), drainHandler.PauseJob)
+			drainRoutes.POST("/:id/resume", authHandler.PermissionChecker("nodes", "update"), drainHandler.ResumeJob)
+			drainRoutes.POST("/:id/cancel", authHandler.PermissionChecker("nodes", "update"), drainHandler.CancelJob)
+		}
+
+		// Usage analytics routes - requires "audit" permission
+		usageHandler := usage.NewHandler(database)
+		usageRoutes := v1.Group("/admin/usage")
+		usageRoutes.Use(auth.AuthMiddleware(jwtSecret), authHandler.PermissionChecker("audit", "read"))
+		{
+			usageRoutes.GET("/users", usageHandler.GetUserUsage)
+			usageRoutes.GET("/groups", usageHandler.GetGroupUsage)
+			usageRoutes.GET("/daily", usageHandler.GetDailyUsage)
+			usageRoutes.GET("/export", usageHandler.ExportUsage)
+			usageRoutes.GET("/unused-privileges", usageHandler.GetUnusedPrivileges)
+		}
+
 		// User permissions route (authenticated users)
 		v1.GET("/permissions", auth.AuthMiddleware(jwtSecret), authHandler.GetUserPermissionsHandler)
 
@@ -294,8 +906,17 @@ func main() {
 			auditRoutes.GET("/logs", auditHandler.ListAuditLogs)
 			auditRoutes.GET("/logs/:id", auditHandler.GetAuditLog)
 			auditRoutes.GET("/logs/stats", auditHandler.GetAuditStats)
+			auditRoutes.GET("/logs/stream", auditHandler.StreamAuditLogs)
+			auditRoutes.GET("/sinks", auditHandler.GetSinks)
+			auditRoutes.GET("/chain/verify", authHandler.PermissionChecker("audit", "manage"), auditHandler.VerifyAuditChain)
 			auditRoutes.POST("/export", auditHandler.ExportAuditLogs)
 
+			// Saved audit queries, owned by their author
+			auditRoutes.GET("/queries", auditHandler.ListSavedQueries)
+			auditRoutes.POST("/queries", auditHandler.CreateSavedQuery)
+			auditRoutes.PUT("/queries/:id", auditHandler.UpdateSavedQuery)
+			auditRoutes.DELETE("/queries/:id", auditHandler.DeleteSavedQuery)
+
 			// Audit settings - read operations
 			auditRoutes.GET("/settings", auditHandler.GetAuditSettings)
 			auditRoutes.GET("/settings/presets", auditHandler.GetAuditPresets)
@@ -317,277 +938,38 @@ func main() {
 
 	// Protected routes - require authentication
 	protected := v1.Group("")
-	protected.Use(auth.AuthMiddleware(jwtSecret))
+	protected.Use(auth.AuthMiddleware(jwtSecret), authHandler.ResourceScope(), maintenanceMode.Middleware(), apiHandler.AuditResourceChanges(), apiHandler.TrackRecentViews())
 	{
 		// Extension management routes with RBAC
 		if extensionManager != nil {
 			extensionManager.RegisterRoutesWithRBAC(protected, authHandler.PermissionChecker)
 		}
 
-		// Global search across all resources
-		protected.GET("/search", apiHandler.Search)
+		// Cluster and resource routes
+		api.RegisterRoutes(protected, apiHandler, authHandler.PermissionChecker, authHandler.FeatureGate, endpointPolicy)
 
-		// Cluster management - read operations available to all authenticated users
-		protected.GET("/clusters", apiHandler.ListClusters)
-		protected.GET("/clusters/:name/status", apiHandler.GetClusterStatus)
-		protected.GET("/clusters/:name/metrics", apiHandler.GetClusterMetrics)
-		protected.GET("/clusters/:name/resources-summary", apiHandler.GetClusterResourcesSummary)
-		
-		// Cluster management - write operations require clusters permission
-		protected.POST("/clusters", authHandler.PermissionChecker("clusters", "create"), apiHandler.AddCluster)
-		protected.PUT("/clusters/:name", authHandler.PermissionChecker("clusters", "update"), apiHandler.UpdateCluster)
-		protected.PATCH("/clusters/:name/enabled", authHandler.PermissionChecker("clusters", "update"), apiHandler.UpdateClusterEnabled)
-		protected.DELETE("/clusters/:name", authHandler.PermissionChecker("clusters", "delete"), apiHandler.RemoveCluster)
-
-		// Namespaces (cluster-scoped)
-		protected.GET("/clusters/:name/namespaces", apiHandler.ListNamespaces)
-		protected.GET("/clusters/:name/namespaces/:namespace", apiHandler.GetNamespace)
-		protected.GET("/clusters/:name/namespaces/:namespace/metrics", apiHandler.GetNamespaceMetrics)
-		protected.PUT("/clusters/:name/namespaces/:namespace", apiHandler.UpdateNamespace)
-		protected.DELETE("/clusters/:name/namespaces/:namespace", apiHandler.DeleteNamespace)
-
-		// Pods
-		protected.GET("/clusters/:name/pods", apiHandler.ListPods)
-		protected.GET("/clusters/:name/namespaces/:namespace/pods/:pod", apiHandler.GetPod)
-		protected.GET("/clusters/:name/namespaces/:namespace/pods/:pod/metrics", apiHandler.GetPodMetrics)
-		protected.PUT("/clusters/:name/namespaces/:namespace/pods/:pod", apiHandler.UpdatePod)
-		protected.DELETE("/clusters/:name/namespaces/:namespace/pods/:pod", apiHandler.DeletePod)
-		protected.POST("/clusters/:name/namespaces/:namespace/pods/:pod/evict", apiHandler.EvictPod)
-		protected.GET("/clusters/:name/namespaces/:namespace/pods/:pod/logs", apiHandler.GetPodLogs)
-		protected.GET("/clusters/:name/namespaces/:namespace/pods/logs", apiHandler.GetMultiPodLogs)
-		protected.GET("/clusters/:name/namespaces/:namespace/pods/:pod/logs/stream", apiHandler.PodLogsStream)
-		protected.GET("/clusters/:name/namespaces/:namespace/pods/logs/stream", apiHandler.MultiPodLogsStream)
-		protected.GET("/clusters/:name/namespaces/:namespace/pods/:pod/shell", apiHandler.PodShell)
-
-		// Deployments
-		protected.GET("/clusters/:name/deployments", apiHandler.ListDeployments)
-		protected.GET("/clusters/:name/namespaces/:namespace/deployments/:deployment", apiHandler.GetDeployment)
-		protected.PUT("/clusters/:name/namespaces/:namespace/deployments/:deployment", apiHandler.UpdateDeployment)
-		protected.DELETE("/clusters/:name/namespaces/:namespace/deployments/:deployment", apiHandler.DeleteDeployment)
-		protected.PATCH("/clusters/:name/namespaces/:namespace/deployments/:deployment/scale", apiHandler.ScaleDeployment)
-		protected.POST("/clusters/:name/namespaces/:namespace/deployments/:deployment/restart", apiHandler.RestartDeployment)
-
-		// DaemonSets
-		protected.GET("/clusters/:name/daemonsets", apiHandler.ListDaemonSets)
-		protected.GET("/clusters/:name/namespaces/:namespace/daemonsets/:daemonset", apiHandler.GetDaemonSet)
-		protected.PUT("/clusters/:name/namespaces/:namespace/daemonsets/:daemonset", apiHandler.UpdateDaemonSet)
-		protected.DELETE("/clusters/:name/namespaces/:namespace/daemonsets/:daemonset", apiHandler.DeleteDaemonSet)
-		protected.POST("/clusters/:name/namespaces/:namespace/daemonsets/:daemonset/restart", apiHandler.RestartDaemonSet)
-
-		// StatefulSets
-		protected.GET("/clusters/:name/statefulsets", apiHandler.ListStatefulSets)
-		protected.GET("/clusters/:name/namespaces/:namespace/statefulsets/:statefulset", apiHandler.GetStatefulSet)
-		protected.PUT("/clusters/:name/namespaces/:namespace/statefulsets/:statefulset", apiHandler.UpdateStatefulSet)
-		protected.DELETE("/clusters/:name/namespaces/:namespace/statefulsets/:statefulset", apiHandler.DeleteStatefulSet)
-		protected.PATCH("/clusters/:name/namespaces/:namespace/statefulsets/:statefulset/scale", apiHandler.ScaleStatefulSet)
-		protected.POST("/clusters/:name/namespaces/:namespace/statefulsets/:statefulset/restart", apiHandler.RestartStatefulSet)
-
-		// ReplicaSets
-		protected.GET("/clusters/:name/replicasets", apiHandler.ListReplicaSets)
-		protected.GET("/clusters/:name/namespaces/:namespace/replicasets/:replicaset", apiHandler.GetReplicaSet)
-		protected.PUT("/clusters/:name/namespaces/:namespace/replicasets/:replicaset", apiHandler.UpdateReplicaSet)
-		protected.DELETE("/clusters/:name/namespaces/:namespace/replicasets/:replicaset", apiHandler.DeleteReplicaSet)
-		protected.PATCH("/clusters/:name/namespaces/:namespace/replicasets/:replicaset/scale", apiHandler.ScaleReplicaSet)
-
-		// Jobs
-		protected.GET("/clusters/:name/jobs", apiHandler.ListJobs)
-		protected.GET("/clusters/:name/namespaces/:namespace/jobs/:job", apiHandler.GetJob)
-		protected.PUT("/clusters/:name/namespaces/:namespace/jobs/:job", apiHandler.UpdateJob)
-		protected.DELETE("/clusters/:name/namespaces/:namespace/jobs/:job", apiHandler.DeleteJob)
-
-		// CronJobs
-		protected.GET("/clusters/:name/cronjobs", apiHandler.ListCronJobs)
-		protected.GET("/clusters/:name/namespaces/:namespace/cronjobs/:cronjob", apiHandler.GetCronJob)
-		protected.PUT("/clusters/:name/namespaces/:namespace/cronjobs/:cronjob", apiHandler.UpdateCronJob)
-		protected.DELETE("/clusters/:name/namespaces/:namespace/cronjobs/:cronjob", apiHandler.DeleteCronJob)
-
-		// Services
-		protected.GET("/clusters/:name/services", apiHandler.ListServices)
-		protected.GET("/clusters/:name/namespaces/:namespace/services/:service", apiHandler.GetService)
-		protected.PUT("/clusters/:name/namespaces/:namespace/services/:service", apiHandler.UpdateService)
-
-		// Endpoints
-		protected.GET("/clusters/:name/endpoints", apiHandler.ListEndpoints)
-		protected.GET("/clusters/:name/namespaces/:namespace/endpoints/:endpoint", apiHandler.GetEndpoint)
-
-		// Ingresses (namespaced)
-		protected.GET("/clusters/:name/namespaces/:namespace/ingresses", apiHandler.ListIngresses)
-		protected.GET("/clusters/:name/ingresses", apiHandler.ListIngresses)
-		protected.GET("/clusters/:name/namespaces/:namespace/ingresses/:ingress", apiHandler.GetIngress)
-		protected.POST("/clusters/:name/namespaces/:namespace/ingresses", apiHandler.CreateIngress)
-		protected.PUT("/clusters/:name/namespaces/:namespace/ingresses/:ingress", apiHandler.UpdateIngress)
-		protected.DELETE("/clusters/:name/namespaces/:namespace/ingresses/:ingress", apiHandler.DeleteIngress)
-
-		// Ingress Classes (cluster-scoped)
-		protected.GET("/clusters/:name/ingressclasses", apiHandler.ListIngressClasses)
-		protected.GET("/clusters/:name/ingressclasses/:ingressclass", apiHandler.GetIngressClass)
-		protected.POST("/clusters/:name/ingressclasses", apiHandler.CreateIngressClass)
-		protected.PUT("/clusters/:name/ingressclasses/:ingressclass", apiHandler.UpdateIngressClass)
-		protected.DELETE("/clusters/:name/ingressclasses/:ingressclass", apiHandler.DeleteIngressClass)
-
-		// Network Policies (namespaced)
-		protected.GET("/clusters/:name/networkpolicies", apiHandler.ListNetworkPolicies)
-		protected.GET("/clusters/:name/namespaces/:namespace/networkpolicies", apiHandler.ListNetworkPolicies)
-		protected.GET("/clusters/:name/namespaces/:namespace/networkpolicies/:networkpolicy", apiHandler.GetNetworkPolicy)
-		protected.PUT("/clusters/:name/namespaces/:namespace/networkpolicies/:networkpolicy", apiHandler.UpdateNetworkPolicy)
-		protected.DELETE("/clusters/:name/namespaces/:namespace/networkpolicies/:networkpolicy", apiHandler.DeleteNetworkPolicy)
-
-		// ConfigMaps
-		protected.GET("/clusters/:name/configmaps", apiHandler.ListConfigMaps)
-		protected.POST("/clusters/:name/namespaces/:namespace/configmaps", apiHandler.CreateConfigMap)
-		protected.GET("/clusters/:name/namespaces/:namespace/configmaps/:configmap", apiHandler.GetConfigMap)
-		protected.PUT("/clusters/:name/namespaces/:namespace/configmaps/:configmap", apiHandler.UpdateConfigMap)
-		protected.DELETE("/clusters/:name/namespaces/:namespace/configmaps/:configmap", apiHandler.DeleteConfigMap)
-
-		// Secrets
-		protected.GET("/clusters/:name/secrets", apiHandler.ListSecrets)
-		protected.POST("/clusters/:name/namespaces/:namespace/secrets", apiHandler.CreateSecret)
-		protected.GET("/clusters/:name/namespaces/:namespace/secrets/:secret", apiHandler.GetSecret)
-		protected.PUT("/clusters/:name/namespaces/:namespace/secrets/:secret", apiHandler.UpdateSecret)
-		protected.DELETE("/clusters/:name/namespaces/:namespace/secrets/:secret", apiHandler.DeleteSecret)
-
-		// Storage Classes (cluster-scoped)
-		protected.GET("/clusters/:name/storageclasses", apiHandler.ListStorageClasses)
-		protected.POST("/clusters/:name/storageclasses", apiHandler.CreateStorageClass)
-		protected.GET("/clusters/:name/storageclasses/:storageclass", apiHandler.GetStorageClass)
-		protected.PUT("/clusters/:name/storageclasses/:storageclass", apiHandler.UpdateStorageClass)
-		protected.DELETE("/clusters/:name/storageclasses/:storageclass", apiHandler.DeleteStorageClass)
-
-		// Persistent Volumes (cluster-scoped)
-		protected.GET("/clusters/:name/persistentvolumes", apiHandler.ListPersistentVolumes)
-		protected.GET("/clusters/:name/persistentvolumes/:pv", apiHandler.GetPersistentVolume)
-		protected.PUT("/clusters/:name/persistentvolumes/:pv", apiHandler.UpdatePersistentVolume)
-		protected.DELETE("/clusters/:name/persistentvolumes/:pv", apiHandler.DeletePersistentVolume)
-
-		// Persistent Volume Claims (namespaced)
-		protected.GET("/clusters/:name/persistentvolumeclaims", apiHandler.ListPersistentVolumeClaims)
-		protected.GET("/clusters/:name/namespaces/:namespace/persistentvolumeclaims", apiHandler.ListPersistentVolumeClaims)
-		protected.GET("/clusters/:name/namespaces/:namespace/persistentvolumeclaims/:pvc", apiHandler.GetPersistentVolumeClaim)
-		protected.PUT("/clusters/:name/namespaces/:namespace/persistentvolumeclaims/:pvc", apiHandler.UpdatePersistentVolumeClaim)
-		protected.DELETE("/clusters/:name/namespaces/:namespace/persistentvolumeclaims/:pvc", apiHandler.DeletePersistentVolumeClaim)
-
-		// ServiceAccounts (namespaced)
-		protected.GET("/clusters/:name/serviceaccounts", apiHandler.ListServiceAccounts)
-		protected.GET("/clusters/:name/namespaces/:namespace/serviceaccounts", apiHandler.ListServiceAccountsByNamespace)
-		protected.GET("/clusters/:name/namespaces/:namespace/serviceaccounts/:serviceaccount", apiHandler.GetServiceAccount)
-		protected.PUT("/clusters/:name/namespaces/:namespace/serviceaccounts/:serviceaccount", apiHandler.UpdateServiceAccount)
-		protected.DELETE("/clusters/:name/namespaces/:namespace/serviceaccounts/:serviceaccount", apiHandler.DeleteServiceAccount)
-		protected.POST("/clusters/:name/namespaces/:namespace/serviceaccounts", apiHandler.CreateServiceAccount)
-
-		// ClusterRoles (cluster-scoped)
-		protected.GET("/clusters/:name/clusterroles", apiHandler.ListClusterRoles)
-		protected.GET("/clusters/:name/clusterroles/:clusterrole", apiHandler.GetClusterRole)
-		protected.PUT("/clusters/:name/clusterroles/:clusterrole", apiHandler.UpdateClusterRole)
-		protected.DELETE("/clusters/:name/clusterroles/:clusterrole", apiHandler.DeleteClusterRole)
-		protected.POST("/clusters/:name/clusterroles", apiHandler.CreateClusterRole)
-
-		// Roles (namespaced)
-		protected.GET("/clusters/:name/roles", apiHandler.ListRoles)
-		protected.GET("/clusters/:name/namespaces/:namespace/roles", apiHandler.ListRolesByNamespace)
-		protected.GET("/clusters/:name/namespaces/:namespace/roles/:role", apiHandler.GetRole)
-		protected.PUT("/clusters/:name/namespaces/:namespace/roles/:role", apiHandler.UpdateRole)
-		protected.DELETE("/clusters/:name/namespaces/:namespace/roles/:role", apiHandler.DeleteRole)
-		protected.POST("/clusters/:name/namespaces/:namespace/roles", apiHandler.CreateRole)
-
-		// ClusterRoleBindings (cluster-scoped)
-		protected.GET("/clusters/:name/clusterrolebindings", apiHandler.ListClusterRoleBindings)
-		protected.GET("/clusters/:name/clusterrolebindings/:clusterrolebinding", apiHandler.GetClusterRoleBinding)
-		protected.PUT("/clusters/:name/clusterrolebindings/:clusterrolebinding", apiHandler.UpdateClusterRoleBinding)
-		protected.DELETE("/clusters/:name/clusterrolebindings/:clusterrolebinding", apiHandler.DeleteClusterRoleBinding)
-		protected.POST("/clusters/:name/clusterrolebindings", apiHandler.CreateClusterRoleBinding)
-
-		// RoleBindings (namespaced)
-		protected.GET("/clusters/:name/rolebindings", apiHandler.ListRoleBindings)
-		protected.GET("/clusters/:name/namespaces/:namespace/rolebindings", apiHandler.ListRoleBindingsByNamespace)
-		protected.GET("/clusters/:name/namespaces/:namespace/rolebindings/:rolebinding", apiHandler.GetRoleBinding)
-		protected.PUT("/clusters/:name/namespaces/:namespace/rolebindings/:rolebinding", apiHandler.UpdateRoleBinding)
-		protected.DELETE("/clusters/:name/namespaces/:namespace/rolebindings/:rolebinding", apiHandler.DeleteRoleBinding)
-		protected.POST("/clusters/:name/namespaces/:namespace/rolebindings", apiHandler.CreateRoleBinding)
-
-		// Nodes
-		protected.GET("/clusters/:name/nodes", apiHandler.ListNodes)
-		protected.GET("/clusters/:name/nodes/:node", apiHandler.GetNode)
-		protected.GET("/clusters/:name/nodes/:node/metrics", apiHandler.GetNodeMetrics)
-		protected.GET("/clusters/:name/nodes/:node/shell", apiHandler.NodeShell)
-		protected.GET("/clusters/:name/nodes/:node/drain", apiHandler.NodeDrainInteractive)
-		protected.POST("/clusters/:name/nodes/:node/cordon", apiHandler.CordonNode)
-		protected.POST("/clusters/:name/nodes/:node/uncordon", apiHandler.UncordonNode)
-		protected.POST("/clusters/:name/nodes/:node/drain", apiHandler.DrainNode)
-		protected.DELETE("/clusters/:name/nodes/:node", apiHandler.DeleteNode)
-
-		// Events
-		protected.GET("/clusters/:name/events", apiHandler.ListEvents)
-
-		// Horizontal Pod Autoscalers
-		protected.GET("/clusters/:name/hpas", apiHandler.ListHPAs)
-		protected.GET("/clusters/:name/namespaces/:namespace/hpas/:hpa", apiHandler.GetHPA)
-		protected.POST("/clusters/:name/namespaces/:namespace/hpas", apiHandler.CreateHPA)
-		protected.PUT("/clusters/:name/namespaces/:namespace/hpas/:hpa", apiHandler.UpdateHPA)
-		protected.DELETE("/clusters/:name/namespaces/:namespace/hpas/:hpa", apiHandler.DeleteHPA)
-
-		// Pod Disruption Budgets
-		protected.GET("/clusters/:name/pdbs", apiHandler.ListPDBs)
-		protected.GET("/clusters/:name/namespaces/:namespace/pdbs/:pdb", apiHandler.GetPDB)
-		protected.POST("/clusters/:name/namespaces/:namespace/pdbs", apiHandler.CreatePDB)
-		protected.PUT("/clusters/:name/namespaces/:namespace/pdbs/:pdb", apiHandler.UpdatePDB)
-		protected.DELETE("/clusters/:name/namespaces/:namespace/pdbs/:pdb", apiHandler.DeletePDB)
-
-		// Priority Classes (cluster-scoped)
-		protected.GET("/clusters/:name/priorityclasses", apiHandler.ListPriorityClasses)
-		protected.GET("/clusters/:name/priorityclasses/:priorityclass", apiHandler.GetPriorityClass)
-		protected.POST("/clusters/:name/priorityclasses", apiHandler.CreatePriorityClass)
-		protected.PUT("/clusters/:name/priorityclasses/:priorityclass", apiHandler.UpdatePriorityClass)
-		protected.DELETE("/clusters/:name/priorityclasses/:priorityclass", apiHandler.DeletePriorityClass)
-
-		// Runtime Classes (cluster-scoped)
-		protected.GET("/clusters/:name/runtimeclasses", apiHandler.ListRuntimeClasses)
-		protected.GET("/clusters/:name/runtimeclasses/:runtimeclass", apiHandler.GetRuntimeClass)
-		protected.POST("/clusters/:name/runtimeclasses", apiHandler.CreateRuntimeClass)
-		protected.PUT("/clusters/:name/runtimeclasses/:runtimeclass", apiHandler.UpdateRuntimeClass)
-		protected.DELETE("/clusters/:name/runtimeclasses/:runtimeclass", apiHandler.DeleteRuntimeClass)
-
-		// Leases (namespaced)
-		protected.GET("/clusters/:name/namespaces/:namespace/leases", apiHandler.ListLeases)
-		protected.GET("/clusters/:name/namespaces/:namespace/leases/:lease", apiHandler.GetLease)
-		protected.POST("/clusters/:name/namespaces/:namespace/leases", apiHandler.CreateLease)
-		protected.PUT("/clusters/:name/namespaces/:namespace/leases/:lease", apiHandler.UpdateLease)
-		protected.DELETE("/clusters/:name/namespaces/:namespace/leases/:lease", apiHandler.DeleteLease)
-
-		// Mutating Webhook Configurations (cluster-scoped)
-		protected.GET("/clusters/:name/mutatingwebhookconfigurations", apiHandler.ListMutatingWebhookConfigurations)
-		protected.GET("/clusters/:name/mutatingwebhookconfigurations/:webhook", apiHandler.GetMutatingWebhookConfiguration)
-		protected.POST("/clusters/:name/mutatingwebhookconfigurations", apiHandler.CreateMutatingWebhookConfiguration)
-		protected.PUT("/clusters/:name/mutatingwebhookconfigurations/:webhook", apiHandler.UpdateMutatingWebhookConfiguration)
-		protected.DELETE("/clusters/:name/mutatingwebhookconfigurations/:webhook", apiHandler.DeleteMutatingWebhookConfiguration)
-
-		// Validating Webhook Configurations (cluster-scoped)
-		protected.GET("/clusters/:name/validatingwebhookconfigurations", apiHandler.ListValidatingWebhookConfigurations)
-		protected.GET("/clusters/:name/validatingwebhookconfigurations/:webhook", apiHandler.GetValidatingWebhookConfiguration)
-		protected.POST("/clusters/:name/validatingwebhookconfigurations", apiHandler.CreateValidatingWebhookConfiguration)
-		protected.PUT("/clusters/:name/validatingwebhookconfigurations/:webhook", apiHandler.UpdateValidatingWebhookConfiguration)
-		protected.DELETE("/clusters/:name/validatingwebhookconfigurations/:webhook", apiHandler.DeleteValidatingWebhookConfiguration)
-
-		// Custom Resource Definitions (cluster-scoped)
-		protected.GET("/clusters/:name/customresourcedefinitions", apiHandler.ListCustomResourceDefinitions)
-		protected.GET("/clusters/:name/customresourcedefinitions/:crd", apiHandler.GetCustomResourceDefinition)
-		protected.PUT("/clusters/:name/customresourcedefinitions/:crd", apiHandler.UpdateCustomResourceDefinition)
-		protected.DELETE("/clusters/:name/customresourcedefinitions/:crd", apiHandler.DeleteCustomResourceDefinition)
-
-		// Custom Resources (Dynamic) - cluster-scoped
-		protected.GET("/clusters/:name/customresources", apiHandler.ListCustomResources)
-		protected.GET("/clusters/:name/customresources/:resourcename", apiHandler.GetCustomResource)
-		protected.PUT("/clusters/:name/customresources/:resourcename", apiHandler.UpdateCustomResource)
-		protected.DELETE("/clusters/:name/customresources/:resourcename", apiHandler.DeleteCustomResource)
-
-		// Custom Resources (Dynamic) - namespaced
-		protected.GET("/clusters/:name/namespaces/:namespace/customresources", apiHandler.ListCustomResources)
-		protected.GET("/clusters/:name/namespaces/:namespace/customresources/:resourcename", apiHandler.GetCustomResource)
-		protected.PUT("/clusters/:name/namespaces/:namespace/customresources/:resourcename", apiHandler.UpdateCustomResource)
-		protected.DELETE("/clusters/:name/namespaces/:namespace/customresources/:resourcename", apiHandler.DeleteCustomResource)
-
-		// WebSocket endpoint for real-time updates
+		// Scheduled log exports of a cluster - changes require clusters permission
+		logArchiveHandler := logarchive.NewHandler(database, logArchiver)
+		protected.GET("/clusters/:name/log-archives", logArchiveHandler.ListPolicies)
+		protected.POST("/clusters/:name/log-archives", authHandler.PermissionChecker("clusters", "update"), logArchiveHandler.CreatePolicy)
+		protected.PUT("/clusters/:name/log-archives/:id", authHandler.PermissionChecker("clusters", "update"), logArchiveHandler.UpdatePolicy)
+		protected.DELETE("/clusters/:name/log-archives/:id", authHandler.PermissionChecker("clusters", "update"), logArchiveHandler.DeletePolicy)
+		protected.POST("/clusters/:name/log-archives/:id/run", authHandler.PermissionChecker("clusters", "update"), logArchiveHandler.RunPolicy)
+
+		// WebSocket endpoint for real-time updates, or server-sent events with ?transport=sse
+		// Connections only receive the topics of what their owner may read
 		protected.GET("/ws", func(c *gin.Context) {
-			ws.ServeWs(wsHub, c.Writer, c.Request)
+			scope, err := authHandler.TopicScope(c)
+			if err != nil {
+				log.Errorf("Failed to get the scope of a live update connection: %v", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check permissions"})
+				return
+			}
+			if sse.Requested(c.Request) {
+				ws.ServeSSE(wsHub, c.GetInt("user_id"), scope, c.Writer, c.Request)
+				return
+			}
+			ws.ServeWs(wsHub, c.GetInt("user_id"), scope, c.Writer, c.Request)
 		})
 	}
 	}
@@ -601,6 +983,36 @@ func main() {
 	// handled by the main server for token exchange
 	v1.POST("/auth/exchange", authHandler.HandleOAuthExchange)
 
+	// OpenAPI document describing every registered route (public, built on first request)
+	openapiOptions := openapi.Options{
+		Title:   "Kubelens API",
+		Version: "1.0.0",
+		Public: []string{
+			"/api/v1/openapi.json",
+			"/api/v1/setup",
+			"/api/v1/setup/status",
+			"/api/v1/setup/token",
+			"/api/v1/auth/signin",
+			"/api/v1/auth/password/expired",
+			"/api/v1/auth/refresh",
+			"/api/v1/auth/exchange",
+			"/api/v1/auth/invitations/{token}",
+			"/api/v1/auth/invitations/{token}/accept",
+			"/api/v1/auth/password-reset/{token}",
+			"/api/v1/avatars/{id}",
+		},
+	}
+	v1.GET("/openapi.json", openapi.Handler(router, openapiOptions))
+
+	// Operator-run: the document pkg/client is generated from; the server is not started
+	if *openapiFile != "" {
+		if err := openapi.WriteFile(*openapiFile, openapi.Build(router.Routes(), openapiOptions)); err != nil {
+			log.Fatalf("Failed to write the OpenAPI document: %v", err)
+		}
+		log.Infof("✅ OpenAPI document written to %s", *openapiFile)
+		return
+	}
+
 	// Create HTTP server
 	srv := &http.Server{
 		Addr:         fmt.Sprintf(":%d", cfg.Port),
@@ -624,6 +1036,7 @@ func main() {
 	<-quit
 
 	log.Info("Shutting down server...")
+	healthChecker.SetShuttingDown()
 
 	// Graceful shutdown with timeout
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
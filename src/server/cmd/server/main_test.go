@@ -0,0 +1,57 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/sonnguyen/kubelens/internal/openapi"
+)
+
+// TestOpenAPIDocumentUpToDate checks that pkg/client/openapi.json describes the routes the
+// server registers, with the default configuration
+func TestOpenAPIDocumentUpToDate(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("KUBELENS_DATABASE_PATH", filepath.Join(dir, "kubelens.db"))
+	t.Setenv("KUBELENS_EXTENSIONS_DIR", dir)
+	out := filepath.Join(dir, "openapi.json")
+
+	args := os.Args
+	defer func() { os.Args = args }()
+	os.Args = []string{"server", "-openapi", out}
+	main()
+
+	read := func(path string) *openapi.Document {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var doc openapi.Document
+		if err := json.Unmarshal(data, &doc); err != nil {
+			t.Fatal(err)
+		}
+		return &doc
+	}
+	got, committed := read(out), read("../../pkg/client/openapi.json")
+	if reflect.DeepEqual(got, committed) {
+		return
+	}
+
+	var changed []string
+	for path, methods := range got.Paths {
+		if !reflect.DeepEqual(methods, committed.Paths[path]) {
+			changed = append(changed, path)
+		}
+	}
+	for path := range committed.Paths {
+		if _, ok := got.Paths[path]; !ok {
+			changed = append(changed, path)
+		}
+	}
+	sort.Strings(changed)
+	t.Errorf("pkg/client/openapi.json is out of date (changed paths: %v); regenerate it with\n"+
+		"\tgo run ./cmd/server -openapi pkg/client/openapi.json && go generate ./pkg/client", changed)
+}
@@ -0,0 +1,202 @@
+package alerting
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+// finding is an object matching the condition of a rule
+type finding struct {
+	Namespace string
+	Resource  string // <Kind>/<name>
+	Message   string
+}
+
+// Condition is a health check a rule can alert on
+type Condition struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Namespaced  bool   `json:"namespaced"`          // whether the namespace of a rule applies
+	Threshold   string `json:"threshold,omitempty"` // meaning of the threshold, if used
+
+	evaluate func(ctx context.Context, client kubernetes.Interface, rule *db.AlertRule) ([]finding, error)
+}
+
+// conditions are the supported rule conditions by name
+var conditions = map[string]*Condition{
+	"pod_crashloop": {
+		Description: "A container of a pod is in CrashLoopBackOff",
+		Namespaced:  true,
+		Threshold:   "restarts above which the pod alerts",
+		evaluate:    crashLoopingPods,
+	},
+	"pod_pending": {
+		Description: "A pod is not scheduled or started",
+		Namespaced:  true,
+		evaluate:    pendingPods,
+	},
+	"deployment_unavailable": {
+		Description: "A deployment has unavailable replicas",
+		Namespaced:  true,
+		Threshold:   "unavailable replicas above which the deployment alerts",
+		evaluate:    unavailableDeployments,
+	},
+	"node_pressure": {
+		Description: "A node reports memory, disk or PID pressure",
+		evaluate:    nodesUnderPressure,
+	},
+	"node_not_ready": {
+		Description: "A node is not ready",
+		evaluate:    notReadyNodes,
+	},
+}
+
+func init() {
+	for name, condition := range conditions {
+		condition.Name = name
+	}
+}
+
+// Conditions returns the supported rule conditions sorted by name
+func Conditions() []*Condition {
+	list := make([]*Condition, 0, len(conditions))
+	for _, condition := range conditions {
+		list = append(list, condition)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+func listPods(ctx context.Context, client kubernetes.Interface, namespace string) ([]corev1.Pod, error) {
+	pods, err := client.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return pods.Items, nil
+}
+
+func crashLoopingPods(ctx context.Context, client kubernetes.Interface, rule *db.AlertRule) ([]finding, error) {
+	pods, err := listPods(ctx, client, rule.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	var findings []finding
+	for _, pod := range pods {
+		statuses := append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+		for _, status := range statuses {
+			if status.State.Waiting == nil || status.State.Waiting.Reason != "CrashLoopBackOff" {
+				continue
+			}
+			if int(status.RestartCount) <= rule.Threshold {
+				continue
+			}
+			findings = append(findings, finding{
+				Namespace: pod.Namespace,
+				Resource:  "Pod/" + pod.Name,
+				Message:   fmt.Sprintf("Container %s is in CrashLoopBackOff (%d restarts)", status.Name, status.RestartCount),
+			})
+			break
+		}
+	}
+	return findings, nil
+}
+
+func pendingPods(ctx context.Context, client kubernetes.Interface, rule *db.AlertRule) ([]finding, error) {
+	pods, err := listPods(ctx, client, rule.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	var findings []finding
+	for _, pod := range pods {
+		if pod.Status.Phase != corev1.PodPending || pod.DeletionTimestamp != nil {
+			continue
+		}
+		message := "Pod is pending"
+		for _, condition := range pod.Status.Conditions {
+			if condition.Type == corev1.PodScheduled && condition.Status == corev1.ConditionFalse && condition.Message != "" {
+				message = "Pod is not scheduled: " + condition.Message
+			}
+		}
+		findings = append(findings, finding{Namespace: pod.Namespace, Resource: "Pod/" + pod.Name, Message: message})
+	}
+	return findings, nil
+}
+
+func unavailableDeployments(ctx context.Context, client kubernetes.Interface, rule *db.AlertRule) ([]finding, error) {
+	deployments, err := client.AppsV1().Deployments(rule.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	var findings []finding
+	for _, deployment := range deployments.Items {
+		unavailable := deployment.Status.UnavailableReplicas
+		if int(unavailable) <= rule.Threshold {
+			continue
+		}
+		desired := int32(1)
+		if deployment.Spec.Replicas != nil {
+			desired = *deployment.Spec.Replicas
+		}
+		findings = append(findings, finding{
+			Namespace: deployment.Namespace,
+			Resource:  "Deployment/" + deployment.Name,
+			Message:   fmt.Sprintf("%d of %d replicas unavailable", unavailable, desired),
+		})
+	}
+	return findings, nil
+}
+
+func nodesUnderPressure(ctx context.Context, client kubernetes.Interface, rule *db.AlertRule) ([]finding, error) {
+	nodes, err := client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	var findings []finding
+	for _, node := range nodes.Items {
+		var pressures []string
+		for _, condition := range node.Status.Conditions {
+			switch condition.Type {
+			case corev1.NodeMemoryPressure, corev1.NodeDiskPressure, corev1.NodePIDPressure:
+				if condition.Status == corev1.ConditionTrue {
+					pressures = append(pressures, string(condition.Type))
+				}
+			}
+		}
+		if len(pressures) > 0 {
+			findings = append(findings, finding{
+				Resource: "Node/" + node.Name,
+				Message:  "Node reports " + strings.Join(pressures, ", "),
+			})
+		}
+	}
+	return findings, nil
+}
+
+func notReadyNodes(ctx context.Context, client kubernetes.Interface, rule *db.AlertRule) ([]finding, error) {
+	nodes, err := client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	var findings []finding
+	for _, node := range nodes.Items {
+		for _, condition := range node.Status.Conditions {
+			if condition.Type != corev1.NodeReady || condition.Status == corev1.ConditionTrue {
+				continue
+			}
+			message := "Node is not ready"
+			if condition.Message != "" {
+				message += ": " + condition.Message
+			}
+			findings = append(findings, finding{Resource: "Node/" + node.Name, Message: message})
+		}
+	}
+	return findings, nil
+}
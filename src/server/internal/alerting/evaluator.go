@@ -0,0 +1,269 @@
+// Package alerting evaluates user-defined workload health rules against the connected
+// clusters and raises in-app notifications and channel alerts when they fire and resolve.
+package alerting
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/sonnguyen/kubelens/internal/cluster"
+	"github.com/sonnguyen/kubelens/internal/db"
+	"github.com/sonnguyen/kubelens/internal/notify"
+)
+
+// Alert states
+const (
+	StatePending  = "pending"
+	StateFiring   = "firing"
+	StateResolved = "resolved"
+)
+
+// resolvedRetention is how long resolved alerts are kept
+const resolvedRetention = 30 * 24 * time.Hour
+
+// Evaluator periodically evaluates the enabled alert rules
+type Evaluator struct {
+	db         *db.DB
+	dispatcher *notify.Dispatcher
+	interval   time.Duration
+	done       chan bool
+
+	// clusterNames and client resolve the connected clusters
+	clusterNames func() []string
+	client       func(name string) (kubernetes.Interface, error)
+	now          func() time.Time
+}
+
+// NewEvaluator creates a new alert rule evaluator
+func NewEvaluator(database *db.DB, clusterManager *cluster.Manager, dispatcher *notify.Dispatcher, interval time.Duration) *Evaluator {
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	return &Evaluator{
+		db:           database,
+		dispatcher:   dispatcher,
+		interval:     interval,
+		done:         make(chan bool),
+		clusterNames: clusterManager.ClusterNames,
+		client:       clusterManager.GetClient,
+		now:          time.Now,
+	}
+}
+
+// Start starts the periodic evaluation and retention loop
+func (e *Evaluator) Start() {
+	go func() {
+		ticker := time.NewTicker(e.interval)
+		retentionTicker := time.NewTicker(1 * time.Hour)
+		defer ticker.Stop()
+		defer retentionTicker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				e.Evaluate()
+			case <-retentionTicker.C:
+				if n, err := e.db.DeleteResolvedAlertsOlderThan(time.Now().Add(-resolvedRetention)); err != nil {
+					log.Warnf("Failed to delete resolved alerts: %v", err)
+				} else if n > 0 {
+					log.Infof("Deleted %d resolved alerts", n)
+				}
+			case <-e.done:
+				return
+			}
+		}
+	}()
+
+	log.Infof("✅ Alert rule evaluator started (interval: %v)", e.interval)
+}
+
+// Stop stops the evaluator
+func (e *Evaluator) Stop() {
+	close(e.done)
+	log.Info("Alert rule evaluator stopped")
+}
+
+// Evaluate evaluates every enabled rule once
+func (e *Evaluator) Evaluate() {
+	rules, err := e.db.ListEnabledAlertRules()
+	if err != nil {
+		log.Errorf("Failed to list alert rules: %v", err)
+		return
+	}
+	for _, rule := range rules {
+		e.evaluateRule(rule)
+	}
+}
+
+// evaluateRule evaluates a rule against the clusters in its scope: new matches become
+// pending alerts, pending alerts fire once the condition held for the rule's duration,
+// and alerts whose condition cleared are resolved. Alerts of clusters that could not be
+// evaluated are left as they are.
+func (e *Evaluator) evaluateRule(rule *db.AlertRule) {
+	condition, ok := conditions[rule.Condition]
+	if !ok {
+		log.Warnf("Alert rule %s has an unknown condition %q", rule.Name, rule.Condition)
+		return
+	}
+	active, err := e.db.ListActiveAlerts(rule.ID)
+	if err != nil {
+		log.Errorf("Failed to list alerts of rule %s: %v", rule.Name, err)
+		return
+	}
+	remaining := make(map[string]*db.Alert, len(active))
+	for _, alert := range active {
+		remaining[alertKey(alert.Cluster, alert.Namespace, alert.Resource)] = alert
+	}
+
+	now := e.now()
+	inScope := map[string]bool{}
+	evaluated := map[string]bool{}
+	for _, clusterName := range e.ruleClusters(rule) {
+		inScope[clusterName] = true
+		client, err := e.client(clusterName)
+		if err != nil {
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), e.interval)
+		findings, err := condition.evaluate(ctx, client, rule)
+		cancel()
+		if err != nil {
+			log.Debugf("Evaluation of alert rule %s on cluster %s failed: %v", rule.Name, clusterName, err)
+			continue
+		}
+		evaluated[clusterName] = true
+
+		for _, f := range findings {
+			key := alertKey(clusterName, f.Namespace, f.Resource)
+			alert, exists := remaining[key]
+			delete(remaining, key)
+			if !exists {
+				alert = &db.Alert{
+					RuleID:      rule.ID,
+					Cluster:     clusterName,
+					Namespace:   f.Namespace,
+					Resource:    f.Resource,
+					State:       StatePending,
+					ActiveSince: now,
+				}
+			}
+			alert.RuleName = rule.Name
+			alert.Severity = rule.Severity
+			alert.Message = f.Message
+			alert.LastSeenAt = now
+
+			fire := alert.State == StatePending && now.Sub(alert.ActiveSince) >= time.Duration(rule.ForSeconds)*time.Second
+			if fire {
+				alert.State = StateFiring
+				alert.FiredAt = &now
+			}
+			if err := e.db.SaveAlert(alert); err != nil {
+				log.Errorf("Failed to save alert of rule %s: %v", rule.Name, err)
+				continue
+			}
+			if fire {
+				log.Infof("Alert %s firing for %s/%s %s", rule.Name, clusterName, f.Namespace, f.Resource)
+				e.notify(rule, alert)
+			}
+		}
+	}
+
+	for _, alert := range remaining {
+		if !evaluated[alert.Cluster] && inScope[alert.Cluster] {
+			continue
+		}
+		if alert.State == StatePending {
+			if err := e.db.DeleteAlert(alert.ID); err != nil {
+				log.Warnf("Failed to delete pending alert %d: %v", alert.ID, err)
+			}
+			continue
+		}
+		alert.State = StateResolved
+		alert.ResolvedAt = &now
+		if err := e.db.SaveAlert(alert); err != nil {
+			log.Errorf("Failed to resolve alert %d: %v", alert.ID, err)
+			continue
+		}
+		log.Infof("Alert %s resolved for %s/%s %s", rule.Name, alert.Cluster, alert.Namespace, alert.Resource)
+		e.notify(rule, alert)
+	}
+}
+
+// ruleClusters returns the clusters a rule applies to
+func (e *Evaluator) ruleClusters(rule *db.AlertRule) []string {
+	if rule.Cluster != "" {
+		return []string{rule.Cluster}
+	}
+	return e.clusterNames()
+}
+
+// notify raises an in-app notification for the admins and the author of the rule, and
+// dispatches the alert to the notification channels
+func (e *Evaluator) notify(rule *db.AlertRule, alert *db.Alert) {
+	subject := alert.Resource
+	if alert.Namespace != "" {
+		subject = alert.Namespace + "/" + subject
+	}
+	title := fmt.Sprintf("%s: %s on %s", rule.Name, subject, alert.Cluster)
+	message := alert.Message
+	notificationType := map[string]string{
+		notify.SeverityCritical: "error",
+		notify.SeverityWarning:  "warning",
+	}[rule.Severity]
+	if notificationType == "" {
+		notificationType = "info"
+	}
+	if alert.State == StateResolved {
+		title = "Resolved: " + title
+		message = "The condition cleared."
+		notificationType = "success"
+	}
+
+	recipients := map[uint]bool{}
+	if rule.CreatedBy != 0 {
+		recipients[rule.CreatedBy] = true
+	}
+	if admins, err := e.db.ListActiveAdmins(); err == nil {
+		for _, admin := range admins {
+			recipients[admin.ID] = true
+		}
+	}
+	orgID := e.db.ClusterOrganization(alert.Cluster)
+	notifications := make([]*db.Notification, 0, len(recipients))
+	for userID := range recipients {
+		notifications = append(notifications, &db.Notification{UserID: userID, Type: notificationType, Title: truncate(title, 255), Message: message, OrganizationID: orgID})
+	}
+	if len(notifications) > 0 {
+		if err := e.db.CreateBulkNotifications(notifications); err != nil {
+			log.Warnf("Failed to create alert notifications: %v", err)
+		}
+	}
+
+	if e.dispatcher != nil {
+		e.dispatcher.Dispatch(&notify.Event{
+			Type:      "alert." + alert.State,
+			Severity:  rule.Severity,
+			Title:     title,
+			Message:   message,
+			Cluster:   alert.Cluster,
+			Namespace: alert.Namespace,
+			Resource:  alert.Resource,
+			Labels:    map[string]string{"rule": rule.Name, "condition": rule.Condition},
+		})
+	}
+}
+
+func alertKey(cluster, namespace, resource string) string {
+	return cluster + "/" + namespace + "/" + resource
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n-3] + "..."
+}
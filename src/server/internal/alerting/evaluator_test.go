@@ -0,0 +1,114 @@
+package alerting
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+func crashLoopingPod(reason string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "shop"},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:         "app",
+				RestartCount: 7,
+				State:        corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: reason}},
+			}},
+		},
+	}
+}
+
+func TestEvaluateRuleLifecycle(t *testing.T) {
+	database, err := db.New(filepath.Join(t.TempDir(), "kubelens.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	admin := &db.User{Email: "admin@example.com", Username: "admin", IsAdmin: true, IsActive: true, AuthProvider: "local"}
+	if err := database.CreateUser(admin); err != nil {
+		t.Fatal(err)
+	}
+
+	client := fake.NewSimpleClientset(crashLoopingPod("CrashLoopBackOff"))
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	e := &Evaluator{
+		db:           database,
+		interval:     time.Second,
+		clusterNames: func() []string { return []string{"prod"} },
+		client:       func(string) (kubernetes.Interface, error) { return client, nil },
+		now:          func() time.Time { return now },
+	}
+
+	rule := &db.AlertRule{Name: "crashloop", Enabled: true, Namespace: "shop", Condition: "pod_crashloop", ForSeconds: 300, Severity: "critical"}
+	if err := database.CreateAlertRule(rule); err != nil {
+		t.Fatal(err)
+	}
+	state := func() string {
+		alerts, _, _ := database.ListAlerts(db.AlertFilters{RuleID: rule.ID})
+		if len(alerts) != 1 {
+			return ""
+		}
+		return alerts[0].State
+	}
+
+	e.Evaluate()
+	if got := state(); got != StatePending {
+		t.Fatalf("state after first match = %q, want pending", got)
+	}
+
+	now = now.Add(6 * time.Minute)
+	e.Evaluate()
+	if got := state(); got != StateFiring {
+		t.Fatalf("state after 6m = %q, want firing", got)
+	}
+	notifications, _ := database.GetUserNotifications(admin.ID, db.DefaultOrganizationID, 10)
+	if len(notifications) != 1 || notifications[0].Type != "error" || notifications[0].Title != "crashloop: shop/Pod/web-1 on prod" {
+		t.Fatalf("notifications = %+v, want one error notification for the admin", notifications)
+	}
+
+	// The container recovers
+	client.CoreV1().Pods("shop").UpdateStatus(context.Background(), crashLoopingPod("ContainerCreating"), metav1.UpdateOptions{})
+	now = now.Add(time.Minute)
+	e.Evaluate()
+	if got := state(); got != StateResolved {
+		t.Fatalf("state after recovery = %q, want resolved", got)
+	}
+	if notifications, _ = database.GetUserNotifications(admin.ID, db.DefaultOrganizationID, 10); len(notifications) != 2 {
+		t.Errorf("got %d notifications, want a resolved notification too", len(notifications))
+	}
+}
+
+func TestUnavailableDeploymentsThreshold(t *testing.T) {
+	replicas := int32(3)
+	client := fake.NewSimpleClientset(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "shop"},
+			Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+			Status:     appsv1.DeploymentStatus{UnavailableReplicas: 2},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "worker", Namespace: "shop"},
+			Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+			Status:     appsv1.DeploymentStatus{UnavailableReplicas: 1},
+		},
+	)
+
+	findings, err := unavailableDeployments(context.Background(), client, &db.AlertRule{Threshold: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(findings) != 1 || findings[0].Resource != "Deployment/api" || findings[0].Message != "2 of 3 replicas unavailable" {
+		t.Errorf("findings = %+v, want only the deployment above the threshold", findings)
+	}
+}
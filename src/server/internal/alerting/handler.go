@@ -0,0 +1,260 @@
+package alerting
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/sonnguyen/kubelens/internal/audit"
+	"github.com/sonnguyen/kubelens/internal/auth"
+	"github.com/sonnguyen/kubelens/internal/db"
+	"github.com/sonnguyen/kubelens/internal/notify"
+)
+
+// maxForSeconds bounds how long a rule waits before firing (one week)
+const maxForSeconds = 7 * 24 * 3600
+
+// namespacePattern matches a Kubernetes namespace name
+var namespacePattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+// Handler handles alert rule and alert API requests
+type Handler struct {
+	db *db.DB
+}
+
+// NewHandler creates a new alerting handler
+func NewHandler(database *db.DB) *Handler {
+	return &Handler{db: database}
+}
+
+// ruleRequest is the body of create and update requests
+type ruleRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+	Enabled     *bool  `json:"enabled"`
+	Cluster     string `json:"cluster"`
+	Namespace   string `json:"namespace"`
+	Condition   string `json:"condition" binding:"required"`
+	Threshold   int    `json:"threshold"`
+	ForSeconds  int    `json:"for_seconds"`
+	Severity    string `json:"severity"`
+}
+
+// apply validates a request and copies it onto a rule
+func (req *ruleRequest) apply(rule *db.AlertRule) error {
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len(name) > 255 {
+		return fmt.Errorf("name is required (at most 255 characters)")
+	}
+	condition, ok := conditions[req.Condition]
+	if !ok {
+		return fmt.Errorf("unsupported condition %q", req.Condition)
+	}
+	if req.Namespace != "" {
+		if !condition.Namespaced {
+			return fmt.Errorf("condition %s does not apply to namespaces", req.Condition)
+		}
+		if !namespacePattern.MatchString(req.Namespace) || len(req.Namespace) > 63 {
+			return fmt.Errorf("invalid namespace %q", req.Namespace)
+		}
+	}
+	if req.Threshold < 0 {
+		return fmt.Errorf("threshold cannot be negative")
+	}
+	if req.ForSeconds < 0 || req.ForSeconds > maxForSeconds {
+		return fmt.Errorf("for_seconds must be between 0 and %d", maxForSeconds)
+	}
+	switch req.Severity {
+	case "":
+		req.Severity = notify.SeverityWarning
+	case notify.SeverityInfo, notify.SeverityWarning, notify.SeverityCritical:
+	default:
+		return fmt.Errorf("severity must be info, warning or critical")
+	}
+
+	rule.Name = name
+	rule.Description = req.Description
+	rule.Cluster = strings.TrimSpace(req.Cluster)
+	rule.Namespace = req.Namespace
+	rule.Condition = req.Condition
+	rule.Threshold = req.Threshold
+	rule.ForSeconds = req.ForSeconds
+	rule.Severity = req.Severity
+	if req.Enabled != nil {
+		rule.Enabled = *req.Enabled
+	}
+	return nil
+}
+
+func (h *Handler) auditChange(c *gin.Context, desc string, rule *db.AlertRule) {
+	if userID, exists := c.Get("user_id"); exists {
+		username, _ := c.Get("username")
+		email, _ := c.Get("email")
+		audit.Log(c, audit.EventAuditConfigChanged, userID.(int), username.(string), email.(string),
+			desc,
+			map[string]interface{}{
+				"rule":      rule.Name,
+				"condition": rule.Condition,
+				"cluster":   rule.Cluster,
+				"namespace": rule.Namespace,
+				"enabled":   rule.Enabled,
+			})
+	}
+}
+
+// ListConditions handles GET /api/v1/alert-rules/conditions
+func (h *Handler) ListConditions(c *gin.Context) {
+	c.JSON(http.StatusOK, Conditions())
+}
+
+// ListRules handles GET /api/v1/alert-rules
+func (h *Handler) ListRules(c *gin.Context) {
+	rules, err := h.db.ListAlertRules()
+	if err != nil {
+		log.Errorf("Failed to list alert rules: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list alert rules"})
+		return
+	}
+	c.JSON(http.StatusOK, rules)
+}
+
+// CreateRule handles POST /api/v1/alert-rules
+func (h *Handler) CreateRule(c *gin.Context) {
+	var req ruleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rule := &db.AlertRule{Enabled: true}
+	if err := req.apply(rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if userID, exists := c.Get("user_id"); exists {
+		rule.CreatedBy = uint(userID.(int))
+	}
+	if err := h.db.CreateAlertRule(rule); err != nil {
+		if strings.Contains(err.Error(), "UNIQUE") {
+			c.JSON(http.StatusConflict, gin.H{"error": "an alert rule with this name already exists"})
+			return
+		}
+		log.Errorf("Failed to create alert rule: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create alert rule"})
+		return
+	}
+
+	h.auditChange(c, fmt.Sprintf("Created alert rule %s", rule.Name), rule)
+	c.JSON(http.StatusCreated, rule)
+}
+
+// UpdateRule handles PUT /api/v1/alert-rules/:id. Disabling a rule resolves its alerts.
+func (h *Handler) UpdateRule(c *gin.Context) {
+	rule, ok := h.rule(c)
+	if !ok {
+		return
+	}
+
+	var req ruleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := req.apply(rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.db.UpdateAlertRule(rule); err != nil {
+		if strings.Contains(err.Error(), "UNIQUE") {
+			c.JSON(http.StatusConflict, gin.H{"error": "an alert rule with this name already exists"})
+			return
+		}
+		log.Errorf("Failed to update alert rule: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update alert rule"})
+		return
+	}
+	if !rule.Enabled {
+		if err := h.db.ResolveActiveAlerts(rule.ID); err != nil {
+			log.Warnf("Failed to resolve alerts of disabled rule %s: %v", rule.Name, err)
+		}
+	}
+
+	h.auditChange(c, fmt.Sprintf("Updated alert rule %s", rule.Name), rule)
+	c.JSON(http.StatusOK, rule)
+}
+
+// DeleteRule handles DELETE /api/v1/alert-rules/:id
+func (h *Handler) DeleteRule(c *gin.Context) {
+	rule, ok := h.rule(c)
+	if !ok {
+		return
+	}
+	if err := h.db.DeleteAlertRule(rule.ID); err != nil {
+		log.Errorf("Failed to delete alert rule: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete alert rule"})
+		return
+	}
+
+	h.auditChange(c, fmt.Sprintf("Deleted alert rule %s", rule.Name), rule)
+	c.JSON(http.StatusOK, gin.H{"message": "alert rule deleted"})
+}
+
+// ListAlerts handles GET /api/v1/alerts
+// Query params: state (pending, firing, resolved or active), cluster, rule_id, page, page_size
+func (h *Handler) ListAlerts(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "50"))
+	if pageSize > 500 {
+		pageSize = 500 // Max 500 per page
+	}
+	ruleID, _ := strconv.ParseUint(c.Query("rule_id"), 10, 32)
+
+	state := c.Query("state")
+	switch state {
+	case "", "active", StatePending, StateFiring, StateResolved:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "state must be pending, firing, resolved or active"})
+		return
+	}
+
+	alerts, total, err := h.db.ListAlerts(db.AlertFilters{
+		State:    state,
+		Cluster:  c.Query("cluster"),
+		RuleID:   uint(ruleID),
+		Visible:  auth.ClusterScopeFilter(c, ""),
+		Page:     page,
+		PageSize: pageSize,
+	})
+	if err != nil {
+		log.Errorf("Failed to list alerts: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list alerts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"alerts":    alerts,
+		"total":     total,
+		"page":      page,
+		"page_size": pageSize,
+	})
+}
+
+// rule loads the rule named in the route, writing the error response when it cannot
+func (h *Handler) rule(c *gin.Context) (*db.AlertRule, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid alert rule ID"})
+		return nil, false
+	}
+	rule, err := h.db.GetAlertRule(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	return rule, true
+}
@@ -0,0 +1,471 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/sonnguyen/kubelens/internal/audit"
+)
+
+// argoApplicationGVK is the kind of Argo CD Applications
+var argoApplicationGVK = schema.GroupVersionKind{Group: "argoproj.io", Version: "v1alpha1", Kind: "Application"}
+
+const (
+	// argoRefreshAnnotation asks the Argo CD controller to compare the app with git again
+	argoRefreshAnnotation = "argocd.argoproj.io/refresh"
+	// argoTrackingAnnotation is set on managed resources with annotation based tracking:
+	// <app>:<group>/<kind>:<namespace>/<name>
+	argoTrackingAnnotation = "argocd.argoproj.io/tracking-id"
+	// argoInstanceLabel is set on managed resources with label based tracking (the default)
+	argoInstanceLabel = "app.kubernetes.io/instance"
+)
+
+// ArgoApplication is the status of an Argo CD Application
+type ArgoApplication struct {
+	Name                 string `json:"name"`
+	Namespace            string `json:"namespace"`
+	Project              string `json:"project"`
+	RepoURL              string `json:"repoURL,omitempty"`
+	Path                 string `json:"path,omitempty"`
+	Chart                string `json:"chart,omitempty"`
+	TargetRevision       string `json:"targetRevision,omitempty"`
+	DestinationServer    string `json:"destinationServer,omitempty"`
+	DestinationName      string `json:"destinationName,omitempty"`
+	DestinationNamespace string `json:"destinationNamespace,omitempty"`
+	AutoSync             bool   `json:"autoSync"`
+	SyncStatus           string `json:"syncStatus"`   // Synced, OutOfSync, Unknown
+	HealthStatus         string `json:"healthStatus"` // Healthy, Progressing, Degraded, Suspended, Missing, Unknown
+	HealthMessage        string `json:"healthMessage,omitempty"`
+	Revision             string `json:"revision,omitempty"`
+	OperationPhase       string `json:"operationPhase,omitempty"` // Running, Succeeded, Failed, Error, Terminating
+	OperationMessage     string `json:"operationMessage,omitempty"`
+	ReconciledAt         string `json:"reconciledAt,omitempty"`
+	Resources            int    `json:"resources"`
+	OutOfSyncResources   int    `json:"outOfSyncResources"`
+}
+
+// ArgoApplicationResource is a resource managed by an Application
+type ArgoApplicationResource struct {
+	Group        string `json:"group,omitempty"`
+	Version      string `json:"version"`
+	Kind         string `json:"kind"`
+	Namespace    string `json:"namespace,omitempty"`
+	Name         string `json:"name"`
+	SyncStatus   string `json:"syncStatus,omitempty"`
+	HealthStatus string `json:"healthStatus,omitempty"`
+	Message      string `json:"message,omitempty"`
+}
+
+// ArgoSummary counts the Applications of a cluster by sync and health status
+type ArgoSummary struct {
+	Applications int            `json:"applications"`
+	Sync         map[string]int `json:"sync"`
+	Health       map[string]int `json:"health"`
+}
+
+// ArgoApplicationRef names the Application that manages a resource
+type ArgoApplicationRef struct {
+	Name         string `json:"name"`
+	Namespace    string `json:"namespace"`
+	SyncStatus   string `json:"syncStatus"`
+	HealthStatus string `json:"healthStatus"`
+}
+
+// argoApplications returns the Applications of a cluster (all namespaces when namespace
+// is empty). installed is false when the Argo CD CRDs are not present.
+func (h *Handler) argoApplications(c *gin.Context, clusterName, namespace string) (items []unstructured.Unstructured, installed bool, err error) {
+	resource, installed, err := h.argoApplicationResource(c, clusterName)
+	if err != nil || !installed {
+		return nil, installed, err
+	}
+	list, err := resource.Namespace(namespace).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return nil, true, err
+	}
+	return list.Items, true, nil
+}
+
+// argoApplicationResource returns the client of Applications, or installed=false
+func (h *Handler) argoApplicationResource(c *gin.Context, clusterName string) (dynamic.NamespaceableResourceInterface, bool, error) {
+	client, err := h.dynamicClient(c, clusterName)
+	if err != nil {
+		return nil, false, err
+	}
+	mapping, installed, err := h.optionalMapping(clusterName, argoApplicationGVK)
+	if err != nil || !installed {
+		return nil, false, err
+	}
+	return client.Resource(mapping.Resource), true, nil
+}
+
+// ListArgoApplications handles GET /clusters/:name/argocd/applications
+// Query: namespace (optional) - only Applications defined in this namespace
+func (h *Handler) ListArgoApplications(c *gin.Context) {
+	clusterName := c.Param("name")
+
+	items, installed, err := h.argoApplications(c, clusterName, c.Query("namespace"))
+	if err != nil {
+		if apierrors.IsForbidden(err) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		log.Errorf("Failed to list Argo CD applications in cluster %s: %v", clusterName, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	applications := make([]ArgoApplication, 0, len(items))
+	for i := range items {
+		applications = append(applications, argoApplicationStatus(&items[i]))
+	}
+	sort.Slice(applications, func(i, j int) bool {
+		if applications[i].Namespace != applications[j].Namespace {
+			return applications[i].Namespace < applications[j].Namespace
+		}
+		return applications[i].Name < applications[j].Name
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"installed":    installed,
+		"applications": applications,
+		"summary":      summarizeArgoApplications(applications),
+	})
+}
+
+// GetArgoApplication handles GET /clusters/:name/argocd/applications/:namespace/:app and
+// returns the Application status with its managed resources and conditions
+func (h *Handler) GetArgoApplication(c *gin.Context) {
+	app, ok := h.getArgoApplication(c)
+	if !ok {
+		return
+	}
+
+	resources := []ArgoApplicationResource{}
+	for _, r := range nestedObjectList(app.Object, "status", "resources") {
+		resources = append(resources, ArgoApplicationResource{
+			Group:        nestedStringValue(r, "group"),
+			Version:      nestedStringValue(r, "version"),
+			Kind:         nestedStringValue(r, "kind"),
+			Namespace:    nestedStringValue(r, "namespace"),
+			Name:         nestedStringValue(r, "name"),
+			SyncStatus:   nestedStringValue(r, "status"),
+			HealthStatus: nestedStringValue(r, "health", "status"),
+			Message:      nestedStringValue(r, "health", "message"),
+		})
+	}
+	conditions, _, _ := unstructured.NestedSlice(app.Object, "status", "conditions")
+	if conditions == nil {
+		conditions = []interface{}{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"application": argoApplicationStatus(app),
+		"resources":   resources,
+		"conditions":  conditions,
+	})
+}
+
+// RefreshArgoApplication handles POST /clusters/:name/argocd/applications/:namespace/:app/refresh
+// by setting the refresh annotation, which makes Argo CD compare the app with git again.
+// Query: hard=true also invalidates the manifest cache
+func (h *Handler) RefreshArgoApplication(c *gin.Context) {
+	app, ok := h.getArgoApplication(c)
+	if !ok {
+		return
+	}
+
+	mode := "normal"
+	if c.Query("hard") == "true" {
+		mode = "hard"
+	}
+	patch := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{argoRefreshAnnotation: mode},
+		},
+	}
+	h.patchArgoApplication(c, app, patch, fmt.Sprintf("Requested %s refresh of Argo CD application %s", mode, app.GetName()), map[string]interface{}{"mode": mode})
+}
+
+// argoSyncRequest is the body of a sync request
+type argoSyncRequest struct {
+	Revision string `json:"revision"` // default: the target revision of the app
+	Prune    bool   `json:"prune"`
+	DryRun   bool   `json:"dryRun"`
+}
+
+// SyncArgoApplication handles POST /clusters/:name/argocd/applications/:namespace/:app/sync
+// by setting the operation field of the Application, as the argocd CLI does. Fails with
+// 409 while another operation is running.
+func (h *Handler) SyncArgoApplication(c *gin.Context) {
+	var req argoSyncRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	app, ok := h.getArgoApplication(c)
+	if !ok {
+		return
+	}
+	_, pending, _ := unstructured.NestedMap(app.Object, "operation")
+	if pending || nestedStringValue(app.Object, "status", "operationState", "phase") == "Running" {
+		c.JSON(http.StatusConflict, gin.H{"error": "another operation is already in progress for this application"})
+		return
+	}
+
+	username, _ := c.Get("username")
+	sync := map[string]interface{}{
+		"prune":        req.Prune,
+		"dryRun":       req.DryRun,
+		"syncStrategy": map[string]interface{}{"hook": map[string]interface{}{}},
+	}
+	if sources := nestedObjectList(app.Object, "spec", "sources"); len(sources) > 0 {
+		revisions := make([]interface{}, len(sources))
+		for i, source := range sources {
+			revisions[i] = nestedStringValue(source, "targetRevision")
+		}
+		sync["revisions"] = revisions
+	} else {
+		revision := req.Revision
+		if revision == "" {
+			revision = nestedStringValue(app.Object, "spec", "source", "targetRevision")
+		}
+		sync["revision"] = revision
+	}
+
+	patch := map[string]interface{}{
+		"operation": map[string]interface{}{
+			"initiatedBy": map[string]interface{}{"username": fmt.Sprintf("kubelens:%v", username)},
+			"info":        []interface{}{map[string]interface{}{"name": "Reason", "value": "Sync requested from kubelens"}},
+			"sync":        sync,
+		},
+	}
+	h.patchArgoApplication(c, app, patch, fmt.Sprintf("Requested sync of Argo CD application %s", app.GetName()),
+		map[string]interface{}{"prune": req.Prune, "dry_run": req.DryRun, "revision": sync["revision"]})
+}
+
+// getArgoApplication loads the Application named in the route, writing the error response
+// when it cannot
+func (h *Handler) getArgoApplication(c *gin.Context) (*unstructured.Unstructured, bool) {
+	clusterName := c.Param("name")
+	resource, installed, err := h.argoApplicationResource(c, clusterName)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	if !installed {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Argo CD is not installed in this cluster"})
+		return nil, false
+	}
+
+	app, err := resource.Namespace(c.Param("namespace")).Get(context.Background(), c.Param("app"), metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return nil, false
+		}
+		log.Errorf("Failed to get Argo CD application %s: %v", c.Param("app"), err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	return app, true
+}
+
+// patchArgoApplication merge-patches an Application and responds with its new status
+func (h *Handler) patchArgoApplication(c *gin.Context, app *unstructured.Unstructured, patch map[string]interface{}, desc string, meta map[string]interface{}) {
+	clusterName := c.Param("name")
+	resource, _, err := h.argoApplicationResource(c, clusterName)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	data, _ := json.Marshal(patch)
+	patched, err := resource.Namespace(app.GetNamespace()).Patch(context.Background(), app.GetName(), types.MergePatchType, data, patchOptions(c))
+	if err != nil {
+		if apierrors.IsForbidden(err) || apierrors.IsInvalid(err) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
+		log.Errorf("Failed to patch Argo CD application %s: %v", app.GetName(), err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Audit log
+	if userID, exists := c.Get("user_id"); exists {
+		username, _ := c.Get("username")
+		email, _ := c.Get("email")
+		meta["cluster_name"] = clusterName
+		meta["namespace"] = app.GetNamespace()
+		meta["application"] = app.GetName()
+		audit.Log(c, audit.EventAuditResourceUpdated, userID.(int), username.(string), email.(string), desc, meta)
+	}
+
+	c.JSON(http.StatusOK, argoApplicationStatus(patched))
+}
+
+// argoApplicationStatus extracts the status of an Application
+func argoApplicationStatus(app *unstructured.Unstructured) ArgoApplication {
+	obj := app.Object
+	status := ArgoApplication{
+		Name:                 app.GetName(),
+		Namespace:            app.GetNamespace(),
+		Project:              nestedStringValue(obj, "spec", "project"),
+		RepoURL:              nestedStringValue(obj, "spec", "source", "repoURL"),
+		Path:                 nestedStringValue(obj, "spec", "source", "path"),
+		Chart:                nestedStringValue(obj, "spec", "source", "chart"),
+		TargetRevision:       nestedStringValue(obj, "spec", "source", "targetRevision"),
+		DestinationServer:    nestedStringValue(obj, "spec", "destination", "server"),
+		DestinationName:      nestedStringValue(obj, "spec", "destination", "name"),
+		DestinationNamespace: nestedStringValue(obj, "spec", "destination", "namespace"),
+		SyncStatus:           nestedStringValue(obj, "status", "sync", "status"),
+		HealthStatus:         nestedStringValue(obj, "status", "health", "status"),
+		HealthMessage:        nestedStringValue(obj, "status", "health", "message"),
+		Revision:             nestedStringValue(obj, "status", "sync", "revision"),
+		OperationPhase:       nestedStringValue(obj, "status", "operationState", "phase"),
+		OperationMessage:     nestedStringValue(obj, "status", "operationState", "message"),
+		ReconciledAt:         nestedStringValue(obj, "status", "reconciledAt"),
+	}
+	if sources := nestedObjectList(obj, "spec", "sources"); status.RepoURL == "" && len(sources) > 0 {
+		// Multi-source app: show the first source
+		status.RepoURL = nestedStringValue(sources[0], "repoURL")
+		status.Path = nestedStringValue(sources[0], "path")
+		status.Chart = nestedStringValue(sources[0], "chart")
+		status.TargetRevision = nestedStringValue(sources[0], "targetRevision")
+	}
+	if _, automated, _ := unstructured.NestedMap(obj, "spec", "syncPolicy", "automated"); automated {
+		status.AutoSync = true
+	}
+	if status.SyncStatus == "" {
+		status.SyncStatus = "Unknown"
+	}
+	if status.HealthStatus == "" {
+		status.HealthStatus = "Unknown"
+	}
+	for _, r := range nestedObjectList(obj, "status", "resources") {
+		status.Resources++
+		if nestedStringValue(r, "status") == "OutOfSync" {
+			status.OutOfSyncResources++
+		}
+	}
+	return status
+}
+
+// summarizeArgoApplications counts Applications by sync and health status
+func summarizeArgoApplications(applications []ArgoApplication) ArgoSummary {
+	summary := ArgoSummary{Applications: len(applications), Sync: map[string]int{}, Health: map[string]int{}}
+	for _, app := range applications {
+		summary.Sync[app.SyncStatus]++
+		summary.Health[app.HealthStatus]++
+	}
+	return summary
+}
+
+// argoApplicationSummary returns the Argo CD summary of a cluster for the overview, or
+// nil when Argo CD is not installed or cannot be read
+func (h *Handler) argoApplicationSummary(c *gin.Context, clusterName string) *ArgoSummary {
+	items, installed, err := h.argoApplications(c, clusterName, "")
+	if err != nil {
+		log.Warnf("Failed to list Argo CD applications in cluster %s: %v", clusterName, err)
+		return nil
+	}
+	if !installed {
+		return nil
+	}
+	applications := make([]ArgoApplication, 0, len(items))
+	for i := range items {
+		applications = append(applications, argoApplicationStatus(&items[i]))
+	}
+	summary := summarizeArgoApplications(applications)
+	return &summary
+}
+
+// argoOwnerName returns the name of the Application tracking an object, from the
+// tracking-id annotation or the instance label, and whether it came from the annotation
+func argoOwnerName(obj *unstructured.Unstructured) (name string, fromAnnotation bool) {
+	if tracking := obj.GetAnnotations()[argoTrackingAnnotation]; tracking != "" {
+		app, _, _ := strings.Cut(tracking, ":")
+		return app, true
+	}
+	return obj.GetLabels()[argoInstanceLabel], false
+}
+
+// argoOwner returns the Application that manages an object, or nil. The instance label is
+// also set by Helm, so it only counts when an Application with that name lists the object
+// among its resources.
+func (h *Handler) argoOwner(c *gin.Context, clusterName string, obj *unstructured.Unstructured) *ArgoApplicationRef {
+	name, fromAnnotation := argoOwnerName(obj)
+	if name == "" {
+		return nil
+	}
+	// Applications outside the control plane namespace are tracked as <namespace>_<name>
+	appNamespace := ""
+	if ns, n, ok := strings.Cut(name, "_"); ok {
+		appNamespace, name = ns, n
+	}
+
+	items, installed, err := h.argoApplications(c, clusterName, appNamespace)
+	if err != nil || !installed {
+		return nil
+	}
+	for i := range items {
+		app := &items[i]
+		if app.GetName() != name || (!fromAnnotation && !argoManages(app, obj)) {
+			continue
+		}
+		status := argoApplicationStatus(app)
+		return &ArgoApplicationRef{
+			Name:         status.Name,
+			Namespace:    status.Namespace,
+			SyncStatus:   status.SyncStatus,
+			HealthStatus: status.HealthStatus,
+		}
+	}
+	return nil
+}
+
+// argoManages reports whether an Application lists an object among its resources
+func argoManages(app, obj *unstructured.Unstructured) bool {
+	gvk := obj.GroupVersionKind()
+	for _, r := range nestedObjectList(app.Object, "status", "resources") {
+		if nestedStringValue(r, "kind") == gvk.Kind && nestedStringValue(r, "group") == gvk.Group &&
+			nestedStringValue(r, "name") == obj.GetName() && nestedStringValue(r, "namespace") == obj.GetNamespace() {
+			return true
+		}
+	}
+	return false
+}
+
+// nestedStringValue returns the string at a path, or ""
+func nestedStringValue(obj map[string]interface{}, fields ...string) string {
+	value, _, _ := unstructured.NestedString(obj, fields...)
+	return value
+}
+
+// nestedObjectList returns the map elements of the list at a path
+func nestedObjectList(obj map[string]interface{}, fields ...string) []map[string]interface{} {
+	list, _, _ := unstructured.NestedSlice(obj, fields...)
+	objects := make([]map[string]interface{}, 0, len(list))
+	for _, item := range list {
+		if m, ok := item.(map[string]interface{}); ok {
+			objects = append(objects, m)
+		}
+	}
+	return objects
+}
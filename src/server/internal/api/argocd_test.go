@@ -0,0 +1,117 @@
+package api_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/sonnguyen/kubelens/internal/apitest"
+)
+
+var argoApplications = schema.GroupVersionResource{Group: "argoproj.io", Version: "v1alpha1", Resource: "applications"}
+
+func argoApplication(name, syncStatus, healthStatus string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "argoproj.io/v1alpha1",
+		"kind":       "Application",
+		"metadata":   map[string]interface{}{"name": name, "namespace": "argocd"},
+		"spec": map[string]interface{}{
+			"project": "default",
+			"source": map[string]interface{}{
+				"repoURL":        "https://github.com/example/apps.git",
+				"path":           name,
+				"targetRevision": "main",
+			},
+			"destination": map[string]interface{}{"server": "https://kubernetes.default.svc", "namespace": "shop"},
+		},
+		"status": map[string]interface{}{
+			"sync":   map[string]interface{}{"status": syncStatus, "revision": "abc123"},
+			"health": map[string]interface{}{"status": healthStatus},
+			"resources": []interface{}{
+				map[string]interface{}{"group": "apps", "version": "v1", "kind": "Deployment", "namespace": "shop", "name": name, "status": syncStatus},
+			},
+		},
+	}}
+}
+
+func TestArgoApplications(t *testing.T) {
+	s := apitest.New(t,
+		argoApplication("web", "Synced", "Healthy"),
+		argoApplication("api", "OutOfSync", "Degraded"),
+	)
+	s.Cluster.Client.Resources = append(s.Cluster.Client.Resources, &metav1.APIResourceList{
+		GroupVersion: "argoproj.io/v1alpha1",
+		APIResources: []metav1.APIResource{{Name: "applications", SingularName: "application", Namespaced: true, Kind: "Application"}},
+	})
+
+	var list struct {
+		Installed    bool `json:"installed"`
+		Applications []struct {
+			Name               string `json:"name"`
+			SyncStatus         string `json:"syncStatus"`
+			OutOfSyncResources int    `json:"outOfSyncResources"`
+		} `json:"applications"`
+		Summary struct {
+			Applications int            `json:"applications"`
+			Sync         map[string]int `json:"sync"`
+		} `json:"summary"`
+	}
+	w := s.Get("/api/v1/clusters/test/argocd/applications")
+	if w.Code != http.StatusOK {
+		t.Fatalf("list status = %d: %s", w.Code, w.Body)
+	}
+	apitest.DecodeJSON(t, w, &list)
+	if !list.Installed || len(list.Applications) != 2 || list.Applications[0].Name != "api" {
+		t.Fatalf("list = %+v, want api and web", list)
+	}
+	if list.Applications[0].OutOfSyncResources != 1 || list.Summary.Sync["OutOfSync"] != 1 {
+		t.Errorf("list = %+v, want one out of sync application", list)
+	}
+
+	if w := s.Do(http.MethodPost, "/api/v1/clusters/test/argocd/applications/argocd/web/refresh?hard=true", nil); w.Code != http.StatusOK {
+		t.Fatalf("refresh status = %d: %s", w.Code, w.Body)
+	}
+	if w := s.Do(http.MethodPost, "/api/v1/clusters/test/argocd/applications/argocd/api/sync", map[string]interface{}{"prune": true}); w.Code != http.StatusOK {
+		t.Fatalf("sync status = %d: %s", w.Code, w.Body)
+	}
+	// The first operation is still pending
+	if w := s.Do(http.MethodPost, "/api/v1/clusters/test/argocd/applications/argocd/api/sync", nil); w.Code != http.StatusConflict {
+		t.Errorf("second sync status = %d, want 409", w.Code)
+	}
+
+	ctx := context.Background()
+	web, _ := s.Cluster.Dynamic.Resource(argoApplications).Namespace("argocd").Get(ctx, "web", metav1.GetOptions{})
+	if mode := web.GetAnnotations()["argocd.argoproj.io/refresh"]; mode != "hard" {
+		t.Errorf("refresh annotation = %q, want hard", mode)
+	}
+	api, _ := s.Cluster.Dynamic.Resource(argoApplications).Namespace("argocd").Get(ctx, "api", metav1.GetOptions{})
+	if revision, _, _ := unstructured.NestedString(api.Object, "operation", "sync", "revision"); revision != "main" {
+		t.Errorf("sync revision = %q, want the target revision main", revision)
+	}
+	if prune, _, _ := unstructured.NestedBool(api.Object, "operation", "sync", "prune"); !prune {
+		t.Error("sync operation does not prune")
+	}
+}
+
+func TestArgoApplicationsNotInstalled(t *testing.T) {
+	s := apitest.New(t)
+
+	var list struct {
+		Installed bool `json:"installed"`
+	}
+	w := s.Get("/api/v1/clusters/test/argocd/applications")
+	if w.Code != http.StatusOK {
+		t.Fatalf("list status = %d: %s", w.Code, w.Body)
+	}
+	apitest.DecodeJSON(t, w, &list)
+	if list.Installed {
+		t.Error("installed = true without the Argo CD CRDs")
+	}
+	if w := s.Do(http.MethodPost, "/api/v1/clusters/test/argocd/applications/argocd/web/sync", nil); w.Code != http.StatusNotFound {
+		t.Errorf("sync status = %d, want 404", w.Code)
+	}
+}
@@ -0,0 +1,290 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/sonnguyen/kubelens/internal/audit"
+)
+
+// maxAuditChangesSize bounds the changes recorded in an audit entry; larger changes are
+// recorded as their paths only
+const maxAuditChangesSize = 32 * 1024
+
+// redactedValue replaces the values of Secrets in recorded changes
+const redactedValue = "<redacted>"
+
+// auditedResources maps the resource segment of the typed cluster routes to the resource
+// it names (see RegisterRoutes)
+var auditedResources = map[string]string{
+	"namespaces": "namespaces", "nodes": "nodes", "pods": "pods",
+	"deployments": "deployments", "daemonsets": "daemonsets", "statefulsets": "statefulsets",
+	"replicasets": "replicasets", "jobs": "jobs", "cronjobs": "cronjobs",
+	"services": "services", "endpoints": "endpoints", "endpointslices": "endpointslices",
+	"ingresses": "ingresses", "ingressclasses": "ingressclasses", "networkpolicies": "networkpolicies",
+	"configmaps": "configmaps", "secrets": "secrets", "storageclasses": "storageclasses",
+	"persistentvolumes": "persistentvolumes", "persistentvolumeclaims": "persistentvolumeclaims",
+	"serviceaccounts": "serviceaccounts", "roles": "roles", "rolebindings": "rolebindings",
+	"clusterroles": "clusterroles", "clusterrolebindings": "clusterrolebindings",
+	"hpas": "horizontalpodautoscalers", "pdbs": "poddisruptionbudgets",
+	"priorityclasses": "priorityclasses", "runtimeclasses": "runtimeclasses",
+	"leases": "leases", "limitranges": "limitranges",
+	"mutatingwebhookconfigurations":   "mutatingwebhookconfigurations",
+	"validatingwebhookconfigurations": "validatingwebhookconfigurations",
+	"customresourcedefinitions":       "customresourcedefinitions",
+	"certificatesigningrequests":      "certificatesigningrequests",
+}
+
+// auditTarget is the object a request changes
+type auditTarget struct {
+	cluster   string
+	resource  string
+	namespace string
+	name      string
+}
+
+// auditTargetOf returns the object a cluster route changes, from its route pattern:
+// /clusters/:name/[namespaces/:namespace/]<resource>/:<param>[/<action>], the generic
+// /clusters/:name/resources/:resource/:resname routes and custom resources
+func auditTargetOf(c *gin.Context) (auditTarget, bool) {
+	_, route, found := strings.Cut(c.FullPath(), "/clusters/:name/")
+	if !found {
+		return auditTarget{}, false
+	}
+	target := auditTarget{cluster: c.Param("name")}
+	segments := strings.Split(route, "/")
+
+	if segments[0] == "namespaces" && len(segments) >= 2 && segments[1] == ":namespace" {
+		if len(segments) == 2 {
+			target.resource, target.name = "namespaces", c.Param("namespace")
+			return target, target.name != ""
+		}
+		target.namespace = c.Param("namespace")
+		segments = segments[2:]
+	}
+	if len(segments) < 2 || !strings.HasPrefix(segments[1], ":") {
+		return auditTarget{}, false
+	}
+
+	switch segments[0] {
+	case "resources":
+		if len(segments) < 3 || !strings.HasPrefix(segments[2], ":") {
+			return auditTarget{}, false
+		}
+		target.resource = c.Param(segments[1][1:])
+		target.name = c.Param(segments[2][1:])
+		target.namespace = c.Query("namespace")
+	case "customresources":
+		resource, version, group := c.Query("resource"), c.Query("version"), c.Query("group")
+		if resource == "" || version == "" {
+			return auditTarget{}, false
+		}
+		target.resource = resource + "." + version + "." + group
+		target.name = c.Param(segments[1][1:])
+	default:
+		resource, ok := auditedResources[segments[0]]
+		if !ok {
+			return auditTarget{}, false
+		}
+		target.resource = resource
+		target.name = c.Param(segments[1][1:])
+	}
+	return target, target.resource != "" && target.name != ""
+}
+
+// AuditResourceChanges records what requests change in Kubernetes objects. The object is
+// read before the handler runs and again when the request is audited, and the normalized
+// difference (Secret values redacted) is added to the audit entry as "changes". Changes
+// made by handlers that record no audit entry of their own are recorded here.
+func (h *Handler) AuditResourceChanges() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodPost:
+		default:
+			c.Next()
+			return
+		}
+		if dryRunValue(c) != nil {
+			c.Next()
+			return
+		}
+		target, ok := auditTargetOf(c)
+		if !ok {
+			c.Next()
+			return
+		}
+		mapping, err := h.resolveResource(target.cluster, target.resource)
+		if err != nil {
+			c.Next()
+			return
+		}
+		if !isNamespaced(mapping) {
+			target.namespace = ""
+		} else if target.namespace == "" {
+			c.Next()
+			return
+		}
+
+		get := func() (*unstructured.Unstructured, error) {
+			// Read with the server's credentials: the entry is recorded whatever the user may read
+			dynamicClient, err := h.clusterManager.GetDynamicClient(target.cluster)
+			if err != nil {
+				return nil, err
+			}
+			obj, err := dynamicClient.Resource(mapping.Resource).Namespace(target.namespace).Get(context.Background(), target.name, metav1.GetOptions{})
+			if apierrors.IsNotFound(err) {
+				return nil, nil
+			}
+			return obj, err
+		}
+		before, err := get()
+		if err != nil {
+			log.Debugf("Not recording changes to %s %s: %v", mapping.GroupVersionKind.Kind, target.name, err)
+			c.Next()
+			return
+		}
+
+		var once sync.Once
+		var changes interface{}
+		c.Set(audit.ChangesKey, func() interface{} {
+			once.Do(func() {
+				var after *unstructured.Unstructured
+				if c.Request.Method != http.MethodDelete {
+					var err error
+					if after, err = get(); err != nil {
+						log.Debugf("Not recording changes to %s %s: %v", mapping.GroupVersionKind.Kind, target.name, err)
+						return
+					}
+				}
+				if diff := resourceChanges(mapping.GroupVersionKind.Kind, before, after); diff != nil {
+					changes = diff
+				}
+			})
+			return changes
+		})
+
+		c.Next()
+
+		if c.Writer.Status() >= http.StatusMultipleChoices || c.GetBool(audit.LoggedKey) {
+			return
+		}
+		userID, exists := c.Get("user_id")
+		if !exists {
+			return
+		}
+		username, _ := c.Get("username")
+		email, _ := c.Get("email")
+
+		event, action := audit.EventAuditResourceUpdated, "Updated"
+		if c.Request.Method == http.MethodDelete {
+			event, action = audit.EventAuditResourceDeleted, "Deleted"
+		}
+		objectName := target.name
+		if target.namespace != "" {
+			objectName = target.namespace + "/" + target.name
+		}
+		audit.Log(c, event, userID.(int), username.(string), email.(string),
+			fmt.Sprintf("%s %s: %s", action, strings.ToLower(mapping.GroupVersionKind.Kind), objectName),
+			map[string]interface{}{
+				"cluster_name": target.cluster,
+				"namespace":    target.namespace,
+				"kind":         mapping.GroupVersionKind.Kind,
+				"name":         target.name,
+			})
+	}
+}
+
+// resourceChanges returns the field changes between two versions of an object, nil for
+// either when it does not exist, or nil when nothing changed. Objects are normalized as
+// for comparisons, values of Secrets are redacted and changes too large to record are
+// reduced to their paths.
+func resourceChanges(kind string, before, after *unstructured.Unstructured) []fieldChange {
+	var changes []fieldChange
+	switch {
+	case before == nil && after == nil:
+		return nil
+	case before == nil:
+		changes = []fieldChange{{Path: "", Op: "add", New: normalizeForAudit(after)}}
+	case after == nil:
+		changes = []fieldChange{{Path: "", Op: "remove", Old: normalizeForAudit(before)}}
+	default:
+		changes = structuredDiff(normalizeForAudit(before), normalizeForAudit(after))
+		if len(changes) == 0 {
+			return nil
+		}
+	}
+
+	if kind == "Secret" {
+		redactSecretChanges(changes)
+	}
+	if encoded, err := json.Marshal(changes); err != nil || len(encoded) > maxAuditChangesSize {
+		for i := range changes {
+			changes[i].Old, changes[i].New = nil, nil
+		}
+	}
+	return changes
+}
+
+// normalizeForAudit is normalizeForCompare with numbers in one form, as objects read
+// through different clients hold them as int64 or float64
+func normalizeForAudit(obj *unstructured.Unstructured) map[string]interface{} {
+	clean := normalizeForCompare(obj)
+	encoded, err := json.Marshal(clean)
+	if err != nil {
+		return clean
+	}
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+	var normalized map[string]interface{}
+	if err := decoder.Decode(&normalized); err != nil {
+		return clean
+	}
+	return normalized
+}
+
+// redactSecretValues replaces the data of a Secret found at path (the whole object, its
+// data or stringData, or one key of them) with redactedValue
+func redactSecretValues(path string, value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+	switch {
+	case path == "":
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return redactedValue
+		}
+		redacted := make(map[string]interface{}, len(obj))
+		for key, field := range obj {
+			redacted[key] = field
+			if key == "data" || key == "stringData" {
+				redacted[key] = redactSecretValues("."+key, field)
+			}
+		}
+		return redacted
+	case path == ".data" || path == ".stringData":
+		data, ok := value.(map[string]interface{})
+		if !ok {
+			return redactedValue
+		}
+		redacted := make(map[string]interface{}, len(data))
+		for key := range data {
+			redacted[key] = redactedValue
+		}
+		return redacted
+	case strings.HasPrefix(path, ".data.") || strings.HasPrefix(path, ".stringData."):
+		return redactedValue
+	}
+	return value
+}
@@ -0,0 +1,83 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/sonnguyen/kubelens/internal/apitest"
+	"github.com/sonnguyen/kubelens/internal/audit"
+)
+
+// auditChanges returns the changes recorded by the latest audit entry of an event type
+func auditChanges(t *testing.T, s *apitest.Server, eventType string) []map[string]interface{} {
+	t.Helper()
+	entries, _, err := s.DB.ListAuditLogs(1, 1, map[string]interface{}{"event_type": eventType})
+	if err != nil || len(entries) == 0 {
+		t.Fatalf("no %s audit entry: %v", eventType, err)
+	}
+	var metadata struct {
+		Changes []map[string]interface{} `json:"changes"`
+	}
+	if err := json.Unmarshal([]byte(entries[0].Metadata), &metadata); err != nil {
+		t.Fatalf("metadata %q: %v", entries[0].Metadata, err)
+	}
+	return metadata.Changes
+}
+
+func TestAuditResourceChanges(t *testing.T) {
+	replicas := int32(1)
+	deployment := &appsv1.Deployment{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web"},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "web", Image: "web:1.0"}}}},
+		},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "db"},
+		Data:       map[string][]byte{"password": []byte("hunter2")},
+	}
+	s := apitest.New(t, deployment, secret)
+
+	// The custom resource update goes through the dynamic client and records no entry itself
+	updated := deployment.DeepCopy()
+	updated.Spec.Template.Spec.Containers[0].Image = "web:1.1"
+	w := s.Do(http.MethodPut, "/api/v1/clusters/"+apitest.ClusterName+"/namespaces/default/customresources/web?group=apps&version=v1&resource=deployments", updated)
+	if w.Code != http.StatusOK {
+		t.Fatalf("update: %d %s", w.Code, w.Body.String())
+	}
+	changes := auditChanges(t, s, audit.EventAuditResourceUpdated)
+	if len(changes) != 1 || changes[0]["path"] != ".spec.template.spec.containers[0].image" ||
+		changes[0]["old"] != "web:1.0" || changes[0]["new"] != "web:1.1" {
+		t.Errorf("changes = %v, want the image replaced", changes)
+	}
+
+	// Deletes record the object removed, with the Secret's data redacted
+	w = s.Do(http.MethodDelete, "/api/v1/clusters/"+apitest.ClusterName+"/namespaces/default/secrets/db", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("delete: %d %s", w.Code, w.Body.String())
+	}
+	changes = auditChanges(t, s, audit.EventAuditResourceDeleted)
+	if len(changes) != 1 || changes[0]["op"] != "remove" {
+		t.Fatalf("changes = %v, want the removal of the object", changes)
+	}
+	data, _ := changes[0]["old"].(map[string]interface{})["data"].(map[string]interface{})
+	if data["password"] != "<redacted>" {
+		t.Errorf("secret data = %v, want it redacted", data)
+	}
+
+	// Dry runs change nothing and are not recorded
+	w = s.Do(http.MethodDelete, "/api/v1/clusters/"+apitest.ClusterName+"/namespaces/default/deployments/web?dryRun=true", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("dry-run delete: %d %s", w.Code, w.Body.String())
+	}
+	if entries, _, _ := s.DB.ListAuditLogs(1, 10, map[string]interface{}{"event_type": audit.EventAuditResourceDeleted}); len(entries) != 1 {
+		t.Errorf("%d delete entries, want 1", len(entries))
+	}
+}
@@ -0,0 +1,503 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/sonnguyen/kubelens/internal/audit"
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+const (
+	// kubeBenchImage is the kube-bench image run unless the request names another one
+	kubeBenchImage = "docker.io/aquasec/kube-bench:v0.10.1"
+	// benchmarkTimeout bounds a kube-bench run, from creating the job to reading its report
+	benchmarkTimeout = 10 * time.Minute
+	// benchmarkRunLabel labels the job and pod of a run with its ID
+	benchmarkRunLabel = "kubelens.io/benchmark-run"
+	// maxBenchmarkRuns bounds the runs listed
+	maxBenchmarkRuns = 100
+)
+
+// benchmarkPollInterval is how often a running job is checked; a variable so tests can
+// shorten it
+var benchmarkPollInterval = 5 * time.Second
+
+var (
+	// benchmarkNamePattern matches kube-bench benchmark names such as cis-1.8 or eks-1.2.0
+	benchmarkNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9.-]{0,49}$`)
+	// imagePattern matches an image reference without whitespace or shell characters
+	imagePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._/:@-]{0,254}$`)
+)
+
+// kubeBenchHostPaths are the host directories kube-bench inspects, mounted read-only
+// (from the upstream job.yaml)
+var kubeBenchHostPaths = []struct{ name, host, mount string }{
+	{"var-lib-etcd", "/var/lib/etcd", "/var/lib/etcd"},
+	{"var-lib-kubelet", "/var/lib/kubelet", "/var/lib/kubelet"},
+	{"var-lib-kube-scheduler", "/var/lib/kube-scheduler", "/var/lib/kube-scheduler"},
+	{"var-lib-kube-controller-manager", "/var/lib/kube-controller-manager", "/var/lib/kube-controller-manager"},
+	{"etc-systemd", "/etc/systemd", "/etc/systemd"},
+	{"lib-systemd", "/lib/systemd", "/lib/systemd/"},
+	{"srv-kubernetes", "/srv/kubernetes", "/srv/kubernetes/"},
+	{"etc-kubernetes", "/etc/kubernetes", "/etc/kubernetes"},
+	{"usr-bin", "/usr/bin", "/usr/local/mount-from-host/bin"},
+	{"etc-cni-netd", "/etc/cni/net.d/", "/etc/cni/net.d/"},
+	{"opt-cni-bin", "/opt/cni/bin/", "/opt/cni/bin/"},
+}
+
+// BenchmarkSection is the outcome of one section of the benchmark (e.g. 1 Control Plane
+// Security Configuration) with its groups of checks
+type BenchmarkSection struct {
+	ID       string           `json:"id"`
+	Text     string           `json:"text"`
+	NodeType string           `json:"node_type"`
+	Version  string           `json:"version,omitempty"`
+	Pass     int              `json:"pass"`
+	Fail     int              `json:"fail"`
+	Warn     int              `json:"warn"`
+	Info     int              `json:"info"`
+	Groups   []BenchmarkGroup `json:"groups"`
+}
+
+// BenchmarkGroup is the outcome of a group of checks (e.g. 1.1 Control Plane Node
+// Configuration Files)
+type BenchmarkGroup struct {
+	Section string `json:"section"`
+	Desc    string `json:"desc"`
+	Pass    int    `json:"pass"`
+	Fail    int    `json:"fail"`
+	Warn    int    `json:"warn"`
+	Info    int    `json:"info"`
+}
+
+// BenchmarkResult is one check of the benchmark
+type BenchmarkResult struct {
+	ID          string `json:"id"` // e.g. 1.1.1
+	Section     string `json:"section"`
+	Description string `json:"description"`
+	Status      string `json:"status"` // PASS, FAIL, WARN or INFO
+	Scored      bool   `json:"scored"`
+	Remediation string `json:"remediation,omitempty"`
+}
+
+// kubeBenchControls is a section of the kube-bench JSON report
+type kubeBenchControls struct {
+	ID              string `json:"id"`
+	Version         string `json:"version"`
+	DetectedVersion string `json:"detected_version"`
+	Text            string `json:"text"`
+	NodeType        string `json:"node_type"`
+	Groups          []struct {
+		Section string `json:"section"`
+		Desc    string `json:"desc"`
+		Pass    int    `json:"pass"`
+		Fail    int    `json:"fail"`
+		Warn    int    `json:"warn"`
+		Info    int    `json:"info"`
+		Results []struct {
+			TestNumber  string `json:"test_number"`
+			TestDesc    string `json:"test_desc"`
+			Status      string `json:"status"`
+			Scored      bool   `json:"scored"`
+			Remediation string `json:"remediation"`
+		} `json:"results"`
+	} `json:"tests"`
+	Pass int `json:"total_pass"`
+	Fail int `json:"total_fail"`
+	Warn int `json:"total_warn"`
+	Info int `json:"total_info"`
+}
+
+// parseKubeBench reads the output of kube-bench --json: an object with Controls and Totals
+// (kube-bench 0.6 and later) or a bare array of controls (older releases)
+func parseKubeBench(output []byte) ([]BenchmarkSection, []BenchmarkResult, error) {
+	start := bytes.IndexAny(output, "{[")
+	if start < 0 {
+		return nil, nil, fmt.Errorf("kube-bench output contains no JSON report")
+	}
+	output = output[start:]
+
+	var controls []kubeBenchControls
+	if output[0] == '[' {
+		if err := json.Unmarshal(output, &controls); err != nil {
+			return nil, nil, fmt.Errorf("invalid kube-bench report: %v", err)
+		}
+	} else {
+		var report struct {
+			Controls []kubeBenchControls `json:"Controls"`
+		}
+		if err := json.NewDecoder(bytes.NewReader(output)).Decode(&report); err != nil {
+			return nil, nil, fmt.Errorf("invalid kube-bench report: %v", err)
+		}
+		controls = report.Controls
+	}
+	if len(controls) == 0 {
+		return nil, nil, fmt.Errorf("kube-bench report contains no checks")
+	}
+
+	sections := make([]BenchmarkSection, 0, len(controls))
+	results := []BenchmarkResult{}
+	for _, control := range controls {
+		version := control.Version
+		if version == "" {
+			version = control.DetectedVersion
+		}
+		section := BenchmarkSection{
+			ID:       control.ID,
+			Text:     control.Text,
+			NodeType: control.NodeType,
+			Version:  version,
+			Pass:     control.Pass,
+			Fail:     control.Fail,
+			Warn:     control.Warn,
+			Info:     control.Info,
+			Groups:   []BenchmarkGroup{},
+		}
+		for _, group := range control.Groups {
+			section.Groups = append(section.Groups, BenchmarkGroup{
+				Section: group.Section,
+				Desc:    group.Desc,
+				Pass:    group.Pass,
+				Fail:    group.Fail,
+				Warn:    group.Warn,
+				Info:    group.Info,
+			})
+			for _, result := range group.Results {
+				results = append(results, BenchmarkResult{
+					ID:          result.TestNumber,
+					Section:     group.Section,
+					Description: result.TestDesc,
+					Status:      strings.ToUpper(result.Status),
+					Scored:      result.Scored,
+					Remediation: result.Remediation,
+				})
+			}
+		}
+		sections = append(sections, section)
+	}
+	return sections, results, nil
+}
+
+// kubeBenchJob builds the job of a run. It shares the host PID namespace and reads the
+// node's configuration through read-only host mounts, like the upstream job.yaml, and
+// tolerates every taint so that control plane nodes can be benchmarked.
+func kubeBenchJob(run *db.BenchmarkRun, image string) *batchv1.Job {
+	labels := map[string]string{
+		"app.kubernetes.io/name":       "kube-bench",
+		"app.kubernetes.io/managed-by": "kubelens",
+		benchmarkRunLabel:              strconv.FormatUint(uint64(run.ID), 10),
+	}
+	args := []string{"--json"}
+	if run.Benchmark != "" {
+		args = append(args, "--benchmark", run.Benchmark)
+	}
+
+	var volumes []corev1.Volume
+	var mounts []corev1.VolumeMount
+	for _, p := range kubeBenchHostPaths {
+		volumes = append(volumes, corev1.Volume{
+			Name:         p.name,
+			VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: p.host}},
+		})
+		mounts = append(mounts, corev1.VolumeMount{Name: p.name, MountPath: p.mount, ReadOnly: true})
+	}
+
+	backoffLimit := int32(0)
+	ttl := int32(3600)
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: run.JobName, Namespace: run.Namespace, Labels: labels},
+		Spec: batchv1.JobSpec{
+			BackoffLimit:            &backoffLimit,
+			TTLSecondsAfterFinished: &ttl,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: corev1.PodSpec{
+					HostPID:       true,
+					NodeName:      run.Node,
+					RestartPolicy: corev1.RestartPolicyNever,
+					Tolerations:   []corev1.Toleration{{Operator: corev1.TolerationOpExists}},
+					Containers: []corev1.Container{{
+						Name:         "kube-bench",
+						Image:        image,
+						Command:      []string{"kube-bench"},
+						Args:         args,
+						VolumeMounts: mounts,
+					}},
+					Volumes: volumes,
+				},
+			},
+		},
+	}
+}
+
+// StartBenchmark handles POST /clusters/:name/benchmarks. It launches a kube-bench job
+// (on the given node, if any) and collects its report in the background; poll the run
+// for the outcome.
+func (h *Handler) StartBenchmark(c *gin.Context) {
+	clusterName := c.Param("name")
+
+	var req struct {
+		Node      string `json:"node"`
+		Benchmark string `json:"benchmark"` // kube-bench --benchmark, e.g. cis-1.8; detected if empty
+		Namespace string `json:"namespace"`
+		Image     string `json:"image"`
+	}
+	// The body is optional
+	_ = c.ShouldBindJSON(&req)
+	if req.Namespace == "" {
+		req.Namespace = "kube-system"
+	}
+	if req.Image == "" {
+		req.Image = kubeBenchImage
+	}
+	if req.Benchmark != "" && !benchmarkNamePattern.MatchString(req.Benchmark) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid benchmark name"})
+		return
+	}
+	if !imagePattern.MatchString(req.Image) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid image"})
+		return
+	}
+
+	client, err := h.client(c, clusterName)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Node != "" {
+		if _, err := client.CoreV1().Nodes().Get(context.Background(), req.Node, metav1.GetOptions{}); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("node %s not found", req.Node)})
+			return
+		}
+	}
+
+	run := &db.BenchmarkRun{
+		ClusterName: clusterName,
+		Node:        req.Node,
+		Benchmark:   req.Benchmark,
+		Namespace:   req.Namespace,
+		Status:      "running",
+	}
+	if userID, exists := c.Get("user_id"); exists {
+		run.StartedBy = uint(userID.(int))
+	}
+	if err := h.db.CreateBenchmarkRun(run); err != nil {
+		log.Errorf("Failed to create benchmark run: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create benchmark run"})
+		return
+	}
+	run.JobName = fmt.Sprintf("kubelens-kube-bench-%d", run.ID)
+
+	if _, err := client.BatchV1().Jobs(run.Namespace).Create(context.Background(), kubeBenchJob(run, req.Image), metav1.CreateOptions{}); err != nil {
+		h.finishBenchmark(run, err)
+		if apierrors.IsForbidden(err) || apierrors.IsInvalid(err) || apierrors.IsNotFound(err) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
+		log.Errorf("Failed to create kube-bench job: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.db.UpdateBenchmarkRun(run); err != nil {
+		log.Warnf("Failed to update benchmark run %d: %v", run.ID, err)
+	}
+
+	go h.collectBenchmark(client, run)
+
+	// Audit log
+	if userID, exists := c.Get("user_id"); exists {
+		username, _ := c.Get("username")
+		email, _ := c.Get("email")
+		audit.Log(c, audit.EventAuditResourceCreated, userID.(int), username.(string), email.(string),
+			fmt.Sprintf("Started CIS benchmark on cluster %s", clusterName),
+			map[string]interface{}{
+				"cluster_name": clusterName,
+				"run_id":       run.ID,
+				"node":         run.Node,
+				"benchmark":    run.Benchmark,
+				"job":          run.Namespace + "/" + run.JobName,
+			})
+	}
+
+	c.JSON(http.StatusAccepted, run)
+}
+
+// collectBenchmark waits for the job of a run, stores its report and removes the job
+func (h *Handler) collectBenchmark(client kubernetes.Interface, run *db.BenchmarkRun) {
+	ctx, cancel := context.WithTimeout(context.Background(), benchmarkTimeout)
+	defer cancel()
+
+	output, err := awaitBenchmarkOutput(ctx, client, run)
+	if err == nil {
+		var sections []BenchmarkSection
+		var results []BenchmarkResult
+		if sections, results, err = parseKubeBench(output); err == nil {
+			for _, section := range sections {
+				run.Pass += section.Pass
+				run.Fail += section.Fail
+				run.Warn += section.Warn
+				run.Info += section.Info
+				if run.Benchmark == "" {
+					run.Benchmark = section.Version
+				}
+			}
+			sectionsJSON, _ := json.Marshal(sections)
+			resultsJSON, _ := json.Marshal(results)
+			run.Sections = db.JSON(sectionsJSON)
+			run.Results = db.JSON(resultsJSON)
+		}
+	}
+	h.finishBenchmark(run, err)
+
+	propagation := metav1.DeletePropagationBackground
+	if err := client.BatchV1().Jobs(run.Namespace).Delete(context.Background(), run.JobName, metav1.DeleteOptions{PropagationPolicy: &propagation}); err != nil && !apierrors.IsNotFound(err) {
+		log.Warnf("Failed to delete kube-bench job %s/%s: %v", run.Namespace, run.JobName, err)
+	}
+}
+
+// finishBenchmark records the outcome of a run
+func (h *Handler) finishBenchmark(run *db.BenchmarkRun, err error) {
+	now := time.Now()
+	run.CompletedAt = &now
+	run.Status = "completed"
+	if err != nil {
+		run.Status = "failed"
+		run.Error = err.Error()
+	}
+	if err := h.db.UpdateBenchmarkRun(run); err != nil {
+		log.Errorf("Failed to update benchmark run %d: %v", run.ID, err)
+	}
+	log.Infof("CIS benchmark run %d on cluster %s %s", run.ID, run.ClusterName, run.Status)
+}
+
+// awaitBenchmarkOutput waits for the job of a run to finish and returns the logs of its pod
+func awaitBenchmarkOutput(ctx context.Context, client kubernetes.Interface, run *db.BenchmarkRun) ([]byte, error) {
+	ticker := time.NewTicker(benchmarkPollInterval)
+	defer ticker.Stop()
+	for {
+		job, err := client.BatchV1().Jobs(run.Namespace).Get(ctx, run.JobName, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get kube-bench job: %w", err)
+		}
+		if job.Status.Succeeded > 0 || job.Status.Failed > 0 {
+			break
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil, fmt.Errorf("kube-bench did not finish within %v", benchmarkTimeout)
+		}
+	}
+
+	pods, err := client.CoreV1().Pods(run.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%d", benchmarkRunLabel, run.ID),
+	})
+	if err != nil || len(pods.Items) == 0 {
+		return nil, fmt.Errorf("kube-bench pod not found")
+	}
+	pod := pods.Items[0]
+	if run.Node == "" {
+		run.Node = pod.Spec.NodeName
+	}
+	output, err := client.CoreV1().Pods(run.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{Container: "kube-bench"}).DoRaw(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read kube-bench output: %w", err)
+	}
+	if pod.Status.Phase == corev1.PodFailed && bytes.IndexAny(output, "{[") < 0 {
+		return nil, fmt.Errorf("kube-bench failed: %s", lastLines(string(output), 5))
+	}
+	return output, nil
+}
+
+func lastLines(s string, n int) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ListBenchmarkRuns handles GET /clusters/:name/benchmarks, the runs of a cluster with
+// their totals, newest first
+func (h *Handler) ListBenchmarkRuns(c *gin.Context) {
+	runs, err := h.db.ListBenchmarkRuns(c.Param("name"), maxBenchmarkRuns)
+	if err != nil {
+		log.Errorf("Failed to list benchmark runs: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list benchmark runs"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"runs": runs})
+}
+
+// GetBenchmarkRun handles GET /clusters/:name/benchmarks/:id. The status query param
+// (e.g. FAIL) filters the results.
+func (h *Handler) GetBenchmarkRun(c *gin.Context) {
+	run, ok := h.benchmarkRun(c)
+	if !ok {
+		return
+	}
+
+	if status := strings.ToUpper(c.Query("status")); status != "" && len(run.Results) > 0 {
+		var results []BenchmarkResult
+		if err := json.Unmarshal(run.Results, &results); err == nil {
+			filtered := []BenchmarkResult{}
+			for _, result := range results {
+				if result.Status == status {
+					filtered = append(filtered, result)
+				}
+			}
+			data, _ := json.Marshal(filtered)
+			run.Results = db.JSON(data)
+		}
+	}
+	c.JSON(http.StatusOK, run)
+}
+
+// DeleteBenchmarkRun handles DELETE /clusters/:name/benchmarks/:id
+func (h *Handler) DeleteBenchmarkRun(c *gin.Context) {
+	run, ok := h.benchmarkRun(c)
+	if !ok {
+		return
+	}
+	if run.Status == "running" {
+		c.JSON(http.StatusConflict, gin.H{"error": "benchmark run is still running"})
+		return
+	}
+	if err := h.db.DeleteBenchmarkRun(run.ID); err != nil {
+		log.Errorf("Failed to delete benchmark run: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete benchmark run"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "benchmark run deleted"})
+}
+
+// benchmarkRun loads the run named in the route, writing the error response when it cannot
+func (h *Handler) benchmarkRun(c *gin.Context) (*db.BenchmarkRun, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid benchmark run ID"})
+		return nil, false
+	}
+	run, err := h.db.GetBenchmarkRun(c.Param("name"), uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	return run, true
+}
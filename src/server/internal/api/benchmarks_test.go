@@ -0,0 +1,58 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+func TestParseKubeBench(t *testing.T) {
+	// kube-bench logs a line before the report when it cannot detect the version
+	output := []byte(`Warning: Kubernetes version was not auto-detected
+{"Controls": [{
+  "id": "4", "version": "cis-1.8", "text": "Worker Node Security Configuration", "node_type": "node",
+  "tests": [{
+    "section": "4.1", "desc": "Worker Node Configuration Files", "pass": 1, "fail": 1, "warn": 0, "info": 0,
+    "results": [
+      {"test_number": "4.1.1", "test_desc": "Ensure that the kubelet service file permissions are set to 600", "status": "PASS", "scored": true},
+      {"test_number": "4.1.2", "test_desc": "Ensure that the kubelet service file ownership is set to root:root", "status": "FAIL", "scored": true, "remediation": "chown root:root /etc/systemd/system/kubelet.service.d/10-kubeadm.conf"}
+    ]}],
+  "total_pass": 1, "total_fail": 1, "total_warn": 0, "total_info": 0
+}], "Totals": {"total_pass": 1, "total_fail": 1, "total_warn": 0, "total_info": 0}}`)
+
+	sections, results, err := parseKubeBench(output)
+	if err != nil {
+		t.Fatalf("parseKubeBench() error = %v", err)
+	}
+	if len(sections) != 1 || sections[0].Version != "cis-1.8" || sections[0].Fail != 1 || len(sections[0].Groups) != 1 {
+		t.Errorf("sections = %+v, want section 4 with one failure in group 4.1", sections)
+	}
+	if len(results) != 2 || results[1].ID != "4.1.2" || results[1].Status != "FAIL" || results[1].Section != "4.1" {
+		t.Errorf("results = %+v, want 4.1.2 failed", results)
+	}
+
+	if _, _, err := parseKubeBench([]byte("error: no config found")); err == nil {
+		t.Error("parseKubeBench() accepted output without a report")
+	}
+}
+
+func TestKubeBenchJob(t *testing.T) {
+	run := &db.BenchmarkRun{ID: 7, Node: "cp-1", Benchmark: "cis-1.8", Namespace: "kube-system", JobName: "kubelens-kube-bench-7"}
+	job := kubeBenchJob(run, kubeBenchImage)
+
+	spec := job.Spec.Template.Spec
+	if !spec.HostPID || spec.NodeName != "cp-1" || len(spec.Tolerations) != 1 {
+		t.Errorf("pod spec = %+v, want host PID on node cp-1 tolerating all taints", spec)
+	}
+	for _, mount := range spec.Containers[0].VolumeMounts {
+		if !mount.ReadOnly {
+			t.Errorf("host path %s is mounted writable", mount.MountPath)
+		}
+	}
+	if args := spec.Containers[0].Args; len(args) != 3 || args[2] != "cis-1.8" {
+		t.Errorf("args = %v, want --json --benchmark cis-1.8", args)
+	}
+	if job.Labels[benchmarkRunLabel] != "7" {
+		t.Errorf("labels = %v, want the run ID", job.Labels)
+	}
+}
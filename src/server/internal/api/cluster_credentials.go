@@ -0,0 +1,74 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/sonnguyen/kubelens/internal/audit"
+	"github.com/sonnguyen/kubelens/internal/cluster"
+)
+
+// RotateClusterCredentials replaces the credentials of a cluster with a fresh token of the
+// kubelens ServiceAccount. Body (optional): {"expiration_seconds": 604800}
+func (h *Handler) RotateClusterCredentials(c *gin.Context) {
+	name := c.Param("name")
+
+	var req struct {
+		ExpirationSeconds int64 `json:"expiration_seconds"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	lifetime := time.Duration(req.ExpirationSeconds) * time.Second
+	if req.ExpirationSeconds != 0 && lifetime < cluster.MinCredentialLifetime {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("expiration_seconds must be at least %d", int64(cluster.MinCredentialLifetime/time.Second))})
+		return
+	}
+
+	if _, err := h.db.GetCluster(name); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Cluster not found"})
+		return
+	}
+	if _, err := h.clusterManager.GetClient(name); err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Cluster is not connected"})
+		return
+	}
+
+	rotation, err := h.clusterManager.RotateCredentials(name, lifetime)
+	switch {
+	case errors.Is(err, cluster.ErrRotationUnsupported):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, cluster.ErrNoServiceAccount):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		log.Errorf("Failed to rotate credentials of cluster %s: %v", name, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Audit log
+	if userID, exists := c.Get("user_id"); exists {
+		username, _ := c.Get("username")
+		email, _ := c.Get("email")
+
+		audit.Log(c, audit.EventAuditClusterCredentialsRotated, userID.(int), username.(string), email.(string),
+			fmt.Sprintf("Rotated credentials of cluster %s", name),
+			map[string]interface{}{
+				"cluster_name":       name,
+				"previous_auth_type": rotation.PreviousAuthType,
+				"service_account":    rotation.ServiceAccount,
+				"expires_at":         rotation.ExpiresAt,
+			})
+	}
+
+	c.JSON(http.StatusOK, rotation)
+}
@@ -0,0 +1,357 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/sonnguyen/kubelens/internal/audit"
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+// ClusterGroupInfo is a cluster group with its current members
+type ClusterGroupInfo struct {
+	*db.ClusterGroup
+	Members []string `json:"members"`
+}
+
+// clusterGroupRequest is the body of creating or updating a cluster group
+type clusterGroupRequest struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Selector    string   `json:"selector"`
+	Clusters    []string `json:"clusters"`
+}
+
+// validateClusterLabels checks labels against the Kubernetes label syntax
+func validateClusterLabels(clusterLabels map[string]string) error {
+	for key, value := range clusterLabels {
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return fmt.Errorf("invalid label key %q: %s", key, strings.Join(errs, "; "))
+		}
+		if errs := validation.IsValidLabelValue(value); len(errs) > 0 {
+			return fmt.Errorf("invalid value of label %q: %s", key, strings.Join(errs, "; "))
+		}
+	}
+	return nil
+}
+
+// clusterFilter returns the predicate of the cluster_selector (a label selector) and
+// cluster_group query parameters of a list across clusters; nil when neither is set
+func (h *Handler) clusterFilter(c *gin.Context) (func(name string) bool, error) {
+	return h.clusterFilterOf(c.Query("cluster_selector"), c.Query("cluster_group"))
+}
+
+// clusterFilterOf returns the predicate of a cluster selector and cluster group; nil when
+// neither is set
+func (h *Handler) clusterFilterOf(selectorQuery, groupName string) (func(name string) bool, error) {
+	if selectorQuery == "" && groupName == "" {
+		return nil, nil
+	}
+
+	selector := labels.Everything()
+	if selectorQuery != "" {
+		var err error
+		if selector, err = labels.Parse(selectorQuery); err != nil {
+			return nil, fmt.Errorf("invalid cluster_selector: %w", err)
+		}
+	}
+	var members map[string]bool
+	if groupName != "" {
+		group, err := h.db.GetClusterGroup(groupName)
+		if err != nil {
+			return nil, fmt.Errorf("unknown cluster_group %q", groupName)
+		}
+		names, err := h.db.ClusterGroupMembers(group)
+		if err != nil {
+			return nil, err
+		}
+		members = map[string]bool{}
+		for _, name := range names {
+			members[name] = true
+		}
+	}
+
+	dbClusters, err := h.db.ListClusters()
+	if err != nil {
+		return nil, err
+	}
+	selected := map[string]bool{}
+	for _, dbCluster := range dbClusters {
+		if (members == nil || members[dbCluster.Name]) && selector.Matches(labels.Set(dbCluster.LabelSet())) {
+			selected[dbCluster.Name] = true
+		}
+	}
+	return func(name string) bool { return selected[name] }, nil
+}
+
+// SetClusterLabels replaces the labels of a cluster. Body: {"labels": {"env": "prod"}}
+func (h *Handler) SetClusterLabels(c *gin.Context) {
+	name := c.Param("name")
+
+	var req struct {
+		Labels map[string]string `json:"labels"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Labels == nil {
+		req.Labels = map[string]string{}
+	}
+	if err := validateClusterLabels(req.Labels); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if _, err := h.db.GetCluster(name); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Cluster not found"})
+		return
+	}
+	if err := h.db.UpdateClusterLabels(name, req.Labels); err != nil {
+		log.Errorf("Failed to update labels of cluster %s: %v", name, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Audit log
+	if userID, exists := c.Get("user_id"); exists {
+		username, _ := c.Get("username")
+		email, _ := c.Get("email")
+
+		audit.Log(c, audit.EventClusterUpdated, userID.(int), username.(string), email.(string),
+			fmt.Sprintf("Cluster %s: labels updated", name),
+			map[string]interface{}{
+				"cluster_name": name,
+				"labels":       req.Labels,
+			})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"name": name, "labels": req.Labels})
+}
+
+// ListClusterGroups lists the cluster groups with their members
+func (h *Handler) ListClusterGroups(c *gin.Context) {
+	groups, err := h.db.ListClusterGroups()
+	if err != nil {
+		log.Errorf("Failed to list cluster groups: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	result := make([]ClusterGroupInfo, 0, len(groups))
+	for _, group := range groups {
+		members, err := h.db.ClusterGroupMembers(group)
+		if err != nil {
+			log.Warnf("Failed to resolve members of cluster group %s: %v", group.Name, err)
+			members = []string{}
+		}
+		result = append(result, ClusterGroupInfo{ClusterGroup: group, Members: members})
+	}
+	c.JSON(http.StatusOK, gin.H{"groups": result})
+}
+
+// GetClusterGroup returns a cluster group with its members
+func (h *Handler) GetClusterGroup(c *gin.Context) {
+	group, err := h.db.GetClusterGroup(c.Param("group"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Cluster group not found"})
+		return
+	}
+	members, err := h.db.ClusterGroupMembers(group)
+	if err != nil {
+		log.Errorf("Failed to resolve members of cluster group %s: %v", group.Name, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, ClusterGroupInfo{ClusterGroup: group, Members: members})
+}
+
+// CreateClusterGroup creates a cluster group
+func (h *Handler) CreateClusterGroup(c *gin.Context) {
+	var req clusterGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	group := &db.ClusterGroup{}
+	if err := applyClusterGroupRequest(group, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if _, err := h.db.GetClusterGroup(group.Name); err == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("cluster group %s already exists", group.Name)})
+		return
+	}
+	if err := h.db.CreateClusterGroup(group); err != nil {
+		log.Errorf("Failed to create cluster group: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	h.auditClusterGroup(c, group, "created")
+
+	members, _ := h.db.ClusterGroupMembers(group)
+	c.JSON(http.StatusCreated, ClusterGroupInfo{ClusterGroup: group, Members: members})
+}
+
+// UpdateClusterGroup replaces the description, selector and cluster list of a group
+func (h *Handler) UpdateClusterGroup(c *gin.Context) {
+	group, err := h.db.GetClusterGroup(c.Param("group"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Cluster group not found"})
+		return
+	}
+	var req clusterGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	// Groups are referenced by name in permissions, so they cannot be renamed
+	req.Name = group.Name
+	if err := applyClusterGroupRequest(group, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.db.UpdateClusterGroup(group); err != nil {
+		log.Errorf("Failed to update cluster group %s: %v", group.Name, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	h.auditClusterGroup(c, group, "updated")
+
+	members, _ := h.db.ClusterGroupMembers(group)
+	c.JSON(http.StatusOK, ClusterGroupInfo{ClusterGroup: group, Members: members})
+}
+
+// DeleteClusterGroup deletes a cluster group. Permissions referring to it match no
+// cluster afterwards.
+func (h *Handler) DeleteClusterGroup(c *gin.Context) {
+	group, err := h.db.GetClusterGroup(c.Param("group"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Cluster group not found"})
+		return
+	}
+	if err := h.db.DeleteClusterGroup(group.Name); err != nil {
+		log.Errorf("Failed to delete cluster group %s: %v", group.Name, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	h.auditClusterGroup(c, group, "deleted")
+
+	c.JSON(http.StatusOK, gin.H{"message": "Cluster group deleted successfully"})
+}
+
+// SetClusterGroupEnabled enables or disables every cluster of a group. Body: {"enabled": true}
+func (h *Handler) SetClusterGroupEnabled(c *gin.Context) {
+	var req struct {
+		Enabled bool `json:"enabled"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	group, err := h.db.GetClusterGroup(c.Param("group"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Cluster group not found"})
+		return
+	}
+	members, err := h.db.ClusterGroupMembers(group)
+	if err != nil {
+		log.Errorf("Failed to resolve members of cluster group %s: %v", group.Name, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Each cluster is handled on its own; one failing to connect does not stop the rest
+	results := make([]gin.H, 0, len(members))
+	for _, name := range members {
+		result := gin.H{"cluster": name, "enabled": req.Enabled}
+		if err := h.setClusterEnabled(name, req.Enabled); err != nil {
+			result["error"] = err.Error()
+		}
+		results = append(results, result)
+	}
+
+	// Audit log
+	if userID, exists := c.Get("user_id"); exists {
+		username, _ := c.Get("username")
+		email, _ := c.Get("email")
+
+		action := "enabled"
+		if !req.Enabled {
+			action = "disabled"
+		}
+		audit.Log(c, audit.EventClusterUpdated, userID.(int), username.(string), email.(string),
+			fmt.Sprintf("Cluster group %s: %d clusters %s", group.Name, len(members), action),
+			map[string]interface{}{
+				"cluster_group": group.Name,
+				"clusters":      members,
+				"enabled":       req.Enabled,
+			})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"group": group.Name, "results": results})
+}
+
+// setClusterEnabled enables a cluster and connects it from its stored credentials, or
+// disables and disconnects it
+func (h *Handler) setClusterEnabled(name string, enabled bool) error {
+	dbCluster, err := h.db.GetCluster(name)
+	if err != nil {
+		return err
+	}
+	if err := h.db.UpdateClusterEnabled(dbCluster.ID, enabled); err != nil {
+		return err
+	}
+	if !enabled {
+		h.clusterManager.RemoveCluster(name)
+		return h.db.UpdateClusterStatus(name, "disconnected")
+	}
+	return h.clusterManager.LoadCluster(dbCluster)
+}
+
+// applyClusterGroupRequest validates a group request and copies it into group
+func applyClusterGroupRequest(group *db.ClusterGroup, req *clusterGroupRequest) error {
+	if errs := validation.IsDNS1123Subdomain(req.Name); len(errs) > 0 {
+		return fmt.Errorf("invalid name %q: %s", req.Name, strings.Join(errs, "; "))
+	}
+	if req.Selector == "" && len(req.Clusters) == 0 {
+		return fmt.Errorf("a selector or a list of clusters is required")
+	}
+	if req.Selector != "" {
+		if _, err := labels.Parse(req.Selector); err != nil {
+			return fmt.Errorf("invalid selector: %w", err)
+		}
+	}
+	if req.Clusters == nil {
+		req.Clusters = []string{}
+	}
+	clusters, _ := json.Marshal(req.Clusters)
+
+	group.Name = req.Name
+	group.Description = req.Description
+	group.Selector = req.Selector
+	group.Clusters = db.JSON(clusters)
+	return nil
+}
+
+func (h *Handler) auditClusterGroup(c *gin.Context, group *db.ClusterGroup, action string) {
+	if userID, exists := c.Get("user_id"); exists {
+		username, _ := c.Get("username")
+		email, _ := c.Get("email")
+
+		audit.Log(c, audit.EventClusterUpdated, userID.(int), username.(string), email.(string),
+			fmt.Sprintf("Cluster group %s %s", group.Name, action),
+			map[string]interface{}{
+				"cluster_group": group.Name,
+				"selector":      group.Selector,
+				"clusters":      group.ListedClusters(),
+			})
+	}
+}
@@ -0,0 +1,96 @@
+package api_test
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/sonnguyen/kubelens/internal/apitest"
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+func TestClusterLabelsAndGroups(t *testing.T) {
+	s := apitest.New(t)
+	for _, name := range []string{"prod-eu", "prod-us", "staging"} {
+		if err := s.DB.CreateCluster(&db.Cluster{Name: name, AuthType: "token", AuthConfig: db.JSON("{}"), Enabled: true}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for name, labels := range map[string]map[string]string{
+		"prod-eu": {"env": "prod", "region": "eu"},
+		"prod-us": {"env": "prod", "region": "us"},
+		"staging": {"env": "staging", "region": "eu"},
+	} {
+		if w := s.Do(http.MethodPut, "/api/v1/clusters/"+name+"/labels", map[string]interface{}{"labels": labels}); w.Code != http.StatusOK {
+			t.Fatalf("set labels of %s: %d %s", name, w.Code, w.Body.String())
+		}
+	}
+	if w := s.Do(http.MethodPut, "/api/v1/clusters/staging/labels", map[string]interface{}{"labels": map[string]string{"bad key": "x"}}); w.Code != http.StatusBadRequest {
+		t.Errorf("invalid label key: status = %d, want 400", w.Code)
+	}
+
+	listed := func(path string) []string {
+		t.Helper()
+		w := s.Get(path)
+		if w.Code != http.StatusOK {
+			t.Fatalf("GET %s: %d %s", path, w.Code, w.Body.String())
+		}
+		var resp struct {
+			Clusters []struct {
+				Name string `json:"name"`
+			} `json:"clusters"`
+		}
+		apitest.DecodeJSON(t, w, &resp)
+		names := []string{}
+		for _, c := range resp.Clusters {
+			names = append(names, c.Name)
+		}
+		return names
+	}
+	if got := listed("/api/v1/clusters?cluster_selector=env%3Dprod"); !reflect.DeepEqual(got, []string{"prod-eu", "prod-us"}) {
+		t.Errorf("env=prod clusters = %v", got)
+	}
+	if got := listed("/api/v1/clusters?cluster_selector=region+in+(eu),env!%3Dprod"); !reflect.DeepEqual(got, []string{"staging"}) {
+		t.Errorf("region in (eu),env!=prod clusters = %v", got)
+	}
+	if w := s.Get("/api/v1/clusters?cluster_selector=env%3D%3D%3D"); w.Code != http.StatusBadRequest {
+		t.Errorf("invalid selector: status = %d, want 400", w.Code)
+	}
+
+	// A group combines a selector with listed clusters
+	w := s.Do(http.MethodPost, "/api/v1/cluster-groups", map[string]interface{}{
+		"name": "eu", "selector": "region=eu", "clusters": []string{"prod-us"},
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create group: %d %s", w.Code, w.Body.String())
+	}
+	var group struct {
+		Members []string `json:"members"`
+	}
+	apitest.DecodeJSON(t, w, &group)
+	if !reflect.DeepEqual(group.Members, []string{"prod-eu", "prod-us", "staging"}) {
+		t.Errorf("members = %v", group.Members)
+	}
+	if got := listed("/api/v1/clusters?cluster_group=eu&cluster_selector=env%3Dprod"); !reflect.DeepEqual(got, []string{"prod-eu", "prod-us"}) {
+		t.Errorf("prod clusters of group eu = %v", got)
+	}
+
+	// Permissions referring to the group cover its members
+	expanded, err := s.DB.ExpandClusterGroups([]db.Permission{{Resource: "pods", Actions: []string{"read"}, Clusters: []string{"group:eu", "group:none"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"prod-eu", "prod-us", "staging", "group:none"}; !reflect.DeepEqual(expanded[0].Clusters, want) {
+		t.Errorf("expanded clusters = %v, want %v", expanded[0].Clusters, want)
+	}
+
+	// Bulk disable
+	if w := s.Do(http.MethodPatch, "/api/v1/cluster-groups/eu/enabled", map[string]interface{}{"enabled": false}); w.Code != http.StatusOK {
+		t.Fatalf("disable group: %d %s", w.Code, w.Body.String())
+	}
+	for _, name := range group.Members {
+		if c, _ := s.DB.GetCluster(name); c.Enabled || c.Status != "disconnected" {
+			t.Errorf("%s: enabled %v, status %q after disabling its group", name, c.Enabled, c.Status)
+		}
+	}
+}
@@ -0,0 +1,254 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+
+	"github.com/sonnguyen/kubelens/internal/audit"
+	"github.com/sonnguyen/kubelens/internal/cluster"
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+const (
+	// maxImportContexts bounds the clusters created by one bulk import
+	maxImportContexts = 100
+	// importConnectParallelism bounds the clusters connected at once during a bulk import
+	importConnectParallelism = 8
+)
+
+// KubeconfigContext is a context of an uploaded kubeconfig
+type KubeconfigContext struct {
+	Name       string `json:"name"`
+	Cluster    string `json:"cluster"`
+	User       string `json:"user"`
+	Namespace  string `json:"namespace,omitempty"`
+	Server     string `json:"server"`
+	AuthMethod string `json:"auth_method"` // token, client_certificate, exec, auth_provider, basic or none
+	Current    bool   `json:"current"`
+	Exists     bool   `json:"exists"` // a kubelens cluster is named after the context
+}
+
+// ImportedCluster is the outcome of importing one context
+type ImportedCluster struct {
+	Name    string `json:"name"`
+	Context string `json:"context"`
+	Server  string `json:"server"`
+	Status  string `json:"status"` // connected or error
+	Error   string `json:"error,omitempty"`
+}
+
+// ListKubeconfigContexts parses an uploaded kubeconfig and returns its contexts to pick
+// from for a bulk import
+func (h *Handler) ListKubeconfigContexts(c *gin.Context) {
+	var req struct {
+		Kubeconfig string `json:"kubeconfig" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	kubeconfig, err := clientcmd.Load([]byte(req.Kubeconfig))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid kubeconfig: %v", err)})
+		return
+	}
+
+	contexts := make([]KubeconfigContext, 0, len(kubeconfig.Contexts))
+	for name, kubeContext := range kubeconfig.Contexts {
+		item := KubeconfigContext{
+			Name:      name,
+			Cluster:   kubeContext.Cluster,
+			User:      kubeContext.AuthInfo,
+			Namespace: kubeContext.Namespace,
+			Current:   name == kubeconfig.CurrentContext,
+		}
+		if kubeCluster, ok := kubeconfig.Clusters[kubeContext.Cluster]; ok {
+			item.Server = kubeCluster.Server
+		}
+		item.AuthMethod = authMethod(kubeconfig.AuthInfos[kubeContext.AuthInfo])
+		exists, err := h.db.ClusterExists(name)
+		if err != nil {
+			log.Errorf("Failed to check cluster %s: %v", name, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		item.Exists = exists
+		contexts = append(contexts, item)
+	}
+	sort.Slice(contexts, func(i, j int) bool { return contexts[i].Name < contexts[j].Name })
+
+	c.JSON(http.StatusOK, gin.H{
+		"contexts":        contexts,
+		"current_context": kubeconfig.CurrentContext,
+	})
+}
+
+// authMethod names how a kubeconfig user authenticates
+func authMethod(user *clientcmdapi.AuthInfo) string {
+	switch {
+	case user == nil:
+		return "none"
+	case user.Exec != nil:
+		return "exec"
+	case user.AuthProvider != nil:
+		return "auth_provider"
+	case user.Token != "" || user.TokenFile != "":
+		return "token"
+	case len(user.ClientCertificateData) > 0 || user.ClientCertificate != "":
+		return "client_certificate"
+	case user.Username != "":
+		return "basic"
+	default:
+		return "none"
+	}
+}
+
+// ImportKubeconfigContexts creates one kubeconfig cluster per selected context of an
+// uploaded kubeconfig. Each cluster stores a kubeconfig holding only its own context.
+// Nothing is created unless every selection is valid, and the clusters are saved in one
+// transaction; a cluster that cannot be reached is saved with the error status, as
+// AddCluster does.
+func (h *Handler) ImportKubeconfigContexts(c *gin.Context) {
+	var req struct {
+		Kubeconfig string `json:"kubeconfig" binding:"required"`
+		Contexts   []struct {
+			Context string `json:"context" binding:"required"`
+			Name    string `json:"name"` // defaults to the context name
+		} `json:"contexts" binding:"required,min=1,dive"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(req.Contexts) > maxImportContexts {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("at most %d contexts can be imported at once", maxImportContexts)})
+		return
+	}
+
+	kubeconfig, err := clientcmd.Load([]byte(req.Kubeconfig))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid kubeconfig: %v", err)})
+		return
+	}
+
+	// Validate every selection before connecting anything
+	clusters := make([]*db.Cluster, 0, len(req.Contexts))
+	results := make([]ImportedCluster, 0, len(req.Contexts))
+	kubeconfigs := make([]string, 0, len(req.Contexts))
+	names := map[string]bool{}
+	for _, selection := range req.Contexts {
+		name := selection.Name
+		if name == "" {
+			name = selection.Context
+		}
+		if _, ok := kubeconfig.Contexts[selection.Context]; !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("context %s is not in the kubeconfig", selection.Context)})
+			return
+		}
+		if names[name] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("cluster name %s is used more than once", name)})
+			return
+		}
+		names[name] = true
+		exists, err := h.db.ClusterExists(name)
+		if err != nil {
+			log.Errorf("Failed to check cluster %s: %v", name, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if exists {
+			c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("cluster %s already exists", name)})
+			return
+		}
+
+		// File references are not inlined: the kubeconfig was uploaded, so its paths
+		// would be read from the kubelens server
+		content, server, err := cluster.ContextKubeconfig(kubeconfig, selection.Context, false)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid context %s: %v", selection.Context, err)})
+			return
+		}
+		authConfig, _ := json.Marshal(map[string]string{"kubeconfig": content, "context": selection.Context})
+		dbCluster := &db.Cluster{
+			Name:           name,
+			AuthType:       string(db.AuthTypeKubeconfig),
+			AuthConfig:     db.JSON(authConfig),
+			Server:         server,
+			Enabled:        true,
+			OrganizationID: organizationID(c),
+		}
+		if err := db.ValidateCluster(dbCluster); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		clusters = append(clusters, dbCluster)
+		kubeconfigs = append(kubeconfigs, content)
+		results = append(results, ImportedCluster{Name: name, Context: selection.Context, Server: server})
+	}
+
+	// Connect the clusters concurrently
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, importConnectParallelism)
+	for i := range clusters {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			err := h.clusterManager.AddClusterFromKubeconfigContent(clusters[i].Name, kubeconfigs[i], results[i].Context)
+			if err != nil {
+				log.Errorf("Failed to add cluster %s: %v", clusters[i].Name, err)
+				clusters[i].Status = "error"
+				results[i].Error = err.Error()
+			} else {
+				clusters[i].Status = "connected"
+			}
+			results[i].Status = clusters[i].Status
+		}(i)
+	}
+	wg.Wait()
+
+	if err := h.db.CreateClusters(clusters); err != nil {
+		log.Errorf("Failed to save imported clusters: %v", err)
+		for _, dbCluster := range clusters {
+			h.clusterManager.RemoveCluster(dbCluster.Name)
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save clusters"})
+		return
+	}
+
+	for i, dbCluster := range clusters {
+		if dbCluster.Status == "connected" {
+			if err := h.setupKubelensServiceAccount(dbCluster.Name); err != nil {
+				log.Warnf("Failed to setup kubelens ServiceAccount for cluster %s: %v", dbCluster.Name, err)
+			}
+		}
+
+		if userID, exists := c.Get("user_id"); exists {
+			username, _ := c.Get("username")
+			email, _ := c.Get("email")
+
+			audit.Log(c, audit.EventClusterAdded, userID.(int), username.(string), email.(string),
+				fmt.Sprintf("Added cluster: %s", dbCluster.Name),
+				map[string]interface{}{
+					"cluster_name": dbCluster.Name,
+					"auth_type":    dbCluster.AuthType,
+					"server":       dbCluster.Server,
+					"context":      results[i].Context,
+					"bulk_import":  true,
+				})
+		}
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"clusters": results})
+}
@@ -0,0 +1,112 @@
+package api_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sonnguyen/kubelens/internal/api"
+	"github.com/sonnguyen/kubelens/internal/apitest"
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+const importKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: east
+  cluster: {server: %s}
+- name: west
+  cluster: {server: "http://127.0.0.1:1"}
+users:
+- name: admin
+  user: {token: secret}
+- name: sso
+  user:
+    exec: {apiVersion: client.authentication.k8s.io/v1beta1, command: kubelogin}
+contexts:
+- name: east-admin
+  context: {cluster: east, user: admin, namespace: shop}
+- name: west-sso
+  context: {cluster: west, user: sso}
+- name: taken
+  context: {cluster: east, user: admin}
+current-context: east-admin
+`
+
+func TestImportKubeconfigContexts(t *testing.T) {
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/version" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"major":"1","minor":"30","gitVersion":"v1.30.2"}`))
+	}))
+	defer apiServer.Close()
+	kubeconfig := fmt.Sprintf(importKubeconfig, apiServer.URL)
+
+	s := apitest.New(t)
+	s.Clusters.SetExecCommands([]string{"kubelogin"})
+	if err := s.DB.CreateCluster(&db.Cluster{Name: "taken", AuthType: "token", AuthConfig: db.JSON("{}")}); err != nil {
+		t.Fatal(err)
+	}
+
+	w := s.Do(http.MethodPost, "/api/v1/clusters/kubeconfig-contexts", map[string]interface{}{"kubeconfig": kubeconfig})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body.String())
+	}
+	var listed struct {
+		Contexts []api.KubeconfigContext `json:"contexts"`
+	}
+	apitest.DecodeJSON(t, w, &listed)
+	if len(listed.Contexts) != 3 {
+		t.Fatalf("contexts = %+v, want 3", listed.Contexts)
+	}
+	if east := listed.Contexts[0]; east.Name != "east-admin" || east.Server != apiServer.URL || east.AuthMethod != "token" || !east.Current || east.Namespace != "shop" {
+		t.Errorf("east-admin = %+v", east)
+	}
+	if west := listed.Contexts[2]; west.AuthMethod != "exec" || west.Exists {
+		t.Errorf("west-sso = %+v", west)
+	}
+	if taken := listed.Contexts[1]; !taken.Exists {
+		t.Errorf("taken = %+v, want exists", taken)
+	}
+
+	// A name clash aborts the whole import
+	w = s.Do(http.MethodPost, "/api/v1/clusters/bulk-import", map[string]interface{}{
+		"kubeconfig": kubeconfig,
+		"contexts":   []map[string]string{{"context": "east-admin"}, {"context": "taken"}},
+	})
+	if w.Code != http.StatusConflict {
+		t.Fatalf("status = %d, want 409: %s", w.Code, w.Body.String())
+	}
+	if exists, _ := s.DB.ClusterExists("east-admin"); exists {
+		t.Error("east-admin was created by a failed import")
+	}
+
+	w = s.Do(http.MethodPost, "/api/v1/clusters/bulk-import", map[string]interface{}{
+		"kubeconfig": kubeconfig,
+		"contexts":   []map[string]string{{"context": "east-admin", "name": "east"}, {"context": "west-sso"}},
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d: %s", w.Code, w.Body.String())
+	}
+	var imported struct {
+		Clusters []api.ImportedCluster `json:"clusters"`
+	}
+	apitest.DecodeJSON(t, w, &imported)
+	if len(imported.Clusters) != 2 || imported.Clusters[0].Status != "connected" || imported.Clusters[1].Status != "error" {
+		t.Fatalf("clusters = %+v, want east connected and west-sso failed", imported.Clusters)
+	}
+	east, err := s.DB.GetCluster("east")
+	if err != nil || east.AuthType != "kubeconfig" || east.Server != apiServer.URL {
+		t.Fatalf("east = %+v (%v)", east, err)
+	}
+	if _, err := s.Clusters.GetClient("east"); err != nil {
+		t.Errorf("east not connected: %v", err)
+	}
+	if west, err := s.DB.GetCluster("west-sso"); err != nil || west.Status != "error" {
+		t.Errorf("west-sso = %+v (%v), want it saved with the error status", west, err)
+	}
+}
@@ -0,0 +1,167 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/sonnguyen/kubelens/internal/audit"
+	"github.com/sonnguyen/kubelens/internal/cluster"
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+// Objects created in a cluster by setupKubelensServiceAccount
+const (
+	kubelensServiceAccountNamespace = cluster.ServiceAccountNamespace
+	kubelensServiceAccountName      = cluster.ServiceAccountName
+	kubelensClusterRoleBindingName  = "kubelens-cluster-admin"
+	kubelensManagedByLabel          = "app.kubernetes.io/managed-by"
+)
+
+// errClusterNotConnected is returned when RBAC cleanup is requested for a disconnected cluster
+var errClusterNotConnected = errors.New("cannot clean up kubelens RBAC, cluster is not connected")
+
+// OffboardingReport is the final record produced when a cluster is removed
+type OffboardingReport struct {
+	ClusterName        string                    `json:"cluster_name"`
+	RBACCleanup        *RBACCleanupResult        `json:"rbac_cleanup,omitempty"`
+	CredentialsRevoked bool                      `json:"credentials_revoked"`
+	DeletedRecords     map[string]int64          `json:"deleted_records"`
+	Activity           *db.ClusterActivityReport `json:"activity"`
+	CompletedAt        time.Time                 `json:"completed_at"`
+}
+
+// RBACCleanupResult lists the kubelens-created objects removed from the cluster
+type RBACCleanupResult struct {
+	Deleted []string `json:"deleted"`
+	Skipped []string `json:"skipped"`
+	Errors  []string `json:"errors"`
+}
+
+// GetOffboardingReport previews the activity report that removing a cluster would produce
+func (h *Handler) GetOffboardingReport(c *gin.Context) {
+	name := c.Param("name")
+
+	if _, err := h.db.GetCluster(name); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	activity, err := h.db.GetClusterActivityReport(name)
+	if err != nil {
+		log.Errorf("Failed to build activity report for cluster %s: %v", name, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, activity)
+}
+
+// offboardCluster removes a cluster and everything kubelens holds for it. The activity
+// report is built first so it still covers data that is about to be deleted.
+func (h *Handler) offboardCluster(name string, cleanupRBAC bool) (*OffboardingReport, error) {
+	activity, err := h.db.GetClusterActivityReport(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build activity report: %w", err)
+	}
+	report := &OffboardingReport{ClusterName: name, Activity: activity}
+
+	// Kubelens RBAC can only be removed while the cluster is still connected
+	if cleanupRBAC {
+		client, err := h.clusterManager.GetClient(name)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", errClusterNotConnected, err)
+		}
+		report.RBACCleanup = removeKubelensServiceAccount(context.Background(), client)
+	}
+
+	// Drop cached clients and discovery data
+	if err := h.clusterManager.RemoveCluster(name); err != nil {
+		return nil, fmt.Errorf("failed to remove cluster from manager: %w", err)
+	}
+	h.mappers.forget(name)
+	h.clusterManager.SetConnectionOptions(name, nil)
+
+	// Delete stored credentials together with metadata, crash reports, upgrade plans and usage
+	deleted, err := h.db.DeleteClusterData(name)
+	if err != nil {
+		return nil, err
+	}
+	report.DeletedRecords = deleted
+	report.CredentialsRevoked = deleted["clusters"] > 0
+	report.CompletedAt = time.Now().UTC()
+
+	return report, nil
+}
+
+// removeKubelensServiceAccount deletes the ClusterRoleBinding and ServiceAccount created by
+// setupKubelensServiceAccount. Objects not labelled as managed by kubelens are left alone.
+// Deleting the ServiceAccount also invalidates any token issued for it.
+func removeKubelensServiceAccount(ctx context.Context, client kubernetes.Interface) *RBACCleanupResult {
+	result := &RBACCleanupResult{Deleted: []string{}, Skipped: []string{}, Errors: []string{}}
+
+	crbName := "clusterrolebinding/" + kubelensClusterRoleBindingName
+	crb, err := client.RbacV1().ClusterRoleBindings().Get(ctx, kubelensClusterRoleBindingName, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		result.Skipped = append(result.Skipped, crbName+" (not found)")
+	case err != nil:
+		result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", crbName, err))
+	case crb.Labels[kubelensManagedByLabel] != "kubelens":
+		result.Skipped = append(result.Skipped, crbName+" (not managed by kubelens)")
+	default:
+		if err := client.RbacV1().ClusterRoleBindings().Delete(ctx, crb.Name, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", crbName, err))
+		} else {
+			result.Deleted = append(result.Deleted, crbName)
+		}
+	}
+
+	saName := fmt.Sprintf("serviceaccount/%s/%s", kubelensServiceAccountNamespace, kubelensServiceAccountName)
+	sa, err := client.CoreV1().ServiceAccounts(kubelensServiceAccountNamespace).Get(ctx, kubelensServiceAccountName, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		result.Skipped = append(result.Skipped, saName+" (not found)")
+	case err != nil:
+		result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", saName, err))
+	case sa.Labels[kubelensManagedByLabel] != "kubelens":
+		result.Skipped = append(result.Skipped, saName+" (not managed by kubelens)")
+	default:
+		if err := client.CoreV1().ServiceAccounts(sa.Namespace).Delete(ctx, sa.Name, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", saName, err))
+		} else {
+			result.Deleted = append(result.Deleted, saName)
+		}
+	}
+
+	return result
+}
+
+// auditOffboarding records the removal with a summary of the offboarding report
+func auditOffboarding(c *gin.Context, report *OffboardingReport) {
+	if userID, exists := c.Get("user_id"); exists {
+		username, _ := c.Get("username")
+		email, _ := c.Get("email")
+
+		metadata := map[string]interface{}{
+			"cluster_name":        report.ClusterName,
+			"credentials_revoked": report.CredentialsRevoked,
+			"deleted_records":     report.DeletedRecords,
+			"activity_events":     report.Activity.TotalEvents,
+		}
+		if report.RBACCleanup != nil {
+			metadata["rbac_deleted"] = report.RBACCleanup.Deleted
+			metadata["rbac_errors"] = report.RBACCleanup.Errors
+		}
+		audit.Log(c, audit.EventClusterRemoved, userID.(int), username.(string), email.(string),
+			fmt.Sprintf("Removed cluster: %s", report.ClusterName), metadata)
+	}
+}
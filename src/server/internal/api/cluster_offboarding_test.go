@@ -0,0 +1,34 @@
+package api
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestRemoveKubelensServiceAccount(t *testing.T) {
+	managed := map[string]string{kubelensManagedByLabel: "kubelens"}
+	client := fake.NewSimpleClientset(
+		&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{
+			Name: kubelensServiceAccountName, Namespace: kubelensServiceAccountNamespace, Labels: managed,
+		}},
+		// A binding with the same name created by someone else must survive
+		&rbacv1.ClusterRoleBinding{ObjectMeta: metav1.ObjectMeta{Name: kubelensClusterRoleBindingName}},
+	)
+
+	result := removeKubelensServiceAccount(context.Background(), client)
+	if len(result.Deleted) != 1 || len(result.Skipped) != 1 || len(result.Errors) != 0 {
+		t.Fatalf("result = %+v, want 1 deleted and 1 skipped", result)
+	}
+
+	if _, err := client.CoreV1().ServiceAccounts(kubelensServiceAccountNamespace).Get(context.Background(), kubelensServiceAccountName, metav1.GetOptions{}); err == nil {
+		t.Error("expected kubelens ServiceAccount to be deleted")
+	}
+	if _, err := client.RbacV1().ClusterRoleBindings().Get(context.Background(), kubelensClusterRoleBindingName, metav1.GetOptions{}); err != nil {
+		t.Errorf("unmanaged ClusterRoleBinding was deleted: %v", err)
+	}
+}
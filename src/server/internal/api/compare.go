@@ -0,0 +1,206 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// generatedAnnotations are annotations written by controllers and clients rather than
+// by whoever authored the object
+var generatedAnnotations = []string{
+	"kubectl.kubernetes.io/last-applied-configuration",
+	"deployment.kubernetes.io/revision",
+	"deprecated.daemonset.template.generation",
+	"pv.kubernetes.io/bind-completed",
+	"pv.kubernetes.io/bound-by-controller",
+}
+
+// generatedSpecFields are spec fields the API server or a controller allocates, which
+// always differ between clusters
+var generatedSpecFields = map[string][][]string{
+	"Service":               {{"spec", "clusterIP"}, {"spec", "clusterIPs"}, {"spec", "healthCheckNodePort"}},
+	"PersistentVolumeClaim": {{"spec", "volumeName"}},
+	"Pod":                   {{"spec", "nodeName"}},
+}
+
+// compareRef names one side of a comparison
+type compareRef struct {
+	Cluster   string `json:"cluster"`
+	Namespace string `json:"namespace"`
+	Kind      string `json:"kind"` // resource or kind, e.g. deployments, Deployment or deployments.v1.apps
+	Name      string `json:"name"`
+}
+
+func (r compareRef) String() string {
+	if r.Namespace == "" {
+		return r.Cluster + "/" + r.Name
+	}
+	return r.Cluster + "/" + r.Namespace + "/" + r.Name
+}
+
+// compareRequest is the body of CompareResources
+type compareRequest struct {
+	Left  compareRef `json:"left"`
+	Right compareRef `json:"right"`
+	// Ignore lists further paths to leave out, such as spec.replicas
+	Ignore []string `json:"ignore"`
+}
+
+// resourceComparison is the result of comparing two objects
+type resourceComparison struct {
+	Left      compareRef    `json:"left"`
+	Right     compareRef    `json:"right"`
+	Identical bool          `json:"identical"`
+	Changes   []fieldChange `json:"changes"`
+	Diff      string        `json:"diff"`
+}
+
+// CompareResources compares two objects, usually the same object in two clusters or
+// namespaces, to spot drift such as between staging and prod. Both objects are
+// normalized first: status, server-managed metadata, generated annotations and
+// allocated fields (a Service's clusterIP...) are left out, as is the namespace.
+// Body: {"left": {cluster, namespace, kind, name}, "right": {...}, "ignore": ["spec.replicas"]}
+func (h *Handler) CompareResources(c *gin.Context) {
+	var req compareRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	objects := make([]map[string]interface{}, 2)
+	secrets := false
+	for i, ref := range []*compareRef{&req.Left, &req.Right} {
+		obj, status, err := h.getComparedObject(c, ref)
+		if err != nil {
+			if status == http.StatusInternalServerError {
+				log.Errorf("Failed to get %s %s for comparison: %v", ref.Kind, ref, err)
+			}
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+		objects[i] = normalizeForCompare(obj)
+		secrets = secrets || isSecret(obj.Object)
+	}
+	redact := secrets && !h.secretsRevealed()
+
+	changes := []fieldChange{}
+	for _, change := range structuredDiff(objects[0], objects[1]) {
+		if !isIgnoredPath(change.Path, req.Ignore) {
+			changes = append(changes, change)
+		}
+	}
+	for _, path := range req.Ignore {
+		for _, obj := range objects {
+			unstructured.RemoveNestedField(obj, strings.Split(strings.TrimPrefix(path, "."), ".")...)
+		}
+	}
+	// The changes still name the keys that differ, without their values
+	if redact {
+		redactSecretChanges(changes)
+		for i := range objects {
+			objects[i], _ = redactSecretValues("", objects[i]).(map[string]interface{})
+		}
+	}
+	leftYAML, _ := objectYAMLForDiff(objects[0])
+	rightYAML, _ := objectYAMLForDiff(objects[1])
+
+	c.JSON(http.StatusOK, resourceComparison{
+		Left:      req.Left,
+		Right:     req.Right,
+		Identical: len(changes) == 0,
+		Changes:   changes,
+		Diff:      unifiedDiff(leftYAML, rightYAML, req.Left.String(), req.Right.String()),
+	})
+}
+
+// getComparedObject fetches one side of a comparison, returning the HTTP status of a
+// failure. The reference's namespace is cleared for cluster-scoped kinds.
+func (h *Handler) getComparedObject(c *gin.Context, ref *compareRef) (*unstructured.Unstructured, int, error) {
+	if ref.Cluster == "" || ref.Kind == "" || ref.Name == "" {
+		return nil, http.StatusBadRequest, fmt.Errorf("cluster, kind and name are required on both sides")
+	}
+	mapping, err := h.resolveResource(ref.Cluster, ref.Kind)
+	if err != nil {
+		return nil, http.StatusNotFound, err
+	}
+	if !isNamespaced(mapping) {
+		ref.Namespace = ""
+	} else if ref.Namespace == "" {
+		return nil, http.StatusBadRequest, fmt.Errorf("namespace is required for %s", mapping.GroupVersionKind.Kind)
+	}
+
+	// The body names the objects, so the caller's cluster and namespace scope is checked here
+	if value, ok := c.Get("scope_allows"); ok {
+		if allows, ok := value.(func(cluster, resource, namespace string) bool); ok && !allows(ref.Cluster, mapping.Resource.Resource, ref.Namespace) {
+			return nil, http.StatusForbidden, fmt.Errorf("no access to %s in cluster %s namespace %q", mapping.Resource.Resource, ref.Cluster, ref.Namespace)
+		}
+	}
+
+	dynamicClient, err := h.dynamicClient(c, ref.Cluster)
+	if err != nil {
+		return nil, http.StatusNotFound, err
+	}
+	resourceClient := dynamicClient.Resource(mapping.Resource)
+	var obj *unstructured.Unstructured
+	if ref.Namespace != "" {
+		obj, err = resourceClient.Namespace(ref.Namespace).Get(context.Background(), ref.Name, metav1.GetOptions{})
+	} else {
+		obj, err = resourceClient.Get(context.Background(), ref.Name, metav1.GetOptions{})
+	}
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, http.StatusNotFound, fmt.Errorf("%s %s not found", mapping.GroupVersionKind.Kind, ref)
+		}
+		if apierrors.IsForbidden(err) {
+			return nil, http.StatusForbidden, err
+		}
+		return nil, http.StatusInternalServerError, err
+	}
+	ref.Kind = mapping.GroupVersionKind.Kind
+	return obj, http.StatusOK, nil
+}
+
+// normalizeForCompare returns a copy of an object without what differs between any two
+// copies of it: status, server-managed and generated metadata, and allocated fields
+func normalizeForCompare(obj *unstructured.Unstructured) map[string]interface{} {
+	clean := withoutDiffNoise(obj.Object)
+	unstructured.RemoveNestedField(clean, "status")
+	for _, field := range []string{"namespace", "selfLink", "ownerReferences", "generateName"} {
+		unstructured.RemoveNestedField(clean, "metadata", field)
+	}
+	for _, annotation := range generatedAnnotations {
+		unstructured.RemoveNestedField(clean, "metadata", "annotations", annotation)
+	}
+	if annotations, _, _ := unstructured.NestedMap(clean, "metadata", "annotations"); len(annotations) == 0 {
+		unstructured.RemoveNestedField(clean, "metadata", "annotations")
+	}
+	for _, fields := range generatedSpecFields[obj.GetKind()] {
+		unstructured.RemoveNestedField(clean, fields...)
+	}
+	return clean
+}
+
+// isIgnoredPath reports whether a change path (.spec.replicas) is, or is inside, one of
+// the ignored paths (spec.replicas or .spec.replicas)
+func isIgnoredPath(path string, ignore []string) bool {
+	for _, prefix := range ignore {
+		if prefix == "" {
+			continue
+		}
+		if !strings.HasPrefix(prefix, ".") {
+			prefix = "." + prefix
+		}
+		if path == prefix || strings.HasPrefix(path, prefix+".") || strings.HasPrefix(path, prefix+"[") {
+			return true
+		}
+	}
+	return false
+}
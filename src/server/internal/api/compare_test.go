@@ -0,0 +1,70 @@
+package api_test
+
+import (
+	"net/http"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/sonnguyen/kubelens/internal/apitest"
+)
+
+func TestCompareResources(t *testing.T) {
+	deployment := func(namespace, uid, image string, replicas int32) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   namespace,
+				Name:        "web",
+				UID:         types.UID(uid),
+				Annotations: map[string]string{"deployment.kubernetes.io/revision": uid},
+			},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: &replicas,
+				Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "web", Image: image}}}},
+			},
+			Status: appsv1.DeploymentStatus{ReadyReplicas: replicas},
+		}
+	}
+	s := apitest.New(t, deployment("staging", "1", "web:1.1", 1))
+	s.AddCluster("prod", deployment("web", "2", "web:1.0", 3))
+
+	compare := func(body map[string]interface{}) (int, map[string]interface{}) {
+		t.Helper()
+		w := s.Do(http.MethodPost, "/api/v1/compare", body)
+		var resp map[string]interface{}
+		apitest.DecodeJSON(t, w, &resp)
+		return w.Code, resp
+	}
+	left := map[string]string{"cluster": apitest.ClusterName, "namespace": "staging", "kind": "Deployment", "name": "web"}
+	right := map[string]string{"cluster": "prod", "namespace": "web", "kind": "deployments", "name": "web"}
+
+	code, resp := compare(map[string]interface{}{"left": left, "right": right})
+	if code != http.StatusOK {
+		t.Fatalf("status = %d: %v", code, resp)
+	}
+	// Namespace, uid, status and the revision annotation are not drift
+	paths := map[string]bool{}
+	for _, change := range resp["changes"].([]interface{}) {
+		paths[change.(map[string]interface{})["path"].(string)] = true
+	}
+	if len(paths) != 2 || !paths[".spec.replicas"] || !paths[".spec.template.spec.containers[0].image"] {
+		t.Errorf("changed paths = %v, want replicas and image", paths)
+	}
+
+	code, resp = compare(map[string]interface{}{"left": left, "right": right, "ignore": []string{"spec.replicas", "spec.template.spec.containers"}})
+	if code != http.StatusOK || resp["identical"] != true || resp["diff"] != "" {
+		t.Errorf("with ignored fields: %d %v, want identical", code, resp)
+	}
+
+	right["name"] = "missing"
+	if code, _ := compare(map[string]interface{}{"left": left, "right": right}); code != http.StatusNotFound {
+		t.Errorf("missing object: status = %d, want 404", code)
+	}
+	delete(left, "namespace")
+	if code, _ := compare(map[string]interface{}{"left": left, "right": right}); code != http.StatusBadRequest {
+		t.Errorf("missing namespace: status = %d, want 400", code)
+	}
+}
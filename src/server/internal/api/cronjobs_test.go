@@ -0,0 +1,77 @@
+package api_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/sonnguyen/kubelens/internal/apitest"
+	"github.com/sonnguyen/kubelens/internal/audit"
+)
+
+// auditDescribed reports whether an audit entry of an event type has the description
+func auditDescribed(t *testing.T, s *apitest.Server, eventType, description string) bool {
+	t.Helper()
+	entries, _, err := s.DB.ListAuditLogs(1, 50, map[string]interface{}{"event_type": eventType})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if entry.Description == description {
+			return true
+		}
+	}
+	return false
+}
+
+func TestSuspendCronJob(t *testing.T) {
+	s := apitest.New(t, apitest.Fixtures()...)
+	path := "/api/v1/clusters/" + apitest.ClusterName + "/namespaces/" + apitest.FixtureNamespace + "/cronjobs/nightly/suspend"
+	suspended := func() bool {
+		t.Helper()
+		cronjob, err := s.Cluster.Client.BatchV1().CronJobs(apitest.FixtureNamespace).Get(context.Background(), "nightly", metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cronjob.Spec.Suspend != nil && *cronjob.Spec.Suspend
+	}
+
+	if w := s.Do(http.MethodPatch, path, map[string]bool{"suspend": true}); w.Code != http.StatusOK {
+		t.Fatalf("suspend: %d %s", w.Code, w.Body.String())
+	}
+	if !suspended() {
+		t.Error("cronjob is not suspended")
+	}
+	if !auditDescribed(t, s, audit.EventAuditResourceUpdated, "Suspended cronjob: shop/nightly") {
+		t.Error("suspend was not audited")
+	}
+
+	if w := s.Do(http.MethodPatch, path, map[string]bool{"suspend": false}); w.Code != http.StatusOK {
+		t.Fatalf("resume: %d %s", w.Code, w.Body.String())
+	}
+	if suspended() {
+		t.Error("cronjob is still suspended")
+	}
+	if !auditDescribed(t, s, audit.EventAuditResourceUpdated, "Resumed cronjob: shop/nightly") {
+		t.Error("resume was not audited")
+	}
+
+	// suspend is required: an empty body must not resume the cronjob
+	if w := s.Do(http.MethodPatch, path, map[string]string{}); w.Code != http.StatusBadRequest {
+		t.Errorf("without suspend: %d, want 400", w.Code)
+	}
+	if w := s.Do(http.MethodPatch, "/api/v1/clusters/missing/namespaces/shop/cronjobs/nightly/suspend", map[string]bool{"suspend": true}); w.Code != http.StatusNotFound {
+		t.Errorf("unknown cluster: %d, want 404", w.Code)
+	}
+
+	// Suspending changes the cronjob, which a read-only caller may not do
+	do := scopedRouter(s, "read")
+	if w := do(http.MethodPatch, path, "application/json", `{"suspend":true}`); w.Code != http.StatusForbidden {
+		t.Errorf("read-only caller: %d, want 403: %s", w.Code, w.Body.String())
+	}
+	if suspended() {
+		t.Error("read-only caller suspended the cronjob")
+	}
+}
@@ -0,0 +1,246 @@
+package api
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	certificatesv1 "k8s.io/api/certificates/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/sonnguyen/kubelens/internal/audit"
+)
+
+// ==================== CertificateSigningRequest Handlers ====================
+
+// CSRDetails is the decoded content of a PKCS#10 certificate request
+type CSRDetails struct {
+	CommonName     string   `json:"commonName"`
+	Organizations  []string `json:"organizations,omitempty"`
+	DNSNames       []string `json:"dnsNames,omitempty"`
+	IPAddresses    []string `json:"ipAddresses,omitempty"`
+	EmailAddresses []string `json:"emailAddresses,omitempty"`
+	URIs           []string `json:"uris,omitempty"`
+	PublicKeyAlgo  string   `json:"publicKeyAlgorithm"`
+	SignatureAlgo  string   `json:"signatureAlgorithm"`
+}
+
+// csrStatus returns "Approved", "Denied", "Failed" or "Pending" for a CSR
+func csrStatus(csr *certificatesv1.CertificateSigningRequest) string {
+	for _, cond := range csr.Status.Conditions {
+		switch cond.Type {
+		case certificatesv1.CertificateDenied:
+			return "Denied"
+		case certificatesv1.CertificateFailed:
+			return "Failed"
+		case certificatesv1.CertificateApproved:
+			if len(csr.Status.Certificate) > 0 {
+				return "Approved,Issued"
+			}
+			return "Approved"
+		}
+	}
+	return "Pending"
+}
+
+// decodeCSR parses the PEM-encoded request embedded in a CSR object
+func decodeCSR(request []byte) (*CSRDetails, error) {
+	block, _ := pem.Decode(request)
+	if block == nil || block.Type != "CERTIFICATE REQUEST" {
+		return nil, fmt.Errorf("request is not a PEM encoded certificate request")
+	}
+
+	req, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	details := &CSRDetails{
+		CommonName:     req.Subject.CommonName,
+		Organizations:  req.Subject.Organization,
+		DNSNames:       req.DNSNames,
+		EmailAddresses: req.EmailAddresses,
+		PublicKeyAlgo:  req.PublicKeyAlgorithm.String(),
+		SignatureAlgo:  req.SignatureAlgorithm.String(),
+	}
+	for _, ip := range req.IPAddresses {
+		details.IPAddresses = append(details.IPAddresses, ip.String())
+	}
+	for _, uri := range req.URIs {
+		details.URIs = append(details.URIs, uri.String())
+	}
+	return details, nil
+}
+
+// csrToMap builds the API representation of a CSR with its decoded request
+func csrToMap(csr *certificatesv1.CertificateSigningRequest, clusterName string) map[string]interface{} {
+	result := map[string]interface{}{
+		"metadata":    csr.ObjectMeta,
+		"spec":        csr.Spec,
+		"status":      csr.Status,
+		"condition":   csrStatus(csr),
+		"clusterName": clusterName,
+	}
+	if details, err := decodeCSR(csr.Spec.Request); err == nil {
+		result["decoded"] = details
+	} else {
+		result["decodeError"] = err.Error()
+	}
+	return result
+}
+
+// ListCertificateSigningRequests returns all CSRs in a cluster
+func (h *Handler) ListCertificateSigningRequests(c *gin.Context) {
+	clusterName := c.Param("name")
+
+	client, err := h.client(c, clusterName)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	csrs, err := client.CertificatesV1().CertificateSigningRequests().List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		log.Errorf("Failed to list certificate signing requests: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	result := make([]map[string]interface{}, len(csrs.Items))
+	for i := range csrs.Items {
+		result[i] = csrToMap(&csrs.Items[i], clusterName)
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetCertificateSigningRequest returns details of a specific CSR
+func (h *Handler) GetCertificateSigningRequest(c *gin.Context) {
+	clusterName := c.Param("name")
+	csrName := c.Param("csr")
+
+	client, err := h.client(c, clusterName)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	csr, err := client.CertificatesV1().CertificateSigningRequests().Get(context.Background(), csrName, metav1.GetOptions{})
+	if err != nil {
+		log.Errorf("Failed to get certificate signing request: %v", err)
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, csrToMap(csr, clusterName))
+}
+
+// ApproveCertificateSigningRequest approves a pending CSR
+func (h *Handler) ApproveCertificateSigningRequest(c *gin.Context) {
+	h.updateCSRApproval(c, certificatesv1.CertificateApproved)
+}
+
+// DenyCertificateSigningRequest denies a pending CSR
+func (h *Handler) DenyCertificateSigningRequest(c *gin.Context) {
+	h.updateCSRApproval(c, certificatesv1.CertificateDenied)
+}
+
+// updateCSRApproval adds an Approved or Denied condition to a CSR
+func (h *Handler) updateCSRApproval(c *gin.Context, condType certificatesv1.RequestConditionType) {
+	clusterName := c.Param("name")
+	csrName := c.Param("csr")
+
+	var req struct {
+		Message string `json:"message"`
+	}
+	// Body is optional
+	_ = c.ShouldBindJSON(&req)
+
+	client, err := h.client(c, clusterName)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	csr, err := client.CertificatesV1().CertificateSigningRequests().Get(context.Background(), csrName, metav1.GetOptions{})
+	if err != nil {
+		log.Errorf("Failed to get certificate signing request: %v", err)
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	if status := csrStatus(csr); status != "Pending" {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("certificate signing request is already %s", status)})
+		return
+	}
+
+	username := c.GetString("username")
+	reason := "KubelensApprove"
+	message := fmt.Sprintf("Approved by %s via kubelens", username)
+	if condType == certificatesv1.CertificateDenied {
+		reason = "KubelensDeny"
+		message = fmt.Sprintf("Denied by %s via kubelens", username)
+	}
+	if req.Message != "" {
+		message = req.Message
+	}
+
+	csr.Status.Conditions = append(csr.Status.Conditions, certificatesv1.CertificateSigningRequestCondition{
+		Type:           condType,
+		Status:         corev1.ConditionTrue,
+		Reason:         reason,
+		Message:        message,
+		LastUpdateTime: metav1.Now(),
+	})
+
+	updated, err := client.CertificatesV1().CertificateSigningRequests().UpdateApproval(context.Background(), csrName, csr, updateOptions(c))
+	if err != nil {
+		log.Errorf("Failed to update certificate signing request approval: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Audit log
+	if userID, exists := c.Get("user_id"); exists {
+		email, _ := c.Get("email")
+
+		audit.Log(c, audit.EventAuditResourceUpdated, userID.(int), username, email.(string),
+			fmt.Sprintf("%s certificate signing request: %s", condType, csrName),
+			map[string]interface{}{
+				"cluster_name": clusterName,
+				"kind":         "CertificateSigningRequest",
+				"name":         csrName,
+				"signer_name":  csr.Spec.SignerName,
+				"requestor":    csr.Spec.Username,
+				"decision":     string(condType),
+			})
+	}
+
+	c.JSON(http.StatusOK, csrToMap(updated, clusterName))
+}
+
+// DeleteCertificateSigningRequest deletes a CSR
+func (h *Handler) DeleteCertificateSigningRequest(c *gin.Context) {
+	clusterName := c.Param("name")
+	csrName := c.Param("csr")
+
+	client, err := h.client(c, clusterName)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = client.CertificatesV1().CertificateSigningRequests().Delete(context.Background(), csrName, deleteOptions(c))
+	if err != nil {
+		log.Errorf("Failed to delete certificate signing request: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "CertificateSigningRequest deleted successfully"})
+}
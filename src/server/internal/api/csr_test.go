@@ -0,0 +1,166 @@
+package api_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"net"
+	"net/http"
+	"testing"
+
+	certificatesv1 "k8s.io/api/certificates/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/sonnguyen/kubelens/internal/apitest"
+	"github.com/sonnguyen/kubelens/internal/audit"
+)
+
+// certificateRequest returns a PEM encoded PKCS#10 request for a kubelet serving certificate
+func certificateRequest(t *testing.T, commonName string) []byte {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:     pkix.Name{CommonName: commonName, Organization: []string{"system:nodes"}},
+		DNSNames:    []string{"node-1.internal"},
+		IPAddresses: []net.IP{net.ParseIP("192.168.0.1")},
+	}, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der})
+}
+
+func certificateSigningRequest(name string, request []byte, conditions ...certificatesv1.RequestConditionType) *certificatesv1.CertificateSigningRequest {
+	csr := &certificatesv1.CertificateSigningRequest{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: certificatesv1.CertificateSigningRequestSpec{
+			Request:    request,
+			SignerName: "kubernetes.io/kubelet-serving",
+			Username:   "system:node:node-1",
+			Usages:     []certificatesv1.KeyUsage{certificatesv1.UsageServerAuth},
+		},
+	}
+	for _, condition := range conditions {
+		csr.Status.Conditions = append(csr.Status.Conditions, certificatesv1.CertificateSigningRequestCondition{Type: condition, Status: corev1.ConditionTrue})
+	}
+	return csr
+}
+
+type csrView struct {
+	Metadata  metav1.ObjectMeta `json:"metadata"`
+	Condition string            `json:"condition"`
+	Decoded   *struct {
+		CommonName    string   `json:"commonName"`
+		Organizations []string `json:"organizations"`
+		DNSNames      []string `json:"dnsNames"`
+		IPAddresses   []string `json:"ipAddresses"`
+	} `json:"decoded"`
+	DecodeError string `json:"decodeError"`
+}
+
+func TestCertificateSigningRequests(t *testing.T) {
+	request := certificateRequest(t, "system:node:node-1")
+	s := apitest.New(t,
+		certificateSigningRequest("csr-pending", request),
+		certificateSigningRequest("csr-other", request),
+		certificateSigningRequest("csr-approved", request, certificatesv1.CertificateApproved),
+		certificateSigningRequest("csr-garbled", []byte("not a request")),
+	)
+	base := "/api/v1/clusters/" + apitest.ClusterName + "/certificatesigningrequests"
+
+	var list []csrView
+	w := s.Get(base)
+	if w.Code != http.StatusOK {
+		t.Fatalf("list: %d %s", w.Code, w.Body.String())
+	}
+	apitest.DecodeJSON(t, w, &list)
+	if len(list) != 4 {
+		t.Fatalf("got %d CSRs, want 4", len(list))
+	}
+
+	var csr csrView
+	w = s.Get(base + "/csr-pending")
+	if w.Code != http.StatusOK {
+		t.Fatalf("get: %d %s", w.Code, w.Body.String())
+	}
+	apitest.DecodeJSON(t, w, &csr)
+	if csr.Condition != "Pending" || csr.Decoded == nil {
+		t.Fatalf("csr = %+v, want a pending CSR with its decoded request", csr)
+	}
+	if csr.Decoded.CommonName != "system:node:node-1" || len(csr.Decoded.Organizations) != 1 ||
+		len(csr.Decoded.DNSNames) != 1 || csr.Decoded.DNSNames[0] != "node-1.internal" ||
+		len(csr.Decoded.IPAddresses) != 1 || csr.Decoded.IPAddresses[0] != "192.168.0.1" {
+		t.Errorf("decoded = %+v, want the subject and SANs of the request", csr.Decoded)
+	}
+
+	csr = csrView{}
+	apitest.DecodeJSON(t, s.Get(base+"/csr-garbled"), &csr)
+	if csr.Decoded != nil || csr.DecodeError == "" {
+		t.Errorf("garbled CSR = %+v, want a decode error", csr)
+	}
+
+	csr = csrView{}
+	w = s.Do(http.MethodPost, base+"/csr-pending/approve", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("approve: %d %s", w.Code, w.Body.String())
+	}
+	apitest.DecodeJSON(t, w, &csr)
+	if csr.Condition != "Approved" {
+		t.Errorf("condition = %q, want Approved", csr.Condition)
+	}
+	if !auditDescribed(t, s, audit.EventAuditResourceUpdated, "Approved certificate signing request: csr-pending") {
+		t.Error("approval was not audited")
+	}
+
+	csr = csrView{}
+	w = s.Do(http.MethodPost, base+"/csr-other/deny", map[string]string{"message": "unknown node"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("deny: %d %s", w.Code, w.Body.String())
+	}
+	apitest.DecodeJSON(t, w, &csr)
+	if csr.Condition != "Denied" {
+		t.Errorf("condition = %q, want Denied", csr.Condition)
+	}
+
+	// A decision is final
+	if w := s.Do(http.MethodPost, base+"/csr-approved/deny", nil); w.Code != http.StatusConflict {
+		t.Errorf("deny an approved CSR: %d, want 409", w.Code)
+	}
+	if w := s.Do(http.MethodPost, base+"/missing/approve", nil); w.Code != http.StatusNotFound {
+		t.Errorf("approve an unknown CSR: %d, want 404", w.Code)
+	}
+
+	if w := s.Do(http.MethodDelete, base+"/csr-approved", nil); w.Code != http.StatusOK {
+		t.Fatalf("delete: %d %s", w.Code, w.Body.String())
+	}
+	if w := s.Get(base + "/csr-approved"); w.Code != http.StatusNotFound {
+		t.Errorf("get a deleted CSR: %d, want 404", w.Code)
+	}
+}
+
+func TestCertificateSigningRequestsScoped(t *testing.T) {
+	s := apitest.New(t, certificateSigningRequest("csr-pending", certificateRequest(t, "system:node:node-1")))
+	base := "/api/v1/clusters/" + apitest.ClusterName + "/certificatesigningrequests"
+
+	// CSRs are cluster-scoped, out of reach of a caller restricted to a namespace
+	do := scopedRouter(s, "read", "update")
+	if w := do(http.MethodGet, base+"/csr-pending", "", ""); w.Code != http.StatusForbidden {
+		t.Errorf("get: %d, want 403", w.Code)
+	}
+	if w := do(http.MethodPost, base+"/csr-pending/approve", "", ""); w.Code != http.StatusForbidden {
+		t.Errorf("approve: %d, want 403", w.Code)
+	}
+
+	var csr csrView
+	apitest.DecodeJSON(t, s.Get(base+"/csr-pending"), &csr)
+	if csr.Condition != "Pending" {
+		t.Errorf("condition = %q, want the CSR still pending", csr.Condition)
+	}
+}
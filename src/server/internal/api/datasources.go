@@ -0,0 +1,333 @@
+package api
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/sonnguyen/kubelens/internal/audit"
+	"github.com/sonnguyen/kubelens/internal/crypto"
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+const (
+	// datasourceTimeout bounds a request to a datasource
+	datasourceTimeout = 30 * time.Second
+	// maxDatasourceResponse bounds the size of a datasource response
+	maxDatasourceResponse = 20 << 20
+)
+
+// datasourceHealthPaths are the supported datasource types and the endpoint requested to
+// test a connection
+var datasourceHealthPaths = map[string]string{
+	"prometheus": "/api/v1/status/buildinfo",
+	"loki":       "/loki/api/v1/labels",
+	"grafana":    "/api/health",
+	"opencost":   "/healthz",
+}
+
+// datasourceRequest is the body of PUT /clusters/:name/datasources/:type. A nil secret
+// keeps the stored one; an empty string clears it.
+type datasourceRequest struct {
+	URL                string            `json:"url" binding:"required"`
+	AuthType           string            `json:"auth_type"` // none (default), basic or bearer
+	Username           string            `json:"username"`
+	Secret             *string           `json:"secret"` // password (basic) or token (bearer)
+	Headers            map[string]string `json:"headers"`
+	InsecureSkipVerify bool              `json:"insecure_skip_verify"`
+	Settings           json.RawMessage   `json:"settings"` // grafana: see grafanaSettings; opencost: see opencostSettings
+}
+
+// validate checks the request and normalizes the auth type
+func (req *datasourceRequest) validate() error {
+	u, err := url.Parse(req.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("url must be an http(s) URL")
+	}
+	if u.User != nil {
+		return fmt.Errorf("url must not contain credentials; use auth_type basic")
+	}
+	switch req.AuthType {
+	case "":
+		req.AuthType = "none"
+	case "none", "basic", "bearer":
+	default:
+		return fmt.Errorf("auth_type must be none, basic or bearer")
+	}
+	if req.AuthType == "basic" && req.Username == "" {
+		return fmt.Errorf("username is required for basic auth")
+	}
+	for name := range req.Headers {
+		if http.CanonicalHeaderKey(name) == "Authorization" {
+			return fmt.Errorf("set credentials with auth_type instead of an Authorization header")
+		}
+	}
+	return nil
+}
+
+// validateDatasourceSettings checks the type specific settings of a datasource and returns them
+// normalized for storage
+func validateDatasourceSettings(datasourceType string, raw json.RawMessage) (db.JSON, error) {
+	if datasourceType == "opencost" {
+		return validateOpenCostSettings(raw)
+	}
+	if datasourceType != "grafana" {
+		if len(raw) > 0 && string(raw) != "null" {
+			return nil, fmt.Errorf("%s datasources have no settings", datasourceType)
+		}
+		return nil, nil
+	}
+
+	var settings grafanaSettings
+	if len(raw) > 0 {
+		decoder := json.NewDecoder(strings.NewReader(string(raw)))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&settings); err != nil {
+			return nil, fmt.Errorf("invalid settings: %v", err)
+		}
+	}
+	if err := settings.validate(); err != nil {
+		return nil, err
+	}
+	normalized, _ := json.Marshal(settings)
+	return db.JSON(normalized), nil
+}
+
+// ListDatasources handles GET /clusters/:name/datasources
+func (h *Handler) ListDatasources(c *gin.Context) {
+	datasources, err := h.db.ListClusterDatasources(c.Param("name"))
+	if err != nil {
+		log.Errorf("Failed to list datasources: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list datasources"})
+		return
+	}
+	c.JSON(http.StatusOK, datasources)
+}
+
+// SaveDatasource handles PUT /clusters/:name/datasources/:type and creates or replaces the
+// datasource of that type
+func (h *Handler) SaveDatasource(c *gin.Context) {
+	clusterName := c.Param("name")
+	datasourceType := c.Param("type")
+
+	if _, ok := datasourceHealthPaths[datasourceType]; !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("unsupported datasource type %q", datasourceType)})
+		return
+	}
+	if _, err := h.db.GetCluster(clusterName); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req datasourceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := req.validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	settings, err := validateDatasourceSettings(datasourceType, req.Settings)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ds, err := h.db.GetClusterDatasource(clusterName, datasourceType)
+	if err != nil {
+		ds = &db.ClusterDatasource{ClusterName: clusterName, Type: datasourceType}
+	}
+	ds.URL = strings.TrimSuffix(req.URL, "/")
+	ds.AuthType = req.AuthType
+	ds.Username = req.Username
+	ds.InsecureSkipVerify = req.InsecureSkipVerify
+	ds.Settings = settings
+	ds.Headers = nil
+	if len(req.Headers) > 0 {
+		headers, _ := json.Marshal(req.Headers)
+		ds.Headers = db.JSON(headers)
+	}
+	if req.Secret != nil {
+		ds.Secret = ""
+		if *req.Secret != "" {
+			encryptor, err := h.encryptor()
+			if err == nil {
+				ds.Secret, err = encryptor.Encrypt([]byte(*req.Secret))
+			}
+			if err != nil {
+				log.Errorf("Failed to encrypt datasource secret: %v", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to store the secret"})
+				return
+			}
+		}
+	}
+	if ds.AuthType == "none" {
+		ds.Username, ds.Secret = "", ""
+	}
+
+	if err := h.db.SaveClusterDatasource(ds); err != nil {
+		log.Errorf("Failed to save datasource: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save datasource"})
+		return
+	}
+
+	h.auditDatasource(c, fmt.Sprintf("Configured %s datasource for cluster %s", datasourceType, clusterName), ds)
+	c.JSON(http.StatusOK, ds)
+}
+
+// DeleteDatasource handles DELETE /clusters/:name/datasources/:type
+func (h *Handler) DeleteDatasource(c *gin.Context) {
+	clusterName := c.Param("name")
+	datasourceType := c.Param("type")
+
+	ds, err := h.db.GetClusterDatasource(clusterName, datasourceType)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.db.DeleteClusterDatasource(clusterName, datasourceType); err != nil {
+		log.Errorf("Failed to delete datasource: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete datasource"})
+		return
+	}
+
+	h.auditDatasource(c, fmt.Sprintf("Removed %s datasource of cluster %s", datasourceType, clusterName), ds)
+	c.JSON(http.StatusOK, gin.H{"message": "datasource deleted"})
+}
+
+// TestDatasource handles POST /clusters/:name/datasources/:type/test and checks that the
+// stored datasource is reachable with its credentials
+func (h *Handler) TestDatasource(c *gin.Context) {
+	ds, err := h.db.GetClusterDatasource(c.Param("name"), c.Param("type"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	start := time.Now()
+	healthPath := datasourceHealthPaths[ds.Type]
+	if ds.Type == "opencost" {
+		healthPath = opencostPrefix(ds) + healthPath
+	}
+	if _, err := h.datasourceGet(c.Request.Context(), ds, healthPath, nil); err != nil {
+		c.JSON(http.StatusOK, gin.H{"ok": false, "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"ok": true, "latency_ms": time.Since(start).Milliseconds()})
+}
+
+func (h *Handler) auditDatasource(c *gin.Context, desc string, ds *db.ClusterDatasource) {
+	if userID, exists := c.Get("user_id"); exists {
+		username, _ := c.Get("username")
+		email, _ := c.Get("email")
+		audit.Log(c, audit.EventAuditConfigChanged, userID.(int), username.(string), email.(string),
+			desc,
+			map[string]interface{}{
+				"cluster_name": ds.ClusterName,
+				"type":         ds.Type,
+				"url":          ds.URL,
+				"auth_type":    ds.AuthType,
+			})
+	}
+}
+
+// datasourceGet sends a GET request to a datasource with its credentials and headers and
+// returns the response body. Responses other than 2xx are returned as errors.
+func (h *Handler) datasourceGet(ctx context.Context, ds *db.ClusterDatasource, path string, query url.Values) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, datasourceTimeout)
+	defer cancel()
+
+	target := ds.URL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if len(ds.Headers) > 0 {
+		var headers map[string]string
+		if err := json.Unmarshal(ds.Headers, &headers); err == nil {
+			for name, value := range headers {
+				req.Header.Set(name, value)
+			}
+		}
+	}
+	if ds.AuthType == "basic" || ds.AuthType == "bearer" {
+		secret := ""
+		if ds.Secret != "" {
+			encryptor, err := h.encryptor()
+			if err != nil {
+				return nil, err
+			}
+			decrypted, err := encryptor.Decrypt(ds.Secret)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decrypt datasource secret: %w", err)
+			}
+			secret = string(decrypted)
+		}
+		if ds.AuthType == "basic" {
+			req.SetBasicAuth(ds.Username, secret)
+		} else {
+			req.Header.Set("Authorization", "Bearer "+secret)
+		}
+	}
+
+	client := &http.Client{}
+	if ds.InsecureSkipVerify {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+		client.Transport = transport
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s request failed: %w", ds.Type, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxDatasourceResponse+1))
+	if err != nil {
+		return nil, fmt.Errorf("%s request failed: %w", ds.Type, err)
+	}
+	if len(body) > maxDatasourceResponse {
+		return nil, fmt.Errorf("%s response exceeds %d MB", ds.Type, maxDatasourceResponse>>20)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return body, &datasourceError{status: resp.StatusCode, body: strings.TrimSpace(string(body))}
+	}
+	return body, nil
+}
+
+// datasourceError is a non-2xx response of a datasource
+type datasourceError struct {
+	status int
+	body   string
+}
+
+func (e *datasourceError) Error() string {
+	body := e.body
+	if len(body) > 512 {
+		body = body[:512] + "..."
+	}
+	return fmt.Sprintf("datasource returned HTTP %d: %s", e.status, body)
+}
+
+// encryptor returns the encryptor for secrets stored by kubelens
+func (h *Handler) encryptor() (*crypto.Encryptor, error) {
+	key, err := h.db.GetOrCreateEncryptionKey()
+	if err != nil {
+		return nil, err
+	}
+	return crypto.NewEncryptor(key)
+}
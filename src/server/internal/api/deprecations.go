@@ -0,0 +1,256 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/version"
+)
+
+// maxKubeletSkew is how many minor versions a kubelet may lag behind the API server
+const maxKubeletSkew = 3
+
+// removedAPI is an API version the Kubernetes project removed in a given release
+type removedAPI struct {
+	GroupVersion string
+	Kind         string
+	Resource     string
+	RemovedIn    string
+	ReplacedBy   string // "" when the API was removed without a replacement
+}
+
+// removedAPIs lists the built-in API versions removed since Kubernetes 1.22, following
+// the upstream deprecated API migration guide
+var removedAPIs = []removedAPI{
+	{"extensions/v1beta1", "Ingress", "ingresses", "1.22", "networking.k8s.io/v1"},
+	{"networking.k8s.io/v1beta1", "Ingress", "ingresses", "1.22", "networking.k8s.io/v1"},
+	{"networking.k8s.io/v1beta1", "IngressClass", "ingressclasses", "1.22", "networking.k8s.io/v1"},
+	{"apiextensions.k8s.io/v1beta1", "CustomResourceDefinition", "customresourcedefinitions", "1.22", "apiextensions.k8s.io/v1"},
+	{"admissionregistration.k8s.io/v1beta1", "MutatingWebhookConfiguration", "mutatingwebhookconfigurations", "1.22", "admissionregistration.k8s.io/v1"},
+	{"admissionregistration.k8s.io/v1beta1", "ValidatingWebhookConfiguration", "validatingwebhookconfigurations", "1.22", "admissionregistration.k8s.io/v1"},
+	{"apiregistration.k8s.io/v1beta1", "APIService", "apiservices", "1.22", "apiregistration.k8s.io/v1"},
+	{"certificates.k8s.io/v1beta1", "CertificateSigningRequest", "certificatesigningrequests", "1.22", "certificates.k8s.io/v1"},
+	{"coordination.k8s.io/v1beta1", "Lease", "leases", "1.22", "coordination.k8s.io/v1"},
+	{"rbac.authorization.k8s.io/v1beta1", "ClusterRole", "clusterroles", "1.22", "rbac.authorization.k8s.io/v1"},
+	{"rbac.authorization.k8s.io/v1beta1", "ClusterRoleBinding", "clusterrolebindings", "1.22", "rbac.authorization.k8s.io/v1"},
+	{"rbac.authorization.k8s.io/v1beta1", "Role", "roles", "1.22", "rbac.authorization.k8s.io/v1"},
+	{"rbac.authorization.k8s.io/v1beta1", "RoleBinding", "rolebindings", "1.22", "rbac.authorization.k8s.io/v1"},
+	{"scheduling.k8s.io/v1beta1", "PriorityClass", "priorityclasses", "1.22", "scheduling.k8s.io/v1"},
+	{"storage.k8s.io/v1beta1", "CSIDriver", "csidrivers", "1.22", "storage.k8s.io/v1"},
+	{"storage.k8s.io/v1beta1", "CSINode", "csinodes", "1.22", "storage.k8s.io/v1"},
+	{"storage.k8s.io/v1beta1", "StorageClass", "storageclasses", "1.22", "storage.k8s.io/v1"},
+	{"storage.k8s.io/v1beta1", "VolumeAttachment", "volumeattachments", "1.22", "storage.k8s.io/v1"},
+	{"batch/v1beta1", "CronJob", "cronjobs", "1.25", "batch/v1"},
+	{"discovery.k8s.io/v1beta1", "EndpointSlice", "endpointslices", "1.25", "discovery.k8s.io/v1"},
+	{"events.k8s.io/v1beta1", "Event", "events", "1.25", "events.k8s.io/v1"},
+	{"autoscaling/v2beta1", "HorizontalPodAutoscaler", "horizontalpodautoscalers", "1.25", "autoscaling/v2"},
+	{"policy/v1beta1", "PodDisruptionBudget", "poddisruptionbudgets", "1.25", "policy/v1"},
+	{"policy/v1beta1", "PodSecurityPolicy", "podsecuritypolicies", "1.25", ""},
+	{"node.k8s.io/v1beta1", "RuntimeClass", "runtimeclasses", "1.25", "node.k8s.io/v1"},
+	{"autoscaling/v2beta2", "HorizontalPodAutoscaler", "horizontalpodautoscalers", "1.26", "autoscaling/v2"},
+	{"flowcontrol.apiserver.k8s.io/v1beta1", "FlowSchema", "flowschemas", "1.26", "flowcontrol.apiserver.k8s.io/v1"},
+	{"flowcontrol.apiserver.k8s.io/v1beta1", "PriorityLevelConfiguration", "prioritylevelconfigurations", "1.26", "flowcontrol.apiserver.k8s.io/v1"},
+	{"storage.k8s.io/v1beta1", "CSIStorageCapacity", "csistoragecapacities", "1.27", "storage.k8s.io/v1"},
+	{"flowcontrol.apiserver.k8s.io/v1beta2", "FlowSchema", "flowschemas", "1.29", "flowcontrol.apiserver.k8s.io/v1"},
+	{"flowcontrol.apiserver.k8s.io/v1beta2", "PriorityLevelConfiguration", "prioritylevelconfigurations", "1.29", "flowcontrol.apiserver.k8s.io/v1"},
+	{"flowcontrol.apiserver.k8s.io/v1beta3", "FlowSchema", "flowschemas", "1.32", "flowcontrol.apiserver.k8s.io/v1"},
+	{"flowcontrol.apiserver.k8s.io/v1beta3", "PriorityLevelConfiguration", "prioritylevelconfigurations", "1.32", "flowcontrol.apiserver.k8s.io/v1"},
+}
+
+// DeprecationReport is what would break when upgrading a cluster to a target version
+type DeprecationReport struct {
+	Cluster        string            `json:"cluster"`
+	CurrentVersion string            `json:"current_version"`
+	TargetVersion  string            `json:"target_version"`
+	Warnings       []string          `json:"warnings"`
+	SkewedNodes    []SkewedNode      `json:"skewed_nodes"`
+	RemovedAPIs    []RemovedAPIUsage `json:"removed_apis"`
+}
+
+// SkewedNode is a node whose kubelet would be too old for the target version
+type SkewedNode struct {
+	Name           string `json:"name"`
+	KubeletVersion string `json:"kubelet_version"`
+}
+
+// RemovedAPIUsage lists the objects still relying on an API version removed by the target
+type RemovedAPIUsage struct {
+	APIVersion string             `json:"api_version"`
+	Kind       string             `json:"kind"`
+	RemovedIn  string             `json:"removed_in"`
+	ReplacedBy string             `json:"replaced_by,omitempty"`
+	Objects    []DeprecatedObject `json:"objects"`
+}
+
+// DeprecatedObject is an object found using a removed API version, and why
+type DeprecatedObject struct {
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	Reason    string `json:"reason"`
+}
+
+// GetClusterDeprecations checks a cluster against an upgrade to a target Kubernetes
+// version: version skew of the control plane and the kubelets, and the objects that use
+// API versions removed between the current and the target version. An object counts as
+// using a removed version when it was last applied with it (kubectl's last-applied
+// annotation) or when the cluster serves the kind in no other version.
+// Query: target - the target version, e.g. 1.32 (default: the next minor version)
+func (h *Handler) GetClusterDeprecations(c *gin.Context) {
+	clusterName := c.Param("name")
+	client, err := h.client(c, clusterName)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	serverVersion, err := client.Discovery().ServerVersion()
+	if err != nil {
+		log.Errorf("Failed to get version of cluster %s: %v", clusterName, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	current, err := version.ParseGeneric(serverVersion.GitVersion)
+	if err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("cluster reports an unknown version %q", serverVersion.GitVersion)})
+		return
+	}
+	target := version.MajorMinor(current.Major(), current.Minor()+1)
+	if query := c.Query("target"); query != "" {
+		if target, err = version.ParseGeneric(query); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid target version %q", query)})
+			return
+		}
+	}
+	if target.Major() != current.Major() || target.Minor() < current.Minor() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("target version %s is not an upgrade of %s", majorMinor(target), majorMinor(current))})
+		return
+	}
+
+	report := DeprecationReport{
+		Cluster:        clusterName,
+		CurrentVersion: serverVersion.GitVersion,
+		TargetVersion:  majorMinor(target),
+		Warnings:       []string{},
+		SkewedNodes:    []SkewedNode{},
+		RemovedAPIs:    []RemovedAPIUsage{},
+	}
+	ctx := context.Background()
+
+	if target.Minor() > current.Minor()+1 {
+		report.Warnings = append(report.Warnings, fmt.Sprintf(
+			"the control plane must be upgraded one minor version at a time, through %d minor releases", target.Minor()-current.Minor()))
+	}
+
+	nodes, err := client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("kubelet versions not checked: %v", err))
+	} else {
+		for _, node := range nodes.Items {
+			kubelet, err := version.ParseGeneric(node.Status.NodeInfo.KubeletVersion)
+			if err != nil {
+				continue
+			}
+			if kubelet.Major() != target.Major() || kubelet.Minor()+maxKubeletSkew < target.Minor() {
+				report.SkewedNodes = append(report.SkewedNodes, SkewedNode{Name: node.Name, KubeletVersion: node.Status.NodeInfo.KubeletVersion})
+			}
+		}
+		if len(report.SkewedNodes) > 0 {
+			report.Warnings = append(report.Warnings, fmt.Sprintf(
+				"%d nodes run a kubelet more than %d minor versions older than %s", len(report.SkewedNodes), maxKubeletSkew, majorMinor(target)))
+		}
+	}
+
+	dynamicClient, err := h.dynamicClient(c, clusterName)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	served := map[string]map[string]bool{}
+	serves := func(groupVersion, resource string) bool {
+		resources, ok := served[groupVersion]
+		if !ok {
+			resources = map[string]bool{}
+			if list, err := client.Discovery().ServerResourcesForGroupVersion(groupVersion); err == nil {
+				for _, r := range list.APIResources {
+					resources[r.Name] = true
+				}
+			}
+			served[groupVersion] = resources
+		}
+		return resources[resource]
+	}
+
+	for _, api := range removedAPIs {
+		removedIn := version.MustParseGeneric(api.RemovedIn)
+		if removedIn.Minor() <= current.Minor() || removedIn.Minor() > target.Minor() {
+			continue
+		}
+
+		// List through the replacement when the cluster serves it, so that objects show
+		// up whichever version they were written with
+		listVersion, onlyRemoved := api.ReplacedBy, false
+		if api.ReplacedBy == "" || !serves(api.ReplacedBy, api.Resource) {
+			if !serves(api.GroupVersion, api.Resource) {
+				continue
+			}
+			listVersion, onlyRemoved = api.GroupVersion, true
+		}
+		gv, _ := schema.ParseGroupVersion(listVersion)
+		list, err := dynamicClient.Resource(gv.WithResource(api.Resource)).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("%s %s not checked: %v", api.GroupVersion, api.Kind, err))
+			continue
+		}
+
+		usage := RemovedAPIUsage{APIVersion: api.GroupVersion, Kind: api.Kind, RemovedIn: api.RemovedIn, ReplacedBy: api.ReplacedBy, Objects: []DeprecatedObject{}}
+		for _, obj := range list.Items {
+			reason := ""
+			if onlyRemoved {
+				reason = "served only as " + api.GroupVersion
+			} else if lastAppliedAPIVersion(obj.GetAnnotations()) == api.GroupVersion {
+				reason = "last applied as " + api.GroupVersion
+			}
+			if reason != "" {
+				usage.Objects = append(usage.Objects, DeprecatedObject{Namespace: obj.GetNamespace(), Name: obj.GetName(), Reason: reason})
+			}
+		}
+		if len(usage.Objects) > 0 {
+			sort.Slice(usage.Objects, func(i, j int) bool {
+				if usage.Objects[i].Namespace != usage.Objects[j].Namespace {
+					return usage.Objects[i].Namespace < usage.Objects[j].Namespace
+				}
+				return usage.Objects[i].Name < usage.Objects[j].Name
+			})
+			report.RemovedAPIs = append(report.RemovedAPIs, usage)
+		}
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// majorMinor formats a version as major.minor
+func majorMinor(v *version.Version) string {
+	return fmt.Sprintf("%d.%d", v.Major(), v.Minor())
+}
+
+// lastAppliedAPIVersion returns the apiVersion of kubectl's last-applied configuration
+func lastAppliedAPIVersion(annotations map[string]string) string {
+	lastApplied := annotations["kubectl.kubernetes.io/last-applied-configuration"]
+	if lastApplied == "" {
+		return ""
+	}
+	var applied struct {
+		APIVersion string `json:"apiVersion"`
+	}
+	if err := json.Unmarshal([]byte(lastApplied), &applied); err != nil {
+		return ""
+	}
+	return strings.TrimSpace(applied.APIVersion)
+}
@@ -0,0 +1,85 @@
+package api_test
+
+import (
+	"net/http"
+	"testing"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+
+	"github.com/sonnguyen/kubelens/internal/apitest"
+)
+
+func TestClusterDeprecations(t *testing.T) {
+	cronJob := func(name, appliedAs string) *batchv1.CronJob {
+		return &batchv1.CronJob{ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "jobs",
+			Name:        name,
+			Annotations: map[string]string{"kubectl.kubernetes.io/last-applied-configuration": `{"apiVersion":"` + appliedAs + `","kind":"CronJob"}`},
+		}}
+	}
+	node := func(name, kubelet string) *corev1.Node {
+		return &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}, Status: corev1.NodeStatus{NodeInfo: corev1.NodeSystemInfo{KubeletVersion: kubelet}}}
+	}
+	psp := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "policy/v1beta1",
+		"kind":       "PodSecurityPolicy",
+		"metadata":   map[string]interface{}{"name": "restricted"},
+	}}
+	s := apitest.New(t, cronJob("old", "batch/v1beta1"), cronJob("new", "batch/v1"), node("a", "v1.24.3"), node("b", "v1.21.5"), psp)
+	s.Cluster.Client.Resources = append(s.Cluster.Client.Resources, &metav1.APIResourceList{
+		GroupVersion: "policy/v1beta1",
+		APIResources: []metav1.APIResource{{Name: "podsecuritypolicies", Kind: "PodSecurityPolicy"}},
+	})
+	s.Cluster.Client.Discovery().(*fakediscovery.FakeDiscovery).FakedServerVersion = &version.Info{GitVersion: "v1.24.3"}
+
+	var report struct {
+		TargetVersion string `json:"target_version"`
+		SkewedNodes   []struct {
+			Name string `json:"name"`
+		} `json:"skewed_nodes"`
+		RemovedAPIs []struct {
+			APIVersion string `json:"api_version"`
+			Kind       string `json:"kind"`
+			Objects    []struct {
+				Name string `json:"name"`
+			} `json:"objects"`
+		} `json:"removed_apis"`
+	}
+	w := s.Get("/api/v1/clusters/" + apitest.ClusterName + "/deprecations")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body.String())
+	}
+	apitest.DecodeJSON(t, w, &report)
+	if report.TargetVersion != "1.25" {
+		t.Errorf("default target = %s, want 1.25", report.TargetVersion)
+	}
+	if len(report.SkewedNodes) != 1 || report.SkewedNodes[0].Name != "b" {
+		t.Errorf("skewed nodes = %+v, want b", report.SkewedNodes)
+	}
+	found := map[string][]string{}
+	for _, api := range report.RemovedAPIs {
+		for _, obj := range api.Objects {
+			found[api.APIVersion+" "+api.Kind] = append(found[api.APIVersion+" "+api.Kind], obj.Name)
+		}
+	}
+	// The CronJob applied as batch/v1 is fine; the PodSecurityPolicy has no replacement
+	if len(found) != 2 || len(found["batch/v1beta1 CronJob"]) != 1 || found["batch/v1beta1 CronJob"][0] != "old" ||
+		len(found["policy/v1beta1 PodSecurityPolicy"]) != 1 {
+		t.Errorf("removed APIs in use = %v", found)
+	}
+
+	// Nothing was removed in 1.24 itself
+	w = s.Get("/api/v1/clusters/" + apitest.ClusterName + "/deprecations?target=1.24")
+	apitest.DecodeJSON(t, w, &report)
+	if len(report.RemovedAPIs) != 0 {
+		t.Errorf("removed APIs for 1.24 = %+v, want none", report.RemovedAPIs)
+	}
+	if w := s.Get("/api/v1/clusters/" + apitest.ClusterName + "/deprecations?target=1.23"); w.Code != http.StatusBadRequest {
+		t.Errorf("downgrade target: status = %d, want 400", w.Code)
+	}
+}
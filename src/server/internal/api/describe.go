@@ -0,0 +1,371 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+// ==================== Describe Handlers ====================
+
+// maxOwnerChainDepth bounds the owner reference walk
+const maxOwnerChainDepth = 10
+
+// OwnerChainEntry is one controller in an object's owner chain, closest first
+type OwnerChainEntry struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	UID        string `json:"uid"`
+	Missing    bool   `json:"missing,omitempty"`
+}
+
+// ReferencedObject is a ConfigMap or Secret used by a pod spec
+type ReferencedObject struct {
+	Name     string   `json:"name"`
+	Exists   bool     `json:"exists"`
+	Optional bool     `json:"optional"`
+	Via      []string `json:"via"` // volume, env, envFrom, projected, imagePullSecret
+}
+
+// ControllingPolicy is an HPA or PDB that applies to the object
+type ControllingPolicy struct {
+	Kind   string      `json:"kind"`
+	Name   string      `json:"name"`
+	Object interface{} `json:"object"`
+}
+
+// Description aggregates an object with everything kubectl describe shows about it
+type Description struct {
+	Object     map[string]interface{} `json:"object"`
+	Events     []corev1.Event         `json:"events"`
+	OwnerChain []OwnerChainEntry      `json:"ownerChain"`
+	ConfigMaps []ReferencedObject     `json:"configMaps"`
+	Secrets    []ReferencedObject     `json:"secrets"`
+	HPAs       []ControllingPolicy    `json:"hpas"`
+	PDBs       []ControllingPolicy    `json:"pdbs"`
+	// ArgoApplication is the Argo CD Application managing the object, if any
+	ArgoApplication *ArgoApplicationRef `json:"argoApplication,omitempty"`
+	// Links are the Grafana dashboards configured for this kind of resource
+	Links []ExternalLink `json:"links,omitempty"`
+}
+
+// DescribePod returns a pod with its events, owner chain, mounted ConfigMaps/Secrets and
+// the HPAs/PDBs that control it
+func (h *Handler) DescribePod(c *gin.Context) {
+	h.describe(c, c.Param("name"), "pods", c.Param("namespace"), c.Param("pod"))
+}
+
+// DescribeResource is the kind-agnostic form of DescribePod
+// Query: namespace (required for namespaced resources)
+func (h *Handler) DescribeResource(c *gin.Context) {
+	h.describe(c, c.Param("name"), c.Param("resource"), c.Query("namespace"), c.Param("resname"))
+}
+
+func (h *Handler) describe(c *gin.Context, clusterName, resource, namespace, name string) {
+	mapping, err := h.resolveResource(clusterName, resource)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if !isNamespaced(mapping) {
+		namespace = ""
+	} else if namespace == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("namespace is required for %s", mapping.GroupVersionKind.Kind)})
+		return
+	}
+
+	client, err := h.client(c, clusterName)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	dynamicClient, err := h.dynamicClient(c, clusterName)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	ctx := context.Background()
+
+	obj, err := dynamicClient.Resource(mapping.Resource).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		log.Errorf("Failed to get %s %s: %v", mapping.GroupVersionKind.Kind, name, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	description := Description{
+		Object:     h.redactSecret(obj.Object),
+		ConfigMaps: []ReferencedObject{},
+		Secrets:    []ReferencedObject{},
+		HPAs:       []ControllingPolicy{},
+		PDBs:       []ControllingPolicy{},
+	}
+
+	// The sections below are best effort: a failure leaves that section empty
+	// instead of failing the whole description
+	if description.Events, err = eventsFor(ctx, client, namespace, string(obj.GetUID())); err != nil {
+		log.Warnf("Failed to list events for %s %s: %v", mapping.GroupVersionKind.Kind, name, err)
+	}
+	description.OwnerChain = h.ownerChain(ctx, clusterName, dynamicClient, obj)
+	description.ArgoApplication = h.argoOwner(c, clusterName, obj)
+	description.Links = h.grafanaLinks(clusterName, mapping.Resource.Resource, obj)
+
+	if namespace != "" {
+		spec, podLabels := podSpecOf(obj)
+		if spec != nil {
+			configMaps, secrets := podSpecReferences(spec)
+			description.ConfigMaps = resolveReferences(configMaps, func(n string) error {
+				_, err := client.CoreV1().ConfigMaps(namespace).Get(ctx, n, metav1.GetOptions{})
+				return err
+			})
+			description.Secrets = resolveReferences(secrets, func(n string) error {
+				_, err := client.CoreV1().Secrets(namespace).Get(ctx, n, metav1.GetOptions{})
+				return err
+			})
+			description.PDBs = pdbsFor(ctx, client, namespace, podLabels)
+		}
+		description.HPAs = hpasFor(ctx, client, namespace, obj, description.OwnerChain)
+	}
+
+	c.JSON(http.StatusOK, description)
+}
+
+// eventsFor returns the events about an object, newest first
+func eventsFor(ctx context.Context, client kubernetes.Interface, namespace, uid string) ([]corev1.Event, error) {
+	return listEventsNewestFirst(ctx, client, namespace, "involvedObject.uid="+uid)
+}
+
+// eventTime returns the most recent time an event was observed
+func eventTime(e corev1.Event) metav1.Time {
+	switch {
+	case !e.LastTimestamp.IsZero():
+		return e.LastTimestamp
+	case e.Series != nil:
+		return metav1.Time(e.Series.LastObservedTime)
+	case !e.EventTime.IsZero():
+		return metav1.Time(e.EventTime)
+	}
+	return e.FirstTimestamp
+}
+
+// ownerChain follows controller owner references up to the top-level owner
+func (h *Handler) ownerChain(ctx context.Context, clusterName string, client dynamic.Interface, obj *unstructured.Unstructured) []OwnerChainEntry {
+	chain := []OwnerChainEntry{}
+	current := obj
+
+	for depth := 0; depth < maxOwnerChainDepth; depth++ {
+		ref := metav1.GetControllerOfNoCopy(current)
+		if ref == nil {
+			break
+		}
+		entry := OwnerChainEntry{APIVersion: ref.APIVersion, Kind: ref.Kind, Name: ref.Name, UID: string(ref.UID)}
+
+		gv, err := schema.ParseGroupVersion(ref.APIVersion)
+		if err != nil {
+			entry.Missing = true
+			chain = append(chain, entry)
+			break
+		}
+		mapping, err := h.mappingFor(clusterName, gv.WithKind(ref.Kind))
+		if err != nil {
+			entry.Missing = true
+			chain = append(chain, entry)
+			break
+		}
+		namespace := ""
+		if isNamespaced(mapping) {
+			namespace = current.GetNamespace()
+		}
+
+		owner, err := client.Resource(mapping.Resource).Namespace(namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+		if err != nil || owner.GetUID() != ref.UID {
+			entry.Missing = true
+			chain = append(chain, entry)
+			break
+		}
+
+		chain = append(chain, entry)
+		current = owner
+	}
+
+	return chain
+}
+
+// podSpecOf extracts the pod spec and pod labels from a pod or from a workload's pod template
+func podSpecOf(obj *unstructured.Unstructured) (*corev1.PodSpec, map[string]string) {
+	var specPath, labelsPath []string
+	switch obj.GetKind() {
+	case "Pod":
+		specPath, labelsPath = []string{"spec"}, []string{"metadata", "labels"}
+	case "CronJob":
+		specPath = []string{"spec", "jobTemplate", "spec", "template", "spec"}
+		labelsPath = []string{"spec", "jobTemplate", "spec", "template", "metadata", "labels"}
+	default:
+		specPath = []string{"spec", "template", "spec"}
+		labelsPath = []string{"spec", "template", "metadata", "labels"}
+	}
+
+	raw, found, err := unstructured.NestedMap(obj.Object, specPath...)
+	if err != nil || !found {
+		return nil, nil
+	}
+	if _, hasContainers := raw["containers"]; !hasContainers {
+		return nil, nil
+	}
+
+	spec := &corev1.PodSpec{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(raw, spec); err != nil {
+		return nil, nil
+	}
+	podLabels, _, _ := unstructured.NestedStringMap(obj.Object, labelsPath...)
+	return spec, podLabels
+}
+
+// podSpecReferences returns the ConfigMaps and Secrets a pod spec uses, keyed by name
+func podSpecReferences(spec *corev1.PodSpec) (configMaps, secrets map[string]*ReferencedObject) {
+	configMaps = map[string]*ReferencedObject{}
+	secrets = map[string]*ReferencedObject{}
+
+	add := func(refs map[string]*ReferencedObject, name, via string, optional *bool) {
+		if name == "" {
+			return
+		}
+		ref, ok := refs[name]
+		if !ok {
+			ref = &ReferencedObject{Name: name, Optional: true}
+			refs[name] = ref
+		}
+		// A reference is optional only if every use of it is optional
+		ref.Optional = ref.Optional && optional != nil && *optional
+		for _, v := range ref.Via {
+			if v == via {
+				return
+			}
+		}
+		ref.Via = append(ref.Via, via)
+	}
+
+	for _, v := range spec.Volumes {
+		if v.ConfigMap != nil {
+			add(configMaps, v.ConfigMap.Name, "volume", v.ConfigMap.Optional)
+		}
+		if v.Secret != nil {
+			add(secrets, v.Secret.SecretName, "volume", v.Secret.Optional)
+		}
+		if v.Projected != nil {
+			for _, source := range v.Projected.Sources {
+				if source.ConfigMap != nil {
+					add(configMaps, source.ConfigMap.Name, "projected", source.ConfigMap.Optional)
+				}
+				if source.Secret != nil {
+					add(secrets, source.Secret.Name, "projected", source.Secret.Optional)
+				}
+			}
+		}
+	}
+
+	containers := append(append([]corev1.Container{}, spec.InitContainers...), spec.Containers...)
+	for _, container := range containers {
+		for _, env := range container.Env {
+			if env.ValueFrom == nil {
+				continue
+			}
+			if ref := env.ValueFrom.ConfigMapKeyRef; ref != nil {
+				add(configMaps, ref.Name, "env", ref.Optional)
+			}
+			if ref := env.ValueFrom.SecretKeyRef; ref != nil {
+				add(secrets, ref.Name, "env", ref.Optional)
+			}
+		}
+		for _, envFrom := range container.EnvFrom {
+			if envFrom.ConfigMapRef != nil {
+				add(configMaps, envFrom.ConfigMapRef.Name, "envFrom", envFrom.ConfigMapRef.Optional)
+			}
+			if envFrom.SecretRef != nil {
+				add(secrets, envFrom.SecretRef.Name, "envFrom", envFrom.SecretRef.Optional)
+			}
+		}
+	}
+
+	for _, pullSecret := range spec.ImagePullSecrets {
+		add(secrets, pullSecret.Name, "imagePullSecret", nil)
+	}
+
+	return configMaps, secrets
+}
+
+// resolveReferences checks which referenced objects exist and returns them sorted by name
+func resolveReferences(refs map[string]*ReferencedObject, get func(name string) error) []ReferencedObject {
+	result := make([]ReferencedObject, 0, len(refs))
+	for name, ref := range refs {
+		ref.Exists = get(name) == nil
+		result = append(result, *ref)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// hpasFor returns the HPAs that scale the object or one of its owners
+func hpasFor(ctx context.Context, client kubernetes.Interface, namespace string, obj *unstructured.Unstructured, chain []OwnerChainEntry) []ControllingPolicy {
+	result := []ControllingPolicy{}
+	hpas, err := client.AutoscalingV2().HorizontalPodAutoscalers(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		log.Warnf("Failed to list HPAs in %s: %v", namespace, err)
+		return result
+	}
+
+	targets := map[string]bool{obj.GetKind() + "/" + obj.GetName(): true}
+	for _, owner := range chain {
+		targets[owner.Kind+"/"+owner.Name] = true
+	}
+	for i := range hpas.Items {
+		hpa := &hpas.Items[i]
+		if targets[hpa.Spec.ScaleTargetRef.Kind+"/"+hpa.Spec.ScaleTargetRef.Name] {
+			result = append(result, ControllingPolicy{Kind: "HorizontalPodAutoscaler", Name: hpa.Name, Object: hpa})
+		}
+	}
+	return result
+}
+
+// pdbsFor returns the PodDisruptionBudgets whose selector matches the pod labels
+func pdbsFor(ctx context.Context, client kubernetes.Interface, namespace string, podLabels map[string]string) []ControllingPolicy {
+	result := []ControllingPolicy{}
+	if len(podLabels) == 0 {
+		return result
+	}
+	pdbs, err := client.PolicyV1().PodDisruptionBudgets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		log.Warnf("Failed to list PDBs in %s: %v", namespace, err)
+		return result
+	}
+
+	for i := range pdbs.Items {
+		pdb := &pdbs.Items[i]
+		selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+		if err != nil || selector.Empty() {
+			continue
+		}
+		if selector.Matches(labels.Set(podLabels)) {
+			result = append(result, ControllingPolicy{Kind: "PodDisruptionBudget", Name: pdb.Name, Object: pdb})
+		}
+	}
+	return result
+}
@@ -0,0 +1,45 @@
+package api
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestPodSpecReferences(t *testing.T) {
+	optional := true
+	spec := &corev1.PodSpec{
+		Volumes: []corev1.Volume{
+			{Name: "config", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "app-config"},
+			}}},
+			{Name: "tls", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "tls", Optional: &optional}}},
+		},
+		Containers: []corev1.Container{{
+			Name: "app",
+			Env: []corev1.EnvVar{{Name: "LEVEL", ValueFrom: &corev1.EnvVarSource{ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "app-config"}, Key: "level",
+			}}}},
+			EnvFrom: []corev1.EnvFromSource{{SecretRef: &corev1.SecretEnvSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "db"},
+			}}},
+		}},
+		ImagePullSecrets: []corev1.LocalObjectReference{{Name: "registry"}},
+	}
+
+	configMaps, secrets := podSpecReferences(spec)
+
+	if cm := configMaps["app-config"]; cm == nil || !reflect.DeepEqual(cm.Via, []string{"volume", "env"}) || cm.Optional {
+		t.Errorf("app-config = %+v, want required, via volume and env", cm)
+	}
+	if len(secrets) != 3 {
+		t.Fatalf("secrets = %d, want 3", len(secrets))
+	}
+	if s := secrets["tls"]; !s.Optional {
+		t.Errorf("tls = %+v, want optional", s)
+	}
+	if s := secrets["registry"]; s.Optional || s.Via[0] != "imagePullSecret" {
+		t.Errorf("registry = %+v, want required image pull secret", s)
+	}
+}
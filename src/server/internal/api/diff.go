@@ -0,0 +1,242 @@
+package api
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+// diffContextLines is the number of unchanged lines shown around each change
+const diffContextLines = 3
+
+// maxDiffLines bounds the LCS table; larger inputs fall back to a full replace hunk
+const maxDiffLines = 5000
+
+// diffOp is a single line operation in an edit script
+type diffOp struct {
+	kind byte // ' ', '-', '+'
+	line string
+}
+
+// unifiedDiff returns a unified diff of two texts, or "" when they are equal
+func unifiedDiff(from, to, fromName, toName string) string {
+	if from == to {
+		return ""
+	}
+
+	a := splitLines(from)
+	b := splitLines(to)
+	ops := diffLines(a, b)
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", fromName, toName)
+
+	// Group operations into hunks with surrounding context
+	i := 0
+	aLine, bLine := 1, 1
+	for i < len(ops) {
+		// Skip to the next change
+		start := i
+		for start < len(ops) && ops[start].kind == ' ' {
+			start++
+		}
+		if start == len(ops) {
+			break
+		}
+
+		hunkStart := start - diffContextLines
+		if hunkStart < i {
+			hunkStart = i
+		}
+		// Advance line counters over skipped unchanged lines
+		for k := i; k < hunkStart; k++ {
+			aLine++
+			bLine++
+		}
+
+		// Extend the hunk while changes are within 2*context of each other
+		end := start
+		for end < len(ops) {
+			if ops[end].kind != ' ' {
+				end++
+				continue
+			}
+			run := end
+			for run < len(ops) && ops[run].kind == ' ' {
+				run++
+			}
+			if run == len(ops) || run-end > 2*diffContextLines {
+				end += min(diffContextLines, run-end)
+				break
+			}
+			end = run
+		}
+
+		aCount, bCount := 0, 0
+		for _, op := range ops[hunkStart:end] {
+			if op.kind != '+' {
+				aCount++
+			}
+			if op.kind != '-' {
+				bCount++
+			}
+		}
+		fmt.Fprintf(&sb, "@@ -%d,%d +%d,%d @@\n", aLine, aCount, bLine, bCount)
+		for _, op := range ops[hunkStart:end] {
+			sb.WriteByte(op.kind)
+			sb.WriteString(op.line)
+			sb.WriteByte('\n')
+		}
+
+		aLine += aCount
+		bLine += bCount
+		i = end
+	}
+
+	return sb.String()
+}
+
+// diffLines computes a line edit script using the longest common subsequence
+func diffLines(a, b []string) []diffOp {
+	if len(a) > maxDiffLines || len(b) > maxDiffLines {
+		ops := make([]diffOp, 0, len(a)+len(b))
+		for _, l := range a {
+			ops = append(ops, diffOp{'-', l})
+		}
+		for _, l := range b {
+			ops = append(ops, diffOp{'+', l})
+		}
+		return ops
+	}
+
+	// lcs[i][j] is the LCS length of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	ops := make([]diffOp, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, diffOp{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, diffOp{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		ops = append(ops, diffOp{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		ops = append(ops, diffOp{'+', b[j]})
+	}
+	return ops
+}
+
+// splitLines splits text into lines without a trailing empty element
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// objectYAMLForDiff renders an object as YAML with noisy server-managed fields removed
+func objectYAMLForDiff(obj map[string]interface{}) (string, error) {
+	u := unstructured.Unstructured{Object: obj}
+	clean := u.DeepCopy()
+	unstructured.RemoveNestedField(clean.Object, "metadata", "managedFields")
+	unstructured.RemoveNestedField(clean.Object, "metadata", "generation")
+
+	out, err := yaml.Marshal(clean.Object)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// fieldChange is a single field-level difference between two objects
+type fieldChange struct {
+	Path string      `json:"path"`
+	Op   string      `json:"op"` // add, remove, replace
+	Old  interface{} `json:"old,omitempty"`
+	New  interface{} `json:"new,omitempty"`
+}
+
+// structuredDiff returns the field-level changes from one object to another. Maps are
+// compared key by key and lists of equal length element by element; anything else that
+// differs is reported as a replace of the whole value.
+func structuredDiff(from, to map[string]interface{}) []fieldChange {
+	changes := []fieldChange{}
+	diffValues("", from, to, &changes)
+	return changes
+}
+
+func diffValues(path string, from, to interface{}, changes *[]fieldChange) {
+	if reflect.DeepEqual(from, to) {
+		return
+	}
+
+	fromMap, fromIsMap := from.(map[string]interface{})
+	toMap, toIsMap := to.(map[string]interface{})
+	if fromIsMap && toIsMap {
+		keys := make([]string, 0, len(fromMap)+len(toMap))
+		for k := range fromMap {
+			keys = append(keys, k)
+		}
+		for k := range toMap {
+			if _, ok := fromMap[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			childPath := path + "." + k
+			oldValue, inOld := fromMap[k]
+			newValue, inNew := toMap[k]
+			switch {
+			case !inOld:
+				*changes = append(*changes, fieldChange{Path: childPath, Op: "add", New: newValue})
+			case !inNew:
+				*changes = append(*changes, fieldChange{Path: childPath, Op: "remove", Old: oldValue})
+			default:
+				diffValues(childPath, oldValue, newValue, changes)
+			}
+		}
+		return
+	}
+
+	fromList, fromIsList := from.([]interface{})
+	toList, toIsList := to.([]interface{})
+	if fromIsList && toIsList && len(fromList) == len(toList) {
+		for i := range fromList {
+			diffValues(fmt.Sprintf("%s[%d]", path, i), fromList[i], toList[i], changes)
+		}
+		return
+	}
+
+	*changes = append(*changes, fieldChange{Path: path, Op: "replace", Old: from, New: to})
+}
@@ -0,0 +1,95 @@
+package api
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		from     string
+		to       string
+		wantDiff bool
+		contains []string
+	}{
+		{
+			name:     "identical",
+			from:     "a\nb\nc\n",
+			to:       "a\nb\nc\n",
+			wantDiff: false,
+		},
+		{
+			name:     "changed line",
+			from:     "replicas: 1\nimage: nginx:1.25\n",
+			to:       "replicas: 3\nimage: nginx:1.25\n",
+			wantDiff: true,
+			contains: []string{"-replicas: 1", "+replicas: 3", " image: nginx:1.25", "@@ -1,2 +1,2 @@"},
+		},
+		{
+			name:     "added line",
+			from:     "a\nb\n",
+			to:       "a\nb\nc\n",
+			wantDiff: true,
+			contains: []string{"+c", "@@ -1,2 +1,3 @@"},
+		},
+		{
+			name:     "separate hunks",
+			from:     "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n",
+			to:       "x\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\ny\n",
+			wantDiff: true,
+			contains: []string{"@@ -1,4 +1,4 @@", "@@ -9,4 +9,4 @@"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := unifiedDiff(tt.from, tt.to, "a", "b")
+			if (got != "") != tt.wantDiff {
+				t.Fatalf("unifiedDiff() = %q, wantDiff %v", got, tt.wantDiff)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(got, want) {
+					t.Errorf("unifiedDiff() missing %q in:\n%s", want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestStructuredDiff(t *testing.T) {
+	from := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"replicas": int64(1),
+			"paused":   true,
+			"containers": []interface{}{
+				map[string]interface{}{"name": "web", "image": "nginx:1.25"},
+			},
+		},
+	}
+	to := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"replicas": int64(3),
+			"strategy": "Recreate",
+			"containers": []interface{}{
+				map[string]interface{}{"name": "web", "image": "nginx:1.27"},
+			},
+		},
+	}
+
+	got := structuredDiff(from, to)
+	want := []fieldChange{
+		{Path: ".spec.containers[0].image", Op: "replace", Old: "nginx:1.25", New: "nginx:1.27"},
+		{Path: ".spec.paused", Op: "remove", Old: true},
+		{Path: ".spec.replicas", Op: "replace", Old: int64(1), New: int64(3)},
+		{Path: ".spec.strategy", Op: "add", New: "Recreate"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("structuredDiff() = %+v, want %+v", got, want)
+	}
+
+	if changes := structuredDiff(from, from); len(changes) != 0 {
+		t.Errorf("structuredDiff() of equal objects = %+v, want none", changes)
+	}
+}
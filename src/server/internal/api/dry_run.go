@@ -0,0 +1,38 @@
+package api
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// dryRunValue returns the DryRun option for a request. With ?dryRun=true (or ?dryRun=All)
+// the API server runs admission and schema validation but does not persist the change.
+func dryRunValue(c *gin.Context) []string {
+	switch strings.ToLower(c.Query("dryRun")) {
+	case "true", "all":
+		return []string{metav1.DryRunAll}
+	}
+	return nil
+}
+
+// createOptions returns CreateOptions honouring the dryRun query parameter
+func createOptions(c *gin.Context) metav1.CreateOptions {
+	return metav1.CreateOptions{DryRun: dryRunValue(c)}
+}
+
+// updateOptions returns UpdateOptions honouring the dryRun query parameter
+func updateOptions(c *gin.Context) metav1.UpdateOptions {
+	return metav1.UpdateOptions{DryRun: dryRunValue(c)}
+}
+
+// patchOptions returns PatchOptions honouring the dryRun query parameter
+func patchOptions(c *gin.Context) metav1.PatchOptions {
+	return metav1.PatchOptions{DryRun: dryRunValue(c)}
+}
+
+// deleteOptions returns DeleteOptions honouring the dryRun query parameter
+func deleteOptions(c *gin.Context) metav1.DeleteOptions {
+	return metav1.DeleteOptions{DryRun: dryRunValue(c)}
+}
@@ -0,0 +1,95 @@
+package api_test
+
+import (
+	"net/http"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/sonnguyen/kubelens/internal/apitest"
+	"github.com/sonnguyen/kubelens/internal/audit"
+)
+
+// dryRunOf returns the DryRun option of a create, update, patch or delete action
+func dryRunOf(action k8stesting.Action) ([]string, bool) {
+	switch a := action.(type) {
+	case k8stesting.CreateActionImpl:
+		return a.CreateOptions.DryRun, true
+	case k8stesting.UpdateActionImpl:
+		return a.UpdateOptions.DryRun, true
+	case k8stesting.PatchActionImpl:
+		return a.PatchOptions.DryRun, true
+	case k8stesting.DeleteActionImpl:
+		return a.DeleteOptions.DryRun, true
+	}
+	return nil, false
+}
+
+func TestDryRun(t *testing.T) {
+	base := "/api/v1/clusters/" + apitest.ClusterName + "/namespaces/" + apitest.FixtureNamespace
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   interface{}
+		want   int
+	}{
+		{"create", http.MethodPost, base + "/limitranges", map[string]interface{}{"metadata": map[string]string{"name": "extra"}}, http.StatusCreated},
+		{"update", http.MethodPut, base + "/deployments/web", map[string]interface{}{"metadata": map[string]string{"name": "web"}}, http.StatusOK},
+		{"patch", http.MethodPatch, base + "/cronjobs/nightly/suspend", map[string]bool{"suspend": true}, http.StatusOK},
+		{"delete", http.MethodDelete, base + "/pods/web-5d8f-abcde", nil, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		for _, query := range []string{"?dryRun=true", "?dryRun=All", ""} {
+			t.Run(tt.name+query, func(t *testing.T) {
+				s := apitest.New(t, apitest.Fixtures()...)
+				s.Cluster.Client.ClearActions()
+				if w := s.Do(tt.method, tt.path+query, tt.body); w.Code != tt.want {
+					t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body.String())
+				}
+
+				writes := 0
+				for _, action := range s.Cluster.Client.Actions() {
+					dryRun, ok := dryRunOf(action)
+					if !ok {
+						continue
+					}
+					writes++
+					isDryRun := len(dryRun) == 1 && dryRun[0] == metav1.DryRunAll
+					if isDryRun != (query != "") {
+						t.Errorf("%s %s sent DryRun %v", action.GetVerb(), action.GetResource().Resource, dryRun)
+					}
+				}
+				if writes != 1 {
+					t.Errorf("made %d writes, want 1", writes)
+				}
+			})
+		}
+	}
+}
+
+func TestDryRunAudit(t *testing.T) {
+	s := apitest.New(t, apitest.Fixtures()...)
+	path := "/api/v1/clusters/" + apitest.ClusterName + "/namespaces/" + apitest.FixtureNamespace + "/cronjobs/nightly/suspend"
+
+	if w := s.Do(http.MethodPatch, path+"?dryRun=true", map[string]bool{"suspend": true}); w.Code != http.StatusOK {
+		t.Fatalf("dry-run suspend: %d %s", w.Code, w.Body.String())
+	}
+	if !auditDescribed(t, s, audit.EventAuditResourceUpdated, "[dry run] Suspended cronjob: shop/nightly") {
+		t.Error("dry run is not marked in the audit log")
+	}
+	if auditDescribed(t, s, audit.EventAuditResourceUpdated, "Suspended cronjob: shop/nightly") {
+		t.Error("dry run is audited as a real change")
+	}
+
+	// A dry run still needs the permission to make the change
+	if w := scopedRouter(s, "read")(http.MethodDelete, "/api/v1/clusters/"+apitest.ClusterName+"/namespaces/shop/pods/web-5d8f-abcde?dryRun=true", "", ""); w.Code != http.StatusForbidden {
+		t.Errorf("read-only dry-run delete: %d, want 403: %s", w.Code, w.Body.String())
+	}
+	// Failures are reported as for real requests
+	if w := s.Do(http.MethodPost, "/api/v1/clusters/"+apitest.ClusterName+"/namespaces/shop/limitranges?dryRun=true", "not json"); w.Code != http.StatusBadRequest {
+		t.Errorf("invalid dry-run create: %d, want 400", w.Code)
+	}
+}
@@ -0,0 +1,393 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+
+	"github.com/sonnguyen/kubelens/internal/diagnostics"
+	"github.com/sonnguyen/kubelens/internal/ws"
+)
+
+const (
+	// editSessionTTL is how long an edit session lives without being renewed
+	editSessionTTL = 10 * time.Minute
+
+	// maxEditSessionsPerUser bounds the number of concurrent watches a user can hold
+	maxEditSessionsPerUser = 20
+
+	// editSessionTopic is the websocket topic edit session events are published on
+	editSessionTopic = "edit_sessions"
+
+	// editSessionRewatchDelay is how long to wait before resuming a watch the API server closed
+	editSessionRewatchDelay = time.Second
+)
+
+// EditSession tracks an object opened in the YAML editor and watches it for
+// changes made by someone else while the user is editing.
+type EditSession struct {
+	ID              string    `json:"id"`
+	UserID          int       `json:"user_id"`
+	Cluster         string    `json:"cluster"`
+	Group           string    `json:"group"`
+	Version         string    `json:"version"`
+	Resource        string    `json:"resource"`
+	Namespace       string    `json:"namespace,omitempty"`
+	Name            string    `json:"name"`
+	ResourceVersion string    `json:"resourceVersion"`
+	ExpiresAt       time.Time `json:"expires_at"`
+
+	mu       sync.Mutex
+	baseYAML string
+	timer    *time.Timer
+	cancel   context.CancelFunc
+	// redactSecrets leaves the values of a Secret out of the diffs sent to the client
+	redactSecrets bool
+	// rewatch resumes watching the object from a resource version
+	rewatch func(ctx context.Context, resourceVersion string) (watch.Interface, error)
+}
+
+// EditSessionEvent is pushed to the session owner over WebSocket
+type EditSessionEvent struct {
+	Type            string `json:"type"` // edit_session.modified, edit_session.deleted, edit_session.expired
+	SessionID       string `json:"session_id"`
+	Cluster         string `json:"cluster"`
+	Namespace       string `json:"namespace,omitempty"`
+	Name            string `json:"name"`
+	ResourceVersion string `json:"resourceVersion,omitempty"`
+	Diff            string `json:"diff,omitempty"`
+}
+
+// EditSessionManager owns all active edit sessions
+type EditSessionManager struct {
+	sessions map[string]*EditSession
+	mu       sync.RWMutex
+	wsHub    *ws.Hub
+}
+
+// NewEditSessionManager creates a new edit session manager
+func NewEditSessionManager(wsHub *ws.Hub) *EditSessionManager {
+	return &EditSessionManager{
+		sessions: make(map[string]*EditSession),
+		wsHub:    wsHub,
+	}
+}
+
+// countForUser returns the number of active sessions owned by a user
+func (m *EditSessionManager) countForUser(userID int) int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	count := 0
+	for _, s := range m.sessions {
+		if s.UserID == userID {
+			count++
+		}
+	}
+	return count
+}
+
+// get returns a session owned by the given user
+func (m *EditSessionManager) get(id string, userID int) (*EditSession, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	s, ok := m.sessions[id]
+	if !ok || s.UserID != userID {
+		return nil, false
+	}
+	return s, true
+}
+
+// add registers a session and arms its expiry timer
+func (m *EditSessionManager) add(s *EditSession) {
+	m.mu.Lock()
+	m.sessions[s.ID] = s
+	m.mu.Unlock()
+
+	s.timer = time.AfterFunc(editSessionTTL, func() {
+		m.expire(s)
+	})
+}
+
+// expire tells the owner the session ended and forgets it
+func (m *EditSessionManager) expire(s *EditSession) {
+	m.notify(s, EditSessionEvent{Type: "edit_session.expired"})
+	m.remove(s.ID)
+}
+
+// remove stops the watch and forgets the session
+func (m *EditSessionManager) remove(id string) {
+	m.mu.Lock()
+	s, ok := m.sessions[id]
+	delete(m.sessions, id)
+	m.mu.Unlock()
+
+	if ok {
+		s.timer.Stop()
+		s.cancel()
+	}
+}
+
+// renew pushes the session expiry forward
+func (m *EditSessionManager) renew(s *EditSession) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.ExpiresAt = time.Now().Add(editSessionTTL)
+	s.timer.Reset(editSessionTTL)
+}
+
+// notify sends an event to the session owner
+func (m *EditSessionManager) notify(s *EditSession, event EditSessionEvent) {
+	event.SessionID = s.ID
+	event.Cluster = s.Cluster
+	event.Namespace = s.Namespace
+	event.Name = s.Name
+
+	m.wsHub.PublishToUser(s.UserID, editSessionTopic, event.Type, event)
+}
+
+// watch forwards changes to the watched object until the session ends. Watches closed by
+// the API server are resumed from the last resource version seen; when that is no longer
+// possible the session expires, so the client knows stale detection stopped.
+func (m *EditSessionManager) watch(ctx context.Context, s *EditSession, w watch.Interface) {
+	defer diagnostics.TrackWatch("edit_session")()
+
+	for {
+		resume := m.forward(ctx, s, w)
+		w.Stop()
+		if !resume {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(editSessionRewatchDelay):
+		}
+
+		s.mu.Lock()
+		resourceVersion := s.ResourceVersion
+		s.mu.Unlock()
+
+		var err error
+		w, err = s.rewatch(ctx, resourceVersion)
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Debugf("Edit session %s could not resume its watch: %v", s.ID, err)
+				m.expire(s)
+			}
+			return
+		}
+		log.Debugf("Edit session %s watch resumed from resourceVersion %s", s.ID, resourceVersion)
+	}
+}
+
+// forward sends the events of one watch to the session owner. It returns true when the
+// API server closed the watch and it should be resumed.
+func (m *EditSessionManager) forward(ctx context.Context, s *EditSession, w watch.Interface) bool {
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case event, ok := <-w.ResultChan():
+			if !ok {
+				return true
+			}
+
+			switch event.Type {
+			case watch.Modified:
+				obj, ok := event.Object.(*unstructured.Unstructured)
+				if !ok {
+					continue
+				}
+				newYAML, err := objectYAMLForDiff(diffableObject(obj, s.redactSecrets))
+				if err != nil {
+					log.Warnf("Failed to render object for edit session %s: %v", s.ID, err)
+					continue
+				}
+
+				s.mu.Lock()
+				diff := unifiedDiff(s.baseYAML, newYAML, "opened", "current")
+				s.ResourceVersion = obj.GetResourceVersion()
+				s.mu.Unlock()
+
+				m.notify(s, EditSessionEvent{
+					Type:            "edit_session.modified",
+					ResourceVersion: obj.GetResourceVersion(),
+					Diff:            diff,
+				})
+
+			case watch.Deleted:
+				m.notify(s, EditSessionEvent{Type: "edit_session.deleted"})
+				m.remove(s.ID)
+				return false
+
+			case watch.Error:
+				// Typically the resource version is too old to resume from
+				log.Debugf("Edit session %s watch failed: %v", s.ID, apierrors.FromObject(event.Object))
+				m.expire(s)
+				return false
+			}
+		}
+	}
+}
+
+// diffableObject is an object as edit session diffs show it, the values of a Secret
+// redacted with redactSecrets
+func diffableObject(obj *unstructured.Unstructured, redactSecrets bool) map[string]interface{} {
+	if !redactSecrets {
+		return obj.Object
+	}
+	redacted, _ := redactSecretValues("", obj.Object).(map[string]interface{})
+	return redacted
+}
+
+// newEditSessionID returns a random session identifier
+func newEditSessionID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// ==================== Edit Session Handlers ====================
+
+// CreateEditSession opens an edit session on an object and starts watching it.
+// The client receives edit_session.* events over /ws if the object changes.
+func (h *Handler) CreateEditSession(c *gin.Context) {
+	clusterName := c.Param("name")
+	userID := c.GetInt("user_id")
+
+	var req struct {
+		Group     string `json:"group"`
+		Version   string `json:"version" binding:"required"`
+		Resource  string `json:"resource" binding:"required"`
+		Namespace string `json:"namespace"`
+		Name      string `json:"name" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// The body names the object, so the caller's cluster and namespace scope is checked here
+	if allows, ok := scopeAllowsAction(c); ok && !allows(clusterName, req.Resource, req.Namespace, "update") {
+		c.JSON(http.StatusForbidden, gin.H{"error": fmt.Sprintf("no access to %s %s in cluster %s namespace %q", req.Resource, req.Name, clusterName, req.Namespace)})
+		return
+	}
+
+	if h.editSessions.countForUser(userID) >= maxEditSessionsPerUser {
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "too many open edit sessions"})
+		return
+	}
+
+	client, err := h.dynamicClient(c, clusterName)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	gvr := schema.GroupVersionResource{Group: req.Group, Version: req.Version, Resource: req.Resource}
+	resourceClient := client.Resource(gvr).Namespace(req.Namespace)
+
+	obj, err := resourceClient.Get(context.Background(), req.Name, metav1.GetOptions{})
+	if err != nil {
+		log.Errorf("Failed to get object for edit session: %v", err)
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	redactSecrets := isSecret(obj.Object) && !h.secretsRevealed()
+	baseYAML, err := objectYAMLForDiff(diffableObject(obj, redactSecrets))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	id, err := newEditSessionID()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create edit session"})
+		return
+	}
+
+	rewatch := func(ctx context.Context, resourceVersion string) (watch.Interface, error) {
+		return resourceClient.Watch(ctx, metav1.ListOptions{
+			FieldSelector:   fields.OneTermEqualSelector("metadata.name", req.Name).String(),
+			ResourceVersion: resourceVersion,
+		})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	w, err := rewatch(ctx, obj.GetResourceVersion())
+	if err != nil {
+		cancel()
+		log.Errorf("Failed to watch object for edit session: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	session := &EditSession{
+		ID:              id,
+		UserID:          userID,
+		Cluster:         clusterName,
+		Group:           req.Group,
+		Version:         req.Version,
+		Resource:        req.Resource,
+		Namespace:       req.Namespace,
+		Name:            req.Name,
+		ResourceVersion: obj.GetResourceVersion(),
+		ExpiresAt:       time.Now().Add(editSessionTTL),
+		baseYAML:        baseYAML,
+		cancel:          cancel,
+		redactSecrets:   redactSecrets,
+		rewatch:         rewatch,
+	}
+	h.editSessions.add(session)
+
+	c.JSON(http.StatusCreated, session)
+	go h.editSessions.watch(ctx, session, w)
+}
+
+// RenewEditSession extends an edit session (clients call this as a heartbeat)
+func (h *Handler) RenewEditSession(c *gin.Context) {
+	session, ok := h.editSessions.get(c.Param("id"), c.GetInt("user_id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "edit session not found"})
+		return
+	}
+
+	h.editSessions.renew(session)
+
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	c.JSON(http.StatusOK, session)
+}
+
+// CloseEditSession stops watching an object (editor closed or changes saved)
+func (h *Handler) CloseEditSession(c *gin.Context) {
+	session, ok := h.editSessions.get(c.Param("id"), c.GetInt("user_id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "edit session not found"})
+		return
+	}
+
+	h.editSessions.remove(session.ID)
+
+	c.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("Edit session %s closed", session.ID)})
+}
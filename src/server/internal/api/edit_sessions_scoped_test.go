@@ -0,0 +1,47 @@
+package api_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/sonnguyen/kubelens/internal/apitest"
+)
+
+func TestCreateEditSessionScoped(t *testing.T) {
+	s := apitest.New(t, apitest.Fixtures()...)
+	path := "/api/v1/clusters/" + apitest.ClusterName + "/edit-sessions"
+	web := `{"group":"apps","version":"v1","resource":"deployments","namespace":"shop","name":"web"}`
+
+	// The scope is that of the edited object, which is edited to be updated
+	if w := scopedRouter(s, "read")(http.MethodPost, path, "application/json", web); w.Code != http.StatusForbidden {
+		t.Errorf("read-only caller: %d, want 403: %s", w.Code, w.Body.String())
+	}
+
+	do := scopedRouter(s, "read", "update")
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"another namespace", `{"group":"apps","version":"v1","resource":"deployments","namespace":"kube-system","name":"web"}`},
+		{"cluster-scoped object", `{"version":"v1","resource":"nodes","name":"node-1"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := do(http.MethodPost, path, "application/json", tt.body); w.Code != http.StatusForbidden {
+				t.Errorf("status = %d, want 403: %s", w.Code, w.Body.String())
+			}
+		})
+	}
+
+	w := do(http.MethodPost, path, "application/json", web)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("inside the scope: %d, want 201: %s", w.Code, w.Body.String())
+	}
+	var session struct {
+		ID string `json:"id"`
+	}
+	apitest.DecodeJSON(t, w, &session)
+	if w := do(http.MethodDelete, "/api/v1/edit-sessions/"+session.ID, "", ""); w.Code != http.StatusOK {
+		t.Errorf("close: %d %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,68 @@
+package api
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/watch"
+
+	"github.com/sonnguyen/kubelens/internal/ws"
+)
+
+func TestEditSessionResumesWatch(t *testing.T) {
+	m := NewEditSessionManager(ws.NewHub())
+
+	first, second := watch.NewFake(), watch.NewFake()
+	resumedFrom := make(chan string, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &EditSession{
+		ID:              "session",
+		UserID:          1,
+		Name:            "web",
+		ResourceVersion: "1",
+		cancel:          cancel,
+		rewatch: func(ctx context.Context, resourceVersion string) (watch.Interface, error) {
+			resumedFrom <- resourceVersion
+			return second, nil
+		},
+	}
+	m.add(s)
+	defer m.remove(s.ID)
+	done := make(chan struct{})
+	go func() {
+		m.watch(ctx, s, first)
+		close(done)
+	}()
+
+	obj := &unstructured.Unstructured{}
+	obj.SetName("web")
+	obj.SetResourceVersion("7")
+	first.Modify(obj)
+	// The API server ends the watch; the session resumes from the last version seen
+	first.Stop()
+	select {
+	case rv := <-resumedFrom:
+		if rv != "7" {
+			t.Errorf("watch resumed from resourceVersion %q, want 7", rv)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("watch was not resumed")
+	}
+	if _, ok := m.get(s.ID, s.UserID); !ok {
+		t.Fatal("session was dropped when its watch closed")
+	}
+
+	// A version too old to resume from ends the session
+	second.Error(&metav1.Status{Status: metav1.StatusFailure, Code: 410, Reason: metav1.StatusReasonExpired})
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("watch did not stop on an error event")
+	}
+	if _, ok := m.get(s.ID, s.UserID); ok {
+		t.Error("session was kept after its watch could not be resumed")
+	}
+}
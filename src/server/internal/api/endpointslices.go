@@ -0,0 +1,209 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ==================== EndpointSlice Handlers ====================
+
+// ListEndpointSlices returns a list of endpoint slices from a cluster
+// Optional query params: namespace, service (filters by kubernetes.io/service-name)
+func (h *Handler) ListEndpointSlices(c *gin.Context) {
+	clusterName := c.Param("name")
+	namespace := c.Query("namespace")
+	serviceName := c.Query("service")
+
+	if namespace == "" {
+		namespace = metav1.NamespaceAll
+	}
+
+	client, err := h.client(c, clusterName)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	opts := metav1.ListOptions{}
+	if serviceName != "" {
+		opts.LabelSelector = discoveryv1.LabelServiceName + "=" + serviceName
+	}
+
+	slices, err := client.DiscoveryV1().EndpointSlices(namespace).List(context.Background(), opts)
+	if err != nil {
+		log.Errorf("Failed to list endpoint slices: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"endpointslices": slices.Items})
+}
+
+// GetEndpointSlice returns details of a specific endpoint slice
+func (h *Handler) GetEndpointSlice(c *gin.Context) {
+	clusterName := c.Param("name")
+	namespace := c.Param("namespace")
+	sliceName := c.Param("endpointslice")
+
+	client, err := h.client(c, clusterName)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	slice, err := client.DiscoveryV1().EndpointSlices(namespace).Get(context.Background(), sliceName, metav1.GetOptions{})
+	if err != nil {
+		log.Errorf("Failed to get endpoint slice: %v", err)
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, slice)
+}
+
+// ServiceBackend is a single address backing a Service
+type ServiceBackend struct {
+	Address     string                  `json:"address"`
+	AddressType discoveryv1.AddressType `json:"addressType"`
+	Hostname    string                  `json:"hostname,omitempty"`
+	NodeName    string                  `json:"nodeName,omitempty"`
+	Zone        string                  `json:"zone,omitempty"`
+	TargetKind  string                  `json:"targetKind,omitempty"`
+	TargetName  string                  `json:"targetName,omitempty"`
+	Serving     bool                    `json:"serving"`
+	Terminating bool                    `json:"terminating"`
+	Slice       string                  `json:"slice"`
+}
+
+// ServiceBackendPort is a port exposed by the backends of a Service
+type ServiceBackendPort struct {
+	Name        string `json:"name,omitempty"`
+	Port        int32  `json:"port"`
+	Protocol    string `json:"protocol,omitempty"`
+	AppProtocol string `json:"appProtocol,omitempty"`
+}
+
+// GetServiceBackends resolves a Service to its ready and not-ready addresses
+// by merging all EndpointSlices that belong to it
+func (h *Handler) GetServiceBackends(c *gin.Context) {
+	clusterName := c.Param("name")
+	namespace := c.Param("namespace")
+	serviceName := c.Param("service")
+
+	client, err := h.client(c, clusterName)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	if _, err := client.CoreV1().Services(namespace).Get(context.Background(), serviceName, metav1.GetOptions{}); err != nil {
+		log.Errorf("Failed to get service: %v", err)
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	slices, err := client.DiscoveryV1().EndpointSlices(namespace).List(context.Background(), metav1.ListOptions{
+		LabelSelector: discoveryv1.LabelServiceName + "=" + serviceName,
+	})
+	if err != nil {
+		log.Errorf("Failed to list endpoint slices: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ready, notReady, ports := mergeEndpointSlices(slices.Items)
+
+	c.JSON(http.StatusOK, gin.H{
+		"service":     serviceName,
+		"namespace":   namespace,
+		"clusterName": clusterName,
+		"ready":       ready,
+		"notReady":    notReady,
+		"ports":       ports,
+		"sliceCount":  len(slices.Items),
+	})
+}
+
+// mergeEndpointSlices flattens slices into ready/not-ready backends and the
+// union of ports. Addresses appearing in several slices are reported once.
+func mergeEndpointSlices(slices []discoveryv1.EndpointSlice) ([]ServiceBackend, []ServiceBackend, []ServiceBackendPort) {
+	ready := []ServiceBackend{}
+	notReady := []ServiceBackend{}
+	ports := []ServiceBackendPort{}
+	seenAddr := make(map[string]bool)
+	seenPort := make(map[string]bool)
+
+	for _, slice := range slices {
+		for _, p := range slice.Ports {
+			port := ServiceBackendPort{}
+			if p.Name != nil {
+				port.Name = *p.Name
+			}
+			if p.Port != nil {
+				port.Port = *p.Port
+			}
+			if p.Protocol != nil {
+				port.Protocol = string(*p.Protocol)
+			}
+			if p.AppProtocol != nil {
+				port.AppProtocol = *p.AppProtocol
+			}
+			key := fmt.Sprintf("%s/%s/%d", port.Name, port.Protocol, port.Port)
+			if !seenPort[key] {
+				seenPort[key] = true
+				ports = append(ports, port)
+			}
+		}
+
+		for _, ep := range slice.Endpoints {
+			// A nil ready condition means "unknown", which consumers should treat as ready
+			isReady := ep.Conditions.Ready == nil || *ep.Conditions.Ready
+
+			for _, addr := range ep.Addresses {
+				if seenAddr[addr] {
+					continue
+				}
+				seenAddr[addr] = true
+
+				backend := ServiceBackend{
+					Address:     addr,
+					AddressType: slice.AddressType,
+					Serving:     ep.Conditions.Serving == nil || *ep.Conditions.Serving,
+					Terminating: ep.Conditions.Terminating != nil && *ep.Conditions.Terminating,
+					Slice:       slice.Name,
+				}
+				if ep.Hostname != nil {
+					backend.Hostname = *ep.Hostname
+				}
+				if ep.NodeName != nil {
+					backend.NodeName = *ep.NodeName
+				}
+				if ep.Zone != nil {
+					backend.Zone = *ep.Zone
+				}
+				if ep.TargetRef != nil {
+					backend.TargetKind = ep.TargetRef.Kind
+					backend.TargetName = ep.TargetRef.Name
+				}
+
+				if isReady {
+					ready = append(ready, backend)
+				} else {
+					notReady = append(notReady, backend)
+				}
+			}
+		}
+	}
+
+	sort.Slice(ready, func(i, j int) bool { return ready[i].Address < ready[j].Address })
+	sort.Slice(notReady, func(i, j int) bool { return notReady[i].Address < notReady[j].Address })
+
+	return ready, notReady, ports
+}
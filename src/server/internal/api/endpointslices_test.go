@@ -0,0 +1,137 @@
+package api_test
+
+import (
+	"net/http"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/sonnguyen/kubelens/internal/api"
+	"github.com/sonnguyen/kubelens/internal/apitest"
+)
+
+func endpointSlice(namespace, name, service string, endpoints ...discoveryv1.Endpoint) *discoveryv1.EndpointSlice {
+	port, portName, protocol := int32(80), "http", corev1.ProtocolTCP
+	return &discoveryv1.EndpointSlice{
+		ObjectMeta:  metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: map[string]string{discoveryv1.LabelServiceName: service}},
+		AddressType: discoveryv1.AddressTypeIPv4,
+		Endpoints:   endpoints,
+		Ports:       []discoveryv1.EndpointPort{{Name: &portName, Port: &port, Protocol: &protocol}},
+	}
+}
+
+func endpoint(address string, ready *bool) discoveryv1.Endpoint {
+	return discoveryv1.Endpoint{Addresses: []string{address}, Conditions: discoveryv1.EndpointConditions{Ready: ready}}
+}
+
+func endpointSliceFixtures() []runtime.Object {
+	ready, notReady := true, false
+	return append(apitest.Fixtures(),
+		endpointSlice(apitest.FixtureNamespace, "web-abc", "web", endpoint("10.0.0.10", &ready), endpoint("10.0.0.11", &notReady)),
+		// The same address in a second slice, and one whose readiness is unknown
+		endpointSlice(apitest.FixtureNamespace, "web-def", "web", endpoint("10.0.0.10", &ready), endpoint("10.0.0.12", nil)),
+		endpointSlice(apitest.FixtureNamespace, "db-abc", "db", endpoint("10.0.0.20", &ready)),
+		endpointSlice("kube-system", "dns-abc", "kube-dns", endpoint("10.0.1.10", &ready)),
+	)
+}
+
+func TestEndpointSlices(t *testing.T) {
+	s := apitest.New(t, endpointSliceFixtures()...)
+	base := "/api/v1/clusters/" + apitest.ClusterName
+
+	var list struct {
+		EndpointSlices []discoveryv1.EndpointSlice `json:"endpointslices"`
+	}
+	w := s.Get(base + "/endpointslices?namespace=" + apitest.FixtureNamespace + "&service=web")
+	if w.Code != http.StatusOK {
+		t.Fatalf("list: %d %s", w.Code, w.Body.String())
+	}
+	apitest.DecodeJSON(t, w, &list)
+	if len(list.EndpointSlices) != 2 {
+		t.Errorf("got %d slices of service web, want 2", len(list.EndpointSlices))
+	}
+
+	var slice discoveryv1.EndpointSlice
+	w = s.Get(base + "/namespaces/" + apitest.FixtureNamespace + "/endpointslices/web-abc")
+	if w.Code != http.StatusOK {
+		t.Fatalf("get: %d %s", w.Code, w.Body.String())
+	}
+	apitest.DecodeJSON(t, w, &slice)
+	if slice.Name != "web-abc" || len(slice.Endpoints) != 2 {
+		t.Errorf("slice = %s with %d endpoints, want web-abc with 2", slice.Name, len(slice.Endpoints))
+	}
+	if w := s.Get(base + "/namespaces/" + apitest.FixtureNamespace + "/endpointslices/missing"); w.Code != http.StatusNotFound {
+		t.Errorf("unknown slice: %d, want 404", w.Code)
+	}
+	if w := s.Get("/api/v1/clusters/missing/endpointslices"); w.Code != http.StatusNotFound {
+		t.Errorf("unknown cluster: %d, want 404", w.Code)
+	}
+}
+
+func TestServiceBackends(t *testing.T) {
+	s := apitest.New(t, endpointSliceFixtures()...)
+	base := "/api/v1/clusters/" + apitest.ClusterName + "/namespaces/" + apitest.FixtureNamespace
+
+	var backends struct {
+		Ready      []api.ServiceBackend     `json:"ready"`
+		NotReady   []api.ServiceBackend     `json:"notReady"`
+		Ports      []api.ServiceBackendPort `json:"ports"`
+		SliceCount int                      `json:"sliceCount"`
+	}
+	w := s.Get(base + "/services/web/backends")
+	if w.Code != http.StatusOK {
+		t.Fatalf("backends: %d %s", w.Code, w.Body.String())
+	}
+	apitest.DecodeJSON(t, w, &backends)
+	if backends.SliceCount != 2 {
+		t.Errorf("sliceCount = %d, want 2", backends.SliceCount)
+	}
+	// 10.0.0.10 is listed once, and unknown readiness counts as ready
+	if len(backends.Ready) != 2 || backends.Ready[0].Address != "10.0.0.10" || backends.Ready[1].Address != "10.0.0.12" {
+		t.Errorf("ready = %+v, want 10.0.0.10 and 10.0.0.12", backends.Ready)
+	}
+	if len(backends.NotReady) != 1 || backends.NotReady[0].Address != "10.0.0.11" {
+		t.Errorf("notReady = %+v, want 10.0.0.11", backends.NotReady)
+	}
+	if len(backends.Ports) != 1 || backends.Ports[0].Port != 80 || backends.Ports[0].Name != "http" {
+		t.Errorf("ports = %+v, want http/80 once", backends.Ports)
+	}
+
+	if w := s.Get(base + "/services/missing/backends"); w.Code != http.StatusNotFound {
+		t.Errorf("unknown service: %d, want 404", w.Code)
+	}
+}
+
+func TestEndpointSlicesScoped(t *testing.T) {
+	s := apitest.New(t, endpointSliceFixtures()...)
+	do := scopedRouter(s, "read")
+	base := "/api/v1/clusters/" + apitest.ClusterName
+
+	// Slices of other namespaces are dropped from the list
+	w := do(http.MethodGet, base+"/endpointslices", "", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("list: %d %s", w.Code, w.Body.String())
+	}
+	var list struct {
+		EndpointSlices []discoveryv1.EndpointSlice `json:"endpointslices"`
+	}
+	apitest.DecodeJSON(t, w, &list)
+	if len(list.EndpointSlices) != 3 {
+		t.Errorf("got %d slices, want the 3 of %s", len(list.EndpointSlices), apitest.FixtureNamespace)
+	}
+	for _, slice := range list.EndpointSlices {
+		if slice.Namespace != apitest.FixtureNamespace {
+			t.Errorf("slice %s/%s is outside the scope", slice.Namespace, slice.Name)
+		}
+	}
+
+	if w := do(http.MethodGet, base+"/namespaces/kube-system/endpointslices/dns-abc", "", ""); w.Code != http.StatusForbidden {
+		t.Errorf("slice in another namespace: %d, want 403", w.Code)
+	}
+	if w := do(http.MethodGet, base+"/namespaces/kube-system/services/kube-dns/backends", "", ""); w.Code != http.StatusForbidden {
+		t.Errorf("backends in another namespace: %d, want 403", w.Code)
+	}
+}
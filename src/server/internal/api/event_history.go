@@ -0,0 +1,61 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+// maxEventHistory bounds the events returned by one history request
+const maxEventHistory = 1000
+
+// ListEventHistory handles GET /clusters/:name/events/history, the events recorded by
+// the event watcher, which outlive the short retention of the Kubernetes API.
+// Query params: namespace, type (Normal or Warning), kind, resname, since (RFC 3339 time
+// or a duration such as 6h), limit
+func (h *Handler) ListEventHistory(c *gin.Context) {
+	filters := db.ClusterEventFilters{
+		ClusterName: c.Param("name"),
+		Namespace:   c.Query("namespace"),
+		Type:        c.Query("type"),
+		Kind:        c.Query("kind"),
+		Name:        c.Query("resname"),
+		Limit:       200,
+	}
+
+	if since := c.Query("since"); since != "" {
+		if d, err := time.ParseDuration(since); err == nil && d > 0 {
+			filters.Since = time.Now().Add(-d)
+		} else if t, err := time.Parse(time.RFC3339, since); err == nil {
+			filters.Since = t
+		} else {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "since must be an RFC 3339 time or a duration such as 6h"})
+			return
+		}
+	}
+	if limit := c.Query("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive number"})
+			return
+		}
+		if n > maxEventHistory {
+			n = maxEventHistory
+		}
+		filters.Limit = n
+	}
+
+	events, err := h.db.ListClusterEvents(filters)
+	if err != nil {
+		log.Errorf("Failed to list event history: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list event history"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"events": events})
+}
@@ -0,0 +1,488 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+const (
+	// maxFavorites bounds the favorites of a user
+	maxFavorites = 100
+	// favoriteParallelism bounds the pinned objects fetched at the same time by
+	// GetFavoritesStatus
+	favoriteParallelism = 8
+	// favoriteTimeout bounds the fetch of one pinned object, so an unreachable cluster
+	// reports its favorites as unknown instead of holding up the widget
+	favoriteTimeout = 10 * time.Second
+)
+
+// Health of a pinned object, as reported by GET /api/v1/favorites/status
+const (
+	healthHealthy     = "Healthy"
+	healthProgressing = "Progressing"
+	healthDegraded    = "Degraded"
+	healthMissing     = "Missing"
+	healthUnknown     = "Unknown"
+)
+
+// favoriteRequest is the body of CreateFavorite
+type favoriteRequest struct {
+	Cluster   string `json:"cluster" binding:"required"`
+	Kind      string `json:"kind" binding:"required"` // kind, plural, short name or resource.group
+	Namespace string `json:"namespace"`
+	Name      string `json:"name" binding:"required"`
+	Pinned    bool   `json:"pinned"`
+}
+
+// favoriteUpdate is the body of UpdateFavorite; fields left out are unchanged
+type favoriteUpdate struct {
+	Pinned   *bool `json:"pinned"`
+	Position *int  `json:"position"` // 0 moves the favorite first
+}
+
+// FavoriteStatus is a pinned favorite with the live health of its object
+type FavoriteStatus struct {
+	*db.Favorite
+	Health string `json:"health"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// ownFavorite returns the caller's favorite of the given ID, writing the error response
+// otherwise. Favorites of other users do not exist for the caller.
+func (h *Handler) ownFavorite(c *gin.Context, idParam string) (*db.Favorite, bool) {
+	userID, orgID, ok := savedSearchCaller(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return nil, false
+	}
+	id, err := strconv.ParseUint(idParam, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid favorite ID"})
+		return nil, false
+	}
+	favorite, err := h.db.GetFavorite(uint(id))
+	if err != nil || favorite.UserID != userID || (orgID != 0 && (favorite.OrganizationID == nil || *favorite.OrganizationID != orgID)) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Favorite not found"})
+		return nil, false
+	}
+	return favorite, true
+}
+
+// scopeAllows returns the check of the caller's cluster and namespace scope set by the
+// scope middleware, one allowing everything when the caller is not restricted
+func scopeAllows(c *gin.Context) func(cluster, resource, namespace string) bool {
+	if value, ok := c.Get("scope_allows"); ok {
+		if allows, ok := value.(func(cluster, resource, namespace string) bool); ok {
+			return allows
+		}
+	}
+	return func(cluster, resource, namespace string) bool { return true }
+}
+
+// favoriteAllowed reports whether the caller's cluster and namespace scope covers a
+// favorite's object
+func favoriteAllowed(c *gin.Context, favorite *db.Favorite) bool {
+	return scopeAllows(c)(favorite.ClusterName, favorite.Resource, favorite.Namespace)
+}
+
+// ListFavorites handles GET /api/v1/favorites: the caller's favorites, in dashboard order
+func (h *Handler) ListFavorites(c *gin.Context) {
+	userID, orgID, ok := savedSearchCaller(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	favorites, err := h.db.ListFavorites(userID, orgID)
+	if err != nil {
+		log.Errorf("Failed to list favorites: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve favorites"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"favorites": favorites})
+}
+
+// CreateFavorite handles POST /api/v1/favorites. The kind is resolved against the
+// cluster and stored as its canonical kind and group; the object itself need not exist.
+// Adding an object that already is a favorite returns the existing one.
+func (h *Handler) CreateFavorite(c *gin.Context) {
+	userID, orgID, ok := savedSearchCaller(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	var req favoriteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || len(req.Name) > 253 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name must be 1 to 253 characters"})
+		return
+	}
+
+	if _, err := h.clusterManager.GetClient(req.Cluster); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Cluster not found"})
+		return
+	}
+	mapping, err := h.resolveResource(req.Cluster, req.Kind)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !isNamespaced(mapping) {
+		req.Namespace = ""
+	} else if req.Namespace == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("namespace is required for %s", mapping.GroupVersionKind.Kind)})
+		return
+	}
+
+	favorite := &db.Favorite{
+		UserID:      userID,
+		ClusterName: req.Cluster,
+		Group:       mapping.Resource.Group,
+		Resource:    mapping.Resource.Resource,
+		Kind:        mapping.GroupVersionKind.Kind,
+		Namespace:   req.Namespace,
+		Name:        req.Name,
+		Pinned:      req.Pinned,
+	}
+	if !favoriteAllowed(c, favorite) {
+		c.JSON(http.StatusForbidden, gin.H{"error": fmt.Sprintf("no access to %s in cluster %s namespace %q", favorite.Resource, favorite.ClusterName, favorite.Namespace)})
+		return
+	}
+
+	existing, err := h.db.FindFavorite(userID, favorite.ClusterName, favorite.Group, favorite.Kind, favorite.Namespace, favorite.Name)
+	if err != nil {
+		log.Errorf("Failed to create favorite: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create favorite"})
+		return
+	}
+	if existing != nil {
+		c.JSON(http.StatusOK, existing)
+		return
+	}
+	count, err := h.db.CountFavorites(userID)
+	if err != nil {
+		log.Errorf("Failed to create favorite: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create favorite"})
+		return
+	}
+	if count >= maxFavorites {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": fmt.Sprintf("at most %d favorites are kept per user", maxFavorites)})
+		return
+	}
+
+	// New favorites go last on the dashboard
+	favorite.Position = int(count)
+	if orgID != 0 {
+		favorite.OrganizationID = &orgID
+	}
+	if err := h.db.CreateFavorite(favorite); err != nil {
+		log.Errorf("Failed to create favorite: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create favorite"})
+		return
+	}
+	c.JSON(http.StatusCreated, favorite)
+}
+
+// UpdateFavorite handles PUT /api/v1/favorites/:id, which pins, unpins or moves a favorite
+func (h *Handler) UpdateFavorite(c *gin.Context) {
+	favorite, ok := h.ownFavorite(c, c.Param("id"))
+	if !ok {
+		return
+	}
+	var req favoriteUpdate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+	if req.Position != nil && *req.Position < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "position must not be negative"})
+		return
+	}
+	if req.Pinned != nil {
+		favorite.Pinned = *req.Pinned
+		if err := h.db.UpdateFavorite(favorite); err != nil {
+			log.Errorf("Failed to update favorite: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update favorite"})
+			return
+		}
+	}
+	// Moving a favorite shifts the ones after it down
+	if req.Position != nil {
+		if err := h.db.MoveFavorite(favorite, *req.Position); err != nil {
+			log.Errorf("Failed to move favorite: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update favorite"})
+			return
+		}
+	}
+	c.JSON(http.StatusOK, favorite)
+}
+
+// DeleteFavorite handles DELETE /api/v1/favorites/:id
+func (h *Handler) DeleteFavorite(c *gin.Context) {
+	favorite, ok := h.ownFavorite(c, c.Param("id"))
+	if !ok {
+		return
+	}
+	if err := h.db.DeleteFavorite(favorite.ID); err != nil {
+		log.Errorf("Failed to delete favorite: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete favorite"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Favorite deleted"})
+}
+
+// GetFavoritesStatus handles GET /api/v1/favorites/status, for the dashboard widget: the
+// live health of each of the caller's pinned favorites (all favorites with all=true).
+// Objects are fetched concurrently; one that is gone is Missing, and one that cannot be
+// fetched (unreachable cluster, no access) is Unknown with the reason.
+func (h *Handler) GetFavoritesStatus(c *gin.Context) {
+	userID, orgID, ok := savedSearchCaller(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	favorites, err := h.db.ListFavorites(userID, orgID)
+	if err != nil {
+		log.Errorf("Failed to list favorites: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve favorites"})
+		return
+	}
+
+	statuses := []FavoriteStatus{}
+	for _, favorite := range favorites {
+		if favorite.Pinned || c.Query("all") == "true" {
+			statuses = append(statuses, FavoriteStatus{Favorite: favorite})
+		}
+	}
+	// Resolve the impersonated identity once, before the workers share the context
+	h.identity(c)
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, favoriteParallelism)
+	for i := range statuses {
+		wg.Add(1)
+		go func(status *FavoriteStatus) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			status.Health, status.Reason = h.favoriteHealth(c, status.Favorite)
+		}(&statuses[i])
+	}
+	wg.Wait()
+
+	summary := map[string]int{healthHealthy: 0, healthProgressing: 0, healthDegraded: 0, healthMissing: 0, healthUnknown: 0}
+	for _, status := range statuses {
+		summary[status.Health]++
+	}
+	c.JSON(http.StatusOK, gin.H{"favorites": statuses, "summary": summary})
+}
+
+// favoriteHealth fetches the object of a favorite and returns its health and the reason
+func (h *Handler) favoriteHealth(c *gin.Context, favorite *db.Favorite) (string, string) {
+	if !favoriteAllowed(c, favorite) {
+		return healthUnknown, "no access"
+	}
+	resource := favorite.Resource
+	if favorite.Group != "" {
+		resource += "." + favorite.Group
+	}
+	mapping, err := h.resolveResource(favorite.ClusterName, resource)
+	if err != nil {
+		return healthUnknown, err.Error()
+	}
+	dynamicClient, err := h.dynamicClient(c, favorite.ClusterName)
+	if err != nil {
+		return healthUnknown, err.Error()
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), favoriteTimeout)
+	defer cancel()
+
+	var obj *unstructured.Unstructured
+	if isNamespaced(mapping) {
+		obj, err = dynamicClient.Resource(mapping.Resource).Namespace(favorite.Namespace).Get(ctx, favorite.Name, metav1.GetOptions{})
+	} else {
+		obj, err = dynamicClient.Resource(mapping.Resource).Get(ctx, favorite.Name, metav1.GetOptions{})
+	}
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return healthMissing, fmt.Sprintf("%s %s not found", favorite.Kind, favorite.Name)
+		}
+		if ctx.Err() == context.DeadlineExceeded {
+			return healthUnknown, fmt.Sprintf("timed out after %s", favoriteTimeout)
+		}
+		return healthUnknown, err.Error()
+	}
+	return objectHealth(obj)
+}
+
+// objectHealth assesses the health of an object from its status, with kind-specific rules
+// for the built-in workloads, nodes and claims. Other objects follow their Ready condition
+// or their phase, and are Healthy when they have neither.
+func objectHealth(obj *unstructured.Unstructured) (string, string) {
+	if obj.GetDeletionTimestamp() != nil {
+		return healthProgressing, "terminating"
+	}
+	switch obj.GetObjectKind().GroupVersionKind().GroupKind().String() {
+	case "Pod":
+		return podHealth(obj)
+	case "Deployment.apps", "StatefulSet.apps", "ReplicaSet.apps":
+		return replicasHealth(obj)
+	case "DaemonSet.apps":
+		desired, _, _ := unstructured.NestedInt64(obj.Object, "status", "desiredNumberScheduled")
+		available, _, _ := unstructured.NestedInt64(obj.Object, "status", "numberAvailable")
+		updated, _, _ := unstructured.NestedInt64(obj.Object, "status", "updatedNumberScheduled")
+		return rolloutHealth(obj, desired, available, updated)
+	case "Job.batch":
+		if reason, ok := conditionTrue(obj, "Failed"); ok {
+			return healthDegraded, reason
+		}
+		if _, ok := conditionTrue(obj, "Complete"); ok {
+			return healthHealthy, ""
+		}
+		return healthProgressing, "running"
+	case "Node":
+		if _, ok := conditionTrue(obj, "Ready"); !ok {
+			return healthDegraded, "node is not ready"
+		}
+		return healthHealthy, ""
+	case "PersistentVolumeClaim":
+		switch phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase"); phase {
+		case "Bound":
+			return healthHealthy, ""
+		case "Lost":
+			return healthDegraded, "claim lost its volume"
+		default:
+			return healthProgressing, strings.ToLower(phase)
+		}
+	}
+
+	for _, c := range conditionsOf(obj) {
+		if c["type"] != "Ready" {
+			continue
+		}
+		if c["status"] == "True" {
+			return healthHealthy, ""
+		}
+		message, _ := c["message"].(string)
+		return healthDegraded, message
+	}
+	switch phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase"); strings.ToLower(phase) {
+	case "failed", "error":
+		return healthDegraded, phase
+	case "pending", "progressing", "provisioning":
+		return healthProgressing, phase
+	}
+	return healthHealthy, ""
+}
+
+// podHealth is the health of a pod: failing containers degrade it, pods that are not yet
+// ready are progressing
+func podHealth(obj *unstructured.Unstructured) (string, string) {
+	phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase")
+	switch phase {
+	case "Succeeded":
+		return healthHealthy, ""
+	case "Failed":
+		reason, _, _ := unstructured.NestedString(obj.Object, "status", "reason")
+		return healthDegraded, strings.TrimSpace("failed " + reason)
+	}
+	statuses, _, _ := unstructured.NestedSlice(obj.Object, "status", "containerStatuses")
+	for _, s := range statuses {
+		status, ok := s.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		reason, _, _ := unstructured.NestedString(status, "state", "waiting", "reason")
+		switch reason {
+		case "CrashLoopBackOff", "ImagePullBackOff", "ErrImagePull", "CreateContainerConfigError", "InvalidImageName":
+			return healthDegraded, reason
+		}
+	}
+	if _, ok := conditionTrue(obj, "Ready"); !ok {
+		return healthProgressing, "containers are not ready"
+	}
+	return healthHealthy, ""
+}
+
+// replicasHealth is the health of a Deployment, StatefulSet or ReplicaSet
+func replicasHealth(obj *unstructured.Unstructured) (string, string) {
+	desired, found, _ := unstructured.NestedInt64(obj.Object, "spec", "replicas")
+	if !found {
+		desired = 1
+	}
+	available, _, _ := unstructured.NestedInt64(obj.Object, "status", "availableReplicas")
+	if obj.GetKind() == "StatefulSet" {
+		available, _, _ = unstructured.NestedInt64(obj.Object, "status", "readyReplicas")
+	}
+	updated, found, _ := unstructured.NestedInt64(obj.Object, "status", "updatedReplicas")
+	if !found || obj.GetKind() == "ReplicaSet" {
+		updated = desired
+	}
+	for _, c := range conditionsOf(obj) {
+		if c["type"] == "Progressing" && c["status"] == "False" && c["reason"] == "ProgressDeadlineExceeded" {
+			return healthDegraded, "rollout exceeded its progress deadline"
+		}
+	}
+	return rolloutHealth(obj, desired, available, updated)
+}
+
+// rolloutHealth compares the available and updated replicas of a workload to those it
+// wants: none available is Degraded, fewer or a rollout under way is Progressing
+func rolloutHealth(obj *unstructured.Unstructured, desired, available, updated int64) (string, string) {
+	observed, _, _ := unstructured.NestedInt64(obj.Object, "status", "observedGeneration")
+	switch {
+	case desired > 0 && available == 0:
+		return healthDegraded, fmt.Sprintf("0/%d available", desired)
+	case observed < obj.GetGeneration() || updated < desired:
+		return healthProgressing, "rollout in progress"
+	case available < desired:
+		return healthProgressing, fmt.Sprintf("%d/%d available", available, desired)
+	}
+	return healthHealthy, ""
+}
+
+// conditionsOf returns the status conditions of an object
+func conditionsOf(obj *unstructured.Unstructured) []map[string]interface{} {
+	items, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	conditions := make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
+		if condition, ok := item.(map[string]interface{}); ok {
+			conditions = append(conditions, condition)
+		}
+	}
+	return conditions
+}
+
+// conditionTrue reports whether a status condition of an object is True, with its
+// message (or reason) for the caller to report
+func conditionTrue(obj *unstructured.Unstructured, conditionType string) (string, bool) {
+	for _, c := range conditionsOf(obj) {
+		if c["type"] != conditionType {
+			continue
+		}
+		message, _ := c["message"].(string)
+		if message == "" {
+			message, _ = c["reason"].(string)
+		}
+		return message, c["status"] == "True"
+	}
+	return "", false
+}
@@ -0,0 +1,108 @@
+package api_test
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/sonnguyen/kubelens/internal/apitest"
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+func TestFavorites(t *testing.T) {
+	crashing := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker", Namespace: apitest.FixtureNamespace},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:  "worker",
+				State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+			}},
+		},
+	}
+	s := apitest.New(t, append(apitest.Fixtures(), crashing)...)
+
+	create := func(body map[string]interface{}) db.Favorite {
+		t.Helper()
+		w := s.Do(http.MethodPost, "/api/v1/favorites", body)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("create %v: %d %s", body, w.Code, w.Body.String())
+		}
+		var favorite db.Favorite
+		apitest.DecodeJSON(t, w, &favorite)
+		return favorite
+	}
+	ns := apitest.FixtureNamespace
+	pod := create(map[string]interface{}{"cluster": "test", "kind": "po", "namespace": ns, "name": "web-5d8f-abcde", "pinned": true})
+	if pod.Kind != "Pod" || pod.Resource != "pods" || pod.Group != "" {
+		t.Errorf("favorite kind = %q %q %q, want the canonical Pod", pod.Kind, pod.Resource, pod.Group)
+	}
+	create(map[string]interface{}{"cluster": "test", "kind": "Node", "namespace": "ignored", "name": "node-1", "pinned": true})
+	create(map[string]interface{}{"cluster": "test", "kind": "pods", "namespace": ns, "name": "worker", "pinned": true})
+	gone := create(map[string]interface{}{"cluster": "test", "kind": "deployments.apps", "namespace": ns, "name": "gone", "pinned": true})
+	unpinned := create(map[string]interface{}{"cluster": "test", "kind": "deploy", "namespace": ns, "name": "web"})
+
+	// Adding a favorite again returns the existing one
+	w := s.Do(http.MethodPost, "/api/v1/favorites", map[string]interface{}{"cluster": "test", "kind": "Pod", "namespace": ns, "name": "web-5d8f-abcde"})
+	if w.Code != http.StatusOK {
+		t.Errorf("duplicate favorite: %d, want 200", w.Code)
+	}
+	for _, body := range []map[string]interface{}{
+		{"cluster": "test", "kind": "widgets", "namespace": ns, "name": "x"},
+		{"cluster": "test", "kind": "pods", "name": "no-namespace"},
+		{"cluster": "test", "kind": "pods", "namespace": ns},
+	} {
+		if w := s.Do(http.MethodPost, "/api/v1/favorites", body); w.Code != http.StatusBadRequest {
+			t.Errorf("create %v: %d, want 400", body, w.Code)
+		}
+	}
+	if w := s.Do(http.MethodPost, "/api/v1/favorites", map[string]interface{}{"cluster": "nope", "kind": "pods", "namespace": ns, "name": "x"}); w.Code != http.StatusNotFound {
+		t.Errorf("unknown cluster: %d, want 404", w.Code)
+	}
+
+	var status struct {
+		Favorites []struct {
+			Name   string `json:"name"`
+			Health string `json:"health"`
+			Reason string `json:"reason"`
+		} `json:"favorites"`
+		Summary map[string]int `json:"summary"`
+	}
+	apitest.DecodeJSON(t, s.Get("/api/v1/favorites/status"), &status)
+	want := map[string]string{"web-5d8f-abcde": "Healthy", "node-1": "Healthy", "worker": "Degraded", "gone": "Missing"}
+	if len(status.Favorites) != len(want) {
+		t.Fatalf("status of %d favorites, want the %d pinned: %+v", len(status.Favorites), len(want), status.Favorites)
+	}
+	for _, f := range status.Favorites {
+		if f.Health != want[f.Name] {
+			t.Errorf("health of %s = %s (%s), want %s", f.Name, f.Health, f.Reason, want[f.Name])
+		}
+	}
+	if status.Summary["Healthy"] != 2 || status.Summary["Degraded"] != 1 || status.Summary["Missing"] != 1 {
+		t.Errorf("summary = %v", status.Summary)
+	}
+
+	// Pinning and moving a favorite
+	id := strconv.Itoa(int(unpinned.ID))
+	if w := s.Do(http.MethodPut, "/api/v1/favorites/"+id, map[string]interface{}{"pinned": true, "position": 0}); w.Code != http.StatusOK {
+		t.Fatalf("update: %d %s", w.Code, w.Body.String())
+	}
+	var list struct {
+		Favorites []db.Favorite `json:"favorites"`
+	}
+	apitest.DecodeJSON(t, s.Get("/api/v1/favorites"), &list)
+	if len(list.Favorites) != 5 || list.Favorites[0].ID != unpinned.ID || !list.Favorites[0].Pinned {
+		t.Errorf("favorites = %+v, want the pinned deployment first", list.Favorites)
+	}
+
+	id = strconv.Itoa(int(gone.ID))
+	if w := s.Do(http.MethodDelete, "/api/v1/favorites/"+id, nil); w.Code != http.StatusOK {
+		t.Errorf("delete: %d %s", w.Code, w.Body.String())
+	}
+	if w := s.Do(http.MethodDelete, "/api/v1/favorites/"+id, nil); w.Code != http.StatusNotFound {
+		t.Errorf("delete again: %d, want 404", w.Code)
+	}
+}
@@ -0,0 +1,55 @@
+package api_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/sonnguyen/kubelens/internal/apitest"
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+func TestFeatureGatedRoutes(t *testing.T) {
+	s := apitest.New(t)
+	if err := s.DB.CreateCluster(&db.Cluster{Name: apitest.ClusterName, AuthType: "token", AuthConfig: db.JSON("{}"), Enabled: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		flag   string
+		method string
+		path   string
+		body   interface{}
+	}{
+		{flag: "impersonation", method: http.MethodPatch, path: "/api/v1/clusters/" + apitest.ClusterName + "/impersonation", body: map[string]bool{"enabled": true}},
+		{flag: "search_index", method: http.MethodGet, path: "/api/v1/search?q=" + apitest.ClusterName},
+	}
+	for _, tt := range tests {
+		t.Run(tt.flag, func(t *testing.T) {
+			// Unknown flags are disabled, and the route looks like one that does not exist
+			unknown := s.Do(tt.method, "/api/v1/clusters/"+apitest.ClusterName+"/no-such-route", tt.body)
+			w := s.Do(tt.method, tt.path, tt.body)
+			if w.Code != http.StatusNotFound {
+				t.Errorf("without the flag: %d %s, want 404", w.Code, w.Body.String())
+			}
+			if w.Body.String() != unknown.Body.String() || w.Header().Get("Content-Type") != unknown.Header().Get("Content-Type") {
+				t.Errorf("without the flag: body %q, want the unknown route body %q", w.Body.String(), unknown.Body.String())
+			}
+
+			flag := &db.FeatureFlag{Key: tt.flag, Enabled: false, GroupIDs: db.JSON("[]")}
+			if err := s.DB.UpsertFeatureFlag(flag); err != nil {
+				t.Fatal(err)
+			}
+			if w := s.Do(tt.method, tt.path, tt.body); w.Code != http.StatusNotFound {
+				t.Errorf("with the flag disabled: %d %s, want 404", w.Code, w.Body.String())
+			}
+
+			flag.Enabled = true
+			if err := s.DB.UpsertFeatureFlag(flag); err != nil {
+				t.Fatal(err)
+			}
+			if w := s.Do(tt.method, tt.path, tt.body); w.Code != http.StatusOK {
+				t.Errorf("with the flag enabled: %d %s, want 200", w.Code, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// fleetParallelism bounds the clusters listed at the same time by a fleet view
+	fleetParallelism = 8
+	// fleetClusterTimeout bounds the list of one cluster, so a slow cluster is reported
+	// as failed instead of holding up the whole view
+	fleetClusterTimeout = 20 * time.Second
+)
+
+// FleetItem is an object of a fleet view, tagged with the cluster it was listed from
+type FleetItem struct {
+	Cluster string                 `json:"cluster"`
+	Object  map[string]interface{} `json:"object"`
+}
+
+// FleetError reports a cluster a fleet view could not list
+type FleetError struct {
+	Cluster string `json:"cluster"`
+	Error   string `json:"error"`
+}
+
+// FleetList is a resource listed across clusters. Clusters holds the clusters queried;
+// the ones that failed are also in Errors and contribute no items.
+type FleetList struct {
+	Resource string       `json:"resource"`
+	Clusters []string     `json:"clusters"`
+	Items    []FleetItem  `json:"items"`
+	Errors   []FleetError `json:"errors"`
+}
+
+// ListFleetResources lists a resource type (pods, deployments, a CRD plural...) across
+// all enabled clusters at once, tagging each object with its cluster. Clusters are
+// listed concurrently; a cluster that fails or times out is reported in errors while
+// the others still return their items.
+// Query: namespace, labelSelector, fieldSelector, cluster_selector, cluster_group
+func (h *Handler) ListFleetResources(c *gin.Context) {
+	resource := c.Param("resource")
+
+	selected, err := h.clusterFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	dbClusters, err := h.db.ListEnabledClusters()
+	if err != nil {
+		log.Errorf("Failed to list clusters: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	// Clusters outside the caller's scope are not queried at all
+	inScope, _ := c.Get("scope_clusters")
+	allowed, _ := inScope.(func(name string) bool)
+
+	list := FleetList{Resource: resource, Clusters: []string{}, Items: []FleetItem{}, Errors: []FleetError{}}
+	for _, dbCluster := range dbClusters {
+		if (selected == nil || selected(dbCluster.Name)) && (allowed == nil || allowed(dbCluster.Name)) {
+			list.Clusters = append(list.Clusters, dbCluster.Name)
+		}
+	}
+	sort.Strings(list.Clusters)
+
+	opts := metav1.ListOptions{LabelSelector: c.Query("labelSelector"), FieldSelector: c.Query("fieldSelector")}
+	namespace := c.Query("namespace")
+	// Resolve the impersonated identity once, before the workers share the context
+	h.identity(c)
+
+	items := make([][]unstructured.Unstructured, len(list.Clusters))
+	errs := make([]error, len(list.Clusters))
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, fleetParallelism)
+	for i, clusterName := range list.Clusters {
+		wg.Add(1)
+		go func(i int, clusterName string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			items[i], errs[i] = h.listFleetCluster(c, clusterName, resource, namespace, opts)
+		}(i, clusterName)
+	}
+	wg.Wait()
+
+	for i, clusterName := range list.Clusters {
+		if errs[i] != nil {
+			log.Warnf("Fleet list of %s failed in cluster %s: %v", resource, clusterName, errs[i])
+			list.Errors = append(list.Errors, FleetError{Cluster: clusterName, Error: errs[i].Error()})
+			continue
+		}
+		objects := items[i]
+		sort.Slice(objects, func(a, b int) bool {
+			if objects[a].GetNamespace() != objects[b].GetNamespace() {
+				return objects[a].GetNamespace() < objects[b].GetNamespace()
+			}
+			return objects[a].GetName() < objects[b].GetName()
+		})
+		for _, obj := range objects {
+			list.Items = append(list.Items, FleetItem{Cluster: clusterName, Object: h.redactSecret(obj.Object)})
+		}
+	}
+
+	c.JSON(http.StatusOK, list)
+}
+
+// listFleetCluster lists one cluster's part of a fleet view
+func (h *Handler) listFleetCluster(c *gin.Context, clusterName, resource, namespace string, opts metav1.ListOptions) ([]unstructured.Unstructured, error) {
+	mapping, err := h.resolveResource(clusterName, resource)
+	if err != nil {
+		return nil, err
+	}
+	dynamicClient, err := h.dynamicClient(c, clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), fleetClusterTimeout)
+	defer cancel()
+
+	var list *unstructured.UnstructuredList
+	if isNamespaced(mapping) && namespace != "" {
+		list, err = dynamicClient.Resource(mapping.Resource).Namespace(namespace).List(ctx, opts)
+	} else {
+		list, err = dynamicClient.Resource(mapping.Resource).List(ctx, opts)
+	}
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, fmt.Errorf("timed out after %s", fleetClusterTimeout)
+		}
+		return nil, err
+	}
+	for i := range list.Items {
+		list.Items[i].SetManagedFields(nil)
+	}
+	return list.Items, nil
+}
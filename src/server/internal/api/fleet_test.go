@@ -0,0 +1,68 @@
+package api_test
+
+import (
+	"net/http"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/sonnguyen/kubelens/internal/apitest"
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+func TestListFleetResources(t *testing.T) {
+	pod := func(namespace, name string) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, Labels: map[string]string{"app": name}}}
+	}
+	s := apitest.New(t, pod("default", "web"), pod("kube-system", "dns"))
+	s.AddCluster("second", pod("default", "api"))
+	for _, name := range []string{apitest.ClusterName, "second", "unreachable"} {
+		if err := s.DB.CreateCluster(&db.Cluster{Name: name, AuthType: "token", AuthConfig: db.JSON("{}"), Enabled: true}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	disabled := &db.Cluster{Name: "disabled", AuthType: "token", AuthConfig: db.JSON("{}")}
+	if err := s.DB.CreateCluster(disabled); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DB.UpdateClusterEnabled(disabled.ID, false); err != nil {
+		t.Fatal(err)
+	}
+
+	var list struct {
+		Clusters []string `json:"clusters"`
+		Items    []struct {
+			Cluster string     `json:"cluster"`
+			Object  corev1.Pod `json:"object"`
+		} `json:"items"`
+		Errors []struct {
+			Cluster string `json:"cluster"`
+		} `json:"errors"`
+	}
+	w := s.Get("/api/v1/fleet/pods?namespace=default")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body.String())
+	}
+	apitest.DecodeJSON(t, w, &list)
+	if len(list.Clusters) != 3 {
+		t.Errorf("clusters = %v, want the three enabled clusters", list.Clusters)
+	}
+	got := map[string]string{}
+	for _, item := range list.Items {
+		got[item.Cluster] = item.Object.Name
+	}
+	if len(list.Items) != 2 || got[apitest.ClusterName] != "web" || got["second"] != "api" {
+		t.Errorf("items = %+v, want web and api", list.Items)
+	}
+	// The cluster without a connection fails alone
+	if len(list.Errors) != 1 || list.Errors[0].Cluster != "unreachable" {
+		t.Errorf("errors = %+v, want unreachable only", list.Errors)
+	}
+
+	w = s.Get("/api/v1/fleet/pods?labelSelector=app%3Ddns")
+	apitest.DecodeJSON(t, w, &list)
+	if len(list.Items) != 1 || list.Items[0].Object.Name != "dns" {
+		t.Errorf("app=dns items = %+v", list.Items)
+	}
+}
@@ -0,0 +1,362 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/sonnguyen/kubelens/internal/audit"
+)
+
+// fluxKinds are the Flux objects served under /clusters/:name/flux/:kind. The version is
+// left empty so that whichever version the cluster prefers is used (v1, v2 or the betas;
+// the status fields read here are the same).
+var fluxKinds = map[string]schema.GroupVersionKind{
+	"kustomizations": {Group: "kustomize.toolkit.fluxcd.io", Kind: "Kustomization"},
+	"helmreleases":   {Group: "helm.toolkit.fluxcd.io", Kind: "HelmRelease"},
+}
+
+const (
+	// fluxReconcileAnnotation requests a reconciliation when its value changes
+	fluxReconcileAnnotation = "reconcile.fluxcd.io/requestedAt"
+	// fluxForceAnnotation forces a Helm upgrade when it matches the requestedAt value
+	fluxForceAnnotation = "reconcile.fluxcd.io/forceAt"
+)
+
+// FluxResource is the reconciliation status of a Flux Kustomization or HelmRelease
+type FluxResource struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	// Status is Ready, Failed, Reconciling, Suspended or Unknown
+	Status                string `json:"status"`
+	Reason                string `json:"reason,omitempty"`
+	Message               string `json:"message,omitempty"`
+	Suspended             bool   `json:"suspended"`
+	Source                string `json:"source,omitempty"` // <kind>/<namespace>/<name>
+	Path                  string `json:"path,omitempty"`   // Kustomization
+	Chart                 string `json:"chart,omitempty"`  // HelmRelease
+	ChartVersion          string `json:"chartVersion,omitempty"`
+	Interval              string `json:"interval,omitempty"`
+	LastAppliedRevision   string `json:"lastAppliedRevision,omitempty"`
+	LastAttemptedRevision string `json:"lastAttemptedRevision,omitempty"`
+	LastHandledReconcile  string `json:"lastHandledReconcileAt,omitempty"`
+	LastTransitionTime    string `json:"lastTransitionTime,omitempty"`
+}
+
+// FluxSummary counts the Flux objects of a cluster by status
+type FluxSummary struct {
+	Kustomizations int            `json:"kustomizations"`
+	HelmReleases   int            `json:"helmReleases"`
+	Status         map[string]int `json:"status"`
+}
+
+// fluxResource returns the client of a Flux kind (kustomizations or helmreleases), or
+// installed=false when its CRD is not present
+func (h *Handler) fluxResource(c *gin.Context, clusterName, kind string) (dynamic.NamespaceableResourceInterface, bool, error) {
+	gvk, ok := fluxKinds[kind]
+	if !ok {
+		return nil, false, fmt.Errorf("unknown Flux kind %q (kustomizations or helmreleases)", kind)
+	}
+	client, err := h.dynamicClient(c, clusterName)
+	if err != nil {
+		return nil, false, err
+	}
+	mapping, installed, err := h.optionalMapping(clusterName, gvk)
+	if err != nil || !installed {
+		return nil, false, err
+	}
+	return client.Resource(mapping.Resource), true, nil
+}
+
+// ListFluxResources handles GET /clusters/:name/flux/:kind
+// Query: namespace (optional)
+func (h *Handler) ListFluxResources(c *gin.Context) {
+	clusterName := c.Param("name")
+	kind := c.Param("kind")
+
+	if _, ok := fluxKinds[kind]; !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("unknown Flux kind %q (kustomizations or helmreleases)", kind)})
+		return
+	}
+	resource, installed, err := h.fluxResource(c, clusterName, kind)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	items := []FluxResource{}
+	if installed {
+		list, err := resource.Namespace(c.Query("namespace")).List(context.Background(), metav1.ListOptions{})
+		if err != nil {
+			if apierrors.IsForbidden(err) {
+				c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+				return
+			}
+			log.Errorf("Failed to list Flux %s in cluster %s: %v", kind, clusterName, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		for i := range list.Items {
+			items = append(items, fluxStatus(&list.Items[i]))
+		}
+		sort.Slice(items, func(i, j int) bool {
+			if items[i].Namespace != items[j].Namespace {
+				return items[i].Namespace < items[j].Namespace
+			}
+			return items[i].Name < items[j].Name
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"installed": installed, "items": items})
+}
+
+// GetFluxResource handles GET /clusters/:name/flux/:kind/:namespace/:resname and returns the
+// status with the conditions and, for Kustomizations, the inventory of applied objects
+func (h *Handler) GetFluxResource(c *gin.Context) {
+	obj, ok := h.getFluxResource(c)
+	if !ok {
+		return
+	}
+
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	if conditions == nil {
+		conditions = []interface{}{}
+	}
+	// Inventory entries are "<namespace>_<name>_<group>_<kind>" ids
+	inventory := []string{}
+	for _, entry := range nestedObjectList(obj.Object, "status", "inventory", "entries") {
+		inventory = append(inventory, nestedStringValue(entry, "id"))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"resource":   fluxStatus(obj),
+		"conditions": conditions,
+		"inventory":  inventory,
+	})
+}
+
+// SuspendFluxResource handles POST /clusters/:name/flux/:kind/:namespace/:resname/suspend
+func (h *Handler) SuspendFluxResource(c *gin.Context) {
+	obj, ok := h.getFluxResource(c)
+	if !ok {
+		return
+	}
+	patch := map[string]interface{}{"spec": map[string]interface{}{"suspend": true}}
+	h.patchFluxResource(c, obj, patch, "Suspended")
+}
+
+// ResumeFluxResource handles POST /clusters/:name/flux/:kind/:namespace/:resname/resume.
+// Like flux resume, it also requests a reconciliation.
+func (h *Handler) ResumeFluxResource(c *gin.Context) {
+	obj, ok := h.getFluxResource(c)
+	if !ok {
+		return
+	}
+	patch := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{fluxReconcileAnnotation: time.Now().Format(time.RFC3339Nano)},
+		},
+		"spec": map[string]interface{}{"suspend": nil},
+	}
+	h.patchFluxResource(c, obj, patch, "Resumed")
+}
+
+// ReconcileFluxResource handles POST /clusters/:name/flux/:kind/:namespace/:resname/reconcile
+// by setting the reconcile annotation, as flux reconcile does.
+// Query: force=true (HelmReleases) forces a Helm upgrade even without changes
+func (h *Handler) ReconcileFluxResource(c *gin.Context) {
+	obj, ok := h.getFluxResource(c)
+	if !ok {
+		return
+	}
+	if suspended, _, _ := unstructured.NestedBool(obj.Object, "spec", "suspend"); suspended {
+		c.JSON(http.StatusConflict, gin.H{"error": "resource is suspended; resume it to reconcile"})
+		return
+	}
+
+	requestedAt := time.Now().Format(time.RFC3339Nano)
+	annotations := map[string]interface{}{fluxReconcileAnnotation: requestedAt}
+	if c.Query("force") == "true" && obj.GetKind() == "HelmRelease" {
+		annotations[fluxForceAnnotation] = requestedAt
+	}
+	patch := map[string]interface{}{"metadata": map[string]interface{}{"annotations": annotations}}
+	h.patchFluxResource(c, obj, patch, "Requested reconciliation of")
+}
+
+// getFluxResource loads the Flux object named in the route, writing the error response
+// when it cannot
+func (h *Handler) getFluxResource(c *gin.Context) (*unstructured.Unstructured, bool) {
+	clusterName := c.Param("name")
+	kind := c.Param("kind")
+
+	if _, ok := fluxKinds[kind]; !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("unknown Flux kind %q (kustomizations or helmreleases)", kind)})
+		return nil, false
+	}
+	resource, installed, err := h.fluxResource(c, clusterName, kind)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	if !installed {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Flux is not installed in this cluster"})
+		return nil, false
+	}
+
+	obj, err := resource.Namespace(c.Param("namespace")).Get(context.Background(), c.Param("resname"), metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return nil, false
+		}
+		log.Errorf("Failed to get Flux %s %s: %v", kind, c.Param("resname"), err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	return obj, true
+}
+
+// patchFluxResource merge-patches a Flux object and responds with its new status
+func (h *Handler) patchFluxResource(c *gin.Context, obj *unstructured.Unstructured, patch map[string]interface{}, action string) {
+	clusterName := c.Param("name")
+	resource, _, err := h.fluxResource(c, clusterName, c.Param("kind"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	data, _ := json.Marshal(patch)
+	patched, err := resource.Namespace(obj.GetNamespace()).Patch(context.Background(), obj.GetName(), types.MergePatchType, data, patchOptions(c))
+	if err != nil {
+		if apierrors.IsForbidden(err) || apierrors.IsInvalid(err) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
+		log.Errorf("Failed to patch Flux %s %s: %v", obj.GetKind(), obj.GetName(), err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Audit log
+	if userID, exists := c.Get("user_id"); exists {
+		username, _ := c.Get("username")
+		email, _ := c.Get("email")
+		audit.Log(c, audit.EventAuditResourceUpdated, userID.(int), username.(string), email.(string),
+			fmt.Sprintf("%s Flux %s %s/%s", action, obj.GetKind(), obj.GetNamespace(), obj.GetName()),
+			map[string]interface{}{
+				"cluster_name": clusterName,
+				"namespace":    obj.GetNamespace(),
+				"kind":         obj.GetKind(),
+				"name":         obj.GetName(),
+				"force":        c.Query("force") == "true",
+			})
+	}
+
+	c.JSON(http.StatusOK, fluxStatus(patched))
+}
+
+// fluxStatus extracts the reconciliation status of a Flux object from its Ready and
+// Reconciling conditions
+func fluxStatus(obj *unstructured.Unstructured) FluxResource {
+	o := obj.Object
+	status := FluxResource{
+		Kind:                  obj.GetKind(),
+		Name:                  obj.GetName(),
+		Namespace:             obj.GetNamespace(),
+		Path:                  nestedStringValue(o, "spec", "path"),
+		Interval:              nestedStringValue(o, "spec", "interval"),
+		LastAppliedRevision:   nestedStringValue(o, "status", "lastAppliedRevision"),
+		LastAttemptedRevision: nestedStringValue(o, "status", "lastAttemptedRevision"),
+		LastHandledReconcile:  nestedStringValue(o, "status", "lastHandledReconcileAt"),
+	}
+	status.Suspended, _, _ = unstructured.NestedBool(o, "spec", "suspend")
+
+	sourceRef, _, _ := unstructured.NestedMap(o, "spec", "sourceRef")
+	if obj.GetKind() == "HelmRelease" {
+		sourceRef, _, _ = unstructured.NestedMap(o, "spec", "chart", "spec", "sourceRef")
+		status.Chart = nestedStringValue(o, "spec", "chart", "spec", "chart")
+		status.ChartVersion = nestedStringValue(o, "spec", "chart", "spec", "version")
+		if status.LastAppliedRevision == "" {
+			// helm.toolkit.fluxcd.io/v2 keeps the release history instead
+			if history := nestedObjectList(o, "status", "history"); len(history) > 0 {
+				status.LastAppliedRevision = nestedStringValue(history[0], "chartVersion")
+			}
+		}
+	}
+	if sourceRef != nil {
+		namespace := nestedStringValue(sourceRef, "namespace")
+		if namespace == "" {
+			namespace = obj.GetNamespace()
+		}
+		status.Source = fmt.Sprintf("%s/%s/%s", nestedStringValue(sourceRef, "kind"), namespace, nestedStringValue(sourceRef, "name"))
+	}
+
+	status.Status = "Unknown"
+	reconciling := false
+	for _, condition := range nestedObjectList(o, "status", "conditions") {
+		switch nestedStringValue(condition, "type") {
+		case "Ready":
+			switch nestedStringValue(condition, "status") {
+			case "True":
+				status.Status = "Ready"
+			case "False":
+				status.Status = "Failed"
+			}
+			status.Reason = nestedStringValue(condition, "reason")
+			status.Message = nestedStringValue(condition, "message")
+			status.LastTransitionTime = nestedStringValue(condition, "lastTransitionTime")
+		case "Reconciling":
+			reconciling = nestedStringValue(condition, "status") == "True"
+		}
+	}
+	switch {
+	case status.Suspended:
+		status.Status = "Suspended"
+	case reconciling && status.Status != "Failed":
+		status.Status = "Reconciling"
+	}
+	return status
+}
+
+// fluxSummary returns the Flux summary of a cluster for the overview, or nil when Flux
+// is not installed or cannot be read
+func (h *Handler) fluxSummary(c *gin.Context, clusterName string) *FluxSummary {
+	summary := FluxSummary{Status: map[string]int{}}
+	found := false
+	for kind := range fluxKinds {
+		resource, installed, err := h.fluxResource(c, clusterName, kind)
+		if err != nil || !installed {
+			continue
+		}
+		list, err := resource.List(context.Background(), metav1.ListOptions{})
+		if err != nil {
+			log.Warnf("Failed to list Flux %s in cluster %s: %v", kind, clusterName, err)
+			continue
+		}
+		found = true
+		for i := range list.Items {
+			summary.Status[fluxStatus(&list.Items[i]).Status]++
+		}
+		if kind == "kustomizations" {
+			summary.Kustomizations = len(list.Items)
+		} else {
+			summary.HelmReleases = len(list.Items)
+		}
+	}
+	if !found {
+		return nil
+	}
+	return &summary
+}
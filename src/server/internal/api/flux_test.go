@@ -0,0 +1,96 @@
+package api_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/sonnguyen/kubelens/internal/apitest"
+)
+
+var fluxKustomizations = schema.GroupVersionResource{Group: "kustomize.toolkit.fluxcd.io", Version: "v1", Resource: "kustomizations"}
+
+func fluxKustomization(name string, ready string, suspended bool) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "kustomize.toolkit.fluxcd.io/v1",
+		"kind":       "Kustomization",
+		"metadata":   map[string]interface{}{"name": name, "namespace": "flux-system"},
+		"spec": map[string]interface{}{
+			"interval":  "10m",
+			"path":      "./apps/" + name,
+			"suspend":   suspended,
+			"sourceRef": map[string]interface{}{"kind": "GitRepository", "name": "flux-system"},
+		},
+		"status": map[string]interface{}{
+			"lastAppliedRevision": "main@sha1:abc123",
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Ready", "status": ready, "reason": "ReconciliationSucceeded"},
+			},
+		},
+	}}
+}
+
+func TestFluxResources(t *testing.T) {
+	s := apitest.New(t,
+		fluxKustomization("apps", "True", false),
+		fluxKustomization("infra", "False", true),
+	)
+	s.Cluster.Client.Resources = append(s.Cluster.Client.Resources, &metav1.APIResourceList{
+		GroupVersion: "kustomize.toolkit.fluxcd.io/v1",
+		APIResources: []metav1.APIResource{{Name: "kustomizations", SingularName: "kustomization", Namespaced: true, Kind: "Kustomization"}},
+	})
+
+	var list struct {
+		Installed bool `json:"installed"`
+		Items     []struct {
+			Name   string `json:"name"`
+			Status string `json:"status"`
+			Source string `json:"source"`
+		} `json:"items"`
+	}
+	w := s.Get("/api/v1/clusters/test/flux/kustomizations")
+	if w.Code != http.StatusOK {
+		t.Fatalf("list status = %d: %s", w.Code, w.Body)
+	}
+	apitest.DecodeJSON(t, w, &list)
+	if !list.Installed || len(list.Items) != 2 {
+		t.Fatalf("list = %+v, want 2 kustomizations", list)
+	}
+	if list.Items[0].Status != "Ready" || list.Items[0].Source != "GitRepository/flux-system/flux-system" {
+		t.Errorf("apps = %+v, want Ready from GitRepository/flux-system/flux-system", list.Items[0])
+	}
+	if list.Items[1].Status != "Suspended" {
+		t.Errorf("infra status = %s, want Suspended", list.Items[1].Status)
+	}
+
+	if w := s.Do(http.MethodPost, "/api/v1/clusters/test/flux/kustomizations/flux-system/infra/reconcile", nil); w.Code != http.StatusConflict {
+		t.Errorf("reconcile of a suspended kustomization status = %d, want 409", w.Code)
+	}
+	if w := s.Do(http.MethodPost, "/api/v1/clusters/test/flux/kustomizations/flux-system/infra/resume", nil); w.Code != http.StatusOK {
+		t.Fatalf("resume status = %d: %s", w.Code, w.Body)
+	}
+	if w := s.Do(http.MethodPost, "/api/v1/clusters/test/flux/kustomizations/flux-system/apps/suspend", nil); w.Code != http.StatusOK {
+		t.Fatalf("suspend status = %d: %s", w.Code, w.Body)
+	}
+
+	ctx := context.Background()
+	infra, _ := s.Cluster.Dynamic.Resource(fluxKustomizations).Namespace("flux-system").Get(ctx, "infra", metav1.GetOptions{})
+	if _, found, _ := unstructured.NestedBool(infra.Object, "spec", "suspend"); found {
+		t.Error("resume left spec.suspend set")
+	}
+	if infra.GetAnnotations()["reconcile.fluxcd.io/requestedAt"] == "" {
+		t.Error("resume did not request a reconciliation")
+	}
+	apps, _ := s.Cluster.Dynamic.Resource(fluxKustomizations).Namespace("flux-system").Get(ctx, "apps", metav1.GetOptions{})
+	if suspended, _, _ := unstructured.NestedBool(apps.Object, "spec", "suspend"); !suspended {
+		t.Error("suspend did not set spec.suspend")
+	}
+
+	if w := s.Get("/api/v1/clusters/test/flux/gitrepositories"); w.Code != http.StatusBadRequest {
+		t.Errorf("unknown kind status = %d, want 400", w.Code)
+	}
+}
@@ -0,0 +1,281 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// Gatekeeper API groups. Constraint kinds are generated from the ConstraintTemplates, so
+// the constraints of a cluster are found through its templates. Versions are left empty
+// so that whichever version the cluster prefers is used.
+var (
+	gatekeeperTemplateKind = schema.GroupVersionKind{Group: "templates.gatekeeper.sh", Kind: "ConstraintTemplate"}
+	gatekeeperConstraints  = "constraints.gatekeeper.sh"
+)
+
+// GatekeeperTemplate is a ConstraintTemplate with the violations of its constraints
+type GatekeeperTemplate struct {
+	Name            string `json:"name"`
+	Kind            string `json:"kind"` // kind of the constraints it defines
+	Created         bool   `json:"created"`
+	Constraints     int    `json:"constraints"`
+	TotalViolations int    `json:"totalViolations"`
+	CreatedAt       string `json:"createdAt,omitempty"`
+}
+
+// GatekeeperConstraint is a constraint with the outcome of the last Gatekeeper audit
+type GatekeeperConstraint struct {
+	Kind              string `json:"kind"`
+	Name              string `json:"name"`
+	EnforcementAction string `json:"enforcementAction"` // deny, dryrun, warn or scoped
+	TotalViolations   int    `json:"totalViolations"`
+	AuditTimestamp    string `json:"auditTimestamp,omitempty"`
+	// Violations lists the violations reported by the audit. Gatekeeper caps the list (20
+	// per constraint by default), so it can be shorter than TotalViolations.
+	Violations []GatekeeperViolation `json:"violations,omitempty"`
+}
+
+// GatekeeperViolation is a resource violating a constraint
+type GatekeeperViolation struct {
+	ConstraintKind    string `json:"constraintKind,omitempty"`
+	ConstraintName    string `json:"constraintName,omitempty"`
+	EnforcementAction string `json:"enforcementAction"`
+	Group             string `json:"group,omitempty"`
+	Version           string `json:"version,omitempty"`
+	Kind              string `json:"kind"`
+	Name              string `json:"name"`
+	Namespace         string `json:"namespace,omitempty"`
+	Message           string `json:"message"`
+}
+
+// gatekeeperState is the templates and constraints of a cluster
+type gatekeeperState struct {
+	templates   []GatekeeperTemplate
+	constraints []GatekeeperConstraint
+}
+
+// loadGatekeeper reads the ConstraintTemplates of a cluster and the constraints of each,
+// or installed=false when Gatekeeper is not installed
+func (h *Handler) loadGatekeeper(c *gin.Context, clusterName string) (*gatekeeperState, bool, error) {
+	client, err := h.dynamicClient(c, clusterName)
+	if err != nil {
+		return nil, false, err
+	}
+	mapping, installed, err := h.optionalMapping(clusterName, gatekeeperTemplateKind)
+	if err != nil || !installed {
+		return nil, false, err
+	}
+	templates, err := client.Resource(mapping.Resource).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return nil, true, err
+	}
+
+	state := &gatekeeperState{templates: []GatekeeperTemplate{}, constraints: []GatekeeperConstraint{}}
+	for i := range templates.Items {
+		template := gatekeeperTemplateStatus(&templates.Items[i])
+		if template.Kind != "" {
+			constraints, err := h.listGatekeeperConstraints(client, clusterName, template.Kind)
+			if err != nil {
+				log.Warnf("Failed to list Gatekeeper %s constraints in cluster %s: %v", template.Kind, clusterName, err)
+			}
+			for _, constraint := range constraints {
+				template.Constraints++
+				template.TotalViolations += constraint.TotalViolations
+				state.constraints = append(state.constraints, constraint)
+			}
+		}
+		state.templates = append(state.templates, template)
+	}
+
+	sort.Slice(state.templates, func(i, j int) bool { return state.templates[i].Name < state.templates[j].Name })
+	sort.Slice(state.constraints, func(i, j int) bool {
+		if state.constraints[i].Kind != state.constraints[j].Kind {
+			return state.constraints[i].Kind < state.constraints[j].Kind
+		}
+		return state.constraints[i].Name < state.constraints[j].Name
+	})
+	return state, true, nil
+}
+
+// listGatekeeperConstraints lists the constraints of a kind. The kind is absent until
+// Gatekeeper has created the CRD of its template.
+func (h *Handler) listGatekeeperConstraints(client dynamic.Interface, clusterName, kind string) ([]GatekeeperConstraint, error) {
+	mapping, installed, err := h.optionalMapping(clusterName, schema.GroupVersionKind{Group: gatekeeperConstraints, Kind: kind})
+	if err != nil || !installed {
+		return nil, err
+	}
+	list, err := client.Resource(mapping.Resource).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	constraints := make([]GatekeeperConstraint, 0, len(list.Items))
+	for i := range list.Items {
+		constraints = append(constraints, gatekeeperConstraintStatus(&list.Items[i]))
+	}
+	return constraints, nil
+}
+
+// gatekeeperTemplateStatus extracts the constraint kind of a ConstraintTemplate and
+// whether Gatekeeper created its CRD
+func gatekeeperTemplateStatus(obj *unstructured.Unstructured) GatekeeperTemplate {
+	created, _, _ := unstructured.NestedBool(obj.Object, "status", "created")
+	return GatekeeperTemplate{
+		Name:      obj.GetName(),
+		Kind:      nestedStringValue(obj.Object, "spec", "crd", "spec", "names", "kind"),
+		Created:   created,
+		CreatedAt: obj.GetCreationTimestamp().UTC().Format(time.RFC3339),
+	}
+}
+
+// gatekeeperConstraintStatus extracts the audit results of a constraint
+func gatekeeperConstraintStatus(obj *unstructured.Unstructured) GatekeeperConstraint {
+	action := nestedStringValue(obj.Object, "spec", "enforcementAction")
+	if action == "" {
+		action = "deny"
+	}
+	total, _, _ := unstructured.NestedInt64(obj.Object, "status", "totalViolations")
+	constraint := GatekeeperConstraint{
+		Kind:              obj.GetKind(),
+		Name:              obj.GetName(),
+		EnforcementAction: action,
+		TotalViolations:   int(total),
+		AuditTimestamp:    nestedStringValue(obj.Object, "status", "auditTimestamp"),
+	}
+	for _, v := range nestedObjectList(obj.Object, "status", "violations") {
+		violationAction := nestedStringValue(v, "enforcementAction")
+		if violationAction == "" {
+			violationAction = action
+		}
+		constraint.Violations = append(constraint.Violations, GatekeeperViolation{
+			EnforcementAction: violationAction,
+			Group:             nestedStringValue(v, "group"),
+			Version:           nestedStringValue(v, "version"),
+			Kind:              nestedStringValue(v, "kind"),
+			Name:              nestedStringValue(v, "name"),
+			Namespace:         nestedStringValue(v, "namespace"),
+			Message:           nestedStringValue(v, "message"),
+		})
+	}
+	return constraint
+}
+
+// gatekeeper loads the Gatekeeper state of the cluster in the route, writing the error
+// response when it cannot
+func (h *Handler) gatekeeper(c *gin.Context) (*gatekeeperState, bool, bool) {
+	clusterName := c.Param("name")
+	state, installed, err := h.loadGatekeeper(c, clusterName)
+	if err != nil {
+		if !installed {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return nil, false, false
+		}
+		if apierrors.IsForbidden(err) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return nil, false, false
+		}
+		log.Errorf("Failed to list Gatekeeper constraint templates in cluster %s: %v", clusterName, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, false, false
+	}
+	return state, installed, true
+}
+
+// ListGatekeeperTemplates handles GET /clusters/:name/gatekeeper/templates
+func (h *Handler) ListGatekeeperTemplates(c *gin.Context) {
+	state, installed, ok := h.gatekeeper(c)
+	if !ok {
+		return
+	}
+	items := []GatekeeperTemplate{}
+	if installed {
+		items = state.templates
+	}
+	c.JSON(http.StatusOK, gin.H{"installed": installed, "items": items})
+}
+
+// ListGatekeeperConstraints handles GET /clusters/:name/gatekeeper/constraints. The
+// violations are left out; get a constraint for them.
+// Query: kind (optional)
+func (h *Handler) ListGatekeeperConstraints(c *gin.Context) {
+	state, installed, ok := h.gatekeeper(c)
+	if !ok {
+		return
+	}
+	items := []GatekeeperConstraint{}
+	if installed {
+		for _, constraint := range state.constraints {
+			if kind := c.Query("kind"); kind != "" && constraint.Kind != kind {
+				continue
+			}
+			constraint.Violations = nil
+			items = append(items, constraint)
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"installed": installed, "items": items})
+}
+
+// GetGatekeeperConstraint handles GET /clusters/:name/gatekeeper/constraints/:kind/:resname
+func (h *Handler) GetGatekeeperConstraint(c *gin.Context) {
+	state, installed, ok := h.gatekeeper(c)
+	if !ok {
+		return
+	}
+	if !installed {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Gatekeeper is not installed in this cluster"})
+		return
+	}
+	for _, constraint := range state.constraints {
+		if constraint.Kind == c.Param("kind") && constraint.Name == c.Param("resname") {
+			if constraint.Violations == nil {
+				constraint.Violations = []GatekeeperViolation{}
+			}
+			c.JSON(http.StatusOK, constraint)
+			return
+		}
+	}
+	c.JSON(http.StatusNotFound, gin.H{"error": "constraint not found"})
+}
+
+// ListGatekeeperViolations handles GET /clusters/:name/namespaces/:namespace/gatekeeper/violations,
+// the audit violations of the resources of a namespace across all constraints.
+// Query: kind (optional) filters on the kind of the violating resource
+func (h *Handler) ListGatekeeperViolations(c *gin.Context) {
+	state, installed, ok := h.gatekeeper(c)
+	if !ok {
+		return
+	}
+	namespace := c.Param("namespace")
+	items := []GatekeeperViolation{}
+	if installed {
+		for _, constraint := range state.constraints {
+			for _, violation := range constraint.Violations {
+				if violation.Namespace != namespace {
+					continue
+				}
+				if kind := c.Query("kind"); kind != "" && violation.Kind != kind {
+					continue
+				}
+				violation.ConstraintKind = constraint.Kind
+				violation.ConstraintName = constraint.Name
+				items = append(items, violation)
+			}
+		}
+		sort.SliceStable(items, func(i, j int) bool {
+			if items[i].Kind != items[j].Kind {
+				return items[i].Kind < items[j].Kind
+			}
+			return items[i].Name < items[j].Name
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"installed": installed, "namespace": namespace, "items": items})
+}
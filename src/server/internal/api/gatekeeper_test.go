@@ -0,0 +1,104 @@
+package api_test
+
+import (
+	"net/http"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/sonnguyen/kubelens/internal/apitest"
+)
+
+func TestGatekeeper(t *testing.T) {
+	template := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "templates.gatekeeper.sh/v1",
+		"kind":       "ConstraintTemplate",
+		"metadata":   map[string]interface{}{"name": "k8srequiredlabels"},
+		"spec": map[string]interface{}{
+			"crd": map[string]interface{}{"spec": map[string]interface{}{"names": map[string]interface{}{"kind": "K8sRequiredLabels"}}},
+		},
+		"status": map[string]interface{}{"created": true},
+	}}
+	constraint := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "constraints.gatekeeper.sh/v1beta1",
+		"kind":       "K8sRequiredLabels",
+		"metadata":   map[string]interface{}{"name": "ns-must-have-owner"},
+		"spec":       map[string]interface{}{"enforcementAction": "dryrun"},
+		"status": map[string]interface{}{
+			"auditTimestamp":  "2024-05-01T10:00:00Z",
+			"totalViolations": int64(2),
+			"violations": []interface{}{
+				map[string]interface{}{"kind": "Deployment", "name": "web", "namespace": "default", "message": `missing required label "owner"`},
+				map[string]interface{}{"kind": "Deployment", "name": "api", "namespace": "payments", "message": `missing required label "owner"`},
+			},
+		},
+	}}
+	s := apitest.New(t, template, constraint)
+	s.Cluster.Client.Resources = append(s.Cluster.Client.Resources,
+		&metav1.APIResourceList{
+			GroupVersion: "templates.gatekeeper.sh/v1",
+			APIResources: []metav1.APIResource{{Name: "constrainttemplates", SingularName: "constrainttemplate", Kind: "ConstraintTemplate"}},
+		},
+		&metav1.APIResourceList{
+			GroupVersion: "constraints.gatekeeper.sh/v1beta1",
+			// Gatekeeper names the resource k8srequiredlabels; the fake dynamic client only
+			// lists the plural it guesses from the kind
+			APIResources: []metav1.APIResource{{Name: "k8srequiredlabelses", SingularName: "k8srequiredlabels", Kind: "K8sRequiredLabels"}},
+		},
+	)
+
+	var templates struct {
+		Installed bool `json:"installed"`
+		Items     []struct {
+			Kind            string `json:"kind"`
+			Constraints     int    `json:"constraints"`
+			TotalViolations int    `json:"totalViolations"`
+		} `json:"items"`
+	}
+	w := s.Get("/api/v1/clusters/test/gatekeeper/templates")
+	if w.Code != http.StatusOK {
+		t.Fatalf("templates status = %d: %s", w.Code, w.Body)
+	}
+	apitest.DecodeJSON(t, w, &templates)
+	if !templates.Installed || len(templates.Items) != 1 || templates.Items[0].Constraints != 1 || templates.Items[0].TotalViolations != 2 {
+		t.Fatalf("templates = %+v, want K8sRequiredLabels with 1 constraint and 2 violations", templates)
+	}
+
+	var violations struct {
+		Items []struct {
+			ConstraintName    string `json:"constraintName"`
+			EnforcementAction string `json:"enforcementAction"`
+			Name              string `json:"name"`
+		} `json:"items"`
+	}
+	w = s.Get("/api/v1/clusters/test/namespaces/payments/gatekeeper/violations")
+	if w.Code != http.StatusOK {
+		t.Fatalf("violations status = %d: %s", w.Code, w.Body)
+	}
+	apitest.DecodeJSON(t, w, &violations)
+	if len(violations.Items) != 1 || violations.Items[0].Name != "api" ||
+		violations.Items[0].ConstraintName != "ns-must-have-owner" || violations.Items[0].EnforcementAction != "dryrun" {
+		t.Errorf("violations = %+v, want Deployment api of ns-must-have-owner", violations)
+	}
+
+	if w := s.Get("/api/v1/clusters/test/gatekeeper/constraints/K8sRequiredLabels/missing"); w.Code != http.StatusNotFound {
+		t.Errorf("missing constraint status = %d, want 404", w.Code)
+	}
+}
+
+func TestGatekeeperNotInstalled(t *testing.T) {
+	s := apitest.New(t)
+	var list struct {
+		Installed bool          `json:"installed"`
+		Items     []interface{} `json:"items"`
+	}
+	w := s.Get("/api/v1/clusters/test/gatekeeper/constraints")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body)
+	}
+	apitest.DecodeJSON(t, w, &list)
+	if list.Installed || list.Items == nil {
+		t.Errorf("list = %+v, want not installed with no items", list)
+	}
+}
@@ -0,0 +1,34 @@
+package api_test
+
+import (
+	"testing"
+
+	"github.com/sonnguyen/kubelens/internal/apitest"
+)
+
+// TestGoldenResponses locks the JSON contract of representative endpoints used by the
+// frontend. Run go test ./internal/api -run TestGoldenResponses -update after an
+// intended change and review the diff of testdata/golden.
+func TestGoldenResponses(t *testing.T) {
+	s := apitest.New(t, apitest.Fixtures()...)
+
+	tests := []struct {
+		name string
+		path string
+	}{
+		{name: "list_namespaces", path: "/api/v1/clusters/test/namespaces"},
+		{name: "list_nodes", path: "/api/v1/clusters/test/nodes"},
+		{name: "list_pods", path: "/api/v1/clusters/test/pods?namespace=shop"},
+		{name: "get_pod", path: "/api/v1/clusters/test/namespaces/shop/pods/web-5d8f-abcde"},
+		{name: "list_deployments", path: "/api/v1/clusters/test/deployments?namespace=shop"},
+		{name: "list_services", path: "/api/v1/clusters/test/services?namespace=shop"},
+		{name: "describe_pod", path: "/api/v1/clusters/test/namespaces/shop/pods/web-5d8f-abcde/describe"},
+		{name: "list_orphans", path: "/api/v1/clusters/test/orphans?namespace=shop"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apitest.AssertGolden(t, s.Get(tt.path), tt.name)
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var (
+	// grafanaUIDPattern matches Grafana dashboard UIDs
+	grafanaUIDPattern = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,40}$`)
+	// grafanaVariablePattern matches Grafana template variable names
+	grafanaVariablePattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+	// grafanaPlaceholderPattern matches the placeholders of variable values
+	grafanaPlaceholderPattern = regexp.MustCompile(`\{[a-z]+\}`)
+)
+
+// grafanaPlaceholders are the values a dashboard variable can be set from
+var grafanaPlaceholders = map[string]bool{
+	"{cluster}":   true, // cluster_value, or the kubelens cluster name
+	"{namespace}": true, // the namespace of the object (the name for a Namespace)
+	"{name}":      true, // the name of the object
+	"{kind}":      true, // the kind of the object, lowercase
+	"{node}":      true, // the node of a pod (the name for a Node)
+}
+
+// grafanaDefaultVariables are the variables set when a dashboard lists none. They match
+// the dashboards of the Kubernetes mixin shipped with kube-prometheus-stack.
+var grafanaDefaultVariables = map[string]map[string]string{
+	"pods":         {"namespace": "{namespace}", "pod": "{name}"},
+	"nodes":        {"node": "{name}"},
+	"namespaces":   {"namespace": "{name}"},
+	"deployments":  {"namespace": "{namespace}", "workload": "{name}", "type": "{kind}"},
+	"statefulsets": {"namespace": "{namespace}", "workload": "{name}", "type": "{kind}"},
+	"daemonsets":   {"namespace": "{namespace}", "workload": "{name}", "type": "{kind}"},
+	"jobs":         {"namespace": "{namespace}", "workload": "{name}", "type": "{kind}"},
+	"cronjobs":     {"namespace": "{namespace}", "workload": "{name}", "type": "{kind}"},
+}
+
+// grafanaSettings are the settings of a Grafana datasource: the dashboards linked from
+// the detail of each resource type
+type grafanaSettings struct {
+	OrgID int `json:"org_id,omitempty"`
+	// ClusterVariable, if set, is a variable set to ClusterValue (default: the cluster name)
+	// on every dashboard, for Grafana instances shared by several clusters
+	ClusterVariable string `json:"cluster_variable,omitempty"`
+	ClusterValue    string `json:"cluster_value,omitempty"`
+	// Dashboards by resource (pods, nodes, namespaces, deployments, statefulsets,
+	// daemonsets, jobs, cronjobs)
+	Dashboards map[string][]grafanaDashboard `json:"dashboards"`
+}
+
+// grafanaDashboard is a dashboard linked from the detail of a resource
+type grafanaDashboard struct {
+	UID   string `json:"uid"`
+	Title string `json:"title,omitempty"`
+	// Variables maps dashboard variables to values with placeholders such as {namespace};
+	// default: grafanaDefaultVariables of the resource
+	Variables map[string]string `json:"variables,omitempty"`
+}
+
+// ExternalLink is a link from a resource to another tool
+type ExternalLink struct {
+	Type  string `json:"type"` // grafana
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
+
+func (s *grafanaSettings) validate() error {
+	if s.ClusterVariable != "" && !grafanaVariablePattern.MatchString(s.ClusterVariable) {
+		return fmt.Errorf("invalid cluster_variable %q", s.ClusterVariable)
+	}
+	if len(s.Dashboards) == 0 {
+		return fmt.Errorf("settings.dashboards must map at least one resource to dashboards")
+	}
+	for resource, dashboards := range s.Dashboards {
+		if _, ok := grafanaDefaultVariables[resource]; !ok {
+			return fmt.Errorf("unsupported resource %q in dashboards", resource)
+		}
+		for _, dashboard := range dashboards {
+			if !grafanaUIDPattern.MatchString(dashboard.UID) {
+				return fmt.Errorf("invalid dashboard uid %q", dashboard.UID)
+			}
+			for variable, value := range dashboard.Variables {
+				if !grafanaVariablePattern.MatchString(variable) {
+					return fmt.Errorf("invalid variable name %q", variable)
+				}
+				for _, placeholder := range grafanaPlaceholderPattern.FindAllString(value, -1) {
+					if !grafanaPlaceholders[placeholder] {
+						return fmt.Errorf("unknown placeholder %s in variable %s", placeholder, variable)
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// grafanaLinks returns the links to the Grafana dashboards configured for a resource, or
+// nil when the cluster has no Grafana datasource
+func (h *Handler) grafanaLinks(clusterName, resource string, obj *unstructured.Unstructured) []ExternalLink {
+	ds, err := h.db.GetClusterDatasource(clusterName, "grafana")
+	if err != nil || len(ds.Settings) == 0 {
+		return nil
+	}
+	var settings grafanaSettings
+	if err := json.Unmarshal(ds.Settings, &settings); err != nil {
+		log.Warnf("Invalid Grafana settings for cluster %s: %v", clusterName, err)
+		return nil
+	}
+	return settings.links(ds.URL, clusterName, resource, obj)
+}
+
+// links builds the dashboard links of an object
+func (s *grafanaSettings) links(baseURL, clusterName, resource string, obj *unstructured.Unstructured) []ExternalLink {
+	dashboards := s.Dashboards[resource]
+	if len(dashboards) == 0 {
+		return nil
+	}
+
+	cluster := s.ClusterValue
+	if cluster == "" {
+		cluster = clusterName
+	}
+	namespace, node := obj.GetNamespace(), ""
+	switch resource {
+	case "namespaces":
+		namespace = obj.GetName()
+	case "nodes":
+		node = obj.GetName()
+	case "pods":
+		node, _, _ = unstructured.NestedString(obj.Object, "spec", "nodeName")
+	}
+	replacer := strings.NewReplacer(
+		"{cluster}", cluster,
+		"{namespace}", namespace,
+		"{name}", obj.GetName(),
+		"{kind}", strings.ToLower(obj.GetKind()),
+		"{node}", node,
+	)
+
+	links := make([]ExternalLink, 0, len(dashboards))
+	for _, dashboard := range dashboards {
+		variables := dashboard.Variables
+		if len(variables) == 0 {
+			variables = grafanaDefaultVariables[resource]
+		}
+		query := url.Values{}
+		if s.OrgID > 0 {
+			query.Set("orgId", fmt.Sprint(s.OrgID))
+		}
+		if s.ClusterVariable != "" {
+			query.Set("var-"+s.ClusterVariable, cluster)
+		}
+		names := make([]string, 0, len(variables))
+		for name := range variables {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			query.Set("var-"+name, replacer.Replace(variables[name]))
+		}
+
+		title := dashboard.Title
+		if title == "" {
+			title = "Grafana dashboard"
+		}
+		links = append(links, ExternalLink{
+			Type:  "grafana",
+			Title: title,
+			URL:   fmt.Sprintf("%s/d/%s?%s", baseURL, dashboard.UID, query.Encode()),
+		})
+	}
+	return links
+}
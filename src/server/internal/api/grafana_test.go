@@ -0,0 +1,54 @@
+package api
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestGrafanaLinks(t *testing.T) {
+	settings := grafanaSettings{
+		OrgID:           2,
+		ClusterVariable: "cluster",
+		ClusterValue:    "prod-eu",
+		Dashboards: map[string][]grafanaDashboard{
+			"pods": {
+				{UID: "pod-resources", Title: "Pod resources"},
+				{UID: "pod-node", Variables: map[string]string{"instance": "{node}", "app": "{namespace}/{name}"}},
+			},
+		},
+	}
+	if err := settings.validate(); err != nil {
+		t.Fatalf("validate() error = %v", err)
+	}
+
+	pod := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata":   map[string]interface{}{"name": "web-1", "namespace": "shop"},
+		"spec":       map[string]interface{}{"nodeName": "node-a"},
+	}}
+	links := settings.links("https://grafana.example.com", "prod", "pods", pod)
+	if len(links) != 2 {
+		t.Fatalf("links = %+v, want 2", links)
+	}
+	if want := "https://grafana.example.com/d/pod-resources?orgId=2&var-cluster=prod-eu&var-namespace=shop&var-pod=web-1"; links[0].URL != want {
+		t.Errorf("default variables link = %s, want %s", links[0].URL, want)
+	}
+	if want := "https://grafana.example.com/d/pod-node?orgId=2&var-app=shop%2Fweb-1&var-cluster=prod-eu&var-instance=node-a"; links[1].URL != want {
+		t.Errorf("custom variables link = %s, want %s", links[1].URL, want)
+	}
+	if links := settings.links("https://grafana.example.com", "prod", "nodes", pod); links != nil {
+		t.Errorf("links for an unmapped resource = %+v, want none", links)
+	}
+
+	for _, bad := range []grafanaSettings{
+		{Dashboards: map[string][]grafanaDashboard{"services": {{UID: "x"}}}},
+		{Dashboards: map[string][]grafanaDashboard{"pods": {{UID: "../admin"}}}},
+		{Dashboards: map[string][]grafanaDashboard{"pods": {{UID: "x", Variables: map[string]string{"pod": "{secret}"}}}}},
+	} {
+		if err := bad.validate(); err == nil {
+			t.Errorf("validate(%+v) accepted invalid settings", bad)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -29,11 +30,16 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/yaml"
 
 	"github.com/sonnguyen/kubelens/internal/audit"
+	"github.com/sonnguyen/kubelens/internal/cache"
 	"github.com/sonnguyen/kubelens/internal/cluster"
 	"github.com/sonnguyen/kubelens/internal/db"
+	"github.com/sonnguyen/kubelens/internal/policy"
+	"github.com/sonnguyen/kubelens/internal/search"
+	"github.com/sonnguyen/kubelens/internal/tunnel"
 	"github.com/sonnguyen/kubelens/internal/ws"
 )
 
@@ -42,6 +48,20 @@ type Handler struct {
 	clusterManager *cluster.Manager
 	db             *db.DB
 	wsHub          *ws.Hub
+	editSessions   *EditSessionManager
+	mappers        resourceMappers
+	// cache holds cluster summaries, shared by the replicas when it is Redis
+	cache cache.Store
+	// shellCommandAudit records the commands typed in shells as audit events
+	shellCommandAudit bool
+	// shellPolicy restricts the pod and node shells, nil when there is none
+	shellPolicy *policy.ShellPolicy
+	// searchIndex serves global search from memory, nil when search lists clusters live
+	searchIndex *search.Indexer
+	// recentViews throttles the recording of repeated views of a resource
+	recentViews recentViewThrottle
+	// endpointPolicy is checked before returning Secret values, set by RegisterRoutes
+	endpointPolicy *policy.EndpointPolicy
 }
 
 // NewHandler creates a new API handler
@@ -50,20 +70,49 @@ func NewHandler(clusterManager *cluster.Manager, database *db.DB, wsHub *ws.Hub)
 		clusterManager: clusterManager,
 		db:             database,
 		wsHub:          wsHub,
+		editSessions:   NewEditSessionManager(wsHub),
+		cache:          cache.NewMemory(),
+		shellCommandAudit: true,
 	}
 }
 
+// SetShellCommandAudit turns the audit of the commands typed in pod and node shells on or off
+func (h *Handler) SetShellCommandAudit(enabled bool) {
+	h.shellCommandAudit = enabled
+}
+
+// SetShellPolicy sets the policy restricting pod and node shells
+func (h *Handler) SetShellPolicy(shellPolicy *policy.ShellPolicy) {
+	h.shellPolicy = shellPolicy
+}
+
+// SetSearchIndex sets the index global search is served from
+func (h *Handler) SetSearchIndex(index *search.Indexer) {
+	h.searchIndex = index
+}
+
+// SetCache replaces the in-memory cache of cluster summaries, e.g. with Redis
+func (h *Handler) SetCache(store cache.Store) {
+	h.cache = store
+}
+
 // ListClusters returns a list of all clusters
 func (h *Handler) ListClusters(c *gin.Context) {
 	// Check if we should filter by enabled status
 	enabledOnly := c.Query("enabled") == "true"
 
+	// Filter by cluster labels or group
+	selected, err := h.clusterFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	// Initialize as empty slice (not nil) to avoid "null" in JSON response
 	clusters := make([]cluster.ClusterInfo, 0)
 
 	// Always get clusters from database (source of truth)
 	var dbClusters []*db.Cluster
-	var err error
 	
 	if enabledOnly {
 		dbClusters, err = h.db.ListEnabledClusters()
@@ -79,12 +128,17 @@ func (h *Handler) ListClusters(c *gin.Context) {
 
 	// Convert DB clusters to ClusterInfo with additional metadata from manager
 	for _, dbCluster := range dbClusters {
+		if selected != nil && !selected(dbCluster.Name) {
+			continue
+		}
 		info := cluster.ClusterInfo{
-			Name:      dbCluster.Name,
-			Status:    dbCluster.Status,
-			IsDefault: dbCluster.IsDefault,
-			Enabled:   dbCluster.Enabled,
-			Metadata:  make(map[string]interface{}),
+			Name:        dbCluster.Name,
+			Status:      dbCluster.Status,
+			IsDefault:   dbCluster.IsDefault,
+			Enabled:     dbCluster.Enabled,
+			Impersonate: dbCluster.Impersonate,
+			Labels:      dbCluster.LabelSet(),
+			Metadata:    make(map[string]interface{}),
 		}
 		
 		// Try to get version from manager if cluster is loaded
@@ -109,14 +163,24 @@ func getMapKeys(m map[string]interface{}) []string {
 	return keys
 }
 
+// organizationID returns the organization a request works in, where new clusters go
+func organizationID(c *gin.Context) uint {
+	if orgID := c.GetUint("org_id"); orgID != 0 {
+		return orgID
+	}
+	return db.DefaultOrganizationID
+}
+
 // AddCluster adds a new cluster with support for multiple auth types
 func (h *Handler) AddCluster(c *gin.Context) {
 	var req struct {
 		Name       string                 `json:"name" binding:"required"`
-		AuthType   string                 `json:"auth_type"` // "token", "kubeconfig"
+		AuthType   string                 `json:"auth_type"` // "token", "kubeconfig", "exec", "eks", "gke", "aks", "agent"
 		AuthConfig map[string]interface{} `json:"auth_config" binding:"required"`
 		IsDefault  bool                   `json:"is_default"`
 		Enabled    bool                   `json:"enabled"`
+		Connection *db.ConnectionOptions  `json:"connection"` // proxy and TLS overrides
+		Labels     map[string]string      `json:"labels"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -143,8 +207,25 @@ func (h *Handler) AddCluster(c *gin.Context) {
 		return
 	}
 
+	if err := validateClusterLabels(req.Labels); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Proxy and TLS overrides apply to every connection made to the cluster
+	if err := cluster.ValidateConnectionOptions(req.Connection); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Connection != nil && req.AuthType == "agent" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "connection options do not apply to agent clusters"})
+		return
+	}
+	h.clusterManager.SetConnectionOptions(req.Name, req.Connection)
+
 	var serverURL string
 	var addErr error
+	var agentToken string
 
 	// Handle different auth types
 	switch req.AuthType {
@@ -194,6 +275,26 @@ func (h *Handler) AddCluster(c *gin.Context) {
 		addErr = h.clusterManager.AddClusterFromConfig(req.Name, server, ca, token)
 		serverURL = server
 
+	case "exec", "eks", "gke", "aks":
+		// Exec credential plugin or cloud IAM authentication: no long-lived token is stored
+		if err := h.clusterManager.ValidateAuthConfig(req.AuthType, authConfigJSON); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		addErr = h.clusterManager.AddClusterFromAuthConfig(req.Name, req.AuthType, authConfigJSON)
+		serverURL, _ = req.AuthConfig["server"].(string)
+
+	case "agent":
+		// The cluster connects once its agent dials in with this token; only its hash is stored
+		token, tokenHash, err := tunnel.GenerateAgentToken()
+		if err != nil {
+			log.Errorf("Failed to generate agent token: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate agent token"})
+			return
+		}
+		authConfigJSON, _ = json.Marshal(db.AgentAuthConfig{TokenHash: tokenHash})
+		agentToken = token
+
 	default:
 		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("unsupported auth_type: %s", req.AuthType)})
 		return
@@ -204,17 +305,28 @@ func (h *Handler) AddCluster(c *gin.Context) {
 	if addErr != nil {
 		log.Errorf("Failed to add cluster %s: %v", req.Name, addErr)
 		status = "error"
+	} else if agentToken != "" {
+		status = "disconnected"
 	}
 
 	// Prepare cluster struct with extracted fields
 	dbCluster := &db.Cluster{
-		Name:       req.Name,
-		AuthType:   req.AuthType,
-		AuthConfig: db.JSON(authConfigJSON),
-		Server:     serverURL,
-		IsDefault:  req.IsDefault,
-		Enabled:    req.Enabled,
-		Status:     status,
+		Name:           req.Name,
+		AuthType:       req.AuthType,
+		AuthConfig:     db.JSON(authConfigJSON),
+		Server:         serverURL,
+		IsDefault:      req.IsDefault,
+		Enabled:        req.Enabled,
+		Status:         status,
+		OrganizationID: organizationID(c),
+	}
+	if req.Connection != nil {
+		connectionJSON, _ := json.Marshal(req.Connection)
+		dbCluster.Connection = db.JSON(connectionJSON)
+	}
+	if len(req.Labels) > 0 {
+		labelsJSON, _ := json.Marshal(req.Labels)
+		dbCluster.Labels = db.JSON(labelsJSON)
 	}
 
 	// For "token" auth, extract and store CA/Token for direct cluster manager use
@@ -239,10 +351,13 @@ func (h *Handler) AddCluster(c *gin.Context) {
 		return
 	}
 
-	// Setup kubelens ServiceAccount in kube-system namespace
-	if err := h.setupKubelensServiceAccount(req.Name); err != nil {
-		log.Warnf("Failed to setup kubelens ServiceAccount for cluster %s: %v", req.Name, err)
-		// Don't fail the cluster import if SA setup fails
+	// Setup kubelens ServiceAccount in kube-system namespace (agent clusters use the
+	// ServiceAccount of the agent)
+	if agentToken == "" {
+		if err := h.setupKubelensServiceAccount(req.Name); err != nil {
+			log.Warnf("Failed to setup kubelens ServiceAccount for cluster %s: %v", req.Name, err)
+			// Don't fail the cluster import if SA setup fails
+		}
 	}
 
 	// Audit log
@@ -259,11 +374,16 @@ func (h *Handler) AddCluster(c *gin.Context) {
 			})
 	}
 
-	c.JSON(http.StatusCreated, gin.H{
+	response := gin.H{
 		"message":   "Cluster added successfully",
 		"name":      req.Name,
 		"auth_type": req.AuthType,
-	})
+	}
+	if agentToken != "" {
+		// Shown once: the agent authenticates with it
+		response["agent_token"] = agentToken
+	}
+	c.JSON(http.StatusCreated, response)
 }
 
 // extractServerFromKubeconfig extracts the server URL from kubeconfig YAML
@@ -338,6 +458,7 @@ func (h *Handler) UpdateCluster(c *gin.Context) {
 		AuthConfig map[string]interface{} `json:"auth_config"`
 		IsDefault  bool                   `json:"is_default"`
 		Enabled    bool                   `json:"enabled"`
+		Connection *db.ConnectionOptions  `json:"connection"` // replaces the proxy and TLS overrides if set
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -352,11 +473,30 @@ func (h *Handler) UpdateCluster(c *gin.Context) {
 		return
 	}
 
+	// Replace the proxy and TLS overrides if provided
+	if req.Connection != nil {
+		if err := cluster.ValidateConnectionOptions(req.Connection); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if existingCluster.AuthType == "agent" && *req.Connection != (db.ConnectionOptions{}) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "connection options do not apply to agent clusters"})
+			return
+		}
+		connectionJSON, _ := json.Marshal(req.Connection)
+		existingCluster.Connection = db.JSON(connectionJSON)
+		h.clusterManager.SetConnectionOptions(name, req.Connection)
+	}
+
 	// Handle auth_config update if provided
 	if req.AuthConfig != nil && len(req.AuthConfig) > 0 {
 		// Remove old cluster from manager
 		h.clusterManager.RemoveCluster(name)
 
+		// New credentials are no longer rotated on schedule
+		existingCluster.CredentialsRotatedAt = nil
+		existingCluster.CredentialsExpireAt = nil
+
 		// Determine auth type (use provided or keep existing)
 		authType := req.AuthType
 		if authType == "" {
@@ -439,10 +579,43 @@ func (h *Handler) UpdateCluster(c *gin.Context) {
 			authConfigJSON, _ := json.Marshal(req.AuthConfig)
 			existingCluster.AuthConfig = db.JSON(authConfigJSON)
 
+		case "exec", "eks", "gke", "aks":
+			authConfigJSON, _ := json.Marshal(req.AuthConfig)
+			if err := h.clusterManager.ValidateAuthConfig(authType, authConfigJSON); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			if err := h.clusterManager.AddClusterFromAuthConfig(name, authType, authConfigJSON); err != nil {
+				log.Errorf("Failed to update cluster: %v", err)
+				existingCluster.Status = "error"
+				h.db.SaveCluster(existingCluster)
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Failed to connect to cluster: %v", err)})
+				return
+			}
+
+			// Update cluster fields
+			existingCluster.Server, _ = req.AuthConfig["server"].(string)
+			existingCluster.CA = ""
+			existingCluster.Token = ""
+			existingCluster.AuthType = authType
+			existingCluster.Status = "connected"
+			existingCluster.AuthConfig = db.JSON(authConfigJSON)
+
 		default:
 			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("unsupported auth_type: %s", authType)})
 			return
 		}
+	} else if req.Connection != nil && existingCluster.Enabled && existingCluster.AuthType != "agent" {
+		// Reconnect with the new connection options
+		if err := h.clusterManager.LoadCluster(existingCluster); err != nil {
+			log.Errorf("Failed to update cluster: %v", err)
+			existingCluster.Status = "error"
+			h.db.SaveCluster(existingCluster)
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Failed to connect to cluster: %v", err)})
+			return
+		}
+		existingCluster.Status = "connected"
 	}
 
 	// Update other fields
@@ -455,6 +628,7 @@ func (h *Handler) UpdateCluster(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	h.clusterManager.SetImpersonation(name, existingCluster.Impersonate)
 
 	c.JSON(http.StatusOK, gin.H{"message": "Cluster updated successfully"})
 }
@@ -490,6 +664,13 @@ func (h *Handler) UpdateClusterEnabled(c *gin.Context) {
 	if req.Enabled {
 		// Re-add cluster to manager based on auth type
 		var addErr error
+
+		if err := h.clusterManager.UseStoredConnectionOptions(cluster); err != nil {
+			log.Errorf("Failed to parse connection options: %v", err)
+			h.db.UpdateClusterStatus(name, "error")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse cluster configuration"})
+			return
+		}
 		
 		switch cluster.AuthType {
 		case "kubeconfig":
@@ -523,6 +704,15 @@ func (h *Handler) UpdateClusterEnabled(c *gin.Context) {
 				c.JSON(http.StatusInternalServerError, gin.H{"error": "Incomplete cluster configuration"})
 				return
 			}
+
+		case "exec", "eks", "gke", "aks":
+			addErr = h.clusterManager.AddClusterFromAuthConfig(name, cluster.AuthType, cluster.AuthConfig)
+
+		case "in_cluster":
+			addErr = h.clusterManager.AddClusterInCluster(name)
+
+		case "agent":
+			addErr = h.clusterManager.AddClusterFromAgent(name)
 			
 		default:
 			log.Errorf("Unsupported auth type: %s", cluster.AuthType)
@@ -538,6 +728,7 @@ func (h *Handler) UpdateClusterEnabled(c *gin.Context) {
 		} else {
 			log.Infof("Successfully re-enabled cluster: %s", name)
 			h.db.UpdateClusterStatus(name, "connected")
+			h.clusterManager.SetImpersonation(name, cluster.Impersonate)
 		}
 	} else {
 		// Remove cluster from manager if disabling
@@ -566,38 +757,28 @@ func (h *Handler) UpdateClusterEnabled(c *gin.Context) {
 }
 
 // RemoveCluster removes a cluster (deletes from both manager and database)
+// Query: cleanup_rbac=true also deletes the kubelens ServiceAccount and binding in the cluster
 func (h *Handler) RemoveCluster(c *gin.Context) {
 	name := c.Param("name")
+	cleanupRBAC := c.Query("cleanup_rbac") == "true"
 
-	// Remove from in-memory manager
-	if err := h.clusterManager.RemoveCluster(name); err != nil {
-		log.Errorf("Failed to remove cluster from manager: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-
-	// Delete from database
-	if err := h.db.DeleteCluster(name); err != nil {
-		log.Errorf("Failed to delete cluster from database: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	report, err := h.offboardCluster(name, cleanupRBAC)
+	if err != nil {
+		log.Errorf("Failed to offboard cluster %s: %v", name, err)
+		status := http.StatusInternalServerError
+		if errors.Is(err, errClusterNotConnected) {
+			status = http.StatusConflict
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
 		return
 	}
 
 	log.Infof("Deleted cluster: %s", name)
 
 	// Audit log
-	if userID, exists := c.Get("user_id"); exists {
-		username, _ := c.Get("username")
-		email, _ := c.Get("email")
-		
-		audit.Log(c, audit.EventClusterRemoved, userID.(int), username.(string), email.(string),
-			fmt.Sprintf("Removed cluster: %s", name),
-			map[string]interface{}{
-				"cluster_name": name,
-			})
-	}
+	auditOffboarding(c, report)
 
-	c.JSON(http.StatusOK, gin.H{"message": "Cluster removed successfully"})
+	c.JSON(http.StatusOK, gin.H{"message": "Cluster removed successfully", "report": report})
 }
 
 // GetClusterStatus returns the status of a cluster
@@ -618,7 +799,7 @@ func (h *Handler) GetClusterStatus(c *gin.Context) {
 func (h *Handler) ListNamespaces(c *gin.Context) {
 	clusterName := c.Param("name")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -651,7 +832,7 @@ func (h *Handler) GetNamespace(c *gin.Context) {
 	clusterName := c.Param("name")
 	namespaceName := c.Param("namespace")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -688,7 +869,7 @@ func (h *Handler) UpdateNamespace(c *gin.Context) {
 	clusterName := c.Param("name")
 	namespaceName := c.Param("namespace")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -705,7 +886,7 @@ func (h *Handler) UpdateNamespace(c *gin.Context) {
 		ns.ObjectMeta.Name = namespaceName
 	}
 
-	updatedNS, err := client.CoreV1().Namespaces().Update(context.Background(), &ns, metav1.UpdateOptions{})
+	updatedNS, err := client.CoreV1().Namespaces().Update(context.Background(), &ns, updateOptions(c))
 	if err != nil {
 		log.Errorf("Failed to update namespace: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -720,13 +901,13 @@ func (h *Handler) DeleteNamespace(c *gin.Context) {
 	clusterName := c.Param("name")
 	namespaceName := c.Param("namespace")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
 
-	err = client.CoreV1().Namespaces().Delete(context.Background(), namespaceName, metav1.DeleteOptions{})
+	err = client.CoreV1().Namespaces().Delete(context.Background(), namespaceName, deleteOptions(c))
 	if err != nil {
 		log.Errorf("Failed to delete namespace: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -748,7 +929,7 @@ func (h *Handler) ListPods(c *gin.Context) {
 		namespace = metav1.NamespaceAll
 	}
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -804,6 +985,17 @@ func (h *Handler) ListPods(c *gin.Context) {
 		}
 	}
 
+	if wantsNDJSON(c) {
+		streamList(c, listOptions, func(ctx context.Context, opts metav1.ListOptions) ([]corev1.Pod, string, error) {
+			list, err := client.CoreV1().Pods(namespace).List(ctx, opts)
+			if err != nil {
+				return nil, "", err
+			}
+			return list.Items, list.Continue, nil
+		})
+		return
+	}
+
 	pods, err := client.CoreV1().Pods(namespace).List(context.Background(), listOptions)
 	if err != nil {
 		log.Errorf("Failed to list pods: %v", err)
@@ -820,7 +1012,7 @@ func (h *Handler) GetPod(c *gin.Context) {
 	namespace := c.Param("namespace")
 	podName := c.Param("pod")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -842,13 +1034,13 @@ func (h *Handler) DeletePod(c *gin.Context) {
 	namespace := c.Param("namespace")
 	podName := c.Param("pod")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
 
-	err = client.CoreV1().Pods(namespace).Delete(context.Background(), podName, metav1.DeleteOptions{})
+	err = client.CoreV1().Pods(namespace).Delete(context.Background(), podName, deleteOptions(c))
 	if err != nil {
 		log.Errorf("Failed to delete pod: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -864,7 +1056,7 @@ func (h *Handler) EvictPod(c *gin.Context) {
 	namespace := c.Param("namespace")
 	podName := c.Param("pod")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -886,6 +1078,7 @@ func (h *Handler) EvictPod(c *gin.Context) {
 		},
 		DeleteOptions: &metav1.DeleteOptions{
 			GracePeriodSeconds: pod.Spec.TerminationGracePeriodSeconds,
+			DryRun:             dryRunValue(c),
 		},
 	}
 
@@ -901,8 +1094,20 @@ func (h *Handler) EvictPod(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Pod evicted successfully"})
 }
 
-// GetPodLogs returns logs from a pod
+// GetPodLogs returns logs from a pod (source=loki: historical logs from the Loki datasource).
+// match, invert, level and field filter the lines (parseLogFilter); structured=true returns
+// them parsed as records (logRecord) instead of text.
 func (h *Handler) GetPodLogs(c *gin.Context) {
+	filter, err := parseLogFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if c.Query("source") == "loki" {
+		h.getPodLogsFromLoki(c, filter)
+		return
+	}
+
 	clusterName := c.Param("name")
 	namespace := c.Param("namespace")
 	podName := c.Param("pod")
@@ -911,7 +1116,7 @@ func (h *Handler) GetPodLogs(c *gin.Context) {
 	previous := c.Query("previous")
 	sinceTime := c.Query("sinceTime")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -948,7 +1153,28 @@ func (h *Handler) GetPodLogs(c *gin.Context) {
 	}
 	defer logs.Close()
 
-	// Read logs
+	if c.Query("structured") == "true" {
+		records, err := readLogRecords(logs, filter, false, podName, container)
+		if err != nil {
+			log.Errorf("Failed to read pod logs: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"records": records})
+		return
+	}
+
+	// Read logs, keeping the lines of the filter
+	if filter != nil {
+		filtered, err := filter.filterLogs(logs)
+		if err != nil {
+			log.Errorf("Failed to read pod logs: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"logs": filtered})
+		return
+	}
 	logData, err := io.ReadAll(logs)
 	if err != nil {
 		log.Errorf("Failed to read pod logs: %v", err)
@@ -959,7 +1185,8 @@ func (h *Handler) GetPodLogs(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"logs": string(logData)})
 }
 
-// GetMultiPodLogs returns logs from multiple pods
+// GetMultiPodLogs returns logs from multiple pods, filtered and parsed (structured=true) like
+// those of GetPodLogs
 func (h *Handler) GetMultiPodLogs(c *gin.Context) {
 	clusterName := c.Param("name")
 	namespace := c.Param("namespace")
@@ -971,13 +1198,19 @@ func (h *Handler) GetMultiPodLogs(c *gin.Context) {
 	previous := c.Query("previous")
 	sinceTime := c.Query("sinceTime")
 	timestamps := c.Query("timestamps") == "true"
+	structured := c.Query("structured") == "true"
 
 	if len(pods) == 0 {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "No pods specified"})
 		return
 	}
+	filter, err := parseLogFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -1008,9 +1241,10 @@ func (h *Handler) GetMultiPodLogs(c *gin.Context) {
 
 	// Collect logs from all pods
 	type PodLogs struct {
-		PodName string `json:"podName"`
-		Logs    string `json:"logs"`
-		Error   string `json:"error,omitempty"`
+		PodName string      `json:"podName"`
+		Logs    string      `json:"logs"`
+		Records []logRecord `json:"records,omitempty"`
+		Error   string      `json:"error,omitempty"`
 	}
 
 	results := make([]PodLogs, 0, len(pods))
@@ -1028,6 +1262,17 @@ func (h *Handler) GetMultiPodLogs(c *gin.Context) {
 			continue
 		}
 		
+		if structured {
+			podLog.Records, err = readLogRecords(logs, filter, timestamps, podName, container)
+			logs.Close()
+			if err != nil {
+				log.Warnf("Failed to read logs for pod %s: %v", podName, err)
+				podLog.Error = err.Error()
+			}
+			results = append(results, podLog)
+			continue
+		}
+
 		// Read logs
 		logData, err := io.ReadAll(logs)
 		logs.Close()
@@ -1039,8 +1284,12 @@ func (h *Handler) GetMultiPodLogs(c *gin.Context) {
 			// Format logs with pod name prefix
 			logLines := strings.Split(string(logData), "\n")
 			formattedLines := make([]string, 0, len(logLines))
+			keep := func(string) bool { return true }
+			if filter != nil {
+				keep = filter.lines()
+			}
 			for _, line := range logLines {
-				if line != "" {
+				if line != "" && keep(line) {
 					formattedLines = append(formattedLines, fmt.Sprintf("[%s] %s", podName, line))
 				}
 			}
@@ -1062,7 +1311,7 @@ func (h *Handler) ListDeployments(c *gin.Context) {
 		namespace = metav1.NamespaceAll
 	}
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -1084,7 +1333,7 @@ func (h *Handler) GetDeployment(c *gin.Context) {
 	namespace := c.Param("namespace")
 	deploymentName := c.Param("deployment")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -1106,7 +1355,7 @@ func (h *Handler) UpdateDeployment(c *gin.Context) {
 	namespace := c.Param("namespace")
 	deploymentName := c.Param("deployment")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -1122,7 +1371,7 @@ func (h *Handler) UpdateDeployment(c *gin.Context) {
 	deployment.Name = deploymentName
 	deployment.Namespace = namespace
 
-	updatedDeployment, err := client.AppsV1().Deployments(namespace).Update(context.Background(), &deployment, metav1.UpdateOptions{})
+	updatedDeployment, err := client.AppsV1().Deployments(namespace).Update(context.Background(), &deployment, updateOptions(c))
 	if err != nil {
 		log.Errorf("Failed to update deployment: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -1138,13 +1387,13 @@ func (h *Handler) DeleteDeployment(c *gin.Context) {
 	namespace := c.Param("namespace")
 	deploymentName := c.Param("deployment")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
 
-	err = client.AppsV1().Deployments(namespace).Delete(context.Background(), deploymentName, metav1.DeleteOptions{})
+	err = client.AppsV1().Deployments(namespace).Delete(context.Background(), deploymentName, deleteOptions(c))
 	if err != nil {
 		log.Errorf("Failed to delete deployment: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -1169,7 +1418,7 @@ func (h *Handler) ScaleDeployment(c *gin.Context) {
 		return
 	}
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -1185,7 +1434,7 @@ func (h *Handler) ScaleDeployment(c *gin.Context) {
 
 	// Update replicas
 	deployment.Spec.Replicas = &req.Replicas
-	_, err = client.AppsV1().Deployments(namespace).Update(context.Background(), deployment, metav1.UpdateOptions{})
+	_, err = client.AppsV1().Deployments(namespace).Update(context.Background(), deployment, updateOptions(c))
 	if err != nil {
 		log.Errorf("Failed to scale deployment: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -1201,7 +1450,7 @@ func (h *Handler) RestartDeployment(c *gin.Context) {
 	namespace := c.Param("namespace")
 	deploymentName := c.Param("deployment")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -1222,7 +1471,7 @@ func (h *Handler) RestartDeployment(c *gin.Context) {
 	deployment.Spec.Template.Annotations["kubectl.kubernetes.io/restartedAt"] = metav1.Now().Format("2006-01-02T15:04:05Z07:00")
 
 	// Update deployment
-	_, err = client.AppsV1().Deployments(namespace).Update(context.Background(), deployment, metav1.UpdateOptions{})
+	_, err = client.AppsV1().Deployments(namespace).Update(context.Background(), deployment, updateOptions(c))
 	if err != nil {
 		log.Errorf("Failed to restart deployment: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -1241,7 +1490,7 @@ func (h *Handler) ListDaemonSets(c *gin.Context) {
 		namespace = metav1.NamespaceAll
 	}
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -1263,7 +1512,7 @@ func (h *Handler) GetDaemonSet(c *gin.Context) {
 	namespace := c.Param("namespace")
 	daemonsetName := c.Param("daemonset")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -1285,7 +1534,7 @@ func (h *Handler) UpdateDaemonSet(c *gin.Context) {
 	namespace := c.Param("namespace")
 	daemonsetName := c.Param("daemonset")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -1301,7 +1550,7 @@ func (h *Handler) UpdateDaemonSet(c *gin.Context) {
 	daemonset.Name = daemonsetName
 	daemonset.Namespace = namespace
 
-	updatedDaemonSet, err := client.AppsV1().DaemonSets(namespace).Update(context.Background(), &daemonset, metav1.UpdateOptions{})
+	updatedDaemonSet, err := client.AppsV1().DaemonSets(namespace).Update(context.Background(), &daemonset, updateOptions(c))
 	if err != nil {
 		log.Errorf("Failed to update daemonset: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -1317,13 +1566,13 @@ func (h *Handler) DeleteDaemonSet(c *gin.Context) {
 	namespace := c.Param("namespace")
 	daemonsetName := c.Param("daemonset")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
 
-	err = client.AppsV1().DaemonSets(namespace).Delete(context.Background(), daemonsetName, metav1.DeleteOptions{})
+	err = client.AppsV1().DaemonSets(namespace).Delete(context.Background(), daemonsetName, deleteOptions(c))
 	if err != nil {
 		log.Errorf("Failed to delete daemonset: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -1339,7 +1588,7 @@ func (h *Handler) RestartDaemonSet(c *gin.Context) {
 	namespace := c.Param("namespace")
 	daemonsetName := c.Param("daemonset")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -1360,7 +1609,7 @@ func (h *Handler) RestartDaemonSet(c *gin.Context) {
 	daemonset.Spec.Template.Annotations["kubectl.kubernetes.io/restartedAt"] = metav1.Now().Format("2006-01-02T15:04:05Z07:00")
 
 	// Update daemonset
-	_, err = client.AppsV1().DaemonSets(namespace).Update(context.Background(), daemonset, metav1.UpdateOptions{})
+	_, err = client.AppsV1().DaemonSets(namespace).Update(context.Background(), daemonset, updateOptions(c))
 	if err != nil {
 		log.Errorf("Failed to restart daemonset: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -1379,7 +1628,7 @@ func (h *Handler) ListStatefulSets(c *gin.Context) {
 		namespace = metav1.NamespaceAll
 	}
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -1401,7 +1650,7 @@ func (h *Handler) GetStatefulSet(c *gin.Context) {
 	namespace := c.Param("namespace")
 	statefulsetName := c.Param("statefulset")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -1423,7 +1672,7 @@ func (h *Handler) UpdateStatefulSet(c *gin.Context) {
 	namespace := c.Param("namespace")
 	statefulsetName := c.Param("statefulset")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -1439,7 +1688,7 @@ func (h *Handler) UpdateStatefulSet(c *gin.Context) {
 	statefulset.Name = statefulsetName
 	statefulset.Namespace = namespace
 
-	updatedStatefulSet, err := client.AppsV1().StatefulSets(namespace).Update(context.Background(), &statefulset, metav1.UpdateOptions{})
+	updatedStatefulSet, err := client.AppsV1().StatefulSets(namespace).Update(context.Background(), &statefulset, updateOptions(c))
 	if err != nil {
 		log.Errorf("Failed to update statefulset: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -1455,13 +1704,13 @@ func (h *Handler) DeleteStatefulSet(c *gin.Context) {
 	namespace := c.Param("namespace")
 	statefulsetName := c.Param("statefulset")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
 
-	err = client.AppsV1().StatefulSets(namespace).Delete(context.Background(), statefulsetName, metav1.DeleteOptions{})
+	err = client.AppsV1().StatefulSets(namespace).Delete(context.Background(), statefulsetName, deleteOptions(c))
 	if err != nil {
 		log.Errorf("Failed to delete statefulset: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -1477,7 +1726,7 @@ func (h *Handler) ScaleStatefulSet(c *gin.Context) {
 	namespace := c.Param("namespace")
 	statefulsetName := c.Param("statefulset")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -1501,7 +1750,7 @@ func (h *Handler) ScaleStatefulSet(c *gin.Context) {
 
 	// Update replicas
 	statefulset.Spec.Replicas = &scaleRequest.Replicas
-	_, err = client.AppsV1().StatefulSets(namespace).Update(context.Background(), statefulset, metav1.UpdateOptions{})
+	_, err = client.AppsV1().StatefulSets(namespace).Update(context.Background(), statefulset, updateOptions(c))
 	if err != nil {
 		log.Errorf("Failed to scale statefulset: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -1517,7 +1766,7 @@ func (h *Handler) RestartStatefulSet(c *gin.Context) {
 	namespace := c.Param("namespace")
 	statefulsetName := c.Param("statefulset")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -1538,7 +1787,7 @@ func (h *Handler) RestartStatefulSet(c *gin.Context) {
 	statefulset.Spec.Template.Annotations["kubectl.kubernetes.io/restartedAt"] = metav1.Now().Format("2006-01-02T15:04:05Z07:00")
 
 	// Update statefulset
-	_, err = client.AppsV1().StatefulSets(namespace).Update(context.Background(), statefulset, metav1.UpdateOptions{})
+	_, err = client.AppsV1().StatefulSets(namespace).Update(context.Background(), statefulset, updateOptions(c))
 	if err != nil {
 		log.Errorf("Failed to restart statefulset: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -1557,12 +1806,23 @@ func (h *Handler) ListReplicaSets(c *gin.Context) {
 		namespace = metav1.NamespaceAll
 	}
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
 
+	if wantsNDJSON(c) {
+		streamList(c, metav1.ListOptions{}, func(ctx context.Context, opts metav1.ListOptions) ([]appsv1.ReplicaSet, string, error) {
+			list, err := client.AppsV1().ReplicaSets(namespace).List(ctx, opts)
+			if err != nil {
+				return nil, "", err
+			}
+			return list.Items, list.Continue, nil
+		})
+		return
+	}
+
 	replicasets, err := client.AppsV1().ReplicaSets(namespace).List(context.Background(), metav1.ListOptions{})
 	if err != nil {
 		log.Errorf("Failed to list replicasets: %v", err)
@@ -1579,7 +1839,7 @@ func (h *Handler) GetReplicaSet(c *gin.Context) {
 	namespace := c.Param("namespace")
 	replicasetName := c.Param("replicaset")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -1601,7 +1861,7 @@ func (h *Handler) UpdateReplicaSet(c *gin.Context) {
 	namespace := c.Param("namespace")
 	replicasetName := c.Param("replicaset")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -1617,7 +1877,7 @@ func (h *Handler) UpdateReplicaSet(c *gin.Context) {
 	replicaset.Name = replicasetName
 	replicaset.Namespace = namespace
 
-	updatedReplicaSet, err := client.AppsV1().ReplicaSets(namespace).Update(context.Background(), &replicaset, metav1.UpdateOptions{})
+	updatedReplicaSet, err := client.AppsV1().ReplicaSets(namespace).Update(context.Background(), &replicaset, updateOptions(c))
 	if err != nil {
 		log.Errorf("Failed to update replicaset: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -1633,13 +1893,13 @@ func (h *Handler) DeleteReplicaSet(c *gin.Context) {
 	namespace := c.Param("namespace")
 	replicasetName := c.Param("replicaset")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
 
-	err = client.AppsV1().ReplicaSets(namespace).Delete(context.Background(), replicasetName, metav1.DeleteOptions{})
+	err = client.AppsV1().ReplicaSets(namespace).Delete(context.Background(), replicasetName, deleteOptions(c))
 	if err != nil {
 		log.Errorf("Failed to delete replicaset: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -1655,7 +1915,7 @@ func (h *Handler) ScaleReplicaSet(c *gin.Context) {
 	namespace := c.Param("namespace")
 	replicasetName := c.Param("replicaset")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -1679,7 +1939,7 @@ func (h *Handler) ScaleReplicaSet(c *gin.Context) {
 
 	// Update replicas
 	replicaset.Spec.Replicas = &scaleRequest.Replicas
-	_, err = client.AppsV1().ReplicaSets(namespace).Update(context.Background(), replicaset, metav1.UpdateOptions{})
+	_, err = client.AppsV1().ReplicaSets(namespace).Update(context.Background(), replicaset, updateOptions(c))
 	if err != nil {
 		log.Errorf("Failed to scale replicaset: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -1699,7 +1959,7 @@ func (h *Handler) ListJobs(c *gin.Context) {
 		namespace = metav1.NamespaceAll
 	}
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -1736,7 +1996,7 @@ func (h *Handler) GetJob(c *gin.Context) {
 	namespace := c.Param("namespace")
 	jobName := c.Param("job")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -1758,7 +2018,7 @@ func (h *Handler) UpdateJob(c *gin.Context) {
 	namespace := c.Param("namespace")
 	jobName := c.Param("job")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -1774,7 +2034,7 @@ func (h *Handler) UpdateJob(c *gin.Context) {
 	job.Name = jobName
 	job.Namespace = namespace
 
-	updatedJob, err := client.BatchV1().Jobs(namespace).Update(context.Background(), &job, metav1.UpdateOptions{})
+	updatedJob, err := client.BatchV1().Jobs(namespace).Update(context.Background(), &job, updateOptions(c))
 	if err != nil {
 		log.Errorf("Failed to update job: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -1790,7 +2050,7 @@ func (h *Handler) DeleteJob(c *gin.Context) {
 	namespace := c.Param("namespace")
 	jobName := c.Param("job")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -1799,6 +2059,7 @@ func (h *Handler) DeleteJob(c *gin.Context) {
 	propagationPolicy := metav1.DeletePropagationBackground
 	err = client.BatchV1().Jobs(namespace).Delete(context.Background(), jobName, metav1.DeleteOptions{
 		PropagationPolicy: &propagationPolicy,
+		DryRun:            dryRunValue(c),
 	})
 	if err != nil {
 		log.Errorf("Failed to delete job: %v", err)
@@ -1818,7 +2079,7 @@ func (h *Handler) ListCronJobs(c *gin.Context) {
 		namespace = metav1.NamespaceAll
 	}
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -1840,7 +2101,7 @@ func (h *Handler) GetCronJob(c *gin.Context) {
 	namespace := c.Param("namespace")
 	cronjobName := c.Param("cronjob")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -1862,7 +2123,7 @@ func (h *Handler) UpdateCronJob(c *gin.Context) {
 	namespace := c.Param("namespace")
 	cronjobName := c.Param("cronjob")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -1878,7 +2139,7 @@ func (h *Handler) UpdateCronJob(c *gin.Context) {
 	cronjob.Name = cronjobName
 	cronjob.Namespace = namespace
 
-	updatedCronJob, err := client.BatchV1().CronJobs(namespace).Update(context.Background(), &cronjob, metav1.UpdateOptions{})
+	updatedCronJob, err := client.BatchV1().CronJobs(namespace).Update(context.Background(), &cronjob, updateOptions(c))
 	if err != nil {
 		log.Errorf("Failed to update cronjob: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -1894,7 +2155,7 @@ func (h *Handler) DeleteCronJob(c *gin.Context) {
 	namespace := c.Param("namespace")
 	cronjobName := c.Param("cronjob")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -1903,6 +2164,7 @@ func (h *Handler) DeleteCronJob(c *gin.Context) {
 	propagationPolicy := metav1.DeletePropagationBackground
 	err = client.BatchV1().CronJobs(namespace).Delete(context.Background(), cronjobName, metav1.DeleteOptions{
 		PropagationPolicy: &propagationPolicy,
+		DryRun:            dryRunValue(c),
 	})
 	if err != nil {
 		log.Errorf("Failed to delete cronjob: %v", err)
@@ -1913,6 +2175,57 @@ func (h *Handler) DeleteCronJob(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "CronJob deleted successfully"})
 }
 
+// SuspendCronJob suspends or resumes a cronjob by patching .spec.suspend
+func (h *Handler) SuspendCronJob(c *gin.Context) {
+	clusterName := c.Param("name")
+	namespace := c.Param("namespace")
+	cronjobName := c.Param("cronjob")
+
+	var req struct {
+		Suspend *bool `json:"suspend" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	client, err := h.client(c, clusterName)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	patch := []byte(fmt.Sprintf(`{"spec":{"suspend":%t}}`, *req.Suspend))
+	cronjob, err := client.BatchV1().CronJobs(namespace).Patch(context.Background(), cronjobName, types.MergePatchType, patch, patchOptions(c))
+	if err != nil {
+		log.Errorf("Failed to patch cronjob suspend: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Audit log
+	if userID, exists := c.Get("user_id"); exists {
+		username, _ := c.Get("username")
+		email, _ := c.Get("email")
+
+		action := "Resumed"
+		if *req.Suspend {
+			action = "Suspended"
+		}
+		audit.Log(c, audit.EventAuditResourceUpdated, userID.(int), username.(string), email.(string),
+			fmt.Sprintf("%s cronjob: %s/%s", action, namespace, cronjobName),
+			map[string]interface{}{
+				"cluster_name": clusterName,
+				"namespace":    namespace,
+				"kind":         "CronJob",
+				"name":         cronjobName,
+				"suspend":      *req.Suspend,
+			})
+	}
+
+	c.JSON(http.StatusOK, cronjob)
+}
+
 // ListServices returns a list of services
 func (h *Handler) ListServices(c *gin.Context) {
 	clusterName := c.Param("name")
@@ -1922,7 +2235,7 @@ func (h *Handler) ListServices(c *gin.Context) {
 		namespace = metav1.NamespaceAll
 	}
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -1944,7 +2257,7 @@ func (h *Handler) GetService(c *gin.Context) {
 	namespace := c.Param("namespace")
 	serviceName := c.Param("service")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -1966,13 +2279,13 @@ func (h *Handler) DeleteService(c *gin.Context) {
 	namespace := c.Param("namespace")
 	serviceName := c.Param("service")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
 
-	err = client.CoreV1().Services(namespace).Delete(context.Background(), serviceName, metav1.DeleteOptions{})
+	err = client.CoreV1().Services(namespace).Delete(context.Background(), serviceName, deleteOptions(c))
 	if err != nil {
 		log.Errorf("Failed to delete service: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -1988,7 +2301,7 @@ func (h *Handler) UpdateService(c *gin.Context) {
 	namespace := c.Param("namespace")
 	serviceName := c.Param("service")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -2004,7 +2317,7 @@ func (h *Handler) UpdateService(c *gin.Context) {
 	service.Name = serviceName
 	service.Namespace = namespace
 
-	updatedService, err := client.CoreV1().Services(namespace).Update(context.Background(), &service, metav1.UpdateOptions{})
+	updatedService, err := client.CoreV1().Services(namespace).Update(context.Background(), &service, updateOptions(c))
 	if err != nil {
 		log.Errorf("Failed to update service: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -2023,12 +2336,23 @@ func (h *Handler) ListConfigMaps(c *gin.Context) {
 		namespace = metav1.NamespaceAll
 	}
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
 
+	if wantsNDJSON(c) {
+		streamList(c, metav1.ListOptions{}, func(ctx context.Context, opts metav1.ListOptions) ([]corev1.ConfigMap, string, error) {
+			list, err := client.CoreV1().ConfigMaps(namespace).List(ctx, opts)
+			if err != nil {
+				return nil, "", err
+			}
+			return list.Items, list.Continue, nil
+		})
+		return
+	}
+
 	configMaps, err := client.CoreV1().ConfigMaps(namespace).List(context.Background(), metav1.ListOptions{})
 	if err != nil {
 		log.Errorf("Failed to list configmaps: %v", err)
@@ -2044,7 +2368,7 @@ func (h *Handler) CreateConfigMap(c *gin.Context) {
 	clusterName := c.Param("name")
 	namespace := c.Param("namespace")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -2059,7 +2383,7 @@ func (h *Handler) CreateConfigMap(c *gin.Context) {
 	// Ensure namespace matches URL parameter
 	configMap.Namespace = namespace
 
-	createdConfigMap, err := client.CoreV1().ConfigMaps(namespace).Create(context.Background(), &configMap, metav1.CreateOptions{})
+	createdConfigMap, err := client.CoreV1().ConfigMaps(namespace).Create(context.Background(), &configMap, createOptions(c))
 	if err != nil {
 		log.Errorf("Failed to create configmap: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -2075,7 +2399,7 @@ func (h *Handler) GetConfigMap(c *gin.Context) {
 	namespace := c.Param("namespace")
 	configMapName := c.Param("configmap")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -2097,7 +2421,7 @@ func (h *Handler) UpdateConfigMap(c *gin.Context) {
 	namespace := c.Param("namespace")
 	configMapName := c.Param("configmap")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -2113,7 +2437,7 @@ func (h *Handler) UpdateConfigMap(c *gin.Context) {
 	configMap.Name = configMapName
 	configMap.Namespace = namespace
 
-	updatedConfigMap, err := client.CoreV1().ConfigMaps(namespace).Update(context.Background(), &configMap, metav1.UpdateOptions{})
+	updatedConfigMap, err := client.CoreV1().ConfigMaps(namespace).Update(context.Background(), &configMap, updateOptions(c))
 	if err != nil {
 		log.Errorf("Failed to update configmap: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -2129,13 +2453,13 @@ func (h *Handler) DeleteConfigMap(c *gin.Context) {
 	namespace := c.Param("namespace")
 	configMapName := c.Param("configmap")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
 
-	err = client.CoreV1().ConfigMaps(namespace).Delete(context.Background(), configMapName, metav1.DeleteOptions{})
+	err = client.CoreV1().ConfigMaps(namespace).Delete(context.Background(), configMapName, deleteOptions(c))
 	if err != nil {
 		log.Errorf("Failed to delete configmap: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -2154,12 +2478,23 @@ func (h *Handler) ListSecrets(c *gin.Context) {
 		namespace = metav1.NamespaceAll
 	}
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
 
+	if wantsNDJSON(c) {
+		streamList(c, metav1.ListOptions{}, func(ctx context.Context, opts metav1.ListOptions) ([]corev1.Secret, string, error) {
+			list, err := client.CoreV1().Secrets(namespace).List(ctx, opts)
+			if err != nil {
+				return nil, "", err
+			}
+			return list.Items, list.Continue, nil
+		})
+		return
+	}
+
 	secrets, err := client.CoreV1().Secrets(namespace).List(context.Background(), metav1.ListOptions{})
 	if err != nil {
 		log.Errorf("Failed to list secrets: %v", err)
@@ -2176,7 +2511,7 @@ func (h *Handler) GetSecret(c *gin.Context) {
 	namespace := c.Param("namespace")
 	secretName := c.Param("secret")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -2198,7 +2533,7 @@ func (h *Handler) UpdateSecret(c *gin.Context) {
 	namespace := c.Param("namespace")
 	secretName := c.Param("secret")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -2214,7 +2549,7 @@ func (h *Handler) UpdateSecret(c *gin.Context) {
 	secret.Name = secretName
 	secret.Namespace = namespace
 
-	updatedSecret, err := client.CoreV1().Secrets(namespace).Update(context.Background(), &secret, metav1.UpdateOptions{})
+	updatedSecret, err := client.CoreV1().Secrets(namespace).Update(context.Background(), &secret, updateOptions(c))
 	if err != nil {
 		log.Errorf("Failed to update secret: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -2230,13 +2565,13 @@ func (h *Handler) DeleteSecret(c *gin.Context) {
 	namespace := c.Param("namespace")
 	secretName := c.Param("secret")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
 
-	err = client.CoreV1().Secrets(namespace).Delete(context.Background(), secretName, metav1.DeleteOptions{})
+	err = client.CoreV1().Secrets(namespace).Delete(context.Background(), secretName, deleteOptions(c))
 	if err != nil {
 		log.Errorf("Failed to delete secret: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -2251,7 +2586,7 @@ func (h *Handler) CreateSecret(c *gin.Context) {
 	clusterName := c.Param("name")
 	namespace := c.Param("namespace")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -2266,7 +2601,7 @@ func (h *Handler) CreateSecret(c *gin.Context) {
 	// Ensure namespace matches URL parameter
 	secret.Namespace = namespace
 
-	createdSecret, err := client.CoreV1().Secrets(namespace).Create(context.Background(), &secret, metav1.CreateOptions{})
+	createdSecret, err := client.CoreV1().Secrets(namespace).Create(context.Background(), &secret, createOptions(c))
 	if err != nil {
 		log.Errorf("Failed to create secret: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -2285,7 +2620,7 @@ func (h *Handler) ListEndpoints(c *gin.Context) {
 		namespace = metav1.NamespaceAll
 	}
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -2307,7 +2642,7 @@ func (h *Handler) GetEndpoint(c *gin.Context) {
 	namespace := c.Param("namespace")
 	endpointName := c.Param("endpoint")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -2332,12 +2667,23 @@ func (h *Handler) ListEvents(c *gin.Context) {
 		namespace = metav1.NamespaceAll
 	}
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
 
+	if wantsNDJSON(c) {
+		streamList(c, metav1.ListOptions{}, func(ctx context.Context, opts metav1.ListOptions) ([]corev1.Event, string, error) {
+			list, err := client.CoreV1().Events(namespace).List(ctx, opts)
+			if err != nil {
+				return nil, "", err
+			}
+			return list.Items, list.Continue, nil
+		})
+		return
+	}
+
 	events, err := client.CoreV1().Events(namespace).List(context.Background(), metav1.ListOptions{})
 	if err != nil {
 		log.Errorf("Failed to list events: %v", err)
@@ -2357,152 +2703,181 @@ type SearchResult struct {
 	Namespace   string `json:"namespace,omitempty"`
 	Status      string `json:"status,omitempty"`
 	Description string `json:"description,omitempty"`
-}
-
-// Search searches across all resources in all clusters
+	// Group, Version and Resource locate custom resources (see ListCustomResources)
+	Group    string `json:"group,omitempty"`
+	Version  string `json:"version,omitempty"`
+	Resource string `json:"resource,omitempty"`
+}
+
+// Search searches the clusters and their pods, deployments, services, nodes and custom
+// resources, best matches first. Clusters indexed by the search indexer are searched in
+// memory; the others, and those whose requests impersonate the caller, are listed live,
+// without their custom resources.
+//
+// q takes search terms and filters (see search.Query), e.g. "api kind:pod ns:prod
+// status:CrashLoopBackOff label:app=web". Results are paged with page and limit; per_kind
+// pages each kind separately instead, per_kind results of each at a time. facets counts
+// all matches by kind. saved runs one of the caller's saved searches, narrowed further by q.
 func (h *Handler) Search(c *gin.Context) {
 	query := c.Query("q")
+	selectorQuery, groupName := c.Query("cluster_selector"), c.Query("cluster_group")
+	if id := c.Query("saved"); id != "" {
+		saved, ok := h.ownSavedSearch(c, id)
+		if !ok {
+			return
+		}
+		query = strings.TrimSpace(savedSearchQuery(saved) + " " + query)
+		if saved.ClusterSelector != "" {
+			selectorQuery = saved.ClusterSelector
+		}
+		if saved.ClusterGroup != "" {
+			groupName = saved.ClusterGroup
+		}
+	}
 	if query == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "query parameter 'q' is required"})
 		return
 	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive integer"})
+		return
+	}
+	if limit > 500 {
+		limit = 500
+	}
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "page must be a positive integer"})
+		return
+	}
+	perKind, err := strconv.Atoi(c.DefaultQuery("per_kind", "0"))
+	if err != nil || perKind < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "per_kind must be a positive integer"})
+		return
+	}
+
+	q, err := search.ParseQuery(query)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if q.Empty() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "query parameter 'q' is required"})
+		return
+	}
 
-	query = strings.ToLower(query)
-	results := []SearchResult{}
-	
 	// Get all clusters
-	clusters, err := h.clusterManager.ListClusters()
+	allClusters, err := h.clusterManager.ListClusters()
 	if err != nil {
 		log.Errorf("Failed to list clusters: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	// Narrow the search to clusters matching cluster_selector or cluster_group
+	selected, err := h.clusterFilterOf(selectorQuery, groupName)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	clusters := allClusters[:0]
+	for _, info := range allClusters {
+		if selected == nil || selected(info.Name) {
+			clusters = append(clusters, info)
+		}
+	}
+
 	// Search clusters themselves
+	var hits []search.Hit
 	for _, cluster := range clusters {
-		if strings.Contains(strings.ToLower(cluster.Name), query) ||
-			strings.Contains(strings.ToLower(cluster.Version), query) {
-			results = append(results, SearchResult{
-				ID:          fmt.Sprintf("cluster-%s", cluster.Name),
-				Type:        "cluster",
-				Name:        cluster.Name,
-				Status:      cluster.Status,
-				Description: cluster.Version,
-			})
+		doc := search.Document{Type: "cluster", Cluster: cluster.Name, Name: cluster.Name, Status: cluster.Status, Description: cluster.Version, Labels: cluster.Labels}
+		if score := q.Score(&doc); score > 0 {
+			hits = append(hits, search.Hit{Document: doc, Score: score})
 		}
 	}
 
 	// Search resources in each cluster
 	for _, cluster := range clusters {
-		client, err := h.clusterManager.GetClient(cluster.Name)
+		if h.searchIndex != nil && !h.clusterManager.Impersonates(cluster.Name) {
+			if indexed, ok := h.searchIndex.Search(cluster.Name, q); ok {
+				hits = append(hits, indexed...)
+				continue
+			}
+		}
+
+		client, err := h.client(c, cluster.Name)
 		if err != nil {
 			log.Warnf("Failed to get client for cluster %s: %v", cluster.Name, err)
 			continue
 		}
+		hits = append(hits, q.Match(search.List(c.Request.Context(), client, cluster.Name, q))...)
+	}
 
-		ctx := context.Background()
-
-		// Search Pods
-		pods, err := client.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
-		if err == nil {
-			for _, pod := range pods.Items {
-				if strings.Contains(strings.ToLower(pod.Name), query) ||
-					strings.Contains(strings.ToLower(pod.Namespace), query) {
-					status := "Unknown"
-					if pod.Status.Phase != "" {
-						status = string(pod.Status.Phase)
-					}
-					results = append(results, SearchResult{
-						ID:        fmt.Sprintf("pod-%s-%s-%s", cluster.Name, pod.Namespace, pod.Name),
-						Type:      "pod",
-						Name:      pod.Name,
-						Cluster:   cluster.Name,
-						Namespace: pod.Namespace,
-						Status:    status,
-					})
+	// Results outside the caller's scope are dropped before they are counted
+	if value, ok := c.Get("scope_search"); ok {
+		if keep, ok := value.(func(resultType, cluster, namespace string) bool); ok {
+			inScope := hits[:0]
+			for _, hit := range hits {
+				if keep(hit.Type, hit.Cluster, hit.Namespace) {
+					inScope = append(inScope, hit)
 				}
 			}
+			hits = inScope
 		}
+	}
 
-		// Search Deployments
-		deployments, err := client.AppsV1().Deployments(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
-		if err == nil {
-			for _, deployment := range deployments.Items {
-				if strings.Contains(strings.ToLower(deployment.Name), query) ||
-					strings.Contains(strings.ToLower(deployment.Namespace), query) {
-					status := "Unknown"
-					if deployment.Status.AvailableReplicas > 0 {
-						status = "Available"
-					} else {
-						status = "Unavailable"
-					}
-					results = append(results, SearchResult{
-						ID:        fmt.Sprintf("deployment-%s-%s-%s", cluster.Name, deployment.Namespace, deployment.Name),
-						Type:      "deployment",
-						Name:      deployment.Name,
-						Cluster:   cluster.Name,
-						Namespace: deployment.Namespace,
-						Status:    status,
-					})
-				}
+	hits = search.Rank(hits, 0)
+	total := len(hits)
+	facets := search.Facets(hits)
+	var totalPages int
+	if perKind > 0 {
+		hits = search.PerType(hits, page, perKind)
+		for _, facet := range facets {
+			if pages := (facet.Count + perKind - 1) / perKind; pages > totalPages {
+				totalPages = pages
 			}
 		}
-
-		// Search Services
-		services, err := client.CoreV1().Services(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
-		if err == nil {
-			for _, service := range services.Items {
-				if strings.Contains(strings.ToLower(service.Name), query) ||
-					strings.Contains(strings.ToLower(service.Namespace), query) {
-					results = append(results, SearchResult{
-						ID:        fmt.Sprintf("service-%s-%s-%s", cluster.Name, service.Namespace, service.Name),
-						Type:      "service",
-						Name:      service.Name,
-						Cluster:   cluster.Name,
-						Namespace: service.Namespace,
-						Status:    "Active",
-					})
-				}
-			}
+	} else {
+		totalPages = (total + limit - 1) / limit
+		if offset := (page - 1) * limit; offset < len(hits) {
+			hits = hits[offset:]
+		} else {
+			hits = nil
 		}
+	}
+	if len(hits) > limit {
+		hits = hits[:limit]
+	}
 
-		// Search Nodes
-		nodes, err := client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
-		if err == nil {
-			for _, node := range nodes.Items {
-				if strings.Contains(strings.ToLower(node.Name), query) {
-					status := "Unknown"
-					for _, condition := range node.Status.Conditions {
-						if condition.Type == corev1.NodeReady {
-							if condition.Status == corev1.ConditionTrue {
-								status = "Ready"
-							} else {
-								status = "NotReady"
-							}
-							break
-						}
-					}
-					results = append(results, SearchResult{
-						ID:      fmt.Sprintf("node-%s-%s", cluster.Name, node.Name),
-						Type:    "node",
-						Name:    node.Name,
-						Cluster: cluster.Name,
-						Status:  status,
-					})
-				}
-			}
+	results := []SearchResult{}
+	for _, hit := range hits {
+		result := SearchResult{
+			ID:          hit.ID(),
+			Type:        hit.Type,
+			Name:        hit.Name,
+			Cluster:     hit.Cluster,
+			Namespace:   hit.Namespace,
+			Status:      hit.Status,
+			Description: hit.Description,
+			Group:       hit.Group,
+			Version:     hit.Version,
+			Resource:    hit.Resource,
 		}
-
-		// Limit results to prevent overwhelming response
-		if len(results) >= 50 {
-			break
+		if hit.Type == "cluster" {
+			result.Cluster = ""
 		}
+		results = append(results, result)
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"query":   query,
-		"results": results,
-		"count":   len(results),
+		"query":       query,
+		"results":     results,
+		"count":       len(results),
+		"total":       total,
+		"facets":      facets,
+		"page":        page,
+		"total_pages": totalPages,
 	})
 }
 
@@ -2511,7 +2886,7 @@ func (h *Handler) ListHPAs(c *gin.Context) {
 	clusterName := c.Param("name")
 	namespace := c.Query("namespace")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -2557,7 +2932,7 @@ func (h *Handler) GetHPA(c *gin.Context) {
 	namespace := c.Param("namespace")
 	hpaName := c.Param("hpa")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -2592,13 +2967,13 @@ func (h *Handler) UpdateHPA(c *gin.Context) {
 		return
 	}
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
 
-	updatedHPA, err := client.AutoscalingV2().HorizontalPodAutoscalers(namespace).Update(context.Background(), &hpa, metav1.UpdateOptions{})
+	updatedHPA, err := client.AutoscalingV2().HorizontalPodAutoscalers(namespace).Update(context.Background(), &hpa, updateOptions(c))
 	if err != nil {
 		log.Errorf("Failed to update HPA: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -2614,13 +2989,13 @@ func (h *Handler) DeleteHPA(c *gin.Context) {
 	namespace := c.Param("namespace")
 	hpaName := c.Param("hpa")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
 
-	err = client.AutoscalingV2().HorizontalPodAutoscalers(namespace).Delete(context.Background(), hpaName, metav1.DeleteOptions{})
+	err = client.AutoscalingV2().HorizontalPodAutoscalers(namespace).Delete(context.Background(), hpaName, deleteOptions(c))
 	if err != nil {
 		log.Errorf("Failed to delete HPA: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -2635,7 +3010,7 @@ func (h *Handler) CreateHPA(c *gin.Context) {
 	clusterName := c.Param("name")
 	namespace := c.Param("namespace")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -2650,7 +3025,7 @@ func (h *Handler) CreateHPA(c *gin.Context) {
 	// Ensure namespace matches URL parameter
 	hpa.Namespace = namespace
 
-	createdHPA, err := client.AutoscalingV2().HorizontalPodAutoscalers(namespace).Create(context.Background(), &hpa, metav1.CreateOptions{})
+	createdHPA, err := client.AutoscalingV2().HorizontalPodAutoscalers(namespace).Create(context.Background(), &hpa, createOptions(c))
 	if err != nil {
 		log.Errorf("Failed to create HPA: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -2665,7 +3040,7 @@ func (h *Handler) ListPDBs(c *gin.Context) {
 	clusterName := c.Param("name")
 	namespace := c.Query("namespace")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -2712,7 +3087,7 @@ func (h *Handler) GetPDB(c *gin.Context) {
 	namespace := c.Param("namespace")
 	pdbName := c.Param("pdb")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -2740,7 +3115,7 @@ func (h *Handler) UpdatePDB(c *gin.Context) {
 	namespace := c.Param("namespace")
 	pdbName := c.Param("pdb")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -2756,7 +3131,7 @@ func (h *Handler) UpdatePDB(c *gin.Context) {
 	pdb.Name = pdbName
 	pdb.Namespace = namespace
 
-	updatedPDB, err := client.PolicyV1().PodDisruptionBudgets(namespace).Update(context.Background(), &pdb, metav1.UpdateOptions{})
+	updatedPDB, err := client.PolicyV1().PodDisruptionBudgets(namespace).Update(context.Background(), &pdb, updateOptions(c))
 	if err != nil {
 		log.Errorf("Failed to update PDB: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -2772,13 +3147,13 @@ func (h *Handler) DeletePDB(c *gin.Context) {
 	namespace := c.Param("namespace")
 	pdbName := c.Param("pdb")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
 
-	err = client.PolicyV1().PodDisruptionBudgets(namespace).Delete(context.Background(), pdbName, metav1.DeleteOptions{})
+	err = client.PolicyV1().PodDisruptionBudgets(namespace).Delete(context.Background(), pdbName, deleteOptions(c))
 	if err != nil {
 		log.Errorf("Failed to delete PDB: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -2793,7 +3168,7 @@ func (h *Handler) CreatePDB(c *gin.Context) {
 	clusterName := c.Param("name")
 	namespace := c.Param("namespace")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -2808,7 +3183,7 @@ func (h *Handler) CreatePDB(c *gin.Context) {
 	// Ensure namespace matches URL parameter
 	pdb.Namespace = namespace
 
-	createdPDB, err := client.PolicyV1().PodDisruptionBudgets(namespace).Create(context.Background(), &pdb, metav1.CreateOptions{})
+	createdPDB, err := client.PolicyV1().PodDisruptionBudgets(namespace).Create(context.Background(), &pdb, createOptions(c))
 	if err != nil {
 		log.Errorf("Failed to create PDB: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -2822,7 +3197,7 @@ func (h *Handler) CreatePDB(c *gin.Context) {
 func (h *Handler) ListPriorityClasses(c *gin.Context) {
 	clusterName := c.Param("name")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -2857,7 +3232,7 @@ func (h *Handler) GetPriorityClass(c *gin.Context) {
 	clusterName := c.Param("name")
 	pcName := c.Param("priorityclass")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -2886,7 +3261,7 @@ func (h *Handler) UpdatePriorityClass(c *gin.Context) {
 	clusterName := c.Param("name")
 	pcName := c.Param("priorityclass")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -2901,7 +3276,7 @@ func (h *Handler) UpdatePriorityClass(c *gin.Context) {
 	// Ensure name matches
 	pc.Name = pcName
 
-	updatedPC, err := client.SchedulingV1().PriorityClasses().Update(context.Background(), &pc, metav1.UpdateOptions{})
+	updatedPC, err := client.SchedulingV1().PriorityClasses().Update(context.Background(), &pc, updateOptions(c))
 	if err != nil {
 		log.Errorf("Failed to update priority class: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -2916,13 +3291,13 @@ func (h *Handler) DeletePriorityClass(c *gin.Context) {
 	clusterName := c.Param("name")
 	pcName := c.Param("priorityclass")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
 
-	err = client.SchedulingV1().PriorityClasses().Delete(context.Background(), pcName, metav1.DeleteOptions{})
+	err = client.SchedulingV1().PriorityClasses().Delete(context.Background(), pcName, deleteOptions(c))
 	if err != nil {
 		log.Errorf("Failed to delete priority class: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -2936,7 +3311,7 @@ func (h *Handler) DeletePriorityClass(c *gin.Context) {
 func (h *Handler) CreatePriorityClass(c *gin.Context) {
 	clusterName := c.Param("name")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -2948,7 +3323,7 @@ func (h *Handler) CreatePriorityClass(c *gin.Context) {
 		return
 	}
 
-	createdPC, err := client.SchedulingV1().PriorityClasses().Create(context.Background(), &pc, metav1.CreateOptions{})
+	createdPC, err := client.SchedulingV1().PriorityClasses().Create(context.Background(), &pc, createOptions(c))
 	if err != nil {
 		log.Errorf("Failed to create priority class: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -2962,7 +3337,7 @@ func (h *Handler) CreatePriorityClass(c *gin.Context) {
 func (h *Handler) ListRuntimeClasses(c *gin.Context) {
 	clusterName := c.Param("name")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -2996,7 +3371,7 @@ func (h *Handler) GetRuntimeClass(c *gin.Context) {
 	clusterName := c.Param("name")
 	rcName := c.Param("runtimeclass")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -3024,7 +3399,7 @@ func (h *Handler) UpdateRuntimeClass(c *gin.Context) {
 	clusterName := c.Param("name")
 	rcName := c.Param("runtimeclass")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -3039,7 +3414,7 @@ func (h *Handler) UpdateRuntimeClass(c *gin.Context) {
 	// Ensure name matches
 	rc.Name = rcName
 
-	updatedRC, err := client.NodeV1().RuntimeClasses().Update(context.Background(), &rc, metav1.UpdateOptions{})
+	updatedRC, err := client.NodeV1().RuntimeClasses().Update(context.Background(), &rc, updateOptions(c))
 	if err != nil {
 		log.Errorf("Failed to update runtime class: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -3054,13 +3429,13 @@ func (h *Handler) DeleteRuntimeClass(c *gin.Context) {
 	clusterName := c.Param("name")
 	rcName := c.Param("runtimeclass")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
 
-	err = client.NodeV1().RuntimeClasses().Delete(context.Background(), rcName, metav1.DeleteOptions{})
+	err = client.NodeV1().RuntimeClasses().Delete(context.Background(), rcName, deleteOptions(c))
 	if err != nil {
 		log.Errorf("Failed to delete runtime class: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -3074,7 +3449,7 @@ func (h *Handler) DeleteRuntimeClass(c *gin.Context) {
 func (h *Handler) CreateRuntimeClass(c *gin.Context) {
 	clusterName := c.Param("name")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -3086,7 +3461,7 @@ func (h *Handler) CreateRuntimeClass(c *gin.Context) {
 		return
 	}
 
-	createdRC, err := client.NodeV1().RuntimeClasses().Create(context.Background(), &rc, metav1.CreateOptions{})
+	createdRC, err := client.NodeV1().RuntimeClasses().Create(context.Background(), &rc, createOptions(c))
 	if err != nil {
 		log.Errorf("Failed to create runtime class: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -3101,7 +3476,7 @@ func (h *Handler) ListLeases(c *gin.Context) {
 	clusterName := c.Param("name")
 	namespace := c.Param("namespace")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -3140,7 +3515,7 @@ func (h *Handler) GetLease(c *gin.Context) {
 	namespace := c.Param("namespace")
 	leaseName := c.Param("lease")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -3167,7 +3542,7 @@ func (h *Handler) UpdateLease(c *gin.Context) {
 	namespace := c.Param("namespace")
 	leaseName := c.Param("lease")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -3183,7 +3558,7 @@ func (h *Handler) UpdateLease(c *gin.Context) {
 	lease.Namespace = namespace
 	lease.Name = leaseName
 
-	updatedLease, err := client.CoordinationV1().Leases(namespace).Update(context.Background(), &lease, metav1.UpdateOptions{})
+	updatedLease, err := client.CoordinationV1().Leases(namespace).Update(context.Background(), &lease, updateOptions(c))
 	if err != nil {
 		log.Errorf("Failed to update lease: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -3199,13 +3574,13 @@ func (h *Handler) DeleteLease(c *gin.Context) {
 	namespace := c.Param("namespace")
 	leaseName := c.Param("lease")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
 
-	err = client.CoordinationV1().Leases(namespace).Delete(context.Background(), leaseName, metav1.DeleteOptions{})
+	err = client.CoordinationV1().Leases(namespace).Delete(context.Background(), leaseName, deleteOptions(c))
 	if err != nil {
 		log.Errorf("Failed to delete lease: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -3220,7 +3595,7 @@ func (h *Handler) CreateLease(c *gin.Context) {
 	clusterName := c.Param("name")
 	namespace := c.Param("namespace")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -3235,7 +3610,7 @@ func (h *Handler) CreateLease(c *gin.Context) {
 	// Ensure namespace matches
 	lease.Namespace = namespace
 
-	createdLease, err := client.CoordinationV1().Leases(namespace).Create(context.Background(), &lease, metav1.CreateOptions{})
+	createdLease, err := client.CoordinationV1().Leases(namespace).Create(context.Background(), &lease, createOptions(c))
 	if err != nil {
 		log.Errorf("Failed to create lease: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -3249,7 +3624,7 @@ func (h *Handler) CreateLease(c *gin.Context) {
 func (h *Handler) ListMutatingWebhookConfigurations(c *gin.Context) {
 	clusterName := c.Param("name")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -3281,7 +3656,7 @@ func (h *Handler) GetMutatingWebhookConfiguration(c *gin.Context) {
 	clusterName := c.Param("name")
 	webhookName := c.Param("webhook")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -3307,7 +3682,7 @@ func (h *Handler) UpdateMutatingWebhookConfiguration(c *gin.Context) {
 	clusterName := c.Param("name")
 	webhookName := c.Param("webhook")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -3322,7 +3697,7 @@ func (h *Handler) UpdateMutatingWebhookConfiguration(c *gin.Context) {
 	// Ensure name matches
 	webhook.Name = webhookName
 
-	updatedWebhook, err := client.AdmissionregistrationV1().MutatingWebhookConfigurations().Update(context.Background(), &webhook, metav1.UpdateOptions{})
+	updatedWebhook, err := client.AdmissionregistrationV1().MutatingWebhookConfigurations().Update(context.Background(), &webhook, updateOptions(c))
 	if err != nil {
 		log.Errorf("Failed to update mutating webhook configuration: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -3337,13 +3712,13 @@ func (h *Handler) DeleteMutatingWebhookConfiguration(c *gin.Context) {
 	clusterName := c.Param("name")
 	webhookName := c.Param("webhook")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
 
-	err = client.AdmissionregistrationV1().MutatingWebhookConfigurations().Delete(context.Background(), webhookName, metav1.DeleteOptions{})
+	err = client.AdmissionregistrationV1().MutatingWebhookConfigurations().Delete(context.Background(), webhookName, deleteOptions(c))
 	if err != nil {
 		log.Errorf("Failed to delete mutating webhook configuration: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -3357,7 +3732,7 @@ func (h *Handler) DeleteMutatingWebhookConfiguration(c *gin.Context) {
 func (h *Handler) CreateMutatingWebhookConfiguration(c *gin.Context) {
 	clusterName := c.Param("name")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -3369,7 +3744,7 @@ func (h *Handler) CreateMutatingWebhookConfiguration(c *gin.Context) {
 		return
 	}
 
-	createdWebhook, err := client.AdmissionregistrationV1().MutatingWebhookConfigurations().Create(context.Background(), &webhook, metav1.CreateOptions{})
+	createdWebhook, err := client.AdmissionregistrationV1().MutatingWebhookConfigurations().Create(context.Background(), &webhook, createOptions(c))
 	if err != nil {
 		log.Errorf("Failed to create mutating webhook configuration: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -3383,7 +3758,7 @@ func (h *Handler) CreateMutatingWebhookConfiguration(c *gin.Context) {
 func (h *Handler) ListValidatingWebhookConfigurations(c *gin.Context) {
 	clusterName := c.Param("name")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -3415,7 +3790,7 @@ func (h *Handler) GetValidatingWebhookConfiguration(c *gin.Context) {
 	clusterName := c.Param("name")
 	webhookName := c.Param("webhook")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -3441,7 +3816,7 @@ func (h *Handler) UpdateValidatingWebhookConfiguration(c *gin.Context) {
 	clusterName := c.Param("name")
 	webhookName := c.Param("webhook")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -3456,7 +3831,7 @@ func (h *Handler) UpdateValidatingWebhookConfiguration(c *gin.Context) {
 	// Ensure name matches
 	webhook.Name = webhookName
 
-	updatedWebhook, err := client.AdmissionregistrationV1().ValidatingWebhookConfigurations().Update(context.Background(), &webhook, metav1.UpdateOptions{})
+	updatedWebhook, err := client.AdmissionregistrationV1().ValidatingWebhookConfigurations().Update(context.Background(), &webhook, updateOptions(c))
 	if err != nil {
 		log.Errorf("Failed to update validating webhook configuration: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -3471,13 +3846,13 @@ func (h *Handler) DeleteValidatingWebhookConfiguration(c *gin.Context) {
 	clusterName := c.Param("name")
 	webhookName := c.Param("webhook")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
 
-	err = client.AdmissionregistrationV1().ValidatingWebhookConfigurations().Delete(context.Background(), webhookName, metav1.DeleteOptions{})
+	err = client.AdmissionregistrationV1().ValidatingWebhookConfigurations().Delete(context.Background(), webhookName, deleteOptions(c))
 	if err != nil {
 		log.Errorf("Failed to delete validating webhook configuration: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -3491,7 +3866,7 @@ func (h *Handler) DeleteValidatingWebhookConfiguration(c *gin.Context) {
 func (h *Handler) CreateValidatingWebhookConfiguration(c *gin.Context) {
 	clusterName := c.Param("name")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -3503,7 +3878,7 @@ func (h *Handler) CreateValidatingWebhookConfiguration(c *gin.Context) {
 		return
 	}
 
-	createdWebhook, err := client.AdmissionregistrationV1().ValidatingWebhookConfigurations().Create(context.Background(), &webhook, metav1.CreateOptions{})
+	createdWebhook, err := client.AdmissionregistrationV1().ValidatingWebhookConfigurations().Create(context.Background(), &webhook, createOptions(c))
 	if err != nil {
 		log.Errorf("Failed to create validating webhook configuration: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -3518,7 +3893,7 @@ func (h *Handler) ListIngresses(c *gin.Context) {
 	clusterName := c.Param("name")
 	namespace := c.Param("namespace")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -3558,7 +3933,7 @@ func (h *Handler) GetIngress(c *gin.Context) {
 	namespace := c.Param("namespace")
 	ingressName := c.Param("ingress")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -3586,7 +3961,7 @@ func (h *Handler) UpdateIngress(c *gin.Context) {
 	namespace := c.Param("namespace")
 	ingressName := c.Param("ingress")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -3602,7 +3977,7 @@ func (h *Handler) UpdateIngress(c *gin.Context) {
 	ingress.Name = ingressName
 	ingress.Namespace = namespace
 
-	updatedIngress, err := client.NetworkingV1().Ingresses(namespace).Update(context.Background(), &ingress, metav1.UpdateOptions{})
+	updatedIngress, err := client.NetworkingV1().Ingresses(namespace).Update(context.Background(), &ingress, updateOptions(c))
 	if err != nil {
 		log.Errorf("Failed to update ingress: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -3618,13 +3993,13 @@ func (h *Handler) DeleteIngress(c *gin.Context) {
 	namespace := c.Param("namespace")
 	ingressName := c.Param("ingress")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
 
-	err = client.NetworkingV1().Ingresses(namespace).Delete(context.Background(), ingressName, metav1.DeleteOptions{})
+	err = client.NetworkingV1().Ingresses(namespace).Delete(context.Background(), ingressName, deleteOptions(c))
 	if err != nil {
 		log.Errorf("Failed to delete ingress: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -3639,7 +4014,7 @@ func (h *Handler) CreateIngress(c *gin.Context) {
 	clusterName := c.Param("name")
 	namespace := c.Param("namespace")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -3654,7 +4029,7 @@ func (h *Handler) CreateIngress(c *gin.Context) {
 	// Ensure namespace is set
 	ingress.Namespace = namespace
 
-	createdIngress, err := client.NetworkingV1().Ingresses(namespace).Create(context.Background(), &ingress, metav1.CreateOptions{})
+	createdIngress, err := client.NetworkingV1().Ingresses(namespace).Create(context.Background(), &ingress, createOptions(c))
 	if err != nil {
 		log.Errorf("Failed to create ingress: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -3668,7 +4043,7 @@ func (h *Handler) CreateIngress(c *gin.Context) {
 func (h *Handler) ListIngressClasses(c *gin.Context) {
 	clusterName := c.Param("name")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -3700,7 +4075,7 @@ func (h *Handler) GetIngressClass(c *gin.Context) {
 	clusterName := c.Param("name")
 	ingressClassName := c.Param("ingressclass")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -3726,7 +4101,7 @@ func (h *Handler) UpdateIngressClass(c *gin.Context) {
 	clusterName := c.Param("name")
 	ingressClassName := c.Param("ingressclass")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -3741,7 +4116,7 @@ func (h *Handler) UpdateIngressClass(c *gin.Context) {
 	// Ensure name matches
 	ingressClass.Name = ingressClassName
 
-	updatedIngressClass, err := client.NetworkingV1().IngressClasses().Update(context.Background(), &ingressClass, metav1.UpdateOptions{})
+	updatedIngressClass, err := client.NetworkingV1().IngressClasses().Update(context.Background(), &ingressClass, updateOptions(c))
 	if err != nil {
 		log.Errorf("Failed to update ingress class: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -3756,13 +4131,13 @@ func (h *Handler) DeleteIngressClass(c *gin.Context) {
 	clusterName := c.Param("name")
 	ingressClassName := c.Param("ingressclass")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
 
-	err = client.NetworkingV1().IngressClasses().Delete(context.Background(), ingressClassName, metav1.DeleteOptions{})
+	err = client.NetworkingV1().IngressClasses().Delete(context.Background(), ingressClassName, deleteOptions(c))
 	if err != nil {
 		log.Errorf("Failed to delete ingress class: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -3776,7 +4151,7 @@ func (h *Handler) DeleteIngressClass(c *gin.Context) {
 func (h *Handler) CreateIngressClass(c *gin.Context) {
 	clusterName := c.Param("name")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -3788,7 +4163,7 @@ func (h *Handler) CreateIngressClass(c *gin.Context) {
 		return
 	}
 
-	createdIngressClass, err := client.NetworkingV1().IngressClasses().Create(context.Background(), &ingressClass, metav1.CreateOptions{})
+	createdIngressClass, err := client.NetworkingV1().IngressClasses().Create(context.Background(), &ingressClass, createOptions(c))
 	if err != nil {
 		log.Errorf("Failed to create ingress class: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -3807,7 +4182,7 @@ func (h *Handler) ListNetworkPolicies(c *gin.Context) {
 		namespace = ""
 	}
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -3840,7 +4215,7 @@ func (h *Handler) GetNetworkPolicy(c *gin.Context) {
 	namespace := c.Param("namespace")
 	networkPolicyName := c.Param("networkpolicy")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -3871,7 +4246,7 @@ func (h *Handler) UpdateNetworkPolicy(c *gin.Context) {
 	namespace := c.Param("namespace")
 	networkPolicyName := c.Param("networkpolicy")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -3891,7 +4266,7 @@ func (h *Handler) UpdateNetworkPolicy(c *gin.Context) {
 		networkPolicy.ObjectMeta.Namespace = namespace
 	}
 
-	updatedNetworkPolicy, err := client.NetworkingV1().NetworkPolicies(namespace).Update(context.Background(), &networkPolicy, metav1.UpdateOptions{})
+	updatedNetworkPolicy, err := client.NetworkingV1().NetworkPolicies(namespace).Update(context.Background(), &networkPolicy, updateOptions(c))
 	if err != nil {
 		log.Errorf("Failed to update network policy: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -3907,13 +4282,13 @@ func (h *Handler) DeleteNetworkPolicy(c *gin.Context) {
 	namespace := c.Param("namespace")
 	networkPolicyName := c.Param("networkpolicy")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
 
-	err = client.NetworkingV1().NetworkPolicies(namespace).Delete(context.Background(), networkPolicyName, metav1.DeleteOptions{})
+	err = client.NetworkingV1().NetworkPolicies(namespace).Delete(context.Background(), networkPolicyName, deleteOptions(c))
 	if err != nil {
 		log.Errorf("Failed to delete network policy: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -3927,7 +4302,7 @@ func (h *Handler) DeleteNetworkPolicy(c *gin.Context) {
 func (h *Handler) ListStorageClasses(c *gin.Context) {
 	clusterName := c.Param("name")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -3964,7 +4339,7 @@ func (h *Handler) GetStorageClass(c *gin.Context) {
 	clusterName := c.Param("name")
 	scName := c.Param("storageclass")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -4005,7 +4380,7 @@ func (h *Handler) GetStorageClass(c *gin.Context) {
 func (h *Handler) CreateStorageClass(c *gin.Context) {
 	clusterName := c.Param("name")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -4023,7 +4398,7 @@ func (h *Handler) CreateStorageClass(c *gin.Context) {
 		Kind:       "StorageClass",
 	}
 
-	createdSC, err := client.StorageV1().StorageClasses().Create(context.Background(), &sc, metav1.CreateOptions{})
+	createdSC, err := client.StorageV1().StorageClasses().Create(context.Background(), &sc, createOptions(c))
 	if err != nil {
 		log.Errorf("Failed to create storage class: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -4038,7 +4413,7 @@ func (h *Handler) UpdateStorageClass(c *gin.Context) {
 	clusterName := c.Param("name")
 	scName := c.Param("storageclass")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -4055,7 +4430,7 @@ func (h *Handler) UpdateStorageClass(c *gin.Context) {
 		sc.ObjectMeta.Name = scName
 	}
 
-	updatedSC, err := client.StorageV1().StorageClasses().Update(context.Background(), &sc, metav1.UpdateOptions{})
+	updatedSC, err := client.StorageV1().StorageClasses().Update(context.Background(), &sc, updateOptions(c))
 	if err != nil {
 		log.Errorf("Failed to update storage class: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -4070,13 +4445,13 @@ func (h *Handler) DeleteStorageClass(c *gin.Context) {
 	clusterName := c.Param("name")
 	scName := c.Param("storageclass")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
 
-	err = client.StorageV1().StorageClasses().Delete(context.Background(), scName, metav1.DeleteOptions{})
+	err = client.StorageV1().StorageClasses().Delete(context.Background(), scName, deleteOptions(c))
 	if err != nil {
 		log.Errorf("Failed to delete storage class: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -4090,7 +4465,7 @@ func (h *Handler) DeleteStorageClass(c *gin.Context) {
 func (h *Handler) ListPersistentVolumes(c *gin.Context) {
 	clusterName := c.Param("name")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -4123,7 +4498,7 @@ func (h *Handler) GetPersistentVolume(c *gin.Context) {
 	clusterName := c.Param("name")
 	pvName := c.Param("pv")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -4154,7 +4529,7 @@ func (h *Handler) UpdatePersistentVolume(c *gin.Context) {
 	clusterName := c.Param("name")
 	pvName := c.Param("pv")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -4171,7 +4546,7 @@ func (h *Handler) UpdatePersistentVolume(c *gin.Context) {
 		pv.ObjectMeta.Name = pvName
 	}
 
-	updatedPV, err := client.CoreV1().PersistentVolumes().Update(context.Background(), &pv, metav1.UpdateOptions{})
+	updatedPV, err := client.CoreV1().PersistentVolumes().Update(context.Background(), &pv, updateOptions(c))
 	if err != nil {
 		log.Errorf("Failed to update persistent volume: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -4186,13 +4561,13 @@ func (h *Handler) DeletePersistentVolume(c *gin.Context) {
 	clusterName := c.Param("name")
 	pvName := c.Param("pv")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
 
-	err = client.CoreV1().PersistentVolumes().Delete(context.Background(), pvName, metav1.DeleteOptions{})
+	err = client.CoreV1().PersistentVolumes().Delete(context.Background(), pvName, deleteOptions(c))
 	if err != nil {
 		log.Errorf("Failed to delete persistent volume: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -4211,7 +4586,7 @@ func (h *Handler) ListPersistentVolumeClaims(c *gin.Context) {
 		namespace = ""
 	}
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -4245,7 +4620,7 @@ func (h *Handler) GetPersistentVolumeClaim(c *gin.Context) {
 	namespace := c.Param("namespace")
 	pvcName := c.Param("pvc")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -4277,7 +4652,7 @@ func (h *Handler) UpdatePersistentVolumeClaim(c *gin.Context) {
 	namespace := c.Param("namespace")
 	pvcName := c.Param("pvc")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -4297,7 +4672,7 @@ func (h *Handler) UpdatePersistentVolumeClaim(c *gin.Context) {
 		pvc.ObjectMeta.Namespace = namespace
 	}
 
-	updatedPVC, err := client.CoreV1().PersistentVolumeClaims(namespace).Update(context.Background(), &pvc, metav1.UpdateOptions{})
+	updatedPVC, err := client.CoreV1().PersistentVolumeClaims(namespace).Update(context.Background(), &pvc, updateOptions(c))
 	if err != nil {
 		log.Errorf("Failed to update persistent volume claim: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -4313,13 +4688,13 @@ func (h *Handler) DeletePersistentVolumeClaim(c *gin.Context) {
 	namespace := c.Param("namespace")
 	pvcName := c.Param("pvc")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
 
-	err = client.CoreV1().PersistentVolumeClaims(namespace).Delete(context.Background(), pvcName, metav1.DeleteOptions{})
+	err = client.CoreV1().PersistentVolumeClaims(namespace).Delete(context.Background(), pvcName, deleteOptions(c))
 	if err != nil {
 		log.Errorf("Failed to delete persistent volume claim: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -4340,7 +4715,7 @@ func (h *Handler) ListServiceAccounts(c *gin.Context) {
 		namespace = metav1.NamespaceAll
 	}
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -4379,7 +4754,7 @@ func (h *Handler) ListServiceAccountsByNamespace(c *gin.Context) {
 	clusterName := c.Param("name")
 	namespace := c.Param("namespace")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -4419,7 +4794,7 @@ func (h *Handler) GetServiceAccount(c *gin.Context) {
 	namespace := c.Param("namespace")
 	saName := c.Param("serviceaccount")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -4451,7 +4826,7 @@ func (h *Handler) UpdateServiceAccount(c *gin.Context) {
 	namespace := c.Param("namespace")
 	saName := c.Param("serviceaccount")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -4476,7 +4851,7 @@ func (h *Handler) UpdateServiceAccount(c *gin.Context) {
 	sa.Name = saName
 
 	// Update the ServiceAccount
-	updated, err := client.CoreV1().ServiceAccounts(namespace).Update(context.Background(), &sa, metav1.UpdateOptions{})
+	updated, err := client.CoreV1().ServiceAccounts(namespace).Update(context.Background(), &sa, updateOptions(c))
 	if err != nil {
 		log.Errorf("Failed to update service account: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -4492,13 +4867,13 @@ func (h *Handler) DeleteServiceAccount(c *gin.Context) {
 	namespace := c.Param("namespace")
 	saName := c.Param("serviceaccount")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
 
-	err = client.CoreV1().ServiceAccounts(namespace).Delete(context.Background(), saName, metav1.DeleteOptions{})
+	err = client.CoreV1().ServiceAccounts(namespace).Delete(context.Background(), saName, deleteOptions(c))
 	if err != nil {
 		log.Errorf("Failed to delete service account: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -4513,7 +4888,7 @@ func (h *Handler) CreateServiceAccount(c *gin.Context) {
 	clusterName := c.Param("name")
 	namespace := c.Param("namespace")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -4537,7 +4912,7 @@ func (h *Handler) CreateServiceAccount(c *gin.Context) {
 	sa.Namespace = namespace
 
 	// Create the ServiceAccount
-	created, err := client.CoreV1().ServiceAccounts(namespace).Create(context.Background(), &sa, metav1.CreateOptions{})
+	created, err := client.CoreV1().ServiceAccounts(namespace).Create(context.Background(), &sa, createOptions(c))
 	if err != nil {
 		log.Errorf("Failed to create service account: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -4553,7 +4928,7 @@ func (h *Handler) CreateServiceAccount(c *gin.Context) {
 func (h *Handler) ListClusterRoles(c *gin.Context) {
 	clusterName := c.Param("name")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -4591,7 +4966,7 @@ func (h *Handler) GetClusterRole(c *gin.Context) {
 	clusterName := c.Param("name")
 	crName := c.Param("clusterrole")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -4621,7 +4996,7 @@ func (h *Handler) UpdateClusterRole(c *gin.Context) {
 	clusterName := c.Param("name")
 	crName := c.Param("clusterrole")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -4645,7 +5020,7 @@ func (h *Handler) UpdateClusterRole(c *gin.Context) {
 	cr.Name = crName
 
 	// Update the ClusterRole
-	updated, err := client.RbacV1().ClusterRoles().Update(context.Background(), &cr, metav1.UpdateOptions{})
+	updated, err := client.RbacV1().ClusterRoles().Update(context.Background(), &cr, updateOptions(c))
 	if err != nil {
 		log.Errorf("Failed to update cluster role: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -4660,13 +5035,13 @@ func (h *Handler) DeleteClusterRole(c *gin.Context) {
 	clusterName := c.Param("name")
 	crName := c.Param("clusterrole")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
 
-	err = client.RbacV1().ClusterRoles().Delete(context.Background(), crName, metav1.DeleteOptions{})
+	err = client.RbacV1().ClusterRoles().Delete(context.Background(), crName, deleteOptions(c))
 	if err != nil {
 		log.Errorf("Failed to delete cluster role: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -4680,7 +5055,7 @@ func (h *Handler) DeleteClusterRole(c *gin.Context) {
 func (h *Handler) CreateClusterRole(c *gin.Context) {
 	clusterName := c.Param("name")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -4701,7 +5076,7 @@ func (h *Handler) CreateClusterRole(c *gin.Context) {
 	}
 
 	// Create the ClusterRole
-	created, err := client.RbacV1().ClusterRoles().Create(context.Background(), &cr, metav1.CreateOptions{})
+	created, err := client.RbacV1().ClusterRoles().Create(context.Background(), &cr, createOptions(c))
 	if err != nil {
 		log.Errorf("Failed to create cluster role: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -4722,7 +5097,7 @@ func (h *Handler) ListRoles(c *gin.Context) {
 		namespace = metav1.NamespaceAll
 	}
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -4759,7 +5134,7 @@ func (h *Handler) ListRolesByNamespace(c *gin.Context) {
 	clusterName := c.Param("name")
 	namespace := c.Param("namespace")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -4797,7 +5172,7 @@ func (h *Handler) GetRole(c *gin.Context) {
 	namespace := c.Param("namespace")
 	roleName := c.Param("role")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -4827,7 +5202,7 @@ func (h *Handler) UpdateRole(c *gin.Context) {
 	namespace := c.Param("namespace")
 	roleName := c.Param("role")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -4852,7 +5227,7 @@ func (h *Handler) UpdateRole(c *gin.Context) {
 	role.Name = roleName
 
 	// Update the Role
-	updated, err := client.RbacV1().Roles(namespace).Update(context.Background(), &role, metav1.UpdateOptions{})
+	updated, err := client.RbacV1().Roles(namespace).Update(context.Background(), &role, updateOptions(c))
 	if err != nil {
 		log.Errorf("Failed to update role: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -4868,13 +5243,13 @@ func (h *Handler) DeleteRole(c *gin.Context) {
 	namespace := c.Param("namespace")
 	roleName := c.Param("role")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
 
-	err = client.RbacV1().Roles(namespace).Delete(context.Background(), roleName, metav1.DeleteOptions{})
+	err = client.RbacV1().Roles(namespace).Delete(context.Background(), roleName, deleteOptions(c))
 	if err != nil {
 		log.Errorf("Failed to delete role: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -4889,7 +5264,7 @@ func (h *Handler) CreateRole(c *gin.Context) {
 	clusterName := c.Param("name")
 	namespace := c.Param("namespace")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -4913,7 +5288,7 @@ func (h *Handler) CreateRole(c *gin.Context) {
 	role.Namespace = namespace
 
 	// Create the Role
-	created, err := client.RbacV1().Roles(namespace).Create(context.Background(), &role, metav1.CreateOptions{})
+	created, err := client.RbacV1().Roles(namespace).Create(context.Background(), &role, createOptions(c))
 	if err != nil {
 		log.Errorf("Failed to create role: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -4929,7 +5304,7 @@ func (h *Handler) CreateRole(c *gin.Context) {
 func (h *Handler) ListClusterRoleBindings(c *gin.Context) {
 	clusterName := c.Param("name")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -4967,7 +5342,7 @@ func (h *Handler) GetClusterRoleBinding(c *gin.Context) {
 	clusterName := c.Param("name")
 	crbName := c.Param("clusterrolebinding")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -4997,7 +5372,7 @@ func (h *Handler) UpdateClusterRoleBinding(c *gin.Context) {
 	clusterName := c.Param("name")
 	crbName := c.Param("clusterrolebinding")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -5021,7 +5396,7 @@ func (h *Handler) UpdateClusterRoleBinding(c *gin.Context) {
 	crb.Name = crbName
 
 	// Update the ClusterRoleBinding
-	updated, err := client.RbacV1().ClusterRoleBindings().Update(context.Background(), &crb, metav1.UpdateOptions{})
+	updated, err := client.RbacV1().ClusterRoleBindings().Update(context.Background(), &crb, updateOptions(c))
 	if err != nil {
 		log.Errorf("Failed to update cluster role binding: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -5036,13 +5411,13 @@ func (h *Handler) DeleteClusterRoleBinding(c *gin.Context) {
 	clusterName := c.Param("name")
 	crbName := c.Param("clusterrolebinding")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
 
-	err = client.RbacV1().ClusterRoleBindings().Delete(context.Background(), crbName, metav1.DeleteOptions{})
+	err = client.RbacV1().ClusterRoleBindings().Delete(context.Background(), crbName, deleteOptions(c))
 	if err != nil {
 		log.Errorf("Failed to delete cluster role binding: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -5056,7 +5431,7 @@ func (h *Handler) DeleteClusterRoleBinding(c *gin.Context) {
 func (h *Handler) CreateClusterRoleBinding(c *gin.Context) {
 	clusterName := c.Param("name")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -5077,7 +5452,7 @@ func (h *Handler) CreateClusterRoleBinding(c *gin.Context) {
 	}
 
 	// Create the ClusterRoleBinding
-	created, err := client.RbacV1().ClusterRoleBindings().Create(context.Background(), &crb, metav1.CreateOptions{})
+	created, err := client.RbacV1().ClusterRoleBindings().Create(context.Background(), &crb, createOptions(c))
 	if err != nil {
 		log.Errorf("Failed to create cluster role binding: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -5098,7 +5473,7 @@ func (h *Handler) ListRoleBindings(c *gin.Context) {
 		namespace = metav1.NamespaceAll
 	}
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -5136,7 +5511,7 @@ func (h *Handler) ListRoleBindingsByNamespace(c *gin.Context) {
 	clusterName := c.Param("name")
 	namespace := c.Param("namespace")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -5175,7 +5550,7 @@ func (h *Handler) GetRoleBinding(c *gin.Context) {
 	namespace := c.Param("namespace")
 	rbName := c.Param("rolebinding")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -5206,7 +5581,7 @@ func (h *Handler) UpdateRoleBinding(c *gin.Context) {
 	namespace := c.Param("namespace")
 	rbName := c.Param("rolebinding")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -5231,7 +5606,7 @@ func (h *Handler) UpdateRoleBinding(c *gin.Context) {
 	rb.Name = rbName
 
 	// Update the RoleBinding
-	updated, err := client.RbacV1().RoleBindings(namespace).Update(context.Background(), &rb, metav1.UpdateOptions{})
+	updated, err := client.RbacV1().RoleBindings(namespace).Update(context.Background(), &rb, updateOptions(c))
 	if err != nil {
 		log.Errorf("Failed to update role binding: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -5247,13 +5622,13 @@ func (h *Handler) DeleteRoleBinding(c *gin.Context) {
 	namespace := c.Param("namespace")
 	rbName := c.Param("rolebinding")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
 
-	err = client.RbacV1().RoleBindings(namespace).Delete(context.Background(), rbName, metav1.DeleteOptions{})
+	err = client.RbacV1().RoleBindings(namespace).Delete(context.Background(), rbName, deleteOptions(c))
 	if err != nil {
 		log.Errorf("Failed to delete role binding: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -5268,7 +5643,7 @@ func (h *Handler) CreateRoleBinding(c *gin.Context) {
 	clusterName := c.Param("name")
 	namespace := c.Param("namespace")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -5292,7 +5667,7 @@ func (h *Handler) CreateRoleBinding(c *gin.Context) {
 	rb.Namespace = namespace
 
 	// Create the RoleBinding
-	created, err := client.RbacV1().RoleBindings(namespace).Create(context.Background(), &rb, metav1.CreateOptions{})
+	created, err := client.RbacV1().RoleBindings(namespace).Create(context.Background(), &rb, createOptions(c))
 	if err != nil {
 		log.Errorf("Failed to create role binding: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -5425,7 +5800,7 @@ func (h *Handler) UpdateCustomResourceDefinition(c *gin.Context) {
 
 	// Update the CRD
 	crdClient := client.ApiextensionsV1().CustomResourceDefinitions()
-	updated, err := crdClient.Update(context.Background(), &crd, metav1.UpdateOptions{})
+	updated, err := crdClient.Update(context.Background(), &crd, updateOptions(c))
 	if err != nil {
 		log.Errorf("Failed to update custom resource definition: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -5447,7 +5822,7 @@ func (h *Handler) DeleteCustomResourceDefinition(c *gin.Context) {
 	}
 
 	crdClient := client.ApiextensionsV1().CustomResourceDefinitions()
-	err = crdClient.Delete(context.Background(), crdName, metav1.DeleteOptions{})
+	err = crdClient.Delete(context.Background(), crdName, deleteOptions(c))
 	if err != nil {
 		log.Errorf("Failed to delete custom resource definition: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -5472,7 +5847,7 @@ func (h *Handler) ListCustomResources(c *gin.Context) {
 		return
 	}
 
-	client, err := h.clusterManager.GetDynamicClient(clusterName)
+	client, err := h.dynamicClient(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -5485,6 +5860,26 @@ func (h *Handler) ListCustomResources(c *gin.Context) {
 		Resource: resource,
 	}
 
+	if wantsNDJSON(c) {
+		resourceClient := client.Resource(gvr).Namespace("")
+		if namespace != "" && namespace != "all" {
+			resourceClient = client.Resource(gvr).Namespace(namespace)
+		}
+		streamList(c, metav1.ListOptions{}, func(ctx context.Context, opts metav1.ListOptions) ([]map[string]interface{}, string, error) {
+			list, err := resourceClient.List(ctx, opts)
+			if err != nil {
+				return nil, "", err
+			}
+			items := make([]map[string]interface{}, len(list.Items))
+			for i, item := range list.Items {
+				item.Object["ClusterName"] = clusterName
+				items[i] = item.Object
+			}
+			return items, list.GetContinue(), nil
+		})
+		return
+	}
+
 	var list *unstructured.UnstructuredList
 	if namespace != "" && namespace != "all" {
 		list, err = client.Resource(gvr).Namespace(namespace).List(context.Background(), metav1.ListOptions{})
@@ -5524,7 +5919,7 @@ func (h *Handler) GetCustomResource(c *gin.Context) {
 		return
 	}
 
-	client, err := h.clusterManager.GetDynamicClient(clusterName)
+	client, err := h.dynamicClient(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -5566,7 +5961,7 @@ func (h *Handler) UpdateCustomResource(c *gin.Context) {
 		return
 	}
 
-	client, err := h.clusterManager.GetDynamicClient(clusterName)
+	client, err := h.dynamicClient(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -5600,9 +5995,9 @@ func (h *Handler) UpdateCustomResource(c *gin.Context) {
 
 	var updated *unstructured.Unstructured
 	if namespace != "" {
-		updated, err = client.Resource(gvr).Namespace(namespace).Update(context.Background(), &obj, metav1.UpdateOptions{})
+		updated, err = client.Resource(gvr).Namespace(namespace).Update(context.Background(), &obj, updateOptions(c))
 	} else {
-		updated, err = client.Resource(gvr).Update(context.Background(), &obj, metav1.UpdateOptions{})
+		updated, err = client.Resource(gvr).Update(context.Background(), &obj, updateOptions(c))
 	}
 
 	if err != nil {
@@ -5628,7 +6023,7 @@ func (h *Handler) DeleteCustomResource(c *gin.Context) {
 		return
 	}
 
-	client, err := h.clusterManager.GetDynamicClient(clusterName)
+	client, err := h.dynamicClient(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -5641,9 +6036,9 @@ func (h *Handler) DeleteCustomResource(c *gin.Context) {
 	}
 
 	if namespace != "" {
-		err = client.Resource(gvr).Namespace(namespace).Delete(context.Background(), resourceName, metav1.DeleteOptions{})
+		err = client.Resource(gvr).Namespace(namespace).Delete(context.Background(), resourceName, deleteOptions(c))
 	} else {
-		err = client.Resource(gvr).Delete(context.Background(), resourceName, metav1.DeleteOptions{})
+		err = client.Resource(gvr).Delete(context.Background(), resourceName, deleteOptions(c))
 	}
 
 	if err != nil {
@@ -5666,9 +6061,9 @@ func (h *Handler) setupKubelensServiceAccount(clusterName string) error {
 	}
 
 	ctx := context.Background()
-	namespace := "kube-system"
-	serviceAccountName := "kubelens"
-	clusterRoleBindingName := "kubelens-cluster-admin"
+	namespace := kubelensServiceAccountNamespace
+	serviceAccountName := kubelensServiceAccountName
+	clusterRoleBindingName := kubelensClusterRoleBindingName
 
 	// 1. Create ServiceAccount if it doesn't exist
 	_, err = client.CoreV1().ServiceAccounts(namespace).Get(ctx, serviceAccountName, metav1.GetOptions{})
@@ -0,0 +1,133 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	metricsclientset "k8s.io/metrics/pkg/client/clientset/versioned"
+
+	"github.com/sonnguyen/kubelens/internal/audit"
+	"github.com/sonnguyen/kubelens/internal/cluster"
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+// identity returns the Kubernetes identity of the request's user for clusters in
+// impersonation mode: the user's email (the usual OIDC username claim, falling back to
+// the username) and the user's kubelens groups, prefixed with "kubelens:".
+// It is resolved once per request; nil means no user, and the cluster's own credentials are used.
+func (h *Handler) identity(c *gin.Context) *cluster.Identity {
+	if id, ok := c.Get("k8s_identity"); ok {
+		return id.(*cluster.Identity)
+	}
+
+	var id *cluster.Identity
+	if value, ok := c.Get("user"); ok {
+		if user, ok := value.(*db.User); ok {
+			name := user.Email
+			if name == "" {
+				name = user.Username
+			}
+
+			var groups []string
+			userGroups, err := h.db.GetUserGroups(user.ID)
+			if err != nil {
+				log.Warnf("Failed to get groups of user %d for impersonation: %v", user.ID, err)
+			}
+			for _, group := range userGroups {
+				groups = append(groups, group.Name)
+			}
+			id = cluster.NewIdentity(name, groups)
+		}
+	}
+
+	c.Set("k8s_identity", id)
+	return id
+}
+
+// client returns the Kubernetes client for a request, impersonating its user when the
+// cluster is in impersonation mode
+func (h *Handler) client(c *gin.Context, clusterName string) (kubernetes.Interface, error) {
+	if !h.clusterManager.Impersonates(clusterName) {
+		return h.clusterManager.GetClient(clusterName)
+	}
+	return h.clusterManager.ClientAs(clusterName, h.identity(c))
+}
+
+// dynamicClient is the dynamic client form of client
+func (h *Handler) dynamicClient(c *gin.Context, clusterName string) (dynamic.Interface, error) {
+	if !h.clusterManager.Impersonates(clusterName) {
+		return h.clusterManager.GetDynamicClient(clusterName)
+	}
+	return h.clusterManager.DynamicClientAs(clusterName, h.identity(c))
+}
+
+// restConfig is the REST config form of client, for exec, attach and port-forward
+func (h *Handler) restConfig(c *gin.Context, clusterName string) (*rest.Config, error) {
+	if !h.clusterManager.Impersonates(clusterName) {
+		return h.clusterManager.GetConfig(clusterName)
+	}
+	return h.clusterManager.ConfigAs(clusterName, h.identity(c))
+}
+
+// metricsClient is the metrics-server form of client
+func (h *Handler) metricsClient(c *gin.Context, clusterName string) (metricsclientset.Interface, error) {
+	if !h.clusterManager.Impersonates(clusterName) {
+		return h.clusterManager.GetMetricsClient(clusterName)
+	}
+	return h.clusterManager.MetricsClientAs(clusterName, h.identity(c))
+}
+
+// UpdateClusterImpersonation turns impersonation mode on or off for a cluster. In this mode
+// API calls carry Impersonate-User/Impersonate-Group headers for the logged-in user, so the
+// cluster's RBAC governs what each user can see and do. The cluster credentials need the
+// impersonate verb on users and groups.
+func (h *Handler) UpdateClusterImpersonation(c *gin.Context) {
+	name := c.Param("name")
+
+	var req struct {
+		Enabled bool `json:"enabled"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	dbCluster, err := h.db.GetCluster(name)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Cluster not found"})
+		return
+	}
+
+	if err := h.db.UpdateClusterImpersonate(dbCluster.ID, req.Enabled); err != nil {
+		log.Errorf("Failed to update cluster impersonation: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	h.clusterManager.SetImpersonation(name, req.Enabled)
+
+	log.Infof("Impersonation for cluster %s set to %v", name, req.Enabled)
+
+	// Audit log
+	if user, exists := c.Get("user"); exists {
+		if u, ok := user.(*db.User); ok {
+			action := "enabled"
+			if !req.Enabled {
+				action = "disabled"
+			}
+			audit.Log(c, audit.EventClusterUpdated, int(u.ID), u.Username, u.Email,
+				fmt.Sprintf("Cluster %s: impersonation %s", name, action),
+				map[string]interface{}{
+					"cluster_name": name,
+					"impersonate":  req.Enabled,
+				})
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Cluster impersonation updated successfully", "impersonate": req.Enabled})
+}
@@ -0,0 +1,415 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// istioKinds are the Istio objects served under /clusters/:name/istio/:kind. The version
+// is left empty so that whichever version the cluster prefers is used (v1, v1beta1 or
+// v1alpha3; the fields read here are the same).
+var istioKinds = map[string]schema.GroupVersionKind{
+	"virtualservices":     {Group: "networking.istio.io", Kind: "VirtualService"},
+	"destinationrules":    {Group: "networking.istio.io", Kind: "DestinationRule"},
+	"gateways":            {Group: "networking.istio.io", Kind: "Gateway"},
+	"peerauthentications": {Group: "security.istio.io", Kind: "PeerAuthentication"},
+}
+
+const (
+	// istioRootNamespace is where mesh-wide policies live, unless the mesh config changes it
+	istioRootNamespace = "istio-system"
+	// clusterDomain is the DNS domain of services, unless the cluster changes it
+	clusterDomain = "cluster.local"
+)
+
+// IstioResource is the summary of an Istio object
+type IstioResource struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	// VirtualServices
+	Hosts        []string `json:"hosts,omitempty"`
+	Gateways     []string `json:"gateways,omitempty"`
+	Routes       int      `json:"routes,omitempty"`
+	Destinations []string `json:"destinations,omitempty"` // hosts, with the subset as host/subset
+	// DestinationRules
+	Host    string   `json:"host,omitempty"`
+	Subsets []string `json:"subsets,omitempty"`
+	TLSMode string   `json:"tlsMode,omitempty"` // trafficPolicy.tls.mode
+	// Gateways
+	Servers []string `json:"servers,omitempty"` // <protocol>/<port> <hosts>
+	// Gateways and PeerAuthentications
+	Selector map[string]string `json:"selector,omitempty"`
+	// PeerAuthentications
+	MTLSMode string `json:"mtlsMode,omitempty"` // STRICT, PERMISSIVE, DISABLE or UNSET
+}
+
+// MeshService is a service of a namespace with the Istio configuration that applies to it
+type MeshService struct {
+	Name             string   `json:"name"`
+	Host             string   `json:"host"` // <name>.<namespace>.svc.cluster.local
+	Ports            []string `json:"ports"`
+	VirtualServices  []string `json:"virtualServices"`  // <namespace>/<name>
+	DestinationRules []string `json:"destinationRules"` // <namespace>/<name>
+	Subsets          []string `json:"subsets,omitempty"`
+	// MTLSMode is the effective mode of the PeerAuthentications, and MTLSSource the one
+	// it comes from (<namespace>/<name>, or default)
+	MTLSMode   string `json:"mtlsMode"`
+	MTLSSource string `json:"mtlsSource"`
+	// ClientTLSMode is the TLS mode of the DestinationRule for clients of the service
+	ClientTLSMode string `json:"clientTlsMode,omitempty"`
+}
+
+// listIstioObjects loads the Istio objects of a kind in a namespace (all namespaces when
+// empty), or installed=false when the kind is not installed
+func (h *Handler) listIstioObjects(c *gin.Context, clusterName, kind, namespace string) ([]unstructured.Unstructured, bool, error) {
+	gvk, ok := istioKinds[kind]
+	if !ok {
+		return nil, false, fmt.Errorf("unknown Istio kind %q", kind)
+	}
+	client, err := h.dynamicClient(c, clusterName)
+	if err != nil {
+		return nil, false, err
+	}
+	mapping, installed, err := h.optionalMapping(clusterName, gvk)
+	if err != nil || !installed {
+		return nil, false, err
+	}
+	list, err := client.Resource(mapping.Resource).Namespace(namespace).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return nil, true, err
+	}
+	return list.Items, true, nil
+}
+
+// ListIstioResources handles GET /clusters/:name/istio/:kind
+// (virtualservices, destinationrules, gateways or peerauthentications).
+// Query: namespace (optional)
+func (h *Handler) ListIstioResources(c *gin.Context) {
+	clusterName := c.Param("name")
+	kind := c.Param("kind")
+
+	if _, ok := istioKinds[kind]; !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("unknown Istio kind %q (virtualservices, destinationrules, gateways or peerauthentications)", kind)})
+		return
+	}
+	objects, installed, err := h.listIstioObjects(c, clusterName, kind, c.Query("namespace"))
+	if err != nil {
+		writeIstioError(c, clusterName, kind, installed, err)
+		return
+	}
+	items := []IstioResource{}
+	for i := range objects {
+		items = append(items, istioSummary(&objects[i]))
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Namespace != items[j].Namespace {
+			return items[i].Namespace < items[j].Namespace
+		}
+		return items[i].Name < items[j].Name
+	})
+
+	c.JSON(http.StatusOK, gin.H{"installed": installed, "items": items})
+}
+
+// GetIstioResource handles GET /clusters/:name/istio/:kind/:namespace/:resname with the
+// summary and the spec
+func (h *Handler) GetIstioResource(c *gin.Context) {
+	clusterName := c.Param("name")
+	kind := c.Param("kind")
+
+	gvk, ok := istioKinds[kind]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("unknown Istio kind %q (virtualservices, destinationrules, gateways or peerauthentications)", kind)})
+		return
+	}
+	client, err := h.dynamicClient(c, clusterName)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	mapping, installed, err := h.optionalMapping(clusterName, gvk)
+	if err != nil || !installed {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Istio is not installed in this cluster"})
+		return
+	}
+	obj, err := client.Resource(mapping.Resource).Namespace(c.Param("namespace")).Get(context.Background(), c.Param("resname"), metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		log.Errorf("Failed to get Istio %s %s: %v", kind, c.Param("resname"), err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	spec, _, _ := unstructured.NestedMap(obj.Object, "spec")
+	c.JSON(http.StatusOK, gin.H{"resource": istioSummary(obj), "spec": spec})
+}
+
+// GetMeshView handles GET /clusters/:name/namespaces/:namespace/mesh and correlates the
+// services of a namespace with the VirtualServices routing to them, their
+// DestinationRules and the mTLS mode their PeerAuthentications result in
+func (h *Handler) GetMeshView(c *gin.Context) {
+	clusterName := c.Param("name")
+	namespace := c.Param("namespace")
+
+	client, err := h.client(c, clusterName)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	ns, err := client.CoreV1().Namespaces().Get(context.Background(), namespace, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		log.Errorf("Failed to get namespace %s: %v", namespace, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	services, err := client.CoreV1().Services(namespace).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		log.Errorf("Failed to list services in namespace %s: %v", namespace, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// VirtualServices and DestinationRules can be anywhere when exported to all namespaces;
+	// PeerAuthentications apply from the namespace and the root namespace
+	virtualServices, installed, err := h.listIstioObjects(c, clusterName, "virtualservices", "")
+	if err != nil {
+		writeIstioError(c, clusterName, "virtualservices", installed, err)
+		return
+	}
+	destinationRules, _, err := h.listIstioObjects(c, clusterName, "destinationrules", "")
+	if err != nil {
+		writeIstioError(c, clusterName, "destinationrules", true, err)
+		return
+	}
+	var peerAuthentications []unstructured.Unstructured
+	for _, paNamespace := range []string{namespace, istioRootNamespace} {
+		items, _, err := h.listIstioObjects(c, clusterName, "peerauthentications", paNamespace)
+		if err != nil {
+			writeIstioError(c, clusterName, "peerauthentications", true, err)
+			return
+		}
+		peerAuthentications = append(peerAuthentications, items...)
+	}
+
+	mesh := buildMeshView(namespace, services.Items, virtualServices, destinationRules, peerAuthentications)
+	c.JSON(http.StatusOK, gin.H{
+		"installed":           installed,
+		"namespace":           namespace,
+		"sidecarInjection":    sidecarInjection(ns),
+		"services":            mesh,
+		"virtualServices":     len(virtualServices),
+		"destinationRules":    len(destinationRules),
+		"peerAuthentications": len(peerAuthentications),
+	})
+}
+
+// buildMeshView correlates the services of a namespace with the Istio configuration
+func buildMeshView(namespace string, services []corev1.Service, virtualServices, destinationRules, peerAuthentications []unstructured.Unstructured) []MeshService {
+	mesh := make([]MeshService, 0, len(services))
+	for i := range services {
+		svc := &services[i]
+		entry := MeshService{
+			Name:             svc.Name,
+			Host:             fmt.Sprintf("%s.%s.svc.%s", svc.Name, namespace, clusterDomain),
+			Ports:            []string{},
+			VirtualServices:  []string{},
+			DestinationRules: []string{},
+		}
+		for _, port := range svc.Spec.Ports {
+			entry.Ports = append(entry.Ports, fmt.Sprintf("%s/%d", port.Protocol, port.Port))
+		}
+
+		for j := range virtualServices {
+			vs := &virtualServices[j]
+			summary := istioSummary(vs)
+			hosts := append(append([]string{}, summary.Hosts...), summary.Destinations...)
+			for _, host := range hosts {
+				host, _, _ = strings.Cut(host, "/") // drop the subset
+				if istioHostMatches(host, vs.GetNamespace(), entry.Host) {
+					entry.VirtualServices = append(entry.VirtualServices, vs.GetNamespace()+"/"+vs.GetName())
+					break
+				}
+			}
+		}
+
+		// The DestinationRule in the namespace of the service wins over the others
+		var rule *IstioResource
+		for j := range destinationRules {
+			dr := &destinationRules[j]
+			summary := istioSummary(dr)
+			if !istioHostMatches(summary.Host, dr.GetNamespace(), entry.Host) {
+				continue
+			}
+			entry.DestinationRules = append(entry.DestinationRules, dr.GetNamespace()+"/"+dr.GetName())
+			if rule == nil || dr.GetNamespace() == namespace {
+				rule = &summary
+			}
+		}
+		if rule != nil {
+			entry.Subsets = rule.Subsets
+			entry.ClientTLSMode = rule.TLSMode
+		}
+
+		entry.MTLSMode, entry.MTLSSource = effectiveMTLS(namespace, svc.Spec.Selector, peerAuthentications)
+		mesh = append(mesh, entry)
+	}
+	sort.Slice(mesh, func(i, j int) bool { return mesh[i].Name < mesh[j].Name })
+	return mesh
+}
+
+// effectiveMTLS works out the mTLS mode of the workloads selected by a service. The most
+// specific PeerAuthentication wins: one selecting the workloads, then the namespace-wide
+// one, then the mesh-wide one in the root namespace. UNSET inherits from the next level;
+// without any, Istio accepts plain text and mTLS (PERMISSIVE).
+func effectiveMTLS(namespace string, podLabels map[string]string, peerAuthentications []unstructured.Unstructured) (string, string) {
+	var workload, namespaceWide, meshWide *IstioResource
+	for i := range peerAuthentications {
+		pa := istioSummary(&peerAuthentications[i])
+		switch {
+		case pa.Namespace == namespace && len(pa.Selector) > 0:
+			if len(podLabels) > 0 && labelsMatch(pa.Selector, podLabels) {
+				workload = &pa
+			}
+		case pa.Namespace == namespace:
+			namespaceWide = &pa
+		case pa.Namespace == istioRootNamespace && len(pa.Selector) == 0:
+			meshWide = &pa
+		}
+	}
+	for _, pa := range []*IstioResource{workload, namespaceWide, meshWide} {
+		if pa != nil && pa.MTLSMode != "" && pa.MTLSMode != "UNSET" {
+			return pa.MTLSMode, pa.Namespace + "/" + pa.Name
+		}
+	}
+	return "PERMISSIVE", "default"
+}
+
+// labelsMatch reports whether labels has every label of selector
+func labelsMatch(selector, labels map[string]string) bool {
+	for key, value := range selector {
+		if labels[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// istioHostMatches reports whether an Istio host, relative to the namespace of its object,
+// names a service FQDN. Short names resolve in that namespace; *.suffix wildcards match.
+func istioHostMatches(host, namespace, fqdn string) bool {
+	if host == "" {
+		return false
+	}
+	if host == "*" {
+		return true
+	}
+	if strings.HasPrefix(host, "*.") {
+		return strings.HasSuffix(fqdn, host[1:])
+	}
+	switch strings.Count(host, ".") {
+	case 0:
+		host = fmt.Sprintf("%s.%s.svc.%s", host, namespace, clusterDomain)
+	case 1:
+		host += ".svc." + clusterDomain
+	case 2:
+		if strings.HasSuffix(host, ".svc") {
+			host += "." + clusterDomain
+		}
+	}
+	return host == fqdn
+}
+
+// sidecarInjection describes how sidecars are injected in a namespace: enabled, disabled,
+// the revision (istio.io/rev) or none when the namespace is not labeled
+func sidecarInjection(ns *corev1.Namespace) string {
+	if value, ok := ns.Labels["istio-injection"]; ok {
+		return value
+	}
+	if revision, ok := ns.Labels["istio.io/rev"]; ok {
+		return "revision " + revision
+	}
+	if mode, ok := ns.Labels["istio.io/dataplane-mode"]; ok {
+		return mode // ambient
+	}
+	return "none"
+}
+
+// istioSummary extracts the fields of an Istio object shown in lists and the mesh view
+func istioSummary(obj *unstructured.Unstructured) IstioResource {
+	o := obj.Object
+	summary := IstioResource{Kind: obj.GetKind(), Name: obj.GetName(), Namespace: obj.GetNamespace()}
+	switch obj.GetKind() {
+	case "VirtualService":
+		summary.Hosts, _, _ = unstructured.NestedStringSlice(o, "spec", "hosts")
+		summary.Gateways, _, _ = unstructured.NestedStringSlice(o, "spec", "gateways")
+		seen := map[string]bool{}
+		for _, protocol := range []string{"http", "tcp", "tls"} {
+			for _, route := range nestedObjectList(o, "spec", protocol) {
+				summary.Routes++
+				for _, destination := range nestedObjectList(route, "route") {
+					host := nestedStringValue(destination, "destination", "host")
+					if subset := nestedStringValue(destination, "destination", "subset"); subset != "" {
+						host += "/" + subset
+					}
+					if host != "" && !seen[host] {
+						seen[host] = true
+						summary.Destinations = append(summary.Destinations, host)
+					}
+				}
+			}
+		}
+	case "DestinationRule":
+		summary.Host = nestedStringValue(o, "spec", "host")
+		summary.TLSMode = nestedStringValue(o, "spec", "trafficPolicy", "tls", "mode")
+		for _, subset := range nestedObjectList(o, "spec", "subsets") {
+			summary.Subsets = append(summary.Subsets, nestedStringValue(subset, "name"))
+		}
+	case "Gateway":
+		summary.Selector, _, _ = unstructured.NestedStringMap(o, "spec", "selector")
+		for _, server := range nestedObjectList(o, "spec", "servers") {
+			port, _, _ := unstructured.NestedInt64(server, "port", "number")
+			hosts, _, _ := unstructured.NestedStringSlice(server, "hosts")
+			summary.Servers = append(summary.Servers, fmt.Sprintf("%s/%d %s",
+				nestedStringValue(server, "port", "protocol"), port, strings.Join(hosts, ",")))
+		}
+	case "PeerAuthentication":
+		summary.Selector, _, _ = unstructured.NestedStringMap(o, "spec", "selector", "matchLabels")
+		summary.MTLSMode = nestedStringValue(o, "spec", "mtls", "mode")
+		if summary.MTLSMode == "" {
+			summary.MTLSMode = "UNSET"
+		}
+	}
+	return summary
+}
+
+// writeIstioError writes the response for an error listing Istio objects
+func writeIstioError(c *gin.Context, clusterName, kind string, installed bool, err error) {
+	switch {
+	case !installed:
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case apierrors.IsForbidden(err):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		log.Errorf("Failed to list Istio %s in cluster %s: %v", kind, clusterName, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
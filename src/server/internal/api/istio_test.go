@@ -0,0 +1,77 @@
+package api
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func istioObject(kind, namespace, name string, spec map[string]interface{}) unstructured.Unstructured {
+	return unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "networking.istio.io/v1",
+		"kind":       kind,
+		"metadata":   map[string]interface{}{"name": name, "namespace": namespace},
+		"spec":       spec,
+	}}
+}
+
+func TestBuildMeshView(t *testing.T) {
+	services := []corev1.Service{
+		{ObjectMeta: metav1.ObjectMeta{Name: "reviews", Namespace: "shop"}, Spec: corev1.ServiceSpec{
+			Selector: map[string]string{"app": "reviews"},
+			Ports:    []corev1.ServicePort{{Protocol: corev1.ProtocolTCP, Port: 9080}},
+		}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "legacy", Namespace: "shop"}, Spec: corev1.ServiceSpec{
+			Selector: map[string]string{"app": "legacy"},
+		}},
+	}
+	virtualServices := []unstructured.Unstructured{
+		// Canary split, with a short host name relative to the namespace
+		istioObject("VirtualService", "shop", "reviews", map[string]interface{}{
+			"hosts": []interface{}{"reviews"},
+			"http": []interface{}{map[string]interface{}{"route": []interface{}{
+				map[string]interface{}{"destination": map[string]interface{}{"host": "reviews", "subset": "v1"}, "weight": int64(90)},
+				map[string]interface{}{"destination": map[string]interface{}{"host": "reviews", "subset": "v2"}, "weight": int64(10)},
+			}}},
+		}),
+		// Ingress route from another namespace, by FQDN
+		istioObject("VirtualService", "istio-ingress", "storefront", map[string]interface{}{
+			"hosts":    []interface{}{"shop.example.com"},
+			"gateways": []interface{}{"istio-ingress/public"},
+			"http": []interface{}{map[string]interface{}{"route": []interface{}{
+				map[string]interface{}{"destination": map[string]interface{}{"host": "reviews.shop.svc.cluster.local"}},
+			}}},
+		}),
+	}
+	destinationRules := []unstructured.Unstructured{
+		istioObject("DestinationRule", "shop", "reviews", map[string]interface{}{
+			"host":          "reviews.shop.svc.cluster.local",
+			"trafficPolicy": map[string]interface{}{"tls": map[string]interface{}{"mode": "ISTIO_MUTUAL"}},
+			"subsets":       []interface{}{map[string]interface{}{"name": "v1"}, map[string]interface{}{"name": "v2"}},
+		}),
+	}
+	peerAuthentications := []unstructured.Unstructured{
+		istioObject("PeerAuthentication", "istio-system", "default", map[string]interface{}{"mtls": map[string]interface{}{"mode": "STRICT"}}),
+		istioObject("PeerAuthentication", "shop", "legacy-plaintext", map[string]interface{}{
+			"selector": map[string]interface{}{"matchLabels": map[string]interface{}{"app": "legacy"}},
+			"mtls":     map[string]interface{}{"mode": "PERMISSIVE"},
+		}),
+	}
+
+	mesh := buildMeshView("shop", services, virtualServices, destinationRules, peerAuthentications)
+	if len(mesh) != 2 {
+		t.Fatalf("mesh = %+v, want 2 services", mesh)
+	}
+	legacy, reviews := mesh[0], mesh[1]
+	if len(reviews.VirtualServices) != 2 || len(reviews.DestinationRules) != 1 || len(reviews.Subsets) != 2 || reviews.ClientTLSMode != "ISTIO_MUTUAL" {
+		t.Errorf("reviews = %+v, want both VirtualServices and the DestinationRule with 2 subsets", reviews)
+	}
+	if reviews.MTLSMode != "STRICT" || reviews.MTLSSource != "istio-system/default" {
+		t.Errorf("reviews mTLS = %s from %s, want STRICT from the mesh-wide policy", reviews.MTLSMode, reviews.MTLSSource)
+	}
+	if legacy.MTLSMode != "PERMISSIVE" || legacy.MTLSSource != "shop/legacy-plaintext" || len(legacy.VirtualServices) != 0 {
+		t.Errorf("legacy = %+v, want PERMISSIVE from its workload policy and no routes", legacy)
+	}
+}
@@ -0,0 +1,299 @@
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+
+	"github.com/sonnguyen/kubelens/internal/audit"
+	"github.com/sonnguyen/kubelens/internal/kustomize"
+)
+
+// kustomizeFetchTimeout bounds cloning the git repository of a kustomization
+const kustomizeFetchTimeout = 2 * time.Minute
+
+// kustomizeRequest is the body of POST /clusters/:name/kustomize
+type kustomizeRequest struct {
+	// Archive is a base64 encoded tarball (gzipped or not) of the kustomization sources
+	Archive string `json:"archive"`
+	// Git is a repository to clone the sources from instead (ref: branch or tag)
+	Git *struct {
+		URL string `json:"url"`
+		Ref string `json:"ref"`
+	} `json:"git"`
+	// Path is the directory of the kustomization within the sources (default: the root)
+	Path string `json:"path"`
+	// Namespace is used for namespaced objects the kustomization leaves without one
+	Namespace string `json:"namespace"`
+	// Confirm applies the rendered objects. Digest must be the digest returned by the
+	// preview, so that what is applied is what was reviewed.
+	Confirm bool   `json:"confirm"`
+	Digest  string `json:"digest"`
+	Force   bool   `json:"force"`
+}
+
+// kustomizePreview is the diff of one rendered object against the cluster
+type kustomizePreview struct {
+	Index int `json:"index"`
+	manifestDiff
+	Error string `json:"error,omitempty"`
+}
+
+// Kustomize handles POST /clusters/:name/kustomize. It renders a kustomization from an
+// uploaded archive or a git repository and returns the rendered manifests with a
+// server-side (dry run) diff of every object. Sending the request again with confirm=true
+// and the digest of the preview applies the objects with server-side apply; if the
+// rendered manifests changed in between (e.g. a branch moved) the request fails with 409.
+// Query: dryRun=true (with confirm, validate only)
+func (h *Handler) Kustomize(c *gin.Context) {
+	clusterName := c.Param("name")
+
+	var req kustomizeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	objects, err := renderKustomization(c.Request.Context(), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(objects) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "kustomization rendered no objects"})
+		return
+	}
+	if len(objects) > maxManifestDocuments {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("too many objects (%d), at most %d are allowed", len(objects), maxManifestDocuments)})
+		return
+	}
+
+	manifests, err := joinManifests(objects)
+	if err != nil {
+		log.Errorf("Failed to encode rendered kustomization: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to encode rendered manifests"})
+		return
+	}
+	digest := fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(manifests)))
+	namespace := req.Namespace
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	actions := []string{"read"}
+	if req.Confirm {
+		actions = []string{"create", "update"}
+	}
+	if err := h.checkObjectsScope(c, clusterName, objects, namespace, actions...); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !req.Confirm {
+		previews, err := h.previewObjects(c, clusterName, objects, namespace)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"manifests": manifests,
+			"digest":    digest,
+			"objects":   previews,
+			"total":     len(objects),
+		})
+		return
+	}
+
+	if req.Digest == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "digest of the preview is required to apply"})
+		return
+	}
+	if req.Digest != digest {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":  "the rendered manifests changed since the preview; review the diff again",
+			"digest": digest,
+		})
+		return
+	}
+
+	results, failed, err := h.applyObjects(c, clusterName, objects, namespace, req.Force)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if failed > 0 {
+		log.Warnf("Applied kustomization to cluster %s: %d of %d failed", clusterName, failed, len(objects))
+	}
+
+	// Audit log
+	if userID, exists := c.Get("user_id"); exists {
+		username, _ := c.Get("username")
+		email, _ := c.Get("email")
+
+		objectNames := make([]string, 0, len(results))
+		for _, r := range results {
+			if r.Error == "" {
+				objectNames = append(objectNames, manifestResultName(r))
+			}
+		}
+		audit.Log(c, audit.EventAuditResourceUpdated, userID.(int), username.(string), email.(string),
+			fmt.Sprintf("Applied kustomization (%d objects) to cluster %s (%d failed)", len(objects)-failed, clusterName, failed),
+			map[string]interface{}{
+				"cluster_name": clusterName,
+				"source":       kustomizeSource(&req),
+				"path":         req.Path,
+				"digest":       digest,
+				"objects":      objectNames,
+				"failed":       failed,
+				"force":        req.Force,
+			})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"results": results,
+		"digest":  digest,
+		"total":   len(objects),
+		"applied": len(objects) - failed,
+		"failed":  failed,
+	})
+}
+
+// renderKustomization fetches the sources of a request into a temporary directory and
+// renders the kustomization
+func renderKustomization(ctx context.Context, req *kustomizeRequest) ([]*unstructured.Unstructured, error) {
+	dir, err := os.MkdirTemp("", "kubelens-kustomize-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	switch {
+	case req.Archive != "" && req.Git != nil:
+		return nil, fmt.Errorf("set either archive or git, not both")
+	case req.Archive != "":
+		data, err := base64.StdEncoding.DecodeString(req.Archive)
+		if err != nil {
+			return nil, fmt.Errorf("archive must be base64 encoded: %v", err)
+		}
+		if err := kustomize.ExtractArchive(data, dir); err != nil {
+			return nil, err
+		}
+	case req.Git != nil:
+		ctx, cancel := context.WithTimeout(ctx, kustomizeFetchTimeout)
+		defer cancel()
+		if err := kustomize.CloneGit(ctx, req.Git.URL, req.Git.Ref, dir); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("archive or git is required")
+	}
+
+	path := req.Path
+	if path == "" {
+		path = "."
+	}
+	return kustomize.Render(dir, path)
+}
+
+// previewObjects diffs every rendered object against the cluster with a dry run. Objects
+// in a namespace created by the same kustomization cannot be dry-run yet and are shown as
+// created as rendered.
+func (h *Handler) previewObjects(c *gin.Context, clusterName string, objects []*unstructured.Unstructured, defaultNamespace string) ([]kustomizePreview, error) {
+	client, err := h.dynamicClient(c, clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	newNamespaces := map[string]bool{}
+	for _, obj := range objects {
+		if obj.GetKind() == "Namespace" && obj.GetAPIVersion() == "v1" {
+			newNamespaces[obj.GetName()] = true
+		}
+	}
+
+	ctx := context.Background()
+	previews := make([]kustomizePreview, len(objects))
+	for i, rendered := range objects {
+		obj := rendered.DeepCopy()
+		preview := &previews[i]
+		preview.Index = i
+		preview.APIVersion, preview.Kind, preview.Name = obj.GetAPIVersion(), obj.GetKind(), obj.GetName()
+
+		mapping, err := h.mappingFor(clusterName, obj.GroupVersionKind())
+		if err != nil {
+			preview.Error = err.Error()
+			continue
+		}
+		if isNamespaced(mapping) {
+			if obj.GetNamespace() == "" {
+				obj.SetNamespace(defaultNamespace)
+			}
+		} else {
+			obj.SetNamespace("")
+		}
+		preview.Namespace = obj.GetNamespace()
+
+		diff, err := dryRunDiff(ctx, client.Resource(mapping.Resource).Namespace(obj.GetNamespace()), mapping.GroupVersionKind.Kind, obj, !h.secretsRevealed())
+		if apierrors.IsNotFound(err) && newNamespaces[obj.GetNamespace()] {
+			after := withoutDiffNoise(obj.Object)
+			afterYAML, _ := objectYAMLForDiff(after)
+			diff = &manifestDiff{
+				Action:     "create",
+				APIVersion: obj.GetAPIVersion(),
+				Kind:       mapping.GroupVersionKind.Kind,
+				Name:       obj.GetName(),
+				Namespace:  obj.GetNamespace(),
+				Changes:    structuredDiff(map[string]interface{}{}, after),
+				Diff:       unifiedDiff("", afterYAML, "live", "manifest"),
+			}
+			err = nil
+		}
+		if err != nil {
+			preview.Error = err.Error()
+			continue
+		}
+		preview.manifestDiff = *diff
+	}
+	return previews, nil
+}
+
+// joinManifests encodes objects as a multi-document YAML stream
+func joinManifests(objects []*unstructured.Unstructured) (string, error) {
+	docs := make([]string, 0, len(objects))
+	for _, obj := range objects {
+		data, err := yaml.Marshal(obj.Object)
+		if err != nil {
+			return "", err
+		}
+		docs = append(docs, string(data))
+	}
+	return strings.Join(docs, "---\n"), nil
+}
+
+// kustomizeSource describes where a kustomization came from, for the audit log
+func kustomizeSource(req *kustomizeRequest) string {
+	if req.Git == nil {
+		return "archive"
+	}
+	source := req.Git.URL
+	if u, err := url.Parse(req.Git.URL); err == nil {
+		u.User = nil
+		source = u.String()
+	}
+	if req.Git.Ref != "" {
+		source += "@" + req.Git.Ref
+	}
+	return source
+}
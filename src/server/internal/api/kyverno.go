@@ -0,0 +1,396 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Kyverno policies and the policy reports it writes (the Kubernetes Policy WG API). The
+// version is left empty so that whichever version the cluster prefers is used.
+var (
+	kyvernoPolicyKinds = []schema.GroupVersionKind{
+		{Group: "kyverno.io", Kind: "ClusterPolicy"},
+		{Group: "kyverno.io", Kind: "Policy"},
+	}
+	policyReportKinds = []schema.GroupVersionKind{
+		{Group: "wgpolicyk8s.io", Kind: "ClusterPolicyReport"},
+		{Group: "wgpolicyk8s.io", Kind: "PolicyReport"},
+	}
+)
+
+// maxPolicyFailures bounds the failing results returned by the drill-down
+const maxPolicyFailures = 1000
+
+// KyvernoPolicy is a Kyverno ClusterPolicy or Policy with its report results
+type KyvernoPolicy struct {
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	Namespace  string `json:"namespace,omitempty"`
+	Action     string `json:"validationFailureAction"` // Audit or Enforce
+	Background bool   `json:"background"`
+	Ready      bool   `json:"ready"`
+	Rules      int    `json:"rules"`
+	Category   string `json:"category,omitempty"`
+	Severity   string `json:"severity,omitempty"`
+	PolicyResultCounts
+}
+
+// PolicyResultCounts counts policy report results by outcome
+type PolicyResultCounts struct {
+	Pass  int `json:"pass"`
+	Fail  int `json:"fail"`
+	Warn  int `json:"warn"`
+	Error int `json:"error"`
+	Skip  int `json:"skip"`
+}
+
+func (counts *PolicyResultCounts) add(result string) {
+	switch result {
+	case "pass":
+		counts.Pass++
+	case "fail":
+		counts.Fail++
+	case "warn":
+		counts.Warn++
+	case "error":
+		counts.Error++
+	case "skip":
+		counts.Skip++
+	}
+}
+
+// PolicyComplianceGroup is the results of a policy or namespace
+type PolicyComplianceGroup struct {
+	Name string `json:"name"`
+	PolicyResultCounts
+}
+
+// PolicyCompliance summarizes the policy reports of a cluster
+type PolicyCompliance struct {
+	PolicyResultCounts
+	// Score is the share of passed results among passed and failed ones, in percent;
+	// -1 when there are none
+	Score       float64                 `json:"score"`
+	Reports     int                     `json:"reports"`
+	Policies    []PolicyComplianceGroup `json:"policies"`
+	Namespaces  []PolicyComplianceGroup `json:"namespaces"`
+	ClusterWide PolicyResultCounts      `json:"clusterWide"` // results of cluster-scoped resources
+}
+
+// PolicyResult is a policy report result for one resource
+type PolicyResult struct {
+	Policy     string `json:"policy"`
+	Rule       string `json:"rule,omitempty"`
+	Result     string `json:"result"` // pass, fail, warn, error or skip
+	Severity   string `json:"severity,omitempty"`
+	Category   string `json:"category,omitempty"`
+	Message    string `json:"message,omitempty"`
+	APIVersion string `json:"apiVersion,omitempty"`
+	Kind       string `json:"kind,omitempty"`
+	Name       string `json:"name,omitempty"`
+	Namespace  string `json:"namespace,omitempty"`
+	Timestamp  int64  `json:"timestamp,omitempty"` // unix seconds
+}
+
+// listKyvernoPolicies lists the ClusterPolicies and Policies of a cluster, or
+// installed=false when Kyverno is not installed
+func (h *Handler) listKyvernoPolicies(c *gin.Context, clusterName, namespace string) ([]KyvernoPolicy, bool, error) {
+	client, err := h.dynamicClient(c, clusterName)
+	if err != nil {
+		return nil, false, err
+	}
+	policies := []KyvernoPolicy{}
+	installed := false
+	for _, gvk := range kyvernoPolicyKinds {
+		mapping, ok, err := h.optionalMapping(clusterName, gvk)
+		if err != nil {
+			return nil, false, err
+		}
+		if !ok {
+			continue
+		}
+		installed = true
+		if namespace != "" && gvk.Kind == "ClusterPolicy" {
+			continue
+		}
+		list, err := client.Resource(mapping.Resource).Namespace(namespace).List(context.Background(), metav1.ListOptions{})
+		if err != nil {
+			return nil, true, err
+		}
+		for i := range list.Items {
+			policies = append(policies, kyvernoPolicyStatus(&list.Items[i]))
+		}
+	}
+	return policies, installed, nil
+}
+
+// kyvernoPolicyStatus extracts the settings and readiness of a Kyverno policy
+func kyvernoPolicyStatus(obj *unstructured.Unstructured) KyvernoPolicy {
+	o := obj.Object
+	policy := KyvernoPolicy{
+		Kind:      obj.GetKind(),
+		Name:      obj.GetName(),
+		Namespace: obj.GetNamespace(),
+		Action:    nestedStringValue(o, "spec", "validationFailureAction"),
+		Category:  obj.GetAnnotations()["policies.kyverno.io/category"],
+		Severity:  obj.GetAnnotations()["policies.kyverno.io/severity"],
+	}
+	if policy.Action == "" {
+		policy.Action = "Audit"
+	}
+	policy.Background = true
+	if background, found, _ := unstructured.NestedBool(o, "spec", "background"); found {
+		policy.Background = background
+	}
+	rules, _, _ := unstructured.NestedSlice(o, "spec", "rules")
+	policy.Rules = len(rules)
+
+	// Kyverno 1.9+ reports readiness with a Ready condition, earlier releases with status.ready
+	policy.Ready, _, _ = unstructured.NestedBool(o, "status", "ready")
+	for _, condition := range nestedObjectList(o, "status", "conditions") {
+		if nestedStringValue(condition, "type") == "Ready" {
+			policy.Ready = nestedStringValue(condition, "status") == "True"
+		}
+	}
+	return policy
+}
+
+// listPolicyResults flattens the results of the PolicyReports and ClusterPolicyReports of a
+// cluster, or installed=false when the policy report CRDs are not installed
+func (h *Handler) listPolicyResults(c *gin.Context, clusterName string) ([]PolicyResult, int, bool, error) {
+	client, err := h.dynamicClient(c, clusterName)
+	if err != nil {
+		return nil, 0, false, err
+	}
+	results := []PolicyResult{}
+	reports := 0
+	installed := false
+	for _, gvk := range policyReportKinds {
+		mapping, ok, err := h.optionalMapping(clusterName, gvk)
+		if err != nil {
+			return nil, 0, false, err
+		}
+		if !ok {
+			continue
+		}
+		installed = true
+		list, err := client.Resource(mapping.Resource).List(context.Background(), metav1.ListOptions{})
+		if err != nil {
+			return nil, 0, true, err
+		}
+		reports += len(list.Items)
+		for i := range list.Items {
+			results = append(results, policyReportResults(&list.Items[i])...)
+		}
+	}
+	return results, reports, installed, nil
+}
+
+// policyReportResults flattens a report into one result per resource. Kyverno 1.11+ writes
+// a report per resource, named in its scope, with results that list no resources; earlier
+// releases list the resources of each result.
+func policyReportResults(report *unstructured.Unstructured) []PolicyResult {
+	scope, _, _ := unstructured.NestedMap(report.Object, "scope")
+
+	var results []PolicyResult
+	for _, r := range nestedObjectList(report.Object, "results") {
+		result := PolicyResult{
+			Policy:   nestedStringValue(r, "policy"),
+			Rule:     nestedStringValue(r, "rule"),
+			Result:   strings.ToLower(nestedStringValue(r, "result")),
+			Severity: nestedStringValue(r, "severity"),
+			Category: nestedStringValue(r, "category"),
+			Message:  nestedStringValue(r, "message"),
+		}
+		result.Timestamp, _, _ = unstructured.NestedInt64(r, "timestamp", "seconds")
+
+		resources := nestedObjectList(r, "resources")
+		if len(resources) == 0 && scope != nil {
+			resources = []map[string]interface{}{scope}
+		}
+		if len(resources) == 0 {
+			result.Namespace = report.GetNamespace()
+			results = append(results, result)
+			continue
+		}
+		for _, resource := range resources {
+			result.APIVersion = nestedStringValue(resource, "apiVersion")
+			result.Kind = nestedStringValue(resource, "kind")
+			result.Name = nestedStringValue(resource, "name")
+			result.Namespace = nestedStringValue(resource, "namespace")
+			if result.Namespace == "" && report.GetKind() == "PolicyReport" {
+				result.Namespace = report.GetNamespace()
+			}
+			results = append(results, result)
+		}
+	}
+	return results
+}
+
+// summarizePolicyResults aggregates results per policy and per namespace
+func summarizePolicyResults(results []PolicyResult, reports int) PolicyCompliance {
+	summary := PolicyCompliance{Reports: reports, Policies: []PolicyComplianceGroup{}, Namespaces: []PolicyComplianceGroup{}}
+	byPolicy := map[string]*PolicyResultCounts{}
+	byNamespace := map[string]*PolicyResultCounts{}
+	for _, result := range results {
+		summary.add(result.Result)
+		if byPolicy[result.Policy] == nil {
+			byPolicy[result.Policy] = &PolicyResultCounts{}
+		}
+		byPolicy[result.Policy].add(result.Result)
+		if result.Namespace == "" {
+			summary.ClusterWide.add(result.Result)
+			continue
+		}
+		if byNamespace[result.Namespace] == nil {
+			byNamespace[result.Namespace] = &PolicyResultCounts{}
+		}
+		byNamespace[result.Namespace].add(result.Result)
+	}
+
+	summary.Score = -1
+	if scored := summary.Pass + summary.Fail; scored > 0 {
+		summary.Score = float64(summary.Pass*1000/scored) / 10
+	}
+	summary.Policies = complianceGroups(byPolicy)
+	summary.Namespaces = complianceGroups(byNamespace)
+	return summary
+}
+
+// complianceGroups sorts groups by failures, most first
+func complianceGroups(counts map[string]*PolicyResultCounts) []PolicyComplianceGroup {
+	groups := make([]PolicyComplianceGroup, 0, len(counts))
+	for name, groupCounts := range counts {
+		groups = append(groups, PolicyComplianceGroup{Name: name, PolicyResultCounts: *groupCounts})
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Fail != groups[j].Fail {
+			return groups[i].Fail > groups[j].Fail
+		}
+		return groups[i].Name < groups[j].Name
+	})
+	return groups
+}
+
+// writeKyvernoError writes the response for an error listing Kyverno objects
+func writeKyvernoError(c *gin.Context, clusterName string, installed bool, err error) {
+	switch {
+	case !installed:
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case apierrors.IsForbidden(err):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		log.Errorf("Failed to list Kyverno objects in cluster %s: %v", clusterName, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// ListKyvernoPolicies handles GET /clusters/:name/kyverno/policies with the report results
+// of each policy.
+// Query: namespace (optional) lists the Policies of a namespace only
+func (h *Handler) ListKyvernoPolicies(c *gin.Context) {
+	clusterName := c.Param("name")
+	policies, installed, err := h.listKyvernoPolicies(c, clusterName, c.Query("namespace"))
+	if err != nil {
+		writeKyvernoError(c, clusterName, installed, err)
+		return
+	}
+
+	if installed && len(policies) > 0 {
+		results, _, _, err := h.listPolicyResults(c, clusterName)
+		if err != nil {
+			log.Warnf("Failed to list policy reports in cluster %s: %v", clusterName, err)
+		}
+		// Results name a Policy as <namespace>/<name>
+		counts := map[string]*PolicyResultCounts{}
+		for _, result := range results {
+			if counts[result.Policy] == nil {
+				counts[result.Policy] = &PolicyResultCounts{}
+			}
+			counts[result.Policy].add(result.Result)
+		}
+		for i := range policies {
+			key := policies[i].Name
+			if policies[i].Namespace != "" {
+				key = policies[i].Namespace + "/" + key
+			}
+			if policyCounts := counts[key]; policyCounts != nil {
+				policies[i].PolicyResultCounts = *policyCounts
+			}
+		}
+		sort.Slice(policies, func(i, j int) bool {
+			if policies[i].Namespace != policies[j].Namespace {
+				return policies[i].Namespace < policies[j].Namespace
+			}
+			return policies[i].Name < policies[j].Name
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"installed": installed, "items": policies})
+}
+
+// GetPolicyCompliance handles GET /clusters/:name/kyverno/compliance, the policy report
+// results of a cluster aggregated per policy and namespace
+func (h *Handler) GetPolicyCompliance(c *gin.Context) {
+	clusterName := c.Param("name")
+	results, reports, installed, err := h.listPolicyResults(c, clusterName)
+	if err != nil {
+		writeKyvernoError(c, clusterName, installed, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"installed": installed, "summary": summarizePolicyResults(results, reports)})
+}
+
+// ListPolicyFailures handles GET /clusters/:name/kyverno/failures, the resources failing
+// policies.
+// Query: policy, namespace, kind (optional); result (fail by default; fail, warn or error)
+func (h *Handler) ListPolicyFailures(c *gin.Context) {
+	clusterName := c.Param("name")
+	want := c.DefaultQuery("result", "fail")
+	if want != "fail" && want != "warn" && want != "error" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid result %q (fail, warn or error)", want)})
+		return
+	}
+
+	results, _, installed, err := h.listPolicyResults(c, clusterName)
+	if err != nil {
+		writeKyvernoError(c, clusterName, installed, err)
+		return
+	}
+
+	items := []PolicyResult{}
+	total := 0
+	for _, result := range results {
+		if result.Result != want ||
+			(c.Query("policy") != "" && result.Policy != c.Query("policy")) ||
+			(c.Query("namespace") != "" && result.Namespace != c.Query("namespace")) ||
+			(c.Query("kind") != "" && result.Kind != c.Query("kind")) {
+			continue
+		}
+		total++
+		if len(items) < maxPolicyFailures {
+			items = append(items, result)
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].Namespace != items[j].Namespace {
+			return items[i].Namespace < items[j].Namespace
+		}
+		if items[i].Kind != items[j].Kind {
+			return items[i].Kind < items[j].Kind
+		}
+		return items[i].Name < items[j].Name
+	})
+
+	c.JSON(http.StatusOK, gin.H{"installed": installed, "total": total, "items": items})
+}
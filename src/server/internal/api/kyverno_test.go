@@ -0,0 +1,55 @@
+package api
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestSummarizePolicyReports(t *testing.T) {
+	// Kyverno 1.11+ report of a single resource, named in its scope
+	perResource := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "wgpolicyk8s.io/v1alpha2",
+		"kind":       "PolicyReport",
+		"metadata":   map[string]interface{}{"name": "6f1c", "namespace": "payments"},
+		"scope":      map[string]interface{}{"apiVersion": "apps/v1", "kind": "Deployment", "name": "api", "namespace": "payments"},
+		"results": []interface{}{
+			map[string]interface{}{"policy": "require-labels", "rule": "check-team", "result": "fail", "message": "label team is required"},
+			map[string]interface{}{"policy": "disallow-latest-tag", "rule": "require-image-tag", "result": "pass"},
+		},
+	}}
+	// Earlier releases list the resources of each result
+	clusterReport := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "wgpolicyk8s.io/v1alpha2",
+		"kind":       "ClusterPolicyReport",
+		"metadata":   map[string]interface{}{"name": "clusterpolicyreport"},
+		"results": []interface{}{
+			map[string]interface{}{"policy": "require-labels", "result": "pass", "resources": []interface{}{
+				map[string]interface{}{"kind": "Namespace", "name": "default"},
+				map[string]interface{}{"kind": "Namespace", "name": "payments"},
+			}},
+		},
+	}}
+
+	var results []PolicyResult
+	results = append(results, policyReportResults(perResource)...)
+	results = append(results, policyReportResults(clusterReport)...)
+	if len(results) != 4 || results[0].Kind != "Deployment" || results[0].Namespace != "payments" {
+		t.Fatalf("results = %+v, want 4 results with the scope as resource", results)
+	}
+
+	summary := summarizePolicyResults(results, 2)
+	if summary.Pass != 3 || summary.Fail != 1 || summary.Score != 75 || summary.ClusterWide.Pass != 2 {
+		t.Errorf("summary = %+v, want 3 passed, 1 failed, 2 of them cluster-wide, score 75", summary)
+	}
+	if len(summary.Policies) != 2 || summary.Policies[0].Name != "require-labels" || summary.Policies[0].Fail != 1 {
+		t.Errorf("policies = %+v, want require-labels first with its failure", summary.Policies)
+	}
+	if len(summary.Namespaces) != 1 || summary.Namespaces[0].Name != "payments" {
+		t.Errorf("namespaces = %+v, want payments", summary.Namespaces)
+	}
+
+	if empty := summarizePolicyResults(nil, 0); empty.Score != -1 {
+		t.Errorf("score without results = %v, want -1", empty.Score)
+	}
+}
@@ -0,0 +1,243 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ==================== LimitRange Handlers ====================
+
+// ListLimitRanges returns a list of limit ranges from a cluster namespace (or all namespaces)
+func (h *Handler) ListLimitRanges(c *gin.Context) {
+	clusterName := c.Param("name")
+	namespace := c.Param("namespace")
+
+	client, err := h.client(c, clusterName)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	if namespace == "all" {
+		namespace = ""
+	}
+
+	limitRanges, err := client.CoreV1().LimitRanges(namespace).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		log.Errorf("Failed to list limit ranges: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	result := make([]map[string]interface{}, len(limitRanges.Items))
+	for i, lr := range limitRanges.Items {
+		result[i] = map[string]interface{}{
+			"metadata":    lr.ObjectMeta,
+			"spec":        lr.Spec,
+			"clusterName": clusterName,
+		}
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetLimitRange returns details about a specific limit range
+func (h *Handler) GetLimitRange(c *gin.Context) {
+	clusterName := c.Param("name")
+	namespace := c.Param("namespace")
+	limitRangeName := c.Param("limitrange")
+
+	client, err := h.client(c, clusterName)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	lr, err := client.CoreV1().LimitRanges(namespace).Get(context.Background(), limitRangeName, metav1.GetOptions{})
+	if err != nil {
+		log.Errorf("Failed to get limit range: %v", err)
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, lr)
+}
+
+// CreateLimitRange creates a new limit range
+func (h *Handler) CreateLimitRange(c *gin.Context) {
+	clusterName := c.Param("name")
+	namespace := c.Param("namespace")
+
+	client, err := h.client(c, clusterName)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	var lr corev1.LimitRange
+	if err := c.ShouldBindJSON(&lr); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Ensure namespace matches
+	lr.Namespace = namespace
+
+	created, err := client.CoreV1().LimitRanges(namespace).Create(context.Background(), &lr, createOptions(c))
+	if err != nil {
+		log.Errorf("Failed to create limit range: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, created)
+}
+
+// UpdateLimitRange updates a limit range
+func (h *Handler) UpdateLimitRange(c *gin.Context) {
+	clusterName := c.Param("name")
+	namespace := c.Param("namespace")
+	limitRangeName := c.Param("limitrange")
+
+	client, err := h.client(c, clusterName)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	var lr corev1.LimitRange
+	if err := c.ShouldBindJSON(&lr); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Ensure namespace and name match
+	lr.Namespace = namespace
+	lr.Name = limitRangeName
+
+	updated, err := client.CoreV1().LimitRanges(namespace).Update(context.Background(), &lr, updateOptions(c))
+	if err != nil {
+		log.Errorf("Failed to update limit range: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+// DeleteLimitRange deletes a limit range
+func (h *Handler) DeleteLimitRange(c *gin.Context) {
+	clusterName := c.Param("name")
+	namespace := c.Param("namespace")
+	limitRangeName := c.Param("limitrange")
+
+	client, err := h.client(c, clusterName)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = client.CoreV1().LimitRanges(namespace).Delete(context.Background(), limitRangeName, deleteOptions(c))
+	if err != nil {
+		log.Errorf("Failed to delete limit range: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "LimitRange deleted successfully"})
+}
+
+// NamespaceLimits is the aggregated view of all LimitRanges in a namespace
+type NamespaceLimits struct {
+	Namespace   string                                     `json:"namespace"`
+	LimitRanges []string                                   `json:"limitRanges"`
+	Limits      map[corev1.LimitType]corev1.LimitRangeItem `json:"limits"`
+}
+
+// GetLimitRangesByNamespace aggregates LimitRanges per namespace into the
+// effective constraints: the tightest min/max across objects, and the first
+// default/defaultRequest found (the admission plugin applies them in the same way).
+func (h *Handler) GetLimitRangesByNamespace(c *gin.Context) {
+	clusterName := c.Param("name")
+
+	client, err := h.client(c, clusterName)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	limitRanges, err := client.CoreV1().LimitRanges("").List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		log.Errorf("Failed to list limit ranges: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	byNamespace := make(map[string]*NamespaceLimits)
+	for _, lr := range limitRanges.Items {
+		nl, ok := byNamespace[lr.Namespace]
+		if !ok {
+			nl = &NamespaceLimits{
+				Namespace:   lr.Namespace,
+				LimitRanges: []string{},
+				Limits:      make(map[corev1.LimitType]corev1.LimitRangeItem),
+			}
+			byNamespace[lr.Namespace] = nl
+		}
+		nl.LimitRanges = append(nl.LimitRanges, lr.Name)
+
+		for _, item := range lr.Spec.Limits {
+			nl.Limits[item.Type] = mergeLimitRangeItems(nl.Limits[item.Type], item)
+		}
+	}
+
+	result := make([]*NamespaceLimits, 0, len(byNamespace))
+	for _, nl := range byNamespace {
+		result = append(result, nl)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Namespace < result[j].Namespace
+	})
+
+	c.JSON(http.StatusOK, gin.H{"namespaces": result, "clusterName": clusterName})
+}
+
+// mergeLimitRangeItems folds item into acc, keeping the most restrictive bounds
+func mergeLimitRangeItems(acc, item corev1.LimitRangeItem) corev1.LimitRangeItem {
+	acc.Type = item.Type
+	acc.Max = mergeResourceList(acc.Max, item.Max, tighterMax)
+	acc.Min = mergeResourceList(acc.Min, item.Min, tighterMin)
+	acc.MaxLimitRequestRatio = mergeResourceList(acc.MaxLimitRequestRatio, item.MaxLimitRequestRatio, tighterMax)
+	acc.Default = mergeResourceList(acc.Default, item.Default, keepFirst)
+	acc.DefaultRequest = mergeResourceList(acc.DefaultRequest, item.DefaultRequest, keepFirst)
+	return acc
+}
+
+// Comparison policies for mergeResourceList; cmp is next.Cmp(current)
+func tighterMax(cmp int) bool { return cmp < 0 }
+func tighterMin(cmp int) bool { return cmp > 0 }
+func keepFirst(cmp int) bool  { return false }
+
+// mergeResourceList adds resources from next to acc. When both define a
+// resource, replace decides whether the new value wins.
+func mergeResourceList(acc, next corev1.ResourceList, replace func(cmp int) bool) corev1.ResourceList {
+	if len(next) == 0 {
+		return acc
+	}
+	if acc == nil {
+		acc = corev1.ResourceList{}
+	}
+	for name, qty := range next {
+		current, exists := acc[name]
+		if !exists || replace(qty.Cmp(current)) {
+			acc[name] = qty.DeepCopy()
+		}
+	}
+	return acc
+}
@@ -0,0 +1,217 @@
+package api_test
+
+import (
+	"net/http"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/sonnguyen/kubelens/internal/api"
+	"github.com/sonnguyen/kubelens/internal/apitest"
+)
+
+func limitRange(namespace, name string, items ...corev1.LimitRangeItem) *corev1.LimitRange {
+	return &corev1.LimitRange{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec:       corev1.LimitRangeSpec{Limits: items},
+	}
+}
+
+// resources builds a ResourceList from name/quantity pairs
+func resources(pairs ...string) corev1.ResourceList {
+	list := corev1.ResourceList{}
+	for i := 0; i+1 < len(pairs); i += 2 {
+		list[corev1.ResourceName(pairs[i])] = resource.MustParse(pairs[i+1])
+	}
+	return list
+}
+
+func TestGetLimitRangesByNamespace(t *testing.T) {
+	tests := []struct {
+		name    string
+		objects []runtime.Object
+		// want maps namespace and limit type to the effective item
+		want map[string]map[corev1.LimitType]corev1.LimitRangeItem
+	}{
+		{
+			name: "single range",
+			objects: []runtime.Object{
+				limitRange("shop", "defaults", corev1.LimitRangeItem{
+					Type:           corev1.LimitTypeContainer,
+					Max:            resources("cpu", "2"),
+					Min:            resources("cpu", "100m"),
+					Default:        resources("cpu", "500m"),
+					DefaultRequest: resources("cpu", "250m"),
+				}),
+			},
+			want: map[string]map[corev1.LimitType]corev1.LimitRangeItem{
+				"shop": {corev1.LimitTypeContainer: {
+					Max:            resources("cpu", "2"),
+					Min:            resources("cpu", "100m"),
+					Default:        resources("cpu", "500m"),
+					DefaultRequest: resources("cpu", "250m"),
+				}},
+			},
+		},
+		{
+			name: "multiple ranges keep the tightest bounds",
+			objects: []runtime.Object{
+				limitRange("shop", "wide", corev1.LimitRangeItem{
+					Type: corev1.LimitTypeContainer,
+					Max:  resources("cpu", "4", "memory", "2Gi"),
+					Min:  resources("cpu", "100m"),
+				}),
+				limitRange("shop", "narrow", corev1.LimitRangeItem{
+					Type: corev1.LimitTypeContainer,
+					Max:  resources("cpu", "1"),
+					Min:  resources("cpu", "200m", "memory", "64Mi"),
+				}),
+			},
+			want: map[string]map[corev1.LimitType]corev1.LimitRangeItem{
+				"shop": {corev1.LimitTypeContainer: {
+					Max: resources("cpu", "1", "memory", "2Gi"),
+					Min: resources("cpu", "200m", "memory", "64Mi"),
+				}},
+			},
+		},
+		{
+			name: "missing min and max",
+			objects: []runtime.Object{
+				limitRange("shop", "defaults", corev1.LimitRangeItem{
+					Type:    corev1.LimitTypeContainer,
+					Default: resources("memory", "256Mi"),
+				}),
+				limitRange("shop", "ceiling", corev1.LimitRangeItem{
+					Type: corev1.LimitTypeContainer,
+					Max:  resources("memory", "1Gi"),
+				}),
+			},
+			want: map[string]map[corev1.LimitType]corev1.LimitRangeItem{
+				"shop": {corev1.LimitTypeContainer: {
+					Max:     resources("memory", "1Gi"),
+					Default: resources("memory", "256Mi"),
+				}},
+			},
+		},
+		{
+			name: "mixed container, pod and claim types",
+			objects: []runtime.Object{
+				limitRange("shop", "containers",
+					corev1.LimitRangeItem{Type: corev1.LimitTypeContainer, Max: resources("cpu", "1")},
+					corev1.LimitRangeItem{Type: corev1.LimitTypePod, Max: resources("cpu", "2")},
+				),
+				limitRange("shop", "storage",
+					corev1.LimitRangeItem{Type: corev1.LimitTypePersistentVolumeClaim, Max: resources("storage", "10Gi")},
+					corev1.LimitRangeItem{Type: corev1.LimitTypePod, Max: resources("cpu", "3", "memory", "4Gi")},
+				),
+			},
+			want: map[string]map[corev1.LimitType]corev1.LimitRangeItem{
+				"shop": {
+					corev1.LimitTypeContainer:             {Max: resources("cpu", "1")},
+					corev1.LimitTypePod:                   {Max: resources("cpu", "2", "memory", "4Gi")},
+					corev1.LimitTypePersistentVolumeClaim: {Max: resources("storage", "10Gi")},
+				},
+			},
+		},
+		{
+			name: "conflicting quantities in different units",
+			objects: []runtime.Object{
+				limitRange("shop", "binary", corev1.LimitRangeItem{
+					Type:                 corev1.LimitTypeContainer,
+					Max:                  resources("memory", "1Gi", "cpu", "1500m"),
+					Min:                  resources("memory", "100Mi"),
+					MaxLimitRequestRatio: resources("cpu", "4"),
+				}),
+				limitRange("shop", "decimal", corev1.LimitRangeItem{
+					Type:                 corev1.LimitTypeContainer,
+					Max:                  resources("memory", "1000M", "cpu", "2"),
+					Min:                  resources("memory", "100M"),
+					MaxLimitRequestRatio: resources("cpu", "2"),
+				}),
+			},
+			want: map[string]map[corev1.LimitType]corev1.LimitRangeItem{
+				"shop": {corev1.LimitTypeContainer: {
+					Max:                  resources("memory", "1000M", "cpu", "1500m"),
+					Min:                  resources("memory", "100Mi"),
+					MaxLimitRequestRatio: resources("cpu", "2"),
+				}},
+			},
+		},
+		{
+			name: "namespaces are not merged",
+			objects: []runtime.Object{
+				limitRange("shop", "defaults", corev1.LimitRangeItem{Type: corev1.LimitTypeContainer, Max: resources("cpu", "1")}),
+				limitRange("billing", "defaults", corev1.LimitRangeItem{Type: corev1.LimitTypeContainer, Max: resources("cpu", "2")}),
+			},
+			want: map[string]map[corev1.LimitType]corev1.LimitRangeItem{
+				"billing": {corev1.LimitTypeContainer: {Max: resources("cpu", "2")}},
+				"shop":    {corev1.LimitTypeContainer: {Max: resources("cpu", "1")}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := apitest.New(t, tt.objects...)
+			w := s.Get("/api/v1/clusters/test/limitranges/by-namespace")
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d: %s", w.Code, w.Body)
+			}
+			var resp struct {
+				Namespaces []api.NamespaceLimits `json:"namespaces"`
+			}
+			apitest.DecodeJSON(t, w, &resp)
+
+			if len(resp.Namespaces) != len(tt.want) {
+				t.Fatalf("got %d namespaces, want %d: %s", len(resp.Namespaces), len(tt.want), w.Body)
+			}
+			for i, nl := range resp.Namespaces {
+				if i > 0 && resp.Namespaces[i-1].Namespace >= nl.Namespace {
+					t.Errorf("namespaces are not sorted: %s after %s", nl.Namespace, resp.Namespaces[i-1].Namespace)
+				}
+				want, ok := tt.want[nl.Namespace]
+				if !ok {
+					t.Errorf("unexpected namespace %s", nl.Namespace)
+					continue
+				}
+				if len(nl.Limits) != len(want) {
+					t.Errorf("%s: got limit types %v, want %d", nl.Namespace, nl.Limits, len(want))
+				}
+				for limitType, item := range want {
+					got := nl.Limits[limitType]
+					if got.Type != limitType {
+						t.Errorf("%s/%s: type = %q", nl.Namespace, limitType, got.Type)
+					}
+					checkResources(t, nl.Namespace+"/"+string(limitType)+" max", got.Max, item.Max)
+					checkResources(t, nl.Namespace+"/"+string(limitType)+" min", got.Min, item.Min)
+					checkResources(t, nl.Namespace+"/"+string(limitType)+" maxLimitRequestRatio", got.MaxLimitRequestRatio, item.MaxLimitRequestRatio)
+					checkResources(t, nl.Namespace+"/"+string(limitType)+" default", got.Default, item.Default)
+					checkResources(t, nl.Namespace+"/"+string(limitType)+" defaultRequest", got.DefaultRequest, item.DefaultRequest)
+				}
+			}
+		})
+	}
+}
+
+func TestGetLimitRangesByNamespaceUnknownCluster(t *testing.T) {
+	s := apitest.New(t)
+	if w := s.Get("/api/v1/clusters/missing/limitranges/by-namespace"); w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404: %s", w.Code, w.Body)
+	}
+}
+
+func checkResources(t *testing.T, what string, got, want corev1.ResourceList) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Errorf("%s = %v, want %v", what, got, want)
+		return
+	}
+	for name, qty := range want {
+		if value, ok := got[name]; !ok || value.Cmp(qty) != 0 {
+			t.Errorf("%s %s = %s, want %s", what, name, value.String(), qty.String())
+		}
+	}
+}
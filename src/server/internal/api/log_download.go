@@ -0,0 +1,203 @@
+package api
+
+import (
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// unsafeFilenameChars are replaced in the names of downloaded files
+var unsafeFilenameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// containerLog is the log of one container in a download
+type containerLog struct {
+	pod, container string
+}
+
+// DownloadPodLogs handles GET /clusters/:name/namespaces/:namespace/pods/:pod/logs/download:
+// the log of the container, or of every container of the pod, as an attachment
+// Query: container, format (gzip or zip; default gzip for one container, zip for several),
+// sinceTime (RFC 3339), sinceSeconds, tailLines, previous, timestamps, and the filter of
+// GetPodLogs (match, invert, level). Without bounds the full log is sent.
+func (h *Handler) DownloadPodLogs(c *gin.Context) {
+	h.downloadLogs(c, []string{c.Param("pod")})
+}
+
+// DownloadMultiPodLogs handles GET /clusters/:name/namespaces/:namespace/pods/logs/download:
+// the logs of the pods given with ?pods=, like DownloadPodLogs
+func (h *Handler) DownloadMultiPodLogs(c *gin.Context) {
+	pods := c.QueryArray("pods")
+	if len(pods) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No pods specified"})
+		return
+	}
+	h.downloadLogs(c, pods)
+}
+
+func (h *Handler) downloadLogs(c *gin.Context, pods []string) {
+	clusterName := c.Param("name")
+	namespace := c.Param("namespace")
+	container := c.Query("container")
+
+	filter, err := parseLogFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	logOptions, err := downloadLogOptions(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	format := c.Query("format")
+	if format != "" && format != "gzip" && format != "zip" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be gzip or zip"})
+		return
+	}
+
+	client, err := h.client(c, clusterName)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	var logs []containerLog
+	for _, podName := range pods {
+		pod, err := client.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("Pod %s not found", podName)})
+			return
+		}
+		if container != "" {
+			logs = append(logs, containerLog{pod: pod.Name, container: container})
+			continue
+		}
+		for _, ctr := range pod.Spec.Containers {
+			logs = append(logs, containerLog{pod: pod.Name, container: ctr.Name})
+		}
+	}
+	if len(logs) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No containers found"})
+		return
+	}
+	if format == "" {
+		format = "zip"
+		if len(logs) == 1 {
+			format = "gzip"
+		}
+	}
+
+	// cluster_namespace_pod[_container]_20240501-120000
+	parts := []string{clusterName, namespace, "pods"}
+	if len(pods) == 1 {
+		parts[2] = pods[0]
+		if len(logs) == 1 {
+			parts = append(parts, logs[0].container)
+		}
+	}
+	parts = append(parts, time.Now().UTC().Format("20060102-150405"))
+	name := unsafeFilenameChars.ReplaceAllString(strings.Join(parts, "_"), "-")
+
+	if format == "zip" {
+		c.Header("Content-Type", "application/zip")
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.zip"`, name))
+		c.Status(http.StatusOK)
+		zw := zip.NewWriter(c.Writer)
+		defer zw.Close()
+		for _, l := range logs {
+			w, err := zw.CreateHeader(&zip.FileHeader{
+				Name:     l.pod + "/" + l.container + ".log",
+				Method:   zip.Deflate,
+				Modified: time.Now(),
+			})
+			if err != nil {
+				return
+			}
+			if err := copyContainerLog(ctx, client, namespace, l, logOptions, filter, w); err != nil {
+				log.Warnf("Failed to download logs of %s/%s/%s: %v", namespace, l.pod, l.container, err)
+				fmt.Fprintf(w, "\n*** failed to read the log: %v\n", err)
+			}
+		}
+		return
+	}
+
+	c.Header("Content-Type", "application/gzip")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.log.gz"`, name))
+	c.Status(http.StatusOK)
+	gz := gzip.NewWriter(c.Writer)
+	gz.Name = name + ".log"
+	defer gz.Close()
+	for _, l := range logs {
+		if len(logs) > 1 {
+			fmt.Fprintf(gz, "==> %s/%s <==\n", l.pod, l.container)
+		}
+		if err := copyContainerLog(ctx, client, namespace, l, logOptions, filter, gz); err != nil {
+			log.Warnf("Failed to download logs of %s/%s/%s: %v", namespace, l.pod, l.container, err)
+			fmt.Fprintf(gz, "\n*** failed to read the log: %v\n", err)
+		}
+	}
+}
+
+// downloadLogOptions reads the bounds of a log download
+func downloadLogOptions(c *gin.Context) (corev1.PodLogOptions, error) {
+	options := corev1.PodLogOptions{
+		Previous:   c.Query("previous") == "true",
+		Timestamps: c.Query("timestamps") == "true",
+	}
+	if value := c.Query("sinceTime"); value != "" {
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return options, fmt.Errorf("invalid sinceTime, expected RFC 3339")
+		}
+		since := metav1.NewTime(t)
+		options.SinceTime = &since
+	}
+	if value := c.Query("sinceSeconds"); value != "" {
+		seconds, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || seconds < 1 {
+			return options, fmt.Errorf("invalid sinceSeconds")
+		}
+		options.SinceSeconds = &seconds
+	}
+	if value := c.Query("tailLines"); value != "" {
+		lines, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || lines < 0 {
+			return options, fmt.Errorf("invalid tailLines")
+		}
+		options.TailLines = &lines
+	}
+	if options.SinceTime != nil && options.SinceSeconds != nil {
+		return options, fmt.Errorf("sinceTime and sinceSeconds cannot be combined")
+	}
+	return options, nil
+}
+
+// copyContainerLog writes the log of a container, keeping the lines of filter if any
+func copyContainerLog(ctx context.Context, client kubernetes.Interface, namespace string, l containerLog, options corev1.PodLogOptions, filter *logFilter, w io.Writer) error {
+	options.Container = l.container
+	stream, err := client.CoreV1().Pods(namespace).GetLogs(l.pod, &options).Stream(ctx)
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+
+	if filter == nil {
+		_, err = io.Copy(w, stream)
+		return err
+	}
+	return filter.copy(w, stream)
+}
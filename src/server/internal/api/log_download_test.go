@@ -0,0 +1,72 @@
+package api_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/sonnguyen/kubelens/internal/apitest"
+)
+
+func TestDownloadPodLogs(t *testing.T) {
+	pod := func(name string, containers ...string) *corev1.Pod {
+		p := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "shop"}}
+		for _, c := range containers {
+			p.Spec.Containers = append(p.Spec.Containers, corev1.Container{Name: c})
+		}
+		return p
+	}
+	s := apitest.New(t, pod("web-1", "web"), pod("web-2", "web", "proxy"))
+	base := "/api/v1/clusters/" + apitest.ClusterName + "/namespaces/shop/pods/"
+
+	// One container: gzip
+	w := s.Get(base + "web-1/logs/download")
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/gzip" {
+		t.Fatalf("status %d, content type %s: %s", w.Code, w.Header().Get("Content-Type"), w.Body.String())
+	}
+	if disposition := w.Header().Get("Content-Disposition"); !strings.Contains(disposition, `filename="`+apitest.ClusterName+`_shop_web-1_web_`) {
+		t.Errorf("Content-Disposition = %s", disposition)
+	}
+	gz, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := io.ReadAll(gz); string(data) != "fake logs" {
+		t.Errorf("log = %q, want the container log", data)
+	}
+
+	// Several containers: zip, one file each
+	w = s.Get(base + "logs/download?pods=web-1&pods=web-2")
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/zip" {
+		t.Fatalf("status %d, content type %s: %s", w.Code, w.Header().Get("Content-Type"), w.Body.String())
+	}
+	archive, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, f := range archive.File {
+		names = append(names, f.Name)
+	}
+	if got := strings.Join(names, ","); got != "web-1/web.log,web-2/web.log,web-2/proxy.log" {
+		t.Errorf("archive files = %s", got)
+	}
+
+	for path, want := range map[string]int{
+		base + "web-3/logs/download":                  http.StatusNotFound,
+		base + "web-1/logs/download?format=rar":       http.StatusBadRequest,
+		base + "web-1/logs/download?sinceTime=monday": http.StatusBadRequest,
+		base + "logs/download":                        http.StatusBadRequest,
+	} {
+		if w := s.Get(path); w.Code != want {
+			t.Errorf("GET %s: status %d, want %d", path, w.Code, want)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package api
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Log levels, from the least severe
+var logLevels = map[string]int{
+	"trace": 0, "debug": 1, "info": 2, "notice": 2,
+	"warn": 3, "warning": 3,
+	"error": 4, "err": 4,
+	"fatal": 5, "panic": 5, "critical": 5, "crit": 5,
+}
+
+var (
+	// level=error, "level":"error", "severity": "ERROR" and the like
+	levelFieldPattern = regexp.MustCompile(`(?i)"?\b(?:level|lvl|severity|loglevel)"?\s*[:=]\s*"?([a-z]+)`)
+	// [ERROR], ERROR: or a bare ERROR near the start of the line
+	levelTokenPattern = regexp.MustCompile(`\b(TRACE|DEBUG|INFO|NOTICE|WARN|WARNING|ERROR|ERR|FATAL|PANIC|CRITICAL|CRIT)\b`)
+	// klog: E0612 10:04:05.123456 ...
+	klogPattern = regexp.MustCompile(`^(?:\S+ )?([IWEF])\d{4} `)
+)
+
+// levelTokenWindow bounds how far in a line a bare level word is looked for, past the
+// timestamp, so words of the message do not count
+const levelTokenWindow = 80
+
+// maxLogLine bounds the lines read when logs are filtered
+const maxLogLine = 1024 * 1024
+
+// logFilter keeps the log lines matching the match, invert, level and field query parameters
+type logFilter struct {
+	match    *regexp.Regexp
+	invert   bool
+	minLevel int // -1 for any
+	fields   []fieldMatch
+}
+
+// fieldMatch keeps the JSON log lines with a field of a value
+type fieldMatch struct {
+	path  string // see lookupField
+	value string
+}
+
+// parseLogFilter reads the filter of a log request: ?match=<regexp>&invert=true keeps the
+// lines that do (not) match, ?level=warn the lines of that level and above, and each
+// ?field=<path>=<value> the JSON lines whose field has the value (e.g. field=http.status=500).
+// It returns nil when the request filters nothing.
+func parseLogFilter(c *gin.Context) (*logFilter, error) {
+	f := &logFilter{invert: c.Query("invert") == "true", minLevel: -1}
+	if match := c.Query("match"); match != "" {
+		re, err := regexp.Compile(match)
+		if err != nil {
+			return nil, fmt.Errorf("invalid match: %v", err)
+		}
+		f.match = re
+	}
+	if level := c.Query("level"); level != "" {
+		min, ok := logLevels[strings.ToLower(level)]
+		if !ok {
+			return nil, fmt.Errorf("invalid level %q, expected trace, debug, info, warn, error or fatal", level)
+		}
+		f.minLevel = min
+	}
+	for _, field := range c.QueryArray("field") {
+		path, value, found := strings.Cut(field, "=")
+		if !found || path == "" {
+			return nil, fmt.Errorf("invalid field %q, expected <path>=<value>", field)
+		}
+		f.fields = append(f.fields, fieldMatch{path: path, value: value})
+	}
+	if f.match == nil && f.minLevel < 0 && len(f.fields) == 0 {
+		return nil, nil
+	}
+	return f, nil
+}
+
+// lineLevel returns the level of a log line, -1 when it has none
+func lineLevel(line string) int {
+	if fields := parseJSONLine(line); fields != nil {
+		if value, ok := takeField(fields, levelKeys); ok {
+			if level, ok := logLevels[normalizeLevel(value)]; ok {
+				return level
+			}
+		}
+		return -1
+	}
+	if m := levelFieldPattern.FindStringSubmatch(line); m != nil {
+		if level, ok := logLevels[strings.ToLower(m[1])]; ok {
+			return level
+		}
+	}
+	if m := klogPattern.FindStringSubmatch(line); m != nil {
+		return map[string]int{"I": 2, "W": 3, "E": 4, "F": 5}[m[1]]
+	}
+	head := line
+	if len(head) > levelTokenWindow {
+		head = head[:levelTokenWindow]
+	}
+	if m := levelTokenPattern.FindStringSubmatch(head); m != nil {
+		return logLevels[strings.ToLower(m[1])]
+	}
+	return -1
+}
+
+// lines returns the filter of the lines of one log stream, in order. Lines without a level,
+// such as those of a stack trace, take the level of the line before them.
+func (f *logFilter) lines() func(line string) bool {
+	last := -1
+	return func(line string) bool {
+		if f.minLevel >= 0 {
+			if level := lineLevel(line); level >= 0 {
+				last = level
+			}
+			if last < f.minLevel {
+				return false
+			}
+		}
+		if f.match != nil && f.match.MatchString(line) == f.invert {
+			return false
+		}
+		return f.matchFields(line)
+	}
+}
+
+// matchFields reports whether a line has the fields of the filter; lines that are not JSON
+// have none
+func (f *logFilter) matchFields(line string) bool {
+	if len(f.fields) == 0 {
+		return true
+	}
+	fields := parseJSONLine(line)
+	if fields == nil {
+		return false
+	}
+	for _, match := range f.fields {
+		value, ok := lookupField(fields, match.path)
+		if !ok || fieldString(value) != match.value {
+			return false
+		}
+	}
+	return true
+}
+
+// fieldString returns a field of a JSON log line as compared to field filters: strings as
+// they are, other values as JSON
+func fieldString(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	data, _ := json.Marshal(value)
+	return string(data)
+}
+
+// filterLogs returns the lines of logs the filter keeps
+func (f *logFilter) filterLogs(logs io.Reader) (string, error) {
+	var out strings.Builder
+	err := f.copy(&out, logs)
+	return out.String(), err
+}
+
+// copy writes the lines of logs the filter keeps
+func (f *logFilter) copy(w io.Writer, logs io.Reader) error {
+	keep := f.lines()
+	scanner := bufio.NewScanner(logs)
+	scanner.Buffer(make([]byte, 64*1024), maxLogLine)
+	for scanner.Scan() {
+		if line := scanner.Text(); keep(line) {
+			if _, err := io.WriteString(w, line+"\n"); err != nil {
+				return err
+			}
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,65 @@
+package api
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestLineLevel(t *testing.T) {
+	for line, want := range map[string]int{
+		`{"ts":1,"level":"error","msg":"boom"}`:                       4,
+		`time=2024-05-01T12:00:00Z level=warn msg="slow request"`:     3,
+		`2024-05-01T12:00:00Z [INFO] listening on :8080`:              2,
+		`E0501 12:00:00.000000       1 controller.go:42] sync failed`: 4,
+		`2024-05-01 12:00:00,123 DEBUG worker: polling`:               1,
+		`GET /healthz 200 (no error)`:                                 -1,
+	} {
+		if got := lineLevel(line); got != want {
+			t.Errorf("lineLevel(%s) = %d, want %d", line, got, want)
+		}
+	}
+}
+
+func TestLogFilter(t *testing.T) {
+	logs := strings.Join([]string{
+		"INFO starting",
+		"ERROR request failed: timeout",
+		"    at handler.go:12",
+		"WARN retrying request",
+		"INFO request done",
+	}, "\n")
+	for query, want := range map[string]string{
+		"level=warn":                   "ERROR request failed: timeout\n    at handler.go:12\nWARN retrying request\n",
+		"match=request":                "ERROR request failed: timeout\nWARN retrying request\nINFO request done\n",
+		"match=request&invert=true":    "INFO starting\n    at handler.go:12\n",
+		"match=request&level=error":    "ERROR request failed: timeout\n",
+		"match=%5Brequest&level=error": "invalid",
+		"level=loud":                   "invalid",
+	} {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest("GET", "/logs?"+query, nil)
+		filter, err := parseLogFilter(c)
+		if err != nil {
+			if want != "invalid" {
+				t.Errorf("%s: %v", query, err)
+			}
+			continue
+		}
+		if want == "invalid" {
+			t.Errorf("%s: expected an error", query)
+			continue
+		}
+		if got, _ := filter.filterLogs(strings.NewReader(logs)); got != want {
+			t.Errorf("%s: logs = %q, want %q", query, got, want)
+		}
+	}
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/logs?tailLines=10", nil)
+	if filter, err := parseLogFilter(c); filter != nil || err != nil {
+		t.Errorf("parseLogFilter() without filter = %v, %v", filter, err)
+	}
+}
@@ -0,0 +1,324 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/sonnguyen/kubelens/internal/diagnostics"
+)
+
+const (
+	// maxFollowedLogs bounds the container logs streamed at once for a label selector
+	maxFollowedLogs = 100
+	// followRetryDelay is the wait before watching the selected pods again after a failure
+	followRetryDelay = 5 * time.Second
+)
+
+// podSelector returns the label selector of the pods streamed by MultiPodLogsStream: the
+// selector given, or the pod selector of a workload given as <kind>/<name>
+func podSelector(ctx context.Context, client kubernetes.Interface, namespace, selector, workload string) (string, error) {
+	if workload == "" {
+		parsed, err := labels.Parse(selector)
+		if err != nil {
+			return "", fmt.Errorf("invalid selector: %v", err)
+		}
+		if parsed.Empty() {
+			return "", fmt.Errorf("selector must not be empty")
+		}
+		return parsed.String(), nil
+	}
+	if selector != "" {
+		return "", fmt.Errorf("give a selector or a workload, not both")
+	}
+
+	kind, name, found := strings.Cut(workload, "/")
+	if !found || name == "" {
+		return "", fmt.Errorf("workload must be <kind>/<name>")
+	}
+	var labelSelector *metav1.LabelSelector
+	var err error
+	switch strings.ToLower(kind) {
+	case "deployment", "deployments", "deploy":
+		d, e := client.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err = e; err == nil {
+			labelSelector = d.Spec.Selector
+		}
+	case "statefulset", "statefulsets", "sts":
+		s, e := client.AppsV1().StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err = e; err == nil {
+			labelSelector = s.Spec.Selector
+		}
+	case "daemonset", "daemonsets", "ds":
+		d, e := client.AppsV1().DaemonSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err = e; err == nil {
+			labelSelector = d.Spec.Selector
+		}
+	case "replicaset", "replicasets", "rs":
+		r, e := client.AppsV1().ReplicaSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err = e; err == nil {
+			labelSelector = r.Spec.Selector
+		}
+	case "job", "jobs":
+		j, e := client.BatchV1().Jobs(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err = e; err == nil {
+			labelSelector = j.Spec.Selector
+		}
+	default:
+		return "", fmt.Errorf("unsupported workload kind %q (deployment, statefulset, daemonset, replicaset or job)", kind)
+	}
+	if err != nil {
+		return "", err
+	}
+	parsed, err := metav1.LabelSelectorAsSelector(labelSelector)
+	if err != nil || parsed.Empty() {
+		return "", fmt.Errorf("%s has no usable pod selector", workload)
+	}
+	return parsed.String(), nil
+}
+
+// podLogStream streams the log of one container to a sink, a line at a time prefixed with
+// the pod name, or as records when structured, until the log ends or ctx is canceled
+func podLogStream(ctx context.Context, client kubernetes.Interface, sink logSink, namespace, pod, prefix string, logOptions *corev1.PodLogOptions, filter *logFilter, structured bool) {
+	stream, err := client.CoreV1().Pods(namespace).GetLogs(pod, logOptions).Stream(ctx)
+	if err != nil {
+		log.Errorf("Failed to get log stream for pod %s: %v", pod, err)
+		sink.fail(map[string]string{
+			"podName": pod,
+			"error":   err.Error(),
+		})
+		return
+	}
+	defer stream.Close()
+	defer diagnostics.TrackWatch("pod_logs")()
+
+	log.Infof("Log stream started for pod: %s", pod)
+
+	// Use bufio.Scanner to read line by line
+	scanner := bufio.NewScanner(stream)
+	keep := func(string) bool { return true }
+	if filter != nil {
+		scanner.Buffer(make([]byte, 64*1024), maxLogLine)
+		keep = filter.lines()
+	}
+	for scanner.Scan() {
+		if ctx.Err() != nil {
+			return
+		}
+		logLine := scanner.Text()
+		if !keep(logLine) {
+			continue
+		}
+		// Send the line to the client with the prefix; the sink serializes writes
+		if structured {
+			record := parseLogRecord(logLine, logOptions.Timestamps)
+			record.Pod, record.Container = pod, logOptions.Container
+			err = sink.record(record)
+		} else {
+			err = sink.write([]byte(fmt.Sprintf("[%s] %s\n", prefix, logLine)))
+		}
+		if err != nil {
+			log.Errorf("Failed to write log stream: %v", err)
+			return
+		}
+	}
+	if err := scanner.Err(); err != nil && ctx.Err() == nil {
+		log.Errorf("Error reading log stream for pod %s: %v", pod, err)
+	} else {
+		log.Infof("Log stream ended for pod %s (EOF)", pod)
+	}
+}
+
+// podFollower streams the logs of the pods matching a label selector, like stern: it
+// attaches to the containers of the pods as they start, and to containers again when they
+// restart
+type podFollower struct {
+	client     kubernetes.Interface
+	sink       logSink
+	namespace  string
+	selector   string
+	container  string // every container when empty
+	tailLines  *int64 // for the containers running when the stream starts
+	timestamps bool
+	structured bool
+	filter     *logFilter
+
+	mu      sync.Mutex
+	streams map[string]context.CancelFunc // by pod/container
+	ended   map[string]time.Time          // when the last stream of a container ended
+	pods    map[string]bool               // pods reported to the client
+	full    bool                          // maxFollowedLogs was reached
+}
+
+// run follows the selected pods until ctx is canceled. The watch is started again from a
+// new list when it ends, as watches time out.
+func (f *podFollower) run(ctx context.Context) {
+	initial := true
+	for ctx.Err() == nil {
+		if err := f.watch(ctx, initial); err != nil && ctx.Err() == nil {
+			log.Warnf("Watch of pods %s failed: %v", f.selector, err)
+			select {
+			case <-ctx.Done():
+			case <-time.After(followRetryDelay):
+			}
+		}
+		initial = false
+	}
+}
+
+func (f *podFollower) watch(ctx context.Context, initial bool) error {
+	pods, err := f.client.CoreV1().Pods(f.namespace).List(ctx, metav1.ListOptions{LabelSelector: f.selector})
+	if err != nil {
+		return err
+	}
+	listed := make(map[string]bool, len(pods.Items))
+	for i := range pods.Items {
+		listed[pods.Items[i].Name] = true
+		f.sync(ctx, &pods.Items[i], initial)
+	}
+	// Pods deleted while the watch was down
+	for _, pod := range f.reported() {
+		if !listed[pod] {
+			f.remove(pod)
+		}
+	}
+
+	watcher, err := f.client.CoreV1().Pods(f.namespace).Watch(ctx, metav1.ListOptions{
+		LabelSelector:   f.selector,
+		ResourceVersion: pods.ResourceVersion,
+	})
+	if err != nil {
+		return err
+	}
+	defer watcher.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				return nil
+			}
+			pod, isPod := event.Object.(*corev1.Pod)
+			if !isPod {
+				if event.Type == watch.Error {
+					return fmt.Errorf("%v", event.Object)
+				}
+				continue
+			}
+			switch event.Type {
+			case watch.Added, watch.Modified:
+				f.sync(ctx, pod, false)
+			case watch.Deleted:
+				f.remove(pod.Name)
+			}
+		}
+	}
+}
+
+// sync attaches to the containers of a pod that run and are not streamed yet. Containers
+// that already ended are attached to once, for their log.
+func (f *podFollower) sync(ctx context.Context, pod *corev1.Pod, initial bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	statuses := make(map[string]corev1.ContainerStatus, len(pod.Status.ContainerStatuses))
+	for _, status := range pod.Status.ContainerStatuses {
+		statuses[status.Name] = status
+	}
+	for _, container := range pod.Spec.Containers {
+		if f.container != "" && container.Name != f.container {
+			continue
+		}
+		key := pod.Name + "/" + container.Name
+		status := statuses[container.Name]
+		ended, streamed := f.ended[key]
+		if _, streaming := f.streams[key]; streaming {
+			continue
+		}
+		if status.State.Running == nil && (status.State.Terminated == nil || streamed) {
+			continue
+		}
+		if len(f.streams) >= maxFollowedLogs {
+			if !f.full {
+				f.full = true
+				f.sink.fail(map[string]string{"error": fmt.Sprintf("more than %d containers match; the others are not streamed", maxFollowedLogs)})
+			}
+			return
+		}
+
+		// Running containers show their recent lines, new and restarted ones what they
+		// wrote since they started or since the last stream ended
+		logOptions := &corev1.PodLogOptions{Container: container.Name, Follow: true, Timestamps: f.timestamps}
+		switch {
+		case streamed:
+			logOptions.SinceTime = &metav1.Time{Time: ended}
+		case initial:
+			logOptions.TailLines = f.tailLines
+		}
+		prefix := pod.Name
+		if f.container == "" && len(pod.Spec.Containers) > 1 {
+			prefix = key
+		}
+
+		streamCtx, cancel := context.WithCancel(ctx)
+		f.streams[key] = cancel
+		if !f.pods[pod.Name] {
+			f.pods[pod.Name] = true
+			f.sink.notice("pod_added", map[string]interface{}{"podName": pod.Name})
+		}
+		go func() {
+			defer cancel()
+			podLogStream(streamCtx, f.client, f.sink, f.namespace, pod.Name, prefix, logOptions, f.filter, f.structured)
+
+			f.mu.Lock()
+			defer f.mu.Unlock()
+			delete(f.streams, key)
+			if f.pods[pod.Name] {
+				f.ended[key] = time.Now()
+			}
+			f.full = false
+		}()
+	}
+}
+
+// remove stops streaming the containers of a deleted pod
+func (f *podFollower) remove(pod string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for key, cancel := range f.streams {
+		if strings.HasPrefix(key, pod+"/") {
+			cancel()
+		}
+	}
+	for key := range f.ended {
+		if strings.HasPrefix(key, pod+"/") {
+			delete(f.ended, key)
+		}
+	}
+	if f.pods[pod] {
+		delete(f.pods, pod)
+		f.sink.notice("pod_removed", map[string]interface{}{"podName": pod})
+	}
+}
+
+// reported returns the pods reported to the client
+func (f *podFollower) reported() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	pods := make([]string, 0, len(f.pods))
+	for pod := range f.pods {
+		pods = append(pods, pod)
+	}
+	return pods
+}
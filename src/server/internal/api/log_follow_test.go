@@ -0,0 +1,199 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// recordingSink records what a log stream sends
+type recordingSink struct {
+	mu      sync.Mutex
+	lines   []string
+	notices []map[string]interface{}
+	closed  chan struct{}
+}
+
+func (s *recordingSink) write(data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lines = append(s.lines, strings.TrimSuffix(string(data), "\n"))
+	return nil
+}
+
+func (s *recordingSink) record(r logRecord) error {
+	return s.write([]byte(fmt.Sprintf("[%s] %s\n", r.Pod, r.Message)))
+}
+
+func (s *recordingSink) fail(payload interface{}) {}
+
+func (s *recordingSink) notice(event string, payload map[string]interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	notice := map[string]interface{}{"event": event}
+	for key, value := range payload {
+		notice[key] = value
+	}
+	s.notices = append(s.notices, notice)
+}
+
+func (s *recordingSink) done() <-chan struct{} { return s.closed }
+func (s *recordingSink) close()                {}
+
+// waitFor polls the recorded messages until check accepts them
+func (s *recordingSink) waitFor(t *testing.T, what string, check func(lines []string, notices []map[string]interface{}) bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		s.mu.Lock()
+		ok := check(s.lines, s.notices)
+		s.mu.Unlock()
+		if ok {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s; lines %v, notices %v", what, s.lines, s.notices)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func runningPod(name string, containers ...string) *corev1.Pod {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "shop", Labels: map[string]string{"app": "web"}}}
+	for _, container := range containers {
+		pod.Spec.Containers = append(pod.Spec.Containers, corev1.Container{Name: container})
+		pod.Status.ContainerStatuses = append(pod.Status.ContainerStatuses, corev1.ContainerStatus{
+			Name:  container,
+			State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+		})
+	}
+	return pod
+}
+
+func TestPodFollower(t *testing.T) {
+	client := fake.NewSimpleClientset(runningPod("web-1", "app"), runningPod("web-2", "app", "proxy"))
+	sink := &recordingSink{closed: make(chan struct{})}
+	follower := &podFollower{
+		client:    client,
+		sink:      sink,
+		namespace: "shop",
+		selector:  "app=web",
+		streams:   map[string]context.CancelFunc{},
+		ended:     map[string]time.Time{},
+		pods:      map[string]bool{},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go follower.run(ctx)
+
+	has := func(lines []string, want string) bool {
+		for _, line := range lines {
+			if line == want {
+				return true
+			}
+		}
+		return false
+	}
+	sink.waitFor(t, "the running pods", func(lines []string, _ []map[string]interface{}) bool {
+		return has(lines, "[web-1] fake logs") && has(lines, "[web-2/app] fake logs") && has(lines, "[web-2/proxy] fake logs")
+	})
+
+	// Pods are attached to as they start
+	pending := runningPod("web-3", "app")
+	pending.Status.ContainerStatuses[0].State = corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{}}
+	if _, err := client.CoreV1().Pods("shop").Create(ctx, pending, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	sink.mu.Lock()
+	attached := has(sink.lines, "[web-3] fake logs")
+	sink.mu.Unlock()
+	if attached {
+		t.Fatal("attached to a container that is not running")
+	}
+	if _, err := client.CoreV1().Pods("shop").UpdateStatus(ctx, runningPod("web-3", "app"), metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	sink.waitFor(t, "the new pod", func(lines []string, _ []map[string]interface{}) bool {
+		return has(lines, "[web-3] fake logs")
+	})
+
+	if err := client.CoreV1().Pods("shop").Delete(ctx, "web-1", metav1.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	sink.waitFor(t, "the deletion", func(_ []string, notices []map[string]interface{}) bool {
+		for _, notice := range notices {
+			if notice["event"] == "pod_removed" && notice["podName"] == "web-1" {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+func TestPodSelector(t *testing.T) {
+	client := fake.NewSimpleClientset(&appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop"},
+		Spec:       appsv1.DeploymentSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}},
+	})
+	ctx := context.Background()
+
+	if selector, err := podSelector(ctx, client, "shop", "", "deployment/web"); err != nil || selector != "app=web" {
+		t.Errorf("podSelector(deployment/web) = %q, %v", selector, err)
+	}
+	if selector, err := podSelector(ctx, client, "shop", "app in (web, api)", ""); err != nil || selector != "app in (api,web)" {
+		t.Errorf("podSelector(app in (web, api)) = %q, %v", selector, err)
+	}
+	for _, tc := range [][2]string{{"", "deployment/missing"}, {"", "cronjob/web"}, {"", "web"}, {"app in (", ""}, {"app=web", "deployment/web"}} {
+		if _, err := podSelector(ctx, client, "shop", tc[0], tc[1]); err == nil {
+			t.Errorf("podSelector(%q, %q) succeeded, want an error", tc[0], tc[1])
+		}
+	}
+}
+
+func TestLogStreamEnd(t *testing.T) {
+	oomKilled := &corev1.ContainerStateTerminated{ExitCode: 137, Reason: "OOMKilled"}
+	for _, tc := range []struct {
+		status corev1.ContainerStatus
+		want   map[string]interface{}
+	}{
+		{
+			corev1.ContainerStatus{Name: "app", ContainerID: "1", State: corev1.ContainerState{Terminated: oomKilled}},
+			map[string]interface{}{"reason": "terminated", "exitCode": int32(137), "terminationReason": "OOMKilled"},
+		},
+		{
+			corev1.ContainerStatus{Name: "app", ContainerID: "2", RestartCount: 3,
+				State:                corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+				LastTerminationState: corev1.ContainerState{Terminated: oomKilled}},
+			map[string]interface{}{"reason": "terminated", "exitCode": int32(137), "waiting": "CrashLoopBackOff", "restartCount": int32(3)},
+		},
+		{
+			corev1.ContainerStatus{Name: "app", ContainerID: "2", RestartCount: 1,
+				State:                corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+				LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 1}}},
+			map[string]interface{}{"reason": "restarted", "exitCode": int32(1), "restartCount": int32(1)},
+		},
+	} {
+		pod := runningPod("web-1", "app")
+		pod.Status.ContainerStatuses[0] = tc.status
+		end := logStreamEnd(context.Background(), fake.NewSimpleClientset(pod), "shop", "web-1", "app", "1")
+		for key, value := range tc.want {
+			if end[key] != value {
+				t.Errorf("%+v: end[%s] = %v, want %v", tc.status.State, key, end[key], value)
+			}
+		}
+	}
+
+	end := logStreamEnd(context.Background(), fake.NewSimpleClientset(), "shop", "web-1", "app", "1")
+	if end["reason"] != "pod_deleted" {
+		t.Errorf("reason = %v, want pod_deleted", end["reason"])
+	}
+}
@@ -0,0 +1,185 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// levelNames are the names structured log records give the levels of logLevels
+var levelNames = []string{"trace", "debug", "info", "warn", "error", "fatal"}
+
+// Keys of the timestamp, level and message of JSON log lines, as written by the common
+// loggers (zap, logrus, slog, zerolog, pino, bunyan, ECS, GCP structured logging)
+var (
+	timestampKeys = []string{"time", "timestamp", "ts", "@timestamp", "t", "datetime"}
+	levelKeys     = []string{"level", "lvl", "severity", "log.level", "loglevel"}
+	messageKeys   = []string{"msg", "message", "@message", "log"}
+)
+
+// logRecord is a log line parsed by the structured mode of the log endpoints
+type logRecord struct {
+	Pod       string                 `json:"pod,omitempty"`
+	Container string                 `json:"container,omitempty"`
+	Timestamp string                 `json:"timestamp,omitempty"` // RFC 3339 when it could be read, as logged otherwise
+	Level     string                 `json:"level,omitempty"`     // trace, debug, info, warn, error or fatal
+	Message   string                 `json:"message"`
+	Fields    map[string]interface{} `json:"fields,omitempty"` // the other fields of a JSON line
+	JSON      bool                   `json:"json"`             // false for lines that are not JSON, whose message is the line
+}
+
+// parseLogRecord parses a log line. With timestamps, the line starts with the timestamp the
+// kubelet added, used when the line has none of its own.
+func parseLogRecord(line string, timestamps bool) logRecord {
+	var record logRecord
+	if timestamps {
+		if ts, rest, found := strings.Cut(line, " "); found {
+			if _, err := time.Parse(time.RFC3339Nano, ts); err == nil {
+				record.Timestamp, line = ts, rest
+			}
+		}
+	}
+
+	fields := parseJSONLine(line)
+	if fields == nil {
+		record.Message = line
+		if level := lineLevel(line); level >= 0 {
+			record.Level = levelNames[level]
+		}
+		return record
+	}
+
+	record.JSON = true
+	if value, ok := takeField(fields, timestampKeys); ok {
+		record.Timestamp = formatLogTimestamp(value)
+	}
+	if value, ok := takeField(fields, levelKeys); ok {
+		record.Level = normalizeLevel(value)
+	}
+	if value, ok := takeField(fields, messageKeys); ok {
+		record.Message = fmt.Sprint(value)
+	}
+	if len(fields) > 0 {
+		record.Fields = fields
+	}
+	return record
+}
+
+// parseJSONLine returns the fields of a JSON object log line, nil for other lines. Numbers
+// are kept as json.Number so large IDs are not rounded.
+func parseJSONLine(line string) map[string]interface{} {
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, "{") || !strings.HasSuffix(line, "}") {
+		return nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader([]byte(line)))
+	decoder.UseNumber()
+	var fields map[string]interface{}
+	if err := decoder.Decode(&fields); err != nil {
+		return nil
+	}
+	return fields
+}
+
+// takeField removes and returns the first of keys present in fields
+func takeField(fields map[string]interface{}, keys []string) (interface{}, bool) {
+	for _, key := range keys {
+		if value, ok := fields[key]; ok && value != nil {
+			delete(fields, key)
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+// lookupField returns the value of a field of a JSON log line: a top-level key, or a path
+// of nested keys separated by dots
+func lookupField(fields map[string]interface{}, path string) (interface{}, bool) {
+	if value, ok := fields[path]; ok {
+		return value, true
+	}
+	head, rest, found := strings.Cut(path, ".")
+	if !found {
+		return nil, false
+	}
+	nested, ok := fields[head].(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	return lookupField(nested, rest)
+}
+
+// normalizeLevel maps a level name, or a pino/bunyan level number, to one of levelNames
+func normalizeLevel(value interface{}) string {
+	if number, ok := value.(json.Number); ok {
+		n, err := number.Int64()
+		if err != nil {
+			return number.String()
+		}
+		// pino and bunyan: 10 trace, 20 debug, 30 info, 40 warn, 50 error, 60 fatal
+		if n >= 10 && n <= 60 {
+			return levelNames[n/10-1]
+		}
+		return number.String()
+	}
+	name := strings.ToLower(fmt.Sprint(value))
+	if level, ok := logLevels[name]; ok {
+		return levelNames[level]
+	}
+	return name
+}
+
+// formatLogTimestamp returns a logged timestamp as RFC 3339: strings in a format it can
+// read, and unix times in seconds, milliseconds or nanoseconds. Others are kept as logged.
+func formatLogTimestamp(value interface{}) string {
+	switch v := value.(type) {
+	case json.Number:
+		f, err := strconv.ParseFloat(v.String(), 64)
+		if err != nil {
+			return v.String()
+		}
+		var t time.Time
+		switch {
+		case f < 1e11:
+			t = time.Unix(0, int64(f*1e9))
+		case f < 1e14:
+			t = time.UnixMilli(int64(f))
+		default:
+			t = time.Unix(0, int64(f))
+		}
+		return t.UTC().Format(time.RFC3339Nano)
+	case string:
+		for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999Z0700", "2006-01-02 15:04:05.999999999Z07:00", "2006-01-02 15:04:05.999999999"} {
+			if t, err := time.Parse(layout, v); err == nil {
+				return t.Format(time.RFC3339Nano)
+			}
+		}
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// readLogRecords parses the lines of a log the filter (if any) keeps
+func readLogRecords(logs io.Reader, filter *logFilter, timestamps bool, pod, container string) ([]logRecord, error) {
+	keep := func(string) bool { return true }
+	if filter != nil {
+		keep = filter.lines()
+	}
+	records := []logRecord{}
+	scanner := bufio.NewScanner(logs)
+	scanner.Buffer(make([]byte, 64*1024), maxLogLine)
+	for scanner.Scan() {
+		if line := scanner.Text(); line != "" && keep(line) {
+			record := parseLogRecord(line, timestamps)
+			record.Pod, record.Container = pod, container
+			records = append(records, record)
+		}
+	}
+	return records, scanner.Err()
+}
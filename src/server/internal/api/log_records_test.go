@@ -0,0 +1,77 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestParseLogRecord(t *testing.T) {
+	for _, tc := range []struct {
+		line       string
+		timestamps bool
+		want       logRecord
+	}{
+		{
+			line: `{"level":"WARN","ts":1714564800.5,"msg":"slow request","path":"/api","status":200}`,
+			want: logRecord{Timestamp: "2024-05-01T12:00:00.5Z", Level: "warn", Message: "slow request", JSON: true,
+				Fields: map[string]interface{}{"path": "/api", "status": json.Number("200")}},
+		},
+		{
+			// pino
+			line: `{"level":50,"time":1714564800000,"msg":"boom","err":{"type":"Error"}}`,
+			want: logRecord{Timestamp: "2024-05-01T12:00:00Z", Level: "error", Message: "boom", JSON: true,
+				Fields: map[string]interface{}{"err": map[string]interface{}{"type": "Error"}}},
+		},
+		{
+			// kubelet timestamp, used when the line has none
+			line:       `2024-05-01T12:00:00.123Z {"severity":"INFO","message":"ready"}`,
+			timestamps: true,
+			want:       logRecord{Timestamp: "2024-05-01T12:00:00.123Z", Level: "info", Message: "ready", JSON: true},
+		},
+		{
+			line: `2024-05-01 12:00:00 [ERROR] connection refused`,
+			want: logRecord{Level: "error", Message: `2024-05-01 12:00:00 [ERROR] connection refused`},
+		},
+		{
+			line: `{not json}`,
+			want: logRecord{Message: `{not json}`},
+		},
+	} {
+		if got := parseLogRecord(tc.line, tc.timestamps); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("parseLogRecord(%s) = %+v, want %+v", tc.line, got, tc.want)
+		}
+	}
+}
+
+func TestLogFilterFields(t *testing.T) {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/logs?field=http.status=500&field=user=alice&level=warn", nil)
+	filter, err := parseLogFilter(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logs := strings.Join([]string{
+		`{"level":50,"http":{"status":500},"user":"alice","msg":"failed"}`,
+		`{"level":50,"http":{"status":500},"user":"bob","msg":"failed"}`,
+		`{"level":30,"http":{"status":500},"user":"alice","msg":"retried"}`,
+		`ERROR http.status=500 user=alice`,
+	}, "\n")
+	got, err := filter.filterLogs(strings.NewReader(logs))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"level":50,"http":{"status":500},"user":"alice","msg":"failed"}` + "\n"; got != want {
+		t.Errorf("filtered logs = %q, want %q", got, want)
+	}
+
+	c, _ = gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/logs?field=status", nil)
+	if _, err := parseLogFilter(c); err == nil {
+		t.Error("parseLogFilter(field=status) succeeded, want an error")
+	}
+}
@@ -0,0 +1,247 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/sonnguyen/kubelens/internal/sse"
+)
+
+// logSink is the connection a log stream is written to: a WebSocket, or server-sent events
+// for clients behind proxies that break WebSockets. It is safe for concurrent use.
+type logSink interface {
+	// write sends a chunk of logs
+	write(data []byte) error
+	// record sends a parsed log line (structured mode) as JSON
+	record(r logRecord) error
+	// fail sends an error as JSON
+	fail(payload interface{})
+	// notice sends an event about the stream, such as a change of the streamed pods or its
+	// end, as JSON with the event name in its "event" field
+	notice(event string, payload map[string]interface{})
+	// done is closed when the client goes away
+	done() <-chan struct{}
+	close()
+}
+
+// openLogSink answers a log stream request with server-sent events when the client asks for
+// them (sse.Requested), with a WebSocket otherwise. It returns nil when the WebSocket upgrade
+// failed, which has answered the request.
+func openLogSink(c *gin.Context) logSink {
+	if sse.Requested(c.Request) {
+		stream, err := sse.Start(c.Writer, 3*time.Second)
+		if err != nil {
+			return nil
+		}
+		ctx, cancel := context.WithCancel(c.Request.Context())
+		go stream.KeepAlive(ctx)
+		return &sseLogSink{stream: stream, ctx: ctx, cancel: cancel}
+	}
+
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		log.Errorf("Failed to upgrade WebSocket: %v", err)
+		return nil
+	}
+	sink := &wsLogSink{conn: conn, closed: make(chan struct{})}
+	// Reading notices the client closing the connection
+	go func() {
+		defer close(sink.closed)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				log.Infof("WebSocket closed: %v", err)
+				return
+			}
+		}
+	}()
+	return sink
+}
+
+// wsLogSink writes logs as WebSocket text messages
+type wsLogSink struct {
+	mu     sync.Mutex
+	conn   *websocket.Conn
+	closed chan struct{}
+}
+
+func (s *wsLogSink) write(data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.conn.WriteMessage(websocket.TextMessage, data)
+}
+
+func (s *wsLogSink) record(r logRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.conn.WriteJSON(r)
+}
+
+func (s *wsLogSink) fail(payload interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.conn.WriteJSON(payload)
+}
+
+func (s *wsLogSink) notice(event string, payload map[string]interface{}) {
+	message := map[string]interface{}{"event": event}
+	for key, value := range payload {
+		message[key] = value
+	}
+	s.fail(message)
+}
+
+func (s *wsLogSink) done() <-chan struct{} {
+	return s.closed
+}
+
+func (s *wsLogSink) close() {
+	s.conn.Close()
+}
+
+// sseLogSink writes logs as "log" events, records as "record" events, errors as "error"
+// events and notices as events of their name
+type sseLogSink struct {
+	stream *sse.Writer
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func (s *sseLogSink) write(data []byte) error {
+	return s.stream.Event("", "log", data)
+}
+
+func (s *sseLogSink) record(r logRecord) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	return s.stream.Event("", "record", data)
+}
+
+func (s *sseLogSink) fail(payload interface{}) {
+	data, _ := json.Marshal(payload)
+	s.stream.Event("", "error", data)
+}
+
+func (s *sseLogSink) notice(event string, payload map[string]interface{}) {
+	data, _ := json.Marshal(payload)
+	s.stream.Event("", event, data)
+}
+
+func (s *sseLogSink) done() <-chan struct{} {
+	return s.ctx.Done()
+}
+
+func (s *sseLogSink) close() {
+	s.cancel()
+}
+
+// defaultContainerAnnotation names the container kubectl picks when none is given
+const defaultContainerAnnotation = "kubectl.kubernetes.io/default-container"
+
+// logContainer resolves the container whose log a stream reads: an init container, the
+// container given, or the default container of the pod
+func logContainer(pod *corev1.Pod, container, initContainer string) (string, error) {
+	if initContainer != "" {
+		if container != "" {
+			return "", fmt.Errorf("give a container or an initContainer, not both")
+		}
+		for _, c := range pod.Spec.InitContainers {
+			if c.Name == initContainer {
+				return initContainer, nil
+			}
+		}
+		return "", fmt.Errorf("pod %s has no init container %q", pod.Name, initContainer)
+	}
+	if container == "" {
+		container = pod.Annotations[defaultContainerAnnotation]
+	}
+	for _, c := range pod.Spec.Containers {
+		if container == "" || c.Name == container {
+			return c.Name, nil
+		}
+	}
+	for _, c := range pod.Spec.InitContainers {
+		if c.Name == container {
+			return container, nil
+		}
+	}
+	return "", fmt.Errorf("pod %s has no container %q", pod.Name, container)
+}
+
+// containerStatus returns the status of a container or init container of a pod
+func containerStatus(pod *corev1.Pod, container string) (corev1.ContainerStatus, bool) {
+	for _, statuses := range [][]corev1.ContainerStatus{pod.Status.ContainerStatuses, pod.Status.InitContainerStatuses} {
+		for _, status := range statuses {
+			if status.Name == container {
+				return status, true
+			}
+		}
+	}
+	return corev1.ContainerStatus{}, false
+}
+
+// logStreamEnd describes why the log of a followed container ended, for the "end" notice:
+// the container terminated (with its exit code), restarted, or the pod was deleted. The
+// status of the container is read again for a few seconds, until the kubelet reports the
+// instance that was streamed (containerID) as no longer running.
+func logStreamEnd(ctx context.Context, client kubernetes.Interface, namespace, podName, container, containerID string) map[string]interface{} {
+	end := map[string]interface{}{"podName": podName, "container": container, "reason": "closed"}
+	for attempt := 0; attempt < 5; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return end
+			case <-time.After(500 * time.Millisecond):
+			}
+		}
+		pod, err := client.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			end["reason"] = "pod_deleted"
+			return end
+		}
+		if err != nil {
+			continue
+		}
+		status, ok := containerStatus(pod, container)
+		if !ok {
+			continue
+		}
+		end["restartCount"] = status.RestartCount
+
+		terminated := status.State.Terminated
+		if terminated == nil && status.ContainerID != containerID {
+			// The streamed instance exited and the kubelet already started or scheduled
+			// the next one
+			terminated = status.LastTerminationState.Terminated
+			if status.State.Running != nil {
+				end["reason"] = "restarted"
+			}
+			if status.State.Waiting != nil {
+				end["waiting"] = status.State.Waiting.Reason
+			}
+		}
+		if terminated != nil {
+			if end["reason"] == "closed" {
+				end["reason"] = "terminated"
+			}
+			end["exitCode"] = terminated.ExitCode
+			if terminated.Reason != "" {
+				end["terminationReason"] = terminated.Reason
+			}
+			return end
+		}
+	}
+	return end
+}
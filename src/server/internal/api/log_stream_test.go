@@ -0,0 +1,61 @@
+package api_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/sonnguyen/kubelens/internal/apitest"
+)
+
+func TestPodLogsStreamContainers(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "shop"},
+		Spec: corev1.PodSpec{
+			InitContainers: []corev1.Container{{Name: "migrate"}},
+			Containers:     []corev1.Container{{Name: "web"}},
+		},
+	}
+	s := apitest.New(t, pod)
+	base := "/api/v1/clusters/" + apitest.ClusterName + "/namespaces/shop/pods/"
+
+	// The log of the previous instance is complete: it ends with an end event
+	w := s.Get(base + "web-1/logs/stream?transport=sse&previous=true")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", w.Code, w.Body.String())
+	}
+	body := w.Body.String()
+	if !strings.Contains(body, "event: log\ndata: fake logs\n") {
+		t.Errorf("stream has no log event: %q", body)
+	}
+	if !strings.Contains(body, "event: end\n") || !strings.Contains(body, `"reason":"complete"`) || !strings.Contains(body, `"container":"web"`) || !strings.Contains(body, `"previous":true`) {
+		t.Errorf("stream has no end event for the previous instance: %q", body)
+	}
+
+	w = s.Get(base + "web-1/logs/stream?transport=sse&follow=false&initContainer=migrate")
+	if body := w.Body.String(); !strings.Contains(body, `"container":"migrate"`) {
+		t.Errorf("init container stream = %q", body)
+	}
+
+	for _, query := range []string{"initContainer=web", "container=proxy", "container=web&initContainer=migrate"} {
+		if w := s.Get(base + "web-1/logs/stream?transport=sse&" + query); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400", query, w.Code)
+		}
+	}
+	if w := s.Get(base + "web-2/logs/stream?transport=sse"); w.Code != http.StatusNotFound {
+		t.Errorf("missing pod: status %d, want 404", w.Code)
+	}
+
+	// Structured mode: records instead of text
+	w = s.Get(base + "web-1/logs/stream?transport=sse&follow=false&structured=true")
+	if body := w.Body.String(); !strings.Contains(body, "event: record\ndata: "+`{"pod":"web-1","container":"web","message":"fake logs","json":false}`) {
+		t.Errorf("structured stream = %q", body)
+	}
+	w = s.Get(base + "web-1/logs?structured=true")
+	if body := w.Body.String(); w.Code != http.StatusOK || body != `{"records":[{"pod":"web-1","message":"fake logs","json":false}]}` {
+		t.Errorf("structured logs: status %d, %s", w.Code, body)
+	}
+}
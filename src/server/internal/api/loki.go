@@ -0,0 +1,280 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+const (
+	// defaultLokiLimit and maxLokiLimit bound the number of lines of a Loki query
+	defaultLokiLimit = 1000
+	maxLokiLimit     = 5000
+	// maxLokiRange bounds the range of a Loki query
+	maxLokiRange = 30 * 24 * time.Hour
+)
+
+// lokiLabelCandidates are the label names the common log shippers (Promtail, Grafana
+// Alloy/Agent, the OpenTelemetry collector, Fluent Bit) give the pod metadata, in order
+// of preference
+var lokiLabelCandidates = map[string][]string{
+	"namespace": {"namespace", "k8s_namespace_name", "kubernetes_namespace_name"},
+	"pod":       {"pod", "k8s_pod_name", "pod_name", "kubernetes_pod_name"},
+	"container": {"container", "k8s_container_name", "container_name", "kubernetes_container_name"},
+}
+
+// LokiLogEntry is one log line from Loki
+type LokiLogEntry struct {
+	Timestamp time.Time `json:"timestamp"`
+	Container string    `json:"container,omitempty"`
+	Line      string    `json:"line"`
+}
+
+// getPodLogsFromLoki handles GET /clusters/:name/namespaces/:namespace/pods/:pod/logs with
+// source=loki: historical logs of the pod from the Loki datasource of the cluster, which
+// outlive container restarts and deleted pods. The stream selector is built from the pod
+// labels found in Loki.
+// Query: container, start and end (RFC 3339 or unix seconds; default: the last hour),
+// limit (lines, default 1000), filter (line contains). The match and invert of the log
+// filter run in Loki; its level and fields apply to the lines returned. structured=true
+// adds the entries parsed as records.
+func (h *Handler) getPodLogsFromLoki(c *gin.Context, filter *logFilter) {
+	clusterName := c.Param("name")
+	namespace := c.Param("namespace")
+	podName := c.Param("pod")
+	container := c.Query("container")
+
+	for _, value := range []string{namespace, podName, container} {
+		if value != "" && !prometheusParamPattern.MatchString(value) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid name %q", value)})
+			return
+		}
+	}
+	start, end, err := lokiRange(c.Query("start"), c.Query("end"), time.Now())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	limit := defaultLokiLimit
+	if value := c.Query("limit"); value != "" {
+		if limit, err = strconv.Atoi(value); err != nil || limit < 1 || limit > maxLokiLimit {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("limit must be between 1 and %d", maxLokiLimit)})
+			return
+		}
+	}
+
+	ds, err := h.db.GetClusterDatasource(clusterName, "loki")
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	labels, err := h.lokiLabelNames(c, ds, start, end)
+	if err != nil {
+		log.Warnf("Failed to list Loki labels for cluster %s: %v", clusterName, err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+	selector, containerLabel, err := lokiSelector(labels, namespace, podName, container)
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+	query := selector
+	if filter := c.Query("filter"); filter != "" {
+		query += " |= " + strconv.Quote(filter)
+	}
+	if filter != nil && filter.match != nil {
+		operator := " |~ "
+		if filter.invert {
+			operator = " !~ "
+		}
+		query += operator + strconv.Quote(filter.match.String())
+	}
+
+	values := url.Values{}
+	values.Set("query", query)
+	values.Set("start", strconv.FormatInt(start.UnixNano(), 10))
+	values.Set("end", strconv.FormatInt(end.UnixNano(), 10))
+	values.Set("limit", strconv.Itoa(limit))
+	values.Set("direction", "backward")
+	body, err := h.datasourceGet(c.Request.Context(), ds, "/loki/api/v1/query_range", values)
+	if err != nil {
+		var dsErr *datasourceError
+		if errors.As(err, &dsErr) && dsErr.status < 500 && dsErr.body != "" {
+			err = fmt.Errorf("loki: %s", dsErr.body)
+		}
+		log.Warnf("Loki query for pod %s/%s in cluster %s failed: %v", namespace, podName, clusterName, err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+
+	entries, err := parseLokiStreams(body, containerLabel)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+
+	truncated := len(entries) >= limit
+	if filter != nil && (filter.minLevel >= 0 || len(filter.fields) > 0) {
+		keep, kept := filter.lines(), entries[:0]
+		for _, entry := range entries {
+			if keep(entry.Line) {
+				kept = append(kept, entry)
+			}
+		}
+		entries = kept
+	}
+	lines := make([]string, len(entries))
+	for i, entry := range entries {
+		lines[i] = entry.Line
+	}
+	response := gin.H{
+		"source":    "loki",
+		"query":     query,
+		"logs":      strings.Join(lines, "\n"),
+		"entries":   entries,
+		"truncated": truncated,
+	}
+	if c.Query("structured") == "true" {
+		records := make([]logRecord, len(entries))
+		for i, entry := range entries {
+			records[i] = parseLogRecord(entry.Line, false)
+			records[i].Pod, records[i].Container = podName, entry.Container
+			if records[i].Timestamp == "" {
+				records[i].Timestamp = entry.Timestamp.UTC().Format(time.RFC3339Nano)
+			}
+		}
+		response["records"] = records
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// lokiLabelNames returns the label names Loki knows for a time range
+func (h *Handler) lokiLabelNames(c *gin.Context, ds *db.ClusterDatasource, start, end time.Time) (map[string]bool, error) {
+	values := url.Values{}
+	values.Set("start", strconv.FormatInt(start.UnixNano(), 10))
+	values.Set("end", strconv.FormatInt(end.UnixNano(), 10))
+	body, err := h.datasourceGet(c.Request.Context(), ds, "/loki/api/v1/labels", values)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Status string   `json:"status"`
+		Data   []string `json:"data"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil || resp.Status != "success" {
+		return nil, fmt.Errorf("invalid loki labels response")
+	}
+	names := make(map[string]bool, len(resp.Data))
+	for _, name := range resp.Data {
+		names[name] = true
+	}
+	return names, nil
+}
+
+// lokiSelector builds the LogQL stream selector of a pod (and container) from the label
+// names present in Loki. It also returns the label holding the container name.
+func lokiSelector(labels map[string]bool, namespace, pod, container string) (selector, containerLabel string, err error) {
+	pick := func(field string) string {
+		for _, name := range lokiLabelCandidates[field] {
+			if labels[name] {
+				return name
+			}
+		}
+		return ""
+	}
+
+	namespaceLabel, podLabel := pick("namespace"), pick("pod")
+	if namespaceLabel == "" || podLabel == "" {
+		return "", "", fmt.Errorf("loki has no namespace and pod labels; expected one of %s and %s",
+			strings.Join(lokiLabelCandidates["namespace"], ", "), strings.Join(lokiLabelCandidates["pod"], ", "))
+	}
+	matchers := []string{
+		fmt.Sprintf("%s=%q", namespaceLabel, namespace),
+		fmt.Sprintf("%s=%q", podLabel, pod),
+	}
+	containerLabel = pick("container")
+	if container != "" {
+		if containerLabel == "" {
+			return "", "", fmt.Errorf("loki has no container label; expected one of %s", strings.Join(lokiLabelCandidates["container"], ", "))
+		}
+		matchers = append(matchers, fmt.Sprintf("%s=%q", containerLabel, container))
+	}
+	return "{" + strings.Join(matchers, ",") + "}", containerLabel, nil
+}
+
+// lokiRange parses the range of a Loki query
+func lokiRange(startParam, endParam string, now time.Time) (start, end time.Time, err error) {
+	end = now
+	if endParam != "" {
+		if end, err = parsePrometheusTime(endParam); err != nil {
+			return start, end, fmt.Errorf("invalid end: %v", err)
+		}
+	}
+	start = end.Add(-time.Hour)
+	if startParam != "" {
+		if start, err = parsePrometheusTime(startParam); err != nil {
+			return start, end, fmt.Errorf("invalid start: %v", err)
+		}
+	}
+	if !start.Before(end) {
+		return start, end, fmt.Errorf("start must be before end")
+	}
+	if end.Sub(start) > maxLokiRange {
+		return start, end, fmt.Errorf("range must be at most %d days", int(maxLokiRange.Hours()/24))
+	}
+	return start, end, nil
+}
+
+// parseLokiStreams merges the streams of a Loki query response into lines in
+// chronological order
+func parseLokiStreams(body []byte, containerLabel string) ([]LokiLogEntry, error) {
+	var resp struct {
+		Status string `json:"status"`
+		Data   struct {
+			ResultType string `json:"resultType"`
+			Result     []struct {
+				Stream map[string]string `json:"stream"`
+				Values [][2]string       `json:"values"`
+			} `json:"result"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("invalid loki response: %v", err)
+	}
+	if resp.Status != "success" || resp.Data.ResultType != "streams" {
+		return nil, fmt.Errorf("unexpected loki response (%s %s)", resp.Status, resp.Data.ResultType)
+	}
+
+	entries := []LokiLogEntry{}
+	for _, stream := range resp.Data.Result {
+		container := ""
+		if containerLabel != "" {
+			container = stream.Stream[containerLabel]
+		}
+		for _, value := range stream.Values {
+			nanos, err := strconv.ParseInt(value[0], 10, 64)
+			if err != nil {
+				continue
+			}
+			entries = append(entries, LokiLogEntry{Timestamp: time.Unix(0, nanos).UTC(), Container: container, Line: value[1]})
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Timestamp.Before(entries[j].Timestamp)
+	})
+	return entries, nil
+}
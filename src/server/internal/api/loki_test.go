@@ -0,0 +1,68 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sonnguyen/kubelens/internal/apitest"
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+func TestPodLogsFromLoki(t *testing.T) {
+	var gotQuery, gotTenant string
+	loki := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotTenant = r.Header.Get("X-Scope-OrgID")
+		switch r.URL.Path {
+		case "/loki/api/v1/labels":
+			w.Write([]byte(`{"status":"success","data":["k8s_namespace_name","k8s_pod_name","k8s_container_name","job"]}`))
+		case "/loki/api/v1/query_range":
+			gotQuery = r.URL.Query().Get("query")
+			// Newest first, as requested with direction=backward
+			w.Write([]byte(`{"status":"success","data":{"resultType":"streams","result":[
+				{"stream":{"k8s_container_name":"web"},"values":[["1700000002000000000","third"],["1700000000000000000","first"]]},
+				{"stream":{"k8s_container_name":"sidecar"},"values":[["1700000001000000000","second"]]}
+			]}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer loki.Close()
+
+	s := apitest.New(t)
+	if err := s.DB.CreateCluster(&db.Cluster{Name: apitest.ClusterName, AuthConfig: db.JSON("{}"), Enabled: true}); err != nil {
+		t.Fatal(err)
+	}
+	w := s.Do(http.MethodPut, "/api/v1/clusters/test/datasources/loki", map[string]interface{}{
+		"url": loki.URL, "headers": map[string]string{"X-Scope-OrgID": "team-a"},
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("save datasource status = %d: %s", w.Code, w.Body)
+	}
+
+	// The pod no longer exists in the cluster; Loki still has its logs
+	var resp struct {
+		Logs    string `json:"logs"`
+		Entries []struct {
+			Container string `json:"container"`
+		} `json:"entries"`
+	}
+	w = s.Get("/api/v1/clusters/test/namespaces/shop/pods/web-1/logs?source=loki&filter=error")
+	if w.Code != http.StatusOK {
+		t.Fatalf("logs status = %d: %s", w.Code, w.Body)
+	}
+	apitest.DecodeJSON(t, w, &resp)
+	if want := `{k8s_namespace_name="shop",k8s_pod_name="web-1"} |= "error"`; gotQuery != want {
+		t.Errorf("query = %s, want %s", gotQuery, want)
+	}
+	if gotTenant != "team-a" {
+		t.Errorf("X-Scope-OrgID = %q, want the configured header", gotTenant)
+	}
+	if resp.Logs != "first\nsecond\nthird" || resp.Entries[1].Container != "sidecar" {
+		t.Errorf("logs = %q (%+v), want the streams merged in order", resp.Logs, resp.Entries)
+	}
+
+	if w := s.Get("/api/v1/clusters/test/namespaces/shop/pods/web-1/logs?source=loki&limit=0"); w.Code != http.StatusBadRequest {
+		t.Errorf("limit=0 status = %d, want 400", w.Code)
+	}
+}
@@ -0,0 +1,184 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+	"sigs.k8s.io/yaml"
+)
+
+// kubelensFieldManager is the field manager name used for server-side apply
+const kubelensFieldManager = "kubelens"
+
+// DiffManifest returns what saving a manifest would change on the live object. The change is
+// computed by the API server with a dry run, so defaulting, admission webhooks and schema
+// validation are all taken into account.
+// Body: a single YAML or JSON manifest. A manifest carrying metadata.resourceVersion (as
+// produced by the editor) is compared as a full update, anything else as a server-side apply.
+// Query: namespace - used for namespaced objects without metadata.namespace (default "default")
+func (h *Handler) DiffManifest(c *gin.Context) {
+	clusterName := c.Param("name")
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+
+	obj, err := decodeManifest(body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	mapping, err := h.mappingFor(clusterName, obj.GroupVersionKind())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if isNamespaced(mapping) {
+		if obj.GetNamespace() == "" {
+			obj.SetNamespace(c.DefaultQuery("namespace", "default"))
+		}
+	} else {
+		obj.SetNamespace("")
+	}
+	if err := h.checkObjectsScope(c, clusterName, []*unstructured.Unstructured{obj}, "", "read"); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	client, err := h.dynamicClient(c, clusterName)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	diff, err := dryRunDiff(context.Background(), client.Resource(mapping.Resource).Namespace(obj.GetNamespace()), mapping.GroupVersionKind.Kind, obj, !h.secretsRevealed())
+	if err != nil {
+		if apierrors.IsInvalid(err) || apierrors.IsBadRequest(err) || apierrors.IsConflict(err) || apierrors.IsForbidden(err) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
+		log.Errorf("Failed to dry-run %s %s: %v", mapping.GroupVersionKind.Kind, obj.GetName(), err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, diff)
+}
+
+// manifestDiff is what saving a manifest would change on the live object
+type manifestDiff struct {
+	Action     string        `json:"action"` // create, update, unchanged
+	APIVersion string        `json:"apiVersion"`
+	Kind       string        `json:"kind"`
+	Name       string        `json:"name"`
+	Namespace  string        `json:"namespace"`
+	Changes    []fieldChange `json:"changes"`
+	Diff       string        `json:"diff"`
+}
+
+// dryRunDiff compares the live object with the result of saving obj with a dry run. obj
+// must already have its namespace set (or cleared for cluster-scoped kinds). With
+// redactSecrets, the values of a Secret are left out of the changes and the diff.
+func dryRunDiff(ctx context.Context, resourceClient dynamic.ResourceInterface, kind string, obj *unstructured.Unstructured, redactSecrets bool) (*manifestDiff, error) {
+	live, err := resourceClient.Get(ctx, obj.GetName(), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		live = nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	dryRun := []string{metav1.DryRunAll}
+	var result *unstructured.Unstructured
+	if live != nil && obj.GetResourceVersion() != "" {
+		result, err = resourceClient.Update(ctx, obj, metav1.UpdateOptions{DryRun: dryRun})
+	} else {
+		result, err = resourceClient.Apply(ctx, obj.GetName(), obj, metav1.ApplyOptions{
+			FieldManager: kubelensFieldManager,
+			Force:        true,
+			DryRun:       dryRun,
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	before := map[string]interface{}{}
+	action := "create"
+	if live != nil {
+		before = withoutDiffNoise(live.Object)
+		action = "update"
+	}
+	after := withoutDiffNoise(result.Object)
+
+	changes := structuredDiff(before, after)
+	if live != nil && len(changes) == 0 {
+		action = "unchanged"
+	}
+	if redactSecrets && isSecret(result.Object) {
+		redactSecretChanges(changes)
+		before, _ = redactSecretValues("", before).(map[string]interface{})
+		after, _ = redactSecretValues("", after).(map[string]interface{})
+	}
+
+	beforeYAML := ""
+	if live != nil {
+		beforeYAML, _ = objectYAMLForDiff(before)
+	}
+	afterYAML, _ := objectYAMLForDiff(after)
+
+	return &manifestDiff{
+		Action:     action,
+		APIVersion: obj.GetAPIVersion(),
+		Kind:       kind,
+		Name:       obj.GetName(),
+		Namespace:  obj.GetNamespace(),
+		Changes:    changes,
+		Diff:       unifiedDiff(beforeYAML, afterYAML, "live", "manifest"),
+	}, nil
+}
+
+// decodeManifest parses a single YAML or JSON manifest into an unstructured object
+func decodeManifest(data []byte) (*unstructured.Unstructured, error) {
+	obj := &unstructured.Unstructured{}
+	if err := yaml.Unmarshal(data, &obj.Object); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %v", err)
+	}
+	if len(obj.Object) == 0 {
+		return nil, fmt.Errorf("manifest is empty")
+	}
+	if err := validateManifest(obj); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// validateManifest checks that an object carries what is needed to find and apply it
+func validateManifest(obj *unstructured.Unstructured) error {
+	if obj.GetAPIVersion() == "" || obj.GetKind() == "" {
+		return fmt.Errorf("manifest must set apiVersion and kind")
+	}
+	if obj.GetName() == "" {
+		return fmt.Errorf("manifest must set metadata.name")
+	}
+	return nil
+}
+
+// withoutDiffNoise returns a copy of an object without fields that change on every write
+// and would otherwise show up in every diff
+func withoutDiffNoise(obj map[string]interface{}) map[string]interface{} {
+	clean := (&unstructured.Unstructured{Object: obj}).DeepCopy().Object
+	for _, field := range []string{"managedFields", "generation", "resourceVersion", "uid", "creationTimestamp"} {
+		unstructured.RemoveNestedField(clean, "metadata", field)
+	}
+	return clean
+}
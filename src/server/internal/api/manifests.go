@@ -0,0 +1,335 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+
+	"github.com/sonnguyen/kubelens/internal/audit"
+)
+
+// maxManifestDocuments bounds the number of objects in one apply request
+const maxManifestDocuments = 500
+
+// applyKindOrder is the order in which kinds are applied, so that objects are created after
+// what they depend on (namespaces and CRDs first, workloads last). Unlisted kinds, including
+// custom resources, go after all listed ones.
+var applyKindOrder = []string{
+	"Namespace",
+	"NetworkPolicy",
+	"ResourceQuota",
+	"LimitRange",
+	"PodSecurityPolicy",
+	"PodDisruptionBudget",
+	"ServiceAccount",
+	"Secret",
+	"ConfigMap",
+	"StorageClass",
+	"PersistentVolume",
+	"PersistentVolumeClaim",
+	"CustomResourceDefinition",
+	"ClusterRole",
+	"ClusterRoleBinding",
+	"Role",
+	"RoleBinding",
+	"Service",
+	"DaemonSet",
+	"Pod",
+	"ReplicationController",
+	"ReplicaSet",
+	"Deployment",
+	"HorizontalPodAutoscaler",
+	"StatefulSet",
+	"Job",
+	"CronJob",
+	"IngressClass",
+	"Ingress",
+	"APIService",
+	"MutatingWebhookConfiguration",
+	"ValidatingWebhookConfiguration",
+}
+
+// ManifestResult is the outcome of applying one object
+type ManifestResult struct {
+	Index      int    `json:"index"`
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	Namespace  string `json:"namespace,omitempty"`
+	Action     string `json:"action,omitempty"` // created, configured, unchanged
+	Error      string `json:"error,omitempty"`
+}
+
+// ApplyManifests applies a multi-document YAML (or JSON) stream with server-side apply,
+// e.g. the output of helm template. Objects are applied in dependency order and every
+// object gets its own result; a failing object does not stop the others.
+// Query: namespace (for objects without metadata.namespace, default "default"),
+// force=true (take over fields managed by someone else), dryRun=true (validate only)
+func (h *Handler) ApplyManifests(c *gin.Context) {
+	clusterName := c.Param("name")
+	defaultNamespace := c.DefaultQuery("namespace", "default")
+	force := c.Query("force") == "true"
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+
+	objects, err := decodeManifests(body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(objects) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "no manifests found"})
+		return
+	}
+	if len(objects) > maxManifestDocuments {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("too many manifests (%d), at most %d are allowed", len(objects), maxManifestDocuments)})
+		return
+	}
+
+	// Server-side apply creates or updates each object
+	if err := h.checkObjectsScope(c, clusterName, objects, defaultNamespace, "create", "update"); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	results, failed, err := h.applyObjects(c, clusterName, objects, defaultNamespace, force)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	if failed > 0 {
+		log.Warnf("Applied manifests to cluster %s: %d of %d failed", clusterName, failed, len(objects))
+	}
+
+	// Audit log
+	if userID, exists := c.Get("user_id"); exists {
+		username, _ := c.Get("username")
+		email, _ := c.Get("email")
+
+		objectNames := make([]string, 0, len(results))
+		for _, r := range results {
+			if r.Error == "" {
+				objectNames = append(objectNames, manifestResultName(r))
+			}
+		}
+		audit.Log(c, audit.EventAuditResourceUpdated, userID.(int), username.(string), email.(string),
+			fmt.Sprintf("Applied %d manifests to cluster %s (%d failed)", len(objects)-failed, clusterName, failed),
+			map[string]interface{}{
+				"cluster_name": clusterName,
+				"objects":      objectNames,
+				"failed":       failed,
+				"force":        force,
+			})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"results": results,
+		"total":   len(objects),
+		"applied": len(objects) - failed,
+		"failed":  failed,
+	})
+}
+
+// applyObjects applies objects with server-side apply in dependency order and returns one
+// result per object plus the number of failures. The error is only set when the cluster
+// cannot be reached at all.
+func (h *Handler) applyObjects(c *gin.Context, clusterName string, objects []*unstructured.Unstructured, defaultNamespace string, force bool) ([]ManifestResult, int, error) {
+	client, err := h.dynamicClient(c, clusterName)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	order := sortForApply(objects)
+	results := make([]ManifestResult, len(objects))
+	failed := 0
+	ctx := context.Background()
+
+	for _, i := range order {
+		obj := objects[i]
+		result := &results[i]
+		*result = ManifestResult{Index: i, APIVersion: obj.GetAPIVersion(), Kind: obj.GetKind(), Name: obj.GetName()}
+
+		mapping, err := h.mappingFor(clusterName, obj.GroupVersionKind())
+		if err != nil {
+			result.Error = err.Error()
+			failed++
+			continue
+		}
+		if isNamespaced(mapping) {
+			if obj.GetNamespace() == "" {
+				obj.SetNamespace(defaultNamespace)
+			}
+		} else {
+			obj.SetNamespace("")
+		}
+		result.Namespace = obj.GetNamespace()
+
+		resourceClient := client.Resource(mapping.Resource).Namespace(obj.GetNamespace())
+		existing, err := resourceClient.Get(ctx, obj.GetName(), metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			existing = nil
+		} else if err != nil {
+			result.Error = err.Error()
+			failed++
+			continue
+		}
+
+		applied, err := resourceClient.Apply(ctx, obj.GetName(), obj, metav1.ApplyOptions{
+			FieldManager: kubelensFieldManager,
+			Force:        force,
+			DryRun:       dryRunValue(c),
+		})
+		if err != nil {
+			result.Error = err.Error()
+			failed++
+			continue
+		}
+
+		switch {
+		case existing == nil:
+			result.Action = "created"
+		case existing.GetResourceVersion() == applied.GetResourceVersion():
+			result.Action = "unchanged"
+		default:
+			result.Action = "configured"
+		}
+
+		// A new CRD makes its kind available to later documents in the stream
+		if obj.GetKind() == "CustomResourceDefinition" {
+			h.mappers.forget(clusterName)
+		}
+	}
+	return results, failed, nil
+}
+
+// scopeAllowsAction returns the check of the caller's cluster and namespace scope that
+// auth.ResourceScope sets for the routes whose body names the objects, if the caller is
+// restricted
+func scopeAllowsAction(c *gin.Context) (func(cluster, resource, namespace, action string) bool, bool) {
+	value, ok := c.Get("scope_allows_action")
+	if !ok {
+		return nil, false
+	}
+	allows, ok := value.(func(cluster, resource, namespace, action string) bool)
+	return allows, ok
+}
+
+// checkObjectsScope checks that the caller's scope allows the actions on every object of a
+// request body, namespaced objects without a namespace being checked in defaultNamespace.
+// The error names the first object out of scope; nothing should be done then.
+func (h *Handler) checkObjectsScope(c *gin.Context, clusterName string, objects []*unstructured.Unstructured, defaultNamespace string, actions ...string) error {
+	allows, ok := scopeAllowsAction(c)
+	if !ok {
+		return nil
+	}
+	for _, obj := range objects {
+		mapping, err := h.mappingFor(clusterName, obj.GroupVersionKind())
+		if err != nil {
+			// Including kinds defined by a CRD of the same request, not known yet
+			return fmt.Errorf("cannot check access to %s %s: %v", obj.GetKind(), obj.GetName(), err)
+		}
+		namespace := ""
+		if isNamespaced(mapping) {
+			namespace = obj.GetNamespace()
+			if namespace == "" {
+				namespace = defaultNamespace
+			}
+		}
+		for _, action := range actions {
+			if !allows(clusterName, mapping.Resource.Resource, namespace, action) {
+				return fmt.Errorf("no access to %s %s in cluster %s namespace %q", mapping.Resource.Resource, obj.GetName(), clusterName, namespace)
+			}
+		}
+	}
+	return nil
+}
+
+// decodeManifests splits a multi-document YAML or JSON stream into objects. Empty
+// documents are skipped and List objects are expanded into their items.
+func decodeManifests(data []byte) ([]*unstructured.Unstructured, error) {
+	decoder := utilyaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+	var objects []*unstructured.Unstructured
+
+	for doc := 1; ; doc++ {
+		obj := &unstructured.Unstructured{}
+		if err := decoder.Decode(&obj.Object); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, fmt.Errorf("document %d: failed to parse manifest: %v", doc, err)
+		}
+		if len(obj.Object) == 0 {
+			continue
+		}
+
+		if obj.IsList() {
+			list, err := obj.ToList()
+			if err != nil {
+				return nil, fmt.Errorf("document %d: %v", doc, err)
+			}
+			for i := range list.Items {
+				item := list.Items[i]
+				if err := validateManifest(&item); err != nil {
+					return nil, fmt.Errorf("document %d, item %d: %v", doc, i, err)
+				}
+				objects = append(objects, &item)
+			}
+			continue
+		}
+
+		if err := validateManifest(obj); err != nil {
+			return nil, fmt.Errorf("document %d: %v", doc, err)
+		}
+		objects = append(objects, obj)
+	}
+
+	return objects, nil
+}
+
+// sortForApply returns the indexes of objects in the order they should be applied
+func sortForApply(objects []*unstructured.Unstructured) []int {
+	rank := make(map[string]int, len(applyKindOrder))
+	for i, kind := range applyKindOrder {
+		rank[kind] = i
+	}
+	kindRank := func(kind string) int {
+		if r, ok := rank[kind]; ok {
+			return r
+		}
+		return len(applyKindOrder)
+	}
+
+	order := make([]int, len(objects))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return kindRank(objects[order[a]].GetKind()) < kindRank(objects[order[b]].GetKind())
+	})
+	return order
+}
+
+// manifestResultName formats an applied object as kind/namespace/name
+func manifestResultName(r ManifestResult) string {
+	parts := []string{strings.ToLower(r.Kind)}
+	if r.Namespace != "" {
+		parts = append(parts, r.Namespace)
+	}
+	return strings.Join(append(parts, r.Name), "/")
+}
@@ -0,0 +1,63 @@
+package api_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/sonnguyen/kubelens/internal/apitest"
+)
+
+// TestBodyScopedRoutes checks that a caller restricted to a namespace cannot reach other
+// namespaces or cluster-scoped objects through the routes whose body names the objects
+func TestBodyScopedRoutes(t *testing.T) {
+	s := apitest.New(t, apitest.Fixtures()...)
+	do := scopedRouter(s, "read", "create", "update")
+
+	binding := `apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRoleBinding
+metadata:
+  name: take-over
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: cluster-admin
+subjects:
+- kind: User
+  name: me
+`
+	otherNamespace := "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: web-config\n  namespace: kube-system\n"
+
+	tests := []struct {
+		name string
+		path string
+		body string
+	}{
+		{name: "cluster-scoped object", path: "/manifests", body: binding},
+		{name: "cluster-scoped object after an allowed one", path: "/manifests", body: "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: web-config\n---\n" + binding},
+		{name: "object in another namespace", path: "/manifests", body: otherNamespace},
+		{name: "default namespace", path: "/manifests?namespace=default", body: "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: web-config\n"},
+		{name: "diff in another namespace", path: "/diff", body: otherNamespace},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := do(http.MethodPost, "/api/v1/clusters/"+apitest.ClusterName+tt.path, "application/yaml", tt.body)
+			if w.Code != http.StatusForbidden {
+				t.Fatalf("status = %d, want 403: %s", w.Code, w.Body.String())
+			}
+		})
+	}
+	for _, action := range s.Cluster.Dynamic.Actions() {
+		if action.GetVerb() != "get" && action.GetVerb() != "list" {
+			t.Errorf("unexpected %s of %s after a denied request", action.GetVerb(), action.GetResource().Resource)
+		}
+	}
+
+	w := do(http.MethodPost, "/api/v1/clusters/"+apitest.ClusterName+"/quick-actions/node-cordon", "application/json", `{"name":"node-1","params":{"unschedulable":true}}`)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("cordon: status = %d, want 403: %s", w.Code, w.Body.String())
+	}
+	w = do(http.MethodPost, "/api/v1/clusters/"+apitest.ClusterName+"/quick-actions/cronjob-suspend", "application/json", `{"namespace":"`+apitest.FixtureNamespace+`","name":"nightly","params":{"suspend":true}}`)
+	if w.Code == http.StatusForbidden {
+		t.Errorf("suspend in the granted namespace: status = 403: %s", w.Body.String())
+	}
+}
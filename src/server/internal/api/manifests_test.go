@@ -0,0 +1,59 @@
+package api
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDecodeManifestsAndOrder(t *testing.T) {
+	stream := `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+---
+# comment-only document
+---
+apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: Service
+  metadata:
+    name: web
+- apiVersion: example.com/v1
+  kind: Widget
+  metadata:
+    name: w1
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: shop
+---
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: widgets.example.com
+`
+	objects, err := decodeManifests([]byte(stream))
+	if err != nil {
+		t.Fatalf("decodeManifests() error = %v", err)
+	}
+	if len(objects) != 5 {
+		t.Fatalf("decodeManifests() returned %d objects, want 5", len(objects))
+	}
+
+	var kinds []string
+	for _, i := range sortForApply(objects) {
+		kinds = append(kinds, objects[i].GetKind())
+	}
+	want := "Namespace,CustomResourceDefinition,Service,Deployment,Widget"
+	if got := strings.Join(kinds, ","); got != want {
+		t.Errorf("apply order = %s, want %s", got, want)
+	}
+
+	if _, err := decodeManifests([]byte("apiVersion: v1\nkind: ConfigMap\n")); err == nil {
+		t.Error("expected error for manifest without a name")
+	}
+}
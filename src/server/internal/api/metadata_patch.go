@@ -0,0 +1,134 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/sonnguyen/kubelens/internal/audit"
+)
+
+// ==================== Label & Annotation Patch Handlers ====================
+
+// PatchResourceLabels adds, updates or removes labels on any resource
+// Body: {"app": "web", "obsolete": null} - null removes the key
+func (h *Handler) PatchResourceLabels(c *gin.Context) {
+	h.patchResourceMetadata(c, "labels")
+}
+
+// PatchResourceAnnotations adds, updates or removes annotations on any resource
+// Body: {"owner": "team-a", "obsolete": null} - null removes the key
+func (h *Handler) PatchResourceAnnotations(c *gin.Context) {
+	h.patchResourceMetadata(c, "annotations")
+}
+
+// patchResourceMetadata patches metadata.labels or metadata.annotations without sending the whole object
+func (h *Handler) patchResourceMetadata(c *gin.Context, field string) {
+	clusterName := c.Param("name")
+	resource := c.Param("resource")
+	name := c.Param("resname")
+	namespace := c.Query("namespace")
+
+	var changes map[string]*string
+	if err := c.ShouldBindJSON(&changes); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(changes) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("no %s to change", field)})
+		return
+	}
+	if errs := validateMetadataChanges(field, changes); len(errs) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": strings.Join(errs, "; ")})
+		return
+	}
+
+	mapping, err := h.resolveResource(clusterName, resource)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if isNamespaced(mapping) && namespace == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("namespace query parameter is required for %s", mapping.GroupVersionKind.Kind)})
+		return
+	}
+	if !isNamespaced(mapping) {
+		namespace = ""
+	}
+
+	client, err := h.dynamicClient(c, clusterName)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	patch, _ := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{field: changes},
+	})
+
+	// Labels and annotations are plain maps, which a strategic merge patch merges like a JSON
+	// merge patch does; unlike the former, a merge patch also works on custom resources
+	updated, err := client.Resource(mapping.Resource).Namespace(namespace).Patch(context.Background(), name, types.MergePatchType, patch, patchOptions(c))
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		log.Errorf("Failed to patch %s on %s %s: %v", field, mapping.GroupVersionKind.Kind, name, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Audit log
+	if userID, exists := c.Get("user_id"); exists {
+		username, _ := c.Get("username")
+		email, _ := c.Get("email")
+
+		target := name
+		if namespace != "" {
+			target = namespace + "/" + name
+		}
+		audit.Log(c, audit.EventAuditResourceUpdated, userID.(int), username.(string), email.(string),
+			fmt.Sprintf("Updated %s on %s: %s", field, strings.ToLower(mapping.GroupVersionKind.Kind), target),
+			map[string]interface{}{
+				"cluster_name": clusterName,
+				"namespace":    namespace,
+				"kind":         mapping.GroupVersionKind.Kind,
+				"name":         name,
+				field:          changes,
+			})
+	}
+
+	result := updated.GetLabels()
+	if field == "annotations" {
+		result = updated.GetAnnotations()
+	}
+	if result == nil {
+		result = map[string]string{}
+	}
+	c.JSON(http.StatusOK, gin.H{field: result, "resourceVersion": updated.GetResourceVersion()})
+}
+
+// validateMetadataChanges checks keys (and label values) against Kubernetes naming rules
+func validateMetadataChanges(field string, changes map[string]*string) []string {
+	var errs []string
+	for key, value := range changes {
+		for _, msg := range validation.IsQualifiedName(key) {
+			errs = append(errs, fmt.Sprintf("invalid key %q: %s", key, msg))
+		}
+		if field == "labels" && value != nil {
+			for _, msg := range validation.IsValidLabelValue(*value) {
+				errs = append(errs, fmt.Sprintf("invalid value for %q: %s", key, msg))
+			}
+		}
+	}
+	return errs
+}
@@ -0,0 +1,98 @@
+package api_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/sonnguyen/kubelens/internal/apitest"
+	"github.com/sonnguyen/kubelens/internal/audit"
+)
+
+func TestPatchResourceMetadata(t *testing.T) {
+	s := apitest.New(t, apitest.Fixtures()...)
+	base := "/api/v1/clusters/" + apitest.ClusterName + "/resources"
+	deployments := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+
+	var labels struct {
+		Labels map[string]string `json:"labels"`
+	}
+	w := s.Do(http.MethodPatch, base+"/deployments/web/labels?namespace="+apitest.FixtureNamespace,
+		map[string]interface{}{"tier": "frontend", "app": nil})
+	if w.Code != http.StatusOK {
+		t.Fatalf("patch labels: %d %s", w.Code, w.Body.String())
+	}
+	apitest.DecodeJSON(t, w, &labels)
+	if labels.Labels["tier"] != "frontend" || labels.Labels["app"] != "" {
+		t.Errorf("labels = %v, want tier added and app removed", labels.Labels)
+	}
+	web, err := s.Cluster.Dynamic.Resource(deployments).Namespace(apitest.FixtureNamespace).Get(context.Background(), "web", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := web.GetLabels(); got["tier"] != "frontend" || got["app"] != "" {
+		t.Errorf("stored labels = %v, want tier added and app removed", got)
+	}
+	if !auditDescribed(t, s, audit.EventAuditResourceUpdated, "Updated labels on deployment: shop/web") {
+		t.Error("label patch was not audited")
+	}
+
+	// Cluster-scoped resources need no namespace
+	var annotations struct {
+		Annotations map[string]string `json:"annotations"`
+	}
+	w = s.Do(http.MethodPatch, base+"/nodes/node-1/annotations", map[string]string{"example.com/owner": "team-a"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("patch annotations: %d %s", w.Code, w.Body.String())
+	}
+	apitest.DecodeJSON(t, w, &annotations)
+	if annotations.Annotations["example.com/owner"] != "team-a" {
+		t.Errorf("annotations = %v, want example.com/owner added", annotations.Annotations)
+	}
+
+	tests := []struct {
+		name string
+		path string
+		body interface{}
+		want int
+	}{
+		{"no changes", "/deployments/web/labels?namespace=shop", map[string]string{}, http.StatusBadRequest},
+		{"invalid key", "/deployments/web/labels?namespace=shop", map[string]string{"bad key!": "x"}, http.StatusBadRequest},
+		{"invalid label value", "/deployments/web/labels?namespace=shop", map[string]string{"tier": "front end"}, http.StatusBadRequest},
+		{"missing namespace", "/deployments/web/labels", map[string]string{"tier": "frontend"}, http.StatusBadRequest},
+		{"unknown object", "/deployments/missing/labels?namespace=shop", map[string]string{"tier": "frontend"}, http.StatusNotFound},
+		{"unknown resource", "/widgets/web/labels?namespace=shop", map[string]string{"tier": "frontend"}, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := s.Do(http.MethodPatch, base+tt.path, tt.body); w.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestPatchResourceMetadataScoped(t *testing.T) {
+	s := apitest.New(t, apitest.Fixtures()...)
+	base := "/api/v1/clusters/" + apitest.ClusterName + "/resources"
+	body := `{"tier":"frontend"}`
+
+	// Patching metadata is an update
+	if w := scopedRouter(s, "read")(http.MethodPatch, base+"/deployments/web/labels?namespace=shop", "application/json", body); w.Code != http.StatusForbidden {
+		t.Errorf("read-only caller: %d, want 403: %s", w.Code, w.Body.String())
+	}
+
+	do := scopedRouter(s, "read", "update")
+	if w := do(http.MethodPatch, base+"/deployments/web/labels?namespace=kube-system", "application/json", body); w.Code != http.StatusForbidden {
+		t.Errorf("another namespace: %d, want 403: %s", w.Code, w.Body.String())
+	}
+	if w := do(http.MethodPatch, base+"/nodes/node-1/labels", "application/json", body); w.Code != http.StatusForbidden {
+		t.Errorf("cluster-scoped object: %d, want 403: %s", w.Code, w.Body.String())
+	}
+	if w := do(http.MethodPatch, base+"/deployments/web/labels?namespace=shop", "application/json", body); w.Code != http.StatusOK {
+		t.Errorf("inside the scope: %d, want 200: %s", w.Code, w.Body.String())
+	}
+}
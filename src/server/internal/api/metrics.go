@@ -2,7 +2,11 @@ package api
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
+	"sort"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	log "github.com/sirupsen/logrus"
@@ -41,6 +45,10 @@ type ClusterResourcesSummary struct {
 	TotalNamespaces      int `json:"totalNamespaces"`
 	ActiveNamespaces     int `json:"activeNamespaces"`
 	TotalServices        int `json:"totalServices"`
+	// ArgoCD is set when Argo CD is installed in the cluster
+	ArgoCD *ArgoSummary `json:"argocd,omitempty"`
+	// Flux is set when Flux is installed in the cluster
+	Flux *FluxSummary `json:"flux,omitempty"`
 }
 
 // NodeMetrics represents metrics for a single node
@@ -74,7 +82,7 @@ type ContainerMetrics struct {
 func (h *Handler) GetClusterMetrics(c *gin.Context) {
 	clusterName := c.Param("name")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -144,7 +152,7 @@ func (h *Handler) GetClusterMetrics(c *gin.Context) {
 	}
 
 	// Try to get actual usage from metrics-server
-	metricsClient, err := h.clusterManager.GetMetricsClient(clusterName)
+	metricsClient, err := h.metricsClient(c, clusterName)
 	if err != nil {
 		log.Warnf("Metrics server not available for cluster %s: %v", clusterName, err)
 		// Continue without usage data
@@ -181,17 +189,30 @@ func (h *Handler) GetClusterMetrics(c *gin.Context) {
 	c.JSON(http.StatusOK, metrics)
 }
 
-// GetClusterResourcesSummary returns a summary of cluster resources
+// clusterSummaryTTL is how long a cluster resources summary is served from the cache
+const clusterSummaryTTL = 30 * time.Second
+
+// GetClusterResourcesSummary returns a summary of cluster resources. Summaries are cached
+// for clusters that are not impersonated, as they are the same for every user;
+// ?refresh=true skips the cache.
 func (h *Handler) GetClusterResourcesSummary(c *gin.Context) {
 	clusterName := c.Param("name")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
 
 	ctx := context.Background()
+	cacheKey := "cluster-summary:" + clusterName
+	cacheable := !h.clusterManager.Impersonates(clusterName)
+	if cacheable && c.Query("refresh") != "true" {
+		if data, err := h.cache.Get(ctx, cacheKey); err == nil {
+			c.Data(http.StatusOK, "application/json; charset=utf-8", data)
+			return
+		}
+	}
 	summary := ClusterResourcesSummary{}
 
 	// Count nodes
@@ -271,6 +292,16 @@ func (h *Handler) GetClusterResourcesSummary(c *gin.Context) {
 		summary.TotalServices = len(services.Items)
 	}
 
+	summary.ArgoCD = h.argoApplicationSummary(c, clusterName)
+	summary.Flux = h.fluxSummary(c, clusterName)
+
+	if cacheable {
+		if data, err := json.Marshal(summary); err == nil {
+			if err := h.cache.Set(ctx, cacheKey, data, clusterSummaryTTL); err != nil {
+				log.Warnf("Failed to cache the summary of cluster %s: %v", clusterName, err)
+			}
+		}
+	}
 	c.JSON(http.StatusOK, summary)
 }
 
@@ -283,7 +314,7 @@ func (h *Handler) GetNodeMetrics(c *gin.Context) {
 	clusterName := c.Param("name")
 	nodeName := c.Param("node")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -311,7 +342,7 @@ func (h *Handler) GetNodeMetrics(c *gin.Context) {
 	metrics.Capacity.Memory = memCapacity.Value()
 
 	// Try to get usage from metrics-server
-	metricsClient, err := h.clusterManager.GetMetricsClient(clusterName)
+	metricsClient, err := h.metricsClient(c, clusterName)
 	if err != nil {
 		log.Warnf("Metrics server not available for cluster %s: %v", clusterName, err)
 		// Return with only capacity data
@@ -347,7 +378,7 @@ func (h *Handler) GetPodMetrics(c *gin.Context) {
 	namespace := c.Param("namespace")
 	podName := c.Param("pod")
 
-	metricsClient, err := h.clusterManager.GetMetricsClient(clusterName)
+	metricsClient, err := h.metricsClient(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get metrics client"})
 		return
@@ -400,7 +431,7 @@ func (h *Handler) GetNamespaceMetrics(c *gin.Context) {
 	clusterName := c.Param("name")
 	namespace := c.Param("namespace")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -448,7 +479,7 @@ func (h *Handler) GetNamespaceMetrics(c *gin.Context) {
 	}
 
 	// Try to get actual usage from metrics-server
-	metricsClient, err := h.clusterManager.GetMetricsClient(clusterName)
+	metricsClient, err := h.metricsClient(c, clusterName)
 	if err != nil {
 		log.Warnf("Metrics server not available for cluster %s: %v", clusterName, err)
 		// Return with only requests and limits
@@ -481,6 +512,139 @@ func (h *Handler) GetNamespaceMetrics(c *gin.Context) {
 	c.JSON(http.StatusOK, metrics)
 }
 
+// ============================================================================
+// Top Pods
+// ============================================================================
+
+// TopPod represents a pod's usage joined with its requests and limits
+type TopPod struct {
+	Name      string                 `json:"name"`
+	Namespace string                 `json:"namespace"`
+	NodeName  string                 `json:"nodeName,omitempty"`
+	Usage     NamespaceResourceUsage `json:"usage"`
+	Requests  NamespaceResourceUsage `json:"requests"`
+	Limits    NamespaceResourceUsage `json:"limits"`
+	// Usage as a percentage of requests (0 when no request is set)
+	CPURequestPercent    float64 `json:"cpuRequestPercent"`
+	MemoryRequestPercent float64 `json:"memoryRequestPercent"`
+}
+
+// GetTopPods returns the top resource consumers across a cluster
+// Query params: sortBy (cpu|memory, default cpu), limit (default 50, max 500), namespace
+func (h *Handler) GetTopPods(c *gin.Context) {
+	clusterName := c.Param("name")
+	namespace := c.Query("namespace")
+	sortBy := c.DefaultQuery("sortBy", "cpu")
+
+	if sortBy != "cpu" && sortBy != "memory" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "sortBy must be cpu or memory"})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive integer"})
+		return
+	}
+	if limit > 500 {
+		limit = 500 // Max 500 pods
+	}
+
+	client, err := h.client(c, clusterName)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	metricsClient, err := h.metricsClient(c, clusterName)
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Metrics server not available"})
+		return
+	}
+
+	ctx := context.Background()
+
+	podMetricsList, err := metricsClient.MetricsV1beta1().PodMetricses(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		log.Warnf("Failed to get pod metrics for cluster %s: %v", clusterName, err)
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Metrics server not available"})
+		return
+	}
+
+	pods, err := client.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		log.Errorf("Failed to list pods: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	podsByKey := make(map[string]*corev1.Pod, len(pods.Items))
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		podsByKey[pod.Namespace+"/"+pod.Name] = pod
+	}
+
+	result := make([]TopPod, 0, len(podMetricsList.Items))
+	for _, podMetrics := range podMetricsList.Items {
+		top := TopPod{
+			Name:      podMetrics.Name,
+			Namespace: podMetrics.Namespace,
+		}
+
+		for _, container := range podMetrics.Containers {
+			cpuUsage := container.Usage[corev1.ResourceCPU]
+			memUsage := container.Usage[corev1.ResourceMemory]
+			top.Usage.CPU += cpuUsage.MilliValue()
+			top.Usage.Memory += memUsage.Value()
+		}
+
+		if pod, ok := podsByKey[podMetrics.Namespace+"/"+podMetrics.Name]; ok {
+			top.NodeName = pod.Spec.NodeName
+			for _, container := range pod.Spec.Containers {
+				if cpuRequest, ok := container.Resources.Requests[corev1.ResourceCPU]; ok {
+					top.Requests.CPU += cpuRequest.MilliValue()
+				}
+				if cpuLimit, ok := container.Resources.Limits[corev1.ResourceCPU]; ok {
+					top.Limits.CPU += cpuLimit.MilliValue()
+				}
+				if memRequest, ok := container.Resources.Requests[corev1.ResourceMemory]; ok {
+					top.Requests.Memory += memRequest.Value()
+				}
+				if memLimit, ok := container.Resources.Limits[corev1.ResourceMemory]; ok {
+					top.Limits.Memory += memLimit.Value()
+				}
+			}
+		}
+
+		if top.Requests.CPU > 0 {
+			top.CPURequestPercent = float64(top.Usage.CPU) / float64(top.Requests.CPU) * 100
+		}
+		if top.Requests.Memory > 0 {
+			top.MemoryRequestPercent = float64(top.Usage.Memory) / float64(top.Requests.Memory) * 100
+		}
+
+		result = append(result, top)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if sortBy == "memory" {
+			return result[i].Usage.Memory > result[j].Usage.Memory
+		}
+		return result[i].Usage.CPU > result[j].Usage.CPU
+	})
+
+	total := len(result)
+	if len(result) > limit {
+		result = result[:limit]
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"pods":   result,
+		"total":  total,
+		"sortBy": sortBy,
+	})
+}
+
 // ============================================================================
 // Helper Functions
 // ============================================================================
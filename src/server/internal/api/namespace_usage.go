@@ -0,0 +1,279 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// namespaceUsageTimeout bounds the calls made for a namespace usage report
+const namespaceUsageTimeout = 20 * time.Second
+
+// NamespaceUsage is what a namespace consumes: what its pods request and are limited to,
+// what they actually use, how much of its quotas is used, and how many objects it holds
+type NamespaceUsage struct {
+	Cluster   string                 `json:"cluster"`
+	Namespace string                 `json:"namespace"`
+	Requests  NamespaceResourceUsage `json:"requests"`
+	Limits    NamespaceResourceUsage `json:"limits"`
+	// Usage is only set when metrics-server answers
+	Usage            *NamespaceResourceUsage `json:"usage"`
+	MetricsAvailable bool                    `json:"metricsAvailable"`
+	// Usage as a percentage of requests (0 when no request is set or no usage is known)
+	CPURequestPercent    float64 `json:"cpuRequestPercent"`
+	MemoryRequestPercent float64 `json:"memoryRequestPercent"`
+	// StorageRequests is the storage requested by persistent volume claims, in bytes
+	StorageRequests int64          `json:"storageRequests"`
+	Quotas          []QuotaUsage   `json:"quotas"`
+	Objects         map[string]int `json:"objects"`
+	PodPhases       map[string]int `json:"podPhases"`
+	// Errors lists the parts of the report that could not be read
+	Errors []string `json:"errors,omitempty"`
+}
+
+// QuotaUsage is the consumption of one resource quota
+type QuotaUsage struct {
+	Name      string               `json:"name"`
+	Resources []QuotaResourceUsage `json:"resources"`
+}
+
+// QuotaResourceUsage is the consumption of one resource limited by a quota
+type QuotaResourceUsage struct {
+	Resource string  `json:"resource"`
+	Hard     string  `json:"hard"`
+	Used     string  `json:"used"`
+	Percent  float64 `json:"percent"`
+}
+
+// namespaceObjectCounts are the kinds counted in a namespace usage report
+var namespaceObjectCounts = []struct {
+	name  string
+	count func(ctx context.Context, client kubernetes.Interface, namespace string) (int, error)
+}{
+	{"deployments", func(ctx context.Context, client kubernetes.Interface, namespace string) (int, error) {
+		list, err := client.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return 0, err
+		}
+		return len(list.Items), nil
+	}},
+	{"statefulsets", func(ctx context.Context, client kubernetes.Interface, namespace string) (int, error) {
+		list, err := client.AppsV1().StatefulSets(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return 0, err
+		}
+		return len(list.Items), nil
+	}},
+	{"daemonsets", func(ctx context.Context, client kubernetes.Interface, namespace string) (int, error) {
+		list, err := client.AppsV1().DaemonSets(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return 0, err
+		}
+		return len(list.Items), nil
+	}},
+	{"jobs", func(ctx context.Context, client kubernetes.Interface, namespace string) (int, error) {
+		list, err := client.BatchV1().Jobs(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return 0, err
+		}
+		return len(list.Items), nil
+	}},
+	{"cronjobs", func(ctx context.Context, client kubernetes.Interface, namespace string) (int, error) {
+		list, err := client.BatchV1().CronJobs(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return 0, err
+		}
+		return len(list.Items), nil
+	}},
+	{"services", func(ctx context.Context, client kubernetes.Interface, namespace string) (int, error) {
+		list, err := client.CoreV1().Services(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return 0, err
+		}
+		return len(list.Items), nil
+	}},
+	{"ingresses", func(ctx context.Context, client kubernetes.Interface, namespace string) (int, error) {
+		list, err := client.NetworkingV1().Ingresses(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return 0, err
+		}
+		return len(list.Items), nil
+	}},
+	{"configmaps", func(ctx context.Context, client kubernetes.Interface, namespace string) (int, error) {
+		list, err := client.CoreV1().ConfigMaps(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return 0, err
+		}
+		return len(list.Items), nil
+	}},
+	{"secrets", func(ctx context.Context, client kubernetes.Interface, namespace string) (int, error) {
+		list, err := client.CoreV1().Secrets(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return 0, err
+		}
+		return len(list.Items), nil
+	}},
+}
+
+// GetNamespaceUsage returns the consumption of a namespace in one call, for platform teams
+// reviewing their tenants: pod requests and limits, actual usage from metrics-server,
+// quota consumption and object counts. Parts other than the pods that cannot be read are
+// reported in errors rather than failing the request.
+func (h *Handler) GetNamespaceUsage(c *gin.Context) {
+	clusterName := c.Param("name")
+	namespace := c.Param("namespace")
+
+	client, err := h.client(c, clusterName)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), namespaceUsageTimeout)
+	defer cancel()
+
+	if _, err := client.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{}); err != nil {
+		if apierrors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("namespace %s not found", namespace)})
+			return
+		}
+		log.Errorf("Failed to get namespace %s: %v", namespace, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	usage := NamespaceUsage{
+		Cluster:   clusterName,
+		Namespace: namespace,
+		Quotas:    []QuotaUsage{},
+		Objects:   map[string]int{},
+		PodPhases: map[string]int{},
+	}
+
+	pods, err := client.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		log.Errorf("Failed to list pods in namespace %s: %v", namespace, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	usage.Objects["pods"] = len(pods.Items)
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		usage.PodPhases[string(pod.Status.Phase)]++
+		// Terminated pods no longer hold their requests
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		addPodResources(&usage.Requests, &usage.Limits, pod)
+	}
+
+	if metricsClient, err := h.metricsClient(c, clusterName); err != nil {
+		log.Debugf("Metrics server not available for cluster %s: %v", clusterName, err)
+	} else if podMetricsList, err := metricsClient.MetricsV1beta1().PodMetricses(namespace).List(ctx, metav1.ListOptions{}); err != nil {
+		log.Debugf("Failed to get pod metrics for namespace %s: %v", namespace, err)
+	} else {
+		used := NamespaceResourceUsage{}
+		for _, podMetrics := range podMetricsList.Items {
+			for _, container := range podMetrics.Containers {
+				cpuUsage := container.Usage[corev1.ResourceCPU]
+				memUsage := container.Usage[corev1.ResourceMemory]
+				used.CPU += cpuUsage.MilliValue()
+				used.Memory += memUsage.Value()
+			}
+		}
+		usage.Usage = &used
+		usage.MetricsAvailable = true
+		if usage.Requests.CPU > 0 {
+			usage.CPURequestPercent = float64(used.CPU) / float64(usage.Requests.CPU) * 100
+		}
+		if usage.Requests.Memory > 0 {
+			usage.MemoryRequestPercent = float64(used.Memory) / float64(usage.Requests.Memory) * 100
+		}
+	}
+
+	if quotas, err := client.CoreV1().ResourceQuotas(namespace).List(ctx, metav1.ListOptions{}); err != nil {
+		usage.Errors = append(usage.Errors, fmt.Sprintf("resource quotas: %v", err))
+	} else {
+		for i := range quotas.Items {
+			usage.Quotas = append(usage.Quotas, quotaUsage(&quotas.Items[i]))
+		}
+		sort.Slice(usage.Quotas, func(i, j int) bool { return usage.Quotas[i].Name < usage.Quotas[j].Name })
+	}
+
+	if claims, err := client.CoreV1().PersistentVolumeClaims(namespace).List(ctx, metav1.ListOptions{}); err != nil {
+		usage.Errors = append(usage.Errors, fmt.Sprintf("persistentvolumeclaims: %v", err))
+	} else {
+		usage.Objects["persistentvolumeclaims"] = len(claims.Items)
+		for _, claim := range claims.Items {
+			if storage, ok := claim.Spec.Resources.Requests[corev1.ResourceStorage]; ok {
+				usage.StorageRequests += storage.Value()
+			}
+		}
+	}
+
+	for _, kind := range namespaceObjectCounts {
+		n, err := kind.count(ctx, client, namespace)
+		if err != nil {
+			usage.Errors = append(usage.Errors, fmt.Sprintf("%s: %v", kind.name, err))
+			continue
+		}
+		usage.Objects[kind.name] = n
+	}
+
+	c.JSON(http.StatusOK, usage)
+}
+
+// addPodResources adds the requests and limits of the containers of a pod
+func addPodResources(requests, limits *NamespaceResourceUsage, pod *corev1.Pod) {
+	for _, container := range pod.Spec.Containers {
+		if cpuRequest, ok := container.Resources.Requests[corev1.ResourceCPU]; ok {
+			requests.CPU += cpuRequest.MilliValue()
+		}
+		if cpuLimit, ok := container.Resources.Limits[corev1.ResourceCPU]; ok {
+			limits.CPU += cpuLimit.MilliValue()
+		}
+		if memRequest, ok := container.Resources.Requests[corev1.ResourceMemory]; ok {
+			requests.Memory += memRequest.Value()
+		}
+		if memLimit, ok := container.Resources.Limits[corev1.ResourceMemory]; ok {
+			limits.Memory += memLimit.Value()
+		}
+	}
+}
+
+// quotaUsage reports how much of each resource of a quota is used, by resource name
+func quotaUsage(quota *corev1.ResourceQuota) QuotaUsage {
+	result := QuotaUsage{Name: quota.Name, Resources: []QuotaResourceUsage{}}
+	hardLimits := quota.Status.Hard
+	if len(hardLimits) == 0 {
+		// Not yet counted by the quota controller
+		hardLimits = quota.Spec.Hard
+	}
+	for name, hard := range hardLimits {
+		used := quota.Status.Used[name]
+		entry := QuotaResourceUsage{
+			Resource: string(name),
+			Hard:     hard.String(),
+			Used:     used.String(),
+		}
+		switch {
+		case hard.Sign() > 0:
+			entry.Percent = used.AsApproximateFloat64() / hard.AsApproximateFloat64() * 100
+		case used.Sign() > 0:
+			// Anything used of a zero quota is over it
+			entry.Percent = 100
+		}
+		result.Resources = append(result.Resources, entry)
+	}
+	sort.Slice(result.Resources, func(i, j int) bool { return result.Resources[i].Resource < result.Resources[j].Resource })
+	return result
+}
@@ -0,0 +1,86 @@
+package api_test
+
+import (
+	"net/http"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/sonnguyen/kubelens/internal/api"
+	"github.com/sonnguyen/kubelens/internal/apitest"
+)
+
+func TestNamespaceUsage(t *testing.T) {
+	ns := apitest.FixtureNamespace
+	quota := &corev1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{Name: "tenant", Namespace: ns},
+		Status: corev1.ResourceQuotaStatus{
+			Hard: corev1.ResourceList{
+				corev1.ResourceRequestsCPU: resource.MustParse("1"),
+				corev1.ResourcePods:        resource.MustParse("10"),
+			},
+			Used: corev1.ResourceList{
+				corev1.ResourceRequestsCPU: resource.MustParse("250m"),
+				corev1.ResourcePods:        resource.MustParse("10"),
+			},
+		},
+	}
+	claim := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "cache", Namespace: ns},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			Resources: corev1.VolumeResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("1Gi")}},
+		},
+	}
+	done := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "done", Namespace: ns},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{{
+			Name:      "done",
+			Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")}},
+		}}},
+		Status: corev1.PodStatus{Phase: corev1.PodSucceeded},
+	}
+	s := apitest.New(t, append(apitest.Fixtures(), quota, claim, done)...)
+
+	w := s.Get("/api/v1/clusters/test/namespaces/" + ns + "/usage")
+	if w.Code != http.StatusOK {
+		t.Fatalf("usage: %d %s", w.Code, w.Body.String())
+	}
+	var usage api.NamespaceUsage
+	apitest.DecodeJSON(t, w, &usage)
+
+	// Only the running pod holds its requests
+	if usage.Requests.CPU != 100 || usage.Requests.Memory != 128<<20 {
+		t.Errorf("requests = %+v, want 100m and 128Mi", usage.Requests)
+	}
+	if usage.MetricsAvailable || usage.Usage != nil {
+		t.Errorf("usage reported without metrics-server: %+v", usage.Usage)
+	}
+	if usage.StorageRequests != 1<<30 {
+		t.Errorf("storage requests = %d, want 1Gi", usage.StorageRequests)
+	}
+	if usage.Objects["pods"] != 2 || usage.Objects["deployments"] != 1 || usage.Objects["persistentvolumeclaims"] != 2 {
+		t.Errorf("objects = %v", usage.Objects)
+	}
+	if usage.PodPhases["Running"] != 1 || usage.PodPhases["Succeeded"] != 1 {
+		t.Errorf("pod phases = %v", usage.PodPhases)
+	}
+	// The fixture quota has not been counted yet and reports its spec
+	if len(usage.Quotas) != 2 || usage.Quotas[0].Name != "compute" || len(usage.Quotas[0].Resources) != 1 || len(usage.Quotas[1].Resources) != 2 {
+		t.Fatalf("quotas = %+v", usage.Quotas)
+	}
+	for _, r := range usage.Quotas[1].Resources {
+		want := map[string]float64{"pods": 100, "requests.cpu": 25}[r.Resource]
+		if r.Percent != want {
+			t.Errorf("quota %s at %.0f%%, want %.0f%%", r.Resource, r.Percent, want)
+		}
+	}
+	if len(usage.Errors) != 0 {
+		t.Errorf("errors = %v", usage.Errors)
+	}
+
+	if w := s.Get("/api/v1/clusters/test/namespaces/missing/usage"); w.Code != http.StatusNotFound {
+		t.Errorf("missing namespace: %d, want 404", w.Code)
+	}
+}
@@ -0,0 +1,68 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ndjsonContentType is the media type of newline-delimited JSON list responses
+	ndjsonContentType = "application/x-ndjson"
+
+	// listPageSize is the number of items requested per API call when streaming a list
+	listPageSize = 500
+)
+
+// wantsNDJSON reports whether the client asked for a streamed list with Accept: application/x-ndjson
+func wantsNDJSON(c *gin.Context) bool {
+	return strings.Contains(c.GetHeader("Accept"), ndjsonContentType)
+}
+
+// streamList writes a list as newline-delimited JSON, one item per line, reading it from
+// the API server page by page so the whole list is never held in memory. fetch returns
+// the items of one page and the continue token of the next.
+// An error before the first item is returned as a regular JSON error; after that the
+// response is already committed, so it is written as a final {"error": ...} line.
+func streamList[T any](c *gin.Context, opts metav1.ListOptions, fetch func(ctx context.Context, opts metav1.ListOptions) ([]T, string, error)) {
+	ctx := c.Request.Context()
+	opts.Limit = listPageSize
+	encoder := json.NewEncoder(c.Writer)
+	started := false
+
+	for {
+		items, next, err := fetch(ctx, opts)
+		if err != nil {
+			log.Errorf("Failed to stream list %s: %v", c.FullPath(), err)
+			if !started {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			} else {
+				encoder.Encode(gin.H{"error": err.Error()})
+			}
+			return
+		}
+
+		if !started {
+			c.Header("Content-Type", ndjsonContentType)
+			c.Status(http.StatusOK)
+			started = true
+		}
+		for i := range items {
+			if err := encoder.Encode(&items[i]); err != nil {
+				// Client went away
+				return
+			}
+		}
+		c.Writer.Flush()
+
+		if next == "" {
+			return
+		}
+		opts.Continue = next
+	}
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestStreamList(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	pages := map[string]struct {
+		items []string
+		next  string
+	}{
+		"":   {items: []string{"a", "b"}, next: "p2"},
+		"p2": {items: []string{"c"}},
+	}
+	fetch := func(ctx context.Context, opts metav1.ListOptions) ([]string, string, error) {
+		if opts.Limit != listPageSize {
+			t.Errorf("Limit = %d, want %d", opts.Limit, listPageSize)
+		}
+		page := pages[opts.Continue]
+		return page.items, page.next, nil
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	streamList(c, metav1.ListOptions{}, fetch)
+
+	if got := w.Body.String(); got != "\"a\"\n\"b\"\n\"c\"\n" {
+		t.Errorf("body = %q", got)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != ndjsonContentType {
+		t.Errorf("Content-Type = %q, want %q", ct, ndjsonContentType)
+	}
+
+	// A failure after the first page is reported in-band
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	streamList(c, metav1.ListOptions{}, func(ctx context.Context, opts metav1.ListOptions) ([]string, string, error) {
+		if opts.Continue == "" {
+			return []string{"a"}, "p2", nil
+		}
+		return nil, "", errors.New("expired")
+	})
+	if w.Code != http.StatusOK || !strings.HasSuffix(w.Body.String(), "{\"error\":\"expired\"}\n") {
+		t.Errorf("status = %d, body = %q", w.Code, w.Body.String())
+	}
+}
@@ -10,11 +10,13 @@ import (
 	"github.com/gin-gonic/gin"
 	log "github.com/sirupsen/logrus"
 	corev1 "k8s.io/api/core/v1"
-	policyv1 "k8s.io/api/policy/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/tools/remotecommand"
+
+	"github.com/sonnguyen/kubelens/internal/cluster"
+	"github.com/sonnguyen/kubelens/internal/policy"
 )
 
 // ============================================================================
@@ -25,7 +27,7 @@ import (
 func (h *Handler) ListNodes(c *gin.Context) {
 	clusterName := c.Param("name")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -46,7 +48,7 @@ func (h *Handler) GetNode(c *gin.Context) {
 	clusterName := c.Param("name")
 	nodeName := c.Param("node")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -67,13 +69,13 @@ func (h *Handler) DeleteNode(c *gin.Context) {
 	clusterName := c.Param("name")
 	nodeName := c.Param("node")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
 
-	err = client.CoreV1().Nodes().Delete(context.Background(), nodeName, metav1.DeleteOptions{})
+	err = client.CoreV1().Nodes().Delete(context.Background(), nodeName, deleteOptions(c))
 	if err != nil {
 		log.Errorf("Failed to delete node: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -93,7 +95,7 @@ func (h *Handler) CordonNode(c *gin.Context) {
 	clusterName := c.Param("name")
 	nodeName := c.Param("node")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -112,7 +114,7 @@ func (h *Handler) CordonNode(c *gin.Context) {
 	}
 
 	node.Spec.Unschedulable = true
-	_, err = client.CoreV1().Nodes().Update(context.Background(), node, metav1.UpdateOptions{})
+	_, err = client.CoreV1().Nodes().Update(context.Background(), node, updateOptions(c))
 	if err != nil {
 		log.Errorf("Failed to cordon node: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -128,7 +130,7 @@ func (h *Handler) UncordonNode(c *gin.Context) {
 	clusterName := c.Param("name")
 	nodeName := c.Param("node")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -147,7 +149,7 @@ func (h *Handler) UncordonNode(c *gin.Context) {
 	}
 
 	node.Spec.Unschedulable = false
-	_, err = client.CoreV1().Nodes().Update(context.Background(), node, metav1.UpdateOptions{})
+	_, err = client.CoreV1().Nodes().Update(context.Background(), node, updateOptions(c))
 	if err != nil {
 		log.Errorf("Failed to uncordon node: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -163,93 +165,30 @@ func (h *Handler) DrainNode(c *gin.Context) {
 	clusterName := c.Param("name")
 	nodeName := c.Param("node")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
 
-	// First, cordon the node
-	node, err := client.CoreV1().Nodes().Get(context.Background(), nodeName, metav1.GetOptions{})
-	if err != nil {
+	if _, err := client.CoreV1().Nodes().Get(context.Background(), nodeName, metav1.GetOptions{}); err != nil {
 		log.Errorf("Failed to get node: %v", err)
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
 
-	if !node.Spec.Unschedulable {
-		node.Spec.Unschedulable = true
-		_, err = client.CoreV1().Nodes().Update(context.Background(), node, metav1.UpdateOptions{})
-		if err != nil {
-			log.Errorf("Failed to cordon node before drain: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to cordon node: %v", err)})
-			return
-		}
-	}
-
-	// Get all pods on this node
-	pods, err := client.CoreV1().Pods(metav1.NamespaceAll).List(context.Background(), metav1.ListOptions{
-		FieldSelector: fmt.Sprintf("spec.nodeName=%s", nodeName),
-	})
+	result, err := cluster.DrainNode(context.Background(), client, nodeName)
 	if err != nil {
-		log.Errorf("Failed to list pods on node: %v", err)
+		log.Errorf("Failed to drain node: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	evictedCount := 0
-	failedCount := 0
-	skippedCount := 0
-
-	// Evict each pod
-	for _, pod := range pods.Items {
-		// Skip DaemonSet pods (they can't be evicted)
-		if pod.OwnerReferences != nil {
-			isDaemonSet := false
-			for _, owner := range pod.OwnerReferences {
-				if owner.Kind == "DaemonSet" {
-					isDaemonSet = true
-					break
-				}
-			}
-			if isDaemonSet {
-				skippedCount++
-				continue
-			}
-		}
-
-		// Skip pods that are already terminating
-		if pod.DeletionTimestamp != nil {
-			skippedCount++
-			continue
-		}
-
-		// Create eviction
-		eviction := &policyv1.Eviction{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:      pod.Name,
-				Namespace: pod.Namespace,
-			},
-			DeleteOptions: &metav1.DeleteOptions{
-				GracePeriodSeconds: pod.Spec.TerminationGracePeriodSeconds,
-			},
-		}
-
-		err := client.CoreV1().Pods(pod.Namespace).EvictV1(context.Background(), eviction)
-		if err != nil {
-			log.Warnf("Failed to evict pod %s/%s: %v", pod.Namespace, pod.Name, err)
-			failedCount++
-		} else {
-			evictedCount++
-		}
-	}
-
-	log.Infof("Drained node %s: %d evicted, %d failed, %d skipped (DaemonSets)", nodeName, evictedCount, failedCount, skippedCount)
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Node drain initiated",
-		"evicted": evictedCount,
-		"failed":  failedCount,
-		"skipped": skippedCount,
+		"evicted": result.Evicted,
+		"failed":  result.Failed,
+		"skipped": result.Skipped,
 	})
 }
 
@@ -265,14 +204,14 @@ func (h *Handler) NodeShell(c *gin.Context) {
 
 	log.Infof("Node shell request: cluster=%s, node=%s, shell=%s", clusterName, nodeName, shellPath)
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		log.Errorf("Failed to get client: %v", err)
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
 
-	restConfig, err := h.clusterManager.GetConfig(clusterName)
+	restConfig, err := h.restConfig(c, clusterName)
 	if err != nil {
 		log.Errorf("Failed to get config: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get cluster config"})
@@ -292,6 +231,11 @@ func (h *Handler) NodeShell(c *gin.Context) {
 
 	log.Infof("Node found: %s", node.Name)
 
+	if err := h.shellPolicy.CheckNodeShell(c); err != nil {
+		policy.Deny(c, err.(*policy.ShellDenied))
+		return
+	}
+
 	// Upgrade HTTP connection to WebSocket
 	ws, err := upgrader.Upgrade(c.Writer, c.Request, nil)
 	if err != nil {
@@ -504,7 +448,7 @@ func (h *Handler) NodeShell(c *gin.Context) {
 
 	// Execute shell in the debug pod
 	// Use -l flag for login shell to properly load shell configuration
-	command := []string{shellPath, "-l"}
+	command := h.shellPolicy.Command(shellPath, "-l")
 
 	log.Infof("Creating executor with command: %v", command)
 
@@ -533,8 +477,9 @@ func (h *Handler) NodeShell(c *gin.Context) {
 
 	log.Infof("Executor created successfully")
 
-	// Create pipes for stdin/stdout/stderr
-	stdin := &wsReader{conn: ws}
+	// Create pipes for stdin/stdout/stderr; the commands typed are audited
+	shellAudit := h.newShellAudit(c, clusterName, debugNamespace, debugPodName, "shell", nodeName, shellPath)
+	stdin := shellAudit.reader(&wsReader{conn: ws})
 	stdout := &wsWriter{conn: ws}
 	stderr := &wsWriter{conn: ws}
 
@@ -548,6 +493,7 @@ func (h *Handler) NodeShell(c *gin.Context) {
 		Stderr: stderr,
 		Tty:    true,
 	})
+	shellAudit.end(err)
 
 	// Clean up debug pod after shell exits (always delete when connection closes)
 	log.Infof("Shell session ended, cleaning up debug pod...")
@@ -578,14 +524,14 @@ func (h *Handler) NodeDrainInteractive(c *gin.Context) {
 
 	log.Infof("Node drain request: cluster=%s, node=%s, force=%s, gracePeriod=%s", clusterName, nodeName, force, gracePeriod)
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		log.Errorf("Failed to get client: %v", err)
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
 
-	restConfig, err := h.clusterManager.GetConfig(clusterName)
+	restConfig, err := h.restConfig(c, clusterName)
 	if err != nil {
 		log.Errorf("Failed to get config: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get cluster config"})
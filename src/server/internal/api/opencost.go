@@ -0,0 +1,310 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+const (
+	// defaultCostWindow is the allocation window used when none is given
+	defaultCostWindow = "7d"
+	// maxCostItems bounds the allocations returned
+	maxCostItems = 500
+)
+
+// costWindowPattern matches the allocation windows OpenCost and Kubecost accept: a
+// duration (24h, 7d), a named window (today, lastweek, ...) or a start,end pair in
+// RFC 3339 or unix seconds
+var costWindowPattern = regexp.MustCompile(`^(\d{1,4}[mhd]|today|yesterday|week|month|lastweek|lastmonth|[0-9TZ:.+-]{10,30},[0-9TZ:.+-]{10,30})$`)
+
+// costAggregations are the supported aggregations; controller is the workload
+// (Deployment, StatefulSet, ...) owning the pods
+var costAggregations = map[string]bool{
+	"namespace":      true,
+	"controller":     true,
+	"controllerKind": true,
+	"pod":            true,
+	"node":           true,
+	"service":        true,
+}
+
+// opencostSettings are the settings of an OpenCost datasource
+type opencostSettings struct {
+	// API is opencost (default) for OpenCost or the Kubecost cost model itself, or
+	// kubecost for the Kubecost frontend, which serves the cost model under /model
+	API string `json:"api,omitempty"`
+}
+
+func (s *opencostSettings) validate() error {
+	switch s.API {
+	case "":
+		s.API = "opencost"
+	case "opencost", "kubecost":
+	default:
+		return fmt.Errorf("settings.api must be opencost or kubecost")
+	}
+	return nil
+}
+
+// opencostPrefix returns the path the cost model API of a datasource is served under
+func opencostPrefix(ds *db.ClusterDatasource) string {
+	var settings opencostSettings
+	if len(ds.Settings) > 0 {
+		json.Unmarshal(ds.Settings, &settings)
+	}
+	if settings.API == "kubecost" {
+		return "/model"
+	}
+	return ""
+}
+
+// CostAllocation is the cost of a namespace, workload, pod or node over a window
+type CostAllocation struct {
+	Name             string  `json:"name"`
+	Start            string  `json:"start,omitempty"`
+	End              string  `json:"end,omitempty"`
+	CPUCost          float64 `json:"cpuCost"`
+	GPUCost          float64 `json:"gpuCost"`
+	RAMCost          float64 `json:"ramCost"`
+	PVCost           float64 `json:"pvCost"`
+	NetworkCost      float64 `json:"networkCost"`
+	LoadBalancerCost float64 `json:"loadBalancerCost"`
+	SharedCost       float64 `json:"sharedCost"`
+	ExternalCost     float64 `json:"externalCost"`
+	TotalCost        float64 `json:"totalCost"`
+	// Efficiency is the share of the requested CPU and memory cost actually used, from 0
+	// to 1; nil when unknown
+	Efficiency *float64 `json:"efficiency,omitempty"`
+}
+
+func (a *CostAllocation) add(other *CostAllocation) {
+	a.CPUCost += other.CPUCost
+	a.GPUCost += other.GPUCost
+	a.RAMCost += other.RAMCost
+	a.PVCost += other.PVCost
+	a.NetworkCost += other.NetworkCost
+	a.LoadBalancerCost += other.LoadBalancerCost
+	a.SharedCost += other.SharedCost
+	a.ExternalCost += other.ExternalCost
+	a.TotalCost += other.TotalCost
+}
+
+// round rounds the costs to a tenth of a cent, as the cost model reports floating point
+// noise
+func (a *CostAllocation) round() {
+	for _, cost := range []*float64{&a.CPUCost, &a.GPUCost, &a.RAMCost, &a.PVCost, &a.NetworkCost,
+		&a.LoadBalancerCost, &a.SharedCost, &a.ExternalCost, &a.TotalCost} {
+		*cost = math.Round(*cost*1000) / 1000
+	}
+}
+
+// opencostAllocation is an allocation as returned by the cost model
+type opencostAllocation struct {
+	Name   string `json:"name"`
+	Window struct {
+		Start string `json:"start"`
+		End   string `json:"end"`
+	} `json:"window"`
+	CPUCost          float64  `json:"cpuCost"`
+	GPUCost          float64  `json:"gpuCost"`
+	RAMCost          float64  `json:"ramCost"`
+	PVCost           float64  `json:"pvCost"`
+	NetworkCost      float64  `json:"networkCost"`
+	LoadBalancerCost float64  `json:"loadBalancerCost"`
+	SharedCost       float64  `json:"sharedCost"`
+	ExternalCost     float64  `json:"externalCost"`
+	TotalCost        float64  `json:"totalCost"`
+	TotalEfficiency  *float64 `json:"totalEfficiency"`
+}
+
+// parseOpenCostAllocation reads an allocation response: {"code": 200, "data": [sets]} where
+// each set maps names to allocations. The sets (one per step, a single one with
+// accumulate=true) are summed per name. The idle allocation is returned apart.
+func parseOpenCostAllocation(body []byte) ([]CostAllocation, *CostAllocation, error) {
+	var resp struct {
+		Code    int                              `json:"code"`
+		Message string                           `json:"message"`
+		Data    []map[string]*opencostAllocation `json:"data"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, nil, fmt.Errorf("invalid cost allocation response: %v", err)
+	}
+	if resp.Code != 0 && resp.Code != http.StatusOK {
+		return nil, nil, fmt.Errorf("cost model returned code %d: %s", resp.Code, resp.Message)
+	}
+
+	byName := map[string]*CostAllocation{}
+	var idle *CostAllocation
+	for _, set := range resp.Data {
+		for key, a := range set {
+			if a == nil {
+				continue
+			}
+			name := a.Name
+			if name == "" {
+				name = key
+			}
+			allocation := &CostAllocation{
+				Name:             name,
+				Start:            a.Window.Start,
+				End:              a.Window.End,
+				CPUCost:          a.CPUCost,
+				GPUCost:          a.GPUCost,
+				RAMCost:          a.RAMCost,
+				PVCost:           a.PVCost,
+				NetworkCost:      a.NetworkCost,
+				LoadBalancerCost: a.LoadBalancerCost,
+				SharedCost:       a.SharedCost,
+				ExternalCost:     a.ExternalCost,
+				TotalCost:        a.TotalCost,
+				Efficiency:       a.TotalEfficiency,
+			}
+			if name == "__idle__" {
+				if idle == nil {
+					idle = allocation
+				} else {
+					idle.add(allocation)
+					idle.End = allocation.End
+				}
+				continue
+			}
+			if existing := byName[name]; existing != nil {
+				existing.add(allocation)
+				existing.End = allocation.End
+				existing.Efficiency = nil
+				continue
+			}
+			byName[name] = allocation
+		}
+	}
+
+	allocations := make([]CostAllocation, 0, len(byName))
+	for _, allocation := range byName {
+		allocation.round()
+		allocations = append(allocations, *allocation)
+	}
+	sort.Slice(allocations, func(i, j int) bool {
+		if allocations[i].TotalCost != allocations[j].TotalCost {
+			return allocations[i].TotalCost > allocations[j].TotalCost
+		}
+		return allocations[i].Name < allocations[j].Name
+	})
+	if idle != nil {
+		idle.round()
+	}
+	return allocations, idle, nil
+}
+
+// GetCostAllocation handles GET /clusters/:name/costs and returns the cost allocation of
+// the cluster from its OpenCost (or Kubecost) datasource, most expensive first.
+// Query: window (default 7d; e.g. 24h, 30d, today, lastmonth or start,end), aggregate
+// (namespace (default), controller, controllerKind, pod, node or service), namespace
+// (optional) limits the allocation to a namespace
+func (h *Handler) GetCostAllocation(c *gin.Context) {
+	clusterName := c.Param("name")
+
+	window := c.DefaultQuery("window", defaultCostWindow)
+	if !costWindowPattern.MatchString(window) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid window %q", window)})
+		return
+	}
+	aggregate := c.DefaultQuery("aggregate", "namespace")
+	if !costAggregations[aggregate] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid aggregate %q", aggregate)})
+		return
+	}
+	namespace := c.Query("namespace")
+	if namespace != "" && !prometheusParamPattern.MatchString(namespace) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid namespace %q", namespace)})
+		return
+	}
+
+	ds, err := h.db.GetClusterDatasource(clusterName, "opencost")
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	values := url.Values{}
+	values.Set("window", window)
+	values.Set("aggregate", aggregate)
+	values.Set("accumulate", "true")
+	if namespace != "" {
+		values.Set("filter", "namespace:"+strconv.Quote(namespace))
+	}
+	body, err := h.datasourceGet(c.Request.Context(), ds, opencostPrefix(ds)+"/allocation", values)
+	if err != nil {
+		var dsErr *datasourceError
+		if errors.As(err, &dsErr) && dsErr.status < 500 && dsErr.body != "" {
+			err = fmt.Errorf("cost model: %s", dsErr.body)
+		}
+		log.Warnf("Cost allocation query for cluster %s failed: %v", clusterName, err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+
+	allocations, idle, err := parseOpenCostAllocation(body)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+	total := CostAllocation{Name: "__total__"}
+	for i := range allocations {
+		total.add(&allocations[i])
+		if total.Start == "" || allocations[i].Start < total.Start {
+			total.Start = allocations[i].Start
+		}
+		if allocations[i].End > total.End {
+			total.End = allocations[i].End
+		}
+	}
+	if idle != nil {
+		total.add(idle)
+	}
+	total.round()
+	truncated := len(allocations) > maxCostItems
+	if truncated {
+		allocations = allocations[:maxCostItems]
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"window":    window,
+		"aggregate": aggregate,
+		"namespace": namespace,
+		"items":     allocations,
+		"idle":      idle,
+		"total":     total,
+		"truncated": truncated,
+	})
+}
+
+// validateOpenCostSettings checks the settings of an OpenCost datasource and returns them
+// normalized for storage
+func validateOpenCostSettings(raw json.RawMessage) (db.JSON, error) {
+	var settings opencostSettings
+	if len(raw) > 0 && string(raw) != "null" {
+		decoder := json.NewDecoder(strings.NewReader(string(raw)))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&settings); err != nil {
+			return nil, fmt.Errorf("invalid settings: %v", err)
+		}
+	}
+	if err := settings.validate(); err != nil {
+		return nil, err
+	}
+	normalized, _ := json.Marshal(settings)
+	return db.JSON(normalized), nil
+}
@@ -0,0 +1,66 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/sonnguyen/kubelens/internal/apitest"
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+func TestCostAllocation(t *testing.T) {
+	var gotPath string
+	var gotQuery url.Values
+	kubecost := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotQuery = r.URL.Path, r.URL.Query()
+		w.Write([]byte(`{"code":200,"data":[{
+			"shop":     {"name":"shop","window":{"start":"2024-05-01T00:00:00Z","end":"2024-05-08T00:00:00Z"},"cpuCost":3.2000001,"ramCost":1.1,"totalCost":4.3000001,"totalEfficiency":0.41},
+			"payments": {"name":"payments","window":{"start":"2024-05-01T00:00:00Z","end":"2024-05-08T00:00:00Z"},"cpuCost":7,"ramCost":2.5,"pvCost":0.5,"totalCost":10},
+			"__idle__": {"name":"__idle__","cpuCost":2,"totalCost":2}
+		}]}`))
+	}))
+	defer kubecost.Close()
+
+	s := apitest.New(t)
+	if err := s.DB.CreateCluster(&db.Cluster{Name: apitest.ClusterName, AuthConfig: db.JSON("{}"), Enabled: true}); err != nil {
+		t.Fatal(err)
+	}
+	w := s.Do(http.MethodPut, "/api/v1/clusters/test/datasources/opencost", map[string]interface{}{
+		"url": kubecost.URL, "settings": map[string]string{"api": "kubecost"},
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("save datasource status = %d: %s", w.Code, w.Body)
+	}
+
+	var resp struct {
+		Items []struct {
+			Name      string  `json:"name"`
+			TotalCost float64 `json:"totalCost"`
+		} `json:"items"`
+		Idle  struct{ TotalCost float64 } `json:"idle"`
+		Total struct{ TotalCost float64 } `json:"total"`
+	}
+	w = s.Get("/api/v1/clusters/test/costs?window=lastweek")
+	if w.Code != http.StatusOK {
+		t.Fatalf("costs status = %d: %s", w.Code, w.Body)
+	}
+	apitest.DecodeJSON(t, w, &resp)
+	if gotPath != "/model/allocation" || gotQuery.Get("window") != "lastweek" || gotQuery.Get("aggregate") != "namespace" {
+		t.Errorf("request = %s?%s, want the Kubecost allocation API by namespace", gotPath, gotQuery.Encode())
+	}
+	if len(resp.Items) != 2 || resp.Items[0].Name != "payments" || resp.Items[1].TotalCost != 4.3 {
+		t.Errorf("items = %+v, want payments then shop at 4.3", resp.Items)
+	}
+	if resp.Idle.TotalCost != 2 || resp.Total.TotalCost != 16.3 {
+		t.Errorf("idle = %v, total = %v, want 2 and 16.3", resp.Idle.TotalCost, resp.Total.TotalCost)
+	}
+
+	if w := s.Get("/api/v1/clusters/test/costs?window=1y"); w.Code != http.StatusBadRequest {
+		t.Errorf("invalid window status = %d, want 400", w.Code)
+	}
+	if w := s.Get("/api/v1/clusters/test/costs?aggregate=label"); w.Code != http.StatusBadRequest {
+		t.Errorf("invalid aggregate status = %d, want 400", w.Code)
+	}
+}
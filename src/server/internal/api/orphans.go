@@ -0,0 +1,473 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/sonnguyen/kubelens/internal/audit"
+)
+
+// ==================== Orphaned Resource Handlers ====================
+
+// OwnerCandidate is a controller that could adopt an orphan
+type OwnerCandidate struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+}
+
+// OrphanedResource is a pod or ReplicaSet that was created by a controller but no
+// longer has one, e.g. after its owner was deleted with orphan propagation
+type OrphanedResource struct {
+	Kind            string           `json:"kind"`
+	Name            string           `json:"name"`
+	Namespace       string           `json:"namespace"`
+	Reason          string           `json:"reason"`
+	FormerOwnerKind string           `json:"formerOwnerKind"`
+	Candidates      []OwnerCandidate `json:"candidates"`
+	CreatedAt       metav1.Time      `json:"createdAt"`
+}
+
+// orphanTarget identifies an orphan in adopt and cleanup requests
+type orphanTarget struct {
+	Kind      string `json:"kind" binding:"required"`
+	Namespace string `json:"namespace" binding:"required"`
+	Name      string `json:"name" binding:"required"`
+	OwnerKind string `json:"ownerKind"`
+	OwnerName string `json:"ownerName"`
+}
+
+// ListOrphans scans a namespace (or all namespaces) for orphaned pods and ReplicaSets
+// Query: namespace (optional)
+func (h *Handler) ListOrphans(c *gin.Context) {
+	clusterName := c.Param("name")
+	namespace := c.Query("namespace")
+
+	client, err := h.client(c, clusterName)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	orphans, err := scanOrphans(context.Background(), client, namespace)
+	if err != nil {
+		log.Errorf("Failed to scan for orphaned resources: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"orphans": orphans, "total": len(orphans)})
+}
+
+// AdoptOrphan makes a controller the owner of an orphaned pod or ReplicaSet
+// Body: {"kind": "Pod", "namespace": "default", "name": "web-x", "ownerKind": "ReplicaSet", "ownerName": "web-5d9"}
+func (h *Handler) AdoptOrphan(c *gin.Context) {
+	clusterName := c.Param("name")
+
+	var req orphanTarget
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.OwnerKind == "" || req.OwnerName == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ownerKind and ownerName are required"})
+		return
+	}
+
+	if !formerOwnerKinds(req.Kind)[req.OwnerKind] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("a %s cannot own a %s", req.OwnerKind, req.Kind)})
+		return
+	}
+
+	client, err := h.client(c, clusterName)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	ctx := context.Background()
+
+	orphan, err := getOrphanMeta(ctx, client, req.Kind, req.Namespace, req.Name)
+	if err != nil {
+		writeOrphanError(c, err)
+		return
+	}
+	if metav1.GetControllerOf(orphan) != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("%s %s already has a controller", req.Kind, req.Name)})
+		return
+	}
+
+	owner, selector, err := getOwnerController(ctx, client, req.OwnerKind, req.Namespace, req.OwnerName)
+	if err != nil {
+		writeOrphanError(c, err)
+		return
+	}
+	if !selector.Matches(labels.Set(orphan.GetLabels())) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("%s %s selector does not match %s %s", req.OwnerKind, req.OwnerName, req.Kind, req.Name)})
+		return
+	}
+
+	isController := true
+	refs := append(orphan.GetOwnerReferences(), metav1.OwnerReference{
+		APIVersion:         ownerAPIVersion(req.OwnerKind),
+		Kind:               req.OwnerKind,
+		Name:               owner.GetName(),
+		UID:                owner.GetUID(),
+		Controller:         &isController,
+		BlockOwnerDeletion: &isController,
+	})
+	patch := mergePatch(map[string]interface{}{"metadata": map[string]interface{}{
+		"ownerReferences": refs,
+		"resourceVersion": orphan.GetResourceVersion(),
+	}})
+
+	switch req.Kind {
+	case "Pod":
+		_, err = client.CoreV1().Pods(req.Namespace).Patch(ctx, req.Name, types.MergePatchType, patch, patchOptions(c))
+	case "ReplicaSet":
+		_, err = client.AppsV1().ReplicaSets(req.Namespace).Patch(ctx, req.Name, types.MergePatchType, patch, patchOptions(c))
+	}
+	if err != nil {
+		log.Errorf("Failed to adopt %s %s/%s: %v", req.Kind, req.Namespace, req.Name, err)
+		writeOrphanError(c, err)
+		return
+	}
+
+	h.auditOrphanAction(c, clusterName, "adopted", req)
+	c.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("%s %s adopted by %s %s", req.Kind, req.Name, req.OwnerKind, req.OwnerName)})
+}
+
+// CleanupOrphan deletes an orphaned pod or ReplicaSet (including the ReplicaSet's pods)
+// Body: {"kind": "ReplicaSet", "namespace": "default", "name": "web-5d9"}
+func (h *Handler) CleanupOrphan(c *gin.Context) {
+	clusterName := c.Param("name")
+
+	var req orphanTarget
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	client, err := h.client(c, clusterName)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	ctx := context.Background()
+
+	orphan, err := getOrphanMeta(ctx, client, req.Kind, req.Namespace, req.Name)
+	if err != nil {
+		writeOrphanError(c, err)
+		return
+	}
+	if metav1.GetControllerOf(orphan) != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("%s %s has a controller and is not orphaned", req.Kind, req.Name)})
+		return
+	}
+
+	opts := deleteOptions(c)
+	propagation := metav1.DeletePropagationBackground
+	opts.PropagationPolicy = &propagation
+	opts.Preconditions = &metav1.Preconditions{UID: ptrUID(orphan.GetUID())}
+
+	switch req.Kind {
+	case "Pod":
+		err = client.CoreV1().Pods(req.Namespace).Delete(ctx, req.Name, opts)
+	case "ReplicaSet":
+		err = client.AppsV1().ReplicaSets(req.Namespace).Delete(ctx, req.Name, opts)
+	}
+	if err != nil {
+		log.Errorf("Failed to delete orphaned %s %s/%s: %v", req.Kind, req.Namespace, req.Name, err)
+		writeOrphanError(c, err)
+		return
+	}
+
+	h.auditOrphanAction(c, clusterName, "deleted", req)
+	c.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("Orphaned %s %s deleted", req.Kind, req.Name)})
+}
+
+// scanOrphans finds pods and ReplicaSets that carry the labels their controller sets but
+// have no controller owner reference, and lists the controllers that could adopt them
+func scanOrphans(ctx context.Context, client kubernetes.Interface, namespace string) ([]OrphanedResource, error) {
+	pods, err := client.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	replicaSets, err := client.AppsV1().ReplicaSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	orphans := []OrphanedResource{}
+	var controllers *controllerIndex
+
+	addOrphan := func(kind string, meta metav1.Object, formerOwner, reason string) error {
+		if controllers == nil {
+			var err error
+			if controllers, err = loadControllers(ctx, client, namespace); err != nil {
+				return err
+			}
+		}
+		orphans = append(orphans, OrphanedResource{
+			Kind:            kind,
+			Name:            meta.GetName(),
+			Namespace:       meta.GetNamespace(),
+			Reason:          reason,
+			FormerOwnerKind: formerOwner,
+			Candidates:      controllers.candidates(formerOwner, meta.GetNamespace(), meta.GetLabels()),
+			CreatedAt:       meta.GetCreationTimestamp(),
+		})
+		return nil
+	}
+
+	for i := range replicaSets.Items {
+		rs := &replicaSets.Items[i]
+		if metav1.GetControllerOf(rs) != nil {
+			continue
+		}
+		if _, ok := rs.Labels[appsv1.DefaultDeploymentUniqueLabelKey]; ok {
+			if err := addOrphan("ReplicaSet", rs, "Deployment", "created by a Deployment but has no owner"); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if metav1.GetControllerOf(pod) != nil {
+			continue
+		}
+		formerOwner := podFormerOwnerKind(pod.Labels)
+		if formerOwner == "" {
+			continue
+		}
+		if err := addOrphan("Pod", pod, formerOwner, fmt.Sprintf("created by a %s but no controller manages it", formerOwner)); err != nil {
+			return nil, err
+		}
+	}
+
+	sort.Slice(orphans, func(i, j int) bool {
+		if orphans[i].Namespace != orphans[j].Namespace {
+			return orphans[i].Namespace < orphans[j].Namespace
+		}
+		if orphans[i].Kind != orphans[j].Kind {
+			return orphans[i].Kind < orphans[j].Kind
+		}
+		return orphans[i].Name < orphans[j].Name
+	})
+	return orphans, nil
+}
+
+// podFormerOwnerKind guesses the controller kind that created a pod from the labels it sets
+func podFormerOwnerKind(podLabels map[string]string) string {
+	switch {
+	case podLabels[appsv1.DefaultDeploymentUniqueLabelKey] != "":
+		return "ReplicaSet"
+	case podLabels[appsv1.StatefulSetPodNameLabel] != "":
+		return "StatefulSet"
+	case podLabels[appsv1.ControllerRevisionHashLabelKey] != "":
+		return "DaemonSet"
+	case podLabels["batch.kubernetes.io/job-name"] != "", podLabels["job-name"] != "":
+		return "Job"
+	}
+	return ""
+}
+
+// formerOwnerKinds lists the controller kinds that may own a kind of orphan
+func formerOwnerKinds(kind string) map[string]bool {
+	switch kind {
+	case "Pod":
+		return map[string]bool{"ReplicaSet": true, "StatefulSet": true, "DaemonSet": true, "Job": true}
+	case "ReplicaSet":
+		return map[string]bool{"Deployment": true}
+	}
+	return map[string]bool{}
+}
+
+// ownerAPIVersion returns the API version used in owner references to a controller kind
+func ownerAPIVersion(kind string) string {
+	if kind == "Job" {
+		return "batch/v1"
+	}
+	return "apps/v1"
+}
+
+// controllerIndex holds the selectors of the controllers in scope of a scan
+type controllerIndex struct {
+	entries []controllerEntry
+}
+
+type controllerEntry struct {
+	kind      string
+	name      string
+	namespace string
+	selector  labels.Selector
+}
+
+// loadControllers lists the controllers that can adopt orphans
+func loadControllers(ctx context.Context, client kubernetes.Interface, namespace string) (*controllerIndex, error) {
+	index := &controllerIndex{}
+	add := func(kind string, meta metav1.Object, selector *metav1.LabelSelector) {
+		s, err := metav1.LabelSelectorAsSelector(selector)
+		if err != nil || s.Empty() {
+			return
+		}
+		index.entries = append(index.entries, controllerEntry{kind: kind, name: meta.GetName(), namespace: meta.GetNamespace(), selector: s})
+	}
+
+	deployments, err := client.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for i := range deployments.Items {
+		add("Deployment", &deployments.Items[i], deployments.Items[i].Spec.Selector)
+	}
+	replicaSets, err := client.AppsV1().ReplicaSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for i := range replicaSets.Items {
+		add("ReplicaSet", &replicaSets.Items[i], replicaSets.Items[i].Spec.Selector)
+	}
+	statefulSets, err := client.AppsV1().StatefulSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for i := range statefulSets.Items {
+		add("StatefulSet", &statefulSets.Items[i], statefulSets.Items[i].Spec.Selector)
+	}
+	daemonSets, err := client.AppsV1().DaemonSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for i := range daemonSets.Items {
+		add("DaemonSet", &daemonSets.Items[i], daemonSets.Items[i].Spec.Selector)
+	}
+	jobs, err := client.BatchV1().Jobs(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for i := range jobs.Items {
+		add("Job", &jobs.Items[i], jobs.Items[i].Spec.Selector)
+	}
+
+	return index, nil
+}
+
+// candidates returns the controllers of a kind whose selector matches the given labels
+func (idx *controllerIndex) candidates(kind, namespace string, objLabels map[string]string) []OwnerCandidate {
+	candidates := []OwnerCandidate{}
+	for _, e := range idx.entries {
+		if e.kind == kind && e.namespace == namespace && e.selector.Matches(labels.Set(objLabels)) {
+			candidates = append(candidates, OwnerCandidate{Kind: e.kind, Name: e.name})
+		}
+	}
+	return candidates
+}
+
+// getOrphanMeta fetches a pod or ReplicaSet
+func getOrphanMeta(ctx context.Context, client kubernetes.Interface, kind, namespace, name string) (metav1.Object, error) {
+	switch kind {
+	case "Pod":
+		return client.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+	case "ReplicaSet":
+		return client.AppsV1().ReplicaSets(namespace).Get(ctx, name, metav1.GetOptions{})
+	}
+	return nil, apierrors.NewBadRequest(fmt.Sprintf("unsupported kind %q, expected Pod or ReplicaSet", kind))
+}
+
+// getOwnerController fetches a controller and its selector
+func getOwnerController(ctx context.Context, client kubernetes.Interface, kind, namespace, name string) (metav1.Object, labels.Selector, error) {
+	var owner metav1.Object
+	var selector *metav1.LabelSelector
+
+	switch kind {
+	case "Deployment":
+		d, err := client.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, nil, err
+		}
+		owner, selector = d, d.Spec.Selector
+	case "ReplicaSet":
+		rs, err := client.AppsV1().ReplicaSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, nil, err
+		}
+		owner, selector = rs, rs.Spec.Selector
+	case "StatefulSet":
+		sts, err := client.AppsV1().StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, nil, err
+		}
+		owner, selector = sts, sts.Spec.Selector
+	case "DaemonSet":
+		ds, err := client.AppsV1().DaemonSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, nil, err
+		}
+		owner, selector = ds, ds.Spec.Selector
+	case "Job":
+		job, err := client.BatchV1().Jobs(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, nil, err
+		}
+		owner, selector = job, job.Spec.Selector
+	default:
+		return nil, nil, apierrors.NewBadRequest(fmt.Sprintf("unsupported owner kind %q", kind))
+	}
+
+	s, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return nil, nil, apierrors.NewBadRequest(fmt.Sprintf("invalid selector on %s %s: %v", kind, name, err))
+	}
+	return owner, s, nil
+}
+
+// writeOrphanError maps Kubernetes API errors to HTTP responses
+func writeOrphanError(c *gin.Context, err error) {
+	switch {
+	case apierrors.IsNotFound(err):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case apierrors.IsBadRequest(err):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case apierrors.IsConflict(err):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+func (h *Handler) auditOrphanAction(c *gin.Context, clusterName, action string, req orphanTarget) {
+	if userID, exists := c.Get("user_id"); exists {
+		username, _ := c.Get("username")
+		email, _ := c.Get("email")
+
+		event := audit.EventAuditResourceUpdated
+		if action == "deleted" {
+			event = audit.EventAuditResourceDeleted
+		}
+		audit.Log(c, event, userID.(int), username.(string), email.(string),
+			fmt.Sprintf("Orphaned %s %s/%s %s", req.Kind, req.Namespace, req.Name, action),
+			map[string]interface{}{
+				"cluster_name": clusterName,
+				"namespace":    req.Namespace,
+				"kind":         req.Kind,
+				"name":         req.Name,
+				"owner_kind":   req.OwnerKind,
+				"owner_name":   req.OwnerName,
+			})
+	}
+}
+
+func ptrUID(uid types.UID) *types.UID {
+	return &uid
+}
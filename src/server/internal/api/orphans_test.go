@@ -0,0 +1,54 @@
+package api
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestScanOrphans(t *testing.T) {
+	isController := true
+	selector := &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}
+	client := fake.NewSimpleClientset(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec:       appsv1.DeploymentSpec{Selector: selector},
+		},
+		// ReplicaSet left behind after its Deployment was deleted with orphan propagation
+		&appsv1.ReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-5d9", Namespace: "default", Labels: map[string]string{"app": "web", "pod-template-hash": "5d9"}},
+			Spec:       appsv1.ReplicaSetSpec{Selector: selector},
+		},
+		// Managed pod
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name: "web-5d9-a", Namespace: "default", Labels: map[string]string{"app": "web", "pod-template-hash": "5d9"},
+			OwnerReferences: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "web-5d9", Controller: &isController}},
+		}},
+		// Pod whose Job is gone
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name: "migrate-x", Namespace: "default", Labels: map[string]string{"batch.kubernetes.io/job-name": "migrate"},
+		}},
+		// Bare pod, never had a controller
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "debug", Namespace: "default"}},
+	)
+
+	orphans, err := scanOrphans(context.Background(), client, "")
+	if err != nil {
+		t.Fatalf("scanOrphans() error = %v", err)
+	}
+	if len(orphans) != 2 {
+		t.Fatalf("scanOrphans() = %+v, want 2 orphans", orphans)
+	}
+
+	if o := orphans[0]; o.Kind != "Pod" || o.Name != "migrate-x" || o.FormerOwnerKind != "Job" || len(o.Candidates) != 0 {
+		t.Errorf("orphans[0] = %+v, want pod migrate-x from a Job without candidates", o)
+	}
+	if o := orphans[1]; o.Kind != "ReplicaSet" || o.FormerOwnerKind != "Deployment" ||
+		len(o.Candidates) != 1 || o.Candidates[0].Name != "web" {
+		t.Errorf("orphans[1] = %+v, want ReplicaSet web-5d9 adoptable by Deployment web", o)
+	}
+}
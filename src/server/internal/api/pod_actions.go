@@ -8,7 +8,7 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
-	"sync"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
@@ -17,6 +17,9 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/tools/remotecommand"
+
+	"github.com/sonnguyen/kubelens/internal/diagnostics"
+	"github.com/sonnguyen/kubelens/internal/policy"
 )
 
 var upgrader = websocket.Upgrader{
@@ -33,7 +36,7 @@ func (h *Handler) UpdatePod(c *gin.Context) {
 	clusterName := c.Param("name")
 	namespace := c.Param("namespace")
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -46,7 +49,7 @@ func (h *Handler) UpdatePod(c *gin.Context) {
 	}
 
 	ctx := context.Background()
-	updatedPod, err := client.CoreV1().Pods(namespace).Update(ctx, &pod, metav1.UpdateOptions{})
+	updatedPod, err := client.CoreV1().Pods(namespace).Update(ctx, &pod, updateOptions(c))
 	if err != nil {
 		log.Errorf("Failed to update pod: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -56,41 +59,67 @@ func (h *Handler) UpdatePod(c *gin.Context) {
 	c.JSON(http.StatusOK, updatedPod)
 }
 
-// PodLogsStream handles WebSocket connection for real-time log streaming
+// PodLogsStream handles WebSocket connection for real-time log streaming. Clients that
+// cannot use WebSockets get server-sent events with ?transport=sse. match, invert and level
+// filter the lines (parseLogFilter).
+// container, or initContainer, picks the container (default: the pod's default container);
+// previous=true sends the log of its previous instance, e.g. the one that crashed. When the
+// log ends an "end" notice tells why: complete (previous or follow=false), terminated (with
+// the exitCode of the container), restarted, pod_deleted, closed or error. structured=true
+// sends each line parsed as a record (logRecord) instead of text.
 func (h *Handler) PodLogsStream(c *gin.Context) {
 	clusterName := c.Param("name")
 	namespace := c.Param("namespace")
 	podName := c.Param("pod")
 	container := c.Query("container")
+	initContainer := c.Query("initContainer")
+	previous := c.Query("previous") == "true"
+	structured := c.Query("structured") == "true"
 	tailLines := c.DefaultQuery("tailLines", "100")
-	follow := c.DefaultQuery("follow", "true")
+	follow := c.DefaultQuery("follow", "true") == "true" && !previous
+
+	log.Infof("Log stream request: cluster=%s, namespace=%s, pod=%s, container=%s, initContainer=%s, previous=%v", clusterName, namespace, podName, container, initContainer, previous)
 
-	log.Infof("Log stream request: cluster=%s, namespace=%s, pod=%s, container=%s", clusterName, namespace, podName, container)
+	filter, err := parseLogFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		log.Errorf("Failed to get client: %v", err)
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Upgrade HTTP connection to WebSocket
-	ws, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	pod, err := client.CoreV1().Pods(namespace).Get(c.Request.Context(), podName, metav1.GetOptions{})
 	if err != nil {
-		log.Errorf("Failed to upgrade WebSocket: %v", err)
+		log.Errorf("Failed to get pod: %v", err)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Pod not found"})
 		return
 	}
-	defer ws.Close()
+	container, err = logContainer(pod, container, initContainer)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	status, _ := containerStatus(pod, container)
 
-	log.Infof("WebSocket upgraded successfully for log streaming")
+	// Upgrade HTTP connection to WebSocket, or start server-sent events
+	sink := openLogSink(c)
+	if sink == nil {
+		return
+	}
+	defer sink.close()
+
+	log.Infof("Connection opened successfully for log streaming")
 
 	// Build log options
 	logOptions := &corev1.PodLogOptions{
-		Follow: follow == "true",
-	}
-	
-	if container != "" {
-		logOptions.Container = container
+		Container: container,
+		Follow:    follow,
+		Previous:  previous,
 	}
 	
 	if tailLines != "" {
@@ -99,163 +128,192 @@ func (h *Handler) PodLogsStream(c *gin.Context) {
 		}
 	}
 
-	ctx := context.Background()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-sink.done()
+		cancel()
+	}()
 
 	// Get logs stream
 	req := client.CoreV1().Pods(namespace).GetLogs(podName, logOptions)
 	stream, err := req.Stream(ctx)
 	if err != nil {
 		log.Errorf("Failed to get log stream: %v", err)
-		ws.WriteJSON(map[string]string{"error": err.Error()})
+		sink.fail(map[string]string{"error": err.Error()})
 		return
 	}
 	defer stream.Close()
+	defer diagnostics.TrackWatch("pod_logs")()
 
 	log.Infof("Log stream started successfully")
 
-	// Stream logs to WebSocket
-	buf := make([]byte, 4096)
-	for {
-		n, err := stream.Read(buf)
-		if n > 0 {
-			// Send log chunk to WebSocket
-			if err := ws.WriteMessage(websocket.TextMessage, buf[:n]); err != nil {
-				log.Errorf("Failed to write to WebSocket: %v", err)
+	var readErr error
+	if filter != nil || structured {
+		// Filtered logs are sent a line at a time, the kept ones only, and parsed logs a
+		// record at a time
+		keep := func(string) bool { return true }
+		if filter != nil {
+			keep = filter.lines()
+		}
+		scanner := bufio.NewScanner(stream)
+		scanner.Buffer(make([]byte, 64*1024), maxLogLine)
+		for scanner.Scan() {
+			line := scanner.Text()
+			if !keep(line) {
+				continue
+			}
+			if structured {
+				record := parseLogRecord(line, false)
+				record.Pod, record.Container = podName, container
+				err = sink.record(record)
+			} else {
+				err = sink.write([]byte(line + "\n"))
+			}
+			if err != nil {
+				log.Errorf("Failed to write log stream: %v", err)
 				return
 			}
 		}
-		
-		if err != nil {
-			if err == io.EOF {
-				log.Infof("Log stream ended (EOF)")
-				return
+		readErr = scanner.Err()
+	} else {
+		// Stream logs to the client
+		buf := make([]byte, 4096)
+		for {
+			n, err := stream.Read(buf)
+			if n > 0 {
+				// Send log chunk to the client
+				if err := sink.write(buf[:n]); err != nil {
+					log.Errorf("Failed to write log stream: %v", err)
+					return
+				}
+			}
+			if err != nil {
+				if err != io.EOF {
+					readErr = err
+				}
+				break
 			}
-			log.Errorf("Error reading log stream: %v", err)
-			return
 		}
 	}
+	if ctx.Err() != nil {
+		return
+	}
+
+	// Tell the client why the log ended
+	var end map[string]interface{}
+	switch {
+	case readErr != nil:
+		log.Errorf("Error reading log stream: %v", readErr)
+		end = map[string]interface{}{"podName": podName, "container": container, "reason": "error", "error": readErr.Error()}
+	case !follow:
+		log.Infof("Log stream ended (EOF)")
+		end = map[string]interface{}{"podName": podName, "container": container, "reason": "complete", "previous": previous}
+	default:
+		log.Infof("Log stream ended (EOF)")
+		end = logStreamEnd(ctx, client, namespace, podName, container, status.ContainerID)
+	}
+	sink.notice("end", end)
 }
 
-// MultiPodLogsStream handles WebSocket connection for real-time log streaming from multiple pods
+// MultiPodLogsStream handles WebSocket connection for real-time log streaming from multiple pods.
+// Clients that cannot use WebSockets get server-sent events with ?transport=sse. The lines
+// are filtered like those of PodLogsStream.
+// Instead of a list of pods, ?selector= (a label selector) or ?workload=<kind>/<name> streams
+// the logs of the matching pods, attaching to new pods as they start (podFollower).
+// structured=true sends records like PodLogsStream.
 func (h *Handler) MultiPodLogsStream(c *gin.Context) {
 	clusterName := c.Param("name")
 	namespace := c.Param("namespace")
 	pods := c.QueryArray("pods")
+	selector := c.Query("selector")
+	workload := c.Query("workload")
 	container := c.Query("container")
 	tailLines := c.DefaultQuery("tailLines", "100")
 	timestamps := c.Query("timestamps") == "true"
+	structured := c.Query("structured") == "true"
 
-	log.Infof("Multi-pod log stream request: cluster=%s, namespace=%s, pods=%v, timestamps=%v", clusterName, namespace, pods, timestamps)
+	log.Infof("Multi-pod log stream request: cluster=%s, namespace=%s, pods=%v, selector=%q, workload=%q, timestamps=%v", clusterName, namespace, pods, selector, workload, timestamps)
 
-	if len(pods) == 0 {
+	following := selector != "" || workload != ""
+	if len(pods) == 0 && !following {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "No pods specified"})
 		return
 	}
+	if len(pods) > 0 && following {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "give pods or a selector, not both"})
+		return
+	}
+	filter, err := parseLogFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		log.Errorf("Failed to get client: %v", err)
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Upgrade HTTP connection to WebSocket
-	ws, err := upgrader.Upgrade(c.Writer, c.Request, nil)
-	if err != nil {
-		log.Errorf("Failed to upgrade WebSocket: %v", err)
+	var tail *int64
+	if tailLines != "" {
+		if lines, err := strconv.ParseInt(tailLines, 10, 64); err == nil {
+			tail = &lines
+		}
+	}
+	if following {
+		selector, err = podSelector(c.Request.Context(), client, namespace, selector, workload)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	// Upgrade HTTP connection to WebSocket, or start server-sent events
+	sink := openLogSink(c)
+	if sink == nil {
 		return
 	}
-	defer ws.Close()
+	defer sink.close()
 
-	log.Infof("WebSocket upgraded successfully for multi-pod log streaming")
+	log.Infof("Connection opened successfully for multi-pod log streaming")
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	// Mutex to synchronize WebSocket writes from multiple goroutines
-	var wsMutex sync.Mutex
+	if following {
+		follower := &podFollower{
+			client:     client,
+			sink:       sink,
+			namespace:  namespace,
+			selector:   selector,
+			container:  container,
+			tailLines:  tail,
+			timestamps: timestamps,
+			structured: structured,
+			filter:     filter,
+			streams:    map[string]context.CancelFunc{},
+			ended:      map[string]time.Time{},
+			pods:       map[string]bool{},
+		}
+		go follower.run(ctx)
+	}
 
-	// Stream logs from all pods concurrently
+	// Stream logs from all pods concurrently, each line prefixed with the pod name
 	for _, podName := range pods {
-		go func(pod string) {
-			// Build log options
-			logOptions := &corev1.PodLogOptions{
-				Follow:     true,
-				Timestamps: timestamps,
-			}
-			
-			if container != "" {
-				logOptions.Container = container
-			}
-			
-			if tailLines != "" {
-				if lines, err := strconv.ParseInt(tailLines, 10, 64); err == nil {
-					logOptions.TailLines = &lines
-				}
-			}
-
-			// Get logs stream
-			req := client.CoreV1().Pods(namespace).GetLogs(pod, logOptions)
-			stream, err := req.Stream(ctx)
-			if err != nil {
-				log.Errorf("Failed to get log stream for pod %s: %v", pod, err)
-				wsMutex.Lock()
-				ws.WriteJSON(map[string]string{
-					"podName": pod,
-					"error":   err.Error(),
-				})
-				wsMutex.Unlock()
-				return
-			}
-			defer stream.Close()
-
-		log.Infof("Log stream started for pod: %s", pod)
-
-		// Stream logs to WebSocket with pod name prefix
-		// Use bufio.Scanner to read line by line
-		scanner := bufio.NewScanner(stream)
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			default:
-				if scanner.Scan() {
-					logLine := scanner.Text()
-					// Prefix each log line with pod name
-					prefixedLog := fmt.Sprintf("[%s] %s\n", pod, logLine)
-					
-					// Send log line to WebSocket with mutex protection
-					wsMutex.Lock()
-					err := ws.WriteMessage(websocket.TextMessage, []byte(prefixedLog))
-					wsMutex.Unlock()
-					
-					if err != nil {
-						log.Errorf("Failed to write to WebSocket: %v", err)
-						return
-					}
-				} else {
-					// Check for errors
-					if err := scanner.Err(); err != nil {
-						log.Errorf("Error reading log stream for pod %s: %v", pod, err)
-					} else {
-						log.Infof("Log stream ended for pod %s (EOF)", pod)
-					}
-					return
-				}
-			}
+		logOptions := &corev1.PodLogOptions{
+			Container:  container,
+			Follow:     true,
+			Timestamps: timestamps,
+			TailLines:  tail,
 		}
-		}(podName)
+		go podLogStream(ctx, client, sink, namespace, podName, podName, logOptions, filter, structured)
 	}
 
 	// Keep connection alive until client disconnects
-	for {
-		_, _, err := ws.ReadMessage()
-		if err != nil {
-			log.Infof("WebSocket closed: %v", err)
-			cancel()
-			return
-		}
-	}
+	<-sink.done()
 }
 
 // PodShell handles WebSocket connection for pod shell access
@@ -268,14 +326,14 @@ func (h *Handler) PodShell(c *gin.Context) {
 
 	log.Infof("Shell request: cluster=%s, namespace=%s, pod=%s, container=%s, shell=%s", clusterName, namespace, podName, container, shellPath)
 
-	client, err := h.clusterManager.GetClient(clusterName)
+	client, err := h.client(c, clusterName)
 	if err != nil {
 		log.Errorf("Failed to get client: %v", err)
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
 
-	restConfig, err := h.clusterManager.GetConfig(clusterName)
+	restConfig, err := h.restConfig(c, clusterName)
 	if err != nil {
 		log.Errorf("Failed to get config: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get cluster config"})
@@ -299,6 +357,11 @@ func (h *Handler) PodShell(c *gin.Context) {
 
 	log.Infof("Using container: %s", container)
 
+	if err := h.shellPolicy.CheckPodShell(c, namespace, pod.Labels); err != nil {
+		policy.Deny(c, err.(*policy.ShellDenied))
+		return
+	}
+
 	// Upgrade HTTP connection to WebSocket
 	ws, err := upgrader.Upgrade(c.Writer, c.Request, nil)
 	if err != nil {
@@ -320,7 +383,7 @@ func (h *Handler) PodShell(c *gin.Context) {
 		SubResource("exec").
 		VersionedParams(&corev1.PodExecOptions{
 			Container: container,
-			Command:   []string{shellPath},
+			Command:   h.shellPolicy.Command(shellPath),
 			Stdin:     true,
 			Stdout:    true,
 			Stderr:    true,
@@ -336,8 +399,9 @@ func (h *Handler) PodShell(c *gin.Context) {
 
 	log.Infof("Executor created successfully with shell: %s", shellPath)
 
-	// Create pipes for stdin/stdout/stderr
-	stdin := &wsReader{conn: ws}
+	// Create pipes for stdin/stdout/stderr; the commands typed are audited
+	shellAudit := h.newShellAudit(c, clusterName, namespace, podName, container, "", shellPath)
+	stdin := shellAudit.reader(&wsReader{conn: ws})
 	stdout := &wsWriter{conn: ws}
 	stderr := &wsWriter{conn: ws}
 
@@ -350,6 +414,7 @@ func (h *Handler) PodShell(c *gin.Context) {
 		Stderr: stderr,
 		Tty:    true,
 	})
+	shellAudit.end(err)
 
 	if err != nil {
 		log.Errorf("Shell execution error: %v", err)
@@ -0,0 +1,308 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// defaultPrometheusRange is the range queried when start is not given
+	defaultPrometheusRange = time.Hour
+	// maxPrometheusRange bounds the range of a query
+	maxPrometheusRange = 30 * 24 * time.Hour
+	// maxPrometheusPoints bounds the number of points per series; a smaller step is raised
+	maxPrometheusPoints = 1000
+	// minPrometheusStep is the smallest step, about one scrape interval
+	minPrometheusStep = 15 * time.Second
+)
+
+// prometheusQuery is a curated PromQL query. Expr references parameters as {{name}};
+// {{rate}} is the rate window derived from the step.
+type prometheusQuery struct {
+	Name   string   `json:"name"`
+	Title  string   `json:"title"`
+	Unit   string   `json:"unit"` // cores, bytes or bytes/s
+	Params []string `json:"params"`
+	Expr   string   `json:"-"`
+}
+
+// prometheusQueries are the queries served by /clusters/:name/prometheus/query_range. They
+// use the cAdvisor metrics scraped from the kubelet by a standard Prometheus setup
+// (kube-prometheus-stack, prometheus-community chart).
+var prometheusQueries = []prometheusQuery{
+	{
+		Name: "pod_cpu", Title: "Pod CPU usage by container", Unit: "cores", Params: []string{"namespace", "pod"},
+		Expr: `sum by (container) (rate(container_cpu_usage_seconds_total{namespace="{{namespace}}",pod="{{pod}}",container!="",container!="POD"}[{{rate}}]))`,
+	},
+	{
+		Name: "pod_memory", Title: "Pod memory working set by container", Unit: "bytes", Params: []string{"namespace", "pod"},
+		Expr: `sum by (container) (container_memory_working_set_bytes{namespace="{{namespace}}",pod="{{pod}}",container!="",container!="POD"})`,
+	},
+	{
+		Name: "pod_network_receive", Title: "Pod network receive", Unit: "bytes/s", Params: []string{"namespace", "pod"},
+		Expr: `sum by (interface) (rate(container_network_receive_bytes_total{namespace="{{namespace}}",pod="{{pod}}"}[{{rate}}]))`,
+	},
+	{
+		Name: "pod_network_transmit", Title: "Pod network transmit", Unit: "bytes/s", Params: []string{"namespace", "pod"},
+		Expr: `sum by (interface) (rate(container_network_transmit_bytes_total{namespace="{{namespace}}",pod="{{pod}}"}[{{rate}}]))`,
+	},
+	{
+		Name: "namespace_cpu", Title: "Namespace CPU usage by pod", Unit: "cores", Params: []string{"namespace"},
+		Expr: `sum by (pod) (rate(container_cpu_usage_seconds_total{namespace="{{namespace}}",container!="",container!="POD"}[{{rate}}]))`,
+	},
+	{
+		Name: "namespace_memory", Title: "Namespace memory working set by pod", Unit: "bytes", Params: []string{"namespace"},
+		Expr: `sum by (pod) (container_memory_working_set_bytes{namespace="{{namespace}}",container!="",container!="POD"})`,
+	},
+}
+
+// prometheusParamPattern matches parameter values: Kubernetes namespace and pod names
+var prometheusParamPattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9.]{0,251}[a-z0-9])?$`)
+
+// PrometheusSeries is one series of a range query
+type PrometheusSeries struct {
+	Labels map[string]string `json:"labels"`
+	// Values are [unix seconds, value] pairs; NaN and infinite samples are left out
+	Values [][2]float64 `json:"values"`
+}
+
+// ListPrometheusQueries handles GET /clusters/:name/prometheus/queries
+func (h *Handler) ListPrometheusQueries(c *gin.Context) {
+	_, err := h.db.GetClusterDatasource(c.Param("name"), "prometheus")
+	c.JSON(http.StatusOK, gin.H{
+		"configured": err == nil,
+		"queries":    prometheusQueries,
+	})
+}
+
+// PrometheusQueryRange handles GET /clusters/:name/prometheus/query_range and runs a curated
+// query against the Prometheus datasource of the cluster.
+// Query: query (name of a curated query), its params (namespace, pod), start and end (RFC 3339
+// or unix seconds; default: the last hour), step (duration; default: range/250)
+func (h *Handler) PrometheusQueryRange(c *gin.Context) {
+	clusterName := c.Param("name")
+
+	query := findPrometheusQuery(c.Query("query"))
+	if query == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("unknown query %q", c.Query("query"))})
+		return
+	}
+	start, end, step, err := prometheusRange(c.Query("start"), c.Query("end"), c.Query("step"), time.Now())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	params := map[string]string{}
+	for _, name := range query.Params {
+		params[name] = c.Query(name)
+	}
+	expr, err := renderPrometheusQuery(query, params, step)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ds, err := h.db.GetClusterDatasource(clusterName, "prometheus")
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	values := url.Values{}
+	values.Set("query", expr)
+	values.Set("start", strconv.FormatInt(start.Unix(), 10))
+	values.Set("end", strconv.FormatInt(end.Unix(), 10))
+	values.Set("step", strconv.FormatFloat(step.Seconds(), 'f', -1, 64))
+	body, err := h.datasourceGet(c.Request.Context(), ds, "/api/v1/query_range", values)
+	if err != nil {
+		var dsErr *datasourceError
+		if errors.As(err, &dsErr) && dsErr.status < 500 {
+			// Prometheus returns 400/422 with a JSON error for bad queries
+			if message := prometheusError(body); message != "" {
+				err = fmt.Errorf("prometheus: %s", message)
+			}
+		}
+		log.Warnf("Prometheus query %s for cluster %s failed: %v", query.Name, clusterName, err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+
+	series, err := parsePrometheusMatrix(body)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"query":  query.Name,
+		"title":  query.Title,
+		"unit":   query.Unit,
+		"start":  start.Unix(),
+		"end":    end.Unix(),
+		"step":   step.Seconds(),
+		"series": series,
+	})
+}
+
+func findPrometheusQuery(name string) *prometheusQuery {
+	for i := range prometheusQueries {
+		if prometheusQueries[i].Name == name {
+			return &prometheusQueries[i]
+		}
+	}
+	return nil
+}
+
+// renderPrometheusQuery fills in the parameters of a query. Values must be Kubernetes
+// names, so they cannot break out of the label matchers.
+func renderPrometheusQuery(query *prometheusQuery, params map[string]string, step time.Duration) (string, error) {
+	expr := query.Expr
+	for _, name := range query.Params {
+		value := params[name]
+		if value == "" {
+			return "", fmt.Errorf("%s is required for query %s", name, query.Name)
+		}
+		if !prometheusParamPattern.MatchString(value) {
+			return "", fmt.Errorf("invalid %s %q", name, value)
+		}
+		expr = strings.ReplaceAll(expr, "{{"+name+"}}", value)
+	}
+
+	// The rate window must span a few scrapes, and at least the step so no sample is skipped
+	window := 4 * minPrometheusStep
+	if step > window {
+		window = step
+	}
+	return strings.ReplaceAll(expr, "{{rate}}", fmt.Sprintf("%ds", int64(window.Seconds()))), nil
+}
+
+// prometheusRange parses the range of a query and picks the step
+func prometheusRange(startParam, endParam, stepParam string, now time.Time) (start, end time.Time, step time.Duration, err error) {
+	end = now
+	if endParam != "" {
+		if end, err = parsePrometheusTime(endParam); err != nil {
+			return start, end, 0, fmt.Errorf("invalid end: %v", err)
+		}
+	}
+	start = end.Add(-defaultPrometheusRange)
+	if startParam != "" {
+		if start, err = parsePrometheusTime(startParam); err != nil {
+			return start, end, 0, fmt.Errorf("invalid start: %v", err)
+		}
+	}
+	if !start.Before(end) {
+		return start, end, 0, fmt.Errorf("start must be before end")
+	}
+	if end.Sub(start) > maxPrometheusRange {
+		return start, end, 0, fmt.Errorf("range must be at most %d days", int(maxPrometheusRange.Hours()/24))
+	}
+
+	step = end.Sub(start) / 250
+	if stepParam != "" {
+		if step, err = time.ParseDuration(stepParam); err != nil {
+			return start, end, 0, fmt.Errorf("invalid step: %v", err)
+		}
+	}
+	if minStep := end.Sub(start) / maxPrometheusPoints; step < minStep {
+		step = minStep
+	}
+	if step < minPrometheusStep {
+		step = minPrometheusStep
+	}
+	return start, end, step.Round(time.Second), nil
+}
+
+// parsePrometheusTime parses RFC 3339 or unix seconds, as the Prometheus API does
+func parsePrometheusTime(value string) (time.Time, error) {
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		return time.Unix(0, int64(seconds*float64(time.Second))), nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// prometheusResponse is the envelope of the Prometheus HTTP API
+type prometheusResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error"`
+	Data   struct {
+		ResultType string `json:"resultType"`
+		Result     []struct {
+			Metric map[string]string `json:"metric"`
+			Values [][2]interface{}  `json:"values"`
+		} `json:"result"`
+	} `json:"data"`
+}
+
+// prometheusError returns the error message of a Prometheus API response, if any
+func prometheusError(body []byte) string {
+	var resp prometheusResponse
+	if json.Unmarshal(body, &resp) != nil {
+		return ""
+	}
+	return resp.Error
+}
+
+// parsePrometheusMatrix converts a range query response into series sorted by labels
+func parsePrometheusMatrix(body []byte) ([]PrometheusSeries, error) {
+	var resp prometheusResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("invalid prometheus response: %v", err)
+	}
+	if resp.Status != "success" {
+		return nil, fmt.Errorf("prometheus: %s", resp.Error)
+	}
+	if resp.Data.ResultType != "matrix" {
+		return nil, fmt.Errorf("prometheus returned a %s instead of a matrix", resp.Data.ResultType)
+	}
+
+	series := make([]PrometheusSeries, 0, len(resp.Data.Result))
+	for _, result := range resp.Data.Result {
+		s := PrometheusSeries{Labels: result.Metric, Values: make([][2]float64, 0, len(result.Values))}
+		if s.Labels == nil {
+			s.Labels = map[string]string{}
+		}
+		for _, pair := range result.Values {
+			timestamp, ok := pair[0].(float64)
+			raw, isString := pair[1].(string)
+			if !ok || !isString {
+				continue
+			}
+			value, err := strconv.ParseFloat(raw, 64)
+			if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+				continue
+			}
+			s.Values = append(s.Values, [2]float64{timestamp, value})
+		}
+		series = append(series, s)
+	}
+	sort.Slice(series, func(i, j int) bool {
+		return labelsKey(series[i].Labels) < labelsKey(series[j].Labels)
+	})
+	return series, nil
+}
+
+// labelsKey is a stable string form of a label set, for sorting
+func labelsKey(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	for _, k := range keys {
+		b.WriteString(k + "=" + labels[k] + ",")
+	}
+	return b.String()
+}
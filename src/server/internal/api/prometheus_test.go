@@ -0,0 +1,83 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sonnguyen/kubelens/internal/apitest"
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+func TestPrometheusQueryRange(t *testing.T) {
+	var gotQuery, gotAuth string
+	prometheus := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/query_range" {
+			http.NotFound(w, r)
+			return
+		}
+		gotQuery, gotAuth = r.URL.Query().Get("query"), r.Header.Get("Authorization")
+		w.Write([]byte(`{"status":"success","data":{"resultType":"matrix","result":[
+			{"metric":{"container":"web"},"values":[[1700000000,"0.25"],[1700000060,"NaN"],[1700000120,"0.5"]]},
+			{"metric":{"container":"db"},"values":[[1700000000,"1"]]}
+		]}}`))
+	}))
+	defer prometheus.Close()
+
+	s := apitest.New(t)
+	if err := s.DB.CreateCluster(&db.Cluster{Name: apitest.ClusterName, AuthConfig: db.JSON("{}"), Enabled: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	path := "/api/v1/clusters/test/prometheus/query_range?query=pod_cpu&namespace=shop&pod=web-1"
+	if w := s.Get(path); w.Code != http.StatusNotFound {
+		t.Errorf("query without a datasource status = %d, want 404", w.Code)
+	}
+
+	w := s.Do(http.MethodPut, "/api/v1/clusters/test/datasources/prometheus", map[string]interface{}{
+		"url": prometheus.URL + "/", "auth_type": "bearer", "secret": "s3cret",
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("save datasource status = %d: %s", w.Code, w.Body)
+	}
+	if strings.Contains(w.Body.String(), "s3cret") {
+		t.Error("datasource response contains the secret")
+	}
+
+	var resp struct {
+		Unit   string `json:"unit"`
+		Series []struct {
+			Labels map[string]string `json:"labels"`
+			Values [][2]float64      `json:"values"`
+		} `json:"series"`
+	}
+	w = s.Get(path)
+	if w.Code != http.StatusOK {
+		t.Fatalf("query status = %d: %s", w.Code, w.Body)
+	}
+	apitest.DecodeJSON(t, w, &resp)
+	if gotAuth != "Bearer s3cret" {
+		t.Errorf("Authorization = %q, want the stored token", gotAuth)
+	}
+	if !strings.Contains(gotQuery, `namespace="shop",pod="web-1"`) || strings.Contains(gotQuery, "{{") {
+		t.Errorf("query = %s, want the namespace and pod filled in", gotQuery)
+	}
+	if resp.Unit != "cores" || len(resp.Series) != 2 || resp.Series[0].Labels["container"] != "db" {
+		t.Fatalf("response = %+v, want db and web series in cores", resp)
+	}
+	if len(resp.Series[1].Values) != 2 {
+		t.Errorf("web values = %v, want the NaN sample dropped", resp.Series[1].Values)
+	}
+
+	for _, bad := range []string{
+		"query=pod_cpu&namespace=shop",                     // missing pod
+		`query=pod_cpu&namespace=shop&pod=x"}or vector(1)`, // label matcher injection
+		"query=up", // not a curated query
+		"query=pod_memory&namespace=shop&pod=web-1&start=2024-01-01T00:00:00Z&end=2023-01-01T00:00:00Z",
+	} {
+		if w := s.Get("/api/v1/clusters/test/prometheus/query_range?" + strings.ReplaceAll(bad, " ", "%20")); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", bad, w.Code)
+		}
+	}
+}
@@ -0,0 +1,402 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/sonnguyen/kubelens/internal/audit"
+)
+
+// ==================== Quick Action Handlers ====================
+
+// Annotations managed by quick actions
+const (
+	hpaOriginalMinAnnotation      = "kubelens.io/original-min-replicas"
+	hpaOriginalMaxAnnotation      = "kubelens.io/original-max-replicas"
+	defaultIngressClassAnnotation = "ingressclass.kubernetes.io/is-default-class"
+)
+
+// QuickActionParam describes a parameter accepted by a quick action
+type QuickActionParam struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"` // bool, int
+	Required    bool   `json:"required"`
+	Description string `json:"description"`
+}
+
+// QuickAction is a safe, single-purpose mutation exposed as a dedicated endpoint
+type QuickAction struct {
+	Name        string             `json:"name"`
+	Kind        string             `json:"kind"`
+	Namespaced  bool               `json:"namespaced"`
+	Description string             `json:"description"`
+	Params      []QuickActionParam `json:"params"`
+
+	// resource is the resource the action changes, as named by its routes and permissions
+	resource string
+	// apply performs the mutation with the given patch options (for dry runs) and returns
+	// the updated object and an audit summary
+	apply func(ctx context.Context, client kubernetes.Interface, namespace, name string, params quickActionParams, opts metav1.PatchOptions) (interface{}, string, error)
+}
+
+// quickActionError carries the HTTP status for validation and conflict errors
+type quickActionError struct {
+	status  int
+	message string
+}
+
+func (e *quickActionError) Error() string { return e.message }
+
+// quickActionParams holds validated parameters
+type quickActionParams map[string]interface{}
+
+func (p quickActionParams) bool(name string) bool {
+	v, _ := p[name].(bool)
+	return v
+}
+
+func (p quickActionParams) int(name string) (int32, bool) {
+	v, ok := p[name].(int32)
+	return v, ok
+}
+
+// quickActions is the registry of available quick actions
+var quickActions = map[string]*QuickAction{
+	"cronjob-suspend": {
+		Name:        "cronjob-suspend",
+		Kind:        "CronJob",
+		Namespaced:  true,
+		Description: "Suspend or resume a CronJob",
+		Params: []QuickActionParam{
+			{Name: "suspend", Type: "bool", Required: true, Description: "true to suspend, false to resume"},
+		},
+		resource: "cronjobs",
+		apply:    applyCronJobSuspend,
+	},
+	"hpa-pin": {
+		Name:        "hpa-pin",
+		Kind:        "HorizontalPodAutoscaler",
+		Namespaced:  true,
+		Description: "Effectively disable autoscaling by setting minReplicas = maxReplicas (original bounds are kept for hpa-unpin)",
+		Params: []QuickActionParam{
+			{Name: "replicas", Type: "int", Description: "Replica count to pin to (defaults to the current replica count)"},
+		},
+		resource: "hpas",
+		apply:    applyHPAPin,
+	},
+	"hpa-unpin": {
+		Name:        "hpa-unpin",
+		Kind:        "HorizontalPodAutoscaler",
+		Namespaced:  true,
+		Description: "Restore the replica bounds saved by hpa-pin",
+		resource:    "hpas",
+		apply:       applyHPAUnpin,
+	},
+	"ingressclass-default": {
+		Name:        "ingressclass-default",
+		Kind:        "IngressClass",
+		Description: "Mark or unmark an IngressClass as the cluster default",
+		Params: []QuickActionParam{
+			{Name: "default", Type: "bool", Required: true, Description: "true to make this the default class"},
+		},
+		resource: "ingressclasses",
+		apply:    applyIngressClassDefault,
+	},
+	"node-cordon": {
+		Name:        "node-cordon",
+		Kind:        "Node",
+		Description: "Cordon or uncordon a node",
+		Params: []QuickActionParam{
+			{Name: "unschedulable", Type: "bool", Required: true, Description: "true to cordon, false to uncordon"},
+		},
+		resource: "nodes",
+		apply:    applyNodeCordon,
+	},
+}
+
+// validateQuickActionParams checks required parameters and converts JSON values to their declared types
+func validateQuickActionParams(action *QuickAction, raw map[string]interface{}) (quickActionParams, error) {
+	params := quickActionParams{}
+	known := make(map[string]bool)
+
+	for _, p := range action.Params {
+		known[p.Name] = true
+		v, ok := raw[p.Name]
+		if !ok || v == nil {
+			if p.Required {
+				return nil, &quickActionError{http.StatusBadRequest, fmt.Sprintf("parameter %q is required", p.Name)}
+			}
+			continue
+		}
+
+		switch p.Type {
+		case "bool":
+			b, ok := v.(bool)
+			if !ok {
+				return nil, &quickActionError{http.StatusBadRequest, fmt.Sprintf("parameter %q must be a boolean", p.Name)}
+			}
+			params[p.Name] = b
+		case "int":
+			f, ok := v.(float64)
+			if !ok || f != float64(int32(f)) || f < 0 {
+				return nil, &quickActionError{http.StatusBadRequest, fmt.Sprintf("parameter %q must be a non-negative integer", p.Name)}
+			}
+			params[p.Name] = int32(f)
+		}
+	}
+
+	for name := range raw {
+		if !known[name] {
+			return nil, &quickActionError{http.StatusBadRequest, fmt.Sprintf("unknown parameter %q", name)}
+		}
+	}
+	return params, nil
+}
+
+// ListQuickActions returns the registry of quick actions
+func (h *Handler) ListQuickActions(c *gin.Context) {
+	result := make([]*QuickAction, 0, len(quickActions))
+	for _, action := range quickActions {
+		result = append(result, action)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+
+	c.JSON(http.StatusOK, result)
+}
+
+// RunQuickAction executes a quick action against a single resource
+// Body: {"namespace": "default", "name": "my-cronjob", "params": {"suspend": true}}
+func (h *Handler) RunQuickAction(c *gin.Context) {
+	clusterName := c.Param("name")
+	actionName := c.Param("action")
+
+	action, ok := quickActions[actionName]
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("unknown quick action: %s", actionName)})
+		return
+	}
+
+	var req struct {
+		Namespace string                 `json:"namespace"`
+		Name      string                 `json:"name" binding:"required"`
+		Params    map[string]interface{} `json:"params"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if action.Namespaced && req.Namespace == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("namespace is required for %s", action.Kind)})
+		return
+	}
+	if !action.Namespaced {
+		req.Namespace = ""
+	}
+
+	params, err := validateQuickActionParams(action, req.Params)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// The body names the object, so the caller's cluster and namespace scope is checked here
+	if allows, ok := scopeAllowsAction(c); ok && !allows(clusterName, action.resource, req.Namespace, "update") {
+		c.JSON(http.StatusForbidden, gin.H{"error": fmt.Sprintf("no access to %s %s in cluster %s namespace %q", action.resource, req.Name, clusterName, req.Namespace)})
+		return
+	}
+
+	client, err := h.client(c, clusterName)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, summary, err := action.apply(context.Background(), client, req.Namespace, req.Name, params, patchOptions(c))
+	if err != nil {
+		if qe, ok := err.(*quickActionError); ok {
+			c.JSON(qe.status, gin.H{"error": qe.message})
+			return
+		}
+		if apierrors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		log.Errorf("Failed to run quick action %s: %v", actionName, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Audit log
+	if userID, exists := c.Get("user_id"); exists {
+		username, _ := c.Get("username")
+		email, _ := c.Get("email")
+
+		audit.Log(c, audit.EventAuditResourceUpdated, userID.(int), username.(string), email.(string),
+			summary,
+			map[string]interface{}{
+				"cluster_name": clusterName,
+				"namespace":    req.Namespace,
+				"kind":         action.Kind,
+				"name":         req.Name,
+				"quick_action": actionName,
+				"params":       params,
+			})
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// mergePatch builds a JSON merge patch body
+func mergePatch(patch map[string]interface{}) []byte {
+	data, _ := json.Marshal(patch)
+	return data
+}
+
+func applyCronJobSuspend(ctx context.Context, client kubernetes.Interface, namespace, name string, params quickActionParams, opts metav1.PatchOptions) (interface{}, string, error) {
+	suspend := params.bool("suspend")
+	patch := mergePatch(map[string]interface{}{"spec": map[string]interface{}{"suspend": suspend}})
+
+	cronjob, err := client.BatchV1().CronJobs(namespace).Patch(ctx, name, types.MergePatchType, patch, opts)
+	if err != nil {
+		return nil, "", err
+	}
+
+	verb := "Resumed"
+	if suspend {
+		verb = "Suspended"
+	}
+	return cronjob, fmt.Sprintf("%s cronjob: %s/%s", verb, namespace, name), nil
+}
+
+func applyHPAPin(ctx context.Context, client kubernetes.Interface, namespace, name string, params quickActionParams, opts metav1.PatchOptions) (interface{}, string, error) {
+	hpa, err := client.AutoscalingV2().HorizontalPodAutoscalers(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, "", err
+	}
+
+	replicas, ok := params.int("replicas")
+	if !ok {
+		replicas = hpa.Status.CurrentReplicas
+		if replicas == 0 {
+			replicas = hpa.Spec.MaxReplicas
+		}
+	}
+	if replicas < 1 {
+		return nil, "", &quickActionError{http.StatusBadRequest, "replicas must be at least 1"}
+	}
+
+	annotations := map[string]interface{}{}
+	// Only record the original bounds the first time, so re-pinning keeps the real originals
+	if _, pinned := hpa.Annotations[hpaOriginalMaxAnnotation]; !pinned {
+		minReplicas := int32(1)
+		if hpa.Spec.MinReplicas != nil {
+			minReplicas = *hpa.Spec.MinReplicas
+		}
+		annotations[hpaOriginalMinAnnotation] = strconv.Itoa(int(minReplicas))
+		annotations[hpaOriginalMaxAnnotation] = strconv.Itoa(int(hpa.Spec.MaxReplicas))
+	}
+
+	patch := mergePatch(map[string]interface{}{
+		"metadata": map[string]interface{}{"annotations": annotations},
+		"spec":     map[string]interface{}{"minReplicas": replicas, "maxReplicas": replicas},
+	})
+	updated, err := client.AutoscalingV2().HorizontalPodAutoscalers(namespace).Patch(ctx, name, types.MergePatchType, patch, opts)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return updated, fmt.Sprintf("Pinned HPA %s/%s to %d replicas", namespace, name, replicas), nil
+}
+
+func applyHPAUnpin(ctx context.Context, client kubernetes.Interface, namespace, name string, params quickActionParams, opts metav1.PatchOptions) (interface{}, string, error) {
+	hpa, err := client.AutoscalingV2().HorizontalPodAutoscalers(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, "", err
+	}
+
+	minStr, hasMin := hpa.Annotations[hpaOriginalMinAnnotation]
+	maxStr, hasMax := hpa.Annotations[hpaOriginalMaxAnnotation]
+	if !hasMin || !hasMax {
+		return nil, "", &quickActionError{http.StatusConflict, "HPA is not pinned by kubelens"}
+	}
+	minReplicas, err1 := strconv.Atoi(minStr)
+	maxReplicas, err2 := strconv.Atoi(maxStr)
+	if err1 != nil || err2 != nil {
+		return nil, "", &quickActionError{http.StatusConflict, "HPA has invalid original replica annotations"}
+	}
+
+	patch := mergePatch(map[string]interface{}{
+		"metadata": map[string]interface{}{"annotations": map[string]interface{}{
+			hpaOriginalMinAnnotation: nil,
+			hpaOriginalMaxAnnotation: nil,
+		}},
+		"spec": map[string]interface{}{"minReplicas": minReplicas, "maxReplicas": maxReplicas},
+	})
+	updated, err := client.AutoscalingV2().HorizontalPodAutoscalers(namespace).Patch(ctx, name, types.MergePatchType, patch, opts)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return updated, fmt.Sprintf("Unpinned HPA %s/%s (min %d, max %d)", namespace, name, minReplicas, maxReplicas), nil
+}
+
+func applyIngressClassDefault(ctx context.Context, client kubernetes.Interface, namespace, name string, params quickActionParams, opts metav1.PatchOptions) (interface{}, string, error) {
+	makeDefault := params.bool("default")
+
+	if makeDefault {
+		// Multiple default classes make admission reject Ingresses without a class
+		classes, err := client.NetworkingV1().IngressClasses().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, "", err
+		}
+		for _, ic := range classes.Items {
+			if ic.Name != name && ic.Annotations[defaultIngressClassAnnotation] == "true" {
+				return nil, "", &quickActionError{http.StatusConflict,
+					fmt.Sprintf("IngressClass %s is already the default; unset it first", ic.Name)}
+			}
+		}
+	}
+
+	patch := mergePatch(map[string]interface{}{
+		"metadata": map[string]interface{}{"annotations": map[string]interface{}{
+			defaultIngressClassAnnotation: strconv.FormatBool(makeDefault),
+		}},
+	})
+	updated, err := client.NetworkingV1().IngressClasses().Patch(ctx, name, types.MergePatchType, patch, opts)
+	if err != nil {
+		return nil, "", err
+	}
+
+	verb := "Unset default"
+	if makeDefault {
+		verb = "Set default"
+	}
+	return updated, fmt.Sprintf("%s ingress class: %s", verb, name), nil
+}
+
+func applyNodeCordon(ctx context.Context, client kubernetes.Interface, namespace, name string, params quickActionParams, opts metav1.PatchOptions) (interface{}, string, error) {
+	unschedulable := params.bool("unschedulable")
+	patch := mergePatch(map[string]interface{}{"spec": map[string]interface{}{"unschedulable": unschedulable}})
+
+	node, err := client.CoreV1().Nodes().Patch(ctx, name, types.MergePatchType, patch, opts)
+	if err != nil {
+		return nil, "", err
+	}
+
+	verb := "Uncordoned"
+	if unschedulable {
+		verb = "Cordoned"
+	}
+	return node, fmt.Sprintf("%s node: %s", verb, name), nil
+}
@@ -0,0 +1,41 @@
+package api_test
+
+import (
+	"net/http"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/sonnguyen/kubelens/internal/apitest"
+)
+
+func TestQuickActionDryRun(t *testing.T) {
+	s := apitest.New(t, apitest.Fixtures()...)
+
+	for _, tc := range []struct {
+		query  string
+		dryRun bool
+	}{{"?dryRun=true", true}, {"", false}} {
+		s.Cluster.Client.ClearActions()
+		w := s.Do(http.MethodPost, "/api/v1/clusters/test/quick-actions/node-cordon"+tc.query,
+			map[string]interface{}{"name": "node-1", "params": map[string]interface{}{"unschedulable": true}})
+		if w.Code != http.StatusOK {
+			t.Fatalf("cordon%s: %d %s", tc.query, w.Code, w.Body.String())
+		}
+
+		var patches []k8stesting.PatchActionImpl
+		for _, action := range s.Cluster.Client.Actions() {
+			if patch, ok := action.(k8stesting.PatchActionImpl); ok {
+				patches = append(patches, patch)
+			}
+		}
+		if len(patches) != 1 {
+			t.Fatalf("cordon%s made %d patches, want 1", tc.query, len(patches))
+		}
+		dryRun := len(patches[0].PatchOptions.DryRun) == 1 && patches[0].PatchOptions.DryRun[0] == metav1.DryRunAll
+		if dryRun != tc.dryRun {
+			t.Errorf("cordon%s patched with DryRun %v", tc.query, patches[0].PatchOptions.DryRun)
+		}
+	}
+}
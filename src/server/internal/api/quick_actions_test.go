@@ -0,0 +1,71 @@
+package api
+
+import (
+	"context"
+	"testing"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestValidateQuickActionParams(t *testing.T) {
+	action := quickActions["hpa-pin"]
+
+	if _, err := validateQuickActionParams(action, map[string]interface{}{"replicas": 1.5}); err == nil {
+		t.Error("expected error for fractional replicas")
+	}
+	if _, err := validateQuickActionParams(action, map[string]interface{}{"bogus": true}); err == nil {
+		t.Error("expected error for unknown parameter")
+	}
+	if _, err := validateQuickActionParams(quickActions["cronjob-suspend"], nil); err == nil {
+		t.Error("expected error for missing required parameter")
+	}
+
+	params, err := validateQuickActionParams(action, map[string]interface{}{"replicas": float64(3)})
+	if err != nil {
+		t.Fatalf("validateQuickActionParams() error = %v", err)
+	}
+	if v, ok := params.int("replicas"); !ok || v != 3 {
+		t.Errorf("replicas = %v, %v; want 3, true", v, ok)
+	}
+}
+
+func TestHPAPinUnpin(t *testing.T) {
+	minReplicas := int32(2)
+	client := fake.NewSimpleClientset(&autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec:       autoscalingv2.HorizontalPodAutoscalerSpec{MinReplicas: &minReplicas, MaxReplicas: 10},
+		Status:     autoscalingv2.HorizontalPodAutoscalerStatus{CurrentReplicas: 4},
+	})
+	ctx := context.Background()
+	hpas := client.AutoscalingV2().HorizontalPodAutoscalers("default")
+
+	if _, _, err := applyHPAUnpin(ctx, client, "default", "web", nil, metav1.PatchOptions{}); err == nil {
+		t.Fatal("expected error when unpinning an HPA that is not pinned")
+	}
+
+	if _, _, err := applyHPAPin(ctx, client, "default", "web", quickActionParams{}, metav1.PatchOptions{}); err != nil {
+		t.Fatalf("applyHPAPin() error = %v", err)
+	}
+	hpa, _ := hpas.Get(ctx, "web", metav1.GetOptions{})
+	if *hpa.Spec.MinReplicas != 4 || hpa.Spec.MaxReplicas != 4 {
+		t.Fatalf("pinned bounds = %d/%d, want 4/4", *hpa.Spec.MinReplicas, hpa.Spec.MaxReplicas)
+	}
+
+	// Re-pinning must keep the original bounds
+	if _, _, err := applyHPAPin(ctx, client, "default", "web", quickActionParams{"replicas": int32(6)}, metav1.PatchOptions{}); err != nil {
+		t.Fatalf("applyHPAPin() error = %v", err)
+	}
+
+	if _, _, err := applyHPAUnpin(ctx, client, "default", "web", nil, metav1.PatchOptions{}); err != nil {
+		t.Fatalf("applyHPAUnpin() error = %v", err)
+	}
+	hpa, _ = hpas.Get(ctx, "web", metav1.GetOptions{})
+	if *hpa.Spec.MinReplicas != 2 || hpa.Spec.MaxReplicas != 10 {
+		t.Errorf("restored bounds = %d/%d, want 2/10", *hpa.Spec.MinReplicas, hpa.Spec.MaxReplicas)
+	}
+	if _, ok := hpa.Annotations[hpaOriginalMaxAnnotation]; ok {
+		t.Error("expected original replica annotations to be removed")
+	}
+}
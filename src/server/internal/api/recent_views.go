@@ -0,0 +1,174 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+const (
+	// recentViewInterval is how often repeated views of the same resource by a user are
+	// recorded, so that polling a resource page does not write on every request
+	recentViewInterval = time.Minute
+	// maxThrottledViews bounds the views remembered by the throttle before old ones are
+	// swept out
+	maxThrottledViews = 10000
+)
+
+// recentViewThrottle remembers when views were last recorded, by user and resource
+type recentViewThrottle struct {
+	mu   sync.Mutex
+	seen map[string]time.Time
+}
+
+// due reports whether a view is to be recorded, and remembers it if so
+func (t *recentViewThrottle) due(key string, now time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.seen == nil {
+		t.seen = make(map[string]time.Time)
+	}
+	if last, ok := t.seen[key]; ok && now.Sub(last) < recentViewInterval {
+		return false
+	}
+	if len(t.seen) >= maxThrottledViews {
+		for k, last := range t.seen {
+			if now.Sub(last) >= recentViewInterval {
+				delete(t.seen, k)
+			}
+		}
+	}
+	t.seen[key] = now
+	return true
+}
+
+// forget drops the views of a user, so that their next views are recorded at once
+func (t *recentViewThrottle) forget(userID uint) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	prefix := fmt.Sprintf("%d/", userID)
+	for k := range t.seen {
+		if strings.HasPrefix(k, prefix) {
+			delete(t.seen, k)
+		}
+	}
+}
+
+// TrackRecentViews records the resources users view for GET /api/v1/me/recent: a
+// successful GET of an object route (the object, its logs, events, describe...) counts as
+// a view. Users who disabled recent views in their session preferences are not tracked.
+func (h *Handler) TrackRecentViews() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if c.Request.Method != http.MethodGet || c.Writer.Status() != http.StatusOK {
+			return
+		}
+		userID, orgID, ok := savedSearchCaller(c)
+		if !ok {
+			return
+		}
+		target, ok := auditTargetOf(c)
+		if !ok {
+			return
+		}
+		mapping, err := h.resolveResource(target.cluster, target.resource)
+		if err != nil {
+			return
+		}
+		if !isNamespaced(mapping) {
+			target.namespace = ""
+		}
+
+		now := time.Now()
+		key := fmt.Sprintf("%d/%s/%s/%s/%s", userID, target.cluster, mapping.Resource.GroupResource(), target.namespace, target.name)
+		if !h.recentViews.due(key, now) {
+			return
+		}
+		view := &db.RecentView{
+			UserID:      userID,
+			ClusterName: target.cluster,
+			Group:       mapping.Resource.Group,
+			Resource:    mapping.Resource.Resource,
+			Kind:        mapping.GroupVersionKind.Kind,
+			Namespace:   target.namespace,
+			Name:        target.name,
+			ViewedAt:    now,
+		}
+		if orgID != 0 {
+			view.OrganizationID = &orgID
+		}
+		if _, err := h.db.RecordRecentView(view); err != nil {
+			log.Warnf("Failed to record the view of %s %s by user %d: %v", view.Kind, view.Name, userID, err)
+		}
+	}
+}
+
+// ListRecentViews handles GET /api/v1/me/recent: the resources the caller viewed last,
+// most recent first, for jumping back to them. Query: limit (default 20, at most 50).
+func (h *Handler) ListRecentViews(c *gin.Context) {
+	userID, orgID, ok := savedSearchCaller(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	limit := 20
+	if value := c.Query("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive number"})
+			return
+		}
+		limit = min(n, db.MaxRecentViews)
+	}
+
+	session, err := h.db.GetUserSession(userID)
+	if err != nil {
+		log.Errorf("Failed to get user session: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve recent views"})
+		return
+	}
+	views, err := h.db.ListRecentViews(userID, orgID, limit)
+	if err != nil {
+		log.Errorf("Failed to list recent views: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve recent views"})
+		return
+	}
+
+	// Resources the caller may no longer read are left out
+	allows := scopeAllows(c)
+	visible := views[:0]
+	for _, view := range views {
+		if allows(view.ClusterName, view.Resource, view.Namespace) {
+			visible = append(visible, view)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"enabled": !session.RecentViewsDisabled, "views": visible})
+}
+
+// ClearRecentViews handles DELETE /api/v1/me/recent, which forgets the caller's views
+func (h *Handler) ClearRecentViews(c *gin.Context) {
+	userID, _, ok := savedSearchCaller(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	deleted, err := h.db.DeleteRecentViews(userID)
+	if err != nil {
+		log.Errorf("Failed to clear recent views: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to clear recent views"})
+		return
+	}
+	h.recentViews.forget(userID)
+	c.JSON(http.StatusOK, gin.H{"message": "Recent views cleared", "deleted": deleted})
+}
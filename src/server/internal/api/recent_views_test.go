@@ -0,0 +1,70 @@
+package api_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/sonnguyen/kubelens/internal/apitest"
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+func TestRecentViews(t *testing.T) {
+	s := apitest.New(t, apitest.Fixtures()...)
+	ns := apitest.FixtureNamespace
+
+	type recent struct {
+		Enabled bool             `json:"enabled"`
+		Views   []*db.RecentView `json:"views"`
+	}
+	list := func() recent {
+		t.Helper()
+		var resp recent
+		apitest.DecodeJSON(t, s.Get("/api/v1/me/recent"), &resp)
+		return resp
+	}
+
+	for _, path := range []string{
+		"/api/v1/clusters/test/namespaces/" + ns + "/pods/web-5d8f-abcde",
+		"/api/v1/clusters/test/nodes/node-1",
+		"/api/v1/clusters/test/namespaces/" + ns + "/deployments/web",
+		"/api/v1/clusters/test/namespaces/" + ns + "/pods/web-5d8f-abcde",
+		"/api/v1/clusters/test/namespaces/" + ns + "/pods/missing",
+	} {
+		s.Get(path)
+	}
+
+	got := list()
+	if !got.Enabled {
+		t.Error("recent views are disabled by default")
+	}
+	want := []string{"Deployment/web", "Node/node-1", "Pod/web-5d8f-abcde"}
+	if len(got.Views) != len(want) {
+		t.Fatalf("got %d recent views, want %v: %+v", len(got.Views), want, got.Views)
+	}
+	for i, view := range got.Views {
+		if view.Kind+"/"+view.Name != want[i] {
+			t.Errorf("recent view %d = %s/%s, want %s", i, view.Kind, view.Name, want[i])
+		}
+	}
+	// Repeated views within a minute are recorded once
+	if pod := got.Views[2]; pod.Views != 1 || pod.Namespace != ns || pod.ClusterName != "test" {
+		t.Errorf("pod view = %+v", pod)
+	}
+
+	// Disabling recent views forgets them and stops tracking
+	session, err := s.DB.GetUserSession(s.User.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	session.RecentViewsDisabled = true
+	if err := s.DB.UpdateUserSession(session); err != nil {
+		t.Fatal(err)
+	}
+	if w := s.Do(http.MethodDelete, "/api/v1/me/recent", nil); w.Code != http.StatusOK {
+		t.Fatalf("clear: %d %s", w.Code, w.Body.String())
+	}
+	s.Get("/api/v1/clusters/test/nodes/node-1")
+	if got := list(); got.Enabled || len(got.Views) != 0 {
+		t.Errorf("recent views when disabled = %+v", got)
+	}
+}
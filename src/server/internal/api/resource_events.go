@@ -0,0 +1,113 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/kubernetes"
+)
+
+// ListNamespacedResourceEvents returns the events about one namespaced object, filtered
+// on the API server by involvedObject instead of client-side
+// Query: type (Normal or Warning), uid (only events about this instance of the object)
+func (h *Handler) ListNamespacedResourceEvents(c *gin.Context) {
+	h.listResourceEvents(c, c.Param("name"), c.Param("kind"), c.Param("namespace"), c.Param("resname"))
+}
+
+// ListResourceEvents is the form of ListNamespacedResourceEvents for cluster-scoped
+// objects such as nodes. Query: namespace (for namespaced resources), type, uid
+func (h *Handler) ListResourceEvents(c *gin.Context) {
+	h.listResourceEvents(c, c.Param("name"), c.Param("resource"), c.Query("namespace"), c.Param("resname"))
+}
+
+func (h *Handler) listResourceEvents(c *gin.Context, clusterName, resource, namespace, name string) {
+	mapping, err := h.resolveResource(clusterName, resource)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if !isNamespaced(mapping) {
+		namespace = ""
+	} else if namespace == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("namespace is required for %s", mapping.GroupVersionKind.Kind)})
+		return
+	}
+
+	eventType := c.Query("type")
+	if eventType != "" && eventType != corev1.EventTypeNormal && eventType != corev1.EventTypeWarning {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "type must be Normal or Warning"})
+		return
+	}
+
+	client, err := h.client(c, clusterName)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	selector := involvedObjectSelector(mapping.GroupVersionKind.Kind, namespace, name, c.Query("uid"), eventType)
+
+	// Events about cluster-scoped objects are recorded in whatever namespace the reporter
+	// chose (usually default), so they are searched across all namespaces
+	eventsNamespace := namespace
+	if eventsNamespace == "" {
+		eventsNamespace = metav1.NamespaceAll
+	}
+
+	events, err := listEventsNewestFirst(context.Background(), client, eventsNamespace, selector)
+	if err != nil {
+		log.Errorf("Failed to list events for %s %s: %v", mapping.GroupVersionKind.Kind, name, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	for i := range events {
+		events[i].ManagedFields = nil
+	}
+
+	c.JSON(http.StatusOK, events)
+}
+
+// involvedObjectSelector builds the field selector matching events about one object
+func involvedObjectSelector(kind, namespace, name, uid, eventType string) string {
+	selectors := []fields.Selector{
+		fields.OneTermEqualSelector("involvedObject.kind", kind),
+		fields.OneTermEqualSelector("involvedObject.name", name),
+	}
+	if namespace != "" {
+		selectors = append(selectors, fields.OneTermEqualSelector("involvedObject.namespace", namespace))
+	}
+	if uid != "" {
+		selectors = append(selectors, fields.OneTermEqualSelector("involvedObject.uid", uid))
+	}
+	if eventType != "" {
+		selectors = append(selectors, fields.OneTermEqualSelector("type", eventType))
+	}
+	return fields.AndSelectors(selectors...).String()
+}
+
+// listEventsNewestFirst lists the events matching a field selector, newest first
+func listEventsNewestFirst(ctx context.Context, client kubernetes.Interface, namespace, fieldSelector string) ([]corev1.Event, error) {
+	events, err := client.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{
+		FieldSelector: fieldSelector,
+	})
+	if err != nil {
+		return []corev1.Event{}, err
+	}
+
+	items := events.Items
+	if items == nil {
+		items = []corev1.Event{}
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return eventTime(items[i]).After(eventTime(items[j]).Time)
+	})
+	return items, nil
+}
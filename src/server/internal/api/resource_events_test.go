@@ -0,0 +1,32 @@
+package api
+
+import "testing"
+
+func TestInvolvedObjectSelector(t *testing.T) {
+	tests := []struct {
+		name                                  string
+		kind, namespace, objName, uid, evType string
+		want                                  string
+	}{
+		{
+			name: "namespaced object", kind: "Deployment", namespace: "web", objName: "api",
+			want: "involvedObject.kind=Deployment,involvedObject.name=api,involvedObject.namespace=web",
+		},
+		{
+			name: "cluster-scoped object", kind: "Node", objName: "node-1",
+			want: "involvedObject.kind=Node,involvedObject.name=node-1",
+		},
+		{
+			name: "uid and type", kind: "Pod", namespace: "web", objName: "api-0", uid: "abc", evType: "Warning",
+			want: "involvedObject.kind=Pod,involvedObject.name=api-0,involvedObject.namespace=web,involvedObject.uid=abc,type=Warning",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := involvedObjectSelector(tt.kind, tt.namespace, tt.objName, tt.uid, tt.evType); got != tt.want {
+				t.Errorf("involvedObjectSelector() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
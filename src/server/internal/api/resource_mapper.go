@@ -0,0 +1,134 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/restmapper"
+)
+
+// resourceMappers caches a discovery-backed REST mapper per cluster. The mapper
+// refreshes its discovery cache when it sees a resource it does not know (e.g. a new CRD).
+type resourceMappers struct {
+	mu      sync.Mutex
+	mappers map[string]meta.RESTMapper
+	// absent remembers optional kinds (cluster + "/" + kind) found missing, and when
+	absent map[string]time.Time
+}
+
+// absentKindTTL is how long an optional kind found missing is remembered, so that polled
+// endpoints do not refresh discovery on every request for add-ons that are not installed
+const absentKindTTL = 5 * time.Minute
+
+// resourceMapper returns the REST mapper for a cluster, creating it on first use
+func (h *Handler) resourceMapper(clusterName string) (meta.RESTMapper, error) {
+	h.mappers.mu.Lock()
+	defer h.mappers.mu.Unlock()
+
+	if h.mappers.mappers == nil {
+		h.mappers.mappers = make(map[string]meta.RESTMapper)
+	}
+	if mapper, ok := h.mappers.mappers[clusterName]; ok {
+		return mapper, nil
+	}
+
+	client, err := h.clusterManager.GetClient(clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	cached := memory.NewMemCacheClient(client.Discovery())
+	mapper := restmapper.NewShortcutExpander(restmapper.NewDeferredDiscoveryRESTMapper(cached), cached, nil)
+	h.mappers.mappers[clusterName] = mapper
+	return mapper, nil
+}
+
+// resolveResource maps a user-supplied resource name (plural, singular, short name,
+// or "resource.group") to its preferred GroupVersionResource and REST mapping
+func (h *Handler) resolveResource(clusterName, resource string) (*meta.RESTMapping, error) {
+	mapper, err := h.resourceMapper(clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	var gvr schema.GroupVersionResource
+	if fullySpecified, gr := schema.ParseResourceArg(strings.ToLower(resource)); fullySpecified != nil {
+		gvr = *fullySpecified
+	} else {
+		gvr = gr.WithVersion("")
+	}
+
+	gvk, err := mapper.KindFor(gvr)
+	if err != nil {
+		return nil, fmt.Errorf("unknown resource type %q: %w", resource, err)
+	}
+
+	mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return nil, fmt.Errorf("unknown resource type %q: %w", resource, err)
+	}
+	return mapping, nil
+}
+
+// isNamespaced reports whether a REST mapping is for a namespaced resource
+func isNamespaced(mapping *meta.RESTMapping) bool {
+	return mapping.Scope.Name() == meta.RESTScopeNameNamespace
+}
+
+// forget drops the cached REST mapper of a cluster
+func (m *resourceMappers) forget(clusterName string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.mappers, clusterName)
+	for key := range m.absent {
+		if strings.HasPrefix(key, clusterName+"/") {
+			delete(m.absent, key)
+		}
+	}
+}
+
+// mappingFor returns the REST mapping for an object's apiVersion and kind
+func (h *Handler) mappingFor(clusterName string, gvk schema.GroupVersionKind) (*meta.RESTMapping, error) {
+	mapper, err := h.resourceMapper(clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return nil, fmt.Errorf("unknown kind %q in %q: %w", gvk.Kind, gvk.GroupVersion().String(), err)
+	}
+	return mapping, nil
+}
+
+// optionalMapping returns the REST mapping of a kind provided by an add-on (e.g. Argo CD)
+// that may not be installed. ok is false when the cluster does not serve the kind.
+func (h *Handler) optionalMapping(clusterName string, gvk schema.GroupVersionKind) (mapping *meta.RESTMapping, ok bool, err error) {
+	key := clusterName + "/" + gvk.String()
+	h.mappers.mu.Lock()
+	checked, absent := h.mappers.absent[key]
+	h.mappers.mu.Unlock()
+	if absent && time.Since(checked) < absentKindTTL {
+		return nil, false, nil
+	}
+
+	mapping, err = h.mappingFor(clusterName, gvk)
+	if meta.IsNoMatchError(err) {
+		h.mappers.mu.Lock()
+		if h.mappers.absent == nil {
+			h.mappers.absent = make(map[string]time.Time)
+		}
+		h.mappers.absent[key] = time.Now()
+		h.mappers.mu.Unlock()
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return mapping, true, nil
+}
@@ -0,0 +1,447 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/sonnguyen/kubelens/internal/policy"
+)
+
+// RegisterRoutes registers the cluster and resource routes served by the API handler on
+// an authenticated router group. permission is the RBAC check applied to admin-only routes
+// (auth.Handler.PermissionChecker in the server), feature hides dark-launched routes unless
+// their feature flag is enabled for the user (auth.Handler.FeatureGate) and endpointPolicy
+// gates the sensitive ones.
+func RegisterRoutes(rg *gin.RouterGroup, h *Handler, permission func(resource, action string) gin.HandlerFunc, feature func(key string) gin.HandlerFunc, endpointPolicy *policy.EndpointPolicy) {
+	h.endpointPolicy = endpointPolicy
+
+	// Global search across all resources, served from the search index (dark-launched)
+	rg.GET("/search", feature("search_index"), h.Search)
+
+	// Saved searches, private to their owner
+	rg.GET("/search/saved", h.ListSavedSearches)
+	rg.POST("/search/saved", h.CreateSavedSearch)
+	rg.PUT("/search/saved/:id", h.UpdateSavedSearch)
+	rg.DELETE("/search/saved/:id", h.DeleteSavedSearch)
+
+	// Favorites, private to their owner; status reports the live health of the pinned ones
+	rg.GET("/favorites", h.ListFavorites)
+	rg.POST("/favorites", h.CreateFavorite)
+	rg.GET("/favorites/status", h.GetFavoritesStatus)
+	rg.PUT("/favorites/:id", h.UpdateFavorite)
+	rg.DELETE("/favorites/:id", h.DeleteFavorite)
+
+	// Resources the caller viewed last, recorded by TrackRecentViews
+	rg.GET("/me/recent", h.ListRecentViews)
+	rg.DELETE("/me/recent", h.ClearRecentViews)
+
+	// Resource templates: admins manage them, users render, preview and apply them
+	rg.GET("/templates", h.ListTemplates)
+	rg.GET("/templates/:id", h.GetTemplate)
+	rg.POST("/templates", permission("templates", "create"), h.CreateTemplate)
+	rg.PUT("/templates/:id", permission("templates", "update"), h.UpdateTemplate)
+	rg.DELETE("/templates/:id", permission("templates", "delete"), h.DeleteTemplate)
+	rg.POST("/templates/:id/render", h.RenderTemplate)
+	rg.POST("/clusters/:name/templates/:id/preview", h.PreviewTemplate)
+	rg.POST("/clusters/:name/templates/:id/apply", h.ApplyTemplate)
+
+	// Fleet views: one resource type listed across all enabled clusters
+	rg.GET("/fleet/:resource", h.ListFleetResources)
+
+	// Normalized diff of two objects, e.g. the same Deployment in staging and prod
+	rg.POST("/compare", h.CompareResources)
+
+	// Quick actions (safe single-field mutations)
+	rg.GET("/quick-actions", h.ListQuickActions)
+	rg.POST("/clusters/:name/quick-actions/:action", h.RunQuickAction)
+
+	// Generic label and annotation patches (namespace query param for namespaced resources)
+	rg.PATCH("/clusters/:name/resources/:resource/:resname/labels", h.PatchResourceLabels)
+	rg.PATCH("/clusters/:name/resources/:resource/:resname/annotations", h.PatchResourceAnnotations)
+
+	// Describe-style aggregated detail for any resource (namespace query param for namespaced resources)
+	rg.GET("/clusters/:name/resources/:resource/:resname/describe", h.DescribeResource)
+
+	// Events about one object, filtered server-side by involvedObject
+	rg.GET("/clusters/:name/resources/:resource/:resname/events", h.ListResourceEvents)
+	rg.GET("/clusters/:name/namespaces/:namespace/:kind/:resname/events", h.ListNamespacedResourceEvents)
+
+	// Server-side diff of a manifest against the live object (dry run, nothing is persisted)
+	rg.POST("/clusters/:name/diff", h.DiffManifest)
+
+	// Multi-document YAML apply (server-side apply in dependency order)
+	rg.POST("/clusters/:name/manifests", h.ApplyManifests)
+
+	// Kustomize render, diff and apply (archive or git sources; applies on confirmation)
+	rg.POST("/clusters/:name/kustomize", h.Kustomize)
+
+	// Argo CD Applications (when the Argo CD CRDs are installed) with refresh and sync
+	rg.GET("/clusters/:name/argocd/applications", h.ListArgoApplications)
+	rg.GET("/clusters/:name/argocd/applications/:namespace/:app", h.GetArgoApplication)
+	rg.POST("/clusters/:name/argocd/applications/:namespace/:app/refresh", h.RefreshArgoApplication)
+	rg.POST("/clusters/:name/argocd/applications/:namespace/:app/sync", h.SyncArgoApplication)
+
+	// Flux Kustomizations and HelmReleases (kind: kustomizations or helmreleases)
+	rg.GET("/clusters/:name/flux/:kind", h.ListFluxResources)
+	rg.GET("/clusters/:name/flux/:kind/:namespace/:resname", h.GetFluxResource)
+	rg.POST("/clusters/:name/flux/:kind/:namespace/:resname/suspend", h.SuspendFluxResource)
+	rg.POST("/clusters/:name/flux/:kind/:namespace/:resname/resume", h.ResumeFluxResource)
+	rg.POST("/clusters/:name/flux/:kind/:namespace/:resname/reconcile", h.ReconcileFluxResource)
+
+	// OPA Gatekeeper constraint templates, constraints and audit violations
+	rg.GET("/clusters/:name/gatekeeper/templates", h.ListGatekeeperTemplates)
+	rg.GET("/clusters/:name/gatekeeper/constraints", h.ListGatekeeperConstraints)
+	rg.GET("/clusters/:name/gatekeeper/constraints/:kind/:resname", h.GetGatekeeperConstraint)
+	rg.GET("/clusters/:name/namespaces/:namespace/gatekeeper/violations", h.ListGatekeeperViolations)
+
+	// Kyverno policies and policy report compliance
+	rg.GET("/clusters/:name/kyverno/policies", h.ListKyvernoPolicies)
+	rg.GET("/clusters/:name/kyverno/compliance", h.GetPolicyCompliance)
+	rg.GET("/clusters/:name/kyverno/failures", h.ListPolicyFailures)
+
+	// Velero backups, restores and schedules; the progress of backups and restores started
+	// here is recorded as Events
+	rg.GET("/clusters/:name/velero/:kind", h.ListVeleroResources)
+	rg.GET("/clusters/:name/velero/:kind/:resname", h.GetVeleroResource)
+	rg.POST("/clusters/:name/velero/backups", h.CreateVeleroBackup)
+	rg.POST("/clusters/:name/velero/restores", h.CreateVeleroRestore)
+
+	// Istio VirtualServices, DestinationRules, Gateways and PeerAuthentications, and the mesh
+	// view of a namespace
+	rg.GET("/clusters/:name/istio/:kind", h.ListIstioResources)
+	rg.GET("/clusters/:name/istio/:kind/:namespace/:resname", h.GetIstioResource)
+	rg.GET("/clusters/:name/namespaces/:namespace/mesh", h.GetMeshView)
+
+	// Orphaned pods/ReplicaSets with adopt and cleanup actions
+	rg.GET("/clusters/:name/orphans", h.ListOrphans)
+	rg.POST("/clusters/:name/orphans/adopt", h.AdoptOrphan)
+	rg.POST("/clusters/:name/orphans/cleanup", h.CleanupOrphan)
+
+	// Cluster management - read operations available to all authenticated users
+	rg.GET("/clusters", h.ListClusters)
+	rg.GET("/clusters/:name/status", h.GetClusterStatus)
+	rg.GET("/clusters/:name/metrics", h.GetClusterMetrics)
+	rg.GET("/clusters/:name/metrics/pods", h.GetTopPods)
+	rg.GET("/clusters/:name/resources-summary", h.GetClusterResourcesSummary)
+
+	// Cluster management - write operations require clusters permission
+	rg.POST("/clusters", permission("clusters", "create"), h.AddCluster)
+	rg.POST("/clusters/kubeconfig-contexts", permission("clusters", "create"), h.ListKubeconfigContexts)
+	rg.POST("/clusters/bulk-import", permission("clusters", "create"), h.ImportKubeconfigContexts)
+	rg.PUT("/clusters/:name", permission("clusters", "update"), h.UpdateCluster)
+	rg.PATCH("/clusters/:name/enabled", permission("clusters", "update"), h.UpdateClusterEnabled)
+	rg.PATCH("/clusters/:name/impersonation", feature("impersonation"), permission("clusters", "update"), h.UpdateClusterImpersonation)
+	rg.POST("/clusters/:name/rotate-credentials", permission("clusters", "update"), h.RotateClusterCredentials)
+	rg.PUT("/clusters/:name/labels", permission("clusters", "update"), h.SetClusterLabels)
+
+	// Version skew and removed API versions in use, checked against an upgrade target
+	rg.GET("/clusters/:name/deprecations", h.GetClusterDeprecations)
+
+	// Cluster groups, selected by labels or listed, for bulk operations and permission scoping (group:<name>)
+	rg.GET("/cluster-groups", h.ListClusterGroups)
+	rg.GET("/cluster-groups/:group", h.GetClusterGroup)
+	rg.POST("/cluster-groups", permission("clusters", "create"), h.CreateClusterGroup)
+	rg.PUT("/cluster-groups/:group", permission("clusters", "update"), h.UpdateClusterGroup)
+	rg.DELETE("/cluster-groups/:group", permission("clusters", "delete"), h.DeleteClusterGroup)
+	rg.PATCH("/cluster-groups/:group/enabled", permission("clusters", "update"), h.SetClusterGroupEnabled)
+	rg.DELETE("/clusters/:name", permission("clusters", "delete"), h.RemoveCluster)
+	rg.GET("/clusters/:name/offboarding-report", permission("clusters", "delete"), h.GetOffboardingReport)
+
+	// External datasources of a cluster (Prometheus, Loki, Grafana, OpenCost) - changes require clusters permission
+	rg.GET("/clusters/:name/datasources", h.ListDatasources)
+	rg.PUT("/clusters/:name/datasources/:type", permission("clusters", "update"), h.SaveDatasource)
+	rg.DELETE("/clusters/:name/datasources/:type", permission("clusters", "update"), h.DeleteDatasource)
+	rg.POST("/clusters/:name/datasources/:type/test", permission("clusters", "update"), h.TestDatasource)
+
+	// Curated Prometheus range queries for resource charts
+	rg.GET("/clusters/:name/prometheus/queries", h.ListPrometheusQueries)
+	rg.GET("/clusters/:name/prometheus/query_range", h.PrometheusQueryRange)
+
+	// Cost allocation from the OpenCost (or Kubecost) datasource
+	rg.GET("/clusters/:name/costs", h.GetCostAllocation)
+
+	// Cluster autoscaler and Karpenter scaling activity
+	rg.GET("/clusters/:name/scaling", h.GetScalingActivity)
+
+	// CIS benchmark (kube-bench) runs - starting and deleting runs require clusters permission
+	rg.GET("/clusters/:name/benchmarks", h.ListBenchmarkRuns)
+	rg.POST("/clusters/:name/benchmarks", permission("clusters", "update"), h.StartBenchmark)
+	rg.GET("/clusters/:name/benchmarks/:id", h.GetBenchmarkRun)
+	rg.DELETE("/clusters/:name/benchmarks/:id", permission("clusters", "update"), h.DeleteBenchmarkRun)
+
+	// Namespaces (cluster-scoped)
+	rg.GET("/clusters/:name/namespaces", h.ListNamespaces)
+	rg.GET("/clusters/:name/namespaces/:namespace", h.GetNamespace)
+	rg.GET("/clusters/:name/namespaces/:namespace/metrics", h.GetNamespaceMetrics)
+	rg.GET("/clusters/:name/namespaces/:namespace/usage", h.GetNamespaceUsage)
+	rg.PUT("/clusters/:name/namespaces/:namespace", h.UpdateNamespace)
+	rg.DELETE("/clusters/:name/namespaces/:namespace", endpointPolicy.Require(policy.NamespaceDelete), h.DeleteNamespace)
+
+	// Pods
+	rg.GET("/clusters/:name/pods", h.ListPods)
+	rg.GET("/clusters/:name/namespaces/:namespace/pods/:pod", h.GetPod)
+	rg.GET("/clusters/:name/namespaces/:namespace/pods/:pod/describe", h.DescribePod)
+	rg.GET("/clusters/:name/namespaces/:namespace/pods/:pod/metrics", h.GetPodMetrics)
+	rg.PUT("/clusters/:name/namespaces/:namespace/pods/:pod", h.UpdatePod)
+	rg.DELETE("/clusters/:name/namespaces/:namespace/pods/:pod", h.DeletePod)
+	rg.POST("/clusters/:name/namespaces/:namespace/pods/:pod/evict", h.EvictPod)
+	rg.GET("/clusters/:name/namespaces/:namespace/pods/:pod/logs", h.GetPodLogs)
+	rg.GET("/clusters/:name/namespaces/:namespace/pods/logs", h.GetMultiPodLogs)
+	rg.GET("/clusters/:name/namespaces/:namespace/pods/:pod/logs/stream", h.PodLogsStream)
+	rg.GET("/clusters/:name/namespaces/:namespace/pods/logs/stream", h.MultiPodLogsStream)
+	rg.GET("/clusters/:name/namespaces/:namespace/pods/:pod/logs/download", h.DownloadPodLogs)
+	rg.GET("/clusters/:name/namespaces/:namespace/pods/logs/download", h.DownloadMultiPodLogs)
+	rg.GET("/clusters/:name/namespaces/:namespace/pods/:pod/shell", endpointPolicy.Require(policy.PodShell), h.PodShell)
+
+	// Deployments
+	rg.GET("/clusters/:name/deployments", h.ListDeployments)
+	rg.GET("/clusters/:name/namespaces/:namespace/deployments/:deployment", h.GetDeployment)
+	rg.PUT("/clusters/:name/namespaces/:namespace/deployments/:deployment", h.UpdateDeployment)
+	rg.DELETE("/clusters/:name/namespaces/:namespace/deployments/:deployment", h.DeleteDeployment)
+	rg.PATCH("/clusters/:name/namespaces/:namespace/deployments/:deployment/scale", h.ScaleDeployment)
+	rg.POST("/clusters/:name/namespaces/:namespace/deployments/:deployment/restart", h.RestartDeployment)
+
+	// DaemonSets
+	rg.GET("/clusters/:name/daemonsets", h.ListDaemonSets)
+	rg.GET("/clusters/:name/namespaces/:namespace/daemonsets/:daemonset", h.GetDaemonSet)
+	rg.PUT("/clusters/:name/namespaces/:namespace/daemonsets/:daemonset", h.UpdateDaemonSet)
+	rg.DELETE("/clusters/:name/namespaces/:namespace/daemonsets/:daemonset", h.DeleteDaemonSet)
+	rg.POST("/clusters/:name/namespaces/:namespace/daemonsets/:daemonset/restart", h.RestartDaemonSet)
+
+	// StatefulSets
+	rg.GET("/clusters/:name/statefulsets", h.ListStatefulSets)
+	rg.GET("/clusters/:name/namespaces/:namespace/statefulsets/:statefulset", h.GetStatefulSet)
+	rg.PUT("/clusters/:name/namespaces/:namespace/statefulsets/:statefulset", h.UpdateStatefulSet)
+	rg.DELETE("/clusters/:name/namespaces/:namespace/statefulsets/:statefulset", h.DeleteStatefulSet)
+	rg.PATCH("/clusters/:name/namespaces/:namespace/statefulsets/:statefulset/scale", h.ScaleStatefulSet)
+	rg.POST("/clusters/:name/namespaces/:namespace/statefulsets/:statefulset/restart", h.RestartStatefulSet)
+
+	// ReplicaSets
+	rg.GET("/clusters/:name/replicasets", h.ListReplicaSets)
+	rg.GET("/clusters/:name/namespaces/:namespace/replicasets/:replicaset", h.GetReplicaSet)
+	rg.PUT("/clusters/:name/namespaces/:namespace/replicasets/:replicaset", h.UpdateReplicaSet)
+	rg.DELETE("/clusters/:name/namespaces/:namespace/replicasets/:replicaset", h.DeleteReplicaSet)
+	rg.PATCH("/clusters/:name/namespaces/:namespace/replicasets/:replicaset/scale", h.ScaleReplicaSet)
+
+	// Jobs
+	rg.GET("/clusters/:name/jobs", h.ListJobs)
+	rg.GET("/clusters/:name/namespaces/:namespace/jobs/:job", h.GetJob)
+	rg.PUT("/clusters/:name/namespaces/:namespace/jobs/:job", h.UpdateJob)
+	rg.DELETE("/clusters/:name/namespaces/:namespace/jobs/:job", h.DeleteJob)
+
+	// CronJobs
+	rg.GET("/clusters/:name/cronjobs", h.ListCronJobs)
+	rg.GET("/clusters/:name/namespaces/:namespace/cronjobs/:cronjob", h.GetCronJob)
+	rg.PUT("/clusters/:name/namespaces/:namespace/cronjobs/:cronjob", h.UpdateCronJob)
+	rg.DELETE("/clusters/:name/namespaces/:namespace/cronjobs/:cronjob", h.DeleteCronJob)
+	rg.PATCH("/clusters/:name/namespaces/:namespace/cronjobs/:cronjob/suspend", h.SuspendCronJob)
+
+	// Services
+	rg.GET("/clusters/:name/services", h.ListServices)
+	rg.GET("/clusters/:name/namespaces/:namespace/services/:service", h.GetService)
+	rg.PUT("/clusters/:name/namespaces/:namespace/services/:service", h.UpdateService)
+
+	// Endpoints
+	rg.GET("/clusters/:name/endpoints", h.ListEndpoints)
+	rg.GET("/clusters/:name/namespaces/:namespace/endpoints/:endpoint", h.GetEndpoint)
+
+	// EndpointSlices
+	rg.GET("/clusters/:name/endpointslices", h.ListEndpointSlices)
+	rg.GET("/clusters/:name/namespaces/:namespace/endpointslices/:endpointslice", h.GetEndpointSlice)
+	rg.GET("/clusters/:name/namespaces/:namespace/services/:service/backends", h.GetServiceBackends)
+
+	// Ingresses (namespaced)
+	rg.GET("/clusters/:name/namespaces/:namespace/ingresses", h.ListIngresses)
+	rg.GET("/clusters/:name/ingresses", h.ListIngresses)
+	rg.GET("/clusters/:name/namespaces/:namespace/ingresses/:ingress", h.GetIngress)
+	rg.POST("/clusters/:name/namespaces/:namespace/ingresses", h.CreateIngress)
+	rg.PUT("/clusters/:name/namespaces/:namespace/ingresses/:ingress", h.UpdateIngress)
+	rg.DELETE("/clusters/:name/namespaces/:namespace/ingresses/:ingress", h.DeleteIngress)
+
+	// Ingress Classes (cluster-scoped)
+	rg.GET("/clusters/:name/ingressclasses", h.ListIngressClasses)
+	rg.GET("/clusters/:name/ingressclasses/:ingressclass", h.GetIngressClass)
+	rg.POST("/clusters/:name/ingressclasses", h.CreateIngressClass)
+	rg.PUT("/clusters/:name/ingressclasses/:ingressclass", h.UpdateIngressClass)
+	rg.DELETE("/clusters/:name/ingressclasses/:ingressclass", h.DeleteIngressClass)
+
+	// Network Policies (namespaced)
+	rg.GET("/clusters/:name/networkpolicies", h.ListNetworkPolicies)
+	rg.GET("/clusters/:name/namespaces/:namespace/networkpolicies", h.ListNetworkPolicies)
+	rg.GET("/clusters/:name/namespaces/:namespace/networkpolicies/:networkpolicy", h.GetNetworkPolicy)
+	rg.PUT("/clusters/:name/namespaces/:namespace/networkpolicies/:networkpolicy", h.UpdateNetworkPolicy)
+	rg.DELETE("/clusters/:name/namespaces/:namespace/networkpolicies/:networkpolicy", h.DeleteNetworkPolicy)
+
+	// ConfigMaps
+	rg.GET("/clusters/:name/configmaps", h.ListConfigMaps)
+	rg.POST("/clusters/:name/namespaces/:namespace/configmaps", h.CreateConfigMap)
+	rg.GET("/clusters/:name/namespaces/:namespace/configmaps/:configmap", h.GetConfigMap)
+	rg.PUT("/clusters/:name/namespaces/:namespace/configmaps/:configmap", h.UpdateConfigMap)
+	rg.DELETE("/clusters/:name/namespaces/:namespace/configmaps/:configmap", h.DeleteConfigMap)
+
+	// Secrets
+	rg.GET("/clusters/:name/secrets", endpointPolicy.Require(policy.SecretReveal), h.ListSecrets)
+	rg.POST("/clusters/:name/namespaces/:namespace/secrets", h.CreateSecret)
+	rg.GET("/clusters/:name/namespaces/:namespace/secrets/:secret", endpointPolicy.Require(policy.SecretReveal), h.GetSecret)
+	rg.PUT("/clusters/:name/namespaces/:namespace/secrets/:secret", h.UpdateSecret)
+	rg.DELETE("/clusters/:name/namespaces/:namespace/secrets/:secret", h.DeleteSecret)
+
+	// Storage Classes (cluster-scoped)
+	rg.GET("/clusters/:name/storageclasses", h.ListStorageClasses)
+	rg.POST("/clusters/:name/storageclasses", h.CreateStorageClass)
+	rg.GET("/clusters/:name/storageclasses/:storageclass", h.GetStorageClass)
+	rg.PUT("/clusters/:name/storageclasses/:storageclass", h.UpdateStorageClass)
+	rg.DELETE("/clusters/:name/storageclasses/:storageclass", h.DeleteStorageClass)
+
+	// Persistent Volumes (cluster-scoped)
+	rg.GET("/clusters/:name/persistentvolumes", h.ListPersistentVolumes)
+	rg.GET("/clusters/:name/persistentvolumes/:pv", h.GetPersistentVolume)
+	rg.PUT("/clusters/:name/persistentvolumes/:pv", h.UpdatePersistentVolume)
+	rg.DELETE("/clusters/:name/persistentvolumes/:pv", h.DeletePersistentVolume)
+
+	// Persistent Volume Claims (namespaced)
+	rg.GET("/clusters/:name/persistentvolumeclaims", h.ListPersistentVolumeClaims)
+	rg.GET("/clusters/:name/namespaces/:namespace/persistentvolumeclaims", h.ListPersistentVolumeClaims)
+	rg.GET("/clusters/:name/namespaces/:namespace/persistentvolumeclaims/:pvc", h.GetPersistentVolumeClaim)
+	rg.PUT("/clusters/:name/namespaces/:namespace/persistentvolumeclaims/:pvc", h.UpdatePersistentVolumeClaim)
+	rg.DELETE("/clusters/:name/namespaces/:namespace/persistentvolumeclaims/:pvc", h.DeletePersistentVolumeClaim)
+
+	// ServiceAccounts (namespaced)
+	rg.GET("/clusters/:name/serviceaccounts", h.ListServiceAccounts)
+	rg.GET("/clusters/:name/namespaces/:namespace/serviceaccounts", h.ListServiceAccountsByNamespace)
+	rg.GET("/clusters/:name/namespaces/:namespace/serviceaccounts/:serviceaccount", h.GetServiceAccount)
+	rg.PUT("/clusters/:name/namespaces/:namespace/serviceaccounts/:serviceaccount", h.UpdateServiceAccount)
+	rg.DELETE("/clusters/:name/namespaces/:namespace/serviceaccounts/:serviceaccount", h.DeleteServiceAccount)
+	rg.POST("/clusters/:name/namespaces/:namespace/serviceaccounts", h.CreateServiceAccount)
+
+	// ClusterRoles (cluster-scoped)
+	rg.GET("/clusters/:name/clusterroles", h.ListClusterRoles)
+	rg.GET("/clusters/:name/clusterroles/:clusterrole", h.GetClusterRole)
+	rg.PUT("/clusters/:name/clusterroles/:clusterrole", h.UpdateClusterRole)
+	rg.DELETE("/clusters/:name/clusterroles/:clusterrole", h.DeleteClusterRole)
+	rg.POST("/clusters/:name/clusterroles", h.CreateClusterRole)
+
+	// Roles (namespaced)
+	rg.GET("/clusters/:name/roles", h.ListRoles)
+	rg.GET("/clusters/:name/namespaces/:namespace/roles", h.ListRolesByNamespace)
+	rg.GET("/clusters/:name/namespaces/:namespace/roles/:role", h.GetRole)
+	rg.PUT("/clusters/:name/namespaces/:namespace/roles/:role", h.UpdateRole)
+	rg.DELETE("/clusters/:name/namespaces/:namespace/roles/:role", h.DeleteRole)
+	rg.POST("/clusters/:name/namespaces/:namespace/roles", h.CreateRole)
+
+	// ClusterRoleBindings (cluster-scoped)
+	rg.GET("/clusters/:name/clusterrolebindings", h.ListClusterRoleBindings)
+	rg.GET("/clusters/:name/clusterrolebindings/:clusterrolebinding", h.GetClusterRoleBinding)
+	rg.PUT("/clusters/:name/clusterrolebindings/:clusterrolebinding", h.UpdateClusterRoleBinding)
+	rg.DELETE("/clusters/:name/clusterrolebindings/:clusterrolebinding", h.DeleteClusterRoleBinding)
+	rg.POST("/clusters/:name/clusterrolebindings", h.CreateClusterRoleBinding)
+
+	// RoleBindings (namespaced)
+	rg.GET("/clusters/:name/rolebindings", h.ListRoleBindings)
+	rg.GET("/clusters/:name/namespaces/:namespace/rolebindings", h.ListRoleBindingsByNamespace)
+	rg.GET("/clusters/:name/namespaces/:namespace/rolebindings/:rolebinding", h.GetRoleBinding)
+	rg.PUT("/clusters/:name/namespaces/:namespace/rolebindings/:rolebinding", h.UpdateRoleBinding)
+	rg.DELETE("/clusters/:name/namespaces/:namespace/rolebindings/:rolebinding", h.DeleteRoleBinding)
+	rg.POST("/clusters/:name/namespaces/:namespace/rolebindings", h.CreateRoleBinding)
+
+	// Nodes
+	rg.GET("/clusters/:name/nodes", h.ListNodes)
+	rg.GET("/clusters/:name/nodes/:node", h.GetNode)
+	rg.GET("/clusters/:name/nodes/:node/metrics", h.GetNodeMetrics)
+	rg.GET("/clusters/:name/nodes/:node/shell", endpointPolicy.Require(policy.NodeShell), h.NodeShell)
+	rg.GET("/clusters/:name/nodes/:node/drain", h.NodeDrainInteractive)
+	rg.POST("/clusters/:name/nodes/:node/cordon", h.CordonNode)
+	rg.POST("/clusters/:name/nodes/:node/uncordon", h.UncordonNode)
+	rg.POST("/clusters/:name/nodes/:node/drain", h.DrainNode)
+	rg.DELETE("/clusters/:name/nodes/:node", h.DeleteNode)
+
+	// Events
+	rg.GET("/clusters/:name/events", h.ListEvents)
+	rg.GET("/clusters/:name/events/history", h.ListEventHistory)
+
+	// Horizontal Pod Autoscalers
+	rg.GET("/clusters/:name/hpas", h.ListHPAs)
+	rg.GET("/clusters/:name/namespaces/:namespace/hpas/:hpa", h.GetHPA)
+	rg.POST("/clusters/:name/namespaces/:namespace/hpas", h.CreateHPA)
+	rg.PUT("/clusters/:name/namespaces/:namespace/hpas/:hpa", h.UpdateHPA)
+	rg.DELETE("/clusters/:name/namespaces/:namespace/hpas/:hpa", h.DeleteHPA)
+
+	// Pod Disruption Budgets
+	rg.GET("/clusters/:name/pdbs", h.ListPDBs)
+	rg.GET("/clusters/:name/namespaces/:namespace/pdbs/:pdb", h.GetPDB)
+	rg.POST("/clusters/:name/namespaces/:namespace/pdbs", h.CreatePDB)
+	rg.PUT("/clusters/:name/namespaces/:namespace/pdbs/:pdb", h.UpdatePDB)
+	rg.DELETE("/clusters/:name/namespaces/:namespace/pdbs/:pdb", h.DeletePDB)
+
+	// Priority Classes (cluster-scoped)
+	rg.GET("/clusters/:name/priorityclasses", h.ListPriorityClasses)
+	rg.GET("/clusters/:name/priorityclasses/:priorityclass", h.GetPriorityClass)
+	rg.POST("/clusters/:name/priorityclasses", h.CreatePriorityClass)
+	rg.PUT("/clusters/:name/priorityclasses/:priorityclass", h.UpdatePriorityClass)
+	rg.DELETE("/clusters/:name/priorityclasses/:priorityclass", h.DeletePriorityClass)
+
+	// Runtime Classes (cluster-scoped)
+	rg.GET("/clusters/:name/runtimeclasses", h.ListRuntimeClasses)
+	rg.GET("/clusters/:name/runtimeclasses/:runtimeclass", h.GetRuntimeClass)
+	rg.POST("/clusters/:name/runtimeclasses", h.CreateRuntimeClass)
+	rg.PUT("/clusters/:name/runtimeclasses/:runtimeclass", h.UpdateRuntimeClass)
+	rg.DELETE("/clusters/:name/runtimeclasses/:runtimeclass", h.DeleteRuntimeClass)
+
+	// Leases (namespaced)
+	rg.GET("/clusters/:name/namespaces/:namespace/leases", h.ListLeases)
+	rg.GET("/clusters/:name/namespaces/:namespace/leases/:lease", h.GetLease)
+	rg.POST("/clusters/:name/namespaces/:namespace/leases", h.CreateLease)
+	rg.PUT("/clusters/:name/namespaces/:namespace/leases/:lease", h.UpdateLease)
+	rg.DELETE("/clusters/:name/namespaces/:namespace/leases/:lease", h.DeleteLease)
+
+	// Limit Ranges (namespaced)
+	rg.GET("/clusters/:name/limitranges/by-namespace", h.GetLimitRangesByNamespace)
+	rg.GET("/clusters/:name/namespaces/:namespace/limitranges", h.ListLimitRanges)
+	rg.GET("/clusters/:name/namespaces/:namespace/limitranges/:limitrange", h.GetLimitRange)
+	rg.POST("/clusters/:name/namespaces/:namespace/limitranges", h.CreateLimitRange)
+	rg.PUT("/clusters/:name/namespaces/:namespace/limitranges/:limitrange", h.UpdateLimitRange)
+	rg.DELETE("/clusters/:name/namespaces/:namespace/limitranges/:limitrange", h.DeleteLimitRange)
+
+	// Certificate Signing Requests (cluster-scoped)
+	rg.GET("/clusters/:name/certificatesigningrequests", h.ListCertificateSigningRequests)
+	rg.GET("/clusters/:name/certificatesigningrequests/:csr", h.GetCertificateSigningRequest)
+	rg.POST("/clusters/:name/certificatesigningrequests/:csr/approve", h.ApproveCertificateSigningRequest)
+	rg.POST("/clusters/:name/certificatesigningrequests/:csr/deny", h.DenyCertificateSigningRequest)
+	rg.DELETE("/clusters/:name/certificatesigningrequests/:csr", h.DeleteCertificateSigningRequest)
+
+	// Mutating Webhook Configurations (cluster-scoped)
+	rg.GET("/clusters/:name/mutatingwebhookconfigurations", h.ListMutatingWebhookConfigurations)
+	rg.GET("/clusters/:name/mutatingwebhookconfigurations/:webhook", h.GetMutatingWebhookConfiguration)
+	rg.POST("/clusters/:name/mutatingwebhookconfigurations", h.CreateMutatingWebhookConfiguration)
+	rg.PUT("/clusters/:name/mutatingwebhookconfigurations/:webhook", h.UpdateMutatingWebhookConfiguration)
+	rg.DELETE("/clusters/:name/mutatingwebhookconfigurations/:webhook", h.DeleteMutatingWebhookConfiguration)
+
+	// Validating Webhook Configurations (cluster-scoped)
+	rg.GET("/clusters/:name/validatingwebhookconfigurations", h.ListValidatingWebhookConfigurations)
+	rg.GET("/clusters/:name/validatingwebhookconfigurations/:webhook", h.GetValidatingWebhookConfiguration)
+	rg.POST("/clusters/:name/validatingwebhookconfigurations", h.CreateValidatingWebhookConfiguration)
+	rg.PUT("/clusters/:name/validatingwebhookconfigurations/:webhook", h.UpdateValidatingWebhookConfiguration)
+	rg.DELETE("/clusters/:name/validatingwebhookconfigurations/:webhook", h.DeleteValidatingWebhookConfiguration)
+
+	// Custom Resource Definitions (cluster-scoped)
+	rg.GET("/clusters/:name/customresourcedefinitions", h.ListCustomResourceDefinitions)
+	rg.GET("/clusters/:name/customresourcedefinitions/:crd", h.GetCustomResourceDefinition)
+	rg.PUT("/clusters/:name/customresourcedefinitions/:crd", h.UpdateCustomResourceDefinition)
+	rg.DELETE("/clusters/:name/customresourcedefinitions/:crd", endpointPolicy.Require(policy.CRDDelete), h.DeleteCustomResourceDefinition)
+
+	// Custom Resources (Dynamic) - cluster-scoped
+	rg.GET("/clusters/:name/customresources", h.ListCustomResources)
+	rg.GET("/clusters/:name/customresources/:resourcename", h.GetCustomResource)
+	rg.PUT("/clusters/:name/customresources/:resourcename", h.UpdateCustomResource)
+	rg.DELETE("/clusters/:name/customresources/:resourcename", h.DeleteCustomResource)
+
+	// Custom Resources (Dynamic) - namespaced
+	rg.GET("/clusters/:name/namespaces/:namespace/customresources", h.ListCustomResources)
+	rg.GET("/clusters/:name/namespaces/:namespace/customresources/:resourcename", h.GetCustomResource)
+	rg.PUT("/clusters/:name/namespaces/:namespace/customresources/:resourcename", h.UpdateCustomResource)
+	rg.DELETE("/clusters/:name/namespaces/:namespace/customresources/:resourcename", h.DeleteCustomResource)
+
+	// Edit sessions (stale-object detection for the YAML editor)
+	rg.POST("/clusters/:name/edit-sessions", h.CreateEditSession)
+	rg.POST("/edit-sessions/:id/renew", h.RenewEditSession)
+	rg.DELETE("/edit-sessions/:id", h.CloseEditSession)
+}
@@ -0,0 +1,212 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/sonnguyen/kubelens/internal/db"
+	"github.com/sonnguyen/kubelens/internal/search"
+)
+
+// savedSearchRequest is the body of CreateSavedSearch and UpdateSavedSearch
+type savedSearchRequest struct {
+	Name            string `json:"name" binding:"required"`
+	Query           string `json:"query"`
+	Cluster         string `json:"cluster"`
+	Namespace       string `json:"namespace"`
+	ClusterSelector string `json:"cluster_selector"`
+	ClusterGroup    string `json:"cluster_group"`
+}
+
+// validateSavedSearch checks the query and scope of a saved search
+func (h *Handler) validateSavedSearch(req *savedSearchRequest) error {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || len(req.Name) > 255 {
+		return fmt.Errorf("name must be 1 to 255 characters")
+	}
+	q, err := search.ParseQuery(req.Query)
+	if err != nil {
+		return err
+	}
+	if q.Empty() && req.Cluster == "" && req.Namespace == "" {
+		return fmt.Errorf("a query, cluster or namespace is required")
+	}
+	if strings.ContainsAny(req.Cluster+req.Namespace, " \t,:") {
+		return fmt.Errorf("invalid cluster or namespace")
+	}
+	if req.ClusterSelector != "" {
+		if _, err := labels.Parse(req.ClusterSelector); err != nil {
+			return fmt.Errorf("invalid cluster_selector: %w", err)
+		}
+	}
+	if req.ClusterGroup != "" {
+		if _, err := h.db.GetClusterGroup(req.ClusterGroup); err != nil {
+			return fmt.Errorf("unknown cluster_group %q", req.ClusterGroup)
+		}
+	}
+	return nil
+}
+
+// savedSearchQuery is the search query a saved search runs: its query narrowed to its
+// cluster and namespace
+func savedSearchQuery(saved *db.SavedSearch) string {
+	query := saved.Query
+	if saved.Cluster != "" {
+		query += " cluster:" + saved.Cluster
+	}
+	if saved.Namespace != "" {
+		query += " ns:" + saved.Namespace
+	}
+	return strings.TrimSpace(query)
+}
+
+// savedSearchCaller returns the user calling and the organization it works in
+func savedSearchCaller(c *gin.Context) (uint, uint, bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		return 0, 0, false
+	}
+	return uint(userID.(int)), c.GetUint("org_id"), true
+}
+
+// ownSavedSearch returns the caller's saved search of the given ID, writing the error
+// response otherwise. Saved searches of other users do not exist for the caller.
+func (h *Handler) ownSavedSearch(c *gin.Context, idParam string) (*db.SavedSearch, bool) {
+	userID, orgID, ok := savedSearchCaller(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return nil, false
+	}
+	id, err := strconv.ParseUint(idParam, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid saved search ID"})
+		return nil, false
+	}
+	saved, err := h.db.GetSavedSearch(uint(id))
+	if err != nil || saved.UserID != userID || (orgID != 0 && (saved.OrganizationID == nil || *saved.OrganizationID != orgID)) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Saved search not found"})
+		return nil, false
+	}
+	return saved, true
+}
+
+// ListSavedSearches handles GET /api/v1/search/saved: the caller's saved searches
+func (h *Handler) ListSavedSearches(c *gin.Context) {
+	userID, orgID, ok := savedSearchCaller(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	searches, err := h.db.ListSavedSearches(userID, orgID)
+	if err != nil {
+		log.Errorf("Failed to list saved searches: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve saved searches"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"searches": searches})
+}
+
+// CreateSavedSearch handles POST /api/v1/search/saved. The query takes the syntax of
+// GET /api/v1/search; cluster, namespace, cluster_selector and cluster_group narrow it.
+func (h *Handler) CreateSavedSearch(c *gin.Context) {
+	userID, orgID, ok := savedSearchCaller(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	var req savedSearchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+	if err := h.validateSavedSearch(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if taken, err := h.db.SavedSearchNameTaken(userID, orgID, req.Name, 0); err != nil {
+		log.Errorf("Failed to create saved search: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create saved search"})
+		return
+	} else if taken {
+		c.JSON(http.StatusConflict, gin.H{"error": "A saved search with this name already exists"})
+		return
+	}
+
+	saved := &db.SavedSearch{
+		UserID:          userID,
+		Name:            req.Name,
+		Query:           strings.TrimSpace(req.Query),
+		Cluster:         req.Cluster,
+		Namespace:       req.Namespace,
+		ClusterSelector: req.ClusterSelector,
+		ClusterGroup:    req.ClusterGroup,
+	}
+	if orgID != 0 {
+		saved.OrganizationID = &orgID
+	}
+	if err := h.db.CreateSavedSearch(saved); err != nil {
+		log.Errorf("Failed to create saved search: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create saved search"})
+		return
+	}
+	c.JSON(http.StatusCreated, saved)
+}
+
+// UpdateSavedSearch handles PUT /api/v1/search/saved/:id
+func (h *Handler) UpdateSavedSearch(c *gin.Context) {
+	saved, ok := h.ownSavedSearch(c, c.Param("id"))
+	if !ok {
+		return
+	}
+	var req savedSearchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+	if err := h.validateSavedSearch(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var orgID uint
+	if saved.OrganizationID != nil {
+		orgID = *saved.OrganizationID
+	}
+	if taken, err := h.db.SavedSearchNameTaken(saved.UserID, orgID, req.Name, saved.ID); err != nil {
+		log.Errorf("Failed to update saved search: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update saved search"})
+		return
+	} else if taken {
+		c.JSON(http.StatusConflict, gin.H{"error": "A saved search with this name already exists"})
+		return
+	}
+
+	saved.Name, saved.Query = req.Name, strings.TrimSpace(req.Query)
+	saved.Cluster, saved.Namespace = req.Cluster, req.Namespace
+	saved.ClusterSelector, saved.ClusterGroup = req.ClusterSelector, req.ClusterGroup
+	if err := h.db.UpdateSavedSearch(saved); err != nil {
+		log.Errorf("Failed to update saved search: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update saved search"})
+		return
+	}
+	c.JSON(http.StatusOK, saved)
+}
+
+// DeleteSavedSearch handles DELETE /api/v1/search/saved/:id
+func (h *Handler) DeleteSavedSearch(c *gin.Context) {
+	saved, ok := h.ownSavedSearch(c, c.Param("id"))
+	if !ok {
+		return
+	}
+	if err := h.db.DeleteSavedSearch(saved.ID); err != nil {
+		log.Errorf("Failed to delete saved search: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete saved search"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Saved search deleted"})
+}
@@ -0,0 +1,101 @@
+package api_test
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/sonnguyen/kubelens/internal/apitest"
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+func TestSavedSearches(t *testing.T) {
+	s := apitest.New(t, apitest.Fixtures()...)
+	s.AddCluster("staging", &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: apitest.FixtureNamespace}})
+	if err := s.DB.UpsertFeatureFlag(&db.FeatureFlag{Key: "search_index", Enabled: true, GroupIDs: db.JSON("[]")}); err != nil {
+		t.Fatal(err)
+	}
+
+	type result struct {
+		Type      string `json:"type"`
+		Name      string `json:"name"`
+		Cluster   string `json:"cluster"`
+		Namespace string `json:"namespace"`
+	}
+	search := func(path string) []result {
+		t.Helper()
+		w := s.Get(path)
+		if w.Code != http.StatusOK {
+			t.Fatalf("GET %s: %d %s", path, w.Code, w.Body.String())
+		}
+		var resp struct {
+			Results []result `json:"results"`
+		}
+		apitest.DecodeJSON(t, w, &resp)
+		return resp.Results
+	}
+
+	if results := search("/api/v1/search?q=web+kind:pod"); len(results) != 2 {
+		t.Fatalf("got %d pods named web in both clusters, want 2: %+v", len(results), results)
+	}
+
+	w := s.Do(http.MethodPost, "/api/v1/search/saved", map[string]string{"name": "web pods", "query": "web kind:pod", "cluster": "test"})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: %d %s", w.Code, w.Body.String())
+	}
+	var saved db.SavedSearch
+	apitest.DecodeJSON(t, w, &saved)
+	id := strconv.Itoa(int(saved.ID))
+
+	if w := s.Do(http.MethodPost, "/api/v1/search/saved", map[string]string{"name": "web pods", "query": "web"}); w.Code != http.StatusConflict {
+		t.Errorf("duplicate name: %d, want 409", w.Code)
+	}
+	for _, body := range []map[string]string{
+		{"name": "bad", "query": "owner:me"},
+		{"name": "empty", "query": ""},
+		{"name": "bad selector", "query": "web", "cluster_selector": "env in"},
+		{"name": "bad group", "query": "web", "cluster_group": "nope"},
+	} {
+		if w := s.Do(http.MethodPost, "/api/v1/search/saved", body); w.Code != http.StatusBadRequest {
+			t.Errorf("create %v: %d, want 400", body, w.Code)
+		}
+	}
+
+	// Running a saved search applies its scope, and q narrows it further
+	results := search("/api/v1/search?saved=" + id)
+	if len(results) != 1 || results[0].Cluster != "test" || results[0].Name != "web-5d8f-abcde" {
+		t.Errorf("saved search results = %+v, want the web pod of cluster test", results)
+	}
+	if results := search("/api/v1/search?saved=" + id + "&q=ns:elsewhere"); len(results) != 0 {
+		t.Errorf("saved search narrowed by q = %+v, want none", results)
+	}
+	if w := s.Get("/api/v1/search?saved=999"); w.Code != http.StatusNotFound {
+		t.Errorf("unknown saved search: %d, want 404", w.Code)
+	}
+
+	w = s.Do(http.MethodPut, "/api/v1/search/saved/"+id, map[string]string{"name": "staging web pods", "query": "web kind:pod", "cluster": "staging"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("update: %d %s", w.Code, w.Body.String())
+	}
+	if results := search("/api/v1/search?saved=" + id); len(results) != 1 || results[0].Cluster != "staging" {
+		t.Errorf("updated saved search results = %+v, want the pod of staging", results)
+	}
+
+	var list struct {
+		Searches []db.SavedSearch `json:"searches"`
+	}
+	apitest.DecodeJSON(t, s.Get("/api/v1/search/saved"), &list)
+	if len(list.Searches) != 1 || list.Searches[0].Name != "staging web pods" {
+		t.Errorf("saved searches = %+v", list.Searches)
+	}
+
+	if w := s.Do(http.MethodDelete, "/api/v1/search/saved/"+id, nil); w.Code != http.StatusOK {
+		t.Errorf("delete: %d %s", w.Code, w.Body.String())
+	}
+	if w := s.Do(http.MethodDelete, "/api/v1/search/saved/"+id, nil); w.Code != http.StatusNotFound {
+		t.Errorf("delete again: %d, want 404", w.Code)
+	}
+}
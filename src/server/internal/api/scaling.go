@@ -0,0 +1,562 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+const (
+	// autoscalerStatusConfigMap is the ConfigMap the cluster autoscaler writes its status to
+	autoscalerStatusConfigMap = "cluster-autoscaler-status"
+	// defaultScalingWindow is how far back scaling activity is shown unless the request
+	// asks otherwise
+	defaultScalingWindow = 24 * time.Hour
+	// maxScalingWindow bounds the window of scaling activity
+	maxScalingWindow = 7 * 24 * time.Hour
+	// maxScalingActivity and maxPendingPods bound the items returned
+	maxScalingActivity = 200
+	maxPendingPods     = 200
+)
+
+// Karpenter objects; the version is left empty so that whichever version the cluster
+// prefers is used (v1 or v1beta1)
+var (
+	karpenterNodePoolKind  = schema.GroupVersionKind{Group: "karpenter.sh", Kind: "NodePool"}
+	karpenterNodeClaimKind = schema.GroupVersionKind{Group: "karpenter.sh", Kind: "NodeClaim"}
+)
+
+// scalingEventDirections are the event reasons of the cluster autoscaler and Karpenter
+// that record scaling activity, by direction (up, down or failed)
+var scalingEventDirections = map[string]string{
+	// Cluster autoscaler
+	"TriggeredScaleUp":     "up",
+	"ScaledUpGroup":        "up",
+	"ScaleDown":            "down",
+	"ScaleDownEmpty":       "down",
+	"NotTriggerScaleUp":    "failed",
+	"FailedToScaleUpGroup": "failed",
+	"ScaleDownFailed":      "failed",
+	// Karpenter
+	"Launched":                  "up",
+	"Nominated":                 "up",
+	"DisruptionLaunching":       "up",
+	"DisruptionTerminating":     "down",
+	"DisruptionBlocked":         "failed",
+	"InsufficientCapacityError": "failed",
+}
+
+// unschedulableCountPattern matches the "<count> <reason>" parts of a FailedScheduling
+// message
+var unschedulableCountPattern = regexp.MustCompile(`^(\d+) (.+)$`)
+
+// ScalingActivity is the autoscaling state of a cluster
+type ScalingActivity struct {
+	ClusterAutoscaler *ClusterAutoscalerStatus `json:"clusterAutoscaler"` // nil when not found
+	Karpenter         *KarpenterStatus         `json:"karpenter"`         // nil when not installed
+	PendingPods       []PendingPod             `json:"pendingPods"`
+	// UnschedulableReasons counts the reasons the scheduler gave for the pending pods, such
+	// as "Insufficient cpu", over all nodes
+	UnschedulableReasons map[string]int `json:"unschedulableReasons"`
+	Activity             []ScalingEvent `json:"activity"`
+	Window               string         `json:"window"`
+}
+
+// ClusterAutoscalerStatus is the status the cluster autoscaler reports in its ConfigMap
+type ClusterAutoscalerStatus struct {
+	Health     string                      `json:"health"`    // e.g. Healthy or Unhealthy
+	ScaleUp    string                      `json:"scaleUp"`   // e.g. NoActivity, InProgress or Backoff
+	ScaleDown  string                      `json:"scaleDown"` // e.g. NoCandidates or CandidatesPresent
+	UpdatedAt  string                      `json:"updatedAt,omitempty"`
+	NodeGroups []AutoscalerNodeGroupStatus `json:"nodeGroups"`
+	// Raw is the status as written by the autoscaler
+	Raw string `json:"raw"`
+}
+
+// AutoscalerNodeGroupStatus is the status of a node group of the cluster autoscaler
+type AutoscalerNodeGroupStatus struct {
+	Name      string `json:"name"`
+	Health    string `json:"health"`
+	ScaleUp   string `json:"scaleUp"`
+	ScaleDown string `json:"scaleDown"`
+	Ready     int    `json:"ready"`
+	Target    int    `json:"target"` // cloud provider target
+	MinSize   int    `json:"minSize"`
+	MaxSize   int    `json:"maxSize"`
+}
+
+// KarpenterStatus is the NodePools and NodeClaims of Karpenter
+type KarpenterStatus struct {
+	NodePools  []KarpenterNodePool  `json:"nodePools"`
+	NodeClaims []KarpenterNodeClaim `json:"nodeClaims"`
+}
+
+// KarpenterNodePool is a Karpenter NodePool with the resources of its nodes
+type KarpenterNodePool struct {
+	Name                string            `json:"name"`
+	Ready               bool              `json:"ready"`
+	Weight              int64             `json:"weight,omitempty"`
+	NodeClass           string            `json:"nodeClass,omitempty"`
+	ConsolidationPolicy string            `json:"consolidationPolicy,omitempty"`
+	Limits              map[string]string `json:"limits,omitempty"`
+	Resources           map[string]string `json:"resources,omitempty"` // of its nodes
+	NodeClaims          int               `json:"nodeClaims"`
+}
+
+// KarpenterNodeClaim is a node launched, or being launched, by Karpenter
+type KarpenterNodeClaim struct {
+	Name         string `json:"name"`
+	NodePool     string `json:"nodePool"`
+	NodeName     string `json:"nodeName,omitempty"`
+	InstanceType string `json:"instanceType,omitempty"`
+	CapacityType string `json:"capacityType,omitempty"` // on-demand or spot
+	Zone         string `json:"zone,omitempty"`
+	// Status is Launching, Registering, Initializing, Ready or Deleting
+	Status    string `json:"status"`
+	Message   string `json:"message,omitempty"`
+	CreatedAt string `json:"createdAt"`
+}
+
+// PendingPod is a pod the scheduler cannot place
+type PendingPod struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Reason    string `json:"reason"`
+	Message   string `json:"message"`
+	Since     string `json:"since,omitempty"`
+}
+
+// ScalingEvent is a scale up, scale down or failed scaling attempt
+type ScalingEvent struct {
+	Direction string    `json:"direction"` // up, down or failed
+	Reason    string    `json:"reason"`
+	Kind      string    `json:"kind"`
+	Namespace string    `json:"namespace,omitempty"`
+	Name      string    `json:"name"`
+	Message   string    `json:"message"`
+	Count     int32     `json:"count"`
+	LastSeen  time.Time `json:"lastSeen"`
+}
+
+// GetScalingActivity handles GET /clusters/:name/scaling: the status of the cluster
+// autoscaler and Karpenter, the pending pods with why they are unschedulable, and the
+// recent scale ups and downs.
+// Query: window (duration, default 24h, at most 7 days)
+func (h *Handler) GetScalingActivity(c *gin.Context) {
+	clusterName := c.Param("name")
+	window := defaultScalingWindow
+	if value := c.Query("window"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed <= 0 || parsed > maxScalingWindow {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("window must be a duration of at most %v", maxScalingWindow)})
+			return
+		}
+		window = parsed
+	}
+
+	client, err := h.client(c, clusterName)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	ctx := context.Background()
+	activity := ScalingActivity{Window: window.String(), UnschedulableReasons: map[string]int{}}
+
+	cm, err := client.CoreV1().ConfigMaps("kube-system").Get(ctx, autoscalerStatusConfigMap, metav1.GetOptions{})
+	if err == nil {
+		activity.ClusterAutoscaler = parseAutoscalerStatus(cm.Data["status"])
+	} else if !apierrors.IsNotFound(err) && !apierrors.IsForbidden(err) {
+		log.Warnf("Failed to get the cluster autoscaler status of cluster %s: %v", clusterName, err)
+	}
+
+	if activity.Karpenter, err = h.karpenterStatus(c, clusterName); err != nil {
+		log.Warnf("Failed to read Karpenter in cluster %s: %v", clusterName, err)
+	}
+
+	pods, err := client.CoreV1().Pods("").List(ctx, metav1.ListOptions{FieldSelector: "status.phase=Pending"})
+	if err != nil {
+		if apierrors.IsForbidden(err) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		log.Errorf("Failed to list pending pods in cluster %s: %v", clusterName, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	activity.PendingPods, activity.UnschedulableReasons = unschedulablePods(pods.Items)
+
+	activity.Activity = h.scalingEvents(c, clusterName, time.Now().Add(-window))
+	c.JSON(http.StatusOK, activity)
+}
+
+// scalingEvents returns the scaling events of a cluster since a time, from the event
+// history, or from the live Events when the history has none (the event watcher is off or
+// just started)
+func (h *Handler) scalingEvents(c *gin.Context, clusterName string, since time.Time) []ScalingEvent {
+	reasons := make([]string, 0, len(scalingEventDirections))
+	for reason := range scalingEventDirections {
+		reasons = append(reasons, reason)
+	}
+
+	events := []ScalingEvent{}
+	history, err := h.db.ListClusterEvents(db.ClusterEventFilters{
+		ClusterName: clusterName,
+		Reasons:     reasons,
+		Since:       since,
+		Limit:       maxScalingActivity,
+	})
+	if err != nil {
+		log.Warnf("Failed to list the event history of cluster %s: %v", clusterName, err)
+	}
+	for _, e := range history {
+		events = append(events, ScalingEvent{
+			Direction: scalingEventDirections[e.Reason],
+			Reason:    e.Reason,
+			Kind:      e.Kind,
+			Namespace: e.Namespace,
+			Name:      e.Name,
+			Message:   e.Message,
+			Count:     e.Count,
+			LastSeen:  e.LastSeen,
+		})
+	}
+	if len(events) > 0 {
+		return events
+	}
+
+	client, err := h.client(c, clusterName)
+	if err != nil {
+		return events
+	}
+	live, err := client.CoreV1().Events("").List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		log.Warnf("Failed to list events of cluster %s: %v", clusterName, err)
+		return events
+	}
+	for i := range live.Items {
+		e := &live.Items[i]
+		direction, ok := scalingEventDirections[e.Reason]
+		lastSeen := e.LastTimestamp.Time
+		if lastSeen.IsZero() {
+			lastSeen = e.EventTime.Time
+		}
+		if !ok || lastSeen.Before(since) {
+			continue
+		}
+		events = append(events, ScalingEvent{
+			Direction: direction,
+			Reason:    e.Reason,
+			Kind:      e.InvolvedObject.Kind,
+			Namespace: e.InvolvedObject.Namespace,
+			Name:      e.InvolvedObject.Name,
+			Message:   e.Message,
+			Count:     e.Count,
+			LastSeen:  lastSeen,
+		})
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].LastSeen.After(events[j].LastSeen) })
+	if len(events) > maxScalingActivity {
+		events = events[:maxScalingActivity]
+	}
+	return events
+}
+
+// unschedulablePods returns the pending pods the scheduler marked unschedulable, oldest
+// first, and counts the reasons given over all nodes
+func unschedulablePods(pods []corev1.Pod) ([]PendingPod, map[string]int) {
+	pending := []PendingPod{}
+	reasons := map[string]int{}
+	for i := range pods {
+		pod := &pods[i]
+		for _, condition := range pod.Status.Conditions {
+			if condition.Type != corev1.PodScheduled || condition.Status != corev1.ConditionFalse ||
+				condition.Reason != corev1.PodReasonUnschedulable {
+				continue
+			}
+			pending = append(pending, PendingPod{
+				Namespace: pod.Namespace,
+				Name:      pod.Name,
+				Reason:    condition.Reason,
+				Message:   condition.Message,
+				Since:     condition.LastTransitionTime.UTC().Format(time.RFC3339),
+			})
+			for reason, count := range parseUnschedulableMessage(condition.Message) {
+				reasons[reason] += count
+			}
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool { return pending[i].Since < pending[j].Since })
+	if len(pending) > maxPendingPods {
+		pending = pending[:maxPendingPods]
+	}
+	return pending, reasons
+}
+
+// parseUnschedulableMessage counts the reasons of a scheduler message such as "0/5 nodes are
+// available: 3 Insufficient cpu, 2 node(s) had untolerated taint {dedicated: gpu}.
+// preemption: ..."
+func parseUnschedulableMessage(message string) map[string]int {
+	reasons := map[string]int{}
+	message, _, _ = strings.Cut(message, " preemption:")
+	_, list, found := strings.Cut(message, "available: ")
+	if !found {
+		return reasons
+	}
+	list = strings.TrimSuffix(strings.TrimSpace(list), ".")
+
+	// Reasons can contain ", " themselves (taints); a part not starting with a count
+	// belongs to the previous reason
+	var parts []string
+	for _, part := range strings.Split(list, ", ") {
+		if len(parts) > 0 && !unschedulableCountPattern.MatchString(part) {
+			parts[len(parts)-1] += ", " + part
+			continue
+		}
+		parts = append(parts, part)
+	}
+	for _, part := range parts {
+		if match := unschedulableCountPattern.FindStringSubmatch(part); match != nil {
+			count, _ := strconv.Atoi(match[1])
+			reasons[match[2]] += count
+		}
+	}
+	return reasons
+}
+
+// parseAutoscalerStatus reads the status of the cluster autoscaler: YAML since 1.30,
+// a text report before
+func parseAutoscalerStatus(raw string) *ClusterAutoscalerStatus {
+	status := &ClusterAutoscalerStatus{Raw: raw, NodeGroups: []AutoscalerNodeGroupStatus{}}
+
+	var structured struct {
+		Time             string `json:"time"`
+		AutoscalerStatus string `json:"autoscalerStatus"`
+		ClusterWide      *struct {
+			Health    autoscalerCondition `json:"health"`
+			ScaleUp   autoscalerCondition `json:"scaleUp"`
+			ScaleDown autoscalerCondition `json:"scaleDown"`
+		} `json:"clusterWide"`
+		NodeGroups []struct {
+			Name   string `json:"name"`
+			Health struct {
+				autoscalerCondition
+				CloudProviderTarget int `json:"cloudProviderTarget"`
+				MinSize             int `json:"minSize"`
+				MaxSize             int `json:"maxSize"`
+			} `json:"health"`
+			ScaleUp   autoscalerCondition `json:"scaleUp"`
+			ScaleDown autoscalerCondition `json:"scaleDown"`
+		} `json:"nodeGroups"`
+	}
+	if err := yaml.Unmarshal([]byte(raw), &structured); err == nil && structured.ClusterWide != nil {
+		status.UpdatedAt = structured.Time
+		status.Health = structured.ClusterWide.Health.Status
+		status.ScaleUp = structured.ClusterWide.ScaleUp.Status
+		status.ScaleDown = structured.ClusterWide.ScaleDown.Status
+		for _, group := range structured.NodeGroups {
+			status.NodeGroups = append(status.NodeGroups, AutoscalerNodeGroupStatus{
+				Name:      group.Name,
+				Health:    group.Health.Status,
+				ScaleUp:   group.ScaleUp.Status,
+				ScaleDown: group.ScaleDown.Status,
+				Ready:     group.Health.NodeCounts.Registered.Ready,
+				Target:    group.Health.CloudProviderTarget,
+				MinSize:   group.Health.MinSize,
+				MaxSize:   group.Health.MaxSize,
+			})
+		}
+		return status
+	}
+
+	// Text report: the first Health/ScaleUp/ScaleDown lines are cluster-wide, the next ones
+	// follow each "Name:" line of the node groups
+	var group *AutoscalerNodeGroupStatus
+	for _, line := range strings.Split(raw, "\n") {
+		line = strings.TrimSpace(line)
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		word, _, _ := strings.Cut(value, " ")
+		switch key {
+		case "Cluster-autoscaler status at":
+			status.UpdatedAt = value
+		case "Name":
+			status.NodeGroups = append(status.NodeGroups, AutoscalerNodeGroupStatus{Name: value})
+			group = &status.NodeGroups[len(status.NodeGroups)-1]
+		case "Health":
+			if group == nil {
+				status.Health = word
+			} else {
+				group.Health = word
+				group.Ready = statusCount(value, "ready")
+				group.Target = statusCount(value, "cloudProviderTarget")
+				group.MinSize = statusCount(value, "minSize")
+				group.MaxSize = statusCount(value, "maxSize")
+			}
+		case "ScaleUp":
+			if group == nil {
+				status.ScaleUp = word
+			} else {
+				group.ScaleUp = word
+			}
+		case "ScaleDown":
+			if group == nil {
+				status.ScaleDown = word
+			} else {
+				group.ScaleDown = word
+			}
+		}
+	}
+	return status
+}
+
+// autoscalerCondition is a condition of the YAML status of the cluster autoscaler
+type autoscalerCondition struct {
+	Status     string `json:"status"`
+	NodeCounts struct {
+		Registered struct {
+			Ready int `json:"ready"`
+		} `json:"registered"`
+	} `json:"nodeCounts"`
+}
+
+// statusCount returns the count of a key=value pair of a text status line, e.g. ready=3
+func statusCount(value, key string) int {
+	for _, field := range strings.FieldsFunc(value, func(r rune) bool { return strings.ContainsRune(" (),", r) }) {
+		if name, count, found := strings.Cut(field, "="); found && name == key {
+			n, _ := strconv.Atoi(count)
+			return n
+		}
+	}
+	return 0
+}
+
+// karpenterStatus reads the NodePools and NodeClaims of a cluster, or nil when Karpenter
+// is not installed
+func (h *Handler) karpenterStatus(c *gin.Context, clusterName string) (*KarpenterStatus, error) {
+	client, err := h.dynamicClient(c, clusterName)
+	if err != nil {
+		return nil, err
+	}
+	poolMapping, installed, err := h.optionalMapping(clusterName, karpenterNodePoolKind)
+	if err != nil || !installed {
+		return nil, err
+	}
+	pools, err := client.Resource(poolMapping.Resource).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	status := &KarpenterStatus{NodePools: []KarpenterNodePool{}, NodeClaims: []KarpenterNodeClaim{}}
+	if claimMapping, ok, err := h.optionalMapping(clusterName, karpenterNodeClaimKind); err == nil && ok {
+		claims, err := client.Resource(claimMapping.Resource).List(context.Background(), metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		for i := range claims.Items {
+			status.NodeClaims = append(status.NodeClaims, karpenterNodeClaimStatus(&claims.Items[i]))
+		}
+	}
+	sort.Slice(status.NodeClaims, func(i, j int) bool { return status.NodeClaims[i].CreatedAt > status.NodeClaims[j].CreatedAt })
+
+	for i := range pools.Items {
+		pool := karpenterNodePoolStatus(&pools.Items[i])
+		for _, claim := range status.NodeClaims {
+			if claim.NodePool == pool.Name {
+				pool.NodeClaims++
+			}
+		}
+		status.NodePools = append(status.NodePools, pool)
+	}
+	sort.Slice(status.NodePools, func(i, j int) bool { return status.NodePools[i].Name < status.NodePools[j].Name })
+	return status, nil
+}
+
+// karpenterNodePoolStatus extracts the settings and resources of a NodePool
+func karpenterNodePoolStatus(obj *unstructured.Unstructured) KarpenterNodePool {
+	o := obj.Object
+	pool := KarpenterNodePool{
+		Name:                obj.GetName(),
+		NodeClass:           nestedStringValue(o, "spec", "template", "spec", "nodeClassRef", "name"),
+		ConsolidationPolicy: nestedStringValue(o, "spec", "disruption", "consolidationPolicy"),
+		Ready:               true,
+	}
+	pool.Weight, _, _ = unstructured.NestedInt64(o, "spec", "weight")
+	pool.Limits = nestedQuantities(o, "spec", "limits")
+	pool.Resources = nestedQuantities(o, "status", "resources")
+	for _, condition := range nestedObjectList(o, "status", "conditions") {
+		if nestedStringValue(condition, "type") == "Ready" {
+			pool.Ready = nestedStringValue(condition, "status") == "True"
+		}
+	}
+	return pool
+}
+
+// karpenterNodeClaimStatus extracts the node and lifecycle status of a NodeClaim
+func karpenterNodeClaimStatus(obj *unstructured.Unstructured) KarpenterNodeClaim {
+	labels := obj.GetLabels()
+	claim := KarpenterNodeClaim{
+		Name:         obj.GetName(),
+		NodePool:     labels["karpenter.sh/nodepool"],
+		NodeName:     nestedStringValue(obj.Object, "status", "nodeName"),
+		InstanceType: labels["node.kubernetes.io/instance-type"],
+		CapacityType: labels["karpenter.sh/capacity-type"],
+		Zone:         labels["topology.kubernetes.io/zone"],
+		CreatedAt:    obj.GetCreationTimestamp().UTC().Format(time.RFC3339),
+	}
+
+	conditions := map[string]map[string]interface{}{}
+	for _, condition := range nestedObjectList(obj.Object, "status", "conditions") {
+		conditions[nestedStringValue(condition, "type")] = condition
+	}
+	// A NodeClaim goes through Launched, Registered and Initialized before it is Ready
+	claim.Status = "Ready"
+	for _, step := range []struct{ condition, status string }{
+		{"Launched", "Launching"},
+		{"Registered", "Registering"},
+		{"Initialized", "Initializing"},
+	} {
+		if condition := conditions[step.condition]; nestedStringValue(condition, "status") != "True" {
+			claim.Status = step.status
+			if condition != nil {
+				claim.Message = nestedStringValue(condition, "message")
+			}
+			break
+		}
+	}
+	if obj.GetDeletionTimestamp() != nil {
+		claim.Status = "Deleting"
+	}
+	return claim
+}
+
+// nestedQuantities returns a map of resource quantities, such as NodePool limits
+func nestedQuantities(obj map[string]interface{}, fields ...string) map[string]string {
+	values, found, _ := unstructured.NestedMap(obj, fields...)
+	if !found {
+		return nil
+	}
+	quantities := map[string]string{}
+	for name, value := range values {
+		quantities[name] = fmt.Sprint(value)
+	}
+	return quantities
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseUnschedulableMessage(t *testing.T) {
+	got := parseUnschedulableMessage("0/5 nodes are available: 1 node(s) had untolerated taint {dedicated: gpu, team: ml}, 3 Insufficient cpu, 1 Insufficient memory. preemption: 0/5 nodes are available: 5 No preemption victims found for incoming pod.")
+	want := map[string]int{
+		"node(s) had untolerated taint {dedicated: gpu, team: ml}": 1,
+		"Insufficient cpu":    3,
+		"Insufficient memory": 1,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("reasons = %v, want %v", got, want)
+	}
+}
+
+func TestParseAutoscalerStatus(t *testing.T) {
+	yamlStatus := `time: 2024-05-02 10:00:00.000000 +0000 UTC
+autoscalerStatus: Running
+clusterWide:
+  health:
+    status: Healthy
+    nodeCounts:
+      registered:
+        ready: 4
+  scaleUp:
+    status: InProgress
+  scaleDown:
+    status: NoCandidates
+nodeGroups:
+- name: workers
+  health:
+    status: Healthy
+    nodeCounts:
+      registered:
+        ready: 3
+    cloudProviderTarget: 4
+    minSize: 1
+    maxSize: 10
+  scaleUp:
+    status: InProgress
+  scaleDown:
+    status: NoCandidates
+`
+	textStatus := `Cluster-autoscaler status at 2024-05-02 10:00:00.000000 +0000 UTC:
+Cluster-wide:
+  Health:      Healthy (ready=4 unready=0 notStarted=0 longNotStarted=0 registered=4 longUnregistered=0)
+  ScaleUp:     InProgress (ready=4 registered=4)
+  ScaleDown:   NoCandidates (candidates=0)
+
+NodeGroups:
+  Name:        workers
+  Health:      Healthy (ready=3 unready=0 notStarted=0 longNotStarted=0 registered=3 longUnregistered=0 cloudProviderTarget=4 (minSize=1, maxSize=10))
+  ScaleUp:     InProgress (ready=3 cloudProviderTarget=4)
+  ScaleDown:   NoCandidates (candidates=0)
+`
+	want := AutoscalerNodeGroupStatus{
+		Name: "workers", Health: "Healthy", ScaleUp: "InProgress", ScaleDown: "NoCandidates",
+		Ready: 3, Target: 4, MinSize: 1, MaxSize: 10,
+	}
+	for name, raw := range map[string]string{"yaml": yamlStatus, "text": textStatus} {
+		status := parseAutoscalerStatus(raw)
+		if status.Health != "Healthy" || status.ScaleUp != "InProgress" || status.ScaleDown != "NoCandidates" {
+			t.Errorf("%s: cluster-wide = %s/%s/%s", name, status.Health, status.ScaleUp, status.ScaleDown)
+		}
+		if len(status.NodeGroups) != 1 || status.NodeGroups[0] != want {
+			t.Errorf("%s: node groups = %+v, want [%+v]", name, status.NodeGroups, want)
+		}
+	}
+}
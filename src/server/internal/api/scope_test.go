@@ -0,0 +1,122 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/sonnguyen/kubelens/internal/api"
+	"github.com/sonnguyen/kubelens/internal/apitest"
+	"github.com/sonnguyen/kubelens/internal/auth"
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+// scopedRouter serves the API routes of s behind the real auth.ResourceScope, as the test
+// user holding an API token restricted to the fixture namespace of the test cluster
+func scopedRouter(s *apitest.Server, actions ...string) func(method, path, contentType, body string) *httptest.ResponseRecorder {
+	authHandler := auth.NewHandler(s.DB, "test-secret", nil)
+
+	router := gin.New()
+	v1 := router.Group("/api/v1", func(c *gin.Context) {
+		c.Set("user", s.User)
+		c.Set("user_id", int(s.User.ID))
+		c.Set("username", s.User.Username)
+		c.Set("email", s.User.Email)
+		c.Set("is_admin", true)
+		c.Set("token_permissions", []db.Permission{{
+			Resource:   "*",
+			Actions:    actions,
+			Clusters:   []string{apitest.ClusterName},
+			Namespaces: []string{apitest.FixtureNamespace},
+		}})
+	}, authHandler.ResourceScope())
+	allow := func(resource, action string) gin.HandlerFunc { return func(c *gin.Context) { c.Next() } }
+	api.RegisterRoutes(v1, s.Handler, allow, authHandler.FeatureGate, s.Policy)
+
+	return func(method, path, contentType, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+}
+
+// TestScopedClusterResponses checks that cluster-wide responses are trimmed to the
+// namespace a caller is restricted to, or refused when they cannot be
+func TestScopedClusterResponses(t *testing.T) {
+	limitRange := func(namespace string) *corev1.LimitRange {
+		return &corev1.LimitRange{
+			ObjectMeta: metav1.ObjectMeta{Name: "limits", Namespace: namespace},
+			Spec:       corev1.LimitRangeSpec{Limits: []corev1.LimitRangeItem{{Type: corev1.LimitTypeContainer}}},
+		}
+	}
+	// Pods whose Job is gone
+	orphan := func(namespace string) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name: "migrate-x", Namespace: namespace, Labels: map[string]string{"batch.kubernetes.io/job-name": "migrate"},
+		}}
+	}
+	s := apitest.New(t,
+		limitRange(apitest.FixtureNamespace), limitRange("kube-system"),
+		orphan(apitest.FixtureNamespace), orphan("kube-system"),
+	)
+	for _, namespace := range []string{apitest.FixtureNamespace, "kube-system", ""} {
+		kind := "Pod"
+		if namespace == "" {
+			kind = "Node"
+		}
+		if _, _, err := s.DB.RecordClusterEvent(&db.ClusterEvent{
+			ClusterName: apitest.ClusterName, Fingerprint: "event-" + namespace, Namespace: namespace,
+			Kind: kind, Name: "x", Type: "Warning", Reason: "Test", Count: 1,
+			FirstSeen: time.Now(), LastSeen: time.Now(),
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	do := scopedRouter(s, "read")
+	base := "/api/v1/clusters/" + apitest.ClusterName
+
+	tests := []struct {
+		path string
+		key  string
+	}{
+		{"/events/history", "events"},
+		{"/orphans", "orphans"},
+		{"/limitranges/by-namespace", "namespaces"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			w := do(http.MethodGet, base+tt.path, "", "")
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+			}
+			var resp map[string]interface{}
+			apitest.DecodeJSON(t, w, &resp)
+			items, _ := resp[tt.key].([]interface{})
+			if len(items) != 1 || items[0].(map[string]interface{})["namespace"] != apitest.FixtureNamespace {
+				t.Errorf("%s = %v, want only the items of %s", tt.key, resp[tt.key], apitest.FixtureNamespace)
+			}
+			if total, ok := resp["total"]; ok && total != float64(1) {
+				t.Errorf("total = %v, want 1", total)
+			}
+		})
+	}
+
+	// Documents about the whole cluster cannot be trimmed
+	if w := do(http.MethodGet, base+"/resources-summary", "", ""); w.Code != http.StatusForbidden {
+		t.Errorf("resources summary: status = %d, want 403: %s", w.Code, w.Body.String())
+	}
+	// The cluster itself is readable, but not changed
+	if w := do(http.MethodDelete, base, "", ""); w.Code != http.StatusForbidden {
+		t.Errorf("delete the cluster: status = %d, want 403: %s", w.Code, w.Body.String())
+	}
+}
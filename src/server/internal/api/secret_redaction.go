@@ -0,0 +1,38 @@
+package api
+
+import (
+	"github.com/sonnguyen/kubelens/internal/policy"
+)
+
+// secretsRevealed reports whether responses may carry the values of Secrets, which they
+// may not while the secret_reveal endpoint class is disabled. Endpoints returning any
+// object (describe, fleet views, comparisons, diffs) redact Secrets then, as the Secret
+// endpoints themselves are refused.
+func (h *Handler) secretsRevealed() bool {
+	return h.endpointPolicy == nil || !h.endpointPolicy.IsDisabled(policy.SecretReveal)
+}
+
+// isSecret reports whether an object is a core Secret
+func isSecret(obj map[string]interface{}) bool {
+	kind, _ := obj["kind"].(string)
+	apiVersion, _ := obj["apiVersion"].(string)
+	return kind == "Secret" && apiVersion == "v1"
+}
+
+// redactSecret returns an object with its data and stringData values redacted if it is a
+// Secret and Secret values are not revealed, the object itself otherwise
+func (h *Handler) redactSecret(obj map[string]interface{}) map[string]interface{} {
+	if !isSecret(obj) || h.secretsRevealed() {
+		return obj
+	}
+	redacted, _ := redactSecretValues("", obj).(map[string]interface{})
+	return redacted
+}
+
+// redactSecretChanges redacts the values of the changes to a Secret
+func redactSecretChanges(changes []fieldChange) {
+	for i := range changes {
+		changes[i].Old = redactSecretValues(changes[i].Path, changes[i].Old)
+		changes[i].New = redactSecretValues(changes[i].Path, changes[i].New)
+	}
+}
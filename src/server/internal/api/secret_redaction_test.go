@@ -0,0 +1,101 @@
+package api_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/sonnguyen/kubelens/internal/api"
+	"github.com/sonnguyen/kubelens/internal/apitest"
+	"github.com/sonnguyen/kubelens/internal/db"
+	"github.com/sonnguyen/kubelens/internal/policy"
+)
+
+// secretData returns the data of a Secret in a response
+func secretData(t *testing.T, obj map[string]interface{}) map[string]interface{} {
+	t.Helper()
+	data, ok := obj["data"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("object without data: %v", obj)
+	}
+	return data
+}
+
+func TestDescribeRedactsSecrets(t *testing.T) {
+	s := apitest.New(t, apitest.Fixtures()...)
+	path := "/api/v1/clusters/test/resources/secrets/web-tls/describe?namespace=" + apitest.FixtureNamespace
+
+	var description api.Description
+	apitest.DecodeJSON(t, s.Get(path), &description)
+	if data := secretData(t, description.Object); data["tls.key"] == "<redacted>" {
+		t.Errorf("Secret redacted while secret_reveal is enabled: %v", data)
+	}
+
+	if err := s.Policy.SetClassDisabled(policy.SecretReveal, true); err != nil {
+		t.Fatal(err)
+	}
+	description = api.Description{}
+	apitest.DecodeJSON(t, s.Get(path), &description)
+	if data := secretData(t, description.Object); data["tls.key"] != "<redacted>" || data["tls.crt"] != "<redacted>" {
+		t.Errorf("Secret values returned while secret_reveal is disabled: %v", data)
+	}
+}
+
+func TestFleetRedactsSecrets(t *testing.T) {
+	s := apitest.New(t, apitest.Fixtures()...)
+	if err := s.DB.CreateCluster(&db.Cluster{Name: apitest.ClusterName, AuthType: "token", AuthConfig: db.JSON("{}"), Enabled: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Policy.SetClassDisabled(policy.SecretReveal, true); err != nil {
+		t.Fatal(err)
+	}
+
+	var list api.FleetList
+	apitest.DecodeJSON(t, s.Get("/api/v1/fleet/secrets"), &list)
+	if len(list.Items) == 0 {
+		t.Fatal("no Secrets listed")
+	}
+	for _, item := range list.Items {
+		for key, value := range secretData(t, item.Object) {
+			if value != "<redacted>" {
+				t.Errorf("%s: value of %s returned while secret_reveal is disabled", item.Cluster, key)
+			}
+		}
+	}
+}
+
+func TestCompareRedactsSecrets(t *testing.T) {
+	s := apitest.New(t, apitest.Fixtures()...)
+	s.AddCluster("prod", &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-tls", Namespace: apitest.FixtureNamespace},
+		Type:       corev1.SecretTypeTLS,
+		Data:       map[string][]byte{"tls.crt": []byte("cert"), "tls.key": []byte("prod-key")},
+	})
+	if err := s.Policy.SetClassDisabled(policy.SecretReveal, true); err != nil {
+		t.Fatal(err)
+	}
+
+	ref := func(cluster string) map[string]interface{} {
+		return map[string]interface{}{"cluster": cluster, "namespace": apitest.FixtureNamespace, "kind": "Secret", "name": "web-tls"}
+	}
+	w := s.Do(http.MethodPost, "/api/v1/compare", map[string]interface{}{"left": ref("test"), "right": ref("prod")})
+	if w.Code != http.StatusOK {
+		t.Fatalf("compare: %d %s", w.Code, w.Body.String())
+	}
+	// "a2V5" and "cHJvZC1rZXk=" are the encoded keys
+	if body := w.Body.String(); strings.Contains(body, "a2V5") || strings.Contains(body, "cHJvZC1rZXk=") {
+		t.Errorf("Secret values compared while secret_reveal is disabled: %s", body)
+	}
+	var comparison struct {
+		Changes []struct {
+			Path string `json:"path"`
+		} `json:"changes"`
+	}
+	apitest.DecodeJSON(t, w, &comparison)
+	if len(comparison.Changes) != 1 || comparison.Changes[0].Path != ".data.tls.key" {
+		t.Errorf("changes = %+v, want the changed key without its value", comparison.Changes)
+	}
+}
@@ -0,0 +1,224 @@
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/sonnguyen/kubelens/internal/audit"
+)
+
+// maxCommandLength bounds the command lines recorded, in characters
+const maxCommandLength = 4096
+
+// typedCommand is a command line rebuilt from the keystrokes of a terminal
+type typedCommand struct {
+	text string
+	// edited is set when the line was changed in ways keystrokes do not tell: completion,
+	// history or cursor movement. The command run may differ from text.
+	edited bool
+}
+
+// commandLine rebuilds the command lines typed in a terminal from its keystrokes, with
+// the line editing of shells: backspace, Ctrl-U, Ctrl-W and Ctrl-C
+type commandLine struct {
+	line   []rune
+	edited bool
+	// escape is the escape sequence being read, from its ESC
+	escape []byte
+	// partial holds the bytes of a character split between reads
+	partial []byte
+}
+
+// feed processes keystrokes and returns the command lines they complete
+func (l *commandLine) feed(input []byte) []typedCommand {
+	var commands []typedCommand
+	input = append(l.partial, input...)
+	l.partial = nil
+	for len(input) > 0 {
+		if l.escape != nil {
+			b := input[0]
+			input = input[1:]
+			l.escape = append(l.escape, b)
+			if len(l.escape) == 2 && b != '[' && b != 'O' {
+				// Alt+key
+				l.escape = nil
+				l.edited = true
+			} else if len(l.escape) > 2 && b >= 0x40 && b <= 0x7e {
+				// Bracketed paste markers wrap text typed as is; other sequences move the cursor
+				// or recall history
+				if seq := string(l.escape[2:]); seq != "200~" && seq != "201~" {
+					l.edited = true
+				}
+				l.escape = nil
+			}
+			continue
+		}
+
+		r, size := utf8.DecodeRune(input)
+		if r == utf8.RuneError && size <= 1 {
+			if !utf8.FullRune(input) {
+				l.partial = append([]byte(nil), input...)
+				break
+			}
+			input = input[1:]
+			continue
+		}
+		input = input[size:]
+
+		switch r {
+		case '\r', '\n':
+			text := strings.TrimSpace(string(l.line))
+			if text != "" || l.edited {
+				commands = append(commands, typedCommand{text: text, edited: l.edited})
+			}
+			l.line, l.edited = nil, false
+		case 0x1b: // ESC
+			l.escape = []byte{0x1b}
+		case 0x7f, 0x08: // backspace
+			if len(l.line) > 0 {
+				l.line = l.line[:len(l.line)-1]
+			}
+		case 0x03, 0x15: // Ctrl-C abandons the line, Ctrl-U clears it
+			l.line = nil
+			if r == 0x03 {
+				l.edited = false
+			}
+		case 0x17: // Ctrl-W deletes the word before the cursor
+			end := len(l.line)
+			for end > 0 && l.line[end-1] == ' ' {
+				end--
+			}
+			for end > 0 && l.line[end-1] != ' ' {
+				end--
+			}
+			l.line = l.line[:end]
+		case '\t', 0x12, 0x10, 0x0e, 0x01, 0x05, 0x02, 0x06, 0x0b, 0x19:
+			// Completion, history search and navigation, cursor movement, kill and yank
+			l.edited = true
+		default:
+			if r >= 0x20 {
+				if len(l.line) < maxCommandLength {
+					l.line = append(l.line, r)
+				} else {
+					l.edited = true
+				}
+			}
+		}
+	}
+	return commands
+}
+
+// shellAudit records the commands of an interactive shell as audit events
+type shellAudit struct {
+	c        *gin.Context
+	metadata map[string]interface{}
+	target   string // the pod or node, for descriptions
+
+	mu       sync.Mutex
+	commands int
+}
+
+// newShellAudit starts the audit of a shell session in a pod (node is empty) or in the debug
+// pod of a node. It returns nil when the audit of shell commands is disabled.
+func (h *Handler) newShellAudit(c *gin.Context, cluster, namespace, pod, container, node, shell string) *shellAudit {
+	if !h.shellCommandAudit {
+		return nil
+	}
+	b := make([]byte, 8)
+	rand.Read(b)
+	id := hex.EncodeToString(b)
+	a := &shellAudit{
+		c: c,
+		metadata: map[string]interface{}{
+			"cluster_name":  cluster,
+			"pod":           pod,
+			"container":     container,
+			"shell":         shell,
+			"shell_session": id,
+		},
+	}
+	if node != "" {
+		a.metadata["kind"], a.metadata["name"], a.metadata["node"] = "Node", node, node
+		a.target = "node " + node
+	} else {
+		a.metadata["kind"], a.metadata["name"], a.metadata["namespace"] = "Pod", pod, namespace
+		a.target = fmt.Sprintf("pod %s/%s (%s)", namespace, pod, container)
+	}
+	a.log(audit.EventAuditShellSessionStarted, "Opened a shell in "+a.target, nil)
+	return a
+}
+
+func (a *shellAudit) log(event, description string, extra map[string]interface{}) {
+	userID, exists := a.c.Get("user_id")
+	if !exists {
+		return
+	}
+	username, _ := a.c.Get("username")
+	email, _ := a.c.Get("email")
+	metadata := make(map[string]interface{}, len(a.metadata)+len(extra))
+	for key, value := range a.metadata {
+		metadata[key] = value
+	}
+	for key, value := range extra {
+		metadata[key] = value
+	}
+	audit.Log(a.c, event, userID.(int), username.(string), email.(string), description, metadata)
+}
+
+// command records a command run in the shell
+func (a *shellAudit) command(cmd typedCommand) {
+	a.mu.Lock()
+	a.commands++
+	a.mu.Unlock()
+	text := cmd.text
+	if text == "" {
+		text = "(recalled from history)"
+	}
+	a.log(audit.EventAuditShellCommand, fmt.Sprintf("Ran in %s: %s", a.target, text),
+		map[string]interface{}{"command": cmd.text, "edited": cmd.edited})
+}
+
+// end records the end of the session
+func (a *shellAudit) end(err error) {
+	if a == nil {
+		return
+	}
+	a.mu.Lock()
+	commands := a.commands
+	a.mu.Unlock()
+	extra := map[string]interface{}{"commands": commands}
+	if err != nil {
+		extra["error"] = err.Error()
+	}
+	a.log(audit.EventAuditShellSessionEnded, fmt.Sprintf("Closed the shell in %s after %d commands", a.target, commands), extra)
+}
+
+// reader wraps the input of the shell so the commands typed are recorded
+func (a *shellAudit) reader(r io.Reader) io.Reader {
+	if a == nil {
+		return r
+	}
+	return &auditedReader{r: r, audit: a}
+}
+
+// auditedReader records the commands typed in what it reads
+type auditedReader struct {
+	r     io.Reader
+	line  commandLine
+	audit *shellAudit
+}
+
+func (r *auditedReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	for _, cmd := range r.line.feed(p[:n]) {
+		r.audit.command(cmd)
+	}
+	return n, err
+}
@@ -0,0 +1,33 @@
+package api
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCommandLine(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		input []string // keystrokes, as the terminal sends them
+		want  []typedCommand
+	}{
+		{"typed", []string{"l", "s", " ", "-", "l", "\r"}, []typedCommand{{text: "ls -l"}}},
+		{"pasted", []string{"kubectl get pods\rwhoami\r"}, []typedCommand{{text: "kubectl get pods"}, {text: "whoami"}}},
+		{"bracketed paste", []string{"\x1b[200~cat /etc/hosts\x1b[201~\r"}, []typedCommand{{text: "cat /etc/hosts"}}},
+		{"backspace", []string{"lss\x7f -a\r"}, []typedCommand{{text: "ls -a"}}},
+		{"ctrl-w and ctrl-u", []string{"rm -rf /tmp/x\x17/tmp/y\r", "reboot\x15uptime\r"}, []typedCommand{{text: "rm -rf /tmp/y"}, {text: "uptime"}}},
+		{"ctrl-c", []string{"shutdown now\x03", "\r", "date\r"}, []typedCommand{{text: "date"}}},
+		{"history", []string{"\x1b[A\r"}, []typedCommand{{text: "", edited: true}}},
+		{"completion", []string{"cat /etc/pass\twd\r"}, []typedCommand{{text: "cat /etc/passwd", edited: true}}},
+		{"split escape and rune", []string{"echo caf\xc3", "\xa9 \x1b", "[D\r"}, []typedCommand{{text: "echo café", edited: true}}},
+	} {
+		var line commandLine
+		var got []typedCommand
+		for _, keys := range tc.input {
+			got = append(got, line.feed([]byte(keys))...)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: commands = %+v, want %+v", tc.name, got, tc.want)
+		}
+	}
+}
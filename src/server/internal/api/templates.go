@@ -0,0 +1,449 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/sonnguyen/kubelens/internal/audit"
+	"github.com/sonnguyen/kubelens/internal/db"
+	"github.com/sonnguyen/kubelens/internal/templates"
+)
+
+// templateRequest is the body of CreateTemplate and UpdateTemplate
+type templateRequest struct {
+	Name        string                `json:"name" binding:"required"`
+	Description string                `json:"description"`
+	Category    string                `json:"category"`
+	Manifest    string                `json:"manifest" binding:"required"`
+	Parameters  []templates.Parameter `json:"parameters"`
+}
+
+// templateValues is the body of RenderTemplate, PreviewTemplate and ApplyTemplate
+type templateValues struct {
+	Values map[string]interface{} `json:"values"`
+}
+
+// TemplateObject names an object a template renders
+type TemplateObject struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	Namespace  string `json:"namespace,omitempty"`
+}
+
+// TemplatePreview is what applying one object of a template would do, or why it cannot be
+// applied
+type TemplatePreview struct {
+	*manifestDiff
+	Index int    `json:"index"`
+	Error string `json:"error,omitempty"`
+}
+
+// validateTemplate checks a template request: its parameters, and that the manifest
+// renders to valid objects with sample values
+func validateTemplate(req *templateRequest) error {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || len(req.Name) > 255 {
+		return fmt.Errorf("name must be 1 to 255 characters")
+	}
+	if req.Parameters == nil {
+		req.Parameters = []templates.Parameter{}
+	}
+	if err := templates.ValidateParameters(req.Parameters); err != nil {
+		return err
+	}
+	rendered, err := templates.Check(req.Manifest, req.Parameters)
+	if err != nil {
+		return err
+	}
+	objects, err := decodeManifests(rendered)
+	if err != nil {
+		return fmt.Errorf("template does not render valid manifests: %v", err)
+	}
+	if len(objects) == 0 {
+		return fmt.Errorf("template renders no manifests")
+	}
+	if len(objects) > maxManifestDocuments {
+		return fmt.Errorf("template renders too many manifests (%d), at most %d are allowed", len(objects), maxManifestDocuments)
+	}
+	return nil
+}
+
+// applyTemplateRequest copies a validated request onto a template
+func applyTemplateRequest(tmpl *db.ResourceTemplate, req *templateRequest) error {
+	params, err := json.Marshal(req.Parameters)
+	if err != nil {
+		return err
+	}
+	tmpl.Name, tmpl.Description, tmpl.Category = req.Name, req.Description, strings.TrimSpace(req.Category)
+	tmpl.Manifest, tmpl.Parameters = req.Manifest, db.JSON(params)
+	return nil
+}
+
+// templateParameters returns the parameters of a stored template
+func templateParameters(tmpl *db.ResourceTemplate) ([]templates.Parameter, error) {
+	params := []templates.Parameter{}
+	if len(tmpl.Parameters) == 0 {
+		return params, nil
+	}
+	if err := json.Unmarshal(tmpl.Parameters, &params); err != nil {
+		return nil, fmt.Errorf("invalid parameters of template %s: %v", tmpl.Name, err)
+	}
+	return params, nil
+}
+
+// organizationTemplate returns the template of the given ID in the caller's organization,
+// writing the error response otherwise
+func (h *Handler) organizationTemplate(c *gin.Context) (*db.ResourceTemplate, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid template ID"})
+		return nil, false
+	}
+	tmpl, err := h.db.GetResourceTemplate(uint(id))
+	orgID := c.GetUint("org_id")
+	if err != nil || (orgID != 0 && (tmpl.OrganizationID == nil || *tmpl.OrganizationID != orgID)) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Template not found"})
+		return nil, false
+	}
+	return tmpl, true
+}
+
+// renderTemplate instantiates a template with the values of the request body in the
+// namespace query parameter (default "default"), writing the error response on failure.
+// Objects that set another namespace are refused.
+func (h *Handler) renderTemplate(c *gin.Context, tmpl *db.ResourceTemplate) ([]byte, []*unstructured.Unstructured, bool) {
+	var req templateValues
+	if err := c.ShouldBindJSON(&req); err != nil && c.Request.ContentLength != 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return nil, nil, false
+	}
+	namespace := c.DefaultQuery("namespace", "default")
+	if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid namespace %q: %s", namespace, strings.Join(errs, ", "))})
+		return nil, nil, false
+	}
+
+	params, err := templateParameters(tmpl)
+	if err != nil {
+		log.Errorf("Failed to render template: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render template"})
+		return nil, nil, false
+	}
+	values, err := templates.Values(params, req.Values)
+	if err != nil {
+		problems := []string{}
+		if joined, ok := err.(interface{ Unwrap() []error }); ok {
+			for _, e := range joined.Unwrap() {
+				problems = append(problems, e.Error())
+			}
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid values", "errors": problems})
+		return nil, nil, false
+	}
+
+	rendered, err := templates.Render(tmpl.Manifest, namespace, values)
+	if err == nil {
+		var objects []*unstructured.Unstructured
+		if objects, err = decodeManifests(rendered); err == nil {
+			if err = checkTemplateObjects(objects, namespace); err == nil {
+				return rendered, objects, true
+			}
+		}
+	}
+	c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	return nil, nil, false
+}
+
+// checkTemplateObjects checks that the objects a template rendered are not too many and
+// stay in the namespace it is instantiated in
+func checkTemplateObjects(objects []*unstructured.Unstructured, namespace string) error {
+	if len(objects) == 0 {
+		return errors.New("template renders no manifests")
+	}
+	if len(objects) > maxManifestDocuments {
+		return fmt.Errorf("template renders too many manifests (%d), at most %d are allowed", len(objects), maxManifestDocuments)
+	}
+	for _, obj := range objects {
+		if ns := obj.GetNamespace(); ns != "" && ns != namespace {
+			return fmt.Errorf("%s %s is in namespace %s, not %s", obj.GetKind(), obj.GetName(), ns, namespace)
+		}
+	}
+	return nil
+}
+
+// auditTemplate records a change to a template
+func (h *Handler) auditTemplate(c *gin.Context, tmpl *db.ResourceTemplate, action string) {
+	if userID, exists := c.Get("user_id"); exists {
+		username, _ := c.Get("username")
+		email, _ := c.Get("email")
+
+		audit.Log(c, audit.EventAuditConfigChanged, userID.(int), username.(string), email.(string),
+			fmt.Sprintf("Resource template %s %s", tmpl.Name, action),
+			map[string]interface{}{
+				"template_id": tmpl.ID,
+				"template":    tmpl.Name,
+			})
+	}
+}
+
+// ListTemplates handles GET /api/v1/templates: the templates of the caller's organization.
+// Query: category
+func (h *Handler) ListTemplates(c *gin.Context) {
+	list, err := h.db.ListResourceTemplates(c.GetUint("org_id"))
+	if err != nil {
+		log.Errorf("Failed to list templates: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve templates"})
+		return
+	}
+	if category := c.Query("category"); category != "" {
+		filtered := list[:0]
+		for _, tmpl := range list {
+			if tmpl.Category == category {
+				filtered = append(filtered, tmpl)
+			}
+		}
+		list = filtered
+	}
+	c.JSON(http.StatusOK, gin.H{"templates": list})
+}
+
+// GetTemplate handles GET /api/v1/templates/:id
+func (h *Handler) GetTemplate(c *gin.Context) {
+	tmpl, ok := h.organizationTemplate(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, tmpl)
+}
+
+// CreateTemplate handles POST /api/v1/templates. The manifest is rendered with sample
+// values first, so that templates that cannot produce valid manifests are refused.
+func (h *Handler) CreateTemplate(c *gin.Context) {
+	var req templateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+	if err := validateTemplate(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	orgID := c.GetUint("org_id")
+	if taken, err := h.db.ResourceTemplateNameTaken(orgID, req.Name, 0); err != nil {
+		log.Errorf("Failed to create template: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create template"})
+		return
+	} else if taken {
+		c.JSON(http.StatusConflict, gin.H{"error": "A template with this name already exists"})
+		return
+	}
+
+	tmpl := &db.ResourceTemplate{CreatedBy: uint(c.GetInt("user_id"))}
+	if err := applyTemplateRequest(tmpl, &req); err != nil {
+		log.Errorf("Failed to create template: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create template"})
+		return
+	}
+	if orgID != 0 {
+		tmpl.OrganizationID = &orgID
+	}
+	if err := h.db.CreateResourceTemplate(tmpl); err != nil {
+		log.Errorf("Failed to create template: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create template"})
+		return
+	}
+	h.auditTemplate(c, tmpl, "created")
+	c.JSON(http.StatusCreated, tmpl)
+}
+
+// UpdateTemplate handles PUT /api/v1/templates/:id
+func (h *Handler) UpdateTemplate(c *gin.Context) {
+	tmpl, ok := h.organizationTemplate(c)
+	if !ok {
+		return
+	}
+	var req templateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+	if err := validateTemplate(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var orgID uint
+	if tmpl.OrganizationID != nil {
+		orgID = *tmpl.OrganizationID
+	}
+	if taken, err := h.db.ResourceTemplateNameTaken(orgID, req.Name, tmpl.ID); err != nil {
+		log.Errorf("Failed to update template: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update template"})
+		return
+	} else if taken {
+		c.JSON(http.StatusConflict, gin.H{"error": "A template with this name already exists"})
+		return
+	}
+
+	if err := applyTemplateRequest(tmpl, &req); err != nil {
+		log.Errorf("Failed to update template: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update template"})
+		return
+	}
+	if err := h.db.UpdateResourceTemplate(tmpl); err != nil {
+		log.Errorf("Failed to update template: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update template"})
+		return
+	}
+	h.auditTemplate(c, tmpl, "updated")
+	c.JSON(http.StatusOK, tmpl)
+}
+
+// DeleteTemplate handles DELETE /api/v1/templates/:id
+func (h *Handler) DeleteTemplate(c *gin.Context) {
+	tmpl, ok := h.organizationTemplate(c)
+	if !ok {
+		return
+	}
+	if err := h.db.DeleteResourceTemplate(tmpl.ID); err != nil {
+		log.Errorf("Failed to delete template: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete template"})
+		return
+	}
+	h.auditTemplate(c, tmpl, "deleted")
+	c.JSON(http.StatusOK, gin.H{"message": "Template deleted"})
+}
+
+// RenderTemplate handles POST /api/v1/templates/:id/render: the manifests a template
+// produces with the values of the body, without touching any cluster.
+// Body: {"values": {"name": "web", "replicas": 2}}. Query: namespace (default "default")
+func (h *Handler) RenderTemplate(c *gin.Context) {
+	tmpl, ok := h.organizationTemplate(c)
+	if !ok {
+		return
+	}
+	rendered, objects, ok := h.renderTemplate(c, tmpl)
+	if !ok {
+		return
+	}
+	names := make([]TemplateObject, len(objects))
+	for i, obj := range objects {
+		names[i] = TemplateObject{APIVersion: obj.GetAPIVersion(), Kind: obj.GetKind(), Name: obj.GetName(), Namespace: obj.GetNamespace()}
+	}
+	c.JSON(http.StatusOK, gin.H{"manifest": string(rendered), "objects": names})
+}
+
+// PreviewTemplate handles POST /api/v1/clusters/:name/templates/:id/preview: what applying
+// a template would create or change in the cluster, object by object, computed by the API
+// server with a dry run. Body and query as for RenderTemplate.
+func (h *Handler) PreviewTemplate(c *gin.Context) {
+	clusterName := c.Param("name")
+	tmpl, ok := h.organizationTemplate(c)
+	if !ok {
+		return
+	}
+	rendered, objects, ok := h.renderTemplate(c, tmpl)
+	if !ok {
+		return
+	}
+	namespace := c.DefaultQuery("namespace", "default")
+	if err := h.checkObjectsScope(c, clusterName, objects, namespace, "read"); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	client, err := h.dynamicClient(c, clusterName)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	previews := make([]TemplatePreview, len(objects))
+	failed := 0
+	for _, i := range sortForApply(objects) {
+		obj := objects[i]
+		previews[i] = TemplatePreview{Index: i}
+		mapping, err := h.mappingFor(clusterName, obj.GroupVersionKind())
+		if err == nil {
+			if !isNamespaced(mapping) {
+				obj.SetNamespace("")
+			} else if obj.GetNamespace() == "" {
+				obj.SetNamespace(namespace)
+			}
+			previews[i].manifestDiff, err = dryRunDiff(context.Background(), client.Resource(mapping.Resource).Namespace(obj.GetNamespace()), mapping.GroupVersionKind.Kind, obj, !h.secretsRevealed())
+		}
+		if err != nil {
+			previews[i].manifestDiff = &manifestDiff{APIVersion: obj.GetAPIVersion(), Kind: obj.GetKind(), Name: obj.GetName(), Namespace: obj.GetNamespace()}
+			previews[i].Error = err.Error()
+			failed++
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"manifest": string(rendered), "objects": previews, "failed": failed})
+}
+
+// ApplyTemplate handles POST /api/v1/clusters/:name/templates/:id/apply: renders a template
+// and applies the objects with server-side apply, in dependency order, as ApplyManifests
+// does. Body and query as for RenderTemplate, plus dryRun=true.
+func (h *Handler) ApplyTemplate(c *gin.Context) {
+	clusterName := c.Param("name")
+	tmpl, ok := h.organizationTemplate(c)
+	if !ok {
+		return
+	}
+	_, objects, ok := h.renderTemplate(c, tmpl)
+	if !ok {
+		return
+	}
+	namespace := c.DefaultQuery("namespace", "default")
+	if err := h.checkObjectsScope(c, clusterName, objects, namespace, "create", "update"); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	results, failed, err := h.applyObjects(c, clusterName, objects, namespace, false)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if failed > 0 {
+		log.Warnf("Applied template %s to cluster %s: %d of %d objects failed", tmpl.Name, clusterName, failed, len(objects))
+	}
+
+	if userID, exists := c.Get("user_id"); exists && dryRunValue(c) == nil {
+		username, _ := c.Get("username")
+		email, _ := c.Get("email")
+
+		objectNames := make([]string, 0, len(results))
+		for _, r := range results {
+			if r.Error == "" {
+				objectNames = append(objectNames, manifestResultName(r))
+			}
+		}
+		audit.Log(c, audit.EventAuditResourceCreated, userID.(int), username.(string), email.(string),
+			fmt.Sprintf("Applied template %s to cluster %s namespace %s (%d objects, %d failed)", tmpl.Name, clusterName, namespace, len(objects)-failed, failed),
+			map[string]interface{}{
+				"cluster_name": clusterName,
+				"namespace":    namespace,
+				"template_id":  tmpl.ID,
+				"template":     tmpl.Name,
+				"objects":      objectNames,
+				"failed":       failed,
+			})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"template": tmpl.Name,
+		"results":  results,
+		"total":    len(objects),
+		"applied":  len(objects) - failed,
+		"failed":   failed,
+	})
+}
@@ -0,0 +1,136 @@
+package api_test
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/sonnguyen/kubelens/internal/apitest"
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+const webTemplate = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{ .Values.name }}
+  namespace: {{ .Namespace }}
+spec:
+  replicas: {{ .Values.replicas }}
+  selector:
+    matchLabels:
+      app: {{ .Values.name }}
+  template:
+    metadata:
+      labels:
+        app: {{ .Values.name }}
+    spec:
+      containers:
+      - name: app
+        image: {{ quote .Values.image }}
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: {{ .Values.name }}
+spec:
+  selector:
+    app: {{ .Values.name }}
+  ports:
+  - port: 80
+{{- if .Values.host }}
+---
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: {{ .Values.name }}
+spec:
+  rules:
+  - host: {{ .Values.host }}
+{{- end }}
+`
+
+func TestTemplates(t *testing.T) {
+	s := apitest.New(t, apitest.Fixtures()...)
+
+	params := []map[string]interface{}{
+		{"name": "name", "required": true, "pattern": "[a-z][a-z0-9-]*"},
+		{"name": "image", "required": true},
+		{"name": "replicas", "type": "number", "default": 2},
+		{"name": "host"},
+	}
+	w := s.Do(http.MethodPost, "/api/v1/templates", map[string]interface{}{"name": "web app", "category": "web", "manifest": webTemplate, "parameters": params})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: %d %s", w.Code, w.Body.String())
+	}
+	var tmpl db.ResourceTemplate
+	apitest.DecodeJSON(t, w, &tmpl)
+	id := strconv.Itoa(int(tmpl.ID))
+
+	for _, body := range []map[string]interface{}{
+		{"name": "broken", "manifest": "kind: {{ .Values.kind"},
+		{"name": "undeclared", "manifest": "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: {{ .Values.missing }}\n"},
+		{"name": "not yaml", "manifest": "just text"},
+		{"name": "bad parameter", "manifest": webTemplate, "parameters": []map[string]interface{}{{"name": "a-b"}}},
+	} {
+		if w := s.Do(http.MethodPost, "/api/v1/templates", body); w.Code != http.StatusBadRequest {
+			t.Errorf("create %s: %d, want 400", body["name"], w.Code)
+		}
+	}
+	if w := s.Do(http.MethodPost, "/api/v1/templates", map[string]interface{}{"name": "web app", "manifest": webTemplate, "parameters": params}); w.Code != http.StatusConflict {
+		t.Errorf("duplicate name: %d, want 409", w.Code)
+	}
+
+	// Rendering checks the values and fills in the defaults
+	var rendered struct {
+		Manifest string `json:"manifest"`
+		Objects  []struct {
+			Kind      string `json:"kind"`
+			Name      string `json:"name"`
+			Namespace string `json:"namespace"`
+		} `json:"objects"`
+	}
+	w = s.Do(http.MethodPost, "/api/v1/templates/"+id+"/render?namespace=shop", map[string]interface{}{"values": map[string]interface{}{"name": "api", "image": "api:1.0"}})
+	if w.Code != http.StatusOK {
+		t.Fatalf("render: %d %s", w.Code, w.Body.String())
+	}
+	apitest.DecodeJSON(t, w, &rendered)
+	if len(rendered.Objects) != 2 || rendered.Objects[0].Kind != "Deployment" || rendered.Objects[0].Namespace != "shop" {
+		t.Errorf("rendered objects = %+v, want the deployment in shop and its service", rendered.Objects)
+	}
+	if !strings.Contains(rendered.Manifest, "replicas: 2") || !strings.Contains(rendered.Manifest, `image: "api:1.0"`) {
+		t.Errorf("rendered manifest:\n%s", rendered.Manifest)
+	}
+
+	var invalid struct {
+		Errors []string `json:"errors"`
+	}
+	w = s.Do(http.MethodPost, "/api/v1/templates/"+id+"/render", map[string]interface{}{"values": map[string]interface{}{"name": "Bad Name", "replicas": "many", "extra": "x"}})
+	apitest.DecodeJSON(t, w, &invalid)
+	if w.Code != http.StatusBadRequest || len(invalid.Errors) != 4 {
+		t.Errorf("invalid values: %d %v, want 400 with the name, image, replicas and extra problems", w.Code, invalid.Errors)
+	}
+	// Values cannot write their own YAML
+	if w := s.Do(http.MethodPost, "/api/v1/templates/"+id+"/render", map[string]interface{}{"values": map[string]interface{}{"name": "api", "image": "api:1.0", "host": "x\nnamespace: kube-system"}}); w.Code != http.StatusBadRequest {
+		t.Errorf("multi-line value: %d, want 400", w.Code)
+	}
+
+	// A template cannot write outside the namespace it is instantiated in
+	pinned := strings.Replace(webTemplate, "namespace: {{ .Namespace }}", "namespace: kube-system", 1)
+	w = s.Do(http.MethodPost, "/api/v1/templates", map[string]interface{}{"name": "pinned", "manifest": pinned, "parameters": params})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create pinned: %d %s", w.Code, w.Body.String())
+	}
+	var other db.ResourceTemplate
+	apitest.DecodeJSON(t, w, &other)
+	if w := s.Do(http.MethodPost, "/api/v1/templates/"+strconv.Itoa(int(other.ID))+"/render?namespace=shop", map[string]interface{}{"values": map[string]interface{}{"name": "api", "image": "api:1.0"}}); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("object in another namespace: %d, want 422", w.Code)
+	}
+
+	if w := s.Do(http.MethodDelete, "/api/v1/templates/"+id, nil); w.Code != http.StatusOK {
+		t.Errorf("delete: %d %s", w.Code, w.Body.String())
+	}
+	if w := s.Get("/api/v1/templates/" + id); w.Code != http.StatusNotFound {
+		t.Errorf("get deleted: %d, want 404", w.Code)
+	}
+}
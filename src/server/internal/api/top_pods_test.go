@@ -0,0 +1,128 @@
+package api_test
+
+import (
+	"net/http"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+
+	"github.com/sonnguyen/kubelens/internal/api"
+	"github.com/sonnguyen/kubelens/internal/apitest"
+)
+
+func podMetrics(namespace, name, cpu, memory string) *metricsv1beta1.PodMetrics {
+	return &metricsv1beta1.PodMetrics{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Containers: []metricsv1beta1.ContainerMetrics{{
+			Name:  "main",
+			Usage: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu), corev1.ResourceMemory: resource.MustParse(memory)},
+		}},
+	}
+}
+
+type topPods struct {
+	Pods   []api.TopPod `json:"pods"`
+	Total  int          `json:"total"`
+	SortBy string       `json:"sortBy"`
+}
+
+func newTopPodsServer(t *testing.T) *apitest.Server {
+	database := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "db-0", Namespace: apitest.FixtureNamespace},
+		Spec: corev1.PodSpec{NodeName: "node-1", Containers: []corev1.Container{{
+			Name: "db",
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m"), corev1.ResourceMemory: resource.MustParse("1Gi")},
+				Limits:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1"), corev1.ResourceMemory: resource.MustParse("2Gi")},
+			},
+		}}},
+	}
+	s := apitest.New(t, append(apitest.Fixtures(), database)...)
+	s.AddMetrics(apitest.ClusterName,
+		podMetrics(apitest.FixtureNamespace, "web-5d8f-abcde", "50m", "256Mi"),
+		podMetrics(apitest.FixtureNamespace, "db-0", "400m", "128Mi"),
+		// A pod that is gone by the time its metrics are read
+		podMetrics("kube-system", "coredns-1", "10m", "20Mi"),
+	)
+	return s
+}
+
+func TestGetTopPods(t *testing.T) {
+	s := newTopPodsServer(t)
+	base := "/api/v1/clusters/" + apitest.ClusterName + "/metrics/pods"
+
+	var top topPods
+	w := s.Get(base)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body.String())
+	}
+	apitest.DecodeJSON(t, w, &top)
+	if top.Total != 3 || len(top.Pods) != 3 || top.SortBy != "cpu" {
+		t.Fatalf("top = %+v, want the 3 pods with metrics sorted by cpu", top)
+	}
+	db := top.Pods[0]
+	if db.Name != "db-0" || db.Usage.CPU != 400 || db.Requests.CPU != 500 || db.Limits.CPU != 1000 || db.NodeName != "node-1" {
+		t.Errorf("top pod = %+v, want db-0 with its usage, requests and limits", db)
+	}
+	if db.CPURequestPercent != 80 {
+		t.Errorf("cpuRequestPercent = %v, want 80", db.CPURequestPercent)
+	}
+	if coredns := top.Pods[2]; coredns.Name != "coredns-1" || coredns.Requests.CPU != 0 || coredns.CPURequestPercent != 0 {
+		t.Errorf("pod without spec = %+v, want usage only", coredns)
+	}
+
+	top = topPods{}
+	apitest.DecodeJSON(t, s.Get(base+"?sortBy=memory&limit=1"), &top)
+	if top.Total != 3 || len(top.Pods) != 1 || top.Pods[0].Name != "web-5d8f-abcde" {
+		t.Errorf("top by memory = %+v, want web-5d8f-abcde of 3", top)
+	}
+	if top.Pods[0].MemoryRequestPercent != 200 {
+		t.Errorf("memoryRequestPercent = %v, want 200", top.Pods[0].MemoryRequestPercent)
+	}
+
+	top = topPods{}
+	apitest.DecodeJSON(t, s.Get(base+"?namespace="+apitest.FixtureNamespace), &top)
+	if top.Total != 2 {
+		t.Errorf("top in %s = %+v, want 2 pods", apitest.FixtureNamespace, top)
+	}
+
+	for _, query := range []string{"?sortBy=disk", "?limit=0", "?limit=many"} {
+		if w := s.Get(base + query); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", query, w.Code)
+		}
+	}
+}
+
+func TestGetTopPodsWithoutMetricsServer(t *testing.T) {
+	s := apitest.New(t, apitest.Fixtures()...)
+	if w := s.Get("/api/v1/clusters/" + apitest.ClusterName + "/metrics/pods"); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want 503: %s", w.Code, w.Body.String())
+	}
+	if w := s.Get("/api/v1/clusters/missing/metrics/pods"); w.Code != http.StatusNotFound {
+		t.Errorf("unknown cluster: status = %d, want 404", w.Code)
+	}
+}
+
+func TestGetTopPodsScoped(t *testing.T) {
+	s := newTopPodsServer(t)
+	do := scopedRouter(s, "read")
+
+	// Pods of other namespaces are dropped and the total recounted
+	w := do(http.MethodGet, "/api/v1/clusters/"+apitest.ClusterName+"/metrics/pods", "", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body.String())
+	}
+	var top topPods
+	apitest.DecodeJSON(t, w, &top)
+	if top.Total != 2 || len(top.Pods) != 2 {
+		t.Fatalf("top = %+v, want the 2 pods of %s", top, apitest.FixtureNamespace)
+	}
+	for _, pod := range top.Pods {
+		if pod.Namespace != apitest.FixtureNamespace {
+			t.Errorf("pod %s/%s is outside the scope", pod.Namespace, pod.Name)
+		}
+	}
+}
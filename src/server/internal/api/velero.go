@@ -0,0 +1,583 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/sonnguyen/kubelens/internal/audit"
+)
+
+// veleroKinds are the Velero objects served under /clusters/:name/velero/:kind
+var veleroKinds = map[string]schema.GroupVersionKind{
+	"backups":   {Group: "velero.io", Version: "v1", Kind: "Backup"},
+	"restores":  {Group: "velero.io", Version: "v1", Kind: "Restore"},
+	"schedules": {Group: "velero.io", Version: "v1", Kind: "Schedule"},
+}
+
+const (
+	// defaultVeleroNamespace is the namespace Velero is installed in unless the request
+	// names another one
+	defaultVeleroNamespace = "velero"
+	// veleroTrackTimeout bounds how long a backup or restore started from kubelens is
+	// followed
+	veleroTrackTimeout = 6 * time.Hour
+	// veleroEventSource is the component of the Events recorded for backup and restore
+	// progress
+	veleroEventSource = "kubelens"
+)
+
+// veleroPollInterval is how often a backup or restore started from kubelens is checked;
+// a variable so tests can shorten it
+var veleroPollInterval = 10 * time.Second
+
+// veleroFinalPhases are the phases after which a backup or restore no longer changes
+var veleroFinalPhases = map[string]bool{
+	"Completed":        true,
+	"PartiallyFailed":  true,
+	"Failed":           true,
+	"FailedValidation": true,
+}
+
+// VeleroResource is the status of a Velero Backup, Restore or Schedule
+type VeleroResource struct {
+	Kind       string   `json:"kind"`
+	Name       string   `json:"name"`
+	Namespace  string   `json:"namespace"`
+	Phase      string   `json:"phase"`
+	Namespaces []string `json:"includedNamespaces,omitempty"`
+	// Backups and restores
+	ItemsTotal      int64  `json:"itemsTotal,omitempty"`
+	ItemsDone       int64  `json:"itemsDone,omitempty"` // backed up or restored
+	Errors          int64  `json:"errors,omitempty"`
+	Warnings        int64  `json:"warnings,omitempty"`
+	FailureReason   string `json:"failureReason,omitempty"`
+	StartedAt       string `json:"startedAt,omitempty"`
+	CompletedAt     string `json:"completedAt,omitempty"`
+	Expiration      string `json:"expiration,omitempty"`      // backups
+	StorageLocation string `json:"storageLocation,omitempty"` // backups
+	Backup          string `json:"backup,omitempty"`          // restores
+	Schedule        string `json:"schedule,omitempty"`        // restores and backups of a schedule
+	// Schedules
+	Cron       string `json:"cron,omitempty"`
+	Paused     bool   `json:"paused,omitempty"`
+	LastBackup string `json:"lastBackup,omitempty"`
+	CreatedAt  string `json:"createdAt"`
+}
+
+// veleroBackupRequest is the body of POST /clusters/:name/velero/backups
+type veleroBackupRequest struct {
+	Name               string   `json:"name"` // default: kubelens-<timestamp>
+	IncludedNamespaces []string `json:"includedNamespaces"`
+	ExcludedResources  []string `json:"excludedResources"`
+	LabelSelector      string   `json:"labelSelector"`
+	TTL                string   `json:"ttl"` // e.g. 720h; default: the Velero server default
+	StorageLocation    string   `json:"storageLocation"`
+	SnapshotVolumes    *bool    `json:"snapshotVolumes"`
+	// DefaultVolumesToFsBackup backs up pod volumes with the file system uploader
+	DefaultVolumesToFsBackup *bool `json:"defaultVolumesToFsBackup"`
+}
+
+// veleroRestoreRequest is the body of POST /clusters/:name/velero/restores
+type veleroRestoreRequest struct {
+	Name               string            `json:"name"` // default: <backup>-<timestamp>
+	BackupName         string            `json:"backupName"`
+	ScheduleName       string            `json:"scheduleName"` // restore the latest backup of a schedule
+	IncludedNamespaces []string          `json:"includedNamespaces"`
+	NamespaceMapping   map[string]string `json:"namespaceMapping"`
+	RestorePVs         *bool             `json:"restorePVs"`
+	// ExistingResourcePolicy is none (default: existing objects are skipped) or update
+	ExistingResourcePolicy string `json:"existingResourcePolicy"`
+}
+
+// veleroResource returns the client of a Velero kind, or installed=false when Velero is
+// not installed
+func (h *Handler) veleroResource(c *gin.Context, clusterName, kind string) (dynamic.NamespaceableResourceInterface, bool, error) {
+	gvk, ok := veleroKinds[kind]
+	if !ok {
+		return nil, false, fmt.Errorf("unknown Velero kind %q (backups, restores or schedules)", kind)
+	}
+	client, err := h.dynamicClient(c, clusterName)
+	if err != nil {
+		return nil, false, err
+	}
+	mapping, installed, err := h.optionalMapping(clusterName, gvk)
+	if err != nil || !installed {
+		return nil, false, err
+	}
+	return client.Resource(mapping.Resource), true, nil
+}
+
+// veleroNamespace is the namespace Velero runs in: the namespace query param, or velero
+func veleroNamespace(c *gin.Context) string {
+	if namespace := c.Query("namespace"); namespace != "" {
+		return namespace
+	}
+	return defaultVeleroNamespace
+}
+
+// ListVeleroResources handles GET /clusters/:name/velero/:kind, newest first.
+// Query: namespace (the Velero namespace, default velero)
+func (h *Handler) ListVeleroResources(c *gin.Context) {
+	clusterName := c.Param("name")
+	kind := c.Param("kind")
+
+	if _, ok := veleroKinds[kind]; !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("unknown Velero kind %q (backups, restores or schedules)", kind)})
+		return
+	}
+	resource, installed, err := h.veleroResource(c, clusterName, kind)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	items := []VeleroResource{}
+	if installed {
+		list, err := resource.Namespace(veleroNamespace(c)).List(context.Background(), metav1.ListOptions{})
+		if err != nil {
+			if apierrors.IsForbidden(err) {
+				c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+				return
+			}
+			log.Errorf("Failed to list Velero %s in cluster %s: %v", kind, clusterName, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		for i := range list.Items {
+			items = append(items, veleroStatus(&list.Items[i]))
+		}
+		sort.Slice(items, func(i, j int) bool {
+			if items[i].CreatedAt != items[j].CreatedAt {
+				return items[i].CreatedAt > items[j].CreatedAt
+			}
+			return items[i].Name < items[j].Name
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"installed": installed, "namespace": veleroNamespace(c), "items": items})
+}
+
+// GetVeleroResource handles GET /clusters/:name/velero/:kind/:resname with the status and,
+// for backups and restores, the validation errors.
+// Query: namespace (the Velero namespace, default velero)
+func (h *Handler) GetVeleroResource(c *gin.Context) {
+	clusterName := c.Param("name")
+	kind := c.Param("kind")
+
+	if _, ok := veleroKinds[kind]; !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("unknown Velero kind %q (backups, restores or schedules)", kind)})
+		return
+	}
+	resource, installed, err := h.veleroResource(c, clusterName, kind)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if !installed {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Velero is not installed in this cluster"})
+		return
+	}
+	obj, err := resource.Namespace(veleroNamespace(c)).Get(context.Background(), c.Param("resname"), metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		log.Errorf("Failed to get Velero %s %s: %v", kind, c.Param("resname"), err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	validationErrors, _, _ := unstructured.NestedStringSlice(obj.Object, "status", "validationErrors")
+	if validationErrors == nil {
+		validationErrors = []string{}
+	}
+	spec, _, _ := unstructured.NestedMap(obj.Object, "spec")
+	c.JSON(http.StatusOK, gin.H{
+		"resource":         veleroStatus(obj),
+		"spec":             spec,
+		"validationErrors": validationErrors,
+	})
+}
+
+// CreateVeleroBackup handles POST /clusters/:name/velero/backups and starts an on-demand
+// backup of the selected namespaces. Its progress is recorded as Events of the Backup.
+// Query: namespace (the Velero namespace, default velero)
+func (h *Handler) CreateVeleroBackup(c *gin.Context) {
+	var req veleroBackupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(req.IncludedNamespaces) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "includedNamespaces is required"})
+		return
+	}
+	if err := validateNames("namespace", req.IncludedNamespaces); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Name == "" {
+		req.Name = "kubelens-" + time.Now().UTC().Format("20060102150405")
+	}
+	if err := validateNames("name", []string{req.Name}); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	spec := map[string]interface{}{"includedNamespaces": toInterfaceSlice(req.IncludedNamespaces)}
+	if len(req.ExcludedResources) > 0 {
+		spec["excludedResources"] = toInterfaceSlice(req.ExcludedResources)
+	}
+	if req.LabelSelector != "" {
+		selector, err := metav1.ParseToLabelSelector(req.LabelSelector)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid labelSelector: %v", err)})
+			return
+		}
+		selectorMap, err := runtime.DefaultUnstructuredConverter.ToUnstructured(selector)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid labelSelector: %v", err)})
+			return
+		}
+		spec["labelSelector"] = selectorMap
+	}
+	if req.TTL != "" {
+		ttl, err := time.ParseDuration(req.TTL)
+		if err != nil || ttl <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "ttl must be a positive duration such as 720h"})
+			return
+		}
+		spec["ttl"] = ttl.String()
+	}
+	if req.StorageLocation != "" {
+		spec["storageLocation"] = req.StorageLocation
+	}
+	if req.SnapshotVolumes != nil {
+		spec["snapshotVolumes"] = *req.SnapshotVolumes
+	}
+	if req.DefaultVolumesToFsBackup != nil {
+		spec["defaultVolumesToFsBackup"] = *req.DefaultVolumesToFsBackup
+	}
+
+	h.createVeleroObject(c, "backups", req.Name, spec)
+}
+
+// CreateVeleroRestore handles POST /clusters/:name/velero/restores and restores a backup,
+// or the latest backup of a schedule. Its progress is recorded as Events of the Restore.
+// Query: namespace (the Velero namespace, default velero)
+func (h *Handler) CreateVeleroRestore(c *gin.Context) {
+	var req veleroRestoreRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if (req.BackupName == "") == (req.ScheduleName == "") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "exactly one of backupName and scheduleName is required"})
+		return
+	}
+	source := req.BackupName + req.ScheduleName
+	if req.Name == "" {
+		req.Name = source + "-" + time.Now().UTC().Format("20060102150405")
+	}
+	if err := validateNames("name", []string{req.Name, source}); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validateNames("namespace", req.IncludedNamespaces); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	switch req.ExistingResourcePolicy {
+	case "", "none", "update":
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "existingResourcePolicy must be none or update"})
+		return
+	}
+
+	spec := map[string]interface{}{}
+	if req.BackupName != "" {
+		spec["backupName"] = req.BackupName
+	} else {
+		spec["scheduleName"] = req.ScheduleName
+	}
+	if len(req.IncludedNamespaces) > 0 {
+		spec["includedNamespaces"] = toInterfaceSlice(req.IncludedNamespaces)
+	}
+	if len(req.NamespaceMapping) > 0 {
+		mapping := map[string]interface{}{}
+		for from, to := range req.NamespaceMapping {
+			if err := validateNames("namespace", []string{from, to}); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			mapping[from] = to
+		}
+		spec["namespaceMapping"] = mapping
+	}
+	if req.RestorePVs != nil {
+		spec["restorePVs"] = *req.RestorePVs
+	}
+	if req.ExistingResourcePolicy != "" {
+		spec["existingResourcePolicy"] = req.ExistingResourcePolicy
+	}
+
+	h.createVeleroObject(c, "restores", req.Name, spec)
+}
+
+// createVeleroObject creates a Backup or Restore, follows its progress in the background and
+// responds with its status
+func (h *Handler) createVeleroObject(c *gin.Context, kind, name string, spec map[string]interface{}) {
+	clusterName := c.Param("name")
+	resource, installed, err := h.veleroResource(c, clusterName, kind)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if !installed {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Velero is not installed in this cluster"})
+		return
+	}
+
+	gvk := veleroKinds[kind]
+	namespace := veleroNamespace(c)
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": gvk.GroupVersion().String(),
+		"kind":       gvk.Kind,
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": namespace,
+			"labels":    map[string]interface{}{"app.kubernetes.io/managed-by": "kubelens"},
+		},
+		"spec": spec,
+	}}
+	created, err := resource.Namespace(namespace).Create(context.Background(), obj, createOptions(c))
+	if err != nil {
+		if apierrors.IsAlreadyExists(err) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if apierrors.IsForbidden(err) || apierrors.IsInvalid(err) || apierrors.IsNotFound(err) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
+		log.Errorf("Failed to create Velero %s %s: %v", gvk.Kind, name, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if dryRunValue(c) == nil {
+		go h.trackVelero(clusterName, kind, namespace, name)
+	}
+
+	// Audit log
+	if userID, exists := c.Get("user_id"); exists {
+		username, _ := c.Get("username")
+		email, _ := c.Get("email")
+		audit.Log(c, audit.EventAuditResourceCreated, userID.(int), username.(string), email.(string),
+			fmt.Sprintf("Created Velero %s %s/%s", gvk.Kind, namespace, name),
+			map[string]interface{}{
+				"cluster_name": clusterName,
+				"namespace":    namespace,
+				"kind":         gvk.Kind,
+				"name":         name,
+				"spec":         spec,
+			})
+	}
+
+	c.JSON(http.StatusCreated, veleroStatus(created))
+}
+
+// trackVelero follows a backup or restore until it finishes, recording each phase change
+// as an Event of the object. The event watcher picks them up, so progress shows in the
+// event history and live event stream and failures reach the notification channels.
+func (h *Handler) trackVelero(clusterName, kind, namespace, name string) {
+	client, err := h.clusterManager.GetClient(clusterName)
+	if err != nil {
+		return
+	}
+	dynamicClient, err := h.clusterManager.GetDynamicClient(clusterName)
+	if err != nil {
+		return
+	}
+	mapping, installed, err := h.optionalMapping(clusterName, veleroKinds[kind])
+	if err != nil || !installed {
+		return
+	}
+	resource := dynamicClient.Resource(mapping.Resource).Namespace(namespace)
+
+	ctx, cancel := context.WithTimeout(context.Background(), veleroTrackTimeout)
+	defer cancel()
+	ticker := time.NewTicker(veleroPollInterval)
+	defer ticker.Stop()
+
+	lastPhase := ""
+	for {
+		obj, err := resource.Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			if !apierrors.IsNotFound(err) && ctx.Err() == nil {
+				log.Warnf("Stopped following Velero %s %s/%s: %v", kind, namespace, name, err)
+			}
+			return
+		}
+		status := veleroStatus(obj)
+		if status.Phase != "" && status.Phase != lastPhase {
+			lastPhase = status.Phase
+			recordVeleroEvent(ctx, client, obj, status)
+		}
+		if veleroFinalPhases[status.Phase] {
+			return
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// recordVeleroEvent records the phase of a backup or restore as an Event of the object
+func recordVeleroEvent(ctx context.Context, client kubernetes.Interface, obj *unstructured.Unstructured, status VeleroResource) {
+	eventType := corev1.EventTypeNormal
+	if status.Phase == "Failed" || status.Phase == "PartiallyFailed" || status.Phase == "FailedValidation" {
+		eventType = corev1.EventTypeWarning
+	}
+	message := fmt.Sprintf("%s %s", status.Kind, veleroPhaseText(status.Phase))
+	if status.ItemsTotal > 0 {
+		message += fmt.Sprintf(": %d of %d items", status.ItemsDone, status.ItemsTotal)
+	}
+	if status.Errors > 0 || status.Warnings > 0 {
+		message += fmt.Sprintf(" (%d errors, %d warnings)", status.Errors, status.Warnings)
+	}
+	if status.FailureReason != "" {
+		message += ": " + status.FailureReason
+	}
+
+	now := metav1.Now()
+	event := &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s.%x", obj.GetName(), now.UnixNano()),
+			Namespace: obj.GetNamespace(),
+		},
+		InvolvedObject: corev1.ObjectReference{
+			APIVersion: obj.GetAPIVersion(),
+			Kind:       obj.GetKind(),
+			Name:       obj.GetName(),
+			Namespace:  obj.GetNamespace(),
+			UID:        obj.GetUID(),
+		},
+		Type:           eventType,
+		Reason:         status.Kind + status.Phase,
+		Message:        message,
+		Source:         corev1.EventSource{Component: veleroEventSource},
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          1,
+	}
+	if _, err := client.CoreV1().Events(obj.GetNamespace()).Create(ctx, event, metav1.CreateOptions{}); err != nil {
+		log.Warnf("Failed to record progress of Velero %s %s: %v", status.Kind, status.Name, err)
+	}
+}
+
+// veleroPhaseText describes a phase in an event message
+func veleroPhaseText(phase string) string {
+	switch phase {
+	case "New":
+		return "created"
+	case "InProgress":
+		return "in progress"
+	case "WaitingForPluginOperations", "WaitingForPluginOperationsPartiallyFailed":
+		return "waiting for plugin operations"
+	case "Finalizing", "FinalizingPartiallyFailed":
+		return "finalizing"
+	case "Completed":
+		return "completed"
+	case "PartiallyFailed":
+		return "partially failed"
+	case "FailedValidation":
+		return "failed validation"
+	default:
+		return strings.ToLower(phase)
+	}
+}
+
+// veleroStatus extracts the status of a Velero object
+func veleroStatus(obj *unstructured.Unstructured) VeleroResource {
+	o := obj.Object
+	status := VeleroResource{
+		Kind:          obj.GetKind(),
+		Name:          obj.GetName(),
+		Namespace:     obj.GetNamespace(),
+		Phase:         nestedStringValue(o, "status", "phase"),
+		FailureReason: nestedStringValue(o, "status", "failureReason"),
+		StartedAt:     nestedStringValue(o, "status", "startTimestamp"),
+		CompletedAt:   nestedStringValue(o, "status", "completionTimestamp"),
+		CreatedAt:     obj.GetCreationTimestamp().UTC().Format(time.RFC3339),
+		Schedule:      obj.GetLabels()["velero.io/schedule-name"],
+	}
+	if status.Phase == "" {
+		status.Phase = "New"
+	}
+	status.Errors, _, _ = unstructured.NestedInt64(o, "status", "errors")
+	status.Warnings, _, _ = unstructured.NestedInt64(o, "status", "warnings")
+	status.ItemsTotal, _, _ = unstructured.NestedInt64(o, "status", "progress", "totalItems")
+
+	switch obj.GetKind() {
+	case "Backup":
+		status.Namespaces, _, _ = unstructured.NestedStringSlice(o, "spec", "includedNamespaces")
+		status.ItemsDone, _, _ = unstructured.NestedInt64(o, "status", "progress", "itemsBackedUp")
+		status.Expiration = nestedStringValue(o, "status", "expiration")
+		status.StorageLocation = nestedStringValue(o, "spec", "storageLocation")
+	case "Restore":
+		status.Namespaces, _, _ = unstructured.NestedStringSlice(o, "spec", "includedNamespaces")
+		status.ItemsDone, _, _ = unstructured.NestedInt64(o, "status", "progress", "itemsRestored")
+		status.Backup = nestedStringValue(o, "spec", "backupName")
+		if scheduleName := nestedStringValue(o, "spec", "scheduleName"); scheduleName != "" {
+			status.Schedule = scheduleName
+		}
+	case "Schedule":
+		status.Namespaces, _, _ = unstructured.NestedStringSlice(o, "spec", "template", "includedNamespaces")
+		status.Cron = nestedStringValue(o, "spec", "schedule")
+		status.Paused, _, _ = unstructured.NestedBool(o, "spec", "paused")
+		status.LastBackup = nestedStringValue(o, "status", "lastBackup")
+	}
+	return status
+}
+
+// validateNames checks that values are valid names: DNS-1123 labels for namespaces,
+// DNS-1123 subdomains for Velero objects
+func validateNames(field string, values []string) error {
+	for _, value := range values {
+		errs := validation.IsDNS1123Subdomain(value)
+		if field == "namespace" {
+			errs = validation.IsDNS1123Label(value)
+		}
+		if len(errs) > 0 {
+			return fmt.Errorf("invalid %s %q: %s", field, value, strings.Join(errs, "; "))
+		}
+	}
+	return nil
+}
+
+func toInterfaceSlice(values []string) []interface{} {
+	result := make([]interface{}, len(values))
+	for i, value := range values {
+		result[i] = value
+	}
+	return result
+}
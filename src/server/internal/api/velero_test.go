@@ -0,0 +1,95 @@
+package api_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/sonnguyen/kubelens/internal/apitest"
+)
+
+var veleroBackups = schema.GroupVersionResource{Group: "velero.io", Version: "v1", Resource: "backups"}
+
+func TestVeleroBackup(t *testing.T) {
+	// A completed backup from the nightly schedule
+	s := apitest.New(t, &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "velero.io/v1",
+		"kind":       "Backup",
+		"metadata": map[string]interface{}{
+			"name": "nightly-20240501", "namespace": "velero", "creationTimestamp": "2024-05-01T02:00:00Z",
+			"labels": map[string]interface{}{"velero.io/schedule-name": "nightly"},
+		},
+		"status": map[string]interface{}{"phase": "Completed", "progress": map[string]interface{}{"totalItems": int64(120), "itemsBackedUp": int64(120)}},
+	}})
+	s.Cluster.Client.Resources = append(s.Cluster.Client.Resources, &metav1.APIResourceList{
+		GroupVersion: "velero.io/v1",
+		APIResources: []metav1.APIResource{
+			{Name: "backups", SingularName: "backup", Namespaced: true, Kind: "Backup"},
+			{Name: "restores", SingularName: "restore", Namespaced: true, Kind: "Restore"},
+		},
+	})
+
+	if w := s.Do(http.MethodPost, "/api/v1/clusters/test/velero/backups", map[string]interface{}{}); w.Code != http.StatusBadRequest {
+		t.Errorf("backup without namespaces status = %d, want 400", w.Code)
+	}
+	w := s.Do(http.MethodPost, "/api/v1/clusters/test/velero/backups", map[string]interface{}{
+		"name": "shop-before-upgrade", "includedNamespaces": []string{"shop"}, "ttl": "72h", "labelSelector": "app=web",
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create backup status = %d: %s", w.Code, w.Body)
+	}
+
+	ctx := context.Background()
+	backup, err := s.Cluster.Dynamic.Resource(veleroBackups).Namespace("velero").Get(ctx, "shop-before-upgrade", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("backup not created: %v", err)
+	}
+	spec := backup.Object["spec"].(map[string]interface{})
+	if spec["ttl"] != "72h0m0s" || spec["includedNamespaces"].([]interface{})[0] != "shop" || spec["labelSelector"] == nil {
+		t.Errorf("spec = %v, want shop with a 72h TTL and the label selector", spec)
+	}
+
+	// The new backup is recorded as an Event for the event pipeline
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		events, _ := s.Cluster.Client.CoreV1().Events("velero").List(ctx, metav1.ListOptions{})
+		if len(events.Items) > 0 {
+			if e := events.Items[0]; e.Reason != "BackupNew" || e.InvolvedObject.Name != "shop-before-upgrade" {
+				t.Errorf("event = %s on %s, want BackupNew on the backup", e.Reason, e.InvolvedObject.Name)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("no progress event recorded for the backup")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	var list struct {
+		Installed bool `json:"installed"`
+		Items     []struct {
+			Name  string `json:"name"`
+			Phase string `json:"phase"`
+		} `json:"items"`
+	}
+	w = s.Get("/api/v1/clusters/test/velero/backups")
+	if w.Code != http.StatusOK {
+		t.Fatalf("list status = %d: %s", w.Code, w.Body)
+	}
+	apitest.DecodeJSON(t, w, &list)
+	// The fake API server sets no creation timestamp, so the new backup sorts last
+	if !list.Installed || len(list.Items) != 2 || list.Items[0].Phase != "Completed" || list.Items[1].Phase != "New" {
+		t.Errorf("list = %+v, want the nightly and the new backup", list)
+	}
+
+	if w := s.Do(http.MethodPost, "/api/v1/clusters/test/velero/restores", map[string]interface{}{
+		"backupName": "shop-before-upgrade", "scheduleName": "daily",
+	}); w.Code != http.StatusBadRequest {
+		t.Errorf("restore with backup and schedule status = %d, want 400", w.Code)
+	}
+}
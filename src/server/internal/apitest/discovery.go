@@ -0,0 +1,95 @@
+package apitest
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var allVerbs = metav1.Verbs{"create", "delete", "deletecollection", "get", "list", "patch", "update", "watch"}
+
+func apiResource(name, singular, kind string, namespaced bool, shortNames ...string) metav1.APIResource {
+	return metav1.APIResource{
+		Name:         name,
+		SingularName: singular,
+		Kind:         kind,
+		Namespaced:   namespaced,
+		Verbs:        allVerbs,
+		ShortNames:   shortNames,
+	}
+}
+
+// DiscoveryResources is what the fake API server reports through discovery: the built-in
+// resources the fixtures use. Tests with custom resources append their own groups.
+func DiscoveryResources() []*metav1.APIResourceList {
+	return []*metav1.APIResourceList{
+		{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{
+				apiResource("namespaces", "namespace", "Namespace", false, "ns"),
+				apiResource("nodes", "node", "Node", false, "no"),
+				apiResource("pods", "pod", "Pod", true, "po"),
+				apiResource("services", "service", "Service", true, "svc"),
+				apiResource("configmaps", "configmap", "ConfigMap", true, "cm"),
+				apiResource("secrets", "secret", "Secret", true),
+				apiResource("serviceaccounts", "serviceaccount", "ServiceAccount", true, "sa"),
+				apiResource("events", "event", "Event", true, "ev"),
+				apiResource("endpoints", "endpoints", "Endpoints", true, "ep"),
+				apiResource("persistentvolumes", "persistentvolume", "PersistentVolume", false, "pv"),
+				apiResource("persistentvolumeclaims", "persistentvolumeclaim", "PersistentVolumeClaim", true, "pvc"),
+				apiResource("resourcequotas", "resourcequota", "ResourceQuota", true, "quota"),
+				apiResource("limitranges", "limitrange", "LimitRange", true, "limits"),
+			},
+		},
+		{
+			GroupVersion: "apps/v1",
+			APIResources: []metav1.APIResource{
+				apiResource("deployments", "deployment", "Deployment", true, "deploy"),
+				apiResource("replicasets", "replicaset", "ReplicaSet", true, "rs"),
+				apiResource("statefulsets", "statefulset", "StatefulSet", true, "sts"),
+				apiResource("daemonsets", "daemonset", "DaemonSet", true, "ds"),
+				apiResource("controllerrevisions", "controllerrevision", "ControllerRevision", true),
+			},
+		},
+		{
+			GroupVersion: "batch/v1",
+			APIResources: []metav1.APIResource{
+				apiResource("jobs", "job", "Job", true),
+				apiResource("cronjobs", "cronjob", "CronJob", true, "cj"),
+			},
+		},
+		{
+			GroupVersion: "networking.k8s.io/v1",
+			APIResources: []metav1.APIResource{
+				apiResource("ingresses", "ingress", "Ingress", true, "ing"),
+				apiResource("ingressclasses", "ingressclass", "IngressClass", false),
+				apiResource("networkpolicies", "networkpolicy", "NetworkPolicy", true, "netpol"),
+			},
+		},
+		{
+			GroupVersion: "autoscaling/v2",
+			APIResources: []metav1.APIResource{
+				apiResource("horizontalpodautoscalers", "horizontalpodautoscaler", "HorizontalPodAutoscaler", true, "hpa"),
+			},
+		},
+		{
+			GroupVersion: "policy/v1",
+			APIResources: []metav1.APIResource{
+				apiResource("poddisruptionbudgets", "poddisruptionbudget", "PodDisruptionBudget", true, "pdb"),
+			},
+		},
+		{
+			GroupVersion: "rbac.authorization.k8s.io/v1",
+			APIResources: []metav1.APIResource{
+				apiResource("roles", "role", "Role", true),
+				apiResource("rolebindings", "rolebinding", "RoleBinding", true),
+				apiResource("clusterroles", "clusterrole", "ClusterRole", false),
+				apiResource("clusterrolebindings", "clusterrolebinding", "ClusterRoleBinding", false),
+			},
+		},
+		{
+			GroupVersion: "storage.k8s.io/v1",
+			APIResources: []metav1.APIResource{
+				apiResource("storageclasses", "storageclass", "StorageClass", false, "sc"),
+			},
+		},
+	}
+}
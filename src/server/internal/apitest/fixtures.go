@@ -0,0 +1,327 @@
+package apitest
+
+import (
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// FixtureNamespace is the namespace of all namespaced fixtures
+const FixtureNamespace = "shop"
+
+// FixtureTime is the creation time of every fixture, so responses are stable
+var FixtureTime = metav1.NewTime(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+
+func fixtureUID(namespace, name string) types.UID {
+	if namespace == "" {
+		return types.UID(name + "-uid")
+	}
+	return types.UID(namespace + "-" + name + "-uid")
+}
+
+func fixtureMeta(name, namespace string, labels map[string]string) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:              name,
+		Namespace:         namespace,
+		UID:               fixtureUID(namespace, name),
+		ResourceVersion:   "1",
+		CreationTimestamp: FixtureTime,
+		Labels:            labels,
+	}
+}
+
+func controlledBy(meta metav1.ObjectMeta, apiVersion, kind, name string) metav1.ObjectMeta {
+	isController := true
+	meta.OwnerReferences = []metav1.OwnerReference{{
+		APIVersion: apiVersion,
+		Kind:       kind,
+		Name:       name,
+		UID:        fixtureUID(meta.Namespace, name),
+		Controller: &isController,
+	}}
+	return meta
+}
+
+// Fixtures returns one object of every resource kind the API serves: a "web" Deployment
+// with its ReplicaSet and Pod, a Service, Ingress, HPA and PDB in front of it, the
+// ConfigMap and Secret it mounts, a StatefulSet, DaemonSet, Job, CronJob, RBAC objects,
+// storage, a node and an event about the pod. Tests can pass a subset or add their own.
+func Fixtures() []runtime.Object {
+	ns := FixtureNamespace
+	webLabels := map[string]string{"app": "web"}
+	replicas := int32(2)
+	minAvailable := intstr.FromInt(1)
+
+	podSpec := corev1.PodSpec{
+		NodeName:           "node-1",
+		ServiceAccountName: "web",
+		Containers: []corev1.Container{{
+			Name:  "web",
+			Image: "nginx:1.27",
+			Ports: []corev1.ContainerPort{{ContainerPort: 80}},
+			EnvFrom: []corev1.EnvFromSource{{
+				ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "web-config"}},
+			}},
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("100m"),
+					corev1.ResourceMemory: resource.MustParse("128Mi"),
+				},
+			},
+		}},
+		Volumes: []corev1.Volume{{
+			Name:         "tls",
+			VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "web-tls"}},
+		}},
+	}
+	template := corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{Labels: webLabels},
+		Spec:       podSpec,
+	}
+
+	podLabels := map[string]string{"app": "web", "pod-template-hash": "5d8f"}
+	pod := &corev1.Pod{
+		ObjectMeta: controlledBy(fixtureMeta("web-5d8f-abcde", ns, podLabels), "apps/v1", "ReplicaSet", "web-5d8f"),
+		Spec:       podSpec,
+		Status: corev1.PodStatus{
+			Phase:     corev1.PodRunning,
+			PodIP:     "10.0.0.10",
+			HostIP:    "192.168.0.1",
+			StartTime: &FixtureTime,
+			Conditions: []corev1.PodCondition{{
+				Type:   corev1.PodReady,
+				Status: corev1.ConditionTrue,
+			}},
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:         "web",
+				Image:        "nginx:1.27",
+				Ready:        true,
+				RestartCount: 1,
+				State:        corev1.ContainerState{Running: &corev1.ContainerStateRunning{StartedAt: FixtureTime}},
+			}},
+		},
+	}
+
+	return []runtime.Object{
+		&corev1.Namespace{
+			ObjectMeta: fixtureMeta(ns, "", nil),
+			Status:     corev1.NamespaceStatus{Phase: corev1.NamespaceActive},
+		},
+		&corev1.Node{
+			ObjectMeta: fixtureMeta("node-1", "", map[string]string{"kubernetes.io/hostname": "node-1"}),
+			Status: corev1.NodeStatus{
+				Capacity: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("4"),
+					corev1.ResourceMemory: resource.MustParse("16Gi"),
+					corev1.ResourcePods:   resource.MustParse("110"),
+				},
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("3800m"),
+					corev1.ResourceMemory: resource.MustParse("15Gi"),
+					corev1.ResourcePods:   resource.MustParse("110"),
+				},
+				Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+				NodeInfo:   corev1.NodeSystemInfo{KubeletVersion: "v1.31.0", OSImage: "Ubuntu 24.04", ContainerRuntimeVersion: "containerd://1.7.0"},
+				Addresses:  []corev1.NodeAddress{{Type: corev1.NodeInternalIP, Address: "192.168.0.1"}},
+			},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: fixtureMeta("web", ns, webLabels),
+			Spec: appsv1.DeploymentSpec{
+				Replicas: &replicas,
+				Selector: &metav1.LabelSelector{MatchLabels: webLabels},
+				Template: template,
+			},
+			Status: appsv1.DeploymentStatus{Replicas: 2, ReadyReplicas: 2, AvailableReplicas: 2, UpdatedReplicas: 2},
+		},
+		&appsv1.ReplicaSet{
+			ObjectMeta: controlledBy(fixtureMeta("web-5d8f", ns, podLabels), "apps/v1", "Deployment", "web"),
+			Spec: appsv1.ReplicaSetSpec{
+				Replicas: &replicas,
+				Selector: &metav1.LabelSelector{MatchLabels: podLabels},
+				Template: template,
+			},
+			Status: appsv1.ReplicaSetStatus{Replicas: 2, ReadyReplicas: 2, AvailableReplicas: 2},
+		},
+		pod,
+		&appsv1.StatefulSet{
+			ObjectMeta: fixtureMeta("db", ns, map[string]string{"app": "db"}),
+			Spec: appsv1.StatefulSetSpec{
+				Replicas:    &replicas,
+				ServiceName: "db",
+				Selector:    &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+				Template:    corev1.PodTemplateSpec{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "db"}}, Spec: podSpec},
+			},
+			Status: appsv1.StatefulSetStatus{Replicas: 2, ReadyReplicas: 2},
+		},
+		&appsv1.DaemonSet{
+			ObjectMeta: fixtureMeta("agent", ns, map[string]string{"app": "agent"}),
+			Spec: appsv1.DaemonSetSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "agent"}},
+				Template: corev1.PodTemplateSpec{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "agent"}}, Spec: podSpec},
+			},
+			Status: appsv1.DaemonSetStatus{DesiredNumberScheduled: 1, CurrentNumberScheduled: 1, NumberReady: 1},
+		},
+		&batchv1.Job{
+			ObjectMeta: fixtureMeta("migrate", ns, nil),
+			Spec: batchv1.JobSpec{
+				Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyNever,
+					Containers:    []corev1.Container{{Name: "migrate", Image: "migrate:1.0"}},
+				}},
+			},
+			Status: batchv1.JobStatus{Succeeded: 1, StartTime: &FixtureTime, CompletionTime: &FixtureTime},
+		},
+		&batchv1.CronJob{
+			ObjectMeta: fixtureMeta("nightly", ns, nil),
+			Spec: batchv1.CronJobSpec{
+				Schedule: "0 2 * * *",
+				JobTemplate: batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{
+					Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+						RestartPolicy: corev1.RestartPolicyOnFailure,
+						Containers:    []corev1.Container{{Name: "report", Image: "report:1.0"}},
+					}},
+				}},
+			},
+		},
+		&corev1.Service{
+			ObjectMeta: fixtureMeta("web", ns, webLabels),
+			Spec: corev1.ServiceSpec{
+				Type:      corev1.ServiceTypeClusterIP,
+				ClusterIP: "10.96.0.10",
+				Selector:  webLabels,
+				Ports:     []corev1.ServicePort{{Name: "http", Port: 80, TargetPort: intstr.FromInt(80), Protocol: corev1.ProtocolTCP}},
+			},
+		},
+		&networkingv1.Ingress{
+			ObjectMeta: fixtureMeta("web", ns, nil),
+			Spec: networkingv1.IngressSpec{
+				Rules: []networkingv1.IngressRule{{
+					Host: "shop.example.com",
+					IngressRuleValue: networkingv1.IngressRuleValue{HTTP: &networkingv1.HTTPIngressRuleValue{
+						Paths: []networkingv1.HTTPIngressPath{{
+							Path:     "/",
+							PathType: func() *networkingv1.PathType { p := networkingv1.PathTypePrefix; return &p }(),
+							Backend: networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{
+								Name: "web",
+								Port: networkingv1.ServiceBackendPort{Number: 80},
+							}},
+						}},
+					}},
+				}},
+			},
+		},
+		&networkingv1.NetworkPolicy{
+			ObjectMeta: fixtureMeta("web-ingress", ns, nil),
+			Spec: networkingv1.NetworkPolicySpec{
+				PodSelector: metav1.LabelSelector{MatchLabels: webLabels},
+				PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+			},
+		},
+		&corev1.ConfigMap{
+			ObjectMeta: fixtureMeta("web-config", ns, nil),
+			Data:       map[string]string{"LOG_LEVEL": "info"},
+		},
+		&corev1.Secret{
+			ObjectMeta: fixtureMeta("web-tls", ns, nil),
+			Type:       corev1.SecretTypeTLS,
+			Data:       map[string][]byte{"tls.crt": []byte("cert"), "tls.key": []byte("key")},
+		},
+		&corev1.ServiceAccount{
+			ObjectMeta: fixtureMeta("web", ns, nil),
+		},
+		&rbacv1.Role{
+			ObjectMeta: fixtureMeta("web-reader", ns, nil),
+			Rules:      []rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"configmaps"}, Verbs: []string{"get", "list"}}},
+		},
+		&rbacv1.RoleBinding{
+			ObjectMeta: fixtureMeta("web-reader", ns, nil),
+			RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: "web-reader"},
+			Subjects:   []rbacv1.Subject{{Kind: "ServiceAccount", Name: "web", Namespace: ns}},
+		},
+		&rbacv1.ClusterRole{
+			ObjectMeta: fixtureMeta("node-reader", "", nil),
+			Rules:      []rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"nodes"}, Verbs: []string{"get", "list"}}},
+		},
+		&rbacv1.ClusterRoleBinding{
+			ObjectMeta: fixtureMeta("node-reader", "", nil),
+			RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "node-reader"},
+			Subjects:   []rbacv1.Subject{{Kind: "ServiceAccount", Name: "web", Namespace: ns}},
+		},
+		&autoscalingv2.HorizontalPodAutoscaler{
+			ObjectMeta: fixtureMeta("web", ns, nil),
+			Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+				ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "web"},
+				MaxReplicas:    5,
+			},
+		},
+		&policyv1.PodDisruptionBudget{
+			ObjectMeta: fixtureMeta("web", ns, nil),
+			Spec: policyv1.PodDisruptionBudgetSpec{
+				MinAvailable: &minAvailable,
+				Selector:     &metav1.LabelSelector{MatchLabels: webLabels},
+			},
+		},
+		&corev1.ResourceQuota{
+			ObjectMeta: fixtureMeta("compute", ns, nil),
+			Spec:       corev1.ResourceQuotaSpec{Hard: corev1.ResourceList{corev1.ResourcePods: resource.MustParse("20")}},
+		},
+		&corev1.LimitRange{
+			ObjectMeta: fixtureMeta("defaults", ns, nil),
+			Spec: corev1.LimitRangeSpec{Limits: []corev1.LimitRangeItem{{
+				Type:           corev1.LimitTypeContainer,
+				DefaultRequest: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("50m")},
+			}}},
+		},
+		&corev1.PersistentVolume{
+			ObjectMeta: fixtureMeta("pv-data", "", nil),
+			Spec: corev1.PersistentVolumeSpec{
+				Capacity:         corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")},
+				AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+				StorageClassName: "standard",
+			},
+			Status: corev1.PersistentVolumeStatus{Phase: corev1.VolumeBound},
+		},
+		&corev1.PersistentVolumeClaim{
+			ObjectMeta: fixtureMeta("data", ns, nil),
+			Spec: corev1.PersistentVolumeClaimSpec{
+				AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+				VolumeName:       "pv-data",
+				StorageClassName: func() *string { s := "standard"; return &s }(),
+			},
+			Status: corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimBound},
+		},
+		&storagev1.StorageClass{
+			ObjectMeta:  fixtureMeta("standard", "", nil),
+			Provisioner: "kubernetes.io/no-provisioner",
+		},
+		&corev1.Event{
+			ObjectMeta: fixtureMeta("web-5d8f-abcde.pulled", ns, nil),
+			InvolvedObject: corev1.ObjectReference{
+				Kind:      "Pod",
+				Namespace: ns,
+				Name:      pod.Name,
+				UID:       pod.UID,
+			},
+			Reason:         "Pulled",
+			Message:        "Container image \"nginx:1.27\" already present on machine",
+			Type:           corev1.EventTypeNormal,
+			Count:          1,
+			FirstTimestamp: FixtureTime,
+			LastTimestamp:  FixtureTime,
+			Source:         corev1.EventSource{Component: "kubelet", Host: "node-1"},
+		},
+	}
+}
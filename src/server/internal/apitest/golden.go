@@ -0,0 +1,49 @@
+package apitest
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files with the current responses")
+
+// AssertGolden compares a JSON response, re-indented, with testdata/golden/<name>.json in
+// the calling package, failing unless the response status is 200. Run the tests with
+// -update to write the golden files after an intended change of the API contract.
+func AssertGolden(t testing.TB, w *httptest.ResponseRecorder, name string) {
+	t.Helper()
+	if w.Code != http.StatusOK {
+		t.Fatalf("%s: status %d, want 200: %s", name, w.Code, w.Body.String())
+	}
+
+	var got bytes.Buffer
+	if err := json.Indent(&got, w.Body.Bytes(), "", "  "); err != nil {
+		t.Fatalf("%s: response is not valid JSON: %v", name, err)
+	}
+	got.WriteByte('\n')
+
+	path := filepath.Join("testdata", "golden", name+".json")
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, got.Bytes(), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%s: %v (run with -update to create it)", name, err)
+	}
+	if !bytes.Equal(got.Bytes(), want) {
+		t.Errorf("%s: response differs from %s (run with -update if the change is intended)\ngot:\n%s", name, path, got.String())
+	}
+}
@@ -0,0 +1,203 @@
+// Package apitest runs the API handlers behind the real routes against fake clientsets
+// and a throwaway SQLite database, so handler tests can be written as HTTP requests:
+//
+//	s := apitest.New(t, apitest.Fixtures()...)
+//	w := s.Get("/api/v1/clusters/test/namespaces/shop/pods/web-5d8f-abcde")
+//	apitest.AssertGolden(t, w, "get_pod")
+//
+// Each cluster gets a typed and a dynamic fake clientset seeded with the same objects.
+// They do not share storage: an object written through one is not seen by the other.
+package apitest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+	metricsfake "k8s.io/metrics/pkg/client/clientset/versioned/fake"
+
+	"github.com/sonnguyen/kubelens/internal/api"
+	"github.com/sonnguyen/kubelens/internal/audit"
+	"github.com/sonnguyen/kubelens/internal/auth"
+	"github.com/sonnguyen/kubelens/internal/cluster"
+	"github.com/sonnguyen/kubelens/internal/db"
+	"github.com/sonnguyen/kubelens/internal/policy"
+	"github.com/sonnguyen/kubelens/internal/ws"
+)
+
+// ClusterName is the name of the cluster created by New
+const ClusterName = "test"
+
+// Cluster holds the fake clients behind one registered cluster
+type Cluster struct {
+	Client  *fake.Clientset
+	Dynamic *dynamicfake.FakeDynamicClient
+}
+
+// Server is the API router wired to fake clusters and a temporary database. Requests are
+// made as User, an admin, with every permission check allowed. Feature flags are evaluated
+// from the database, so dark-launched routes answer 404 until their flag is enabled.
+type Server struct {
+	Router   *gin.Engine
+	Handler  *api.Handler
+	DB       *db.DB
+	Clusters *cluster.Manager
+	Hub      *ws.Hub
+	User     *db.User
+	Policy   *policy.EndpointPolicy
+
+	// Cluster is the cluster named ClusterName
+	Cluster *Cluster
+}
+
+// New starts a test server with one cluster, ClusterName, seeded with objects
+func New(t testing.TB, objects ...runtime.Object) *Server {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	log.SetLevel(log.WarnLevel)
+
+	database, err := db.New(filepath.Join(t.TempDir(), "kubelens.db"))
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	user, err := database.CreateInitialAdmin("admin", "admin@kubelens.local", "Administrator", "not-a-password-hash")
+	if err != nil {
+		t.Fatalf("failed to create test user: %v", err)
+	}
+	audit.InitGlobalLogger(database)
+
+	endpointPolicy, err := policy.NewEndpointPolicy(database, nil)
+	if err != nil {
+		t.Fatalf("failed to create endpoint policy: %v", err)
+	}
+
+	hub := ws.NewHub()
+	go hub.Run()
+
+	s := &Server{
+		Router:   gin.New(),
+		DB:       database,
+		Clusters: cluster.NewManager(database),
+		Hub:      hub,
+		User:     user,
+		Policy:   endpointPolicy,
+	}
+	s.Handler = api.NewHandler(s.Clusters, database, hub)
+	s.Cluster = s.AddCluster(ClusterName, objects...)
+
+	v1 := s.Router.Group("/api/v1")
+	v1.Use(s.authenticate, s.Handler.AuditResourceChanges(), s.Handler.TrackRecentViews())
+	api.RegisterRoutes(v1, s.Handler, allowAll, auth.NewHandler(database, "", nil).FeatureGate, endpointPolicy)
+
+	return s
+}
+
+// AddCluster registers another cluster seeded with objects. Its discovery reports
+// DiscoveryResources; append to Client.Resources for custom resources. Unstructured objects
+// (custom resources) are only seeded into the dynamic client.
+func (s *Server) AddCluster(name string, objects ...runtime.Object) *Cluster {
+	typed := make([]runtime.Object, 0, len(objects))
+	for _, obj := range objects {
+		if _, ok := obj.(*unstructured.Unstructured); !ok {
+			typed = append(typed, obj)
+		}
+	}
+	client := fake.NewSimpleClientset(typed...)
+	client.Discovery().(*fakediscovery.FakeDiscovery).Resources = DiscoveryResources()
+
+	// The dynamic tracker keeps its own copies of the objects
+	copies := make([]runtime.Object, len(objects))
+	for i, obj := range objects {
+		copies[i] = obj.DeepCopyObject()
+	}
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(scheme.Scheme, copies...)
+
+	s.Clusters.AddClusterFromClients(name, client, dynamicClient)
+	return &Cluster{Client: client, Dynamic: dynamicClient}
+}
+
+// AddMetrics makes metrics-server of a cluster available, serving the pod metrics given.
+// Without it the cluster reports metrics-server as unavailable.
+func (s *Server) AddMetrics(clusterName string, pods ...*metricsv1beta1.PodMetrics) *metricsfake.Clientset {
+	client := metricsfake.NewSimpleClientset()
+	for _, podMetrics := range pods {
+		// The fake lists pod metrics under the "pods" resource, which seeding would miss
+		gvr := metricsv1beta1.SchemeGroupVersion.WithResource("pods")
+		if err := client.Tracker().Create(gvr, podMetrics, podMetrics.Namespace); err != nil {
+			panic(err)
+		}
+	}
+	s.Clusters.AddMetricsClient(clusterName, client)
+	return client
+}
+
+// authenticate stands in for auth.AuthMiddleware and sets the same context keys
+func (s *Server) authenticate(c *gin.Context) {
+	c.Set("user", s.User)
+	c.Set("user_id", int(s.User.ID))
+	c.Set("email", s.User.Email)
+	c.Set("username", s.User.Username)
+	c.Set("is_admin", s.User.IsAdmin)
+	c.Next()
+}
+
+func allowAll(resource, action string) gin.HandlerFunc {
+	return func(c *gin.Context) { c.Next() }
+}
+
+// Do sends a request to the router. body may be nil, a []byte or string sent as is, or
+// any other value sent as JSON.
+func (s *Server) Do(method, path string, body interface{}) *httptest.ResponseRecorder {
+	var reader io.Reader
+	contentType := ""
+	switch b := body.(type) {
+	case nil:
+	case []byte:
+		reader = bytes.NewReader(b)
+	case string:
+		reader = bytes.NewReader([]byte(b))
+	default:
+		data, err := json.Marshal(b)
+		if err != nil {
+			panic(err)
+		}
+		reader = bytes.NewReader(data)
+		contentType = "application/json"
+	}
+
+	req := httptest.NewRequest(method, path, reader)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	w := httptest.NewRecorder()
+	s.Router.ServeHTTP(w, req)
+	return w
+}
+
+// Get sends a GET request to the router
+func (s *Server) Get(path string) *httptest.ResponseRecorder {
+	return s.Do(http.MethodGet, path, nil)
+}
+
+// DecodeJSON decodes a response body into v, failing the test if it is not valid JSON
+func DecodeJSON(t testing.TB, w *httptest.ResponseRecorder, v interface{}) {
+	t.Helper()
+	if err := json.Unmarshal(w.Body.Bytes(), v); err != nil {
+		t.Fatalf("response is not valid JSON: %v\n%s", err, w.Body.String())
+	}
+}
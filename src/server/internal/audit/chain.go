@@ -0,0 +1,211 @@
+package audit
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+const (
+	// chainVerifyBatch bounds the entries read at a time while verifying the chain
+	chainVerifyBatch = 1000
+	// maxChainIssues bounds the issues a verification reports
+	maxChainIssues = 100
+)
+
+// Chain issue types
+const (
+	ChainIssueModified       = "modified"        // the entry does not match its hash
+	ChainIssueBrokenLink     = "broken_link"     // the entry does not follow the one before it: entries were deleted or inserted
+	ChainIssueUnchained      = "unchained"       // the entry was recorded outside the chain
+	ChainIssueTruncated      = "truncated"       // the last entries of the chain are missing
+	ChainIssueAnchorMismatch = "anchor_mismatch" // an anchored entry is missing or its hash changed
+)
+
+// HashChain links audit entries: each stores the hash of the previous entry and a hash
+// of that and its own content, keyed with HMAC-SHA256 when a key is set (SHA-256
+// otherwise), so deleting or modifying an entry breaks the chain after it
+type HashChain struct {
+	key []byte
+}
+
+// chain links the entries Logger records, when enabled
+var chain *HashChain
+
+// EnableHashChain makes every recorded audit entry part of the hash chain. Without a key,
+// anyone able to write the database can recompute the chain; anchors shipped to the sinks
+// still reveal it.
+func EnableHashChain(key string) *HashChain {
+	chain = &HashChain{key: []byte(key)}
+	return chain
+}
+
+// Hash returns the chain hash of an entry following prevHash
+func (hc *HashChain) Hash(prevHash string, entry *db.AuditLog) string {
+	var h hash.Hash
+	if len(hc.key) > 0 {
+		h = hmac.New(sha256.New, hc.key)
+	} else {
+		h = sha256.New()
+	}
+	h.Write([]byte(prevHash))
+	h.Write([]byte{'\n'})
+	h.Write(entry.ChainContent())
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// ChainIssue is a problem found while verifying the chain
+type ChainIssue struct {
+	EntryID uint   `json:"entry_id"`
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+// ChainReport is the result of verifying the chain
+type ChainReport struct {
+	Valid        bool         `json:"valid"`
+	Checked      int          `json:"checked"`                  // entries verified
+	FirstEntryID uint         `json:"first_entry_id,omitempty"` // the oldest entry of the chain still kept
+	LastEntryID  uint         `json:"last_entry_id,omitempty"`
+	Anchors      int          `json:"anchors"` // anchors checked
+	Issues       []ChainIssue `json:"issues"`
+	Truncated    bool         `json:"issues_truncated,omitempty"` // more issues than reported
+	VerifiedAt   time.Time    `json:"verified_at"`
+}
+
+func (r *ChainReport) add(entryID uint, issueType, format string, args ...interface{}) {
+	r.Valid = false
+	if len(r.Issues) >= maxChainIssues {
+		r.Truncated = true
+		return
+	}
+	r.Issues = append(r.Issues, ChainIssue{EntryID: entryID, Type: issueType, Message: fmt.Sprintf(format, args...)})
+}
+
+// Verify walks the chain from its oldest kept entry (retention deletes the oldest ones) and
+// reports the entries that were modified, deleted or inserted, the entries missing at its
+// end and the anchors that no longer match
+func (hc *HashChain) Verify(database *db.DB) (*ChainReport, error) {
+	report := &ChainReport{Valid: true, Issues: []ChainIssue{}, VerifiedAt: time.Now().UTC()}
+	head, err := database.GetAuditChainHead()
+	if err != nil {
+		return nil, err
+	}
+
+	var afterID uint
+	var prev *db.AuditLog
+	for {
+		entries, err := database.ListAuditLogsAfter(afterID, chainVerifyBatch, nil)
+		if err != nil {
+			return nil, err
+		}
+		for i := range entries {
+			entry := &entries[i]
+			afterID = entry.ID
+			if entry.Hash == "" {
+				// Entries recorded before the chain was enabled are not part of it
+				if prev != nil {
+					report.add(entry.ID, ChainIssueUnchained, "entry %d has no chain hash", entry.ID)
+				}
+				continue
+			}
+			if prev == nil {
+				report.FirstEntryID = entry.ID
+			} else if entry.PrevHash != prev.Hash {
+				report.add(entry.ID, ChainIssueBrokenLink, "entry %d does not follow entry %d: entries between them were deleted or inserted", entry.ID, prev.ID)
+			}
+			if hc.Hash(entry.PrevHash, entry) != entry.Hash {
+				report.add(entry.ID, ChainIssueModified, "entry %d does not match its hash", entry.ID)
+			}
+			report.Checked++
+			prev = entry
+		}
+		if len(entries) < chainVerifyBatch {
+			break
+		}
+	}
+
+	if prev != nil {
+		report.LastEntryID = prev.ID
+	}
+	if head.EntryID != 0 && (prev == nil || head.EntryID > prev.ID || (head.EntryID == prev.ID && head.Hash != prev.Hash)) {
+		report.add(head.EntryID, ChainIssueTruncated, "the chain ends at entry %d, but entry %d was recorded last", report.LastEntryID, head.EntryID)
+	}
+
+	anchors, err := database.ListAuditChainAnchors(report.FirstEntryID)
+	if err != nil {
+		return nil, err
+	}
+	for _, anchor := range anchors {
+		report.Anchors++
+		entry, err := database.GetAuditLogByID(anchor.EntryID)
+		if err != nil {
+			report.add(anchor.EntryID, ChainIssueAnchorMismatch, "entry %d, anchored on %s, is missing", anchor.EntryID, anchor.CreatedAt.UTC().Format(time.RFC3339))
+		} else if entry.Hash != anchor.Hash {
+			report.add(anchor.EntryID, ChainIssueAnchorMismatch, "entry %d does not match its anchor of %s", anchor.EntryID, anchor.CreatedAt.UTC().Format(time.RFC3339))
+		}
+	}
+	return report, nil
+}
+
+// Anchor records the current end of the chain as an anchor, and as an audit entry shipped
+// to the sinks so a copy is kept outside the database. Nothing is recorded when no entry
+// was added since the last anchor.
+func (hc *HashChain) Anchor(database *db.DB, logger *Logger) error {
+	head, err := database.GetAuditChainHead()
+	if err != nil {
+		return err
+	}
+	if head.EntryID == 0 {
+		return nil
+	}
+	last, err := database.LatestAuditChainAnchor()
+	if err != nil {
+		return err
+	}
+	if last != nil && last.EntryID == head.EntryID {
+		return nil
+	}
+	// The previous anchor's own entry alone is no reason for another one
+	if entry, err := database.GetAuditLogByID(head.EntryID); err == nil && entry.EventType == EventAuditChainAnchored && last != nil {
+		return nil
+	}
+
+	anchor := &db.AuditChainAnchor{EntryID: head.EntryID, Hash: head.Hash}
+	if err := database.CreateAuditChainAnchor(anchor); err != nil {
+		return err
+	}
+	return logger.Log(LogEntry{
+		EventType:     EventAuditChainAnchored,
+		EventCategory: CategoryAudit,
+		Level:         LevelInfo,
+		Description:   fmt.Sprintf("Audit log chain anchored at entry %d", anchor.EntryID),
+		Metadata:      fmt.Sprintf(`{"entry_id":%d,"hash":%q}`, anchor.EntryID, anchor.Hash),
+		Success:       true,
+	})
+}
+
+// StartAnchoring anchors the chain every interval until done is closed
+func (hc *HashChain) StartAnchoring(database *db.DB, logger *Logger, interval time.Duration, done <-chan struct{}) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := hc.Anchor(database, logger); err != nil {
+					log.Errorf("Failed to anchor the audit log chain: %v", err)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+}
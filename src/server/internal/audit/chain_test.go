@@ -0,0 +1,72 @@
+package audit
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+// chainIssues returns the issue types a verification reports, by entry
+func chainIssues(t *testing.T, hc *HashChain, database *db.DB) map[uint]string {
+	t.Helper()
+	report, err := hc.Verify(database)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issues := map[uint]string{}
+	for _, issue := range report.Issues {
+		issues[issue.EntryID] = issue.Type
+	}
+	if report.Valid != (len(issues) == 0) {
+		t.Errorf("report valid = %v with issues %v", report.Valid, issues)
+	}
+	return issues
+}
+
+func TestHashChain(t *testing.T) {
+	database, err := db.New(filepath.Join(t.TempDir(), "kubelens.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	logger := NewLogger(database)
+	logger.Log(testEntry(EventLoginSuccess, CategoryAuthentication, LevelInfo)) // before the chain
+
+	hc := EnableHashChain("s3cret")
+	defer func() { chain = nil }()
+	for i := 0; i < 5; i++ {
+		if err := logger.Log(testEntry(EventLoginFailed, CategoryAuthentication, LevelWarn)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := hc.Anchor(database, logger); err != nil {
+		t.Fatal(err)
+	}
+	if issues := chainIssues(t, hc, database); len(issues) != 0 {
+		t.Fatalf("issues of an intact chain: %v", issues)
+	}
+
+	// Entries 2-6 are the chain, 7 records the anchor at 6
+	database.Model(&db.AuditLog{}).Where("id = ?", 3).Update("description", "rewritten")
+	database.Delete(&db.AuditLog{}, 5)
+	database.Model(&db.AuditLog{}).Where("id = ?", 6).Update("hash", "forged")
+	database.Delete(&db.AuditLog{}, 7)
+	issues := chainIssues(t, hc, database)
+	if issues[3] != ChainIssueModified || issues[6] != ChainIssueAnchorMismatch || issues[7] != ChainIssueTruncated {
+		t.Errorf("issues = %v, want 3 modified, 6 not matching its anchor and 7 missing", issues)
+	}
+	report, _ := hc.Verify(database)
+	brokenAt6 := false
+	for _, issue := range report.Issues {
+		brokenAt6 = brokenAt6 || (issue.EntryID == 6 && issue.Type == ChainIssueBrokenLink)
+	}
+	if !brokenAt6 {
+		t.Errorf("issues = %+v, want the deletion of 5 found at 6", report.Issues)
+	}
+
+	// Another key finds every entry modified
+	if issues := chainIssues(t, &HashChain{key: []byte("other")}, database); issues[2] != ChainIssueModified {
+		t.Errorf("issues with another key = %v", issues)
+	}
+}
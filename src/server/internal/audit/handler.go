@@ -28,6 +28,13 @@ func NewHandler(database *db.DB, logger *Logger, retentionManager *RetentionMana
 
 // ========== Audit Logs Endpoints ==========
 
+// organizationScope returns the organization whose audit logs the caller sees, the one it
+// works in, and whether the events outside any organization are shown too (to admins)
+func organizationScope(c *gin.Context) (uint, bool) {
+	isAdmin, _ := c.Get("is_admin")
+	return c.GetUint("org_id"), isAdmin == true
+}
+
 // ListAuditLogs handles GET /api/v1/audit/logs
 func (h *Handler) ListAuditLogs(c *gin.Context) {
 	// Parse pagination
@@ -37,8 +44,31 @@ func (h *Handler) ListAuditLogs(c *gin.Context) {
 		pageSize = 500 // Max 500 per page
 	}
 
-	// Parse filters
+	filters := listFilters(c)
+
+	// Query logs
+	logs, total, err := h.db.ListAuditLogs(page, pageSize, filters)
+	if err != nil {
+		log.Errorf("Failed to list audit logs: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve audit logs"})
+		return
+	}
+
+	totalPages := (total + pageSize - 1) / pageSize
+
+	c.JSON(http.StatusOK, gin.H{
+		"logs":        logs,
+		"total":       total,
+		"page":        page,
+		"page_size":   pageSize,
+		"total_pages": totalPages,
+	})
+}
+
+// listFilters parses the filters of ListAuditLogs and StreamAuditLogs
+func listFilters(c *gin.Context) map[string]interface{} {
 	filters := make(map[string]interface{})
+	filters["organization_id"], filters["instance_events"] = organizationScope(c)
 	
 	if startDate := c.Query("start_date"); startDate != "" {
 		if t, err := time.Parse(time.RFC3339, startDate); err == nil {
@@ -84,24 +114,24 @@ func (h *Handler) ListAuditLogs(c *gin.Context) {
 	if search := c.Query("search"); search != "" {
 		filters["search"] = search
 	}
-
-	// Query logs
-	logs, total, err := h.db.ListAuditLogs(page, pageSize, filters)
-	if err != nil {
-		log.Errorf("Failed to list audit logs: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve audit logs"})
-		return
+	if actor := c.Query("actor"); actor != "" {
+		filters["actor"] = actor
 	}
+	if verb := c.Query("verb"); verb != "" {
+		filters["verb"] = verb
+	}
+	if resourceName := c.Query("resource_name"); resourceName != "" {
+		filters["resource_name"] = resourceName
+	}
+	return filters
+}
 
-	totalPages := (total + pageSize - 1) / pageSize
-
-	c.JSON(http.StatusOK, gin.H{
-		"logs":        logs,
-		"total":       total,
-		"page":        page,
-		"page_size":   pageSize,
-		"total_pages": totalPages,
-	})
+// filterParams are the query parameters listFilters reads
+var filterParams = map[string]bool{
+	"start_date": true, "end_date": true, "event_type": true, "event_category": true,
+	"level": true, "user_id": true, "source_ip": true, "resource_type": true,
+	"cluster_name": true, "namespace": true, "success": true, "search": true,
+	"actor": true, "verb": true, "resource_name": true,
 }
 
 // GetAuditLog handles GET /api/v1/audit/logs/:id
@@ -117,10 +147,37 @@ func (h *Handler) GetAuditLog(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Audit log not found"})
 		return
 	}
+	if orgID, instanceEvents := organizationScope(c); orgID != 0 {
+		inScope := logEntry.OrganizationID == nil && instanceEvents
+		if logEntry.OrganizationID != nil && *logEntry.OrganizationID == orgID {
+			inScope = true
+		}
+		if !inScope {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Audit log not found"})
+			return
+		}
+	}
 
 	c.JSON(http.StatusOK, logEntry)
 }
 
+// VerifyAuditChain handles GET /api/v1/audit/chain/verify: it walks the hash chain of the
+// audit logs and reports the entries deleted, modified or inserted since they were recorded
+func (h *Handler) VerifyAuditChain(c *gin.Context) {
+	hc := chain
+	if hc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Audit log hash chaining is not enabled"})
+		return
+	}
+	report, err := hc.Verify(h.db)
+	if err != nil {
+		log.Errorf("Failed to verify the audit log chain: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify the audit log chain"})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
 // GetAuditStats handles GET /api/v1/audit/logs/stats
 func (h *Handler) GetAuditStats(c *gin.Context) {
 	period := c.DefaultQuery("period", "24h")
@@ -134,7 +191,8 @@ func (h *Handler) GetAuditStats(c *gin.Context) {
 	endDate := time.Now()
 	startDate := endDate.Add(-duration)
 
-	stats, err := h.db.GetAuditLogStats(startDate, endDate)
+	orgID, instanceEvents := organizationScope(c)
+	stats, err := h.db.GetAuditLogStats(startDate, endDate, orgID, instanceEvents)
 	if err != nil {
 		log.Errorf("Failed to get audit stats: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve statistics"})
@@ -174,6 +232,7 @@ func (h *Handler) ExportAuditLogs(c *gin.Context) {
 		"start_date": startDate.UTC(),
 		"end_date":   endDate.UTC(),
 	}
+	filters["organization_id"], filters["instance_events"] = organizationScope(c)
 	logs, _, err := h.db.ListAuditLogs(1, 100000, filters) // Large limit for export
 	if err != nil {
 		log.Errorf("Failed to export audit logs: %v", err)
@@ -298,6 +357,16 @@ func (h *Handler) GetStorageImpact(c *gin.Context) {
 	})
 }
 
+// GetSinks handles GET /api/v1/audit/sinks: the state of the external sinks audit
+// entries are shipped to
+func (h *Handler) GetSinks(c *gin.Context) {
+	statuses := []SinkStatus{}
+	if s := sinks; s != nil {
+		statuses = s.Status()
+	}
+	c.JSON(http.StatusOK, gin.H{"sinks": statuses})
+}
+
 // ========== Retention Management Endpoints ==========
 
 // GetRetentionStats handles GET /api/v1/audit/retention/stats
@@ -3,6 +3,7 @@ package audit
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -69,8 +70,21 @@ func (al *Logger) Log(entry LogEntry) error {
 		return fmt.Errorf("validation failed: %w", err)
 	}
 
-	// Create log entry in database
-	return al.db.CreateAuditLog(entry)
+	// Create log entry in database, in the hash chain when enabled
+	if hc := chain; hc != nil {
+		if err := al.db.CreateChainedAuditLog(&entry, hc.Hash); err != nil {
+			return err
+		}
+	} else if err := al.db.CreateAuditLog(&entry); err != nil {
+		return err
+	}
+
+	// Ship it to the external sinks too
+	if s := sinks; s != nil {
+		s.Ship(entry)
+	}
+	recorded.notify()
+	return nil
 }
 
 // LogSimple creates a simple audit log entry with minimal fields
@@ -279,6 +293,28 @@ func Log(c *gin.Context, eventType string, userID int, username, email, descript
 		return
 	}
 
+	// Dry-run requests change nothing; mark them so they are not mistaken for real changes
+	if dryRun := strings.ToLower(c.Query("dryRun")); dryRun == "true" || dryRun == "all" {
+		if metadata == nil {
+			metadata = map[string]interface{}{}
+		}
+		metadata["dry_run"] = true
+		description = "[dry run] " + description
+	}
+
+	// Requests that change a Kubernetes object record what changed
+	if value, ok := c.Get(ChangesKey); ok {
+		if changes, ok := value.(func() interface{}); ok {
+			if diff := changes(); diff != nil {
+				if metadata == nil {
+					metadata = map[string]interface{}{}
+				}
+				metadata["changes"] = diff
+			}
+			c.Set(LoggedKey, true)
+		}
+	}
+
 	// Convert metadata to JSON string
 	metadataJSON := ""
 	if metadata != nil {
@@ -321,6 +357,11 @@ func Log(c *gin.Context, eventType string, userID int, username, email, descript
 		CreatedAt:     time.Now(),
 	}
 
+	// Events of a request are recorded in the organization it works in
+	if orgID := c.GetUint("org_id"); orgID != 0 {
+		entry.OrganizationID = &orgID
+	}
+
 	if err := globalLogger.Log(entry); err != nil {
 		log.Errorf("Failed to create audit log: %v", err)
 	}
@@ -334,6 +375,8 @@ func categorizeEvent(eventType string) (string, string) {
 		EventPasswordChanged: true, EventPasswordResetRequested: true,
 		EventMFAEnabled: true, EventMFADisabled: true, EventMFAVerified: true, EventMFAFailed: true,
 		EventAccountLocked: true, EventAccountUnlocked: true,
+		EventAuthAPITokenUsed: true, EventAuthTokenRefresh: true,
+		EventAuthSessionRevoked: true, EventAuthOrganizationSwitched: true,
 	}
 	if authEvents[eventType] {
 		if eventType == EventLoginFailed || eventType == EventMFAFailed || eventType == EventAccountLocked {
@@ -0,0 +1,180 @@
+package audit
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+// ========== Saved Audit Queries Endpoints ==========
+
+// savedQueryRequest is the body of CreateSavedQuery and UpdateSavedQuery
+type savedQueryRequest struct {
+	Name   string `json:"name" binding:"required"`
+	Query  string `json:"query"`
+	Shared bool   `json:"shared"`
+}
+
+// validateSavedQuery checks that a saved query only holds the filters of ListAuditLogs and
+// returns it normalized
+func validateSavedQuery(query string) (string, error) {
+	values, err := url.ParseQuery(strings.TrimPrefix(query, "?"))
+	if err != nil {
+		return "", fmt.Errorf("invalid query: %v", err)
+	}
+	for key := range values {
+		if !filterParams[key] {
+			return "", fmt.Errorf("unknown filter: %s", key)
+		}
+	}
+	return values.Encode(), nil
+}
+
+// savedQueryCaller returns the user calling and the organization it works in
+func savedQueryCaller(c *gin.Context) (uint, uint, bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		return 0, 0, false
+	}
+	return uint(userID.(int)), c.GetUint("org_id"), true
+}
+
+// ListSavedQueries handles GET /api/v1/audit/queries: the caller's saved queries and those
+// shared in its organization
+func (h *Handler) ListSavedQueries(c *gin.Context) {
+	userID, orgID, ok := savedQueryCaller(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	queries, err := h.db.ListSavedAuditQueries(userID, orgID)
+	if err != nil {
+		log.Errorf("Failed to list saved audit queries: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve saved queries"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"queries": queries})
+}
+
+// CreateSavedQuery handles POST /api/v1/audit/queries. The query holds the filters of
+// GET /api/v1/audit/logs as a URL query.
+func (h *Handler) CreateSavedQuery(c *gin.Context) {
+	userID, orgID, ok := savedQueryCaller(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	var req savedQueryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+	query, err := validateSavedQuery(req.Query)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if taken, err := h.db.SavedAuditQueryNameTaken(userID, orgID, req.Name, 0); err != nil {
+		log.Errorf("Failed to create saved audit query: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create saved query"})
+		return
+	} else if taken {
+		c.JSON(http.StatusConflict, gin.H{"error": "A saved query with this name already exists"})
+		return
+	}
+
+	saved := &db.SavedAuditQuery{UserID: userID, Name: req.Name, Query: query, Shared: req.Shared}
+	if orgID != 0 {
+		saved.OrganizationID = &orgID
+	}
+	if err := h.db.CreateSavedAuditQuery(saved); err != nil {
+		log.Errorf("Failed to create saved audit query: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create saved query"})
+		return
+	}
+	c.JSON(http.StatusCreated, saved)
+}
+
+// ownSavedQuery returns the saved query of the request's :id if the caller owns it (admins
+// may change any), writing the error response otherwise
+func (h *Handler) ownSavedQuery(c *gin.Context) (*db.SavedAuditQuery, bool) {
+	userID, orgID, ok := savedQueryCaller(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return nil, false
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query ID"})
+		return nil, false
+	}
+	saved, err := h.db.GetSavedAuditQuery(uint(id))
+	if err != nil || (orgID != 0 && (saved.OrganizationID == nil || *saved.OrganizationID != orgID)) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Saved query not found"})
+		return nil, false
+	}
+	if isAdmin, _ := c.Get("is_admin"); saved.UserID != userID && isAdmin != true {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the owner of a saved query can change it"})
+		return nil, false
+	}
+	return saved, true
+}
+
+// UpdateSavedQuery handles PUT /api/v1/audit/queries/:id
+func (h *Handler) UpdateSavedQuery(c *gin.Context) {
+	saved, ok := h.ownSavedQuery(c)
+	if !ok {
+		return
+	}
+	var req savedQueryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+	query, err := validateSavedQuery(req.Query)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var orgID uint
+	if saved.OrganizationID != nil {
+		orgID = *saved.OrganizationID
+	}
+	if taken, err := h.db.SavedAuditQueryNameTaken(saved.UserID, orgID, req.Name, saved.ID); err != nil {
+		log.Errorf("Failed to update saved audit query: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update saved query"})
+		return
+	} else if taken {
+		c.JSON(http.StatusConflict, gin.H{"error": "A saved query with this name already exists"})
+		return
+	}
+
+	saved.Name, saved.Query, saved.Shared = req.Name, query, req.Shared
+	if err := h.db.UpdateSavedAuditQuery(saved); err != nil {
+		log.Errorf("Failed to update saved audit query: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update saved query"})
+		return
+	}
+	c.JSON(http.StatusOK, saved)
+}
+
+// DeleteSavedQuery handles DELETE /api/v1/audit/queries/:id
+func (h *Handler) DeleteSavedQuery(c *gin.Context) {
+	saved, ok := h.ownSavedQuery(c)
+	if !ok {
+		return
+	}
+	if err := h.db.DeleteSavedAuditQuery(saved.ID); err != nil {
+		log.Errorf("Failed to delete saved audit query: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete saved query"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Saved query deleted"})
+}
@@ -0,0 +1,73 @@
+package audit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+func TestSavedQueries(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database, err := db.New(filepath.Join(t.TempDir(), "kubelens.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	h := NewHandler(database, NewLogger(database), nil)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		userID := 1
+		if c.GetHeader("X-User") == "other" {
+			userID = 2
+		}
+		c.Set("user_id", userID)
+		c.Set("is_admin", false)
+	})
+	router.GET("/audit/queries", h.ListSavedQueries)
+	router.POST("/audit/queries", h.CreateSavedQuery)
+	router.DELETE("/audit/queries/:id", h.DeleteSavedQuery)
+	do := func(method, path, user, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-User", user)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do(http.MethodPost, "/audit/queries", "", `{"name":"prod deletes","query":"?verb=delete&cluster_name=prod","shared":true}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: %d %s", w.Code, w.Body.String())
+	}
+	var saved db.SavedAuditQuery
+	json.Unmarshal(w.Body.Bytes(), &saved)
+	if saved.Query != "cluster_name=prod&verb=delete" {
+		t.Errorf("query = %q, want it normalized", saved.Query)
+	}
+	if w := do(http.MethodPost, "/audit/queries", "", `{"name":"prod deletes","query":"verb=delete"}`); w.Code != http.StatusConflict {
+		t.Errorf("duplicate name: %d, want 409", w.Code)
+	}
+	if w := do(http.MethodPost, "/audit/queries", "", `{"name":"bad","query":"drop=table"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("unknown filter: %d, want 400", w.Code)
+	}
+
+	// Shared queries are listed for others, who may not delete them
+	w = do(http.MethodGet, "/audit/queries", "other", "")
+	if !strings.Contains(w.Body.String(), `"name":"prod deletes"`) {
+		t.Errorf("list for another user = %s", w.Body.String())
+	}
+	if w := do(http.MethodDelete, "/audit/queries/1", "other", ""); w.Code != http.StatusForbidden {
+		t.Errorf("delete by another user: %d, want 403", w.Code)
+	}
+	if w := do(http.MethodDelete, "/audit/queries/1", "", ""); w.Code != http.StatusOK {
+		t.Errorf("delete by the owner: %d %s", w.Code, w.Body.String())
+	}
+}
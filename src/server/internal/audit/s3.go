@@ -0,0 +1,70 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/sonnguyen/kubelens/internal/config"
+	"github.com/sonnguyen/kubelens/internal/objectstore"
+)
+
+// s3Sink writes each batch as an NDJSON object to an S3-compatible bucket
+type s3Sink struct {
+	store    *objectstore.S3
+	prefix   string
+	hostname string
+}
+
+func newS3Sink(cfg config.AuditSinkConfig) (*s3Sink, error) {
+	if !objectstore.KeyPattern.MatchString(cfg.Prefix) {
+		return nil, fmt.Errorf("prefix may only hold letters, digits and . _ / -")
+	}
+	store, err := objectstore.NewS3(objectstore.S3Config{
+		Endpoint:        cfg.Endpoint,
+		Bucket:          cfg.Bucket,
+		Region:          cfg.Region,
+		AccessKeyID:     cfg.AccessKeyID,
+		SecretAccessKey: cfg.SecretAccessKey,
+	})
+	if err != nil {
+		return nil, err
+	}
+	s := &s3Sink{store: store, prefix: cfg.Prefix}
+	if s.prefix != "" && !strings.HasSuffix(s.prefix, "/") {
+		s.prefix += "/"
+	}
+	hostname, _ := os.Hostname()
+	s.hostname = strings.Map(func(r rune) rune {
+		if strings.ContainsRune("._-", r) || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || ('0' <= r && r <= '9') {
+			return r
+		}
+		return '_'
+	}, hostname)
+	if s.hostname == "" {
+		s.hostname = "kubelens"
+	}
+	return s, nil
+}
+
+// Send writes a batch as one object named after its first entry
+func (s *s3Sink) Send(ctx context.Context, entries []LogEntry) error {
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	for _, entry := range entries {
+		if err := encoder.Encode(entry); err != nil {
+			return err
+		}
+	}
+
+	first := entries[0].Datetime.UTC()
+	key := fmt.Sprintf("%s%s/%d-%s.ndjson", s.prefix, first.Format("2006/01/02"), first.UnixNano(), s.hostname)
+	return s.store.Put(ctx, key, "application/x-ndjson", body.Bytes())
+}
+
+func (s *s3Sink) Close() error {
+	return s.store.Close()
+}
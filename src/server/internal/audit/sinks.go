@@ -0,0 +1,337 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/sonnguyen/kubelens/internal/config"
+)
+
+const (
+	// sinkAttempts is how many times a batch is sent before it is given up
+	sinkAttempts = 5
+	// sinkTimeout bounds one attempt
+	sinkTimeout = 30 * time.Second
+	// sinkBlockTimeout bounds how long an entry waits for room in a full "block" queue
+	sinkBlockTimeout = time.Second
+)
+
+// sinkRetryDelay is the wait before the given retry (1-based); a variable so tests can
+// shorten it
+var sinkRetryDelay = func(retry int) time.Duration {
+	return time.Duration(1<<uint(retry-1)) * time.Second
+}
+
+// levelRank orders the levels for the minimum level of a sink
+var levelRank = map[string]int{LevelInfo: 0, LevelWarn: 1, LevelError: 2, LevelCritical: 3}
+
+// Sink ships audit entries to an external system
+type Sink interface {
+	// Send delivers a batch of entries, oldest first
+	Send(ctx context.Context, entries []LogEntry) error
+	// Close releases the connections of the sink
+	Close() error
+}
+
+// SinkStatus describes a sink for the status endpoint
+type SinkStatus struct {
+	Name      string     `json:"name"`
+	Type      string     `json:"type"`
+	Queued    int        `json:"queued"`
+	Sent      int64      `json:"sent"`
+	Dropped   int64      `json:"dropped"` // queue full
+	Failed    int64      `json:"failed"`  // given up after retries
+	LastError string     `json:"last_error,omitempty"`
+	LastSent  *time.Time `json:"last_sent,omitempty"`
+}
+
+// sinkWorker queues the entries of one sink and sends them in batches
+type sinkWorker struct {
+	config.AuditSinkConfig
+	sink          Sink
+	queue         chan LogEntry
+	flushInterval time.Duration
+	categories    map[string]bool
+	minLevel      int
+
+	sent, dropped, failed atomic.Int64
+	mu                    sync.Mutex
+	lastError             string
+	lastSent              *time.Time
+	lastDropWarning       time.Time
+}
+
+// Sinks fans audit entries out to the configured sinks
+type Sinks struct {
+	workers []*sinkWorker
+	done    chan struct{}
+	wg      sync.WaitGroup
+}
+
+// sinks is where Logger ships the entries it records
+var sinks *Sinks
+
+// SetSinks makes every recorded audit entry also go to s (nil stops shipping)
+func SetSinks(s *Sinks) {
+	sinks = s
+}
+
+// NewSinks creates and starts the sinks of a configuration. Errors name the sink at fault.
+func NewSinks(configs []config.AuditSinkConfig) (*Sinks, error) {
+	s := &Sinks{done: make(chan struct{})}
+	names := map[string]bool{}
+	for i, cfg := range configs {
+		if cfg.Name == "" {
+			cfg.Name = fmt.Sprintf("%s-%d", cfg.Type, i+1)
+		}
+		if names[cfg.Name] {
+			s.Close(context.Background())
+			return nil, fmt.Errorf("audit sink %s: duplicate name", cfg.Name)
+		}
+		names[cfg.Name] = true
+		worker, err := newSinkWorker(cfg)
+		if err != nil {
+			s.Close(context.Background())
+			return nil, fmt.Errorf("audit sink %s: %w", cfg.Name, err)
+		}
+		s.workers = append(s.workers, worker)
+	}
+	for _, worker := range s.workers {
+		s.wg.Add(1)
+		go func(w *sinkWorker) {
+			defer s.wg.Done()
+			w.run(s.done)
+		}(worker)
+	}
+	return s, nil
+}
+
+// newSinkWorker applies the defaults of a sink configuration and creates its sink
+func newSinkWorker(cfg config.AuditSinkConfig) (*sinkWorker, error) {
+	w := &sinkWorker{AuditSinkConfig: cfg}
+
+	var err error
+	switch cfg.Type {
+	case "syslog":
+		w.sink, err = newSyslogSink(cfg)
+	case "webhook":
+		w.sink, err = newWebhookSink(cfg)
+	case "s3":
+		w.sink, err = newS3Sink(cfg)
+		// Objects are written per batch, so they are larger and rarer
+		if w.BatchSize == 0 {
+			w.BatchSize = 1000
+		}
+		if w.FlushInterval == "" {
+			w.FlushInterval = "1m"
+		}
+	default:
+		return nil, fmt.Errorf("unknown type %q (syslog, s3 or webhook)", cfg.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if w.QueueSize <= 0 {
+		w.QueueSize = 10000
+	}
+	if w.BatchSize <= 0 {
+		w.BatchSize = 100
+	}
+	if w.FlushInterval == "" {
+		w.FlushInterval = "5s"
+	}
+	if w.flushInterval, err = time.ParseDuration(w.FlushInterval); err != nil || w.flushInterval <= 0 {
+		return nil, fmt.Errorf("invalid flush_interval %q", w.FlushInterval)
+	}
+	switch w.OnFull {
+	case "":
+		w.OnFull = "drop"
+	case "drop", "block":
+	default:
+		return nil, fmt.Errorf("on_full must be drop or block")
+	}
+	if w.MinLevel != "" {
+		rank, ok := levelRank[strings.ToUpper(w.MinLevel)]
+		if !ok {
+			return nil, fmt.Errorf("invalid min_level %q", w.MinLevel)
+		}
+		w.minLevel = rank
+	}
+	if len(w.Categories) > 0 {
+		w.categories = map[string]bool{}
+		for _, category := range w.Categories {
+			w.categories[category] = true
+		}
+	}
+	for _, pattern := range w.EventTypes {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid event type pattern %q", pattern)
+		}
+	}
+
+	w.queue = make(chan LogEntry, w.QueueSize)
+	return w, nil
+}
+
+// Ship queues an entry for the sinks whose filters it passes
+func (s *Sinks) Ship(entry LogEntry) {
+	for _, w := range s.workers {
+		if w.accepts(entry) {
+			w.enqueue(entry)
+		}
+	}
+}
+
+// Status describes every sink
+func (s *Sinks) Status() []SinkStatus {
+	statuses := make([]SinkStatus, 0, len(s.workers))
+	for _, w := range s.workers {
+		w.mu.Lock()
+		statuses = append(statuses, SinkStatus{
+			Name: w.Name, Type: w.Type, Queued: len(w.queue),
+			Sent: w.sent.Load(), Dropped: w.dropped.Load(), Failed: w.failed.Load(),
+			LastError: w.lastError, LastSent: w.lastSent,
+		})
+		w.mu.Unlock()
+	}
+	return statuses
+}
+
+// Close stops accepting entries and sends those queued, until ctx is done
+func (s *Sinks) Close(ctx context.Context) {
+	close(s.done)
+	finished := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+	case <-ctx.Done():
+		log.Warn("Audit sinks did not drain before shutdown")
+	}
+	for _, w := range s.workers {
+		w.sink.Close()
+	}
+}
+
+// accepts reports whether an entry passes the filters of the sink
+func (w *sinkWorker) accepts(entry LogEntry) bool {
+	if w.categories != nil && !w.categories[entry.EventCategory] {
+		return false
+	}
+	if levelRank[entry.Level] < w.minLevel {
+		return false
+	}
+	if len(w.EventTypes) == 0 {
+		return true
+	}
+	for _, pattern := range w.EventTypes {
+		if ok, _ := path.Match(pattern, entry.EventType); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// enqueue queues an entry, dropping it if the queue stays full
+func (w *sinkWorker) enqueue(entry LogEntry) {
+	select {
+	case w.queue <- entry:
+		return
+	default:
+	}
+	if w.OnFull == "block" {
+		timer := time.NewTimer(sinkBlockTimeout)
+		defer timer.Stop()
+		select {
+		case w.queue <- entry:
+			return
+		case <-timer.C:
+		}
+	}
+
+	w.dropped.Add(1)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if time.Since(w.lastDropWarning) > time.Minute {
+		w.lastDropWarning = time.Now()
+		log.Warnf("Audit sink %s is falling behind: its queue of %d is full and entries are dropped (%d so far)", w.Name, w.QueueSize, w.dropped.Load())
+	}
+}
+
+// run sends the queued entries in batches until done is closed, then sends the rest
+func (w *sinkWorker) run(done <-chan struct{}) {
+	ticker := time.NewTicker(w.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]LogEntry, 0, w.BatchSize)
+	flush := func() {
+		if len(batch) > 0 {
+			w.send(batch)
+			batch = make([]LogEntry, 0, w.BatchSize)
+		}
+	}
+	for {
+		select {
+		case entry := <-w.queue:
+			batch = append(batch, entry)
+			if len(batch) >= w.BatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-done:
+			for {
+				select {
+				case entry := <-w.queue:
+					batch = append(batch, entry)
+					if len(batch) >= w.BatchSize {
+						flush()
+					}
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+// send delivers a batch, retrying with backoff. Entries keep queueing meanwhile, so a sink
+// that stays down fills its queue and drops entries instead of holding up requests.
+func (w *sinkWorker) send(batch []LogEntry) {
+	var err error
+	for attempt := 1; attempt <= sinkAttempts; attempt++ {
+		if attempt > 1 {
+			time.Sleep(sinkRetryDelay(attempt - 1))
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), sinkTimeout)
+		err = w.sink.Send(ctx, batch)
+		cancel()
+		if err == nil {
+			now := time.Now()
+			w.sent.Add(int64(len(batch)))
+			w.mu.Lock()
+			w.lastSent = &now
+			w.lastError = ""
+			w.mu.Unlock()
+			return
+		}
+		log.Debugf("Audit sink %s: attempt %d failed: %v", w.Name, attempt, err)
+	}
+
+	w.failed.Add(int64(len(batch)))
+	w.mu.Lock()
+	w.lastError = err.Error()
+	w.mu.Unlock()
+	log.Errorf("Audit sink %s: %d entries lost after %d attempts: %v", w.Name, len(batch), sinkAttempts, err)
+}
@@ -0,0 +1,173 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sonnguyen/kubelens/internal/config"
+)
+
+func testEntry(eventType, category, level string) LogEntry {
+	return LogEntry{EventType: eventType, EventCategory: category, Level: level, Description: eventType, Datetime: time.Now()}
+}
+
+func TestWebhookSink(t *testing.T) {
+	var mu sync.Mutex
+	var received []LogEntry
+	var signature string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var batch []LogEntry
+		json.NewDecoder(r.Body).Decode(&batch)
+		mu.Lock()
+		received = append(received, batch...)
+		signature = r.Header.Get("X-Kubelens-Signature")
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	s, err := NewSinks([]config.AuditSinkConfig{{
+		Type: "webhook", URL: server.URL, SigningSecret: "s3cret",
+		MinLevel: "warn", EventTypes: []string{"authn_*"}, FlushInterval: "10ms",
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Ship(testEntry(EventLoginFailed, CategoryAuthentication, LevelWarn))
+	s.Ship(testEntry(EventLoginSuccess, CategoryAuthentication, LevelInfo)) // below the minimum level
+	s.Ship(testEntry(EventAuditUserCreated, CategoryAudit, LevelError))     // other event type
+	s.Close(context.Background())
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(received) != 1 || received[0].EventType != EventLoginFailed {
+		t.Fatalf("received %v, want the failed login only", received)
+	}
+	if !strings.HasPrefix(signature, "sha256=") {
+		t.Errorf("signature = %q", signature)
+	}
+	if status := s.Status()[0]; status.Sent != 1 || status.Name != "webhook-1" {
+		t.Errorf("status = %+v", status)
+	}
+}
+
+func TestSinkBackpressure(t *testing.T) {
+	sinkRetryDelay = func(int) time.Duration { return time.Millisecond }
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	s, err := NewSinks([]config.AuditSinkConfig{{Type: "webhook", URL: server.URL, QueueSize: 2, BatchSize: 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The first entry is being sent, two wait and the others are dropped
+	for i := 0; i < 6; i++ {
+		s.Ship(testEntry("audit_test", CategoryAudit, LevelInfo))
+		time.Sleep(5 * time.Millisecond)
+	}
+	if dropped := s.Status()[0].Dropped; dropped != 3 {
+		t.Errorf("dropped = %d, want 3", dropped)
+	}
+	close(release)
+	s.Close(context.Background())
+	if status := s.Status()[0]; status.Failed != 3 || status.LastError == "" {
+		t.Errorf("status = %+v, want 3 failed entries", status)
+	}
+}
+
+func TestSyslogSink(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	lines := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		length, _ := r.ReadString(' ')
+		var size int
+		json.Unmarshal([]byte(strings.TrimSpace(length)), &size)
+		msg := make([]byte, size)
+		io.ReadFull(r, msg)
+		lines <- string(msg)
+	}()
+
+	s, err := NewSinks([]config.AuditSinkConfig{{Type: "syslog", Address: "tcp://" + ln.Addr().String(), FlushInterval: "10ms"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Ship(testEntry(EventLoginFailed, CategoryAuthentication, LevelWarn))
+	s.Close(context.Background())
+
+	select {
+	case msg := <-lines:
+		// facility 13 (log audit) * 8 + severity 4 (warning)
+		if !strings.HasPrefix(msg, "<108>1 ") || !strings.Contains(msg, " kubelens ") || !strings.Contains(msg, `"event_type":"authn_login_failed"`) {
+			t.Errorf("message = %q", msg)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no syslog message received")
+	}
+}
+
+func TestS3Sink(t *testing.T) {
+	var path, authorization, body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		path, authorization, body = r.URL.Path, r.Header.Get("Authorization"), string(data)
+	}))
+	defer server.Close()
+
+	s, err := NewSinks([]config.AuditSinkConfig{{
+		Type: "s3", Endpoint: server.URL, Bucket: "audit", Prefix: "kubelens",
+		AccessKeyID: "AKID", SecretAccessKey: "secret", Region: "eu-west-1",
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Ship(testEntry(EventLoginSuccess, CategoryAuthentication, LevelInfo))
+	s.Ship(testEntry(EventLogout, CategoryAuthentication, LevelInfo))
+	s.Close(context.Background())
+
+	if !strings.HasPrefix(path, "/audit/kubelens/"+time.Now().UTC().Format("2006/01/02")+"/") || !strings.HasSuffix(path, ".ndjson") {
+		t.Errorf("object path = %q", path)
+	}
+	if !strings.HasPrefix(authorization, "AWS4-HMAC-SHA256 Credential=AKID/") || !strings.Contains(authorization, "/eu-west-1/s3/aws4_request") {
+		t.Errorf("authorization = %q", authorization)
+	}
+	if lines := strings.Split(strings.TrimSpace(body), "\n"); len(lines) != 2 {
+		t.Errorf("body has %d lines, want 2 NDJSON entries", len(lines))
+	}
+}
+
+func TestNewSinksValidation(t *testing.T) {
+	for _, cfg := range []config.AuditSinkConfig{
+		{Type: "kafka"},
+		{Type: "syslog", Address: "localhost:514"},
+		{Type: "webhook", URL: "ftp://example.com"},
+		{Type: "webhook", URL: "https://example.com", OnFull: "wait"},
+		{Type: "webhook", URL: "https://example.com", MinLevel: "debug"},
+		{Type: "s3", Bucket: "audit", AccessKeyID: "AKID", SecretAccessKey: "secret", Prefix: "a b"},
+	} {
+		if _, err := NewSinks([]config.AuditSinkConfig{cfg}); err == nil {
+			t.Errorf("NewSinks(%+v) succeeded, want an error", cfg)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package audit
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/sonnguyen/kubelens/internal/sse"
+)
+
+const (
+	// streamPollInterval is how often streams look for entries recorded by other replicas;
+	// entries recorded by this one wake them at once
+	streamPollInterval = time.Second
+	// streamBatch bounds the entries read at a time
+	streamBatch = 500
+)
+
+// signal wakes every waiter when notified
+type signal struct {
+	mu sync.Mutex
+	ch chan struct{}
+}
+
+// recorded is notified whenever this replica records an audit entry
+var recorded = &signal{ch: make(chan struct{})}
+
+// wait returns a channel closed at the next notify
+func (s *signal) wait() <-chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.ch
+}
+
+func (s *signal) notify() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	close(s.ch)
+	s.ch = make(chan struct{})
+}
+
+// StreamAuditLogs handles GET /api/v1/audit/logs/stream: the audit logs recorded from now
+// on that match the filters of ListAuditLogs, as server-sent events. Each "audit" event
+// holds an entry as JSON with its ID as event ID, so a client reconnecting with
+// Last-Event-ID (or ?since_id=) gets the entries it missed.
+func (h *Handler) StreamAuditLogs(c *gin.Context) {
+	filters := listFilters(c)
+
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("since_id")
+	}
+	var lastID uint
+	if lastEventID != "" {
+		id, err := strconv.ParseUint(lastEventID, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid last event ID"})
+			return
+		}
+		lastID = uint(id)
+	} else {
+		id, err := h.db.LatestAuditLogID()
+		if err != nil {
+			log.Errorf("Failed to start audit log stream: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to stream audit logs"})
+			return
+		}
+		lastID = id
+	}
+
+	stream, err := sse.Start(c.Writer, 3*time.Second)
+	if err != nil {
+		return
+	}
+
+	poll := time.NewTicker(streamPollInterval)
+	defer poll.Stop()
+	lastWrite := time.Now()
+	for {
+		wake := recorded.wait()
+		entries, err := h.db.ListAuditLogsAfter(lastID, streamBatch, filters)
+		if err != nil {
+			log.Errorf("Failed to read audit logs for a stream: %v", err)
+			stream.Event("", "error", []byte(`{"error":"Failed to retrieve audit logs"}`))
+			return
+		}
+		for _, entry := range entries {
+			data, err := json.Marshal(entry)
+			if err != nil {
+				continue
+			}
+			if stream.Event(strconv.FormatUint(uint64(entry.ID), 10), "audit", data) != nil {
+				return
+			}
+			lastID = entry.ID
+			lastWrite = time.Now()
+		}
+		if len(entries) == streamBatch {
+			continue
+		}
+
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-wake:
+		case <-poll.C:
+			if time.Since(lastWrite) >= sse.Heartbeat {
+				if stream.Comment("ping") != nil {
+					return
+				}
+				lastWrite = time.Now()
+			}
+		}
+	}
+}
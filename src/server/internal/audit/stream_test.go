@@ -0,0 +1,94 @@
+package audit
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+// readEvents returns the data lines of the next n "audit" events of a stream
+func readEvents(t *testing.T, r *bufio.Reader, n int) []string {
+	t.Helper()
+	lines := make(chan string)
+	go func() {
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				close(lines)
+				return
+			}
+			lines <- strings.TrimSpace(line)
+		}
+	}()
+	var events []string
+	timeout := time.After(5 * time.Second)
+	for len(events) < n {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				t.Fatalf("stream closed after %d events", len(events))
+			}
+			if strings.HasPrefix(line, "data: ") {
+				events = append(events, strings.TrimPrefix(line, "data: "))
+			}
+		case <-timeout:
+			t.Fatalf("got %d events, want %d", len(events), n)
+		}
+	}
+	return events
+}
+
+func TestStreamAuditLogs(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database, err := db.New(filepath.Join(t.TempDir(), "kubelens.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	logger := NewLogger(database)
+	logger.Log(LogEntry{EventType: EventLoginFailed, EventCategory: CategoryAuthentication, Level: LevelWarn, Description: "before the stream"})
+
+	router := gin.New()
+	router.GET("/audit/logs/stream", NewHandler(database, logger, nil).StreamAuditLogs)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/audit/logs/stream?event_category=" + CategoryAuthentication)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("status %d, content type %q", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	body := bufio.NewReader(resp.Body)
+
+	// Entries recorded before the stream and those of other categories are left out
+	logger.Log(LogEntry{EventType: EventAuditUserCreated, EventCategory: CategoryAudit, Level: LevelInfo, Description: "other category"})
+	logger.Log(LogEntry{EventType: EventLoginSuccess, EventCategory: CategoryAuthentication, Level: LevelInfo, Description: "during the stream"})
+	events := readEvents(t, body, 1)
+	if !strings.Contains(events[0], `"description":"during the stream"`) {
+		t.Errorf("event = %s, want the login during the stream", events[0])
+	}
+
+	// A reconnecting client gets what it missed
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/audit/logs/stream?event_category="+CategoryAuthentication, nil)
+	req.Header.Set("Last-Event-ID", "0")
+	replay, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer replay.Body.Close()
+	events = readEvents(t, bufio.NewReader(replay.Body), 2)
+	if !strings.Contains(events[0], "before the stream") || !strings.Contains(events[1], "during the stream") {
+		t.Errorf("replayed events = %v", events)
+	}
+}
@@ -0,0 +1,143 @@
+package audit
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sonnguyen/kubelens/internal/config"
+)
+
+// syslogSeverity maps levels to RFC 5424 severities
+var syslogSeverity = map[string]int{LevelInfo: 6, LevelWarn: 4, LevelError: 3, LevelCritical: 2}
+
+// syslogSink writes entries as RFC 5424 messages, the entry as JSON in the message body.
+// Over TCP and TLS messages are framed by octet counting (RFC 6587).
+type syslogSink struct {
+	network  string // udp, tcp or tls
+	address  string
+	facility int
+	appName  string
+	hostname string
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+func newSyslogSink(cfg config.AuditSinkConfig) (*syslogSink, error) {
+	u, err := url.Parse(cfg.Address)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("address must be udp://, tcp:// or tls://host:port")
+	}
+	switch u.Scheme {
+	case "udp", "tcp", "tls":
+	default:
+		return nil, fmt.Errorf("address must be udp://, tcp:// or tls://host:port")
+	}
+	facility := cfg.Facility
+	if facility == 0 {
+		facility = 13 // log audit
+	}
+	if facility < 0 || facility > 23 {
+		return nil, fmt.Errorf("facility must be between 0 and 23")
+	}
+	appName := sanitizeHeader(cfg.AppName)
+	if appName == "" {
+		appName = "kubelens"
+	}
+	hostname, _ := os.Hostname()
+	if hostname = sanitizeHeader(hostname); hostname == "" {
+		hostname = "-"
+	}
+	return &syslogSink{network: u.Scheme, address: u.Host, facility: facility, appName: appName, hostname: hostname}, nil
+}
+
+// format renders an entry as an RFC 5424 message
+func (s *syslogSink) format(entry LogEntry) ([]byte, error) {
+	body, err := json.Marshal(entry)
+	if err != nil {
+		return nil, err
+	}
+	severity, ok := syslogSeverity[entry.Level]
+	if !ok {
+		severity = 6
+	}
+	msgID := sanitizeHeader(entry.EventType)
+	if msgID == "" || len(msgID) > 32 {
+		msgID = "-"
+	}
+	header := fmt.Sprintf("<%d>1 %s %s %s %d %s - ", s.facility*8+severity,
+		entry.Datetime.UTC().Format(time.RFC3339Nano), s.hostname, s.appName, os.Getpid(), msgID)
+	return append([]byte(header), body...), nil
+}
+
+// dial connects to the syslog endpoint
+func (s *syslogSink) dial(ctx context.Context) (net.Conn, error) {
+	dialer := &net.Dialer{}
+	if s.network == "tls" {
+		host, _, _ := net.SplitHostPort(s.address)
+		return (&tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: host, MinVersion: tls.VersionTLS12}}).DialContext(ctx, "tcp", s.address)
+	}
+	return dialer.DialContext(ctx, s.network, s.address)
+}
+
+// Send writes the messages of a batch, connecting again after an error. A batch retried
+// after a failed write may repeat the messages written before it.
+func (s *syslogSink) Send(ctx context.Context, entries []LogEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil {
+		conn, err := s.dial(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to connect to syslog: %w", err)
+		}
+		s.conn = conn
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		s.conn.SetWriteDeadline(deadline)
+	}
+	for _, entry := range entries {
+		msg, err := s.format(entry)
+		if err != nil {
+			return err
+		}
+		if s.network != "udp" {
+			msg = append([]byte(fmt.Sprintf("%d ", len(msg))), msg...)
+		}
+		if _, err := s.conn.Write(msg); err != nil {
+			s.conn.Close()
+			s.conn = nil
+			return fmt.Errorf("failed to write to syslog: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s *syslogSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+// sanitizeHeader keeps an RFC 5424 header field printable and space-free
+func sanitizeHeader(value string) string {
+	return strings.Map(func(r rune) rune {
+		if r <= ' ' || r > '~' {
+			return '_'
+		}
+		return r
+	}, value)
+}
@@ -16,6 +16,15 @@ const (
 	CategorySystem         = "system"
 )
 
+// Context keys shared with the middleware that records changes to Kubernetes objects
+const (
+	// ChangesKey holds a func() interface{} returning the changes the request made to an
+	// object; Log records them in the metadata as "changes"
+	ChangesKey = "audit_changes"
+	// LoggedKey is set once an entry of the request has recorded its changes
+	LoggedKey = "audit_logged"
+)
+
 // Log levels
 const (
 	LevelInfo     = "INFO"
@@ -37,6 +46,9 @@ const (
 	EventAuthMFAVerifyFailed  = "authn_mfa_verify_failed"
 	EventAuthSessionExpired   = "authn_session_expired"
 	EventAuthTokenRefresh     = "authn_token_refresh"
+	EventAuthAPITokenUsed     = "authn_api_token_used"
+	EventAuthSessionRevoked   = "authn_session_revoked"
+	EventAuthOrganizationSwitched = "authn_organization_switched"
 
 	// Aliases for backward compatibility
 	EventLoginSuccess          = EventAuthLoginSuccess
@@ -88,10 +100,29 @@ const (
 	EventAuditClusterRemoved   = "audit_cluster_removed"
 	EventAuditClusterEnabled   = "audit_cluster_enabled"
 	EventAuditClusterDisabled  = "audit_cluster_disabled"
+	EventAuditClusterCredentialsRotated = "audit_cluster_credentials_rotated"
 	EventAuditResourceCreated  = "audit_resource_created"
 	EventAuditResourceUpdated  = "audit_resource_updated"
 	EventAuditResourceDeleted  = "audit_resource_deleted"
 	EventAuditConfigChanged    = "audit_config_changed"
+	EventAuditInvitationCreated  = "audit_invitation_created"
+	EventAuditInvitationRevoked  = "audit_invitation_revoked"
+	EventAuditInvitationAccepted = "audit_invitation_accepted"
+	EventAuditAPITokenCreated    = "audit_api_token_created"
+	EventAuditAPITokenRevoked    = "audit_api_token_revoked"
+	EventAuditBackupExported     = "audit_backup_exported"
+	EventAuditBackupRestored     = "audit_backup_restored"
+	EventAuditOrganizationCreated       = "audit_organization_created"
+	EventAuditOrganizationUpdated       = "audit_organization_updated"
+	EventAuditOrganizationDeleted       = "audit_organization_deleted"
+	EventAuditOrganizationMemberAdded   = "audit_organization_member_added"
+	EventAuditOrganizationMemberUpdated = "audit_organization_member_updated"
+	EventAuditOrganizationMemberRemoved = "audit_organization_member_removed"
+	EventAuditOrganizationClusterMoved  = "audit_organization_cluster_moved"
+	EventAuditChainAnchored             = "audit_chain_anchored"
+	EventAuditShellSessionStarted       = "audit_shell_session_started"
+	EventAuditShellCommand              = "audit_shell_command"
+	EventAuditShellSessionEnded         = "audit_shell_session_ended"
 
 	// Aliases for backward compatibility
 	EventUserCreated    = EventAuditUserCreated
@@ -0,0 +1,87 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sonnguyen/kubelens/internal/config"
+)
+
+// webhookSink POSTs batches to an HTTP endpoint as a JSON array. Deliveries are signed
+// like those of notification webhooks: X-Kubelens-Signature is
+// sha256=<hex of HMAC(secret, "<X-Kubelens-Timestamp>.<body>")>.
+type webhookSink struct {
+	url           string
+	headers       map[string]string
+	signingSecret string
+	client        *http.Client
+}
+
+func newWebhookSink(cfg config.AuditSinkConfig) (*webhookSink, error) {
+	u, err := url.Parse(cfg.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("url must be an http(s) URL")
+	}
+	for name := range cfg.Headers {
+		if name == "" || strings.ContainsAny(name, " :\r\n") {
+			return nil, fmt.Errorf("invalid header name %q", name)
+		}
+		if strings.HasPrefix(http.CanonicalHeaderKey(name), "X-Kubelens-") {
+			return nil, fmt.Errorf("header %s is set by kubelens", name)
+		}
+	}
+	return &webhookSink{url: cfg.URL, headers: cfg.Headers, signingSecret: cfg.SigningSecret, client: &http.Client{}}, nil
+}
+
+// Send posts a batch; responses other than 2xx are errors
+func (s *webhookSink) Send(ctx context.Context, entries []LogEntry) error {
+	payload, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "kubelens-audit")
+	for name, value := range s.headers {
+		req.Header.Set(name, value)
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("X-Kubelens-Timestamp", timestamp)
+	req.Header.Set("X-Kubelens-Event", "audit")
+	if s.signingSecret != "" {
+		mac := hmac.New(sha256.New, []byte(s.signingSecret))
+		mac.Write([]byte(timestamp + "."))
+		mac.Write(payload)
+		req.Header.Set("X-Kubelens-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("webhook returned HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+func (s *webhookSink) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
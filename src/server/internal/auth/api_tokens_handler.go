@@ -0,0 +1,386 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	"github.com/sonnguyen/kubelens/internal/audit"
+	"github.com/sonnguyen/kubelens/internal/db"
+	"github.com/sonnguyen/kubelens/internal/middleware"
+)
+
+const (
+	// APITokenPrefix marks personal access tokens so the middleware can tell them from JWTs
+	APITokenPrefix = "klp_"
+	// defaultAPITokenTTL is used when the user does not specify an expiry
+	defaultAPITokenTTL = 90 * 24 * time.Hour
+	// maxAPITokenTTL caps how long an API token stays valid
+	maxAPITokenTTL = 365 * 24 * time.Hour
+)
+
+// apiTokenStore is what the middleware needs to authenticate API tokens
+type apiTokenStore interface {
+	GetActiveAPITokenByHash(tokenHash string) (*db.APIToken, error)
+	TouchAPIToken(id uint, ip string) error
+}
+
+// clusterGroupExpander resolves the group:<name> cluster patterns of token scopes
+type clusterGroupExpander interface {
+	ExpandClusterGroups(permissions []db.Permission) ([]db.Permission, error)
+}
+
+// generateAPIToken returns a random token and its SHA-256 hash.
+// Only the hash is persisted, so a database leak does not expose usable tokens.
+func generateAPIToken() (string, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	token := APITokenPrefix + hex.EncodeToString(b)
+	return token, hashAPIToken(token), nil
+}
+
+// hashAPIToken hashes an API token for storage and lookup
+func hashAPIToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// apiTokenPermissions parses the scopes of a token; none means the owner's permissions
+func apiTokenPermissions(token *db.APIToken) []db.Permission {
+	var permissions []db.Permission
+	if len(token.Permissions) > 0 {
+		if err := json.Unmarshal([]byte(token.Permissions), &permissions); err != nil {
+			log.Warnf("Failed to parse permissions of API token %d: %v", token.ID, err)
+			// Fail closed: an unreadable scope grants nothing rather than everything
+			return []db.Permission{}
+		}
+	}
+	return permissions
+}
+
+// apiTokenTouchInterval is how often the use of an API token from the same address is
+// recorded on the token and in the audit log
+const apiTokenTouchInterval = time.Minute
+
+// authenticateAPIToken validates a personal access token and sets the user context.
+// A scoped token never carries admin rights; its scopes are enforced by PermissionChecker.
+func authenticateAPIToken(c *gin.Context, tokenString string) bool {
+	store, ok := middlewareDB.(apiTokenStore)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
+		return false
+	}
+
+	token, err := store.GetActiveAPITokenByHash(hashAPIToken(tokenString))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
+		return false
+	}
+
+	user, err := middlewareDB.GetUserByID(token.UserID)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not found"})
+		return false
+	}
+	if !user.IsActive {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "account is disabled"})
+		return false
+	}
+
+	// Automation calls in bursts: record the first use of each interval, or from a new address
+	touch := token.LastUsedAt == nil || time.Since(*token.LastUsedAt) > apiTokenTouchInterval || token.LastUsedIP != c.ClientIP()
+	if touch {
+		if err := store.TouchAPIToken(token.ID, c.ClientIP()); err != nil {
+			log.Warnf("Failed to record use of API token %d: %v", token.ID, err)
+		}
+	}
+
+	permissions := apiTokenPermissions(token)
+	scoped := len(token.Permissions) > 0
+	if expander, ok := middlewareDB.(clusterGroupExpander); ok && scoped {
+		expanded, err := expander.ExpandClusterGroups(permissions)
+		if err != nil {
+			log.Errorf("Failed to resolve cluster groups of API token %d: %v", token.ID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check permissions"})
+			return false
+		}
+		permissions = expanded
+	}
+
+	// A token works in the organization it was created in
+	if err := setOrganization(c, user, token.OrganizationID); err != nil {
+		abortOrganization(c, err)
+		return false
+	}
+
+	c.Set("user", user)
+	c.Set("user_id", int(user.ID))
+	c.Set("email", user.Email)
+	c.Set("username", user.Username)
+	c.Set("is_admin", user.IsAdmin && !scoped)
+	c.Set("api_token_id", token.ID)
+	if scoped {
+		c.Set("token_permissions", permissions)
+		// Nor does a scoped token carry the organization role of its user
+		c.Set("org_role", "")
+	}
+
+	if touch {
+		audit.Log(c, audit.EventAuthAPITokenUsed, int(user.ID), user.Username, user.Email,
+			fmt.Sprintf("API token %q used for %s %s", token.Name, c.Request.Method, c.Request.URL.Path),
+			map[string]interface{}{
+				"token_id":   token.ID,
+				"token_name": token.Name,
+				"method":     c.Request.Method,
+				"path":       c.Request.URL.Path,
+			})
+	}
+	return true
+}
+
+// coversPermission reports whether one of the owned permissions grants action on the
+// resource of perm in every cluster and namespace perm is scoped to
+func coversPermission(owned []db.Permission, perm db.Permission, action string) bool {
+	for _, o := range owned {
+		if (o.Resource == "*" || o.Resource == perm.Resource) && containsAction(o.Actions, action) &&
+			coversPatterns(o.Clusters, perm.Clusters) && coversPatterns(o.Namespaces, perm.Namespaces) {
+			return true
+		}
+	}
+	return false
+}
+
+// coversPatterns reports whether every name the requested scope patterns match is also
+// matched by the owned ones. An unrestricted scope is only covered by an unrestricted one.
+func coversPatterns(owned, requested []string) bool {
+	if !isRestricted(owned) {
+		return true
+	}
+	if !isRestricted(requested) {
+		return false
+	}
+	for _, r := range requested {
+		covered := false
+		for _, o := range owned {
+			if patternCovers(o, r) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			return false
+		}
+	}
+	return true
+}
+
+// patternCovers reports whether the pattern owned matches every name requested matches:
+// requested is the same pattern, a name owned matches, or starts with the literal prefix
+// of an owned "prefix*" pattern
+func patternCovers(owned, requested string) bool {
+	if owned == requested {
+		return true
+	}
+	if !strings.ContainsAny(requested, `*?[\`) {
+		return matchesScope([]string{owned}, requested)
+	}
+	prefix, isPrefix := strings.CutSuffix(owned, "*")
+	if !isPrefix || strings.ContainsAny(prefix, `*?[\`) {
+		return false
+	}
+	literal := requested[:strings.IndexAny(requested, `*?[\`)]
+	return strings.HasPrefix(literal, prefix)
+}
+
+// CreateAPIToken creates a personal access token for the current user. The token can be
+// scoped to a subset of the user's permissions and is only returned once.
+func (h *Handler) CreateAPIToken(c *gin.Context) {
+	var req struct {
+		Name          string          `json:"name" binding:"required,min=1,max=255"`
+		Permissions   []db.Permission `json:"permissions"`
+		ExpiresInDays int             `json:"expires_in_days"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Tokens cannot mint more tokens
+	if _, viaToken := c.Get("api_token_id"); viaToken {
+		c.JSON(http.StatusForbidden, gin.H{"error": "API tokens cannot be created with an API token"})
+		return
+	}
+
+	userID := c.GetInt("user_id")
+	req.Name = middleware.SanitizeString(strings.TrimSpace(req.Name))
+
+	// A token can only be scoped to what its owner is allowed to do in the organization,
+	// in the clusters and namespaces the owner is allowed to do it in
+	if len(req.Permissions) > 0 && !c.GetBool("is_admin") {
+		owned, err := h.userPermissions(c)
+		if err != nil {
+			log.Errorf("Failed to get user permissions: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create API token"})
+			return
+		}
+		requested, err := h.db.ExpandClusterGroups(req.Permissions)
+		if err != nil {
+			log.Errorf("Failed to resolve cluster groups: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create API token"})
+			return
+		}
+		for _, perm := range requested {
+			for _, action := range perm.Actions {
+				// Organization owners and admins may do anything within their organization
+				if isOrganizationAdmin(c) && !instanceWide(perm.Resource, action) {
+					continue
+				}
+				if !hasPermission(owned, perm.Resource, action) {
+					c.JSON(http.StatusForbidden, gin.H{"error": fmt.Sprintf("you do not have permission %s:%s", perm.Resource, action)})
+					return
+				}
+				if !coversPermission(owned, perm, action) {
+					c.JSON(http.StatusForbidden, gin.H{"error": fmt.Sprintf("you do not have permission %s:%s in all of the requested clusters and namespaces", perm.Resource, action)})
+					return
+				}
+			}
+		}
+	}
+
+	ttl := defaultAPITokenTTL
+	if req.ExpiresInDays > 0 {
+		ttl = time.Duration(req.ExpiresInDays) * 24 * time.Hour
+	}
+	if ttl > maxAPITokenTTL {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("expiry cannot exceed %d days", int(maxAPITokenTTL.Hours()/24))})
+		return
+	}
+
+	rawToken, tokenHash, err := generateAPIToken()
+	if err != nil {
+		log.Errorf("Failed to generate API token: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create API token"})
+		return
+	}
+
+	expiresAt := time.Now().Add(ttl)
+	token := &db.APIToken{
+		UserID:         uint(userID),
+		OrganizationID: organizationID(c),
+		Name:           req.Name,
+		Prefix:         rawToken[:len(APITokenPrefix)+8],
+		TokenHash:      tokenHash,
+		ExpiresAt:      &expiresAt,
+	}
+	if len(req.Permissions) > 0 {
+		permissionsJSON, _ := json.Marshal(req.Permissions)
+		token.Permissions = db.JSON(permissionsJSON)
+	}
+
+	if err := h.db.CreateAPIToken(token); err != nil {
+		log.Errorf("Failed to create API token: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create API token"})
+		return
+	}
+
+	log.Infof("API token %q created for user %d (expires %s)", token.Name, userID, expiresAt.Format(time.RFC3339))
+
+	// Audit log
+	if username, exists := c.Get("username"); exists {
+		email, _ := c.Get("email")
+		audit.Log(c, audit.EventAuditAPITokenCreated, userID, username.(string), email.(string),
+			fmt.Sprintf("Created API token: %s", token.Name),
+			map[string]interface{}{
+				"token_id":   token.ID,
+				"token_name": token.Name,
+				"scoped":     len(req.Permissions) > 0,
+				"expires_at": expiresAt,
+			})
+	}
+
+	// The token is only returned once; it cannot be recovered later
+	c.JSON(http.StatusCreated, gin.H{
+		"api_token": token,
+		"token":     rawToken,
+	})
+}
+
+// ListMyAPITokens lists the current user's API tokens
+func (h *Handler) ListMyAPITokens(c *gin.Context) {
+	tokens, err := h.db.ListAPITokensByUser(uint(c.GetInt("user_id")))
+	if err != nil {
+		log.Errorf("Failed to list API tokens: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list API tokens"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// RevokeMyAPIToken revokes one of the current user's API tokens
+func (h *Handler) RevokeMyAPIToken(c *gin.Context) {
+	h.revokeAPIToken(c, true)
+}
+
+// ListAPITokens lists the API tokens of all users in the caller's organization (admin only)
+func (h *Handler) ListAPITokens(c *gin.Context) {
+	tokens, err := h.db.ListAPITokens(organizationID(c))
+	if err != nil {
+		log.Errorf("Failed to list API tokens: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list API tokens"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// RevokeAPIToken revokes any user's API token in the caller's organization (admin only)
+func (h *Handler) RevokeAPIToken(c *gin.Context) {
+	h.revokeAPIToken(c, false)
+}
+
+func (h *Handler) revokeAPIToken(c *gin.Context, ownOnly bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid API token ID"})
+		return
+	}
+
+	token, err := h.db.GetAPITokenByID(uint(id))
+	if err != nil || (ownOnly && token.UserID != uint(c.GetInt("user_id"))) || (!ownOnly && token.OrganizationID != organizationID(c)) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "API token not found"})
+		return
+	}
+
+	if err := h.db.RevokeAPIToken(token.ID); err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+
+	log.Infof("API token %d (%s) of user %d revoked", token.ID, token.Name, token.UserID)
+
+	// Audit log
+	if username, exists := c.Get("username"); exists {
+		email, _ := c.Get("email")
+		audit.Log(c, audit.EventAuditAPITokenRevoked, c.GetInt("user_id"), username.(string), email.(string),
+			fmt.Sprintf("Revoked API token: %s", token.Name),
+			map[string]interface{}{
+				"token_id":       token.ID,
+				"token_name":     token.Name,
+				"target_user_id": token.UserID,
+			})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "API token revoked successfully"})
+}
@@ -0,0 +1,234 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+func TestGenerateAPIToken(t *testing.T) {
+	token, hash, err := generateAPIToken()
+	if err != nil {
+		t.Fatalf("generateAPIToken() error = %v", err)
+	}
+	if !strings.HasPrefix(token, APITokenPrefix) {
+		t.Errorf("token %q does not start with %q", token, APITokenPrefix)
+	}
+	if hash != hashAPIToken(token) || hash == token {
+		t.Error("hash must be the SHA-256 of the token")
+	}
+}
+
+func TestPermissionCheckerEnforcesTokenScopes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := &Handler{}
+
+	tests := []struct {
+		name     string
+		resource string
+		want     int
+	}{
+		{name: "granted by token", resource: "audit", want: http.StatusOK},
+		{name: "outside token scope", resource: "users", want: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/", func(c *gin.Context) {
+				c.Set("user_id", 1)
+				c.Set("is_admin", true)
+				c.Set("token_permissions", []db.Permission{{Resource: "audit", Actions: []string{"read"}}})
+			}, h.PermissionChecker(tt.resource, "read"), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
+
+// fakeAPITokenStore serves one API token and counts the uses recorded on it
+type fakeAPITokenStore struct {
+	user    *db.User
+	token   *db.APIToken
+	touches int
+}
+
+func (s *fakeAPITokenStore) GetUserByID(id uint) (*db.User, error) {
+	return s.user, nil
+}
+
+func (s *fakeAPITokenStore) GetActiveAPITokenByHash(tokenHash string) (*db.APIToken, error) {
+	if tokenHash != s.token.TokenHash {
+		return nil, fmt.Errorf("API token not found, revoked or expired")
+	}
+	token := *s.token
+	return &token, nil
+}
+
+func (s *fakeAPITokenStore) TouchAPIToken(id uint, ip string) error {
+	now := time.Now()
+	s.token.LastUsedAt, s.token.LastUsedIP = &now, ip
+	s.touches++
+	return nil
+}
+
+func TestAPITokenUseRecordedOncePerInterval(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	raw, hash, err := generateAPIToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := &fakeAPITokenStore{
+		user:  &db.User{ID: 1, Username: "ci", IsActive: true},
+		token: &db.APIToken{ID: 1, UserID: 1, Name: "ci", TokenHash: hash},
+	}
+	SetMiddlewareDB(store)
+	t.Cleanup(func() { SetMiddlewareDB(nil) })
+
+	router := gin.New()
+	router.GET("/clusters", AuthMiddleware("test-secret"), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	tests := []struct {
+		name        string
+		ip          string
+		lastUsedAgo time.Duration // moves the last recorded use back, 0 to keep it
+		wantTouches int
+	}{
+		{name: "first use", ip: "10.0.0.1", wantTouches: 1},
+		{name: "again within the interval", ip: "10.0.0.1", wantTouches: 1},
+		{name: "from another address", ip: "10.0.0.2", wantTouches: 2},
+		{name: "after the interval", ip: "10.0.0.2", lastUsedAgo: 2 * apiTokenTouchInterval, wantTouches: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.lastUsedAgo > 0 {
+				lastUsed := time.Now().Add(-tt.lastUsedAgo)
+				store.token.LastUsedAt = &lastUsed
+			}
+			req := httptest.NewRequest(http.MethodGet, "/clusters", nil)
+			req.RemoteAddr = tt.ip + ":51234"
+			req.Header.Set("Authorization", "Bearer "+raw)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+			}
+			if store.touches != tt.wantTouches {
+				t.Errorf("uses recorded = %d, want %d", store.touches, tt.wantTouches)
+			}
+		})
+	}
+}
+
+// TestCreateAPITokenScopedToOwner checks that a token can only be scoped to permissions
+// the owner holds in the organization it is created in, and within the clusters and
+// namespaces of those permissions
+func TestCreateAPITokenScopedToOwner(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database, err := db.New(filepath.Join(t.TempDir(), "kubelens.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	user := &db.User{Email: "dev@example.com", Username: "dev"}
+	if err := database.CreateUser(user); err != nil {
+		t.Fatal(err)
+	}
+	other := &db.Organization{Name: "other"}
+	if err := database.CreateOrganization(other, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	// Pods in the team-a namespaces of prod here; everything in the other organization
+	grants := []struct {
+		name        string
+		org         uint
+		permissions []db.Permission
+	}{
+		{"team-a", db.DefaultOrganizationID, []db.Permission{{Resource: "pods", Actions: []string{"read"}, Clusters: []string{"prod"}, Namespaces: []string{"team-a-*"}}}},
+		{"other-admins", other.ID, []db.Permission{{Resource: "*", Actions: []string{"*"}}}},
+	}
+	for _, g := range grants {
+		permissions, _ := json.Marshal(g.permissions)
+		group := &db.Group{Name: g.name, Permissions: db.JSON(permissions), OrganizationID: g.org}
+		if err := database.CreateGroup(group); err != nil {
+			t.Fatal(err)
+		}
+		if err := database.AddUserToGroup(user.ID, group.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	h := NewHandler(database, "test-secret", nil)
+
+	router := gin.New()
+	router.POST("/api-tokens", func(c *gin.Context) {
+		c.Set("user_id", int(user.ID))
+		c.Set("username", user.Username)
+		c.Set("email", user.Email)
+		c.Set("is_admin", false)
+		c.Set("org_id", uint(db.DefaultOrganizationID))
+	}, h.CreateAPIToken)
+
+	tests := []struct {
+		name       string
+		permission db.Permission
+		want       int
+	}{
+		{"within the grant", db.Permission{Resource: "pods", Actions: []string{"read"}, Clusters: []string{"prod"}, Namespaces: []string{"team-a-web"}}, http.StatusCreated},
+		{"narrower pattern", db.Permission{Resource: "pods", Actions: []string{"read"}, Clusters: []string{"prod"}, Namespaces: []string{"team-a-web-*"}}, http.StatusCreated},
+		{"granted only in another organization", db.Permission{Resource: "secrets", Actions: []string{"read"}, Clusters: []string{"prod"}, Namespaces: []string{"team-a-web"}}, http.StatusForbidden},
+		{"action not granted", db.Permission{Resource: "pods", Actions: []string{"delete"}, Clusters: []string{"prod"}, Namespaces: []string{"team-a-web"}}, http.StatusForbidden},
+		{"another cluster", db.Permission{Resource: "pods", Actions: []string{"read"}, Clusters: []string{"dev"}, Namespaces: []string{"team-a-web"}}, http.StatusForbidden},
+		{"all clusters", db.Permission{Resource: "pods", Actions: []string{"read"}, Clusters: []string{"*"}, Namespaces: []string{"team-a-web"}}, http.StatusForbidden},
+		{"another namespace", db.Permission{Resource: "pods", Actions: []string{"read"}, Clusters: []string{"prod"}, Namespaces: []string{"team-b"}}, http.StatusForbidden},
+		{"wider pattern", db.Permission{Resource: "pods", Actions: []string{"read"}, Clusters: []string{"prod"}, Namespaces: []string{"team-*"}}, http.StatusForbidden},
+		{"all namespaces", db.Permission{Resource: "pods", Actions: []string{"read"}, Clusters: []string{"prod"}}, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, _ := json.Marshal(map[string]interface{}{"name": tt.name, "permissions": []db.Permission{tt.permission}})
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api-tokens", strings.NewReader(string(body))))
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestPatternCovers(t *testing.T) {
+	tests := []struct {
+		owned, requested string
+		want             bool
+	}{
+		{"team-a", "team-a", true},
+		{"team-a-*", "team-a-web", true},
+		{"team-a-*", "team-a-*", true},
+		{"team-a-*", "team-a-web-?", true},
+		{"team-*", "team-a-*", true},
+		{"team-a-*", "team-*", false},
+		{"team-a-?", "team-a-*", false},
+		{"team-a", "team-b", false},
+		{"team-[ab]", "team-*", false},
+	}
+	for _, tt := range tests {
+		if got := patternCovers(tt.owned, tt.requested); got != tt.want {
+			t.Errorf("patternCovers(%q, %q) = %v, want %v", tt.owned, tt.requested, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/sonnguyen/kubelens/internal/audit"
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+// featureKeyPattern restricts flag keys to lowercase identifiers (e.g. "search_index")
+var featureKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_.-]{0,99}$`)
+
+// routeNotFound is the body gin answers unknown routes with
+const routeNotFound = "404 page not found"
+
+// FeatureGate is a middleware that hides an endpoint unless the feature flag is
+// enabled for the current user. Disabled features respond with the same 404 as
+// unknown routes so that dark-launched endpoints are not discoverable.
+func (h *Handler) FeatureGate(key string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := c.Get("user_id")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "not authenticated"})
+			c.Abort()
+			return
+		}
+
+		enabled, err := h.db.IsFeatureEnabledForUser(key, uint(userID.(int)))
+		if err != nil {
+			log.Errorf("Failed to evaluate feature flag %s: %v", key, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to evaluate feature flag"})
+			c.Abort()
+			return
+		}
+
+		if !enabled {
+			c.Data(http.StatusNotFound, gin.MIMEPlain, []byte(routeNotFound))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// GetEnabledFeatures returns the feature flags enabled for the current user
+// GET /api/v1/system/features
+func (h *Handler) GetEnabledFeatures(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "not authenticated"})
+		return
+	}
+
+	features, err := h.db.GetEnabledFeaturesForUser(uint(userID.(int)))
+	if err != nil {
+		log.Errorf("Failed to get enabled features: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get features"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"features": features})
+}
+
+// ListFeatureFlags returns all feature flags (admin only)
+func (h *Handler) ListFeatureFlags(c *gin.Context) {
+	flags, err := h.db.ListFeatureFlags()
+	if err != nil {
+		log.Errorf("Failed to list feature flags: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list feature flags"})
+		return
+	}
+
+	c.JSON(http.StatusOK, flags)
+}
+
+// UpsertFeatureFlag creates or updates a feature flag (admin only)
+// PUT /api/v1/system/feature-flags/:key
+func (h *Handler) UpsertFeatureFlag(c *gin.Context) {
+	key := c.Param("key")
+	if !featureKeyPattern.MatchString(key) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid feature key"})
+		return
+	}
+
+	var req struct {
+		Description string `json:"description"`
+		Enabled     bool   `json:"enabled"`
+		GroupIDs    []uint `json:"group_ids"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Validate targeted groups exist
+	for _, groupID := range req.GroupIDs {
+		if _, err := h.db.GetGroupByID(groupID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("group %d not found", groupID)})
+			return
+		}
+	}
+	if req.GroupIDs == nil {
+		req.GroupIDs = []uint{}
+	}
+	groupsJSON, _ := json.Marshal(req.GroupIDs)
+
+	flag := &db.FeatureFlag{
+		Key:         key,
+		Description: req.Description,
+		Enabled:     req.Enabled,
+		GroupIDs:    db.JSON(groupsJSON),
+	}
+	if err := h.db.UpsertFeatureFlag(flag); err != nil {
+		log.Errorf("Failed to save feature flag %s: %v", key, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save feature flag"})
+		return
+	}
+
+	log.Infof("Feature flag %s set: enabled=%v groups=%v", key, req.Enabled, req.GroupIDs)
+
+	// Audit log
+	if user, exists := c.Get("user"); exists {
+		if u, ok := user.(*db.User); ok {
+			audit.Log(c, audit.EventAuditConfigChanged, int(u.ID), u.Username, u.Email,
+				fmt.Sprintf("Feature flag updated: %s", key),
+				map[string]interface{}{
+					"feature":   key,
+					"enabled":   req.Enabled,
+					"group_ids": req.GroupIDs,
+				})
+		}
+	}
+
+	c.JSON(http.StatusOK, flag)
+}
+
+// DeleteFeatureFlag deletes a feature flag (admin only)
+func (h *Handler) DeleteFeatureFlag(c *gin.Context) {
+	key := c.Param("key")
+
+	if _, err := h.db.GetFeatureFlag(key); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.db.DeleteFeatureFlag(key); err != nil {
+		log.Errorf("Failed to delete feature flag %s: %v", key, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete feature flag"})
+		return
+	}
+
+	// Audit log
+	if user, exists := c.Get("user"); exists {
+		if u, ok := user.(*db.User); ok {
+			audit.Log(c, audit.EventAuditConfigChanged, int(u.ID), u.Username, u.Email,
+				fmt.Sprintf("Feature flag deleted: %s", key),
+				map[string]interface{}{
+					"feature": key,
+				})
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "feature flag deleted successfully"})
+}
@@ -38,7 +38,24 @@ func (h *Handler) GetPermissionOptions(c *gin.Context) {
 	c.JSON(http.StatusOK, options)
 }
 
-// ListGroups returns all groups (admin only)
+// organizationGroup loads the group of the :id parameter, responding 404 for the groups
+// of other organizations
+func (h *Handler) organizationGroup(c *gin.Context) (*db.Group, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid group ID"})
+		return nil, false
+	}
+
+	group, err := h.db.GetGroupByID(uint(id))
+	if err != nil || group.OrganizationID != organizationID(c) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "group not found"})
+		return nil, false
+	}
+	return group, true
+}
+
+// ListGroups returns the groups of the organization (admin only)
 func (h *Handler) ListGroups(c *gin.Context) {
 	groups, _, err := h.db.ListGroups(1, 1000) // Get all groups (up to 1000)
 	if err != nil {
@@ -47,7 +64,14 @@ func (h *Handler) ListGroups(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, groups)
+	orgGroups := groups[:0]
+	for _, group := range groups {
+		if group.OrganizationID == organizationID(c) {
+			orgGroups = append(orgGroups, group)
+		}
+	}
+
+	c.JSON(http.StatusOK, orgGroups)
 }
 
 // CreateGroup creates a new group (admin only)
@@ -72,10 +96,11 @@ func (h *Handler) CreateGroup(c *gin.Context) {
 	}
 
 	group := &db.Group{
-		Name:        req.Name,
-		Description: req.Description,
-		IsSystem:    false, // User-created groups are not system groups
-		Permissions: db.JSON(permissionsJSON),
+		OrganizationID: organizationID(c),
+		Name:           req.Name,
+		Description:    req.Description,
+		IsSystem:       false, // User-created groups are not system groups
+		Permissions:    db.JSON(permissionsJSON),
 	}
 
 	if err := h.db.CreateGroup(group); err != nil {
@@ -106,16 +131,8 @@ func (h *Handler) CreateGroup(c *gin.Context) {
 
 // GetGroup retrieves a group by ID (admin only)
 func (h *Handler) GetGroup(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := strconv.Atoi(idStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid group ID"})
-		return
-	}
-
-	group, err := h.db.GetGroupByID(uint(id))
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "group not found"})
+	group, ok := h.organizationGroup(c)
+	if !ok {
 		return
 	}
 
@@ -124,17 +141,9 @@ func (h *Handler) GetGroup(c *gin.Context) {
 
 // UpdateGroupHandler updates a group (admin only)
 func (h *Handler) UpdateGroupHandler(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := strconv.Atoi(idStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid group ID"})
-		return
-	}
-
 	// Check if group exists
-	group, err := h.db.GetGroupByID(uint(id))
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "group not found"})
+	group, ok := h.organizationGroup(c)
+	if !ok {
 		return
 	}
 
@@ -195,26 +204,19 @@ func (h *Handler) UpdateGroupHandler(c *gin.Context) {
 
 // DeleteGroup deletes a group (admin only)
 func (h *Handler) DeleteGroup(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := strconv.Atoi(idStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid group ID"})
-		return
-	}
-
 	// Check if group exists and is not a system group
-	group, err := h.db.GetGroupByID(uint(id))
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "group not found"})
+	group, ok := h.organizationGroup(c)
+	if !ok {
 		return
 	}
+	id := group.ID
 
 	if group.IsSystem {
 		c.JSON(http.StatusForbidden, gin.H{"error": "cannot delete system groups"})
 		return
 	}
 
-	if err := h.db.DeleteGroup(uint(id)); err != nil {
+	if err := h.db.DeleteGroup(id); err != nil {
 		log.Errorf("Failed to delete group: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete group"})
 		return
@@ -239,18 +241,12 @@ func (h *Handler) DeleteGroup(c *gin.Context) {
 
 // ListGroupUsers lists all users in a group (admin only)
 func (h *Handler) ListGroupUsers(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := strconv.Atoi(idStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid group ID"})
-		return
-	}
-
 	// Check if group exists
-	if _, err := h.db.GetGroupByID(uint(id)); err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "group not found"})
+	group, ok := h.organizationGroup(c)
+	if !ok {
 		return
 	}
+	id := group.ID
 
 	// Get all users
 	users, _, err := h.db.ListUsers(1, 10000) // Get all users
@@ -268,7 +264,7 @@ func (h *Handler) ListGroupUsers(c *gin.Context) {
 			continue
 		}
 		for _, g := range groups {
-			if g.ID == uint(id) {
+			if g.ID == id {
 				groupUsers = append(groupUsers, user)
 				break
 			}
@@ -283,12 +279,12 @@ func (h *Handler) ListGroupUsers(c *gin.Context) {
 
 // AddUserToGroupHandler adds a user to a group (admin only)
 func (h *Handler) AddUserToGroupHandler(c *gin.Context) {
-	groupIDStr := c.Param("id")
-	groupID, err := strconv.Atoi(groupIDStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid group ID"})
+	// Check if group exists
+	group, ok := h.organizationGroup(c)
+	if !ok {
 		return
 	}
+	groupID := group.ID
 
 	var req struct {
 		UserID int `json:"user_id" binding:"required"`
@@ -298,19 +294,25 @@ func (h *Handler) AddUserToGroupHandler(c *gin.Context) {
 		return
 	}
 
-	// Check if group exists
-	if _, err := h.db.GetGroupByID(uint(groupID)); err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "group not found"})
-		return
-	}
-
 	// Check if user exists
 	if _, err := h.db.GetUserByID(uint(req.UserID)); err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
 		return
 	}
 
-	if err := h.db.AddUserToGroup(uint(req.UserID), uint(groupID)); err != nil {
+	// Groups only hold members of their organization
+	role, err := h.db.GetOrganizationRole(group.OrganizationID, uint(req.UserID))
+	if err != nil {
+		log.Errorf("Failed to get organization role: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to add user to group"})
+		return
+	}
+	if role == "" {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "user is not a member of the organization"})
+		return
+	}
+
+	if err := h.db.AddUserToGroup(uint(req.UserID), groupID); err != nil {
 		log.Errorf("Failed to add user to group: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to add user to group"})
 		return
@@ -323,12 +325,11 @@ func (h *Handler) AddUserToGroupHandler(c *gin.Context) {
 
 // RemoveUserFromGroupHandler removes a user from a group (admin only)
 func (h *Handler) RemoveUserFromGroupHandler(c *gin.Context) {
-	groupIDStr := c.Param("id")
-	groupID, err := strconv.Atoi(groupIDStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid group ID"})
+	group, ok := h.organizationGroup(c)
+	if !ok {
 		return
 	}
+	groupID := group.ID
 
 	userIDStr := c.Param("user_id")
 	userID, err := strconv.Atoi(userIDStr)
@@ -337,7 +338,7 @@ func (h *Handler) RemoveUserFromGroupHandler(c *gin.Context) {
 		return
 	}
 
-	if err := h.db.RemoveUserFromGroup(uint(userID), uint(groupID)); err != nil {
+	if err := h.db.RemoveUserFromGroup(uint(userID), groupID); err != nil {
 		log.Errorf("Failed to remove user from group: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to remove user from group"})
 		return
@@ -3,6 +3,7 @@ package auth
 import (
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -18,6 +19,10 @@ type Handler struct {
 	secret        string
 	accountLockout *middleware.AccountLockout
 	auditLogger   *audit.Logger
+	publicURL     string
+	// Lifetimes of the access JWTs and refresh tokens issued at sign-in
+	accessTokenTTL  time.Duration
+	refreshTokenTTL time.Duration
 }
 
 // NewHandler creates a new auth handler
@@ -26,17 +31,24 @@ func NewHandler(database *db.DB, secret string, auditLogger *audit.Logger) *Hand
 		db:     database,
 		secret: secret,
 		// 5 failed attempts, 15 minute lockout, 5 minute attempt window
-		accountLockout: middleware.NewAccountLockout(5, 15*time.Minute, 5*time.Minute),
-		auditLogger:    auditLogger,
+		accountLockout:  middleware.NewAccountLockout(5, 15*time.Minute, 5*time.Minute),
+		auditLogger:     auditLogger,
+		accessTokenTTL:  defaultAccessTokenTTL,
+		refreshTokenTTL: defaultRefreshTokenTTL,
 	}
 }
 
+// SetPublicURL sets the public base URL used to build links sent to users (e.g., invitations)
+func (h *Handler) SetPublicURL(publicURL string) {
+	h.publicURL = strings.TrimRight(publicURL, "/")
+}
+
 // Signup handles user registration
 func (h *Handler) Signup(c *gin.Context) {
 	var req struct {
 		Email    string `json:"email" binding:"required,email"`
 		Username string `json:"username" binding:"required,min=3"`
-		Password string `json:"password" binding:"required,min=8"`
+		Password string `json:"password" binding:"required"`
 		FullName string `json:"full_name"`
 	}
 
@@ -56,9 +68,10 @@ func (h *Handler) Signup(c *gin.Context) {
 	req.Username = middleware.SanitizeString(req.Username)
 	req.FullName = middleware.SanitizeString(req.FullName)
 
-	// Validate password strength
-	if valid, msg := middleware.ValidatePassword(req.Password); !valid {
-		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+	// Validate password against the password policy
+	policy := h.loadPasswordPolicy()
+	if err := h.checkNewPassword(policy, req.Password, &db.User{Email: req.Email, Username: req.Username}); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
@@ -78,33 +91,32 @@ func (h *Handler) Signup(c *gin.Context) {
 		}
 	}
 
-	// Hash password
-	passwordHash, err := HashPassword(req.Password)
-	if err != nil {
-		log.Errorf("Failed to hash password: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create user"})
-		return
-	}
-
 	// Create user
 	user := &db.User{
 		Email:        req.Email,
 		Username:     req.Username,
-		PasswordHash: passwordHash,
 		FullName:     req.FullName,
 		AuthProvider: "local",
 		IsActive:     true,
 		IsAdmin:      false, // New users are not admins by default
 	}
 
+	// Hash password
+	if err := setPassword(user, req.Password); err != nil {
+		log.Errorf("Failed to hash password: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create user"})
+		return
+	}
+
 	if err := h.db.CreateUser(user); err != nil {
 		log.Errorf("Failed to create user: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create user"})
 		return
 	}
+	h.recordPassword(policy, user)
 
-	// Generate token
-	token, err := GenerateToken(int(user.ID), user.Email, user.Username, user.IsAdmin, h.secret)
+	// Generate tokens
+	tokens, err := h.issueTokens(c, user)
 	if err != nil {
 		log.Errorf("Failed to generate token: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate token"})
@@ -114,7 +126,9 @@ func (h *Handler) Signup(c *gin.Context) {
 	log.Infof("New user registered: %s (%s)", user.Email, user.Username)
 
 	c.JSON(http.StatusCreated, gin.H{
-		"token": token,
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
 		"user": gin.H{
 			"id":            user.ID,
 			"email":         user.Email,
@@ -139,8 +153,11 @@ func (h *Handler) Signin(c *gin.Context) {
 		return
 	}
 
+	// With LDAP enabled, directory users may sign in with their username instead of an email
+	ldapConfig := h.enabledLDAPConfig()
+
 	// Validate email format
-	if !middleware.ValidateEmail(req.Email) {
+	if ldapConfig == nil && !middleware.ValidateEmail(req.Email) {
 		log.Warnf("Invalid email format attempt from IP: %s", c.ClientIP())
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid email format"})
 		return
@@ -175,57 +192,74 @@ func (h *Handler) Signin(c *gin.Context) {
 
 	// Get user
 	user, err := h.db.GetUserByEmail(req.Email)
-	if err != nil {
-		// Record failed attempt even if user doesn't exist (prevent user enumeration timing attacks)
-		h.accountLockout.RecordFailedAttempt(lockIdentifier)
-		log.Warnf("Failed login attempt for non-existent user: %s from IP: %s", req.Email, c.ClientIP())
+	if ldapConfig != nil && (err != nil || user.AuthProvider == AuthProviderLDAP) {
+		user, err = h.ldapSignin(c, ldapConfig, req.Email, req.Password, lockIdentifier)
+		if err != nil {
+			return
+		}
+	} else {
+		if err != nil {
+			// Record failed attempt even if user doesn't exist (prevent user enumeration timing attacks)
+			h.accountLockout.RecordFailedAttempt(lockIdentifier)
+			log.Warnf("Failed login attempt for non-existent user: %s from IP: %s", req.Email, c.ClientIP())
 		
-		// Audit log: Login failed (user not found)
-		h.auditLogger.LogAuth(
-			audit.EventAuthLoginFailed,
-			nil,
-			"",
-			req.Email,
-			c.ClientIP(),
-			"Login failed: user not found",
-			false,
-		)
+			// Audit log: Login failed (user not found)
+			h.auditLogger.LogAuth(
+				audit.EventAuthLoginFailed,
+				nil,
+				"",
+				req.Email,
+				c.ClientIP(),
+				"Login failed: user not found",
+				false,
+			)
 		
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"})
-		return
-	}
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"})
+			return
+		}
 
-	// Check if user is local auth
-	if user.AuthProvider != "local" {
-		log.Warnf("Login attempt with wrong auth provider for user: %s from IP: %s", req.Email, c.ClientIP())
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "this account uses " + user.AuthProvider + " authentication",
-		})
-		return
-	}
+		// Check if user is local auth
+		if user.AuthProvider != "local" {
+			log.Warnf("Login attempt with wrong auth provider for user: %s from IP: %s", req.Email, c.ClientIP())
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "this account uses " + user.AuthProvider + " authentication",
+			})
+			return
+		}
 
-	// Check password
-	if !CheckPassword(req.Password, user.PasswordHash) {
-		// Record failed attempt
-		h.accountLockout.RecordFailedAttempt(lockIdentifier)
-		attemptCount := h.accountLockout.GetAttemptCount(lockIdentifier)
-		log.Warnf("Failed login attempt for user: %s from IP: %s (attempt %d/5)", 
-			req.Email, c.ClientIP(), attemptCount)
+		// Check password
+		if !CheckPassword(req.Password, user.PasswordHash) {
+			// Record failed attempt
+			h.accountLockout.RecordFailedAttempt(lockIdentifier)
+			attemptCount := h.accountLockout.GetAttemptCount(lockIdentifier)
+			log.Warnf("Failed login attempt for user: %s from IP: %s (attempt %d/5)", 
+				req.Email, c.ClientIP(), attemptCount)
 		
-		// Audit log: Login failed (wrong password)
-		userIDInt := int(user.ID)
-		h.auditLogger.LogAuth(
-			audit.EventAuthLoginFailed,
-			&userIDInt,
-			user.Username,
-			user.Email,
-			c.ClientIP(),
-			"Login failed: invalid password",
-			false,
-		)
+			// Audit log: Login failed (wrong password)
+			userIDInt := int(user.ID)
+			h.auditLogger.LogAuth(
+				audit.EventAuthLoginFailed,
+				&userIDInt,
+				user.Username,
+				user.Email,
+				c.ClientIP(),
+				"Login failed: invalid password",
+				false,
+			)
 		
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"})
-		return
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"})
+			return
+		}
+
+		// Check password age; the password is changed via /auth/password/expired
+		if policy := h.loadPasswordPolicy(); user.IsActive && policy.Expired(user) {
+			h.accountLockout.ResetAttempts(lockIdentifier)
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":            errPasswordExpired.Error(),
+				"password_expired": true,
+			})
+			return
+		}
 	}
 
 	// Check if active
@@ -267,7 +301,7 @@ func (h *Handler) Signin(c *gin.Context) {
 	} else if user.MFAEnforcedAt == nil || user.MFAEnforcedAt.IsZero() {
 		// MFA not set up yet - require setup on first login
 		// Generate a temporary token for MFA setup
-		tempToken, err := GenerateToken(int(user.ID), user.Email, user.Username, user.IsAdmin, h.secret)
+		tempToken, err := GenerateMFASetupToken(int(user.ID), user.Email, user.Username, user.IsAdmin, h.secret)
 		if err != nil {
 			log.Errorf("Failed to generate temporary token: %v", err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate token"})
@@ -290,8 +324,8 @@ func (h *Handler) Signin(c *gin.Context) {
 		log.Warnf("Failed to update last login for user %d: %v", user.ID, err)
 	}
 
-	// Generate token
-	token, err := GenerateToken(int(user.ID), user.Email, user.Username, user.IsAdmin, h.secret)
+	// Generate tokens
+	tokens, err := h.issueTokens(c, user)
 	if err != nil {
 		log.Errorf("Failed to generate token: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate token"})
@@ -320,7 +354,9 @@ func (h *Handler) Signin(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"token": token,
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
 		"user": gin.H{
 			"id":            user.ID,
 			"email":         user.Email,
@@ -349,17 +385,25 @@ func (h *Handler) GetCurrentUser(c *gin.Context) {
 		return
 	}
 
+	// Saved searches come with the user so the UI can offer them right after login
+	savedSearches, err := h.db.ListSavedSearches(user.ID, c.GetUint("org_id"))
+	if err != nil {
+		log.Warnf("Failed to list the saved searches of user %d: %v", user.ID, err)
+		savedSearches = []*db.SavedSearch{}
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"id":            user.ID,
-		"email":         user.Email,
-		"username":      user.Username,
-		"full_name":     user.FullName,
-		"avatar_url":    user.AvatarURL,
-		"auth_provider": user.AuthProvider,
-		"is_admin":      user.IsAdmin,
-		"mfa_enabled":   user.MFAEnabled,
-		"last_login":    user.LastLogin,
-		"created_at":    user.CreatedAt,
+		"id":             user.ID,
+		"email":          user.Email,
+		"username":       user.Username,
+		"full_name":      user.FullName,
+		"avatar_url":     user.AvatarURL,
+		"auth_provider":  user.AuthProvider,
+		"is_admin":       user.IsAdmin,
+		"mfa_enabled":    user.MFAEnabled,
+		"last_login":     user.LastLogin,
+		"created_at":     user.CreatedAt,
+		"saved_searches": savedSearches,
 	})
 }
 
@@ -373,7 +417,7 @@ func (h *Handler) ChangePassword(c *gin.Context) {
 
 	var req struct {
 		CurrentPassword string `json:"current_password" binding:"required"`
-		NewPassword     string `json:"new_password" binding:"required,min=8"`
+		NewPassword     string `json:"new_password" binding:"required"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -393,24 +437,35 @@ func (h *Handler) ChangePassword(c *gin.Context) {
 		return
 	}
 
+	// Validate new password against the password policy
+	policy := h.loadPasswordPolicy()
+	if err := h.checkNewPassword(policy, req.NewPassword, user); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	// Hash new password
-	newPasswordHash, err := HashPassword(req.NewPassword)
-	if err != nil {
+	if err := setPassword(user, req.NewPassword); err != nil {
 		log.Errorf("Failed to hash new password: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update password"})
 		return
 	}
 
 	// Update password
-	user.PasswordHash = newPasswordHash
 	if err := h.db.UpdateUser(user); err != nil {
 		log.Errorf("Failed to update user password: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update password"})
 		return
 	}
+	h.recordPassword(policy, user)
 
 	log.Infof("User %s changed password", user.Email)
 
+	// Sign out other devices, keeping the session the password was changed from
+	if err := h.db.RevokeUserSessions(user.ID, c.GetUint("session_id")); err != nil {
+		log.Warnf("Failed to revoke sessions of user %d: %v", user.ID, err)
+	}
+
 	c.JSON(http.StatusOK, gin.H{"message": "password updated successfully"})
 }
 
@@ -475,21 +530,50 @@ func (h *Handler) UpdateProfile(c *gin.Context) {
 	})
 }
 
-// Logout handles user logout
+// Logout handles user logout by revoking the current session and its refresh
+// tokens (or all of the user's sessions with "all": true). A refresh_token in
+// the body ends its session too, for tokens issued without one.
 func (h *Handler) Logout(c *gin.Context) {
 	// Get user info from context (set by AuthMiddleware)
-	userID, exists := c.Get("userID")
+	userID, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "not authenticated"})
 		return
 	}
 
+	var req struct {
+		RefreshToken string `json:"refresh_token"`
+		All          bool   `json:"all"`
+	}
+	// The body is optional
+	_ = c.ShouldBindJSON(&req)
+
+	var err error
+	if req.All {
+		err = h.db.RevokeUserSessions(uint(userID.(int)), 0)
+	} else {
+		if sessionID := c.GetUint("session_id"); sessionID != 0 {
+			err = h.db.RevokeSession(sessionID)
+		}
+		if err == nil && req.RefreshToken != "" {
+			err = h.revokeRefreshTokenOf(uint(userID.(int)), req.RefreshToken)
+		}
+	}
+	if err != nil {
+		log.Errorf("Failed to revoke refresh tokens on logout: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to log out"})
+		return
+	}
+
 	email, _ := c.Get("email")
-	
+	username, _ := c.Get("username")
+
 	log.Infof("User %s (ID: %d) logged out", email, userID)
 
-	// In a JWT-based system, logout is primarily handled client-side by removing the token
-	// However, we can log the event and potentially invalidate refresh tokens if implemented
+	uid := userID.(int)
+	h.auditLogger.LogAuth(audit.EventAuthLogout, &uid, fmt.Sprint(username), fmt.Sprint(email), c.ClientIP(),
+		"User logged out", true)
+
 	c.JSON(http.StatusOK, gin.H{"message": "logged out successfully"})
 }
 
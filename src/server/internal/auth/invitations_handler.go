@@ -0,0 +1,316 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/sonnguyen/kubelens/internal/audit"
+	"github.com/sonnguyen/kubelens/internal/db"
+	"github.com/sonnguyen/kubelens/internal/mail"
+	"github.com/sonnguyen/kubelens/internal/middleware"
+)
+
+const (
+	// defaultInvitationTTL is used when the admin does not specify an expiry
+	defaultInvitationTTL = 72 * time.Hour
+	// maxInvitationTTL caps how long an invitation link stays valid
+	maxInvitationTTL = 30 * 24 * time.Hour
+)
+
+// generateInvitationToken returns a random URL-safe token and its SHA-256 hash.
+// Only the hash is persisted, so a database leak does not expose usable links.
+func generateInvitationToken() (string, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	token := hex.EncodeToString(b)
+	return token, hashInvitationToken(token), nil
+}
+
+// hashInvitationToken hashes an invitation token for storage and lookup
+func hashInvitationToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateInvitation creates a one-time invitation link for a new user (admin only)
+func (h *Handler) CreateInvitation(c *gin.Context) {
+	var req struct {
+		Email          string `json:"email" binding:"required,email"`
+		GroupIDs       []int  `json:"group_ids" binding:"required,min=1"`
+		IsAdmin        bool   `json:"is_admin"`
+		ExpiresInHours int    `json:"expires_in_hours"`
+		SendEmail      bool   `json:"send_email"` // Email the link to the invitee
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	req.Email = middleware.SanitizeString(req.Email)
+
+	// Check if user already exists
+	if existingUser, _ := h.db.GetUserByEmail(req.Email); existingUser != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "email already registered"})
+		return
+	}
+
+	// Validate all groups exist
+	groupIDs := make([]uint, 0, len(req.GroupIDs))
+	for _, groupID := range req.GroupIDs {
+		if _, err := h.db.GetGroupByID(uint(groupID)); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("group %d not found", groupID)})
+			return
+		}
+		groupIDs = append(groupIDs, uint(groupID))
+	}
+
+	ttl := defaultInvitationTTL
+	if req.ExpiresInHours > 0 {
+		ttl = time.Duration(req.ExpiresInHours) * time.Hour
+	}
+	if ttl > maxInvitationTTL {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("expiry cannot exceed %d hours", int(maxInvitationTTL.Hours()))})
+		return
+	}
+
+	token, tokenHash, err := generateInvitationToken()
+	if err != nil {
+		log.Errorf("Failed to generate invitation token: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create invitation"})
+		return
+	}
+
+	groupsJSON, _ := json.Marshal(groupIDs)
+	invitation := &db.Invitation{
+		Email:     req.Email,
+		TokenHash: tokenHash,
+		GroupIDs:  db.JSON(groupsJSON),
+		IsAdmin:   req.IsAdmin,
+		ExpiresAt: time.Now().Add(ttl),
+	}
+	if userID, exists := c.Get("user_id"); exists {
+		invitation.InvitedBy = uint(userID.(int))
+	}
+
+	if err := h.db.CreateInvitation(invitation); err != nil {
+		log.Errorf("Failed to create invitation: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create invitation"})
+		return
+	}
+
+	log.Infof("Invitation created for %s (expires %s)", invitation.Email, invitation.ExpiresAt.Format(time.RFC3339))
+
+	// Audit log
+	if adminUser, exists := c.Get("user"); exists {
+		if admin, ok := adminUser.(*db.User); ok {
+			audit.Log(c, audit.EventAuditInvitationCreated, int(admin.ID), admin.Username, admin.Email,
+				fmt.Sprintf("Invited user: %s", invitation.Email),
+				map[string]interface{}{
+					"invitation_id": invitation.ID,
+					"target_email":  invitation.Email,
+					"group_ids":     groupIDs,
+					"is_admin":      invitation.IsAdmin,
+					"expires_at":    invitation.ExpiresAt,
+				})
+		}
+	}
+
+	link := fmt.Sprintf("%s/accept-invite?token=%s", h.publicURL, token)
+	resp := gin.H{
+		"invitation": invitation,
+		"token":      token,
+		"link":       link,
+	}
+
+	// A failed email does not fail the invitation; the admin can still share the link
+	if req.SendEmail {
+		err := mail.SendMessage(h.db, &mail.Message{
+			To:      []string{invitation.Email},
+			Subject: "You are invited to kubelens",
+			Body: fmt.Sprintf("Hello,\n\nYou have been invited to kubelens. Create your account here:\n\n%s\n\n"+
+				"The invitation expires on %s.\n", link, invitation.ExpiresAt.UTC().Format(time.RFC1123)),
+		})
+		resp["email_sent"] = err == nil
+		if err != nil {
+			log.Warnf("Failed to email invitation %d: %v", invitation.ID, err)
+			resp["email_error"] = err.Error()
+		}
+	}
+
+	// The token is only returned once; it cannot be recovered later
+	c.JSON(http.StatusCreated, resp)
+}
+
+// ListInvitations returns all pending invitations (admin only)
+func (h *Handler) ListInvitations(c *gin.Context) {
+	invitations, err := h.db.ListPendingInvitations()
+	if err != nil {
+		log.Errorf("Failed to list invitations: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list invitations"})
+		return
+	}
+
+	c.JSON(http.StatusOK, invitations)
+}
+
+// RevokeInvitation revokes a pending invitation (admin only)
+func (h *Handler) RevokeInvitation(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid invitation ID"})
+		return
+	}
+
+	invitation, err := h.db.GetInvitationByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "invitation not found"})
+		return
+	}
+
+	if err := h.db.RevokeInvitation(invitation.ID); err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+
+	log.Infof("Invitation %d for %s revoked", invitation.ID, invitation.Email)
+
+	// Audit log
+	if adminUser, exists := c.Get("user"); exists {
+		if admin, ok := adminUser.(*db.User); ok {
+			audit.Log(c, audit.EventAuditInvitationRevoked, int(admin.ID), admin.Username, admin.Email,
+				fmt.Sprintf("Revoked invitation for: %s", invitation.Email),
+				map[string]interface{}{
+					"invitation_id": invitation.ID,
+					"target_email":  invitation.Email,
+				})
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "invitation revoked successfully"})
+}
+
+// GetInvitation validates an invitation token and returns the invited email (public)
+func (h *Handler) GetInvitation(c *gin.Context) {
+	invitation, err := h.db.GetPendingInvitationByTokenHash(hashInvitationToken(c.Param("token")))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "invitation is invalid or has expired"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"email":      invitation.Email,
+		"expires_at": invitation.ExpiresAt,
+	})
+}
+
+// AcceptInvitation redeems an invitation token, creating the user with the chosen
+// username and password. The response carries a temporary token so the client can
+// proceed straight to MFA enrollment.
+func (h *Handler) AcceptInvitation(c *gin.Context) {
+	var req struct {
+		Username string `json:"username" binding:"required,min=3"`
+		Password string `json:"password" binding:"required"`
+		FullName string `json:"full_name"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	invitation, err := h.db.GetPendingInvitationByTokenHash(hashInvitationToken(c.Param("token")))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "invitation is invalid or has expired"})
+		return
+	}
+
+	req.Username = middleware.SanitizeString(req.Username)
+	req.FullName = middleware.SanitizeString(req.FullName)
+
+	// Validate password against the password policy
+	policy := h.loadPasswordPolicy()
+	if err := h.checkNewPassword(policy, req.Password, &db.User{Email: invitation.Email, Username: req.Username}); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if existingUser, _ := h.db.GetUserByEmail(invitation.Email); existingUser != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "email already registered"})
+		return
+	}
+	if exists, _ := h.db.UserExists(req.Username); exists {
+		c.JSON(http.StatusConflict, gin.H{"error": "username already taken"})
+		return
+	}
+
+	user := &db.User{
+		Email:        invitation.Email,
+		Username:     req.Username,
+		FullName:     req.FullName,
+		AuthProvider: "local",
+		IsActive:     true,
+		IsAdmin:      invitation.IsAdmin,
+	}
+	if err := setPassword(user, req.Password); err != nil {
+		log.Errorf("Failed to hash password: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to accept invitation"})
+		return
+	}
+
+	var groupIDs []uint
+	if len(invitation.GroupIDs) > 0 {
+		if err := json.Unmarshal([]byte(invitation.GroupIDs), &groupIDs); err != nil {
+			log.Warnf("Failed to parse group IDs for invitation %d: %v", invitation.ID, err)
+		}
+	}
+
+	// The invitation is claimed with the user created, so concurrent redemptions fail and
+	// a failure leaves the link usable
+	if err := h.db.AcceptInvitation(invitation.ID, user, groupIDs); err != nil {
+		if errors.Is(err, db.ErrInvitationUsed) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		log.Errorf("Failed to create user from invitation %d: %v", invitation.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create user"})
+		return
+	}
+	h.recordPassword(policy, user)
+
+	log.Infof("Invitation %d accepted: %s (%s)", invitation.ID, user.Email, user.Username)
+
+	audit.Log(c, audit.EventAuditInvitationAccepted, int(user.ID), user.Username, user.Email,
+		fmt.Sprintf("Accepted invitation: %s", user.Email),
+		map[string]interface{}{
+			"invitation_id": invitation.ID,
+			"invited_by":    invitation.InvitedBy,
+		})
+
+	// New users must enroll MFA before a full session is issued (same as first login)
+	tempToken, err := GenerateMFASetupToken(int(user.ID), user.Email, user.Username, user.IsAdmin, h.secret)
+	if err != nil {
+		log.Errorf("Failed to generate temporary token: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate token"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":            "invitation accepted",
+		"mfa_setup_required": true,
+		"temp_token":         tempToken,
+		"user":               user,
+	})
+}
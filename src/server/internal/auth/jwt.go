@@ -16,21 +16,59 @@ type Claims struct {
 	Email    string `json:"email"`
 	Username string `json:"username"`
 	IsAdmin  bool   `json:"is_admin"`
+	// SessionID is the login session the token was issued for (0 for tokens without one)
+	SessionID uint `json:"sid,omitempty"`
+	// OrgID is the organization the token works in (0 for the default organization)
+	OrgID uint `json:"org,omitempty"`
+	// Purpose limits a token without a session to one step of signing in, e.g.
+	// TokenPurposeMFASetup ("" for session tokens)
+	Purpose string `json:"purpose,omitempty"`
 	jwt.RegisteredClaims
 }
 
-// GenerateToken generates a JWT token for a user
+// TokenPurposeMFASetup is the purpose of the temporary token of a user who must enroll
+// MFA before signing in: only the MFA enrollment routes accept it (see MFASetupMiddleware)
+const TokenPurposeMFASetup = "mfa_setup"
+
+// mfaSetupTokenTTL is how long a user has to enroll MFA with a temporary token
+const mfaSetupTokenTTL = 15 * time.Minute
+
+// GenerateToken generates a JWT token for a user, valid for 24 hours
 func GenerateToken(userID int, email, username string, isAdmin bool, secret string) (string, error) {
-	claims := Claims{
+	return GenerateSessionToken(userID, email, username, isAdmin, secret, 24*time.Hour, 0, 0)
+}
+
+// GenerateSessionToken generates a JWT token for a login session of a user, working in an
+// organization, that expires after ttl
+func GenerateSessionToken(userID int, email, username string, isAdmin bool, secret string, ttl time.Duration, sessionID, orgID uint) (string, error) {
+	return signToken(Claims{
+		UserID:    userID,
+		Email:     email,
+		Username:  username,
+		IsAdmin:   isAdmin,
+		SessionID: sessionID,
+		OrgID:     orgID,
+	}, ttl, secret)
+}
+
+// GenerateMFASetupToken generates the temporary token a user enrolls MFA with before their
+// first sign-in
+func GenerateMFASetupToken(userID int, email, username string, isAdmin bool, secret string) (string, error) {
+	return signToken(Claims{
 		UserID:   userID,
 		Email:    email,
 		Username: username,
 		IsAdmin:  isAdmin,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			Issuer:    "kubelens",
-		},
+		Purpose:  TokenPurposeMFASetup,
+	}, mfaSetupTokenTTL, secret)
+}
+
+// signToken signs claims issued now that expire after ttl
+func signToken(claims Claims, ttl time.Duration, secret string) (string, error) {
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+		IssuedAt:  jwt.NewNumericDate(time.Now()),
+		Issuer:    "kubelens",
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -0,0 +1,399 @@
+package auth
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-ldap/ldap/v3"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/sonnguyen/kubelens/internal/audit"
+	"github.com/sonnguyen/kubelens/internal/crypto"
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+const (
+	// ldapConfigKey is the system config key the LDAP settings are stored under
+	ldapConfigKey = "ldap_config"
+	// ldapTimeout bounds connecting to and each request against the directory
+	ldapTimeout = 10 * time.Second
+	// AuthProviderLDAP is the auth provider of users signed in through LDAP
+	AuthProviderLDAP = "ldap"
+)
+
+// errLDAPInvalidCredentials is returned when the directory rejects the user or password
+var errLDAPInvalidCredentials = errors.New("invalid credentials")
+
+// LDAPConfig configures sign-in against an LDAP or Active Directory server
+type LDAPConfig struct {
+	Enabled            bool   `json:"enabled"`
+	URL                string `json:"url"` // ldap://host:389 or ldaps://host:636
+	StartTLS           bool   `json:"start_tls"`
+	InsecureSkipVerify bool   `json:"insecure_skip_verify"`
+
+	// Service account used to look users up; empty for an anonymous search
+	BindDN       string `json:"bind_dn"`
+	BindPassword string `json:"bind_password,omitempty"`
+
+	// User lookup; {username} in the filter is replaced with the escaped login name
+	SearchBase        string `json:"search_base"`
+	UserFilter        string `json:"user_filter"`
+	UsernameAttribute string `json:"username_attribute"`
+	EmailAttribute    string `json:"email_attribute"`
+	NameAttribute     string `json:"name_attribute"`
+
+	// Groups are read from GroupAttribute of the user entry (e.g. memberOf), or searched
+	// under GroupSearchBase with GroupFilter ({dn} and {username} are replaced) when set
+	GroupAttribute     string `json:"group_attribute"`
+	GroupSearchBase    string `json:"group_search_base,omitempty"`
+	GroupFilter        string `json:"group_filter,omitempty"`
+	GroupNameAttribute string `json:"group_name_attribute"`
+
+	// Mapping of directory groups to kubelens groups, applied on every login
+	GroupMapping    map[string]string `json:"group_mapping,omitempty"`
+	DefaultGroup    string            `json:"default_group,omitempty"`
+	AutoCreateGroup bool              `json:"auto_create_group"`
+}
+
+// applyDefaults fills in the attribute names of a typical OpenLDAP or AD schema
+func (cfg *LDAPConfig) applyDefaults() {
+	if cfg.UserFilter == "" {
+		cfg.UserFilter = "(|(uid={username})(sAMAccountName={username})(mail={username}))"
+	}
+	if cfg.UsernameAttribute == "" {
+		cfg.UsernameAttribute = "uid"
+	}
+	if cfg.EmailAttribute == "" {
+		cfg.EmailAttribute = "mail"
+	}
+	if cfg.NameAttribute == "" {
+		cfg.NameAttribute = "cn"
+	}
+	if cfg.GroupAttribute == "" {
+		cfg.GroupAttribute = "memberOf"
+	}
+	if cfg.GroupNameAttribute == "" {
+		cfg.GroupNameAttribute = "cn"
+	}
+	if cfg.GroupSearchBase != "" && cfg.GroupFilter == "" {
+		cfg.GroupFilter = "(|(member={dn})(uniqueMember={dn})(memberUid={username}))"
+	}
+}
+
+// groupConfig returns the group mapping settings in the form shared with OIDC
+func (cfg *LDAPConfig) groupConfig() OIDCConfig {
+	return OIDCConfig{
+		GroupMapping:    cfg.GroupMapping,
+		DefaultGroup:    cfg.DefaultGroup,
+		AutoCreateGroup: cfg.AutoCreateGroup,
+	}
+}
+
+// Validate checks the settings needed to reach the directory
+func (cfg *LDAPConfig) Validate() error {
+	u, err := url.Parse(cfg.URL)
+	if err != nil || (u.Scheme != "ldap" && u.Scheme != "ldaps") || u.Host == "" {
+		return fmt.Errorf("url must be ldap://host[:port] or ldaps://host[:port]")
+	}
+	if cfg.StartTLS && u.Scheme == "ldaps" {
+		return fmt.Errorf("start_tls cannot be used with ldaps://")
+	}
+	if cfg.SearchBase == "" {
+		return fmt.Errorf("search_base is required")
+	}
+	if !strings.Contains(cfg.UserFilter, "{username}") {
+		return fmt.Errorf("user_filter must contain {username}")
+	}
+	if cfg.BindDN != "" && cfg.BindPassword == "" {
+		return fmt.Errorf("bind_password is required with bind_dn")
+	}
+	return nil
+}
+
+// ldapEntry is a user as found in the directory
+type ldapEntry struct {
+	DN       string   `json:"dn"`
+	Username string   `json:"username"`
+	Email    string   `json:"email"`
+	Name     string   `json:"name"`
+	Groups   []string `json:"groups"`
+}
+
+// ldapEncryptor returns the encryptor for secrets stored in the database
+func (h *Handler) ldapEncryptor() (*crypto.Encryptor, error) {
+	key, err := h.db.GetOrCreateEncryptionKey()
+	if err != nil {
+		return nil, err
+	}
+	return crypto.NewEncryptor(key)
+}
+
+// loadLDAPConfig returns the stored LDAP settings with the bind password decrypted,
+// or nil when LDAP was never configured
+func (h *Handler) loadLDAPConfig() (*LDAPConfig, error) {
+	value, err := h.db.GetSystemConfig(ldapConfigKey)
+	if err != nil || value == "" {
+		return nil, nil
+	}
+
+	var cfg LDAPConfig
+	if err := json.Unmarshal([]byte(value), &cfg); err != nil {
+		return nil, fmt.Errorf("invalid stored LDAP config: %w", err)
+	}
+	if cfg.BindPassword != "" {
+		encryptor, err := h.ldapEncryptor()
+		if err != nil {
+			return nil, err
+		}
+		password, err := encryptor.Decrypt(cfg.BindPassword)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt LDAP bind password: %w", err)
+		}
+		cfg.BindPassword = string(password)
+	}
+	cfg.applyDefaults()
+	return &cfg, nil
+}
+
+// saveLDAPConfig stores the LDAP settings, encrypting the bind password
+func (h *Handler) saveLDAPConfig(cfg LDAPConfig) error {
+	if cfg.BindPassword != "" {
+		encryptor, err := h.ldapEncryptor()
+		if err != nil {
+			return err
+		}
+		if cfg.BindPassword, err = encryptor.Encrypt([]byte(cfg.BindPassword)); err != nil {
+			return err
+		}
+	}
+	value, err := json.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+	return h.db.SetSystemConfig(ldapConfigKey, string(value))
+}
+
+// enabledLDAPConfig returns the LDAP settings when LDAP sign-in is enabled
+func (h *Handler) enabledLDAPConfig() *LDAPConfig {
+	cfg, err := h.loadLDAPConfig()
+	if err != nil {
+		log.Errorf("Failed to load LDAP config: %v", err)
+		return nil
+	}
+	if cfg == nil || !cfg.Enabled {
+		return nil
+	}
+	return cfg
+}
+
+// dialLDAP connects to the directory, upgrading the connection with StartTLS if configured
+func dialLDAP(cfg *LDAPConfig) (*ldap.Conn, error) {
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig := &tls.Config{
+		ServerName:         u.Hostname(),
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+
+	conn, err := ldap.DialURL(cfg.URL,
+		ldap.DialWithDialer(&net.Dialer{Timeout: ldapTimeout}),
+		ldap.DialWithTLSConfig(tlsConfig))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to LDAP server: %w", err)
+	}
+	conn.SetTimeout(ldapTimeout)
+
+	if cfg.StartTLS {
+		if err := conn.StartTLS(tlsConfig); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to start TLS: %w", err)
+		}
+	}
+	return conn, nil
+}
+
+// bindService binds with the service account, or stays anonymous without one
+func bindService(conn *ldap.Conn, cfg *LDAPConfig) error {
+	if cfg.BindDN == "" {
+		return nil
+	}
+	if err := conn.Bind(cfg.BindDN, cfg.BindPassword); err != nil {
+		return fmt.Errorf("failed to bind as %s: %w", cfg.BindDN, err)
+	}
+	return nil
+}
+
+// authenticateLDAP looks a user up with the service account, verifies the password by
+// binding as the user and reads the user's groups
+func authenticateLDAP(cfg *LDAPConfig, username, password string) (*ldapEntry, error) {
+	// An empty password would be an unauthenticated bind, which servers accept
+	if username == "" || password == "" {
+		return nil, errLDAPInvalidCredentials
+	}
+
+	conn, err := dialLDAP(cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err := bindService(conn, cfg); err != nil {
+		return nil, err
+	}
+
+	attributes := []string{cfg.UsernameAttribute, cfg.EmailAttribute, cfg.NameAttribute}
+	if cfg.GroupSearchBase == "" {
+		attributes = append(attributes, cfg.GroupAttribute)
+	}
+	// The size limit of 2 is enough to tell a unique match from several
+	users, err := conn.Search(ldap.NewSearchRequest(cfg.SearchBase, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases,
+		2, int(ldapTimeout.Seconds()), false, userFilter(cfg.UserFilter, username), attributes, nil))
+	several := ldap.IsErrorWithCode(err, ldap.LDAPResultSizeLimitExceeded)
+	if err != nil && !several {
+		return nil, fmt.Errorf("failed to search for user: %w", err)
+	}
+	if several || len(users.Entries) > 1 {
+		log.Warnf("LDAP user filter matched several entries for %q", username)
+		return nil, errLDAPInvalidCredentials
+	}
+	if len(users.Entries) == 0 {
+		return nil, errLDAPInvalidCredentials
+	}
+	found := users.Entries[0]
+
+	if err := conn.Bind(found.DN, password); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			return nil, errLDAPInvalidCredentials
+		}
+		return nil, fmt.Errorf("failed to bind as user: %w", err)
+	}
+
+	entry := &ldapEntry{
+		DN:       found.DN,
+		Username: found.GetEqualFoldAttributeValue(cfg.UsernameAttribute),
+		Email:    found.GetEqualFoldAttributeValue(cfg.EmailAttribute),
+		Name:     found.GetEqualFoldAttributeValue(cfg.NameAttribute),
+	}
+	if entry.Username == "" {
+		entry.Username = username
+	}
+
+	if cfg.GroupSearchBase == "" {
+		for _, value := range found.GetEqualFoldAttributeValues(cfg.GroupAttribute) {
+			entry.Groups = append(entry.Groups, groupNameFromDN(value))
+		}
+		return entry, nil
+	}
+
+	// Search groups with the service account again; the user may not be allowed to
+	if err := bindService(conn, cfg); err != nil {
+		return nil, err
+	}
+	filter := strings.NewReplacer(
+		"{dn}", ldap.EscapeFilter(found.DN),
+		"{username}", ldap.EscapeFilter(entry.Username),
+	).Replace(cfg.GroupFilter)
+	groups, err := conn.Search(ldap.NewSearchRequest(cfg.GroupSearchBase, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases,
+		0, int(ldapTimeout.Seconds()), false, filter, []string{cfg.GroupNameAttribute}, nil))
+	if err != nil {
+		return nil, fmt.Errorf("failed to search for groups: %w", err)
+	}
+	for _, group := range groups.Entries {
+		if name := group.GetEqualFoldAttributeValue(cfg.GroupNameAttribute); name != "" {
+			entry.Groups = append(entry.Groups, name)
+		}
+	}
+	return entry, nil
+}
+
+// userFilter fills the login name into a user filter
+func userFilter(filter, username string) string {
+	return strings.ReplaceAll(filter, "{username}", ldap.EscapeFilter(username))
+}
+
+// groupNameFromDN returns the value of the first RDN of a group DN
+// ("cn=admins,ou=groups,dc=example,dc=com" is "admins"); other values are kept as is
+func groupNameFromDN(value string) string {
+	dn, err := ldap.ParseDN(value)
+	if err != nil || len(dn.RDNs) == 0 || len(dn.RDNs[0].Attributes) == 0 || dn.RDNs[0].Attributes[0].Value == "" {
+		return value
+	}
+	return dn.RDNs[0].Attributes[0].Value
+}
+
+// syncLDAPUser creates or updates the kubelens user of a directory entry and maps its groups
+func (h *Handler) syncLDAPUser(cfg *LDAPConfig, entry *ldapEntry) (*db.User, error) {
+	if entry.Email == "" {
+		return nil, fmt.Errorf("LDAP entry %s has no %s attribute", entry.DN, cfg.EmailAttribute)
+	}
+
+	user, err := h.db.GetUserByEmail(entry.Email)
+	if err != nil {
+		user = &db.User{
+			Email:          entry.Email,
+			Username:       h.ensureUniqueUsername(generateUsername(OIDCClaims{PreferredName: entry.Username, Email: entry.Email})),
+			FullName:       entry.Name,
+			AuthProvider:   AuthProviderLDAP,
+			ProviderUserID: entry.DN,
+			IsActive:       true,
+		}
+		if err := h.db.CreateUser(user); err != nil {
+			return nil, fmt.Errorf("failed to create user: %w", err)
+		}
+		log.Infof("Created new LDAP user: %s (%s)", user.Username, user.Email)
+	} else {
+		if user.AuthProvider != AuthProviderLDAP {
+			return nil, fmt.Errorf("this account uses %s authentication", user.AuthProvider)
+		}
+		if !user.IsActive {
+			return user, nil
+		}
+		user.FullName = entry.Name
+		user.ProviderUserID = entry.DN
+		if err := h.db.UpdateUser(user); err != nil {
+			return nil, fmt.Errorf("failed to update user: %w", err)
+		}
+	}
+
+	if _, err := h.syncProviderGroups(user, entry.Groups, cfg.groupConfig(), "LDAP"); err != nil {
+		log.Warnf("LDAP group sync warning: %v", err)
+	}
+	return user, nil
+}
+
+// ldapSignin verifies a login against the directory and syncs the user. On failure the
+// response has been written and the error is non-nil.
+func (h *Handler) ldapSignin(c *gin.Context, cfg *LDAPConfig, login, password, lockIdentifier string) (*db.User, error) {
+	entry, err := authenticateLDAP(cfg, login, password)
+	if err != nil {
+		if errors.Is(err, errLDAPInvalidCredentials) {
+			h.accountLockout.RecordFailedAttempt(lockIdentifier)
+			log.Warnf("Failed LDAP login attempt for %s from IP: %s", login, c.ClientIP())
+			h.auditLogger.LogAuth(audit.EventAuthLoginFailed, nil, "", login, c.ClientIP(),
+				"Login failed: LDAP rejected the credentials", false)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"})
+			return nil, err
+		}
+		log.Errorf("LDAP authentication error for %s: %v", login, err)
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "directory service unavailable"})
+		return nil, err
+	}
+
+	user, err := h.syncLDAPUser(cfg, entry)
+	if err != nil {
+		log.Warnf("Failed to sync LDAP user %s: %v", entry.DN, err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, err
+	}
+	return user, nil
+}
@@ -0,0 +1,143 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/sonnguyen/kubelens/internal/audit"
+)
+
+// ldapSettings is the API representation of the LDAP settings; the bind password is
+// write-only
+type ldapSettings struct {
+	LDAPConfig
+	BindPasswordSet bool `json:"bind_password_set"`
+}
+
+func newLDAPSettings(cfg *LDAPConfig) ldapSettings {
+	if cfg == nil {
+		cfg = &LDAPConfig{}
+		cfg.applyDefaults()
+	}
+	settings := ldapSettings{LDAPConfig: *cfg, BindPasswordSet: cfg.BindPassword != ""}
+	settings.BindPassword = ""
+	return settings
+}
+
+// GetLDAPSettings handles GET /api/v1/system/ldap
+func (h *Handler) GetLDAPSettings(c *gin.Context) {
+	cfg, err := h.loadLDAPConfig()
+	if err != nil {
+		log.Errorf("Failed to load LDAP config: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load LDAP settings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, newLDAPSettings(cfg))
+}
+
+// UpdateLDAPSettings handles PUT /api/v1/system/ldap. An empty bind_password keeps the
+// stored one.
+func (h *Handler) UpdateLDAPSettings(c *gin.Context) {
+	var req LDAPConfig
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	current, err := h.loadLDAPConfig()
+	if err != nil {
+		log.Errorf("Failed to load LDAP config: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load LDAP settings"})
+		return
+	}
+	if req.BindPassword == "" && current != nil && req.BindDN != "" {
+		req.BindPassword = current.BindPassword
+	}
+
+	req.applyDefaults()
+	if req.Enabled || req.URL != "" {
+		if err := req.Validate(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	if err := h.saveLDAPConfig(req); err != nil {
+		log.Errorf("Failed to save LDAP config: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save LDAP settings"})
+		return
+	}
+
+	log.Infof("LDAP settings updated (enabled: %v, url: %s)", req.Enabled, req.URL)
+
+	// Audit log
+	if userID, exists := c.Get("user_id"); exists {
+		username, _ := c.Get("username")
+		email, _ := c.Get("email")
+		audit.Log(c, audit.EventAuditConfigChanged, userID.(int), username.(string), email.(string),
+			"Updated LDAP settings",
+			map[string]interface{}{
+				"enabled":     req.Enabled,
+				"url":         req.URL,
+				"bind_dn":     req.BindDN,
+				"search_base": req.SearchBase,
+			})
+	}
+
+	c.JSON(http.StatusOK, newLDAPSettings(&req))
+}
+
+// TestLDAPSettings handles POST /api/v1/system/ldap/test. It checks that the saved
+// settings can reach the directory and bind; with a username and password it also signs
+// that user in against the directory and reports the groups that would be assigned,
+// without creating the user.
+func (h *Handler) TestLDAPSettings(c *gin.Context) {
+	var req struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}
+	// The body is optional
+	_ = c.ShouldBindJSON(&req)
+
+	cfg, err := h.loadLDAPConfig()
+	if err != nil || cfg == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "LDAP is not configured"})
+		return
+	}
+	if err := cfg.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Username == "" {
+		conn, err := dialLDAP(cfg)
+		if err == nil {
+			err = bindService(conn, cfg)
+			conn.Close()
+		}
+		if err != nil {
+			c.JSON(http.StatusOK, gin.H{"success": false, "error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"success": true})
+		return
+	}
+
+	entry, err := authenticateLDAP(cfg, req.Username, req.Password)
+	if err != nil {
+		message := err.Error()
+		if errors.Is(err, errLDAPInvalidCredentials) {
+			message = "user not found or invalid password"
+		}
+		c.JSON(http.StatusOK, gin.H{"success": false, "error": message})
+		return
+	}
+
+	mapped := mapProviderGroups(entry.Groups, cfg.groupConfig())
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "entry": entry, "kubelens_groups": mapped})
+}
@@ -0,0 +1,185 @@
+package auth
+
+import (
+	"errors"
+	"net"
+	"reflect"
+	"sync/atomic"
+	"testing"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+)
+
+// LDAP protocol operations answered by fakeDirectory
+const (
+	ldapBindRequest       = 0
+	ldapBindResponse      = 1
+	ldapUnbindRequest     = 2
+	ldapSearchRequest     = 3
+	ldapSearchResultEntry = 4
+	ldapSearchResultDone  = 5
+	ldapEqualityMatch     = 3
+)
+
+// fakeDirectory serves binds for a service account and jdoe, and finds jdoe with an
+// equality filter on uid. It returns the URL and the number of connections made.
+func fakeDirectory(t *testing.T) (string, *int32) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	passwords := map[string]string{
+		"cn=svc,dc=example,dc=com":             "secret",
+		"uid=jdoe,ou=people,dc=example,dc=com": "hunter2",
+	}
+	var connections int32
+
+	serve := func(conn net.Conn) {
+		defer conn.Close()
+		reply := func(id int64, op *ber.Packet) {
+			message := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "")
+			message.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, id, ""))
+			message.AppendChild(op)
+			conn.Write(message.Bytes())
+		}
+		result := func(op ber.Tag, code int64) *ber.Packet {
+			p := ber.Encode(ber.ClassApplication, ber.TypeConstructed, op, nil, "")
+			p.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, code, ""))
+			p.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", ""))
+			p.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", ""))
+			return p
+		}
+		attribute := func(name string, values ...string) *ber.Packet {
+			p := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "")
+			p.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, name, ""))
+			set := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "")
+			for _, value := range values {
+				set.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, value, ""))
+			}
+			p.AppendChild(set)
+			return p
+		}
+
+		for {
+			message, err := ber.ReadPacket(conn)
+			if err != nil {
+				return
+			}
+			id, op := message.Children[0].Value.(int64), message.Children[1]
+			switch op.Tag {
+			case ldapBindRequest:
+				dn, password := op.Children[1].Value.(string), op.Children[2].Data.String()
+				code := int64(49) // invalidCredentials
+				if want, ok := passwords[dn]; ok && password == want {
+					code = 0
+				}
+				reply(id, result(ldapBindResponse, code))
+			case ldapSearchRequest:
+				filter := op.Children[6]
+				if filter.Tag == ldapEqualityMatch && filter.Children[0].Data.String() == "uid" && filter.Children[1].Data.String() == "jdoe" {
+					entry := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ldapSearchResultEntry, nil, "")
+					entry.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "uid=jdoe,ou=people,dc=example,dc=com", ""))
+					attributes := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "")
+					attributes.AppendChild(attribute("mail", "jdoe@example.com"))
+					attributes.AppendChild(attribute("memberOf", "cn=admins,ou=groups,dc=example,dc=com", "cn=dev\\2c ops,ou=groups,dc=example,dc=com"))
+					entry.AppendChild(attributes)
+					reply(id, entry)
+				}
+				reply(id, result(ldapSearchResultDone, 0))
+			case ldapUnbindRequest:
+				return
+			}
+		}
+	}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			atomic.AddInt32(&connections, 1)
+			go serve(conn)
+		}
+	}()
+	return "ldap://" + listener.Addr().String(), &connections
+}
+
+func TestAuthenticateLDAP(t *testing.T) {
+	url, connections := fakeDirectory(t)
+	cfg := &LDAPConfig{
+		URL:          url,
+		BindDN:       "cn=svc,dc=example,dc=com",
+		BindPassword: "secret",
+		SearchBase:   "dc=example,dc=com",
+		UserFilter:   "(uid={username})",
+	}
+	cfg.applyDefaults()
+
+	entry, err := authenticateLDAP(cfg, "jdoe", "hunter2")
+	if err != nil {
+		t.Fatalf("authenticateLDAP() error = %v", err)
+	}
+	want := &ldapEntry{
+		DN:       "uid=jdoe,ou=people,dc=example,dc=com",
+		Username: "jdoe",
+		Email:    "jdoe@example.com",
+		Groups:   []string{"admins", "dev, ops"},
+	}
+	if !reflect.DeepEqual(entry, want) {
+		t.Errorf("authenticateLDAP() = %+v, want %+v", entry, want)
+	}
+
+	tests := []struct {
+		name     string
+		username string
+		password string
+	}{
+		{"wrong password", "jdoe", "wrong"},
+		{"unknown user", "nobody", "hunter2"},
+		{"filter injection", "jdoe)(uid=*", "hunter2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := authenticateLDAP(cfg, tt.username, tt.password); !errors.Is(err, errLDAPInvalidCredentials) {
+				t.Errorf("authenticateLDAP() error = %v, want invalid credentials", err)
+			}
+		})
+	}
+
+	// An empty password would be an unauthenticated bind; the directory is not asked
+	before := atomic.LoadInt32(connections)
+	if _, err := authenticateLDAP(cfg, "jdoe", ""); !errors.Is(err, errLDAPInvalidCredentials) {
+		t.Errorf("authenticateLDAP() with an empty password error = %v, want invalid credentials", err)
+	}
+	if atomic.LoadInt32(connections) != before {
+		t.Error("authenticateLDAP() with an empty password connected to the directory")
+	}
+}
+
+func TestUserFilterEscaping(t *testing.T) {
+	if got, want := userFilter("(uid={username})", "a*(b)\\c"), "(uid=a\\2a\\28b\\29\\5cc)"; got != want {
+		t.Errorf("userFilter() = %q, want %q", got, want)
+	}
+}
+
+func TestGroupNameFromDN(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"cn=admins,ou=groups,dc=example,dc=com", "admins"},
+		{"CN=Domain Admins,CN=Users,DC=corp,DC=local", "Domain Admins"},
+		{"cn=dev\\2c ops,ou=groups", "dev, ops"},
+		{"cn=a\\,b,ou=groups", "a,b"},
+		{"admins", "admins"},
+	}
+	for _, tt := range tests {
+		if got := groupNameFromDN(tt.value); got != tt.want {
+			t.Errorf("groupNameFromDN(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
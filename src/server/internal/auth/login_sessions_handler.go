@@ -0,0 +1,124 @@
+package auth
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	"github.com/sonnguyen/kubelens/internal/audit"
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+// loginSession is a session as listed to users, flagging the one making the request
+type loginSession struct {
+	*db.Session
+	Current bool `json:"current"`
+}
+
+// listSessions responds with the active sessions of a user
+func (h *Handler) listSessions(c *gin.Context, userID uint) {
+	sessions, err := h.db.ListActiveUserSessions(userID)
+	if err != nil {
+		log.Errorf("Failed to list sessions: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list sessions"})
+		return
+	}
+
+	currentID, _ := c.Get("session_id")
+	result := make([]loginSession, 0, len(sessions))
+	for _, session := range sessions {
+		result = append(result, loginSession{Session: session, Current: currentID == session.ID})
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ListMySessions lists the devices the current user is signed in on
+func (h *Handler) ListMySessions(c *gin.Context) {
+	h.listSessions(c, uint(c.GetInt("user_id")))
+}
+
+// RevokeMySession signs the current user out of one of their sessions
+func (h *Handler) RevokeMySession(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid session ID"})
+		return
+	}
+
+	userID := uint(c.GetInt("user_id"))
+	session, err := h.db.GetSessionByID(uint(id))
+	if err != nil || session.UserID != userID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "session not found"})
+		return
+	}
+
+	if err := h.db.RevokeSession(session.ID); err != nil {
+		log.Errorf("Failed to revoke session %d: %v", session.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to revoke session"})
+		return
+	}
+
+	log.Infof("Session %d of user %d revoked", session.ID, userID)
+
+	uid := int(userID)
+	h.auditLogger.LogAuth(audit.EventAuthSessionRevoked, &uid, c.GetString("username"), c.GetString("email"), c.ClientIP(),
+		fmt.Sprintf("Session %d revoked (%s, %s)", session.ID, session.IPAddress, session.UserAgent), true)
+
+	c.JSON(http.StatusOK, gin.H{"message": "session revoked successfully"})
+}
+
+// ListUserSessions lists the active sessions of any user (admin)
+func (h *Handler) ListUserSessions(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	h.listSessions(c, uint(id))
+}
+
+// ForceLogoutUser signs a user out everywhere: every session and refresh token is revoked,
+// and access tokens issued so far are rejected
+func (h *Handler) ForceLogoutUser(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	user, err := h.db.GetUserByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+
+	if err := h.db.RevokeUserSessions(user.ID, 0); err != nil {
+		log.Errorf("Failed to revoke sessions of user %d: %v", user.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to revoke sessions"})
+		return
+	}
+	if err := h.db.RevokeUserTokens(user.ID); err != nil {
+		log.Errorf("Failed to revoke tokens of user %d: %v", user.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to revoke sessions"})
+		return
+	}
+
+	log.Infof("User %s (ID: %d) signed out of all sessions by %s", user.Email, user.ID, c.GetString("email"))
+
+	// Audit log
+	if username, exists := c.Get("username"); exists {
+		email, _ := c.Get("email")
+		audit.Log(c, audit.EventAuthSessionRevoked, c.GetInt("user_id"), username.(string), email.(string),
+			fmt.Sprintf("Signed out user %s from all sessions", user.Username),
+			map[string]interface{}{
+				"target_user_id":  user.ID,
+				"target_username": user.Username,
+			})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "user signed out of all sessions"})
+}
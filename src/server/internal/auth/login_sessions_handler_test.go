@@ -0,0 +1,123 @@
+package auth
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	"github.com/sonnguyen/kubelens/internal/audit"
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+func TestLoginSessions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	log.SetLevel(log.WarnLevel)
+
+	database, err := db.New(filepath.Join(t.TempDir(), "kubelens.db"))
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	SetMiddlewareDB(database)
+	t.Cleanup(func() { SetMiddlewareDB(nil) })
+
+	user, err := database.CreateInitialAdmin("admin", "admin@kubelens.local", "Administrator", "not-a-password-hash")
+	if err != nil {
+		t.Fatalf("failed to create test user: %v", err)
+	}
+
+	h := NewHandler(database, "test-secret", audit.NewLogger(database))
+	router := gin.New()
+	router.POST("/refresh", h.RefreshToken)
+	router.GET("/sessions", AuthMiddleware("test-secret"), h.ListMySessions)
+	router.DELETE("/sessions/:id", AuthMiddleware("test-secret"), h.RevokeMySession)
+
+	do := func(method, path, token string, body interface{}) *httptest.ResponseRecorder {
+		var reader *bytes.Reader
+		if body != nil {
+			raw, _ := json.Marshal(body)
+			reader = bytes.NewReader(raw)
+		} else {
+			reader = bytes.NewReader(nil)
+		}
+		req := httptest.NewRequest(method, path, reader)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	signIn := func() *tokenPair {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodPost, "/signin", nil)
+		tokens, err := h.issueTokens(c, user)
+		if err != nil {
+			t.Fatalf("issueTokens() error = %v", err)
+		}
+		return tokens
+	}
+
+	laptop := signIn()
+	phone := signIn()
+
+	w := do(http.MethodGet, "/sessions", laptop.AccessToken, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("list status = %d: %s", w.Code, w.Body.String())
+	}
+	var sessions []struct {
+		ID      uint `json:"id"`
+		Current bool `json:"current"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &sessions); err != nil {
+		t.Fatalf("invalid body %s: %v", w.Body.String(), err)
+	}
+	if len(sessions) != 2 {
+		t.Fatalf("got %d sessions, want 2", len(sessions))
+	}
+
+	claims, _ := ValidateToken(phone.AccessToken, "test-secret")
+	var current int
+	for _, s := range sessions {
+		if s.Current {
+			current++
+			if s.ID == claims.SessionID {
+				t.Errorf("phone session %d marked as current on the laptop", s.ID)
+			}
+		}
+	}
+	if current != 1 {
+		t.Errorf("got %d current sessions, want 1", current)
+	}
+
+	// Signing the phone out from the laptop ends its access and refresh tokens
+	if w := do(http.MethodDelete, fmt.Sprintf("/sessions/%d", claims.SessionID), laptop.AccessToken, nil); w.Code != http.StatusOK {
+		t.Fatalf("revoke status = %d: %s", w.Code, w.Body.String())
+	}
+	if w := do(http.MethodGet, "/sessions", phone.AccessToken, nil); w.Code != http.StatusUnauthorized {
+		t.Errorf("revoked session status = %d, want 401", w.Code)
+	}
+	if w := do(http.MethodPost, "/refresh", "", gin.H{"refresh_token": phone.RefreshToken}); w.Code != http.StatusUnauthorized {
+		t.Errorf("refresh of revoked session status = %d, want 401", w.Code)
+	}
+
+	// The laptop is unaffected
+	if w := do(http.MethodGet, "/sessions", laptop.AccessToken, nil); w.Code != http.StatusOK {
+		t.Errorf("remaining session status = %d, want 200", w.Code)
+	}
+	if w := do(http.MethodPost, "/refresh", "", gin.H{"refresh_token": laptop.RefreshToken}); w.Code != http.StatusOK {
+		t.Errorf("refresh of remaining session status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+
+	if w := do(http.MethodDelete, "/sessions/9999", laptop.AccessToken, nil); w.Code != http.StatusNotFound {
+		t.Errorf("unknown session status = %d, want 404", w.Code)
+	}
+}
@@ -3,8 +3,10 @@ package auth
 import (
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
 	"github.com/sonnguyen/kubelens/internal/db"
 )
 
@@ -22,9 +24,55 @@ func SetMiddlewareDB(database userStatusChecker) {
 	middlewareDB = database
 }
 
+// sessionStore is what the middleware needs to check the login session of a token
+type sessionStore interface {
+	GetSessionByID(id uint) (*db.Session, error)
+	TouchSession(id uint, ip string) error
+}
+
+// sessionTouchInterval is how often the last-seen time of a session is written
+const sessionTouchInterval = time.Minute
+
+// activeSession reports whether the login session of a token is still valid, recording
+// activity on it. Only the temporary tokens of a sign-in step have no session; without a
+// session store every token is accepted.
+func activeSession(c *gin.Context, claims *Claims) bool {
+	store, ok := middlewareDB.(sessionStore)
+	if !ok {
+		return true
+	}
+	if claims.SessionID == 0 {
+		return claims.Purpose != ""
+	}
+
+	session, err := store.GetSessionByID(claims.SessionID)
+	if err != nil || session.RevokedAt != nil || session.UserID != uint(claims.UserID) {
+		return false
+	}
+
+	if session.LastSeenAt == nil || time.Since(*session.LastSeenAt) > sessionTouchInterval {
+		if err := store.TouchSession(session.ID, c.ClientIP()); err != nil {
+			log.Warnf("Failed to record activity of session %d: %v", session.ID, err)
+		}
+	}
+	c.Set("session_id", session.ID)
+	return true
+}
+
 // AuthMiddleware validates JWT tokens and sets user context
 // Supports both Authorization header (for HTTP) and token query parameter (for WebSocket)
 func AuthMiddleware(secret string) gin.HandlerFunc {
+	return authenticate(secret, "")
+}
+
+// MFASetupMiddleware is AuthMiddleware for the MFA enrollment routes, which also accept
+// the temporary token of a user who must enroll MFA before signing in
+func MFASetupMiddleware(secret string) gin.HandlerFunc {
+	return authenticate(secret, TokenPurposeMFASetup)
+}
+
+// authenticate validates session tokens, and the temporary tokens of a purpose
+func authenticate(secret, purpose string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var tokenString string
 
@@ -49,8 +97,18 @@ func AuthMiddleware(secret string) gin.HandlerFunc {
 			}
 		}
 
+		// Personal access tokens are looked up in the database instead of being verified as JWTs
+		if strings.HasPrefix(tokenString, APITokenPrefix) {
+			if !authenticateAPIToken(c, tokenString) {
+				c.Abort()
+				return
+			}
+			c.Next()
+			return
+		}
+
 		claims, err := ValidateToken(tokenString, secret)
-		if err != nil {
+		if err != nil || (claims.Purpose != "" && claims.Purpose != purpose) {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
 			c.Abort()
 			return
@@ -81,6 +139,16 @@ func AuthMiddleware(secret string) gin.HandlerFunc {
 				}
 			}
 
+			if !activeSession(c, claims) {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "session revoked, please login again"})
+				c.Abort()
+				return
+			}
+			if err := setOrganization(c, user, claims.OrgID); err != nil {
+				abortOrganization(c, err)
+				return
+			}
+
 			// Set the full user object for handlers that need it
 			c.Set("user", user)
 		}
@@ -126,8 +194,8 @@ func OptionalAuth(secret string) gin.HandlerFunc {
 		}
 
 		claims, err := ValidateToken(tokenString, secret)
-		if err != nil {
-			// Invalid token, continue without user context
+		if err != nil || claims.Purpose != "" {
+			// Invalid or temporary token, continue without user context
 			c.Next()
 			return
 		}
@@ -149,6 +217,14 @@ func OptionalAuth(secret string) gin.HandlerFunc {
 					return
 				}
 			}
+			if !activeSession(c, claims) {
+				c.Next()
+				return
+			}
+			if err := setOrganization(c, user, claims.OrgID); err != nil {
+				c.Next()
+				return
+			}
 
 			c.Set("user", user)
 		}
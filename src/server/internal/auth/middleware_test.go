@@ -0,0 +1,79 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	"github.com/sonnguyen/kubelens/internal/audit"
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+func TestTokensWithoutSession(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	log.SetLevel(log.WarnLevel)
+
+	database, err := db.New(filepath.Join(t.TempDir(), "kubelens.db"))
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	SetMiddlewareDB(database)
+	t.Cleanup(func() { SetMiddlewareDB(nil) })
+
+	user, err := database.CreateInitialAdmin("admin", "admin@kubelens.local", "Administrator", "not-a-password-hash")
+	if err != nil {
+		t.Fatalf("failed to create test user: %v", err)
+	}
+
+	h := NewHandler(database, "test-secret", audit.NewLogger(database))
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	router := gin.New()
+	router.GET("/clusters", AuthMiddleware("test-secret"), ok)
+	router.POST("/mfa/setup", MFASetupMiddleware("test-secret"), ok)
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/signin", nil)
+	tokens, err := h.issueTokens(c, user)
+	if err != nil {
+		t.Fatalf("issueTokens() error = %v", err)
+	}
+	sessionless, _ := GenerateToken(int(user.ID), user.Email, user.Username, user.IsAdmin, "test-secret")
+	mfaSetup, _ := GenerateMFASetupToken(int(user.ID), user.Email, user.Username, user.IsAdmin, "test-secret")
+	expiredMFASetup, _ := signToken(Claims{UserID: int(user.ID), Purpose: TokenPurposeMFASetup}, -time.Minute, "test-secret")
+
+	tests := []struct {
+		name  string
+		token string
+		path  string
+		want  int
+	}{
+		{"session token", tokens.AccessToken, "/clusters", http.StatusOK},
+		{"session token enrolling MFA", tokens.AccessToken, "/mfa/setup", http.StatusOK},
+		{"token without a session", sessionless, "/clusters", http.StatusUnauthorized},
+		{"token without a session enrolling MFA", sessionless, "/mfa/setup", http.StatusUnauthorized},
+		{"MFA setup token", mfaSetup, "/clusters", http.StatusUnauthorized},
+		{"MFA setup token enrolling MFA", mfaSetup, "/mfa/setup", http.StatusOK},
+		{"expired MFA setup token", expiredMFASetup, "/mfa/setup", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method := http.MethodGet
+			if tt.path == "/mfa/setup" {
+				method = http.MethodPost
+			}
+			req := httptest.NewRequest(method, tt.path, nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+}
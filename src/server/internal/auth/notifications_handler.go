@@ -30,7 +30,7 @@ func (h *Handler) GetNotifications(c *gin.Context) {
 		limit = 100
 	}
 	
-	notifications, err := h.db.GetUserNotifications(uint(userID), limit)
+	notifications, err := h.db.GetUserNotifications(uint(userID), organizationID(c), limit)
 	if err != nil {
 		log.Errorf("Failed to get notifications: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get notifications"})
@@ -54,7 +54,7 @@ func (h *Handler) GetUnreadNotifications(c *gin.Context) {
 		return
 	}
 	
-	notifications, err := h.db.GetUnreadNotifications(uint(userID))
+	notifications, err := h.db.GetUnreadNotifications(uint(userID), organizationID(c))
 	if err != nil {
 		log.Errorf("Failed to get unread notifications: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get unread notifications"})
@@ -78,7 +78,7 @@ func (h *Handler) GetUnreadCount(c *gin.Context) {
 		return
 	}
 	
-	count, err := h.db.CountUnreadNotifications(uint(userID))
+	count, err := h.db.CountUnreadNotifications(uint(userID), organizationID(c))
 	if err != nil {
 		log.Errorf("Failed to get unread count: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get unread count"})
@@ -115,10 +115,11 @@ func (h *Handler) CreateNotification(c *gin.Context) {
 	}
 	
 	notification := &db.Notification{
-		UserID:  uint(userID),
-		Type:    req.Type,
-		Title:   req.Title,
-		Message: req.Message,
+		UserID:         uint(userID),
+		Type:           req.Type,
+		Title:          req.Title,
+		Message:        req.Message,
+		OrganizationID: organizationID(c),
 	}
 	
 	if err := h.db.CreateNotification(notification); err != nil {
@@ -168,7 +169,7 @@ func (h *Handler) MarkAllNotificationsAsRead(c *gin.Context) {
 		return
 	}
 	
-	if err := h.db.MarkAllNotificationsAsRead(uint(userID)); err != nil {
+	if err := h.db.MarkAllNotificationsAsRead(uint(userID), organizationID(c)); err != nil {
 		log.Errorf("Failed to mark all notifications as read: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to mark all notifications as read"})
 		return
@@ -215,7 +216,7 @@ func (h *Handler) ClearAllNotifications(c *gin.Context) {
 		return
 	}
 	
-	if err := h.db.DeleteUserNotifications(uint(userID)); err != nil {
+	if err := h.db.DeleteUserNotifications(uint(userID), organizationID(c)); err != nil {
 		log.Errorf("Failed to clear all notifications: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to clear all notifications"})
 		return
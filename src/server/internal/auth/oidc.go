@@ -68,6 +68,7 @@ type OIDCSyncResponse struct {
 	IsNewUser    bool     `json:"is_new_user"`
 	Groups       []string `json:"groups"`
 	SessionToken string   `json:"session_token"`
+	RefreshToken string   `json:"refresh_token"`
 	ExpiresAt    int64    `json:"expires_at"`
 }
 
@@ -123,24 +124,23 @@ func (h *Handler) HandleOIDCSync(c *gin.Context) {
 	user.ProviderUserID = req.ProviderID
 	h.db.UpdateUser(user)
 
-	// Generate session token
-	sessionToken, err := GenerateToken(int(user.ID), user.Email, user.Username, user.IsAdmin, h.secret)
+	// Sign in like a password login: a session with a refresh token
+	tokens, err := h.issueTokens(c, user)
 	if err != nil {
+		log.Errorf("Failed to generate token: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create session"})
 		return
 	}
 
-	// Calculate expiry (24 hours from now)
-	expiresAt := time.Now().Add(24 * time.Hour).Unix()
-
 	c.JSON(http.StatusOK, OIDCSyncResponse{
 		UserID:       user.ID,
 		Email:        user.Email,
 		Username:     user.Username,
 		IsNewUser:    isNew,
 		Groups:       syncedGroups,
-		SessionToken: sessionToken,
-		ExpiresAt:    expiresAt,
+		SessionToken: tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresAt:    time.Now().Add(time.Duration(tokens.ExpiresIn) * time.Second).Unix(),
 	})
 }
 
@@ -254,24 +254,15 @@ func (h *Handler) downloadAndCacheAvatar(user *db.User, avatarURL string) error
 
 // syncOIDCGroups syncs user groups from OIDC claims
 func (h *Handler) syncOIDCGroups(user *db.User, oidcGroups []string, config OIDCConfig) ([]string, error) {
-	var syncedGroups []string
+	return h.syncProviderGroups(user, oidcGroups, config, "OIDC")
+}
 
-	// Map OIDC groups to Kubelens groups
-	mappedGroups := make([]string, 0)
-	for _, oidcGroup := range oidcGroups {
-		// Check if there's a mapping
-		if mapped, ok := config.GroupMapping[oidcGroup]; ok {
-			mappedGroups = append(mappedGroups, mapped)
-		} else {
-			// Use normalized group name
-			mappedGroups = append(mappedGroups, normalizeGroupName(oidcGroup))
-		}
-	}
+// syncProviderGroups adds a user to the kubelens groups mapped from the groups reported
+// by an identity provider (OIDC, LDAP)
+func (h *Handler) syncProviderGroups(user *db.User, oidcGroups []string, config OIDCConfig, provider string) ([]string, error) {
+	var syncedGroups []string
 
-	// If no groups, use default
-	if len(mappedGroups) == 0 && config.DefaultGroup != "" {
-		mappedGroups = append(mappedGroups, config.DefaultGroup)
-	}
+	mappedGroups := mapProviderGroups(oidcGroups, config)
 
 	// Get current user groups
 	currentGroups, err := h.db.GetUserGroups(user.ID)
@@ -302,14 +293,14 @@ func (h *Handler) syncOIDCGroups(user *db.User, oidcGroups []string, config OIDC
 			// Create the group
 			group = &db.Group{
 				Name:        groupName,
-				Description: fmt.Sprintf("Auto-created from OIDC group: %s", groupName),
+				Description: fmt.Sprintf("Auto-created from %s group: %s", provider, groupName),
 				IsSystem:    false,
 			}
 			if err := h.db.CreateGroup(group); err != nil {
 				log.Warnf("Failed to create group %s: %v", groupName, err)
 				continue
 			}
-			log.Infof("Auto-created group from OIDC: %s", groupName)
+			log.Infof("Auto-created group from %s: %s", provider, groupName)
 		}
 
 		// Add user to group
@@ -325,6 +316,26 @@ func (h *Handler) syncOIDCGroups(user *db.User, oidcGroups []string, config OIDC
 	return syncedGroups, nil
 }
 
+// mapProviderGroups maps the groups of an identity provider to Kubelens groups
+func mapProviderGroups(providerGroups []string, config OIDCConfig) []string {
+	mappedGroups := make([]string, 0)
+	for _, providerGroup := range providerGroups {
+		// Check if there's a mapping
+		if mapped, ok := config.GroupMapping[providerGroup]; ok {
+			mappedGroups = append(mappedGroups, mapped)
+		} else {
+			// Use normalized group name
+			mappedGroups = append(mappedGroups, normalizeGroupName(providerGroup))
+		}
+	}
+
+	// If no groups, use default
+	if len(mappedGroups) == 0 && config.DefaultGroup != "" {
+		mappedGroups = append(mappedGroups, config.DefaultGroup)
+	}
+	return mappedGroups
+}
+
 // ensureUniqueUsername ensures the username is unique by appending numbers if needed
 func (h *Handler) ensureUniqueUsername(username string) string {
 	originalUsername := username
@@ -425,9 +436,11 @@ type OAuthExchangeRequest struct {
 
 // OAuthExchangeResponse represents the response after successful token exchange
 type OAuthExchangeResponse struct {
-	Token     string                 `json:"token"`
-	User      map[string]interface{} `json:"user"`
-	IsNewUser bool                   `json:"is_new_user"`
+	Token        string                 `json:"token"`
+	RefreshToken string                 `json:"refresh_token"`
+	ExpiresIn    int                    `json:"expires_in"`
+	User         map[string]interface{} `json:"user"`
+	IsNewUser    bool                   `json:"is_new_user"`
 }
 
 // HandleOAuthExchange handles the OAuth2 code exchange with PKCE
@@ -568,8 +581,8 @@ func (h *Handler) HandleOAuthExchange(c *gin.Context) {
 	user.LastLogin = &now
 	h.db.UpdateUser(user)
 
-	// Generate Kubelens JWT
-	jwtToken, err := GenerateToken(int(user.ID), user.Email, user.Username, user.IsAdmin, h.secret)
+	// Generate Kubelens tokens
+	tokens, err := h.issueTokens(c, user)
 	if err != nil {
 		log.Errorf("Failed to generate token: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate token"})
@@ -605,7 +618,9 @@ func (h *Handler) HandleOAuthExchange(c *gin.Context) {
 
 	// Return response
 	c.JSON(http.StatusOK, OAuthExchangeResponse{
-		Token: jwtToken,
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
 		User: map[string]interface{}{
 			"id":          user.ID,
 			"email":       user.Email,
@@ -0,0 +1,583 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	"github.com/sonnguyen/kubelens/internal/audit"
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+// organizationNamePattern is what organization names look like: a DNS label
+var organizationNamePattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]{0,61}[a-z0-9])?$`)
+
+// errNotOrganizationMember is returned for a request working in an organization its user
+// is not a member of
+var errNotOrganizationMember = errors.New("not a member of this organization")
+
+// organizationStore is what the middleware needs to check the organization of a request
+type organizationStore interface {
+	GetOrganizationRole(orgID, userID uint) (string, error)
+}
+
+// setOrganization puts the organization a request works in (the default one for 0) and
+// the user's role there in the context. Admins may work in any organization, the others
+// only in those they are members of.
+func setOrganization(c *gin.Context, user *db.User, orgID uint) error {
+	if orgID == 0 {
+		orgID = db.DefaultOrganizationID
+	}
+	var role string
+	if store, ok := middlewareDB.(organizationStore); ok {
+		var err error
+		if role, err = store.GetOrganizationRole(orgID, user.ID); err != nil {
+			return err
+		}
+		if role == "" && !user.IsAdmin {
+			return errNotOrganizationMember
+		}
+	}
+	c.Set("org_id", orgID)
+	c.Set("org_role", role)
+	return nil
+}
+
+// abortOrganization ends a request whose organization could not be set
+func abortOrganization(c *gin.Context, err error) {
+	if errors.Is(err, errNotOrganizationMember) {
+		// Refreshing the token moves the session to an organization the user is in
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	} else {
+		log.Errorf("Failed to check organization membership: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check organization membership"})
+	}
+	c.Abort()
+}
+
+// organizationID returns the organization a request works in
+func organizationID(c *gin.Context) uint {
+	if orgID := c.GetUint("org_id"); orgID != 0 {
+		return orgID
+	}
+	return db.DefaultOrganizationID
+}
+
+// isOrganizationAdmin reports whether the caller is an owner or admin of the organization
+// it works in
+func isOrganizationAdmin(c *gin.Context) bool {
+	role := c.GetString("org_role")
+	return role == db.OrgRoleOwner || role == db.OrgRoleAdmin
+}
+
+// instanceWide reports whether a permission covers the whole kubelens instance rather
+// than an organization, so organization owners and admins are not granted it by their role
+func instanceWide(resource, action string) bool {
+	switch resource {
+	case "settings", "users":
+		return true
+	case "audit":
+		return action != "read"
+	}
+	return false
+}
+
+// userPermissions returns the permissions of the caller in the organization it works in
+func (h *Handler) userPermissions(c *gin.Context) ([]db.Permission, error) {
+	return h.db.GetUserOrganizationPermissions(uint(c.GetInt("user_id")), organizationID(c))
+}
+
+// sessionOrganization returns the organization a session works in, moving it to the
+// user's default organization if the user left it
+func (h *Handler) sessionOrganization(user *db.User, session *db.Session) (uint, error) {
+	orgID := session.OrganizationID
+	role, err := h.db.GetOrganizationRole(orgID, user.ID)
+	if err != nil {
+		return 0, err
+	}
+	if role != "" || user.IsAdmin {
+		return orgID, nil
+	}
+	if orgID, err = h.db.DefaultOrganizationOf(user.ID); err != nil || orgID == 0 {
+		return db.DefaultOrganizationID, err
+	}
+	if err := h.db.SetSessionOrganization(session.ID, orgID); err != nil {
+		return 0, err
+	}
+	return orgID, nil
+}
+
+// organizationView is an organization with the caller's role in it
+type organizationView struct {
+	*db.Organization
+	Role string `json:"role,omitempty"`
+}
+
+// callerRole returns the caller's role in an organization: owner for admins, "" for
+// non-members
+func (h *Handler) callerRole(c *gin.Context, orgID uint) (string, error) {
+	if isAdmin, _ := c.Get("is_admin"); isAdmin == true {
+		return db.OrgRoleOwner, nil
+	}
+	return h.db.GetOrganizationRole(orgID, uint(c.GetInt("user_id")))
+}
+
+// organizationParam loads the organization of the :id parameter the caller may see,
+// responding 404 for the others. It returns the caller's role there.
+func (h *Handler) organizationParam(c *gin.Context) (*db.Organization, string, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization ID"})
+		return nil, "", false
+	}
+	org, err := h.db.GetOrganization(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "organization not found"})
+		return nil, "", false
+	}
+	role, err := h.callerRole(c, org.ID)
+	if err != nil {
+		log.Errorf("Failed to get organization role: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check organization membership"})
+		return nil, "", false
+	}
+	if role == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "organization not found"})
+		return nil, "", false
+	}
+	return org, role, true
+}
+
+// auditOrganization records an organization event by the caller
+func auditOrganization(c *gin.Context, event, description string, metadata map[string]interface{}) {
+	if user, ok := c.Get("user"); ok {
+		if u, ok := user.(*db.User); ok {
+			audit.Log(c, event, int(u.ID), u.Username, u.Email, description, metadata)
+		}
+	}
+}
+
+// ListOrganizations returns the organizations the caller can work in, with its role in
+// each and the one it works in. Admins see every organization.
+func (h *Handler) ListOrganizations(c *gin.Context) {
+	memberships, err := h.db.ListUserOrganizations(uint(c.GetInt("user_id")))
+	if err != nil {
+		log.Errorf("Failed to list organizations: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list organizations"})
+		return
+	}
+
+	views := make([]organizationView, 0, len(memberships))
+	if isAdmin, _ := c.Get("is_admin"); isAdmin == true {
+		roles := make(map[uint]string, len(memberships))
+		for _, m := range memberships {
+			roles[m.OrganizationID] = m.Role
+		}
+		orgs, err := h.db.ListOrganizations()
+		if err != nil {
+			log.Errorf("Failed to list organizations: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list organizations"})
+			return
+		}
+		for _, org := range orgs {
+			views = append(views, organizationView{Organization: org, Role: roles[org.ID]})
+		}
+	} else {
+		for _, m := range memberships {
+			if m.Organization != nil {
+				views = append(views, organizationView{Organization: m.Organization, Role: m.Role})
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"organizations": views, "current": organizationID(c)})
+}
+
+// CreateOrganization creates an organization, owned by the admin creating it (admin only)
+func (h *Handler) CreateOrganization(c *gin.Context) {
+	var req struct {
+		Name        string `json:"name" binding:"required"`
+		DisplayName string `json:"display_name"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !organizationNamePattern.MatchString(req.Name) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name must be lowercase letters, digits and dashes, at most 63 characters"})
+		return
+	}
+	orgs, err := h.db.ListOrganizations()
+	if err != nil {
+		log.Errorf("Failed to list organizations: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create organization"})
+		return
+	}
+	for _, org := range orgs {
+		if org.Name == req.Name {
+			c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("organization %s already exists", req.Name)})
+			return
+		}
+	}
+
+	org := &db.Organization{Name: req.Name, DisplayName: req.DisplayName}
+	if err := h.db.CreateOrganization(org, uint(c.GetInt("user_id"))); err != nil {
+		log.Errorf("Failed to create organization: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create organization"})
+		return
+	}
+
+	auditOrganization(c, audit.EventAuditOrganizationCreated, fmt.Sprintf("Created organization: %s", org.Name),
+		map[string]interface{}{"organization_id": org.ID, "organization_name": org.Name})
+	c.JSON(http.StatusCreated, org)
+}
+
+// GetOrganization returns an organization the caller is a member of
+func (h *Handler) GetOrganization(c *gin.Context) {
+	org, role, ok := h.organizationParam(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, organizationView{Organization: org, Role: role})
+}
+
+// UpdateOrganization changes the display name of an organization (owners and admins)
+func (h *Handler) UpdateOrganization(c *gin.Context) {
+	org, role, ok := h.organizationParam(c)
+	if !ok {
+		return
+	}
+	if role != db.OrgRoleOwner && role != db.OrgRoleAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "insufficient organization role"})
+		return
+	}
+	var req struct {
+		DisplayName string `json:"display_name"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	org.DisplayName = req.DisplayName
+	if err := h.db.UpdateOrganization(org); err != nil {
+		log.Errorf("Failed to update organization: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update organization"})
+		return
+	}
+
+	auditOrganization(c, audit.EventAuditOrganizationUpdated, fmt.Sprintf("Updated organization: %s", org.Name),
+		map[string]interface{}{"organization_id": org.ID, "organization_name": org.Name})
+	c.JSON(http.StatusOK, org)
+}
+
+// DeleteOrganization deletes an organization without clusters (admin only)
+func (h *Handler) DeleteOrganization(c *gin.Context) {
+	org, _, ok := h.organizationParam(c)
+	if !ok {
+		return
+	}
+	if org.ID == db.DefaultOrganizationID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "the default organization cannot be deleted"})
+		return
+	}
+
+	if err := h.db.DeleteOrganization(org.ID); err != nil {
+		if errors.Is(err, db.ErrOrganizationNotEmpty) {
+			c.JSON(http.StatusConflict, gin.H{"error": "move or remove the clusters of the organization first"})
+			return
+		}
+		log.Errorf("Failed to delete organization: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete organization"})
+		return
+	}
+
+	auditOrganization(c, audit.EventAuditOrganizationDeleted, fmt.Sprintf("Deleted organization: %s", org.Name),
+		map[string]interface{}{"organization_id": org.ID, "organization_name": org.Name})
+	c.JSON(http.StatusOK, gin.H{"message": "organization deleted"})
+}
+
+// ListOrganizationMembers lists the members of an organization and their roles
+func (h *Handler) ListOrganizationMembers(c *gin.Context) {
+	org, _, ok := h.organizationParam(c)
+	if !ok {
+		return
+	}
+	members, err := h.db.ListOrganizationMembers(org.ID)
+	if err != nil {
+		log.Errorf("Failed to list organization members: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list members"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"members": members, "count": len(members)})
+}
+
+// validOrganizationRole reports whether role is an organization role
+func validOrganizationRole(role string) bool {
+	return role == db.OrgRoleOwner || role == db.OrgRoleAdmin || role == db.OrgRoleMember
+}
+
+// canManageMember reports whether a caller with callerRole may give or take away role:
+// owners and admins manage members, only owners manage owners
+func canManageMember(callerRole, role string) bool {
+	if role == db.OrgRoleOwner {
+		return callerRole == db.OrgRoleOwner
+	}
+	return callerRole == db.OrgRoleOwner || callerRole == db.OrgRoleAdmin
+}
+
+// AddOrganizationMember adds an existing user, by ID or email, to an organization
+func (h *Handler) AddOrganizationMember(c *gin.Context) {
+	org, callerRole, ok := h.organizationParam(c)
+	if !ok {
+		return
+	}
+	var req struct {
+		UserID uint   `json:"user_id"`
+		Email  string `json:"email"`
+		Role   string `json:"role"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Role == "" {
+		req.Role = db.OrgRoleMember
+	}
+	if !validOrganizationRole(req.Role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "role must be owner, admin or member"})
+		return
+	}
+	if !canManageMember(callerRole, req.Role) {
+		c.JSON(http.StatusForbidden, gin.H{"error": fmt.Sprintf("your role cannot add %ss", req.Role)})
+		return
+	}
+
+	var user *db.User
+	var err error
+	switch {
+	case req.UserID != 0:
+		user, err = h.db.GetUserByID(req.UserID)
+	case req.Email != "":
+		user, err = h.db.GetUserByEmail(req.Email)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "user_id or email is required"})
+		return
+	}
+	if err != nil || user == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+	if role, err := h.db.GetOrganizationRole(org.ID, user.ID); err != nil {
+		log.Errorf("Failed to get organization role: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to add member"})
+		return
+	} else if role != "" {
+		c.JSON(http.StatusConflict, gin.H{"error": "user is already a member"})
+		return
+	}
+
+	if err := h.db.SetOrganizationMember(org.ID, user.ID, req.Role); err != nil {
+		log.Errorf("Failed to add organization member: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to add member"})
+		return
+	}
+
+	auditOrganization(c, audit.EventAuditOrganizationMemberAdded,
+		fmt.Sprintf("Added %s to organization %s as %s", user.Username, org.Name, req.Role),
+		map[string]interface{}{"organization_id": org.ID, "user_id": user.ID, "role": req.Role})
+	c.JSON(http.StatusCreated, db.OrganizationMember{OrganizationID: org.ID, UserID: user.ID, Role: req.Role, User: user})
+}
+
+// memberParam loads the role of the member in the :user_id parameter
+func (h *Handler) memberParam(c *gin.Context, org *db.Organization) (uint, string, bool) {
+	userID, err := strconv.Atoi(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return 0, "", false
+	}
+	role, err := h.db.GetOrganizationRole(org.ID, uint(userID))
+	if err != nil {
+		log.Errorf("Failed to get organization role: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check organization membership"})
+		return 0, "", false
+	}
+	if role == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "member not found"})
+		return 0, "", false
+	}
+	return uint(userID), role, true
+}
+
+// lastOwner reports whether taking away the owner role from a member of org would leave it
+// without owners, responding 409 if so
+func (h *Handler) lastOwner(c *gin.Context, org *db.Organization, role string) bool {
+	if role != db.OrgRoleOwner {
+		return false
+	}
+	owners, err := h.db.CountOrganizationOwners(org.ID)
+	if err != nil {
+		log.Errorf("Failed to count organization owners: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check organization owners"})
+		return true
+	}
+	if owners <= 1 {
+		c.JSON(http.StatusConflict, gin.H{"error": "an organization needs at least one owner"})
+		return true
+	}
+	return false
+}
+
+// UpdateOrganizationMember changes the role of a member
+func (h *Handler) UpdateOrganizationMember(c *gin.Context) {
+	org, callerRole, ok := h.organizationParam(c)
+	if !ok {
+		return
+	}
+	userID, current, ok := h.memberParam(c, org)
+	if !ok {
+		return
+	}
+	var req struct {
+		Role string `json:"role" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !validOrganizationRole(req.Role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "role must be owner, admin or member"})
+		return
+	}
+	if !canManageMember(callerRole, current) || !canManageMember(callerRole, req.Role) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only owners can change owners"})
+		return
+	}
+	if req.Role != db.OrgRoleOwner && h.lastOwner(c, org, current) {
+		return
+	}
+
+	if err := h.db.SetOrganizationMember(org.ID, userID, req.Role); err != nil {
+		log.Errorf("Failed to update organization member: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update member"})
+		return
+	}
+
+	auditOrganization(c, audit.EventAuditOrganizationMemberUpdated,
+		fmt.Sprintf("Changed the role of user %d in organization %s to %s", userID, org.Name, req.Role),
+		map[string]interface{}{"organization_id": org.ID, "user_id": userID, "role": req.Role, "previous_role": current})
+	c.JSON(http.StatusOK, gin.H{"message": "member updated"})
+}
+
+// RemoveOrganizationMember removes a member from an organization and its groups. Members
+// may remove themselves.
+func (h *Handler) RemoveOrganizationMember(c *gin.Context) {
+	org, callerRole, ok := h.organizationParam(c)
+	if !ok {
+		return
+	}
+	userID, current, ok := h.memberParam(c, org)
+	if !ok {
+		return
+	}
+	if userID != uint(c.GetInt("user_id")) && !canManageMember(callerRole, current) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "insufficient organization role"})
+		return
+	}
+	if h.lastOwner(c, org, current) {
+		return
+	}
+
+	if err := h.db.RemoveOrganizationMember(org.ID, userID); err != nil {
+		log.Errorf("Failed to remove organization member: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to remove member"})
+		return
+	}
+
+	auditOrganization(c, audit.EventAuditOrganizationMemberRemoved,
+		fmt.Sprintf("Removed user %d from organization %s", userID, org.Name),
+		map[string]interface{}{"organization_id": org.ID, "user_id": userID, "role": current})
+	c.JSON(http.StatusOK, gin.H{"message": "member removed"})
+}
+
+// MoveClusterToOrganization gives a cluster to an organization (admin only)
+func (h *Handler) MoveClusterToOrganization(c *gin.Context) {
+	org, _, ok := h.organizationParam(c)
+	if !ok {
+		return
+	}
+	name := c.Param("name")
+	if err := h.db.MoveCluster(name, org.ID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	auditOrganization(c, audit.EventAuditOrganizationClusterMoved,
+		fmt.Sprintf("Moved cluster %s to organization %s", name, org.Name),
+		map[string]interface{}{"organization_id": org.ID, "cluster": name})
+	c.JSON(http.StatusOK, gin.H{"message": "cluster moved", "cluster": name, "organization_id": org.ID})
+}
+
+// SwitchOrganization moves the caller's session to another organization and returns an
+// access token for it. The refresh token keeps working and follows the session.
+func (h *Handler) SwitchOrganization(c *gin.Context) {
+	var req struct {
+		OrganizationID uint `json:"organization_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	sessionID := c.GetUint("session_id")
+	if sessionID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "only signed-in sessions can switch organizations"})
+		return
+	}
+	user, ok := c.MustGet("user").(*db.User)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "not authenticated"})
+		return
+	}
+	org, err := h.db.GetOrganization(req.OrganizationID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "organization not found"})
+		return
+	}
+	role, err := h.callerRole(c, org.ID)
+	if err != nil {
+		log.Errorf("Failed to get organization role: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check organization membership"})
+		return
+	}
+	if role == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "organization not found"})
+		return
+	}
+
+	if err := h.db.SetSessionOrganization(sessionID, org.ID); err != nil {
+		log.Errorf("Failed to switch organization of session %d: %v", sessionID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to switch organization"})
+		return
+	}
+	token, err := GenerateSessionToken(int(user.ID), user.Email, user.Username, user.IsAdmin, h.secret, h.accessTokenTTL, sessionID, org.ID)
+	if err != nil {
+		log.Errorf("Failed to generate token: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate token"})
+		return
+	}
+
+	c.Set("org_id", org.ID)
+	audit.Log(c, audit.EventAuthOrganizationSwitched, int(user.ID), user.Username, user.Email,
+		fmt.Sprintf("Switched to organization %s", org.Name),
+		map[string]interface{}{"organization_id": org.ID, "session_id": sessionID})
+
+	c.JSON(http.StatusOK, gin.H{
+		"token":        token,
+		"expires_in":   int(h.accessTokenTTL.Seconds()),
+		"organization": organizationView{Organization: org, Role: role},
+	})
+}
@@ -0,0 +1,218 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+const (
+	// passwordPolicyConfigKey is the system config key the password policy is stored under
+	passwordPolicyConfigKey = "password_policy"
+	// maxPasswordLength is the longest password accepted regardless of the policy
+	maxPasswordLength = 128
+	// minPolicyLength is the lowest minimum length an administrator can configure
+	minPolicyLength = 6
+	// maxPasswordHistory caps how many previous passwords are remembered
+	maxPasswordHistory = 24
+)
+
+// errPasswordExpired is returned when a local password is older than the policy allows
+var errPasswordExpired = errors.New("password has expired")
+
+// PasswordPolicy is the set of rules local passwords must follow
+type PasswordPolicy struct {
+	MinLength        int  `json:"min_length"`
+	RequireUppercase bool `json:"require_uppercase"`
+	RequireLowercase bool `json:"require_lowercase"`
+	RequireDigit     bool `json:"require_digit"`
+	RequireSymbol    bool `json:"require_symbol"`
+
+	// HistoryCount is the number of previous passwords that cannot be reused; 0 disables
+	HistoryCount int `json:"history_count"`
+	// MaxAgeDays is how long a password is valid before it must be changed; 0 disables
+	MaxAgeDays int `json:"max_age_days"`
+
+	// BannedPasswords are rejected regardless of complexity (case-insensitive)
+	BannedPasswords []string `json:"banned_passwords"`
+	// DisallowUserInfo rejects passwords containing the username or the local part of the email
+	DisallowUserInfo bool `json:"disallow_user_info"`
+}
+
+// DefaultPasswordPolicy returns the policy used until an administrator changes it. It
+// matches the rules kubelens always enforced.
+func DefaultPasswordPolicy() PasswordPolicy {
+	return PasswordPolicy{
+		MinLength:        8,
+		RequireUppercase: true,
+		RequireLowercase: true,
+		RequireDigit:     true,
+		RequireSymbol:    true,
+		BannedPasswords: []string{
+			"P@ssw0rd", "P@ssword1", "Passw0rd!", "Password1!", "Password123!",
+			"Welcome1!", "Welcome123!", "Admin123!", "Qwerty123!", "Changeme1!",
+		},
+	}
+}
+
+// Validate checks that the policy settings are within bounds
+func (p *PasswordPolicy) Validate() error {
+	if p.MinLength < minPolicyLength || p.MinLength > maxPasswordLength {
+		return fmt.Errorf("min_length must be between %d and %d", minPolicyLength, maxPasswordLength)
+	}
+	if p.HistoryCount < 0 || p.HistoryCount > maxPasswordHistory {
+		return fmt.Errorf("history_count must be between 0 and %d", maxPasswordHistory)
+	}
+	if p.MaxAgeDays < 0 || p.MaxAgeDays > 3650 {
+		return fmt.Errorf("max_age_days must be between 0 and 3650")
+	}
+	return nil
+}
+
+// Check returns the rules a password breaks, or nil if it is acceptable. The user is
+// optional and only used for DisallowUserInfo.
+func (p *PasswordPolicy) Check(password string, user *db.User) []string {
+	var problems []string
+
+	length := len([]rune(password))
+	if length < p.MinLength {
+		problems = append(problems, fmt.Sprintf("be at least %d characters long", p.MinLength))
+	}
+	if length > maxPasswordLength {
+		problems = append(problems, fmt.Sprintf("not exceed %d characters", maxPasswordLength))
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case !unicode.IsLetter(r) && !unicode.IsSpace(r):
+			hasSymbol = true
+		}
+	}
+	if p.RequireUppercase && !hasUpper {
+		problems = append(problems, "contain an uppercase letter")
+	}
+	if p.RequireLowercase && !hasLower {
+		problems = append(problems, "contain a lowercase letter")
+	}
+	if p.RequireDigit && !hasDigit {
+		problems = append(problems, "contain a number")
+	}
+	if p.RequireSymbol && !hasSymbol {
+		problems = append(problems, "contain a special character")
+	}
+
+	for _, banned := range p.BannedPasswords {
+		if strings.EqualFold(password, banned) {
+			problems = append(problems, "not be a commonly used password")
+			break
+		}
+	}
+
+	if p.DisallowUserInfo && user != nil {
+		lower := strings.ToLower(password)
+		local, _, _ := strings.Cut(user.Email, "@")
+		for _, info := range []string{user.Username, local} {
+			if len(info) >= 3 && strings.Contains(lower, strings.ToLower(info)) {
+				problems = append(problems, "not contain your username or email")
+				break
+			}
+		}
+	}
+
+	return problems
+}
+
+// Expired reports whether the password of a local user is older than MaxAgeDays
+func (p *PasswordPolicy) Expired(user *db.User) bool {
+	if p.MaxAgeDays <= 0 || user.AuthProvider != "local" {
+		return false
+	}
+	changedAt := user.CreatedAt
+	if user.PasswordChangedAt != nil {
+		changedAt = *user.PasswordChangedAt
+	}
+	return time.Since(changedAt) > time.Duration(p.MaxAgeDays)*24*time.Hour
+}
+
+// loadPasswordPolicy returns the stored password policy, or the default one
+func (h *Handler) loadPasswordPolicy() PasswordPolicy {
+	policy := DefaultPasswordPolicy()
+	value, err := h.db.GetSystemConfig(passwordPolicyConfigKey)
+	if err != nil || value == "" {
+		return policy
+	}
+	if err := json.Unmarshal([]byte(value), &policy); err != nil {
+		log.Errorf("Invalid stored password policy, using the default: %v", err)
+		return DefaultPasswordPolicy()
+	}
+	return policy
+}
+
+// savePasswordPolicy stores the password policy
+func (h *Handler) savePasswordPolicy(policy PasswordPolicy) error {
+	value, err := json.Marshal(policy)
+	if err != nil {
+		return err
+	}
+	return h.db.SetSystemConfig(passwordPolicyConfigKey, string(value))
+}
+
+// checkNewPassword validates a new password against the policy and, for an existing user,
+// against their previous passwords. The returned error is meant for the client.
+func (h *Handler) checkNewPassword(policy PasswordPolicy, password string, user *db.User) error {
+	if problems := policy.Check(password, user); len(problems) > 0 {
+		return fmt.Errorf("password must %s", strings.Join(problems, ", "))
+	}
+
+	if user == nil || user.ID == 0 || policy.HistoryCount <= 0 {
+		return nil
+	}
+	if user.PasswordHash != "" && CheckPassword(password, user.PasswordHash) {
+		return errors.New("new password must be different from the current password")
+	}
+	history, err := h.db.GetPasswordHistory(user.ID, policy.HistoryCount)
+	if err != nil {
+		log.Warnf("Failed to load password history of user %d: %v", user.ID, err)
+		return nil
+	}
+	for _, hash := range history {
+		if CheckPassword(password, hash) {
+			return fmt.Errorf("password must not match any of your last %d passwords", policy.HistoryCount)
+		}
+	}
+	return nil
+}
+
+// setPassword hashes and sets a new password on a user. Call recordPassword once the user
+// is saved.
+func setPassword(user *db.User, password string) error {
+	hash, err := HashPassword(password)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	user.PasswordHash = hash
+	user.PasswordChangedAt = &now
+	return nil
+}
+
+// recordPassword adds the user's current password to their history
+func (h *Handler) recordPassword(policy PasswordPolicy, user *db.User) {
+	if err := h.db.AddPasswordHistory(user.ID, user.PasswordHash, policy.HistoryCount); err != nil {
+		log.Warnf("Failed to record password history of user %d: %v", user.ID, err)
+	}
+}
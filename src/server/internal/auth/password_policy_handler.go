@@ -0,0 +1,123 @@
+package auth
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/sonnguyen/kubelens/internal/audit"
+	"github.com/sonnguyen/kubelens/internal/middleware"
+)
+
+// GetPasswordPolicy handles GET /api/v1/system/password-policy
+func (h *Handler) GetPasswordPolicy(c *gin.Context) {
+	c.JSON(http.StatusOK, h.loadPasswordPolicy())
+}
+
+// UpdatePasswordPolicy handles PUT /api/v1/system/password-policy. The policy applies to
+// passwords set from now on; existing passwords only expire under the new max_age_days.
+func (h *Handler) UpdatePasswordPolicy(c *gin.Context) {
+	var req PasswordPolicy
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.BannedPasswords == nil {
+		req.BannedPasswords = []string{}
+	}
+
+	if err := h.savePasswordPolicy(req); err != nil {
+		log.Errorf("Failed to save password policy: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save password policy"})
+		return
+	}
+
+	log.Infof("Password policy updated (min length: %d, history: %d, max age: %d days)",
+		req.MinLength, req.HistoryCount, req.MaxAgeDays)
+
+	// Audit log
+	if userID, exists := c.Get("user_id"); exists {
+		username, _ := c.Get("username")
+		email, _ := c.Get("email")
+		audit.Log(c, audit.EventAuditConfigChanged, userID.(int), username.(string), email.(string),
+			"Updated password policy",
+			map[string]interface{}{
+				"min_length":       req.MinLength,
+				"history_count":    req.HistoryCount,
+				"max_age_days":     req.MaxAgeDays,
+				"banned_passwords": len(req.BannedPasswords),
+			})
+	}
+
+	c.JSON(http.StatusOK, req)
+}
+
+// ChangeExpiredPassword handles POST /api/v1/auth/password/expired. Sign-in is refused
+// once a password has expired, so the user proves the old password here instead of
+// with a session, then signs in again with the new one.
+func (h *Handler) ChangeExpiredPassword(c *gin.Context) {
+	var req struct {
+		Email           string `json:"email" binding:"required"`
+		CurrentPassword string `json:"current_password" binding:"required"`
+		NewPassword     string `json:"new_password" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Email = middleware.SanitizeString(req.Email)
+
+	// Share the sign-in lockout so this cannot be used to guess passwords
+	lockIdentifier := req.Email + ":" + c.ClientIP()
+	if locked, _ := h.accountLockout.IsLocked(lockIdentifier); locked {
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "account temporarily locked due to too many failed attempts"})
+		return
+	}
+
+	user, err := h.db.GetUserByEmail(req.Email)
+	if err != nil || user.AuthProvider != "local" || !CheckPassword(req.CurrentPassword, user.PasswordHash) {
+		h.accountLockout.RecordFailedAttempt(lockIdentifier)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"})
+		return
+	}
+	if !user.IsActive {
+		c.JSON(http.StatusForbidden, gin.H{"error": "account is disabled"})
+		return
+	}
+
+	policy := h.loadPasswordPolicy()
+	if !policy.Expired(user) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "password has not expired; sign in and change it from your profile"})
+		return
+	}
+	if err := h.checkNewPassword(policy, req.NewPassword, user); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := setPassword(user, req.NewPassword); err != nil {
+		log.Errorf("Failed to hash password: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update password"})
+		return
+	}
+	if err := h.db.UpdateUser(user); err != nil {
+		log.Errorf("Failed to update user password: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update password"})
+		return
+	}
+	h.recordPassword(policy, user)
+	h.accountLockout.ResetAttempts(lockIdentifier)
+
+	log.Infof("User %s changed an expired password", user.Email)
+
+	uid := int(user.ID)
+	h.auditLogger.LogAuth(audit.EventAuthPasswordChange, &uid, user.Username, user.Email, c.ClientIP(),
+		"Changed expired password", true)
+
+	c.JSON(http.StatusOK, gin.H{"message": "password updated successfully"})
+}
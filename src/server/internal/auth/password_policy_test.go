@@ -0,0 +1,141 @@
+package auth
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/sonnguyen/kubelens/internal/audit"
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+func TestPasswordPolicyCheck(t *testing.T) {
+	policy := DefaultPasswordPolicy()
+	policy.DisallowUserInfo = true
+	user := &db.User{Username: "jdoe", Email: "jane.doe@example.com"}
+
+	tests := []struct {
+		name     string
+		password string
+		want     string // substring of a problem; empty for a valid password
+	}{
+		{"valid", "Tr0ub4dor&3x", ""},
+		{"too short", "Ab1!", "at least 8 characters"},
+		{"no uppercase", "tr0ub4dor&3x", "uppercase"},
+		{"no lowercase", "TR0UB4DOR&3X", "lowercase"},
+		{"no digit", "Troubador&xx", "number"},
+		{"no symbol", "Tr0ub4dor3xx", "special character"},
+		{"banned", "p@ssw0rd", "commonly used"},
+		{"contains username", "Jdoe-2024!x", "username"},
+		{"contains email", "Jane.Doe99!", "username"},
+		{"too long", "Aa1!" + strings.Repeat("x", maxPasswordLength), "exceed"},
+	}
+	for _, tt := range tests {
+		problems := strings.Join(policy.Check(tt.password, user), "; ")
+		if tt.want == "" && problems != "" {
+			t.Errorf("%s: Check(%q) = %q, want no problems", tt.name, tt.password, problems)
+		}
+		if tt.want != "" && !strings.Contains(problems, tt.want) {
+			t.Errorf("%s: Check(%q) = %q, want a problem containing %q", tt.name, tt.password, problems, tt.want)
+		}
+	}
+
+	relaxed := PasswordPolicy{MinLength: 6}
+	if problems := relaxed.Check("simple", nil); len(problems) != 0 {
+		t.Errorf("relaxed policy Check() = %v, want no problems", problems)
+	}
+}
+
+func TestPasswordPolicyValidate(t *testing.T) {
+	valid := DefaultPasswordPolicy()
+	if err := valid.Validate(); err != nil {
+		t.Errorf("default policy Validate() error = %v", err)
+	}
+	for _, policy := range []PasswordPolicy{
+		{MinLength: 4},
+		{MinLength: 8, HistoryCount: -1},
+		{MinLength: 8, HistoryCount: maxPasswordHistory + 1},
+		{MinLength: 8, MaxAgeDays: -1},
+	} {
+		if err := policy.Validate(); err == nil {
+			t.Errorf("Validate(%+v) succeeded, want error", policy)
+		}
+	}
+}
+
+func TestPasswordPolicyExpired(t *testing.T) {
+	policy := PasswordPolicy{MinLength: 8, MaxAgeDays: 90}
+	old := time.Now().Add(-91 * 24 * time.Hour)
+	recent := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name string
+		user db.User
+		want bool
+	}{
+		{"recently changed", db.User{AuthProvider: "local", PasswordChangedAt: &recent, CreatedAt: old}, false},
+		{"changed long ago", db.User{AuthProvider: "local", PasswordChangedAt: &old}, true},
+		{"never changed, old account", db.User{AuthProvider: "local", CreatedAt: old}, true},
+		{"external provider", db.User{AuthProvider: "oidc", PasswordChangedAt: &old}, false},
+	}
+	for _, tt := range tests {
+		if got := policy.Expired(&tt.user); got != tt.want {
+			t.Errorf("%s: Expired() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	policy.MaxAgeDays = 0
+	if policy.Expired(&db.User{AuthProvider: "local", PasswordChangedAt: &old}) {
+		t.Error("Expired() = true with expiry disabled")
+	}
+}
+
+func TestPasswordHistory(t *testing.T) {
+	log.SetLevel(log.WarnLevel)
+
+	database, err := db.New(filepath.Join(t.TempDir(), "kubelens.db"))
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	h := NewHandler(database, "test-secret", audit.NewLogger(database))
+	policy := DefaultPasswordPolicy()
+	policy.HistoryCount = 2
+
+	user := &db.User{Email: "jdoe@example.com", Username: "jdoe", AuthProvider: "local", IsActive: true}
+	passwords := []string{"First-pass1", "Second-pass2", "Third-pass3"}
+	for i, password := range passwords {
+		if err := h.checkNewPassword(policy, password, user); err != nil {
+			t.Fatalf("checkNewPassword(%q) error = %v", password, err)
+		}
+		if err := setPassword(user, password); err != nil {
+			t.Fatalf("setPassword() error = %v", err)
+		}
+		if i == 0 {
+			err = database.CreateUser(user)
+		} else {
+			err = database.UpdateUser(user)
+		}
+		if err != nil {
+			t.Fatalf("failed to save user: %v", err)
+		}
+		h.recordPassword(policy, user)
+	}
+
+	if err := h.checkNewPassword(policy, "Third-pass3", user); err == nil {
+		t.Error("checkNewPassword() accepted the current password")
+	}
+	if err := h.checkNewPassword(policy, "Second-pass2", user); err == nil {
+		t.Error("checkNewPassword() accepted a password within the history")
+	}
+	if err := h.checkNewPassword(policy, "First-pass1", user); err != nil {
+		t.Errorf("checkNewPassword() rejected a password older than the history: %v", err)
+	}
+
+	if history, _ := database.GetPasswordHistory(user.ID, 10); len(history) != 2 {
+		t.Errorf("password history has %d entries, want 2", len(history))
+	}
+}
@@ -0,0 +1,174 @@
+package auth
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/sonnguyen/kubelens/internal/audit"
+	"github.com/sonnguyen/kubelens/internal/db"
+	"github.com/sonnguyen/kubelens/internal/mail"
+)
+
+// passwordResetTTL is how long an emailed password reset link stays valid
+const passwordResetTTL = 24 * time.Hour
+
+// SendPasswordResetEmail handles POST /api/v1/users/:id/reset-password/email. It emails a
+// local user a single-use link to choose a new password, so admins no longer have to
+// hand out temporary passwords (admin only).
+func (h *Handler) SendPasswordResetEmail(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	user, err := h.db.GetUserByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+	if user.AuthProvider != "local" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "can only reset password for local authentication users"})
+		return
+	}
+	if !user.IsActive {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "account is disabled"})
+		return
+	}
+
+	token, tokenHash, err := generateInvitationToken()
+	if err != nil {
+		log.Errorf("Failed to generate password reset token: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create password reset link"})
+		return
+	}
+	resetToken := &db.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: tokenHash,
+		ExpiresAt: time.Now().Add(passwordResetTTL),
+	}
+	if userID, exists := c.Get("user_id"); exists {
+		resetToken.RequestedBy = uint(userID.(int))
+	}
+	if err := h.db.CreatePasswordResetToken(resetToken); err != nil {
+		log.Errorf("Failed to create password reset token: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create password reset link"})
+		return
+	}
+
+	link := fmt.Sprintf("%s/reset-password?token=%s", h.publicURL, token)
+	if !h.sendMail(c, &mail.Message{
+		To:      []string{user.Email},
+		Subject: "Reset your kubelens password",
+		Body: fmt.Sprintf("Hello %s,\n\nAn administrator requested a password reset for your kubelens account.\n"+
+			"Choose a new password here:\n\n%s\n\nThe link can be used once and expires on %s.\n",
+			user.Username, link, resetToken.ExpiresAt.UTC().Format(time.RFC1123)),
+	}) {
+		return
+	}
+
+	log.Infof("Password reset email sent to user %d", user.ID)
+
+	// Audit log
+	if userID, exists := c.Get("user_id"); exists {
+		username, _ := c.Get("username")
+		email, _ := c.Get("email")
+		audit.Log(c, audit.EventPasswordResetRequested, userID.(int), username.(string), email.(string),
+			fmt.Sprintf("Sent password reset email to: %s", user.Email),
+			map[string]interface{}{
+				"target_user_id": user.ID,
+				"target_email":   user.Email,
+				"expires_at":     resetToken.ExpiresAt,
+			})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "password reset email sent", "expires_at": resetToken.ExpiresAt})
+}
+
+// GetPasswordReset validates a password reset token and returns whose it is (public)
+func (h *Handler) GetPasswordReset(c *gin.Context) {
+	token, user, ok := h.passwordResetUser(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"email":      user.Email,
+		"username":   user.Username,
+		"expires_at": token.ExpiresAt,
+	})
+}
+
+// CompletePasswordReset redeems a password reset token, setting the new password and
+// signing the user out everywhere (public)
+func (h *Handler) CompletePasswordReset(c *gin.Context) {
+	var req struct {
+		NewPassword string `json:"new_password" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	token, user, ok := h.passwordResetUser(c)
+	if !ok {
+		return
+	}
+
+	policy := h.loadPasswordPolicy()
+	if err := h.checkNewPassword(policy, req.NewPassword, user); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := setPassword(user, req.NewPassword); err != nil {
+		log.Errorf("Failed to hash password: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to reset password"})
+		return
+	}
+
+	// Claim the token before updating the user so concurrent redemptions fail
+	if err := h.db.UsePasswordResetToken(token.ID); err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.db.UpdateUser(user); err != nil {
+		log.Errorf("Failed to update user password: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to reset password"})
+		return
+	}
+	h.recordPassword(policy, user)
+	h.accountLockout.ResetAttempts(user.Email + ":" + c.ClientIP())
+
+	if err := h.db.RevokeUserSessions(user.ID, 0); err != nil {
+		log.Warnf("Failed to revoke sessions of user %d: %v", user.ID, err)
+	}
+
+	log.Infof("User %s reset their password", user.Email)
+
+	uid := int(user.ID)
+	h.auditLogger.LogAuth(audit.EventPasswordResetCompleted, &uid, user.Username, user.Email, c.ClientIP(),
+		"Reset password from emailed link", true)
+
+	c.JSON(http.StatusOK, gin.H{"message": "password reset successfully"})
+}
+
+// passwordResetUser loads the pending token in the route and its user, writing the error
+// response when either is invalid
+func (h *Handler) passwordResetUser(c *gin.Context) (*db.PasswordResetToken, *db.User, bool) {
+	token, err := h.db.GetPendingPasswordResetToken(hashInvitationToken(c.Param("token")))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "password reset link is invalid or has expired"})
+		return nil, nil, false
+	}
+	user, err := h.db.GetUserByID(token.UserID)
+	if err != nil || !user.IsActive || user.AuthProvider != "local" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "password reset link is invalid or has expired"})
+		return nil, nil, false
+	}
+	return token, user, true
+}
@@ -19,6 +19,21 @@ func (h *Handler) PermissionChecker(resource string, action string) gin.HandlerF
 			return
 		}
 
+		// A scoped API token must grant the permission, whoever owns it
+		if scoped, ok := c.Get("token_permissions"); ok {
+			if !hasPermission(scoped.([]db.Permission), resource, action) {
+				c.JSON(http.StatusForbidden, gin.H{
+					"error": "API token does not grant this permission",
+					"required": gin.H{
+						"resource": resource,
+						"action":   action,
+					},
+				})
+				c.Abort()
+				return
+			}
+		}
+
 		// Check if user is admin (admins bypass all permission checks)
 		isAdmin, _ := c.Get("is_admin")
 		if isAdmin.(bool) {
@@ -26,8 +41,14 @@ func (h *Handler) PermissionChecker(resource string, action string) gin.HandlerF
 			return
 		}
 
-		// Get user permissions
-		permissions, err := h.db.GetUserPermissions(uint(uint(userID.(int))))
+		// Organization owners and admins may do anything within their organization
+		if isOrganizationAdmin(c) && !instanceWide(resource, action) {
+			c.Next()
+			return
+		}
+
+		// Get user permissions in the organization
+		permissions, err := h.userPermissions(c)
 		if err != nil {
 			log.Errorf("Failed to get user permissions: %v", err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check permissions"})
@@ -94,13 +115,13 @@ func (h *Handler) ClusterScopeChecker() gin.HandlerFunc {
 
 		// Check if user is admin (admins have access to all clusters)
 		isAdmin, _ := c.Get("is_admin")
-		if isAdmin.(bool) {
+		if isAdmin.(bool) || isOrganizationAdmin(c) {
 			c.Next()
 			return
 		}
 
-		// Get user permissions
-		permissions, err := h.db.GetUserPermissions(uint(uint(userID.(int))))
+		// Get user permissions in the organization
+		permissions, err := h.userPermissions(c)
 		if err != nil {
 			log.Errorf("Failed to get user permissions: %v", err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check permissions"})
@@ -126,18 +147,10 @@ func (h *Handler) ClusterScopeChecker() gin.HandlerFunc {
 // hasClusterAccess checks if the user has access to the specified cluster
 func hasClusterAccess(permissions []db.Permission, cluster string) bool {
 	for _, perm := range permissions {
-		// Check if permission has cluster scope
-		if len(perm.Clusters) == 0 {
-			// No cluster restriction means access to all clusters
+		// No cluster restriction means access to all clusters
+		if matchesScope(perm.Clusters, cluster) {
 			return true
 		}
-
-		// Check for wildcard or exact match
-		for _, c := range perm.Clusters {
-			if c == "*" || c == cluster {
-				return true
-			}
-		}
 	}
 	return false
 }
@@ -167,13 +180,13 @@ func (h *Handler) NamespaceScopeChecker() gin.HandlerFunc {
 
 		// Check if user is admin (admins have access to all namespaces)
 		isAdmin, _ := c.Get("is_admin")
-		if isAdmin.(bool) {
+		if isAdmin.(bool) || isOrganizationAdmin(c) {
 			c.Next()
 			return
 		}
 
-		// Get user permissions
-		permissions, err := h.db.GetUserPermissions(uint(uint(userID.(int))))
+		// Get user permissions in the organization
+		permissions, err := h.userPermissions(c)
 		if err != nil {
 			log.Errorf("Failed to get user permissions: %v", err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check permissions"})
@@ -199,18 +212,10 @@ func (h *Handler) NamespaceScopeChecker() gin.HandlerFunc {
 // hasNamespaceAccess checks if the user has access to the specified namespace
 func hasNamespaceAccess(permissions []db.Permission, namespace string) bool {
 	for _, perm := range permissions {
-		// Check if permission has namespace scope
-		if len(perm.Namespaces) == 0 {
-			// No namespace restriction means access to all namespaces
+		// No namespace restriction means access to all namespaces
+		if matchesScope(perm.Namespaces, namespace) {
 			return true
 		}
-
-		// Check for wildcard or exact match
-		for _, ns := range perm.Namespaces {
-			if ns == "*" || ns == namespace {
-				return true
-			}
-		}
 	}
 	return false
 }
@@ -223,8 +228,8 @@ func (h *Handler) GetUserPermissionsHandler(c *gin.Context) {
 		return
 	}
 
-	// Get user permissions
-	permissions, err := h.db.GetUserPermissions(uint(userID.(int)))
+	// Get user permissions in the organization
+	permissions, err := h.userPermissions(c)
 	if err != nil {
 		log.Errorf("Failed to get user permissions: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get permissions"})
@@ -238,11 +243,19 @@ func (h *Handler) GetUserPermissionsHandler(c *gin.Context) {
 		// Don't fail, just return permissions without groups
 		groups = []db.Group{}
 	}
+	orgGroups := []db.Group{}
+	for _, group := range groups {
+		if group.OrganizationID == organizationID(c) {
+			orgGroups = append(orgGroups, group)
+		}
+	}
 
 	// Build response
 	response := gin.H{
-		"permissions": permissions,
-		"groups":      groups,
+		"permissions":       permissions,
+		"groups":            orgGroups,
+		"organization_id":   organizationID(c),
+		"organization_role": c.GetString("org_role"),
 	}
 
 	// Add accessible resources summary
@@ -348,17 +361,7 @@ func (h *Handler) GetUserAllowedNamespaces(userID int, cluster string) ([]string
 	namespaceMap := make(map[string]bool)
 	for _, perm := range permissions {
 		// Check if permission applies to this cluster
-		hasClusterAccess := len(perm.Clusters) == 0 // No restriction
-		if !hasClusterAccess {
-			for _, c := range perm.Clusters {
-				if c == "*" || c == cluster {
-					hasClusterAccess = true
-					break
-				}
-			}
-		}
-
-		if hasClusterAccess {
+		if matchesScope(perm.Clusters, cluster) {
 			if len(perm.Namespaces) == 0 {
 				// No restriction means all namespaces
 				return []string{"*"}, nil
@@ -0,0 +1,254 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	"github.com/sonnguyen/kubelens/internal/audit"
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+const (
+	// defaultAccessTokenTTL is how long an access JWT issued at sign-in is valid
+	defaultAccessTokenTTL = 15 * time.Minute
+	// defaultRefreshTokenTTL is how long a refresh token can be used to get a new access token
+	defaultRefreshTokenTTL = 30 * 24 * time.Hour
+)
+
+// SetTokenTTLs sets the lifetimes of access and refresh tokens; zero keeps the default
+func (h *Handler) SetTokenTTLs(access, refresh time.Duration) {
+	if access > 0 {
+		h.accessTokenTTL = access
+	}
+	if refresh > 0 {
+		h.refreshTokenTTL = refresh
+	}
+}
+
+// tokenPair is an access token and the refresh token to renew it, as returned to clients
+type tokenPair struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresIn    int // seconds until the access token expires
+}
+
+// generateRefreshToken returns a random refresh token and its SHA-256 hash
+func generateRefreshToken() (string, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	token := hex.EncodeToString(b)
+	return token, hashRefreshToken(token), nil
+}
+
+// hashRefreshToken hashes a refresh token for storage and lookup
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// newRefreshToken builds the row of a new refresh token in a family ("" starts a new one)
+func (h *Handler) newRefreshToken(c *gin.Context, userID uint, familyID string) (*db.RefreshToken, string, error) {
+	rawToken, tokenHash, err := generateRefreshToken()
+	if err != nil {
+		return nil, "", err
+	}
+	if familyID == "" {
+		// The hash of the first token is as unique as the token and names the sign-in
+		familyID = tokenHash[:32]
+	}
+
+	return &db.RefreshToken{
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: tokenHash,
+		ExpiresAt: time.Now().Add(h.refreshTokenTTL),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}, rawToken, nil
+}
+
+// issueTokens signs a user in: a new session, its refresh token family and a short-lived
+// access token bound to the session
+func (h *Handler) issueTokens(c *gin.Context, user *db.User) (*tokenPair, error) {
+	refresh, rawToken, err := h.newRefreshToken(c, user.ID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	// Sign-ins start in the user's default organization
+	orgID, err := h.db.DefaultOrganizationOf(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if orgID == 0 {
+		orgID = db.DefaultOrganizationID
+	}
+
+	now := time.Now()
+	session := &db.Session{
+		UserID:         user.ID,
+		OrganizationID: orgID,
+		Token:          refresh.FamilyID,
+		ExpiresAt:      refresh.ExpiresAt,
+		IPAddress:      refresh.IPAddress,
+		UserAgent:      refresh.UserAgent,
+		LastSeenAt:     &now,
+	}
+	if err := h.db.CreateSession(session); err != nil {
+		return nil, err
+	}
+	if err := h.db.CreateRefreshToken(refresh); err != nil {
+		return nil, err
+	}
+
+	accessToken, err := GenerateSessionToken(int(user.ID), user.Email, user.Username, user.IsAdmin, h.secret, h.accessTokenTTL, session.ID, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: rawToken,
+		ExpiresIn:    int(h.accessTokenTTL.Seconds()),
+	}, nil
+}
+
+// RefreshToken exchanges a refresh token for a new access token and a new refresh token.
+// The presented token is revoked; presenting it again revokes every token of its sign-in,
+// since only a stolen copy would still be in use.
+func (h *Handler) RefreshToken(c *gin.Context) {
+	var req struct {
+		RefreshToken string `json:"refresh_token" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	current, err := h.db.GetRefreshTokenByHash(hashRefreshToken(req.RefreshToken))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid refresh token"})
+		return
+	}
+
+	session, err := h.db.GetSessionWithoutExpiry(current.FamilyID)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid refresh token"})
+		return
+	}
+
+	if current.RevokedAt != nil {
+		if current.ReplacedBy != nil && session.RevokedAt == nil {
+			// A rotated token was replayed: end the whole sign-in
+			log.Warnf("Reuse of rotated refresh token %d for user %d from IP %s, revoking its session", current.ID, current.UserID, c.ClientIP())
+			if err := h.db.RevokeSession(session.ID); err != nil {
+				log.Errorf("Failed to revoke session: %v", err)
+			}
+			userID := int(current.UserID)
+			h.auditLogger.LogAuth(audit.EventInvalidToken, &userID, "", "", c.ClientIP(),
+				"Reuse of a rotated refresh token, all tokens of the session revoked", false)
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid refresh token"})
+		return
+	}
+	if time.Now().After(current.ExpiresAt) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "refresh token expired"})
+		return
+	}
+
+	user, err := h.db.GetUserByID(current.UserID)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not found"})
+		return
+	}
+	if !user.IsActive {
+		h.db.RevokeUserSessions(user.ID, 0)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "account is disabled"})
+		return
+	}
+
+	orgID, err := h.sessionOrganization(user, session)
+	if err != nil {
+		log.Errorf("Failed to check the organization of session %d: %v", session.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate token"})
+		return
+	}
+
+	accessToken, err := GenerateSessionToken(int(user.ID), user.Email, user.Username, user.IsAdmin, h.secret, h.accessTokenTTL, session.ID, orgID)
+	if err != nil {
+		log.Errorf("Failed to generate token: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate token"})
+		return
+	}
+
+	next, rawToken, err := h.newRefreshToken(c, user.ID, current.FamilyID)
+	if err != nil {
+		log.Errorf("Failed to generate refresh token: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate token"})
+		return
+	}
+	if err := h.db.RotateRefreshToken(current.ID, next); err != nil {
+		// Lost a race with another refresh of the same token
+		log.Warnf("Failed to rotate refresh token %d: %v", current.ID, err)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid refresh token"})
+		return
+	}
+	if err := h.db.ExtendSession(session.ID, next.ExpiresAt, c.ClientIP()); err != nil {
+		log.Warnf("Failed to extend session %d: %v", session.ID, err)
+	}
+
+	userID := int(user.ID)
+	h.auditLogger.LogAuth(audit.EventAuthTokenRefresh, &userID, user.Username, user.Email, c.ClientIP(),
+		"Access token refreshed", true)
+
+	c.JSON(http.StatusOK, gin.H{
+		"token":         accessToken,
+		"refresh_token": rawToken,
+		"expires_in":    int(h.accessTokenTTL.Seconds()),
+	})
+}
+
+// revokeRefreshTokenOf ends the session of a refresh token presented by its owner, ignoring
+// unknown tokens and tokens of other users
+func (h *Handler) revokeRefreshTokenOf(userID uint, rawToken string) error {
+	token, err := h.db.GetRefreshTokenByHash(hashRefreshToken(rawToken))
+	if err != nil || token.UserID != userID {
+		return nil
+	}
+	if session, err := h.db.GetSessionWithoutExpiry(token.FamilyID); err == nil {
+		err = h.db.RevokeSession(session.ID)
+		if err != nil {
+			return fmt.Errorf("failed to revoke session: %w", err)
+		}
+		return nil
+	}
+	if err := h.db.RevokeRefreshTokenFamily(token.FamilyID); err != nil {
+		return fmt.Errorf("failed to revoke refresh token: %w", err)
+	}
+	return nil
+}
+
+// CleanupExpiredSessions deletes sessions that have expired and refresh tokens that
+// expired more than a day ago
+func (h *Handler) CleanupExpiredSessions() {
+	deleted, err := h.db.DeleteExpiredRefreshTokens(time.Now().Add(-24 * time.Hour))
+	if err != nil {
+		log.Warnf("Failed to delete expired refresh tokens: %v", err)
+		return
+	}
+	if deleted > 0 {
+		log.Infof("Deleted %d expired refresh tokens", deleted)
+	}
+	if err := h.db.CleanExpiredSessions(); err != nil {
+		log.Warnf("Failed to delete expired sessions: %v", err)
+	}
+}
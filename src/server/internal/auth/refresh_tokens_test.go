@@ -0,0 +1,121 @@
+package auth
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	"github.com/sonnguyen/kubelens/internal/audit"
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+func TestRefreshTokenRotation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	log.SetLevel(log.WarnLevel)
+
+	database, err := db.New(filepath.Join(t.TempDir(), "kubelens.db"))
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	user, err := database.CreateInitialAdmin("admin", "admin@kubelens.local", "Administrator", "not-a-password-hash")
+	if err != nil {
+		t.Fatalf("failed to create test user: %v", err)
+	}
+
+	h := NewHandler(database, "test-secret", audit.NewLogger(database))
+	router := gin.New()
+	router.POST("/refresh", h.RefreshToken)
+
+	refresh := func(token string) (int, map[string]interface{}) {
+		body, _ := json.Marshal(gin.H{"refresh_token": token})
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/refresh", bytes.NewReader(body)))
+		var resp map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		return w.Code, resp
+	}
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/signin", nil)
+	tokens, err := h.issueTokens(c, user)
+	if err != nil {
+		t.Fatalf("issueTokens() error = %v", err)
+	}
+	if claims, err := ValidateToken(tokens.AccessToken, "test-secret"); err != nil || claims.UserID != int(user.ID) {
+		t.Fatalf("invalid access token: %v", err)
+	}
+
+	code, resp := refresh(tokens.RefreshToken)
+	if code != http.StatusOK {
+		t.Fatalf("refresh status = %d, want 200: %v", code, resp)
+	}
+	rotated, _ := resp["refresh_token"].(string)
+	if rotated == "" || rotated == tokens.RefreshToken {
+		t.Fatalf("expected a new refresh token, got %q", rotated)
+	}
+	if _, err := ValidateToken(resp["token"].(string), "test-secret"); err != nil {
+		t.Fatalf("invalid refreshed access token: %v", err)
+	}
+
+	// Replaying the rotated token is refused and ends the session, including the new token
+	if code, _ := refresh(tokens.RefreshToken); code != http.StatusUnauthorized {
+		t.Errorf("replayed refresh status = %d, want 401", code)
+	}
+	if code, _ := refresh(rotated); code != http.StatusUnauthorized {
+		t.Errorf("refresh after reuse status = %d, want 401", code)
+	}
+
+	if code, _ := refresh("not-a-token"); code != http.StatusUnauthorized {
+		t.Errorf("unknown token status = %d, want 401", code)
+	}
+}
+
+func TestOIDCSyncStartsSession(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	log.SetLevel(log.WarnLevel)
+
+	database, err := db.New(filepath.Join(t.TempDir(), "kubelens.db"))
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	h := NewHandler(database, "test-secret", audit.NewLogger(database))
+	router := gin.New()
+	router.POST("/oidc/sync", h.HandleOIDCSync)
+	router.POST("/refresh", h.RefreshToken)
+
+	body, _ := json.Marshal(OIDCSyncRequest{Subject: "1234", Email: "ada@example.com", EmailVerified: true, Name: "Ada", Provider: "github"})
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/oidc/sync", bytes.NewReader(body)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("sync status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	var resp OIDCSyncResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+
+	claims, err := ValidateToken(resp.SessionToken, "test-secret")
+	if err != nil {
+		t.Fatalf("invalid session token: %v", err)
+	}
+	if claims.SessionID == 0 {
+		t.Error("OIDC session token is not bound to a session")
+	}
+	if session, err := database.GetSessionByID(claims.SessionID); err != nil || session.UserID != resp.UserID {
+		t.Errorf("session of the OIDC token = %+v, %v; want one of user %d", session, err, resp.UserID)
+	}
+
+	body, _ = json.Marshal(gin.H{"refresh_token": resp.RefreshToken})
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/refresh", bytes.NewReader(body)))
+	if w.Code != http.StatusOK {
+		t.Errorf("refresh status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,276 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+// SCIM 2.0 schema URNs (RFC 7643, RFC 7644)
+const (
+	scimUserSchema         = "urn:ietf:params:scim:schemas:core:2.0:User"
+	scimGroupSchema        = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	scimListSchema         = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	scimPatchSchema        = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	scimErrorSchema        = "urn:ietf:params:scim:api:messages:2.0:Error"
+	scimServiceProviderURN = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+	scimResourceTypeURN    = "urn:ietf:params:scim:schemas:core:2.0:ResourceType"
+
+	// SCIMBasePath is where the SCIM server is mounted
+	SCIMBasePath = "/scim/v2"
+	// scimContentType is the media type of SCIM requests and responses
+	scimContentType = "application/scim+json"
+	// scimMaxResults caps the page size of list responses
+	scimMaxResults = 1000
+)
+
+type scimMeta struct {
+	ResourceType string    `json:"resourceType"`
+	Created      time.Time `json:"created"`
+	LastModified time.Time `json:"lastModified"`
+	Location     string    `json:"location"`
+}
+
+type scimName struct {
+	Formatted  string `json:"formatted,omitempty"`
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+}
+
+type scimEmail struct {
+	Value   string `json:"value"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+// scimRef is a member of a group or a group of a user
+type scimRef struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+	Ref     string `json:"$ref,omitempty"`
+}
+
+// scimUser is the SCIM User resource
+type scimUser struct {
+	Schemas     []string    `json:"schemas"`
+	ID          string      `json:"id,omitempty"`
+	ExternalID  string      `json:"externalId,omitempty"`
+	UserName    string      `json:"userName"`
+	Name        *scimName   `json:"name,omitempty"`
+	DisplayName string      `json:"displayName,omitempty"`
+	Emails      []scimEmail `json:"emails,omitempty"`
+	Active      *bool       `json:"active,omitempty"`
+	Groups      []scimRef   `json:"groups,omitempty"`
+	Meta        *scimMeta   `json:"meta,omitempty"`
+}
+
+// scimGroup is the SCIM Group resource
+type scimGroup struct {
+	Schemas     []string  `json:"schemas"`
+	ID          string    `json:"id,omitempty"`
+	ExternalID  string    `json:"externalId,omitempty"`
+	DisplayName string    `json:"displayName"`
+	Members     []scimRef `json:"members,omitempty"`
+	Meta        *scimMeta `json:"meta,omitempty"`
+}
+
+// scimListResponse is a page of resources
+type scimListResponse struct {
+	Schemas      []string    `json:"schemas"`
+	TotalResults int         `json:"totalResults"`
+	StartIndex   int         `json:"startIndex"`
+	ItemsPerPage int         `json:"itemsPerPage"`
+	Resources    interface{} `json:"Resources"`
+}
+
+// scimPatchOp is a PATCH request body
+type scimPatchOp struct {
+	Schemas    []string `json:"schemas"`
+	Operations []struct {
+		Op    string          `json:"op"`
+		Path  string          `json:"path"`
+		Value json.RawMessage `json:"value"`
+	} `json:"Operations"`
+}
+
+// scimError writes a SCIM error response
+func scimError(c *gin.Context, status int, scimType, detail string) {
+	body := gin.H{
+		"schemas": []string{scimErrorSchema},
+		"status":  strconv.Itoa(status),
+		"detail":  detail,
+	}
+	if scimType != "" {
+		body["scimType"] = scimType
+	}
+	scimJSON(c, status, body)
+}
+
+// scimJSON writes a response with the SCIM media type
+func scimJSON(c *gin.Context, status int, body interface{}) {
+	c.Header("Content-Type", scimContentType)
+	c.JSON(status, body)
+}
+
+// primaryEmail returns the primary (or first) email of a SCIM user
+func (u *scimUser) primaryEmail() string {
+	for _, email := range u.Emails {
+		if email.Primary {
+			return email.Value
+		}
+	}
+	if len(u.Emails) > 0 {
+		return u.Emails[0].Value
+	}
+	return ""
+}
+
+// fullName returns the display name of a SCIM user
+func (u *scimUser) fullName() string {
+	if u.Name != nil {
+		if u.Name.Formatted != "" {
+			return u.Name.Formatted
+		}
+		if name := strings.TrimSpace(u.Name.GivenName + " " + u.Name.FamilyName); name != "" {
+			return name
+		}
+	}
+	return u.DisplayName
+}
+
+// toSCIMUser renders a kubelens user. Kubelens signs users in by email, so the email is
+// the SCIM userName.
+func toSCIMUser(user *db.User, groups []db.Group) scimUser {
+	active := user.IsActive
+	resource := scimUser{
+		Schemas:     []string{scimUserSchema},
+		ID:          strconv.FormatUint(uint64(user.ID), 10),
+		ExternalID:  user.ExternalID,
+		UserName:    user.Email,
+		DisplayName: user.FullName,
+		Emails:      []scimEmail{{Value: user.Email, Type: "work", Primary: true}},
+		Active:      &active,
+		Meta: &scimMeta{
+			ResourceType: "User",
+			Created:      user.CreatedAt,
+			LastModified: user.UpdatedAt,
+			Location:     fmt.Sprintf("%s/Users/%d", SCIMBasePath, user.ID),
+		},
+	}
+	if user.FullName != "" {
+		resource.Name = &scimName{Formatted: user.FullName}
+	}
+	for _, group := range groups {
+		resource.Groups = append(resource.Groups, scimRef{
+			Value:   strconv.FormatUint(uint64(group.ID), 10),
+			Display: group.Name,
+			Ref:     fmt.Sprintf("%s/Groups/%d", SCIMBasePath, group.ID),
+		})
+	}
+	return resource
+}
+
+// toSCIMGroup renders a kubelens group with its members
+func toSCIMGroup(group *db.Group) scimGroup {
+	resource := scimGroup{
+		Schemas:     []string{scimGroupSchema},
+		ID:          strconv.FormatUint(uint64(group.ID), 10),
+		ExternalID:  group.ExternalID,
+		DisplayName: group.Name,
+		Meta: &scimMeta{
+			ResourceType: "Group",
+			Created:      group.CreatedAt,
+			LastModified: group.UpdatedAt,
+			Location:     fmt.Sprintf("%s/Groups/%d", SCIMBasePath, group.ID),
+		},
+	}
+	for _, user := range group.Users {
+		resource.Members = append(resource.Members, scimRef{
+			Value:   strconv.FormatUint(uint64(user.ID), 10),
+			Display: user.Email,
+			Ref:     fmt.Sprintf("%s/Users/%d", SCIMBasePath, user.ID),
+		})
+	}
+	return resource
+}
+
+// parseSCIMFilter parses the equality filters provisioning clients use to find existing
+// resources, e.g. `userName eq "jdoe@example.com"`. It returns the lower-cased attribute
+// and the value.
+func parseSCIMFilter(filter string) (string, string, error) {
+	filter = strings.TrimSpace(filter)
+	// The attribute may carry a value filter with spaces, e.g. emails[type eq "work"].value
+	attrEnd := 0
+	if open := strings.IndexByte(filter, '['); open >= 0 && open < strings.IndexByte(filter+" ", ' ') {
+		if close := strings.IndexByte(filter, ']'); close > open {
+			attrEnd = close
+		}
+	}
+	parts := strings.SplitN(filter[attrEnd:], " ", 3)
+	if len(parts) != 3 || !strings.EqualFold(parts[1], "eq") {
+		return "", "", fmt.Errorf("unsupported filter %q: only `attribute eq \"value\"` is supported", filter)
+	}
+	value := strings.TrimSpace(parts[2])
+	if unquoted, err := strconv.Unquote(value); err == nil {
+		value = unquoted
+	}
+	attr := strings.ToLower(filter[:attrEnd] + parts[0])
+	// emails[type eq "work"].value is matched as any email
+	if strings.HasPrefix(attr, "emails") {
+		attr = "emails.value"
+	}
+	return attr, value, nil
+}
+
+// parseSCIMBool reads a boolean PATCH value; some clients send "True"/"False" strings
+func parseSCIMBool(raw json.RawMessage) (bool, error) {
+	var b bool
+	if err := json.Unmarshal(raw, &b); err == nil {
+		return b, nil
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return false, fmt.Errorf("invalid boolean value %s", raw)
+	}
+	return strconv.ParseBool(strings.ToLower(s))
+}
+
+// parseSCIMID parses a resource ID from the path
+func parseSCIMID(id string) (uint, bool) {
+	n, err := strconv.ParseUint(id, 10, 32)
+	return uint(n), err == nil
+}
+
+// scimPage returns the 1-based startIndex and count of a list request
+func scimPage(c *gin.Context) (int, int) {
+	start, err := strconv.Atoi(c.DefaultQuery("startIndex", "1"))
+	if err != nil || start < 1 {
+		start = 1
+	}
+	count, err := strconv.Atoi(c.DefaultQuery("count", "100"))
+	if err != nil || count < 0 {
+		count = 100
+	}
+	if count > scimMaxResults {
+		count = scimMaxResults
+	}
+	return start, count
+}
+
+// paginate returns the page of items selected by startIndex and count
+func paginate[T any](items []T, start, count int) []T {
+	if start > len(items) {
+		return []T{}
+	}
+	end := start - 1 + count
+	if end > len(items) {
+		end = len(items)
+	}
+	return items[start-1 : end]
+}
@@ -0,0 +1,759 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/sonnguyen/kubelens/internal/audit"
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+// scimAudit records a provisioning change under the identity of the token's owner
+func scimAudit(c *gin.Context, event, description string, metadata map[string]interface{}) {
+	username, exists := c.Get("username")
+	if !exists {
+		return
+	}
+	email, _ := c.Get("email")
+	metadata["source"] = "scim"
+	audit.Log(c, event, c.GetInt("user_id"), username.(string), email.(string), description, metadata)
+}
+
+// SCIMServiceProviderConfig handles GET /scim/v2/ServiceProviderConfig
+func (h *Handler) SCIMServiceProviderConfig(c *gin.Context) {
+	scimJSON(c, http.StatusOK, gin.H{
+		"schemas":        []string{scimServiceProviderURN},
+		"patch":          gin.H{"supported": true},
+		"bulk":           gin.H{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":         gin.H{"supported": true, "maxResults": scimMaxResults},
+		"changePassword": gin.H{"supported": false},
+		"sort":           gin.H{"supported": false},
+		"etag":           gin.H{"supported": false},
+		"authenticationSchemes": []gin.H{{
+			"type":        "oauthbearertoken",
+			"name":        "Personal access token",
+			"description": "A kubelens personal access token of a user allowed to manage users",
+			"primary":     true,
+		}},
+	})
+}
+
+// SCIMResourceTypes handles GET /scim/v2/ResourceTypes
+func (h *Handler) SCIMResourceTypes(c *gin.Context) {
+	types := []gin.H{
+		{"schemas": []string{scimResourceTypeURN}, "id": "User", "name": "User", "endpoint": "/Users", "schema": scimUserSchema},
+		{"schemas": []string{scimResourceTypeURN}, "id": "Group", "name": "Group", "endpoint": "/Groups", "schema": scimGroupSchema},
+	}
+	scimJSON(c, http.StatusOK, scimListResponse{
+		Schemas:      []string{scimListSchema},
+		TotalResults: len(types),
+		StartIndex:   1,
+		ItemsPerPage: len(types),
+		Resources:    types,
+	})
+}
+
+// SCIMListUsers handles GET /scim/v2/Users
+func (h *Handler) SCIMListUsers(c *gin.Context) {
+	var users []*db.User
+	if filter := c.Query("filter"); filter != "" {
+		attr, value, err := parseSCIMFilter(filter)
+		if err != nil {
+			scimError(c, http.StatusBadRequest, "invalidFilter", err.Error())
+			return
+		}
+		var user *db.User
+		switch attr {
+		case "username", "emails.value":
+			user, err = h.db.GetUserByEmail(value)
+			if err != nil && attr == "username" {
+				user, err = h.db.GetUserByUsername(value)
+			}
+		case "externalid":
+			user, err = h.db.GetUserByExternalID(value)
+		default:
+			scimError(c, http.StatusBadRequest, "invalidFilter", fmt.Sprintf("filtering on %q is not supported", attr))
+			return
+		}
+		// Lookups fail when nothing matches, which is an empty result
+		if err == nil {
+			users = append(users, user)
+		}
+	} else {
+		all, err := h.db.ListAllUsers()
+		if err != nil {
+			log.Errorf("SCIM: failed to list users: %v", err)
+			scimError(c, http.StatusInternalServerError, "", "failed to list users")
+			return
+		}
+		users = all
+	}
+
+	start, count := scimPage(c)
+	page := paginate(users, start, count)
+	resources := make([]scimUser, 0, len(page))
+	for _, user := range page {
+		groups, err := h.db.GetUserGroups(user.ID)
+		if err != nil {
+			log.Errorf("SCIM: failed to get groups of user %d: %v", user.ID, err)
+		}
+		resources = append(resources, toSCIMUser(user, groups))
+	}
+
+	scimJSON(c, http.StatusOK, scimListResponse{
+		Schemas:      []string{scimListSchema},
+		TotalResults: len(users),
+		StartIndex:   start,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	})
+}
+
+// scimUserByID loads the user of the :id path parameter, writing an error response if it
+// does not exist
+func (h *Handler) scimUserByID(c *gin.Context) (*db.User, bool) {
+	id, ok := parseSCIMID(c.Param("id"))
+	if !ok {
+		scimError(c, http.StatusNotFound, "", "user not found")
+		return nil, false
+	}
+	user, err := h.db.GetUserByIDWithGroups(id)
+	if err != nil {
+		scimError(c, http.StatusNotFound, "", "user not found")
+		return nil, false
+	}
+	return user, true
+}
+
+// SCIMGetUser handles GET /scim/v2/Users/:id
+func (h *Handler) SCIMGetUser(c *gin.Context) {
+	user, ok := h.scimUserByID(c)
+	if !ok {
+		return
+	}
+	scimJSON(c, http.StatusOK, toSCIMUser(user, user.Groups))
+}
+
+// applySCIMUser copies the attributes of a SCIM user onto a kubelens user
+func applySCIMUser(user *db.User, resource *scimUser) error {
+	email := resource.primaryEmail()
+	if strings.Contains(resource.UserName, "@") {
+		email = resource.UserName
+	}
+	if email == "" {
+		return errors.New("userName or emails must contain an email address")
+	}
+	user.Email = email
+	if name := resource.fullName(); name != "" {
+		user.FullName = name
+	}
+	if resource.ExternalID != "" {
+		user.ExternalID = resource.ExternalID
+	}
+	if resource.Active != nil {
+		user.IsActive = *resource.Active
+	}
+	return nil
+}
+
+// SCIMCreateUser handles POST /scim/v2/Users. Provisioned users have no password and sign
+// in through the identity provider's SSO; a password in the request is ignored.
+func (h *Handler) SCIMCreateUser(c *gin.Context) {
+	var resource scimUser
+	if err := c.ShouldBindJSON(&resource); err != nil {
+		scimError(c, http.StatusBadRequest, "invalidSyntax", err.Error())
+		return
+	}
+
+	user := &db.User{IsActive: true, AuthProvider: "oidc"}
+	if err := applySCIMUser(user, &resource); err != nil {
+		scimError(c, http.StatusBadRequest, "invalidValue", err.Error())
+		return
+	}
+
+	if exists, err := h.db.EmailExists(user.Email); err != nil {
+		log.Errorf("SCIM: failed to check email: %v", err)
+		scimError(c, http.StatusInternalServerError, "", "failed to create user")
+		return
+	} else if exists {
+		scimError(c, http.StatusConflict, "uniqueness", "a user with this userName already exists")
+		return
+	}
+
+	username := resource.UserName
+	if strings.Contains(username, "@") {
+		username = ""
+	}
+	user.Username = h.ensureUniqueUsername(generateUsername(OIDCClaims{PreferredName: username, Email: user.Email}))
+
+	if err := h.db.CreateUser(user); err != nil {
+		log.Errorf("SCIM: failed to create user %s: %v", user.Email, err)
+		scimError(c, http.StatusInternalServerError, "", "failed to create user")
+		return
+	}
+
+	log.Infof("SCIM: provisioned user %s (ID: %d)", user.Email, user.ID)
+
+	scimAudit(c, audit.EventUserCreated, fmt.Sprintf("Provisioned user: %s", user.Email),
+		map[string]interface{}{
+			"target_user_id":  user.ID,
+			"target_username": user.Username,
+			"external_id":     user.ExternalID,
+		})
+
+	c.Header("Location", fmt.Sprintf("%s/Users/%d", SCIMBasePath, user.ID))
+	scimJSON(c, http.StatusCreated, toSCIMUser(user, nil))
+}
+
+// saveSCIMUser stores a changed user. A user that was deactivated is signed out of all
+// sessions.
+func (h *Handler) saveSCIMUser(c *gin.Context, user *db.User, wasActive bool) bool {
+	if err := h.db.UpdateUser(user); err != nil {
+		log.Errorf("SCIM: failed to update user %d: %v", user.ID, err)
+		scimError(c, http.StatusInternalServerError, "", "failed to update user")
+		return false
+	}
+
+	if wasActive && !user.IsActive {
+		if err := h.db.RevokeUserSessions(user.ID, 0); err != nil {
+			log.Errorf("SCIM: failed to revoke sessions of user %d: %v", user.ID, err)
+		}
+		if err := h.db.RevokeUserTokens(user.ID); err != nil {
+			log.Errorf("SCIM: failed to revoke tokens of user %d: %v", user.ID, err)
+		}
+		log.Infof("SCIM: deactivated user %s (ID: %d)", user.Email, user.ID)
+	}
+
+	scimAudit(c, audit.EventUserUpdated, fmt.Sprintf("Updated provisioned user: %s", user.Email),
+		map[string]interface{}{
+			"target_user_id":  user.ID,
+			"target_username": user.Username,
+			"is_active":       user.IsActive,
+		})
+	return true
+}
+
+// SCIMReplaceUser handles PUT /scim/v2/Users/:id
+func (h *Handler) SCIMReplaceUser(c *gin.Context) {
+	user, ok := h.scimUserByID(c)
+	if !ok {
+		return
+	}
+
+	var resource scimUser
+	if err := c.ShouldBindJSON(&resource); err != nil {
+		scimError(c, http.StatusBadRequest, "invalidSyntax", err.Error())
+		return
+	}
+
+	wasActive := user.IsActive
+	if err := applySCIMUser(user, &resource); err != nil {
+		scimError(c, http.StatusBadRequest, "invalidValue", err.Error())
+		return
+	}
+	if !h.saveSCIMUser(c, user, wasActive) {
+		return
+	}
+
+	scimJSON(c, http.StatusOK, toSCIMUser(user, user.Groups))
+}
+
+// SCIMPatchUser handles PATCH /scim/v2/Users/:id. Identity providers mostly patch active
+// (deactivation), the name and the email.
+func (h *Handler) SCIMPatchUser(c *gin.Context) {
+	user, ok := h.scimUserByID(c)
+	if !ok {
+		return
+	}
+
+	var patch scimPatchOp
+	if err := c.ShouldBindJSON(&patch); err != nil {
+		scimError(c, http.StatusBadRequest, "invalidSyntax", err.Error())
+		return
+	}
+
+	wasActive := user.IsActive
+	for _, op := range patch.Operations {
+		if !strings.EqualFold(op.Op, "add") && !strings.EqualFold(op.Op, "replace") {
+			scimError(c, http.StatusBadRequest, "invalidValue", fmt.Sprintf("operation %q is not supported on users", op.Op))
+			return
+		}
+
+		values := map[string]json.RawMessage{}
+		if op.Path == "" {
+			if err := json.Unmarshal(op.Value, &values); err != nil {
+				scimError(c, http.StatusBadRequest, "invalidValue", "value must be an object when path is not set")
+				return
+			}
+		} else {
+			values[op.Path] = op.Value
+		}
+
+		for path, value := range values {
+			if err := patchSCIMUserAttribute(user, path, value); err != nil {
+				scimError(c, http.StatusBadRequest, "invalidValue", err.Error())
+				return
+			}
+		}
+	}
+
+	if !h.saveSCIMUser(c, user, wasActive) {
+		return
+	}
+
+	scimJSON(c, http.StatusOK, toSCIMUser(user, user.Groups))
+}
+
+// patchSCIMUserAttribute sets one attribute of a PATCH operation. Unknown attributes are
+// ignored so that clients sending extension attributes keep working.
+func patchSCIMUserAttribute(user *db.User, path string, value json.RawMessage) error {
+	var s string
+	switch strings.ToLower(path) {
+	case "active":
+		active, err := parseSCIMBool(value)
+		if err != nil {
+			return err
+		}
+		user.IsActive = active
+	case "username", "emails[type eq \"work\"].value", "emails.value":
+		if err := json.Unmarshal(value, &s); err != nil {
+			return fmt.Errorf("%s must be a string", path)
+		}
+		if strings.Contains(s, "@") {
+			user.Email = s
+		}
+	case "displayname", "name.formatted":
+		if err := json.Unmarshal(value, &s); err != nil {
+			return fmt.Errorf("%s must be a string", path)
+		}
+		user.FullName = s
+	case "externalid":
+		if err := json.Unmarshal(value, &s); err != nil {
+			return fmt.Errorf("%s must be a string", path)
+		}
+		user.ExternalID = s
+	case "name":
+		var name scimName
+		if err := json.Unmarshal(value, &name); err != nil {
+			return fmt.Errorf("name must be an object")
+		}
+		if fullName := (&scimUser{Name: &name}).fullName(); fullName != "" {
+			user.FullName = fullName
+		}
+	}
+	return nil
+}
+
+// SCIMDeleteUser handles DELETE /scim/v2/Users/:id
+func (h *Handler) SCIMDeleteUser(c *gin.Context) {
+	user, ok := h.scimUserByID(c)
+	if !ok {
+		return
+	}
+
+	if user.IsAdmin && user.AuthProvider == "local" {
+		scimError(c, http.StatusForbidden, "", "local administrators cannot be deleted through SCIM")
+		return
+	}
+
+	if err := h.db.DeleteUser(user.ID); err != nil {
+		log.Errorf("SCIM: failed to delete user %d: %v", user.ID, err)
+		scimError(c, http.StatusInternalServerError, "", "failed to delete user")
+		return
+	}
+
+	log.Infof("SCIM: deleted user %s (ID: %d)", user.Email, user.ID)
+
+	scimAudit(c, audit.EventUserDeleted, fmt.Sprintf("Deprovisioned user: %s", user.Email),
+		map[string]interface{}{
+			"target_user_id":  user.ID,
+			"target_username": user.Username,
+		})
+
+	c.Status(http.StatusNoContent)
+}
+
+// SCIMListGroups handles GET /scim/v2/Groups
+func (h *Handler) SCIMListGroups(c *gin.Context) {
+	var groups []*db.Group
+	if filter := c.Query("filter"); filter != "" {
+		attr, value, err := parseSCIMFilter(filter)
+		if err != nil {
+			scimError(c, http.StatusBadRequest, "invalidFilter", err.Error())
+			return
+		}
+		var group *db.Group
+		switch attr {
+		case "displayname":
+			group, err = h.db.GetGroupByName(value)
+		case "externalid":
+			group, err = h.db.GetGroupByExternalID(value)
+		default:
+			scimError(c, http.StatusBadRequest, "invalidFilter", fmt.Sprintf("filtering on %q is not supported", attr))
+			return
+		}
+		// Lookups fail when nothing matches, which is an empty result
+		if err == nil {
+			groups = append(groups, group)
+		}
+	} else {
+		all, err := h.db.ListAllGroups()
+		if err != nil {
+			log.Errorf("SCIM: failed to list groups: %v", err)
+			scimError(c, http.StatusInternalServerError, "", "failed to list groups")
+			return
+		}
+		groups = all
+	}
+
+	// Members are left out unless asked for, as Azure AD does when it only checks existence
+	withMembers := !strings.Contains(strings.ToLower(c.Query("excludedAttributes")), "members")
+
+	start, count := scimPage(c)
+	page := paginate(groups, start, count)
+	resources := make([]scimGroup, 0, len(page))
+	for _, group := range page {
+		if withMembers {
+			if full, err := h.db.GetGroupByIDWithUsers(group.ID); err == nil {
+				group = full
+			}
+		}
+		resources = append(resources, toSCIMGroup(group))
+	}
+
+	scimJSON(c, http.StatusOK, scimListResponse{
+		Schemas:      []string{scimListSchema},
+		TotalResults: len(groups),
+		StartIndex:   start,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	})
+}
+
+// scimGroupByID loads the group of the :id path parameter with its members, writing an
+// error response if it does not exist
+func (h *Handler) scimGroupByID(c *gin.Context) (*db.Group, bool) {
+	id, ok := parseSCIMID(c.Param("id"))
+	if !ok {
+		scimError(c, http.StatusNotFound, "", "group not found")
+		return nil, false
+	}
+	group, err := h.db.GetGroupByIDWithUsers(id)
+	if err != nil {
+		scimError(c, http.StatusNotFound, "", "group not found")
+		return nil, false
+	}
+	return group, true
+}
+
+// SCIMGetGroup handles GET /scim/v2/Groups/:id
+func (h *Handler) SCIMGetGroup(c *gin.Context) {
+	group, ok := h.scimGroupByID(c)
+	if !ok {
+		return
+	}
+	scimJSON(c, http.StatusOK, toSCIMGroup(group))
+}
+
+// scimMemberIDs resolves SCIM member references to user IDs
+func (h *Handler) scimMemberIDs(members []scimRef) ([]uint, error) {
+	ids := make([]uint, 0, len(members))
+	for _, member := range members {
+		id, ok := parseSCIMID(member.Value)
+		if !ok {
+			return nil, fmt.Errorf("invalid member %q", member.Value)
+		}
+		if _, err := h.db.GetUserByID(id); err != nil {
+			return nil, fmt.Errorf("member %q does not exist", member.Value)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// SCIMCreateGroup handles POST /scim/v2/Groups. New groups have no permissions until an
+// administrator grants them.
+func (h *Handler) SCIMCreateGroup(c *gin.Context) {
+	var resource scimGroup
+	if err := c.ShouldBindJSON(&resource); err != nil {
+		scimError(c, http.StatusBadRequest, "invalidSyntax", err.Error())
+		return
+	}
+	if resource.DisplayName == "" {
+		scimError(c, http.StatusBadRequest, "invalidValue", "displayName is required")
+		return
+	}
+
+	if exists, err := h.db.GroupExists(resource.DisplayName); err != nil {
+		log.Errorf("SCIM: failed to check group: %v", err)
+		scimError(c, http.StatusInternalServerError, "", "failed to create group")
+		return
+	} else if exists {
+		scimError(c, http.StatusConflict, "uniqueness", "a group with this displayName already exists")
+		return
+	}
+
+	memberIDs, err := h.scimMemberIDs(resource.Members)
+	if err != nil {
+		scimError(c, http.StatusBadRequest, "invalidValue", err.Error())
+		return
+	}
+
+	group := &db.Group{
+		Name:        resource.DisplayName,
+		Description: "Provisioned by SCIM",
+		Permissions: db.JSON("[]"),
+		ExternalID:  resource.ExternalID,
+	}
+	if err := h.db.CreateGroup(group); err != nil {
+		log.Errorf("SCIM: failed to create group %s: %v", group.Name, err)
+		scimError(c, http.StatusInternalServerError, "", "failed to create group")
+		return
+	}
+	if err := h.db.SetGroupUsers(group.ID, memberIDs); err != nil {
+		log.Errorf("SCIM: failed to set members of group %d: %v", group.ID, err)
+		scimError(c, http.StatusInternalServerError, "", "failed to set group members")
+		return
+	}
+
+	log.Infof("SCIM: provisioned group %s (ID: %d) with %d members", group.Name, group.ID, len(memberIDs))
+
+	scimAudit(c, audit.EventGroupCreated, fmt.Sprintf("Provisioned group: %s", group.Name),
+		map[string]interface{}{
+			"group_id":    group.ID,
+			"group_name":  group.Name,
+			"external_id": group.ExternalID,
+			"members":     len(memberIDs),
+		})
+
+	if full, err := h.db.GetGroupByIDWithUsers(group.ID); err == nil {
+		group = full
+	}
+	c.Header("Location", fmt.Sprintf("%s/Groups/%d", SCIMBasePath, group.ID))
+	scimJSON(c, http.StatusCreated, toSCIMGroup(group))
+}
+
+// saveSCIMGroup stores a changed group and its members and writes the updated group
+func (h *Handler) saveSCIMGroup(c *gin.Context, group *db.Group, memberIDs []uint) {
+	if err := h.db.UpdateGroup(group); err != nil {
+		log.Errorf("SCIM: failed to update group %d: %v", group.ID, err)
+		scimError(c, http.StatusInternalServerError, "", "failed to update group")
+		return
+	}
+	if err := h.db.SetGroupUsers(group.ID, memberIDs); err != nil {
+		log.Errorf("SCIM: failed to set members of group %d: %v", group.ID, err)
+		scimError(c, http.StatusInternalServerError, "", "failed to set group members")
+		return
+	}
+
+	scimAudit(c, audit.EventGroupUpdated, fmt.Sprintf("Updated provisioned group: %s", group.Name),
+		map[string]interface{}{
+			"group_id":   group.ID,
+			"group_name": group.Name,
+			"members":    len(memberIDs),
+		})
+
+	if full, err := h.db.GetGroupByIDWithUsers(group.ID); err == nil {
+		group = full
+	}
+	scimJSON(c, http.StatusOK, toSCIMGroup(group))
+}
+
+// SCIMReplaceGroup handles PUT /scim/v2/Groups/:id
+func (h *Handler) SCIMReplaceGroup(c *gin.Context) {
+	group, ok := h.scimGroupByID(c)
+	if !ok {
+		return
+	}
+
+	var resource scimGroup
+	if err := c.ShouldBindJSON(&resource); err != nil {
+		scimError(c, http.StatusBadRequest, "invalidSyntax", err.Error())
+		return
+	}
+	memberIDs, err := h.scimMemberIDs(resource.Members)
+	if err != nil {
+		scimError(c, http.StatusBadRequest, "invalidValue", err.Error())
+		return
+	}
+
+	if resource.DisplayName != "" && !group.IsSystem {
+		group.Name = resource.DisplayName
+	}
+	if resource.ExternalID != "" {
+		group.ExternalID = resource.ExternalID
+	}
+	group.Users = nil
+
+	h.saveSCIMGroup(c, group, memberIDs)
+}
+
+// SCIMPatchGroup handles PATCH /scim/v2/Groups/:id. Identity providers push membership
+// changes as add/remove operations on members.
+func (h *Handler) SCIMPatchGroup(c *gin.Context) {
+	group, ok := h.scimGroupByID(c)
+	if !ok {
+		return
+	}
+
+	var patch scimPatchOp
+	if err := c.ShouldBindJSON(&patch); err != nil {
+		scimError(c, http.StatusBadRequest, "invalidSyntax", err.Error())
+		return
+	}
+
+	members := make(map[uint]bool, len(group.Users))
+	for _, user := range group.Users {
+		members[user.ID] = true
+	}
+
+	for _, op := range patch.Operations {
+		path := strings.ToLower(op.Path)
+		operation := strings.ToLower(op.Op)
+
+		switch {
+		case path == "" && (operation == "add" || operation == "replace"):
+			var values struct {
+				DisplayName string    `json:"displayName"`
+				ExternalID  string    `json:"externalId"`
+				Members     []scimRef `json:"members"`
+			}
+			if err := json.Unmarshal(op.Value, &values); err != nil {
+				scimError(c, http.StatusBadRequest, "invalidValue", "value must be an object when path is not set")
+				return
+			}
+			if values.DisplayName != "" && !group.IsSystem {
+				group.Name = values.DisplayName
+			}
+			if values.ExternalID != "" {
+				group.ExternalID = values.ExternalID
+			}
+			if values.Members != nil {
+				if err := h.patchSCIMMembers(members, operation, values.Members); err != nil {
+					scimError(c, http.StatusBadRequest, "invalidValue", err.Error())
+					return
+				}
+			}
+		case path == "displayname" && (operation == "add" || operation == "replace"):
+			var name string
+			if err := json.Unmarshal(op.Value, &name); err != nil || name == "" {
+				scimError(c, http.StatusBadRequest, "invalidValue", "displayName must be a non-empty string")
+				return
+			}
+			if !group.IsSystem {
+				group.Name = name
+			}
+		case path == "externalid" && (operation == "add" || operation == "replace"):
+			if err := json.Unmarshal(op.Value, &group.ExternalID); err != nil {
+				scimError(c, http.StatusBadRequest, "invalidValue", "externalId must be a string")
+				return
+			}
+		case path == "members":
+			var refs []scimRef
+			if len(op.Value) > 0 {
+				if err := json.Unmarshal(op.Value, &refs); err != nil {
+					scimError(c, http.StatusBadRequest, "invalidValue", "members must be a list")
+					return
+				}
+			}
+			if operation == "remove" && len(refs) == 0 {
+				// Removing the attribute removes all members
+				members = map[uint]bool{}
+				continue
+			}
+			if err := h.patchSCIMMembers(members, operation, refs); err != nil {
+				scimError(c, http.StatusBadRequest, "invalidValue", err.Error())
+				return
+			}
+		case strings.HasPrefix(path, "members[") && operation == "remove":
+			// members[value eq "42"]
+			_, value, err := parseSCIMFilter(strings.TrimSuffix(op.Path[len("members["):], "]"))
+			if err != nil {
+				scimError(c, http.StatusBadRequest, "invalidPath", err.Error())
+				return
+			}
+			if id, ok := parseSCIMID(value); ok {
+				delete(members, id)
+			}
+		default:
+			scimError(c, http.StatusBadRequest, "invalidPath", fmt.Sprintf("operation %q on %q is not supported", op.Op, op.Path))
+			return
+		}
+	}
+
+	memberIDs := make([]uint, 0, len(members))
+	for id := range members {
+		memberIDs = append(memberIDs, id)
+	}
+	group.Users = nil
+
+	h.saveSCIMGroup(c, group, memberIDs)
+}
+
+// patchSCIMMembers applies an add, remove or replace of members to the member set
+func (h *Handler) patchSCIMMembers(members map[uint]bool, operation string, refs []scimRef) error {
+	switch operation {
+	case "add", "replace":
+		ids, err := h.scimMemberIDs(refs)
+		if err != nil {
+			return err
+		}
+		if operation == "replace" {
+			for id := range members {
+				delete(members, id)
+			}
+		}
+		for _, id := range ids {
+			members[id] = true
+		}
+	case "remove":
+		for _, ref := range refs {
+			if id, ok := parseSCIMID(ref.Value); ok {
+				delete(members, id)
+			}
+		}
+	default:
+		return fmt.Errorf("operation %q is not supported", operation)
+	}
+	return nil
+}
+
+// SCIMDeleteGroup handles DELETE /scim/v2/Groups/:id. The group's members are removed
+// first; system groups cannot be deleted.
+func (h *Handler) SCIMDeleteGroup(c *gin.Context) {
+	group, ok := h.scimGroupByID(c)
+	if !ok {
+		return
+	}
+	if group.IsSystem {
+		scimError(c, http.StatusForbidden, "", "system groups cannot be deleted")
+		return
+	}
+
+	if err := h.db.SetGroupUsers(group.ID, nil); err != nil {
+		log.Errorf("SCIM: failed to clear members of group %d: %v", group.ID, err)
+		scimError(c, http.StatusInternalServerError, "", "failed to delete group")
+		return
+	}
+	if err := h.db.DeleteGroup(group.ID); err != nil {
+		log.Errorf("SCIM: failed to delete group %d: %v", group.ID, err)
+		scimError(c, http.StatusInternalServerError, "", "failed to delete group")
+		return
+	}
+
+	log.Infof("SCIM: deleted group %s (ID: %d)", group.Name, group.ID)
+
+	scimAudit(c, audit.EventGroupDeleted, fmt.Sprintf("Deprovisioned group: %s", group.Name),
+		map[string]interface{}{
+			"group_id":   group.ID,
+			"group_name": group.Name,
+		})
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,133 @@
+package auth
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	"github.com/sonnguyen/kubelens/internal/audit"
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+func TestSCIMProvisioning(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	log.SetLevel(log.WarnLevel)
+
+	database, err := db.New(filepath.Join(t.TempDir(), "kubelens.db"))
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	h := NewHandler(database, "test-secret", audit.NewLogger(database))
+	router := gin.New()
+	scim := router.Group(SCIMBasePath)
+	scim.GET("/Users", h.SCIMListUsers)
+	scim.POST("/Users", h.SCIMCreateUser)
+	scim.PATCH("/Users/:id", h.SCIMPatchUser)
+	scim.POST("/Groups", h.SCIMCreateGroup)
+	scim.PATCH("/Groups/:id", h.SCIMPatchGroup)
+	scim.DELETE("/Groups/:id", h.SCIMDeleteGroup)
+
+	do := func(method, path string, body interface{}) (int, map[string]interface{}) {
+		var reader *bytes.Reader
+		if body != nil {
+			data, _ := json.Marshal(body)
+			reader = bytes.NewReader(data)
+		} else {
+			reader = bytes.NewReader(nil)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(method, SCIMBasePath+path, reader))
+		var resp map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		return w.Code, resp
+	}
+
+	code, created := do(http.MethodPost, "/Users", gin.H{
+		"schemas":    []string{scimUserSchema},
+		"userName":   "jdoe@example.com",
+		"externalId": "00u1",
+		"name":       gin.H{"givenName": "Jane", "familyName": "Doe"},
+		"password":   "ignored",
+	})
+	if code != http.StatusCreated {
+		t.Fatalf("create user status = %d, body = %v", code, created)
+	}
+	userID := created["id"].(string)
+
+	if code, _ := do(http.MethodPost, "/Users", gin.H{"userName": "jdoe@example.com"}); code != http.StatusConflict {
+		t.Errorf("creating a duplicate user status = %d, want %d", code, http.StatusConflict)
+	}
+
+	code, list := do(http.MethodGet, `/Users?filter=userName+eq+"jdoe@example.com"`, nil)
+	if code != http.StatusOK || list["totalResults"].(float64) != 1 {
+		t.Fatalf("filter users = %d %v, want one result", code, list)
+	}
+	if _, list := do(http.MethodGet, `/Users?filter=externalId+eq+"missing"`, nil); list["totalResults"].(float64) != 0 {
+		t.Errorf("filter on an unknown externalId returned %v results, want 0", list["totalResults"])
+	}
+
+	user, _ := database.GetUserByExternalID("00u1")
+	if user == nil || user.FullName != "Jane Doe" || user.PasswordHash != "" || !user.IsActive {
+		t.Fatalf("provisioned user = %+v", user)
+	}
+
+	code, group := do(http.MethodPost, "/Groups", gin.H{
+		"displayName": "platform",
+		"members":     []gin.H{{"value": userID}},
+	})
+	if code != http.StatusCreated || len(group["members"].([]interface{})) != 1 {
+		t.Fatalf("create group = %d %v, want one member", code, group)
+	}
+	groupID := group["id"].(string)
+
+	code, group = do(http.MethodPatch, "/Groups/"+groupID, gin.H{
+		"schemas":    []string{scimPatchSchema},
+		"Operations": []gin.H{{"op": "Remove", "path": `members[value eq "` + userID + `"]`}},
+	})
+	if code != http.StatusOK || group["members"] != nil {
+		t.Errorf("remove member = %d %v, want no members", code, group)
+	}
+
+	// Azure AD sends booleans as strings
+	code, _ = do(http.MethodPatch, "/Users/"+userID, gin.H{
+		"schemas":    []string{scimPatchSchema},
+		"Operations": []gin.H{{"op": "Replace", "path": "active", "value": "False"}},
+	})
+	if code != http.StatusOK {
+		t.Fatalf("deactivate user status = %d", code)
+	}
+	if user, _ := database.GetUserByID(user.ID); user.IsActive || user.TokenRevokedAt == nil {
+		t.Errorf("deactivated user is_active = %v, token_revoked_at = %v", user.IsActive, user.TokenRevokedAt)
+	}
+
+	if code, _ := do(http.MethodDelete, "/Groups/"+groupID, nil); code != http.StatusNoContent {
+		t.Errorf("delete group status = %d, want %d", code, http.StatusNoContent)
+	}
+}
+
+func TestParseSCIMFilter(t *testing.T) {
+	tests := []struct {
+		filter, attr, value string
+	}{
+		{`userName eq "jdoe@example.com"`, "username", "jdoe@example.com"},
+		{`externalId EQ "00u1"`, "externalid", "00u1"},
+		{`emails[type eq "work"].value eq "a@b.c"`, "emails.value", "a@b.c"},
+		{`displayName eq "dev ops"`, "displayname", "dev ops"},
+	}
+	for _, tt := range tests {
+		attr, value, err := parseSCIMFilter(tt.filter)
+		if err != nil || attr != tt.attr || value != tt.value {
+			t.Errorf("parseSCIMFilter(%q) = %q, %q, %v; want %q, %q", tt.filter, attr, value, err, tt.attr, tt.value)
+		}
+	}
+	if _, _, err := parseSCIMFilter(`userName co "jdoe"`); err == nil {
+		t.Error("parseSCIMFilter() accepted an unsupported operator")
+	}
+}
@@ -0,0 +1,804 @@
+package auth
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	"github.com/sonnguyen/kubelens/internal/db"
+	"github.com/sonnguyen/kubelens/internal/ws"
+)
+
+// clusterInfoSegments are the cluster routes that describe the cluster itself and are
+// checked against the "clusters" resource
+var clusterInfoSegments = map[string]bool{
+	"status":             true,
+	"metrics":            true,
+	"resources-summary":  true,
+	"offboarding-report": true,
+	"datasources":        true,
+	"prometheus":         true,
+	"benchmarks":         true,
+	"costs":              true,
+	"scaling":            true,
+	"labels":             true,
+	"deprecations":       true,
+}
+
+// bodyScopedRoutes are the cluster routes whose body names the objects they act on
+// (manifests, rendered kustomizations and templates, diffs, quick action targets and
+// edited objects). The
+// route says nothing of what it touches, so the handler checks every object.
+var bodyScopedRoutes = map[string]bool{
+	"/clusters/:name/manifests":             true,
+	"/clusters/:name/kustomize":             true,
+	"/clusters/:name/templates/:id/preview": true,
+	"/clusters/:name/templates/:id/apply":   true,
+	"/clusters/:name/diff":                  true,
+	"/clusters/:name/quick-actions/:action": true,
+	"/clusters/:name/edit-sessions":         true,
+}
+
+// clusterJobRoutes are the routes outside /clusters/:name that name their cluster in the
+// body, the query or a stored object: drain jobs, upgrade plans, crash reports and alerts.
+// Their handlers check it, and the caller's organization, with CheckClusterScope and
+// ClusterScopeFilter.
+var clusterJobRoutes = []string{"/drains", "/upgrade", "/crash-reports", "/alerts"}
+
+// isClusterJobRoute reports whether a route is one of clusterJobRoutes
+func isClusterJobRoute(route string) bool {
+	for _, prefix := range clusterJobRoutes {
+		if strings.HasSuffix(route, prefix) || strings.Contains(route, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// scopeRequest is what a cluster route touches: which resource, where, and how
+type scopeRequest struct {
+	cluster   string
+	resource  string
+	namespace string // "" for cluster-scoped requests and lists across namespaces
+	action    string
+}
+
+// ResourceScope enforces the cluster and namespace scopes of the caller's permissions on
+// the cluster routes. A group granted
+//
+//	{"resource": "*", "actions": ["read"], "clusters": ["prod"], "namespaces": ["team-a-*"]}
+//
+// can read objects in the matching namespaces of cluster "prod" and nothing else there:
+// mutating calls outside the grant are rejected and lists across namespaces (and the
+// cluster list and global search) are filtered down to what the grant covers.
+// Cluster-wide responses that cannot be filtered, such as summaries of the whole cluster,
+// and routes whose scope cannot be worked out are refused to callers restricted to
+// namespaces.
+// Routes whose body names the objects (manifests, kustomize, templates, diffs, quick
+// actions and edit sessions) leave the check of each object to the handler, through scope_allows_action.
+// So do drain jobs, upgrade plans, crash reports and alerts, through CheckClusterScope.
+// Cluster and namespace patterns are globs (path.Match); none or "*" matches everything.
+//
+// Permissions without any cluster or namespace restriction keep the previous behaviour of
+// unrestricted cluster access, so existing groups are unaffected. Admins are not checked,
+// but a scoped API token is, whoever owns it: the resource and action of each cluster
+// route must be granted by the token, so a token for "users" cannot reach any cluster.
+func (h *Handler) ResourceScope() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		i := strings.Index(route, "/clusters")
+		isSearch := strings.HasSuffix(route, "/search")
+		isFleet := strings.HasSuffix(route, "/fleet/:resource")
+		isCompare := strings.HasSuffix(route, "/compare")
+		isFavorites := strings.Contains(route, "/favorites")
+		isRecent := strings.HasSuffix(route, "/me/recent")
+		isClusterJob := i < 0 && isClusterJobRoute(route)
+		if i < 0 && !isSearch && !isFleet && !isCompare && !isFavorites && !isRecent && !isClusterJob {
+			c.Next()
+			return
+		}
+
+		grants, err := h.scopedGrants(c)
+		if err != nil {
+			log.Errorf("Failed to get user permissions: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check permissions"})
+			c.Abort()
+			return
+		}
+		outside, err := h.clustersOutsideOrganization(c)
+		if err != nil {
+			log.Errorf("Failed to list the clusters of other organizations: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check permissions"})
+			c.Abort()
+			return
+		}
+		if len(grants) == 0 && len(outside) == 0 {
+			c.Next()
+			return
+		}
+
+		// Global search: keep the results in clusters and namespaces the caller can read.
+		// The handler drops the others before counting and paging; the response is
+		// filtered again in case it does not.
+		if isSearch {
+			keep := func(resultType, cluster, namespace string) bool {
+				if resultType == "cluster" {
+					return !outside[cluster]
+				}
+				if cluster == "" {
+					return true
+				}
+				return !outside[cluster] && allowedByAll(grants, scopeRequest{cluster: cluster, namespace: namespace, action: "read"})
+			}
+			c.Set("scope_search", keep)
+			h.filterResponse(c, func(item map[string]interface{}) bool {
+				resultType, _ := item["type"].(string)
+				cluster, _ := item["cluster"].(string)
+				if resultType == "cluster" {
+					cluster, _ = item["name"].(string)
+				}
+				namespace, _ := item["namespace"].(string)
+				return keep(resultType, cluster, namespace)
+			})
+			return
+		}
+
+		// Fleet views: skip the clusters where the caller cannot read the resource and
+		// keep the objects in namespaces it may see
+		if isFleet {
+			resource := c.Param("resource")
+			c.Set("scope_clusters", func(name string) bool {
+				return !outside[name] && allowedSomewhereByAll(grants, scopeRequest{cluster: name, resource: resource, action: "read"})
+			})
+			h.filterResponse(c, func(item map[string]interface{}) bool {
+				cluster, _ := item["cluster"].(string)
+				return !outside[cluster] && allowedByAll(grants, scopeRequest{cluster: cluster, resource: resource, namespace: namespaceOf(objectOf(item)), action: "read"})
+			})
+			return
+		}
+
+		// Comparisons, favorites and recent views name their objects in the body or the
+		// database, so the handler checks them
+		if isCompare || isFavorites || isRecent {
+			c.Set("scope_allows", func(cluster, resource, namespace string) bool {
+				return !outside[cluster] && allowedByAll(grants, scopeRequest{cluster: cluster, resource: resource, namespace: namespace, action: "read"})
+			})
+			c.Next()
+			return
+		}
+
+		// Drain jobs, upgrade plans, crash reports and alerts name their cluster in the body,
+		// the query or the database, so the handler checks it
+		if isClusterJob {
+			c.Set("scope_cluster", &clusterScope{grants: grants, outside: outside})
+			c.Next()
+			return
+		}
+
+		// Clusters of other organizations do not exist for the caller
+		if name := c.Param("name"); name != "" && outside[name] {
+			c.JSON(http.StatusNotFound, gin.H{"error": "cluster not found"})
+			c.Abort()
+			return
+		}
+
+		// Routes acting on the objects of their body: the handler checks each object with
+		// scope_allows_action
+		if bodyScopedRoutes[route[i:]] {
+			if len(grants) > 0 {
+				c.Set("scope_allows_action", func(cluster, resource, namespace, action string) bool {
+					return allowedByAll(grants, scopeRequest{cluster: cluster, resource: resource, namespace: namespace, action: action})
+				})
+			}
+			c.Next()
+			return
+		}
+
+		req, ok := parseScopeRequest(c, route[i:])
+		if !ok {
+			// The cluster itself: readable by whoever can read anything in it. Other
+			// routes the scope cannot be worked out for are refused to scoped callers.
+			if len(grants) == 0 || (req.action == "read" && req.cluster != "" && allowedSomewhereByAll(grants, scopeRequest{cluster: req.cluster, action: "read"})) {
+				c.Next()
+				return
+			}
+			denyScope(c, req)
+			return
+		}
+
+		// The cluster list: keep the clusters of the organization the caller has any read
+		// grant on
+		if req.cluster == "" {
+			if req.action != "read" {
+				c.Next()
+				return
+			}
+			h.filterResponse(c, func(item map[string]interface{}) bool {
+				name, _ := item["name"].(string)
+				return !outside[name] && allowedSomewhereByAll(grants, scopeRequest{cluster: name, action: "read"})
+			})
+			return
+		}
+		if len(grants) == 0 {
+			c.Next()
+			return
+		}
+
+		if req.namespace != "" || req.action != "read" {
+			if !allowedByAll(grants, req) {
+				denyScope(c, req)
+				return
+			}
+			c.Next()
+			return
+		}
+
+		// A read across namespaces is allowed if the caller can read the resource somewhere
+		// in the cluster; the response is then trimmed to the namespaces it may see
+		if !allowedSomewhereByAll(grants, req) {
+			denyScope(c, req)
+			return
+		}
+		if allowedByAll(grants, req) {
+			c.Next()
+			return
+		}
+		if c.GetHeader("Upgrade") != "" {
+			denyScope(c, req)
+			return
+		}
+		h.filterResponse(c, func(item map[string]interface{}) bool {
+			item = objectOf(item)
+			namespace := namespaceOf(item)
+			if req.resource == "namespaces" {
+				if meta, ok := item["metadata"].(map[string]interface{}); ok {
+					namespace, _ = meta["name"].(string)
+				} else {
+					namespace, _ = item["name"].(string)
+				}
+			}
+			return allowedByAll(grants, scopeRequest{cluster: req.cluster, resource: req.resource, namespace: namespace, action: "read"})
+		})
+	}
+}
+
+// clustersOutsideOrganization returns the clusters of the organizations other than the
+// one the caller works in, none for requests without an organization
+func (h *Handler) clustersOutsideOrganization(c *gin.Context) (map[string]bool, error) {
+	if _, ok := c.Get("org_id"); !ok {
+		return nil, nil
+	}
+	names, err := h.db.ClustersOutsideOrganization(organizationID(c))
+	if err != nil {
+		return nil, err
+	}
+	outside := make(map[string]bool, len(names))
+	for _, name := range names {
+		outside[name] = true
+	}
+	return outside, nil
+}
+
+// topicScope is the ws.Scope of a caller restricted by its permissions or organization
+type topicScope struct {
+	grants  [][]db.Permission
+	outside map[string]bool
+}
+
+func (s *topicScope) Allows(cluster, resource, namespace string) bool {
+	return !s.outside[cluster] && allowedByAll(s.grants, scopeRequest{cluster: cluster, resource: resource, namespace: namespace, action: "read"})
+}
+
+func (s *topicScope) AllowsSomewhere(cluster, resource string) bool {
+	return !s.outside[cluster] && allowedSomewhereByAll(s.grants, scopeRequest{cluster: cluster, resource: resource, action: "read"})
+}
+
+// TopicScope returns what the caller may receive on the live update connection it opens:
+// the events and cluster statuses of the clusters of its organization, in the clusters and
+// namespaces its permissions and API token allow. It is nil for callers restricted by none.
+func (h *Handler) TopicScope(c *gin.Context) (ws.Scope, error) {
+	grants, err := h.scopedGrants(c)
+	if err != nil {
+		return nil, err
+	}
+	outside, err := h.clustersOutsideOrganization(c)
+	if err != nil {
+		return nil, err
+	}
+	if len(grants) == 0 && len(outside) == 0 {
+		return nil, nil
+	}
+	return &topicScope{grants: grants, outside: outside}, nil
+}
+
+// clusterScope is what CheckClusterScope and ClusterScopeFilter check on the routes of
+// clusterJobRoutes for a caller restricted by its permissions or organization
+type clusterScope struct {
+	grants  [][]db.Permission
+	outside map[string]bool
+}
+
+// CheckClusterScope checks that the caller's organization and its cluster and namespace
+// scope allow an action on a resource, for the routes outside /clusters/:name that name
+// the cluster in their body, query or a stored object (drain jobs, upgrade plans, crash
+// reports and alerts). Clusters of other organizations are not found (404); actions
+// outside the scope are forbidden (403). The response is written when false is returned.
+func CheckClusterScope(c *gin.Context, cluster, resource, namespace, action string) bool {
+	value, ok := c.Get("scope_cluster")
+	if !ok {
+		return true
+	}
+	scope := value.(*clusterScope)
+	if scope.outside[cluster] {
+		c.JSON(http.StatusNotFound, gin.H{"error": "cluster not found"})
+		c.Abort()
+		return false
+	}
+	req := scopeRequest{cluster: cluster, resource: resource, namespace: namespace, action: action}
+	if !allowedByAll(scope.grants, req) {
+		denyScope(c, req)
+		return false
+	}
+	return true
+}
+
+// ClusterScopeFilter returns whether the caller may read a resource in a cluster and
+// namespace, to trim the lists of the routes CheckClusterScope is for. A resource of ""
+// stands for any. It is nil for callers restricted by neither scope nor organization.
+func ClusterScopeFilter(c *gin.Context, resource string) func(cluster, namespace string) bool {
+	value, ok := c.Get("scope_cluster")
+	if !ok {
+		return nil
+	}
+	scope := value.(*clusterScope)
+	return func(cluster, namespace string) bool {
+		return !scope.outside[cluster] && allowedByAll(scope.grants, scopeRequest{cluster: cluster, resource: resource, namespace: namespace, action: "read"})
+	}
+}
+
+// scopedGrants returns the permission sets that restrict the caller to clusters or
+// namespaces. Each set must allow a request: the user's own permissions in its
+// organization (unless admin there) if they are restricted, and those of the API token
+// used, if it is scoped, whether or not they name clusters or namespaces.
+func (h *Handler) scopedGrants(c *gin.Context) ([][]db.Permission, error) {
+	var grants [][]db.Permission
+
+	if isAdmin, _ := c.Get("is_admin"); isAdmin != true && !isOrganizationAdmin(c) {
+		permissions, err := h.userPermissions(c)
+		if err != nil {
+			return nil, err
+		}
+		if isScoped(permissions) {
+			grants = append(grants, permissions)
+		}
+	}
+
+	if scoped, ok := c.Get("token_permissions"); ok {
+		grants = append(grants, scoped.([]db.Permission))
+	}
+
+	return grants, nil
+}
+
+// parseScopeRequest works out the scope of a route relative to /clusters, such as
+// /clusters/:name/namespaces/:namespace/pods/:pod or /clusters/:name/resources/:resource/:resname
+func parseScopeRequest(c *gin.Context, route string) (scopeRequest, bool) {
+	segments := strings.Split(strings.Trim(route, "/"), "/")
+	req := scopeRequest{action: scopeAction(c.Request.Method)}
+
+	if len(segments) == 1 {
+		// /clusters
+		return req, true
+	}
+	req.cluster = c.Param("name")
+	rest := segments[2:]
+	if len(rest) == 0 {
+		// Adding, updating and removing clusters is covered by PermissionChecker
+		return req, false
+	}
+
+	collection := 0
+	switch {
+	case rest[0] == "namespaces" && len(rest) <= 2:
+		req.resource = "namespaces"
+		req.namespace = c.Param("namespace")
+	case rest[0] == "namespaces":
+		req.namespace = c.Param("namespace")
+		req.resource = paramOrSegment(c, rest[2])
+		collection = 2
+	case rest[0] == "resources" && len(rest) > 1:
+		req.resource = paramOrSegment(c, rest[1])
+		req.namespace = c.Query("namespace")
+		collection = 1
+	case rest[0] == "argocd" && len(rest) > 1:
+		// /argocd/applications/:namespace/:app
+		req.resource = "applications"
+		req.namespace = paramOrQuery(c, "namespace")
+		collection = 1
+	case (rest[0] == "flux" || rest[0] == "istio") && len(rest) > 1:
+		// /flux/:kind/:namespace/:resname and /istio/:kind/:namespace/:resname
+		req.resource = c.Param("kind")
+		req.namespace = paramOrQuery(c, "namespace")
+		collection = 1
+	case clusterInfoSegments[rest[0]]:
+		req.resource = "clusters"
+		req.namespace = c.Query("namespace")
+	default:
+		req.resource = rest[0]
+		req.namespace = c.Query("namespace")
+	}
+
+	// POST on an object (evict, restart, scale, ...) changes it rather than creating one
+	if req.action == "create" && len(rest) > collection+1 {
+		req.action = "update"
+	}
+	// A shell runs commands in the pod and an interactive drain evicts the pods of the
+	// node, which is never a read
+	if last := rest[len(rest)-1]; last == "shell" || last == "drain" {
+		req.action = "update"
+	}
+
+	return req, true
+}
+
+func paramOrSegment(c *gin.Context, segment string) string {
+	if strings.HasPrefix(segment, ":") {
+		return c.Param(segment[1:])
+	}
+	return segment
+}
+
+// paramOrQuery returns the route parameter key, or the query parameter of that name on
+// routes without it (such as lists across namespaces)
+func paramOrQuery(c *gin.Context, key string) string {
+	if value := c.Param(key); value != "" {
+		return value
+	}
+	return c.Query(key)
+}
+
+// scopeAction maps an HTTP method to the permission action it needs
+func scopeAction(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return "read"
+	case http.MethodPost:
+		return "create"
+	case http.MethodDelete:
+		return "delete"
+	default:
+		return "update"
+	}
+}
+
+func denyScope(c *gin.Context, req scopeRequest) {
+	log.Warnf("User %d denied %s on %s in cluster %s namespace %q", c.GetInt("user_id"), req.action, req.resource, req.cluster, req.namespace)
+	c.JSON(http.StatusForbidden, gin.H{
+		"error": "no access to this resource in this cluster or namespace",
+		"required": gin.H{
+			"resource":  req.resource,
+			"action":    req.action,
+			"cluster":   req.cluster,
+			"namespace": req.namespace,
+		},
+	})
+	c.Abort()
+}
+
+// isScoped reports whether any permission is restricted to some clusters or namespaces
+func isScoped(permissions []db.Permission) bool {
+	for _, perm := range permissions {
+		if isRestricted(perm.Clusters) || isRestricted(perm.Namespaces) {
+			return true
+		}
+	}
+	return false
+}
+
+func isRestricted(patterns []string) bool {
+	for _, p := range patterns {
+		if p == "*" {
+			return false
+		}
+	}
+	return len(patterns) > 0
+}
+
+// matchesScope reports whether a cluster or namespace name matches a list of globs.
+// An empty list or "*" matches everything.
+func matchesScope(patterns []string, name string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, p := range patterns {
+		if p == "*" || p == name {
+			return true
+		}
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// allowsScope reports whether a permission covers a request. A request without a
+// namespace (cluster-scoped objects, cluster-wide writes) needs unrestricted namespaces;
+// an empty resource matches any resource.
+func allowsScope(perm db.Permission, req scopeRequest) bool {
+	if req.resource != "" && perm.Resource != "*" && perm.Resource != req.resource {
+		return false
+	}
+	if !containsAction(perm.Actions, req.action) || !matchesScope(perm.Clusters, req.cluster) {
+		return false
+	}
+	if req.namespace == "" {
+		return !isRestricted(perm.Namespaces)
+	}
+	return matchesScope(perm.Namespaces, req.namespace)
+}
+
+func containsAction(actions []string, action string) bool {
+	for _, a := range actions {
+		if a == "*" || a == action {
+			return true
+		}
+	}
+	return false
+}
+
+func allowedByAll(grants [][]db.Permission, req scopeRequest) bool {
+	for _, permissions := range grants {
+		allowed := false
+		for _, perm := range permissions {
+			if allowsScope(perm, req) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return false
+		}
+	}
+	return true
+}
+
+// allowedSomewhereByAll is allowedByAll ignoring the namespace: the request is allowed in
+// at least one namespace of the cluster
+func allowedSomewhereByAll(grants [][]db.Permission, req scopeRequest) bool {
+	for _, permissions := range grants {
+		allowed := false
+		for _, perm := range permissions {
+			if (req.resource == "" || perm.Resource == "*" || perm.Resource == req.resource) &&
+				containsAction(perm.Actions, req.action) && matchesScope(perm.Clusters, req.cluster) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return false
+		}
+	}
+	return true
+}
+
+// objectOf returns the Kubernetes object of a list item, which some handlers wrap
+func objectOf(item map[string]interface{}) map[string]interface{} {
+	if _, ok := item["metadata"]; ok {
+		return item
+	}
+	for _, key := range []string{"object", "resource"} {
+		if obj, ok := item[key].(map[string]interface{}); ok {
+			return obj
+		}
+	}
+	return item
+}
+
+func namespaceOf(item map[string]interface{}) string {
+	if meta, ok := item["metadata"].(map[string]interface{}); ok {
+		namespace, _ := meta["namespace"].(string)
+		return namespace
+	}
+	namespace, _ := item["namespace"].(string)
+	return namespace
+}
+
+// filterResponse runs the rest of the chain and drops the list items keep rejects from
+// its JSON or NDJSON response. A single object outside the scope is replaced by a 403.
+func (h *Handler) filterResponse(c *gin.Context, keep func(item map[string]interface{}) bool) {
+	w := &scopeWriter{ResponseWriter: c.Writer, keep: keep}
+	c.Writer = w
+	c.Next()
+	c.Writer = w.ResponseWriter
+	w.finish()
+}
+
+// errUnfilterable stops handlers writing a response the scope cannot be checked on
+var errUnfilterable = errors.New("response cannot be filtered by scope")
+
+// scopeWriter holds back a JSON response until the handler is done so it can be
+// filtered, and filters NDJSON line by line so streamed lists keep streaming. Other
+// content (text, YAML, event streams) is refused.
+type scopeWriter struct {
+	gin.ResponseWriter
+	keep    func(item map[string]interface{}) bool
+	status  int
+	ndjson  bool
+	started bool
+	denied  bool
+	buf     bytes.Buffer
+}
+
+func (w *scopeWriter) WriteHeader(code int) {
+	w.status = code
+}
+
+func (w *scopeWriter) WriteHeaderNow() {}
+
+func (w *scopeWriter) Status() int {
+	if w.status != 0 {
+		return w.status
+	}
+	return w.ResponseWriter.Status()
+}
+
+func (w *scopeWriter) Write(data []byte) (int, error) {
+	if !w.started {
+		w.started = true
+		contentType := w.Header().Get("Content-Type")
+		w.ndjson = strings.HasPrefix(contentType, "application/x-ndjson")
+		w.denied = !w.ndjson && !strings.HasPrefix(contentType, "application/json") && w.Status() >= 200 && w.Status() < 300
+		if w.ndjson {
+			w.ResponseWriter.WriteHeader(w.Status())
+		}
+	}
+	if w.denied {
+		return 0, errUnfilterable
+	}
+	w.buf.Write(data)
+	if w.ndjson {
+		w.flushLines(false)
+	}
+	return len(data), nil
+}
+
+func (w *scopeWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *scopeWriter) Flush() {
+	if w.ndjson {
+		w.ResponseWriter.Flush()
+	}
+}
+
+// flushLines writes the complete NDJSON lines buffered so far, or all of them at the end
+func (w *scopeWriter) flushLines(final bool) {
+	for {
+		line, err := w.buf.ReadBytes('\n')
+		if err != nil {
+			if !final {
+				// Incomplete line: keep it for the next write
+				rest := append([]byte(nil), line...)
+				w.buf.Reset()
+				w.buf.Write(rest)
+				return
+			}
+			if len(bytes.TrimSpace(line)) == 0 {
+				return
+			}
+		}
+		var item map[string]interface{}
+		if json.Unmarshal(line, &item) != nil || item["error"] != nil || w.keep(item) {
+			w.ResponseWriter.Write(line)
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (w *scopeWriter) finish() {
+	if w.ndjson {
+		w.flushLines(true)
+		return
+	}
+
+	status := w.Status()
+	body := w.buf.Bytes()
+	if w.denied || (status >= 200 && status < 300 && strings.HasPrefix(w.Header().Get("Content-Type"), "application/json")) {
+		filtered, allowed := body, false
+		if !w.denied {
+			filtered, allowed = filterJSON(body, w.keep)
+		}
+		if !allowed {
+			w.Header().Del("Content-Length")
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.ResponseWriter.WriteHeader(http.StatusForbidden)
+			w.ResponseWriter.Write([]byte(`{"error":"no access to this resource in this cluster or namespace"}`))
+			return
+		}
+		body = filtered
+	}
+
+	if !w.ResponseWriter.Written() {
+		w.ResponseWriter.WriteHeader(status)
+	}
+	if len(body) > 0 {
+		w.ResponseWriter.Write(body)
+	}
+}
+
+// listKeys are the keys list responses hold their items under, such as {"pods": [...],
+// "total": 3}
+var listKeys = []string{"items", "clusters", "pods", "events", "namespaces", "orphans", "endpointslices"}
+
+// filterJSON filters a list response: a JSON array, or an object holding the list under
+// one of listKeys next to scalars only (a "total" is recounted). Any other object must be
+// a Kubernetes object keep accepts. Other documents, such as summaries of the whole
+// cluster, cannot be filtered and are refused.
+func filterJSON(body []byte, keep func(item map[string]interface{}) bool) ([]byte, bool) {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 {
+		return body, true
+	}
+
+	if trimmed[0] == '[' {
+		var items []json.RawMessage
+		if err := json.Unmarshal(trimmed, &items); err != nil {
+			return nil, false
+		}
+		return mustMarshal(filterItems(items, keep)), true
+	}
+
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(trimmed, &object); err != nil {
+		return nil, false
+	}
+	if _, isObject := object["metadata"]; isObject {
+		var item map[string]interface{}
+		if json.Unmarshal(trimmed, &item) != nil || !keep(item) {
+			return nil, false
+		}
+		return body, true
+	}
+	for _, key := range listKeys {
+		var items []json.RawMessage
+		if raw, ok := object[key]; !ok || json.Unmarshal(raw, &items) != nil {
+			continue
+		}
+		for other, raw := range object {
+			if other != key && len(raw) > 0 && (raw[0] == '{' || raw[0] == '[') {
+				return nil, false
+			}
+		}
+		kept := filterItems(items, keep)
+		object[key] = mustMarshal(kept)
+		if _, ok := object["total"]; ok {
+			object["total"] = mustMarshal(len(kept))
+		}
+		return mustMarshal(object), true
+	}
+	return nil, false
+}
+
+func filterItems(items []json.RawMessage, keep func(item map[string]interface{}) bool) []json.RawMessage {
+	kept := make([]json.RawMessage, 0, len(items))
+	for _, raw := range items {
+		var item map[string]interface{}
+		if json.Unmarshal(raw, &item) != nil || keep(item) {
+			kept = append(kept, raw)
+		}
+	}
+	return kept
+}
+
+func mustMarshal(v interface{}) []byte {
+	data, _ := json.Marshal(v)
+	return data
+}
@@ -0,0 +1,438 @@
+package auth
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+func TestResourceScope(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := &Handler{}
+
+	// Read-only on cluster "prod", restricted to the team-a namespaces
+	grant := []db.Permission{{
+		Resource:   "*",
+		Actions:    []string{"read"},
+		Clusters:   []string{"prod"},
+		Namespaces: []string{"team-a-*"},
+	}}
+
+	router := gin.New()
+	api := router.Group("/api/v1", func(c *gin.Context) {
+		c.Set("user_id", 1)
+		c.Set("is_admin", true)
+		c.Set("token_permissions", grant)
+	}, h.ResourceScope())
+
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	api.GET("/clusters", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"clusters": []gin.H{{"name": "prod"}, {"name": "dev"}}})
+	})
+	api.GET("/clusters/:name/pods", func(c *gin.Context) {
+		c.JSON(http.StatusOK, []gin.H{
+			{"metadata": gin.H{"name": "a", "namespace": "team-a-web"}},
+			{"metadata": gin.H{"name": "b", "namespace": "team-b"}},
+		})
+	})
+	api.GET("/clusters/:name/nodes/:node", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"metadata": gin.H{"name": "node-1"}})
+	})
+	api.GET("/clusters/:name/namespaces/:namespace/pods/:pod", ok)
+	api.DELETE("/clusters/:name/namespaces/:namespace/pods/:pod", ok)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		want   int
+		items  int
+	}{
+		{name: "read inside the grant", method: http.MethodGet, path: "/api/v1/clusters/prod/namespaces/team-a-web/pods/a", want: http.StatusOK},
+		{name: "read in another namespace", method: http.MethodGet, path: "/api/v1/clusters/prod/namespaces/team-b/pods/b", want: http.StatusForbidden},
+		{name: "read in another cluster", method: http.MethodGet, path: "/api/v1/clusters/dev/namespaces/team-a-web/pods/a", want: http.StatusForbidden},
+		{name: "write inside the grant", method: http.MethodDelete, path: "/api/v1/clusters/prod/namespaces/team-a-web/pods/a", want: http.StatusForbidden},
+		{name: "list filtered to the grant", method: http.MethodGet, path: "/api/v1/clusters/prod/pods", want: http.StatusOK, items: 1},
+		{name: "cluster-scoped object", method: http.MethodGet, path: "/api/v1/clusters/prod/nodes/node-1", want: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+			if tt.items > 0 {
+				var items []map[string]interface{}
+				if err := json.Unmarshal(w.Body.Bytes(), &items); err != nil {
+					t.Fatalf("invalid body %s: %v", w.Body.String(), err)
+				}
+				if len(items) != tt.items {
+					t.Errorf("got %d items, want %d: %s", len(items), tt.items, w.Body.String())
+				}
+			}
+		})
+	}
+
+	t.Run("cluster list", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/clusters", nil))
+		var body struct {
+			Clusters []struct {
+				Name string `json:"name"`
+			} `json:"clusters"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("invalid body %s: %v", w.Body.String(), err)
+		}
+		if len(body.Clusters) != 1 || body.Clusters[0].Name != "prod" {
+			t.Errorf("clusters = %+v, want only prod", body.Clusters)
+		}
+	})
+}
+
+// TestResourceScopeNamespacedRoutes checks that the Argo CD, Flux and Istio routes are
+// scoped by the namespace in their path and by the resource they act on
+func TestResourceScopeNamespacedRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := &Handler{}
+
+	// Read and update Applications, Kustomizations and VirtualServices in team-a only
+	var grant []db.Permission
+	for _, resource := range []string{"applications", "kustomizations", "virtualservices"} {
+		grant = append(grant, db.Permission{
+			Resource:   resource,
+			Actions:    []string{"read", "update"},
+			Clusters:   []string{"prod"},
+			Namespaces: []string{"team-a"},
+		})
+	}
+
+	router := gin.New()
+	api := router.Group("/api/v1", func(c *gin.Context) {
+		c.Set("user_id", 1)
+		c.Set("is_admin", true)
+		c.Set("token_permissions", grant)
+	}, h.ResourceScope())
+
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	api.GET("/clusters/:name/argocd/applications/:namespace/:app", ok)
+	api.POST("/clusters/:name/argocd/applications/:namespace/:app/refresh", ok)
+	api.POST("/clusters/:name/argocd/applications/:namespace/:app/sync", ok)
+	api.GET("/clusters/:name/flux/:kind/:namespace/:resname", ok)
+	api.POST("/clusters/:name/flux/:kind/:namespace/:resname/suspend", ok)
+	api.POST("/clusters/:name/flux/:kind/:namespace/:resname/resume", ok)
+	api.POST("/clusters/:name/flux/:kind/:namespace/:resname/reconcile", ok)
+	api.GET("/clusters/:name/istio/:kind/:namespace/:resname", ok)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		want   int
+	}{
+		{name: "argocd get", method: http.MethodGet, path: "/api/v1/clusters/prod/argocd/applications/team-a/web", want: http.StatusOK},
+		{name: "argocd get in another namespace", method: http.MethodGet, path: "/api/v1/clusters/prod/argocd/applications/team-b/web", want: http.StatusForbidden},
+		{name: "argocd query cannot override the path", method: http.MethodGet, path: "/api/v1/clusters/prod/argocd/applications/team-b/web?namespace=team-a", want: http.StatusForbidden},
+		{name: "argocd refresh", method: http.MethodPost, path: "/api/v1/clusters/prod/argocd/applications/team-a/web/refresh", want: http.StatusOK},
+		{name: "argocd refresh in another namespace", method: http.MethodPost, path: "/api/v1/clusters/prod/argocd/applications/team-b/web/refresh", want: http.StatusForbidden},
+		{name: "argocd sync", method: http.MethodPost, path: "/api/v1/clusters/prod/argocd/applications/team-a/web/sync", want: http.StatusOK},
+		{name: "argocd sync in another namespace", method: http.MethodPost, path: "/api/v1/clusters/prod/argocd/applications/team-b/web/sync", want: http.StatusForbidden},
+		{name: "flux get", method: http.MethodGet, path: "/api/v1/clusters/prod/flux/kustomizations/team-a/apps", want: http.StatusOK},
+		{name: "flux get in another namespace", method: http.MethodGet, path: "/api/v1/clusters/prod/flux/kustomizations/team-b/apps", want: http.StatusForbidden},
+		{name: "flux get of an ungranted kind", method: http.MethodGet, path: "/api/v1/clusters/prod/flux/helmreleases/team-a/apps", want: http.StatusForbidden},
+		{name: "flux suspend", method: http.MethodPost, path: "/api/v1/clusters/prod/flux/kustomizations/team-a/apps/suspend", want: http.StatusOK},
+		{name: "flux suspend in another namespace", method: http.MethodPost, path: "/api/v1/clusters/prod/flux/kustomizations/team-b/apps/suspend", want: http.StatusForbidden},
+		{name: "flux resume", method: http.MethodPost, path: "/api/v1/clusters/prod/flux/kustomizations/team-a/apps/resume", want: http.StatusOK},
+		{name: "flux resume in another namespace", method: http.MethodPost, path: "/api/v1/clusters/prod/flux/kustomizations/team-b/apps/resume", want: http.StatusForbidden},
+		{name: "flux reconcile", method: http.MethodPost, path: "/api/v1/clusters/prod/flux/kustomizations/team-a/apps/reconcile", want: http.StatusOK},
+		{name: "flux reconcile in another namespace", method: http.MethodPost, path: "/api/v1/clusters/prod/flux/kustomizations/team-b/apps/reconcile", want: http.StatusForbidden},
+		{name: "istio get", method: http.MethodGet, path: "/api/v1/clusters/prod/istio/virtualservices/team-a/web", want: http.StatusOK},
+		{name: "istio get in another namespace", method: http.MethodGet, path: "/api/v1/clusters/prod/istio/virtualservices/team-b/web", want: http.StatusForbidden},
+		{name: "istio get of an ungranted kind", method: http.MethodGet, path: "/api/v1/clusters/prod/istio/gateways/team-a/web", want: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+}
+
+// TestResourceScopeClusterJobRoutes checks the interactive node drain and the drain job and
+// crash report routes, which name their cluster outside the path
+func TestResourceScopeClusterJobRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := &Handler{}
+
+	// Read nodes of cluster "prod", read pods of its team-a namespace
+	grant := []db.Permission{
+		{Resource: "nodes", Actions: []string{"read"}, Clusters: []string{"prod"}},
+		{Resource: "pods", Actions: []string{"read"}, Clusters: []string{"prod"}, Namespaces: []string{"team-a"}},
+	}
+
+	router := gin.New()
+	api := router.Group("/api/v1", func(c *gin.Context) {
+		c.Set("user_id", 1)
+		c.Set("is_admin", true)
+		c.Set("token_permissions", grant)
+	}, h.ResourceScope())
+
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	api.GET("/clusters/:name/nodes/:node/drain", ok)
+	api.GET("/drains", func(c *gin.Context) {
+		if CheckClusterScope(c, c.Query("cluster"), "nodes", "", "read") {
+			c.Status(http.StatusOK)
+		}
+	})
+	api.POST("/drains", func(c *gin.Context) {
+		if CheckClusterScope(c, c.Query("cluster"), "nodes", "", "update") {
+			c.Status(http.StatusCreated)
+		}
+	})
+	api.GET("/crash-reports", func(c *gin.Context) {
+		visible := ClusterScopeFilter(c, "pods")
+		var reports []gin.H
+		for _, report := range []gin.H{{"cluster": "prod", "namespace": "team-a"}, {"cluster": "prod", "namespace": "team-b"}, {"cluster": "dev", "namespace": "team-a"}} {
+			if visible == nil || visible(report["cluster"].(string), report["namespace"].(string)) {
+				reports = append(reports, report)
+			}
+		}
+		c.JSON(http.StatusOK, reports)
+	})
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		want   int
+	}{
+		{name: "interactive drain needs update", method: http.MethodGet, path: "/api/v1/clusters/prod/nodes/node-1/drain", want: http.StatusForbidden},
+		{name: "drain jobs of the cluster", method: http.MethodGet, path: "/api/v1/drains?cluster=prod", want: http.StatusOK},
+		{name: "drain jobs of another cluster", method: http.MethodGet, path: "/api/v1/drains?cluster=dev", want: http.StatusForbidden},
+		{name: "drain job creation needs update", method: http.MethodPost, path: "/api/v1/drains?cluster=prod", want: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/crash-reports", nil))
+	var reports []map[string]string
+	if err := json.Unmarshal(w.Body.Bytes(), &reports); err != nil {
+		t.Fatalf("invalid body %s: %v", w.Body.String(), err)
+	}
+	if len(reports) != 1 || reports[0]["cluster"] != "prod" || reports[0]["namespace"] != "team-a" {
+		t.Errorf("crash reports = %v, want only those of prod/team-a", reports)
+	}
+}
+
+// TestResourceScopeClusterJobRoutesOrganization checks that the drain job and API token
+// routes keep to the clusters and tokens of the caller's organization
+func TestResourceScopeClusterJobRoutesOrganization(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database, err := db.New(filepath.Join(t.TempDir(), "kubelens.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	admin := &db.User{Email: "admin@example.com", Username: "admin", IsAdmin: true}
+	if err := database.CreateUser(admin); err != nil {
+		t.Fatal(err)
+	}
+	other := &db.Organization{Name: "other"}
+	if err := database.CreateOrganization(other, 0); err != nil {
+		t.Fatal(err)
+	}
+	for _, cluster := range []*db.Cluster{
+		{Name: "dev", AuthType: "token", AuthConfig: db.JSON("{}"), OrganizationID: db.DefaultOrganizationID},
+		{Name: "prod", AuthType: "token", AuthConfig: db.JSON("{}"), OrganizationID: other.ID},
+	} {
+		if err := database.CreateCluster(cluster); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, token := range []*db.APIToken{
+		{UserID: admin.ID, Name: "here", TokenHash: "hash-here", OrganizationID: db.DefaultOrganizationID},
+		{UserID: admin.ID, Name: "there", TokenHash: "hash-there", OrganizationID: other.ID},
+	} {
+		if err := database.CreateAPIToken(token); err != nil {
+			t.Fatal(err)
+		}
+	}
+	h := NewHandler(database, "test-secret", nil)
+
+	router := gin.New()
+	api := router.Group("/api/v1", func(c *gin.Context) {
+		c.Set("user_id", int(admin.ID))
+		c.Set("is_admin", true)
+		c.Set("org_id", uint(db.DefaultOrganizationID))
+	}, h.ResourceScope())
+	api.GET("/drains", func(c *gin.Context) {
+		if CheckClusterScope(c, c.Query("cluster"), "nodes", "", "read") {
+			c.Status(http.StatusOK)
+		}
+	})
+	api.GET("/api-tokens", h.ListAPITokens)
+
+	for cluster, want := range map[string]int{"dev": http.StatusOK, "prod": http.StatusNotFound} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/drains?cluster="+cluster, nil))
+		if w.Code != want {
+			t.Errorf("drain jobs of %s: status = %d, want %d", cluster, w.Code, want)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/api-tokens", nil))
+	var tokens []db.APIToken
+	if err := json.Unmarshal(w.Body.Bytes(), &tokens); err != nil {
+		t.Fatalf("invalid body %s: %v", w.Body.String(), err)
+	}
+	if len(tokens) != 1 || tokens[0].Name != "here" {
+		t.Errorf("API tokens = %v, want only the one of the caller's organization", tokens)
+	}
+}
+
+// TestResourceScopeTokenPermissions checks that an API token without cluster or namespace
+// restrictions still only reaches the resources it grants on the cluster routes
+func TestResourceScopeTokenPermissions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database, err := db.New(filepath.Join(t.TempDir(), "kubelens.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	user := &db.User{Email: "ci@example.com", Username: "ci"}
+	if err := database.CreateUser(user); err != nil {
+		t.Fatal(err)
+	}
+	h := NewHandler(database, "test-secret", nil)
+
+	router := gin.New()
+	api := router.Group("/api/v1", func(c *gin.Context) {
+		c.Set("user_id", int(user.ID))
+		c.Set("is_admin", false)
+		c.Set("token_permissions", []db.Permission{
+			{Resource: "users", Actions: []string{"read"}},
+			{Resource: "deployments", Actions: []string{"read"}},
+		})
+	}, h.ResourceScope())
+
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	api.GET("/clusters/:name/namespaces/:namespace/pods/:pod", ok)
+	api.GET("/clusters/:name/namespaces/:namespace/deployments/:deployment", ok)
+	api.PATCH("/clusters/:name/namespaces/:namespace/deployments/:deployment/scale", ok)
+	api.GET("/clusters/:name/status", ok)
+
+	tests := []struct {
+		method string
+		path   string
+		want   int
+	}{
+		{http.MethodGet, "/api/v1/clusters/prod/namespaces/shop/pods/web", http.StatusForbidden},
+		{http.MethodGet, "/api/v1/clusters/prod/status", http.StatusForbidden},
+		{http.MethodGet, "/api/v1/clusters/prod/namespaces/shop/deployments/web", http.StatusOK},
+		{http.MethodPatch, "/api/v1/clusters/prod/namespaces/shop/deployments/web/scale", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+		if w.Code != tt.want {
+			t.Errorf("%s %s: status = %d, want %d: %s", tt.method, tt.path, w.Code, tt.want, w.Body.String())
+		}
+	}
+}
+
+func TestTopicScope(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := &Handler{}
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Set("is_admin", true)
+
+	if scope, err := h.TopicScope(c); err != nil || scope != nil {
+		t.Fatalf("TopicScope() = %v, %v; want no scope for an admin", scope, err)
+	}
+
+	c.Set("token_permissions", []db.Permission{{
+		Resource:   "events",
+		Actions:    []string{"read"},
+		Clusters:   []string{"prod"},
+		Namespaces: []string{"team-a"},
+	}})
+	scope, err := h.TopicScope(c)
+	if err != nil || scope == nil {
+		t.Fatalf("TopicScope() = %v, %v; want the scope of the token", scope, err)
+	}
+	if !scope.Allows("prod", "events", "team-a") || scope.Allows("prod", "events", "team-b") || scope.Allows("prod", "events", "") {
+		t.Error("events should only be allowed in team-a")
+	}
+	if !scope.AllowsSomewhere("prod", "") || scope.AllowsSomewhere("dev", "") || scope.AllowsSomewhere("prod", "pods") {
+		t.Error("only prod and its events should be allowed somewhere")
+	}
+}
+
+func TestFilterJSON(t *testing.T) {
+	keep := func(item map[string]interface{}) bool {
+		return namespaceOf(objectOf(item)) == "team-a"
+	}
+	tests := []struct {
+		name    string
+		body    string
+		want    string
+		allowed bool
+	}{
+		{"array", `[{"namespace":"team-a"},{"namespace":"team-b"}]`, `[{"namespace":"team-a"}]`, true},
+		{"items", `{"items":[{"metadata":{"namespace":"team-b"}}],"continue":""}`, `{"continue":"","items":[]}`, true},
+		{"top pods", `{"pods":[{"namespace":"team-a"},{"namespace":"team-b"}],"total":2,"sortBy":"cpu"}`, `{"pods":[{"namespace":"team-a"}],"sortBy":"cpu","total":1}`, true},
+		{"object in scope", `{"metadata":{"namespace":"team-a"}}`, `{"metadata":{"namespace":"team-a"}}`, true},
+		{"object out of scope", `{"metadata":{"namespace":"team-b"}}`, "", false},
+		{"cluster summary", `{"totalPods":3,"runningPods":2}`, "", false},
+		{"list next to an object", `{"object":{"metadata":{"name":"node-1"}},"events":[]}`, "", false},
+		{"not JSON", `pods: 3`, "", false},
+		{"empty", ``, ``, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, allowed := filterJSON([]byte(tt.body), keep)
+			if allowed != tt.allowed || (allowed && string(got) != tt.want) {
+				t.Errorf("filterJSON() = %s, %v; want %s, %v", got, allowed, tt.want, tt.allowed)
+			}
+		})
+	}
+}
+
+func TestMatchesScope(t *testing.T) {
+	tests := []struct {
+		patterns []string
+		name     string
+		want     bool
+	}{
+		{nil, "anything", true},
+		{[]string{"*"}, "anything", true},
+		{[]string{"team-a-*"}, "team-a-web", true},
+		{[]string{"team-a-*"}, "team-b", false},
+		{[]string{"prod", "staging"}, "staging", true},
+	}
+	for _, tt := range tests {
+		if got := matchesScope(tt.patterns, tt.name); got != tt.want {
+			t.Errorf("matchesScope(%v, %q) = %v, want %v", tt.patterns, tt.name, got, tt.want)
+		}
+	}
+}
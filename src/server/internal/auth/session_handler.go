@@ -12,6 +12,9 @@ type UpdateSessionRequest struct {
 	SelectedCluster   string `json:"selected_cluster"`
 	SelectedNamespace string `json:"selected_namespace"`
 	SelectedTheme     string `json:"selected_theme"`
+	// RecentViewsDisabled stops recording the resources the user views and clears those
+	// recorded; left out, the setting is unchanged
+	RecentViewsDisabled *bool `json:"recent_views_disabled"`
 }
 
 // GetSession retrieves the current user's session
@@ -66,6 +69,9 @@ func (h *Handler) UpdateSession(c *gin.Context) {
 	if req.SelectedTheme != "" {
 		session.SelectedTheme = req.SelectedTheme
 	}
+	if req.RecentViewsDisabled != nil {
+		session.RecentViewsDisabled = *req.RecentViewsDisabled
+	}
 
 	if err := h.db.UpdateUserSession(session); err != nil {
 		log.Errorf("Failed to update user session: %v", err)
@@ -73,6 +79,15 @@ func (h *Handler) UpdateSession(c *gin.Context) {
 		return
 	}
 
+	// Turning recent views off forgets the ones recorded
+	if session.RecentViewsDisabled {
+		if _, err := h.db.DeleteRecentViews(session.UserID); err != nil {
+			log.Errorf("Failed to clear recent views: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update session"})
+			return
+		}
+	}
+
 	log.Infof("User %d session updated: cluster=%s, namespace=%s, theme=%s",
 		userID, session.SelectedCluster, session.SelectedNamespace, session.SelectedTheme)
 
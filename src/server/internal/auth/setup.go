@@ -0,0 +1,252 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	"github.com/sonnguyen/kubelens/internal/audit"
+	"github.com/sonnguyen/kubelens/internal/db"
+	"github.com/sonnguyen/kubelens/internal/middleware"
+)
+
+// DefaultJWTSecret is the well-known development secret. It is rejected in release mode.
+const DefaultJWTSecret = "kubelens-secret-change-in-production"
+
+// jwtSecretConfigKey is the system config key of the generated or configured JWT secret
+const jwtSecretConfigKey = "jwt_secret"
+
+// minJWTSecretLength is the minimum length of a JWT secret configured during setup
+const minJWTSecretLength = 32
+
+// ResolveJWTSecret returns the secret used to sign tokens. JWT_SECRET takes precedence,
+// then the secret stored in the database; on first start a random secret is generated
+// and stored so that tokens survive restarts without any configuration; replicas starting
+// together all use the one stored first. The default development secret is refused in
+// release mode.
+func ResolveJWTSecret(database *db.DB, releaseMode bool) (string, error) {
+	if secret := os.Getenv("JWT_SECRET"); secret != "" {
+		if secret == DefaultJWTSecret {
+			if releaseMode {
+				return "", fmt.Errorf("JWT_SECRET is set to the default development secret, refusing to start in release mode")
+			}
+			log.Warn("⚠️  JWT_SECRET is set to the default development secret (not secure for production!)")
+		}
+		return secret, nil
+	}
+
+	if secret, err := database.GetSystemConfig(jwtSecretConfigKey); err == nil && secret != "" {
+		return secret, nil
+	}
+
+	key := make([]byte, 48)
+	if _, err := rand.Read(key); err != nil {
+		return "", fmt.Errorf("failed to generate JWT secret: %w", err)
+	}
+	generated := base64.RawURLEncoding.EncodeToString(key)
+	secret, err := database.CreateSystemConfigIfAbsent(jwtSecretConfigKey, generated)
+	if err != nil {
+		return "", fmt.Errorf("failed to save JWT secret: %w", err)
+	}
+
+	if secret == generated {
+		log.Info("🔑 Generated and saved new JWT secret to database (first install)")
+	}
+	return secret, nil
+}
+
+// SetupHandler handles the first-run setup. While no admin user exists it holds a one-time
+// setup token, printed to the logs at startup, that is required to create the first admin.
+type SetupHandler struct {
+	db          *db.DB
+	localAccess bool // serve the token to clients on the same host
+	mu          sync.Mutex
+	token       string
+}
+
+// NewSetupHandler creates a setup handler and generates a setup token if no admin exists
+// yet. With localAccess, GetToken serves the token to clients on the same host.
+func NewSetupHandler(database *db.DB, localAccess bool) (*SetupHandler, error) {
+	h := &SetupHandler{db: database, localAccess: localAccess}
+
+	hasAdmin, err := database.HasAdminUser()
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for admin user: %w", err)
+	}
+	if hasAdmin {
+		return h, nil
+	}
+
+	raw := make([]byte, 24)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, fmt.Errorf("failed to generate setup token: %w", err)
+	}
+	h.token = hex.EncodeToString(raw)
+
+	log.Warn("🔐 No admin user exists yet. Create one with this one-time setup token:")
+	log.Warnf("🔐     %s", h.token)
+	log.Warn("🔐 POST it to /api/v1/setup or open the web UI. It is regenerated on every restart until setup is done.")
+
+	return h, nil
+}
+
+// SetupRequired reports whether the first admin has not been created yet
+func (h *SetupHandler) SetupRequired() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.token != ""
+}
+
+// GetStatus reports whether setup is still required
+func (h *SetupHandler) GetStatus(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"setup_required": h.SetupRequired()})
+}
+
+// GetToken returns the setup token to clients on the same host as the server, for
+// installers that cannot read the logs, when enabled by KUBELENS_SETUP_TOKEN_LOCAL_ACCESS:
+// behind a proxy on the same host, every request would look local. Requests that went
+// through a proxy setting forwarding headers are refused.
+func (h *SetupHandler) GetToken(c *gin.Context) {
+	if !h.localAccess {
+		c.JSON(http.StatusForbidden, gin.H{"error": "setup token is only available from the server logs"})
+		return
+	}
+	if !isLocalRequest(c.Request) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "setup token is only available from localhost"})
+		return
+	}
+
+	h.mu.Lock()
+	token := h.token
+	h.mu.Unlock()
+
+	if token == "" {
+		c.JSON(http.StatusGone, gin.H{"error": "setup already completed"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"token": token})
+}
+
+// CompleteSetup creates the first admin user. The setup token is consumed on success.
+// jwt_secret optionally replaces the generated JWT secret; it takes effect after a restart
+// and cannot be set when JWT_SECRET is provided through the environment.
+func (h *SetupHandler) CompleteSetup(c *gin.Context) {
+	var req struct {
+		Token     string `json:"token" binding:"required"`
+		Email     string `json:"email" binding:"required,email"`
+		Username  string `json:"username" binding:"required,min=3"`
+		Password  string `json:"password" binding:"required,min=8"`
+		FullName  string `json:"full_name"`
+		JWTSecret string `json:"jwt_secret"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	req.Email = middleware.SanitizeString(req.Email)
+	req.Username = middleware.SanitizeString(req.Username)
+	req.FullName = middleware.SanitizeString(req.FullName)
+
+	if valid, msg := middleware.ValidatePassword(req.Password); !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+	if req.JWTSecret != "" {
+		if os.Getenv("JWT_SECRET") != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "JWT secret is set by the JWT_SECRET environment variable"})
+			return
+		}
+		if len(req.JWTSecret) < minJWTSecretLength || req.JWTSecret == DefaultJWTSecret {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("JWT secret must be at least %d characters and not the default", minJWTSecretLength)})
+			return
+		}
+	}
+
+	// Hold the lock for the whole setup so the token can only be used once
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.token == "" {
+		c.JSON(http.StatusGone, gin.H{"error": "setup already completed"})
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(req.Token), []byte(h.token)) != 1 {
+		log.Warnf("Invalid setup token from %s", c.ClientIP())
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid setup token"})
+		return
+	}
+
+	passwordHash, err := HashPassword(req.Password)
+	if err != nil {
+		log.Errorf("Failed to hash password: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create admin"})
+		return
+	}
+
+	user, err := h.db.CreateInitialAdmin(req.Username, req.Email, req.FullName, passwordHash)
+	if errors.Is(err, db.ErrAdminExists) {
+		// Another replica completed the setup
+		h.token = ""
+		c.JSON(http.StatusGone, gin.H{"error": "setup already completed"})
+		return
+	}
+	if err != nil {
+		log.Errorf("Failed to create initial admin: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create admin"})
+		return
+	}
+
+	restartRequired := false
+	if req.JWTSecret != "" {
+		if err := h.db.SetSystemConfig(jwtSecretConfigKey, req.JWTSecret); err != nil {
+			log.Errorf("Failed to save JWT secret: %v", err)
+		} else {
+			restartRequired = true
+		}
+	}
+
+	h.token = ""
+	log.Infof("✅ Initial admin created through setup: %s (%s)", user.Email, user.Username)
+
+	audit.Log(c, audit.EventAuditUserCreated, int(user.ID), user.Username, user.Email,
+		fmt.Sprintf("Created initial admin %s with the setup token", user.Username),
+		map[string]interface{}{
+			"target_user_id":     user.ID,
+			"jwt_secret_changed": restartRequired,
+		})
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":          "Setup completed",
+		"restart_required": restartRequired,
+		"user": gin.H{
+			"id":       user.ID,
+			"email":    user.Email,
+			"username": user.Username,
+			"is_admin": user.IsAdmin,
+		},
+	})
+}
+
+// isLocalRequest reports whether a request came directly from a loopback address
+func isLocalRequest(r *http.Request) bool {
+	if r.Header.Get("X-Forwarded-For") != "" || r.Header.Get("X-Real-IP") != "" || r.Header.Get("Forwarded") != "" {
+		return false
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,130 @@
+package auth
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+func TestIsLocalRequest(t *testing.T) {
+	tests := []struct {
+		name       string
+		remoteAddr string
+		header     string
+		want       bool
+	}{
+		{name: "ipv4 loopback", remoteAddr: "127.0.0.1:51234", want: true},
+		{name: "ipv6 loopback", remoteAddr: "[::1]:51234", want: true},
+		{name: "remote address", remoteAddr: "10.0.0.7:51234", want: false},
+		{name: "proxied request", remoteAddr: "127.0.0.1:51234", header: "203.0.113.9", want: false},
+		{name: "malformed address", remoteAddr: "localhost", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/v1/setup/token", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.header != "" {
+				req.Header.Set("X-Forwarded-For", tt.header)
+			}
+			if got := isLocalRequest(req); got != tt.want {
+				t.Errorf("isLocalRequest() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveJWTSecretRefusesDefaultInReleaseMode(t *testing.T) {
+	t.Setenv("JWT_SECRET", DefaultJWTSecret)
+
+	if _, err := ResolveJWTSecret(nil, true); err == nil {
+		t.Error("ResolveJWTSecret() accepted the default secret in release mode")
+	}
+
+	secret, err := ResolveJWTSecret(nil, false)
+	if err != nil || secret != DefaultJWTSecret {
+		t.Errorf("ResolveJWTSecret() = %q, %v; want the default secret outside release mode", secret, err)
+	}
+}
+
+func TestSetupTokenLocalAccess(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	database, err := db.New(filepath.Join(t.TempDir(), "kubelens.db"))
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	tests := []struct {
+		name        string
+		localAccess bool
+		remoteAddr  string
+		want        int
+	}{
+		{name: "disabled", localAccess: false, remoteAddr: "127.0.0.1:51234", want: http.StatusForbidden},
+		{name: "enabled from loopback", localAccess: true, remoteAddr: "127.0.0.1:51234", want: http.StatusOK},
+		{name: "enabled from a remote address", localAccess: true, remoteAddr: "10.0.0.7:51234", want: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, err := NewSetupHandler(database, tt.localAccess)
+			if err != nil {
+				t.Fatal(err)
+			}
+			router := gin.New()
+			router.GET("/api/v1/setup/token", h.GetToken)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/setup/token", nil)
+			req.RemoteAddr = tt.remoteAddr
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestCompleteSetupAfterAnotherReplica(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	database, err := db.New(filepath.Join(t.TempDir(), "kubelens.db"))
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	h, err := NewSetupHandler(database, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Another replica, with its own token, creates the first admin
+	if _, err := database.CreateInitialAdmin("admin", "admin@kubelens.local", "Administrator", "not-a-password-hash"); err != nil {
+		t.Fatal(err)
+	}
+
+	router := gin.New()
+	router.POST("/api/v1/setup", h.CompleteSetup)
+	body := fmt.Sprintf(`{"token":%q,"email":"second@kubelens.local","username":"second","password":"Str0ng!Passw0rd"}`, h.token)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/setup", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusGone {
+		t.Errorf("status = %d, want %d: %s", w.Code, http.StatusGone, w.Body.String())
+	}
+	if h.SetupRequired() {
+		t.Error("SetupRequired() = true after another replica completed the setup")
+	}
+	if _, err := database.GetUserByEmail("second@kubelens.local"); err == nil {
+		t.Error("a second admin was created")
+	}
+}
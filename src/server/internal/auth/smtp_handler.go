@@ -0,0 +1,144 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/sonnguyen/kubelens/internal/audit"
+	"github.com/sonnguyen/kubelens/internal/mail"
+)
+
+// smtpSettings is the API representation of the SMTP settings; the password is write-only
+type smtpSettings struct {
+	mail.Config
+	PasswordSet bool `json:"password_set"`
+}
+
+func newSMTPSettings(cfg *mail.Config) smtpSettings {
+	if cfg == nil {
+		cfg = &mail.Config{Security: mail.SecurityStartTLS, Port: 587}
+	}
+	settings := smtpSettings{Config: *cfg, PasswordSet: cfg.Password != ""}
+	settings.Password = ""
+	return settings
+}
+
+// GetSMTPSettings handles GET /api/v1/system/smtp
+func (h *Handler) GetSMTPSettings(c *gin.Context) {
+	cfg, err := mail.Load(h.db)
+	if err != nil {
+		log.Errorf("Failed to load SMTP config: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load SMTP settings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, newSMTPSettings(cfg))
+}
+
+// UpdateSMTPSettings handles PUT /api/v1/system/smtp. An empty password keeps the stored
+// one.
+func (h *Handler) UpdateSMTPSettings(c *gin.Context) {
+	var req mail.Config
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	current, err := mail.Load(h.db)
+	if err != nil {
+		log.Errorf("Failed to load SMTP config: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load SMTP settings"})
+		return
+	}
+	if req.Password == "" && current != nil && req.Username != "" {
+		req.Password = current.Password
+	}
+
+	if req.Enabled || req.Host != "" {
+		if err := req.Validate(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	if err := mail.Save(h.db, req); err != nil {
+		log.Errorf("Failed to save SMTP config: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save SMTP settings"})
+		return
+	}
+
+	log.Infof("SMTP settings updated (enabled: %v, host: %s)", req.Enabled, req.Host)
+
+	// Audit log
+	if userID, exists := c.Get("user_id"); exists {
+		username, _ := c.Get("username")
+		email, _ := c.Get("email")
+		audit.Log(c, audit.EventAuditConfigChanged, userID.(int), username.(string), email.(string),
+			"Updated SMTP settings",
+			map[string]interface{}{
+				"enabled":  req.Enabled,
+				"host":     req.Host,
+				"port":     req.Port,
+				"security": req.Security,
+				"from":     req.From,
+			})
+	}
+
+	c.JSON(http.StatusOK, newSMTPSettings(&req))
+}
+
+// TestSMTPSettings handles POST /api/v1/system/smtp/test. It sends a test message with
+// the saved settings, even when they are disabled, to the given address or to the
+// current user.
+func (h *Handler) TestSMTPSettings(c *gin.Context) {
+	var req struct {
+		To string `json:"to"`
+	}
+	// The body is optional
+	_ = c.ShouldBindJSON(&req)
+	if req.To == "" {
+		email, _ := c.Get("email")
+		req.To, _ = email.(string)
+	}
+
+	cfg, err := mail.Load(h.db)
+	if err != nil || cfg == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "SMTP is not configured"})
+		return
+	}
+	if err := cfg.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = mail.Send(cfg, &mail.Message{
+		To:      []string{req.To},
+		Subject: "kubelens test email",
+		Body:    fmt.Sprintf("This is a test message from kubelens (%s).\n\nEmail delivery is working.\n", h.publicURL),
+	})
+	if err != nil {
+		c.JSON(http.StatusOK, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true, "to": req.To})
+}
+
+// sendMail sends a message with the stored SMTP settings, writing the error response when
+// it cannot. Disabled SMTP is a bad request, a failed delivery a bad gateway.
+func (h *Handler) sendMail(c *gin.Context, msg *mail.Message) bool {
+	err := mail.SendMessage(h.db, msg)
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, mail.ErrNotConfigured):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		log.Errorf("Failed to send email to %v: %v", msg.To, err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": fmt.Sprintf("failed to send email: %v", err)})
+	}
+	return false
+}
@@ -15,7 +15,7 @@ func (h *Handler) CreateUser(c *gin.Context) {
 	var req struct {
 		Email    string `json:"email" binding:"required,email"`
 		Username string `json:"username" binding:"required,min=3"`
-		Password string `json:"password" binding:"required,min=8"`
+		Password string `json:"password" binding:"required"`
 		FullName string `json:"full_name"`
 		IsAdmin  bool   `json:"is_admin"`
 		GroupIDs []int  `json:"group_ids" binding:"required,min=1"`
@@ -50,30 +50,36 @@ func (h *Handler) CreateUser(c *gin.Context) {
 		}
 	}
 
-	// Hash password
-	passwordHash, err := HashPassword(req.Password)
-	if err != nil {
-		log.Errorf("Failed to hash password: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create user"})
-		return
-	}
-
 	// Create user
 	user := &db.User{
 		Email:        req.Email,
 		Username:     req.Username,
-		PasswordHash: passwordHash,
 		FullName:     req.FullName,
 		AuthProvider: "local",
 		IsActive:     true,
 		IsAdmin:      req.IsAdmin,
 	}
 
+	// Validate password against the password policy
+	policy := h.loadPasswordPolicy()
+	if err := h.checkNewPassword(policy, req.Password, user); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Hash password
+	if err := setPassword(user, req.Password); err != nil {
+		log.Errorf("Failed to hash password: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create user"})
+		return
+	}
+
 	if err := h.db.CreateUser(user); err != nil {
 		log.Errorf("Failed to create user: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create user"})
 		return
 	}
+	h.recordPassword(policy, user)
 
 	// Add user to groups
 	for _, groupID := range req.GroupIDs {
@@ -188,6 +194,9 @@ func (h *Handler) UpdateUser(c *gin.Context) {
 	
 	// If user is being disabled, revoke all their tokens
 	if wasActive && !user.IsActive {
+		if err := h.db.RevokeUserSessions(uint(id), 0); err != nil {
+			log.Errorf("Failed to revoke sessions for disabled user: %v", err)
+		}
 		if err := h.db.RevokeUserTokens(uint(id)); err != nil {
 			log.Errorf("Failed to revoke tokens for disabled user: %v", err)
 			// Don't fail the request, just log the error
@@ -411,10 +420,10 @@ func (h *Handler) ResetUserPassword(c *gin.Context) {
 	}
 
 	var req struct {
-		NewPassword string `json:"new_password" binding:"required,min=8"`
+		NewPassword string `json:"new_password" binding:"required"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "password must be at least 8 characters"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "new_password is required"})
 		return
 	}
 
@@ -431,21 +440,27 @@ func (h *Handler) ResetUserPassword(c *gin.Context) {
 		return
 	}
 
+	// Validate new password against the password policy
+	policy := h.loadPasswordPolicy()
+	if err := h.checkNewPassword(policy, req.NewPassword, user); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	// Hash new password
-	passwordHash, err := HashPassword(req.NewPassword)
-	if err != nil {
+	if err := setPassword(user, req.NewPassword); err != nil {
 		log.Errorf("Failed to hash password: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to reset password"})
 		return
 	}
 
 	// Update password
-	user.PasswordHash = passwordHash
 	if err := h.db.UpdateUser(user); err != nil {
 		log.Errorf("Failed to update user password: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to reset password"})
 		return
 	}
+	h.recordPassword(policy, user)
 
 	log.Infof("Password reset for user %d by admin", id)
 
@@ -0,0 +1,134 @@
+// Package backup exports the kubelens database as an encrypted archive and restores it,
+// on the same kind of database or another one (e.g. moving from SQLite to PostgreSQL).
+package backup
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+
+	"github.com/sonnguyen/kubelens/internal/crypto"
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+// archiveHeader starts every archive, followed by the key salt and the encrypted snapshot
+const archiveHeader = "KUBELENS-BACKUP 1"
+
+// MinPassphraseLength is the shortest passphrase an archive is encrypted with
+const MinPassphraseLength = 12
+
+// ErrWrongPassphrase is returned for an archive the passphrase does not decrypt
+var ErrWrongPassphrase = errors.New("wrong passphrase or corrupted archive")
+
+// ErrNotArchive is returned for data that is not a kubelens backup archive
+var ErrNotArchive = errors.New("not a kubelens backup archive")
+
+// Seal encodes a snapshot into an archive encrypted with AES-256-GCM, under a key
+// derived from the passphrase with scrypt
+func Seal(snapshot *db.Snapshot, passphrase string) ([]byte, error) {
+	if len(passphrase) < MinPassphraseLength {
+		return nil, fmt.Errorf("passphrase must be at least %d characters", MinPassphraseLength)
+	}
+
+	var plain bytes.Buffer
+	zw := gzip.NewWriter(&plain)
+	if err := gob.NewEncoder(zw).Encode(snapshot); err != nil {
+		return nil, fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	encryptor, err := archiveEncryptor(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := encryptor.Encrypt(plain.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	return []byte(archiveHeader + "\n" + base64.StdEncoding.EncodeToString(salt) + "\n" + sealed + "\n"), nil
+}
+
+// Open decrypts and decodes an archive made by Seal
+func Open(archive []byte, passphrase string) (*db.Snapshot, error) {
+	lines := bufio.NewScanner(bytes.NewReader(archive))
+	lines.Buffer(nil, len(archive)+1)
+	var fields []string
+	for lines.Scan() && len(fields) < 3 {
+		fields = append(fields, strings.TrimSpace(lines.Text()))
+	}
+	if len(fields) != 3 || fields[0] != archiveHeader {
+		return nil, ErrNotArchive
+	}
+	salt, err := base64.StdEncoding.DecodeString(fields[1])
+	if err != nil {
+		return nil, ErrNotArchive
+	}
+
+	encryptor, err := archiveEncryptor(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	plain, err := encryptor.Decrypt(fields[2])
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(plain))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress archive: %w", err)
+	}
+	defer zr.Close()
+	var snapshot db.Snapshot
+	if err := gob.NewDecoder(zr).Decode(&snapshot); err != nil {
+		return nil, fmt.Errorf("failed to decode archive: %w", err)
+	}
+	return &snapshot, nil
+}
+
+// WriteFile exports the database to an archive file, readable by its owner only
+func WriteFile(database *db.DB, path, passphrase string, includeCredentials bool) error {
+	snapshot, err := database.ExportSnapshot(includeCredentials)
+	if err != nil {
+		return err
+	}
+	archive, err := Seal(snapshot, passphrase)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, archive, 0o600)
+}
+
+// RestoreFile restores the database from an archive file
+func RestoreFile(database *db.DB, path, passphrase string) error {
+	archive, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	snapshot, err := Open(archive, passphrase)
+	if err != nil {
+		return err
+	}
+	return database.RestoreSnapshot(snapshot)
+}
+
+func archiveEncryptor(passphrase string, salt []byte) (*crypto.Encryptor, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, 1<<15, 8, 1, 32)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.NewEncryptor(key)
+}
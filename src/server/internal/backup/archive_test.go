@@ -0,0 +1,124 @@
+package backup
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+const testPassphrase = "correct horse battery"
+
+func newDB(t *testing.T) *db.DB {
+	t.Helper()
+	database, err := db.New(filepath.Join(t.TempDir(), "kubelens.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { database.Close() })
+	return database
+}
+
+func TestBackupRoundTrip(t *testing.T) {
+	source := newDB(t)
+	user := &db.User{Email: "ann@example.com", Username: "ann", PasswordHash: "hash", IsActive: false}
+	if err := source.CreateUser(user); err != nil {
+		t.Fatal(err)
+	}
+	// CreateUser applies the column default, as restore has to avoid
+	if err := source.Model(user).UpdateColumn("is_active", false).Error; err != nil {
+		t.Fatal(err)
+	}
+	group, err := source.GetGroupByName("admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := source.AddUserToGroup(user.ID, group.ID); err != nil {
+		t.Fatal(err)
+	}
+	prod := &db.Cluster{Name: "prod", AuthType: "token", AuthConfig: db.JSON(`{"token":"secret"}`), Server: "https://prod", Token: "secret", Enabled: true}
+	lab := &db.Cluster{Name: "lab", AuthType: "token", AuthConfig: db.JSON("{}"), Server: "https://lab", Token: "lab-secret"}
+	for _, cluster := range []*db.Cluster{prod, lab} {
+		if err := source.CreateCluster(cluster); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := source.UpdateClusterEnabled(lab.ID, false); err != nil {
+		t.Fatal(err)
+	}
+
+	snapshot, err := source.ExportSnapshot(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	archive, err := Seal(snapshot, testPassphrase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(archive, "wrong passphrase"); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("Open() with a wrong passphrase = %v, want ErrWrongPassphrase", err)
+	}
+	if _, err := Open([]byte("hello"), testPassphrase); !errors.Is(err, ErrNotArchive) {
+		t.Errorf("Open() of garbage = %v, want ErrNotArchive", err)
+	}
+	opened, err := Open(archive, testPassphrase)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	target := newDB(t)
+	if err := target.CreateCluster(&db.Cluster{Name: "stale", AuthType: "token", AuthConfig: db.JSON("{}")}); err != nil {
+		t.Fatal(err)
+	}
+	if err := target.RestoreSnapshot(opened); err != nil {
+		t.Fatal(err)
+	}
+
+	restored, err := target.GetUserByUsername("ann")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.ID != user.ID || restored.PasswordHash != "hash" || restored.IsActive {
+		t.Errorf("restored user = %+v", restored)
+	}
+	groups, err := target.GetUserGroups(restored.ID)
+	if err != nil || len(groups) != 1 || groups[0].Name != "admin" {
+		t.Errorf("restored user groups = %v, %v", groups, err)
+	}
+	if restored, err := target.GetCluster("prod"); err != nil || restored.Token != "secret" || !restored.Enabled {
+		t.Errorf("restored prod cluster = %+v, %v", restored, err)
+	}
+	if restored, err := target.GetCluster("lab"); err != nil || restored.Enabled {
+		t.Errorf("restored lab cluster = %+v, %v; want disabled", restored, err)
+	}
+	if _, err := target.GetCluster("stale"); err == nil {
+		t.Error("cluster missing from the snapshot was kept")
+	}
+}
+
+func TestBackupWithoutCredentials(t *testing.T) {
+	source := newDB(t)
+	if err := source.CreateCluster(&db.Cluster{Name: "prod", AuthType: "token", AuthConfig: db.JSON(`{"token":"secret"}`), Token: "secret", Enabled: true}); err != nil {
+		t.Fatal(err)
+	}
+	snapshot, err := source.ExportSnapshot(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshot.SystemConfigs) != 0 {
+		t.Errorf("system settings exported without credentials")
+	}
+
+	target := newDB(t)
+	if err := target.RestoreSnapshot(snapshot); err != nil {
+		t.Fatal(err)
+	}
+	prod, err := target.GetCluster("prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if prod.Token != "" || string(prod.AuthConfig) != "{}" || prod.Enabled {
+		t.Errorf("cluster restored without credentials = token %q, auth_config %s, enabled %v", prod.Token, prod.AuthConfig, prod.Enabled)
+	}
+}
@@ -0,0 +1,152 @@
+package backup
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/sonnguyen/kubelens/internal/audit"
+	"github.com/sonnguyen/kubelens/internal/cluster"
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+// maxArchiveSize bounds the size of an uploaded archive
+const maxArchiveSize = 64 << 20
+
+// Handler handles backup and restore API requests
+type Handler struct {
+	db       *db.DB
+	clusters *cluster.Manager
+}
+
+// NewHandler creates a new backup handler
+func NewHandler(database *db.DB, clusters *cluster.Manager) *Handler {
+	return &Handler{db: database, clusters: clusters}
+}
+
+// backupRequest is the body of CreateBackup
+type backupRequest struct {
+	Passphrase string `json:"passphrase" binding:"required"`
+	// IncludeCredentials keeps cluster credentials and system settings in the archive
+	IncludeCredentials bool `json:"include_credentials"`
+}
+
+// RestoreSummary describes what a restore brought back
+type RestoreSummary struct {
+	CreatedAt     time.Time `json:"created_at"`
+	SourceDialect string    `json:"source_dialect"`
+	Credentials   bool      `json:"credentials"`
+	Users         int       `json:"users"`
+	Groups        int       `json:"groups"`
+	Clusters      int       `json:"clusters"`
+}
+
+// CreateBackup handles POST /api/v1/system/backup, returning the encrypted archive
+func (h *Handler) CreateBackup(c *gin.Context) {
+	var req backupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(req.Passphrase) < MinPassphraseLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("passphrase must be at least %d characters", MinPassphraseLength)})
+		return
+	}
+
+	snapshot, err := h.db.ExportSnapshot(req.IncludeCredentials)
+	if err != nil {
+		log.Errorf("Failed to export database snapshot: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export the database"})
+		return
+	}
+	archive, err := Seal(snapshot, req.Passphrase)
+	if err != nil {
+		log.Errorf("Failed to seal backup archive: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create the archive"})
+		return
+	}
+
+	h.audit(c, audit.EventAuditBackupExported, "Exported a database backup", snapshot)
+	filename := fmt.Sprintf("kubelens-backup-%s.klbak", snapshot.CreatedAt.Format("20060102-150405"))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Data(http.StatusOK, "application/octet-stream", archive)
+}
+
+// RestoreBackup handles POST /api/v1/system/restore: a multipart form with the archive
+// file and its passphrase. Everyone is signed out, including the caller.
+func (h *Handler) RestoreBackup(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxArchiveSize+1<<20)
+	file, _, err := c.Request.FormFile("archive")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "archive file is required"})
+		return
+	}
+	defer file.Close()
+	archive, err := io.ReadAll(io.LimitReader(file, maxArchiveSize+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(archive) > maxArchiveSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "archive is too large"})
+		return
+	}
+
+	snapshot, err := Open(archive, c.PostForm("passphrase"))
+	if err != nil {
+		if errors.Is(err, ErrWrongPassphrase) || errors.Is(err, ErrNotArchive) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+	// Recorded before the restore, while the caller still exists
+	h.audit(c, audit.EventAuditBackupRestored, "Restored a database backup", snapshot)
+	if err := h.db.RestoreSnapshot(snapshot); err != nil {
+		log.Errorf("Failed to restore database backup: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if h.clusters != nil {
+		if err := h.clusters.ReloadFromDB(); err != nil {
+			log.Warnf("Failed to reload clusters after restore: %v", err)
+		}
+	}
+	log.Infof("♻️  Restored database backup of %s (%d users, %d clusters)", snapshot.CreatedAt.Format(time.RFC3339), len(snapshot.Users), len(snapshot.Clusters))
+
+	c.JSON(http.StatusOK, summarize(snapshot))
+}
+
+// summarize describes the content of a snapshot
+func summarize(snapshot *db.Snapshot) RestoreSummary {
+	return RestoreSummary{
+		CreatedAt:     snapshot.CreatedAt,
+		SourceDialect: snapshot.Dialect,
+		Credentials:   snapshot.Credentials,
+		Users:         len(snapshot.Users),
+		Groups:        len(snapshot.Groups),
+		Clusters:      len(snapshot.Clusters),
+	}
+}
+
+func (h *Handler) audit(c *gin.Context, event, desc string, snapshot *db.Snapshot) {
+	if userID, exists := c.Get("user_id"); exists {
+		username, _ := c.Get("username")
+		email, _ := c.Get("email")
+		audit.Log(c, event, userID.(int), username.(string), email.(string),
+			desc,
+			map[string]interface{}{
+				"created_at":          snapshot.CreatedAt,
+				"schema_version":      snapshot.SchemaVersion,
+				"include_credentials": snapshot.Credentials,
+				"users":               len(snapshot.Users),
+				"clusters":            len(snapshot.Clusters),
+			})
+	}
+}
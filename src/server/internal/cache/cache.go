@@ -0,0 +1,112 @@
+// Package cache holds state that kubelens replicas behind a load balancer must agree on:
+// rate limiter buckets, login session lookups and cached cluster summaries. Redis shares
+// it between replicas; without Redis it stays in the memory of each replica.
+package cache
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrMiss is returned by Get for a key that does not exist or expired
+var ErrMiss = errors.New("cache miss")
+
+// Store is a key/value store with expiring keys
+type Store interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+	// Allow takes a token from the bucket at key, which holds up to burst tokens and
+	// gains one every interval, reporting whether one was left
+	Allow(ctx context.Context, key string, interval time.Duration, burst int) (bool, error)
+}
+
+// memorySweepEvery is how many writes a Memory store takes between sweeps of expired keys
+const memorySweepEvery = 1000
+
+// Memory is a Store local to the process
+type Memory struct {
+	mu     sync.Mutex
+	items  map[string]memoryItem
+	writes int
+}
+
+type memoryItem struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+// NewMemory creates an empty in-memory store
+func NewMemory() *Memory {
+	return &Memory{items: make(map[string]memoryItem)}
+}
+
+// Get returns the value of a key
+func (m *Memory) Get(ctx context.Context, key string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	item, ok := m.items[key]
+	if !ok || (!item.expiresAt.IsZero() && time.Now().After(item.expiresAt)) {
+		return nil, ErrMiss
+	}
+	return item.value, nil
+}
+
+// Set stores a value, expiring after ttl (never for 0)
+func (m *Memory) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.set(key, value, ttl)
+	return nil
+}
+
+func (m *Memory) set(key string, value []byte, ttl time.Duration) {
+	item := memoryItem{value: value}
+	if ttl > 0 {
+		item.expiresAt = time.Now().Add(ttl)
+	}
+	m.items[key] = item
+
+	m.writes++
+	if m.writes%memorySweepEvery == 0 {
+		now := time.Now()
+		for key, item := range m.items {
+			if !item.expiresAt.IsZero() && now.After(item.expiresAt) {
+				delete(m.items, key)
+			}
+		}
+	}
+}
+
+// Delete removes keys
+func (m *Memory) Delete(ctx context.Context, keys ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, key := range keys {
+		delete(m.items, key)
+	}
+	return nil
+}
+
+// Allow takes a token from a bucket, kept as the time it is full again (GCRA)
+func (m *Memory) Allow(ctx context.Context, key string, interval time.Duration, burst int) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	full := now
+	if item, ok := m.items[key]; ok && len(item.value) == 8 {
+		if at := time.Unix(0, int64(binary.BigEndian.Uint64(item.value))); at.After(now) {
+			full = at
+		}
+	}
+	next := full.Add(interval)
+	if next.Sub(now) > interval*time.Duration(burst) {
+		return false, nil
+	}
+	m.set(key, binary.BigEndian.AppendUint64(nil, uint64(next.UnixNano())), next.Sub(now))
+	return true, nil
+}
@@ -0,0 +1,189 @@
+package cache
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMemory(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory()
+
+	if _, err := m.Get(ctx, "missing"); !errors.Is(err, ErrMiss) {
+		t.Errorf("Get(missing) = %v, want ErrMiss", err)
+	}
+	m.Set(ctx, "a", []byte("1"), 0)
+	m.Set(ctx, "b", []byte("2"), time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	if value, err := m.Get(ctx, "a"); err != nil || string(value) != "1" {
+		t.Errorf("Get(a) = %q, %v", value, err)
+	}
+	if _, err := m.Get(ctx, "b"); !errors.Is(err, ErrMiss) {
+		t.Errorf("Get(b) after expiry = %v, want ErrMiss", err)
+	}
+	m.Delete(ctx, "a")
+	if _, err := m.Get(ctx, "a"); !errors.Is(err, ErrMiss) {
+		t.Errorf("Get(a) after Delete = %v, want ErrMiss", err)
+	}
+}
+
+func TestMemoryAllow(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory()
+	for i := 0; i < 3; i++ {
+		if ok, _ := m.Allow(ctx, "ip", time.Hour, 3); !ok {
+			t.Fatalf("request %d of the burst was limited", i+1)
+		}
+	}
+	if ok, _ := m.Allow(ctx, "ip", time.Hour, 3); ok {
+		t.Error("request beyond the burst was allowed")
+	}
+	if ok, _ := m.Allow(ctx, "other", time.Hour, 3); !ok {
+		t.Error("buckets are not separate")
+	}
+
+	// A fast bucket refills
+	for i := 0; i < 2; i++ {
+		m.Allow(ctx, "fast", 10*time.Millisecond, 2)
+	}
+	time.Sleep(15 * time.Millisecond)
+	if ok, _ := m.Allow(ctx, "fast", 10*time.Millisecond, 2); !ok {
+		t.Error("bucket did not refill")
+	}
+}
+
+// fakeRedis serves GET, SET, DEL, PING, AUTH and SELECT over RESP
+type fakeRedis struct {
+	mu       sync.Mutex
+	data     map[string]string
+	password string
+}
+
+func (f *fakeRedis) serve(t *testing.T) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go f.handle(conn)
+		}
+	}()
+	return ln.Addr().String()
+}
+
+func (f *fakeRedis) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	authed := f.password == ""
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		n, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+		args := make([]string, n)
+		for i := range args {
+			header, _ := r.ReadString('\n')
+			size, _ := strconv.Atoi(strings.TrimSpace(header[1:]))
+			arg := make([]byte, size+2)
+			if _, err := io.ReadFull(r, arg); err != nil {
+				return
+			}
+			args[i] = string(arg[:size])
+		}
+
+		f.mu.Lock()
+		var reply string
+		switch cmd := strings.ToUpper(args[0]); {
+		case cmd == "AUTH":
+			authed = args[len(args)-1] == f.password
+			reply = "+OK\r\n"
+			if !authed {
+				reply = "-WRONGPASS invalid password\r\n"
+			}
+		case !authed:
+			reply = "-NOAUTH Authentication required.\r\n"
+		case cmd == "PING":
+			reply = "+PONG\r\n"
+		case cmd == "SELECT":
+			reply = "+OK\r\n"
+		case cmd == "SET":
+			f.data[args[1]] = args[2]
+			reply = "+OK\r\n"
+		case cmd == "GET":
+			value, ok := f.data[args[1]]
+			reply = "$-1\r\n"
+			if ok {
+				reply = fmt.Sprintf("$%d\r\n%s\r\n", len(value), value)
+			}
+		case cmd == "DEL":
+			deleted := 0
+			for _, key := range args[1:] {
+				if _, ok := f.data[key]; ok {
+					delete(f.data, key)
+					deleted++
+				}
+			}
+			reply = fmt.Sprintf(":%d\r\n", deleted)
+		default:
+			reply = "-ERR unknown command\r\n"
+		}
+		f.mu.Unlock()
+		conn.Write([]byte(reply))
+	}
+}
+
+func TestRedis(t *testing.T) {
+	ctx := context.Background()
+	fake := &fakeRedis{data: map[string]string{}, password: "s3cret"}
+	addr := fake.serve(t)
+
+	if _, err := NewRedis(RedisOptions{Addr: addr, Password: "wrong"}); err == nil {
+		t.Error("NewRedis() with a wrong password succeeded")
+	}
+	r, err := NewRedis(RedisOptions{Addr: addr, Password: "s3cret", DB: 1, Prefix: "kubelens:"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	if err := r.Set(ctx, "summary", []byte("{\"pods\":3}\r\n"), time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	fake.mu.Lock()
+	if _, ok := fake.data["kubelens:summary"]; !ok {
+		t.Errorf("key not prefixed: %v", fake.data)
+	}
+	fake.mu.Unlock()
+	if value, err := r.Get(ctx, "summary"); err != nil || string(value) != "{\"pods\":3}\r\n" {
+		t.Errorf("Get() = %q, %v", value, err)
+	}
+	if err := r.Delete(ctx, "summary"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Get(ctx, "summary"); !errors.Is(err, ErrMiss) {
+		t.Errorf("Get() after Delete = %v, want ErrMiss", err)
+	}
+	if err := r.client.Do(ctx, "FLUSHALL").Err(); err == nil || !strings.Contains(err.Error(), "unknown command") {
+		t.Errorf("error reply = %v", err)
+	}
+	// The connection survives an error reply
+	if _, err := r.Get(ctx, "summary"); !errors.Is(err, ErrMiss) {
+		t.Errorf("Get() after an error reply = %v", err)
+	}
+}
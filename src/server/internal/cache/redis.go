@@ -0,0 +1,115 @@
+package cache
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// maxIdleConns is how many Redis connections are kept open between commands
+const maxIdleConns = 8
+
+// allowScript takes a token from a GCRA bucket stored as the time (in microseconds, on
+// the Redis clock so replicas agree) the bucket is full again
+var allowScript = redis.NewScript(`
+local now = redis.call('TIME')
+now = tonumber(now[1]) * 1000000 + tonumber(now[2])
+local interval = tonumber(ARGV[1])
+local full = tonumber(redis.call('GET', KEYS[1]) or '0')
+if full < now then full = now end
+local nextFull = full + interval
+if nextFull - now > interval * tonumber(ARGV[2]) then return 0 end
+redis.call('SET', KEYS[1], string.format('%.0f', nextFull), 'PX', math.ceil((nextFull - now) / 1000))
+return 1
+`)
+
+// RedisOptions configures the Redis client
+type RedisOptions struct {
+	// Addr is host:port of the Redis server
+	Addr     string
+	Username string
+	Password string
+	DB       int
+	TLS      bool
+	// Prefix is prepended to every key, so kubelens can share a Redis server
+	Prefix string
+}
+
+// Redis is a Store shared by all replicas connected to the same Redis server
+type Redis struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedis connects to Redis and checks the connection
+func NewRedis(opts RedisOptions) (*Redis, error) {
+	if opts.Addr == "" {
+		return nil, fmt.Errorf("redis address is required")
+	}
+	options := &redis.Options{
+		Addr:            opts.Addr,
+		Username:        opts.Username,
+		Password:        opts.Password,
+		DB:              opts.DB,
+		MaxIdleConns:    maxIdleConns,
+		DisableIdentity: true,
+	}
+	if opts.TLS {
+		host, _, _ := net.SplitHostPort(opts.Addr)
+		options.TLSConfig = &tls.Config{ServerName: host, MinVersion: tls.VersionTLS12}
+	}
+	client := redis.NewClient(options)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to connect to redis at %s: %w", opts.Addr, err)
+	}
+	return &Redis{client: client, prefix: opts.Prefix}, nil
+}
+
+// Close closes the connections
+func (r *Redis) Close() error {
+	return r.client.Close()
+}
+
+// Get returns the value of a key
+func (r *Redis) Get(ctx context.Context, key string) ([]byte, error) {
+	value, err := r.client.Get(ctx, r.prefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrMiss
+	}
+	return value, err
+}
+
+// Set stores a value, expiring after ttl (never for 0)
+func (r *Redis) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return r.client.Set(ctx, r.prefix+key, value, ttl).Err()
+}
+
+// Delete removes keys
+func (r *Redis) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = r.prefix + key
+	}
+	return r.client.Del(ctx, prefixed...).Err()
+}
+
+// Allow takes a token from a bucket shared by all replicas
+func (r *Redis) Allow(ctx context.Context, key string, interval time.Duration, burst int) (bool, error) {
+	allowed, err := allowScript.Run(ctx, r.client, []string{r.prefix + key}, max(interval.Microseconds(), 1), burst).Int64()
+	if err != nil {
+		return false, err
+	}
+	return allowed == 1, nil
+}
@@ -0,0 +1,57 @@
+package cluster
+
+import (
+	"fmt"
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/oauth2"
+	"k8s.io/client-go/rest"
+
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+// AgentTunnels connects the clusters whose agent dials in to kubelens (tunnel.Server)
+type AgentTunnels interface {
+	// ConnectCluster adds a cluster through the tunnel of its agent; it fails while the
+	// agent is not connected
+	ConnectCluster(name string) error
+}
+
+// SetAgentTunnels sets what connects clusters of the agent auth type
+func (m *Manager) SetAgentTunnels(tunnels AgentTunnels) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.agentTunnels = tunnels
+}
+
+// AddClusterFromAgent adds a cluster of the agent auth type through its tunnel
+func (m *Manager) AddClusterFromAgent(name string) error {
+	m.mu.RLock()
+	tunnels := m.agentTunnels
+	m.mu.RUnlock()
+
+	if tunnels == nil {
+		return fmt.Errorf("agent tunnels are not available")
+	}
+	return tunnels.ConnectCluster(name)
+}
+
+// AddClusterThroughTunnel adds a cluster whose API server is reached at host, a local
+// end of an agent tunnel. serverName is the name the API server certificate is checked
+// against, and source supplies the bearer token the agent forwarded.
+func (m *Manager) AddClusterThroughTunnel(name, host, serverName string, ca []byte, source oauth2.TokenSource) error {
+	config := &rest.Config{
+		Host:            host,
+		TLSClientConfig: rest.TLSClientConfig{CAData: ca, ServerName: serverName},
+	}
+	config.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return &oauth2.Transport{Source: source, Base: rt}
+	})
+
+	if err := m.connect(name, config); err != nil {
+		return err
+	}
+	log.Infof("Successfully added cluster: %s (auth_type: %s)", name, db.AuthTypeAgent)
+	return nil
+}
@@ -0,0 +1,275 @@
+package cluster
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/oauth2"
+	apiextensionsclientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+
+	"github.com/sonnguyen/kubelens/internal/db"
+	"github.com/sonnguyen/kubelens/internal/metrics"
+)
+
+// defaultExecAPIVersion is the exec credential API version used when none is given
+const defaultExecAPIVersion = "client.authentication.k8s.io/v1beta1"
+
+// SetExecCommands sets the exec credential plugins clusters may run, as commands on the
+// PATH or absolute paths. Exec auth (the exec auth type, or exec users in a kubeconfig)
+// with any other command is refused, as the plugin runs on the kubelens server.
+func (m *Manager) SetExecCommands(commands []string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.execCommands = make(map[string]bool, len(commands))
+	for _, command := range commands {
+		if command != "" {
+			m.execCommands[command] = true
+		}
+	}
+}
+
+// checkExecCommand returns an error if an exec credential plugin is not allowed
+func (m *Manager) checkExecCommand(command string) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.execCommands[command] {
+		return nil
+	}
+	allowed := make([]string, 0, len(m.execCommands))
+	for c := range m.execCommands {
+		allowed = append(allowed, c)
+	}
+	sort.Strings(allowed)
+	if len(allowed) == 0 {
+		return fmt.Errorf("exec credential plugins are disabled")
+	}
+	return fmt.Errorf("exec credential plugin %q is not allowed (allowed: %v)", command, allowed)
+}
+
+// ValidateAuthConfig checks the auth_config of the exec, eks, gke and aks auth types
+func (m *Manager) ValidateAuthConfig(authType string, authConfig []byte) error {
+	switch db.AuthType(authType) {
+	case db.AuthTypeExec:
+		var cfg db.ExecAuthConfig
+		if err := json.Unmarshal(authConfig, &cfg); err != nil {
+			return fmt.Errorf("invalid exec auth config: %w", err)
+		}
+		if err := validateServer(cfg.Server, cfg.CA); err != nil {
+			return err
+		}
+		if cfg.Command == "" {
+			return fmt.Errorf("command is required for exec auth type")
+		}
+		switch cfg.APIVersion {
+		case "", "client.authentication.k8s.io/v1", "client.authentication.k8s.io/v1beta1":
+		default:
+			return fmt.Errorf("api_version must be client.authentication.k8s.io/v1 or v1beta1")
+		}
+		return m.checkExecCommand(cfg.Command)
+
+	case db.AuthTypeEKS:
+		var cfg db.EKSAuthConfig
+		if err := json.Unmarshal(authConfig, &cfg); err != nil {
+			return fmt.Errorf("invalid eks auth config: %w", err)
+		}
+		if err := validateServer(cfg.Server, cfg.CA); err != nil {
+			return err
+		}
+		if cfg.ClusterName == "" || cfg.Region == "" {
+			return fmt.Errorf("cluster_name and region are required for eks auth type")
+		}
+		if (cfg.AccessKeyID == "") != (cfg.SecretAccessKey == "") {
+			return fmt.Errorf("access_key_id and secret_access_key must be set together")
+		}
+		return nil
+
+	case db.AuthTypeGKE:
+		var cfg db.GKEAuthConfig
+		if err := json.Unmarshal(authConfig, &cfg); err != nil {
+			return fmt.Errorf("invalid gke auth config: %w", err)
+		}
+		if err := validateServer(cfg.Server, cfg.CA); err != nil {
+			return err
+		}
+		if cfg.ServiceAccountJSON == "" {
+			return fmt.Errorf("service_account_json is required for gke auth type")
+		}
+		_, err := parseGoogleServiceAccount(cfg.ServiceAccountJSON)
+		return err
+
+	case db.AuthTypeAKS:
+		var cfg db.AKSAuthConfig
+		if err := json.Unmarshal(authConfig, &cfg); err != nil {
+			return fmt.Errorf("invalid aks auth config: %w", err)
+		}
+		if err := validateServer(cfg.Server, cfg.CA); err != nil {
+			return err
+		}
+		return validateAKSAuthConfig(&cfg)
+
+	default:
+		return fmt.Errorf("unsupported auth_type: %s", authType)
+	}
+}
+
+// validateServer checks the API server URL and base64 CA of an auth config
+func validateServer(server, ca string) error {
+	u, err := url.Parse(server)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("server must be an https URL")
+	}
+	if ca == "" {
+		return fmt.Errorf("ca is required")
+	}
+	if _, err := base64.StdEncoding.DecodeString(ca); err != nil {
+		return fmt.Errorf("ca must be base64 encoded")
+	}
+	return nil
+}
+
+// AddClusterFromAuthConfig adds a cluster of the exec, eks, gke or aks auth type from its
+// auth_config. The cloud auth types fetch bearer tokens in process and refresh them
+// before they expire, without any plugin in the container.
+func (m *Manager) AddClusterFromAuthConfig(name, authType string, authConfig []byte) error {
+	if err := m.ValidateAuthConfig(authType, authConfig); err != nil {
+		return err
+	}
+
+	var config *rest.Config
+	var source oauth2.TokenSource
+	switch db.AuthType(authType) {
+	case db.AuthTypeExec:
+		var cfg db.ExecAuthConfig
+		json.Unmarshal(authConfig, &cfg)
+		caDecoded, _ := base64.StdEncoding.DecodeString(cfg.CA)
+
+		apiVersion := cfg.APIVersion
+		if apiVersion == "" {
+			apiVersion = defaultExecAPIVersion
+		}
+		names := make([]string, 0, len(cfg.Env))
+		for envName := range cfg.Env {
+			names = append(names, envName)
+		}
+		sort.Strings(names)
+		env := make([]clientcmdapi.ExecEnvVar, 0, len(names))
+		for _, envName := range names {
+			env = append(env, clientcmdapi.ExecEnvVar{Name: envName, Value: cfg.Env[envName]})
+		}
+
+		config = &rest.Config{
+			Host:            cfg.Server,
+			TLSClientConfig: rest.TLSClientConfig{CAData: caDecoded},
+			ExecProvider: &clientcmdapi.ExecConfig{
+				Command:         cfg.Command,
+				Args:            cfg.Args,
+				Env:             env,
+				APIVersion:      apiVersion,
+				InteractiveMode: clientcmdapi.NeverExecInteractiveMode,
+			},
+		}
+
+	case db.AuthTypeEKS:
+		var cfg db.EKSAuthConfig
+		json.Unmarshal(authConfig, &cfg)
+		caDecoded, _ := base64.StdEncoding.DecodeString(cfg.CA)
+
+		source = &eksTokenSource{cfg: cfg}
+		config = &rest.Config{
+			Host:            cfg.Server,
+			TLSClientConfig: rest.TLSClientConfig{CAData: caDecoded},
+		}
+
+	case db.AuthTypeGKE:
+		var cfg db.GKEAuthConfig
+		json.Unmarshal(authConfig, &cfg)
+		caDecoded, _ := base64.StdEncoding.DecodeString(cfg.CA)
+
+		gkeSource, err := gkeTokenSource(context.Background(), &cfg)
+		if err != nil {
+			return err
+		}
+		source = gkeSource
+		config = &rest.Config{
+			Host:            cfg.Server,
+			TLSClientConfig: rest.TLSClientConfig{CAData: caDecoded},
+		}
+
+	case db.AuthTypeAKS:
+		var cfg db.AKSAuthConfig
+		json.Unmarshal(authConfig, &cfg)
+		caDecoded, _ := base64.StdEncoding.DecodeString(cfg.CA)
+
+		aksSource, err := aksTokenSource(context.Background(), &cfg)
+		if err != nil {
+			return err
+		}
+		source = aksSource
+		config = &rest.Config{
+			Host:            cfg.Server,
+			TLSClientConfig: rest.TLSClientConfig{CAData: caDecoded},
+		}
+	}
+
+	if source != nil {
+		// Tokens are reused until shortly before they expire
+		source = oauth2.ReuseTokenSource(nil, source)
+		config.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+			return &oauth2.Transport{Source: source, Base: rt}
+		})
+	}
+
+	if err := m.connect(name, config); err != nil {
+		return err
+	}
+	log.Infof("Successfully added cluster: %s (auth_type: %s)", name, authType)
+	return nil
+}
+
+// connect builds the clients of a cluster from a REST config, tests the connection and
+// registers the cluster
+func (m *Manager) connect(name string, config *rest.Config) error {
+	if err := m.applyConnectionOptions(name, config); err != nil {
+		return err
+	}
+
+	// Count failed API server requests per cluster
+	config.Wrap(metrics.ClusterTransport(name))
+
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return fmt.Errorf("failed to create clientset: %w", err)
+	}
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return fmt.Errorf("failed to create dynamic client: %w", err)
+	}
+	apiextensionsClient, err := apiextensionsclientset.NewForConfig(config)
+	if err != nil {
+		return fmt.Errorf("failed to create apiextensions clientset: %w", err)
+	}
+
+	if _, err := clientset.ServerVersion(); err != nil {
+		return fmt.Errorf("failed to connect to cluster: %w", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.clients[name] = clientset
+	m.dynamicClients[name] = dynamicClient
+	m.apiextensionsClients[name] = apiextensionsClient
+	m.configs[name] = config
+	return nil
+}
@@ -0,0 +1,119 @@
+package cluster
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+	"golang.org/x/oauth2/jwt"
+
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+const (
+	// googleTokenURL is the token endpoint of service account keys without a token_uri
+	googleTokenURL = "https://oauth2.googleapis.com/token"
+	// defaultAzureAuthorityHost is the Microsoft Entra ID endpoint of the public cloud
+	defaultAzureAuthorityHost = "https://login.microsoftonline.com"
+	// aksServerAppScope is the scope of tokens for AKS clusters: the application ID of the
+	// Azure Kubernetes Service AAD server, the same in every tenant
+	aksServerAppScope = "6dae42f8-4368-4678-94ff-3960e28e3630/.default"
+)
+
+// gkeScopes are the scopes of GKE access tokens, as requested by gke-gcloud-auth-plugin
+var gkeScopes = []string{
+	"https://www.googleapis.com/auth/cloud-platform",
+	"https://www.googleapis.com/auth/userinfo.email",
+}
+
+// googleServiceAccount is the part of a Google service account key file kubelens uses
+type googleServiceAccount struct {
+	Type         string `json:"type"`
+	ClientEmail  string `json:"client_email"`
+	PrivateKeyID string `json:"private_key_id"`
+	PrivateKey   string `json:"private_key"`
+	TokenURI     string `json:"token_uri"`
+}
+
+// parseGoogleServiceAccount reads and checks a service account key file
+func parseGoogleServiceAccount(keyJSON string) (*googleServiceAccount, error) {
+	var sa googleServiceAccount
+	if err := json.Unmarshal([]byte(keyJSON), &sa); err != nil {
+		return nil, fmt.Errorf("service_account_json is not a service account key: %v", err)
+	}
+	if sa.Type != "service_account" || sa.ClientEmail == "" || sa.PrivateKey == "" {
+		return nil, fmt.Errorf("service_account_json must be the JSON key file of a service account")
+	}
+	block, _ := pem.Decode([]byte(sa.PrivateKey))
+	if block == nil {
+		return nil, fmt.Errorf("service account private_key is not PEM encoded")
+	}
+	if _, err := x509.ParsePKCS8PrivateKey(block.Bytes); err != nil {
+		if _, err := x509.ParsePKCS1PrivateKey(block.Bytes); err != nil {
+			return nil, fmt.Errorf("invalid service account private_key: %v", err)
+		}
+	}
+	if sa.TokenURI == "" {
+		sa.TokenURI = googleTokenURL
+	}
+	return &sa, nil
+}
+
+// gkeTokenSource returns a refreshing source of Google access tokens for a service account
+func gkeTokenSource(ctx context.Context, cfg *db.GKEAuthConfig) (oauth2.TokenSource, error) {
+	sa, err := parseGoogleServiceAccount(cfg.ServiceAccountJSON)
+	if err != nil {
+		return nil, err
+	}
+	conf := &jwt.Config{
+		Email:        sa.ClientEmail,
+		PrivateKey:   []byte(sa.PrivateKey),
+		PrivateKeyID: sa.PrivateKeyID,
+		Scopes:       gkeScopes,
+		TokenURL:     sa.TokenURI,
+	}
+	return conf.TokenSource(ctx), nil
+}
+
+// validateAKSAuthConfig checks the service principal of an AKS auth config
+func validateAKSAuthConfig(cfg *db.AKSAuthConfig) error {
+	if cfg.TenantID == "" || cfg.ClientID == "" || cfg.ClientSecret == "" {
+		return fmt.Errorf("tenant_id, client_id and client_secret are required for aks auth type")
+	}
+	if strings.ContainsAny(cfg.TenantID, "/?#") {
+		return fmt.Errorf("invalid tenant_id")
+	}
+	if cfg.AuthorityHost != "" {
+		u, err := url.Parse(cfg.AuthorityHost)
+		if err != nil || u.Scheme != "https" || u.Host == "" {
+			return fmt.Errorf("authority_host must be an https URL")
+		}
+	}
+	return nil
+}
+
+// aksTokenSource returns a refreshing source of Microsoft Entra ID access tokens for AKS,
+// obtained with the client credentials of a service principal
+func aksTokenSource(ctx context.Context, cfg *db.AKSAuthConfig) (oauth2.TokenSource, error) {
+	if err := validateAKSAuthConfig(cfg); err != nil {
+		return nil, err
+	}
+	authority := cfg.AuthorityHost
+	if authority == "" {
+		authority = defaultAzureAuthorityHost
+	}
+	conf := &clientcredentials.Config{
+		ClientID:     cfg.ClientID,
+		ClientSecret: cfg.ClientSecret,
+		TokenURL:     strings.TrimSuffix(authority, "/") + "/" + cfg.TenantID + "/oauth2/v2.0/token",
+		Scopes:       []string{aksServerAppScope},
+		AuthStyle:    oauth2.AuthStyleInParams,
+	}
+	return conf.TokenSource(ctx), nil
+}
@@ -0,0 +1,90 @@
+package cluster
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golang.org/x/oauth2"
+
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+func TestGKETokenSource(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	var grantType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		grantType = r.Form.Get("grant_type")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"ya29.gke","token_type":"Bearer","expires_in":3600}`))
+	}))
+	defer server.Close()
+
+	sa, _ := json.Marshal(map[string]string{
+		"type":         "service_account",
+		"client_email": "kubelens@project.iam.gserviceaccount.com",
+		"private_key":  string(keyPEM),
+		"token_uri":    server.URL,
+	})
+	source, err := gkeTokenSource(context.Background(), &db.GKEAuthConfig{ServiceAccountJSON: string(sa)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := source.Token()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.AccessToken != "ya29.gke" || grantType != "urn:ietf:params:oauth:grant-type:jwt-bearer" {
+		t.Errorf("token = %q, grant_type = %q, want a JWT bearer grant", token.AccessToken, grantType)
+	}
+
+	if _, err := gkeTokenSource(context.Background(), &db.GKEAuthConfig{ServiceAccountJSON: `{"type":"authorized_user"}`}); err == nil {
+		t.Error("gkeTokenSource accepted a user credential")
+	}
+}
+
+func TestAKSTokenSource(t *testing.T) {
+	var path, scope, clientSecret string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		path, scope, clientSecret = r.URL.Path, r.Form.Get("scope"), r.Form.Get("client_secret")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"eyJ.aks","token_type":"Bearer","expires_in":3599}`))
+	}))
+	defer server.Close()
+
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, server.Client())
+	source, err := aksTokenSource(ctx, &db.AKSAuthConfig{
+		TenantID:      "tenant",
+		ClientID:      "client",
+		ClientSecret:  "secret",
+		AuthorityHost: server.URL,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := source.Token()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.AccessToken != "eyJ.aks" || path != "/tenant/oauth2/v2.0/token" || scope != aksServerAppScope || clientSecret != "secret" {
+		t.Errorf("token = %q, path = %q, scope = %q, client_secret = %q", token.AccessToken, path, scope, clientSecret)
+	}
+
+	if _, err := aksTokenSource(ctx, &db.AKSAuthConfig{TenantID: "tenant", ClientID: "client", ClientSecret: "secret",
+		AuthorityHost: "http://login.example.com"}); err == nil {
+		t.Error("aksTokenSource accepted an http authority_host")
+	}
+}
@@ -0,0 +1,105 @@
+package cluster
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"k8s.io/client-go/rest"
+
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+// ValidateConnectionOptions checks the proxy and TLS overrides of a cluster
+func ValidateConnectionOptions(opts *db.ConnectionOptions) error {
+	if opts == nil {
+		return nil
+	}
+	if opts.ProxyURL != "" {
+		u, err := url.Parse(opts.ProxyURL)
+		if err != nil {
+			return fmt.Errorf("invalid proxy_url: %w", err)
+		}
+		switch u.Scheme {
+		case "http", "https", "socks5", "socks5h":
+		default:
+			return fmt.Errorf("proxy_url must use http, https, socks5 or socks5h")
+		}
+		if u.Host == "" {
+			return fmt.Errorf("proxy_url must include a host")
+		}
+	}
+	if opts.CA != "" {
+		if opts.InsecureSkipVerify {
+			return fmt.Errorf("ca and insecure_skip_verify cannot be combined")
+		}
+		ca, err := base64.StdEncoding.DecodeString(opts.CA)
+		if err != nil {
+			return fmt.Errorf("ca must be base64 encoded")
+		}
+		if block, _ := pem.Decode(ca); block == nil {
+			return fmt.Errorf("ca must be a PEM certificate bundle")
+		}
+	}
+	return nil
+}
+
+// SetConnectionOptions sets the proxy and TLS overrides applied when a cluster connects;
+// nil clears them. They take effect the next time the cluster is added.
+func (m *Manager) SetConnectionOptions(name string, opts *db.ConnectionOptions) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if opts == nil || *opts == (db.ConnectionOptions{}) {
+		delete(m.connOptions, name)
+	} else {
+		m.connOptions[name] = opts
+	}
+}
+
+// UseStoredConnectionOptions sets the connection overrides stored with a cluster
+func (m *Manager) UseStoredConnectionOptions(dbCluster *db.Cluster) error {
+	var opts *db.ConnectionOptions
+	if len(dbCluster.Connection) > 0 {
+		if err := json.Unmarshal(dbCluster.Connection, &opts); err != nil {
+			return fmt.Errorf("failed to parse connection options: %w", err)
+		}
+	}
+	m.SetConnectionOptions(dbCluster.Name, opts)
+	return nil
+}
+
+// applyConnectionOptions applies the connection overrides of a cluster to its REST config
+func (m *Manager) applyConnectionOptions(name string, config *rest.Config) error {
+	m.mu.RLock()
+	opts := m.connOptions[name]
+	m.mu.RUnlock()
+
+	if opts == nil {
+		return nil
+	}
+	if err := ValidateConnectionOptions(opts); err != nil {
+		return err
+	}
+	if opts.ProxyURL != "" {
+		proxyURL, _ := url.Parse(opts.ProxyURL)
+		config.Proxy = http.ProxyURL(proxyURL)
+	}
+	if opts.CA != "" {
+		config.TLSClientConfig.CAData, _ = base64.StdEncoding.DecodeString(opts.CA)
+		config.TLSClientConfig.CAFile = ""
+	}
+	if opts.ServerName != "" {
+		config.TLSClientConfig.ServerName = opts.ServerName
+	}
+	if opts.InsecureSkipVerify {
+		// client-go refuses a CA together with insecure mode
+		config.TLSClientConfig.Insecure = true
+		config.TLSClientConfig.CAData = nil
+		config.TLSClientConfig.CAFile = ""
+	}
+	return nil
+}
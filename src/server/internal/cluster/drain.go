@@ -0,0 +1,197 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// DrainResult summarizes a node drain
+type DrainResult struct {
+	Evicted int      `json:"evicted"`
+	Failed  int      `json:"failed"`
+	Skipped int      `json:"skipped"`
+	Errors  []string `json:"errors,omitempty"`
+}
+
+// CordonNode marks a node unschedulable. It is a no-op if the node is already cordoned.
+func CordonNode(ctx context.Context, client kubernetes.Interface, nodeName string) error {
+	node, err := client.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	if node.Spec.Unschedulable {
+		return nil
+	}
+	node.Spec.Unschedulable = true
+	_, err = client.CoreV1().Nodes().Update(ctx, node, metav1.UpdateOptions{})
+	return err
+}
+
+// isDaemonSetPod reports whether a pod is owned by a DaemonSet (these cannot be evicted)
+func isDaemonSetPod(pod *corev1.Pod) bool {
+	for _, owner := range pod.OwnerReferences {
+		if owner.Kind == "DaemonSet" {
+			return true
+		}
+	}
+	return false
+}
+
+// DrainOptions tune how a node is drained
+type DrainOptions struct {
+	// Parallelism is how many pods are evicted at once (default 1)
+	Parallelism int
+	// Timeout bounds how long evictions refused by a PodDisruptionBudget are retried;
+	// with no timeout each pod is tried once
+	Timeout time.Duration
+}
+
+// evictionRetryInterval is how often an eviction refused by a PodDisruptionBudget is retried
+const evictionRetryInterval = 5 * time.Second
+
+// DrainNode cordons a node and evicts every pod on it except DaemonSet pods
+// and pods that are already terminating. Evictions honour PodDisruptionBudgets.
+func DrainNode(ctx context.Context, client kubernetes.Interface, nodeName string) (*DrainResult, error) {
+	return DrainNodeWithOptions(ctx, client, nodeName, DrainOptions{})
+}
+
+// DrainNodeWithOptions drains a node like DrainNode, evicting up to opts.Parallelism pods
+// at once and retrying evictions blocked by a PodDisruptionBudget until opts.Timeout
+func DrainNodeWithOptions(ctx context.Context, client kubernetes.Interface, nodeName string, opts DrainOptions) (*DrainResult, error) {
+	if err := CordonNode(ctx, client, nodeName); err != nil {
+		return nil, fmt.Errorf("failed to cordon node: %w", err)
+	}
+
+	pods, err := client.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
+		FieldSelector: fmt.Sprintf("spec.nodeName=%s", nodeName),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods on node: %w", err)
+	}
+
+	parallelism := opts.Parallelism
+	if parallelism < 1 {
+		parallelism = 1
+	}
+	var deadline time.Time
+	if opts.Timeout > 0 {
+		deadline = time.Now().Add(opts.Timeout)
+	}
+
+	result := &DrainResult{}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, parallelism)
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+
+		// Skip DaemonSet pods and pods that are already terminating
+		if isDaemonSetPod(pod) || pod.DeletionTimestamp != nil {
+			result.Skipped++
+			continue
+		}
+
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			err := evictPod(ctx, client, pod, deadline)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				log.Warnf("Failed to evict pod %s/%s: %v", pod.Namespace, pod.Name, err)
+				result.Failed++
+				result.Errors = append(result.Errors, fmt.Sprintf("%s/%s: %v", pod.Namespace, pod.Name, err))
+			} else {
+				result.Evicted++
+			}
+		}()
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return result, err
+	}
+
+	log.Infof("Drained node %s: %d evicted, %d failed, %d skipped (DaemonSets)", nodeName, result.Evicted, result.Failed, result.Skipped)
+	return result, nil
+}
+
+// evictPod evicts a pod, retrying while a PodDisruptionBudget refuses the eviction
+// (429) until the deadline. A zero deadline tries once.
+func evictPod(ctx context.Context, client kubernetes.Interface, pod *corev1.Pod, deadline time.Time) error {
+	eviction := &policyv1.Eviction{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      pod.Name,
+			Namespace: pod.Namespace,
+		},
+		DeleteOptions: &metav1.DeleteOptions{
+			GracePeriodSeconds: pod.Spec.TerminationGracePeriodSeconds,
+		},
+	}
+
+	for {
+		err := client.CoreV1().Pods(pod.Namespace).EvictV1(ctx, eviction)
+		if err == nil || apierrors.IsNotFound(err) {
+			return nil
+		}
+		if !apierrors.IsTooManyRequests(err) || deadline.IsZero() || time.Now().Add(evictionRetryInterval).After(deadline) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(evictionRetryInterval):
+		}
+	}
+}
+
+// WaitForNodeDrained polls until only DaemonSet pods remain on the node or the timeout expires
+func WaitForNodeDrained(ctx context.Context, client kubernetes.Interface, nodeName string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		pods, err := client.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
+			FieldSelector: fmt.Sprintf("spec.nodeName=%s", nodeName),
+		})
+		if err == nil {
+			remaining := 0
+			for i := range pods.Items {
+				pod := &pods.Items[i]
+				if isDaemonSetPod(pod) || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+					continue
+				}
+				remaining++
+			}
+			if remaining == 0 {
+				return nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timed out waiting for node %s to drain", nodeName)
+		case <-ticker.C:
+		}
+	}
+}
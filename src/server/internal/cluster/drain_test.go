@@ -0,0 +1,61 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestDrainNodeWithOptions(t *testing.T) {
+	objects := []runtime.Object{&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}}}
+	for i := 0; i < 4; i++ {
+		objects = append(objects, &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("web-%d", i), Namespace: "shop"},
+			Spec:       corev1.PodSpec{NodeName: "node-1"},
+		})
+	}
+	objects = append(objects, &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "fluentd", Namespace: "logging",
+			OwnerReferences: []metav1.OwnerReference{{Kind: "DaemonSet", Name: "fluentd"}}},
+		Spec: corev1.PodSpec{NodeName: "node-1"},
+	})
+	client := fake.NewSimpleClientset(objects...)
+
+	// A PodDisruptionBudget refuses every eviction of web-3
+	var evictions int32
+	client.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+		atomic.AddInt32(&evictions, 1)
+		if action.(k8stesting.CreateAction).GetObject().(metav1.Object).GetName() == "web-3" {
+			return true, nil, apierrors.NewTooManyRequests("disruption budget", 1)
+		}
+		return true, nil, nil
+	})
+
+	result, err := DrainNodeWithOptions(context.Background(), client, "node-1", DrainOptions{Parallelism: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Evicted != 3 || result.Failed != 1 || result.Skipped != 1 {
+		t.Errorf("result = %+v, want 3 evicted, 1 failed, 1 skipped", result)
+	}
+	// Without a timeout the refused eviction is not retried
+	if evictions != 4 {
+		t.Errorf("evictions = %d, want 4", evictions)
+	}
+
+	node, _ := client.CoreV1().Nodes().Get(context.Background(), "node-1", metav1.GetOptions{})
+	if !node.Spec.Unschedulable {
+		t.Error("node was not cordoned")
+	}
+}
@@ -0,0 +1,186 @@
+package cluster
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"golang.org/x/oauth2"
+
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+const (
+	// eksTokenPrefix prefixes the presigned STS URL in an EKS bearer token
+	eksTokenPrefix = "k8s-aws-v1."
+	// eksClusterIDHeader binds a token to an EKS cluster
+	eksClusterIDHeader = "x-k8s-aws-id"
+	// eksTokenLifetime is how long a token is reused; EKS accepts tokens for 15 minutes
+	eksTokenLifetime = 14 * time.Minute
+	// emptyPayloadHash is the SHA-256 of an empty request body
+	emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+)
+
+// stsEndpoint returns the regional STS endpoint; a variable so tests can point it at a fake
+var stsEndpoint = func(region string) string {
+	if strings.HasPrefix(region, "cn-") {
+		return "https://sts." + region + ".amazonaws.com.cn"
+	}
+	return "https://sts." + region + ".amazonaws.com"
+}
+
+// awsCredentials are AWS access keys; Expires is zero for long-lived keys
+type awsCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	Expires         time.Time
+}
+
+// eksTokenSource generates the bearer tokens of an EKS cluster: presigned STS
+// GetCallerIdentity URLs, which EKS resolves to the IAM identity (as aws-iam-authenticator
+// and "aws eks get-token" do). Wrap it in oauth2.ReuseTokenSource to reuse tokens.
+type eksTokenSource struct {
+	cfg db.EKSAuthConfig
+
+	mu    sync.Mutex
+	creds *awsCredentials
+}
+
+// Token returns a new token, resolving the AWS credentials again when they expire
+func (s *eksTokenSource) Token() (*oauth2.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if s.creds == nil || (!s.creds.Expires.IsZero() && now.Add(eksTokenLifetime).After(s.creds.Expires)) {
+		creds, err := resolveAWSCredentials(&s.cfg)
+		if err != nil {
+			return nil, err
+		}
+		s.creds = creds
+	}
+
+	token, err := eksToken(s.cfg.ClusterName, s.cfg.Region, s.creds, now)
+	if err != nil {
+		return nil, err
+	}
+	return &oauth2.Token{AccessToken: token, TokenType: "Bearer", Expiry: now.Add(eksTokenLifetime)}, nil
+}
+
+// eksToken returns a bearer token for an EKS cluster
+func eksToken(clusterName, region string, creds *awsCredentials, now time.Time) (string, error) {
+	query := url.Values{}
+	query.Set("Action", "GetCallerIdentity")
+	query.Set("Version", "2011-06-15")
+	query.Set("X-Amz-Expires", "60")
+	req, err := http.NewRequest(http.MethodGet, stsEndpoint(region)+"/?"+query.Encode(), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set(eksClusterIDHeader, clusterName)
+	presigned, _, err := v4.NewSigner().PresignHTTP(context.Background(), creds.sdk(), req, emptyPayloadHash, "sts", region, now)
+	if err != nil {
+		return "", err
+	}
+	return eksTokenPrefix + base64.RawURLEncoding.EncodeToString([]byte(presigned)), nil
+}
+
+// signV4 signs a request with AWS Signature Version 4 in its Authorization header
+func signV4(req *http.Request, body []byte, service, region string, creds *awsCredentials, now time.Time) error {
+	payloadHash := sha256.Sum256(body)
+	return v4.NewSigner().SignHTTP(req.Context(), creds.sdk(), req, hex.EncodeToString(payloadHash[:]), service, region, now)
+}
+
+// sdk returns the credentials as the AWS SDK signer takes them
+func (c *awsCredentials) sdk() aws.Credentials {
+	return aws.Credentials{AccessKeyID: c.AccessKeyID, SecretAccessKey: c.SecretAccessKey, SessionToken: c.SessionToken}
+}
+
+// resolveAWSCredentials returns the access keys of an EKS cluster: those of its auth
+// config, the AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY of the environment, or
+// temporary keys for the web identity (IRSA or EKS Pod Identity style AWS_ROLE_ARN and
+// AWS_WEB_IDENTITY_TOKEN_FILE) the server runs with
+func resolveAWSCredentials(cfg *db.EKSAuthConfig) (*awsCredentials, error) {
+	if cfg.AccessKeyID != "" {
+		return &awsCredentials{AccessKeyID: cfg.AccessKeyID, SecretAccessKey: cfg.SecretAccessKey, SessionToken: cfg.SessionToken}, nil
+	}
+	if id, secret := os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY"); id != "" && secret != "" {
+		return &awsCredentials{AccessKeyID: id, SecretAccessKey: secret, SessionToken: os.Getenv("AWS_SESSION_TOKEN")}, nil
+	}
+	if roleARN, tokenFile := os.Getenv("AWS_ROLE_ARN"), os.Getenv("AWS_WEB_IDENTITY_TOKEN_FILE"); roleARN != "" && tokenFile != "" {
+		return assumeRoleWithWebIdentity(cfg.Region, roleARN, tokenFile)
+	}
+	return nil, fmt.Errorf("no AWS credentials: set access_key_id and secret_access_key, or run kubelens with AWS credentials in its environment")
+}
+
+// assumeRoleWithWebIdentity exchanges a web identity token for temporary keys of a role.
+// The call is not signed; the token authenticates it.
+func assumeRoleWithWebIdentity(region, roleARN, tokenFile string) (*awsCredentials, error) {
+	token, err := os.ReadFile(tokenFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read web identity token: %w", err)
+	}
+	sessionName := os.Getenv("AWS_ROLE_SESSION_NAME")
+	if sessionName == "" {
+		sessionName = "kubelens"
+	}
+
+	query := url.Values{}
+	query.Set("Action", "AssumeRoleWithWebIdentity")
+	query.Set("Version", "2011-06-15")
+	query.Set("RoleArn", roleARN)
+	query.Set("RoleSessionName", sessionName)
+	query.Set("WebIdentityToken", strings.TrimSpace(string(token)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, stsEndpoint(region)+"/",
+		strings.NewReader(query.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to assume role %s: %w", roleARN, err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to assume role %s: STS returned %d: %s", roleARN, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var result struct {
+		Credentials struct {
+			AccessKeyID     string    `xml:"AccessKeyId"`
+			SecretAccessKey string    `xml:"SecretAccessKey"`
+			SessionToken    string    `xml:"SessionToken"`
+			Expiration      time.Time `xml:"Expiration"`
+		} `xml:"AssumeRoleWithWebIdentityResult>Credentials"`
+	}
+	if err := xml.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("invalid STS response: %w", err)
+	}
+	if result.Credentials.AccessKeyID == "" {
+		return nil, fmt.Errorf("STS returned no credentials for role %s", roleARN)
+	}
+	return &awsCredentials{
+		AccessKeyID:     result.Credentials.AccessKeyID,
+		SecretAccessKey: result.Credentials.SecretAccessKey,
+		SessionToken:    result.Credentials.SessionToken,
+		Expires:         result.Credentials.Expiration,
+	}, nil
+}
@@ -0,0 +1,108 @@
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+// eksAPITimeout bounds a single call to the EKS API
+const eksAPITimeout = 30 * time.Second
+
+// eksEndpoint returns the regional EKS API endpoint; a variable so tests can point it at a fake
+var eksEndpoint = func(region string) string {
+	if strings.HasPrefix(region, "cn-") {
+		return "https://eks." + region + ".amazonaws.com.cn"
+	}
+	return "https://eks." + region + ".amazonaws.com"
+}
+
+// EKSAPI calls the EKS API in the region of a cluster, with the AWS credentials its auth
+// config resolves to (see resolveAWSCredentials)
+type EKSAPI struct {
+	cfg db.EKSAuthConfig
+
+	mu    sync.Mutex
+	creds *awsCredentials
+}
+
+// NewEKSAPI returns an EKS API client for a cluster of the eks auth type
+func NewEKSAPI(cfg db.EKSAuthConfig) *EKSAPI {
+	return &EKSAPI{cfg: cfg}
+}
+
+// credentials returns the AWS access keys, resolving them again when they expire
+func (a *EKSAPI) credentials() (*awsCredentials, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.creds == nil || (!a.creds.Expires.IsZero() && time.Now().Add(time.Minute).After(a.creds.Expires)) {
+		creds, err := resolveAWSCredentials(&a.cfg)
+		if err != nil {
+			return nil, err
+		}
+		a.creds = creds
+	}
+	return a.creds, nil
+}
+
+// Do sends a request to the EKS API, with in as the JSON body unless nil, and decodes
+// the JSON response into out unless nil
+func (a *EKSAPI) Do(ctx context.Context, method, path string, in, out interface{}) error {
+	creds, err := a.credentials()
+	if err != nil {
+		return err
+	}
+
+	var body []byte
+	if in != nil {
+		if body, err = json.Marshal(in); err != nil {
+			return err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, eksAPITimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, method, eksEndpoint(a.cfg.Region)+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if err := signV4(req, body, "eks", a.cfg.Region, creds, time.Now()); err != nil {
+		return err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("EKS API request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Message != "" {
+			return fmt.Errorf("EKS API returned %d: %s", resp.StatusCode, apiErr.Message)
+		}
+		return fmt.Errorf("EKS API returned %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("invalid EKS API response: %w", err)
+	}
+	return nil
+}
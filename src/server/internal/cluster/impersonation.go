@@ -0,0 +1,175 @@
+package cluster
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	metricsclientset "k8s.io/metrics/pkg/client/clientset/versioned"
+)
+
+// ImpersonationGroupPrefix is prepended to kubelens group names when they are sent as
+// Impersonate-Group, so a kubelens group can never claim a built-in group such as
+// system:masters. Bind cluster roles to e.g. "kubelens:editor".
+const ImpersonationGroupPrefix = "kubelens:"
+
+// Identity is the kubelens user that API calls are made as on clusters in impersonation mode
+type Identity struct {
+	User   string
+	Groups []string
+}
+
+// NewIdentity builds the identity sent to the cluster for a kubelens user
+func NewIdentity(user string, groups []string) *Identity {
+	id := &Identity{User: user}
+	for _, group := range groups {
+		id.Groups = append(id.Groups, ImpersonationGroupPrefix+group)
+	}
+	sort.Strings(id.Groups)
+	return id
+}
+
+func (id *Identity) key() string {
+	return id.User + "\x00" + strings.Join(id.Groups, "\x00")
+}
+
+// impersonatedClients are the clients of one identity on one cluster, built from the
+// cluster's REST config at the time
+type impersonatedClients struct {
+	base          *rest.Config
+	config        *rest.Config
+	client        kubernetes.Interface
+	dynamicClient dynamic.Interface
+}
+
+// SetImpersonation turns impersonation mode on or off for a cluster. In impersonation mode
+// the *As methods make API calls with Impersonate-User/Impersonate-Group headers, so the
+// cluster's RBAC decides what each user can see and do; the kubelens service account only
+// needs the impersonate verb on users and groups.
+func (m *Manager) SetImpersonation(name string, enabled bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if enabled {
+		m.impersonate[name] = true
+	} else {
+		delete(m.impersonate, name)
+	}
+	m.dropImpersonated(name)
+}
+
+// Impersonates reports whether a cluster is in impersonation mode
+func (m *Manager) Impersonates(name string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.impersonate[name]
+}
+
+// dropImpersonated forgets the cached impersonating clients of a cluster. Callers hold m.mu.
+func (m *Manager) dropImpersonated(name string) {
+	prefix := name + "\x00"
+	for key := range m.impersonated {
+		if strings.HasPrefix(key, prefix) {
+			delete(m.impersonated, key)
+		}
+	}
+}
+
+// impersonatedFor returns the cached clients of an identity on a cluster, building them
+// on first use or when the cluster was reconnected with a new config
+func (m *Manager) impersonatedFor(name string, id *Identity) (*impersonatedClients, error) {
+	if strings.HasPrefix(id.User, "system:") || id.User == "" {
+		return nil, fmt.Errorf("user %q cannot be impersonated", id.User)
+	}
+
+	key := name + "\x00" + id.key()
+
+	m.mu.RLock()
+	base, exists := m.configs[name]
+	cached := m.impersonated[key]
+	m.mu.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("cluster %s not found", name)
+	}
+	if cached != nil && cached.base == base {
+		return cached, nil
+	}
+
+	config := rest.CopyConfig(base)
+	config.Impersonate = rest.ImpersonationConfig{UserName: id.User, Groups: id.Groups}
+
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create impersonating clientset: %w", err)
+	}
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create impersonating dynamic client: %w", err)
+	}
+
+	clients := &impersonatedClients{base: base, config: config, client: client, dynamicClient: dynamicClient}
+
+	m.mu.Lock()
+	m.impersonated[key] = clients
+	m.mu.Unlock()
+
+	return clients, nil
+}
+
+// ClientAs returns the Kubernetes client to use for a user: an impersonating client on
+// clusters in impersonation mode, the cluster's own client otherwise or when id is nil
+func (m *Manager) ClientAs(name string, id *Identity) (kubernetes.Interface, error) {
+	if id == nil || !m.Impersonates(name) {
+		return m.GetClient(name)
+	}
+	clients, err := m.impersonatedFor(name, id)
+	if err != nil {
+		return nil, err
+	}
+	return clients.client, nil
+}
+
+// DynamicClientAs is the dynamic client form of ClientAs
+func (m *Manager) DynamicClientAs(name string, id *Identity) (dynamic.Interface, error) {
+	if id == nil || !m.Impersonates(name) {
+		return m.GetDynamicClient(name)
+	}
+	clients, err := m.impersonatedFor(name, id)
+	if err != nil {
+		return nil, err
+	}
+	return clients.dynamicClient, nil
+}
+
+// ConfigAs is the REST config form of ClientAs, used for exec, attach and port-forward
+func (m *Manager) ConfigAs(name string, id *Identity) (*rest.Config, error) {
+	if id == nil || !m.Impersonates(name) {
+		return m.GetConfig(name)
+	}
+	clients, err := m.impersonatedFor(name, id)
+	if err != nil {
+		return nil, err
+	}
+	return clients.config, nil
+}
+
+// MetricsClientAs is the metrics-server form of ClientAs
+func (m *Manager) MetricsClientAs(name string, id *Identity) (metricsclientset.Interface, error) {
+	if id == nil || !m.Impersonates(name) {
+		return m.GetMetricsClient(name)
+	}
+	config, err := m.ConfigAs(name, id)
+	if err != nil {
+		return nil, err
+	}
+	metricsClient, err := metricsclientset.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create metrics client: %v", err)
+	}
+	return metricsClient, nil
+}
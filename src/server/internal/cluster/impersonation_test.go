@@ -0,0 +1,48 @@
+package cluster
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/client-go/rest"
+)
+
+func TestConfigAs(t *testing.T) {
+	m := NewManager(nil)
+	base := &rest.Config{Host: "https://prod.example.com", BearerToken: "sa-token"}
+	m.configs["prod"] = base
+
+	id := NewIdentity("alice@example.com", []string{"viewer", "editor"})
+
+	// Without impersonation mode the cluster's own config is used
+	config, err := m.ConfigAs("prod", id)
+	if err != nil {
+		t.Fatalf("ConfigAs() error = %v", err)
+	}
+	if config != base {
+		t.Error("expected the cluster config when impersonation is off")
+	}
+
+	m.SetImpersonation("prod", true)
+	config, err = m.ConfigAs("prod", id)
+	if err != nil {
+		t.Fatalf("ConfigAs() error = %v", err)
+	}
+	want := rest.ImpersonationConfig{UserName: "alice@example.com", Groups: []string{"kubelens:editor", "kubelens:viewer"}}
+	if !reflect.DeepEqual(config.Impersonate, want) {
+		t.Errorf("Impersonate = %+v, want %+v", config.Impersonate, want)
+	}
+	if base.Impersonate.UserName != "" {
+		t.Error("the cluster config must not be modified")
+	}
+
+	// Clients are cached per identity
+	again, _ := m.ConfigAs("prod", NewIdentity("alice@example.com", []string{"editor", "viewer"}))
+	if again != config {
+		t.Error("expected the cached config for the same identity")
+	}
+
+	if _, err := m.ConfigAs("prod", NewIdentity("system:admin", nil)); err == nil {
+		t.Error("expected system: users to be refused")
+	}
+}
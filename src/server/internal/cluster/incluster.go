@@ -0,0 +1,78 @@
+package cluster
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/client-go/rest"
+
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+// DefaultInClusterName is the name the host cluster is registered under by default
+const DefaultInClusterName = "in-cluster"
+
+// inClusterConfig returns the config of the mounted ServiceAccount; a variable so tests
+// can run outside a pod
+var inClusterConfig = rest.InClusterConfig
+
+// AddClusterInCluster adds the cluster kubelens runs in, authenticated as the ServiceAccount
+// of its pod. client-go rereads the projected token as the kubelet rotates it.
+func (m *Manager) AddClusterInCluster(name string) error {
+	config, err := inClusterConfig()
+	if err != nil {
+		return fmt.Errorf("not running in a Kubernetes pod: %w", err)
+	}
+	if err := m.connect(name, config); err != nil {
+		return err
+	}
+	log.Infof("Successfully added cluster: %s (auth_type: %s)", name, db.AuthTypeInCluster)
+	return nil
+}
+
+// RegisterInCluster stores the host cluster in the database unless a cluster of that name
+// exists, so that it is loaded with the other clusters. The cluster becomes the default if
+// there is none. A registered cluster that was disabled stays disabled.
+func (m *Manager) RegisterInCluster(name string) error {
+	if name == "" {
+		name = DefaultInClusterName
+	}
+	config, err := inClusterConfig()
+	if err != nil {
+		return fmt.Errorf("not running in a Kubernetes pod: %w", err)
+	}
+
+	exists, err := m.db.ClusterExists(name)
+	if err != nil {
+		return err
+	}
+	if exists {
+		existing, err := m.db.GetCluster(name)
+		if err != nil {
+			return err
+		}
+		if existing.AuthType != string(db.AuthTypeInCluster) {
+			return fmt.Errorf("cluster %s exists with auth_type %s", name, existing.AuthType)
+		}
+		return nil
+	}
+
+	defaultCluster, err := m.db.GetDefaultCluster()
+	if err != nil {
+		return err
+	}
+	dbCluster := &db.Cluster{
+		Name:       name,
+		AuthType:   string(db.AuthTypeInCluster),
+		AuthConfig: db.JSON("{}"),
+		Server:     config.Host,
+		IsDefault:  defaultCluster == nil,
+		Enabled:    true,
+		Status:     "disconnected",
+	}
+	if err := m.db.CreateCluster(dbCluster); err != nil {
+		return fmt.Errorf("failed to register in-cluster cluster: %w", err)
+	}
+	log.Infof("Registered the host cluster as %s", name)
+	return nil
+}
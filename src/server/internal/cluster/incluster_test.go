@@ -0,0 +1,55 @@
+package cluster
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"k8s.io/client-go/rest"
+
+	"github.com/sonnguyen/kubelens/internal/config"
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+func TestLoadFromConfigInCluster(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"major":"1","minor":"30","gitVersion":"v1.30.2"}`))
+	}))
+	defer server.Close()
+	defer func(orig func() (*rest.Config, error)) { inClusterConfig = orig }(inClusterConfig)
+	inClusterConfig = func() (*rest.Config, error) { return &rest.Config{Host: server.URL}, nil }
+
+	database, err := db.New(filepath.Join(t.TempDir(), "kubelens.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+
+	cfg := &config.Config{InCluster: true, InClusterName: "host"}
+	manager := NewManager(database)
+	if err := manager.LoadFromConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := manager.GetClient("host"); err != nil {
+		t.Fatalf("host cluster not loaded: %v", err)
+	}
+	stored, err := database.GetCluster("host")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.AuthType != "in_cluster" || !stored.IsDefault || stored.Status != "connected" || stored.Server != server.URL {
+		t.Errorf("stored cluster = %+v, want a connected default in_cluster cluster", stored)
+	}
+
+	// A disabled host cluster is neither enabled again nor loaded on the next start
+	database.UpdateClusterEnabled(stored.ID, false)
+	manager = NewManager(database)
+	if err := manager.LoadFromConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := manager.GetClient("host"); err == nil {
+		t.Error("disabled host cluster was loaded")
+	}
+}
@@ -0,0 +1,261 @@
+package cluster
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+const (
+	// KubeconfigDirSource prefixes the Source of clusters discovered in the kubeconfig
+	// directory; the file name follows
+	KubeconfigDirSource = "kubeconfig_dir:"
+	// ClusterStatusRemoved is the status of a discovered cluster whose context is gone
+	ClusterStatusRemoved = "removed"
+
+	// dirSyncDelay lets a burst of file changes settle before the directory is scanned
+	dirSyncDelay = 2 * time.Second
+	// dirResyncInterval rescans the directory in case a change was not notified
+	dirResyncInterval = 5 * time.Minute
+)
+
+// discoveredCluster is a context of a kubeconfig file in the directory
+type discoveredCluster struct {
+	Name       string // context name
+	File       string
+	Server     string
+	Kubeconfig string // kubeconfig holding only this context, with credentials inlined
+}
+
+// DirWatcher keeps the clusters in sync with the kubeconfig files of a directory. Each
+// context becomes a kubeconfig cluster named after it; changed contexts are reloaded, and
+// clusters whose context disappears are disabled until it comes back. Clusters added
+// through the API are never changed.
+type DirWatcher struct {
+	db      *db.DB
+	manager *Manager
+	dir     string
+	done    chan bool
+	mu      sync.Mutex // serializes syncs
+}
+
+// NewDirWatcher creates a watcher of the kubeconfig files in dir
+func NewDirWatcher(database *db.DB, manager *Manager, dir string) *DirWatcher {
+	return &DirWatcher{
+		db:      database,
+		manager: manager,
+		dir:     dir,
+		done:    make(chan bool),
+	}
+}
+
+// Start syncs the directory and watches it for changes
+func (w *DirWatcher) Start() error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	if err := watcher.Add(w.dir); err != nil {
+		watcher.Close()
+		return fmt.Errorf("failed to watch %s: %w", w.dir, err)
+	}
+
+	go func() {
+		defer watcher.Close()
+		ticker := time.NewTicker(dirResyncInterval)
+		defer ticker.Stop()
+
+		w.Sync()
+		var pending <-chan time.Time
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if event.Op != fsnotify.Chmod {
+					pending = time.After(dirSyncDelay)
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				log.Warnf("Kubeconfig directory watch error: %v", err)
+			case <-pending:
+				pending = nil
+				w.Sync()
+			case <-ticker.C:
+				w.Sync()
+			case <-w.done:
+				return
+			}
+		}
+	}()
+
+	log.Infof("✅ Kubeconfig directory watcher started (%s)", w.dir)
+	return nil
+}
+
+// Stop stops watching the directory
+func (w *DirWatcher) Stop() {
+	close(w.done)
+	log.Info("Kubeconfig directory watcher stopped")
+}
+
+// Sync registers, updates and disables clusters to match the directory
+func (w *DirWatcher) Sync() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	discovered, err := discoverKubeconfigs(w.dir)
+	if err != nil {
+		log.Errorf("Failed to scan kubeconfig directory %s: %v", w.dir, err)
+		return
+	}
+	clusters, err := w.db.ListClusters()
+	if err != nil {
+		log.Errorf("Failed to list clusters: %v", err)
+		return
+	}
+	existing := make(map[string]*db.Cluster, len(clusters))
+	for _, c := range clusters {
+		existing[c.Name] = c
+	}
+
+	seen := make(map[string]bool, len(discovered))
+	for _, d := range discovered {
+		seen[d.Name] = true
+		if err := w.apply(d, existing[d.Name]); err != nil {
+			log.Warnf("Failed to sync cluster %s from %s: %v", d.Name, d.File, err)
+		}
+	}
+
+	for _, c := range clusters {
+		if !strings.HasPrefix(c.Source, KubeconfigDirSource) || seen[c.Name] || c.Status == ClusterStatusRemoved {
+			continue
+		}
+		w.manager.RemoveCluster(c.Name)
+		c.Enabled = false
+		c.Status = ClusterStatusRemoved
+		if err := w.db.SaveCluster(c); err != nil {
+			log.Errorf("Failed to disable cluster %s: %v", c.Name, err)
+			continue
+		}
+		log.Infof("Disabled cluster %s: its context is no longer in %s", c.Name, w.dir)
+	}
+}
+
+// apply registers or updates the cluster of a discovered context and connects it
+func (w *DirWatcher) apply(d discoveredCluster, c *db.Cluster) error {
+	authConfig, err := json.Marshal(map[string]string{"kubeconfig": d.Kubeconfig, "context": d.Name})
+	if err != nil {
+		return err
+	}
+	source := KubeconfigDirSource + d.File
+
+	switch {
+	case c == nil:
+		c = &db.Cluster{
+			Name:       d.Name,
+			AuthType:   string(db.AuthTypeKubeconfig),
+			AuthConfig: db.JSON(authConfig),
+			Server:     d.Server,
+			Enabled:    true,
+			Status:     "disconnected",
+			Source:     source,
+		}
+		if err := w.db.CreateCluster(c); err != nil {
+			return err
+		}
+		log.Infof("Registered cluster %s from %s", d.Name, d.File)
+
+	case !strings.HasPrefix(c.Source, KubeconfigDirSource):
+		return fmt.Errorf("a cluster of that name was added through the API")
+
+	default:
+		restored := c.Status == ClusterStatusRemoved
+		if string(c.AuthConfig) == string(authConfig) && c.Source == source && !restored {
+			return nil
+		}
+		c.AuthType = string(db.AuthTypeKubeconfig)
+		c.AuthConfig = db.JSON(authConfig)
+		c.Server = d.Server
+		c.Source = source
+		if restored {
+			c.Enabled = true
+			c.Status = "disconnected"
+		}
+		if err := w.db.SaveCluster(c); err != nil {
+			return err
+		}
+		log.Infof("Updated cluster %s from %s", d.Name, d.File)
+	}
+
+	if !c.Enabled {
+		return nil
+	}
+	return w.manager.LoadCluster(c)
+}
+
+// discoverKubeconfigs reads the contexts of the kubeconfig files in dir, sorted by name.
+// Hidden files are skipped (including the ..data links of mounted ConfigMaps and Secrets);
+// a context name found in several files is taken from the first file.
+func discoverKubeconfigs(dir string) ([]discoveredCluster, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byName := map[string]discoveredCluster{}
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		if info, err := os.Stat(path); err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+
+		kubeconfig, err := clientcmd.LoadFromFile(path)
+		if err != nil {
+			log.Warnf("Skipping %s: not a kubeconfig: %v", path, err)
+			continue
+		}
+		contexts := make([]string, 0, len(kubeconfig.Contexts))
+		for name := range kubeconfig.Contexts {
+			contexts = append(contexts, name)
+		}
+		sort.Strings(contexts)
+
+		for _, name := range contexts {
+			if prev, ok := byName[name]; ok {
+				log.Warnf("Context %s of %s is also in %s; using %s", name, entry.Name(), prev.File, prev.File)
+				continue
+			}
+			content, server, err := ContextKubeconfig(kubeconfig, name, true)
+			if err != nil {
+				log.Warnf("Skipping context %s of %s: %v", name, path, err)
+				continue
+			}
+			byName[name] = discoveredCluster{Name: name, File: entry.Name(), Server: server, Kubeconfig: content}
+		}
+	}
+
+	discovered := make([]discoveredCluster, 0, len(byName))
+	for _, d := range byName {
+		discovered = append(discovered, d)
+	}
+	sort.Slice(discovered, func(i, j int) bool { return discovered[i].Name < discovered[j].Name })
+	return discovered, nil
+}
@@ -0,0 +1,98 @@
+package cluster
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+const dirTestKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: a
+  cluster:
+    server: %[1]s
+- name: b
+  cluster:
+    server: %[1]s
+users:
+- name: admin
+  user:
+    token: secret
+contexts:
+- name: %[2]s
+  context: {cluster: a, user: admin}
+- name: taken
+  context: {cluster: b, user: admin}
+current-context: %[2]s
+`
+
+func TestDirWatcherSync(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"major":"1","minor":"30","gitVersion":"v1.30.2"}`))
+	}))
+	defer server.Close()
+
+	database, err := db.New(filepath.Join(t.TempDir(), "kubelens.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	manual := &db.Cluster{Name: "taken", AuthType: "token", AuthConfig: db.JSON("{}"), Enabled: true}
+	if err := database.CreateCluster(manual); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "fleet.yaml")
+	if err := os.WriteFile(file, []byte(fmt.Sprintf(dirTestKubeconfig, server.URL, "staging")), 0600); err != nil {
+		t.Fatal(err)
+	}
+	manager := NewManager(database)
+	watcher := NewDirWatcher(database, manager, dir)
+
+	watcher.Sync()
+	staging, err := database.GetCluster("staging")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if staging.Source != KubeconfigDirSource+"fleet.yaml" || staging.Status != "connected" || staging.Server != server.URL {
+		t.Errorf("staging = %+v, want a connected cluster from fleet.yaml", staging)
+	}
+	if _, err := manager.GetClient("staging"); err != nil {
+		t.Errorf("staging not loaded: %v", err)
+	}
+	if taken, _ := database.GetCluster("taken"); taken.AuthType != "token" || taken.Source != "" {
+		t.Errorf("cluster added through the API was changed: %+v", taken)
+	}
+
+	// Renaming the context disables the old cluster and registers the new one
+	if err := os.WriteFile(file, []byte(fmt.Sprintf(dirTestKubeconfig, server.URL, "prod")), 0600); err != nil {
+		t.Fatal(err)
+	}
+	watcher.Sync()
+	if staging, _ := database.GetCluster("staging"); staging.Enabled || staging.Status != ClusterStatusRemoved {
+		t.Errorf("staging = %+v, want it disabled as removed", staging)
+	}
+	if _, err := manager.GetClient("staging"); err == nil {
+		t.Error("removed cluster is still loaded")
+	}
+	if prod, err := database.GetCluster("prod"); err != nil || prod.Status != "connected" {
+		t.Errorf("prod = %+v (%v), want it connected", prod, err)
+	}
+
+	// The context coming back enables the cluster again
+	if err := os.WriteFile(file, []byte(fmt.Sprintf(dirTestKubeconfig, server.URL, "staging")), 0600); err != nil {
+		t.Fatal(err)
+	}
+	watcher.Sync()
+	if staging, _ := database.GetCluster("staging"); !staging.Enabled || staging.Status != "connected" {
+		t.Errorf("staging = %+v, want it enabled and connected", staging)
+	}
+}
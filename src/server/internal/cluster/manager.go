@@ -12,42 +12,57 @@ import (
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 	"k8s.io/client-go/dynamic"
 	metricsclientset "k8s.io/metrics/pkg/client/clientset/versioned"
 	apiextensionsclientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
 
 	"github.com/sonnguyen/kubelens/internal/config"
 	"github.com/sonnguyen/kubelens/internal/db"
+	"github.com/sonnguyen/kubelens/internal/metrics"
 )
 
 // Manager manages multiple Kubernetes cluster connections
 type Manager struct {
 	db                   *db.DB
-	clients              map[string]*kubernetes.Clientset
+	clients              map[string]kubernetes.Interface
 	dynamicClients       map[string]dynamic.Interface
 	apiextensionsClients map[string]*apiextensionsclientset.Clientset
+	metricsClients       map[string]metricsclientset.Interface // prebuilt, see AddMetricsClient
 	configs              map[string]*rest.Config
+	impersonate          map[string]bool
+	impersonated         map[string]*impersonatedClients
+	execCommands         map[string]bool                  // allowed exec credential plugins
+	agentTunnels         AgentTunnels                     // connects clusters of the agent auth type
+	connOptions          map[string]*db.ConnectionOptions // proxy and TLS overrides
+	rotateMu             sync.Mutex                       // serializes credential rotations
 	mu                   sync.RWMutex
 }
 
 // ClusterInfo holds cluster information
 type ClusterInfo struct {
-	Name      string                 `json:"name"`
-	Version   string                 `json:"version"`
-	Status    string                 `json:"status"`
-	IsDefault bool                   `json:"is_default"`
-	Enabled   bool                   `json:"enabled"`
-	Metadata  map[string]interface{} `json:"metadata"`
+	Name        string                 `json:"name"`
+	Version     string                 `json:"version"`
+	Status      string                 `json:"status"`
+	IsDefault   bool                   `json:"is_default"`
+	Enabled     bool                   `json:"enabled"`
+	Impersonate bool                   `json:"impersonate"`
+	Labels      map[string]string      `json:"labels,omitempty"`
+	Metadata    map[string]interface{} `json:"metadata"`
 }
 
 // NewManager creates a new cluster manager
 func NewManager(database *db.DB) *Manager {
 	return &Manager{
 		db:                   database,
-		clients:              make(map[string]*kubernetes.Clientset),
+		clients:              make(map[string]kubernetes.Interface),
 		dynamicClients:       make(map[string]dynamic.Interface),
 		apiextensionsClients: make(map[string]*apiextensionsclientset.Clientset),
+		metricsClients:       make(map[string]metricsclientset.Interface),
 		configs:              make(map[string]*rest.Config),
+		impersonate:          make(map[string]bool),
+		impersonated:         make(map[string]*impersonatedClients),
+		connOptions:          make(map[string]*db.ConnectionOptions),
 	}
 }
 
@@ -64,6 +79,13 @@ func (m *Manager) LoadFromConfig(cfg *config.Config) error {
 	// 	}
 	// }
 
+	// Register the host cluster when running inside Kubernetes
+	if cfg.InCluster {
+		if err := m.RegisterInCluster(cfg.InClusterName); err != nil {
+			log.Warnf("Failed to register the host cluster: %v", err)
+		}
+	}
+
 	// Load clusters from database
 	dbClusters, err := m.db.ListEnabledClusters()
 	if err != nil {
@@ -71,66 +93,87 @@ func (m *Manager) LoadFromConfig(cfg *config.Config) error {
 	}
 
 	for _, dbCluster := range dbClusters {
-		if _, exists := m.clients[dbCluster.Name]; !exists {
-			var loadErr error
-
-			// Load based on auth_type
-			switch dbCluster.AuthType {
-			case "kubeconfig":
-				// Parse auth_config JSON to extract kubeconfig
-				var authConfig map[string]string
-				if err := json.Unmarshal([]byte(dbCluster.AuthConfig), &authConfig); err != nil {
-					log.Errorf("Failed to parse auth_config for cluster %s: %v", dbCluster.Name, err)
-					m.db.UpdateClusterStatus(dbCluster.Name, "error")
-					continue
-				}
-
-				kubeconfigContent := authConfig["kubeconfig"]
-				context := authConfig["context"]
-
-				if kubeconfigContent != "" {
-					loadErr = m.AddClusterFromKubeconfigContent(dbCluster.Name, kubeconfigContent, context)
-				} else {
-					log.Errorf("Empty kubeconfig for cluster %s", dbCluster.Name)
-					m.db.UpdateClusterStatus(dbCluster.Name, "error")
-					continue
-				}
-
-			case "token":
-				// Use extracted server/ca/token fields
-				if dbCluster.Server != "" && dbCluster.CA != "" && dbCluster.Token != "" {
-					loadErr = m.AddClusterFromConfig(dbCluster.Name, dbCluster.Server, dbCluster.CA, dbCluster.Token)
-				} else {
-					log.Errorf("Missing server/ca/token for cluster %s", dbCluster.Name)
-					m.db.UpdateClusterStatus(dbCluster.Name, "error")
-					continue
-				}
-
-			default:
-				log.Warnf("Unsupported auth_type '%s' for cluster %s", dbCluster.AuthType, dbCluster.Name)
-				m.db.UpdateClusterStatus(dbCluster.Name, "error")
-				continue
-			}
+		m.mu.RLock()
+		_, exists := m.clients[dbCluster.Name]
+		m.mu.RUnlock()
+		if exists {
+			continue
+		}
 
-			// Update status based on load result
-			if loadErr != nil {
-				log.Warnf("Failed to load cluster %s from database: %v", dbCluster.Name, loadErr)
-				m.db.UpdateClusterStatus(dbCluster.Name, "error")
-			} else {
-				log.Infof("Successfully loaded cluster %s (auth_type: %s)", dbCluster.Name, dbCluster.AuthType)
-				m.db.UpdateClusterStatus(dbCluster.Name, "connected")
-			}
+		if err := m.LoadCluster(dbCluster); err != nil {
+			log.Warnf("Failed to load cluster %s from database: %v", dbCluster.Name, err)
+		} else {
+			log.Infof("Successfully loaded cluster %s (auth_type: %s)", dbCluster.Name, dbCluster.AuthType)
 		}
 	}
 
 	return nil
 }
 
+// LoadCluster connects a cluster from its stored credentials, replacing its clients if it
+// was connected already, and records the outcome in the cluster status
+func (m *Manager) LoadCluster(dbCluster *db.Cluster) error {
+	var loadErr error
+
+	if err := m.UseStoredConnectionOptions(dbCluster); err != nil {
+		m.db.UpdateClusterStatus(dbCluster.Name, "error")
+		return err
+	}
+
+	// Load based on auth_type
+	switch dbCluster.AuthType {
+	case "kubeconfig":
+		// Parse auth_config JSON to extract kubeconfig
+		var authConfig map[string]string
+		if err := json.Unmarshal([]byte(dbCluster.AuthConfig), &authConfig); err != nil {
+			m.db.UpdateClusterStatus(dbCluster.Name, "error")
+			return fmt.Errorf("failed to parse auth_config: %w", err)
+		}
+
+		kubeconfigContent := authConfig["kubeconfig"]
+		context := authConfig["context"]
+
+		if kubeconfigContent == "" {
+			m.db.UpdateClusterStatus(dbCluster.Name, "error")
+			return fmt.Errorf("empty kubeconfig")
+		}
+		loadErr = m.AddClusterFromKubeconfigContent(dbCluster.Name, kubeconfigContent, context)
+
+	case "token":
+		// Use extracted server/ca/token fields
+		if dbCluster.Server == "" || dbCluster.CA == "" || dbCluster.Token == "" {
+			m.db.UpdateClusterStatus(dbCluster.Name, "error")
+			return fmt.Errorf("missing server/ca/token")
+		}
+		loadErr = m.AddClusterFromConfig(dbCluster.Name, dbCluster.Server, dbCluster.CA, dbCluster.Token)
+
+	case string(db.AuthTypeExec), string(db.AuthTypeEKS), string(db.AuthTypeGKE), string(db.AuthTypeAKS):
+		loadErr = m.AddClusterFromAuthConfig(dbCluster.Name, dbCluster.AuthType, dbCluster.AuthConfig)
+
+	case string(db.AuthTypeInCluster):
+		loadErr = m.AddClusterInCluster(dbCluster.Name)
+
+	case string(db.AuthTypeAgent):
+		loadErr = m.AddClusterFromAgent(dbCluster.Name)
+
+	default:
+		m.db.UpdateClusterStatus(dbCluster.Name, "error")
+		return fmt.Errorf("unsupported auth_type '%s'", dbCluster.AuthType)
+	}
+
+	m.SetImpersonation(dbCluster.Name, dbCluster.Impersonate)
+
+	// Update status based on load result
+	if loadErr != nil {
+		m.db.UpdateClusterStatus(dbCluster.Name, "error")
+		return loadErr
+	}
+	m.db.UpdateClusterStatus(dbCluster.Name, "connected")
+	return nil
+}
+
 // AddClusterFromKubeconfig adds a cluster from a kubeconfig file
 func (m *Manager) AddClusterFromKubeconfig(name, kubeconfigPath, kubeContext string) error {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-
 	// Build config from kubeconfig
 	loadingRules := &clientcmd.ClientConfigLoadingRules{ExplicitPath: kubeconfigPath}
 	configOverrides := &clientcmd.ConfigOverrides{}
@@ -146,6 +189,13 @@ func (m *Manager) AddClusterFromKubeconfig(name, kubeconfigPath, kubeContext str
 		return fmt.Errorf("failed to build config: %w", err)
 	}
 
+	if err := m.applyConnectionOptions(name, config); err != nil {
+		return err
+	}
+
+	// Count failed API server requests per cluster
+	config.Wrap(metrics.ClusterTransport(name))
+
 	// Create clientset
 	clientset, err := kubernetes.NewForConfig(config)
 	if err != nil {
@@ -170,6 +220,10 @@ func (m *Manager) AddClusterFromKubeconfig(name, kubeconfigPath, kubeContext str
 		return fmt.Errorf("failed to connect to cluster: %w", err)
 	}
 
+	// The connection is tested before locking so that an unreachable cluster does not
+	// block the other clusters
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.clients[name] = clientset
 	m.dynamicClients[name] = dynamicClient
 	m.apiextensionsClients[name] = apiextensionsClient
@@ -182,9 +236,6 @@ func (m *Manager) AddClusterFromKubeconfig(name, kubeconfigPath, kubeContext str
 
 // AddClusterFromConfig adds a cluster from server, CA, and token
 func (m *Manager) AddClusterFromConfig(name, server, ca, token string) error {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-
 	// Decode base64 CA certificate
 	caDecoded, err := base64.StdEncoding.DecodeString(ca)
 	if err != nil {
@@ -206,6 +257,13 @@ func (m *Manager) AddClusterFromConfig(name, server, ca, token string) error {
 		},
 	}
 
+	if err := m.applyConnectionOptions(name, config); err != nil {
+		return err
+	}
+
+	// Count failed API server requests per cluster
+	config.Wrap(metrics.ClusterTransport(name))
+
 	// Create clientset
 	clientset, err := kubernetes.NewForConfig(config)
 	if err != nil {
@@ -230,6 +288,8 @@ func (m *Manager) AddClusterFromConfig(name, server, ca, token string) error {
 		return fmt.Errorf("failed to connect to cluster: %w", err)
 	}
 
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.clients[name] = clientset
 	m.dynamicClients[name] = dynamicClient
 	m.apiextensionsClients[name] = apiextensionsClient
@@ -240,11 +300,30 @@ func (m *Manager) AddClusterFromConfig(name, server, ca, token string) error {
 	return nil
 }
 
+// ContextKubeconfig returns a kubeconfig holding only the given context, and the server
+// of the context. With inline, certificate and key files it refers to are read into it,
+// which is only safe for kubeconfigs that come from the server's own filesystem.
+func ContextKubeconfig(kubeconfig *clientcmdapi.Config, context string, inline bool) (string, string, error) {
+	config := kubeconfig.DeepCopy()
+	config.CurrentContext = context
+	if err := clientcmdapi.MinifyConfig(config); err != nil {
+		return "", "", err
+	}
+	if inline {
+		if err := clientcmdapi.FlattenConfig(config); err != nil {
+			return "", "", err
+		}
+	}
+	content, err := clientcmd.Write(*config)
+	if err != nil {
+		return "", "", err
+	}
+	server := config.Clusters[config.Contexts[context].Cluster].Server
+	return string(content), server, nil
+}
+
 // AddClusterFromKubeconfigContent adds a cluster from kubeconfig content (YAML string)
 func (m *Manager) AddClusterFromKubeconfigContent(name, kubeconfigContent, kubeContext string) error {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-
 	// Parse kubeconfig content
 	config, err := clientcmd.RESTConfigFromKubeConfig([]byte(kubeconfigContent))
 	if err != nil {
@@ -270,6 +349,19 @@ func (m *Manager) AddClusterFromKubeconfigContent(name, kubeconfigContent, kubeC
 		}
 	}
 
+	if config.ExecProvider != nil {
+		if err := m.checkExecCommand(config.ExecProvider.Command); err != nil {
+			return err
+		}
+	}
+
+	if err := m.applyConnectionOptions(name, config); err != nil {
+		return err
+	}
+
+	// Count failed API server requests per cluster
+	config.Wrap(metrics.ClusterTransport(name))
+
 	// Create clientset
 	clientset, err := kubernetes.NewForConfig(config)
 	if err != nil {
@@ -294,6 +386,8 @@ func (m *Manager) AddClusterFromKubeconfigContent(name, kubeconfigContent, kubeC
 		return fmt.Errorf("failed to connect to cluster: %w", err)
 	}
 
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.clients[name] = clientset
 	m.dynamicClients[name] = dynamicClient
 	m.apiextensionsClients[name] = apiextensionsClient
@@ -304,8 +398,29 @@ func (m *Manager) AddClusterFromKubeconfigContent(name, kubeconfigContent, kubeC
 	return nil
 }
 
+// AddClusterFromClients registers a cluster with already-built clients, e.g. the fake
+// clientsets used by the API test harness. The cluster has no REST config, so features
+// that need one (exec, port-forward, and metrics unless AddMetricsClient provides a
+// client) report it as not found.
+func (m *Manager) AddClusterFromClients(name string, client kubernetes.Interface, dynamicClient dynamic.Interface) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.clients[name] = client
+	m.dynamicClients[name] = dynamicClient
+}
+
+// AddMetricsClient registers an already-built metrics-server client for a cluster added
+// with AddClusterFromClients, e.g. the fake clientset used by the API test harness
+func (m *Manager) AddMetricsClient(name string, metricsClient metricsclientset.Interface) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.metricsClients[name] = metricsClient
+}
+
 // GetClient returns a Kubernetes client for the specified cluster
-func (m *Manager) GetClient(name string) (*kubernetes.Clientset, error) {
+func (m *Manager) GetClient(name string) (kubernetes.Interface, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
@@ -357,10 +472,13 @@ func (m *Manager) GetConfig(name string) (*rest.Config, error) {
 }
 
 // GetMetricsClient returns a typed metrics client for metrics-server API
-func (m *Manager) GetMetricsClient(name string) (*metricsclientset.Clientset, error) {
+func (m *Manager) GetMetricsClient(name string) (metricsclientset.Interface, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
+	if metricsClient, exists := m.metricsClients[name]; exists {
+		return metricsClient, nil
+	}
 	cfg, exists := m.configs[name]
 	if !exists {
 		return nil, fmt.Errorf("cluster %s not found", name)
@@ -383,7 +501,10 @@ func (m *Manager) RemoveCluster(name string) error {
 	delete(m.clients, name)
 	delete(m.dynamicClients, name)
 	delete(m.apiextensionsClients, name)
+	delete(m.metricsClients, name)
 	delete(m.configs, name)
+	delete(m.impersonate, name)
+	m.dropImpersonated(name)
 
 	// NOTE: Do NOT delete from database here!
 	// This method is called when disabling a cluster (toggle OFF)
@@ -395,6 +516,40 @@ func (m *Manager) RemoveCluster(name string) error {
 	return nil
 }
 
+// ClusterNames returns the names of all connected clusters without contacting them
+func (m *Manager) ClusterNames() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	names := make([]string, 0, len(m.clients))
+	for name := range m.clients {
+		names = append(names, name)
+	}
+	return names
+}
+
+// ReloadFromDB connects the enabled clusters of the database again and drops those no
+// longer enabled, after the cluster table was replaced (e.g. by a restore)
+func (m *Manager) ReloadFromDB() error {
+	dbClusters, err := m.db.ListEnabledClusters()
+	if err != nil {
+		return err
+	}
+	enabled := make(map[string]bool, len(dbClusters))
+	for _, dbCluster := range dbClusters {
+		enabled[dbCluster.Name] = true
+		if err := m.LoadCluster(dbCluster); err != nil {
+			log.Warnf("Failed to load cluster %s from database: %v", dbCluster.Name, err)
+		}
+	}
+	for _, name := range m.ClusterNames() {
+		if !enabled[name] {
+			m.RemoveCluster(name)
+		}
+	}
+	return nil
+}
+
 // ListClusters returns a list of all managed clusters
 func (m *Manager) ListClusters() ([]ClusterInfo, error) {
 	m.mu.RLock()
@@ -409,7 +564,7 @@ func (m *Manager) ListClusters() ([]ClusterInfo, error) {
 		}
 
 		// Get cluster version
-		version, err := client.ServerVersion()
+		version, err := client.Discovery().ServerVersion()
 		if err != nil {
 			info.Status = "error"
 			info.Version = "unknown"
@@ -447,7 +602,7 @@ func (m *Manager) GetClusterInfo(name string) (*ClusterInfo, error) {
 
 	// Get cluster version
 	ctx := context.Background()
-	version, err := client.ServerVersion()
+	version, err := client.Discovery().ServerVersion()
 	if err != nil {
 		info.Status = "error"
 		info.Version = "unknown"
@@ -0,0 +1,256 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/sonnguyen/kubelens/internal/db"
+	"github.com/sonnguyen/kubelens/internal/ws"
+)
+
+const (
+	// StatusMessageType is the WebSocket message type of cluster status changes, published
+	// on the topic StatusTopic/<cluster> so only the users who can see the cluster get them
+	StatusMessageType = "cluster_status"
+	StatusTopic       = ws.ClustersTopic
+
+	// probeTimeout bounds a single probe of a cluster
+	probeTimeout = 10 * time.Second
+	// maxProbeBackoff bounds the delay between probes of a failing cluster
+	maxProbeBackoff = 5 * time.Minute
+)
+
+// Publisher publishes messages to WebSocket clients (ws.Hub)
+type Publisher interface {
+	Publish(topic, msgType string, payload interface{})
+}
+
+// StatusChange is published when the status of a cluster changes
+type StatusChange struct {
+	Cluster   string    `json:"cluster"`
+	Status    string    `json:"status"` // connected or error
+	Previous  string    `json:"previous,omitempty"`
+	Version   string    `json:"version,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	Failures  int       `json:"failures,omitempty"` // consecutive failed probes
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// probeState is what the prober remembers of a cluster between probes
+type probeState struct {
+	status    string
+	failures  int
+	nextProbe time.Time
+	probing   bool
+}
+
+// Prober periodically checks that each enabled cluster answers with its credentials. A
+// cluster that fails is retried with exponential backoff and reconnected from its stored
+// credentials; clusters that failed to load at startup are connected once they answer.
+type Prober struct {
+	db       *db.DB
+	manager  *Manager
+	hub      Publisher
+	interval time.Duration
+	done     chan bool
+
+	mu     sync.Mutex
+	states map[string]*probeState // by cluster
+}
+
+// NewProber creates a prober checking each enabled cluster every interval
+func NewProber(database *db.DB, manager *Manager, hub Publisher, interval time.Duration) *Prober {
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	return &Prober{
+		db:       database,
+		manager:  manager,
+		hub:      hub,
+		interval: interval,
+		done:     make(chan bool),
+		states:   map[string]*probeState{},
+	}
+}
+
+// Start begins probing the clusters
+func (p *Prober) Start() {
+	go func() {
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+
+		p.probeAll()
+		for {
+			select {
+			case <-ticker.C:
+				p.probeAll()
+			case <-p.done:
+				return
+			}
+		}
+	}()
+
+	log.Infof("✅ Cluster health prober started (interval: %v)", p.interval)
+}
+
+// Stop stops probing
+func (p *Prober) Stop() {
+	close(p.done)
+	log.Info("Cluster health prober stopped")
+}
+
+// probeAll probes the enabled clusters that are due. Probes run concurrently; a cluster
+// whose previous probe is still running is skipped.
+func (p *Prober) probeAll() {
+	clusters, err := p.db.ListEnabledClusters()
+	if err != nil {
+		log.Errorf("Failed to list clusters to probe: %v", err)
+		return
+	}
+
+	now := time.Now()
+	enabled := make(map[string]bool, len(clusters))
+	var wg sync.WaitGroup
+	p.mu.Lock()
+	for _, dbCluster := range clusters {
+		enabled[dbCluster.Name] = true
+		state, ok := p.states[dbCluster.Name]
+		if !ok {
+			state = &probeState{status: dbCluster.Status}
+			p.states[dbCluster.Name] = state
+		}
+		if state.probing || now.Before(state.nextProbe) {
+			continue
+		}
+		state.probing = true
+
+		wg.Add(1)
+		go func(dbCluster *db.Cluster) {
+			defer wg.Done()
+			p.probe(dbCluster)
+		}(dbCluster)
+	}
+	// Forget clusters that were disabled or deleted
+	for name, state := range p.states {
+		if !enabled[name] && !state.probing {
+			delete(p.states, name)
+		}
+	}
+	p.mu.Unlock()
+	wg.Wait()
+}
+
+// probe checks one cluster, reconnecting it when it is not connected or failing, and
+// records the outcome
+func (p *Prober) probe(dbCluster *db.Cluster) {
+	name := dbCluster.Name
+
+	p.mu.Lock()
+	failing := p.states[name].failures > 0
+	p.mu.Unlock()
+
+	var version string
+	reconnected := false
+	client, err := p.manager.GetClient(name)
+	if err == nil {
+		version, err = check(client)
+	}
+	// A cluster that is not connected, or keeps failing with its current clients, is
+	// reconnected from its stored credentials (which may have been rotated)
+	if client == nil || (err != nil && failing) {
+		reconnected = true
+		if loadErr := p.manager.LoadCluster(dbCluster); loadErr != nil {
+			err = loadErr
+		} else if client, err = p.manager.GetClient(name); err == nil {
+			version, err = check(client)
+			if err == nil {
+				log.Infof("Reconnected cluster %s", name)
+			}
+		}
+	}
+
+	p.record(name, version, err, reconnected)
+}
+
+// check verifies that a cluster answers with its credentials: a SelfSubjectAccessReview
+// needs a valid identity even where the version endpoint is public
+func check(client kubernetes.Interface) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+	defer cancel()
+
+	review := &authorizationv1.SelfSubjectAccessReview{
+		Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+			ResourceAttributes: &authorizationv1.ResourceAttributes{Verb: "list", Resource: "namespaces"},
+		},
+	}
+	if _, err := client.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, review, metav1.CreateOptions{}); err != nil {
+		return "", fmt.Errorf("access review failed: %w", err)
+	}
+	version, err := client.Discovery().ServerVersion()
+	if err != nil {
+		return "", fmt.Errorf("failed to get server version: %w", err)
+	}
+	return version.GitVersion, nil
+}
+
+// record updates the probe state of a cluster, schedules its next probe, and stores and
+// publishes its status when it changed. A reconnect attempt stores its own status, which
+// is overwritten with the outcome of the probe.
+func (p *Prober) record(name, version string, err error, reconnected bool) {
+	now := time.Now()
+	change := StatusChange{Cluster: name, Status: "connected", Version: version, CheckedAt: now}
+
+	p.mu.Lock()
+	state := p.states[name]
+	state.probing = false
+	if err != nil {
+		state.failures++
+		change.Status = "error"
+		change.Error = err.Error()
+		change.Failures = state.failures
+	} else {
+		state.failures = 0
+	}
+	state.nextProbe = now.Add(probeBackoff(p.interval, state.failures))
+	change.Previous = state.status
+	changed := state.status != change.Status
+	state.status = change.Status
+	p.mu.Unlock()
+
+	if changed || reconnected {
+		if dbErr := p.db.UpdateClusterStatus(name, change.Status); dbErr != nil {
+			log.Errorf("Failed to update status of cluster %s: %v", name, dbErr)
+		}
+	}
+	if !changed {
+		return
+	}
+	if err != nil {
+		log.Warnf("Cluster %s is unhealthy: %v", name, err)
+	} else {
+		log.Infof("Cluster %s is healthy (%s)", name, version)
+	}
+	if p.hub != nil {
+		p.hub.Publish(ws.Topic(StatusTopic, name), StatusMessageType, change)
+	}
+}
+
+// probeBackoff returns the delay before the next probe: the interval while healthy,
+// doubling with each consecutive failure up to maxProbeBackoff
+func probeBackoff(interval time.Duration, failures int) time.Duration {
+	delay := interval
+	for i := 0; i < failures && delay < maxProbeBackoff; i++ {
+		delay *= 2
+	}
+	if delay > maxProbeBackoff && interval < maxProbeBackoff {
+		delay = maxProbeBackoff
+	}
+	return delay
+}
@@ -0,0 +1,102 @@
+package cluster
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+type recordingPublisher struct {
+	changes []StatusChange
+}
+
+func (r *recordingPublisher) Publish(topic, msgType string, payload interface{}) {
+	if topic == StatusTopic+"/prod" && msgType == StatusMessageType {
+		r.changes = append(r.changes, payload.(StatusChange))
+	}
+}
+
+func TestProberStatusChanges(t *testing.T) {
+	database, err := db.New(filepath.Join(t.TempDir(), "kubelens.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	dbCluster := &db.Cluster{Name: "prod", AuthType: "token", AuthConfig: db.JSON("{}"), Enabled: true, Status: "connected"}
+	if err := database.CreateCluster(dbCluster); err != nil {
+		t.Fatal(err)
+	}
+
+	// The access review fails while the credentials are rejected
+	rejected := true
+	client := fake.NewSimpleClientset()
+	client.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if rejected {
+			return true, nil, apierrors.NewUnauthorized("token expired")
+		}
+		return true, action.(k8stesting.CreateAction).GetObject(), nil
+	})
+	manager := NewManager(database)
+	manager.AddClusterFromClients("prod", client, nil)
+
+	hub := &recordingPublisher{}
+	prober := NewProber(database, manager, hub, time.Minute)
+	prober.states["prod"] = &probeState{status: "connected"}
+	probe := func() {
+		prober.states["prod"].probing = true
+		prober.probe(dbCluster)
+	}
+
+	probe()
+	if len(hub.changes) != 1 || hub.changes[0].Status != "error" || hub.changes[0].Previous != "connected" {
+		t.Fatalf("changes = %+v, want one change to error", hub.changes)
+	}
+	if stored, _ := database.GetCluster("prod"); stored.Status != "error" {
+		t.Errorf("stored status = %q, want error", stored.Status)
+	}
+
+	// Still failing: the reconnect from stored credentials fails too, and nothing is published
+	probe()
+	if len(hub.changes) != 1 {
+		t.Fatalf("changes = %+v, want no new change", hub.changes)
+	}
+	if state := prober.states["prod"]; state.failures != 2 || time.Until(state.nextProbe) < 3*time.Minute {
+		t.Errorf("failures = %d, next probe in %v, want 2 failures and a 4m backoff", state.failures, time.Until(state.nextProbe))
+	}
+	if _, err := manager.GetClient("prod"); err != nil {
+		t.Errorf("failed reconnect dropped the cluster: %v", err)
+	}
+
+	rejected = false
+	probe()
+	if len(hub.changes) != 2 || hub.changes[1].Status != "connected" || hub.changes[1].Version == "" {
+		t.Fatalf("changes = %+v, want a change back to connected", hub.changes)
+	}
+	if stored, _ := database.GetCluster("prod"); stored.Status != "connected" {
+		t.Errorf("stored status = %q, want connected", stored.Status)
+	}
+}
+
+func TestProbeBackoff(t *testing.T) {
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{0, 30 * time.Second},
+		{1, time.Minute},
+		{3, 4 * time.Minute},
+		{10, 5 * time.Minute},
+	}
+	for _, tt := range tests {
+		if got := probeBackoff(30*time.Second, tt.failures); got != tt.want {
+			t.Errorf("probeBackoff(30s, %d) = %v, want %v", tt.failures, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,250 @@
+package cluster
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/sonnguyen/kubelens/internal/audit"
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+const (
+	// ServiceAccountNamespace and ServiceAccountName name the ServiceAccount kubelens sets
+	// up in each cluster it adds
+	ServiceAccountNamespace = "kube-system"
+	ServiceAccountName      = "kubelens"
+
+	// DefaultCredentialLifetime is the lifetime requested for rotated tokens
+	DefaultCredentialLifetime = 7 * 24 * time.Hour
+	// MinCredentialLifetime is the shortest token lifetime the TokenRequest API accepts
+	MinCredentialLifetime = 10 * time.Minute
+
+	// rotationCheckInterval is how often the rotator looks for credentials due for rotation
+	rotationCheckInterval = 5 * time.Minute
+)
+
+var (
+	// ErrRotationUnsupported is returned for clusters whose credentials kubelens does not
+	// manage: cloud and exec clusters get short-lived tokens already, and in-cluster,
+	// agent and discovered clusters take theirs from elsewhere
+	ErrRotationUnsupported = errors.New("credentials of this cluster cannot be rotated")
+	// ErrNoServiceAccount is returned when the kubelens ServiceAccount is missing from a cluster
+	ErrNoServiceAccount = errors.New("kubelens ServiceAccount not found in the cluster")
+)
+
+// CredentialRotation describes a completed credential rotation
+type CredentialRotation struct {
+	Cluster          string    `json:"cluster"`
+	PreviousAuthType string    `json:"previous_auth_type"`
+	ServiceAccount   string    `json:"service_account"`
+	RotatedAt        time.Time `json:"rotated_at"`
+	ExpiresAt        time.Time `json:"expires_at"`
+}
+
+// RotateCredentials mints a token for the kubelens ServiceAccount of a cluster with the
+// TokenRequest API and makes it the cluster's credentials: the new clients are tested
+// before they replace the live ones, then the token is stored. Clusters of the kubeconfig
+// auth type become token clusters. Tokens issued before stay valid until they expire.
+func (m *Manager) RotateCredentials(name string, lifetime time.Duration) (*CredentialRotation, error) {
+	m.rotateMu.Lock()
+	defer m.rotateMu.Unlock()
+
+	if lifetime == 0 {
+		lifetime = DefaultCredentialLifetime
+	}
+	if lifetime < MinCredentialLifetime {
+		return nil, fmt.Errorf("token lifetime must be at least %v", MinCredentialLifetime)
+	}
+
+	dbCluster, err := m.db.GetCluster(name)
+	if err != nil {
+		return nil, err
+	}
+	if dbCluster.Source != "" || (dbCluster.AuthType != "token" && dbCluster.AuthType != "kubeconfig") {
+		return nil, fmt.Errorf("%w (auth_type %s)", ErrRotationUnsupported, dbCluster.AuthType)
+	}
+	client, err := m.GetClient(name)
+	if err != nil {
+		return nil, err
+	}
+	config, err := m.GetConfig(name)
+	if err != nil {
+		return nil, err
+	}
+
+	server, ca := dbCluster.Server, dbCluster.CA
+	if dbCluster.AuthType != "token" {
+		server = config.Host
+		caData := config.TLSClientConfig.CAData
+		if len(caData) == 0 && config.TLSClientConfig.CAFile != "" {
+			if caData, err = os.ReadFile(config.TLSClientConfig.CAFile); err != nil {
+				return nil, fmt.Errorf("failed to read CA file: %w", err)
+			}
+		}
+		if len(caData) == 0 {
+			return nil, fmt.Errorf("cluster has no CA certificate to store with the token")
+		}
+		ca = base64.StdEncoding.EncodeToString(caData)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	seconds := int64(lifetime / time.Second)
+	tokenRequest, err := client.CoreV1().ServiceAccounts(ServiceAccountNamespace).CreateToken(ctx, ServiceAccountName,
+		&authenticationv1.TokenRequest{Spec: authenticationv1.TokenRequestSpec{ExpirationSeconds: &seconds}},
+		metav1.CreateOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, ErrNoServiceAccount
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to request a ServiceAccount token: %w", err)
+	}
+	token := base64.StdEncoding.EncodeToString([]byte(tokenRequest.Status.Token))
+
+	// The live clients are only replaced once the new token works
+	if err := m.AddClusterFromConfig(name, server, ca, token); err != nil {
+		return nil, err
+	}
+	m.SetImpersonation(name, dbCluster.Impersonate)
+
+	rotation := &CredentialRotation{
+		Cluster:          name,
+		PreviousAuthType: dbCluster.AuthType,
+		ServiceAccount:   ServiceAccountNamespace + "/" + ServiceAccountName,
+		RotatedAt:        time.Now().UTC(),
+		ExpiresAt:        tokenRequest.Status.ExpirationTimestamp.UTC(),
+	}
+	authConfig, _ := json.Marshal(map[string]string{"server": server, "ca": ca, "token": token})
+	dbCluster.AuthType = "token"
+	dbCluster.AuthConfig = db.JSON(authConfig)
+	dbCluster.Server = server
+	dbCluster.CA = ca
+	dbCluster.Token = token
+	dbCluster.Status = "connected"
+	dbCluster.CredentialsRotatedAt = &rotation.RotatedAt
+	dbCluster.CredentialsExpireAt = &rotation.ExpiresAt
+	if err := m.db.SaveCluster(dbCluster); err != nil {
+		return nil, fmt.Errorf("cluster connected with the new token but it could not be stored: %w", err)
+	}
+
+	log.Infof("Rotated credentials of cluster %s (expire at %s)", name, rotation.ExpiresAt.Format(time.RFC3339))
+	return rotation, nil
+}
+
+// rotationDue reports whether credentials rotated by kubelens should be rotated again:
+// they are older than interval, or less than a quarter of their lifetime is left
+func rotationDue(dbCluster *db.Cluster, interval time.Duration, now time.Time) bool {
+	if dbCluster.CredentialsRotatedAt == nil {
+		return false
+	}
+	rotatedAt := *dbCluster.CredentialsRotatedAt
+	if !now.Before(rotatedAt.Add(interval)) {
+		return true
+	}
+	if dbCluster.CredentialsExpireAt != nil {
+		expireAt := *dbCluster.CredentialsExpireAt
+		return expireAt.Sub(now) < expireAt.Sub(rotatedAt)/4
+	}
+	return false
+}
+
+// Rotator rotates the credentials of clusters on a schedule. Only clusters whose
+// credentials were rotated through the API before are rotated, so the first rotation of a
+// cluster opts it in.
+type Rotator struct {
+	db       *db.DB
+	manager  *Manager
+	audit    *audit.Logger
+	interval time.Duration
+	lifetime time.Duration
+	done     chan bool
+	wg       sync.WaitGroup
+}
+
+// NewRotator creates a rotator renewing credentials every interval with tokens of the given
+// lifetime. Tokens outlive the interval so that a failed rotation can be retried.
+func NewRotator(database *db.DB, manager *Manager, auditLogger *audit.Logger, interval, lifetime time.Duration) *Rotator {
+	if lifetime < 2*interval {
+		lifetime = 2 * interval
+	}
+	if lifetime < MinCredentialLifetime {
+		lifetime = MinCredentialLifetime
+	}
+	return &Rotator{
+		db:       database,
+		manager:  manager,
+		audit:    auditLogger,
+		interval: interval,
+		lifetime: lifetime,
+		done:     make(chan bool),
+	}
+}
+
+// Start starts the rotation loop
+func (r *Rotator) Start() {
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		ticker := time.NewTicker(rotationCheckInterval)
+		defer ticker.Stop()
+
+		log.Infof("Credential rotator started (interval: %v, token lifetime: %v)", r.interval, r.lifetime)
+		for {
+			select {
+			case <-ticker.C:
+				r.RotateDue()
+			case <-r.done:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the rotation loop
+func (r *Rotator) Stop() {
+	close(r.done)
+	r.wg.Wait()
+}
+
+// RotateDue rotates the credentials of the enabled clusters that are due
+func (r *Rotator) RotateDue() {
+	clusters, err := r.db.ListEnabledClusters()
+	if err != nil {
+		log.Errorf("Failed to list clusters for credential rotation: %v", err)
+		return
+	}
+	now := time.Now()
+	for _, dbCluster := range clusters {
+		if !rotationDue(dbCluster, r.interval, now) {
+			continue
+		}
+		rotation, err := r.manager.RotateCredentials(dbCluster.Name, r.lifetime)
+		if err != nil {
+			log.Warnf("Scheduled credential rotation of cluster %s failed: %v", dbCluster.Name, err)
+			r.record(dbCluster.Name, fmt.Sprintf("Scheduled credential rotation of cluster %s failed: %v", dbCluster.Name, err), false)
+			continue
+		}
+		r.record(dbCluster.Name, fmt.Sprintf("Rotated credentials of cluster %s (expire at %s)",
+			dbCluster.Name, rotation.ExpiresAt.Format(time.RFC3339)), true)
+	}
+}
+
+func (r *Rotator) record(name, description string, success bool) {
+	if r.audit == nil {
+		return
+	}
+	if err := r.audit.LogAudit(audit.EventAuditClusterCredentialsRotated, nil, "system", name, "rotate_credentials", description, success); err != nil {
+		log.Errorf("Failed to create audit log: %v", err)
+	}
+}
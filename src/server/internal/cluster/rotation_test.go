@@ -0,0 +1,128 @@
+package cluster
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+func TestRotateCredentials(t *testing.T) {
+	var mu sync.Mutex
+	var authorization string
+	var requested int64
+	apiServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v1/namespaces/kube-system/serviceaccounts/kubelens/token":
+			// client-go sends the request as protobuf
+			body, _ := io.ReadAll(r.Body)
+			var tr authenticationv1.TokenRequest
+			if _, _, err := scheme.Codecs.UniversalDeserializer().Decode(body, nil, &tr); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			requested = *tr.Spec.ExpirationSeconds
+			tr.Status = authenticationv1.TokenRequestStatus{
+				Token:               "minted",
+				ExpirationTimestamp: metav1.NewTime(time.Now().Add(time.Duration(requested) * time.Second)),
+			}
+			json.NewEncoder(w).Encode(&tr)
+		default:
+			authorization = r.Header.Get("Authorization")
+			w.Write([]byte(`{"major":"1","minor":"30","gitVersion":"v1.30.2"}`))
+		}
+	}))
+	defer apiServer.Close()
+
+	database, err := db.New(filepath.Join(t.TempDir(), "kubelens.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	ca := base64.StdEncoding.EncodeToString(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: apiServer.Certificate().Raw}))
+	token := base64.StdEncoding.EncodeToString([]byte("original"))
+	prod := &db.Cluster{Name: "prod", AuthType: "token", AuthConfig: db.JSON(`{}`), Server: apiServer.URL, CA: ca, Token: token, Enabled: true}
+	eks := &db.Cluster{Name: "eks", AuthType: "eks", AuthConfig: db.JSON(`{}`), Enabled: true}
+	for _, c := range []*db.Cluster{prod, eks} {
+		if err := database.CreateCluster(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m := NewManager(database)
+	if err := m.LoadCluster(prod); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.RotateCredentials("eks", 0); !errors.Is(err, ErrRotationUnsupported) {
+		t.Errorf("RotateCredentials(eks) = %v, want ErrRotationUnsupported", err)
+	}
+
+	rotation, err := m.RotateCredentials("prod", time.Hour)
+	if err != nil {
+		t.Fatalf("RotateCredentials() = %v", err)
+	}
+	if requested != 3600 || rotation.ExpiresAt.Before(time.Now().Add(59*time.Minute)) {
+		t.Errorf("requested %ds, expires at %v; want a one hour token", requested, rotation.ExpiresAt)
+	}
+
+	// The live client uses the new token
+	client, _ := m.GetClient("prod")
+	if _, err := client.Discovery().ServerVersion(); err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	if authorization != "Bearer minted" {
+		t.Errorf("API server saw Authorization %q, want the minted token", authorization)
+	}
+	mu.Unlock()
+
+	stored, _ := database.GetCluster("prod")
+	if decoded, _ := base64.StdEncoding.DecodeString(stored.Token); string(decoded) != "minted" {
+		t.Errorf("stored token = %q, want minted", decoded)
+	}
+	if stored.CredentialsRotatedAt == nil || stored.CredentialsExpireAt == nil {
+		t.Error("rotation times not stored")
+	}
+}
+
+func TestRotationDue(t *testing.T) {
+	now := time.Now()
+	at := func(d time.Duration) *time.Time {
+		t := now.Add(d)
+		return &t
+	}
+	tests := []struct {
+		name     string
+		cluster  db.Cluster
+		interval time.Duration
+		due      bool
+	}{
+		{"never rotated", db.Cluster{}, time.Hour, false},
+		{"recent", db.Cluster{CredentialsRotatedAt: at(-time.Minute), CredentialsExpireAt: at(2 * time.Hour)}, time.Hour, false},
+		{"interval passed", db.Cluster{CredentialsRotatedAt: at(-2 * time.Hour), CredentialsExpireAt: at(24 * time.Hour)}, time.Hour, true},
+		{"token nearly expired", db.Cluster{CredentialsRotatedAt: at(-50 * time.Minute), CredentialsExpireAt: at(10 * time.Minute)}, 24 * time.Hour, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if due := rotationDue(&tt.cluster, tt.interval, now); due != tt.due {
+				t.Errorf("rotationDue() = %v, want %v", due, tt.due)
+			}
+		})
+	}
+}
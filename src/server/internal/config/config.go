@@ -1,6 +1,7 @@
 package config
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/url"
 	"os"
@@ -26,13 +27,121 @@ type Config struct {
 	LogLevel                string   `mapstructure:"log_level"`
 	CORSOrigins             []string `mapstructure:"cors_origins"`
 	ReleaseMode             bool     `mapstructure:"release_mode"`
-	AdminPassword           string   `mapstructure:"admin_password"`
+	// Serve the first-run setup token on GET /api/v1/setup/token to clients on the same host
+	SetupTokenLocalAccess   bool     `mapstructure:"setup_token_local_access"`
 	GlobalRateLimitPerMin   int      `mapstructure:"global_rate_limit_per_min"`
 	LoginRateLimitPerMin    int      `mapstructure:"login_rate_limit_per_min"`
 	PublicURL               string   `mapstructure:"public_url"`        // Public URL for OAuth2 callbacks (e.g., https://api.kubelens.example.com)
+	// Crash report capture
+	CrashReportsEnabled       bool   `mapstructure:"crash_reports_enabled"`        // Capture reports for CrashLoopBackOff pods
+	CrashReportScanInterval   string `mapstructure:"crash_report_scan_interval"`   // How often clusters are scanned (e.g., 30s)
+	CrashReportLogLines       int    `mapstructure:"crash_report_log_lines"`       // Lines of previous container logs to keep
+	CrashReportRetentionDays  int    `mapstructure:"crash_report_retention_days"`  // Reports older than this are deleted
+	CrashReportMaxPerContainer int   `mapstructure:"crash_report_max_per_container"` // Newest N reports kept per pod container
+	UsageRetentionDays      int      `mapstructure:"usage_retention_days"` // Daily usage rollups older than this are deleted
+	AccessTokenTTL          string   `mapstructure:"access_token_ttl"`     // Lifetime of access JWTs (e.g., 15m)
+	RefreshTokenTTL         string   `mapstructure:"refresh_token_ttl"`    // Lifetime of refresh tokens (e.g., 720h)
+	// Endpoint classes hard-disabled for this deployment (e.g., node_shell,secret_reveal)
+	DisabledEndpoints       []string `mapstructure:"disabled_endpoints"`
+	// Read-only mode: reject changes to clusters (cannot be turned off through the admin API)
+	MaintenanceMode         bool     `mapstructure:"maintenance_mode"`
+	MaintenanceMessage      string   `mapstructure:"maintenance_message"`
+	// Prometheus metrics on /metrics, optionally protected by a bearer token
+	MetricsEnabled          bool     `mapstructure:"metrics_enabled"`
+	MetricsToken            string   `mapstructure:"metrics_token"`
+	// pprof and /debug/status for users with the settings manage permission
+	DebugEndpoints          bool     `mapstructure:"debug_endpoints"`
+	HelmIndexInterval       string   `mapstructure:"helm_index_interval"` // How often Helm repository indexes are refreshed (e.g., 1h)
+	AlertEvaluationInterval string   `mapstructure:"alert_evaluation_interval"` // How often alert rules are evaluated (e.g., 30s)
+	// Cluster event watcher: records Kubernetes Events and streams them to clients
+	EventWatchEnabled       bool     `mapstructure:"event_watch_enabled"`
+	EventHistoryRetention   string   `mapstructure:"event_history_retention"` // How long recorded events are kept (e.g., 72h)
+	ClusterProbeInterval    string   `mapstructure:"cluster_probe_interval"` // How often enabled clusters are health checked (e.g., 30s)
+	// Scheduled export of pod logs to object storage; disable on all replicas but one
+	LogArchiveEnabled       bool     `mapstructure:"log_archive_enabled"`
+	// Global search from an in-memory index fed by informers, instead of live listing
+	SearchIndexEnabled      bool     `mapstructure:"search_index_enabled"`
+	ExecAuthCommands        []string `mapstructure:"exec_auth_commands"`     // Exec credential plugins clusters may run
+	// Register the cluster kubelens runs in, using the ServiceAccount of its pod
+	InCluster               bool     `mapstructure:"in_cluster"`
+	InClusterName           string   `mapstructure:"in_cluster_name"`
+	// Directory of kubeconfig files whose contexts are registered as clusters and kept in sync
+	KubeconfigDir           string   `mapstructure:"kubeconfig_dir"`
+	// How often credentials rotated by kubelens are renewed (e.g., 72h); empty disables scheduled rotation
+	CredentialRotationInterval string `mapstructure:"credential_rotation_interval"`
+	// HashiCorp Vault: secrets given as vault:<path>#<key> (database password, JWT_SECRET) are
+	// read from it, and cluster credentials can be kept there instead of the database
+	VaultAddr               string   `mapstructure:"vault_addr"`
+	VaultToken              string   `mapstructure:"vault_token"`
+	VaultRole               string   `mapstructure:"vault_role"`       // Kubernetes auth role, used when no token is set
+	VaultAuthMount          string   `mapstructure:"vault_auth_mount"` // Path of the Kubernetes auth method
+	VaultKVMount            string   `mapstructure:"vault_kv_mount"`   // Path of the KV v2 secrets engine
+	VaultNamespace          string   `mapstructure:"vault_namespace"`
+	VaultCACert             string   `mapstructure:"vault_ca_cert"`
+	VaultClusterCredentials bool     `mapstructure:"vault_cluster_credentials"` // Keep cluster credentials in Vault
+	VaultPrefix             string   `mapstructure:"vault_prefix"`              // KV path under which cluster credentials are kept
+	// Redis shares rate limiter buckets, session lookups and cluster summaries between replicas
+	RedisAddr               string   `mapstructure:"redis_addr"` // host:port; empty keeps that state per replica
+	RedisUsername           string   `mapstructure:"redis_username"`
+	RedisPassword           string   `mapstructure:"redis_password"`
+	RedisDB                 int      `mapstructure:"redis_db"`
+	RedisTLS                bool     `mapstructure:"redis_tls"`
+	RedisPrefix             string   `mapstructure:"redis_prefix"` // Prepended to every key
+	// Hash-chain audit entries so deleting or modifying them is detected, keyed with
+	// AuditChainKey (HMAC-SHA256) when set, and anchor the chain every AuditChainAnchorInterval
+	AuditHashChain           bool   `mapstructure:"audit_hash_chain"`
+	AuditChainKey            string `mapstructure:"audit_chain_key"`
+	AuditChainAnchorInterval string `mapstructure:"audit_chain_anchor_interval"`
+	// Record the commands typed in pod and node shells as audit events
+	ShellCommandAudit        bool   `mapstructure:"shell_command_audit"`
+	// Sinks every audit entry is shipped to besides the database (KUBELENS_AUDIT_SINKS takes a JSON array)
+	AuditSinks              []AuditSinkConfig `mapstructure:"audit_sinks"`
 	Clusters                []ClusterConfig `mapstructure:"clusters"`
 }
 
+// AuditSinkConfig configures a sink audit entries are shipped to: a syslog endpoint, an
+// S3-compatible bucket (S3, GCS through its XML API, MinIO) or an HTTP webhook
+type AuditSinkConfig struct {
+	Name string `mapstructure:"name" json:"name"`
+	Type string `mapstructure:"type" json:"type"` // syslog, s3 or webhook
+
+	// Filtering: entries of these categories (default all), at this level or above
+	// (info, warn, error, critical; default info) and of these event types (globs such as
+	// authn_*; default all)
+	Categories []string `mapstructure:"categories" json:"categories,omitempty"`
+	MinLevel   string   `mapstructure:"min_level" json:"min_level,omitempty"`
+	EventTypes []string `mapstructure:"event_types" json:"event_types,omitempty"`
+
+	// Backpressure: entries wait in a queue of QueueSize and are sent in batches of up to
+	// BatchSize, at least every FlushInterval. When the queue is full, entries are dropped
+	// (OnFull "drop", the default) or the request logging them waits up to a second for
+	// room ("block").
+	QueueSize     int    `mapstructure:"queue_size" json:"queue_size,omitempty"`
+	BatchSize     int    `mapstructure:"batch_size" json:"batch_size,omitempty"`
+	FlushInterval string `mapstructure:"flush_interval" json:"flush_interval,omitempty"`
+	OnFull        string `mapstructure:"on_full" json:"on_full,omitempty"`
+
+	// Syslog: udp://, tcp:// or tls://host:port; messages are RFC 5424 with the entry as JSON
+	Address  string `mapstructure:"address" json:"address,omitempty"`
+	Facility int    `mapstructure:"facility" json:"facility,omitempty"` // default 13 (log audit)
+	AppName  string `mapstructure:"app_name" json:"app_name,omitempty"`
+
+	// Webhook: batches are POSTed as a JSON array, signed like notification webhooks
+	URL           string            `mapstructure:"url" json:"url,omitempty"`
+	Headers       map[string]string `mapstructure:"headers" json:"headers,omitempty"`
+	SigningSecret string            `mapstructure:"signing_secret" json:"signing_secret,omitempty"`
+
+	// S3: batches are written as NDJSON objects <prefix>YYYY/MM/DD/<time>-<host>.ndjson.
+	// Keys default to AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY; for GCS use the endpoint
+	// https://storage.googleapis.com with HMAC keys.
+	Bucket          string `mapstructure:"bucket" json:"bucket,omitempty"`
+	Prefix          string `mapstructure:"prefix" json:"prefix,omitempty"`
+	Region          string `mapstructure:"region" json:"region,omitempty"`
+	Endpoint        string `mapstructure:"endpoint" json:"endpoint,omitempty"`
+	AccessKeyID     string `mapstructure:"access_key_id" json:"access_key_id,omitempty"`
+	SecretAccessKey string `mapstructure:"secret_access_key" json:"secret_access_key,omitempty"`
+}
+
 // ClusterConfig holds cluster-specific configuration
 type ClusterConfig struct {
 	Name       string `mapstructure:"name"`
@@ -54,7 +163,38 @@ func Load() (*Config, error) {
 	v.SetDefault("global_rate_limit_per_min", 1000)  // Default: 1000 requests per minute
 	v.SetDefault("login_rate_limit_per_min", 5)      // Default: 5 requests per minute
 	v.SetDefault("public_url", "http://localhost:8080") // Default for local development
-	// admin_password is optional - will be auto-generated if not set
+	v.SetDefault("crash_reports_enabled", true)
+	v.SetDefault("crash_report_scan_interval", "30s")
+	v.SetDefault("crash_report_log_lines", 200)
+	v.SetDefault("crash_report_retention_days", 14)
+	v.SetDefault("crash_report_max_per_container", 20)
+	v.SetDefault("usage_retention_days", 400)
+	v.SetDefault("access_token_ttl", "15m")
+	v.SetDefault("refresh_token_ttl", "720h")
+	v.SetDefault("metrics_enabled", true)
+	v.SetDefault("debug_endpoints", false)
+	v.SetDefault("helm_index_interval", "1h")
+	v.SetDefault("alert_evaluation_interval", "30s")
+	v.SetDefault("event_watch_enabled", true)
+	v.SetDefault("event_history_retention", "72h")
+	v.SetDefault("cluster_probe_interval", "30s")
+	v.SetDefault("log_archive_enabled", true)
+	v.SetDefault("search_index_enabled", true)
+	v.SetDefault("exec_auth_commands", []string{"aws", "aws-iam-authenticator", "gke-gcloud-auth-plugin", "kubelogin"})
+	v.SetDefault("in_cluster", false)
+	v.SetDefault("in_cluster_name", "in-cluster")
+	v.SetDefault("credential_rotation_interval", "")
+	v.SetDefault("vault_auth_mount", "kubernetes")
+	v.SetDefault("vault_kv_mount", "secret")
+	v.SetDefault("vault_cluster_credentials", false)
+	v.SetDefault("vault_prefix", "kubelens")
+	v.SetDefault("redis_db", 0)
+	v.SetDefault("redis_tls", false)
+	v.SetDefault("redis_prefix", "kubelens:")
+	v.SetDefault("audit_hash_chain", false)
+	v.SetDefault("shell_command_audit", true)
+	v.SetDefault("audit_chain_anchor_interval", "1h")
+	v.SetDefault("setup_token_local_access", false)
 
 	// Get kubeconfig from environment or default location
 	kubeconfig := os.Getenv("KUBECONFIG")
@@ -84,7 +224,7 @@ func Load() (*Config, error) {
 	v.AutomaticEnv()
 	
 	// Explicitly bind environment variables
-	v.BindEnv("admin_password")
+	v.BindEnv("setup_token_local_access")
 	v.BindEnv("global_rate_limit_per_min")
 	v.BindEnv("login_rate_limit_per_min")
 	v.BindEnv("database_type")
@@ -96,11 +236,62 @@ func Load() (*Config, error) {
 	v.BindEnv("database_sslmode")
 	v.BindEnv("database_path")
 	v.BindEnv("public_url")
+	v.BindEnv("crash_reports_enabled")
+	v.BindEnv("crash_report_scan_interval")
+	v.BindEnv("crash_report_log_lines")
+	v.BindEnv("crash_report_retention_days")
+	v.BindEnv("crash_report_max_per_container")
+	v.BindEnv("disabled_endpoints")
+	v.BindEnv("maintenance_mode")
+	v.BindEnv("maintenance_message")
+	v.BindEnv("metrics_enabled")
+	v.BindEnv("metrics_token")
+	v.BindEnv("debug_endpoints")
+	v.BindEnv("helm_index_interval")
+	v.BindEnv("alert_evaluation_interval")
+	v.BindEnv("event_watch_enabled")
+	v.BindEnv("event_history_retention")
+	v.BindEnv("cluster_probe_interval")
+	v.BindEnv("log_archive_enabled")
+	v.BindEnv("search_index_enabled")
+	v.BindEnv("exec_auth_commands")
+	v.BindEnv("in_cluster")
+	v.BindEnv("in_cluster_name")
+	v.BindEnv("kubeconfig_dir")
+	v.BindEnv("credential_rotation_interval")
+	v.BindEnv("vault_addr")
+	v.BindEnv("vault_token")
+	v.BindEnv("vault_role")
+	v.BindEnv("vault_auth_mount")
+	v.BindEnv("vault_kv_mount")
+	v.BindEnv("vault_namespace")
+	v.BindEnv("vault_ca_cert")
+	v.BindEnv("vault_cluster_credentials")
+	v.BindEnv("vault_prefix")
+	v.BindEnv("redis_addr")
+	v.BindEnv("redis_username")
+	v.BindEnv("redis_password")
+	v.BindEnv("redis_db")
+	v.BindEnv("redis_tls")
+	v.BindEnv("redis_prefix")
+	v.BindEnv("audit_hash_chain")
+	v.BindEnv("audit_chain_key")
+	v.BindEnv("audit_chain_anchor_interval")
+	v.BindEnv("shell_command_audit")
+	v.BindEnv("usage_retention_days")
+	v.BindEnv("access_token_ttl")
+	v.BindEnv("refresh_token_ttl")
 
 	var cfg Config
 	if err := v.Unmarshal(&cfg); err != nil {
 		return nil, err
 	}
+	if raw := os.Getenv("KUBELENS_AUDIT_SINKS"); raw != "" {
+		cfg.AuditSinks = nil
+		if err := json.Unmarshal([]byte(raw), &cfg.AuditSinks); err != nil {
+			return nil, fmt.Errorf("invalid KUBELENS_AUDIT_SINKS: %w", err)
+		}
+	}
 
 	// Ensure database directory exists (only for SQLite)
 	if cfg.DatabaseType == "" || cfg.DatabaseType == "sqlite" {
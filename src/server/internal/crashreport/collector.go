@@ -0,0 +1,239 @@
+package crashreport
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/sonnguyen/kubelens/internal/cluster"
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+// Options configures crash report capture
+type Options struct {
+	ScanInterval    time.Duration
+	LogLines        int64
+	RetentionDays   int
+	MaxPerContainer int
+}
+
+// Collector scans connected clusters for CrashLoopBackOff containers and stores
+// a crash report for every new restart it has not seen before
+type Collector struct {
+	db             *db.DB
+	clusterManager *cluster.Manager
+	opts           Options
+	done           chan bool
+}
+
+// EventSummary is the subset of a Kubernetes event stored with a crash report
+type EventSummary struct {
+	Type      string    `json:"type"`
+	Reason    string    `json:"reason"`
+	Message   string    `json:"message"`
+	Count     int32     `json:"count"`
+	Source    string    `json:"source,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// NewCollector creates a new crash report collector
+func NewCollector(database *db.DB, clusterManager *cluster.Manager, opts Options) *Collector {
+	if opts.ScanInterval <= 0 {
+		opts.ScanInterval = 30 * time.Second
+	}
+	if opts.LogLines <= 0 {
+		opts.LogLines = 200
+	}
+	return &Collector{
+		db:             database,
+		clusterManager: clusterManager,
+		opts:           opts,
+		done:           make(chan bool),
+	}
+}
+
+// Start starts the periodic scan and retention loop
+func (col *Collector) Start() {
+	go func() {
+		scanTicker := time.NewTicker(col.opts.ScanInterval)
+		retentionTicker := time.NewTicker(1 * time.Hour)
+		defer scanTicker.Stop()
+		defer retentionTicker.Stop()
+
+		col.enforceRetention()
+		for {
+			select {
+			case <-scanTicker.C:
+				col.scan()
+			case <-retentionTicker.C:
+				col.enforceRetention()
+			case <-col.done:
+				return
+			}
+		}
+	}()
+
+	log.Infof("✅ Crash report collector started (scan interval: %v)", col.opts.ScanInterval)
+}
+
+// Stop stops the collector
+func (col *Collector) Stop() {
+	close(col.done)
+	log.Info("Crash report collector stopped")
+}
+
+// scan inspects every connected cluster once
+func (col *Collector) scan() {
+	for _, clusterName := range col.clusterManager.ClusterNames() {
+		client, err := col.clusterManager.GetClient(clusterName)
+		if err != nil {
+			continue
+		}
+		col.scanCluster(clusterName, client)
+	}
+}
+
+// scanCluster captures reports for crash-looping containers in one cluster
+func (col *Collector) scanCluster(clusterName string, client kubernetes.Interface) {
+	ctx, cancel := context.WithTimeout(context.Background(), col.opts.ScanInterval)
+	defer cancel()
+
+	pods, err := client.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
+		FieldSelector: fields.OneTermNotEqualSelector("status.phase", string(corev1.PodSucceeded)).String(),
+	})
+	if err != nil {
+		log.Debugf("Crash report scan of cluster %s failed: %v", clusterName, err)
+		return
+	}
+
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.State.Waiting == nil || status.State.Waiting.Reason != "CrashLoopBackOff" {
+				continue
+			}
+			if status.LastTerminationState.Terminated == nil {
+				continue
+			}
+
+			exists, err := col.db.CrashReportExists(clusterName, string(pod.UID), status.Name, status.RestartCount)
+			if err != nil {
+				log.Warnf("Failed to check crash report: %v", err)
+				continue
+			}
+			if exists {
+				continue
+			}
+
+			report := col.buildReport(ctx, clusterName, client, pod, status)
+			if err := col.db.CreateCrashReport(report); err != nil {
+				log.Errorf("Failed to store crash report for %s/%s/%s: %v", clusterName, pod.Namespace, pod.Name, err)
+				continue
+			}
+			log.Infof("Captured crash report for %s/%s/%s container %s (restart %d)",
+				clusterName, pod.Namespace, pod.Name, status.Name, status.RestartCount)
+		}
+	}
+}
+
+// buildReport gathers termination details, previous logs and events for a container
+func (col *Collector) buildReport(ctx context.Context, clusterName string, client kubernetes.Interface, pod *corev1.Pod, status corev1.ContainerStatus) *db.CrashReport {
+	terminated := status.LastTerminationState.Terminated
+
+	report := &db.CrashReport{
+		ClusterName:        clusterName,
+		Namespace:          pod.Namespace,
+		PodName:            pod.Name,
+		PodUID:             string(pod.UID),
+		ContainerName:      status.Name,
+		Image:              status.Image,
+		RestartCount:       status.RestartCount,
+		ExitCode:           terminated.ExitCode,
+		Signal:             terminated.Signal,
+		Reason:             terminated.Reason,
+		TerminationMessage: terminated.Message,
+		NodeName:           pod.Spec.NodeName,
+	}
+	if !terminated.StartedAt.IsZero() {
+		t := terminated.StartedAt.Time
+		report.StartedAt = &t
+	}
+	if !terminated.FinishedAt.IsZero() {
+		t := terminated.FinishedAt.Time
+		report.FinishedAt = &t
+	}
+	if owner := metav1.GetControllerOf(pod); owner != nil {
+		report.OwnerKind = owner.Kind
+		report.OwnerName = owner.Name
+	}
+
+	// Logs of the previous (crashed) container instance
+	tailLines := col.opts.LogLines
+	logs, err := client.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{
+		Container: status.Name,
+		Previous:  true,
+		TailLines: &tailLines,
+	}).Do(ctx).Raw()
+	if err != nil {
+		log.Debugf("Failed to get previous logs for %s/%s: %v", pod.Namespace, pod.Name, err)
+	} else {
+		report.Logs = string(logs)
+	}
+
+	// Events related to the pod
+	events := []EventSummary{}
+	eventList, err := client.CoreV1().Events(pod.Namespace).List(ctx, metav1.ListOptions{
+		FieldSelector: fields.Set{
+			"involvedObject.name": pod.Name,
+			"involvedObject.uid":  string(pod.UID),
+		}.AsSelector().String(),
+	})
+	if err == nil {
+		for _, e := range eventList.Items {
+			ts := e.LastTimestamp.Time
+			if ts.IsZero() {
+				ts = e.EventTime.Time
+			}
+			events = append(events, EventSummary{
+				Type:      e.Type,
+				Reason:    e.Reason,
+				Message:   e.Message,
+				Count:     e.Count,
+				Source:    e.Source.Component,
+				Timestamp: ts,
+			})
+		}
+		sort.Slice(events, func(i, j int) bool { return events[i].Timestamp.Before(events[j].Timestamp) })
+	}
+	eventsJSON, _ := json.Marshal(events)
+	report.Events = db.JSON(eventsJSON)
+
+	return report
+}
+
+// enforceRetention deletes old reports and trims per-container history
+func (col *Collector) enforceRetention() {
+	if col.opts.RetentionDays > 0 {
+		cutoff := time.Now().AddDate(0, 0, -col.opts.RetentionDays)
+		if deleted, err := col.db.DeleteCrashReportsOlderThan(cutoff); err != nil {
+			log.Errorf("Failed to delete old crash reports: %v", err)
+		} else if deleted > 0 {
+			log.Infof("Deleted %d crash reports older than %d days", deleted, col.opts.RetentionDays)
+		}
+	}
+
+	if col.opts.MaxPerContainer > 0 {
+		if deleted, err := col.db.TrimCrashReports(col.opts.MaxPerContainer); err != nil {
+			log.Errorf("Failed to trim crash reports: %v", err)
+		} else if deleted > 0 {
+			log.Infof("Trimmed %d crash reports exceeding %d per container", deleted, col.opts.MaxPerContainer)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package crashreport
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/sonnguyen/kubelens/internal/apitest"
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+// crashLoopingPod returns a pod whose container is in CrashLoopBackOff after restarts
+func crashLoopingPod(name string, restarts int32) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: apitest.FixtureNamespace, UID: types.UID("uid-" + name)},
+		Spec:       corev1.PodSpec{NodeName: "node-1", Containers: []corev1.Container{{Name: "app", Image: "app:1.0"}}},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:         "app",
+				Image:        "app:1.0",
+				RestartCount: restarts,
+				State:        corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+				LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+					ExitCode: 1, Reason: "Error", Message: "panic: config missing",
+				}},
+			}},
+		},
+	}
+}
+
+func TestCrashReports(t *testing.T) {
+	s := apitest.New(t, crashLoopingPod("worker", 3), &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "healthy", Namespace: apitest.FixtureNamespace},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+	})
+	collector := NewCollector(s.DB, s.Clusters, Options{})
+
+	// Each restart is captured once, however often the cluster is scanned
+	collector.scan()
+	collector.scan()
+
+	h := NewHandler(s.DB)
+	reports := s.Router.Group("/api/v1/crash-reports")
+	reports.GET("", h.ListCrashReports)
+	reports.GET("/:id", h.GetCrashReport)
+	reports.DELETE("/:id", h.DeleteCrashReport)
+
+	var list struct {
+		Reports []*db.CrashReport `json:"reports"`
+		Total   int64             `json:"total"`
+	}
+	w := s.Get("/api/v1/crash-reports?cluster=" + apitest.ClusterName + "&namespace=" + apitest.FixtureNamespace)
+	if w.Code != http.StatusOK {
+		t.Fatalf("list: %d %s", w.Code, w.Body.String())
+	}
+	apitest.DecodeJSON(t, w, &list)
+	if list.Total != 1 || len(list.Reports) != 1 {
+		t.Fatalf("got %d reports, want the one of the crash-looping pod", list.Total)
+	}
+	report := list.Reports[0]
+	if report.PodName != "worker" || report.ContainerName != "app" || report.RestartCount != 3 ||
+		report.ExitCode != 1 || report.TerminationMessage != "panic: config missing" || report.NodeName != "node-1" {
+		t.Errorf("report = %+v, want the last termination of worker/app", report)
+	}
+
+	// Filters narrow the list
+	list.Reports, list.Total = nil, 0
+	apitest.DecodeJSON(t, s.Get("/api/v1/crash-reports?pod=healthy"), &list)
+	if list.Total != 0 {
+		t.Errorf("reports of a healthy pod = %d, want none", list.Total)
+	}
+
+	var got db.CrashReport
+	path := fmt.Sprintf("/api/v1/crash-reports/%d", report.ID)
+	w = s.Get(path)
+	if w.Code != http.StatusOK {
+		t.Fatalf("get: %d %s", w.Code, w.Body.String())
+	}
+	apitest.DecodeJSON(t, w, &got)
+	if got.Logs == "" {
+		t.Error("report has no logs of the previous container")
+	}
+
+	if w := s.Get("/api/v1/crash-reports/abc"); w.Code != http.StatusBadRequest {
+		t.Errorf("invalid id: %d, want 400", w.Code)
+	}
+	if w := s.Get("/api/v1/crash-reports/999"); w.Code != http.StatusNotFound {
+		t.Errorf("unknown id: %d, want 404", w.Code)
+	}
+
+	if w := s.Do(http.MethodDelete, path, nil); w.Code != http.StatusOK {
+		t.Fatalf("delete: %d %s", w.Code, w.Body.String())
+	}
+	if w := s.Get(path); w.Code != http.StatusNotFound {
+		t.Errorf("deleted report: %d, want 404", w.Code)
+	}
+	if w := s.Do(http.MethodDelete, path, nil); w.Code != http.StatusNotFound {
+		t.Errorf("delete twice: %d, want 404", w.Code)
+	}
+}
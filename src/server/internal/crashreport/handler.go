@@ -0,0 +1,99 @@
+package crashreport
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/sonnguyen/kubelens/internal/auth"
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+// Handler handles crash report API requests
+type Handler struct {
+	db *db.DB
+}
+
+// NewHandler creates a new crash report handler
+func NewHandler(database *db.DB) *Handler {
+	return &Handler{db: database}
+}
+
+// ListCrashReports handles GET /api/v1/crash-reports
+// Query params: cluster, namespace, pod, page, page_size
+func (h *Handler) ListCrashReports(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "50"))
+	if pageSize > 500 {
+		pageSize = 500 // Max 500 per page
+	}
+
+	reports, total, err := h.db.ListCrashReports(db.CrashReportFilters{
+		ClusterName: c.Query("cluster"),
+		Namespace:   c.Query("namespace"),
+		PodName:     c.Query("pod"),
+		Visible:     auth.ClusterScopeFilter(c, "pods"),
+		Page:        page,
+		PageSize:    pageSize,
+	})
+	if err != nil {
+		log.Errorf("Failed to list crash reports: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list crash reports"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"reports":   reports,
+		"total":     total,
+		"page":      page,
+		"page_size": pageSize,
+	})
+}
+
+// GetCrashReport handles GET /api/v1/crash-reports/:id
+func (h *Handler) GetCrashReport(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid crash report ID"})
+		return
+	}
+
+	report, err := h.db.GetCrashReport(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if !auth.CheckClusterScope(c, report.ClusterName, "pods", report.Namespace, "read") {
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// DeleteCrashReport handles DELETE /api/v1/crash-reports/:id
+func (h *Handler) DeleteCrashReport(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid crash report ID"})
+		return
+	}
+
+	report, err := h.db.GetCrashReport(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if !auth.CheckClusterScope(c, report.ClusterName, "pods", report.Namespace, "delete") {
+		return
+	}
+
+	if err := h.db.DeleteCrashReport(uint(id)); err != nil {
+		log.Errorf("Failed to delete crash report: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete crash report"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "crash report deleted successfully"})
+}
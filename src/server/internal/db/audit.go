@@ -3,6 +3,7 @@ package db
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
@@ -46,13 +47,29 @@ type IPActivity struct {
 // =============================================================================
 
 // CreateAuditLog creates a new audit log entry
-func (db *DB) CreateAuditLog(entry AuditLogEntry) error {
+func (db *DB) CreateAuditLog(entry *AuditLogEntry) error {
 	// Set datetime if not provided
 	if entry.Datetime.IsZero() {
 		entry.Datetime = time.Now().UTC()
 	}
+	entry.fillSearchFields()
 	
-	return db.GormDB.Create(&entry).Error
+	return db.GormDB.Create(entry).Error
+}
+
+// AuditOrganizationScope keeps the audit logs of an organization (all of them for 0),
+// with the events outside any organization if instanceEvents is set
+func AuditOrganizationScope(orgID uint, instanceEvents bool) func(*gorm.DB) *gorm.DB {
+	return func(tx *gorm.DB) *gorm.DB {
+		switch {
+		case orgID == 0:
+			return tx
+		case instanceEvents:
+			return tx.Where("(organization_id = ? OR organization_id IS NULL)", orgID)
+		default:
+			return tx.Where("organization_id = ?", orgID)
+		}
+	}
 }
 
 // ListAuditLogs retrieves audit logs with pagination and filters
@@ -60,54 +77,7 @@ func (db *DB) ListAuditLogs(page, pageSize int, filters map[string]interface{})
 	var logs []AuditLogEntry
 	var total int64
 	
-	tx := db.GormDB.Model(&AuditLog{})
-	
-	// Apply filters
-	if eventCategory, ok := filters["event_category"].(string); ok && eventCategory != "" {
-		tx = tx.Where("event_category = ?", eventCategory)
-	}
-	
-	if eventType, ok := filters["event_type"].(string); ok && eventType != "" {
-		tx = tx.Where("event_type = ?", eventType)
-	}
-	
-	if level, ok := filters["level"].(string); ok && level != "" {
-		tx = tx.Where("level = ?", level)
-	}
-	
-	if userID, ok := filters["user_id"].(int); ok && userID > 0 {
-		uid := uint(userID)
-		tx = tx.Where("user_id = ?", uid)
-	}
-	
-	if username, ok := filters["username"].(string); ok && username != "" {
-		tx = tx.Where("username LIKE ?", "%"+username+"%")
-	}
-	
-	if sourceIP, ok := filters["source_ip"].(string); ok && sourceIP != "" {
-		tx = tx.Where("source_ip = ?", sourceIP)
-	}
-	
-	if resource, ok := filters["resource"].(string); ok && resource != "" {
-		tx = tx.Where("resource LIKE ?", "%"+resource+"%")
-	}
-	
-	if action, ok := filters["action"].(string); ok && action != "" {
-		tx = tx.Where("action LIKE ?", "%"+action+"%")
-	}
-	
-	if success, ok := filters["success"].(bool); ok {
-		tx = tx.Where("success = ?", success)
-	}
-	
-	// Date range filters
-	if startDate, ok := filters["start_date"].(time.Time); ok && !startDate.IsZero() {
-		tx = tx.Where("datetime >= ?", startDate)
-	}
-	
-	if endDate, ok := filters["end_date"].(time.Time); ok && !endDate.IsZero() {
-		tx = tx.Where("datetime <= ?", endDate)
-	}
+	tx := db.GormDB.Model(&AuditLog{}).Scopes(auditFilters(filters))
 	
 	// Count total
 	tx.Count(&total)
@@ -122,14 +92,121 @@ func (db *DB) ListAuditLogs(page, pageSize int, filters map[string]interface{})
 	return logs, int(total), err
 }
 
-// GetAuditLogStats retrieves audit log statistics
-func (db *DB) GetAuditLogStats(startDate, endDate time.Time) (*AuditStats, error) {
+// auditFilters applies the filters of ListAuditLogs
+func auditFilters(filters map[string]interface{}) func(*gorm.DB) *gorm.DB {
+	return func(tx *gorm.DB) *gorm.DB {
+		if orgID, ok := filters["organization_id"].(uint); ok {
+			instanceEvents, _ := filters["instance_events"].(bool)
+			tx = tx.Scopes(AuditOrganizationScope(orgID, instanceEvents))
+		}
+
+		if eventCategory, ok := filters["event_category"].(string); ok && eventCategory != "" {
+			tx = tx.Where("event_category = ?", eventCategory)
+		}
+
+		if eventType, ok := filters["event_type"].(string); ok && eventType != "" {
+			tx = tx.Where("event_type = ?", eventType)
+		}
+
+		if level, ok := filters["level"].(string); ok && level != "" {
+			tx = tx.Where("level = ?", level)
+		}
+
+		if userID, ok := filters["user_id"].(int); ok && userID > 0 {
+			uid := uint(userID)
+			tx = tx.Where("user_id = ?", uid)
+		}
+
+		if username, ok := filters["username"].(string); ok && username != "" {
+			tx = tx.Where("username LIKE ?", "%"+username+"%")
+		}
+
+		if sourceIP, ok := filters["source_ip"].(string); ok && sourceIP != "" {
+			tx = tx.Where("source_ip = ?", sourceIP)
+		}
+
+		if resource, ok := filters["resource"].(string); ok && resource != "" {
+			tx = tx.Where("resource LIKE ?", "%"+resource+"%")
+		}
+
+		if action, ok := filters["action"].(string); ok && action != "" {
+			tx = tx.Where("action LIKE ?", "%"+action+"%")
+		}
+
+		if actor, ok := filters["actor"].(string); ok && actor != "" {
+			tx = tx.Where("(username LIKE ? OR email LIKE ?)", "%"+actor+"%", "%"+actor+"%")
+		}
+
+		if verb, ok := filters["verb"].(string); ok && verb != "" {
+			tx = tx.Where("action = ?", strings.ToLower(verb))
+		}
+
+		if resourceType, ok := filters["resource_type"].(string); ok && resourceType != "" {
+			tx = tx.Where("LOWER(resource) = ?", strings.ToLower(resourceType))
+		}
+
+		if resourceName, ok := filters["resource_name"].(string); ok && resourceName != "" {
+			tx = tx.Where("resource_name = ?", resourceName)
+		}
+
+		if clusterName, ok := filters["cluster_name"].(string); ok && clusterName != "" {
+			tx = tx.Where("cluster_name = ?", clusterName)
+		}
+
+		if namespace, ok := filters["namespace"].(string); ok && namespace != "" {
+			tx = tx.Where("namespace = ?", namespace)
+		}
+
+		if search, ok := filters["search"].(string); ok && search != "" {
+			tx = tx.Scopes(auditSearchScope(search))
+		}
+
+		if success, ok := filters["success"].(bool); ok {
+			tx = tx.Where("success = ?", success)
+		}
+
+		// Date range filters
+		if startDate, ok := filters["start_date"].(time.Time); ok && !startDate.IsZero() {
+			tx = tx.Where("datetime >= ?", startDate)
+		}
+
+		if endDate, ok := filters["end_date"].(time.Time); ok && !endDate.IsZero() {
+			tx = tx.Where("datetime <= ?", endDate)
+		}
+		return tx
+	}
+}
+
+// ListAuditLogsAfter returns up to limit audit logs with an ID above afterID, oldest first,
+// with the filters of ListAuditLogs
+func (db *DB) ListAuditLogsAfter(afterID uint, limit int, filters map[string]interface{}) ([]AuditLogEntry, error) {
+	var logs []AuditLogEntry
+	err := db.GormDB.Model(&AuditLog{}).
+		Scopes(auditFilters(filters)).
+		Where("id > ?", afterID).
+		Order("id ASC").
+		Limit(limit).
+		Find(&logs).Error
+	return logs, err
+}
+
+// LatestAuditLogID returns the ID of the newest audit log, 0 when there is none
+func (db *DB) LatestAuditLogID() (uint, error) {
+	var id uint
+	err := db.GormDB.Model(&AuditLog{}).Select("COALESCE(MAX(id), 0)").Scan(&id).Error
+	return id, err
+}
+
+// GetAuditLogStats retrieves audit log statistics, of an organization (see
+// AuditOrganizationScope)
+func (db *DB) GetAuditLogStats(startDate, endDate time.Time, orgID uint, instanceEvents bool) (*AuditStats, error) {
 	stats := &AuditStats{
 		EventsByCategory: make(map[string]int),
 		EventsByLevel:    make(map[string]int),
 	}
+	scope := AuditOrganizationScope(orgID, instanceEvents)
 	
-	tx := db.GormDB.Model(&AuditLog{})
+	tx := db.GormDB.Model(&AuditLog{}).Scopes(scope)
 	
 	if !startDate.IsZero() {
 		tx = tx.Where("datetime >= ?", startDate)
@@ -172,7 +249,7 @@ func (db *DB) GetAuditLogStats(startDate, endDate time.Time) (*AuditStats, error
 		Count         int64
 	}
 	var categoryCounts []CategoryCount
-	db.GormDB.Model(&AuditLog{}).
+	db.GormDB.Model(&AuditLog{}).Scopes(scope).
 		Select("event_category, COUNT(*) as count").
 		Where("datetime >= ? AND datetime <= ?", startDate, endDate).
 		Group("event_category").
@@ -188,7 +265,7 @@ func (db *DB) GetAuditLogStats(startDate, endDate time.Time) (*AuditStats, error
 		Count int64
 	}
 	var levelCounts []LevelCount
-	db.GormDB.Model(&AuditLog{}).
+	db.GormDB.Model(&AuditLog{}).Scopes(scope).
 		Select("level, COUNT(*) as count").
 		Where("datetime >= ? AND datetime <= ?", startDate, endDate).
 		Group("level").
@@ -205,7 +282,7 @@ func (db *DB) GetAuditLogStats(startDate, endDate time.Time) (*AuditStats, error
 		Count    int64
 	}
 	var userCounts []UserCount
-	db.GormDB.Model(&AuditLog{}).
+	db.GormDB.Model(&AuditLog{}).Scopes(scope).
 		Select("user_id, username, COUNT(*) as count").
 		Where("datetime >= ? AND datetime <= ? AND user_id IS NOT NULL", startDate, endDate).
 		Group("user_id, username").
@@ -229,7 +306,7 @@ func (db *DB) GetAuditLogStats(startDate, endDate time.Time) (*AuditStats, error
 		Count    int64
 	}
 	var ipCounts []IPCount
-	db.GormDB.Model(&AuditLog{}).
+	db.GormDB.Model(&AuditLog{}).Scopes(scope).
 		Select("source_ip, COUNT(*) as count").
 		Where("datetime >= ? AND datetime <= ?", startDate, endDate).
 		Group("source_ip").
@@ -245,7 +322,7 @@ func (db *DB) GetAuditLogStats(startDate, endDate time.Time) (*AuditStats, error
 	}
 	
 	// Recent critical logs
-	db.GormDB.Where("level = ? AND datetime >= ? AND datetime <= ?", "CRITICAL", startDate, endDate).
+	db.GormDB.Scopes(scope).Where("level = ? AND datetime >= ? AND datetime <= ?", "CRITICAL", startDate, endDate).
 		Order("datetime DESC").
 		Limit(10).
 		Find(&stats.RecentCritical)
@@ -530,3 +607,96 @@ func (db *DB) DeleteOldAuditLogs(before time.Time) (int, error) {
 	return int(deleted), err
 }
 
+
+// =============================================================================
+// Cluster Activity Report
+// =============================================================================
+
+// ClusterActivityReport summarizes all audited kubelens activity against one cluster
+type ClusterActivityReport struct {
+	ClusterName   string           `json:"cluster_name"`
+	TotalEvents   int              `json:"total_events"`
+	FailureCount  int              `json:"failure_count"`
+	FirstActivity *time.Time       `json:"first_activity,omitempty"`
+	LastActivity  *time.Time       `json:"last_activity,omitempty"`
+	EventsByType  map[string]int   `json:"events_by_type"`
+	TopUsers      []UserActivity   `json:"top_users"`
+	RecentEvents  []AuditLogEntry  `json:"recent_events"`
+}
+
+// clusterAuditScope restricts an audit log query to entries about a cluster, either
+// through the cluster_name metadata field or the request URI
+func (db *DB) clusterAuditScope(clusterName string) *gorm.DB {
+	quoted, _ := json.Marshal(clusterName)
+	metadataPattern := fmt.Sprintf("%%\"cluster_name\":%s%%", quoted)
+	uriPattern := fmt.Sprintf("/api/v1/clusters/%s/%%", clusterName)
+	return db.GormDB.Model(&AuditLog{}).
+		Where("metadata LIKE ? OR request_uri LIKE ? OR request_uri = ?", metadataPattern, uriPattern, "/api/v1/clusters/"+clusterName)
+}
+
+// GetClusterActivityReport builds an activity report for a cluster from the audit log
+func (db *DB) GetClusterActivityReport(clusterName string) (*ClusterActivityReport, error) {
+	report := &ClusterActivityReport{
+		ClusterName:  clusterName,
+		EventsByType: make(map[string]int),
+		TopUsers:     []UserActivity{},
+		RecentEvents: []AuditLogEntry{},
+	}
+
+	var total int64
+	if err := db.clusterAuditScope(clusterName).Count(&total).Error; err != nil {
+		return nil, err
+	}
+	report.TotalEvents = int(total)
+	if total == 0 {
+		return report, nil
+	}
+
+	var failures int64
+	if err := db.clusterAuditScope(clusterName).Where("success = ?", false).Count(&failures).Error; err != nil {
+		return nil, err
+	}
+	report.FailureCount = int(failures)
+
+	var first, last AuditLogEntry
+	if err := db.clusterAuditScope(clusterName).Order("datetime ASC").First(&first).Error; err == nil {
+		report.FirstActivity = &first.Datetime
+	}
+	if err := db.clusterAuditScope(clusterName).Order("datetime DESC").First(&last).Error; err == nil {
+		report.LastActivity = &last.Datetime
+	}
+
+	type TypeCount struct {
+		EventType string
+		Count     int64
+	}
+	var typeCounts []TypeCount
+	if err := db.clusterAuditScope(clusterName).
+		Select("event_type, COUNT(*) as count").
+		Group("event_type").
+		Scan(&typeCounts).Error; err != nil {
+		return nil, err
+	}
+	for _, tc := range typeCounts {
+		report.EventsByType[tc.EventType] = int(tc.Count)
+	}
+
+	if err := db.clusterAuditScope(clusterName).
+		Select("user_id, username, COUNT(*) as count").
+		Where("user_id IS NOT NULL").
+		Group("user_id, username").
+		Order("count DESC").
+		Limit(10).
+		Scan(&report.TopUsers).Error; err != nil {
+		return nil, err
+	}
+
+	if err := db.clusterAuditScope(clusterName).
+		Order("datetime DESC").
+		Limit(50).
+		Find(&report.RecentEvents).Error; err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
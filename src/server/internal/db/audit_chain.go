@@ -0,0 +1,128 @@
+package db
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AuditChainHead is the last entry of the audit log hash chain. It is a single row, locked
+// while an entry is appended so replicas extend the same chain.
+type AuditChainHead struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	EntryID   uint      `json:"entry_id"`
+	Hash      string    `gorm:"type:varchar(64)" json:"hash"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName overrides the table name
+func (AuditChainHead) TableName() string {
+	return "audit_chain_heads"
+}
+
+// AuditChainAnchor records the hash of the audit log chain at an entry, taken periodically
+// so rewriting the history after it can be detected
+type AuditChainAnchor struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	EntryID   uint      `gorm:"not null;index" json:"entry_id"`
+	Hash      string    `gorm:"type:varchar(64);not null" json:"hash"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName overrides the table name
+func (AuditChainAnchor) TableName() string {
+	return "audit_chain_anchors"
+}
+
+// auditChainHeadID is the ID of the only AuditChainHead row
+const auditChainHeadID = 1
+
+// auditChainMu serializes the appends of this process; SQLite has no row locks
+var auditChainMu sync.Mutex
+
+// ChainContent returns the content of an audit log covered by its chain hash: every field
+// but the ID, creation time and the hashes, with the time as stored. Columns filled after
+// an entry is recorded must not be added here, or older entries would no longer verify.
+func (l *AuditLog) ChainContent() []byte {
+	content, _ := json.Marshal([]interface{}{
+		l.Datetime.UTC().Format(time.RFC3339Nano), l.EventType, l.EventCategory, l.Level,
+		l.UserID, l.Username, l.Email, l.SourceIP, l.UserAgent,
+		l.Resource, l.Action, l.ClusterName, l.Namespace, l.ResourceName,
+		l.Description, l.Metadata, l.Success, l.ErrorMessage,
+		l.RequestMethod, l.RequestURI, l.ResponseCode, l.DurationMs,
+		l.SessionID, l.CorrelationID, l.GeoLocation, l.OrganizationID,
+	})
+	return content
+}
+
+// CreateChainedAuditLog appends an audit log to the hash chain: the entry stores the hash
+// of the previous one and its own, computed by hash from that and its content
+func (db *DB) CreateChainedAuditLog(entry *AuditLogEntry, hash func(prevHash string, entry *AuditLog) string) error {
+	if entry.Datetime.IsZero() {
+		entry.Datetime = time.Now().UTC()
+	}
+	// Every database keeps milliseconds; the hash covers the time as read back
+	entry.Datetime = entry.Datetime.UTC().Truncate(time.Millisecond)
+	entry.fillSearchFields()
+
+	auditChainMu.Lock()
+	defer auditChainMu.Unlock()
+	return db.GormDB.Transaction(func(tx *gorm.DB) error {
+		locked := tx
+		if tx.Dialector.Name() != "sqlite" {
+			locked = tx.Clauses(clause.Locking{Strength: "UPDATE"})
+		}
+		var head AuditChainHead
+		if err := locked.First(&head, auditChainHeadID).Error; err != nil {
+			return err
+		}
+		entry.PrevHash = head.Hash
+		entry.Hash = hash(head.Hash, entry)
+		// Create writes the column default for a false Success, which the hash does not cover
+		success := entry.Success
+		if err := tx.Create(entry).Error; err != nil {
+			return err
+		}
+		if !success {
+			entry.Success = false
+			if err := tx.Model(entry).Update("success", false).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(&head).Updates(map[string]interface{}{"entry_id": entry.ID, "hash": entry.Hash}).Error
+	})
+}
+
+// GetAuditChainHead returns the last entry of the audit log hash chain
+func (db *DB) GetAuditChainHead() (*AuditChainHead, error) {
+	var head AuditChainHead
+	err := db.GormDB.First(&head, auditChainHeadID).Error
+	return &head, err
+}
+
+// CreateAuditChainAnchor stores an anchor of the audit log hash chain
+func (db *DB) CreateAuditChainAnchor(anchor *AuditChainAnchor) error {
+	return db.GormDB.Create(anchor).Error
+}
+
+// LatestAuditChainAnchor returns the newest anchor of the audit log hash chain, nil when
+// there is none
+func (db *DB) LatestAuditChainAnchor() (*AuditChainAnchor, error) {
+	var anchor AuditChainAnchor
+	err := db.GormDB.Order("entry_id DESC").First(&anchor).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	return &anchor, err
+}
+
+// ListAuditChainAnchors lists the anchors of the audit log hash chain from an entry on,
+// oldest first
+func (db *DB) ListAuditChainAnchors(fromEntryID uint) ([]AuditChainAnchor, error) {
+	var anchors []AuditChainAnchor
+	err := db.GormDB.Where("entry_id >= ?", fromEntryID).Order("entry_id ASC").Find(&anchors).Error
+	return anchors, err
+}
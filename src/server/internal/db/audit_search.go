@@ -0,0 +1,140 @@
+package db
+
+import (
+	"encoding/json"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// auditVerbs maps request methods to the verb recorded as the action of audit logs
+var auditVerbs = map[string]string{
+	"GET":    "get",
+	"POST":   "create",
+	"PUT":    "update",
+	"PATCH":  "patch",
+	"DELETE": "delete",
+}
+
+// fillSearchFields sets the columns audit logs are searched by from the entry's metadata
+// (cluster_name or cluster, namespace, kind and name) and request method, unless set
+func (l *AuditLog) fillSearchFields() {
+	if l.Action == "" {
+		l.Action = auditVerbs[l.RequestMethod]
+	}
+	if l.Metadata == "" {
+		return
+	}
+	var metadata map[string]interface{}
+	if err := json.Unmarshal([]byte(l.Metadata), &metadata); err != nil {
+		return
+	}
+	field := func(keys ...string) string {
+		for _, key := range keys {
+			if value, ok := metadata[key].(string); ok && value != "" {
+				return value
+			}
+		}
+		return ""
+	}
+	if l.ClusterName == "" {
+		l.ClusterName = field("cluster_name", "cluster")
+	}
+	if l.Namespace == "" {
+		l.Namespace = field("namespace")
+	}
+	if l.Resource == "" {
+		l.Resource = field("kind")
+	}
+	if l.ResourceName == "" && l.Resource != "" {
+		l.ResourceName = field("name")
+	}
+}
+
+// searchTerms splits a free-text audit search into the words or "quoted phrases" entries
+// must contain and the -words they must not
+func searchTerms(search string) (include, exclude []string) {
+	for len(search) > 0 {
+		search = strings.TrimLeft(search, " \t")
+		if search == "" {
+			break
+		}
+		negated := strings.HasPrefix(search, "-")
+		if negated {
+			search = search[1:]
+		}
+		var term string
+		if strings.HasPrefix(search, `"`) {
+			end := strings.Index(search[1:], `"`)
+			if end < 0 {
+				term, search = search[1:], ""
+			} else {
+				term, search = search[1:end+1], search[end+2:]
+			}
+		} else if end := strings.IndexAny(search, " \t"); end >= 0 {
+			term, search = search[:end], search[end:]
+		} else {
+			term, search = search, ""
+		}
+		term = strings.TrimSpace(strings.ReplaceAll(term, `"`, ""))
+		switch {
+		case term == "":
+		case negated:
+			exclude = append(exclude, term)
+		default:
+			include = append(include, term)
+		}
+	}
+	return include, exclude
+}
+
+// ftsQuery returns the FTS5 query of a free-text audit search, "" when it has no words to
+// look for. Every term is quoted, so FTS5 operators in searches are taken as words.
+func ftsQuery(search string) string {
+	include, exclude := searchTerms(search)
+	if len(include) == 0 {
+		return ""
+	}
+	quote := func(term string) string {
+		return `"` + term + `"`
+	}
+	var query strings.Builder
+	for i, term := range include {
+		if i > 0 {
+			query.WriteString(" AND ")
+		}
+		query.WriteString(quote(term))
+	}
+	for _, term := range exclude {
+		query.WriteString(" NOT " + quote(term))
+	}
+	return query.String()
+}
+
+// auditSearchScope keeps the audit logs matching a free-text search over their
+// description, actor, resource, cluster and metadata: with the FTS5 index on SQLite, the
+// tsvector column on PostgreSQL (websearch syntax) and LIKE elsewhere
+func auditSearchScope(search string) func(*gorm.DB) *gorm.DB {
+	return func(tx *gorm.DB) *gorm.DB {
+		switch tx.Dialector.Name() {
+		case "sqlite":
+			query := ftsQuery(search)
+			if query == "" {
+				return tx
+			}
+			return tx.Where("id IN (SELECT rowid FROM audit_logs_fts WHERE audit_logs_fts MATCH ?)", query)
+		case "postgres":
+			return tx.Where("search_vector @@ websearch_to_tsquery('simple', ?)", search)
+		default:
+			include, exclude := searchTerms(search)
+			text := "CONCAT_WS(' ', description, username, email, resource, resource_name, cluster_name, metadata)"
+			for _, term := range include {
+				tx = tx.Where(text+" LIKE ?", "%"+term+"%")
+			}
+			for _, term := range exclude {
+				tx = tx.Where(text+" NOT LIKE ?", "%"+term+"%")
+			}
+			return tx
+		}
+	}
+}
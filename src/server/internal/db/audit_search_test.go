@@ -0,0 +1,70 @@
+package db
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAuditSearch(t *testing.T) {
+	database, err := New(filepath.Join(t.TempDir(), "kubelens.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+
+	for _, entry := range []AuditLog{
+		{EventType: "audit_resource_deleted", EventCategory: "audit", Level: "INFO", Username: "alice", Email: "alice@example.com",
+			RequestMethod: "DELETE", Description: "Deleted deployment: default/web",
+			Metadata: `{"cluster_name":"prod","namespace":"default","kind":"Deployment","name":"web"}`},
+		{EventType: "audit_resource_updated", EventCategory: "audit", Level: "INFO", Username: "bob", Email: "bob@example.com",
+			RequestMethod: "PUT", Description: "Updated configmap: kube-system/coredns",
+			Metadata: `{"cluster_name":"staging","namespace":"kube-system","kind":"ConfigMap","name":"coredns","changes":[{"path":".data.Corefile"}]}`},
+		{EventType: "authn_login_success", EventCategory: "authentication", Level: "INFO", Username: "alice", Description: "User logged in"},
+	} {
+		if err := database.CreateAuditLog(&entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, tc := range []struct {
+		filters map[string]interface{}
+		want    []string
+	}{
+		{map[string]interface{}{"actor": "alice"}, []string{"User logged in", "Deleted deployment: default/web"}},
+		{map[string]interface{}{"cluster_name": "prod", "verb": "DELETE"}, []string{"Deleted deployment: default/web"}},
+		{map[string]interface{}{"resource_type": "configmap", "resource_name": "coredns"}, []string{"Updated configmap: kube-system/coredns"}},
+		{map[string]interface{}{"search": "corefile"}, []string{"Updated configmap: kube-system/coredns"}},
+		{map[string]interface{}{"search": `"default/web"`}, []string{"Deleted deployment: default/web"}},
+		{map[string]interface{}{"search": "alice -logged"}, []string{"Deleted deployment: default/web"}},
+		{map[string]interface{}{"search": `NOT AND "`}, nil},
+	} {
+		logs, _, err := database.ListAuditLogs(1, 10, tc.filters)
+		if err != nil {
+			t.Errorf("ListAuditLogs(%v) = %v", tc.filters, err)
+			continue
+		}
+		var got []string
+		for _, entry := range logs {
+			got = append(got, entry.Description)
+		}
+		if len(got) != len(tc.want) {
+			t.Errorf("ListAuditLogs(%v) = %v, want %v", tc.filters, got, tc.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tc.want[i] {
+				t.Errorf("ListAuditLogs(%v) = %v, want %v", tc.filters, got, tc.want)
+				break
+			}
+		}
+	}
+
+	// Deleted entries leave the index
+	if _, err := database.DeleteAuditLogsBefore(time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if logs, _, err := database.ListAuditLogs(1, 10, map[string]interface{}{"search": "corefile"}); err != nil || len(logs) != 0 {
+		t.Errorf("search after deleting = %d entries, %v", len(logs), err)
+	}
+}
@@ -0,0 +1,319 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SnapshotFormatVersion is the version of the Snapshot layout
+const SnapshotFormatVersion = 1
+
+// Snapshot is a consistent copy of the kubelens configuration: organizations, users and
+// what they sign in with, groups, clusters, cluster groups, audit settings and feature flags.
+// Sessions, audit logs, notifications and cached cluster data are not part of it.
+type Snapshot struct {
+	FormatVersion int
+	SchemaVersion int
+	CreatedAt     time.Time
+	Dialect       string
+	// Credentials reports whether cluster credentials and the system settings (which
+	// hold the key encrypting LDAP, SMTP and other stored secrets) are included
+	Credentials bool
+
+	Users         []User
+	Groups        []Group
+	UserGroups    []UserGroup
+	MFASecrets    []MFASecret
+	APITokens     []APIToken
+	Clusters      []Cluster
+	ClusterGroups []ClusterGroup
+	AuditSettings []AuditSettings
+	FeatureFlags  []FeatureFlag
+	SystemConfigs []SystemConfig
+	// Organizations are missing from snapshots taken before schema version 2
+	Organizations       []Organization
+	OrganizationMembers []OrganizationMember
+}
+
+// ExportSnapshot reads a snapshot in a single transaction. Without credentials, the
+// token and auth config of every cluster are cleared and system settings are left out.
+func (db *GormDB) ExportSnapshot(includeCredentials bool) (*Snapshot, error) {
+	version, err := db.SchemaVersion()
+	if err != nil {
+		return nil, err
+	}
+	snapshot := &Snapshot{
+		FormatVersion: SnapshotFormatVersion,
+		SchemaVersion: version,
+		CreatedAt:     time.Now().UTC(),
+		Dialect:       db.dialect,
+		Credentials:   includeCredentials,
+	}
+
+	// SQLite transactions read a consistent snapshot already; the others need asking
+	var opts []*sql.TxOptions
+	if db.dialect != "sqlite" {
+		opts = append(opts, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		reads := []interface{}{
+			&snapshot.Users, &snapshot.Groups, &snapshot.UserGroups, &snapshot.MFASecrets,
+			&snapshot.APITokens, &snapshot.Clusters, &snapshot.ClusterGroups,
+			&snapshot.AuditSettings, &snapshot.FeatureFlags,
+			&snapshot.Organizations, &snapshot.OrganizationMembers,
+		}
+		if includeCredentials {
+			reads = append(reads, &snapshot.SystemConfigs)
+		}
+		for _, rows := range reads {
+			if err := tx.Order(clause.OrderByColumn{Column: clause.PrimaryColumn}).Find(rows).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+
+	if !includeCredentials {
+		for i := range snapshot.Clusters {
+			snapshot.Clusters[i].Token = ""
+			snapshot.Clusters[i].AuthConfig = JSON("{}")
+		}
+	}
+	return snapshot, nil
+}
+
+// RestoreSnapshot replaces the configuration with a snapshot, in a single transaction.
+// Everyone is signed out, as sessions belong to the replaced users. Clusters exported
+// without credentials are restored disabled until their credentials are entered again.
+// Audit logs and notifications are kept; those of users missing from the snapshot lose
+// the link to their user.
+func (db *GormDB) RestoreSnapshot(snapshot *Snapshot) error {
+	if snapshot.FormatVersion != SnapshotFormatVersion {
+		return fmt.Errorf("unsupported snapshot format %d", snapshot.FormatVersion)
+	}
+	current, err := db.SchemaVersion()
+	if err != nil {
+		return err
+	}
+	if snapshot.SchemaVersion > current {
+		return fmt.Errorf("snapshot schema version %d is newer than the database (%d): upgrade kubelens first", snapshot.SchemaVersion, current)
+	}
+	if !snapshot.Credentials {
+		for i := range snapshot.Clusters {
+			snapshot.Clusters[i].Enabled = false
+		}
+	}
+	if len(snapshot.Organizations) == 0 {
+		upgradeSnapshotOrganizations(snapshot)
+	}
+
+	userIDs := make([]uint, 0, len(snapshot.Users))
+	for _, user := range snapshot.Users {
+		userIDs = append(userIDs, user.ID)
+	}
+	var removedClusters []string
+	signedIn := db.sessionIDs("revoked_at IS NULL")
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		all := tx.Session(&gorm.Session{AllowGlobalUpdate: true})
+
+		// Rows tied to users are cleared before the users themselves
+		for _, model := range []interface{}{&Session{}, &UserSession{}, &RefreshToken{}, &UserGroup{}, &MFASecret{}, &APIToken{}} {
+			if err := all.Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("user_id NOT IN ?", append(userIDs, 0)).Delete(&Notification{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&AuditLog{}).Where("user_id NOT IN ?", append(userIDs, 0)).Update("user_id", nil).Error; err != nil {
+			return err
+		}
+
+		// Memberships go before the users, whose creation adds them to the default
+		// organization, and are replaced once the organizations are in place
+		if err := all.Delete(&OrganizationMember{}).Error; err != nil {
+			return err
+		}
+		if err := syncRows(tx, snapshot.Users); err != nil {
+			return fmt.Errorf("users: %w", err)
+		}
+		if err := syncRows(tx, snapshot.Organizations); err != nil {
+			return fmt.Errorf("organizations: %w", err)
+		}
+		if err := all.Delete(&OrganizationMember{}).Error; err != nil {
+			return err
+		}
+		if err := insertRows(tx, snapshot.OrganizationMembers); err != nil {
+			return fmt.Errorf("organization members: %w", err)
+		}
+		if err := syncRows(tx, snapshot.Groups); err != nil {
+			return fmt.Errorf("groups: %w", err)
+		}
+		if err := insertRows(tx, snapshot.UserGroups); err != nil {
+			return fmt.Errorf("user groups: %w", err)
+		}
+		if err := insertRows(tx, snapshot.MFASecrets); err != nil {
+			return fmt.Errorf("mfa secrets: %w", err)
+		}
+		if err := insertRows(tx, snapshot.APITokens); err != nil {
+			return fmt.Errorf("api tokens: %w", err)
+		}
+
+		restored := make(map[string]bool, len(snapshot.Clusters))
+		for _, cluster := range snapshot.Clusters {
+			restored[cluster.Name] = true
+		}
+		var names []string
+		if err := tx.Model(&Cluster{}).Pluck("name", &names).Error; err != nil {
+			return err
+		}
+		for _, name := range names {
+			if !restored[name] {
+				removedClusters = append(removedClusters, name)
+			}
+		}
+		if err := all.Delete(&Cluster{}).Error; err != nil {
+			return err
+		}
+		if err := insertRows(tx, snapshot.Clusters); err != nil {
+			return fmt.Errorf("clusters: %w", err)
+		}
+
+		replaced := []interface{}{&ClusterGroup{}, &AuditSettings{}, &FeatureFlag{}}
+		if snapshot.Credentials {
+			replaced = append(replaced, &SystemConfig{})
+		}
+		for _, model := range replaced {
+			if err := all.Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		if err := insertRows(tx, snapshot.ClusterGroups); err != nil {
+			return fmt.Errorf("cluster groups: %w", err)
+		}
+		if err := insertRows(tx, snapshot.AuditSettings); err != nil {
+			return fmt.Errorf("audit settings: %w", err)
+		}
+		if err := insertRows(tx, snapshot.FeatureFlags); err != nil {
+			return fmt.Errorf("feature flags: %w", err)
+		}
+		if snapshot.Credentials {
+			if err := insertRows(tx, snapshot.SystemConfigs); err != nil {
+				return fmt.Errorf("system settings: %w", err)
+			}
+		}
+
+		// Rows keep their IDs, so PostgreSQL sequences must move past them
+		if db.dialect == "postgres" {
+			for _, table := range []string{"organizations", "users", "groups", "mfa_secrets", "api_tokens", "clusters", "cluster_groups", "audit_settings", "feature_flags", "system_configs"} {
+				err := tx.Exec(fmt.Sprintf("SELECT setval(pg_get_serial_sequence('%s', 'id'), COALESCE((SELECT MAX(id) FROM %s), 0) + 1, false)", table, table)).Error
+				if err != nil {
+					return fmt.Errorf("failed to reset the %s sequence: %w", table, err)
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to restore snapshot: %w", err)
+	}
+
+	for _, name := range removedClusters {
+		deleteStoredCredentials(name)
+	}
+	evictSessions(signedIn...)
+	return nil
+}
+
+// upgradeSnapshotOrganizations puts everything of a snapshot taken before organizations
+// in the default organization, as the migration to them does
+func upgradeSnapshotOrganizations(snapshot *Snapshot) {
+	snapshot.Organizations = []Organization{{ID: DefaultOrganizationID, Name: "default", DisplayName: "Default"}}
+	snapshot.OrganizationMembers = nil
+	for _, user := range snapshot.Users {
+		role := OrgRoleMember
+		if user.IsAdmin {
+			role = OrgRoleOwner
+		}
+		snapshot.OrganizationMembers = append(snapshot.OrganizationMembers,
+			OrganizationMember{OrganizationID: DefaultOrganizationID, UserID: user.ID, Role: role})
+	}
+	for i := range snapshot.Groups {
+		snapshot.Groups[i].OrganizationID = DefaultOrganizationID
+	}
+	for i := range snapshot.APITokens {
+		snapshot.APITokens[i].OrganizationID = DefaultOrganizationID
+	}
+	for i := range snapshot.Clusters {
+		snapshot.Clusters[i].OrganizationID = DefaultOrganizationID
+	}
+}
+
+// syncRows deletes the rows missing from rows and inserts or updates the others, keeping
+// their IDs. Rows are updated in place so the audit log rows pointing to them stay valid.
+func syncRows[T any](tx *gorm.DB, rows []T) error {
+	var model T
+	ids := []interface{}{0}
+	for i := range rows {
+		ids = append(ids, reflect.ValueOf(&rows[i]).Elem().FieldByName("ID").Interface())
+	}
+	if err := tx.Where("id NOT IN ?", ids).Delete(&model).Error; err != nil {
+		return err
+	}
+	return writeRows(tx.Clauses(clause.OnConflict{UpdateAll: true}), rows)
+}
+
+// insertRows inserts rows as they are, keeping their IDs
+func insertRows[T any](tx *gorm.DB, rows []T) error {
+	return writeRows(tx, rows)
+}
+
+// writeRows inserts rows without their associations. GORM writes the column default
+// instead of a zero value (a disabled cluster would come back enabled), so columns with
+// a default are set again afterwards where the row holds the zero value.
+func writeRows[T any](tx *gorm.DB, rows []T) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	stmt := &gorm.Statement{DB: tx}
+	if err := stmt.Parse(&rows[0]); err != nil {
+		return err
+	}
+	zeroed := make([]map[string]interface{}, len(rows))
+	for i := range rows {
+		value := reflect.ValueOf(&rows[i]).Elem()
+		for _, field := range stmt.Schema.Fields {
+			if field.DBName == "" || field.DefaultValueInterface == nil {
+				continue
+			}
+			if _, isZero := field.ValueOf(tx.Statement.Context, value); isZero {
+				if zeroed[i] == nil {
+					zeroed[i] = map[string]interface{}{}
+				}
+				zeroed[i][field.DBName] = reflect.Zero(field.FieldType).Interface()
+			}
+		}
+	}
+
+	if err := tx.Omit(clause.Associations).CreateInBatches(rows, 100).Error; err != nil {
+		return err
+	}
+	for i, columns := range zeroed {
+		if columns == nil {
+			continue
+		}
+		if err := tx.Session(&gorm.Session{NewDB: true}).Model(&rows[i]).UpdateColumns(columns).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
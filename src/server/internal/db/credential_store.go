@@ -0,0 +1,144 @@
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// CredentialStore keeps cluster credentials outside the SQL database, such as in Vault
+type CredentialStore interface {
+	Get(path string) (map[string]string, error)
+	Put(path string, data map[string]string) error
+	Delete(path string) error
+}
+
+// credentialRefPrefix marks a token column holding a reference to the credential store
+const credentialRefPrefix = "credential-store:"
+
+// credentialRefKey is the auth_config key of a reference to the credential store
+const credentialRefKey = "credential_ref"
+
+var credentialStore CredentialStore
+
+// SetCredentialStore makes clusters saved from now on keep their token and auth_config
+// in store, leaving only a reference in the database. Clusters read back resolve the
+// reference, so the rest of kubelens sees the credentials as before.
+func SetCredentialStore(store CredentialStore) {
+	credentialStore = store
+}
+
+// storedCredentials are the credentials of a cluster while its row holds a reference
+type storedCredentials struct {
+	token      string
+	authConfig JSON
+}
+
+func clusterCredentialPath(name string) string {
+	return "clusters/" + name
+}
+
+// credentialRef returns the credential store path a cluster row refers to, if any
+func (c *Cluster) credentialRef() string {
+	if path, ok := strings.CutPrefix(c.Token, credentialRefPrefix); ok {
+		return path
+	}
+	var ref map[string]interface{}
+	if len(c.AuthConfig) > 0 && json.Unmarshal(c.AuthConfig, &ref) == nil && len(ref) == 1 {
+		path, _ := ref[credentialRefKey].(string)
+		return path
+	}
+	return ""
+}
+
+// hasCredentials reports whether a cluster carries anything worth keeping in the store
+func (c *Cluster) hasCredentials() bool {
+	config := strings.TrimSpace(string(c.AuthConfig))
+	return c.Token != "" || (config != "" && config != "{}" && config != "null")
+}
+
+// BeforeSave moves the credentials of a cluster to the credential store, if one is set
+func (c *Cluster) BeforeSave(tx *gorm.DB) error {
+	if credentialStore == nil || c.Name == "" || !c.hasCredentials() || c.credentialRef() != "" {
+		return nil
+	}
+	path := clusterCredentialPath(c.Name)
+	data := map[string]string{"token": c.Token, "auth_config": string(c.AuthConfig)}
+	// Saving a cluster without new credentials does not write a new secret version
+	if current, err := credentialStore.Get(path); err != nil || current["token"] != data["token"] || current["auth_config"] != data["auth_config"] {
+		if err := credentialStore.Put(path, data); err != nil {
+			return fmt.Errorf("failed to store credentials of cluster %s: %w", c.Name, err)
+		}
+	}
+	c.stored = &storedCredentials{token: c.Token, authConfig: c.AuthConfig}
+	c.Token = credentialRefPrefix + path
+	c.AuthConfig = JSON(fmt.Sprintf(`{%q:%q}`, credentialRefKey, path))
+	return nil
+}
+
+// AfterSave puts the credentials moved by BeforeSave back on the saved value
+func (c *Cluster) AfterSave(tx *gorm.DB) error {
+	if c.stored != nil {
+		c.Token, c.AuthConfig = c.stored.token, c.stored.authConfig
+		c.stored = nil
+	}
+	return nil
+}
+
+// AfterFind reads the credentials of a cluster row that refers to the credential store
+func (c *Cluster) AfterFind(tx *gorm.DB) error {
+	path := c.credentialRef()
+	if path == "" {
+		return nil
+	}
+	if credentialStore == nil {
+		return fmt.Errorf("credentials of cluster %s are kept in a credential store, but none is configured", c.Name)
+	}
+	data, err := credentialStore.Get(path)
+	if err != nil {
+		return fmt.Errorf("failed to read credentials of cluster %s: %w", c.Name, err)
+	}
+	c.Token = data["token"]
+	c.AuthConfig = JSON(data["auth_config"])
+	if len(c.AuthConfig) == 0 {
+		c.AuthConfig = JSON("{}")
+	}
+	return nil
+}
+
+// deleteStoredCredentials removes the credentials of a deleted cluster from the store
+func deleteStoredCredentials(name string) {
+	if credentialStore == nil {
+		return
+	}
+	if err := credentialStore.Delete(clusterCredentialPath(name)); err != nil {
+		log.Warnf("Failed to delete stored credentials of cluster %s: %v", name, err)
+	}
+}
+
+// MoveClusterCredentials moves the credentials still held in the database to the
+// credential store, returning how many clusters were moved
+func (db *GormDB) MoveClusterCredentials() (int, error) {
+	if credentialStore == nil {
+		return 0, nil
+	}
+	var clusters []*Cluster
+	// Rows are read without hooks: those already moved keep their reference
+	if err := db.Session(&gorm.Session{SkipHooks: true}).Find(&clusters).Error; err != nil {
+		return 0, err
+	}
+	moved := 0
+	for _, cluster := range clusters {
+		if cluster.credentialRef() != "" || !cluster.hasCredentials() {
+			continue
+		}
+		if err := db.Save(cluster).Error; err != nil {
+			return moved, err
+		}
+		moved++
+	}
+	return moved, nil
+}
@@ -0,0 +1,86 @@
+package db
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+type memoryCredentialStore map[string]map[string]string
+
+func (s memoryCredentialStore) Get(path string) (map[string]string, error) {
+	data, ok := s[path]
+	if !ok {
+		return nil, fmt.Errorf("%s not found", path)
+	}
+	return data, nil
+}
+
+func (s memoryCredentialStore) Put(path string, data map[string]string) error {
+	s[path] = data
+	return nil
+}
+
+func (s memoryCredentialStore) Delete(path string) error {
+	delete(s, path)
+	return nil
+}
+
+func TestCredentialStore(t *testing.T) {
+	db, err := NewGorm(filepath.Join(t.TempDir(), "kubelens.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// A cluster added before the store was configured
+	if err := db.Create(&Cluster{Name: "legacy", AuthType: "token", AuthConfig: JSON("{}"), Token: "legacy-token"}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	store := memoryCredentialStore{}
+	SetCredentialStore(store)
+	defer SetCredentialStore(nil)
+
+	cluster := &Cluster{Name: "prod", AuthType: "kubeconfig", AuthConfig: JSON(`{"kubeconfig":"secret"}`)}
+	if err := db.Create(cluster).Error; err != nil {
+		t.Fatal(err)
+	}
+	if string(cluster.AuthConfig) != `{"kubeconfig":"secret"}` {
+		t.Errorf("saved value auth_config = %s, want the credentials back", cluster.AuthConfig)
+	}
+	if moved, err := db.MoveClusterCredentials(); err != nil || moved != 1 {
+		t.Errorf("MoveClusterCredentials() = %d, %v; want the legacy cluster", moved, err)
+	}
+
+	// The rows only hold references
+	var rows []Cluster
+	db.Session(&gorm.Session{SkipHooks: true}).Order("name").Find(&rows)
+	for _, row := range rows {
+		if row.credentialRef() == "" || row.Token == "legacy-token" {
+			t.Errorf("row of %s holds token %q, auth_config %s", row.Name, row.Token, row.AuthConfig)
+		}
+	}
+
+	for name, want := range map[string]string{"prod": `{"kubeconfig":"secret"}`, "legacy": "{}"} {
+		got, err := db.GetCluster(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got.AuthConfig) != want {
+			t.Errorf("%s auth_config = %s, want %s", name, got.AuthConfig, want)
+		}
+	}
+	if legacy, _ := db.GetCluster("legacy"); legacy.Token != "legacy-token" {
+		t.Errorf("legacy token = %q", legacy.Token)
+	}
+
+	if err := db.DeleteCluster("prod"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := store["clusters/prod"]; ok {
+		t.Error("credentials of a deleted cluster are still stored")
+	}
+}
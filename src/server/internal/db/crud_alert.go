@@ -0,0 +1,163 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// =============================================================================
+// Alert Rule CRUD Operations
+// =============================================================================
+
+// CreateAlertRule creates a new alert rule
+func (db *GormDB) CreateAlertRule(rule *AlertRule) error {
+	return db.Create(rule).Error
+}
+
+// GetAlertRule retrieves an alert rule by ID
+func (db *GormDB) GetAlertRule(id uint) (*AlertRule, error) {
+	var rule AlertRule
+	err := db.First(&rule, id).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("alert rule not found with ID: %d", id)
+	}
+	return &rule, err
+}
+
+// ListAlertRules lists all alert rules
+func (db *GormDB) ListAlertRules() ([]*AlertRule, error) {
+	var rules []*AlertRule
+	err := db.Order("name").Find(&rules).Error
+	return rules, err
+}
+
+// ListEnabledAlertRules lists the alert rules to evaluate
+func (db *GormDB) ListEnabledAlertRules() ([]*AlertRule, error) {
+	var rules []*AlertRule
+	err := db.Where("enabled = ?", true).Order("id").Find(&rules).Error
+	return rules, err
+}
+
+// UpdateAlertRule updates an alert rule
+func (db *GormDB) UpdateAlertRule(rule *AlertRule) error {
+	return db.Save(rule).Error
+}
+
+// DeleteAlertRule deletes an alert rule and its alerts
+func (db *GormDB) DeleteAlertRule(id uint) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("rule_id = ?", id).Delete(&Alert{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&AlertRule{}, id).Error
+	})
+}
+
+// =============================================================================
+// Alert CRUD Operations
+// =============================================================================
+
+// ListActiveAlerts lists the pending and firing alerts of a rule
+func (db *GormDB) ListActiveAlerts(ruleID uint) ([]*Alert, error) {
+	var alerts []*Alert
+	err := db.Where("rule_id = ? AND state <> ?", ruleID, "resolved").Find(&alerts).Error
+	return alerts, err
+}
+
+// SaveAlert creates or updates an alert
+func (db *GormDB) SaveAlert(alert *Alert) error {
+	return db.Save(alert).Error
+}
+
+// DeleteAlert deletes an alert by ID
+func (db *GormDB) DeleteAlert(id uint) error {
+	return db.Delete(&Alert{}, id).Error
+}
+
+// ListAlerts lists alerts with filters and pagination, newest first
+func (db *GormDB) ListAlerts(filters AlertFilters) ([]*Alert, int64, error) {
+	var alerts []*Alert
+	var total int64
+
+	query := db.Model(&Alert{})
+	switch filters.State {
+	case "":
+	case "active":
+		query = query.Where("state <> ?", "resolved")
+	default:
+		query = query.Where("state = ?", filters.State)
+	}
+	if filters.Cluster != "" {
+		query = query.Where("cluster = ?", filters.Cluster)
+	}
+	if filters.RuleID != 0 {
+		query = query.Where("rule_id = ?", filters.RuleID)
+	}
+
+	if filters.Page < 1 {
+		filters.Page = 1
+	}
+	if filters.PageSize < 1 {
+		filters.PageSize = 50
+	}
+
+	// A scope of namespace patterns cannot be put in SQL: filter every match, then page
+	if filters.Visible != nil {
+		if err := query.Order("active_since DESC").Find(&alerts).Error; err != nil {
+			return nil, 0, err
+		}
+		visible := alerts[:0]
+		for _, alert := range alerts {
+			if filters.Visible(alert.Cluster, alert.Namespace) {
+				visible = append(visible, alert)
+			}
+		}
+		return pageOf(visible, filters.Page, filters.PageSize), int64(len(visible)), nil
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Order("active_since DESC").
+		Offset((filters.Page - 1) * filters.PageSize).
+		Limit(filters.PageSize).
+		Find(&alerts).Error
+	return alerts, total, err
+}
+
+// DeleteResolvedAlertsOlderThan deletes alerts resolved before the cutoff
+func (db *GormDB) DeleteResolvedAlertsOlderThan(cutoff time.Time) (int64, error) {
+	result := db.Where("state = ? AND resolved_at < ?", "resolved", cutoff).Delete(&Alert{})
+	return result.RowsAffected, result.Error
+}
+
+// ListActiveAdmins lists the enabled admin users
+func (db *GormDB) ListActiveAdmins() ([]*User, error) {
+	var users []*User
+	err := db.Where("is_admin = ? AND is_active = ?", true, true).Find(&users).Error
+	return users, err
+}
+
+// ResolveActiveAlerts resolves the pending and firing alerts of a rule, e.g. when it is
+// disabled
+func (db *GormDB) ResolveActiveAlerts(ruleID uint) error {
+	return db.Model(&Alert{}).
+		Where("rule_id = ? AND state <> ?", ruleID, "resolved").
+		Updates(map[string]interface{}{"state": "resolved", "resolved_at": time.Now()}).Error
+}
+
+// pageOf returns page (from 1) of items, pageSize items per page
+func pageOf[T any](items []T, page, pageSize int) []T {
+	start := (page - 1) * pageSize
+	if start >= len(items) {
+		return []T{}
+	}
+	end := start + pageSize
+	if end > len(items) {
+		end = len(items)
+	}
+	return items[start:end]
+}
@@ -0,0 +1,78 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// =============================================================================
+// API Token CRUD Operations
+// =============================================================================
+
+// CreateAPIToken creates a new API token
+func (db *GormDB) CreateAPIToken(token *APIToken) error {
+	return db.Create(token).Error
+}
+
+// GetAPITokenByID retrieves an API token by ID
+func (db *GormDB) GetAPITokenByID(id uint) (*APIToken, error) {
+	var token APIToken
+	err := db.First(&token, id).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("API token not found with ID: %d", id)
+	}
+	return &token, err
+}
+
+// GetActiveAPITokenByHash retrieves an unrevoked and unexpired API token
+func (db *GormDB) GetActiveAPITokenByHash(tokenHash string) (*APIToken, error) {
+	var token APIToken
+	err := db.Where("token_hash = ? AND revoked_at IS NULL", tokenHash).
+		Where("expires_at IS NULL OR expires_at > ?", time.Now()).
+		First(&token).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("API token not found, revoked or expired")
+	}
+	return &token, err
+}
+
+// ListAPITokensByUser lists the unrevoked API tokens of a user
+func (db *GormDB) ListAPITokensByUser(userID uint) ([]*APIToken, error) {
+	var tokens []*APIToken
+	err := db.Where("user_id = ? AND revoked_at IS NULL", userID).
+		Order("created_at DESC").
+		Find(&tokens).Error
+	return tokens, err
+}
+
+// ListAPITokens lists the unrevoked API tokens of all users in an organization
+func (db *GormDB) ListAPITokens(orgID uint) ([]*APIToken, error) {
+	var tokens []*APIToken
+	err := db.Where("organization_id = ? AND revoked_at IS NULL", orgID).
+		Order("created_at DESC").
+		Find(&tokens).Error
+	return tokens, err
+}
+
+// TouchAPIToken records that a token was just used
+func (db *GormDB) TouchAPIToken(id uint, ip string) error {
+	return db.Model(&APIToken{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{"last_used_at": time.Now(), "last_used_ip": ip}).Error
+}
+
+// RevokeAPIToken marks an API token as revoked
+func (db *GormDB) RevokeAPIToken(id uint) error {
+	result := db.Model(&APIToken{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("active API token not found with ID: %d", id)
+	}
+	return nil
+}
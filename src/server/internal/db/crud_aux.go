@@ -67,30 +67,30 @@ func (db *GormDB) GetNotification(id uint) (*Notification, error) {
 	return &notification, err
 }
 
-// GetUserNotifications retrieves all notifications for a user
-func (db *GormDB) GetUserNotifications(userID uint, limit int) ([]*Notification, error) {
+// GetUserNotifications retrieves all notifications for a user in an organization
+func (db *GormDB) GetUserNotifications(userID, orgID uint, limit int) ([]*Notification, error) {
 	var notifications []*Notification
-	err := db.Where("user_id = ?", userID).
+	err := db.Where("user_id = ? AND organization_id = ?", userID, orgID).
 		Order("created_at DESC").
 		Limit(limit).
 		Find(&notifications).Error
 	return notifications, err
 }
 
-// GetUnreadNotifications retrieves unread notifications for a user
-func (db *GormDB) GetUnreadNotifications(userID uint) ([]*Notification, error) {
+// GetUnreadNotifications retrieves unread notifications for a user in an organization
+func (db *GormDB) GetUnreadNotifications(userID, orgID uint) ([]*Notification, error) {
 	var notifications []*Notification
-	err := db.Where("user_id = ? AND is_read = ?", userID, false).
+	err := db.Where("user_id = ? AND organization_id = ? AND is_read = ?", userID, orgID, false).
 		Order("created_at DESC").
 		Find(&notifications).Error
 	return notifications, err
 }
 
-// CountUnreadNotifications counts unread notifications for a user
-func (db *GormDB) CountUnreadNotifications(userID uint) (int64, error) {
+// CountUnreadNotifications counts unread notifications for a user in an organization
+func (db *GormDB) CountUnreadNotifications(userID, orgID uint) (int64, error) {
 	var count int64
 	err := db.Model(&Notification{}).
-		Where("user_id = ? AND is_read = ?", userID, false).
+		Where("user_id = ? AND organization_id = ? AND is_read = ?", userID, orgID, false).
 		Count(&count).Error
 	return count, err
 }
@@ -102,10 +102,10 @@ func (db *GormDB) MarkNotificationAsRead(id uint) error {
 		Update("is_read", true).Error
 }
 
-// MarkAllNotificationsAsRead marks all notifications as read for a user
-func (db *GormDB) MarkAllNotificationsAsRead(userID uint) error {
+// MarkAllNotificationsAsRead marks all notifications as read for a user in an organization
+func (db *GormDB) MarkAllNotificationsAsRead(userID, orgID uint) error {
 	return db.Model(&Notification{}).
-		Where("user_id = ?", userID).
+		Where("user_id = ? AND organization_id = ?", userID, orgID).
 		Update("is_read", true).Error
 }
 
@@ -114,9 +114,9 @@ func (db *GormDB) DeleteNotification(id uint) error {
 	return db.Delete(&Notification{}, id).Error
 }
 
-// DeleteUserNotifications deletes all notifications for a user
-func (db *GormDB) DeleteUserNotifications(userID uint) error {
-	return db.Where("user_id = ?", userID).Delete(&Notification{}).Error
+// DeleteUserNotifications deletes all notifications for a user in an organization
+func (db *GormDB) DeleteUserNotifications(userID, orgID uint) error {
+	return db.Where("user_id = ? AND organization_id = ?", userID, orgID).Delete(&Notification{}).Error
 }
 
 // DeleteOldNotifications deletes notifications older than specified days
@@ -131,6 +131,7 @@ func (db *GormDB) DeleteOldNotifications(days int) error {
 
 // CreateAuditLog creates a new audit log entry
 func (db *GormDB) CreateAuditLog(auditLog *AuditLog) error {
+	auditLog.fillSearchFields()
 	return db.Create(auditLog).Error
 }
 
@@ -0,0 +1,48 @@
+package db
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// =============================================================================
+// Benchmark Run CRUD Operations
+// =============================================================================
+
+// CreateBenchmarkRun creates a new benchmark run
+func (db *GormDB) CreateBenchmarkRun(run *BenchmarkRun) error {
+	return db.Create(run).Error
+}
+
+// UpdateBenchmarkRun updates a benchmark run
+func (db *GormDB) UpdateBenchmarkRun(run *BenchmarkRun) error {
+	return db.Save(run).Error
+}
+
+// GetBenchmarkRun retrieves a benchmark run of a cluster by ID
+func (db *GormDB) GetBenchmarkRun(clusterName string, id uint) (*BenchmarkRun, error) {
+	var run BenchmarkRun
+	err := db.Where("cluster_name = ?", clusterName).First(&run, id).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("benchmark run not found with ID: %d", id)
+	}
+	return &run, err
+}
+
+// ListBenchmarkRuns lists the benchmark runs of a cluster without their results, newest
+// first
+func (db *GormDB) ListBenchmarkRuns(clusterName string, limit int) ([]*BenchmarkRun, error) {
+	var runs []*BenchmarkRun
+	err := db.Omit("results").
+		Where("cluster_name = ?", clusterName).
+		Order("started_at DESC").
+		Limit(limit).
+		Find(&runs).Error
+	return runs, err
+}
+
+// DeleteBenchmarkRun deletes a benchmark run by ID
+func (db *GormDB) DeleteBenchmarkRun(id uint) error {
+	return db.Delete(&BenchmarkRun{}, id).Error
+}
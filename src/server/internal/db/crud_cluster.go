@@ -15,6 +15,18 @@ func (db *GormDB) CreateCluster(cluster *Cluster) error {
 	return db.Create(cluster).Error
 }
 
+// CreateClusters creates several clusters in one transaction: all of them or none
+func (db *GormDB) CreateClusters(clusters []*Cluster) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, cluster := range clusters {
+			if err := tx.Create(cluster).Error; err != nil {
+				return fmt.Errorf("failed to create cluster %s: %w", cluster.Name, err)
+			}
+		}
+		return nil
+	})
+}
+
 // GetCluster retrieves a cluster by name
 func (db *GormDB) GetCluster(name string) (*Cluster, error) {
 	var cluster Cluster
@@ -77,7 +89,11 @@ func (db *GormDB) DisableCluster(name string) error {
 
 // DeleteCluster deletes a cluster by name
 func (db *GormDB) DeleteCluster(name string) error {
-	return db.Where("name = ?", name).Delete(&Cluster{}).Error
+	if err := db.Where("name = ?", name).Delete(&Cluster{}).Error; err != nil {
+		return err
+	}
+	deleteStoredCredentials(name)
+	return nil
 }
 
 // SetDefaultCluster sets a cluster as default (unsets others)
@@ -121,6 +137,11 @@ func (db *GormDB) UpdateClusterEnabled(id uint, enabled bool) error {
 	return db.Model(&Cluster{}).Where("id = ?", id).Update("enabled", enabled).Error
 }
 
+// UpdateClusterImpersonate turns impersonation mode on or off for a cluster
+func (db *GormDB) UpdateClusterImpersonate(id uint, impersonate bool) error {
+	return db.Model(&Cluster{}).Where("id = ?", id).Update("impersonate", impersonate).Error
+}
+
 // GetClusterMetadata retrieves cluster metadata
 func (db *GormDB) GetClusterMetadata(clusterName string) (*ClusterMetadata, error) {
 	var metadata ClusterMetadata
@@ -151,3 +172,46 @@ func (db *GormDB) DeleteClusterMetadata(clusterName string) error {
 	return db.Where("cluster_name = ?", clusterName).Delete(&ClusterMetadata{}).Error
 }
 
+
+// DeleteClusterData deletes a cluster together with its stored credentials and every
+// row kubelens keeps for it, returning the number of rows removed per table.
+// Audit logs are kept as the record of past activity.
+func (db *GormDB) DeleteClusterData(clusterName string) (map[string]int64, error) {
+	deleted := make(map[string]int64)
+	err := db.Transaction(func(tx *gorm.DB) error {
+		scoped := []struct {
+			table string
+			model interface{}
+		}{
+			{"cluster_metadata", &ClusterMetadata{}},
+			{"crash_reports", &CrashReport{}},
+			{"upgrade_plans", &UpgradePlan{}},
+			{"usage_rollups", &UsageRollup{}},
+			{"cluster_datasources", &ClusterDatasource{}},
+			{"cluster_events", &ClusterEvent{}},
+			{"benchmark_runs", &BenchmarkRun{}},
+			{"drain_jobs", &DrainJob{}},
+			{"log_archive_policies", &LogArchivePolicy{}},
+			{"favorites", &Favorite{}},
+			{"recent_views", &RecentView{}},
+		}
+		for _, s := range scoped {
+			result := tx.Where("cluster_name = ?", clusterName).Delete(s.model)
+			if result.Error != nil {
+				return fmt.Errorf("failed to delete %s: %w", s.table, result.Error)
+			}
+			deleted[s.table] = result.RowsAffected
+		}
+
+		result := tx.Where("name = ?", clusterName).Delete(&Cluster{})
+		if result.Error != nil {
+			return fmt.Errorf("failed to delete cluster: %w", result.Error)
+		}
+		deleted["clusters"] = result.RowsAffected
+		return nil
+	})
+	if err == nil {
+		deleteStoredCredentials(clusterName)
+	}
+	return deleted, err
+}
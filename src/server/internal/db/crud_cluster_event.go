@@ -0,0 +1,90 @@
+package db
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// =============================================================================
+// Cluster Event CRUD Operations
+// =============================================================================
+
+// RecordClusterEvent folds an event into the history. A new fingerprint is inserted; a
+// known one takes the count, UID and time of the event if it is newer than what is stored,
+// so replays after a re-list change nothing. It reports whether the event was new or
+// recurred, and when it had last been seen before.
+func (db *GormDB) RecordClusterEvent(event *ClusterEvent) (bool, time.Time, error) {
+	var changed bool
+	var previous time.Time
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var existing ClusterEvent
+		err := tx.Where("cluster_name = ? AND fingerprint = ?", event.ClusterName, event.Fingerprint).First(&existing).Error
+		if err == gorm.ErrRecordNotFound {
+			changed = true
+			return tx.Create(event).Error
+		}
+		if err != nil {
+			return err
+		}
+
+		sameObject := existing.UID == event.UID
+		if !event.LastSeen.After(existing.LastSeen) && !(sameObject && event.Count > existing.Count) {
+			*event = existing
+			return nil
+		}
+		changed = true
+		previous = existing.LastSeen
+
+		if sameObject {
+			existing.Count = event.Count
+		} else {
+			existing.Count += event.Count
+			existing.UID = event.UID
+		}
+		if event.LastSeen.After(existing.LastSeen) {
+			existing.LastSeen = event.LastSeen
+		}
+		existing.Source = event.Source
+		*event = existing
+		return tx.Save(event).Error
+	})
+	return changed, previous, err
+}
+
+// ListClusterEvents lists recorded events of a cluster, most recent first
+func (db *GormDB) ListClusterEvents(filters ClusterEventFilters) ([]*ClusterEvent, error) {
+	var events []*ClusterEvent
+
+	query := db.Where("cluster_name = ?", filters.ClusterName)
+	if filters.Namespace != "" {
+		query = query.Where("namespace = ?", filters.Namespace)
+	}
+	if filters.Type != "" {
+		query = query.Where("type = ?", filters.Type)
+	}
+	if filters.Kind != "" {
+		query = query.Where("kind = ?", filters.Kind)
+	}
+	if filters.Name != "" {
+		query = query.Where("name = ?", filters.Name)
+	}
+	if len(filters.Reasons) > 0 {
+		query = query.Where("reason IN ?", filters.Reasons)
+	}
+	if !filters.Since.IsZero() {
+		query = query.Where("last_seen >= ?", filters.Since)
+	}
+	if filters.Limit < 1 {
+		filters.Limit = 200
+	}
+
+	err := query.Order("last_seen DESC").Limit(filters.Limit).Find(&events).Error
+	return events, err
+}
+
+// DeleteClusterEventsOlderThan deletes events last seen before the cutoff
+func (db *GormDB) DeleteClusterEventsOlderThan(cutoff time.Time) (int64, error) {
+	result := db.Where("last_seen < ?", cutoff).Delete(&ClusterEvent{})
+	return result.RowsAffected, result.Error
+}
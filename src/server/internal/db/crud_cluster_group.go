@@ -0,0 +1,143 @@
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// =============================================================================
+// Cluster Group CRUD Operations
+// =============================================================================
+
+// CreateClusterGroup stores a new cluster group
+func (db *GormDB) CreateClusterGroup(group *ClusterGroup) error {
+	return db.Create(group).Error
+}
+
+// GetClusterGroup retrieves a cluster group by name
+func (db *GormDB) GetClusterGroup(name string) (*ClusterGroup, error) {
+	var group ClusterGroup
+	err := db.Where("name = ?", name).First(&group).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("cluster group not found: %s", name)
+	}
+	return &group, err
+}
+
+// ListClusterGroups lists all cluster groups by name
+func (db *GormDB) ListClusterGroups() ([]*ClusterGroup, error) {
+	var groups []*ClusterGroup
+	err := db.Order("name ASC").Find(&groups).Error
+	return groups, err
+}
+
+// UpdateClusterGroup saves a cluster group
+func (db *GormDB) UpdateClusterGroup(group *ClusterGroup) error {
+	return db.Save(group).Error
+}
+
+// DeleteClusterGroup deletes a cluster group by name
+func (db *GormDB) DeleteClusterGroup(name string) error {
+	return db.Where("name = ?", name).Delete(&ClusterGroup{}).Error
+}
+
+// UpdateClusterLabels replaces the labels of a cluster
+func (db *GormDB) UpdateClusterLabels(name string, clusterLabels map[string]string) error {
+	data, err := json.Marshal(clusterLabels)
+	if err != nil {
+		return err
+	}
+	return db.Model(&Cluster{}).Where("name = ?", name).Update("labels", JSON(data)).Error
+}
+
+// ClusterGroupMembers returns the names of the clusters in a group, sorted: the listed
+// clusters that exist and the clusters whose labels match the group's selector
+func (db *GormDB) ClusterGroupMembers(group *ClusterGroup) ([]string, error) {
+	clusters, err := db.ListClusters()
+	if err != nil {
+		return nil, err
+	}
+	return groupMembers(group, clusters)
+}
+
+// ExpandClusterGroups replaces the group:<name> cluster patterns of permissions with the
+// names of the groups' members. A group without members, or one that does not exist,
+// stays as it is and matches no cluster.
+func (db *GormDB) ExpandClusterGroups(permissions []Permission) ([]Permission, error) {
+	referenced := false
+	for _, perm := range permissions {
+		for _, pattern := range perm.Clusters {
+			if strings.HasPrefix(pattern, ClusterGroupPrefix) {
+				referenced = true
+			}
+		}
+	}
+	if !referenced {
+		return permissions, nil
+	}
+
+	groups, err := db.ListClusterGroups()
+	if err != nil {
+		return nil, err
+	}
+	clusters, err := db.ListClusters()
+	if err != nil {
+		return nil, err
+	}
+	members := map[string][]string{}
+	for _, group := range groups {
+		names, err := groupMembers(group, clusters)
+		if err != nil {
+			// An invalid selector selects nothing
+			continue
+		}
+		members[group.Name] = names
+	}
+
+	expanded := make([]Permission, len(permissions))
+	for i, perm := range permissions {
+		expanded[i] = perm
+		if len(perm.Clusters) == 0 {
+			continue
+		}
+		patterns := make([]string, 0, len(perm.Clusters))
+		for _, pattern := range perm.Clusters {
+			name, isGroup := strings.CutPrefix(pattern, ClusterGroupPrefix)
+			if isGroup && len(members[name]) > 0 {
+				patterns = append(patterns, members[name]...)
+			} else {
+				patterns = append(patterns, pattern)
+			}
+		}
+		expanded[i].Clusters = patterns
+	}
+	return expanded, nil
+}
+
+func groupMembers(group *ClusterGroup, clusters []*Cluster) ([]string, error) {
+	selector := labels.Nothing()
+	if group.Selector != "" {
+		var err error
+		if selector, err = labels.Parse(group.Selector); err != nil {
+			return nil, fmt.Errorf("invalid selector of cluster group %s: %w", group.Name, err)
+		}
+	}
+	listed := map[string]bool{}
+	for _, name := range group.ListedClusters() {
+		listed[name] = true
+	}
+
+	names := []string{}
+	for _, cluster := range clusters {
+		if listed[cluster.Name] || selector.Matches(labels.Set(cluster.LabelSet())) {
+			names = append(names, cluster.Name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
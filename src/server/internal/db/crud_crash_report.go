@@ -0,0 +1,143 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// =============================================================================
+// Crash Report CRUD Operations
+// =============================================================================
+
+// CreateCrashReport stores a new crash report
+func (db *GormDB) CreateCrashReport(report *CrashReport) error {
+	return db.Create(report).Error
+}
+
+// GetCrashReport retrieves a crash report by ID
+func (db *GormDB) GetCrashReport(id uint) (*CrashReport, error) {
+	var report CrashReport
+	err := db.First(&report, id).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("crash report not found with ID: %d", id)
+	}
+	return &report, err
+}
+
+// CrashReportExists checks whether a specific container restart was already captured
+func (db *GormDB) CrashReportExists(clusterName, podUID, containerName string, restartCount int32) (bool, error) {
+	var count int64
+	err := db.Model(&CrashReport{}).
+		Where("cluster_name = ? AND pod_uid = ? AND container_name = ? AND restart_count = ?",
+			clusterName, podUID, containerName, restartCount).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// ListCrashReports lists crash reports (without logs) matching the filters, newest first
+func (db *GormDB) ListCrashReports(filters CrashReportFilters) ([]*CrashReport, int64, error) {
+	var reports []*CrashReport
+	var total int64
+
+	query := db.Model(&CrashReport{})
+	if filters.ClusterName != "" {
+		query = query.Where("cluster_name = ?", filters.ClusterName)
+	}
+	if filters.Namespace != "" {
+		query = query.Where("namespace = ?", filters.Namespace)
+	}
+	if filters.PodName != "" {
+		query = query.Where("pod_name = ?", filters.PodName)
+	}
+
+	if filters.Page < 1 {
+		filters.Page = 1
+	}
+	if filters.PageSize < 1 {
+		filters.PageSize = 50
+	}
+
+	// A scope of namespace patterns cannot be put in SQL: filter every match, then page
+	if filters.Visible != nil {
+		if err := query.Omit("logs").Order("created_at DESC").Find(&reports).Error; err != nil {
+			return nil, 0, err
+		}
+		visible := reports[:0]
+		for _, report := range reports {
+			if filters.Visible(report.ClusterName, report.Namespace) {
+				visible = append(visible, report)
+			}
+		}
+		return pageOf(visible, filters.Page, filters.PageSize), int64(len(visible)), nil
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Omit("logs").
+		Order("created_at DESC").
+		Offset((filters.Page - 1) * filters.PageSize).
+		Limit(filters.PageSize).
+		Find(&reports).Error
+	return reports, total, err
+}
+
+// DeleteCrashReport deletes a crash report by ID
+func (db *GormDB) DeleteCrashReport(id uint) error {
+	return db.Delete(&CrashReport{}, id).Error
+}
+
+// DeleteCrashReportsOlderThan deletes reports created before the cutoff
+func (db *GormDB) DeleteCrashReportsOlderThan(cutoff time.Time) (int64, error) {
+	result := db.Where("created_at < ?", cutoff).Delete(&CrashReport{})
+	return result.RowsAffected, result.Error
+}
+
+// TrimCrashReports keeps only the newest maxPerContainer reports for each
+// cluster/namespace/pod/container combination
+func (db *GormDB) TrimCrashReports(maxPerContainer int) (int64, error) {
+	type group struct {
+		ClusterName   string
+		Namespace     string
+		PodName       string
+		ContainerName string
+	}
+
+	var groups []group
+	err := db.Model(&CrashReport{}).
+		Select("cluster_name, namespace, pod_name, container_name").
+		Group("cluster_name, namespace, pod_name, container_name").
+		Having("COUNT(*) > ?", maxPerContainer).
+		Scan(&groups).Error
+	if err != nil {
+		return 0, err
+	}
+
+	var deleted int64
+	for _, g := range groups {
+		var keepIDs []uint
+		err := db.Model(&CrashReport{}).
+			Where("cluster_name = ? AND namespace = ? AND pod_name = ? AND container_name = ?",
+				g.ClusterName, g.Namespace, g.PodName, g.ContainerName).
+			Order("created_at DESC").
+			Limit(maxPerContainer).
+			Pluck("id", &keepIDs).Error
+		if err != nil {
+			return deleted, err
+		}
+
+		result := db.Where("cluster_name = ? AND namespace = ? AND pod_name = ? AND container_name = ?",
+			g.ClusterName, g.Namespace, g.PodName, g.ContainerName).
+			Where("id NOT IN ?", keepIDs).
+			Delete(&CrashReport{})
+		if result.Error != nil {
+			return deleted, result.Error
+		}
+		deleted += result.RowsAffected
+	}
+
+	return deleted, nil
+}
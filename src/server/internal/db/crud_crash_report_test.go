@@ -0,0 +1,41 @@
+package db
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+func TestListCrashReportsVisible(t *testing.T) {
+	db, err := NewGorm(filepath.Join(t.TempDir(), "kubelens.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	for i := 0; i < 6; i++ {
+		namespace := "team-a"
+		if i%2 == 1 {
+			namespace = "team-b"
+		}
+		report := &CrashReport{ClusterName: "prod", Namespace: namespace, PodName: fmt.Sprintf("pod-%d", i), ContainerName: "app"}
+		if err := db.CreateCrashReport(report); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Reports outside the scope are dropped before counting and paging
+	visible := func(cluster, namespace string) bool { return namespace == "team-a" }
+	reports, total, err := db.ListCrashReports(CrashReportFilters{Visible: visible, Page: 2, PageSize: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 3 || len(reports) != 1 || reports[0].Namespace != "team-a" {
+		t.Errorf("page 2 = %d reports of %d, want the last of the 3 in team-a", len(reports), total)
+	}
+
+	reports, total, err = db.ListCrashReports(CrashReportFilters{Visible: visible, Page: 3, PageSize: 2})
+	if err != nil || total != 3 || len(reports) != 0 {
+		t.Errorf("page 3 = %d reports of %d (%v), want none of 3", len(reports), total, err)
+	}
+}
@@ -0,0 +1,38 @@
+package db
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// =============================================================================
+// Cluster Datasource CRUD Operations
+// =============================================================================
+
+// GetClusterDatasource retrieves the datasource of a type configured for a cluster
+func (db *GormDB) GetClusterDatasource(clusterName, datasourceType string) (*ClusterDatasource, error) {
+	var ds ClusterDatasource
+	err := db.Where("cluster_name = ? AND type = ?", clusterName, datasourceType).First(&ds).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("no %s datasource configured for cluster %s", datasourceType, clusterName)
+	}
+	return &ds, err
+}
+
+// ListClusterDatasources lists the datasources configured for a cluster
+func (db *GormDB) ListClusterDatasources(clusterName string) ([]*ClusterDatasource, error) {
+	var datasources []*ClusterDatasource
+	err := db.Where("cluster_name = ?", clusterName).Order("type").Find(&datasources).Error
+	return datasources, err
+}
+
+// SaveClusterDatasource creates or updates a datasource
+func (db *GormDB) SaveClusterDatasource(ds *ClusterDatasource) error {
+	return db.Save(ds).Error
+}
+
+// DeleteClusterDatasource deletes the datasource of a type configured for a cluster
+func (db *GormDB) DeleteClusterDatasource(clusterName, datasourceType string) error {
+	return db.Where("cluster_name = ? AND type = ?", clusterName, datasourceType).Delete(&ClusterDatasource{}).Error
+}
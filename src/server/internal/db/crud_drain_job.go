@@ -0,0 +1,112 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// =============================================================================
+// Drain Job CRUD Operations
+// =============================================================================
+
+// CreateDrainJob stores a new drain job
+func (db *GormDB) CreateDrainJob(job *DrainJob) error {
+	return db.Create(job).Error
+}
+
+// GetDrainJob retrieves a drain job by ID
+func (db *GormDB) GetDrainJob(id uint) (*DrainJob, error) {
+	var job DrainJob
+	err := db.First(&job, id).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("drain job not found with ID: %d", id)
+	}
+	return &job, err
+}
+
+// ListDrainJobs lists drain jobs for a cluster, newest first
+func (db *GormDB) ListDrainJobs(clusterName string) ([]*DrainJob, error) {
+	var jobs []*DrainJob
+	err := db.Where("cluster_name = ?", clusterName).
+		Order("created_at DESC").
+		Find(&jobs).Error
+	return jobs, err
+}
+
+// ListDueDrainJobs lists scheduled drain jobs whose window has started
+func (db *GormDB) ListDueDrainJobs(now time.Time) ([]*DrainJob, error) {
+	var jobs []*DrainJob
+	err := db.Where("status = ? AND scheduled_at <= ?", "scheduled", now).
+		Order("scheduled_at").
+		Find(&jobs).Error
+	return jobs, err
+}
+
+// ClaimDrainJob makes owner the server running a scheduled or paused drain job, saving
+// its new window end. It reports false when the job is in another state, e.g. because
+// another server claimed it first.
+func (db *GormDB) ClaimDrainJob(job *DrainJob, owner string, now time.Time) (bool, error) {
+	startedAt := job.StartedAt
+	if startedAt == nil {
+		startedAt = &now
+	}
+	claimed, err := db.TransitionDrainJob(job.ID, []string{"scheduled", "paused"}, map[string]interface{}{
+		"status":       "running",
+		"owner":        owner,
+		"heartbeat_at": now,
+		"stop_request": "",
+		"error":        "",
+		"started_at":   startedAt,
+		"window_end":   job.WindowEnd,
+	})
+	if !claimed || err != nil {
+		return false, err
+	}
+	job.Status, job.Owner, job.HeartbeatAt, job.StopRequest, job.Error, job.StartedAt = "running", owner, &now, "", "", startedAt
+	return true, nil
+}
+
+// TransitionDrainJob updates columns of a drain job if its status is one of from. It
+// reports false when it is not.
+func (db *GormDB) TransitionDrainJob(id uint, from []string, updates map[string]interface{}) (bool, error) {
+	result := db.Model(&DrainJob{}).
+		Where("id = ? AND status IN ?", id, from).
+		Updates(updates)
+	return result.RowsAffected == 1, result.Error
+}
+
+// SaveDrainJob saves a drain job run by owner, leaving its lease and stop request alone.
+// It reports false when owner no longer runs the job.
+func (db *GormDB) SaveDrainJob(job *DrainJob, owner string) (bool, error) {
+	result := db.Model(job).
+		Select("*").
+		Omit("stop_request", "heartbeat_at", "created_at").
+		Where("owner = ?", owner).
+		Updates(job)
+	return result.RowsAffected == 1, result.Error
+}
+
+// HeartbeatDrainJob renews the lease of owner on a running drain job and returns the stop
+// requested of it, if any. It reports false when owner no longer runs the job.
+func (db *GormDB) HeartbeatDrainJob(id uint, owner string, now time.Time) (string, bool, error) {
+	result := db.Model(&DrainJob{}).
+		Where("id = ? AND owner = ? AND status = ?", id, owner, "running").
+		UpdateColumn("heartbeat_at", now)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return "", false, result.Error
+	}
+	var job DrainJob
+	err := db.Select("stop_request").First(&job, id).Error
+	return job.StopRequest, err == nil, err
+}
+
+// PauseStaleDrainJobs marks running drain jobs whose owner last renewed its lease before
+// cutoff as paused so they can be resumed explicitly. The server running them stopped.
+func (db *GormDB) PauseStaleDrainJobs(cutoff time.Time) (int64, error) {
+	result := db.Model(&DrainJob{}).
+		Where("status = ? AND (heartbeat_at IS NULL OR heartbeat_at < ?)", "running", cutoff).
+		Updates(map[string]interface{}{"status": "paused", "owner": "", "error": "interrupted: the server running it stopped"})
+	return result.RowsAffected, result.Error
+}
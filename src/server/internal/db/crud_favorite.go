@@ -0,0 +1,115 @@
+package db
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// objectKey identifies an object of a cluster by a SHA-256 of its cluster, group, kind or
+// resource, namespace and name. Favorites and recent views are unique per user and key:
+// an index over the columns themselves exceeds MySQL's key length under utf8mb4.
+func objectKey(clusterName, group, kind, namespace, name string) string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{clusterName, group, kind, namespace, name}, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+// BeforeSave keys the favorite by the object it is of
+func (f *Favorite) BeforeSave(tx *gorm.DB) error {
+	f.ObjectKey = objectKey(f.ClusterName, f.Group, f.Kind, f.Namespace, f.Name)
+	return nil
+}
+
+// =============================================================================
+// Favorite CRUD Operations
+// =============================================================================
+
+// CreateFavorite stores a new favorite
+func (db *GormDB) CreateFavorite(favorite *Favorite) error {
+	return db.Create(favorite).Error
+}
+
+// GetFavorite retrieves a favorite by ID
+func (db *GormDB) GetFavorite(id uint) (*Favorite, error) {
+	var favorite Favorite
+	err := db.First(&favorite, id).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("favorite not found: %d", id)
+	}
+	return &favorite, err
+}
+
+// ListFavorites lists the favorites of a user in an organization (all of them for 0), by
+// position then by when they were added
+func (db *GormDB) ListFavorites(userID, orgID uint) ([]*Favorite, error) {
+	favorites := []*Favorite{}
+	tx := db.Where("user_id = ?", userID)
+	if orgID != 0 {
+		tx = tx.Where("organization_id = ?", orgID)
+	}
+	err := tx.Order("position ASC, id ASC").Find(&favorites).Error
+	return favorites, err
+}
+
+// CountFavorites counts the favorites of a user across organizations
+func (db *GormDB) CountFavorites(userID uint) (int64, error) {
+	var count int64
+	err := db.Model(&Favorite{}).Where("user_id = ?", userID).Count(&count).Error
+	return count, err
+}
+
+// FindFavorite returns a user's favorite of an object, nil when the object is not one
+func (db *GormDB) FindFavorite(userID uint, clusterName, group, kind, namespace, name string) (*Favorite, error) {
+	var favorite Favorite
+	err := db.Where("user_id = ? AND object_key = ?",
+		userID, objectKey(clusterName, group, kind, namespace, name)).First(&favorite).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &favorite, nil
+}
+
+// UpdateFavorite saves a favorite
+func (db *GormDB) UpdateFavorite(favorite *Favorite) error {
+	return db.Save(favorite).Error
+}
+
+// MoveFavorite moves a favorite to a position among the favorites of its user and
+// organization, renumbering them from 0
+func (db *GormDB) MoveFavorite(favorite *Favorite, position int) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var others []*Favorite
+		q := tx.Where("user_id = ? AND id <> ?", favorite.UserID, favorite.ID)
+		if favorite.OrganizationID != nil {
+			q = q.Where("organization_id = ?", *favorite.OrganizationID)
+		}
+		if err := q.Order("position ASC, id ASC").Find(&others).Error; err != nil {
+			return err
+		}
+		if position > len(others) {
+			position = len(others)
+		}
+		ordered := append(append(others[:position:position], favorite), others[position:]...)
+		for i, f := range ordered {
+			if f.Position == i {
+				continue
+			}
+			f.Position = i
+			if err := tx.Model(&Favorite{}).Where("id = ?", f.ID).Update("position", i).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// DeleteFavorite deletes a favorite by ID
+func (db *GormDB) DeleteFavorite(id uint) error {
+	return db.Delete(&Favorite{}, id).Error
+}
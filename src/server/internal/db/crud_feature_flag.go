@@ -0,0 +1,94 @@
+package db
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// =============================================================================
+// Feature Flag CRUD Operations
+// =============================================================================
+
+// ListFeatureFlags returns all feature flags ordered by key
+func (db *GormDB) ListFeatureFlags() ([]*FeatureFlag, error) {
+	var flags []*FeatureFlag
+	err := db.Order(clause.OrderByColumn{Column: clause.Column{Name: "key"}}).Find(&flags).Error
+	return flags, err
+}
+
+// GetFeatureFlag retrieves a feature flag by key
+func (db *GormDB) GetFeatureFlag(key string) (*FeatureFlag, error) {
+	var flag FeatureFlag
+	err := db.Where(&FeatureFlag{Key: key}).First(&flag).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("feature flag not found: %s", key)
+	}
+	return &flag, err
+}
+
+// UpsertFeatureFlag creates or updates a feature flag by key
+func (db *GormDB) UpsertFeatureFlag(flag *FeatureFlag) error {
+	existing, err := db.GetFeatureFlag(flag.Key)
+	if err != nil {
+		return db.Create(flag).Error
+	}
+	flag.ID = existing.ID
+	flag.CreatedAt = existing.CreatedAt
+	return db.Save(flag).Error
+}
+
+// DeleteFeatureFlag deletes a feature flag by key
+func (db *GormDB) DeleteFeatureFlag(key string) error {
+	return db.Where(&FeatureFlag{Key: key}).Delete(&FeatureFlag{}).Error
+}
+
+// IsFeatureEnabledForUser evaluates a flag for a user, honouring group targeting.
+// Unknown flags are treated as disabled.
+func (db *GormDB) IsFeatureEnabledForUser(key string, userID uint) (bool, error) {
+	flag, err := db.GetFeatureFlag(key)
+	if err != nil {
+		return false, nil
+	}
+	if !flag.Enabled {
+		return false, nil
+	}
+
+	groups, err := db.GetUserGroups(userID)
+	if err != nil {
+		return false, err
+	}
+	return flag.IsEnabledFor(groupIDsOf(groups)), nil
+}
+
+// GetEnabledFeaturesForUser returns the keys of all flags enabled for a user
+func (db *GormDB) GetEnabledFeaturesForUser(userID uint) ([]string, error) {
+	flags, err := db.ListFeatureFlags()
+	if err != nil {
+		return nil, err
+	}
+
+	groups, err := db.GetUserGroups(userID)
+	if err != nil {
+		return nil, err
+	}
+	groupIDs := groupIDsOf(groups)
+
+	enabled := []string{}
+	for _, flag := range flags {
+		if flag.IsEnabledFor(groupIDs) {
+			enabled = append(enabled, flag.Key)
+		}
+	}
+	return enabled, nil
+}
+
+// groupIDsOf extracts IDs from a list of groups
+func groupIDsOf(groups []Group) []uint {
+	ids := make([]uint, 0, len(groups))
+	for _, g := range groups {
+		ids = append(ids, g.ID)
+	}
+	return ids
+}
@@ -48,6 +48,33 @@ func (db *GormDB) GetGroupByName(name string) (*Group, error) {
 	return &group, err
 }
 
+// GetGroupByExternalID retrieves a group by the ID given by a SCIM provisioning client
+func (db *GormDB) GetGroupByExternalID(externalID string) (*Group, error) {
+	var group Group
+	err := db.Where("external_id = ?", externalID).First(&group).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("group not found with external id: %s", externalID)
+	}
+	return &group, err
+}
+
+// SetGroupUsers replaces all members of a group with the provided users
+func (db *GormDB) SetGroupUsers(groupID uint, userIDs []uint) error {
+	var group Group
+	if err := db.First(&group, groupID).Error; err != nil {
+		return err
+	}
+
+	var users []User
+	if len(userIDs) > 0 {
+		if err := db.Find(&users, userIDs).Error; err != nil {
+			return err
+		}
+	}
+
+	return db.Model(&group).Association("Users").Replace(users)
+}
+
 // NOTE: GetUserGroups is defined in crud_user.go
 
 // SetUserGroups replaces all user groups with the provided list
@@ -0,0 +1,113 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// =============================================================================
+// Helm Repository CRUD Operations
+// =============================================================================
+
+// CreateHelmRepository registers a Helm repository
+func (db *GormDB) CreateHelmRepository(repo *HelmRepository) error {
+	return db.Create(repo).Error
+}
+
+// GetHelmRepository retrieves a Helm repository by ID
+func (db *GormDB) GetHelmRepository(id uint) (*HelmRepository, error) {
+	var repo HelmRepository
+	err := db.First(&repo, id).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("helm repository not found with ID: %d", id)
+	}
+	return &repo, err
+}
+
+// GetHelmRepositoryByName retrieves a Helm repository by name
+func (db *GormDB) GetHelmRepositoryByName(name string) (*HelmRepository, error) {
+	var repo HelmRepository
+	err := db.Where("name = ?", name).First(&repo).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("helm repository not found: %s", name)
+	}
+	return &repo, err
+}
+
+// ListHelmRepositories lists all Helm repositories by name
+func (db *GormDB) ListHelmRepositories() ([]*HelmRepository, error) {
+	var repos []*HelmRepository
+	err := db.Order("name").Find(&repos).Error
+	return repos, err
+}
+
+// UpdateHelmRepository saves a Helm repository
+func (db *GormDB) UpdateHelmRepository(repo *HelmRepository) error {
+	return db.Save(repo).Error
+}
+
+// DeleteHelmRepository deletes a Helm repository and its indexed chart versions
+func (db *GormDB) DeleteHelmRepository(id uint) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("repository_id = ?", id).Delete(&HelmChartVersion{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&HelmRepository{}, id).Error
+	})
+}
+
+// ReplaceHelmChartVersions replaces the indexed chart versions of a repository and
+// records the successful index
+func (db *GormDB) ReplaceHelmChartVersions(repositoryID uint, versions []*HelmChartVersion, chartCount int) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("repository_id = ?", repositoryID).Delete(&HelmChartVersion{}).Error; err != nil {
+			return err
+		}
+		for _, v := range versions {
+			v.ID = 0
+			v.RepositoryID = repositoryID
+		}
+		if len(versions) > 0 {
+			if err := tx.CreateInBatches(versions, 200).Error; err != nil {
+				return err
+			}
+		}
+		now := time.Now()
+		return tx.Model(&HelmRepository{}).Where("id = ?", repositoryID).Updates(map[string]interface{}{
+			"chart_count":     chartCount,
+			"last_indexed_at": &now,
+			"index_error":     "",
+		}).Error
+	})
+}
+
+// SetHelmRepositoryIndexError records a failed index; previously indexed versions are kept
+func (db *GormDB) SetHelmRepositoryIndexError(repositoryID uint, indexErr string) error {
+	now := time.Now()
+	return db.Model(&HelmRepository{}).Where("id = ?", repositoryID).Updates(map[string]interface{}{
+		"last_indexed_at": &now,
+		"index_error":     indexErr,
+	}).Error
+}
+
+// ListHelmChartVersions lists indexed chart versions matching the filters
+func (db *GormDB) ListHelmChartVersions(filters HelmChartFilters) ([]*HelmChartVersion, error) {
+	query := db.Model(&HelmChartVersion{})
+	if filters.RepositoryID != 0 {
+		query = query.Where("repository_id = ?", filters.RepositoryID)
+	}
+	if filters.Chart != "" {
+		query = query.Where("chart = ?", filters.Chart)
+	}
+	if filters.Query != "" {
+		like := "%" + strings.ToLower(filters.Query) + "%"
+		query = query.Where("LOWER(chart) LIKE ? OR LOWER(description) LIKE ?", like, like)
+	}
+
+	var versions []*HelmChartVersion
+	err := query.Order("repository_id, chart").Find(&versions).Error
+	return versions, err
+}
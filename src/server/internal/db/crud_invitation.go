@@ -0,0 +1,96 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrInvitationUsed is returned when redeeming an invitation that was already redeemed or revoked
+var ErrInvitationUsed = errors.New("invitation already used or revoked")
+
+// =============================================================================
+// Invitation CRUD Operations
+// =============================================================================
+
+// CreateInvitation creates a new invitation
+func (db *GormDB) CreateInvitation(invitation *Invitation) error {
+	return db.Create(invitation).Error
+}
+
+// GetInvitationByID retrieves an invitation by ID
+func (db *GormDB) GetInvitationByID(id uint) (*Invitation, error) {
+	var invitation Invitation
+	err := db.First(&invitation, id).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("invitation not found with ID: %d", id)
+	}
+	return &invitation, err
+}
+
+// GetPendingInvitationByTokenHash retrieves an unredeemed, unrevoked and unexpired invitation
+func (db *GormDB) GetPendingInvitationByTokenHash(tokenHash string) (*Invitation, error) {
+	var invitation Invitation
+	err := db.Where("token_hash = ?", tokenHash).
+		Where("accepted_at IS NULL AND revoked_at IS NULL").
+		Where("expires_at > ?", time.Now()).
+		First(&invitation).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("invitation not found or expired")
+	}
+	return &invitation, err
+}
+
+// ListPendingInvitations lists invitations that have not been redeemed or revoked
+func (db *GormDB) ListPendingInvitations() ([]*Invitation, error) {
+	var invitations []*Invitation
+	err := db.Where("accepted_at IS NULL AND revoked_at IS NULL").
+		Order("created_at DESC").
+		Find(&invitations).Error
+	return invitations, err
+}
+
+// RevokeInvitation marks a pending invitation as revoked
+func (db *GormDB) RevokeInvitation(id uint) error {
+	result := db.Model(&Invitation{}).
+		Where("id = ? AND accepted_at IS NULL AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("pending invitation not found with ID: %d", id)
+	}
+	return nil
+}
+
+// AcceptInvitation redeems an invitation by creating its user, with the given groups. The
+// invitation is marked redeemed in the same transaction, so that it stays usable if the
+// user cannot be created and concurrent redemptions fail with ErrInvitationUsed.
+func (db *GormDB) AcceptInvitation(id uint, user *User, groupIDs []uint) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&Invitation{}).
+			Where("id = ? AND accepted_at IS NULL AND revoked_at IS NULL", id).
+			Update("accepted_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrInvitationUsed
+		}
+
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		if len(groupIDs) > 0 {
+			var groups []Group
+			if err := tx.Find(&groups, groupIDs).Error; err != nil {
+				return err
+			}
+			return tx.Model(user).Association("Groups").Append(groups)
+		}
+		return nil
+	})
+}
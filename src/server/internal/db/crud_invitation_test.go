@@ -0,0 +1,43 @@
+package db
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAcceptInvitation(t *testing.T) {
+	db, err := NewGorm(filepath.Join(t.TempDir(), "kubelens.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if err := db.CreateUser(&User{Email: "taken@example.com", Username: "taken"}); err != nil {
+		t.Fatal(err)
+	}
+	invitation := &Invitation{Email: "bob@example.com", TokenHash: "hash", ExpiresAt: time.Now().Add(time.Hour)}
+	if err := db.CreateInvitation(invitation); err != nil {
+		t.Fatal(err)
+	}
+
+	// A user that cannot be created leaves the invitation usable
+	if err := db.AcceptInvitation(invitation.ID, &User{Email: "bob@example.com", Username: "taken"}, nil); err == nil {
+		t.Fatal("expected accepting with a taken username to fail")
+	}
+	if _, err := db.GetPendingInvitationByTokenHash("hash"); err != nil {
+		t.Fatalf("invitation is no longer pending after a failed accept: %v", err)
+	}
+
+	bob := &User{Email: "bob@example.com", Username: "bob"}
+	if err := db.AcceptInvitation(invitation.ID, bob, nil); err != nil {
+		t.Fatal(err)
+	}
+	if bob.ID == 0 {
+		t.Error("user was not created")
+	}
+	if err := db.AcceptInvitation(invitation.ID, &User{Email: "bob@example.com", Username: "bob2"}, nil); !errors.Is(err, ErrInvitationUsed) {
+		t.Errorf("accepting twice: err = %v, want ErrInvitationUsed", err)
+	}
+}
@@ -0,0 +1,81 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// =============================================================================
+// Log Archive Policy CRUD Operations
+// =============================================================================
+
+// CreateLogArchivePolicy stores a new log archive policy
+func (db *GormDB) CreateLogArchivePolicy(policy *LogArchivePolicy) error {
+	return db.Create(policy).Error
+}
+
+// GetLogArchivePolicy retrieves a log archive policy by ID
+func (db *GormDB) GetLogArchivePolicy(id uint) (*LogArchivePolicy, error) {
+	var policy LogArchivePolicy
+	err := db.First(&policy, id).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("log archive policy not found with ID: %d", id)
+	}
+	return &policy, err
+}
+
+// GetLogArchivePolicyByName retrieves the log archive policy of a cluster with a name
+func (db *GormDB) GetLogArchivePolicyByName(clusterName, name string) (*LogArchivePolicy, error) {
+	var policy LogArchivePolicy
+	err := db.Where("cluster_name = ? AND name = ?", clusterName, name).First(&policy).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("log archive policy %s not found for cluster %s", name, clusterName)
+	}
+	return &policy, err
+}
+
+// ListLogArchivePolicies lists the log archive policies of a cluster, by name
+func (db *GormDB) ListLogArchivePolicies(clusterName string) ([]*LogArchivePolicy, error) {
+	var policies []*LogArchivePolicy
+	err := db.Where("cluster_name = ?", clusterName).Order("name").Find(&policies).Error
+	return policies, err
+}
+
+// ListDueLogArchivePolicies lists the enabled log archive policies whose interval has
+// elapsed since their last export
+func (db *GormDB) ListDueLogArchivePolicies(now time.Time) ([]*LogArchivePolicy, error) {
+	var policies []*LogArchivePolicy
+	if err := db.Where("enabled = ?", true).Order("id").Find(&policies).Error; err != nil {
+		return nil, err
+	}
+	due := policies[:0]
+	for _, policy := range policies {
+		if policy.LastRunAt == nil || !now.Before(policy.LastRunAt.Add(time.Duration(policy.IntervalMinutes)*time.Minute)) {
+			due = append(due, policy)
+		}
+	}
+	return due, nil
+}
+
+// UpdateLogArchivePolicy saves a log archive policy
+func (db *GormDB) UpdateLogArchivePolicy(policy *LogArchivePolicy) error {
+	return db.Save(policy).Error
+}
+
+// RecordLogArchiveRun records the outcome of an export without touching the settings of
+// the policy, which may have been changed while it ran. lastRunAt is nil when the export
+// failed before the period was exported.
+func (db *GormDB) RecordLogArchiveRun(id uint, lastRunAt *time.Time, lastError string) error {
+	updates := map[string]interface{}{"last_error": lastError}
+	if lastRunAt != nil {
+		updates["last_run_at"] = *lastRunAt
+	}
+	return db.Model(&LogArchivePolicy{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// DeleteLogArchivePolicy deletes a log archive policy
+func (db *GormDB) DeleteLogArchivePolicy(id uint) error {
+	return db.Delete(&LogArchivePolicy{}, id).Error
+}
@@ -0,0 +1,60 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// =============================================================================
+// Notification Channel CRUD Operations
+// =============================================================================
+
+// CreateNotificationChannel creates a notification channel
+func (db *GormDB) CreateNotificationChannel(channel *NotificationChannel) error {
+	return db.Create(channel).Error
+}
+
+// GetNotificationChannel retrieves a notification channel by ID
+func (db *GormDB) GetNotificationChannel(id uint) (*NotificationChannel, error) {
+	var channel NotificationChannel
+	err := db.First(&channel, id).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("notification channel not found with ID: %d", id)
+	}
+	return &channel, err
+}
+
+// ListNotificationChannels lists all notification channels by name
+func (db *GormDB) ListNotificationChannels() ([]*NotificationChannel, error) {
+	var channels []*NotificationChannel
+	err := db.Order("name").Find(&channels).Error
+	return channels, err
+}
+
+// ListEnabledNotificationChannels lists the channels events are delivered to
+func (db *GormDB) ListEnabledNotificationChannels() ([]*NotificationChannel, error) {
+	var channels []*NotificationChannel
+	err := db.Where("enabled = ?", true).Order("name").Find(&channels).Error
+	return channels, err
+}
+
+// UpdateNotificationChannel saves a notification channel
+func (db *GormDB) UpdateNotificationChannel(channel *NotificationChannel) error {
+	return db.Save(channel).Error
+}
+
+// DeleteNotificationChannel deletes a notification channel
+func (db *GormDB) DeleteNotificationChannel(id uint) error {
+	return db.Delete(&NotificationChannel{}, id).Error
+}
+
+// RecordNotificationDelivery records the outcome of the last delivery to a channel
+func (db *GormDB) RecordNotificationDelivery(id uint, deliveryErr string) error {
+	now := time.Now()
+	return db.Model(&NotificationChannel{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"last_delivery_at": &now,
+		"last_error":       deliveryErr,
+	}).Error
+}
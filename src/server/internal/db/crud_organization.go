@@ -0,0 +1,205 @@
+package db
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrOrganizationNotEmpty is returned when deleting an organization that still owns clusters
+var ErrOrganizationNotEmpty = errors.New("organization still has clusters")
+
+// =============================================================================
+// Organization CRUD Operations
+// =============================================================================
+
+// AfterCreate adds a new user to the default organization, an owner if the user is an
+// admin, so accounts created by every sign-up and provisioning path can work somewhere.
+// GORM also runs it for existing users saved through an association, who keep their
+// membership.
+func (u *User) AfterCreate(tx *gorm.DB) error {
+	role := OrgRoleMember
+	if u.IsAdmin {
+		role = OrgRoleOwner
+	}
+	return tx.Session(&gorm.Session{NewDB: true}).Exec(
+		"INSERT INTO organization_members (organization_id, user_id, role, created_at) SELECT id, ?, ?, ? FROM organizations WHERE id = ? "+
+			"AND NOT EXISTS (SELECT 1 FROM organization_members WHERE organization_id = ? AND user_id = ?)",
+		u.ID, role, time.Now().UTC(), DefaultOrganizationID, DefaultOrganizationID, u.ID).Error
+}
+
+// CreateOrganization creates an organization with its first owner (none for 0)
+func (db *GormDB) CreateOrganization(org *Organization, ownerID uint) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(org).Error; err != nil {
+			return err
+		}
+		if ownerID == 0 {
+			return nil
+		}
+		return tx.Create(&OrganizationMember{OrganizationID: org.ID, UserID: ownerID, Role: OrgRoleOwner}).Error
+	})
+}
+
+// GetOrganization retrieves an organization by ID
+func (db *GormDB) GetOrganization(id uint) (*Organization, error) {
+	var org Organization
+	err := db.First(&org, id).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("organization not found: %d", id)
+	}
+	return &org, err
+}
+
+// ListOrganizations lists all organizations by name
+func (db *GormDB) ListOrganizations() ([]*Organization, error) {
+	var orgs []*Organization
+	err := db.Order("name ASC").Find(&orgs).Error
+	return orgs, err
+}
+
+// UpdateOrganization saves an organization
+func (db *GormDB) UpdateOrganization(org *Organization) error {
+	return db.Save(org).Error
+}
+
+// DeleteOrganization deletes an organization with its members, groups and notifications.
+// Its clusters must be moved or removed first; its audit logs are kept.
+func (db *GormDB) DeleteOrganization(id uint) error {
+	if id == DefaultOrganizationID {
+		return fmt.Errorf("the default organization cannot be deleted")
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		var clusters int64
+		if err := tx.Model(&Cluster{}).Where("organization_id = ?", id).Count(&clusters).Error; err != nil {
+			return err
+		}
+		if clusters > 0 {
+			return ErrOrganizationNotEmpty
+		}
+		groups := tx.Model(&Group{}).Select("id").Where("organization_id = ?", id)
+		if err := tx.Where("group_id IN (?)", groups).Delete(&UserGroup{}).Error; err != nil {
+			return err
+		}
+		for _, model := range []interface{}{&Group{}, &Notification{}, &OrganizationMember{}} {
+			if err := tx.Where("organization_id = ?", id).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Delete(&Organization{}, id).Error
+	})
+}
+
+// ListUserOrganizations lists the memberships of a user, with their organization
+func (db *GormDB) ListUserOrganizations(userID uint) ([]*OrganizationMember, error) {
+	var members []*OrganizationMember
+	err := db.Preload("Organization").Where("user_id = ?", userID).Order("organization_id ASC").Find(&members).Error
+	return members, err
+}
+
+// ListOrganizationMembers lists the members of an organization, with their user
+func (db *GormDB) ListOrganizationMembers(orgID uint) ([]*OrganizationMember, error) {
+	var members []*OrganizationMember
+	err := db.Preload("User").Where("organization_id = ?", orgID).Order("user_id ASC").Find(&members).Error
+	return members, err
+}
+
+// GetOrganizationRole returns the role of a user in an organization, "" for non-members
+func (db *GormDB) GetOrganizationRole(orgID, userID uint) (string, error) {
+	var member OrganizationMember
+	err := db.Where("organization_id = ? AND user_id = ?", orgID, userID).Limit(1).Find(&member).Error
+	return member.Role, err
+}
+
+// DefaultOrganizationOf returns the organization a user signs in to: the default
+// organization if the user is a member, the oldest of its organizations otherwise, and 0
+// for a user in no organization
+func (db *GormDB) DefaultOrganizationOf(userID uint) (uint, error) {
+	var ids []uint
+	err := db.Model(&OrganizationMember{}).Where("user_id = ?", userID).
+		Order("organization_id ASC").Limit(1).Pluck("organization_id", &ids).Error
+	if err != nil || len(ids) == 0 {
+		return 0, err
+	}
+	return ids[0], nil
+}
+
+// SetOrganizationMember adds a user to an organization or changes its role there
+func (db *GormDB) SetOrganizationMember(orgID, userID uint, role string) error {
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "organization_id"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"role"}),
+	}).Create(&OrganizationMember{OrganizationID: orgID, UserID: userID, Role: role}).Error
+}
+
+// RemoveOrganizationMember removes a user from an organization and from its groups
+func (db *GormDB) RemoveOrganizationMember(orgID, userID uint) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		groups := tx.Model(&Group{}).Select("id").Where("organization_id = ?", orgID)
+		if err := tx.Where("user_id = ? AND group_id IN (?)", userID, groups).Delete(&UserGroup{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("organization_id = ? AND user_id = ?", orgID, userID).Delete(&OrganizationMember{}).Error
+	})
+}
+
+// CountOrganizationOwners counts the owners of an organization
+func (db *GormDB) CountOrganizationOwners(orgID uint) (int64, error) {
+	var count int64
+	err := db.Model(&OrganizationMember{}).Where("organization_id = ? AND role = ?", orgID, OrgRoleOwner).Count(&count).Error
+	return count, err
+}
+
+// ClusterOrganization returns the organization of a cluster, the default organization
+// for an unknown cluster
+func (db *GormDB) ClusterOrganization(name string) uint {
+	var ids []uint
+	db.Model(&Cluster{}).Where("name = ?", name).Limit(1).Pluck("organization_id", &ids)
+	if len(ids) == 0 {
+		return DefaultOrganizationID
+	}
+	return ids[0]
+}
+
+// ClustersOutsideOrganization returns the names of the clusters of other organizations
+func (db *GormDB) ClustersOutsideOrganization(orgID uint) ([]string, error) {
+	var names []string
+	err := db.Model(&Cluster{}).Where("organization_id <> ?", orgID).Pluck("name", &names).Error
+	return names, err
+}
+
+// MoveCluster gives a cluster to another organization
+func (db *GormDB) MoveCluster(name string, orgID uint) error {
+	result := db.Model(&Cluster{}).Where("name = ?", name).Update("organization_id", orgID)
+	if result.Error == nil && result.RowsAffected == 0 {
+		return fmt.Errorf("cluster not found: %s", name)
+	}
+	return result.Error
+}
+
+// GetUserOrganizationPermissions returns the permissions a user has in an organization,
+// from its groups there
+func (db *GormDB) GetUserOrganizationPermissions(userID, orgID uint) ([]Permission, error) {
+	var groups []Group
+	err := db.Joins("JOIN user_groups ON user_groups.group_id = groups.id").
+		Where("user_groups.user_id = ? AND groups.organization_id = ?", userID, orgID).
+		Find(&groups).Error
+	if err != nil {
+		return nil, err
+	}
+
+	allPermissions := []Permission{}
+	for _, group := range groups {
+		var permissions []Permission
+		if err := json.Unmarshal([]byte(group.Permissions), &permissions); err == nil {
+			allPermissions = append(allPermissions, permissions...)
+		}
+	}
+
+	// Cluster groups are resolved to their current members
+	return db.ExpandClusterGroups(allPermissions)
+}
@@ -0,0 +1,85 @@
+package db
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestOrganizations(t *testing.T) {
+	db, err := NewGorm(filepath.Join(t.TempDir(), "kubelens.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// New users join the default organization
+	admin := &User{Email: "admin@example.com", Username: "admin", IsAdmin: true}
+	alice := &User{Email: "alice@example.com", Username: "alice"}
+	for _, user := range []*User{admin, alice} {
+		if err := db.CreateUser(user); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if role, err := db.GetOrganizationRole(DefaultOrganizationID, admin.ID); err != nil || role != OrgRoleOwner {
+		t.Errorf("role of the admin in the default organization = %q, %v; want owner", role, err)
+	}
+	if role, _ := db.GetOrganizationRole(DefaultOrganizationID, alice.ID); role != OrgRoleMember {
+		t.Errorf("role of a user in the default organization = %q, want member", role)
+	}
+
+	team := &Organization{Name: "team-a"}
+	if err := db.CreateOrganization(team, alice.ID); err != nil {
+		t.Fatal(err)
+	}
+	if role, _ := db.GetOrganizationRole(team.ID, alice.ID); role != OrgRoleOwner {
+		t.Errorf("role of the creator = %q, want owner", role)
+	}
+	if role, _ := db.GetOrganizationRole(team.ID, admin.ID); role != "" {
+		t.Errorf("role of a non-member = %q, want none", role)
+	}
+
+	// Groups grant their permissions in their organization only
+	group := &Group{Name: "team-a-viewers", Permissions: JSON(`[{"resource":"pods","actions":["read"]}]`), OrganizationID: team.ID}
+	if err := db.CreateGroup(group); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AddUserToGroup(alice.ID, group.ID); err != nil {
+		t.Fatal(err)
+	}
+	if permissions, _ := db.GetUserOrganizationPermissions(alice.ID, team.ID); len(permissions) != 1 {
+		t.Errorf("permissions in team-a = %v, want the group's", permissions)
+	}
+	if permissions, _ := db.GetUserOrganizationPermissions(alice.ID, DefaultOrganizationID); len(permissions) != 0 {
+		t.Errorf("permissions in the default organization = %v, want none", permissions)
+	}
+
+	if err := db.CreateCluster(&Cluster{Name: "prod", AuthType: "token", AuthConfig: JSON("{}"), OrganizationID: team.ID}); err != nil {
+		t.Fatal(err)
+	}
+	if outside, _ := db.ClustersOutsideOrganization(DefaultOrganizationID); len(outside) != 1 || outside[0] != "prod" {
+		t.Errorf("ClustersOutsideOrganization(default) = %v, want [prod]", outside)
+	}
+	if err := db.DeleteOrganization(team.ID); !errors.Is(err, ErrOrganizationNotEmpty) {
+		t.Errorf("DeleteOrganization() with a cluster = %v, want ErrOrganizationNotEmpty", err)
+	}
+	if err := db.MoveCluster("prod", DefaultOrganizationID); err != nil {
+		t.Fatal(err)
+	}
+	if org := db.ClusterOrganization("prod"); org != DefaultOrganizationID {
+		t.Errorf("ClusterOrganization(prod) after the move = %d", org)
+	}
+
+	if err := db.DeleteOrganization(team.ID); err != nil {
+		t.Fatalf("DeleteOrganization() = %v", err)
+	}
+	if orgID, _ := db.DefaultOrganizationOf(alice.ID); orgID != DefaultOrganizationID {
+		t.Errorf("DefaultOrganizationOf(alice) = %d after deleting team-a", orgID)
+	}
+	if _, err := db.GetGroupByID(group.ID); err == nil {
+		t.Error("group of the deleted organization still exists")
+	}
+	if err := db.DeleteOrganization(DefaultOrganizationID); err == nil {
+		t.Error("DeleteOrganization(default) succeeded")
+	}
+}
@@ -0,0 +1,38 @@
+package db
+
+// =============================================================================
+// Password History CRUD Operations
+// =============================================================================
+
+// AddPasswordHistory records a password hash of a user and keeps only the newest keep
+// entries. With keep <= 0 the user's history is cleared.
+func (db *GormDB) AddPasswordHistory(userID uint, passwordHash string, keep int) error {
+	if keep <= 0 {
+		return db.Where("user_id = ?", userID).Delete(&PasswordHistory{}).Error
+	}
+
+	if err := db.Create(&PasswordHistory{UserID: userID, PasswordHash: passwordHash}).Error; err != nil {
+		return err
+	}
+
+	var keepIDs []uint
+	if err := db.Model(&PasswordHistory{}).
+		Where("user_id = ?", userID).
+		Order("id DESC").
+		Limit(keep).
+		Pluck("id", &keepIDs).Error; err != nil {
+		return err
+	}
+	return db.Where("user_id = ? AND id NOT IN ?", userID, keepIDs).Delete(&PasswordHistory{}).Error
+}
+
+// GetPasswordHistory returns the newest limit password hashes of a user
+func (db *GormDB) GetPasswordHistory(userID uint, limit int) ([]string, error) {
+	var hashes []string
+	err := db.Model(&PasswordHistory{}).
+		Where("user_id = ?", userID).
+		Order("id DESC").
+		Limit(limit).
+		Pluck("password_hash", &hashes).Error
+	return hashes, err
+}
@@ -0,0 +1,51 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// =============================================================================
+// Password Reset Token CRUD Operations
+// =============================================================================
+
+// CreatePasswordResetToken stores a reset token, invalidating the pending tokens of the
+// same user so only the newest link works
+func (db *GormDB) CreatePasswordResetToken(token *PasswordResetToken) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&PasswordResetToken{}).
+			Where("user_id = ? AND used_at IS NULL", token.UserID).
+			Update("expires_at", time.Now()).Error; err != nil {
+			return err
+		}
+		return tx.Create(token).Error
+	})
+}
+
+// GetPendingPasswordResetToken retrieves an unused and unexpired reset token
+func (db *GormDB) GetPendingPasswordResetToken(tokenHash string) (*PasswordResetToken, error) {
+	var token PasswordResetToken
+	err := db.Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", tokenHash, time.Now()).
+		First(&token).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("password reset token not found or expired")
+	}
+	return &token, err
+}
+
+// UsePasswordResetToken marks a reset token as used. It fails if the token was already
+// used, which keeps the link single-use.
+func (db *GormDB) UsePasswordResetToken(id uint) error {
+	result := db.Model(&PasswordResetToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("password reset link already used")
+	}
+	return nil
+}
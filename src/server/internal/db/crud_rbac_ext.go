@@ -19,6 +19,7 @@ func (db *GormDB) GetUserPermissions(userID uint) ([]Permission, error) {
 		}
 	}
 
-	return allPermissions, nil
+	// Cluster groups are resolved to their current members
+	return db.ExpandClusterGroups(allPermissions)
 }
 
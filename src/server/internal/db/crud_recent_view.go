@@ -0,0 +1,87 @@
+package db
+
+import "gorm.io/gorm"
+
+// MaxRecentViews is how many distinct resources each user's recent views keep
+const MaxRecentViews = 50
+
+// =============================================================================
+// Recent View CRUD Operations
+// =============================================================================
+
+// BeforeSave keys the view by the object viewed
+func (v *RecentView) BeforeSave(tx *gorm.DB) error {
+	v.ObjectKey = objectKey(v.ClusterName, v.Group, v.Resource, v.Namespace, v.Name)
+	return nil
+}
+
+// RecordRecentView records that a user viewed a resource, unless they disabled recent
+// views: the resource moves to the top of their recent views, and the oldest views beyond
+// MaxRecentViews are dropped. It reports whether the view was recorded.
+func (db *GormDB) RecordRecentView(view *RecentView) (bool, error) {
+	recorded := false
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var disabled []bool
+		if err := tx.Model(&UserSession{}).Where("user_id = ?", view.UserID).Pluck("recent_views_disabled", &disabled).Error; err != nil {
+			return err
+		}
+		if len(disabled) > 0 && disabled[0] {
+			return nil
+		}
+
+		var existing RecentView
+		err := tx.Where("user_id = ? AND object_key = ?",
+			view.UserID, objectKey(view.ClusterName, view.Group, view.Resource, view.Namespace, view.Name)).First(&existing).Error
+		switch {
+		case err == gorm.ErrRecordNotFound:
+			view.Views = 1
+			if err := tx.Create(view).Error; err != nil {
+				return err
+			}
+		case err != nil:
+			return err
+		default:
+			view.ID, view.Views = existing.ID, existing.Views+1
+			if err := tx.Model(&existing).Updates(map[string]interface{}{
+				"views":     view.Views,
+				"viewed_at": view.ViewedAt,
+				"kind":      view.Kind,
+			}).Error; err != nil {
+				return err
+			}
+		}
+		recorded = true
+
+		var stale []uint
+		if err := tx.Model(&RecentView{}).Where("user_id = ?", view.UserID).
+			Order("viewed_at DESC, id DESC").Offset(MaxRecentViews).Pluck("id", &stale).Error; err != nil {
+			return err
+		}
+		if len(stale) == 0 {
+			return nil
+		}
+		return tx.Where("id IN ?", stale).Delete(&RecentView{}).Error
+	})
+	return recorded, err
+}
+
+// ListRecentViews lists the recent views of a user in an organization (all of them for
+// 0), most recent first, at most limit of them (all for 0)
+func (db *GormDB) ListRecentViews(userID, orgID uint, limit int) ([]*RecentView, error) {
+	views := []*RecentView{}
+	tx := db.Where("user_id = ?", userID)
+	if orgID != 0 {
+		tx = tx.Where("organization_id = ?", orgID)
+	}
+	if limit > 0 {
+		tx = tx.Limit(limit)
+	}
+	err := tx.Order("viewed_at DESC, id DESC").Find(&views).Error
+	return views, err
+}
+
+// DeleteRecentViews clears the recent views of a user, returning how many were removed
+func (db *GormDB) DeleteRecentViews(userID uint) (int64, error) {
+	result := db.Where("user_id = ?", userID).Delete(&RecentView{})
+	return result.RowsAffected, result.Error
+}
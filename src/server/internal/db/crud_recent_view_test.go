@@ -0,0 +1,41 @@
+package db
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRecentViewsKeepTheLatest(t *testing.T) {
+	db, err := NewGorm(filepath.Join(t.TempDir(), "kubelens.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	start := time.Now()
+	view := func(i int, at time.Duration) {
+		t.Helper()
+		v := &RecentView{UserID: 1, ClusterName: "prod", Resource: "pods", Kind: "Pod", Namespace: "shop", Name: fmt.Sprintf("pod-%d", i), ViewedAt: start.Add(at)}
+		if recorded, err := db.RecordRecentView(v); err != nil || !recorded {
+			t.Fatalf("RecordRecentView(pod-%d) = %v, %v", i, recorded, err)
+		}
+	}
+	for i := 0; i < MaxRecentViews+5; i++ {
+		view(i, time.Duration(i)*time.Second)
+	}
+	// Viewing the oldest resource kept again moves it to the top
+	view(5, time.Hour)
+
+	views, err := db.ListRecentViews(1, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(views) != MaxRecentViews {
+		t.Fatalf("kept %d recent views, want %d", len(views), MaxRecentViews)
+	}
+	if views[0].Name != "pod-5" || views[0].Views != 2 || views[len(views)-1].Name != "pod-6" {
+		t.Errorf("recent views run from %s (%d views) to %s, want pod-5 (2 views) to pod-6", views[0].Name, views[0].Views, views[len(views)-1].Name)
+	}
+}
@@ -0,0 +1,74 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// =============================================================================
+// Refresh Token CRUD Operations
+// =============================================================================
+
+// CreateRefreshToken creates a new refresh token
+func (db *GormDB) CreateRefreshToken(token *RefreshToken) error {
+	return db.Create(token).Error
+}
+
+// GetRefreshTokenByHash retrieves a refresh token by hash, whether or not it is still valid
+func (db *GormDB) GetRefreshTokenByHash(tokenHash string) (*RefreshToken, error) {
+	var token RefreshToken
+	err := db.Where("token_hash = ?", tokenHash).First(&token).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("refresh token not found")
+	}
+	return &token, err
+}
+
+// RotateRefreshToken revokes a refresh token and stores its replacement in one transaction.
+// It fails if the old token was revoked in the meantime, so a token can only be used once.
+func (db *GormDB) RotateRefreshToken(oldID uint, replacement *RefreshToken) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(replacement).Error; err != nil {
+			return err
+		}
+		result := tx.Model(&RefreshToken{}).
+			Where("id = ? AND revoked_at IS NULL", oldID).
+			Updates(map[string]interface{}{"revoked_at": time.Now(), "replaced_by": replacement.ID})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("refresh token already used")
+		}
+		return nil
+	})
+}
+
+// RevokeRefreshToken revokes one refresh token
+func (db *GormDB) RevokeRefreshToken(id uint) error {
+	return db.Model(&RefreshToken{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now()).Error
+}
+
+// RevokeRefreshTokenFamily revokes every token descended from the same sign-in
+func (db *GormDB) RevokeRefreshTokenFamily(familyID string) error {
+	return db.Model(&RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// RevokeUserRefreshTokens revokes all refresh tokens of a user, signing them out everywhere
+func (db *GormDB) RevokeUserRefreshTokens(userID uint) error {
+	return db.Model(&RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// DeleteExpiredRefreshTokens deletes refresh tokens that expired before the given time
+func (db *GormDB) DeleteExpiredRefreshTokens(before time.Time) (int64, error) {
+	result := db.Where("expires_at < ?", before).Delete(&RefreshToken{})
+	return result.RowsAffected, result.Error
+}
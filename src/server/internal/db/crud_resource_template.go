@@ -0,0 +1,60 @@
+package db
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// =============================================================================
+// Resource Template CRUD Operations
+// =============================================================================
+
+// CreateResourceTemplate stores a new resource template
+func (db *GormDB) CreateResourceTemplate(tmpl *ResourceTemplate) error {
+	return db.Create(tmpl).Error
+}
+
+// GetResourceTemplate retrieves a resource template by ID
+func (db *GormDB) GetResourceTemplate(id uint) (*ResourceTemplate, error) {
+	var tmpl ResourceTemplate
+	err := db.First(&tmpl, id).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("resource template not found: %d", id)
+	}
+	return &tmpl, err
+}
+
+// ListResourceTemplates lists the resource templates of an organization (all of them for
+// 0), by category then name
+func (db *GormDB) ListResourceTemplates(orgID uint) ([]*ResourceTemplate, error) {
+	templates := []*ResourceTemplate{}
+	tx := db.DB
+	if orgID != 0 {
+		tx = tx.Where("organization_id = ?", orgID)
+	}
+	err := tx.Order("category ASC, name ASC").Find(&templates).Error
+	return templates, err
+}
+
+// ResourceTemplateNameTaken reports whether another resource template of an organization
+// is named name
+func (db *GormDB) ResourceTemplateNameTaken(orgID uint, name string, exceptID uint) (bool, error) {
+	var count int64
+	tx := db.Model(&ResourceTemplate{}).Where("name = ? AND id <> ?", name, exceptID)
+	if orgID != 0 {
+		tx = tx.Where("organization_id = ?", orgID)
+	}
+	err := tx.Count(&count).Error
+	return count > 0, err
+}
+
+// UpdateResourceTemplate saves a resource template
+func (db *GormDB) UpdateResourceTemplate(tmpl *ResourceTemplate) error {
+	return db.Save(tmpl).Error
+}
+
+// DeleteResourceTemplate deletes a resource template by ID
+func (db *GormDB) DeleteResourceTemplate(id uint) error {
+	return db.Delete(&ResourceTemplate{}, id).Error
+}
@@ -0,0 +1,60 @@
+package db
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// =============================================================================
+// Saved Audit Query CRUD Operations
+// =============================================================================
+
+// CreateSavedAuditQuery stores a new saved audit query
+func (db *GormDB) CreateSavedAuditQuery(query *SavedAuditQuery) error {
+	return db.Create(query).Error
+}
+
+// GetSavedAuditQuery retrieves a saved audit query by ID
+func (db *GormDB) GetSavedAuditQuery(id uint) (*SavedAuditQuery, error) {
+	var query SavedAuditQuery
+	err := db.First(&query, id).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("saved audit query not found: %d", id)
+	}
+	return &query, err
+}
+
+// ListSavedAuditQueries lists the saved audit queries of a user in an organization (all of
+// them for 0) with those shared there, by name
+func (db *GormDB) ListSavedAuditQueries(userID, orgID uint) ([]*SavedAuditQuery, error) {
+	var queries []*SavedAuditQuery
+	tx := db.Where("user_id = ? OR shared = ?", userID, true)
+	if orgID != 0 {
+		tx = db.Where("organization_id = ?", orgID).Where(tx)
+	}
+	err := tx.Order("name ASC").Find(&queries).Error
+	return queries, err
+}
+
+// SavedAuditQueryNameTaken reports whether a user has another saved audit query named name
+// in an organization
+func (db *GormDB) SavedAuditQueryNameTaken(userID, orgID uint, name string, exceptID uint) (bool, error) {
+	var count int64
+	tx := db.Model(&SavedAuditQuery{}).Where("user_id = ? AND name = ? AND id <> ?", userID, name, exceptID)
+	if orgID != 0 {
+		tx = tx.Where("organization_id = ?", orgID)
+	}
+	err := tx.Count(&count).Error
+	return count > 0, err
+}
+
+// UpdateSavedAuditQuery saves a saved audit query
+func (db *GormDB) UpdateSavedAuditQuery(query *SavedAuditQuery) error {
+	return db.Save(query).Error
+}
+
+// DeleteSavedAuditQuery deletes a saved audit query by ID
+func (db *GormDB) DeleteSavedAuditQuery(id uint) error {
+	return db.Delete(&SavedAuditQuery{}, id).Error
+}
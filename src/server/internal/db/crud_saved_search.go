@@ -0,0 +1,60 @@
+package db
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// =============================================================================
+// Saved Search CRUD Operations
+// =============================================================================
+
+// CreateSavedSearch stores a new saved search
+func (db *GormDB) CreateSavedSearch(search *SavedSearch) error {
+	return db.Create(search).Error
+}
+
+// GetSavedSearch retrieves a saved search by ID
+func (db *GormDB) GetSavedSearch(id uint) (*SavedSearch, error) {
+	var search SavedSearch
+	err := db.First(&search, id).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("saved search not found: %d", id)
+	}
+	return &search, err
+}
+
+// ListSavedSearches lists the saved searches of a user in an organization (all of them for
+// 0), by name
+func (db *GormDB) ListSavedSearches(userID, orgID uint) ([]*SavedSearch, error) {
+	searches := []*SavedSearch{}
+	tx := db.Where("user_id = ?", userID)
+	if orgID != 0 {
+		tx = tx.Where("organization_id = ?", orgID)
+	}
+	err := tx.Order("name ASC").Find(&searches).Error
+	return searches, err
+}
+
+// SavedSearchNameTaken reports whether a user has another saved search named name in an
+// organization
+func (db *GormDB) SavedSearchNameTaken(userID, orgID uint, name string, exceptID uint) (bool, error) {
+	var count int64
+	tx := db.Model(&SavedSearch{}).Where("user_id = ? AND name = ? AND id <> ?", userID, name, exceptID)
+	if orgID != 0 {
+		tx = tx.Where("organization_id = ?", orgID)
+	}
+	err := tx.Count(&count).Error
+	return count > 0, err
+}
+
+// UpdateSavedSearch saves a saved search
+func (db *GormDB) UpdateSavedSearch(search *SavedSearch) error {
+	return db.Save(search).Error
+}
+
+// DeleteSavedSearch deletes a saved search by ID
+func (db *GormDB) DeleteSavedSearch(id uint) error {
+	return db.Delete(&SavedSearch{}, id).Error
+}
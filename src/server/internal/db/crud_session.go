@@ -59,17 +59,21 @@ func (db *GormDB) UpdateSession(token string, expiresAt time.Time) error {
 
 // DeleteSession deletes a session by token
 func (db *GormDB) DeleteSession(token string) error {
+	defer evictSessions(db.sessionIDs("token = ?", token)...)
 	return db.Where("token = ?", token).Delete(&Session{}).Error
 }
 
 // DeleteUserSessions deletes all sessions for a user
 func (db *GormDB) DeleteUserSessions(userID uint) error {
+	defer evictSessions(db.sessionIDs("user_id = ?", userID)...)
 	return db.Where("user_id = ?", userID).Delete(&Session{}).Error
 }
 
 // CleanExpiredSessions deletes all expired sessions
 func (db *GormDB) CleanExpiredSessions() error {
-	return db.Where("expires_at < ?", time.Now()).Delete(&Session{}).Error
+	now := time.Now()
+	defer evictSessions(db.sessionIDs("expires_at < ?", now)...)
+	return db.Where("expires_at < ?", now).Delete(&Session{}).Error
 }
 
 // CountActiveSessions counts active sessions for a user
@@ -82,6 +86,101 @@ func (db *GormDB) CountActiveSessions(userID uint) (int64, error) {
 	return count, err
 }
 
+// GetSessionByID retrieves a session by ID, whether or not it is still valid
+func (db *GormDB) GetSessionByID(id uint) (*Session, error) {
+	if session, ok := cachedSessionByID(id); ok {
+		return session, nil
+	}
+	var session Session
+	err := db.First(&session, id).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("session not found")
+	}
+	if err == nil {
+		cacheSession(&session)
+	}
+	return &session, err
+}
+
+// ListActiveUserSessions lists the sessions of a user that are neither expired nor revoked,
+// most recently used first
+func (db *GormDB) ListActiveUserSessions(userID uint) ([]*Session, error) {
+	var sessions []*Session
+	err := db.Where("user_id = ? AND revoked_at IS NULL", userID).
+		Where("expires_at > ?", time.Now()).
+		Order("last_seen_at DESC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+// TouchSession records activity on a session
+func (db *GormDB) TouchSession(id uint, ip string) error {
+	defer evictSessions(id)
+	return db.Model(&Session{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"last_seen_at": time.Now(),
+		"ip_address":   ip,
+	}).Error
+}
+
+// ExtendSession moves the expiry of a session, when its refresh token is rotated
+func (db *GormDB) ExtendSession(id uint, expiresAt time.Time, ip string) error {
+	defer evictSessions(id)
+	return db.Model(&Session{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"expires_at":   expiresAt,
+		"last_seen_at": time.Now(),
+		"ip_address":   ip,
+	}).Error
+}
+
+// SetSessionOrganization switches the organization a session works in
+func (db *GormDB) SetSessionOrganization(id, orgID uint) error {
+	defer evictSessions(id)
+	return db.Model(&Session{}).Where("id = ?", id).Update("organization_id", orgID).Error
+}
+
+// RevokeSession revokes a session and the refresh tokens of its sign-in. The session is
+// evicted before the transaction too, so a copy cached while it commits does not outlive it.
+func (db *GormDB) RevokeSession(id uint) error {
+	evictSessions(id)
+	defer evictSessions(id)
+	return db.Transaction(func(tx *gorm.DB) error {
+		var session Session
+		if err := tx.First(&session, id).Error; err != nil {
+			return fmt.Errorf("session not found")
+		}
+		now := time.Now()
+		if err := tx.Model(&Session{}).
+			Where("id = ? AND revoked_at IS NULL", id).
+			Update("revoked_at", now).Error; err != nil {
+			return err
+		}
+		return tx.Model(&RefreshToken{}).
+			Where("family_id = ? AND revoked_at IS NULL", session.Token).
+			Update("revoked_at", now).Error
+	})
+}
+
+// RevokeUserSessions revokes every session of a user except exceptID (0 for none),
+// along with their refresh tokens
+func (db *GormDB) RevokeUserSessions(userID uint, exceptID uint) error {
+	ids := db.sessionIDs("user_id = ? AND revoked_at IS NULL AND id <> ?", userID, exceptID)
+	evictSessions(ids...)
+	defer evictSessions(ids...)
+	return db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		sessions := tx.Model(&Session{}).Where("user_id = ? AND revoked_at IS NULL", userID)
+		tokens := tx.Model(&RefreshToken{}).Where("user_id = ? AND revoked_at IS NULL", userID)
+		if exceptID != 0 {
+			sessions = sessions.Where("id <> ?", exceptID)
+			tokens = tokens.Where("family_id NOT IN (?)", tx.Model(&Session{}).Select("token").Where("id = ?", exceptID))
+		}
+		if err := sessions.Update("revoked_at", now).Error; err != nil {
+			return err
+		}
+		return tokens.Update("revoked_at", now).Error
+	})
+}
+
 // =============================================================================
 // UserSession CRUD Operations (Preferences)
 // =============================================================================
@@ -147,9 +246,10 @@ func (db *GormDB) UpdateUserSession(session *UserSession) error {
 	return db.Model(&UserSession{}).
 		Where("user_id = ?", session.UserID).
 		Updates(map[string]interface{}{
-			"selected_cluster":   session.SelectedCluster,
-			"selected_namespace": session.SelectedNamespace,
-			"selected_theme":     session.SelectedTheme,
+			"selected_cluster":      session.SelectedCluster,
+			"selected_namespace":    session.SelectedNamespace,
+			"selected_theme":        session.SelectedTheme,
+			"recent_views_disabled": session.RecentViewsDisabled,
 		}).Error
 }
 
@@ -0,0 +1,102 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// =============================================================================
+// Upgrade Plan CRUD Operations
+// =============================================================================
+
+// CreateUpgradePlan stores a new upgrade plan
+func (db *GormDB) CreateUpgradePlan(plan *UpgradePlan) error {
+	return db.Create(plan).Error
+}
+
+// GetUpgradePlan retrieves an upgrade plan by ID
+func (db *GormDB) GetUpgradePlan(id uint) (*UpgradePlan, error) {
+	var plan UpgradePlan
+	err := db.First(&plan, id).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("upgrade plan not found with ID: %d", id)
+	}
+	return &plan, err
+}
+
+// ListUpgradePlans lists upgrade plans for a cluster, newest first
+func (db *GormDB) ListUpgradePlans(clusterName string) ([]*UpgradePlan, error) {
+	var plans []*UpgradePlan
+	err := db.Where("cluster_name = ?", clusterName).
+		Order("created_at DESC").
+		Find(&plans).Error
+	return plans, err
+}
+
+// ClaimUpgradePlan makes owner the server running a pending or paused upgrade plan. It
+// reports false when the plan is in another state, e.g. because another server claimed
+// it first.
+func (db *GormDB) ClaimUpgradePlan(plan *UpgradePlan, owner string, now time.Time) (bool, error) {
+	startedAt := plan.StartedAt
+	if startedAt == nil {
+		startedAt = &now
+	}
+	claimed, err := db.TransitionUpgradePlan(plan.ID, []string{"pending", "paused"}, map[string]interface{}{
+		"status":       "running",
+		"owner":        owner,
+		"heartbeat_at": now,
+		"stop_request": "",
+		"error":        "",
+		"started_at":   startedAt,
+	})
+	if !claimed || err != nil {
+		return false, err
+	}
+	plan.Status, plan.Owner, plan.HeartbeatAt, plan.StopRequest, plan.Error, plan.StartedAt = "running", owner, &now, "", "", startedAt
+	return true, nil
+}
+
+// TransitionUpgradePlan updates columns of an upgrade plan if its status is one of from.
+// It reports false when it is not.
+func (db *GormDB) TransitionUpgradePlan(id uint, from []string, updates map[string]interface{}) (bool, error) {
+	result := db.Model(&UpgradePlan{}).
+		Where("id = ? AND status IN ?", id, from).
+		Updates(updates)
+	return result.RowsAffected == 1, result.Error
+}
+
+// SaveUpgradePlan saves an upgrade plan run by owner, leaving its lease and stop request
+// alone. It reports false when owner no longer runs the plan.
+func (db *GormDB) SaveUpgradePlan(plan *UpgradePlan, owner string) (bool, error) {
+	result := db.Model(plan).
+		Select("*").
+		Omit("stop_request", "heartbeat_at", "created_at").
+		Where("owner = ?", owner).
+		Updates(plan)
+	return result.RowsAffected == 1, result.Error
+}
+
+// HeartbeatUpgradePlan renews the lease of owner on a running upgrade plan and returns the
+// stop requested of it, if any. It reports false when owner no longer runs the plan.
+func (db *GormDB) HeartbeatUpgradePlan(id uint, owner string, now time.Time) (string, bool, error) {
+	result := db.Model(&UpgradePlan{}).
+		Where("id = ? AND owner = ? AND status = ?", id, owner, "running").
+		UpdateColumn("heartbeat_at", now)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return "", false, result.Error
+	}
+	var plan UpgradePlan
+	err := db.Select("stop_request").First(&plan, id).Error
+	return plan.StopRequest, err == nil, err
+}
+
+// PauseStaleUpgradePlans marks running upgrade plans whose owner last renewed its lease
+// before cutoff as paused so they can be resumed explicitly. The server running them stopped.
+func (db *GormDB) PauseStaleUpgradePlans(cutoff time.Time) (int64, error) {
+	result := db.Model(&UpgradePlan{}).
+		Where("status = ? AND (heartbeat_at IS NULL OR heartbeat_at < ?)", "running", cutoff).
+		Updates(map[string]interface{}{"status": "paused", "owner": "", "error": "interrupted: the server running it stopped"})
+	return result.RowsAffected, result.Error
+}
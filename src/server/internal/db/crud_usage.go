@@ -0,0 +1,61 @@
+package db
+
+import (
+	"gorm.io/gorm"
+)
+
+// =============================================================================
+// Usage Rollup CRUD Operations
+// =============================================================================
+
+// IncrementUsageRollup adds counts to the rollup row for (day, user, cluster), creating it if needed
+func (db *GormDB) IncrementUsageRollup(day string, userID uint, clusterName string, requests, mutations, shells int64) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&UsageRollup{}).
+			Where("day = ? AND user_id = ? AND cluster_name = ?", day, userID, clusterName).
+			Updates(map[string]interface{}{
+				"requests":      gorm.Expr("requests + ?", requests),
+				"mutations":     gorm.Expr("mutations + ?", mutations),
+				"shells_opened": gorm.Expr("shells_opened + ?", shells),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			return nil
+		}
+
+		return tx.Create(&UsageRollup{
+			Day:          day,
+			UserID:       userID,
+			ClusterName:  clusterName,
+			Requests:     requests,
+			Mutations:    mutations,
+			ShellsOpened: shells,
+		}).Error
+	})
+}
+
+// ListUsageRollups returns rollups between two days (inclusive, YYYY-MM-DD), optionally for one user
+func (db *GormDB) ListUsageRollups(fromDay, toDay string, userID uint) ([]*UsageRollup, error) {
+	var rollups []*UsageRollup
+	query := db.Where("day >= ? AND day <= ?", fromDay, toDay)
+	if userID > 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+	err := query.Order("day ASC, user_id ASC, cluster_name ASC").Find(&rollups).Error
+	return rollups, err
+}
+
+// DeleteUsageRollupsBefore deletes rollups older than the given day
+func (db *GormDB) DeleteUsageRollupsBefore(day string) (int64, error) {
+	result := db.Where("day < ?", day).Delete(&UsageRollup{})
+	return result.RowsAffected, result.Error
+}
+
+// ListUserGroupMemberships returns every user-group membership
+func (db *GormDB) ListUserGroupMemberships() ([]UserGroup, error) {
+	var memberships []UserGroup
+	err := db.Find(&memberships).Error
+	return memberships, err
+}
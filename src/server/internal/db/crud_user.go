@@ -77,6 +77,16 @@ func (db *GormDB) GetUserByProvider(provider, providerUserID string) (*User, err
 	return &user, err
 }
 
+// GetUserByExternalID retrieves a user by the ID given by a SCIM provisioning client
+func (db *GormDB) GetUserByExternalID(externalID string) (*User, error) {
+	var user User
+	err := db.Where("external_id = ?", externalID).First(&user).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("user not found with external id: %s", externalID)
+	}
+	return &user, err
+}
+
 // GetUserWithGroups retrieves a user with their groups (eager loading)
 func (db *GormDB) GetUserWithGroups(username string) (*User, error) {
 	var user User
@@ -217,7 +227,21 @@ func (db *GormDB) RevokeUserTokens(userID uint) error {
 
 // DeleteUser deletes a user (soft delete if GORM soft delete is enabled)
 func (db *GormDB) DeleteUser(userID uint) error {
-	return db.Delete(&User{}, userID).Error
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&OrganizationMember{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&SavedSearch{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&Favorite{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&RecentView{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&User{}, userID).Error
+	})
 }
 
 // UpdateUserGroups replaces a user's groups
@@ -24,6 +24,15 @@ func New(connectionString string) (*DB, error) {
 	return &DB{GormDB: gormDB}, nil
 }
 
+// Open connects to the database without running migrations
+func Open(connectionString string) (*DB, error) {
+	gormDB, err := OpenGorm(connectionString)
+	if err != nil {
+		return nil, err
+	}
+	return &DB{GormDB: gormDB}, nil
+}
+
 // Close closes the database connection
 func (db *DB) Close() error {
 	return db.GormDB.Close()
@@ -128,7 +137,9 @@ func ValidateCluster(cluster *Cluster) error {
 		cluster.AuthType = string(AuthTypeToken)
 	}
 	
-	if cluster.AuthType != string(AuthTypeToken) && cluster.AuthType != string(AuthTypeKubeconfig) {
+	switch AuthType(cluster.AuthType) {
+	case AuthTypeToken, AuthTypeKubeconfig, AuthTypeExec, AuthTypeEKS, AuthTypeGKE, AuthTypeAKS, AuthTypeInCluster, AuthTypeAgent:
+	default:
 		return fmt.Errorf("invalid auth type: %s", cluster.AuthType)
 	}
 	
@@ -193,6 +204,41 @@ func ParseAuthConfig(authType, authConfigJSON string) (interface{}, error) {
 			return nil, fmt.Errorf("invalid kubeconfig auth config: %w", err)
 		}
 		return config, nil
+
+	case string(AuthTypeExec):
+		var config ExecAuthConfig
+		if err := json.Unmarshal([]byte(authConfigJSON), &config); err != nil {
+			return nil, fmt.Errorf("invalid exec auth config: %w", err)
+		}
+		return config, nil
+
+	case string(AuthTypeEKS):
+		var config EKSAuthConfig
+		if err := json.Unmarshal([]byte(authConfigJSON), &config); err != nil {
+			return nil, fmt.Errorf("invalid eks auth config: %w", err)
+		}
+		return config, nil
+
+	case string(AuthTypeGKE):
+		var config GKEAuthConfig
+		if err := json.Unmarshal([]byte(authConfigJSON), &config); err != nil {
+			return nil, fmt.Errorf("invalid gke auth config: %w", err)
+		}
+		return config, nil
+
+	case string(AuthTypeAKS):
+		var config AKSAuthConfig
+		if err := json.Unmarshal([]byte(authConfigJSON), &config); err != nil {
+			return nil, fmt.Errorf("invalid aks auth config: %w", err)
+		}
+		return config, nil
+
+	case string(AuthTypeAgent):
+		var config AgentAuthConfig
+		if err := json.Unmarshal([]byte(authConfigJSON), &config); err != nil {
+			return nil, fmt.Errorf("invalid agent auth config: %w", err)
+		}
+		return config, nil
 		
 	default:
 		return nil, fmt.Errorf("unsupported auth type: %s", authType)
@@ -4,17 +4,16 @@ import (
 	"crypto/rand"
 	"database/sql"
 	"encoding/base64"
+	"errors"
 	"fmt"
-	"math/big"
-	"os"
 	"strings"
 	"time"
 
-	"golang.org/x/crypto/bcrypt"
 	"gorm.io/driver/mysql"
 	"gorm.io/driver/postgres"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"gorm.io/gorm/logger"
 	log "github.com/sirupsen/logrus"
 	
@@ -41,6 +40,25 @@ func (db *GormDB) GetConn() *sql.DB {
 
 // NewGorm creates a new GORM database connection with auto-detection and migrations
 func NewGorm(connectionString string) (*GormDB, error) {
+	db, err := OpenGorm(connectionString)
+	if err != nil {
+		return nil, err
+	}
+
+	// Apply pending schema migrations
+	if err := db.migrate(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to run migrations: %w", err)
+	}
+
+	log.Infof("✅ Database initialized successfully (dialect: %s)", db.dialect)
+
+	return db, nil
+}
+
+// OpenGorm connects to the database without migrating it, for operators running
+// migrations by hand (see MigrateTo)
+func OpenGorm(connectionString string) (*GormDB, error) {
 	dialect := detectDialect(connectionString)
 	
 	log.Infof("🔍 Detected database dialect: %s", dialect)
@@ -109,19 +127,10 @@ func NewGorm(connectionString string) (*GormDB, error) {
 		sqlDB.SetConnMaxLifetime(5 * time.Minute)
 	}
 	
-	db := &GormDB{
+	return &GormDB{
 		DB:      gormDB,
 		dialect: dialect,
-	}
-	
-	// Run auto-migrations
-	if err := db.autoMigrate(); err != nil {
-		return nil, fmt.Errorf("failed to run migrations: %w", err)
-	}
-	
-	log.Infof("✅ Database initialized successfully (dialect: %s)", dialect)
-	
-	return db, nil
+	}, nil
 }
 
 // detectDialect detects database type from connection string
@@ -137,27 +146,12 @@ func detectDialect(connectionString string) string {
 	}
 }
 
-// autoMigrate runs GORM auto-migrations for all models
-func (db *GormDB) autoMigrate() error {
+// migrate applies the pending versioned migrations (see migrations.go) and seeds the
+// default data
+func (db *GormDB) migrate() error {
 	log.Info("📦 Running database migrations...")
 	
-	err := db.AutoMigrate(
-		&Cluster{},
-		&User{},
-		&Group{},
-		&UserGroup{},
-		&Session{},
-		&UserSession{},
-		&Notification{},
-		&AuditLog{},
-		&AuditSettings{},
-		&MFASecret{},
-		&ClusterMetadata{},
-		&ExtensionConfig{},
-		&SystemConfig{},
-	)
-	
-	if err != nil {
+	if err := db.Migrate(); err != nil {
 		return err
 	}
 	
@@ -263,78 +257,63 @@ func (db *GormDB) seedDefaultData() error {
 	return nil
 }
 
-// InitializeDefaultData creates default admin user with provided password
-// This is called from main.go after database initialization
-func (db *GormDB) InitializeDefaultData(adminPassword string) error {
-	// Check if admin user already exists
-	var adminUser User
-	result := db.Where("username = ?", "admin").First(&adminUser)
-	
-	if result.Error != gorm.ErrRecordNotFound {
-		// Admin user already exists
-		return nil
-	}
-	
-	log.Info("🌱 Creating default admin user...")
-	
-	// Use provided password or generate random one
-	if adminPassword == "" {
-		adminPassword = os.Getenv("KUBELENS_ADMIN_PASSWORD")
-	}
-	
-	if adminPassword == "" {
-		// Generate random password
-		adminPassword = generateRandomPassword(10)
-		log.Warnf("⚠️  Default admin password: %s", adminPassword)
-		log.Warn("⚠️  Please change this password after first login!")
-	} else {
-		log.Info("✅ Using provided admin password")
-	}
-	
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(adminPassword), bcrypt.DefaultCost)
-	if err != nil {
-		return fmt.Errorf("failed to hash password: %w", err)
+// HasAdminUser reports whether at least one admin user exists
+func (db *GormDB) HasAdminUser() (bool, error) {
+	var count int64
+	if err := db.Model(&User{}).Where("is_admin = ?", true).Count(&count).Error; err != nil {
+		return false, err
 	}
-	
-	adminUser = User{
-		Email:        "admin@kubelens.local",
-		Username:     "admin",
-		PasswordHash: string(hashedPassword),
-		FullName:     "Administrator",
+	return count > 0, nil
+}
+
+// ErrAdminExists is returned by CreateInitialAdmin when an admin user already exists
+var ErrAdminExists = errors.New("an admin user already exists")
+
+// CreateInitialAdmin creates an admin user and adds it to the admin group, unless an admin
+// exists already, e.g. created through another replica
+func (db *GormDB) CreateInitialAdmin(username, email, fullName, passwordHash string) (*User, error) {
+	adminUser := User{
+		Email:        email,
+		Username:     username,
+		PasswordHash: passwordHash,
+		FullName:     fullName,
 		AuthProvider: "local",
 		IsActive:     true,
 		IsAdmin:      true,
 		MFAEnabled:   false,
 	}
-	
-	if err := db.Create(&adminUser).Error; err != nil {
-		return fmt.Errorf("failed to create admin user: %w", err)
-	}
-	
-	// Get admin group and assign user to it
-	var adminGroup Group
-	if err := db.Where("name = ?", "admin").First(&adminGroup).Error; err != nil {
-		return fmt.Errorf("failed to find admin group: %w", err)
-	}
-	
-	if err := db.Model(&adminUser).Association("Groups").Append(&adminGroup); err != nil {
-		return fmt.Errorf("failed to assign admin to admin group: %w", err)
-	}
-	
-	log.Info("✅ Default admin user created successfully")
-	
-	return nil
-}
 
-// generateRandomPassword generates a random alphanumeric password
-func generateRandomPassword(length int) string {
-	const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
-	password := make([]byte, length)
-	for i := range password {
-		n, _ := rand.Int(rand.Reader, big.NewInt(int64(len(charset))))
-		password[i] = charset[n.Int64()]
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// Lock the admin group so concurrent setups check for an admin one after the other
+		locked := tx
+		if tx.Dialector.Name() != "sqlite" {
+			locked = tx.Clauses(clause.Locking{Strength: "UPDATE"})
+		}
+		var adminGroup Group
+		if err := locked.Where("name = ?", "admin").First(&adminGroup).Error; err != nil {
+			return fmt.Errorf("failed to find admin group: %w", err)
+		}
+
+		var admins int64
+		if err := tx.Model(&User{}).Where("is_admin = ?", true).Count(&admins).Error; err != nil {
+			return err
+		}
+		if admins > 0 {
+			return ErrAdminExists
+		}
+
+		if err := tx.Create(&adminUser).Error; err != nil {
+			return fmt.Errorf("failed to create admin user: %w", err)
+		}
+		if err := tx.Model(&adminUser).Association("Groups").Append(&adminGroup); err != nil {
+			return fmt.Errorf("failed to assign admin to admin group: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	return string(password)
+	return &adminUser, nil
 }
 
 // GetDialect returns the database dialect
@@ -412,6 +391,17 @@ func (db *GormDB) SetSystemConfig(key, value string) error {
 		FirstOrCreate(&config).Error
 }
 
+// CreateSystemConfigIfAbsent stores a system config value unless the key already has one,
+// atomically, and returns the value the key ends up with
+func (db *GormDB) CreateSystemConfigIfAbsent(key, value string) (string, error) {
+	config := SystemConfig{Key: key, Value: value}
+	if err := db.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "key"}}, DoNothing: true}).
+		Create(&config).Error; err != nil {
+		return "", err
+	}
+	return db.GetSystemConfig(key)
+}
+
 // GetOrCreateEncryptionKey retrieves existing key or auto-generates a new one on first install
 func (db *GormDB) GetOrCreateEncryptionKey() ([]byte, error) {
 	const keyName = "encryption_key"
@@ -0,0 +1,25 @@
+package db
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestCreateSystemConfigIfAbsent(t *testing.T) {
+	db, err := NewGorm(filepath.Join(t.TempDir(), "kubelens.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// The first value stored wins, e.g. the JWT secret of the replica that started first
+	if value, err := db.CreateSystemConfigIfAbsent("jwt_secret", "first"); err != nil || value != "first" {
+		t.Fatalf("CreateSystemConfigIfAbsent() = %q, %v; want first", value, err)
+	}
+	if value, err := db.CreateSystemConfigIfAbsent("jwt_secret", "second"); err != nil || value != "first" {
+		t.Errorf("CreateSystemConfigIfAbsent() of a stored key = %q, %v; want the stored value", value, err)
+	}
+	if value, err := db.GetSystemConfig("jwt_secret"); err != nil || value != "first" {
+		t.Errorf("GetSystemConfig() = %q, %v; want first", value, err)
+	}
+}
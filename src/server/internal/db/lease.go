@@ -0,0 +1,19 @@
+package db
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+)
+
+// NewLeaseOwner returns a name for a server process in the leases it holds on the jobs it
+// runs, unique among the replicas sharing the database
+func NewLeaseOwner() string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "kubelens"
+	}
+	suffix := make([]byte, 4)
+	_, _ = rand.Read(suffix)
+	return host + "-" + hex.EncodeToString(suffix)
+}
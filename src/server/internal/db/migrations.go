@@ -0,0 +1,299 @@
+package db
+
+import (
+	"embed"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// SchemaMigration records a migration applied to the database
+type SchemaMigration struct {
+	Version   int       `gorm:"primaryKey;autoIncrement:false" json:"version"`
+	Name      string    `gorm:"not null" json:"name"`
+	AppliedAt time.Time `json:"applied_at"`
+}
+
+// Migration is one versioned schema change, written as SQL for each supported dialect in
+// migrations/<dialect>/<version>_<name>.up.sql and .down.sql. Data, when set, moves the
+// existing rows; it runs where the up script has a "-- data" line, or after the script.
+// Both directions run in a transaction where the database supports transactional DDL
+// (SQLite, PostgreSQL).
+type Migration struct {
+	Version int
+	Name    string
+	Data    func(tx *gorm.DB) error
+}
+
+// migrationFiles holds the SQL scripts of the migrations
+//
+//go:embed migrations
+var migrationFiles embed.FS
+
+// dataMarker is the line of an up script where the Data step of its migration runs
+const dataMarker = "-- data"
+
+// migrations is the schema history, in version order. Never edit or renumber a released
+// migration or its scripts: add a new one. The scripts are frozen SQL rather than the
+// current models so that every database at a version has the same schema.
+var migrations = []Migration{
+	{
+		Version: 1,
+		Name:    "baseline",
+		Data:    adoptTables,
+	},
+	{
+		Version: 2,
+		Name:    "organizations",
+		Data: func(tx *gorm.DB) error {
+			// Everything so far belongs to the default organization, with every user in it
+			defaultOrg := Organization{Name: "default", DisplayName: "Default"}
+			if err := tx.Where("name = ?", defaultOrg.Name).FirstOrCreate(&defaultOrg).Error; err != nil {
+				return err
+			}
+			if defaultOrg.ID != DefaultOrganizationID {
+				return fmt.Errorf("default organization was created with ID %d, want %d", defaultOrg.ID, DefaultOrganizationID)
+			}
+			if err := tx.Exec("UPDATE audit_logs SET organization_id = ? WHERE organization_id IS NULL", DefaultOrganizationID).Error; err != nil {
+				return err
+			}
+			return tx.Exec(`INSERT INTO organization_members (organization_id, user_id, role, created_at)
+				SELECT ?, id, CASE WHEN is_admin THEN ? ELSE ? END, ? FROM users`,
+				DefaultOrganizationID, OrgRoleOwner, OrgRoleMember, time.Now().UTC()).Error
+		},
+	},
+	{
+		Version: 3,
+		Name:    "audit_search",
+		Data: func(tx *gorm.DB) error {
+			// Existing entries get the columns from their metadata
+			var batch []AuditLog
+			return tx.Where("metadata <> '' OR (action = '' AND request_method <> '')").FindInBatches(&batch, 500, func(batchTx *gorm.DB, _ int) error {
+				for _, entry := range batch {
+					entry.fillSearchFields()
+					if err := tx.Table("audit_logs").Where("id = ?", entry.ID).Updates(map[string]interface{}{
+						"action":        entry.Action,
+						"resource":      entry.Resource,
+						"cluster_name":  entry.ClusterName,
+						"namespace":     entry.Namespace,
+						"resource_name": entry.ResourceName,
+					}).Error; err != nil {
+						return err
+					}
+				}
+				return nil
+			}).Error
+		},
+	},
+	{
+		Version: 4,
+		Name:    "audit_chain",
+		Data: func(tx *gorm.DB) error {
+			return tx.Exec("INSERT INTO audit_chain_heads (id) VALUES (?)", auditChainHeadID).Error
+		},
+	},
+	{Version: 5, Name: "log_archives"},
+	{Version: 6, Name: "saved_searches"},
+	{Version: 7, Name: "favorites"},
+	{Version: 8, Name: "recent_views"},
+	{Version: 9, Name: "resource_templates"},
+	{Version: 10, Name: "drain_job_leases"},
+	{Version: 11, Name: "upgrade_plan_leases"},
+}
+
+// script returns the statements of the up or down script of a migration for a dialect.
+// The data marker is kept as a statement of its own.
+func (m Migration) script(dialect, direction string) ([]string, error) {
+	name := fmt.Sprintf("migrations/%s/%04d_%s.%s.sql", dialect, m.Version, m.Name, direction)
+	data, err := migrationFiles.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("no %s script for %s", direction, dialect)
+	}
+	return splitStatements(string(data)), nil
+}
+
+// splitStatements splits a script into statements ending with ";" at the end of a line.
+// Triggers end with a line "END;" instead. Comment lines are dropped, except the data
+// marker.
+func splitStatements(script string) []string {
+	var statements []string
+	var current []string
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == dataMarker {
+			statements = append(statements, dataMarker)
+			continue
+		}
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		current = append(current, line)
+		if !strings.HasSuffix(trimmed, ";") {
+			continue
+		}
+		statement := strings.Join(current, "\n")
+		if strings.HasPrefix(strings.ToUpper(strings.TrimSpace(statement)), "CREATE TRIGGER") && strings.ToUpper(trimmed) != "END;" {
+			continue
+		}
+		statements = append(statements, strings.TrimSuffix(strings.TrimSpace(statement), ";"))
+		current = nil
+	}
+	return statements
+}
+
+// up applies a migration: the statements of its up script and its Data step
+func (m Migration) up(tx *gorm.DB) error {
+	statements, err := m.script(tx.Dialector.Name(), "up")
+	if err != nil {
+		return err
+	}
+	dataDone := m.Data == nil
+	for _, statement := range statements {
+		if statement == dataMarker {
+			if !dataDone {
+				if err := m.Data(tx); err != nil {
+					return err
+				}
+				dataDone = true
+			}
+			continue
+		}
+		if err := tx.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	if !dataDone {
+		return m.Data(tx)
+	}
+	return nil
+}
+
+// down reverts a migration with its down script
+func (m Migration) down(tx *gorm.DB) error {
+	statements, err := m.script(tx.Dialector.Name(), "down")
+	if err != nil {
+		return err
+	}
+	for _, statement := range statements {
+		if err := tx.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// baselineTable matches the first line of a table in the baseline scripts, and
+// baselineColumn a column definition inside it
+var (
+	baselineTable  = regexp.MustCompile("^CREATE TABLE IF NOT EXISTS [`\"](\\w+)[`\"] \\(")
+	baselineColumn = regexp.MustCompile("^\\s+([`\"](\\w+)[`\"] .+?),?$")
+)
+
+// adoptTables completes the tables of a database created before versioned migrations,
+// which the baseline does not recreate: the columns added to them before the baseline
+// was frozen are added from its definitions.
+func adoptTables(tx *gorm.DB) error {
+	data, err := migrationFiles.ReadFile(fmt.Sprintf("migrations/%s/0001_baseline.up.sql", tx.Dialector.Name()))
+	if err != nil {
+		return err
+	}
+	table := ""
+	for _, line := range strings.Split(string(data), "\n") {
+		if m := baselineTable.FindStringSubmatch(line); m != nil {
+			table = m[1]
+			if !tx.Migrator().HasTable(table) {
+				table = ""
+			}
+			continue
+		}
+		if table == "" {
+			continue
+		}
+		if strings.HasPrefix(line, ")") {
+			table = ""
+			continue
+		}
+		m := baselineColumn.FindStringSubmatch(line)
+		if m == nil || tx.Migrator().HasColumn(table, m[2]) {
+			continue
+		}
+		if err := tx.Exec(fmt.Sprintf("ALTER TABLE %s ADD %s", tx.Statement.Quote(table), m[1])).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LatestSchemaVersion is the schema version this build migrates to
+func LatestSchemaVersion() int {
+	return migrations[len(migrations)-1].Version
+}
+
+// SchemaVersion returns the version of the last migration applied, 0 for an empty database
+func (db *GormDB) SchemaVersion() (int, error) {
+	if err := db.AutoMigrate(&SchemaMigration{}); err != nil {
+		return 0, fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+	var last SchemaMigration
+	err := db.Order("version DESC").Limit(1).Find(&last).Error
+	return last.Version, err
+}
+
+// Migrate applies all pending migrations
+func (db *GormDB) Migrate() error {
+	return db.MigrateTo(LatestSchemaVersion())
+}
+
+// MigrateTo migrates the schema up or down to a version. Going down runs the down scripts
+// of every migration above the version, newest first; 0 drops the whole schema.
+// A database migrated by a newer build is left alone unless explicitly migrated down.
+func (db *GormDB) MigrateTo(version int) error {
+	if version < 0 || version > LatestSchemaVersion() {
+		return fmt.Errorf("unknown schema version %d (latest is %d)", version, LatestSchemaVersion())
+	}
+	current, err := db.SchemaVersion()
+	if err != nil {
+		return err
+	}
+	if current > LatestSchemaVersion() {
+		return fmt.Errorf("database schema version %d is newer than this build supports (%d)", current, LatestSchemaVersion())
+	}
+
+	for _, m := range migrations {
+		if m.Version <= current || m.Version > version {
+			continue
+		}
+		log.Infof("📦 Applying migration %d (%s)", m.Version, m.Name)
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := m.up(tx); err != nil {
+				return err
+			}
+			return tx.Create(&SchemaMigration{Version: m.Version, Name: m.Name, AppliedAt: time.Now().UTC()}).Error
+		})
+		if err != nil {
+			return fmt.Errorf("migration %d (%s) failed: %w", m.Version, m.Name, err)
+		}
+	}
+
+	for i := len(migrations) - 1; i >= 0; i-- {
+		m := migrations[i]
+		if m.Version > current || m.Version <= version {
+			continue
+		}
+		log.Infof("📦 Reverting migration %d (%s)", m.Version, m.Name)
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := m.down(tx); err != nil {
+				return err
+			}
+			return tx.Delete(&SchemaMigration{}, m.Version).Error
+		})
+		if err != nil {
+			return fmt.Errorf("reverting migration %d (%s) failed: %w", m.Version, m.Name, err)
+		}
+	}
+	return nil
+}
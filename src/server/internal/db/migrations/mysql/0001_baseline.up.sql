@@ -0,0 +1,556 @@
+-- The schema of kubelens before versioned migrations. Tables are created only when
+-- missing: a database created before then is completed instead (see adoptTables).
+-- data
+
+CREATE TABLE IF NOT EXISTS `clusters` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `name` varchar(255) NOT NULL,
+  `auth_type` varchar(191) NOT NULL DEFAULT 'token',
+  `auth_config` text NOT NULL,
+  `server` text,
+  `ca` text,
+  `token` text,
+  `is_default` boolean DEFAULT false,
+  `enabled` boolean DEFAULT true,
+  `impersonate` boolean DEFAULT false,
+  `status` varchar(50),
+  `source` varchar(255),
+  `labels` text,
+  `connection` text,
+  `credentials_rotated_at` datetime(3) NULL,
+  `credentials_expire_at` datetime(3) NULL,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_clusters_name` (`name`)
+);
+
+CREATE TABLE IF NOT EXISTS `users` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `email` varchar(255) NOT NULL,
+  `username` varchar(255) NOT NULL,
+  `password_hash` longtext,
+  `full_name` longtext,
+  `avatar_url` longtext,
+  `avatar_data` longblob,
+  `avatar_mime_type` varchar(50),
+  `auth_provider` varchar(191) DEFAULT 'local',
+  `provider_user_id` longtext,
+  `external_id` varchar(191),
+  `is_active` boolean DEFAULT true,
+  `is_admin` boolean DEFAULT false,
+  `mfa_enabled` boolean DEFAULT false,
+  `mfa_enforced_at` datetime(3) NULL,
+  `token_revoked_at` datetime(3) NULL,
+  `password_changed_at` datetime(3) NULL,
+  `last_login` datetime(3) NULL,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_users_email` (`email`),
+  UNIQUE INDEX `idx_users_username` (`username`),
+  INDEX `idx_users_external_id` (`external_id`)
+);
+
+CREATE TABLE IF NOT EXISTS `groups` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `name` varchar(255) NOT NULL,
+  `description` text,
+  `is_system` boolean DEFAULT false,
+  `permissions` text NOT NULL,
+  `external_id` varchar(191),
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_groups_external_id` (`external_id`),
+  UNIQUE INDEX `idx_groups_name` (`name`)
+);
+
+CREATE TABLE IF NOT EXISTS `user_groups` (
+  `user_id` bigint unsigned,
+  `group_id` bigint unsigned,
+  PRIMARY KEY (`user_id`,`group_id`)
+);
+
+CREATE TABLE IF NOT EXISTS `sessions` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `user_id` bigint unsigned NOT NULL,
+  `token` varchar(255) NOT NULL,
+  `expires_at` datetime(3) NOT NULL,
+  `created_at` datetime(3) NULL,
+  `ip_address` varchar(64),
+  `user_agent` text,
+  `last_seen_at` datetime(3) NULL,
+  `revoked_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_sessions_user_id` (`user_id`),
+  UNIQUE INDEX `idx_sessions_token` (`token`),
+  INDEX `idx_sessions_expires_at` (`expires_at`),
+  CONSTRAINT `fk_users_sessions` FOREIGN KEY (`user_id`) REFERENCES `users`(`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `user_sessions` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `user_id` bigint unsigned NOT NULL,
+  `selected_cluster` longtext,
+  `selected_namespace` longtext,
+  `selected_theme` longtext,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_user_sessions_user_id` (`user_id`),
+  CONSTRAINT `fk_user_sessions_user` FOREIGN KEY (`user_id`) REFERENCES `users`(`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `notifications` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `user_id` bigint unsigned NOT NULL,
+  `type` varchar(50) NOT NULL,
+  `title` varchar(255) NOT NULL,
+  `message` text NOT NULL,
+  `is_read` boolean DEFAULT false,
+  `created_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_notifications_user_id` (`user_id`),
+  INDEX `idx_notifications_created_at` (`created_at`),
+  CONSTRAINT `fk_notifications_user` FOREIGN KEY (`user_id`) REFERENCES `users`(`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `audit_logs` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `datetime` datetime(3) NOT NULL,
+  `event_type` varchar(100) NOT NULL,
+  `event_category` varchar(100) NOT NULL,
+  `level` varchar(20) NOT NULL,
+  `user_id` bigint unsigned,
+  `username` varchar(255),
+  `email` varchar(255),
+  `source_ip` varchar(45),
+  `user_agent` text,
+  `resource` varchar(255),
+  `action` varchar(255),
+  `description` text NOT NULL,
+  `metadata` text,
+  `success` boolean DEFAULT true,
+  `error_message` text,
+  `request_method` varchar(10),
+  `request_uri` text,
+  `response_code` bigint,
+  `duration_ms` bigint,
+  `session_id` varchar(255),
+  `correlation_id` varchar(255),
+  `geo_location` varchar(255),
+  `created_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_audit_logs_datetime` (`datetime`),
+  INDEX `idx_audit_logs_event_type` (`event_type`),
+  INDEX `idx_audit_logs_event_category` (`event_category`),
+  INDEX `idx_audit_logs_user_id` (`user_id`),
+  INDEX `idx_audit_logs_created_at` (`created_at`),
+  CONSTRAINT `fk_audit_logs_user` FOREIGN KEY (`user_id`) REFERENCES `users`(`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `audit_settings` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `user_id` bigint unsigned,
+  `enabled` boolean DEFAULT true,
+  `collect_authentication` boolean DEFAULT true,
+  `collect_security` boolean DEFAULT true,
+  `collect_audit` boolean DEFAULT true,
+  `collect_system` boolean DEFAULT false,
+  `collect_info` boolean DEFAULT true,
+  `collect_warn` boolean DEFAULT true,
+  `collect_error` boolean DEFAULT true,
+  `collect_critical` boolean DEFAULT true,
+  `sampling_enabled` boolean DEFAULT false,
+  `sampling_rate` double DEFAULT 1,
+  `custom_retention_days` bigint,
+  `updated_at` datetime(3) NULL,
+  `updated_by` bigint unsigned,
+  PRIMARY KEY (`id`),
+  INDEX `idx_audit_settings_user_id` (`user_id`)
+);
+
+CREATE TABLE IF NOT EXISTS `mfa_secrets` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `user_id` bigint unsigned NOT NULL,
+  `secret` text NOT NULL,
+  `backup_codes` text,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_mfa_secrets_user_id` (`user_id`),
+  CONSTRAINT `fk_users_mfa_secret` FOREIGN KEY (`user_id`) REFERENCES `users`(`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `cluster_metadata` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `cluster_name` varchar(255) NOT NULL,
+  `kube_version` varchar(50),
+  `node_count` bigint DEFAULT 0,
+  `pod_count` bigint DEFAULT 0,
+  `namespace_count` bigint DEFAULT 0,
+  `last_synced` datetime(3) NULL,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_cluster_metadata_cluster_name` (`cluster_name`)
+);
+
+CREATE TABLE IF NOT EXISTS `extension_configs` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `extension_name` varchar(255) NOT NULL,
+  `config_data` text NOT NULL,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_extension_configs_extension_name` (`extension_name`)
+);
+
+CREATE TABLE IF NOT EXISTS `system_configs` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `key` varchar(255) NOT NULL,
+  `value` text NOT NULL,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_system_configs_key` (`key`)
+);
+
+CREATE TABLE IF NOT EXISTS `invitations` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `email` varchar(255) NOT NULL,
+  `token_hash` varchar(64) NOT NULL,
+  `group_ids` text,
+  `is_admin` boolean DEFAULT false,
+  `invited_by` bigint unsigned,
+  `expires_at` datetime(3) NOT NULL,
+  `accepted_at` datetime(3) NULL,
+  `revoked_at` datetime(3) NULL,
+  `created_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_invitations_email` (`email`),
+  UNIQUE INDEX `idx_invitations_token_hash` (`token_hash`),
+  INDEX `idx_invitations_expires_at` (`expires_at`)
+);
+
+CREATE TABLE IF NOT EXISTS `api_tokens` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `user_id` bigint unsigned NOT NULL,
+  `name` varchar(255) NOT NULL,
+  `prefix` varchar(16),
+  `token_hash` varchar(64) NOT NULL,
+  `permissions` text,
+  `expires_at` datetime(3) NULL,
+  `last_used_at` datetime(3) NULL,
+  `last_used_ip` varchar(64),
+  `revoked_at` datetime(3) NULL,
+  `created_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_api_tokens_user_id` (`user_id`),
+  UNIQUE INDEX `idx_api_tokens_token_hash` (`token_hash`),
+  INDEX `idx_api_tokens_expires_at` (`expires_at`)
+);
+
+CREATE TABLE IF NOT EXISTS `refresh_tokens` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `user_id` bigint unsigned NOT NULL,
+  `family_id` varchar(64) NOT NULL,
+  `token_hash` varchar(64) NOT NULL,
+  `expires_at` datetime(3) NOT NULL,
+  `revoked_at` datetime(3) NULL,
+  `replaced_by` bigint unsigned,
+  `ip_address` varchar(64),
+  `user_agent` text,
+  `created_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_refresh_tokens_expires_at` (`expires_at`),
+  INDEX `idx_refresh_tokens_user_id` (`user_id`),
+  INDEX `idx_refresh_tokens_family_id` (`family_id`),
+  UNIQUE INDEX `idx_refresh_tokens_token_hash` (`token_hash`)
+);
+
+CREATE TABLE IF NOT EXISTS `password_history` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `user_id` bigint unsigned NOT NULL,
+  `password_hash` longtext NOT NULL,
+  `created_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_password_history_user_id` (`user_id`),
+  INDEX `idx_password_history_created_at` (`created_at`)
+);
+
+CREATE TABLE IF NOT EXISTS `password_reset_tokens` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `user_id` bigint unsigned NOT NULL,
+  `token_hash` varchar(64) NOT NULL,
+  `requested_by` bigint unsigned,
+  `expires_at` datetime(3) NOT NULL,
+  `used_at` datetime(3) NULL,
+  `created_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_password_reset_tokens_user_id` (`user_id`),
+  UNIQUE INDEX `idx_password_reset_tokens_token_hash` (`token_hash`),
+  INDEX `idx_password_reset_tokens_expires_at` (`expires_at`)
+);
+
+CREATE TABLE IF NOT EXISTS `feature_flags` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `key` varchar(255) NOT NULL,
+  `description` text,
+  `enabled` boolean DEFAULT false,
+  `group_ids` text,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_feature_flags_key` (`key`)
+);
+
+CREATE TABLE IF NOT EXISTS `crash_reports` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `cluster_name` varchar(255) NOT NULL,
+  `namespace` varchar(255) NOT NULL,
+  `pod_name` varchar(255) NOT NULL,
+  `pod_uid` varchar(64),
+  `owner_kind` varchar(100),
+  `owner_name` varchar(255),
+  `container_name` varchar(255) NOT NULL,
+  `image` text,
+  `restart_count` int,
+  `exit_code` int,
+  `signal` int,
+  `reason` varchar(255),
+  `termination_message` text,
+  `started_at` datetime(3) NULL,
+  `finished_at` datetime(3) NULL,
+  `node_name` varchar(255),
+  `logs` text,
+  `events` text,
+  `created_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_crash_reports_pod` (`cluster_name`,`namespace`,`pod_name`),
+  INDEX `idx_crash_reports_created_at` (`created_at`)
+);
+
+CREATE TABLE IF NOT EXISTS `upgrade_plans` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `cluster_name` varchar(255) NOT NULL,
+  `current_version` varchar(50),
+  `target_version` varchar(50) NOT NULL,
+  `batch_size` bigint DEFAULT 1,
+  `pause_between_batches` boolean DEFAULT true,
+  `status` varchar(50) NOT NULL,
+  `batches` text,
+  `current_batch` bigint DEFAULT 0,
+  `pre_checks` text,
+  `progress` text,
+  `error` text,
+  `created_by` bigint unsigned,
+  `started_at` datetime(3) NULL,
+  `completed_at` datetime(3) NULL,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_upgrade_plans_cluster_name` (`cluster_name`),
+  INDEX `idx_upgrade_plans_status` (`status`)
+);
+
+CREATE TABLE IF NOT EXISTS `usage_rollups` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `day` varchar(10) NOT NULL,
+  `user_id` bigint unsigned NOT NULL,
+  `cluster_name` varchar(255) NOT NULL DEFAULT '',
+  `requests` bigint DEFAULT 0,
+  `mutations` bigint DEFAULT 0,
+  `shells_opened` bigint DEFAULT 0,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_usage_rollup_key` (`day`,`user_id`,`cluster_name`)
+);
+
+CREATE TABLE IF NOT EXISTS `helm_repositories` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `name` varchar(255) NOT NULL,
+  `type` varchar(20) NOT NULL DEFAULT 'http',
+  `url` text NOT NULL,
+  `username` varchar(255),
+  `password` text,
+  `charts` text,
+  `chart_count` bigint DEFAULT 0,
+  `last_indexed_at` datetime(3) NULL,
+  `index_error` text,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_helm_repositories_name` (`name`)
+);
+
+CREATE TABLE IF NOT EXISTS `helm_chart_versions` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `repository_id` bigint unsigned NOT NULL,
+  `chart` varchar(255) NOT NULL,
+  `version` varchar(100) NOT NULL,
+  `app_version` varchar(100),
+  `description` text,
+  `icon` text,
+  `urls` text,
+  `digest` varchar(100),
+  `deprecated` boolean DEFAULT false,
+  `created` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_helm_chart_version` (`repository_id`,`chart`,`version`),
+  INDEX `idx_helm_chart_versions_chart` (`chart`)
+);
+
+CREATE TABLE IF NOT EXISTS `cluster_datasources` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `cluster_name` varchar(255) NOT NULL,
+  `type` varchar(20) NOT NULL,
+  `url` text NOT NULL,
+  `auth_type` varchar(20) NOT NULL DEFAULT 'none',
+  `username` varchar(255),
+  `secret` text,
+  `headers` text,
+  `insecure_skip_verify` boolean DEFAULT false,
+  `settings` text,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_cluster_datasource` (`cluster_name`,`type`)
+);
+
+CREATE TABLE IF NOT EXISTS `notification_channels` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `name` varchar(255) NOT NULL,
+  `type` varchar(20) NOT NULL,
+  `enabled` boolean DEFAULT true,
+  `config` text NOT NULL,
+  `events` text,
+  `min_severity` varchar(20),
+  `last_delivery_at` datetime(3) NULL,
+  `last_error` text,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_notification_channels_name` (`name`)
+);
+
+CREATE TABLE IF NOT EXISTS `alert_rules` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `name` varchar(255) NOT NULL,
+  `description` text,
+  `enabled` boolean DEFAULT true,
+  `cluster` varchar(255),
+  `namespace` varchar(255),
+  `condition` varchar(50) NOT NULL,
+  `threshold` bigint DEFAULT 0,
+  `for_seconds` bigint DEFAULT 0,
+  `severity` varchar(20) NOT NULL DEFAULT 'warning',
+  `created_by` bigint unsigned,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_alert_rules_name` (`name`),
+  INDEX `idx_alert_rules_cluster` (`cluster`)
+);
+
+CREATE TABLE IF NOT EXISTS `alerts` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `rule_id` bigint unsigned NOT NULL,
+  `rule_name` varchar(255),
+  `severity` varchar(20),
+  `cluster` varchar(255) NOT NULL,
+  `namespace` varchar(255),
+  `resource` varchar(512) NOT NULL,
+  `state` varchar(20) NOT NULL,
+  `message` text,
+  `active_since` datetime(3) NOT NULL,
+  `last_seen_at` datetime(3) NULL,
+  `fired_at` datetime(3) NULL,
+  `resolved_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_alerts_rule_id` (`rule_id`),
+  INDEX `idx_alerts_cluster` (`cluster`),
+  INDEX `idx_alerts_state` (`state`),
+  INDEX `idx_alerts_resolved_at` (`resolved_at`)
+);
+
+CREATE TABLE IF NOT EXISTS `cluster_events` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `cluster_name` varchar(255) NOT NULL,
+  `fingerprint` varchar(64) NOT NULL,
+  `uid` varchar(64),
+  `namespace` varchar(255),
+  `kind` varchar(255),
+  `name` varchar(255),
+  `type` varchar(20),
+  `reason` varchar(255),
+  `message` text,
+  `source` varchar(255),
+  `count` int,
+  `first_seen` datetime(3) NULL,
+  `last_seen` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_cluster_events_namespace` (`namespace`),
+  UNIQUE INDEX `idx_cluster_event` (`cluster_name`,`fingerprint`),
+  INDEX `idx_cluster_event_seen` (`cluster_name`,`last_seen`)
+);
+
+CREATE TABLE IF NOT EXISTS `benchmark_runs` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `cluster_name` varchar(255) NOT NULL,
+  `node` varchar(255),
+  `benchmark` varchar(50),
+  `namespace` varchar(255),
+  `job_name` varchar(255),
+  `status` varchar(20) NOT NULL,
+  `error` text,
+  `pass` bigint,
+  `fail` bigint,
+  `warn` bigint,
+  `info` bigint,
+  `sections` text,
+  `results` text,
+  `started_by` bigint unsigned,
+  `started_at` datetime(3) NULL,
+  `completed_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_benchmark_runs_cluster_name` (`cluster_name`),
+  INDEX `idx_benchmark_runs_status` (`status`),
+  INDEX `idx_benchmark_runs_started_at` (`started_at`)
+);
+
+CREATE TABLE IF NOT EXISTS `drain_jobs` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `cluster_name` varchar(255) NOT NULL,
+  `nodes` text,
+  `current_node` bigint DEFAULT 0,
+  `parallelism` bigint DEFAULT 1,
+  `timeout_seconds` bigint,
+  `scheduled_at` datetime(3) NULL,
+  `window_end` datetime(3) NULL,
+  `status` varchar(50) NOT NULL,
+  `progress` text,
+  `error` text,
+  `created_by` bigint unsigned,
+  `started_at` datetime(3) NULL,
+  `completed_at` datetime(3) NULL,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_drain_jobs_cluster_name` (`cluster_name`),
+  INDEX `idx_drain_jobs_scheduled_at` (`scheduled_at`),
+  INDEX `idx_drain_jobs_status` (`status`)
+);
+
+CREATE TABLE IF NOT EXISTS `cluster_groups` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `name` varchar(255) NOT NULL,
+  `description` text,
+  `selector` text,
+  `clusters` text,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_cluster_groups_name` (`name`)
+);
@@ -0,0 +1,12 @@
+DROP INDEX `idx_audit_logs_organization_id` ON `audit_logs`;
+DROP INDEX `idx_notifications_organization_id` ON `notifications`;
+DROP INDEX `idx_groups_organization_id` ON `groups`;
+DROP INDEX `idx_clusters_organization_id` ON `clusters`;
+ALTER TABLE `api_tokens` DROP COLUMN `organization_id`;
+ALTER TABLE `audit_logs` DROP COLUMN `organization_id`;
+ALTER TABLE `notifications` DROP COLUMN `organization_id`;
+ALTER TABLE `sessions` DROP COLUMN `organization_id`;
+ALTER TABLE `groups` DROP COLUMN `organization_id`;
+ALTER TABLE `clusters` DROP COLUMN `organization_id`;
+DROP TABLE `organization_members`;
+DROP TABLE `organizations`;
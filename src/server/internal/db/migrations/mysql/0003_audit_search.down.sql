@@ -0,0 +1,7 @@
+DROP INDEX `idx_audit_logs_resource_name` ON `audit_logs`;
+ALTER TABLE `audit_logs` DROP COLUMN `resource_name`;
+DROP INDEX `idx_audit_logs_namespace` ON `audit_logs`;
+ALTER TABLE `audit_logs` DROP COLUMN `namespace`;
+DROP INDEX `idx_audit_logs_cluster_name` ON `audit_logs`;
+ALTER TABLE `audit_logs` DROP COLUMN `cluster_name`;
+DROP TABLE `saved_audit_queries`;
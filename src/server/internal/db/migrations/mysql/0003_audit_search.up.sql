@@ -0,0 +1,27 @@
+CREATE TABLE `saved_audit_queries` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `organization_id` bigint unsigned,
+  `user_id` bigint unsigned NOT NULL,
+  `name` varchar(255) NOT NULL,
+  `query` text NOT NULL,
+  `shared` boolean DEFAULT false,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_saved_audit_queries_user_id` (`user_id`),
+  INDEX `idx_saved_audit_queries_organization_id` (`organization_id`)
+);
+
+ALTER TABLE `audit_logs` ADD `cluster_name` varchar(255);
+
+CREATE INDEX `idx_audit_logs_cluster_name` ON `audit_logs`(`cluster_name`);
+
+ALTER TABLE `audit_logs` ADD `namespace` varchar(255);
+
+CREATE INDEX `idx_audit_logs_namespace` ON `audit_logs`(`namespace`);
+
+ALTER TABLE `audit_logs` ADD `resource_name` varchar(255);
+
+CREATE INDEX `idx_audit_logs_resource_name` ON `audit_logs`(`resource_name`);
+
+-- data
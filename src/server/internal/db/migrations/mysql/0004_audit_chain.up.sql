@@ -0,0 +1,20 @@
+CREATE TABLE `audit_chain_heads` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `entry_id` bigint unsigned,
+  `hash` varchar(64),
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`)
+);
+
+CREATE TABLE `audit_chain_anchors` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `entry_id` bigint unsigned NOT NULL,
+  `hash` varchar(64) NOT NULL,
+  `created_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_audit_chain_anchors_entry_id` (`entry_id`)
+);
+
+ALTER TABLE `audit_logs` ADD `prev_hash` varchar(64);
+
+ALTER TABLE `audit_logs` ADD `hash` varchar(64);
@@ -0,0 +1,22 @@
+CREATE TABLE `log_archive_policies` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `cluster_name` varchar(255) NOT NULL,
+  `name` varchar(255) NOT NULL,
+  `namespace` varchar(255),
+  `label_selector` text NOT NULL,
+  `interval_minutes` bigint NOT NULL,
+  `retention_days` bigint NOT NULL,
+  `endpoint` text,
+  `bucket` varchar(255) NOT NULL,
+  `prefix` varchar(255),
+  `region` varchar(64),
+  `access_key_id` varchar(255),
+  `secret_access_key` text,
+  `enabled` boolean,
+  `last_run_at` datetime(3) NULL,
+  `last_error` text,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_log_archive_policy` (`cluster_name`,`name`)
+);
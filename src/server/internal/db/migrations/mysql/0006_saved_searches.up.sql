@@ -0,0 +1,16 @@
+CREATE TABLE `saved_searches` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `organization_id` bigint unsigned,
+  `user_id` bigint unsigned NOT NULL,
+  `name` varchar(255) NOT NULL,
+  `query` text NOT NULL,
+  `cluster` varchar(255),
+  `namespace` varchar(255),
+  `cluster_selector` varchar(1024),
+  `cluster_group` varchar(255),
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_saved_searches_organization_id` (`organization_id`),
+  INDEX `idx_saved_searches_user_id` (`user_id`)
+);
@@ -0,0 +1,19 @@
+CREATE TABLE `favorites` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `organization_id` bigint unsigned,
+  `user_id` bigint unsigned NOT NULL,
+  `cluster_name` varchar(255) NOT NULL,
+  `api_group` varchar(255),
+  `resource` varchar(255) NOT NULL,
+  `kind` varchar(255) NOT NULL,
+  `namespace` varchar(255),
+  `name` varchar(255) NOT NULL,
+  `object_key` varchar(64) NOT NULL DEFAULT '',
+  `pinned` boolean DEFAULT false,
+  `position` bigint DEFAULT 0,
+  `created_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_favorite_key` (`user_id`,`object_key`),
+  INDEX `idx_favorites_organization_id` (`organization_id`),
+  INDEX `idx_favorites_cluster_name` (`cluster_name`)
+);
@@ -0,0 +1,21 @@
+ALTER TABLE `user_sessions` ADD `recent_views_disabled` boolean DEFAULT false;
+
+CREATE TABLE `recent_views` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `organization_id` bigint unsigned,
+  `user_id` bigint unsigned NOT NULL,
+  `cluster_name` varchar(255) NOT NULL,
+  `api_group` varchar(255),
+  `resource` varchar(255) NOT NULL,
+  `kind` varchar(255) NOT NULL,
+  `namespace` varchar(255),
+  `name` varchar(255) NOT NULL,
+  `object_key` varchar(64) NOT NULL DEFAULT '',
+  `views` bigint DEFAULT 1,
+  `viewed_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_recent_view_key` (`user_id`,`object_key`),
+  INDEX `idx_recent_views_organization_id` (`organization_id`),
+  INDEX `idx_recent_views_cluster_name` (`cluster_name`),
+  INDEX `idx_recent_views_viewed_at` (`viewed_at`)
+);
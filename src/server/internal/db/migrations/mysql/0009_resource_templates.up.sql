@@ -0,0 +1,16 @@
+CREATE TABLE `resource_templates` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `organization_id` bigint unsigned,
+  `name` varchar(255) NOT NULL,
+  `description` text,
+  `category` varchar(255),
+  `manifest` text NOT NULL,
+  `parameters` text,
+  `created_by` bigint unsigned,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_resource_templates_created_by` (`created_by`),
+  INDEX `idx_resource_templates_organization_id` (`organization_id`),
+  INDEX `idx_resource_templates_category` (`category`)
+);
@@ -0,0 +1,3 @@
+ALTER TABLE `upgrade_plans` DROP COLUMN `stop_request`;
+ALTER TABLE `upgrade_plans` DROP COLUMN `heartbeat_at`;
+ALTER TABLE `upgrade_plans` DROP COLUMN `owner`;
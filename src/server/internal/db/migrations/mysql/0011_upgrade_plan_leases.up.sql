@@ -0,0 +1,5 @@
+ALTER TABLE `upgrade_plans` ADD `owner` varchar(255);
+
+ALTER TABLE `upgrade_plans` ADD `heartbeat_at` datetime(3) NULL;
+
+ALTER TABLE `upgrade_plans` ADD `stop_request` varchar(50);
@@ -0,0 +1,620 @@
+-- The schema of kubelens before versioned migrations. Tables are created only when
+-- missing: a database created before then is completed instead (see adoptTables).
+-- data
+
+CREATE TABLE IF NOT EXISTS "clusters" (
+  "id" bigserial,
+  "name" varchar(255) NOT NULL,
+  "auth_type" text NOT NULL DEFAULT 'token',
+  "auth_config" text NOT NULL,
+  "server" text,
+  "ca" text,
+  "token" text,
+  "is_default" boolean DEFAULT false,
+  "enabled" boolean DEFAULT true,
+  "impersonate" boolean DEFAULT false,
+  "status" varchar(50),
+  "source" varchar(255),
+  "labels" text,
+  "connection" text,
+  "credentials_rotated_at" timestamptz,
+  "credentials_expire_at" timestamptz,
+  "created_at" timestamptz,
+  "updated_at" timestamptz,
+  PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_clusters_name" ON "clusters" ("name");
+
+CREATE TABLE IF NOT EXISTS "users" (
+  "id" bigserial,
+  "email" varchar(255) NOT NULL,
+  "username" varchar(255) NOT NULL,
+  "password_hash" text,
+  "full_name" text,
+  "avatar_url" text,
+  "avatar_data" bytea,
+  "avatar_mime_type" varchar(50),
+  "auth_provider" text DEFAULT 'local',
+  "provider_user_id" text,
+  "external_id" text,
+  "is_active" boolean DEFAULT true,
+  "is_admin" boolean DEFAULT false,
+  "mfa_enabled" boolean DEFAULT false,
+  "mfa_enforced_at" timestamptz,
+  "token_revoked_at" timestamptz,
+  "password_changed_at" timestamptz,
+  "last_login" timestamptz,
+  "created_at" timestamptz,
+  "updated_at" timestamptz,
+  PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS "idx_users_external_id" ON "users" ("external_id");
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_users_username" ON "users" ("username");
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_users_email" ON "users" ("email");
+
+CREATE TABLE IF NOT EXISTS "groups" (
+  "id" bigserial,
+  "name" varchar(255) NOT NULL,
+  "description" text,
+  "is_system" boolean DEFAULT false,
+  "permissions" text NOT NULL,
+  "external_id" text,
+  "created_at" timestamptz,
+  "updated_at" timestamptz,
+  PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS "idx_groups_external_id" ON "groups" ("external_id");
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_groups_name" ON "groups" ("name");
+
+CREATE TABLE IF NOT EXISTS "user_groups" (
+  "user_id" bigint,
+  "group_id" bigint,
+  PRIMARY KEY ("user_id","group_id")
+);
+
+CREATE TABLE IF NOT EXISTS "sessions" (
+  "id" bigserial,
+  "user_id" bigint NOT NULL,
+  "token" varchar(255) NOT NULL,
+  "expires_at" timestamptz NOT NULL,
+  "created_at" timestamptz,
+  "ip_address" varchar(64),
+  "user_agent" text,
+  "last_seen_at" timestamptz,
+  "revoked_at" timestamptz,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_users_sessions" FOREIGN KEY ("user_id") REFERENCES "users"("id")
+);
+
+CREATE INDEX IF NOT EXISTS "idx_sessions_expires_at" ON "sessions" ("expires_at");
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_sessions_token" ON "sessions" ("token");
+
+CREATE INDEX IF NOT EXISTS "idx_sessions_user_id" ON "sessions" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "user_sessions" (
+  "id" bigserial,
+  "user_id" bigint NOT NULL,
+  "selected_cluster" text,
+  "selected_namespace" text,
+  "selected_theme" text,
+  "created_at" timestamptz,
+  "updated_at" timestamptz,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_user_sessions_user" FOREIGN KEY ("user_id") REFERENCES "users"("id")
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_user_sessions_user_id" ON "user_sessions" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "notifications" (
+  "id" bigserial,
+  "user_id" bigint NOT NULL,
+  "type" varchar(50) NOT NULL,
+  "title" varchar(255) NOT NULL,
+  "message" text NOT NULL,
+  "is_read" boolean DEFAULT false,
+  "created_at" timestamptz,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_notifications_user" FOREIGN KEY ("user_id") REFERENCES "users"("id")
+);
+
+CREATE INDEX IF NOT EXISTS "idx_notifications_created_at" ON "notifications" ("created_at");
+
+CREATE INDEX IF NOT EXISTS "idx_notifications_user_id" ON "notifications" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "audit_logs" (
+  "id" bigserial,
+  "datetime" timestamptz NOT NULL,
+  "event_type" varchar(100) NOT NULL,
+  "event_category" varchar(100) NOT NULL,
+  "level" varchar(20) NOT NULL,
+  "user_id" bigint,
+  "username" varchar(255),
+  "email" varchar(255),
+  "source_ip" varchar(45),
+  "user_agent" text,
+  "resource" varchar(255),
+  "action" varchar(255),
+  "description" text NOT NULL,
+  "metadata" text,
+  "success" boolean DEFAULT true,
+  "error_message" text,
+  "request_method" varchar(10),
+  "request_uri" text,
+  "response_code" bigint,
+  "duration_ms" bigint,
+  "session_id" varchar(255),
+  "correlation_id" varchar(255),
+  "geo_location" varchar(255),
+  "created_at" timestamptz,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_audit_logs_user" FOREIGN KEY ("user_id") REFERENCES "users"("id")
+);
+
+CREATE INDEX IF NOT EXISTS "idx_audit_logs_created_at" ON "audit_logs" ("created_at");
+
+CREATE INDEX IF NOT EXISTS "idx_audit_logs_user_id" ON "audit_logs" ("user_id");
+
+CREATE INDEX IF NOT EXISTS "idx_audit_logs_event_category" ON "audit_logs" ("event_category");
+
+CREATE INDEX IF NOT EXISTS "idx_audit_logs_event_type" ON "audit_logs" ("event_type");
+
+CREATE INDEX IF NOT EXISTS "idx_audit_logs_datetime" ON "audit_logs" ("datetime");
+
+CREATE TABLE IF NOT EXISTS "audit_settings" (
+  "id" bigserial,
+  "user_id" bigint,
+  "enabled" boolean DEFAULT true,
+  "collect_authentication" boolean DEFAULT true,
+  "collect_security" boolean DEFAULT true,
+  "collect_audit" boolean DEFAULT true,
+  "collect_system" boolean DEFAULT false,
+  "collect_info" boolean DEFAULT true,
+  "collect_warn" boolean DEFAULT true,
+  "collect_error" boolean DEFAULT true,
+  "collect_critical" boolean DEFAULT true,
+  "sampling_enabled" boolean DEFAULT false,
+  "sampling_rate" decimal DEFAULT 1,
+  "custom_retention_days" bigint,
+  "updated_at" timestamptz,
+  "updated_by" bigint,
+  PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS "idx_audit_settings_user_id" ON "audit_settings" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "mfa_secrets" (
+  "id" bigserial,
+  "user_id" bigint NOT NULL,
+  "secret" text NOT NULL,
+  "backup_codes" text,
+  "created_at" timestamptz,
+  "updated_at" timestamptz,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_users_mfa_secret" FOREIGN KEY ("user_id") REFERENCES "users"("id")
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_mfa_secrets_user_id" ON "mfa_secrets" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "cluster_metadata" (
+  "id" bigserial,
+  "cluster_name" varchar(255) NOT NULL,
+  "kube_version" varchar(50),
+  "node_count" bigint DEFAULT 0,
+  "pod_count" bigint DEFAULT 0,
+  "namespace_count" bigint DEFAULT 0,
+  "last_synced" timestamptz,
+  "created_at" timestamptz,
+  "updated_at" timestamptz,
+  PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_cluster_metadata_cluster_name" ON "cluster_metadata" ("cluster_name");
+
+CREATE TABLE IF NOT EXISTS "extension_configs" (
+  "id" bigserial,
+  "extension_name" varchar(255) NOT NULL,
+  "config_data" text NOT NULL,
+  "created_at" timestamptz,
+  "updated_at" timestamptz,
+  PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_extension_configs_extension_name" ON "extension_configs" ("extension_name");
+
+CREATE TABLE IF NOT EXISTS "system_configs" (
+  "id" bigserial,
+  "key" varchar(255) NOT NULL,
+  "value" text NOT NULL,
+  "created_at" timestamptz,
+  "updated_at" timestamptz,
+  PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_system_configs_key" ON "system_configs" ("key");
+
+CREATE TABLE IF NOT EXISTS "invitations" (
+  "id" bigserial,
+  "email" varchar(255) NOT NULL,
+  "token_hash" varchar(64) NOT NULL,
+  "group_ids" text,
+  "is_admin" boolean DEFAULT false,
+  "invited_by" bigint,
+  "expires_at" timestamptz NOT NULL,
+  "accepted_at" timestamptz,
+  "revoked_at" timestamptz,
+  "created_at" timestamptz,
+  PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS "idx_invitations_expires_at" ON "invitations" ("expires_at");
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_invitations_token_hash" ON "invitations" ("token_hash");
+
+CREATE INDEX IF NOT EXISTS "idx_invitations_email" ON "invitations" ("email");
+
+CREATE TABLE IF NOT EXISTS "api_tokens" (
+  "id" bigserial,
+  "user_id" bigint NOT NULL,
+  "name" varchar(255) NOT NULL,
+  "prefix" varchar(16),
+  "token_hash" varchar(64) NOT NULL,
+  "permissions" text,
+  "expires_at" timestamptz,
+  "last_used_at" timestamptz,
+  "last_used_ip" varchar(64),
+  "revoked_at" timestamptz,
+  "created_at" timestamptz,
+  PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS "idx_api_tokens_expires_at" ON "api_tokens" ("expires_at");
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_api_tokens_token_hash" ON "api_tokens" ("token_hash");
+
+CREATE INDEX IF NOT EXISTS "idx_api_tokens_user_id" ON "api_tokens" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "refresh_tokens" (
+  "id" bigserial,
+  "user_id" bigint NOT NULL,
+  "family_id" varchar(64) NOT NULL,
+  "token_hash" varchar(64) NOT NULL,
+  "expires_at" timestamptz NOT NULL,
+  "revoked_at" timestamptz,
+  "replaced_by" bigint,
+  "ip_address" varchar(64),
+  "user_agent" text,
+  "created_at" timestamptz,
+  PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS "idx_refresh_tokens_expires_at" ON "refresh_tokens" ("expires_at");
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_refresh_tokens_token_hash" ON "refresh_tokens" ("token_hash");
+
+CREATE INDEX IF NOT EXISTS "idx_refresh_tokens_family_id" ON "refresh_tokens" ("family_id");
+
+CREATE INDEX IF NOT EXISTS "idx_refresh_tokens_user_id" ON "refresh_tokens" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "password_history" (
+  "id" bigserial,
+  "user_id" bigint NOT NULL,
+  "password_hash" text NOT NULL,
+  "created_at" timestamptz,
+  PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS "idx_password_history_user_id" ON "password_history" ("user_id");
+
+CREATE INDEX IF NOT EXISTS "idx_password_history_created_at" ON "password_history" ("created_at");
+
+CREATE TABLE IF NOT EXISTS "password_reset_tokens" (
+  "id" bigserial,
+  "user_id" bigint NOT NULL,
+  "token_hash" varchar(64) NOT NULL,
+  "requested_by" bigint,
+  "expires_at" timestamptz NOT NULL,
+  "used_at" timestamptz,
+  "created_at" timestamptz,
+  PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS "idx_password_reset_tokens_expires_at" ON "password_reset_tokens" ("expires_at");
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_password_reset_tokens_token_hash" ON "password_reset_tokens" ("token_hash");
+
+CREATE INDEX IF NOT EXISTS "idx_password_reset_tokens_user_id" ON "password_reset_tokens" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "feature_flags" (
+  "id" bigserial,
+  "key" varchar(255) NOT NULL,
+  "description" text,
+  "enabled" boolean DEFAULT false,
+  "group_ids" text,
+  "created_at" timestamptz,
+  "updated_at" timestamptz,
+  PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_feature_flags_key" ON "feature_flags" ("key");
+
+CREATE TABLE IF NOT EXISTS "crash_reports" (
+  "id" bigserial,
+  "cluster_name" varchar(255) NOT NULL,
+  "namespace" varchar(255) NOT NULL,
+  "pod_name" varchar(255) NOT NULL,
+  "pod_uid" varchar(64),
+  "owner_kind" varchar(100),
+  "owner_name" varchar(255),
+  "container_name" varchar(255) NOT NULL,
+  "image" text,
+  "restart_count" integer,
+  "exit_code" integer,
+  "signal" integer,
+  "reason" varchar(255),
+  "termination_message" text,
+  "started_at" timestamptz,
+  "finished_at" timestamptz,
+  "node_name" varchar(255),
+  "logs" text,
+  "events" text,
+  "created_at" timestamptz,
+  PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS "idx_crash_reports_created_at" ON "crash_reports" ("created_at");
+
+CREATE INDEX IF NOT EXISTS "idx_crash_reports_pod" ON "crash_reports" ("cluster_name","namespace","pod_name");
+
+CREATE TABLE IF NOT EXISTS "upgrade_plans" (
+  "id" bigserial,
+  "cluster_name" varchar(255) NOT NULL,
+  "current_version" varchar(50),
+  "target_version" varchar(50) NOT NULL,
+  "batch_size" bigint DEFAULT 1,
+  "pause_between_batches" boolean DEFAULT true,
+  "status" varchar(50) NOT NULL,
+  "batches" text,
+  "current_batch" bigint DEFAULT 0,
+  "pre_checks" text,
+  "progress" text,
+  "error" text,
+  "created_by" bigint,
+  "started_at" timestamptz,
+  "completed_at" timestamptz,
+  "created_at" timestamptz,
+  "updated_at" timestamptz,
+  PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS "idx_upgrade_plans_status" ON "upgrade_plans" ("status");
+
+CREATE INDEX IF NOT EXISTS "idx_upgrade_plans_cluster_name" ON "upgrade_plans" ("cluster_name");
+
+CREATE TABLE IF NOT EXISTS "usage_rollups" (
+  "id" bigserial,
+  "day" varchar(10) NOT NULL,
+  "user_id" bigint NOT NULL,
+  "cluster_name" varchar(255) NOT NULL DEFAULT '',
+  "requests" bigint DEFAULT 0,
+  "mutations" bigint DEFAULT 0,
+  "shells_opened" bigint DEFAULT 0,
+  "updated_at" timestamptz,
+  PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_usage_rollup_key" ON "usage_rollups" ("day","user_id","cluster_name");
+
+CREATE TABLE IF NOT EXISTS "helm_repositories" (
+  "id" bigserial,
+  "name" varchar(255) NOT NULL,
+  "type" varchar(20) NOT NULL DEFAULT 'http',
+  "url" text NOT NULL,
+  "username" varchar(255),
+  "password" text,
+  "charts" text,
+  "chart_count" bigint DEFAULT 0,
+  "last_indexed_at" timestamptz,
+  "index_error" text,
+  "created_at" timestamptz,
+  "updated_at" timestamptz,
+  PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_helm_repositories_name" ON "helm_repositories" ("name");
+
+CREATE TABLE IF NOT EXISTS "helm_chart_versions" (
+  "id" bigserial,
+  "repository_id" bigint NOT NULL,
+  "chart" varchar(255) NOT NULL,
+  "version" varchar(100) NOT NULL,
+  "app_version" varchar(100),
+  "description" text,
+  "icon" text,
+  "urls" text,
+  "digest" varchar(100),
+  "deprecated" boolean DEFAULT false,
+  "created" timestamptz,
+  PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS "idx_helm_chart_versions_chart" ON "helm_chart_versions" ("chart");
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_helm_chart_version" ON "helm_chart_versions" ("repository_id","chart","version");
+
+CREATE TABLE IF NOT EXISTS "cluster_datasources" (
+  "id" bigserial,
+  "cluster_name" varchar(255) NOT NULL,
+  "type" varchar(20) NOT NULL,
+  "url" text NOT NULL,
+  "auth_type" varchar(20) NOT NULL DEFAULT 'none',
+  "username" varchar(255),
+  "secret" text,
+  "headers" text,
+  "insecure_skip_verify" boolean DEFAULT false,
+  "settings" text,
+  "created_at" timestamptz,
+  "updated_at" timestamptz,
+  PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_cluster_datasource" ON "cluster_datasources" ("cluster_name","type");
+
+CREATE TABLE IF NOT EXISTS "notification_channels" (
+  "id" bigserial,
+  "name" varchar(255) NOT NULL,
+  "type" varchar(20) NOT NULL,
+  "enabled" boolean DEFAULT true,
+  "config" text NOT NULL,
+  "events" text,
+  "min_severity" varchar(20),
+  "last_delivery_at" timestamptz,
+  "last_error" text,
+  "created_at" timestamptz,
+  "updated_at" timestamptz,
+  PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_notification_channels_name" ON "notification_channels" ("name");
+
+CREATE TABLE IF NOT EXISTS "alert_rules" (
+  "id" bigserial,
+  "name" varchar(255) NOT NULL,
+  "description" text,
+  "enabled" boolean DEFAULT true,
+  "cluster" varchar(255),
+  "namespace" varchar(255),
+  "condition" varchar(50) NOT NULL,
+  "threshold" bigint DEFAULT 0,
+  "for_seconds" bigint DEFAULT 0,
+  "severity" varchar(20) NOT NULL DEFAULT 'warning',
+  "created_by" bigint,
+  "created_at" timestamptz,
+  "updated_at" timestamptz,
+  PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS "idx_alert_rules_cluster" ON "alert_rules" ("cluster");
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_alert_rules_name" ON "alert_rules" ("name");
+
+CREATE TABLE IF NOT EXISTS "alerts" (
+  "id" bigserial,
+  "rule_id" bigint NOT NULL,
+  "rule_name" varchar(255),
+  "severity" varchar(20),
+  "cluster" varchar(255) NOT NULL,
+  "namespace" varchar(255),
+  "resource" varchar(512) NOT NULL,
+  "state" varchar(20) NOT NULL,
+  "message" text,
+  "active_since" timestamptz NOT NULL,
+  "last_seen_at" timestamptz,
+  "fired_at" timestamptz,
+  "resolved_at" timestamptz,
+  PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS "idx_alerts_resolved_at" ON "alerts" ("resolved_at");
+
+CREATE INDEX IF NOT EXISTS "idx_alerts_state" ON "alerts" ("state");
+
+CREATE INDEX IF NOT EXISTS "idx_alerts_cluster" ON "alerts" ("cluster");
+
+CREATE INDEX IF NOT EXISTS "idx_alerts_rule_id" ON "alerts" ("rule_id");
+
+CREATE TABLE IF NOT EXISTS "cluster_events" (
+  "id" bigserial,
+  "cluster_name" varchar(255) NOT NULL,
+  "fingerprint" varchar(64) NOT NULL,
+  "uid" varchar(64),
+  "namespace" varchar(255),
+  "kind" varchar(255),
+  "name" varchar(255),
+  "type" varchar(20),
+  "reason" varchar(255),
+  "message" text,
+  "source" varchar(255),
+  "count" integer,
+  "first_seen" timestamptz,
+  "last_seen" timestamptz,
+  PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS "idx_cluster_events_namespace" ON "cluster_events" ("namespace");
+
+CREATE INDEX IF NOT EXISTS "idx_cluster_event_seen" ON "cluster_events" ("cluster_name","last_seen");
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_cluster_event" ON "cluster_events" ("cluster_name","fingerprint");
+
+CREATE TABLE IF NOT EXISTS "benchmark_runs" (
+  "id" bigserial,
+  "cluster_name" varchar(255) NOT NULL,
+  "node" varchar(255),
+  "benchmark" varchar(50),
+  "namespace" varchar(255),
+  "job_name" varchar(255),
+  "status" varchar(20) NOT NULL,
+  "error" text,
+  "pass" bigint,
+  "fail" bigint,
+  "warn" bigint,
+  "info" bigint,
+  "sections" text,
+  "results" text,
+  "started_by" bigint,
+  "started_at" timestamptz,
+  "completed_at" timestamptz,
+  PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS "idx_benchmark_runs_started_at" ON "benchmark_runs" ("started_at");
+
+CREATE INDEX IF NOT EXISTS "idx_benchmark_runs_status" ON "benchmark_runs" ("status");
+
+CREATE INDEX IF NOT EXISTS "idx_benchmark_runs_cluster_name" ON "benchmark_runs" ("cluster_name");
+
+CREATE TABLE IF NOT EXISTS "drain_jobs" (
+  "id" bigserial,
+  "cluster_name" varchar(255) NOT NULL,
+  "nodes" text,
+  "current_node" bigint DEFAULT 0,
+  "parallelism" bigint DEFAULT 1,
+  "timeout_seconds" bigint,
+  "scheduled_at" timestamptz,
+  "window_end" timestamptz,
+  "status" varchar(50) NOT NULL,
+  "progress" text,
+  "error" text,
+  "created_by" bigint,
+  "started_at" timestamptz,
+  "completed_at" timestamptz,
+  "created_at" timestamptz,
+  "updated_at" timestamptz,
+  PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS "idx_drain_jobs_status" ON "drain_jobs" ("status");
+
+CREATE INDEX IF NOT EXISTS "idx_drain_jobs_scheduled_at" ON "drain_jobs" ("scheduled_at");
+
+CREATE INDEX IF NOT EXISTS "idx_drain_jobs_cluster_name" ON "drain_jobs" ("cluster_name");
+
+CREATE TABLE IF NOT EXISTS "cluster_groups" (
+  "id" bigserial,
+  "name" varchar(255) NOT NULL,
+  "description" text,
+  "selector" text,
+  "clusters" text,
+  "created_at" timestamptz,
+  "updated_at" timestamptz,
+  PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_cluster_groups_name" ON "cluster_groups" ("name");
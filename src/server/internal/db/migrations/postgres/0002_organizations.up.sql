@@ -0,0 +1,42 @@
+CREATE TABLE "organizations" (
+  "id" bigserial,
+  "name" varchar(63) NOT NULL,
+  "display_name" varchar(255),
+  "created_at" timestamptz,
+  "updated_at" timestamptz,
+  PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX "idx_organizations_name" ON "organizations" ("name");
+
+CREATE TABLE "organization_members" (
+  "organization_id" bigint,
+  "user_id" bigint,
+  "role" varchar(20) NOT NULL,
+  "created_at" timestamptz,
+  PRIMARY KEY ("organization_id","user_id"),
+  CONSTRAINT "fk_organization_members_organization" FOREIGN KEY ("organization_id") REFERENCES "organizations"("id"),
+  CONSTRAINT "fk_organization_members_user" FOREIGN KEY ("user_id") REFERENCES "users"("id")
+);
+
+CREATE INDEX "idx_organization_members_user_id" ON "organization_members" ("user_id");
+
+ALTER TABLE "clusters" ADD "organization_id" bigint NOT NULL DEFAULT 1;
+
+ALTER TABLE "groups" ADD "organization_id" bigint NOT NULL DEFAULT 1;
+
+ALTER TABLE "sessions" ADD "organization_id" bigint NOT NULL DEFAULT 1;
+
+ALTER TABLE "notifications" ADD "organization_id" bigint NOT NULL DEFAULT 1;
+
+ALTER TABLE "audit_logs" ADD "organization_id" bigint;
+
+ALTER TABLE "api_tokens" ADD "organization_id" bigint NOT NULL DEFAULT 1;
+
+CREATE INDEX "idx_clusters_organization_id" ON "clusters" ("organization_id");
+
+CREATE INDEX "idx_groups_organization_id" ON "groups" ("organization_id");
+
+CREATE INDEX "idx_notifications_organization_id" ON "notifications" ("organization_id");
+
+CREATE INDEX "idx_audit_logs_organization_id" ON "audit_logs" ("organization_id");
@@ -0,0 +1,9 @@
+DROP INDEX idx_audit_logs_search_vector;
+ALTER TABLE audit_logs DROP COLUMN search_vector;
+DROP INDEX "idx_audit_logs_resource_name";
+ALTER TABLE "audit_logs" DROP COLUMN "resource_name";
+DROP INDEX "idx_audit_logs_namespace";
+ALTER TABLE "audit_logs" DROP COLUMN "namespace";
+DROP INDEX "idx_audit_logs_cluster_name";
+ALTER TABLE "audit_logs" DROP COLUMN "cluster_name";
+DROP TABLE "saved_audit_queries";
@@ -0,0 +1,33 @@
+CREATE TABLE "saved_audit_queries" (
+  "id" bigserial,
+  "organization_id" bigint,
+  "user_id" bigint NOT NULL,
+  "name" varchar(255) NOT NULL,
+  "query" text NOT NULL,
+  "shared" boolean DEFAULT false,
+  "created_at" timestamptz,
+  "updated_at" timestamptz,
+  PRIMARY KEY ("id")
+);
+
+CREATE INDEX "idx_saved_audit_queries_user_id" ON "saved_audit_queries" ("user_id");
+
+CREATE INDEX "idx_saved_audit_queries_organization_id" ON "saved_audit_queries" ("organization_id");
+
+ALTER TABLE "audit_logs" ADD "cluster_name" varchar(255);
+
+CREATE INDEX "idx_audit_logs_cluster_name" ON "audit_logs" ("cluster_name");
+
+ALTER TABLE "audit_logs" ADD "namespace" varchar(255);
+
+CREATE INDEX "idx_audit_logs_namespace" ON "audit_logs" ("namespace");
+
+ALTER TABLE "audit_logs" ADD "resource_name" varchar(255);
+
+CREATE INDEX "idx_audit_logs_resource_name" ON "audit_logs" ("resource_name");
+
+-- data
+
+ALTER TABLE audit_logs ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (to_tsvector('simple', coalesce(description, '') || ' ' || coalesce(username, '') || ' ' || coalesce(email, '') || ' ' || coalesce(resource, '') || ' ' || coalesce(resource_name, '') || ' ' || coalesce(cluster_name, '') || ' ' || coalesce(namespace, '') || ' ' || coalesce(metadata, ''))) STORED;
+
+CREATE INDEX idx_audit_logs_search_vector ON audit_logs USING GIN (search_vector);
@@ -0,0 +1,4 @@
+ALTER TABLE "audit_logs" DROP COLUMN "hash";
+ALTER TABLE "audit_logs" DROP COLUMN "prev_hash";
+DROP TABLE "audit_chain_anchors";
+DROP TABLE "audit_chain_heads";
@@ -0,0 +1,21 @@
+CREATE TABLE "audit_chain_heads" (
+  "id" bigserial,
+  "entry_id" bigint,
+  "hash" varchar(64),
+  "updated_at" timestamptz,
+  PRIMARY KEY ("id")
+);
+
+CREATE TABLE "audit_chain_anchors" (
+  "id" bigserial,
+  "entry_id" bigint NOT NULL,
+  "hash" varchar(64) NOT NULL,
+  "created_at" timestamptz,
+  PRIMARY KEY ("id")
+);
+
+CREATE INDEX "idx_audit_chain_anchors_entry_id" ON "audit_chain_anchors" ("entry_id");
+
+ALTER TABLE "audit_logs" ADD "prev_hash" varchar(64);
+
+ALTER TABLE "audit_logs" ADD "hash" varchar(64);
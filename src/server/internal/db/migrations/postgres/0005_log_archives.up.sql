@@ -0,0 +1,23 @@
+CREATE TABLE "log_archive_policies" (
+  "id" bigserial,
+  "cluster_name" varchar(255) NOT NULL,
+  "name" varchar(255) NOT NULL,
+  "namespace" varchar(255),
+  "label_selector" text NOT NULL,
+  "interval_minutes" bigint NOT NULL,
+  "retention_days" bigint NOT NULL,
+  "endpoint" text,
+  "bucket" varchar(255) NOT NULL,
+  "prefix" varchar(255),
+  "region" varchar(64),
+  "access_key_id" varchar(255),
+  "secret_access_key" text,
+  "enabled" boolean,
+  "last_run_at" timestamptz,
+  "last_error" text,
+  "created_at" timestamptz,
+  "updated_at" timestamptz,
+  PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX "idx_log_archive_policy" ON "log_archive_policies" ("cluster_name","name");
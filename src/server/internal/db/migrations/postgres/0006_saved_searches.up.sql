@@ -0,0 +1,18 @@
+CREATE TABLE "saved_searches" (
+  "id" bigserial,
+  "organization_id" bigint,
+  "user_id" bigint NOT NULL,
+  "name" varchar(255) NOT NULL,
+  "query" text NOT NULL,
+  "cluster" varchar(255),
+  "namespace" varchar(255),
+  "cluster_selector" varchar(1024),
+  "cluster_group" varchar(255),
+  "created_at" timestamptz,
+  "updated_at" timestamptz,
+  PRIMARY KEY ("id")
+);
+
+CREATE INDEX "idx_saved_searches_user_id" ON "saved_searches" ("user_id");
+
+CREATE INDEX "idx_saved_searches_organization_id" ON "saved_searches" ("organization_id");
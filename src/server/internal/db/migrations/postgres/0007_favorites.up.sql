@@ -0,0 +1,22 @@
+CREATE TABLE "favorites" (
+  "id" bigserial,
+  "organization_id" bigint,
+  "user_id" bigint NOT NULL,
+  "cluster_name" varchar(255) NOT NULL,
+  "api_group" varchar(255),
+  "resource" varchar(255) NOT NULL,
+  "kind" varchar(255) NOT NULL,
+  "namespace" varchar(255),
+  "name" varchar(255) NOT NULL,
+  "object_key" varchar(64) NOT NULL DEFAULT '',
+  "pinned" boolean DEFAULT false,
+  "position" bigint DEFAULT 0,
+  "created_at" timestamptz,
+  PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX "idx_favorite_key" ON "favorites" ("user_id","object_key");
+
+CREATE INDEX "idx_favorites_organization_id" ON "favorites" ("organization_id");
+
+CREATE INDEX "idx_favorites_cluster_name" ON "favorites" ("cluster_name");
@@ -0,0 +1,2 @@
+DROP TABLE "recent_views";
+ALTER TABLE "user_sessions" DROP COLUMN "recent_views_disabled";
@@ -0,0 +1,25 @@
+ALTER TABLE "user_sessions" ADD "recent_views_disabled" boolean DEFAULT false;
+
+CREATE TABLE "recent_views" (
+  "id" bigserial,
+  "organization_id" bigint,
+  "user_id" bigint NOT NULL,
+  "cluster_name" varchar(255) NOT NULL,
+  "api_group" varchar(255),
+  "resource" varchar(255) NOT NULL,
+  "kind" varchar(255) NOT NULL,
+  "namespace" varchar(255),
+  "name" varchar(255) NOT NULL,
+  "object_key" varchar(64) NOT NULL DEFAULT '',
+  "views" bigint DEFAULT 1,
+  "viewed_at" timestamptz,
+  PRIMARY KEY ("id")
+);
+
+CREATE INDEX "idx_recent_views_viewed_at" ON "recent_views" ("viewed_at");
+
+CREATE INDEX "idx_recent_views_cluster_name" ON "recent_views" ("cluster_name");
+
+CREATE UNIQUE INDEX "idx_recent_view_key" ON "recent_views" ("user_id","object_key");
+
+CREATE INDEX "idx_recent_views_organization_id" ON "recent_views" ("organization_id");
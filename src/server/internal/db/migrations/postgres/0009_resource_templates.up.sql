@@ -0,0 +1,19 @@
+CREATE TABLE "resource_templates" (
+  "id" bigserial,
+  "organization_id" bigint,
+  "name" varchar(255) NOT NULL,
+  "description" text,
+  "category" varchar(255),
+  "manifest" text NOT NULL,
+  "parameters" text,
+  "created_by" bigint,
+  "created_at" timestamptz,
+  "updated_at" timestamptz,
+  PRIMARY KEY ("id")
+);
+
+CREATE INDEX "idx_resource_templates_created_by" ON "resource_templates" ("created_by");
+
+CREATE INDEX "idx_resource_templates_category" ON "resource_templates" ("category");
+
+CREATE INDEX "idx_resource_templates_organization_id" ON "resource_templates" ("organization_id");
@@ -0,0 +1,32 @@
+DROP TABLE `cluster_groups`;
+DROP TABLE `drain_jobs`;
+DROP TABLE `benchmark_runs`;
+DROP TABLE `cluster_events`;
+DROP TABLE `alerts`;
+DROP TABLE `alert_rules`;
+DROP TABLE `notification_channels`;
+DROP TABLE `cluster_datasources`;
+DROP TABLE `helm_chart_versions`;
+DROP TABLE `helm_repositories`;
+DROP TABLE `usage_rollups`;
+DROP TABLE `upgrade_plans`;
+DROP TABLE `crash_reports`;
+DROP TABLE `feature_flags`;
+DROP TABLE `password_reset_tokens`;
+DROP TABLE `password_history`;
+DROP TABLE `refresh_tokens`;
+DROP TABLE `api_tokens`;
+DROP TABLE `invitations`;
+DROP TABLE `system_configs`;
+DROP TABLE `extension_configs`;
+DROP TABLE `cluster_metadata`;
+DROP TABLE `mfa_secrets`;
+DROP TABLE `audit_settings`;
+DROP TABLE `audit_logs`;
+DROP TABLE `notifications`;
+DROP TABLE `user_sessions`;
+DROP TABLE `sessions`;
+DROP TABLE `user_groups`;
+DROP TABLE `groups`;
+DROP TABLE `users`;
+DROP TABLE `clusters`;
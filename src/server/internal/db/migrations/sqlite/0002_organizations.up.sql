@@ -0,0 +1,41 @@
+CREATE TABLE `organizations` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `name` varchar(63) NOT NULL,
+  `display_name` varchar(255),
+  `created_at` datetime,
+  `updated_at` datetime
+);
+
+CREATE UNIQUE INDEX `idx_organizations_name` ON `organizations`(`name`);
+
+CREATE TABLE `organization_members` (
+  `organization_id` integer,
+  `user_id` integer,
+  `role` varchar(20) NOT NULL,
+  `created_at` datetime,
+  PRIMARY KEY (`organization_id`,`user_id`),
+  CONSTRAINT `fk_organization_members_user` FOREIGN KEY (`user_id`) REFERENCES `users`(`id`),
+  CONSTRAINT `fk_organization_members_organization` FOREIGN KEY (`organization_id`) REFERENCES `organizations`(`id`)
+);
+
+CREATE INDEX `idx_organization_members_user_id` ON `organization_members`(`user_id`);
+
+ALTER TABLE `clusters` ADD `organization_id` integer NOT NULL DEFAULT 1;
+
+ALTER TABLE `groups` ADD `organization_id` integer NOT NULL DEFAULT 1;
+
+ALTER TABLE `sessions` ADD `organization_id` integer NOT NULL DEFAULT 1;
+
+ALTER TABLE `notifications` ADD `organization_id` integer NOT NULL DEFAULT 1;
+
+ALTER TABLE `audit_logs` ADD `organization_id` integer;
+
+ALTER TABLE `api_tokens` ADD `organization_id` integer NOT NULL DEFAULT 1;
+
+CREATE INDEX `idx_clusters_organization_id` ON `clusters`(`organization_id`);
+
+CREATE INDEX `idx_groups_organization_id` ON `groups`(`organization_id`);
+
+CREATE INDEX `idx_notifications_organization_id` ON `notifications`(`organization_id`);
+
+CREATE INDEX `idx_audit_logs_organization_id` ON `audit_logs`(`organization_id`);
@@ -0,0 +1,11 @@
+DROP TRIGGER audit_logs_fts_insert;
+DROP TRIGGER audit_logs_fts_delete;
+DROP TRIGGER audit_logs_fts_update;
+DROP TABLE audit_logs_fts;
+DROP INDEX `idx_audit_logs_resource_name`;
+ALTER TABLE `audit_logs` DROP COLUMN `resource_name`;
+DROP INDEX `idx_audit_logs_namespace`;
+ALTER TABLE `audit_logs` DROP COLUMN `namespace`;
+DROP INDEX `idx_audit_logs_cluster_name`;
+ALTER TABLE `audit_logs` DROP COLUMN `cluster_name`;
+DROP TABLE `saved_audit_queries`;
@@ -0,0 +1,45 @@
+CREATE TABLE `saved_audit_queries` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `organization_id` integer,
+  `user_id` integer NOT NULL,
+  `name` varchar(255) NOT NULL,
+  `query` text NOT NULL,
+  `shared` numeric DEFAULT false,
+  `created_at` datetime,
+  `updated_at` datetime
+);
+
+CREATE INDEX `idx_saved_audit_queries_user_id` ON `saved_audit_queries`(`user_id`);
+
+CREATE INDEX `idx_saved_audit_queries_organization_id` ON `saved_audit_queries`(`organization_id`);
+
+ALTER TABLE `audit_logs` ADD `cluster_name` varchar(255);
+
+CREATE INDEX `idx_audit_logs_cluster_name` ON `audit_logs`(`cluster_name`);
+
+ALTER TABLE `audit_logs` ADD `namespace` varchar(255);
+
+CREATE INDEX `idx_audit_logs_namespace` ON `audit_logs`(`namespace`);
+
+ALTER TABLE `audit_logs` ADD `resource_name` varchar(255);
+
+CREATE INDEX `idx_audit_logs_resource_name` ON `audit_logs`(`resource_name`);
+
+-- data
+
+CREATE VIRTUAL TABLE audit_logs_fts USING fts5(description, username, email, resource, resource_name, cluster_name, namespace, metadata, content='audit_logs', content_rowid='id');
+
+CREATE TRIGGER audit_logs_fts_insert AFTER INSERT ON audit_logs BEGIN
+  INSERT INTO audit_logs_fts(rowid, description, username, email, resource, resource_name, cluster_name, namespace, metadata) VALUES (new.id, new.description, new.username, new.email, new.resource, new.resource_name, new.cluster_name, new.namespace, new.metadata);
+END;
+
+CREATE TRIGGER audit_logs_fts_delete AFTER DELETE ON audit_logs BEGIN
+  INSERT INTO audit_logs_fts(audit_logs_fts, rowid, description, username, email, resource, resource_name, cluster_name, namespace, metadata) VALUES ('delete', old.id, old.description, old.username, old.email, old.resource, old.resource_name, old.cluster_name, old.namespace, old.metadata);
+END;
+
+CREATE TRIGGER audit_logs_fts_update AFTER UPDATE ON audit_logs BEGIN
+  INSERT INTO audit_logs_fts(audit_logs_fts, rowid, description, username, email, resource, resource_name, cluster_name, namespace, metadata) VALUES ('delete', old.id, old.description, old.username, old.email, old.resource, old.resource_name, old.cluster_name, old.namespace, old.metadata);
+  INSERT INTO audit_logs_fts(rowid, description, username, email, resource, resource_name, cluster_name, namespace, metadata) VALUES (new.id, new.description, new.username, new.email, new.resource, new.resource_name, new.cluster_name, new.namespace, new.metadata);
+END;
+
+INSERT INTO audit_logs_fts(audit_logs_fts) VALUES ('rebuild');
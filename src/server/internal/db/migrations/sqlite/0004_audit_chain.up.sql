@@ -0,0 +1,19 @@
+CREATE TABLE `audit_chain_heads` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `entry_id` integer,
+  `hash` varchar(64),
+  `updated_at` datetime
+);
+
+CREATE TABLE `audit_chain_anchors` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `entry_id` integer NOT NULL,
+  `hash` varchar(64) NOT NULL,
+  `created_at` datetime
+);
+
+CREATE INDEX `idx_audit_chain_anchors_entry_id` ON `audit_chain_anchors`(`entry_id`);
+
+ALTER TABLE `audit_logs` ADD `prev_hash` varchar(64);
+
+ALTER TABLE `audit_logs` ADD `hash` varchar(64);
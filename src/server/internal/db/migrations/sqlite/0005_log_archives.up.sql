@@ -0,0 +1,22 @@
+CREATE TABLE `log_archive_policies` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `cluster_name` varchar(255) NOT NULL,
+  `name` varchar(255) NOT NULL,
+  `namespace` varchar(255),
+  `label_selector` text NOT NULL,
+  `interval_minutes` integer NOT NULL,
+  `retention_days` integer NOT NULL,
+  `endpoint` text,
+  `bucket` varchar(255) NOT NULL,
+  `prefix` varchar(255),
+  `region` varchar(64),
+  `access_key_id` varchar(255),
+  `secret_access_key` text,
+  `enabled` numeric,
+  `last_run_at` datetime,
+  `last_error` text,
+  `created_at` datetime,
+  `updated_at` datetime
+);
+
+CREATE UNIQUE INDEX `idx_log_archive_policy` ON `log_archive_policies`(`cluster_name`,`name`);
@@ -0,0 +1,17 @@
+CREATE TABLE `saved_searches` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `organization_id` integer,
+  `user_id` integer NOT NULL,
+  `name` varchar(255) NOT NULL,
+  `query` text NOT NULL,
+  `cluster` varchar(255),
+  `namespace` varchar(255),
+  `cluster_selector` varchar(1024),
+  `cluster_group` varchar(255),
+  `created_at` datetime,
+  `updated_at` datetime
+);
+
+CREATE INDEX `idx_saved_searches_organization_id` ON `saved_searches`(`organization_id`);
+
+CREATE INDEX `idx_saved_searches_user_id` ON `saved_searches`(`user_id`);
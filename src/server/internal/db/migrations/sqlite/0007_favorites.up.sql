@@ -0,0 +1,21 @@
+CREATE TABLE `favorites` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `organization_id` integer,
+  `user_id` integer NOT NULL,
+  `cluster_name` varchar(255) NOT NULL,
+  `api_group` varchar(255),
+  `resource` varchar(255) NOT NULL,
+  `kind` varchar(255) NOT NULL,
+  `namespace` varchar(255),
+  `name` varchar(255) NOT NULL,
+  `object_key` varchar(64) NOT NULL DEFAULT '',
+  `pinned` numeric DEFAULT false,
+  `position` integer DEFAULT 0,
+  `created_at` datetime
+);
+
+CREATE INDEX `idx_favorites_cluster_name` ON `favorites`(`cluster_name`);
+
+CREATE UNIQUE INDEX `idx_favorite_key` ON `favorites`(`user_id`,`object_key`);
+
+CREATE INDEX `idx_favorites_organization_id` ON `favorites`(`organization_id`);
@@ -0,0 +1,24 @@
+ALTER TABLE `user_sessions` ADD `recent_views_disabled` numeric DEFAULT false;
+
+CREATE TABLE `recent_views` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `organization_id` integer,
+  `user_id` integer NOT NULL,
+  `cluster_name` varchar(255) NOT NULL,
+  `api_group` varchar(255),
+  `resource` varchar(255) NOT NULL,
+  `kind` varchar(255) NOT NULL,
+  `namespace` varchar(255),
+  `name` varchar(255) NOT NULL,
+  `object_key` varchar(64) NOT NULL DEFAULT '',
+  `views` integer DEFAULT 1,
+  `viewed_at` datetime
+);
+
+CREATE UNIQUE INDEX `idx_recent_view_key` ON `recent_views`(`user_id`,`object_key`);
+
+CREATE INDEX `idx_recent_views_organization_id` ON `recent_views`(`organization_id`);
+
+CREATE INDEX `idx_recent_views_viewed_at` ON `recent_views`(`viewed_at`);
+
+CREATE INDEX `idx_recent_views_cluster_name` ON `recent_views`(`cluster_name`);
@@ -0,0 +1,3 @@
+ALTER TABLE `drain_jobs` DROP COLUMN `stop_request`;
+ALTER TABLE `drain_jobs` DROP COLUMN `heartbeat_at`;
+ALTER TABLE `drain_jobs` DROP COLUMN `owner`;
@@ -0,0 +1,5 @@
+ALTER TABLE `drain_jobs` ADD `owner` varchar(255);
+
+ALTER TABLE `drain_jobs` ADD `heartbeat_at` datetime;
+
+ALTER TABLE `drain_jobs` ADD `stop_request` varchar(50);
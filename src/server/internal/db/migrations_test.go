@@ -0,0 +1,264 @@
+package db
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm/schema"
+)
+
+func TestMigrations(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "kubelens.db")
+
+	// A database created before versioned migrations adopts the baseline and keeps its data
+	legacy, err := OpenGorm(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, statement := range []string{
+		"CREATE TABLE `clusters` (`id` integer PRIMARY KEY AUTOINCREMENT,`name` varchar(255) NOT NULL,`auth_type` text NOT NULL DEFAULT 'token',`auth_config` text NOT NULL,`server` text,`ca` text,`token` text,`is_default` numeric DEFAULT false,`enabled` numeric DEFAULT true,`status` varchar(50),`created_at` datetime,`updated_at` datetime)",
+		"CREATE UNIQUE INDEX `idx_clusters_name` ON `clusters`(`name`)",
+		"INSERT INTO clusters (name, auth_type, auth_config) VALUES ('prod', 'token', '{}')",
+	} {
+		if err := legacy.Exec(statement).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	legacy.Close()
+
+	db, err := NewGorm(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if version, err := db.SchemaVersion(); err != nil || version != LatestSchemaVersion() {
+		t.Fatalf("SchemaVersion() = %d, %v; want %d", version, err, LatestSchemaVersion())
+	}
+	if _, err := db.GetCluster("prod"); err != nil {
+		t.Errorf("cluster lost by the baseline migration: %v", err)
+	}
+	if !db.Migrator().HasColumn(&Cluster{}, "Labels") {
+		t.Error("clusters table of the legacy database not completed by the baseline migration")
+	}
+	if org := db.ClusterOrganization("prod"); org != DefaultOrganizationID {
+		t.Errorf("cluster in organization %d after upgrading, want the default one", org)
+	}
+
+	// Down to an empty schema and back up
+	if err := db.MigrateTo(0); err != nil {
+		t.Fatalf("MigrateTo(0) = %v", err)
+	}
+	if db.Migrator().HasTable(&Cluster{}) {
+		t.Error("clusters table still exists after migrating down to 0")
+	}
+	if err := db.MigrateTo(2); err != nil {
+		t.Fatalf("MigrateTo(2) = %v", err)
+	}
+	if err := db.Exec(`INSERT INTO audit_logs (datetime, event_type, event_category, level, description, metadata, request_method, action)
+		VALUES (?, 'audit_resource_deleted', 'audit', 'INFO', 'Deleted pod', '{"cluster_name":"prod","namespace":"default","kind":"Pod","name":"web-1"}', 'DELETE', '')`,
+		time.Now()).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Migrate(); err != nil {
+		t.Fatalf("Migrate() = %v", err)
+	}
+	var entry AuditLog
+	if err := db.First(&entry).Error; err != nil || entry.ClusterName != "prod" || entry.ResourceName != "web-1" || entry.Action != "delete" {
+		t.Errorf("audit log after the audit_search migration = %+v, %v; want its search columns filled", entry, err)
+	}
+	if !db.Migrator().HasTable(&ClusterGroup{}) {
+		t.Error("cluster_groups table missing after migrating up")
+	}
+
+	if db.Migrator().HasIndex(&Favorite{}, "idx_favorite_object") || !db.Migrator().HasIndex(&Favorite{}, "idx_favorite_key") {
+		t.Error("favorites not indexed by their object key after migrating up")
+	}
+	if db.Migrator().HasIndex(&RecentView{}, "idx_recent_view_object") || !db.Migrator().HasIndex(&RecentView{}, "idx_recent_view_key") {
+		t.Error("recent views not indexed by their object key after migrating up")
+	}
+
+	// A schema written by a newer build is not touched
+	if err := db.Create(&SchemaMigration{Version: LatestSchemaVersion() + 1, Name: "future"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Migrate(); err == nil {
+		t.Error("Migrate() of a newer schema succeeded, want an error")
+	}
+}
+
+// schemaModels are the models stored in tables created by the migrations
+var schemaModels = []interface{}{
+	&Cluster{}, &User{}, &Group{}, &UserGroup{}, &Session{}, &UserSession{}, &Notification{},
+	&AuditLog{}, &AuditSettings{}, &MFASecret{}, &ClusterMetadata{}, &ExtensionConfig{},
+	&SystemConfig{}, &Invitation{}, &APIToken{}, &RefreshToken{}, &PasswordHistory{},
+	&PasswordResetToken{}, &FeatureFlag{}, &CrashReport{}, &UpgradePlan{}, &UsageRollup{},
+	&HelmRepository{}, &HelmChartVersion{}, &ClusterDatasource{}, &NotificationChannel{},
+	&AlertRule{}, &Alert{}, &ClusterEvent{}, &BenchmarkRun{}, &DrainJob{}, &ClusterGroup{},
+	&Organization{}, &OrganizationMember{}, &SavedAuditQuery{}, &AuditChainHead{},
+	&AuditChainAnchor{}, &LogArchivePolicy{}, &SavedSearch{}, &Favorite{}, &RecentView{},
+	&ResourceTemplate{},
+}
+
+// TestMigrationsMatchModels checks that the migrated schema has a column for every field
+// of the models, so that a model change without a migration is caught
+func TestMigrationsMatchModels(t *testing.T) {
+	db, err := NewGorm(filepath.Join(t.TempDir(), "kubelens.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	for _, model := range schemaModels {
+		s, err := schema.Parse(model, &sync.Map{}, schema.NamingStrategy{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !db.Migrator().HasTable(s.Table) {
+			t.Errorf("table %s is not created by the migrations", s.Table)
+			continue
+		}
+		for _, field := range s.Fields {
+			if field.DBName != "" && !field.IgnoreMigration && !db.Migrator().HasColumn(s.Table, field.DBName) {
+				t.Errorf("column %s.%s is not created by the migrations", s.Table, field.DBName)
+			}
+		}
+	}
+}
+
+func TestSplitStatements(t *testing.T) {
+	script := `-- comment
+CREATE TABLE a (
+  id integer
+);
+-- data
+CREATE TRIGGER t AFTER INSERT ON a BEGIN
+  INSERT INTO b VALUES (new.id);
+END;
+DROP TABLE c;
+`
+	want := []string{
+		"CREATE TABLE a (\n  id integer\n)",
+		dataMarker,
+		"CREATE TRIGGER t AFTER INSERT ON a BEGIN\n  INSERT INTO b VALUES (new.id);\nEND",
+		"DROP TABLE c",
+	}
+	got := splitStatements(script)
+	if len(got) != len(want) {
+		t.Fatalf("splitStatements() = %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("statement %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+// TestMigrationScripts checks that every migration has both scripts for every dialect
+func TestMigrationScripts(t *testing.T) {
+	for _, dialect := range []string{"sqlite", "postgres", "mysql"} {
+		for _, m := range migrations {
+			for _, direction := range []string{"up", "down"} {
+				if statements, err := m.script(dialect, direction); err != nil || len(statements) == 0 {
+					t.Errorf("migration %d (%s): %s script for %s = %d statements, %v", m.Version, m.Name, direction, dialect, len(statements), err)
+				}
+			}
+		}
+	}
+}
+
+// mysqlMaxKeyBytes is the longest index key InnoDB accepts
+const mysqlMaxKeyBytes = 3072
+
+var sizedType = regexp.MustCompile(`^(?:var)?char\((\d+)\)`)
+
+// mysqlKeyBytes is the most bytes a column of a MySQL type takes in an index key under
+// utf8mb4, -1 for types that cannot be indexed without a prefix length
+func mysqlKeyBytes(dataType string) int {
+	dataType = strings.ToLower(dataType)
+	if m := sizedType.FindStringSubmatch(dataType); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		return 4 * n
+	}
+	switch {
+	case strings.Contains(dataType, "text"), strings.Contains(dataType, "blob"), strings.HasPrefix(dataType, "json"):
+		return -1
+	case strings.HasPrefix(dataType, "tinyint"), strings.HasPrefix(dataType, "boolean"):
+		return 1
+	case strings.HasPrefix(dataType, "int"):
+		return 4
+	default: // bigint, double, datetime
+		return 8
+	}
+}
+
+// TestMySQLIndexKeys checks that every index of the schema fits MySQL's key length
+func TestMySQLIndexKeys(t *testing.T) {
+	precision := 3
+	dialector := mysql.Dialector{Config: &mysql.Config{DefaultDatetimePrecision: &precision}}
+	for _, model := range schemaModels {
+		s, err := schema.Parse(model, &sync.Map{}, schema.NamingStrategy{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, index := range s.ParseIndexes() {
+			total := 0
+			for _, option := range index.Fields {
+				bytes := mysqlKeyBytes(dialector.DataTypeOf(option.Field))
+				if bytes < 0 {
+					t.Errorf("%s.%s: column %s of type %s cannot be indexed", s.Table, index.Name, option.DBName, dialector.DataTypeOf(option.Field))
+				}
+				total += bytes
+			}
+			if total > mysqlMaxKeyBytes {
+				t.Errorf("%s.%s: key of up to %d bytes, MySQL allows %d", s.Table, index.Name, total, mysqlMaxKeyBytes)
+			}
+		}
+	}
+}
+
+// TestMigrationsMySQL runs the migrations on the empty MySQL database of
+// KUBELENS_TEST_MYSQL_DSN, e.g. "root:secret@tcp(127.0.0.1:3306)/kubelens_test?charset=utf8mb4&parseTime=True"
+func TestMigrationsMySQL(t *testing.T) {
+	dsn := os.Getenv("KUBELENS_TEST_MYSQL_DSN")
+	if dsn == "" {
+		t.Skip("KUBELENS_TEST_MYSQL_DSN is not set")
+	}
+	db, err := NewGorm(dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	defer func() {
+		if err := db.MigrateTo(0); err != nil {
+			t.Errorf("MigrateTo(0) = %v", err)
+		}
+	}()
+	if version, err := db.SchemaVersion(); err != nil || version != LatestSchemaVersion() {
+		t.Fatalf("SchemaVersion() = %d, %v; want %d", version, err, LatestSchemaVersion())
+	}
+
+	favorite := func() *Favorite {
+		return &Favorite{UserID: 1, ClusterName: "prod", Resource: "deployments", Kind: "Deployment", Namespace: "shop", Name: "web"}
+	}
+	if err := db.CreateFavorite(favorite()); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.CreateFavorite(favorite()); err == nil {
+		t.Error("second favorite of the same object created, want a unique index violation")
+	}
+	view := &RecentView{UserID: 1, ClusterName: "prod", Resource: "deployments", Kind: "Deployment", Namespace: "shop", Name: "web", ViewedAt: time.Now()}
+	if err := db.Create(view).Error; err != nil {
+		t.Fatal(err)
+	}
+	view.ID = 0
+	if err := db.Create(view).Error; err == nil {
+		t.Error("second recent view of the same object created, want a unique index violation")
+	}
+}
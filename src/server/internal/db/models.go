@@ -13,6 +13,12 @@ type AuthType string
 const (
 	AuthTypeToken      AuthType = "token"
 	AuthTypeKubeconfig AuthType = "kubeconfig"
+	AuthTypeExec       AuthType = "exec"
+	AuthTypeEKS        AuthType = "eks"
+	AuthTypeGKE        AuthType = "gke"
+	AuthTypeAKS        AuthType = "aks"
+	AuthTypeInCluster  AuthType = "in_cluster" // ServiceAccount of the kubelens pod
+	AuthTypeAgent      AuthType = "agent"      // reverse tunnel opened by an in-cluster agent
 )
 
 // =============================================================================
@@ -30,9 +36,26 @@ type Cluster struct {
 	Token     string    `gorm:"type:text" json:"token,omitempty"`
 	IsDefault bool      `gorm:"default:false;column:is_default" json:"is_default"`
 	Enabled   bool      `gorm:"default:true" json:"enabled"`
+	// Impersonate makes API calls as the kubelens user (Impersonate-User/Group) instead of the cluster credentials
+	Impersonate bool    `gorm:"default:false" json:"impersonate"`
 	Status    string    `gorm:"type:varchar(50)" json:"status"`
+	// Source is where an automatically registered cluster comes from (e.g. kubeconfig_dir:prod.yaml); empty for clusters added through the API
+	Source    string    `gorm:"type:varchar(255)" json:"source,omitempty"`
+	// OrganizationID is the organization owning the cluster; cluster names stay unique across organizations
+	OrganizationID uint `gorm:"not null;default:1;index" json:"organization_id"`
+	// Labels are user-defined key/value pairs (env=prod, region=eu) as a JSON object
+	Labels    JSON      `gorm:"type:text" json:"labels,omitempty"`
+	// Connection holds the proxy and TLS overrides of the cluster (ConnectionOptions)
+	Connection JSON     `gorm:"type:text" json:"connection,omitempty"`
+	// CredentialsRotatedAt and CredentialsExpireAt describe the kubelens ServiceAccount token
+	// minted by the last credential rotation; nil for credentials kubelens never rotated
+	CredentialsRotatedAt *time.Time `json:"credentials_rotated_at,omitempty"`
+	CredentialsExpireAt  *time.Time `json:"credentials_expire_at,omitempty"`
 	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+
+	// stored holds the credentials moved to the credential store while the row is saved
+	stored *storedCredentials
 }
 
 // TableName overrides the table name used by Cluster to `clusters`
@@ -40,6 +63,15 @@ func (Cluster) TableName() string {
 	return "clusters"
 }
 
+// LabelSet returns the labels of a cluster; unreadable labels count as none
+func (c *Cluster) LabelSet() map[string]string {
+	labels := map[string]string{}
+	if len(c.Labels) > 0 {
+		json.Unmarshal(c.Labels, &labels)
+	}
+	return labels
+}
+
 // User represents a user account
 type User struct {
 	ID              uint       `gorm:"primaryKey" json:"id"`
@@ -52,11 +84,13 @@ type User struct {
 	AvatarMimeType  string     `gorm:"column:avatar_mime_type;type:varchar(50)" json:"-"`       // MIME type of cached avatar
 	AuthProvider    string     `gorm:"default:'local';column:auth_provider" json:"auth_provider"`
 	ProviderUserID  string     `gorm:"column:provider_user_id" json:"provider_user_id,omitempty"`
+	ExternalID      string     `gorm:"column:external_id;index" json:"external_id,omitempty"`   // ID in the identity provider that provisions the user over SCIM
 	IsActive        bool       `gorm:"default:true;column:is_active" json:"is_active"`
 	IsAdmin         bool       `gorm:"default:false;column:is_admin" json:"is_admin"`
 	MFAEnabled      bool       `gorm:"default:false;column:mfa_enabled" json:"mfa_enabled"`
 	MFAEnforcedAt   *time.Time `gorm:"column:mfa_enforced_at" json:"mfa_enforced_at,omitempty"`
 	TokenRevokedAt  *time.Time `gorm:"column:token_revoked_at" json:"-"`                        // All tokens issued before this time are invalid
+	PasswordChangedAt *time.Time `gorm:"column:password_changed_at" json:"password_changed_at,omitempty"` // Used to expire local passwords
 	LastLogin       *time.Time `gorm:"column:last_login" json:"last_login,omitempty"`
 	CreatedAt       time.Time  `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt       time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
@@ -79,6 +113,8 @@ type Group struct {
 	Description string    `gorm:"type:text" json:"description,omitempty"`
 	IsSystem    bool      `gorm:"column:is_system;default:false" json:"is_system"`
 	Permissions JSON      `gorm:"type:text;not null" json:"permissions"` // JSON array
+	ExternalID  string    `gorm:"column:external_id;index" json:"external_id,omitempty"` // ID in the identity provider that provisions the group over SCIM
+	OrganizationID uint   `gorm:"not null;default:1;index" json:"organization_id"`       // Its permissions apply in this organization only
 	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt   time.Time `gorm:"autoUpdateTime" json:"updated_at"`
 
@@ -106,10 +142,19 @@ func (UserGroup) TableName() string {
 type Session struct {
 	ID        uint      `gorm:"primaryKey" json:"id"`
 	UserID    uint      `gorm:"not null;index" json:"user_id"`
-	Token     string    `gorm:"type:varchar(255);uniqueIndex;not null" json:"token"`
+	Token     string    `gorm:"type:varchar(255);uniqueIndex;not null" json:"-"` // Refresh token family of the sign-in
 	ExpiresAt time.Time `gorm:"not null;index" json:"expires_at"`
 	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
 
+	// Device and activity, shown in the session list
+	IPAddress  string     `gorm:"type:varchar(64);column:ip_address" json:"ip_address"`
+	UserAgent  string     `gorm:"type:text;column:user_agent" json:"user_agent"`
+	LastSeenAt *time.Time `gorm:"column:last_seen_at" json:"last_seen_at,omitempty"`
+	RevokedAt  *time.Time `gorm:"column:revoked_at" json:"revoked_at,omitempty"`
+
+	// OrganizationID is the organization the sign-in works in, switched by the user
+	OrganizationID uint `gorm:"not null;default:1" json:"organization_id"`
+
 	// Relationships
 	User User `gorm:"foreignKey:UserID" json:"-"`
 }
@@ -119,15 +164,16 @@ func (Session) TableName() string {
 	return "sessions"
 }
 
-// UserSession stores user preferences (selected cluster, namespace, theme)
+// UserSession stores user preferences (selected cluster, namespace, theme, privacy)
 type UserSession struct {
-	ID                uint      `gorm:"primaryKey" json:"id"`
-	UserID            uint      `gorm:"uniqueIndex;not null" json:"user_id"`
-	SelectedCluster   string    `gorm:"column:selected_cluster" json:"selected_cluster,omitempty"`
-	SelectedNamespace string    `gorm:"column:selected_namespace" json:"selected_namespace,omitempty"`
-	SelectedTheme     string    `gorm:"column:selected_theme" json:"selected_theme,omitempty"`
-	CreatedAt         time.Time `gorm:"autoCreateTime" json:"created_at"`
-	UpdatedAt         time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+	ID                  uint      `gorm:"primaryKey" json:"id"`
+	UserID              uint      `gorm:"uniqueIndex;not null" json:"user_id"`
+	SelectedCluster     string    `gorm:"column:selected_cluster" json:"selected_cluster,omitempty"`
+	SelectedNamespace   string    `gorm:"column:selected_namespace" json:"selected_namespace,omitempty"`
+	SelectedTheme       string    `gorm:"column:selected_theme" json:"selected_theme,omitempty"`
+	RecentViewsDisabled bool      `gorm:"column:recent_views_disabled;default:false" json:"recent_views_disabled"` // stops recording the resources the user views
+	CreatedAt           time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt           time.Time `gorm:"autoUpdateTime" json:"updated_at"`
 
 	// Relationships
 	User User `gorm:"foreignKey:UserID" json:"-"`
@@ -146,6 +192,7 @@ type Notification struct {
 	Title     string    `gorm:"type:varchar(255);not null" json:"title"`
 	Message   string    `gorm:"type:text;not null" json:"message"`
 	IsRead    bool      `gorm:"default:false;column:is_read" json:"is_read"`
+	OrganizationID uint `gorm:"not null;default:1;index" json:"organization_id"`
 	CreatedAt time.Time `gorm:"autoCreateTime;index" json:"created_at"`
 
 	// Relationships
@@ -171,6 +218,9 @@ type AuditLog struct {
 	UserAgent      string     `gorm:"type:text;column:user_agent" json:"user_agent,omitempty"`
 	Resource       string     `gorm:"type:varchar(255)" json:"resource,omitempty"`
 	Action         string     `gorm:"type:varchar(255)" json:"action,omitempty"`
+	ClusterName    string     `gorm:"type:varchar(255);index" json:"cluster_name,omitempty"`
+	Namespace      string     `gorm:"type:varchar(255);index" json:"namespace,omitempty"`
+	ResourceName   string     `gorm:"type:varchar(255);index" json:"resource_name,omitempty"`
 	Description    string     `gorm:"type:text;not null" json:"description"`
 	Metadata       string     `gorm:"type:text" json:"metadata,omitempty"` // JSON blob
 	Success        bool       `gorm:"default:true" json:"success"`
@@ -182,6 +232,9 @@ type AuditLog struct {
 	SessionID      string     `gorm:"type:varchar(255);column:session_id" json:"session_id,omitempty"`
 	CorrelationID  string     `gorm:"type:varchar(255);column:correlation_id" json:"correlation_id,omitempty"`
 	GeoLocation    string     `gorm:"type:varchar(255);column:geo_location" json:"geo_location,omitempty"`
+	OrganizationID *uint      `gorm:"index" json:"organization_id,omitempty"` // nil for events outside any organization, such as sign-ins
+	PrevHash       string     `gorm:"type:varchar(64);column:prev_hash" json:"prev_hash,omitempty"` // hash chain, when enabled (see ChainContent)
+	Hash           string     `gorm:"type:varchar(64)" json:"hash,omitempty"`
 	CreatedAt      time.Time  `gorm:"autoCreateTime;index" json:"created_at"`
 
 	// Relationships
@@ -193,6 +246,109 @@ func (AuditLog) TableName() string {
 	return "audit_logs"
 }
 
+// SavedAuditQuery is a named set of audit log filters
+type SavedAuditQuery struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	OrganizationID *uint     `gorm:"index" json:"organization_id,omitempty"`
+	UserID         uint      `gorm:"not null;index" json:"user_id"`
+	Name           string    `gorm:"type:varchar(255);not null" json:"name"`
+	Query          string    `gorm:"type:text;not null" json:"query"` // the filters of ListAuditLogs as a URL query, e.g. cluster_name=prod&verb=delete
+	Shared         bool      `gorm:"default:false" json:"shared"`     // visible to every member of the organization
+	CreatedAt      time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt      time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName overrides the table name
+func (SavedAuditQuery) TableName() string {
+	return "saved_audit_queries"
+}
+
+// SavedSearch is a named global search of a user: a search query and the clusters and
+// namespace it is narrowed to
+type SavedSearch struct {
+	ID              uint      `gorm:"primaryKey" json:"id"`
+	OrganizationID  *uint     `gorm:"index" json:"organization_id,omitempty"`
+	UserID          uint      `gorm:"not null;index" json:"user_id"`
+	Name            string    `gorm:"type:varchar(255);not null" json:"name"`
+	Query           string    `gorm:"type:text;not null" json:"query"` // the q of GET /api/v1/search, e.g. kind:pod status:CrashLoopBackOff
+	Cluster         string    `gorm:"type:varchar(255)" json:"cluster,omitempty"`
+	Namespace       string    `gorm:"type:varchar(255)" json:"namespace,omitempty"`
+	ClusterSelector string    `gorm:"type:varchar(1024)" json:"cluster_selector,omitempty"`
+	ClusterGroup    string    `gorm:"type:varchar(255)" json:"cluster_group,omitempty"`
+	CreatedAt       time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt       time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName overrides the table name
+func (SavedSearch) TableName() string {
+	return "saved_searches"
+}
+
+// Favorite is a resource a user starred: a cluster, kind, namespace and name. Pinned
+// favorites are shown, with their live health, on the dashboard.
+type Favorite struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	OrganizationID *uint     `gorm:"index" json:"organization_id,omitempty"`
+	UserID         uint      `gorm:"not null;uniqueIndex:idx_favorite_key,priority:1" json:"user_id"`
+	ObjectKey      string    `gorm:"type:varchar(64);not null;default:'';uniqueIndex:idx_favorite_key,priority:2" json:"-"` // see objectKey
+	ClusterName    string    `gorm:"type:varchar(255);not null;index" json:"cluster"`
+	Group          string    `gorm:"column:api_group;type:varchar(255)" json:"group,omitempty"`
+	Resource       string    `gorm:"type:varchar(255);not null" json:"resource"` // plural, e.g. deployments
+	Kind           string    `gorm:"type:varchar(255);not null" json:"kind"`
+	Namespace      string    `gorm:"type:varchar(255)" json:"namespace,omitempty"`
+	Name           string    `gorm:"type:varchar(255);not null" json:"name"`
+	Pinned         bool      `gorm:"default:false" json:"pinned"`
+	Position       int       `gorm:"default:0" json:"position"` // order on the dashboard, lowest first
+	CreatedAt      time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName overrides the table name
+func (Favorite) TableName() string {
+	return "favorites"
+}
+
+// RecentView is a resource a user viewed, with when they last did. Each user keeps their
+// last MaxRecentViews distinct resources, the oldest view making way for the newest.
+type RecentView struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	OrganizationID *uint     `gorm:"index" json:"organization_id,omitempty"`
+	UserID         uint      `gorm:"not null;uniqueIndex:idx_recent_view_key,priority:1" json:"-"`
+	ObjectKey      string    `gorm:"type:varchar(64);not null;default:'';uniqueIndex:idx_recent_view_key,priority:2" json:"-"` // see objectKey
+	ClusterName    string    `gorm:"type:varchar(255);not null;index" json:"cluster"`
+	Group          string    `gorm:"column:api_group;type:varchar(255)" json:"group,omitempty"`
+	Resource       string    `gorm:"type:varchar(255);not null" json:"resource"` // plural, e.g. deployments
+	Kind           string    `gorm:"type:varchar(255);not null" json:"kind"`
+	Namespace      string    `gorm:"type:varchar(255)" json:"namespace,omitempty"`
+	Name           string    `gorm:"type:varchar(255);not null" json:"name"`
+	Views          int       `gorm:"default:1" json:"views"`
+	ViewedAt       time.Time `gorm:"index" json:"viewed_at"`
+}
+
+// TableName overrides the table name
+func (RecentView) TableName() string {
+	return "recent_views"
+}
+
+// ResourceTemplate is a parameterized manifest admins provide for users to create
+// resources from, such as a Deployment with its Service and Ingress
+type ResourceTemplate struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	OrganizationID *uint     `gorm:"index" json:"organization_id,omitempty"`
+	Name           string    `gorm:"type:varchar(255);not null" json:"name"`
+	Description    string    `gorm:"type:text" json:"description,omitempty"`
+	Category       string    `gorm:"type:varchar(255);index" json:"category,omitempty"` // e.g. web, worker, database
+	Manifest       string    `gorm:"type:text;not null" json:"manifest"`                // Go text/template of a multi-document YAML, see package templates
+	Parameters     JSON      `gorm:"type:text" json:"parameters"`                       // JSON array of templates.Parameter
+	CreatedBy      uint      `gorm:"index" json:"created_by"`
+	CreatedAt      time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt      time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName overrides the table name
+func (ResourceTemplate) TableName() string {
+	return "resource_templates"
+}
+
 // AuditLogEntry is an alias for backward compatibility
 type AuditLogEntry = AuditLog
 
@@ -404,6 +560,307 @@ func (MFASecret) TableName() string {
 	return "mfa_secrets"
 }
 
+// Invitation represents a one-time invitation for a new local user
+type Invitation struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	Email      string     `gorm:"type:varchar(255);not null;index" json:"email"`
+	TokenHash  string     `gorm:"type:varchar(64);uniqueIndex;not null;column:token_hash" json:"-"` // SHA-256 of the invitation token
+	GroupIDs   JSON       `gorm:"type:text;column:group_ids" json:"group_ids"`                       // JSON array of group IDs
+	IsAdmin    bool       `gorm:"default:false;column:is_admin" json:"is_admin"`
+	InvitedBy  uint       `gorm:"column:invited_by" json:"invited_by"`
+	ExpiresAt  time.Time  `gorm:"not null;index" json:"expires_at"`
+	AcceptedAt *time.Time `gorm:"column:accepted_at" json:"accepted_at,omitempty"`
+	RevokedAt  *time.Time `gorm:"column:revoked_at" json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName overrides the table name
+func (Invitation) TableName() string {
+	return "invitations"
+}
+
+// APIToken is a long-lived personal access token used as a Bearer token by scripts and CI.
+// Only the SHA-256 of the token is stored; Prefix identifies it in listings.
+type APIToken struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	UserID      uint       `gorm:"not null;index;column:user_id" json:"user_id"`
+	Name        string     `gorm:"type:varchar(255);not null" json:"name"`
+	Prefix      string     `gorm:"type:varchar(16);column:prefix" json:"prefix"`
+	TokenHash   string     `gorm:"type:varchar(64);uniqueIndex;not null;column:token_hash" json:"-"`
+	Permissions JSON       `gorm:"type:text" json:"permissions"` // JSON array of Permission; empty = same as the owner
+	ExpiresAt   *time.Time `gorm:"column:expires_at;index" json:"expires_at,omitempty"`
+	LastUsedAt  *time.Time `gorm:"column:last_used_at" json:"last_used_at,omitempty"`
+	LastUsedIP  string     `gorm:"type:varchar(64);column:last_used_ip" json:"last_used_ip,omitempty"`
+	RevokedAt   *time.Time `gorm:"column:revoked_at" json:"revoked_at,omitempty"`
+	OrganizationID uint    `gorm:"not null;default:1" json:"organization_id"` // The organization the token acts in
+	CreatedAt   time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName overrides the table name
+func (APIToken) TableName() string {
+	return "api_tokens"
+}
+
+// RefreshToken lets a client obtain new short-lived access tokens without signing in again.
+// Each use rotates it: the token is revoked and replaced by a new one in the same family,
+// so a revoked token presented again reveals theft and revokes the whole family.
+// Only the SHA-256 of the token is stored.
+type RefreshToken struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	UserID     uint       `gorm:"not null;index;column:user_id" json:"user_id"`
+	FamilyID   string     `gorm:"type:varchar(64);not null;index;column:family_id" json:"family_id"`
+	TokenHash  string     `gorm:"type:varchar(64);uniqueIndex;not null;column:token_hash" json:"-"`
+	ExpiresAt  time.Time  `gorm:"not null;index;column:expires_at" json:"expires_at"`
+	RevokedAt  *time.Time `gorm:"column:revoked_at" json:"revoked_at,omitempty"`
+	ReplacedBy *uint      `gorm:"column:replaced_by" json:"replaced_by,omitempty"`
+	IPAddress  string     `gorm:"type:varchar(64);column:ip_address" json:"ip_address"`
+	UserAgent  string     `gorm:"type:text;column:user_agent" json:"user_agent"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName overrides the table name
+func (RefreshToken) TableName() string {
+	return "refresh_tokens"
+}
+
+// PasswordHistory keeps the hashes of a user's previous passwords so the password policy
+// can prevent their reuse
+type PasswordHistory struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	UserID       uint      `gorm:"not null;index;column:user_id" json:"user_id"`
+	PasswordHash string    `gorm:"not null;column:password_hash" json:"-"`
+	CreatedAt    time.Time `gorm:"autoCreateTime;index" json:"created_at"`
+}
+
+// TableName overrides the table name
+func (PasswordHistory) TableName() string {
+	return "password_history"
+}
+
+// PasswordResetToken is a single-use link, emailed to a local user by an admin, to choose a
+// new password
+type PasswordResetToken struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	UserID      uint       `gorm:"not null;index;column:user_id" json:"user_id"`
+	TokenHash   string     `gorm:"type:varchar(64);uniqueIndex;not null;column:token_hash" json:"-"` // SHA-256 of the token
+	RequestedBy uint       `gorm:"column:requested_by" json:"requested_by"`
+	ExpiresAt   time.Time  `gorm:"not null;index" json:"expires_at"`
+	UsedAt      *time.Time `gorm:"column:used_at" json:"used_at,omitempty"`
+	CreatedAt   time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName overrides the table name
+func (PasswordResetToken) TableName() string {
+	return "password_reset_tokens"
+}
+
+// FeatureFlag gates experimental server capabilities so they can be rolled out gradually
+type FeatureFlag struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	Key         string    `gorm:"type:varchar(255);uniqueIndex;not null" json:"key"`
+	Description string    `gorm:"type:text" json:"description,omitempty"`
+	Enabled     bool      `gorm:"default:false" json:"enabled"`
+	GroupIDs    JSON      `gorm:"type:text;column:group_ids" json:"group_ids"` // JSON array; empty means all users
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName overrides the table name
+func (FeatureFlag) TableName() string {
+	return "feature_flags"
+}
+
+// TargetGroupIDs returns the groups the flag is restricted to (empty means everyone)
+func (f *FeatureFlag) TargetGroupIDs() []uint {
+	var ids []uint
+	if len(f.GroupIDs) > 0 {
+		_ = json.Unmarshal([]byte(f.GroupIDs), &ids)
+	}
+	return ids
+}
+
+// IsEnabledFor reports whether the flag is on for a member of the given groups
+func (f *FeatureFlag) IsEnabledFor(userGroupIDs []uint) bool {
+	if !f.Enabled {
+		return false
+	}
+	targets := f.TargetGroupIDs()
+	if len(targets) == 0 {
+		return true
+	}
+	for _, target := range targets {
+		for _, id := range userGroupIDs {
+			if id == target {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// CrashReport captures the state of a crash-looping container at the time it was detected,
+// so it can be inspected after the pod is recreated or deleted
+type CrashReport struct {
+	ID                 uint       `gorm:"primaryKey" json:"id"`
+	ClusterName        string     `gorm:"type:varchar(255);not null;index:idx_crash_reports_pod;column:cluster_name" json:"cluster_name"`
+	Namespace          string     `gorm:"type:varchar(255);not null;index:idx_crash_reports_pod" json:"namespace"`
+	PodName            string     `gorm:"type:varchar(255);not null;index:idx_crash_reports_pod;column:pod_name" json:"pod_name"`
+	PodUID             string     `gorm:"type:varchar(64);column:pod_uid" json:"pod_uid"`
+	OwnerKind          string     `gorm:"type:varchar(100);column:owner_kind" json:"owner_kind,omitempty"`
+	OwnerName          string     `gorm:"type:varchar(255);column:owner_name" json:"owner_name,omitempty"`
+	ContainerName      string     `gorm:"type:varchar(255);not null;column:container_name" json:"container_name"`
+	Image              string     `gorm:"type:text" json:"image"`
+	RestartCount       int32      `gorm:"column:restart_count" json:"restart_count"`
+	ExitCode           int32      `gorm:"column:exit_code" json:"exit_code"`
+	Signal             int32      `gorm:"column:signal" json:"signal,omitempty"`
+	Reason             string     `gorm:"type:varchar(255)" json:"reason"`
+	TerminationMessage string     `gorm:"type:text;column:termination_message" json:"termination_message,omitempty"`
+	StartedAt          *time.Time `gorm:"column:started_at" json:"started_at,omitempty"`
+	FinishedAt         *time.Time `gorm:"column:finished_at" json:"finished_at,omitempty"`
+	NodeName           string     `gorm:"type:varchar(255);column:node_name" json:"node_name,omitempty"`
+	Logs               string     `gorm:"type:text" json:"logs,omitempty"`
+	Events             JSON       `gorm:"type:text" json:"events"` // JSON array of related events
+	CreatedAt          time.Time  `gorm:"autoCreateTime;index" json:"created_at"`
+}
+
+// TableName overrides the table name
+func (CrashReport) TableName() string {
+	return "crash_reports"
+}
+
+// UpgradePlan is a resumable, batch-by-batch node rotation plan for a cluster upgrade
+type UpgradePlan struct {
+	ID                  uint       `gorm:"primaryKey" json:"id"`
+	ClusterName         string     `gorm:"type:varchar(255);not null;index;column:cluster_name" json:"cluster_name"`
+	CurrentVersion      string     `gorm:"type:varchar(50);column:current_version" json:"current_version"`
+	TargetVersion       string     `gorm:"type:varchar(50);not null;column:target_version" json:"target_version"`
+	BatchSize           int        `gorm:"default:1;column:batch_size" json:"batch_size"`
+	PauseBetweenBatches bool       `gorm:"default:true;column:pause_between_batches" json:"pause_between_batches"`
+	Status              string     `gorm:"type:varchar(50);not null;index" json:"status"` // pending, running, paused, completed, failed, cancelled
+	Batches             JSON       `gorm:"type:text" json:"batches"`                       // JSON array of node name arrays
+	CurrentBatch        int        `gorm:"default:0;column:current_batch" json:"current_batch"`
+	PreChecks           JSON       `gorm:"type:text;column:pre_checks" json:"pre_checks"`
+	Progress            JSON       `gorm:"type:text" json:"progress"` // JSON array of per-node results
+	Error               string     `gorm:"type:text" json:"error,omitempty"`
+	Owner               string     `gorm:"type:varchar(255)" json:"owner,omitempty"`                           // server running the plan
+	HeartbeatAt         *time.Time `gorm:"column:heartbeat_at" json:"heartbeat_at,omitempty"`                  // last renewal of the owner's lease
+	StopRequest         string     `gorm:"type:varchar(50);column:stop_request" json:"stop_request,omitempty"` // paused or cancelled, asked of the owner
+	CreatedBy           uint       `gorm:"column:created_by" json:"created_by"`
+	StartedAt           *time.Time `gorm:"column:started_at" json:"started_at,omitempty"`
+	CompletedAt         *time.Time `gorm:"column:completed_at" json:"completed_at,omitempty"`
+	CreatedAt           time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt           time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName overrides the table name
+func (UpgradePlan) TableName() string {
+	return "upgrade_plans"
+}
+
+// UsageRollup aggregates a user's API usage against one cluster for one day.
+// ClusterName is empty for requests that do not target a cluster.
+type UsageRollup struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	Day          string    `gorm:"type:varchar(10);not null;uniqueIndex:idx_usage_rollup_key,priority:1" json:"day"` // YYYY-MM-DD (UTC)
+	UserID       uint      `gorm:"not null;uniqueIndex:idx_usage_rollup_key,priority:2;column:user_id" json:"user_id"`
+	ClusterName  string    `gorm:"type:varchar(255);not null;default:'';uniqueIndex:idx_usage_rollup_key,priority:3;column:cluster_name" json:"cluster_name"`
+	Requests     int64     `gorm:"default:0" json:"requests"`
+	Mutations    int64     `gorm:"default:0" json:"mutations"`
+	ShellsOpened int64     `gorm:"default:0;column:shells_opened" json:"shells_opened"`
+	UpdatedAt    time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName overrides the table name
+func (UsageRollup) TableName() string {
+	return "usage_rollups"
+}
+
+// HelmRepository is a Helm chart repository registered by an admin: a classic HTTP
+// repository serving index.yaml, or an OCI registry path with an explicit chart list
+type HelmRepository struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	Name          string     `gorm:"type:varchar(255);uniqueIndex;not null" json:"name"`
+	Type          string     `gorm:"type:varchar(20);not null;default:'http'" json:"type"` // http or oci
+	URL           string     `gorm:"type:text;not null" json:"url"`
+	Username      string     `gorm:"type:varchar(255)" json:"username,omitempty"`
+	Password      string     `gorm:"type:text" json:"-"`                      // Encrypted
+	Charts        JSON       `gorm:"type:text" json:"charts,omitempty"`      // OCI only: JSON array of chart names
+	ChartCount    int        `gorm:"default:0;column:chart_count" json:"chart_count"`
+	LastIndexedAt *time.Time `gorm:"column:last_indexed_at" json:"last_indexed_at,omitempty"`
+	IndexError    string     `gorm:"type:text;column:index_error" json:"index_error,omitempty"`
+	CreatedAt     time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt     time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName overrides the table name
+func (HelmRepository) TableName() string {
+	return "helm_repositories"
+}
+
+// HelmChartVersion is one chart version from the last index of a Helm repository
+type HelmChartVersion struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	RepositoryID uint       `gorm:"not null;uniqueIndex:idx_helm_chart_version,priority:1;column:repository_id" json:"repository_id"`
+	Chart        string     `gorm:"type:varchar(255);not null;uniqueIndex:idx_helm_chart_version,priority:2;index" json:"chart"`
+	Version      string     `gorm:"type:varchar(100);not null;uniqueIndex:idx_helm_chart_version,priority:3" json:"version"`
+	AppVersion   string     `gorm:"type:varchar(100);column:app_version" json:"app_version,omitempty"`
+	Description  string     `gorm:"type:text" json:"description,omitempty"`
+	Icon         string     `gorm:"type:text" json:"icon,omitempty"`
+	URLs         JSON       `gorm:"type:text;column:urls" json:"urls"` // JSON array of download URLs
+	Digest       string     `gorm:"type:varchar(100)" json:"digest,omitempty"`
+	Deprecated   bool       `gorm:"default:false" json:"deprecated"`
+	Created      *time.Time `gorm:"column:created" json:"created,omitempty"`
+}
+
+// TableName overrides the table name
+func (HelmChartVersion) TableName() string {
+	return "helm_chart_versions"
+}
+
+// ClusterDatasource is an external observability backend of a cluster, such as the
+// Prometheus server scraping it. A cluster has at most one datasource of each type.
+type ClusterDatasource struct {
+	ID                 uint      `gorm:"primaryKey" json:"id"`
+	ClusterName        string    `gorm:"type:varchar(255);not null;uniqueIndex:idx_cluster_datasource,priority:1;column:cluster_name" json:"cluster_name"`
+	Type               string    `gorm:"type:varchar(20);not null;uniqueIndex:idx_cluster_datasource,priority:2" json:"type"` // prometheus, loki, grafana or opencost
+	URL                string    `gorm:"type:text;not null" json:"url"`
+	AuthType           string    `gorm:"type:varchar(20);not null;default:'none';column:auth_type" json:"auth_type"` // none, basic or bearer
+	Username           string    `gorm:"type:varchar(255)" json:"username,omitempty"`
+	Secret             string    `gorm:"type:text" json:"-"`                 // Encrypted password or bearer token
+	Headers            JSON      `gorm:"type:text" json:"headers,omitempty"` // JSON object of extra request headers (e.g. X-Scope-OrgID)
+	InsecureSkipVerify bool      `gorm:"default:false;column:insecure_skip_verify" json:"insecure_skip_verify"`
+	Settings           JSON      `gorm:"type:text" json:"settings,omitempty"` // Type specific settings (grafana: dashboards; opencost: api)
+	CreatedAt          time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt          time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName overrides the table name
+func (ClusterDatasource) TableName() string {
+	return "cluster_datasources"
+}
+
+// NotificationChannel is an outbound destination for kubelens events (alerts, cluster
+// events). Config is the provider configuration, encrypted as it holds URLs with tokens
+// and signing secrets.
+type NotificationChannel struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	Name           string     `gorm:"type:varchar(255);uniqueIndex;not null" json:"name"`
+	Type           string     `gorm:"type:varchar(20);not null" json:"type"` // webhook
+	Enabled        bool       `gorm:"default:true" json:"enabled"`
+	Config         string     `gorm:"type:text;not null" json:"-"`       // Encrypted JSON
+	Events         JSON       `gorm:"type:text" json:"events,omitempty"` // JSON array of event type patterns (default: all)
+	MinSeverity    string     `gorm:"type:varchar(20);column:min_severity" json:"min_severity,omitempty"`
+	LastDeliveryAt *time.Time `gorm:"column:last_delivery_at" json:"last_delivery_at,omitempty"`
+	LastError      string     `gorm:"type:text;column:last_error" json:"last_error,omitempty"`
+	CreatedAt      time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt      time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName overrides the table name
+func (NotificationChannel) TableName() string {
+	return "notification_channels"
+}
+
 // [Removed Integration structs]
 
 // ClusterMetadata stores cluster metadata and statistics
@@ -498,6 +955,100 @@ type KubeconfigAuthConfig struct {
 	Context    string `json:"context,omitempty"`
 }
 
+// ExecAuthConfig for exec credential plugin authentication (client.authentication.k8s.io)
+type ExecAuthConfig struct {
+	Server     string            `json:"server"`
+	CA         string            `json:"ca"` // base64 encoded
+	Command    string            `json:"command"`
+	Args       []string          `json:"args,omitempty"`
+	Env        map[string]string `json:"env,omitempty"`
+	APIVersion string            `json:"api_version,omitempty"` // default client.authentication.k8s.io/v1beta1
+}
+
+// EKSAuthConfig for Amazon EKS IAM authentication. Without access keys the AWS
+// credentials of the server environment are used (environment variables or web identity).
+type EKSAuthConfig struct {
+	Server          string `json:"server"`
+	CA              string `json:"ca"`           // base64 encoded
+	ClusterName     string `json:"cluster_name"` // name of the EKS cluster
+	Region          string `json:"region"`
+	AccessKeyID     string `json:"access_key_id,omitempty"`
+	SecretAccessKey string `json:"secret_access_key,omitempty"`
+	SessionToken    string `json:"session_token,omitempty"`
+}
+
+// GKEAuthConfig for Google Kubernetes Engine authentication with a service account key
+type GKEAuthConfig struct {
+	Server             string `json:"server"`
+	CA                 string `json:"ca"`                   // base64 encoded
+	ServiceAccountJSON string `json:"service_account_json"` // JSON key file of the service account
+}
+
+// AKSAuthConfig for Azure Kubernetes Service (Microsoft Entra ID integrated) authentication
+// with the client credentials of a service principal
+type AKSAuthConfig struct {
+	Server        string `json:"server"`
+	CA            string `json:"ca"` // base64 encoded
+	TenantID      string `json:"tenant_id"`
+	ClientID      string `json:"client_id"`
+	ClientSecret  string `json:"client_secret"`
+	AuthorityHost string `json:"authority_host,omitempty"` // default https://login.microsoftonline.com
+}
+
+// AgentAuthConfig for clusters reached through the reverse tunnel of an in-cluster agent.
+// Only the hash of the agent token is stored.
+type AgentAuthConfig struct {
+	TokenHash string `json:"token_hash"` // hex SHA-256 of the agent token
+}
+
+// ConnectionOptions adjust how kubelens reaches the API server of a cluster, for clusters
+// behind a bastion or proxy, or whose certificates are signed by a private CA
+type ConnectionOptions struct {
+	ProxyURL           string `json:"proxy_url,omitempty"`            // http, https or socks5 proxy for API server requests
+	CA                 string `json:"ca,omitempty"`                   // base64 PEM bundle trusted instead of the cluster CA
+	ServerName         string `json:"server_name,omitempty"`          // name the API server certificate is checked against (SNI)
+	InsecureSkipVerify bool   `json:"insecure_skip_verify,omitempty"` // skip verification of the API server certificate
+}
+
+// CrashReportFilters for querying crash reports
+type CrashReportFilters struct {
+	ClusterName string
+	Namespace   string
+	PodName     string
+	Visible     func(cluster, namespace string) bool // the caller's scope, nil for all reports
+	Page        int
+	PageSize    int
+}
+
+// AlertFilters for querying alerts
+type AlertFilters struct {
+	State    string // pending, firing, resolved or active (pending and firing)
+	Cluster  string
+	RuleID   uint
+	Visible  func(cluster, namespace string) bool // the caller's scope, nil for all alerts
+	Page     int
+	PageSize int
+}
+
+// ClusterEventFilters for querying recorded cluster events
+type ClusterEventFilters struct {
+	ClusterName string
+	Namespace   string
+	Type        string // Normal or Warning
+	Kind        string
+	Name        string
+	Reasons     []string // any of these reasons
+	Since       time.Time
+	Limit       int
+}
+
+// HelmChartFilters for querying indexed chart versions
+type HelmChartFilters struct {
+	RepositoryID uint
+	Chart        string // exact chart name
+	Query        string // substring of the chart name or description
+}
+
 // AuditLogFilters for querying audit logs
 type AuditLogFilters struct {
 	EventType string
@@ -541,3 +1092,232 @@ func (SystemConfig) TableName() string {
 	return "system_configs"
 }
 
+// AlertRule is a workload health condition (e.g. a pod crash looping for 5 minutes)
+// evaluated periodically against the clusters it is scoped to
+type AlertRule struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	Name        string    `gorm:"type:varchar(255);uniqueIndex;not null" json:"name"`
+	Description string    `gorm:"type:text" json:"description,omitempty"`
+	Enabled     bool      `gorm:"default:true" json:"enabled"`
+	Cluster     string    `gorm:"type:varchar(255);index" json:"cluster"`          // Empty for every connected cluster
+	Namespace   string    `gorm:"type:varchar(255)" json:"namespace"`              // Empty for every namespace
+	Condition   string    `gorm:"type:varchar(50);not null" json:"condition"`      // pod_crashloop, pod_pending, deployment_unavailable, node_pressure or node_not_ready
+	Threshold   int       `gorm:"default:0" json:"threshold"`                      // Condition specific, e.g. unavailable replicas above which a deployment alerts
+	ForSeconds  int       `gorm:"default:0;column:for_seconds" json:"for_seconds"` // How long the condition must hold before the alert fires
+	Severity    string    `gorm:"type:varchar(20);not null;default:'warning'" json:"severity"`
+	CreatedBy   uint      `gorm:"column:created_by" json:"created_by"`
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName overrides the table name
+func (AlertRule) TableName() string {
+	return "alert_rules"
+}
+
+// Alert is an alert rule matching one object. It is pending until the condition held for
+// the duration of the rule, then firing until the condition clears.
+type Alert struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	RuleID      uint       `gorm:"not null;index;column:rule_id" json:"rule_id"`
+	RuleName    string     `gorm:"type:varchar(255);column:rule_name" json:"rule_name"`
+	Severity    string     `gorm:"type:varchar(20)" json:"severity"`
+	Cluster     string     `gorm:"type:varchar(255);not null;index" json:"cluster"`
+	Namespace   string     `gorm:"type:varchar(255)" json:"namespace,omitempty"`
+	Resource    string     `gorm:"type:varchar(512);not null" json:"resource"`   // <Kind>/<name>
+	State       string     `gorm:"type:varchar(20);not null;index" json:"state"` // pending, firing or resolved
+	Message     string     `gorm:"type:text" json:"message"`
+	ActiveSince time.Time  `gorm:"not null;column:active_since" json:"active_since"` // When the condition was first seen
+	LastSeenAt  time.Time  `gorm:"column:last_seen_at" json:"last_seen_at"`
+	FiredAt     *time.Time `gorm:"column:fired_at" json:"fired_at,omitempty"`
+	ResolvedAt  *time.Time `gorm:"column:resolved_at;index" json:"resolved_at,omitempty"`
+}
+
+// TableName overrides the table name
+func (Alert) TableName() string {
+	return "alerts"
+}
+
+// ClusterEvent is a Kubernetes Event recorded by the event watcher. Repeats of an event
+// (same object, type, reason and message) are folded into one row.
+type ClusterEvent struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	ClusterName string    `gorm:"type:varchar(255);not null;uniqueIndex:idx_cluster_event,priority:1;index:idx_cluster_event_seen,priority:1;column:cluster_name" json:"cluster"`
+	Fingerprint string    `gorm:"type:varchar(64);not null;uniqueIndex:idx_cluster_event,priority:2" json:"-"` // SHA-256 of object, type, reason and message
+	UID         string    `gorm:"type:varchar(64);column:uid" json:"uid"`                                      // UID of the newest Event object folded in
+	Namespace   string    `gorm:"type:varchar(255);index" json:"namespace,omitempty"`
+	Kind        string    `gorm:"type:varchar(255)" json:"kind"`
+	Name        string    `gorm:"type:varchar(255)" json:"name"`
+	Type        string    `gorm:"type:varchar(20)" json:"type"` // Normal or Warning
+	Reason      string    `gorm:"type:varchar(255)" json:"reason"`
+	Message     string    `gorm:"type:text" json:"message"`
+	Source      string    `gorm:"type:varchar(255)" json:"source,omitempty"`
+	Count       int32     `json:"count"`
+	FirstSeen   time.Time `gorm:"column:first_seen" json:"first_seen"`
+	LastSeen    time.Time `gorm:"column:last_seen;index:idx_cluster_event_seen,priority:2" json:"last_seen"`
+}
+
+// TableName overrides the table name
+func (ClusterEvent) TableName() string {
+	return "cluster_events"
+}
+
+// BenchmarkRun is a CIS Kubernetes benchmark (kube-bench) run on a node of a cluster
+type BenchmarkRun struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	ClusterName string     `gorm:"type:varchar(255);not null;index;column:cluster_name" json:"cluster"`
+	Node        string     `gorm:"type:varchar(255)" json:"node,omitempty"`     // Node the job ran on
+	Benchmark   string     `gorm:"type:varchar(50)" json:"benchmark,omitempty"` // e.g. cis-1.8; empty when detected by kube-bench
+	Namespace   string     `gorm:"type:varchar(255)" json:"namespace"`          // Namespace of the job
+	JobName     string     `gorm:"type:varchar(255);column:job_name" json:"job_name"`
+	Status      string     `gorm:"type:varchar(20);not null;index" json:"status"` // running, completed or failed
+	Error       string     `gorm:"type:text" json:"error,omitempty"`
+	Pass        int        `json:"pass"`
+	Fail        int        `json:"fail"`
+	Warn        int        `json:"warn"`
+	Info        int        `json:"info"`
+	Sections    JSON       `gorm:"type:text" json:"sections,omitempty"` // JSON array of per-section counts
+	Results     JSON       `gorm:"type:text" json:"results,omitempty"`  // JSON array of check results
+	StartedBy   uint       `gorm:"column:started_by" json:"started_by"`
+	StartedAt   time.Time  `gorm:"autoCreateTime;index" json:"started_at"`
+	CompletedAt *time.Time `gorm:"column:completed_at" json:"completed_at,omitempty"`
+}
+
+// TableName overrides the table name
+func (BenchmarkRun) TableName() string {
+	return "benchmark_runs"
+}
+
+// DrainJob is a persisted drain of one or more nodes of a cluster, one node after the
+// other, optionally scheduled for a maintenance window
+type DrainJob struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	ClusterName    string     `gorm:"type:varchar(255);not null;index;column:cluster_name" json:"cluster_name"`
+	Nodes          JSON       `gorm:"type:text" json:"nodes"` // JSON array of node names, drained in order
+	CurrentNode    int        `gorm:"default:0;column:current_node" json:"current_node"`
+	Parallelism    int        `gorm:"default:1" json:"parallelism"`                            // pods evicted at once
+	TimeoutSeconds int        `gorm:"column:timeout_seconds" json:"timeout_seconds"`           // per node
+	ScheduledAt    *time.Time `gorm:"column:scheduled_at;index" json:"scheduled_at,omitempty"` // start of the window; nil starts immediately
+	WindowEnd      *time.Time `gorm:"column:window_end" json:"window_end,omitempty"`           // the job pauses when the window ends
+	Status         string     `gorm:"type:varchar(50);not null;index" json:"status"`           // scheduled, running, paused, completed, failed, cancelled
+	Progress       JSON       `gorm:"type:text" json:"progress"`                               // JSON array of per-node results
+	Error          string     `gorm:"type:text" json:"error,omitempty"`
+	Owner          string     `gorm:"type:varchar(255)" json:"owner,omitempty"`                           // server running the job
+	HeartbeatAt    *time.Time `gorm:"column:heartbeat_at" json:"heartbeat_at,omitempty"`                  // last renewal of the owner's lease
+	StopRequest    string     `gorm:"type:varchar(50);column:stop_request" json:"stop_request,omitempty"` // paused or cancelled, asked of the owner
+	CreatedBy      uint       `gorm:"column:created_by" json:"created_by"`
+	StartedAt      *time.Time `gorm:"column:started_at" json:"started_at,omitempty"`
+	CompletedAt    *time.Time `gorm:"column:completed_at" json:"completed_at,omitempty"`
+	CreatedAt      time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt      time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName overrides the table name
+func (DrainJob) TableName() string {
+	return "drain_jobs"
+}
+
+// ClusterGroupPrefix marks a cluster group in the cluster patterns of a permission, e.g.
+// "group:production"
+const ClusterGroupPrefix = "group:"
+
+// ClusterGroup is a named set of clusters, used for bulk operations and, referenced as
+// group:<name> in permissions, for scoping access. Its members are the clusters listed in
+// Clusters and those whose labels match Selector.
+type ClusterGroup struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	Name        string    `gorm:"type:varchar(255);uniqueIndex;not null" json:"name"`
+	Description string    `gorm:"type:text" json:"description,omitempty"`
+	Selector    string    `gorm:"type:text" json:"selector,omitempty"` // label selector, e.g. env=prod,region in (eu,us)
+	Clusters    JSON      `gorm:"type:text" json:"clusters"`           // JSON array of cluster names
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName overrides the table name
+func (ClusterGroup) TableName() string {
+	return "cluster_groups"
+}
+
+// ListedClusters returns the cluster names listed in a group
+func (g *ClusterGroup) ListedClusters() []string {
+	var names []string
+	if len(g.Clusters) > 0 {
+		json.Unmarshal(g.Clusters, &names)
+	}
+	return names
+}
+
+// Organization roles. Owners and admins manage everything in their organization except
+// instance-wide settings and user accounts; owners also manage its members and details.
+// Members get the permissions of their groups in the organization.
+const (
+	OrgRoleOwner  = "owner"
+	OrgRoleAdmin  = "admin"
+	OrgRoleMember = "member"
+)
+
+// DefaultOrganizationID is the organization created by the organizations migration. It
+// holds everything that existed before organizations and what is created outside one.
+const DefaultOrganizationID uint = 1
+
+// Organization is a tenant: it owns clusters, groups, notifications and audit logs, and
+// users work in one organization at a time
+type Organization struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	Name        string    `gorm:"type:varchar(63);uniqueIndex;not null" json:"name"`
+	DisplayName string    `gorm:"type:varchar(255);column:display_name" json:"display_name,omitempty"`
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName overrides the table name
+func (Organization) TableName() string {
+	return "organizations"
+}
+
+// OrganizationMember gives a user a role in an organization
+type OrganizationMember struct {
+	OrganizationID uint      `gorm:"primaryKey;autoIncrement:false;column:organization_id" json:"organization_id"`
+	UserID         uint      `gorm:"primaryKey;autoIncrement:false;column:user_id;index" json:"user_id"`
+	Role           string    `gorm:"type:varchar(20);not null" json:"role"`
+	CreatedAt      time.Time `gorm:"autoCreateTime" json:"created_at"`
+
+	// Relationships
+	Organization *Organization `gorm:"foreignKey:OrganizationID" json:"organization,omitempty"`
+	User         *User         `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
+
+// TableName overrides the table name
+func (OrganizationMember) TableName() string {
+	return "organization_members"
+}
+
+// LogArchivePolicy periodically exports the logs of the pods of a cluster matching a label
+// selector to an S3-compatible bucket, and deletes the exported objects older than the
+// retention
+type LogArchivePolicy struct {
+	ID              uint       `gorm:"primaryKey" json:"id"`
+	ClusterName     string     `gorm:"type:varchar(255);not null;uniqueIndex:idx_log_archive_policy,priority:1;column:cluster_name" json:"cluster_name"`
+	Name            string     `gorm:"type:varchar(255);not null;uniqueIndex:idx_log_archive_policy,priority:2" json:"name"`
+	Namespace       string     `gorm:"type:varchar(255)" json:"namespace"`                             // Empty for every namespace
+	LabelSelector   string     `gorm:"type:text;not null;column:label_selector" json:"label_selector"` // Pods whose logs are exported
+	IntervalMinutes int        `gorm:"not null;column:interval_minutes" json:"interval_minutes"`       // Time between exports
+	RetentionDays   int        `gorm:"not null;column:retention_days" json:"retention_days"`           // Exported objects are deleted after; 0 keeps them
+	Endpoint        string     `gorm:"type:text" json:"endpoint,omitempty"`                            // S3-compatible endpoint; empty for AWS
+	Bucket          string     `gorm:"type:varchar(255);not null" json:"bucket"`
+	Prefix          string     `gorm:"type:varchar(255)" json:"prefix,omitempty"` // Key prefix of the exported objects
+	Region          string     `gorm:"type:varchar(64)" json:"region,omitempty"`
+	AccessKeyID     string     `gorm:"type:varchar(255);column:access_key_id" json:"access_key_id,omitempty"` // Empty for the server's AWS_* credentials
+	SecretAccessKey string     `gorm:"type:text;column:secret_access_key" json:"-"`                           // Encrypted
+	Enabled         bool       `json:"enabled"`
+	LastRunAt       *time.Time `gorm:"column:last_run_at" json:"last_run_at,omitempty"` // End of the last exported period
+	LastError       string     `gorm:"type:text;column:last_error" json:"last_error,omitempty"`
+	CreatedAt       time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt       time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName overrides the table name
+func (LogArchivePolicy) TableName() string {
+	return "log_archive_policies"
+}
@@ -21,6 +21,15 @@ func (db *DB) GetPermissionOptions() (*PermissionOptions, error) {
 		clusterNames = append(clusterNames, cluster.Name)
 	}
 
+	// Cluster groups can stand in for their members
+	groups, err := db.ListClusterGroups()
+	if err != nil {
+		return nil, err
+	}
+	for _, group := range groups {
+		clusterNames = append(clusterNames, ClusterGroupPrefix+group.Name)
+	}
+
 	// Define available resources (Kubernetes resource types + system resources)
 	resources := []string{
 		"*",
@@ -31,6 +40,7 @@ func (db *DB) GetPermissionOptions() (*PermissionOptions, error) {
 		"pods",
 		"deployments",
 		"services",
+		"endpointslices",
 		"configmaps",
 		"secrets",
 		"ingresses",
@@ -51,8 +61,18 @@ func (db *DB) GetPermissionOptions() (*PermissionOptions, error) {
 		"storageclasses",
 		"runtimeclasses",
 		"leases",
+		"certificatesigningrequests",
+		"limitranges",
 		"mutatingwebhookconfigurations",
 		"validatingwebhookconfigurations",
+		// Add-on resources (Argo CD, Flux, Istio)
+		"applications",
+		"kustomizations",
+		"helmreleases",
+		"virtualservices",
+		"destinationrules",
+		"gateways",
+		"peerauthentications",
 		// System resources
 		"extensions", // Extension management
 		"users",      // User management
@@ -60,6 +80,7 @@ func (db *DB) GetPermissionOptions() (*PermissionOptions, error) {
 		"audit",      // Audit logs and settings
 		"logging",    // System logging
 		"settings",   // System settings
+		"templates",  // Resource templates
 	}
 
 	// Define available actions (CRUD operations + extension management)
@@ -0,0 +1,105 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// SessionCache keeps the login sessions checked on every request, such as in Redis
+type SessionCache interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// sessionCacheTTL bounds how long a cached session is used; writes evict it earlier
+const sessionCacheTTL = 5 * time.Minute
+
+var sessionCache SessionCache
+
+// SetSessionCache makes session lookups by ID read through cache. Every change to a
+// session evicts it, so the cache must be shared by all replicas using the database.
+func SetSessionCache(cache SessionCache) {
+	sessionCache = cache
+}
+
+// cachedSession is a cached Session, token included
+type cachedSession struct {
+	ID         uint       `json:"id"`
+	UserID     uint       `json:"user_id"`
+	Token      string     `json:"token"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	IPAddress  string     `json:"ip_address"`
+	UserAgent  string     `json:"user_agent"`
+	LastSeenAt *time.Time `json:"last_seen_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	OrgID      uint       `json:"organization_id"`
+}
+
+func sessionCacheKey(id uint) string {
+	return "session:" + strconv.FormatUint(uint64(id), 10)
+}
+
+// cachedSessionByID returns a cached session, if any
+func cachedSessionByID(id uint) (*Session, bool) {
+	if sessionCache == nil {
+		return nil, false
+	}
+	data, err := sessionCache.Get(context.Background(), sessionCacheKey(id))
+	if err != nil {
+		return nil, false
+	}
+	var cached cachedSession
+	if err := json.Unmarshal(data, &cached); err != nil {
+		return nil, false
+	}
+	return &Session{
+		ID: cached.ID, UserID: cached.UserID, Token: cached.Token, ExpiresAt: cached.ExpiresAt,
+		CreatedAt: cached.CreatedAt, IPAddress: cached.IPAddress, UserAgent: cached.UserAgent,
+		LastSeenAt: cached.LastSeenAt, RevokedAt: cached.RevokedAt, OrganizationID: cached.OrgID,
+	}, true
+}
+
+// cacheSession stores a session read from the database
+func cacheSession(session *Session) {
+	if sessionCache == nil {
+		return
+	}
+	data, _ := json.Marshal(cachedSession{
+		ID: session.ID, UserID: session.UserID, Token: session.Token, ExpiresAt: session.ExpiresAt,
+		CreatedAt: session.CreatedAt, IPAddress: session.IPAddress, UserAgent: session.UserAgent,
+		LastSeenAt: session.LastSeenAt, RevokedAt: session.RevokedAt, OrgID: session.OrganizationID,
+	})
+	if err := sessionCache.Set(context.Background(), sessionCacheKey(session.ID), data, sessionCacheTTL); err != nil {
+		log.Debugf("Failed to cache session %d: %v", session.ID, err)
+	}
+}
+
+// evictSessions removes changed sessions from the cache
+func evictSessions(ids ...uint) {
+	if sessionCache == nil || len(ids) == 0 {
+		return
+	}
+	keys := make([]string, 0, len(ids))
+	for _, id := range ids {
+		keys = append(keys, sessionCacheKey(id))
+	}
+	if err := sessionCache.Delete(context.Background(), keys...); err != nil {
+		log.Warnf("Failed to evict %d sessions from the cache: %v", len(ids), err)
+	}
+}
+
+// sessionIDs returns the IDs of the sessions matching a condition, for eviction
+func (db *GormDB) sessionIDs(query interface{}, args ...interface{}) []uint {
+	if sessionCache == nil {
+		return nil
+	}
+	var ids []uint
+	db.Model(&Session{}).Where(query, args...).Pluck("id", &ids)
+	return ids
+}
@@ -0,0 +1,98 @@
+package db
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/sonnguyen/kubelens/internal/cache"
+)
+
+func TestSessionCache(t *testing.T) {
+	db, err := NewGorm(filepath.Join(t.TempDir(), "kubelens.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	store := cache.NewMemory()
+	SetSessionCache(store)
+	defer SetSessionCache(nil)
+
+	user := &User{Email: "ann@example.com", Username: "ann"}
+	if err := db.Create(user).Error; err != nil {
+		t.Fatal(err)
+	}
+	session := &Session{UserID: user.ID, Token: "family", ExpiresAt: time.Now().Add(time.Hour)}
+	if err := db.CreateSession(session); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := db.GetSessionByID(session.ID); err != nil {
+		t.Fatal(err)
+	}
+	cached, ok := cachedSessionByID(session.ID)
+	if !ok || cached.Token != "family" || cached.UserID != user.ID {
+		t.Fatalf("cached session = %+v, %v", cached, ok)
+	}
+
+	// A revocation is seen at once, not when the cached copy expires
+	if err := db.RevokeUserSessions(user.ID, 0); err != nil {
+		t.Fatal(err)
+	}
+	got, err := db.GetSessionByID(session.ID)
+	if err != nil || got.RevokedAt == nil {
+		t.Errorf("session after revocation = %+v, %v; want revoked", got, err)
+	}
+}
+
+// TestSessionCacheRevokeSession checks that a revoked session is not served from the cache,
+// while the revocation commits or after it
+func TestSessionCacheRevokeSession(t *testing.T) {
+	db, err := NewGorm(filepath.Join(t.TempDir(), "kubelens.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	SetSessionCache(cache.NewMemory())
+	defer SetSessionCache(nil)
+
+	user := &User{Email: "ann@example.com", Username: "ann"}
+	if err := db.Create(user).Error; err != nil {
+		t.Fatal(err)
+	}
+	session := &Session{UserID: user.ID, Token: "family", ExpiresAt: time.Now().Add(time.Hour)}
+	if err := db.CreateSession(session); err != nil {
+		t.Fatal(err)
+	}
+	stale, err := db.GetSessionByID(session.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A request in flight during the revocation caches the copy it read before
+	var servedDuringRevoke bool
+	err = db.Callback().Update().After("gorm:update").Register("test:stale_reader", func(tx *gorm.DB) {
+		if tx.Statement.Table != "sessions" || !strings.Contains(tx.Statement.SQL.String(), "revoked_at") {
+			return
+		}
+		_, servedDuringRevoke = cachedSessionByID(session.ID)
+		cacheSession(stale)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := db.RevokeSession(session.ID); err != nil {
+		t.Fatal(err)
+	}
+	if servedDuringRevoke {
+		t.Error("session served from the cache while its revocation commits")
+	}
+	got, err := db.GetSessionByID(session.ID)
+	if err != nil || got.RevokedAt == nil {
+		t.Errorf("session after revocation = %+v, %v; want revoked", got, err)
+	}
+}
@@ -0,0 +1,148 @@
+// Package diagnostics provides runtime diagnostics of the kubelens server: pprof profiles
+// and a status summary used to investigate memory and goroutine growth.
+package diagnostics
+
+import (
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	watchesMu sync.Mutex
+	watches   = map[string]int{}
+
+	startTime = time.Now()
+)
+
+// TrackWatch records an open long-running Kubernetes request (watch or log stream) of the
+// given kind. Call the returned function when it ends.
+func TrackWatch(kind string) func() {
+	watchesMu.Lock()
+	watches[kind]++
+	watchesMu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			watchesMu.Lock()
+			watches[kind]--
+			if watches[kind] <= 0 {
+				delete(watches, kind)
+			}
+			watchesMu.Unlock()
+		})
+	}
+}
+
+// OpenWatches returns the number of open watches by kind
+func OpenWatches() map[string]int {
+	watchesMu.Lock()
+	defer watchesMu.Unlock()
+
+	open := make(map[string]int, len(watches))
+	for kind, count := range watches {
+		open[kind] = count
+	}
+	return open
+}
+
+// Status is the response of GET /debug/status
+type Status struct {
+	StartedAt        time.Time      `json:"started_at"`
+	UptimeSeconds    int64          `json:"uptime_seconds"`
+	GoVersion        string         `json:"go_version"`
+	NumCPU           int            `json:"num_cpu"`
+	Goroutines       int            `json:"goroutines"`
+	Heap             HeapStats      `json:"heap"`
+	OpenWatches      map[string]int `json:"open_watches"`
+	WebSocketClients int            `json:"websocket_clients"`
+	Clusters         []string       `json:"clusters"`
+}
+
+// HeapStats is a subset of runtime.MemStats
+type HeapStats struct {
+	AllocBytes      uint64  `json:"alloc_bytes"`
+	InuseBytes      uint64  `json:"inuse_bytes"`
+	IdleBytes       uint64  `json:"idle_bytes"`
+	ReleasedBytes   uint64  `json:"released_bytes"`
+	SysBytes        uint64  `json:"sys_bytes"`
+	Objects         uint64  `json:"objects"`
+	TotalAllocBytes uint64  `json:"total_alloc_bytes"`
+	NumGC           uint32  `json:"num_gc"`
+	LastGC          *string `json:"last_gc,omitempty"`
+	GCPauseTotalMs  float64 `json:"gc_pause_total_ms"`
+}
+
+// Handler serves the diagnostics endpoints
+type Handler struct {
+	wsClients func() int
+	clusters  func() []string
+}
+
+// NewHandler creates a diagnostics handler. wsClients and clusters report the connected
+// WebSocket clients and the clusters loaded by the cluster manager.
+func NewHandler(wsClients func() int, clusters func() []string) *Handler {
+	return &Handler{wsClients: wsClients, clusters: clusters}
+}
+
+// GetStatus handles GET /debug/status
+func (h *Handler) GetStatus(c *gin.Context) {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	heap := HeapStats{
+		AllocBytes:      mem.HeapAlloc,
+		InuseBytes:      mem.HeapInuse,
+		IdleBytes:       mem.HeapIdle,
+		ReleasedBytes:   mem.HeapReleased,
+		SysBytes:        mem.Sys,
+		Objects:         mem.HeapObjects,
+		TotalAllocBytes: mem.TotalAlloc,
+		NumGC:           mem.NumGC,
+		GCPauseTotalMs:  float64(mem.PauseTotalNs) / float64(time.Millisecond),
+	}
+	if mem.LastGC > 0 {
+		lastGC := time.Unix(0, int64(mem.LastGC)).UTC().Format(time.RFC3339)
+		heap.LastGC = &lastGC
+	}
+
+	clusters := h.clusters()
+	sort.Strings(clusters)
+
+	c.JSON(http.StatusOK, Status{
+		StartedAt:        startTime,
+		UptimeSeconds:    int64(time.Since(startTime).Seconds()),
+		GoVersion:        runtime.Version(),
+		NumCPU:           runtime.NumCPU(),
+		Goroutines:       runtime.NumGoroutine(),
+		Heap:             heap,
+		OpenWatches:      OpenWatches(),
+		WebSocketClients: h.wsClients(),
+		Clusters:         clusters,
+	})
+}
+
+// Pprof handles /debug/pprof/*profile with the handlers of net/http/pprof, e.g.
+// /debug/pprof/heap or /debug/pprof/profile?seconds=30
+func (h *Handler) Pprof(c *gin.Context) {
+	switch strings.TrimPrefix(c.Request.URL.Path, "/debug/pprof/") {
+	case "cmdline":
+		pprof.Cmdline(c.Writer, c.Request)
+	case "profile":
+		pprof.Profile(c.Writer, c.Request)
+	case "symbol":
+		pprof.Symbol(c.Writer, c.Request)
+	case "trace":
+		pprof.Trace(c.Writer, c.Request)
+	default:
+		// Index serves the named profiles (heap, goroutine, allocs, ...) and the listing
+		pprof.Index(c.Writer, c.Request)
+	}
+}
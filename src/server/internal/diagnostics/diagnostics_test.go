@@ -0,0 +1,25 @@
+package diagnostics
+
+import "testing"
+
+func TestTrackWatch(t *testing.T) {
+	done1 := TrackWatch("pod_logs")
+	done2 := TrackWatch("pod_logs")
+	doneEdit := TrackWatch("edit_session")
+
+	if open := OpenWatches(); open["pod_logs"] != 2 || open["edit_session"] != 1 {
+		t.Fatalf("OpenWatches() = %v, want 2 pod_logs and 1 edit_session", open)
+	}
+
+	done1()
+	done1() // calling twice must not double count
+	doneEdit()
+	if open := OpenWatches(); open["pod_logs"] != 1 || len(open) != 1 {
+		t.Errorf("OpenWatches() = %v, want only 1 pod_logs", open)
+	}
+
+	done2()
+	if open := OpenWatches(); len(open) != 0 {
+		t.Errorf("OpenWatches() = %v, want none", open)
+	}
+}
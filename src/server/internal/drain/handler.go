@@ -0,0 +1,315 @@
+package drain
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/sonnguyen/kubelens/internal/audit"
+	"github.com/sonnguyen/kubelens/internal/auth"
+	"github.com/sonnguyen/kubelens/internal/cluster"
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+const (
+	// maxParallelism bounds the pods evicted at once
+	maxParallelism = 50
+	// maxTimeout bounds how long a single node may take to empty
+	maxTimeout = 6 * time.Hour
+)
+
+// Handler handles drain job API requests
+type Handler struct {
+	db             *db.DB
+	clusterManager *cluster.Manager
+	runner         *Runner
+}
+
+// NewHandler creates a new drain job handler
+func NewHandler(database *db.DB, clusterManager *cluster.Manager, runner *Runner) *Handler {
+	return &Handler{
+		db:             database,
+		clusterManager: clusterManager,
+		runner:         runner,
+	}
+}
+
+// JobRequest is the body for drain job creation
+type JobRequest struct {
+	Cluster        string     `json:"cluster" binding:"required"`
+	Nodes          []string   `json:"nodes" binding:"required"` // drained in order
+	Parallelism    int        `json:"parallelism"`              // pods evicted at once, default 1
+	TimeoutSeconds int        `json:"timeout_seconds"`          // per node, default 15 minutes
+	ScheduledAt    *time.Time `json:"scheduled_at"`             // start of the window; omitted starts immediately
+	WindowEnd      *time.Time `json:"window_end"`               // the job pauses when the window ends
+}
+
+// ResumeRequest is the optional body for resuming a job
+type ResumeRequest struct {
+	WindowEnd *time.Time `json:"window_end"` // a new end for a window that has ended
+}
+
+// CreateJob handles POST /api/v1/drains
+func (h *Handler) CreateJob(c *gin.Context) {
+	var req JobRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := req.validate(time.Now()); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !auth.CheckClusterScope(c, req.Cluster, "nodes", "", "update") {
+		return
+	}
+
+	client, err := h.clusterManager.GetClient(req.Cluster)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	for _, node := range req.Nodes {
+		if _, err := client.CoreV1().Nodes().Get(context.Background(), node, metav1.GetOptions{}); err != nil {
+			if apierrors.IsNotFound(err) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("node %s not found", node)})
+				return
+			}
+			log.Errorf("Failed to get node %s: %v", node, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	job := &db.DrainJob{
+		ClusterName:    req.Cluster,
+		Nodes:          marshalJSON(req.Nodes),
+		Parallelism:    req.Parallelism,
+		TimeoutSeconds: req.TimeoutSeconds,
+		ScheduledAt:    req.ScheduledAt,
+		WindowEnd:      req.WindowEnd,
+		Status:         StatusScheduled,
+		Progress:       marshalJSON([]NodeProgress{}),
+		CreatedBy:      uint(c.GetInt("user_id")),
+	}
+	if err := h.db.CreateDrainJob(job); err != nil {
+		log.Errorf("Failed to create drain job: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create drain job"})
+		return
+	}
+
+	// Jobs without a schedule start right away; scheduled ones are started by the runner
+	if job.ScheduledAt == nil {
+		if err := h.runner.Run(job); err != nil {
+			h.runner.failScheduled(job, fmt.Sprintf("failed to start: %v", err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	h.audit(c, audit.EventAuditResourceCreated, job, "created")
+	c.JSON(http.StatusCreated, job)
+}
+
+// validate applies the defaults of a request and checks its bounds
+func (req *JobRequest) validate(now time.Time) error {
+	if len(req.Nodes) == 0 {
+		return fmt.Errorf("nodes must name at least one node")
+	}
+	seen := make(map[string]bool, len(req.Nodes))
+	for _, node := range req.Nodes {
+		if node == "" || seen[node] {
+			return fmt.Errorf("nodes must be distinct, non-empty node names")
+		}
+		seen[node] = true
+	}
+
+	if req.Parallelism == 0 {
+		req.Parallelism = 1
+	}
+	if req.Parallelism < 1 || req.Parallelism > maxParallelism {
+		return fmt.Errorf("parallelism must be between 1 and %d", maxParallelism)
+	}
+	if req.TimeoutSeconds == 0 {
+		req.TimeoutSeconds = int(defaultTimeout.Seconds())
+	}
+	if req.TimeoutSeconds < 1 || req.TimeoutSeconds > int(maxTimeout.Seconds()) {
+		return fmt.Errorf("timeout_seconds must be between 1 and %d", int(maxTimeout.Seconds()))
+	}
+
+	if req.ScheduledAt != nil && !req.ScheduledAt.After(now) {
+		return fmt.Errorf("scheduled_at must be in the future")
+	}
+	if req.WindowEnd != nil {
+		start := now
+		if req.ScheduledAt != nil {
+			start = *req.ScheduledAt
+		}
+		if !req.WindowEnd.After(start) {
+			return fmt.Errorf("window_end must be after the start of the window")
+		}
+	}
+	return nil
+}
+
+// ListJobs handles GET /api/v1/drains?cluster=
+func (h *Handler) ListJobs(c *gin.Context) {
+	clusterName := c.Query("cluster")
+	if clusterName == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cluster query parameter is required"})
+		return
+	}
+	if !auth.CheckClusterScope(c, clusterName, "nodes", "", "read") {
+		return
+	}
+
+	jobs, err := h.db.ListDrainJobs(clusterName)
+	if err != nil {
+		log.Errorf("Failed to list drain jobs: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list drain jobs"})
+		return
+	}
+
+	c.JSON(http.StatusOK, jobs)
+}
+
+// GetJob handles GET /api/v1/drains/:id
+func (h *Handler) GetJob(c *gin.Context) {
+	job, ok := h.loadJob(c, "read")
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"job":     job,
+		"running": h.runner.IsRunning(job.ID),
+	})
+}
+
+// PauseJob handles POST /api/v1/drains/:id/pause
+func (h *Handler) PauseJob(c *gin.Context) {
+	job, ok := h.loadJob(c, "update")
+	if !ok {
+		return
+	}
+
+	requested, err := h.runner.RequestStop(job.ID, StatusPaused)
+	if err != nil {
+		log.Errorf("Failed to pause drain job: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to pause drain job"})
+		return
+	}
+	if !requested {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("drain job is %s, not running", job.Status)})
+		return
+	}
+
+	h.audit(c, audit.EventAuditResourceUpdated, job, "paused")
+	c.JSON(http.StatusOK, gin.H{"message": "drain job pausing"})
+}
+
+// ResumeJob handles POST /api/v1/drains/:id/resume (also starts a scheduled job early)
+func (h *Handler) ResumeJob(c *gin.Context) {
+	job, ok := h.loadJob(c, "update")
+	if !ok {
+		return
+	}
+
+	var req ResumeRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	now := time.Now()
+	if req.WindowEnd != nil {
+		if !req.WindowEnd.After(now) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "window_end must be in the future"})
+			return
+		}
+		job.WindowEnd = req.WindowEnd
+	}
+	if job.WindowEnd != nil && !job.WindowEnd.After(now) {
+		c.JSON(http.StatusConflict, gin.H{"error": "drain window has ended; resume with a new window_end"})
+		return
+	}
+
+	if err := h.runner.Run(job); err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.audit(c, audit.EventAuditResourceUpdated, job, "resumed")
+	c.JSON(http.StatusOK, gin.H{"message": "drain job started", "job": job})
+}
+
+// CancelJob handles POST /api/v1/drains/:id/cancel
+func (h *Handler) CancelJob(c *gin.Context) {
+	job, ok := h.loadJob(c, "update")
+	if !ok {
+		return
+	}
+
+	switch job.Status {
+	case StatusCompleted, StatusFailed, StatusCancelled:
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("drain job is already %s", job.Status)})
+		return
+	}
+
+	cancelled, err := h.runner.Cancel(job.ID)
+	if err != nil {
+		log.Errorf("Failed to cancel drain job: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to cancel drain job"})
+		return
+	}
+	if !cancelled {
+		c.JSON(http.StatusConflict, gin.H{"error": "drain job has already ended"})
+		return
+	}
+
+	h.audit(c, audit.EventAuditResourceUpdated, job, "cancelled")
+	c.JSON(http.StatusOK, gin.H{"message": "drain job cancelled"})
+}
+
+// loadJob loads the job named in the route if the caller's scope allows the action on the
+// nodes of its cluster, writing the error response when it cannot
+func (h *Handler) loadJob(c *gin.Context, action string) (*db.DrainJob, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid drain job ID"})
+		return nil, false
+	}
+
+	job, err := h.db.GetDrainJob(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	if !auth.CheckClusterScope(c, job.ClusterName, "nodes", "", action) {
+		return nil, false
+	}
+	return job, true
+}
+
+func (h *Handler) audit(c *gin.Context, event string, job *db.DrainJob, action string) {
+	if userID, exists := c.Get("user_id"); exists {
+		username, _ := c.Get("username")
+		email, _ := c.Get("email")
+
+		audit.Log(c, event, userID.(int), username.(string), email.(string),
+			fmt.Sprintf("Drain job %d %s for cluster %s", job.ID, action, job.ClusterName),
+			map[string]interface{}{
+				"cluster_name": job.ClusterName,
+				"job_id":       job.ID,
+				"nodes":        job.Nodes,
+				"action":       action,
+			})
+	}
+}
@@ -0,0 +1,124 @@
+package drain
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+func TestJobRequestValidate(t *testing.T) {
+	now := time.Now()
+	at := func(d time.Duration) *time.Time {
+		t := now.Add(d)
+		return &t
+	}
+
+	tests := []struct {
+		name    string
+		req     JobRequest
+		wantErr bool
+	}{
+		{"defaults", JobRequest{Nodes: []string{"node-1", "node-2"}}, false},
+		{"no nodes", JobRequest{}, true},
+		{"repeated node", JobRequest{Nodes: []string{"node-1", "node-1"}}, true},
+		{"empty node", JobRequest{Nodes: []string{""}}, true},
+		{"parallelism too high", JobRequest{Nodes: []string{"node-1"}, Parallelism: maxParallelism + 1}, true},
+		{"negative timeout", JobRequest{Nodes: []string{"node-1"}, TimeoutSeconds: -1}, true},
+		{"timeout too long", JobRequest{Nodes: []string{"node-1"}, TimeoutSeconds: int(maxTimeout.Seconds()) + 1}, true},
+		{"scheduled in the past", JobRequest{Nodes: []string{"node-1"}, ScheduledAt: at(-time.Minute)}, true},
+		{"window", JobRequest{Nodes: []string{"node-1"}, ScheduledAt: at(time.Hour), WindowEnd: at(2 * time.Hour)}, false},
+		{"window ends before it starts", JobRequest{Nodes: []string{"node-1"}, ScheduledAt: at(time.Hour), WindowEnd: at(30 * time.Minute)}, true},
+		{"window already ended", JobRequest{Nodes: []string{"node-1"}, WindowEnd: at(-time.Minute)}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.validate(now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validate() = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && (tt.req.Parallelism != 1 || tt.req.TimeoutSeconds != int(defaultTimeout.Seconds())) {
+				t.Errorf("defaults = parallelism %d, timeout %ds", tt.req.Parallelism, tt.req.TimeoutSeconds)
+			}
+		})
+	}
+}
+
+// newRouter serves the job routes of a handler
+func newRouter(h *Handler) func(method, path, body string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/drains/:id/resume", h.ResumeJob)
+	router.POST("/drains/:id/cancel", h.CancelJob)
+
+	return func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+}
+
+func TestResumeJobWindowEnd(t *testing.T) {
+	database, manager, evict := newCluster(t)
+	r := newRunner(database, manager)
+	do := newRouter(NewHandler(database, manager, r))
+
+	ended := time.Now().Add(-time.Minute)
+	job := createJob(t, database, &db.DrainJob{Status: StatusPaused, WindowEnd: &ended, Error: "drain window ended"})
+	path := fmt.Sprintf("/drains/%d/resume", job.ID)
+
+	if w := do(http.MethodPost, path, ""); w.Code != http.StatusConflict {
+		t.Errorf("resume after the window: %d, want 409: %s", w.Code, w.Body.String())
+	}
+	past := fmt.Sprintf(`{"window_end":%q}`, time.Now().Add(-time.Second).Format(time.RFC3339))
+	if w := do(http.MethodPost, path, past); w.Code != http.StatusBadRequest {
+		t.Errorf("resume with a past window end: %d, want 400: %s", w.Code, w.Body.String())
+	}
+
+	evict.Store(true)
+	windowEnd := time.Now().Add(time.Hour).Truncate(time.Second)
+	if w := do(http.MethodPost, path, fmt.Sprintf(`{"window_end":%q}`, windowEnd.Format(time.RFC3339))); w.Code != http.StatusOK {
+		t.Fatalf("resume with a new window end: %d, want 200: %s", w.Code, w.Body.String())
+	}
+	completed := waitForStatus(t, database, job.ID, StatusCompleted)
+	if !completed.WindowEnd.Equal(windowEnd) || completed.Error != "" {
+		t.Errorf("resumed job = window end %v, error %q; want %v", completed.WindowEnd, completed.Error, windowEnd)
+	}
+}
+
+func TestCancelScheduledJob(t *testing.T) {
+	database, manager, _ := newCluster(t)
+	r := newRunner(database, manager)
+	do := newRouter(NewHandler(database, manager, r))
+
+	scheduledAt := time.Now().Add(time.Hour)
+	job := createJob(t, database, &db.DrainJob{ScheduledAt: &scheduledAt})
+	path := fmt.Sprintf("/drains/%d/cancel", job.ID)
+
+	if w := do(http.MethodPost, path, ""); w.Code != http.StatusOK {
+		t.Fatalf("cancel: %d, want 200: %s", w.Code, w.Body.String())
+	}
+	cancelled, err := database.GetDrainJob(job.ID)
+	if err != nil || cancelled.Status != StatusCancelled || cancelled.CompletedAt == nil {
+		t.Errorf("cancelled job = %+v, %v; want cancelled and completed", cancelled, err)
+	}
+
+	// A cancelled job is never started
+	if err := r.Run(cancelled); err == nil {
+		t.Error("Run() of a cancelled job succeeded")
+	}
+	if w := do(http.MethodPost, path, ""); w.Code != http.StatusConflict {
+		t.Errorf("second cancel: %d, want 409: %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,450 @@
+package drain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/sonnguyen/kubelens/internal/cluster"
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+// Job statuses
+const (
+	StatusScheduled = "scheduled"
+	StatusRunning   = "running"
+	StatusPaused    = "paused"
+	StatusCompleted = "completed"
+	StatusFailed    = "failed"
+	StatusCancelled = "cancelled"
+)
+
+const (
+	// defaultTimeout bounds how long a single node may take to empty
+	defaultTimeout = 15 * time.Minute
+	// scheduleInterval is how often scheduled jobs are checked for a started window
+	scheduleInterval = 30 * time.Second
+	// heartbeatInterval is how often a server renews its lease on the jobs it runs and
+	// checks them for a pause or cancel asked of another server
+	heartbeatInterval = 10 * time.Second
+	// leaseTimeout is how long a lease lasts without renewal before the job is paused
+	leaseTimeout = time.Minute
+)
+
+// errNotClaimed is returned by Run when another request or server changed the job first
+var errNotClaimed = errors.New("drain job is no longer scheduled or paused")
+
+// NodeProgress records the outcome of draining one node of a job
+type NodeProgress struct {
+	Node       string               `json:"node"`
+	Status     string               `json:"status"` // drained, failed
+	Result     *cluster.DrainResult `json:"result,omitempty"`
+	Error      string               `json:"error,omitempty"`
+	FinishedAt time.Time            `json:"finished_at"`
+}
+
+// MaintenanceMode reports whether changes to clusters are suspended (policy.MaintenanceMode)
+type MaintenanceMode interface {
+	Enabled() bool
+}
+
+// Runner executes drain jobs, one goroutine per running job, and starts scheduled jobs
+// when their window opens. Replicas sharing the database share the jobs: a job is run by
+// the server that claims it, which holds a lease on it while it runs.
+type Runner struct {
+	db                *db.DB
+	clusterManager    *cluster.Manager
+	maintenance       MaintenanceMode
+	owner             string // name of this server in the leases
+	heartbeatInterval time.Duration
+	leaseTimeout      time.Duration
+	done              chan struct{}
+
+	mu      sync.Mutex
+	running map[uint]*runningJob
+}
+
+type runningJob struct {
+	cancel     context.CancelFunc
+	stopStatus string
+	lost       bool // the lease was lost; the job is no longer this server's to save
+}
+
+// NewRunner creates a drain job runner. Jobs whose server stopped renewing its lease are
+// marked paused by the scheduler.
+func NewRunner(database *db.DB, clusterManager *cluster.Manager) *Runner {
+	return &Runner{
+		db:                database,
+		clusterManager:    clusterManager,
+		owner:             db.NewLeaseOwner(),
+		heartbeatInterval: heartbeatInterval,
+		leaseTimeout:      leaseTimeout,
+		done:              make(chan struct{}),
+		running:           make(map[uint]*runningJob),
+	}
+}
+
+// SetMaintenanceMode makes the runner hold scheduled jobs and refuse to start jobs while
+// maintenance mode is on. It must be called before Start.
+func (r *Runner) SetMaintenanceMode(maintenance MaintenanceMode) {
+	r.maintenance = maintenance
+}
+
+// inMaintenance reports whether maintenance mode is on
+func (r *Runner) inMaintenance() bool {
+	return r.maintenance != nil && r.maintenance.Enabled()
+}
+
+// Start begins checking for scheduled jobs whose window has opened, and for running jobs
+// whose server stopped
+func (r *Runner) Start() {
+	go func() {
+		ticker := time.NewTicker(scheduleInterval)
+		defer ticker.Stop()
+
+		r.pauseStale()
+		r.startDue()
+		for {
+			select {
+			case <-ticker.C:
+				r.pauseStale()
+				r.startDue()
+			case <-r.done:
+				return
+			}
+		}
+	}()
+
+	log.Infof("✅ Drain scheduler started (interval: %v)", scheduleInterval)
+}
+
+// Stop stops the scheduler and pauses the running jobs
+func (r *Runner) Stop() {
+	close(r.done)
+
+	r.mu.Lock()
+	for _, rj := range r.running {
+		rj.stopStatus = StatusPaused
+		rj.cancel()
+	}
+	r.mu.Unlock()
+	log.Info("Drain scheduler stopped")
+}
+
+// pauseStale pauses the running jobs whose server stopped renewing its lease, e.g. because
+// it was restarted
+func (r *Runner) pauseStale() {
+	if n, err := r.db.PauseStaleDrainJobs(time.Now().Add(-r.leaseTimeout)); err != nil {
+		log.Errorf("Failed to pause interrupted drain jobs: %v", err)
+	} else if n > 0 {
+		log.Warnf("Paused %d drain jobs whose server stopped", n)
+	}
+}
+
+// startDue starts the scheduled jobs whose window has opened. A job whose window closed
+// before it could start (the server was down) fails. Nothing is started in maintenance
+// mode; the jobs wait for it to end.
+func (r *Runner) startDue() {
+	if r.inMaintenance() {
+		return
+	}
+	now := time.Now()
+	jobs, err := r.db.ListDueDrainJobs(now)
+	if err != nil {
+		log.Errorf("Failed to list scheduled drain jobs: %v", err)
+		return
+	}
+	for _, job := range jobs {
+		if job.WindowEnd != nil && !now.Before(*job.WindowEnd) {
+			r.failScheduled(job, "drain window ended before the job could start")
+			continue
+		}
+		if err := r.Run(job); err != nil && !errors.Is(err, errNotClaimed) {
+			log.Warnf("Failed to start scheduled drain job %d: %v", job.ID, err)
+		}
+	}
+}
+
+// Run runs (or resumes) a job from its current node, saving its window end. It fails with
+// errNotClaimed when another request or server started, paused or cancelled the job first.
+func (r *Runner) Run(job *db.DrainJob) error {
+	switch job.Status {
+	case StatusScheduled, StatusPaused:
+	default:
+		return fmt.Errorf("drain job is %s and cannot be started", job.Status)
+	}
+	if r.inMaintenance() {
+		return fmt.Errorf("maintenance mode is on; drain jobs cannot be started")
+	}
+
+	r.mu.Lock()
+	if _, ok := r.running[job.ID]; ok {
+		r.mu.Unlock()
+		return fmt.Errorf("drain job is already running")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	rj := &runningJob{cancel: cancel, stopStatus: StatusPaused}
+	r.running[job.ID] = rj
+	r.mu.Unlock()
+
+	claimed, err := r.db.ClaimDrainJob(job, r.owner, time.Now())
+	if err != nil || !claimed {
+		r.finish(job.ID)
+		if err != nil {
+			return err
+		}
+		return errNotClaimed
+	}
+
+	go r.run(ctx, rj, job)
+	return nil
+}
+
+// RequestStop stops a running job, on whichever server runs it, leaving it with the given
+// status (paused or cancelled). It returns false if the job is not running.
+func (r *Runner) RequestStop(jobID uint, status string) (bool, error) {
+	requested, err := r.db.TransitionDrainJob(jobID, []string{StatusRunning}, map[string]interface{}{"stop_request": status})
+	if err != nil || !requested {
+		return false, err
+	}
+	// A job run here stops at once; one run elsewhere at the next heartbeat of its server
+	r.Interrupt(jobID, status)
+	return true, nil
+}
+
+// Cancel cancels a scheduled or paused job, or stops a running one as cancelled. It
+// returns false if the job has ended.
+func (r *Runner) Cancel(jobID uint) (bool, error) {
+	cancelled, err := r.db.TransitionDrainJob(jobID, []string{StatusScheduled, StatusPaused}, map[string]interface{}{
+		"status":       StatusCancelled,
+		"completed_at": time.Now(),
+	})
+	if err != nil || cancelled {
+		return cancelled, err
+	}
+	return r.RequestStop(jobID, StatusCancelled)
+}
+
+// Interrupt stops a job running in this process, leaving it with the given status (paused
+// or cancelled). It returns false if the job is not running in this process.
+func (r *Runner) Interrupt(jobID uint, status string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rj, ok := r.running[jobID]
+	if !ok {
+		return false
+	}
+	rj.stopStatus = status
+	rj.cancel()
+	return true
+}
+
+// heartbeat renews the lease on a running job until ctx ends, stopping the job when
+// another server asks for it to be paused or cancelled, or when the lease was lost
+func (r *Runner) heartbeat(ctx context.Context, rj *runningJob, jobID uint) {
+	ticker := time.NewTicker(r.heartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		stop, held, err := r.db.HeartbeatDrainJob(jobID, r.owner, time.Now())
+		if err != nil {
+			log.Warnf("Failed to renew the lease on drain job %d: %v", jobID, err)
+			continue
+		}
+		if !held {
+			r.mu.Lock()
+			rj.lost = true
+			r.mu.Unlock()
+			rj.cancel()
+			return
+		}
+		if stop != "" {
+			r.Interrupt(jobID, stop)
+			return
+		}
+	}
+}
+
+// IsRunning reports whether a job is executing in this process
+func (r *Runner) IsRunning(jobID uint) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.running[jobID]
+	return ok
+}
+
+func (r *Runner) finish(jobID uint) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if rj, ok := r.running[jobID]; ok {
+		rj.cancel()
+		delete(r.running, jobID)
+	}
+}
+
+// run drains the nodes starting at job.CurrentNode, persisting progress after each node.
+// The job pauses when a node fails to drain or the window ends.
+func (r *Runner) run(ctx context.Context, rj *runningJob, job *db.DrainJob) {
+	defer r.finish(job.ID)
+
+	var nodes []string
+	if err := json.Unmarshal(job.Nodes, &nodes); err != nil {
+		r.fail(job, fmt.Sprintf("invalid job nodes: %v", err))
+		return
+	}
+	var progress []NodeProgress
+	if len(job.Progress) > 0 {
+		_ = json.Unmarshal(job.Progress, &progress)
+	}
+
+	client, err := r.clusterManager.GetClient(job.ClusterName)
+	if err != nil {
+		r.fail(job, err.Error())
+		return
+	}
+	go r.heartbeat(ctx, rj, job.ID)
+
+	// The window bounds the whole job; evictions still in flight when it ends are abandoned
+	windowCtx := ctx
+	if job.WindowEnd != nil {
+		var cancel context.CancelFunc
+		windowCtx, cancel = context.WithDeadline(ctx, *job.WindowEnd)
+		defer cancel()
+	}
+	timeout := time.Duration(job.TimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	opts := cluster.DrainOptions{Parallelism: job.Parallelism, Timeout: timeout}
+
+	log.Infof("Drain job %d for cluster %s running from node %d/%d", job.ID, job.ClusterName, job.CurrentNode+1, len(nodes))
+
+	for job.CurrentNode < len(nodes) {
+		node := nodes[job.CurrentNode]
+		if r.interrupted(ctx, windowCtx, rj, job) {
+			return
+		}
+
+		entry := NodeProgress{Node: node, Status: "drained"}
+		result, err := cluster.DrainNodeWithOptions(windowCtx, client, node, opts)
+		if err == nil {
+			entry.Result = result
+			err = cluster.WaitForNodeDrained(windowCtx, client, node, timeout)
+		}
+		if r.interrupted(ctx, windowCtx, rj, job) {
+			return
+		}
+		entry.FinishedAt = time.Now()
+		if err != nil {
+			entry.Status = "failed"
+			entry.Error = err.Error()
+		}
+		progress = append(progress, entry)
+		job.Progress = marshalJSON(progress)
+
+		if err != nil {
+			// Leave the job paused so the operator can resolve the blocker and resume
+			job.Status = StatusPaused
+			job.Error = fmt.Sprintf("node %s: %v", node, err)
+			r.save(job)
+			log.Warnf("Drain job %d paused: %s", job.ID, job.Error)
+			return
+		}
+		job.CurrentNode++
+		r.save(job)
+	}
+
+	now := time.Now()
+	job.Status = StatusCompleted
+	job.CompletedAt = &now
+	r.save(job)
+	log.Infof("Drain job %d for cluster %s completed", job.ID, job.ClusterName)
+}
+
+// interrupted persists the requested stop status if the job was stopped, or pauses the
+// job if its window has ended
+func (r *Runner) interrupted(ctx, windowCtx context.Context, rj *runningJob, job *db.DrainJob) bool {
+	if ctx.Err() != nil {
+		r.mu.Lock()
+		status, lost := rj.stopStatus, rj.lost
+		r.mu.Unlock()
+
+		if lost {
+			log.Warnf("Drain job %d stopped: this server no longer holds its lease", job.ID)
+			return true
+		}
+		job.Status = status
+		if status == StatusCancelled {
+			now := time.Now()
+			job.CompletedAt = &now
+		}
+		r.save(job)
+		log.Infof("Drain job %d %s", job.ID, status)
+		return true
+	}
+
+	if errors.Is(windowCtx.Err(), context.DeadlineExceeded) {
+		job.Status = StatusPaused
+		job.Error = "drain window ended"
+		r.save(job)
+		log.Infof("Drain job %d paused: window ended", job.ID)
+		return true
+	}
+	return false
+}
+
+func (r *Runner) fail(job *db.DrainJob, msg string) {
+	now := time.Now()
+	job.Status = StatusFailed
+	job.Error = msg
+	job.CompletedAt = &now
+	r.save(job)
+	log.Errorf("Drain job %d failed: %s", job.ID, msg)
+}
+
+// failScheduled fails a job that has not started
+func (r *Runner) failScheduled(job *db.DrainJob, msg string) {
+	now := time.Now()
+	failed, err := r.db.TransitionDrainJob(job.ID, []string{StatusScheduled}, map[string]interface{}{
+		"status":       StatusFailed,
+		"error":        msg,
+		"completed_at": now,
+	})
+	if err != nil {
+		log.Errorf("Failed to save drain job %d: %v", job.ID, err)
+		return
+	}
+	if failed {
+		job.Status, job.Error, job.CompletedAt = StatusFailed, msg, &now
+		log.Errorf("Drain job %d failed: %s", job.ID, msg)
+	}
+}
+
+// save saves a job run by this server, releasing it once it stops running
+func (r *Runner) save(job *db.DrainJob) {
+	if job.Status != StatusRunning {
+		job.Owner = ""
+	}
+	if saved, err := r.db.SaveDrainJob(job, r.owner); err != nil {
+		log.Errorf("Failed to save drain job %d: %v", job.ID, err)
+	} else if !saved {
+		log.Warnf("Drain job %d not saved: this server no longer holds its lease", job.ID)
+	}
+}
+
+func marshalJSON(v interface{}) db.JSON {
+	data, _ := json.Marshal(v)
+	return db.JSON(data)
+}
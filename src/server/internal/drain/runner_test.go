@@ -0,0 +1,172 @@
+package drain
+
+import (
+	"errors"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/sonnguyen/kubelens/internal/cluster"
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+// newCluster returns a database and a cluster "prod" with node-1 running pod web, whose
+// eviction is refused until evict is set, and then removes the pod
+func newCluster(t *testing.T) (*db.DB, *cluster.Manager, *atomic.Bool) {
+	database, err := db.New(filepath.Join(t.TempDir(), "kubelens.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	client := fake.NewSimpleClientset(
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop"}, Spec: corev1.PodSpec{NodeName: "node-1"}},
+	)
+	evict := &atomic.Bool{}
+	client.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+		if !evict.Load() {
+			return true, nil, apierrors.NewTooManyRequests("disruption budget", 1)
+		}
+		err := client.Tracker().Delete(corev1.SchemeGroupVersion.WithResource("pods"), "shop", "web")
+		return true, nil, err
+	})
+
+	manager := cluster.NewManager(database)
+	manager.AddClusterFromClients("prod", client, nil)
+	return database, manager, evict
+}
+
+func newRunner(database *db.DB, manager *cluster.Manager) *Runner {
+	r := NewRunner(database, manager)
+	r.heartbeatInterval = 20 * time.Millisecond
+	return r
+}
+
+func createJob(t *testing.T, database *db.DB, job *db.DrainJob) *db.DrainJob {
+	job.ClusterName = "prod"
+	job.Nodes = marshalJSON([]string{"node-1"})
+	job.Parallelism = 1
+	job.TimeoutSeconds = 60
+	if job.Status == "" {
+		job.Status = StatusScheduled
+	}
+	if err := database.CreateDrainJob(job); err != nil {
+		t.Fatal(err)
+	}
+	return job
+}
+
+// waitForStatus waits for the stored job to reach a status and returns it
+func waitForStatus(t *testing.T, database *db.DB, id uint, status string) *db.DrainJob {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		job, err := database.GetDrainJob(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if job.Status == status {
+			return job
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("drain job %d is %s (%s), want %s", id, job.Status, job.Error, status)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+type maintenanceMode bool
+
+func (m maintenanceMode) Enabled() bool { return bool(m) }
+
+func TestRunnerWindowEnd(t *testing.T) {
+	database, manager, evict := newCluster(t)
+	r := newRunner(database, manager)
+
+	// The eviction is refused until the window ends, which pauses the job
+	windowEnd := time.Now().Add(200 * time.Millisecond)
+	job := createJob(t, database, &db.DrainJob{WindowEnd: &windowEnd})
+	if err := r.Run(job); err != nil {
+		t.Fatal(err)
+	}
+	paused := waitForStatus(t, database, job.ID, StatusPaused)
+	if paused.Error != "drain window ended" || paused.Owner != "" || paused.CurrentNode != 0 {
+		t.Errorf("job after its window = %q, owner %q, node %d; want paused at node 0 and released", paused.Error, paused.Owner, paused.CurrentNode)
+	}
+
+	// Resuming with a new window end drains the node
+	evict.Store(true)
+	windowEnd = time.Now().Add(time.Minute)
+	paused.WindowEnd = &windowEnd
+	if err := r.Run(paused); err != nil {
+		t.Fatal(err)
+	}
+	completed := waitForStatus(t, database, job.ID, StatusCompleted)
+	if completed.CurrentNode != 1 || completed.Error != "" || !completed.WindowEnd.Equal(windowEnd) {
+		t.Errorf("resumed job = node %d, error %q, window end %v; want node 1 drained by %v", completed.CurrentNode, completed.Error, completed.WindowEnd, windowEnd)
+	}
+}
+
+func TestRunnerReplicas(t *testing.T) {
+	database, manager, _ := newCluster(t)
+	first, second := newRunner(database, manager), newRunner(database, manager)
+
+	// Only one replica claims a job
+	scheduledAt := time.Now()
+	job := createJob(t, database, &db.DrainJob{ScheduledAt: &scheduledAt})
+	stale := *job
+	if err := first.Run(job); err != nil {
+		t.Fatal(err)
+	}
+	if err := second.Run(&stale); !errors.Is(err, errNotClaimed) {
+		t.Errorf("second replica Run() = %v, want %v", err, errNotClaimed)
+	}
+
+	// The lease of a running job is kept
+	second.pauseStale()
+	if job, _ := database.GetDrainJob(job.ID); job.Status != StatusRunning || job.Owner != first.owner {
+		t.Errorf("job with a held lease = %s owned by %q, want running on the first replica", job.Status, job.Owner)
+	}
+
+	// A pause asked of the other replica reaches the one running the job
+	if requested, err := second.RequestStop(job.ID, StatusPaused); err != nil || !requested {
+		t.Fatalf("RequestStop() = %v, %v", requested, err)
+	}
+	waitForStatus(t, database, job.ID, StatusPaused)
+
+	// A job whose server stopped renewing its lease is paused by the others
+	heartbeat := time.Now().Add(-2 * leaseTimeout)
+	orphan := createJob(t, database, &db.DrainJob{Status: StatusRunning, Owner: "gone", HeartbeatAt: &heartbeat})
+	second.pauseStale()
+	if paused := waitForStatus(t, database, orphan.ID, StatusPaused); paused.Owner != "" {
+		t.Errorf("stale job owner = %q, want released", paused.Owner)
+	}
+}
+
+func TestRunnerMaintenanceMode(t *testing.T) {
+	database, manager, _ := newCluster(t)
+	r := newRunner(database, manager)
+	r.SetMaintenanceMode(maintenanceMode(true))
+
+	scheduledAt := time.Now()
+	job := createJob(t, database, &db.DrainJob{ScheduledAt: &scheduledAt})
+	if err := r.Run(job); err == nil {
+		t.Error("Run() in maintenance mode succeeded, want an error")
+	}
+	r.startDue()
+	if job, _ := database.GetDrainJob(job.ID); job.Status != StatusScheduled {
+		t.Errorf("due job in maintenance mode = %s, want it left scheduled", job.Status)
+	}
+}
@@ -0,0 +1,323 @@
+// Package events watches the Kubernetes Events of every connected cluster, records a
+// deduplicated history of them and fans new ones out to WebSocket clients and the
+// notification channels.
+package events
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/sonnguyen/kubelens/internal/cluster"
+	"github.com/sonnguyen/kubelens/internal/db"
+	"github.com/sonnguyen/kubelens/internal/diagnostics"
+	"github.com/sonnguyen/kubelens/internal/notify"
+	"github.com/sonnguyen/kubelens/internal/ws"
+)
+
+const (
+	// MessageType is the WebSocket message type of a recorded event, published on the
+	// topic TopicPrefix/<cluster>/<namespace>/<kind of the object>
+	MessageType = "cluster_event"
+	TopicPrefix = ws.EventsTopic
+
+	// syncInterval is how often watches are started for new clusters and stopped for
+	// removed ones
+	syncInterval = 30 * time.Second
+	// maxBackoff bounds the wait before a failed watch is retried
+	maxBackoff = time.Minute
+	// renotifyAfter is how long a warning must have been quiet before a recurrence is
+	// sent to the notification channels again
+	renotifyAfter = time.Hour
+)
+
+// Publisher publishes messages to WebSocket clients (ws.Hub)
+type Publisher interface {
+	Publish(topic, msgType string, payload interface{})
+}
+
+// Watcher keeps a watch on the Events of each connected cluster
+type Watcher struct {
+	db         *db.DB
+	hub        Publisher
+	dispatcher *notify.Dispatcher
+	retention  time.Duration
+	done       chan bool
+
+	// clusterNames and client resolve the connected clusters
+	clusterNames func() []string
+	client       func(name string) (kubernetes.Interface, error)
+
+	mu      sync.Mutex
+	watches map[string]context.CancelFunc // by cluster
+}
+
+// NewWatcher creates a new event watcher keeping events for the retention period
+func NewWatcher(database *db.DB, clusterManager *cluster.Manager, hub Publisher, dispatcher *notify.Dispatcher, retention time.Duration) *Watcher {
+	if retention <= 0 {
+		retention = 72 * time.Hour
+	}
+	return &Watcher{
+		db:           database,
+		hub:          hub,
+		dispatcher:   dispatcher,
+		retention:    retention,
+		done:         make(chan bool),
+		clusterNames: clusterManager.ClusterNames,
+		client:       clusterManager.GetClient,
+		watches:      map[string]context.CancelFunc{},
+	}
+}
+
+// Start starts watching the connected clusters and the retention loop
+func (w *Watcher) Start() {
+	go func() {
+		syncTicker := time.NewTicker(syncInterval)
+		retentionTicker := time.NewTicker(1 * time.Hour)
+		defer syncTicker.Stop()
+		defer retentionTicker.Stop()
+
+		w.sync()
+		for {
+			select {
+			case <-syncTicker.C:
+				w.sync()
+			case <-retentionTicker.C:
+				if n, err := w.db.DeleteClusterEventsOlderThan(time.Now().Add(-w.retention)); err != nil {
+					log.Warnf("Failed to delete old cluster events: %v", err)
+				} else if n > 0 {
+					log.Infof("Deleted %d cluster events older than %v", n, w.retention)
+				}
+			case <-w.done:
+				w.mu.Lock()
+				for name, cancel := range w.watches {
+					cancel()
+					delete(w.watches, name)
+				}
+				w.mu.Unlock()
+				return
+			}
+		}
+	}()
+
+	log.Infof("✅ Cluster event watcher started (retention: %v)", w.retention)
+}
+
+// Stop stops all watches
+func (w *Watcher) Stop() {
+	close(w.done)
+	log.Info("Cluster event watcher stopped")
+}
+
+// sync starts a watch for every connected cluster without one and stops the watches of
+// clusters that are gone
+func (w *Watcher) sync() {
+	connected := map[string]bool{}
+	for _, name := range w.clusterNames() {
+		connected[name] = true
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for name, cancel := range w.watches {
+		if !connected[name] {
+			cancel()
+			delete(w.watches, name)
+			log.Infof("Stopped watching events of cluster %s", name)
+		}
+	}
+	for name := range connected {
+		if _, ok := w.watches[name]; ok {
+			continue
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		w.watches[name] = cancel
+		go w.watchCluster(ctx, name)
+	}
+}
+
+// watchCluster watches the events of a cluster until ctx is cancelled, retrying with
+// backoff when the watch fails
+func (w *Watcher) watchCluster(ctx context.Context, clusterName string) {
+	defer diagnostics.TrackWatch("cluster_events")()
+
+	backoff := time.Second
+	for ctx.Err() == nil {
+		client, err := w.client(clusterName)
+		if err == nil {
+			err = w.watch(ctx, clusterName, client)
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		if err == nil {
+			backoff = time.Second
+			continue
+		}
+
+		log.Debugf("Event watch of cluster %s failed, retrying in %v: %v", clusterName, backoff, err)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return
+		}
+		if backoff *= 2; backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// watch lists the current events of a cluster, recording them without fanning them out,
+// then records and fans out changes until the resource version expires or the watch fails
+func (w *Watcher) watch(ctx context.Context, clusterName string, client kubernetes.Interface) error {
+	list, err := client.CoreV1().Events(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for i := range list.Items {
+		w.record(clusterName, &list.Items[i], false)
+	}
+
+	resourceVersion := list.ResourceVersion
+	for ctx.Err() == nil {
+		watcher, err := client.CoreV1().Events(metav1.NamespaceAll).Watch(ctx, metav1.ListOptions{
+			ResourceVersion:     resourceVersion,
+			AllowWatchBookmarks: true,
+		})
+		if err != nil {
+			return err
+		}
+		started := time.Now()
+		for event := range watcher.ResultChan() {
+			switch event.Type {
+			case watch.Added, watch.Modified:
+				if e, ok := event.Object.(*corev1.Event); ok {
+					resourceVersion = e.ResourceVersion
+					w.record(clusterName, e, true)
+				}
+			case watch.Bookmark:
+				if e, ok := event.Object.(*corev1.Event); ok {
+					resourceVersion = e.ResourceVersion
+				}
+			case watch.Error:
+				watcher.Stop()
+				err := apierrors.FromObject(event.Object)
+				if statusErr, ok := err.(*apierrors.StatusError); ok && statusErr.Status().Code == http.StatusGone {
+					// The resource version expired: list again
+					return nil
+				}
+				return err
+			}
+		}
+		watcher.Stop()
+		if time.Since(started) < time.Second {
+			return fmt.Errorf("watch closed immediately")
+		}
+	}
+	return nil
+}
+
+// record stores an event and, when it is new or recurred, publishes it to WebSocket
+// clients and sends warnings to the notification channels
+func (w *Watcher) record(clusterName string, event *corev1.Event, fanOut bool) {
+	stored := normalize(clusterName, event)
+	changed, previous, err := w.db.RecordClusterEvent(stored)
+	if err != nil {
+		log.Warnf("Failed to record event of cluster %s: %v", clusterName, err)
+		return
+	}
+	if !changed || !fanOut {
+		return
+	}
+
+	if w.hub != nil {
+		w.hub.Publish(ws.Topic(TopicPrefix, clusterName, stored.Namespace, stored.Kind), MessageType, stored)
+	}
+	if w.dispatcher != nil && stored.Type == corev1.EventTypeWarning &&
+		(previous.IsZero() || stored.LastSeen.Sub(previous) >= renotifyAfter) {
+		w.dispatcher.Dispatch(&notify.Event{
+			Type:      "event.warning",
+			Severity:  notify.SeverityWarning,
+			Title:     fmt.Sprintf("%s: %s/%s", stored.Reason, stored.Kind, stored.Name),
+			Message:   stored.Message,
+			Cluster:   clusterName,
+			Namespace: stored.Namespace,
+			Resource:  stored.Kind + "/" + stored.Name,
+			Labels:    map[string]string{"reason": stored.Reason},
+			Time:      stored.LastSeen,
+		})
+	}
+}
+
+// normalize converts an Event of either API generation (core/v1 fields or the
+// events.k8s.io series) into a history row
+func normalize(clusterName string, event *corev1.Event) *db.ClusterEvent {
+	lastSeen := event.LastTimestamp.Time
+	if lastSeen.IsZero() && event.Series != nil {
+		lastSeen = event.Series.LastObservedTime.Time
+	}
+	if lastSeen.IsZero() {
+		lastSeen = event.EventTime.Time
+	}
+	if lastSeen.IsZero() {
+		lastSeen = event.CreationTimestamp.Time
+	}
+	firstSeen := event.FirstTimestamp.Time
+	if firstSeen.IsZero() {
+		firstSeen = event.EventTime.Time
+	}
+	if firstSeen.IsZero() || firstSeen.After(lastSeen) {
+		firstSeen = lastSeen
+	}
+
+	count := event.Count
+	if count == 0 && event.Series != nil {
+		count = event.Series.Count
+	}
+	if count == 0 {
+		count = 1
+	}
+
+	source := event.Source.Component
+	if source == "" {
+		source = event.ReportingController
+	}
+	if event.Source.Host != "" {
+		source += "/" + event.Source.Host
+	}
+
+	object := event.InvolvedObject
+	namespace := object.Namespace
+	if namespace == "" {
+		namespace = event.Namespace
+	}
+	sum := sha256.Sum256([]byte(strings.Join([]string{namespace, object.Kind, object.Name, event.Type, event.Reason, event.Message}, "\x00")))
+
+	return &db.ClusterEvent{
+		ClusterName: clusterName,
+		Fingerprint: hex.EncodeToString(sum[:]),
+		UID:         string(event.UID),
+		Namespace:   namespace,
+		Kind:        object.Kind,
+		Name:        object.Name,
+		Type:        event.Type,
+		Reason:      event.Reason,
+		Message:     event.Message,
+		Source:      source,
+		Count:       count,
+		FirstSeen:   firstSeen.UTC(),
+		LastSeen:    lastSeen.UTC(),
+	}
+}
@@ -0,0 +1,71 @@
+package events
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+type recordingPublisher struct {
+	topics []string
+}
+
+func (p *recordingPublisher) Publish(topic, msgType string, payload interface{}) {
+	p.topics = append(p.topics, topic)
+}
+
+func backOffEvent(uid string, count int32, last time.Time) *corev1.Event {
+	return &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: "web-1." + uid, Namespace: "shop", UID: types.UID(uid)},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "web-1", Namespace: "shop"},
+		Type:           corev1.EventTypeWarning,
+		Reason:         "BackOff",
+		Message:        "Back-off restarting failed container",
+		Source:         corev1.EventSource{Component: "kubelet", Host: "node-1"},
+		Count:          count,
+		FirstTimestamp: metav1.NewTime(last.Add(-time.Minute)),
+		LastTimestamp:  metav1.NewTime(last),
+	}
+}
+
+func TestRecordFoldsRepeats(t *testing.T) {
+	database, err := db.New(filepath.Join(t.TempDir(), "kubelens.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	hub := &recordingPublisher{}
+	w := &Watcher{db: database, hub: hub}
+
+	t0 := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	w.record("prod", backOffEvent("a", 1, t0), true)
+	w.record("prod", backOffEvent("a", 4, t0.Add(time.Minute)), true)
+	// A replay after a re-list changes nothing
+	w.record("prod", backOffEvent("a", 4, t0.Add(time.Minute)), true)
+	// A new Event object for the same problem is folded into the same row
+	w.record("prod", backOffEvent("b", 2, t0.Add(2*time.Minute)), true)
+
+	events, err := database.ListClusterEvents(db.ClusterEventFilters{ClusterName: "prod"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("got %d events, want the repeats folded into one", len(events))
+	}
+	e := events[0]
+	if e.Count != 6 || e.UID != "b" || !e.FirstSeen.Equal(t0.Add(-time.Minute)) || !e.LastSeen.Equal(t0.Add(2*time.Minute)) {
+		t.Errorf("event = %+v, want count 6 from both objects spanning first to last occurrence", e)
+	}
+	if e.Source != "kubelet/node-1" || e.Kind != "Pod" || e.Name != "web-1" {
+		t.Errorf("event = %+v, want the involved object and source normalized", e)
+	}
+	if len(hub.topics) != 3 || hub.topics[0] != "events/prod/shop/Pod" {
+		t.Errorf("published %v, want the new event and both recurrences but not the replay", hub.topics)
+	}
+}
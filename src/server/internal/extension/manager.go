@@ -1,12 +1,12 @@
 package extension
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/httputil"
-	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -58,11 +58,14 @@ type Manager struct {
 	statuses    map[string]ExtensionStatus
 	configs     map[string]map[string]string
 	enabled     map[string]bool
+	loaded      bool // installed extensions have been loaded and not shut down
 	mu          sync.RWMutex
 
 	// HTTP proxies for extension endpoints
-	httpProxies map[string]*httputil.ReverseProxy
-	router      *gin.Engine
+	httpProxies       map[string]*httputil.ReverseProxy
+	router            *gin.Engine
+	authMiddleware    gin.HandlerFunc
+	permissionChecker func(resource, action string) gin.HandlerFunc
 }
 
 // NewManager creates a new extension manager
@@ -147,6 +150,7 @@ func (m *Manager) LoadExtensions() error {
 		}
 	}
 
+	m.loaded = true
 	return nil
 }
 
@@ -236,6 +240,40 @@ func (m *Manager) Shutdown() {
 	for _, client := range m.clients {
 		client.Kill()
 	}
+	m.loaded = false
+}
+
+// ReadinessCheck reports whether installed extensions have been loaded. Extensions that
+// failed to start are reported but do not fail the check.
+func (m *Manager) ReadinessCheck(ctx context.Context) (string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if !m.loaded {
+		return "", fmt.Errorf("extensions are not loaded")
+	}
+	running, failed := 0, 0
+	for _, status := range m.statuses {
+		switch status {
+		case StatusRunning:
+			running++
+		case StatusError:
+			failed++
+		}
+	}
+	return fmt.Sprintf("%d running, %d failed", running, failed), nil
+}
+
+// Statuses returns the runtime status of each loaded extension
+func (m *Manager) Statuses() map[string]ExtensionStatus {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	statuses := make(map[string]ExtensionStatus, len(m.statuses))
+	for name, status := range m.statuses {
+		statuses[name] = status
+	}
+	return statuses
 }
 
 // ListExtensions returns list of loaded extensions with full info
@@ -725,9 +763,12 @@ func capitalizeFirst(s string) string {
 }
 
 // RegisterHTTPProxies registers reverse proxy routes for extensions that expose HTTP endpoints
-// This should be called with the root gin.Engine to mount routes at top level
-func (m *Manager) RegisterHTTPProxies(engine *gin.Engine) {
+// This should be called with the root gin.Engine to mount routes at top level.
+// authMiddleware and permissionChecker enforce the per-route auth declared in extension metadata.
+func (m *Manager) RegisterHTTPProxies(engine *gin.Engine, authMiddleware gin.HandlerFunc, permissionChecker func(resource, action string) gin.HandlerFunc) {
 	m.router = engine
+	m.authMiddleware = authMiddleware
+	m.permissionChecker = permissionChecker
 	m.mountExtensionProxies()
 }
 
@@ -751,71 +792,14 @@ func (m *Manager) mountExtensionProxies() {
 			continue // Extension doesn't expose HTTP
 		}
 
-		m.setupExtensionProxy(name, endpoint)
-	}
-}
-
-// setupExtensionProxy creates and registers a reverse proxy for an extension
-func (m *Manager) setupExtensionProxy(name, endpoint string) {
-	target, err := url.Parse("http://" + endpoint)
-	if err != nil {
-		log.Errorf("Failed to parse endpoint URL for extension %s: %v", name, err)
-		return
-	}
-
-	proxy := httputil.NewSingleHostReverseProxy(target)
-
-	// Custom error handler
-	proxy.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
-		log.Errorf("Proxy error for extension %s: %v", name, err)
-		http.Error(w, "Extension proxy error", http.StatusBadGateway)
-	}
-
-	m.httpProxies[name] = proxy
-
-	// Mount the proxy route with custom handler that properly forwards headers
-	mountPath := m.getMountPath(name)
-	if m.router != nil {
-		proxyHandler := func(c *gin.Context) {
-			// Create a custom director that has access to the original request
-			originalDirector := proxy.Director
-			proxy.Director = func(req *http.Request) {
-				originalDirector(req)
-
-				// Strip the mount path prefix from the request
-				req.URL.Path = strings.TrimPrefix(req.URL.Path, mountPath)
-				if req.URL.Path == "" {
-					req.URL.Path = "/"
-				}
-
-				// Forward X-Forwarded-Host from incoming request or use original Host
-				forwardedHost := c.Request.Header.Get("X-Forwarded-Host")
-				if forwardedHost == "" {
-					forwardedHost = c.Request.Host
-				}
-				req.Header.Set("X-Forwarded-Host", forwardedHost)
-
-				// Forward X-Forwarded-Proto from incoming request or detect from TLS
-				forwardedProto := c.Request.Header.Get("X-Forwarded-Proto")
-				if forwardedProto == "" {
-					if c.Request.TLS != nil {
-						forwardedProto = "https"
-					} else {
-						forwardedProto = "http"
-					}
-				}
-				req.Header.Set("X-Forwarded-Proto", forwardedProto)
-
-				req.Header.Set("X-Original-URI", c.Request.RequestURI)
-			}
-
-			proxy.ServeHTTP(c.Writer, c.Request)
+		var routes []kbplugin.ProxyRoute
+		if meta, err := ext.GetMetadata(); err == nil {
+			routes = meta.ProxyRoutes
+		} else {
+			log.Warnf("Failed to get metadata for extension %s: %v", name, err)
 		}
 
-		m.router.Any(mountPath+"/*path", proxyHandler)
-		// Also handle root path without trailing wildcard
-		m.router.Any(mountPath, proxyHandler)
-		log.Infof("Mounted HTTP proxy for extension %s at %s -> %s", name, mountPath, endpoint)
+		m.setupExtensionProxy(name, endpoint, routes)
 	}
 }
 
@@ -0,0 +1,252 @@
+package extension
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+
+	kbplugin "github.com/sonnguyen/kubelens/pkg/plugin"
+)
+
+// Proxy route auth modes
+const (
+	ProxyAuthPublic        = "public"
+	ProxyAuthAuthenticated = "authenticated"
+	ProxyAuthPermission    = "permission"
+)
+
+// Identity headers set on authenticated proxy requests. Client-supplied values are always stripped.
+const (
+	headerUserID   = "X-Kubelens-User-Id"
+	headerUsername = "X-Kubelens-User"
+	headerEmail    = "X-Kubelens-Email"
+)
+
+// proxyRouter resolves the routing rule for a request path relative to the mount path
+type proxyRouter struct {
+	routes []kbplugin.ProxyRoute // sorted by descending prefix length
+}
+
+// newProxyRouter validates and sorts the routes declared in extension metadata
+func newProxyRouter(routes []kbplugin.ProxyRoute) (*proxyRouter, error) {
+	sorted := make([]kbplugin.ProxyRoute, 0, len(routes))
+	for _, r := range routes {
+		if !strings.HasPrefix(r.PathPrefix, "/") {
+			return nil, fmt.Errorf("proxy route path_prefix must start with /: %q", r.PathPrefix)
+		}
+		switch r.Auth {
+		case "":
+			r.Auth = ProxyAuthAuthenticated
+		case ProxyAuthPublic, ProxyAuthAuthenticated:
+		case ProxyAuthPermission:
+			if r.Resource == "" || r.Action == "" {
+				return nil, fmt.Errorf("proxy route %s requires resource and action for permission auth", r.PathPrefix)
+			}
+		default:
+			return nil, fmt.Errorf("proxy route %s has unknown auth mode: %s", r.PathPrefix, r.Auth)
+		}
+		sorted = append(sorted, r)
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return len(sorted[i].PathPrefix) > len(sorted[j].PathPrefix)
+	})
+	return &proxyRouter{routes: sorted}, nil
+}
+
+// match returns the route for a path (relative to the mount path and cleaned by proxyPath)
+// and the rewritten upstream path. Paths that match no route are forwarded unchanged and
+// require authentication: only routes declared public are.
+func (pr *proxyRouter) match(path string) (kbplugin.ProxyRoute, string) {
+	if path == "" {
+		path = "/"
+	}
+	for _, r := range pr.routes {
+		if !pathHasPrefix(path, r.PathPrefix) {
+			continue
+		}
+		if r.Rewrite == "" {
+			return r, path
+		}
+		rewritten := strings.TrimSuffix(r.Rewrite, "/") + strings.TrimPrefix(path, strings.TrimSuffix(r.PathPrefix, "/"))
+		if rewritten == "" {
+			rewritten = "/"
+		}
+		return r, rewritten
+	}
+	return kbplugin.ProxyRoute{Auth: ProxyAuthAuthenticated}, path
+}
+
+// proxyPath returns a request path relative to the mount path, cleaned of dot segments and
+// repeated slashes so that /public/../admin is matched, and forwarded, as /admin. A trailing
+// slash is kept.
+func proxyPath(requestPath, mountPath string) string {
+	rel := strings.TrimPrefix(requestPath, mountPath)
+	cleaned := path.Clean("/" + rel)
+	if strings.HasSuffix(rel, "/") && cleaned != "/" {
+		cleaned += "/"
+	}
+	return cleaned
+}
+
+// pathHasPrefix matches whole path segments, so "/api" matches "/api/x" but not "/apix"
+func pathHasPrefix(path, prefix string) bool {
+	prefix = strings.TrimSuffix(prefix, "/")
+	if prefix == "" {
+		return true
+	}
+	return path == prefix || strings.HasPrefix(path, prefix+"/")
+}
+
+// setupExtensionProxy creates and registers a reverse proxy for an extension.
+// WebSocket upgrades are passed through and streamed responses (SSE, chunked)
+// are flushed immediately.
+func (m *Manager) setupExtensionProxy(name, endpoint string, routes []kbplugin.ProxyRoute) {
+	target, err := url.Parse("http://" + endpoint)
+	if err != nil {
+		log.Errorf("Failed to parse endpoint URL for extension %s: %v", name, err)
+		return
+	}
+
+	router, err := newProxyRouter(routes)
+	if err != nil {
+		log.Errorf("Invalid proxy routes for extension %s: %v", name, err)
+		return
+	}
+
+	mountPath := m.getMountPath(name)
+
+	proxy := &httputil.ReverseProxy{
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			pr.SetURL(target)
+			pr.Out.Host = pr.In.Host // Preserve the original Host header
+
+			// Strip the mount path prefix and apply path rewriting rules
+			_, upstreamPath := router.match(proxyPath(pr.In.URL.Path, mountPath))
+			pr.Out.URL.Path = singleJoiningSlash(target.Path, upstreamPath)
+			pr.Out.URL.RawPath = ""
+
+			// Append the client IP to any X-Forwarded-For received from upstream proxies
+			if forwardedFor := pr.In.Header.Get("X-Forwarded-For"); forwardedFor != "" {
+				pr.Out.Header.Set("X-Forwarded-For", forwardedFor)
+			}
+			pr.SetXForwarded()
+
+			// Forward X-Forwarded-Host from incoming request or use original Host
+			forwardedHost := pr.In.Header.Get("X-Forwarded-Host")
+			if forwardedHost == "" {
+				forwardedHost = pr.In.Host
+			}
+			pr.Out.Header.Set("X-Forwarded-Host", forwardedHost)
+
+			// Forward X-Forwarded-Proto from incoming request or detect from TLS
+			forwardedProto := pr.In.Header.Get("X-Forwarded-Proto")
+			if forwardedProto == "" {
+				if pr.In.TLS != nil {
+					forwardedProto = "https"
+				} else {
+					forwardedProto = "http"
+				}
+			}
+			pr.Out.Header.Set("X-Forwarded-Proto", forwardedProto)
+
+			pr.Out.Header.Set("X-Original-URI", pr.In.RequestURI)
+		},
+		// Flush immediately so SSE and chunked responses stream to the client
+		FlushInterval: -1,
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			log.Errorf("Proxy error for extension %s: %v", name, err)
+			http.Error(w, "Extension proxy error", http.StatusBadGateway)
+		},
+	}
+
+	m.httpProxies[name] = proxy
+
+	if m.router == nil {
+		return
+	}
+
+	proxyHandler := func(c *gin.Context) {
+		route, _ := router.match(proxyPath(c.Request.URL.Path, mountPath))
+
+		// Never trust identity headers from the client
+		c.Request.Header.Del(headerUserID)
+		c.Request.Header.Del(headerUsername)
+		c.Request.Header.Del(headerEmail)
+
+		if route.Auth == ProxyAuthAuthenticated || route.Auth == ProxyAuthPermission {
+			if !m.authorizeProxyRequest(c, route) {
+				return
+			}
+
+			// Pass the caller identity instead of the kubelens token
+			c.Request.Header.Set(headerUserID, fmt.Sprintf("%d", c.GetInt("user_id")))
+			c.Request.Header.Set(headerUsername, c.GetString("username"))
+			c.Request.Header.Set(headerEmail, c.GetString("email"))
+			c.Request.Header.Del("Authorization")
+			if query := c.Request.URL.Query(); query.Has("token") {
+				query.Del("token")
+				c.Request.URL.RawQuery = query.Encode()
+			}
+		}
+
+		// Extensions may stream (SSE, chunked downloads) for longer than the server's write
+		// timeout; the upstream decides when the response ends
+		if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+			log.Debugf("Failed to clear the write deadline for extension %s: %v", name, err)
+		}
+		proxy.ServeHTTP(c.Writer, c.Request)
+	}
+
+	m.router.Any(mountPath+"/*path", proxyHandler)
+	// Also handle root path without trailing wildcard
+	m.router.Any(mountPath, proxyHandler)
+	log.Infof("Mounted HTTP proxy for extension %s at %s -> %s (%d routes)", name, mountPath, endpoint, len(router.routes))
+}
+
+// authorizeProxyRequest runs the auth middleware (and permission check) required by a route.
+// It returns false if the request was rejected.
+func (m *Manager) authorizeProxyRequest(c *gin.Context, route kbplugin.ProxyRoute) bool {
+	if m.authMiddleware == nil {
+		log.Errorf("Extension proxy route %s requires auth but no auth middleware is configured", route.PathPrefix)
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "extension route unavailable"})
+		return false
+	}
+
+	m.authMiddleware(c)
+	if c.IsAborted() {
+		return false
+	}
+
+	if route.Auth == ProxyAuthPermission {
+		if m.permissionChecker == nil {
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "extension route unavailable"})
+			return false
+		}
+		m.permissionChecker(route.Resource, route.Action)(c)
+		if c.IsAborted() {
+			return false
+		}
+	}
+	return true
+}
+
+// singleJoiningSlash joins two URL paths with exactly one slash
+func singleJoiningSlash(a, b string) string {
+	aslash := strings.HasSuffix(a, "/")
+	bslash := strings.HasPrefix(b, "/")
+	switch {
+	case aslash && bslash:
+		return a + b[1:]
+	case !aslash && !bslash:
+		return a + "/" + b
+	}
+	return a + b
+}
@@ -0,0 +1,152 @@
+package extension
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	kbplugin "github.com/sonnguyen/kubelens/pkg/plugin"
+)
+
+func TestProxyRouterMatch(t *testing.T) {
+	router, err := newProxyRouter([]kbplugin.ProxyRoute{
+		{PathPrefix: "/api"},
+		{PathPrefix: "/static", Auth: ProxyAuthPublic},
+		{PathPrefix: "/api/admin", Auth: ProxyAuthPermission, Resource: "extensions", Action: "manage"},
+		{PathPrefix: "/terminal", Rewrite: "/ws", Auth: ProxyAuthAuthenticated},
+	})
+	if err != nil {
+		t.Fatalf("newProxyRouter() error = %v", err)
+	}
+
+	tests := []struct {
+		path     string
+		wantAuth string
+		wantPath string
+	}{
+		{"", ProxyAuthAuthenticated, "/"},
+		{"/static/app.js", ProxyAuthPublic, "/static/app.js"},
+		{"/staticx", ProxyAuthAuthenticated, "/staticx"},
+		{"/apix", ProxyAuthAuthenticated, "/apix"},
+		{"/api", ProxyAuthAuthenticated, "/api"},
+		{"/api/items", ProxyAuthAuthenticated, "/api/items"},
+		{"/api/admin/users", ProxyAuthPermission, "/api/admin/users"},
+		{"/terminal", ProxyAuthAuthenticated, "/ws"},
+		{"/terminal/session/1", ProxyAuthAuthenticated, "/ws/session/1"},
+	}
+
+	for _, tt := range tests {
+		route, path := router.match(tt.path)
+		if auth := route.Auth; auth != tt.wantAuth || path != tt.wantPath {
+			t.Errorf("match(%q) = (%s, %q), want (%s, %q)", tt.path, route.Auth, path, tt.wantAuth, tt.wantPath)
+		}
+	}
+}
+
+func TestNewProxyRouterValidation(t *testing.T) {
+	invalid := [][]kbplugin.ProxyRoute{
+		{{PathPrefix: "api"}},
+		{{PathPrefix: "/api", Auth: "magic"}},
+		{{PathPrefix: "/api", Auth: ProxyAuthPermission}},
+	}
+	for _, routes := range invalid {
+		if _, err := newProxyRouter(routes); err == nil {
+			t.Errorf("newProxyRouter(%+v) expected error", routes)
+		}
+	}
+}
+
+func TestProxyDotSegments(t *testing.T) {
+	var upstreamPaths []string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upstreamPaths = append(upstreamPaths, r.URL.Path)
+	}))
+	defer upstream.Close()
+
+	gin.SetMode(gin.TestMode)
+	m := &Manager{
+		httpProxies: make(map[string]*httputil.ReverseProxy),
+		router:      gin.New(),
+		authMiddleware: func(c *gin.Context) {
+			c.AbortWithStatus(http.StatusUnauthorized)
+		},
+	}
+	m.setupExtensionProxy("x", strings.TrimPrefix(upstream.URL, "http://"), []kbplugin.ProxyRoute{
+		{PathPrefix: "/public", Auth: ProxyAuthPublic},
+		{PathPrefix: "/api/admin", Auth: ProxyAuthAuthenticated},
+	})
+
+	tests := []struct {
+		path         string
+		wantStatus   int
+		wantUpstream string
+	}{
+		{"/public/app.js", http.StatusOK, "/public/app.js"},
+		{"/public/../api/admin", http.StatusUnauthorized, ""},
+		{"/public/%2e%2e/api/admin", http.StatusUnauthorized, ""},
+		{"/public//../api/admin/users", http.StatusUnauthorized, ""},
+		{"/public/./css/../app.js", http.StatusOK, "/public/app.js"},
+		{"/undeclared", http.StatusUnauthorized, ""},
+	}
+	server := httptest.NewServer(m.router)
+	defer server.Close()
+	for _, tt := range tests {
+		upstreamPaths = nil
+		// Sent as written: the client does not resolve dot segments
+		resp, err := http.Get(server.URL + "/extensions/x/proxy" + tt.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tt.wantStatus {
+			t.Errorf("GET %s: %d, want %d", tt.path, resp.StatusCode, tt.wantStatus)
+		}
+		if got := strings.Join(upstreamPaths, ","); got != tt.wantUpstream {
+			t.Errorf("GET %s reached the upstream as %q, want %q", tt.path, got, tt.wantUpstream)
+		}
+	}
+}
+
+func TestProxyStreamsPastWriteTimeout(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for i := 0; i < 3; i++ {
+			fmt.Fprintf(w, "data: %d\n\n", i)
+			w.(http.Flusher).Flush()
+			time.Sleep(100 * time.Millisecond)
+		}
+	}))
+	defer upstream.Close()
+
+	gin.SetMode(gin.TestMode)
+	m := &Manager{httpProxies: make(map[string]*httputil.ReverseProxy), router: gin.New()}
+	m.setupExtensionProxy("x", strings.TrimPrefix(upstream.URL, "http://"), []kbplugin.ProxyRoute{
+		{PathPrefix: "/events", Auth: ProxyAuthPublic},
+	})
+
+	// The stream outlasts the write timeout of the server
+	server := httptest.NewUnstartedServer(m.router)
+	server.Config.WriteTimeout = 150 * time.Millisecond
+	server.Start()
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/extensions/x/proxy/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("stream cut off after %q: %v", body, err)
+	}
+	if want := "data: 0\n\ndata: 1\n\ndata: 2\n\n"; string(body) != want {
+		t.Errorf("stream = %q, want %q", body, want)
+	}
+}
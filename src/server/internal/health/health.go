@@ -0,0 +1,156 @@
+// Package health implements the liveness and readiness probes of the kubelens server.
+package health
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+// checkTimeout bounds each readiness check so a slow dependency cannot hang the probe
+const checkTimeout = 2 * time.Second
+
+// Check verifies one dependency. It returns a short human-readable detail on success.
+type Check func(ctx context.Context) (string, error)
+
+// Result is the outcome of one readiness check
+type Result struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"` // ok or failed
+	Message string `json:"message,omitempty"`
+}
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// Checker runs the readiness checks
+type Checker struct {
+	mu           sync.RWMutex
+	checks       []namedCheck
+	shuttingDown atomic.Bool
+}
+
+// NewChecker creates a checker without checks
+func NewChecker() *Checker {
+	return &Checker{}
+}
+
+// Add registers a readiness check
+func (c *Checker) Add(name string, check Check) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checks = append(c.checks, namedCheck{name: name, check: check})
+}
+
+// SetShuttingDown makes the server report not ready, so it is taken out of load
+// balancing while in-flight requests drain
+func (c *Checker) SetShuttingDown() {
+	c.shuttingDown.Store(true)
+}
+
+// Ready runs all checks concurrently and reports whether all of them passed
+func (c *Checker) Ready(ctx context.Context) (bool, []Result) {
+	if c.shuttingDown.Load() {
+		return false, []Result{{Name: "shutdown", Status: "failed", Message: "server is shutting down"}}
+	}
+
+	c.mu.RLock()
+	checks := append([]namedCheck(nil), c.checks...)
+	c.mu.RUnlock()
+
+	results := make([]Result, len(checks))
+	var wg sync.WaitGroup
+	for i, nc := range checks {
+		wg.Add(1)
+		go func(i int, nc namedCheck) {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, checkTimeout)
+			defer cancel()
+
+			detail, err := nc.check(checkCtx)
+			if err != nil {
+				results[i] = Result{Name: nc.name, Status: "failed", Message: err.Error()}
+				return
+			}
+			results[i] = Result{Name: nc.name, Status: "ok", Message: detail}
+		}(i, nc)
+	}
+	wg.Wait()
+
+	ready := true
+	for _, result := range results {
+		if result.Status != "ok" {
+			ready = false
+		}
+	}
+	return ready, results
+}
+
+// Livez handles GET /livez. It only reports that the process is serving requests; a
+// failing dependency must not get kubelens restarted.
+func (c *Checker) Livez(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Readyz handles GET /readyz: 200 when all checks pass, 503 otherwise
+func (c *Checker) Readyz(ctx *gin.Context) {
+	ready, results := c.Ready(ctx.Request.Context())
+	if !ready {
+		for _, result := range results {
+			if result.Status != "ok" {
+				log.Warnf("Readiness check %s failed: %s", result.Name, result.Message)
+			}
+		}
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{"status": "unavailable", "checks": results})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"status": "ok", "checks": results})
+}
+
+// DatabaseCheck verifies the database connection
+func DatabaseCheck(db *sql.DB) Check {
+	return func(ctx context.Context) (string, error) {
+		if db == nil {
+			return "", fmt.Errorf("database connection is not initialized")
+		}
+		if err := db.PingContext(ctx); err != nil {
+			return "", fmt.Errorf("database ping failed: %w", err)
+		}
+		return "connected", nil
+	}
+}
+
+// ClustersCheck passes when no clusters are enabled or at least one enabled cluster is
+// connected, so a few unreachable clusters do not take kubelens out of service.
+func ClustersCheck(enabled func() ([]string, error), connected func() []string) Check {
+	return func(ctx context.Context) (string, error) {
+		names, err := enabled()
+		if err != nil {
+			return "", fmt.Errorf("failed to list clusters: %w", err)
+		}
+		loaded := map[string]bool{}
+		for _, name := range connected() {
+			loaded[name] = true
+		}
+
+		count := 0
+		for _, name := range names {
+			if loaded[name] {
+				count++
+			}
+		}
+		if len(names) > 0 && count == 0 {
+			return "", fmt.Errorf("none of %d enabled clusters are connected", len(names))
+		}
+		return fmt.Sprintf("%d of %d enabled clusters connected", count, len(names)), nil
+	}
+}
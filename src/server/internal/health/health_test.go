@@ -0,0 +1,75 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestReadyz(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	checker := NewChecker()
+	router := gin.New()
+	router.GET("/livez", checker.Livez)
+	router.GET("/readyz", checker.Readyz)
+
+	probe := func(path string) (int, map[string]interface{}) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		var body map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &body)
+		return w.Code, body
+	}
+
+	dbErr := error(nil)
+	checker.Add("database", func(ctx context.Context) (string, error) { return "connected", dbErr })
+	checker.Add("extensions", func(ctx context.Context) (string, error) { return "0 running", nil })
+
+	if code, _ := probe("/readyz"); code != http.StatusOK {
+		t.Errorf("readyz with passing checks = %d, want 200", code)
+	}
+
+	dbErr = errors.New("connection refused")
+	code, body := probe("/readyz")
+	if code != http.StatusServiceUnavailable || body["status"] != "unavailable" {
+		t.Errorf("readyz with failing check = %d %v, want 503", code, body)
+	}
+	if code, _ := probe("/livez"); code != http.StatusOK {
+		t.Errorf("livez with failing dependency = %d, want 200", code)
+	}
+
+	dbErr = nil
+	checker.SetShuttingDown()
+	if code, _ := probe("/readyz"); code != http.StatusServiceUnavailable {
+		t.Errorf("readyz while shutting down = %d, want 503", code)
+	}
+}
+
+func TestClustersCheck(t *testing.T) {
+	tests := []struct {
+		name      string
+		enabled   []string
+		connected []string
+		wantErr   bool
+	}{
+		{"no clusters", nil, nil, false},
+		{"all connected", []string{"prod", "staging"}, []string{"prod", "staging"}, false},
+		{"partially connected", []string{"prod", "staging"}, []string{"staging"}, false},
+		{"none connected", []string{"prod", "staging"}, nil, true},
+		{"only disabled cluster connected", []string{"prod"}, []string{"old"}, true},
+	}
+	for _, tt := range tests {
+		check := ClustersCheck(
+			func() ([]string, error) { return tt.enabled, nil },
+			func() []string { return tt.connected },
+		)
+		if _, err := check(context.Background()); (err != nil) != tt.wantErr {
+			t.Errorf("%s: error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
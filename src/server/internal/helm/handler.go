@@ -0,0 +1,458 @@
+package helm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/sonnguyen/kubelens/internal/audit"
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+// repositoryNamePattern matches the names accepted by `helm repo add`
+var repositoryNamePattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9._-]{0,61}[a-z0-9])?$`)
+
+// Handler handles Helm repository and chart catalog API requests
+type Handler struct {
+	db      *db.DB
+	indexer *Indexer
+}
+
+// NewHandler creates a new Helm handler
+func NewHandler(database *db.DB, indexer *Indexer) *Handler {
+	return &Handler{db: database, indexer: indexer}
+}
+
+// repositoryRequest is the body of create and update requests. A nil password keeps the
+// stored one on update; an empty string clears it.
+type repositoryRequest struct {
+	Name     string   `json:"name"`
+	Type     string   `json:"type"`
+	URL      string   `json:"url" binding:"required"`
+	Username string   `json:"username"`
+	Password *string  `json:"password"`
+	Charts   []string `json:"charts"`
+}
+
+// validate checks the request and normalizes the type
+func (req *repositoryRequest) validate() error {
+	if !repositoryNamePattern.MatchString(req.Name) {
+		return fmt.Errorf("name must be lowercase letters, digits, '.', '_' or '-' (at most 63 characters)")
+	}
+	if req.Type == "" {
+		req.Type = TypeHTTP
+		if strings.HasPrefix(req.URL, "oci://") {
+			req.Type = TypeOCI
+		}
+	}
+
+	switch req.Type {
+	case TypeHTTP:
+		u, err := url.Parse(req.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("url must be an http(s) URL for http repositories")
+		}
+		req.Charts = nil
+	case TypeOCI:
+		if _, err := parseOCIReference(req.URL); err != nil {
+			return err
+		}
+		if len(req.Charts) == 0 {
+			return fmt.Errorf("charts is required for oci repositories")
+		}
+		for _, chart := range req.Charts {
+			if !repositoryNamePattern.MatchString(chart) {
+				return fmt.Errorf("invalid chart name: %q", chart)
+			}
+		}
+	default:
+		return fmt.Errorf("type must be http or oci")
+	}
+	return nil
+}
+
+// apply copies the request onto a repository, encrypting the password
+func (h *Handler) apply(req *repositoryRequest, repo *db.HelmRepository) error {
+	repo.Name = req.Name
+	repo.Type = req.Type
+	repo.URL = strings.TrimSuffix(req.URL, "/")
+	repo.Username = req.Username
+	repo.Charts = nil
+	if len(req.Charts) > 0 {
+		charts, _ := json.Marshal(req.Charts)
+		repo.Charts = db.JSON(charts)
+	}
+	if req.Password != nil {
+		repo.Password = ""
+		if *req.Password != "" {
+			encrypted, err := encryptPassword(h.db, *req.Password)
+			if err != nil {
+				return err
+			}
+			repo.Password = encrypted
+		}
+	}
+	return nil
+}
+
+// refreshInBackground indexes a repository that was just added or changed
+func (h *Handler) refreshInBackground(repo *db.HelmRepository) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), refreshTimeout)
+		defer cancel()
+		if err := h.indexer.Refresh(ctx, repo); err != nil {
+			log.Warnf("Failed to index Helm repository %s: %v", repo.Name, err)
+		}
+	}()
+}
+
+func (h *Handler) auditChange(c *gin.Context, desc string, repo *db.HelmRepository) {
+	if userID, exists := c.Get("user_id"); exists {
+		username, _ := c.Get("username")
+		email, _ := c.Get("email")
+		audit.Log(c, audit.EventAuditConfigChanged, userID.(int), username.(string), email.(string),
+			desc,
+			map[string]interface{}{
+				"repository": repo.Name,
+				"type":       repo.Type,
+				"url":        repo.URL,
+			})
+	}
+}
+
+// ListRepositories handles GET /api/v1/helm/repositories
+func (h *Handler) ListRepositories(c *gin.Context) {
+	repos, err := h.db.ListHelmRepositories()
+	if err != nil {
+		log.Errorf("Failed to list Helm repositories: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list repositories"})
+		return
+	}
+	c.JSON(http.StatusOK, repos)
+}
+
+// CreateRepository handles POST /api/v1/helm/repositories. The repository is indexed in
+// the background.
+func (h *Handler) CreateRepository(c *gin.Context) {
+	var req repositoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := req.validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if _, err := h.db.GetHelmRepositoryByName(req.Name); err == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "a repository with this name already exists"})
+		return
+	}
+
+	repo := &db.HelmRepository{}
+	if err := h.apply(&req, repo); err != nil {
+		log.Errorf("Failed to encrypt Helm repository password: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create repository"})
+		return
+	}
+	if err := h.db.CreateHelmRepository(repo); err != nil {
+		log.Errorf("Failed to create Helm repository: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create repository"})
+		return
+	}
+
+	log.Infof("Helm repository %s added (%s)", repo.Name, repo.URL)
+	h.auditChange(c, fmt.Sprintf("Added Helm repository %s", repo.Name), repo)
+	h.refreshInBackground(repo)
+
+	c.JSON(http.StatusCreated, repo)
+}
+
+// UpdateRepository handles PUT /api/v1/helm/repositories/:id
+func (h *Handler) UpdateRepository(c *gin.Context) {
+	repo, ok := h.repositoryFromParam(c)
+	if !ok {
+		return
+	}
+
+	var req repositoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Name == "" {
+		req.Name = repo.Name
+	}
+	if err := req.validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if existing, err := h.db.GetHelmRepositoryByName(req.Name); err == nil && existing.ID != repo.ID {
+		c.JSON(http.StatusConflict, gin.H{"error": "a repository with this name already exists"})
+		return
+	}
+
+	if err := h.apply(&req, repo); err != nil {
+		log.Errorf("Failed to encrypt Helm repository password: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update repository"})
+		return
+	}
+	if err := h.db.UpdateHelmRepository(repo); err != nil {
+		log.Errorf("Failed to update Helm repository: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update repository"})
+		return
+	}
+
+	h.auditChange(c, fmt.Sprintf("Updated Helm repository %s", repo.Name), repo)
+	h.refreshInBackground(repo)
+
+	c.JSON(http.StatusOK, repo)
+}
+
+// DeleteRepository handles DELETE /api/v1/helm/repositories/:id
+func (h *Handler) DeleteRepository(c *gin.Context) {
+	repo, ok := h.repositoryFromParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.db.DeleteHelmRepository(repo.ID); err != nil {
+		log.Errorf("Failed to delete Helm repository: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete repository"})
+		return
+	}
+
+	log.Infof("Helm repository %s removed", repo.Name)
+	h.auditChange(c, fmt.Sprintf("Removed Helm repository %s", repo.Name), repo)
+
+	c.JSON(http.StatusOK, gin.H{"message": "repository deleted successfully"})
+}
+
+// RefreshRepository handles POST /api/v1/helm/repositories/:id/refresh: re-indexes the
+// repository now and returns it with the updated index status
+func (h *Handler) RefreshRepository(c *gin.Context) {
+	repo, ok := h.repositoryFromParam(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), refreshTimeout)
+	defer cancel()
+	if err := h.indexer.Refresh(ctx, repo); err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": fmt.Sprintf("failed to index repository: %v", err)})
+		return
+	}
+
+	repo, err := h.db.GetHelmRepository(repo.ID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, repo)
+}
+
+func (h *Handler) repositoryFromParam(c *gin.Context) (*db.HelmRepository, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid repository ID"})
+		return nil, false
+	}
+	repo, err := h.db.GetHelmRepository(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	return repo, true
+}
+
+// ChartSummary is a chart in search results, described by its newest version
+type ChartSummary struct {
+	Repository   string `json:"repository"`
+	Name         string `json:"name"`
+	Version      string `json:"version"`
+	AppVersion   string `json:"app_version,omitempty"`
+	Description  string `json:"description,omitempty"`
+	Icon         string `json:"icon,omitempty"`
+	Deprecated   bool   `json:"deprecated"`
+	VersionCount int    `json:"version_count"`
+}
+
+// SearchCharts handles GET /api/v1/helm/charts
+// Query params: q (substring of name or description), repository, include_deprecated,
+// include_prerelease
+func (h *Handler) SearchCharts(c *gin.Context) {
+	repos, err := h.db.ListHelmRepositories()
+	if err != nil {
+		log.Errorf("Failed to list Helm repositories: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to search charts"})
+		return
+	}
+	repoNames := make(map[uint]string, len(repos))
+	filters := db.HelmChartFilters{Query: c.Query("q")}
+	for _, repo := range repos {
+		repoNames[repo.ID] = repo.Name
+		if repo.Name == c.Query("repository") {
+			filters.RepositoryID = repo.ID
+		}
+	}
+	if c.Query("repository") != "" && filters.RepositoryID == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "repository not found"})
+		return
+	}
+
+	versions, err := h.db.ListHelmChartVersions(filters)
+	if err != nil {
+		log.Errorf("Failed to search Helm charts: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to search charts"})
+		return
+	}
+
+	charts := latestVersions(versions, repoNames, c.Query("include_prerelease") == "true")
+	if c.Query("include_deprecated") != "true" {
+		kept := charts[:0]
+		for _, chart := range charts {
+			if !chart.Deprecated {
+				kept = append(kept, chart)
+			}
+		}
+		charts = kept
+	}
+
+	c.JSON(http.StatusOK, gin.H{"charts": charts, "total": len(charts)})
+}
+
+// latestVersions reduces chart versions to one summary per repository and chart, sorted
+// by chart name then repository. Pre-releases are only picked as the newest version when
+// includePrerelease is set or no stable version exists.
+func latestVersions(versions []*db.HelmChartVersion, repoNames map[uint]string, includePrerelease bool) []ChartSummary {
+	type key struct {
+		repo  uint
+		chart string
+	}
+	latest := map[key]*db.HelmChartVersion{}
+	counts := map[key]int{}
+	var order []key
+	for _, v := range versions {
+		k := key{v.RepositoryID, v.Chart}
+		counts[k]++
+		current, ok := latest[k]
+		if !ok {
+			order = append(order, k)
+			latest[k] = v
+			continue
+		}
+		if newer(v.Version, current.Version, includePrerelease) {
+			latest[k] = v
+		}
+	}
+
+	charts := make([]ChartSummary, 0, len(order))
+	for _, k := range order {
+		v := latest[k]
+		charts = append(charts, ChartSummary{
+			Repository:   repoNames[k.repo],
+			Name:         v.Chart,
+			Version:      v.Version,
+			AppVersion:   v.AppVersion,
+			Description:  v.Description,
+			Icon:         v.Icon,
+			Deprecated:   v.Deprecated,
+			VersionCount: counts[k],
+		})
+	}
+	sort.Slice(charts, func(i, j int) bool {
+		if charts[i].Name != charts[j].Name {
+			return charts[i].Name < charts[j].Name
+		}
+		return charts[i].Repository < charts[j].Repository
+	})
+	return charts
+}
+
+// newer reports whether candidate should replace current as the newest version
+func newer(candidate, current string, includePrerelease bool) bool {
+	if !includePrerelease {
+		candidateStable, currentStable := isStable(candidate), isStable(current)
+		if candidateStable != currentStable {
+			return candidateStable
+		}
+	}
+	return CompareVersions(candidate, current) > 0
+}
+
+func isStable(v string) bool {
+	base, _, _ := strings.Cut(v, "+")
+	return !strings.Contains(base, "-")
+}
+
+// ChartVersionInfo is one installable version of a chart
+type ChartVersionInfo struct {
+	Version    string     `json:"version"`
+	AppVersion string     `json:"app_version,omitempty"`
+	Created    *time.Time `json:"created,omitempty"`
+	Deprecated bool       `json:"deprecated"`
+	Digest     string     `json:"digest,omitempty"`
+	URLs       db.JSON    `json:"urls"`
+}
+
+// GetChartVersions handles GET /api/v1/helm/charts/:repository/:chart: the versions of a
+// chart, newest first, with the download URLs used to install them
+func (h *Handler) GetChartVersions(c *gin.Context) {
+	repo, err := h.db.GetHelmRepositoryByName(c.Param("repository"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	records, err := h.db.ListHelmChartVersions(db.HelmChartFilters{RepositoryID: repo.ID, Chart: c.Param("chart")})
+	if err != nil {
+		log.Errorf("Failed to list Helm chart versions: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list chart versions"})
+		return
+	}
+	if len(records) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "chart not found"})
+		return
+	}
+
+	byVersion := make(map[string]*db.HelmChartVersion, len(records))
+	versions := make([]*ChartVersion, 0, len(records))
+	for _, r := range records {
+		byVersion[r.Version] = r
+		versions = append(versions, &ChartVersion{Version: r.Version})
+	}
+	SortVersions(versions)
+
+	infos := make([]ChartVersionInfo, 0, len(versions))
+	for _, v := range versions {
+		r := byVersion[v.Version]
+		infos = append(infos, ChartVersionInfo{
+			Version:    r.Version,
+			AppVersion: r.AppVersion,
+			Created:    r.Created,
+			Deprecated: r.Deprecated,
+			Digest:     r.Digest,
+			URLs:       r.URLs,
+		})
+	}
+
+	newest := byVersion[versions[0].Version]
+	c.JSON(http.StatusOK, gin.H{
+		"repository":      repo.Name,
+		"repository_type": repo.Type,
+		"repository_url":  repo.URL,
+		"name":            newest.Chart,
+		"description":     newest.Description,
+		"icon":            newest.Icon,
+		"versions":        infos,
+	})
+}
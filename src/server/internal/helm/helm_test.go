@@ -0,0 +1,247 @@
+package helm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+const testIndex = `apiVersion: v1
+entries:
+  nginx:
+  - name: nginx
+    version: 1.10.0
+    appVersion: 1.25.3
+    description: NGINX web server
+    urls:
+    - charts/nginx-1.10.0.tgz
+    created: "2024-03-01T10:00:00Z"
+  - name: nginx
+    version: 1.9.2
+    urls:
+    - https://cdn.example.com/nginx-1.9.2.tgz
+  - name: nginx
+    version: 1.11.0-rc.1
+    urls:
+    - charts/nginx-1.11.0-rc.1.tgz
+  redis:
+  - name: redis
+    version: 2.0.0
+    deprecated: true
+`
+
+func TestParseIndex(t *testing.T) {
+	base, _ := url.Parse("https://charts.example.com/stable/")
+	charts, err := parseIndex([]byte(testIndex), base)
+	if err != nil {
+		t.Fatalf("parseIndex() error = %v", err)
+	}
+
+	nginx := charts["nginx"]
+	if len(nginx) != 3 {
+		t.Fatalf("nginx has %d versions, want 3", len(nginx))
+	}
+	var order []string
+	for _, v := range nginx {
+		order = append(order, v.Version)
+	}
+	if got := strings.Join(order, ","); got != "1.11.0-rc.1,1.10.0,1.9.2" {
+		t.Errorf("versions = %s, want newest first", got)
+	}
+	if got := nginx[1].URLs[0]; got != "https://charts.example.com/stable/charts/nginx-1.10.0.tgz" {
+		t.Errorf("relative URL resolved to %s", got)
+	}
+	if got := nginx[2].URLs[0]; got != "https://cdn.example.com/nginx-1.9.2.tgz" {
+		t.Errorf("absolute URL changed to %s", got)
+	}
+	if nginx[1].AppVersion != "1.25.3" || nginx[1].Created.IsZero() {
+		t.Errorf("metadata not parsed: %+v", nginx[1])
+	}
+	if !charts["redis"][0].Deprecated {
+		t.Error("redis should be deprecated")
+	}
+
+	if _, err := parseIndex([]byte("entries: {}"), base); err == nil {
+		t.Error("parseIndex() accepted an index without apiVersion")
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.10.0", "1.9.0", 1},
+		{"1.0.0-rc.1", "1.0.0", -1},
+		{"v2.0.0", "1.0.0", 1},
+		{"1.0.0", "1.0.0+build.1", 0},
+		{"latest", "1.0.0", -1},
+		{"abc", "abd", -1},
+	}
+	for _, tt := range tests {
+		if got := CompareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestLatestVersions(t *testing.T) {
+	versions := []*db.HelmChartVersion{
+		{RepositoryID: 1, Chart: "nginx", Version: "1.9.2"},
+		{RepositoryID: 1, Chart: "nginx", Version: "1.11.0-rc.1"},
+		{RepositoryID: 1, Chart: "nginx", Version: "1.10.0"},
+		{RepositoryID: 2, Chart: "nginx", Version: "0.1.0-alpha"},
+		{RepositoryID: 1, Chart: "apache", Version: "3.0.0"},
+	}
+	names := map[uint]string{1: "stable", 2: "edge"}
+
+	charts := latestVersions(versions, names, false)
+	var got []string
+	for _, c := range charts {
+		got = append(got, c.Repository+"/"+c.Name+"@"+c.Version)
+	}
+	if want := "stable/apache@3.0.0,edge/nginx@0.1.0-alpha,stable/nginx@1.10.0"; strings.Join(got, ",") != want {
+		t.Errorf("latestVersions() = %v, want %s", got, want)
+	}
+	if charts[2].VersionCount != 3 {
+		t.Errorf("version count = %d, want 3", charts[2].VersionCount)
+	}
+
+	if charts := latestVersions(versions, names, true); charts[2].Version != "1.11.0-rc.1" {
+		t.Errorf("with pre-releases, latest = %s, want 1.11.0-rc.1", charts[2].Version)
+	}
+}
+
+func TestParseChallenge(t *testing.T) {
+	scheme, params := parseChallenge(`Bearer realm="https://ghcr.io/token",service="ghcr.io",scope="repository:org/charts/nginx:pull"`)
+	if scheme != "Bearer" || params["realm"] != "https://ghcr.io/token" || params["service"] != "ghcr.io" ||
+		params["scope"] != "repository:org/charts/nginx:pull" {
+		t.Errorf("parseChallenge() = %s %v", scheme, params)
+	}
+	if got := nextPage(`</v2/org/charts/nginx/tags/list?last=1.0.0&n=1000>; rel="next"`); got != "/v2/org/charts/nginx/tags/list?last=1.0.0&n=1000" {
+		t.Errorf("nextPage() = %q", got)
+	}
+}
+
+// newTestRegistry serves an OCI registry with one chart that requires a bearer token
+func newTestRegistry(t *testing.T) *httptest.Server {
+	var srv *httptest.Server
+	srv = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			json.NewEncoder(w).Encode(map[string]string{"token": "registry-token"})
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer registry-token" {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="`+srv.URL+`/token",service="test"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/v2/charts/podinfo/tags/list":
+			json.NewEncoder(w).Encode(map[string]interface{}{"tags": []string{"6.5.0", "latest", "6.6.0_build.1"}})
+		case "/v2/charts/podinfo/manifests/6.6.0_build.1":
+			w.Header().Set("Docker-Content-Digest", "sha256:manifest")
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"config": map[string]string{"mediaType": helmConfigMediaType, "digest": "sha256:config"},
+			})
+		case "/v2/charts/podinfo/blobs/sha256:config":
+			json.NewEncoder(w).Encode(map[string]string{"name": "podinfo", "version": "6.6.0+build.1", "appVersion": "6.6.0", "description": "Podinfo"})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestFetchOCI(t *testing.T) {
+	srv := newTestRegistry(t)
+	host := strings.TrimPrefix(srv.URL, "https://")
+
+	charts, err := NewFetcher(srv.Client()).Fetch(context.Background(), Source{
+		Type:   TypeOCI,
+		URL:    "oci://" + host + "/charts",
+		Charts: []string{"podinfo"},
+	})
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	versions := charts["podinfo"]
+	if len(versions) != 2 {
+		t.Fatalf("podinfo has %d versions, want 2 (non-semver tags skipped)", len(versions))
+	}
+	latest := versions[0]
+	if latest.Version != "6.6.0+build.1" || latest.AppVersion != "6.6.0" || latest.Description != "Podinfo" || latest.Digest != "sha256:manifest" {
+		t.Errorf("latest = %+v", latest)
+	}
+	if latest.URLs[0] != "oci://"+host+"/charts/podinfo" {
+		t.Errorf("URL = %s", latest.URLs[0])
+	}
+}
+
+func TestIndexerRefresh(t *testing.T) {
+	log.SetLevel(log.WarnLevel)
+
+	database, err := db.New(filepath.Join(t.TempDir(), "kubelens.db"))
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	fail := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, _ := r.BasicAuth(); user != "reader" || pass != "s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if fail {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(testIndex))
+	}))
+	t.Cleanup(srv.Close)
+
+	password, err := encryptPassword(database, "s3cret")
+	if err != nil {
+		t.Fatalf("encryptPassword() error = %v", err)
+	}
+	repo := &db.HelmRepository{Name: "stable", Type: TypeHTTP, URL: srv.URL, Username: "reader", Password: password}
+	if err := database.CreateHelmRepository(repo); err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+
+	indexer := NewIndexer(database, NewFetcher(srv.Client()), 0)
+	if err := indexer.Refresh(context.Background(), repo); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	versions, _ := database.ListHelmChartVersions(db.HelmChartFilters{RepositoryID: repo.ID})
+	if len(versions) != 4 {
+		t.Errorf("indexed %d versions, want 4", len(versions))
+	}
+	if found, _ := database.ListHelmChartVersions(db.HelmChartFilters{Query: "WEB SERVER"}); len(found) != 1 {
+		t.Errorf("search by description found %d versions, want 1", len(found))
+	}
+
+	// A failed refresh records the error and keeps the previous index
+	fail = true
+	if err := indexer.Refresh(context.Background(), repo); err == nil {
+		t.Fatal("Refresh() succeeded against a failing repository")
+	}
+	stored, _ := database.GetHelmRepository(repo.ID)
+	if stored.IndexError == "" || stored.ChartCount != 2 {
+		t.Errorf("repository after failed refresh = %+v", stored)
+	}
+	if versions, _ := database.ListHelmChartVersions(db.HelmChartFilters{RepositoryID: repo.ID}); len(versions) != 4 {
+		t.Errorf("previous index lost: %d versions", len(versions))
+	}
+}
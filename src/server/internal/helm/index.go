@@ -0,0 +1,177 @@
+// Package helm manages the Helm chart repositories registered by admins and keeps an index
+// of their charts and versions for the chart catalog.
+package helm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/version"
+	"sigs.k8s.io/yaml"
+)
+
+// maxIndexSize bounds the size of a downloaded index.yaml (large public repositories are
+// a few tens of MB)
+const maxIndexSize = 100 << 20
+
+// Repository types
+const (
+	TypeHTTP = "http"
+	TypeOCI  = "oci"
+)
+
+// ChartVersion is a chart version as listed in a repository index
+type ChartVersion struct {
+	Name        string    `json:"name"`
+	Version     string    `json:"version"`
+	AppVersion  string    `json:"appVersion,omitempty"`
+	Description string    `json:"description,omitempty"`
+	Icon        string    `json:"icon,omitempty"`
+	URLs        []string  `json:"urls,omitempty"`
+	Digest      string    `json:"digest,omitempty"`
+	Deprecated  bool      `json:"deprecated,omitempty"`
+	Created     time.Time `json:"created,omitempty"`
+}
+
+// indexFile is the index.yaml of a classic Helm repository
+type indexFile struct {
+	APIVersion string                     `json:"apiVersion"`
+	Entries    map[string][]*ChartVersion `json:"entries"`
+}
+
+// Source is what is needed to read a repository
+type Source struct {
+	Type     string
+	URL      string
+	Username string
+	Password string
+	Charts   []string // OCI only
+}
+
+// Fetcher downloads repository indexes
+type Fetcher struct {
+	client *http.Client
+}
+
+// NewFetcher creates a fetcher using the given HTTP client (nil for a default client)
+func NewFetcher(client *http.Client) *Fetcher {
+	if client == nil {
+		client = &http.Client{Timeout: 60 * time.Second}
+	}
+	return &Fetcher{client: client}
+}
+
+// Fetch returns the chart versions available in a repository, by chart name
+func (f *Fetcher) Fetch(ctx context.Context, src Source) (map[string][]*ChartVersion, error) {
+	switch src.Type {
+	case TypeHTTP, "":
+		return f.fetchIndex(ctx, src)
+	case TypeOCI:
+		return f.fetchOCI(ctx, src)
+	}
+	return nil, fmt.Errorf("unsupported repository type: %s", src.Type)
+}
+
+// fetchIndex downloads and parses <url>/index.yaml
+func (f *Fetcher) fetchIndex(ctx context.Context, src Source) (map[string][]*ChartVersion, error) {
+	base, err := url.Parse(strings.TrimSuffix(src.URL, "/") + "/")
+	if err != nil {
+		return nil, fmt.Errorf("invalid repository URL: %w", err)
+	}
+	indexURL := base.ResolveReference(&url.URL{Path: "index.yaml"})
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, indexURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if src.Username != "" || src.Password != "" {
+		req.SetBasicAuth(src.Username, src.Password)
+	}
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download index: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download index: %s returned %s", indexURL, resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxIndexSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to download index: %w", err)
+	}
+	if len(data) > maxIndexSize {
+		return nil, fmt.Errorf("index exceeds %d MB", maxIndexSize>>20)
+	}
+	return parseIndex(data, base)
+}
+
+// parseIndex parses an index.yaml, resolving relative chart URLs against the repository URL
+func parseIndex(data []byte, base *url.URL) (map[string][]*ChartVersion, error) {
+	var index indexFile
+	if err := yaml.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("invalid index: %w", err)
+	}
+	if index.APIVersion == "" {
+		return nil, fmt.Errorf("invalid index: missing apiVersion")
+	}
+
+	charts := make(map[string][]*ChartVersion, len(index.Entries))
+	for name, versions := range index.Entries {
+		for _, v := range versions {
+			if v == nil || v.Version == "" {
+				continue
+			}
+			v.Name = name
+			for i, u := range v.URLs {
+				if ref, err := url.Parse(u); err == nil && base != nil {
+					v.URLs[i] = base.ResolveReference(ref).String()
+				}
+			}
+			charts[name] = append(charts[name], v)
+		}
+		SortVersions(charts[name])
+	}
+	return charts, nil
+}
+
+// SortVersions sorts chart versions newest first. Versions that are not valid semver sort
+// last, by string.
+func SortVersions(versions []*ChartVersion) {
+	sort.SliceStable(versions, func(i, j int) bool {
+		return CompareVersions(versions[i].Version, versions[j].Version) > 0
+	})
+}
+
+// CompareVersions compares two chart versions by semver precedence: -1, 0 or 1
+func CompareVersions(a, b string) int {
+	va, errA := version.ParseSemantic(a)
+	vb, errB := version.ParseSemantic(b)
+	switch {
+	case errA != nil && errB != nil:
+		return strings.Compare(a, b)
+	case errA != nil:
+		return -1
+	case errB != nil:
+		return 1
+	}
+	switch {
+	case va.GreaterThan(vb):
+		return 1
+	case va.LessThan(vb):
+		return -1
+	}
+	return 0
+}
+
+// decodeJSON reads a JSON response body of limited size
+func decodeJSON(r io.Reader, v interface{}) error {
+	return json.NewDecoder(io.LimitReader(r, 10<<20)).Decode(v)
+}
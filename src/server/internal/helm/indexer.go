@@ -0,0 +1,182 @@
+package helm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/sonnguyen/kubelens/internal/crypto"
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+// refreshTimeout bounds the indexing of one repository
+const refreshTimeout = 5 * time.Minute
+
+// Indexer keeps the chart index of every registered repository up to date
+type Indexer struct {
+	db       *db.DB
+	fetcher  *Fetcher
+	interval time.Duration
+	mu       sync.Mutex // one index run at a time
+	done     chan bool
+}
+
+// NewIndexer creates an indexer that refreshes every repository at the given interval
+func NewIndexer(database *db.DB, fetcher *Fetcher, interval time.Duration) *Indexer {
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	return &Indexer{
+		db:       database,
+		fetcher:  fetcher,
+		interval: interval,
+		done:     make(chan bool),
+	}
+}
+
+// Start starts the periodic refresh. Repositories not indexed within the interval are
+// refreshed right away.
+func (ix *Indexer) Start() {
+	go func() {
+		ticker := time.NewTicker(ix.interval)
+		defer ticker.Stop()
+
+		ix.refreshAll(time.Now().Add(-ix.interval))
+		for {
+			select {
+			case <-ticker.C:
+				ix.refreshAll(time.Now())
+			case <-ix.done:
+				return
+			}
+		}
+	}()
+	log.Infof("Helm repository indexer started (interval: %v)", ix.interval)
+}
+
+// Stop stops the periodic refresh
+func (ix *Indexer) Stop() {
+	close(ix.done)
+	log.Info("Helm repository indexer stopped")
+}
+
+// refreshAll refreshes the repositories last indexed before the given time
+func (ix *Indexer) refreshAll(before time.Time) {
+	repos, err := ix.db.ListHelmRepositories()
+	if err != nil {
+		log.Errorf("Failed to list Helm repositories: %v", err)
+		return
+	}
+	for _, repo := range repos {
+		if repo.LastIndexedAt != nil && repo.LastIndexedAt.After(before) {
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), refreshTimeout)
+		if err := ix.Refresh(ctx, repo); err != nil {
+			log.Warnf("Failed to index Helm repository %s: %v", repo.Name, err)
+		}
+		cancel()
+	}
+}
+
+// Refresh downloads the index of a repository and replaces its stored chart versions.
+// On failure the error is recorded on the repository and the previous index is kept.
+func (ix *Indexer) Refresh(ctx context.Context, repo *db.HelmRepository) error {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	src, err := ix.source(repo)
+	if err == nil {
+		var charts map[string][]*ChartVersion
+		if charts, err = ix.fetcher.Fetch(ctx, src); err == nil {
+			err = ix.db.ReplaceHelmChartVersions(repo.ID, toRecords(charts), len(charts))
+			if err == nil {
+				log.Infof("Indexed Helm repository %s: %d charts", repo.Name, len(charts))
+				return nil
+			}
+		}
+	}
+
+	if dbErr := ix.db.SetHelmRepositoryIndexError(repo.ID, err.Error()); dbErr != nil {
+		log.Errorf("Failed to record index error of Helm repository %s: %v", repo.Name, dbErr)
+	}
+	return err
+}
+
+// source returns the repository connection settings with the password decrypted
+func (ix *Indexer) source(repo *db.HelmRepository) (Source, error) {
+	src := Source{Type: repo.Type, URL: repo.URL, Username: repo.Username}
+	if len(repo.Charts) > 0 && string(repo.Charts) != "null" {
+		if err := json.Unmarshal(repo.Charts, &src.Charts); err != nil {
+			return src, fmt.Errorf("invalid chart list: %w", err)
+		}
+	}
+	if repo.Password != "" {
+		password, err := decryptPassword(ix.db, repo.Password)
+		if err != nil {
+			return src, err
+		}
+		src.Password = password
+	}
+	return src, nil
+}
+
+// toRecords flattens fetched chart versions into database records
+func toRecords(charts map[string][]*ChartVersion) []*db.HelmChartVersion {
+	var records []*db.HelmChartVersion
+	for name, versions := range charts {
+		for _, v := range versions {
+			urls, _ := json.Marshal(v.URLs)
+			record := &db.HelmChartVersion{
+				Chart:       name,
+				Version:     v.Version,
+				AppVersion:  v.AppVersion,
+				Description: v.Description,
+				Icon:        v.Icon,
+				URLs:        db.JSON(urls),
+				Digest:      v.Digest,
+				Deprecated:  v.Deprecated,
+			}
+			if !v.Created.IsZero() {
+				created := v.Created
+				record.Created = &created
+			}
+			records = append(records, record)
+		}
+	}
+	return records
+}
+
+// encryptPassword encrypts a repository password for storage
+func encryptPassword(database *db.DB, password string) (string, error) {
+	encryptor, err := encryptor(database)
+	if err != nil {
+		return "", err
+	}
+	return encryptor.Encrypt([]byte(password))
+}
+
+// decryptPassword decrypts a stored repository password
+func decryptPassword(database *db.DB, encrypted string) (string, error) {
+	encryptor, err := encryptor(database)
+	if err != nil {
+		return "", err
+	}
+	password, err := encryptor.Decrypt(encrypted)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt repository password: %w", err)
+	}
+	return string(password), nil
+}
+
+func encryptor(database *db.DB) (*crypto.Encryptor, error) {
+	key, err := database.GetOrCreateEncryptionKey()
+	if err != nil {
+		return nil, err
+	}
+	return crypto.NewEncryptor(key)
+}
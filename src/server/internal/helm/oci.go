@@ -0,0 +1,274 @@
+package helm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/version"
+)
+
+const (
+	ociManifestMediaType = "application/vnd.oci.image.manifest.v1+json"
+	helmConfigMediaType  = "application/vnd.cncf.helm.config.v1+json"
+)
+
+// ociReference is a registry host and repository path parsed from oci://host/path
+type ociReference struct {
+	host string
+	path string
+}
+
+// parseOCIReference parses a repository URL such as oci://ghcr.io/org/charts
+func parseOCIReference(raw string) (ociReference, error) {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme != "oci" || u.Host == "" {
+		return ociReference{}, fmt.Errorf("invalid OCI repository URL %q: expected oci://<registry>/<path>", raw)
+	}
+	return ociReference{host: u.Host, path: strings.Trim(u.Path, "/")}, nil
+}
+
+// repository returns the registry repository of a chart
+func (r ociReference) repository(chart string) string {
+	if r.path == "" {
+		return chart
+	}
+	return r.path + "/" + chart
+}
+
+// fetchOCI lists the versions of the configured charts in an OCI registry. OCI registries
+// cannot be browsed reliably, so the charts to index are part of the repository config.
+// Only the newest version of each chart has its metadata (description, app version) read.
+func (f *Fetcher) fetchOCI(ctx context.Context, src Source) (map[string][]*ChartVersion, error) {
+	ref, err := parseOCIReference(src.URL)
+	if err != nil {
+		return nil, err
+	}
+	if len(src.Charts) == 0 {
+		return nil, fmt.Errorf("OCI repositories need the list of charts to index")
+	}
+
+	client := &registryClient{http: f.client, host: ref.host, username: src.Username, password: src.Password}
+	charts := make(map[string][]*ChartVersion, len(src.Charts))
+	for _, chart := range src.Charts {
+		repo := ref.repository(chart)
+		tags, err := client.listTags(ctx, repo)
+		if err != nil {
+			return nil, fmt.Errorf("chart %s: %w", chart, err)
+		}
+
+		var versions []*ChartVersion
+		for _, tag := range tags {
+			// Helm stores the semver build separator "+" as "_" in OCI tags
+			v := strings.ReplaceAll(tag, "_", "+")
+			if _, err := version.ParseSemantic(v); err != nil {
+				continue // not a chart version tag
+			}
+			versions = append(versions, &ChartVersion{
+				Name:    chart,
+				Version: v,
+				URLs:    []string{"oci://" + ref.host + "/" + repo},
+			})
+		}
+		if len(versions) == 0 {
+			continue
+		}
+		SortVersions(versions)
+
+		latest := versions[0]
+		if meta, digest, err := client.chartMetadata(ctx, repo, strings.ReplaceAll(latest.Version, "+", "_")); err == nil {
+			latest.AppVersion = meta.AppVersion
+			latest.Description = meta.Description
+			latest.Icon = meta.Icon
+			latest.Deprecated = meta.Deprecated
+			latest.Digest = digest
+		}
+		charts[chart] = versions
+	}
+	return charts, nil
+}
+
+// registryClient talks to the OCI distribution API, handling the bearer token challenge
+// used by most registries (including for anonymous pulls)
+type registryClient struct {
+	http     *http.Client
+	host     string
+	username string
+	password string
+	token    string
+}
+
+func (c *registryClient) get(ctx context.Context, path, accept string, v interface{}) (http.Header, error) {
+	for attempt := 0; attempt < 2; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://"+c.host+path, nil)
+		if err != nil {
+			return nil, err
+		}
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		switch {
+		case c.token != "":
+			req.Header.Set("Authorization", "Bearer "+c.token)
+		case c.username != "" || c.password != "":
+			req.SetBasicAuth(c.username, c.password)
+		}
+
+		resp, err := c.http.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode == http.StatusUnauthorized && attempt == 0 {
+			challenge := resp.Header.Get("WWW-Authenticate")
+			resp.Body.Close()
+			if err := c.authenticate(ctx, challenge); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("registry returned %s for %s", resp.Status, path)
+		}
+		return resp.Header, decodeJSON(resp.Body, v)
+	}
+	return nil, fmt.Errorf("registry authentication failed")
+}
+
+// authenticate obtains a bearer token as requested by a WWW-Authenticate challenge
+func (c *registryClient) authenticate(ctx context.Context, challenge string) error {
+	scheme, params := parseChallenge(challenge)
+	if !strings.EqualFold(scheme, "bearer") || params["realm"] == "" {
+		return fmt.Errorf("registry requires unsupported authentication %q", scheme)
+	}
+
+	tokenURL, err := url.Parse(params["realm"])
+	if err != nil {
+		return fmt.Errorf("invalid token realm: %w", err)
+	}
+	query := tokenURL.Query()
+	if params["service"] != "" {
+		query.Set("service", params["service"])
+	}
+	if params["scope"] != "" {
+		query.Set("scope", params["scope"])
+	}
+	tokenURL.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, tokenURL.String(), nil)
+	if err != nil {
+		return err
+	}
+	if c.username != "" || c.password != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to get registry token: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to get registry token: %s", resp.Status)
+	}
+
+	var body struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := decodeJSON(resp.Body, &body); err != nil {
+		return fmt.Errorf("invalid registry token response: %w", err)
+	}
+	c.token = body.Token
+	if c.token == "" {
+		c.token = body.AccessToken
+	}
+	if c.token == "" {
+		return fmt.Errorf("registry token response contains no token")
+	}
+	return nil
+}
+
+// listTags lists all tags of a repository, following pagination
+func (c *registryClient) listTags(ctx context.Context, repo string) ([]string, error) {
+	var tags []string
+	path := "/v2/" + repo + "/tags/list?n=1000"
+	for page := 0; path != "" && page < 50; page++ {
+		var body struct {
+			Tags []string `json:"tags"`
+		}
+		header, err := c.get(ctx, path, "", &body)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, body.Tags...)
+		path = nextPage(header.Get("Link"))
+	}
+	return tags, nil
+}
+
+// chartMetadata reads the Chart.yaml fields stored as the config of a chart manifest
+func (c *registryClient) chartMetadata(ctx context.Context, repo, tag string) (*ChartVersion, string, error) {
+	var manifest struct {
+		Config struct {
+			MediaType string `json:"mediaType"`
+			Digest    string `json:"digest"`
+		} `json:"config"`
+	}
+	header, err := c.get(ctx, "/v2/"+repo+"/manifests/"+tag, ociManifestMediaType, &manifest)
+	if err != nil {
+		return nil, "", err
+	}
+	if manifest.Config.MediaType != helmConfigMediaType {
+		return nil, "", fmt.Errorf("%s:%s is not a Helm chart", repo, tag)
+	}
+
+	var meta ChartVersion
+	if _, err := c.get(ctx, "/v2/"+repo+"/blobs/"+manifest.Config.Digest, "", &meta); err != nil {
+		return nil, "", err
+	}
+	return &meta, header.Get("Docker-Content-Digest"), nil
+}
+
+// parseChallenge parses `Bearer realm="...",service="...",scope="..."`
+func parseChallenge(header string) (string, map[string]string) {
+	scheme, rest, _ := strings.Cut(strings.TrimSpace(header), " ")
+	params := map[string]string{}
+	for rest != "" {
+		var pair string
+		rest = strings.TrimLeft(rest, ", ")
+		key, value, ok := strings.Cut(rest, "=")
+		if !ok {
+			break
+		}
+		if strings.HasPrefix(value, `"`) {
+			end := strings.Index(value[1:], `"`)
+			if end < 0 {
+				break
+			}
+			pair, rest = value[1:end+1], value[end+2:]
+		} else {
+			pair, rest, _ = strings.Cut(value, ",")
+		}
+		params[strings.ToLower(strings.TrimSpace(key))] = pair
+	}
+	return scheme, params
+}
+
+// nextPage returns the path of the next page from a `Link: </v2/...>; rel="next"` header
+func nextPage(link string) string {
+	if link == "" || !strings.Contains(link, `rel="next"`) {
+		return ""
+	}
+	start := strings.Index(link, "<")
+	end := strings.Index(link, ">")
+	if start < 0 || end <= start {
+		return ""
+	}
+	next, err := url.Parse(link[start+1 : end])
+	if err != nil {
+		return ""
+	}
+	return next.RequestURI()
+}
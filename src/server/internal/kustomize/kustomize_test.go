@@ -0,0 +1,364 @@
+package kustomize
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// writeFiles creates files (path -> content) under a new temporary directory
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func find(objects []*unstructured.Unstructured, kind string) *unstructured.Unstructured {
+	for _, obj := range objects {
+		if obj.GetKind() == kind {
+			return obj
+		}
+	}
+	return nil
+}
+
+var overlayFiles = map[string]string{
+	"base/kustomization.yaml": `
+resources:
+- deployment.yaml
+- service.yaml
+configMapGenerator:
+- name: web-config
+  literals:
+  - LOG_LEVEL=info
+`,
+	"base/deployment.yaml": `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  selector:
+    matchLabels:
+      component: web
+  template:
+    metadata:
+      labels:
+        component: web
+    spec:
+      containers:
+      - name: web
+        image: nginx:1.0
+        envFrom:
+        - configMapRef:
+            name: web-config
+      - name: sidecar
+        image: busybox
+`,
+	"base/service.yaml": `
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  selector:
+    component: web
+  ports:
+  - port: 80
+`,
+	"overlays/prod/kustomization.yaml": `
+resources:
+- ../../base
+namespace: prod
+namePrefix: prod-
+commonLabels:
+  env: prod
+images:
+- name: nginx
+  newTag: "1.25"
+replicas:
+- name: web
+  count: 3
+configMapGenerator:
+- name: web-config
+  behavior: merge
+  literals:
+  - LOG_LEVEL=warn
+patches:
+- patch: |
+    apiVersion: apps/v1
+    kind: Deployment
+    metadata:
+      name: web
+    spec:
+      template:
+        spec:
+          containers:
+          - name: web
+            resources:
+              limits:
+                memory: 256Mi
+- target:
+    kind: Service
+  patch: |
+    - op: replace
+      path: /spec/ports/0/port
+      value: 8080
+`,
+}
+
+func TestRenderOverlay(t *testing.T) {
+	root := writeFiles(t, overlayFiles)
+
+	objects, err := Render(root, "overlays/prod")
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if len(objects) != 3 {
+		t.Fatalf("rendered %d objects, want 3", len(objects))
+	}
+
+	cm := find(objects, "ConfigMap")
+	if !strings.HasPrefix(cm.GetName(), "prod-web-config-") || len(cm.GetName()) != len("prod-web-config-")+10 {
+		t.Errorf("ConfigMap name = %s, want prod-web-config-<hash>", cm.GetName())
+	}
+	if level, _, _ := unstructured.NestedString(cm.Object, "data", "LOG_LEVEL"); level != "warn" {
+		t.Errorf("merged LOG_LEVEL = %s, want warn", level)
+	}
+
+	deploy := find(objects, "Deployment")
+	if deploy.GetName() != "prod-web" || deploy.GetNamespace() != "prod" {
+		t.Errorf("Deployment = %s/%s, want prod/prod-web", deploy.GetNamespace(), deploy.GetName())
+	}
+	if replicas, _, _ := unstructured.NestedInt64(deploy.Object, "spec", "replicas"); replicas != 3 {
+		t.Errorf("replicas = %d, want 3", replicas)
+	}
+	if env, _, _ := unstructured.NestedString(deploy.Object, "spec", "selector", "matchLabels", "env"); env != "prod" {
+		t.Error("commonLabels not added to the selector")
+	}
+	if env, _, _ := unstructured.NestedString(deploy.Object, "spec", "template", "metadata", "labels", "env"); env != "prod" {
+		t.Error("commonLabels not added to the pod template")
+	}
+
+	containers, _, _ := unstructured.NestedSlice(deploy.Object, "spec", "template", "spec", "containers")
+	if len(containers) != 2 {
+		t.Fatalf("containers = %d, want 2 (strategic merge keeps the sidecar)", len(containers))
+	}
+	web := containers[0].(map[string]interface{})
+	if web["image"] != "nginx:1.25" {
+		t.Errorf("image = %v, want nginx:1.25", web["image"])
+	}
+	if memory, _, _ := unstructured.NestedString(web, "resources", "limits", "memory"); memory != "256Mi" {
+		t.Error("strategic merge patch not applied")
+	}
+	envFrom, _, _ := unstructured.NestedSlice(web, "envFrom")
+	if ref, _, _ := unstructured.NestedString(envFrom[0].(map[string]interface{}), "configMapRef", "name"); ref != cm.GetName() {
+		t.Errorf("configMapRef = %s, want %s", ref, cm.GetName())
+	}
+
+	svc := find(objects, "Service")
+	ports, _, _ := unstructured.NestedSlice(svc.Object, "spec", "ports")
+	if port := ports[0].(map[string]interface{})["port"]; port != int64(8080) && port != float64(8080) {
+		t.Errorf("port = %v, want 8080 (JSON 6902 patch)", port)
+	}
+	if env, _, _ := unstructured.NestedString(svc.Object, "spec", "selector", "env"); env != "prod" {
+		t.Error("commonLabels not added to the Service selector")
+	}
+}
+
+func TestRenderNamespacedGenerators(t *testing.T) {
+	// The same generated ConfigMap in two namespaces must keep separate hashed names and
+	// references
+	root := writeFiles(t, map[string]string{
+		"kustomization.yaml": "resources:\n- dev\n- prod\n",
+		"dev/kustomization.yaml": `
+namespace: dev
+resources:
+- ../app
+configMapGenerator:
+- name: app-config
+  literals:
+  - ENV=dev
+`,
+		"prod/kustomization.yaml": `
+namespace: prod
+resources:
+- ../app
+configMapGenerator:
+- name: app-config
+  literals:
+  - ENV=prod
+`,
+		"app/kustomization.yaml": "resources:\n- pod.yaml\n",
+		"app/pod.yaml": `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: app
+spec:
+  containers:
+  - name: app
+    image: busybox
+    envFrom:
+    - configMapRef:
+        name: app-config
+`,
+	})
+
+	objects, err := Render(root, ".")
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	configMaps := map[string]string{}
+	for _, obj := range objects {
+		if obj.GetKind() == "ConfigMap" {
+			configMaps[obj.GetNamespace()] = obj.GetName()
+		}
+	}
+	if len(configMaps) != 2 || configMaps["dev"] == configMaps["prod"] {
+		t.Fatalf("ConfigMaps = %v, want one per namespace with different hashes", configMaps)
+	}
+	for _, obj := range objects {
+		if obj.GetKind() != "Pod" {
+			continue
+		}
+		containers, _, _ := unstructured.NestedSlice(obj.Object, "spec", "containers")
+		envFrom, _, _ := unstructured.NestedSlice(containers[0].(map[string]interface{}), "envFrom")
+		ref, _, _ := unstructured.NestedString(envFrom[0].(map[string]interface{}), "configMapRef", "name")
+		if ref != configMaps[obj.GetNamespace()] {
+			t.Errorf("Pod in %s references %s, want %s", obj.GetNamespace(), ref, configMaps[obj.GetNamespace()])
+		}
+	}
+}
+
+func TestRenderReplacements(t *testing.T) {
+	root := writeFiles(t, map[string]string{
+		"kustomization.yaml": `
+resources:
+- resources.yaml
+replacements:
+- source:
+    kind: ConfigMap
+    name: settings
+    fieldPath: data.host
+  targets:
+  - select:
+      kind: Service
+    fieldPaths:
+    - spec.externalName
+`,
+		"resources.yaml": `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+data:
+  host: db.example.com
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: db
+spec:
+  type: ExternalName
+  externalName: placeholder
+`,
+	})
+
+	objects, err := Render(root, ".")
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if name, _, _ := unstructured.NestedString(find(objects, "Service").Object, "spec", "externalName"); name != "db.example.com" {
+		t.Errorf("externalName = %s, want db.example.com", name)
+	}
+}
+
+func TestRenderRejects(t *testing.T) {
+	tests := []struct {
+		name  string
+		dir   string
+		files map[string]string
+	}{
+		{
+			name:  "path outside of the source",
+			dir:   "app",
+			files: map[string]string{"app/kustomization.yaml": "resources:\n- ../../etc/passwd\n"},
+		},
+		{
+			name:  "missing directory",
+			dir:   "missing",
+			files: map[string]string{"kustomization.yaml": "resources: []\n"},
+		},
+		{
+			name: "duplicate object",
+			dir:  ".",
+			files: map[string]string{
+				"kustomization.yaml": "resources:\n- a.yaml\n- b.yaml\n",
+				"a.yaml":             "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: a\n",
+				"b.yaml":             "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: a\n",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := writeFiles(t, tt.files)
+			if _, err := Render(root, tt.dir); err == nil {
+				t.Error("Render() succeeded, want an error")
+			}
+		})
+	}
+}
+
+func TestRenderRejectsSymlinkEscape(t *testing.T) {
+	outside := writeFiles(t, map[string]string{"secret.yaml": "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: a\n"})
+	root := writeFiles(t, map[string]string{"kustomization.yaml": "resources:\n- link.yaml\n"})
+	if err := os.Symlink(filepath.Join(outside, "secret.yaml"), filepath.Join(root, "link.yaml")); err != nil {
+		t.Skip("symlinks not supported")
+	}
+	if _, err := Render(root, "."); err == nil {
+		t.Error("Render() followed a symlink outside of the sources")
+	}
+}
+
+func TestExtractArchive(t *testing.T) {
+	archive := func(entries map[string]string) []byte {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		tw := tar.NewWriter(gz)
+		for name, content := range entries {
+			tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg})
+			tw.Write([]byte(content))
+		}
+		tw.Close()
+		gz.Close()
+		return buf.Bytes()
+	}
+
+	dir := t.TempDir()
+	if err := ExtractArchive(archive(overlayFiles), dir); err != nil {
+		t.Fatalf("ExtractArchive() error = %v", err)
+	}
+	if _, err := Render(dir, "overlays/prod"); err != nil {
+		t.Errorf("Render() of the extracted archive error = %v", err)
+	}
+
+	if err := ExtractArchive(archive(map[string]string{"../escape.yaml": "x"}), t.TempDir()); err == nil {
+		t.Error("ExtractArchive() accepted an entry outside of the archive")
+	}
+}
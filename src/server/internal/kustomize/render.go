@@ -0,0 +1,127 @@
+// Package kustomize renders kustomizations server-side with the kustomize API (krusty).
+// The sources are copied into an in-memory filesystem before rendering, so a
+// kustomization can only read files from its own sources and never from the server.
+package kustomize
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+const (
+	// maxSourceSize bounds the total size of the sources copied for rendering
+	maxSourceSize = 50 << 20
+	// maxSourceFiles bounds the number of files copied for rendering
+	maxSourceFiles = 10000
+)
+
+// Render builds the kustomization in dir (relative to root) and returns the resulting
+// objects. Only files within root are visible to the kustomization: symlinks resolving
+// outside of it are left out.
+func Render(root, dir string) ([]*unstructured.Unstructured, error) {
+	root, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return nil, err
+	}
+	fSys, err := loadSources(root)
+	if err != nil {
+		return nil, err
+	}
+
+	target := path.Join("/", filepath.ToSlash(dir))
+	if !fSys.IsDir(target) {
+		return nil, fmt.Errorf("%s: not found", dir)
+	}
+
+	resMap, err := krusty.MakeKustomizer(krusty.MakeDefaultOptions()).Run(fSys, target)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := resMap.Resources()
+	objects := make([]*unstructured.Unstructured, 0, len(resources))
+	for _, res := range resources {
+		data, err := res.MarshalJSON()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", res.CurId(), err)
+		}
+		obj := &unstructured.Unstructured{}
+		if err := obj.UnmarshalJSON(data); err != nil {
+			return nil, fmt.Errorf("%s: %v", res.CurId(), err)
+		}
+		objects = append(objects, obj)
+	}
+	return objects, nil
+}
+
+// loadSources copies the regular files under root into an in-memory filesystem rooted at
+// "/". Symlinks are followed only when they resolve within root; the .git directory is
+// skipped.
+func loadSources(root string) (filesys.FileSystem, error) {
+	fSys := filesys.MakeFsInMemory()
+	var total int64
+	files := 0
+
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		target := path.Join("/", filepath.ToSlash(rel))
+
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return fSys.MkdirAll(target)
+		}
+
+		source := p
+		if d.Type()&fs.ModeSymlink != 0 {
+			resolved, err := filepath.EvalSymlinks(p)
+			if err != nil || !within(root, resolved) {
+				return nil
+			}
+			source = resolved
+		}
+		info, err := os.Stat(source)
+		if err != nil || !info.Mode().IsRegular() {
+			return nil
+		}
+
+		files++
+		total += info.Size()
+		if files > maxSourceFiles {
+			return fmt.Errorf("kustomization sources contain more than %d files", maxSourceFiles)
+		}
+		if total > maxSourceSize {
+			return fmt.Errorf("kustomization sources exceed %d MB", maxSourceSize>>20)
+		}
+		data, err := os.ReadFile(source)
+		if err != nil {
+			return err
+		}
+		return fSys.WriteFile(target, data)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return fSys, nil
+}
+
+// within reports whether path is root or below it
+func within(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
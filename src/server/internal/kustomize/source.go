@@ -0,0 +1,131 @@
+package kustomize
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// MaxArchiveSize bounds the size of an uploaded archive once extracted
+	MaxArchiveSize = 20 << 20
+	// maxArchiveFiles bounds the number of files in an uploaded archive
+	maxArchiveFiles = 2000
+)
+
+// ExtractArchive extracts a tarball (gzipped or not) into dir. Only regular files and
+// directories are extracted; links and entries escaping dir are rejected.
+func ExtractArchive(data []byte, dir string) error {
+	var reader io.Reader = bytes.NewReader(data)
+	if len(data) > 2 && data[0] == 0x1f && data[1] == 0x8b {
+		gz, err := gzip.NewReader(reader)
+		if err != nil {
+			return fmt.Errorf("invalid archive: %w", err)
+		}
+		defer gz.Close()
+		reader = gz
+	}
+
+	tr := tar.NewReader(reader)
+	var total int64
+	for files := 0; ; files++ {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("invalid archive: %w", err)
+		}
+		if files >= maxArchiveFiles {
+			return fmt.Errorf("archive contains more than %d files", maxArchiveFiles)
+		}
+
+		name := filepath.Clean(filepath.FromSlash(header.Name))
+		if filepath.IsAbs(name) || name == ".." || strings.HasPrefix(name, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("archive entry %q is outside of the archive", header.Name)
+		}
+		target := filepath.Join(dir, name)
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0o755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			total += header.Size
+			if total > MaxArchiveSize {
+				return fmt.Errorf("archive exceeds %d MB", MaxArchiveSize>>20)
+			}
+			if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+				return err
+			}
+			f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(f, io.LimitReader(tr, header.Size))
+			f.Close()
+			if err != nil {
+				return err
+			}
+		case tar.TypeXGlobalHeader, tar.TypeXHeader:
+		default:
+			return fmt.Errorf("archive entry %q: only files and directories are supported", header.Name)
+		}
+	}
+}
+
+// CloneGit makes a shallow clone of a git repository into dir using the git CLI, as
+// kustomize does for remote bases. Only http(s) URLs are accepted so that the server's own
+// files and other transports cannot be reached. ref is a branch or tag; empty means the
+// default branch.
+func CloneGit(ctx context.Context, repoURL, ref, dir string) error {
+	u, err := url.Parse(repoURL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return fmt.Errorf("git URL must be an http(s) URL")
+	}
+	if strings.HasPrefix(ref, "-") {
+		return fmt.Errorf("invalid git ref %q", ref)
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		return fmt.Errorf("git is not installed on the server")
+	}
+
+	args := []string{"clone", "--depth", "1", "--single-branch", "--no-tags"}
+	if ref != "" {
+		args = append(args, "--branch", ref)
+	}
+	args = append(args, "--", repoURL, dir)
+
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Env = append(os.Environ(),
+		"GIT_TERMINAL_PROMPT=0",
+		"GIT_ALLOW_PROTOCOL=http:https",
+		"GIT_CONFIG_NOSYSTEM=1",
+	)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("git clone timed out")
+		}
+		return fmt.Errorf("git clone failed: %s", redactURL(strings.TrimSpace(stderr.String()), u))
+	}
+	return nil
+}
+
+// redactURL removes the credentials of a URL from a message
+func redactURL(msg string, u *url.URL) string {
+	if u.User == nil {
+		return msg
+	}
+	return strings.ReplaceAll(msg, u.User.String()+"@", "***@")
+}
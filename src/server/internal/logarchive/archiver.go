@@ -0,0 +1,296 @@
+// Package logarchive exports the logs of selected pods to S3-compatible object storage on a
+// schedule, for teams without a central logging stack
+package logarchive
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/sonnguyen/kubelens/internal/cluster"
+	"github.com/sonnguyen/kubelens/internal/crypto"
+	"github.com/sonnguyen/kubelens/internal/db"
+	"github.com/sonnguyen/kubelens/internal/objectstore"
+)
+
+const (
+	// scheduleInterval is how often policies are checked for an elapsed interval
+	scheduleInterval = time.Minute
+	// runTimeout bounds one export of a policy, retention included
+	runTimeout = 30 * time.Minute
+	// maxContainerLog bounds the log of one container exported in one period
+	maxContainerLog = 256 << 20
+	// maxErrors bounds the container errors recorded as the last error of a policy
+	maxErrors = 5
+)
+
+// unsafeKeyChars are replaced in the names making up object keys
+var unsafeKeyChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// Archiver runs the export of each enabled log archive policy when its interval has elapsed
+type Archiver struct {
+	db             *db.DB
+	clusterManager *cluster.Manager
+	ctx            context.Context
+	cancel         context.CancelFunc
+
+	mu      sync.Mutex
+	running map[uint]bool
+}
+
+// NewArchiver creates a log archiver
+func NewArchiver(database *db.DB, clusterManager *cluster.Manager) *Archiver {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Archiver{
+		db:             database,
+		clusterManager: clusterManager,
+		ctx:            ctx,
+		cancel:         cancel,
+		running:        make(map[uint]bool),
+	}
+}
+
+// Start begins exporting the logs of the due policies
+func (a *Archiver) Start() {
+	go func() {
+		ticker := time.NewTicker(scheduleInterval)
+		defer ticker.Stop()
+
+		a.runDue()
+		for {
+			select {
+			case <-ticker.C:
+				a.runDue()
+			case <-a.ctx.Done():
+				return
+			}
+		}
+	}()
+
+	log.Infof("✅ Log archiver started (interval: %v)", scheduleInterval)
+}
+
+// Stop stops the scheduler and interrupts the running exports. Their period is exported
+// again by the next run.
+func (a *Archiver) Stop() {
+	a.cancel()
+	log.Info("Log archiver stopped")
+}
+
+// runDue starts the export of the policies whose interval has elapsed
+func (a *Archiver) runDue() {
+	policies, err := a.db.ListDueLogArchivePolicies(time.Now())
+	if err != nil {
+		log.Errorf("Failed to list log archive policies: %v", err)
+		return
+	}
+	for _, policy := range policies {
+		a.Run(policy)
+	}
+}
+
+// Run starts the export of a policy in the background. It returns false when an export
+// of the policy is already running.
+func (a *Archiver) Run(policy *db.LogArchivePolicy) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.running[policy.ID] {
+		return false
+	}
+	a.running[policy.ID] = true
+
+	go func() {
+		defer func() {
+			a.mu.Lock()
+			delete(a.running, policy.ID)
+			a.mu.Unlock()
+		}()
+
+		ctx, cancel := context.WithTimeout(a.ctx, runTimeout)
+		defer cancel()
+		lastRunAt, err := a.export(ctx, policy, time.Now())
+		lastError := ""
+		if err != nil {
+			lastError = err.Error()
+			log.Warnf("Log archive %s of cluster %s: %v", policy.Name, policy.ClusterName, err)
+		}
+		if err := a.db.RecordLogArchiveRun(policy.ID, lastRunAt, lastError); err != nil {
+			log.Errorf("Failed to record log archive run: %v", err)
+		}
+	}()
+	return true
+}
+
+// IsRunning reports whether an export of a policy is running
+func (a *Archiver) IsRunning(id uint) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.running[id]
+}
+
+// export uploads the logs written by the selected pods since the last run, then deletes the
+// objects older than the retention. It returns the end of the exported period, or nil when
+// it must be exported again: objects are named after the start of their period, so the next
+// run overwrites what this one uploaded.
+func (a *Archiver) export(ctx context.Context, policy *db.LogArchivePolicy, now time.Time) (*time.Time, error) {
+	store, err := a.store(policy)
+	if err != nil {
+		return nil, err
+	}
+	defer store.Close()
+	client, err := a.clusterManager.GetClient(policy.ClusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	since := now.Add(-time.Duration(policy.IntervalMinutes) * time.Minute)
+	if policy.LastRunAt != nil {
+		since = *policy.LastRunAt
+	}
+	failures, err := exportLogs(ctx, client, store, policy, since)
+	if err != nil {
+		return nil, err
+	}
+	var exported *time.Time
+	if len(failures) == 0 {
+		exported = &now
+	}
+
+	if policy.RetentionDays > 0 {
+		prefix := keyPrefix(policy)
+		if policy.Namespace != "" {
+			prefix += safeKey(policy.Namespace) + "/"
+		}
+		if err := prune(ctx, store, prefix, now.AddDate(0, 0, -policy.RetentionDays)); err != nil {
+			failures = append(failures, err)
+		}
+	}
+	if len(failures) > maxErrors {
+		failures = append(failures[:maxErrors], fmt.Errorf("and %d more errors", len(failures)-maxErrors))
+	}
+	return exported, errors.Join(failures...)
+}
+
+// store returns a client of the bucket of a policy
+func (a *Archiver) store(policy *db.LogArchivePolicy) (*objectstore.S3, error) {
+	cfg := objectstore.S3Config{
+		Endpoint:    policy.Endpoint,
+		Bucket:      policy.Bucket,
+		Region:      policy.Region,
+		AccessKeyID: policy.AccessKeyID,
+	}
+	if policy.SecretAccessKey != "" {
+		key, err := a.db.GetOrCreateEncryptionKey()
+		if err != nil {
+			return nil, err
+		}
+		encryptor, err := crypto.NewEncryptor(key)
+		if err != nil {
+			return nil, err
+		}
+		secret, err := encryptor.Decrypt(policy.SecretAccessKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt the secret access key: %w", err)
+		}
+		cfg.SecretAccessKey = string(secret)
+	}
+	return objectstore.NewS3(cfg)
+}
+
+// exportLogs uploads the logs written since a time by the containers of the selected pods,
+// one gzip object per container. It returns the containers that failed, or an error when
+// the pods cannot be listed.
+func exportLogs(ctx context.Context, client kubernetes.Interface, store *objectstore.S3, policy *db.LogArchivePolicy, since time.Time) ([]error, error) {
+	pods, err := client.CoreV1().Pods(policy.Namespace).List(ctx, metav1.ListOptions{LabelSelector: policy.LabelSelector})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	var failures []error
+	for _, pod := range pods.Items {
+		if pod.Status.Phase == corev1.PodPending {
+			continue
+		}
+		for _, container := range pod.Spec.Containers {
+			if err := exportContainerLog(ctx, client, store, policy, &pod, container.Name, since); err != nil {
+				failures = append(failures, fmt.Errorf("%s/%s/%s: %w", pod.Namespace, pod.Name, container.Name, err))
+			}
+			if ctx.Err() != nil {
+				return failures, ctx.Err()
+			}
+		}
+	}
+	return failures, nil
+}
+
+func exportContainerLog(ctx context.Context, client kubernetes.Interface, store *objectstore.S3, policy *db.LogArchivePolicy, pod *corev1.Pod, container string, since time.Time) error {
+	stream, err := client.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{
+		Container:  container,
+		SinceTime:  &metav1.Time{Time: since},
+		Timestamps: true,
+	}).Stream(ctx)
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+
+	var body bytes.Buffer
+	gz := gzip.NewWriter(&body)
+	n, err := io.Copy(gz, io.LimitReader(stream, maxContainerLog))
+	if err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	if n == 0 {
+		return nil
+	}
+
+	since = since.UTC()
+	key := fmt.Sprintf("%s%s/%s/%s/%s/%d.log.gz", keyPrefix(policy), safeKey(pod.Namespace), safeKey(pod.Name),
+		safeKey(container), since.Format("2006/01/02"), since.Unix())
+	return store.Put(ctx, key, "application/gzip", body.Bytes())
+}
+
+// keyPrefix returns the prefix of the objects of a policy, followed by the namespace, pod
+// and container of the log: its prefix and the cluster
+func keyPrefix(policy *db.LogArchivePolicy) string {
+	prefix := policy.Prefix
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	return prefix + safeKey(policy.ClusterName) + "/"
+}
+
+func safeKey(name string) string {
+	return unsafeKeyChars.ReplaceAllString(name, "_")
+}
+
+// prune deletes the objects under a prefix written before a time
+func prune(ctx context.Context, store *objectstore.S3, prefix string, before time.Time) error {
+	objects, err := store.List(ctx, prefix)
+	if err != nil {
+		return err
+	}
+	for _, object := range objects {
+		if object.LastModified.Before(before) {
+			if err := store.Delete(ctx, object.Key); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package logarchive
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/sonnguyen/kubelens/internal/db"
+	"github.com/sonnguyen/kubelens/internal/objectstore"
+)
+
+// bucket records the objects uploaded to it and lists those of listed, written in 2026
+type bucket struct {
+	mu       sync.Mutex
+	uploaded map[string]string
+	deleted  []string
+	listed   []string
+}
+
+func (b *bucket) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	key := strings.TrimPrefix(r.URL.Path, "/logs/")
+	switch r.Method {
+	case http.MethodPut:
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		body, _ := io.ReadAll(gz)
+		b.uploaded[key] = string(body)
+	case http.MethodDelete:
+		b.deleted = append(b.deleted, key)
+	case http.MethodGet:
+		fmt.Fprint(w, "<ListBucketResult>")
+		for i, k := range b.listed {
+			fmt.Fprintf(w, "<Contents><Key>%s</Key><LastModified>2026-01-%02dT00:00:00Z</LastModified></Contents>", k, i+1)
+		}
+		fmt.Fprint(w, "</ListBucketResult>")
+	}
+}
+
+func newBucket(t *testing.T) (*bucket, *objectstore.S3) {
+	b := &bucket{uploaded: map[string]string{}}
+	server := httptest.NewServer(b)
+	t.Cleanup(server.Close)
+	store, err := objectstore.NewS3(objectstore.S3Config{Endpoint: server.URL, Bucket: "logs", AccessKeyID: "AKID", SecretAccessKey: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b, store
+}
+
+func pod(name, app string, phase corev1.PodPhase, containers ...string) *corev1.Pod {
+	p := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "shop", Labels: map[string]string{"app": app}},
+		Status:     corev1.PodStatus{Phase: phase},
+	}
+	for _, container := range containers {
+		p.Spec.Containers = append(p.Spec.Containers, corev1.Container{Name: container})
+	}
+	return p
+}
+
+func TestExportLogs(t *testing.T) {
+	b, store := newBucket(t)
+	client := fake.NewSimpleClientset(
+		pod("web-1", "web", corev1.PodRunning, "app", "proxy"),
+		pod("web-2", "web", corev1.PodPending, "app"),
+		pod("db-1", "db", corev1.PodRunning, "postgres"),
+	)
+	policy := &db.LogArchivePolicy{ClusterName: "prod", Namespace: "shop", LabelSelector: "app=web", Prefix: "archive"}
+	since := time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+
+	failures, err := exportLogs(context.Background(), client, store, policy, since)
+	if err != nil || len(failures) != 0 {
+		t.Fatalf("exportLogs() = %v, %v", failures, err)
+	}
+	var keys []string
+	for key, body := range b.uploaded {
+		keys = append(keys, key)
+		if body != "fake logs" {
+			t.Errorf("object %s = %q, want the container log", key, body)
+		}
+	}
+	sort.Strings(keys)
+	want := []string{
+		fmt.Sprintf("archive/prod/shop/web-1/app/2026/03/04/%d.log.gz", since.Unix()),
+		fmt.Sprintf("archive/prod/shop/web-1/proxy/2026/03/04/%d.log.gz", since.Unix()),
+	}
+	if strings.Join(keys, " ") != strings.Join(want, " ") {
+		t.Errorf("uploaded %v, want %v", keys, want)
+	}
+}
+
+func TestPrune(t *testing.T) {
+	b, store := newBucket(t)
+	b.listed = []string{"prod/a.log.gz", "prod/b.log.gz", "prod/c.log.gz"}
+
+	if err := prune(context.Background(), store, "prod/", time.Date(2026, 1, 3, 0, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(b.deleted, " ") != "prod/a.log.gz prod/b.log.gz" {
+		t.Errorf("deleted %v, want the objects written before the retention", b.deleted)
+	}
+}
+
+func TestPolicyRequestValidation(t *testing.T) {
+	valid := PolicyRequest{Name: "web", LabelSelector: "app=web", Bucket: "logs"}
+	if err := valid.validate(); err != nil || valid.IntervalMinutes != defaultIntervalMinutes {
+		t.Fatalf("validate() = %v, interval %d", err, valid.IntervalMinutes)
+	}
+
+	secret := "secret"
+	for _, req := range []PolicyRequest{
+		{Name: "Web", LabelSelector: "app=web", Bucket: "logs"},
+		{Name: "web", LabelSelector: "app in (", Bucket: "logs"},
+		{Name: "web", LabelSelector: " ", Bucket: "logs"},
+		{Name: "web", LabelSelector: "app=web", Bucket: "logs", IntervalMinutes: 1},
+		{Name: "web", LabelSelector: "app=web", Bucket: "logs", RetentionDays: -1},
+		{Name: "web", LabelSelector: "app=web", Bucket: "logs", Prefix: "a b"},
+		{Name: "web", LabelSelector: "app=web", Bucket: "logs", SecretAccessKey: &secret},
+		{Name: "web", LabelSelector: "app=web", Bucket: "logs", Endpoint: "minio:9000"},
+	} {
+		if err := req.validate(); err == nil {
+			t.Errorf("validate(%+v) succeeded, want an error", req)
+		}
+	}
+}
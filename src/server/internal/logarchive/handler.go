@@ -0,0 +1,276 @@
+package logarchive
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/sonnguyen/kubelens/internal/audit"
+	"github.com/sonnguyen/kubelens/internal/crypto"
+	"github.com/sonnguyen/kubelens/internal/db"
+	"github.com/sonnguyen/kubelens/internal/objectstore"
+)
+
+const (
+	// defaultIntervalMinutes is the time between exports when a policy leaves it out
+	defaultIntervalMinutes = 60
+	// minIntervalMinutes and maxIntervalMinutes bound the time between exports
+	minIntervalMinutes = 5
+	maxIntervalMinutes = 7 * 24 * 60
+	// maxRetentionDays bounds the retention of exported objects
+	maxRetentionDays = 3650
+)
+
+// policyNamePattern is what policy names may hold
+var policyNamePattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// Handler handles log archive policy API requests
+type Handler struct {
+	db       *db.DB
+	archiver *Archiver
+}
+
+// NewHandler creates a new log archive policy handler. archiver is nil when exports are
+// disabled on this server.
+func NewHandler(database *db.DB, archiver *Archiver) *Handler {
+	return &Handler{
+		db:       database,
+		archiver: archiver,
+	}
+}
+
+// PolicyRequest is the body for creating or replacing a log archive policy. A nil secret
+// access key keeps the stored one; an empty string clears it.
+type PolicyRequest struct {
+	Name            string  `json:"name" binding:"required"`
+	Namespace       string  `json:"namespace"`                         // empty for every namespace
+	LabelSelector   string  `json:"label_selector" binding:"required"` // e.g. app=checkout,tier!=cache
+	IntervalMinutes int     `json:"interval_minutes"`                  // default 60
+	RetentionDays   int     `json:"retention_days"`                    // 0 keeps the exported objects
+	Endpoint        string  `json:"endpoint"`                          // S3-compatible endpoint; empty for AWS
+	Bucket          string  `json:"bucket" binding:"required"`
+	Prefix          string  `json:"prefix"`
+	Region          string  `json:"region"`
+	AccessKeyID     string  `json:"access_key_id"` // empty for the server's AWS_* credentials
+	SecretAccessKey *string `json:"secret_access_key"`
+	Enabled         *bool   `json:"enabled"` // default true
+}
+
+// validate applies the defaults of a request and checks it
+func (req *PolicyRequest) validate() error {
+	if !policyNamePattern.MatchString(req.Name) {
+		return fmt.Errorf("name must be a lowercase DNS label")
+	}
+	selector, err := labels.Parse(req.LabelSelector)
+	if err != nil {
+		return fmt.Errorf("invalid label_selector: %v", err)
+	}
+	if selector.Empty() {
+		return fmt.Errorf("label_selector must select the pods whose logs are exported")
+	}
+	if req.IntervalMinutes == 0 {
+		req.IntervalMinutes = defaultIntervalMinutes
+	}
+	if req.IntervalMinutes < minIntervalMinutes || req.IntervalMinutes > maxIntervalMinutes {
+		return fmt.Errorf("interval_minutes must be between %d and %d", minIntervalMinutes, maxIntervalMinutes)
+	}
+	if req.RetentionDays < 0 || req.RetentionDays > maxRetentionDays {
+		return fmt.Errorf("retention_days must be between 0 and %d", maxRetentionDays)
+	}
+	if !objectstore.KeyPattern.MatchString(req.Prefix) {
+		return fmt.Errorf("prefix may only hold letters, digits and . _ / -")
+	}
+	if req.AccessKeyID == "" && req.SecretAccessKey != nil && *req.SecretAccessKey != "" {
+		return fmt.Errorf("secret_access_key requires an access_key_id")
+	}
+	return objectstore.S3Config{Endpoint: req.Endpoint, Bucket: req.Bucket, Region: req.Region}.Validate()
+}
+
+// ListPolicies handles GET /api/v1/clusters/:name/log-archives
+func (h *Handler) ListPolicies(c *gin.Context) {
+	policies, err := h.db.ListLogArchivePolicies(c.Param("name"))
+	if err != nil {
+		log.Errorf("Failed to list log archive policies: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list log archive policies"})
+		return
+	}
+	c.JSON(http.StatusOK, policies)
+}
+
+// CreatePolicy handles POST /api/v1/clusters/:name/log-archives
+func (h *Handler) CreatePolicy(c *gin.Context) {
+	clusterName := c.Param("name")
+	if _, err := h.db.GetCluster(clusterName); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	h.savePolicy(c, &db.LogArchivePolicy{ClusterName: clusterName}, http.StatusCreated)
+}
+
+// UpdatePolicy handles PUT /api/v1/clusters/:name/log-archives/:id and replaces the policy
+func (h *Handler) UpdatePolicy(c *gin.Context) {
+	policy, ok := h.loadPolicy(c)
+	if !ok {
+		return
+	}
+	h.savePolicy(c, policy, http.StatusOK)
+}
+
+func (h *Handler) savePolicy(c *gin.Context, policy *db.LogArchivePolicy, status int) {
+	var req PolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := req.validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if existing, err := h.db.GetLogArchivePolicyByName(policy.ClusterName, req.Name); err == nil && existing.ID != policy.ID {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("log archive policy %s already exists", req.Name)})
+		return
+	}
+
+	// Logs are exported from the last run on; a new selection starts from the next run
+	if policy.ID != 0 && (policy.Namespace != req.Namespace || policy.LabelSelector != req.LabelSelector) {
+		policy.LastRunAt = nil
+	}
+	policy.Name = req.Name
+	policy.Namespace = req.Namespace
+	policy.LabelSelector = req.LabelSelector
+	policy.IntervalMinutes = req.IntervalMinutes
+	policy.RetentionDays = req.RetentionDays
+	policy.Endpoint = req.Endpoint
+	policy.Bucket = req.Bucket
+	policy.Prefix = req.Prefix
+	policy.Region = req.Region
+	policy.AccessKeyID = req.AccessKeyID
+	policy.Enabled = req.Enabled == nil || *req.Enabled
+	if req.SecretAccessKey != nil {
+		policy.SecretAccessKey = ""
+		if *req.SecretAccessKey != "" {
+			encrypted, err := h.encrypt(*req.SecretAccessKey)
+			if err != nil {
+				log.Errorf("Failed to encrypt log archive secret: %v", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to store the secret"})
+				return
+			}
+			policy.SecretAccessKey = encrypted
+		}
+	}
+	if policy.AccessKeyID == "" {
+		policy.SecretAccessKey = ""
+	} else if policy.SecretAccessKey == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "access_key_id requires a secret_access_key"})
+		return
+	}
+
+	var err error
+	if policy.ID == 0 {
+		err = h.db.CreateLogArchivePolicy(policy)
+	} else {
+		err = h.db.UpdateLogArchivePolicy(policy)
+	}
+	if err != nil {
+		log.Errorf("Failed to save log archive policy: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save log archive policy"})
+		return
+	}
+
+	action := "created"
+	if status == http.StatusOK {
+		action = "updated"
+	}
+	h.audit(c, policy, action)
+	c.JSON(status, policy)
+}
+
+// DeletePolicy handles DELETE /api/v1/clusters/:name/log-archives/:id. Exported objects
+// are kept.
+func (h *Handler) DeletePolicy(c *gin.Context) {
+	policy, ok := h.loadPolicy(c)
+	if !ok {
+		return
+	}
+	if err := h.db.DeleteLogArchivePolicy(policy.ID); err != nil {
+		log.Errorf("Failed to delete log archive policy: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete log archive policy"})
+		return
+	}
+
+	h.audit(c, policy, "deleted")
+	c.JSON(http.StatusOK, gin.H{"message": "log archive policy deleted"})
+}
+
+// RunPolicy handles POST /api/v1/clusters/:name/log-archives/:id/run and starts an export
+// without waiting for the interval
+func (h *Handler) RunPolicy(c *gin.Context) {
+	policy, ok := h.loadPolicy(c)
+	if !ok {
+		return
+	}
+	if h.archiver == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "log archiving is disabled on this server"})
+		return
+	}
+	if !h.archiver.Run(policy) {
+		c.JSON(http.StatusConflict, gin.H{"error": "an export of this policy is already running"})
+		return
+	}
+
+	h.audit(c, policy, "run")
+	c.JSON(http.StatusAccepted, gin.H{"message": "log export started"})
+}
+
+func (h *Handler) loadPolicy(c *gin.Context) (*db.LogArchivePolicy, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid log archive policy ID"})
+		return nil, false
+	}
+
+	policy, err := h.db.GetLogArchivePolicy(uint(id))
+	if err == nil && policy.ClusterName != c.Param("name") {
+		err = fmt.Errorf("log archive policy not found with ID: %d", id)
+	}
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	return policy, true
+}
+
+func (h *Handler) encrypt(secret string) (string, error) {
+	key, err := h.db.GetOrCreateEncryptionKey()
+	if err != nil {
+		return "", err
+	}
+	encryptor, err := crypto.NewEncryptor(key)
+	if err != nil {
+		return "", err
+	}
+	return encryptor.Encrypt([]byte(secret))
+}
+
+func (h *Handler) audit(c *gin.Context, policy *db.LogArchivePolicy, action string) {
+	if userID, exists := c.Get("user_id"); exists {
+		username, _ := c.Get("username")
+		email, _ := c.Get("email")
+
+		audit.Log(c, audit.EventAuditConfigChanged, userID.(int), username.(string), email.(string),
+			fmt.Sprintf("Log archive policy %s %s for cluster %s", policy.Name, action, policy.ClusterName),
+			map[string]interface{}{
+				"cluster_name":   policy.ClusterName,
+				"policy_id":      policy.ID,
+				"namespace":      policy.Namespace,
+				"label_selector": policy.LabelSelector,
+				"bucket":         policy.Bucket,
+				"action":         action,
+			})
+	}
+}
@@ -0,0 +1,336 @@
+// Package mail sends email through the SMTP server configured in the system settings:
+// notification deliveries, password reset links and user invitations.
+package mail
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sonnguyen/kubelens/internal/crypto"
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+const (
+	// configKey is the system config key the SMTP settings are stored under
+	configKey = "smtp_config"
+	// timeout bounds a whole delivery, from connecting to QUIT
+	timeout = 30 * time.Second
+)
+
+// Connection security modes
+const (
+	SecurityStartTLS = "starttls" // plain connection upgraded with STARTTLS (port 587)
+	SecurityTLS      = "tls"      // implicit TLS (port 465)
+	SecurityNone     = "none"     // no encryption; only for relays on a trusted network
+)
+
+// ErrNotConfigured is returned when email is sent while SMTP is not configured or disabled
+var ErrNotConfigured = errors.New("email (SMTP) is not configured")
+
+// Config configures the SMTP server mail is sent through
+type Config struct {
+	Enabled            bool   `json:"enabled"`
+	Host               string `json:"host"`
+	Port               int    `json:"port"`
+	Security           string `json:"security"`
+	InsecureSkipVerify bool   `json:"insecure_skip_verify"`
+
+	// Credentials for SMTP AUTH; empty for relays that do not authenticate
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+
+	From     string `json:"from"` // sender address, e.g. kubelens@example.com
+	FromName string `json:"from_name,omitempty"`
+}
+
+// applyDefaults fills in the security mode and its usual port
+func (cfg *Config) applyDefaults() {
+	if cfg.Security == "" {
+		cfg.Security = SecurityStartTLS
+	}
+	if cfg.Port == 0 {
+		switch cfg.Security {
+		case SecurityTLS:
+			cfg.Port = 465
+		case SecurityNone:
+			cfg.Port = 25
+		default:
+			cfg.Port = 587
+		}
+	}
+}
+
+// Validate checks the settings needed to send mail
+func (cfg *Config) Validate() error {
+	cfg.applyDefaults()
+	if cfg.Host == "" || strings.ContainsAny(cfg.Host, " /:") {
+		return fmt.Errorf("host must be a hostname or IP address without a port")
+	}
+	if cfg.Port < 1 || cfg.Port > 65535 {
+		return fmt.Errorf("port must be between 1 and 65535")
+	}
+	switch cfg.Security {
+	case SecurityStartTLS, SecurityTLS, SecurityNone:
+	default:
+		return fmt.Errorf("security must be starttls, tls or none")
+	}
+	if cfg.Username != "" && cfg.Password == "" {
+		return fmt.Errorf("password is required with username")
+	}
+	if _, err := mail.ParseAddress(cfg.From); err != nil {
+		return fmt.Errorf("from must be an email address")
+	}
+	if strings.ContainsAny(cfg.FromName, "\r\n") {
+		return fmt.Errorf("from_name must be a single line")
+	}
+	return nil
+}
+
+func encryptor(database *db.DB) (*crypto.Encryptor, error) {
+	key, err := database.GetOrCreateEncryptionKey()
+	if err != nil {
+		return nil, err
+	}
+	return crypto.NewEncryptor(key)
+}
+
+// Load returns the stored SMTP settings with the password decrypted, or nil when SMTP
+// was never configured
+func Load(database *db.DB) (*Config, error) {
+	value, err := database.GetSystemConfig(configKey)
+	if err != nil || value == "" {
+		return nil, nil
+	}
+
+	var cfg Config
+	if err := json.Unmarshal([]byte(value), &cfg); err != nil {
+		return nil, fmt.Errorf("invalid stored SMTP config: %w", err)
+	}
+	if cfg.Password != "" {
+		encryptor, err := encryptor(database)
+		if err != nil {
+			return nil, err
+		}
+		password, err := encryptor.Decrypt(cfg.Password)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt SMTP password: %w", err)
+		}
+		cfg.Password = string(password)
+	}
+	cfg.applyDefaults()
+	return &cfg, nil
+}
+
+// Save stores the SMTP settings, encrypting the password
+func Save(database *db.DB, cfg Config) error {
+	if cfg.Password != "" {
+		encryptor, err := encryptor(database)
+		if err != nil {
+			return err
+		}
+		if cfg.Password, err = encryptor.Encrypt([]byte(cfg.Password)); err != nil {
+			return err
+		}
+	}
+	value, err := json.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+	return database.SetSystemConfig(configKey, string(value))
+}
+
+// Enabled returns the SMTP settings when sending mail is enabled, or ErrNotConfigured
+func Enabled(database *db.DB) (*Config, error) {
+	cfg, err := Load(database)
+	if err != nil {
+		return nil, err
+	}
+	if cfg == nil || !cfg.Enabled {
+		return nil, ErrNotConfigured
+	}
+	return cfg, nil
+}
+
+// Message is a plain text email
+type Message struct {
+	To      []string
+	Subject string
+	Body    string
+}
+
+// SendMessage sends a message with the stored settings
+func SendMessage(database *db.DB, msg *Message) error {
+	cfg, err := Enabled(database)
+	if err != nil {
+		return err
+	}
+	return Send(cfg, msg)
+}
+
+// Send delivers a message through the SMTP server of cfg
+func Send(cfg *Config, msg *Message) error {
+	if len(msg.To) == 0 {
+		return fmt.Errorf("no recipients")
+	}
+	recipients := make([]string, 0, len(msg.To))
+	for _, to := range msg.To {
+		addr, err := mail.ParseAddress(to)
+		if err != nil {
+			return fmt.Errorf("invalid recipient %q", to)
+		}
+		recipients = append(recipients, addr.Address)
+	}
+	from, err := mail.ParseAddress(cfg.From)
+	if err != nil {
+		return fmt.Errorf("invalid sender %q", cfg.From)
+	}
+	data, err := buildMessage(cfg, from.Address, recipients, msg)
+	if err != nil {
+		return err
+	}
+
+	client, err := dial(cfg)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if cfg.Username != "" {
+		if ok, _ := client.Extension("AUTH"); !ok {
+			return fmt.Errorf("SMTP server does not support authentication")
+		}
+		if err := client.Auth(smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)); err != nil {
+			return fmt.Errorf("SMTP authentication failed: %w", err)
+		}
+	}
+	if err := client.Mail(from.Address); err != nil {
+		return fmt.Errorf("SMTP server rejected the sender: %w", err)
+	}
+	for _, rcpt := range recipients {
+		if err := client.Rcpt(rcpt); err != nil {
+			return fmt.Errorf("SMTP server rejected recipient %s: %w", rcpt, err)
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("SMTP server rejected the message: %w", err)
+	}
+	return client.Quit()
+}
+
+// dial connects to the SMTP server, with implicit TLS or STARTTLS as configured
+func dial(cfg *Config) (*smtp.Client, error) {
+	addr := net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port))
+	tlsConfig := &tls.Config{ServerName: cfg.Host, InsecureSkipVerify: cfg.InsecureSkipVerify}
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+
+	var conn net.Conn
+	var err error
+	if cfg.Security == SecurityTLS {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", addr, err)
+	}
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	client, err := smtp.NewClient(conn, cfg.Host)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("SMTP handshake with %s failed: %w", addr, err)
+	}
+	if err := client.Hello(helloName()); err != nil {
+		client.Close()
+		return nil, err
+	}
+	if cfg.Security == SecurityStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			client.Close()
+			return nil, fmt.Errorf("SMTP server does not support STARTTLS")
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("STARTTLS failed: %w", err)
+		}
+	}
+	return client, nil
+}
+
+// helloName is the name kubelens introduces itself with
+func helloName() string {
+	if name, err := os.Hostname(); err == nil && name != "" && !strings.ContainsAny(name, " \r\n") {
+		return name
+	}
+	return "localhost"
+}
+
+// buildMessage renders the headers and quoted-printable body of a message
+func buildMessage(cfg *Config, from string, to []string, msg *Message) ([]byte, error) {
+	if strings.ContainsAny(msg.Subject, "\r\n") {
+		return nil, fmt.Errorf("subject must be a single line")
+	}
+
+	var buf bytes.Buffer
+	sender := mail.Address{Name: cfg.FromName, Address: from}
+	writeHeader(&buf, "From", sender.String())
+	writeHeader(&buf, "To", strings.Join(to, ", "))
+	writeHeader(&buf, "Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	writeHeader(&buf, "Date", time.Now().Format(time.RFC1123Z))
+	writeHeader(&buf, "Message-ID", messageID(from))
+	writeHeader(&buf, "MIME-Version", "1.0")
+	writeHeader(&buf, "Content-Type", "text/plain; charset=utf-8")
+	writeHeader(&buf, "Content-Transfer-Encoding", "quoted-printable")
+	writeHeader(&buf, "Auto-Submitted", "auto-generated")
+	buf.WriteString("\r\n")
+
+	body := strings.ReplaceAll(strings.ReplaceAll(msg.Body, "\r\n", "\n"), "\n", "\r\n")
+	qp := quotedprintable.NewWriter(&buf)
+	if _, err := qp.Write([]byte(body)); err != nil {
+		return nil, err
+	}
+	if err := qp.Close(); err != nil {
+		return nil, err
+	}
+	buf.WriteString("\r\n")
+	return buf.Bytes(), nil
+}
+
+func writeHeader(buf *bytes.Buffer, name, value string) {
+	buf.WriteString(name)
+	buf.WriteString(": ")
+	buf.WriteString(value)
+	buf.WriteString("\r\n")
+}
+
+// messageID returns a unique Message-ID in the domain of the sender
+func messageID(from string) string {
+	domain := "kubelens"
+	if i := strings.LastIndex(from, "@"); i >= 0 {
+		domain = from[i+1:]
+	}
+	b := make([]byte, 12)
+	rand.Read(b)
+	return fmt.Sprintf("<%d.%s@%s>", time.Now().UnixNano(), hex.EncodeToString(b), domain)
+}
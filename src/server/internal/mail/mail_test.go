@@ -0,0 +1,118 @@
+package mail
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+)
+
+// fakeSMTP accepts one session on a local listener and returns the envelope and data
+func fakeSMTP(t *testing.T) (port int, received chan []string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	received = make(chan []string, 1)
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+		var lines []string
+		reply("220 fake ESMTP")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			switch cmd := strings.ToUpper(strings.Fields(line + " x")[0]); cmd {
+			case "EHLO", "HELO":
+				reply("250 fake")
+			case "MAIL", "RCPT":
+				lines = append(lines, line)
+				reply("250 OK")
+			case "DATA":
+				reply("354 go ahead")
+				for {
+					data, err := r.ReadString('\n')
+					if err != nil || data == ".\r\n" {
+						break
+					}
+					lines = append(lines, strings.TrimRight(data, "\r\n"))
+				}
+				reply("250 queued")
+			case "QUIT":
+				reply("221 bye")
+				received <- lines
+				return
+			default:
+				reply("502 unsupported")
+			}
+		}
+	}()
+	return ln.Addr().(*net.TCPAddr).Port, received
+}
+
+func TestSend(t *testing.T) {
+	port, received := fakeSMTP(t)
+	cfg := &Config{Enabled: true, Host: "127.0.0.1", Port: port, Security: SecurityNone, From: "kubelens@example.com", FromName: "Kubelens"}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	err := Send(cfg, &Message{
+		To:      []string{"Ops <ops@example.com>", "dev@example.com"},
+		Subject: "Pod crash looping – web",
+		Body:    "Restarted 5 times.\nSee the cluster.",
+	})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	session := strings.Join(<-received, "\n")
+	for _, want := range []string{
+		"MAIL FROM:<kubelens@example.com>",
+		"RCPT TO:<ops@example.com>",
+		"RCPT TO:<dev@example.com>",
+		`From: "Kubelens" <kubelens@example.com>`,
+		"To: ops@example.com, dev@example.com",
+		"Subject: =?utf-8?q?Pod_crash_looping_=E2=80=93_web?=",
+		"Restarted 5 times.",
+	} {
+		if !strings.Contains(session, want) {
+			t.Errorf("session lacks %q:\n%s", want, session)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, cfg := range []Config{
+		{Host: "smtp.example.com:587", From: "a@example.com"},
+		{Host: "smtp.example.com", From: "not an address"},
+		{Host: "smtp.example.com", From: "a@example.com", Security: "ssl"},
+		{Host: "smtp.example.com", From: "a@example.com", Username: "user"},
+		{Host: "smtp.example.com", From: "a@example.com", Port: 70000},
+	} {
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("Validate(%+v) accepted invalid settings", cfg)
+		}
+	}
+
+	cfg := Config{Host: "smtp.example.com", From: "a@example.com", Security: SecurityTLS}
+	if err := cfg.Validate(); err != nil || cfg.Port != 465 {
+		t.Errorf("Validate() = %v, port %d; want the implicit TLS port", err, cfg.Port)
+	}
+
+	if err := Send(&cfg, &Message{To: []string{"a@example.com"}, Subject: "two\r\nlines"}); err == nil {
+		t.Error("Send() accepted a subject with a line break")
+	}
+}
@@ -0,0 +1,112 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gorm.io/gorm"
+)
+
+var (
+	clusterClientErrors = factory.NewCounterVec(prometheus.CounterOpts{Name: "kubelens_cluster_client_errors_total",
+		Help: "Failed requests to Kubernetes API servers, by cluster and reason (transport, or the HTTP status code for 401, 403, 429 and 5xx responses)."},
+		[]string{"cluster", "reason"})
+	dbQueryDuration = factory.NewHistogramVec(prometheus.HistogramOpts{Name: "kubelens_db_query_duration_seconds",
+		Help: "Database query latency by operation.", Buckets: DefBuckets}, []string{"operation"})
+)
+
+// RegisterWebSocketConnections exposes the number of open WebSocket connections
+func RegisterWebSocketConnections(count func() int) {
+	factory.NewGaugeFunc(prometheus.GaugeOpts{Name: "kubelens_websocket_connections", Help: "Open WebSocket connections."},
+		func() float64 { return float64(count()) })
+}
+
+// RegisterExtensionHealth exposes whether each extension is running (1) or not (0)
+func RegisterExtensionHealth(running func() map[string]bool) {
+	Default.MustRegister(&extensionHealth{
+		desc:    prometheus.NewDesc("kubelens_extension_up", "Whether an extension process is running.", []string{"extension"}, nil),
+		running: running,
+	})
+}
+
+// extensionHealth collects kubelens_extension_up for the extensions known when scraped
+type extensionHealth struct {
+	desc    *prometheus.Desc
+	running func() map[string]bool
+}
+
+func (e *extensionHealth) Describe(ch chan<- *prometheus.Desc) { ch <- e.desc }
+
+func (e *extensionHealth) Collect(ch chan<- prometheus.Metric) {
+	for name, up := range e.running() {
+		value := 0.0
+		if up {
+			value = 1
+		}
+		ch <- prometheus.MustNewConstMetric(e.desc, prometheus.GaugeValue, value, name)
+	}
+}
+
+// ClusterTransport wraps a Kubernetes client transport to count failed requests to the
+// cluster. Use it as rest.Config.WrapTransport.
+func ClusterTransport(cluster string) func(http.RoundTripper) http.RoundTripper {
+	return func(rt http.RoundTripper) http.RoundTripper {
+		return &clusterRoundTripper{cluster: cluster, next: rt}
+	}
+}
+
+type clusterRoundTripper struct {
+	cluster string
+	next    http.RoundTripper
+}
+
+func (t *clusterRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	switch {
+	case err != nil:
+		clusterClientErrors.WithLabelValues(t.cluster, "transport").Inc()
+	case resp.StatusCode >= 500, resp.StatusCode == http.StatusUnauthorized,
+		resp.StatusCode == http.StatusForbidden, resp.StatusCode == http.StatusTooManyRequests:
+		// 404s and conflicts are part of normal operation and not counted
+		clusterClientErrors.WithLabelValues(t.cluster, strconv.Itoa(resp.StatusCode)).Inc()
+	}
+	return resp, err
+}
+
+const dbStartKey = "metrics:start"
+
+// InstrumentGorm records the duration of every database operation
+func InstrumentGorm(db *gorm.DB) error {
+	before := func(tx *gorm.DB) { tx.InstanceSet(dbStartKey, time.Now()) }
+	after := func(operation string) func(*gorm.DB) {
+		return func(tx *gorm.DB) {
+			if start, ok := tx.InstanceGet(dbStartKey); ok {
+				dbQueryDuration.WithLabelValues(operation).Observe(time.Since(start.(time.Time)).Seconds())
+			}
+		}
+	}
+
+	cb := db.Callback()
+	registrations := []error{
+		cb.Create().Before("gorm:create").Register("metrics:before_create", before),
+		cb.Create().After("gorm:create").Register("metrics:after_create", after("create")),
+		cb.Query().Before("gorm:query").Register("metrics:before_query", before),
+		cb.Query().After("gorm:query").Register("metrics:after_query", after("query")),
+		cb.Update().Before("gorm:update").Register("metrics:before_update", before),
+		cb.Update().After("gorm:update").Register("metrics:after_update", after("update")),
+		cb.Delete().Before("gorm:delete").Register("metrics:before_delete", before),
+		cb.Delete().After("gorm:delete").Register("metrics:after_delete", after("delete")),
+		cb.Row().Before("gorm:row").Register("metrics:before_row", before),
+		cb.Row().After("gorm:row").Register("metrics:after_row", after("row")),
+		cb.Raw().Before("gorm:raw").Register("metrics:before_raw", before),
+		cb.Raw().After("gorm:raw").Register("metrics:after_raw", after("raw")),
+	}
+	for _, err := range registrations {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
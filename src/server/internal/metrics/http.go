@@ -0,0 +1,98 @@
+package metrics
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	httpRequests = factory.NewCounterVec(prometheus.CounterOpts{Name: "kubelens_http_requests_total",
+		Help: "HTTP requests handled, by route group, method and status code."}, []string{"group", "method", "code"})
+	httpDuration = factory.NewHistogramVec(prometheus.HistogramOpts{Name: "kubelens_http_request_duration_seconds",
+		Help: "HTTP request latency by route group. WebSocket upgrades are excluded.", Buckets: DefBuckets}, []string{"group", "method"})
+	httpRequestSize = factory.NewHistogramVec(prometheus.HistogramOpts{Name: "kubelens_http_request_size_bytes",
+		Help: "HTTP request body size by route group.", Buckets: SizeBuckets}, []string{"group"})
+	httpResponseSize = factory.NewHistogramVec(prometheus.HistogramOpts{Name: "kubelens_http_response_size_bytes",
+		Help: "HTTP response body size by route group. WebSocket upgrades are excluded.", Buckets: SizeBuckets}, []string{"group"})
+)
+
+// RouteGroup maps a matched route pattern to a low-cardinality group: the first path
+// segment below /api/v1, plus the resource type for cluster routes. For example
+// /api/v1/clusters/:name/namespaces/:namespace/pods/:pod/logs becomes "clusters/pods".
+// Unmatched requests (404s, static assets) are grouped as "other".
+func RouteGroup(fullPath string) string {
+	if fullPath == "" {
+		return "other"
+	}
+	path := strings.TrimPrefix(fullPath, "/api/v1")
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	if segments[0] == "" {
+		return "root"
+	}
+	if segments[0] != "clusters" {
+		if strings.HasPrefix(segments[0], ":") || strings.HasPrefix(segments[0], "*") {
+			return "other"
+		}
+		return segments[0]
+	}
+
+	// Skip the cluster and namespace parameters to find the resource type
+	for i := 1; i < len(segments); i++ {
+		segment := segments[i]
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			continue
+		}
+		if segment == "namespaces" && i+2 < len(segments) && strings.HasPrefix(segments[i+1], ":") {
+			// Namespaced resource: the type follows the namespace parameter
+			continue
+		}
+		return "clusters/" + segment
+	}
+	return "clusters"
+}
+
+// Middleware records request counts, latency and sizes per route group
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		upgrade := strings.EqualFold(c.GetHeader("Upgrade"), "websocket")
+
+		c.Next()
+
+		group := RouteGroup(c.FullPath())
+		method := c.Request.Method
+		httpRequests.WithLabelValues(group, method, strconv.Itoa(c.Writer.Status())).Inc()
+		if c.Request.ContentLength >= 0 {
+			httpRequestSize.WithLabelValues(group).Observe(float64(c.Request.ContentLength))
+		}
+		if upgrade {
+			// Long-lived connections would only skew latency; they are counted by the hub
+			return
+		}
+		httpDuration.WithLabelValues(group, method).Observe(time.Since(start).Seconds())
+		httpResponseSize.WithLabelValues(group).Observe(float64(max(c.Writer.Size(), 0)))
+	}
+}
+
+// Handler serves the default registry. When token is set, requests must send it as a
+// bearer token.
+func Handler(token string) gin.HandlerFunc {
+	handler := promhttp.HandlerFor(Default, promhttp.HandlerOpts{})
+	return func(c *gin.Context) {
+		if token != "" {
+			got := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+			if subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+				c.AbortWithStatus(http.StatusUnauthorized)
+				return
+			}
+		}
+		handler.ServeHTTP(c.Writer, c.Request)
+	}
+}
@@ -0,0 +1,32 @@
+// Package metrics collects operational metrics of the kubelens server and exposes them to
+// Prometheus.
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// DefBuckets are latency buckets in seconds, suitable for HTTP requests and DB queries
+var DefBuckets = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// SizeBuckets are size buckets in bytes
+var SizeBuckets = []float64{100, 1000, 10000, 100000, 1e6, 1e7, 1e8}
+
+// Default is the registry served on /metrics. It holds the Go runtime and process metrics
+// besides those of kubelens.
+var Default = prometheus.NewRegistry()
+
+// factory creates metrics registered with Default
+var factory = promauto.With(Default)
+
+var startTime = time.Now()
+
+func init() {
+	Default.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	factory.NewGaugeFunc(prometheus.GaugeOpts{Name: "kubelens_uptime_seconds", Help: "Seconds since the server started."},
+		func() float64 { return time.Since(startTime).Seconds() })
+}
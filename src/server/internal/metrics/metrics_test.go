@@ -0,0 +1,85 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestRouteGroup(t *testing.T) {
+	tests := []struct {
+		path, want string
+	}{
+		{"", "other"},
+		{"/", "root"},
+		{"/health", "health"},
+		{"/metrics", "metrics"},
+		{"/api/v1/auth/signin", "auth"},
+		{"/api/v1/system/maintenance", "system"},
+		{"/api/v1/clusters", "clusters"},
+		{"/api/v1/clusters/:name", "clusters"},
+		{"/api/v1/clusters/:name/nodes/:node/shell", "clusters/nodes"},
+		{"/api/v1/clusters/:name/namespaces", "clusters/namespaces"},
+		{"/api/v1/clusters/:name/namespaces/:namespace/pods/:pod/logs", "clusters/pods"},
+		{"/api/v1/clusters/:name/namespaces/:namespace", "clusters/namespaces"},
+		{"/scim/v2/Users", "scim"},
+		{"/*filepath", "other"},
+	}
+	for _, tt := range tests {
+		if got := RouteGroup(tt.path); got != tt.want {
+			t.Errorf("RouteGroup(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestExtensionHealth(t *testing.T) {
+	health := &extensionHealth{
+		desc:    prometheus.NewDesc("kubelens_extension_up", "Whether an extension process is running.", []string{"extension"}, nil),
+		running: func() map[string]bool { return map[string]bool{"cost": true, `a"b`: false} },
+	}
+	want := `
+# HELP kubelens_extension_up Whether an extension process is running.
+# TYPE kubelens_extension_up gauge
+kubelens_extension_up{extension="a\"b"} 0
+kubelens_extension_up{extension="cost"} 1
+`
+	if err := testutil.CollectAndCompare(health, strings.NewReader(want)); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestHandlerToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Middleware())
+	router.GET("/metrics", Handler("secret"))
+
+	request := func(auth string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := request(""); w.Code != http.StatusUnauthorized {
+		t.Errorf("without token = %d, want 401", w.Code)
+	}
+	if w := request("Bearer wrong"); w.Code != http.StatusUnauthorized {
+		t.Errorf("with wrong token = %d, want 401", w.Code)
+	}
+	w := request("Bearer secret")
+	if w.Code != http.StatusOK {
+		t.Fatalf("with token = %d, want 200", w.Code)
+	}
+	if body := w.Body.String(); !strings.Contains(body, `kubelens_http_requests_total{code="401",group="metrics",method="GET"} 2`) {
+		t.Errorf("request counter missing from output:\n%s", body)
+	}
+}
@@ -7,14 +7,24 @@ import (
 
 	"github.com/gin-gonic/gin"
 	log "github.com/sirupsen/logrus"
+
+	"github.com/sonnguyen/kubelens/internal/cache"
 )
 
+// sharedFailureLogInterval is how often a failing shared store is reported
+const sharedFailureLogInterval = time.Minute
+
 // RateLimiter implements a token bucket rate limiter
 type RateLimiter struct {
 	visitors map[string]*visitor
 	mu       sync.RWMutex
 	rate     time.Duration
 	burst    int
+
+	// store holds the buckets shared with other replicas, if any
+	store      cache.Store
+	name       string
+	lastFailed time.Time
 }
 
 type visitor struct {
@@ -46,12 +56,20 @@ func NewRateLimiter(rate time.Duration, burst int) *RateLimiter {
 	return rl
 }
 
+// Share keeps the buckets in a store shared by the replicas, under name, so that they
+// enforce a single limit. The limiter falls back to its own buckets while the store fails.
+func (rl *RateLimiter) Share(store cache.Store, name string) *RateLimiter {
+	rl.store = store
+	rl.name = name
+	return rl
+}
+
 // Middleware returns a Gin middleware function
 func (rl *RateLimiter) Middleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		ip := c.ClientIP()
 		
-		if !rl.allow(ip) {
+		if !rl.allowShared(c, ip) {
 			log.Warnf("Rate limit exceeded for IP: %s, Path: %s", ip, c.Request.URL.Path)
 			c.JSON(http.StatusTooManyRequests, gin.H{
 				"error": "too many requests, please try again later",
@@ -64,6 +82,24 @@ func (rl *RateLimiter) Middleware() gin.HandlerFunc {
 	}
 }
 
+// allowShared checks a request against the shared buckets, or the local ones without a
+// shared store or when it fails
+func (rl *RateLimiter) allowShared(c *gin.Context, ip string) bool {
+	if rl.store != nil {
+		allowed, err := rl.store.Allow(c.Request.Context(), "ratelimit:"+rl.name+":"+ip, rl.rate, rl.burst)
+		if err == nil {
+			return allowed
+		}
+		rl.mu.Lock()
+		if time.Since(rl.lastFailed) > sharedFailureLogInterval {
+			log.Warnf("Shared rate limiter unavailable, limiting per replica: %v", err)
+			rl.lastFailed = time.Now()
+		}
+		rl.mu.Unlock()
+	}
+	return rl.allow(ip)
+}
+
 // allow checks if a request from the given IP should be allowed
 func (rl *RateLimiter) allow(ip string) bool {
 	rl.mu.Lock()
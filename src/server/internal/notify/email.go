@@ -0,0 +1,125 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/mail"
+	"sort"
+	"strings"
+
+	"github.com/sonnguyen/kubelens/internal/db"
+	kmail "github.com/sonnguyen/kubelens/internal/mail"
+)
+
+// maxEmailRecipients bounds the recipients of an email channel
+const maxEmailRecipients = 50
+
+// emailConfig is the configuration of an email channel
+type emailConfig struct {
+	To            []string `json:"to"`
+	SubjectPrefix string   `json:"subject_prefix,omitempty"` // default: [kubelens]
+}
+
+// emailProvider sends events as plain text email through the SMTP settings
+type emailProvider struct {
+	db *db.DB
+}
+
+func parseEmailConfig(raw json.RawMessage) (*emailConfig, error) {
+	var config emailConfig
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&config); err != nil {
+		return nil, fmt.Errorf("invalid email configuration: %v", err)
+	}
+	return &config, nil
+}
+
+// Validate checks the recipients and the subject prefix
+func (emailProvider) Validate(raw json.RawMessage) (json.RawMessage, error) {
+	config, err := parseEmailConfig(raw)
+	if err != nil {
+		return nil, err
+	}
+	if len(config.To) == 0 || len(config.To) > maxEmailRecipients {
+		return nil, fmt.Errorf("to must list 1 to %d recipients", maxEmailRecipients)
+	}
+	for i, to := range config.To {
+		addr, err := mail.ParseAddress(to)
+		if err != nil {
+			return nil, fmt.Errorf("invalid recipient %q", to)
+		}
+		config.To[i] = addr.Address
+	}
+	if strings.ContainsAny(config.SubjectPrefix, "\r\n") || len(config.SubjectPrefix) > 64 {
+		return nil, fmt.Errorf("subject_prefix must be a single line of at most 64 characters")
+	}
+	return json.Marshal(config)
+}
+
+// Redact returns the configuration as is; it holds no secrets
+func (emailProvider) Redact(raw json.RawMessage) map[string]interface{} {
+	config, err := parseEmailConfig(raw)
+	if err != nil {
+		return map[string]interface{}{}
+	}
+	return map[string]interface{}{
+		"to":             config.To,
+		"subject_prefix": config.SubjectPrefix,
+	}
+}
+
+// Send mails the event to the recipients
+func (p emailProvider) Send(ctx context.Context, raw json.RawMessage, event *Event) error {
+	config, err := parseEmailConfig(raw)
+	if err != nil {
+		return err
+	}
+	prefix := config.SubjectPrefix
+	if prefix == "" {
+		prefix = "[kubelens]"
+	}
+	return kmail.SendMessage(p.db, &kmail.Message{
+		To:      config.To,
+		Subject: fmt.Sprintf("%s [%s] %s", prefix, strings.ToUpper(event.Severity), singleLine(event.Title)),
+		Body:    emailBody(event),
+	})
+}
+
+// emailBody renders an event as plain text
+func emailBody(event *Event) string {
+	var b strings.Builder
+	b.WriteString(event.Title)
+	b.WriteString("\n\n")
+	if event.Message != "" {
+		b.WriteString(event.Message)
+		b.WriteString("\n\n")
+	}
+	field := func(name, value string) {
+		if value != "" {
+			fmt.Fprintf(&b, "%-10s %s\n", name+":", value)
+		}
+	}
+	field("Event", event.Type)
+	field("Severity", event.Severity)
+	field("Cluster", event.Cluster)
+	field("Namespace", event.Namespace)
+	field("Resource", event.Resource)
+	field("Time", event.Time.Format("2006-01-02 15:04:05 MST"))
+
+	keys := make([]string, 0, len(event.Labels))
+	for key := range event.Labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		field(key, event.Labels[key])
+	}
+	return b.String()
+}
+
+func singleLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,250 @@
+package notify
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/sonnguyen/kubelens/internal/audit"
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+// Handler handles notification channel API requests
+type Handler struct {
+	db         *db.DB
+	dispatcher *Dispatcher
+}
+
+// NewHandler creates a new notification channel handler
+func NewHandler(database *db.DB, dispatcher *Dispatcher) *Handler {
+	return &Handler{db: database, dispatcher: dispatcher}
+}
+
+// channelRequest is the body of create and update requests. A nil config keeps the stored
+// one on update, as it is never returned in full.
+type channelRequest struct {
+	Name        string          `json:"name" binding:"required"`
+	Type        string          `json:"type"`
+	Enabled     *bool           `json:"enabled"`
+	Config      json.RawMessage `json:"config"`
+	Events      []string        `json:"events"` // event type patterns, e.g. alert.*
+	MinSeverity string          `json:"min_severity"`
+}
+
+// channelResponse is a channel with its configuration redacted
+type channelResponse struct {
+	*db.NotificationChannel
+	Config map[string]interface{} `json:"config"`
+}
+
+// apply validates a request and copies it onto a channel, encrypting the configuration
+func (h *Handler) apply(req *channelRequest, channel *db.NotificationChannel) error {
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len(name) > 255 {
+		return fmt.Errorf("name is required (at most 255 characters)")
+	}
+	if channel.ID == 0 {
+		if req.Type == "" {
+			return fmt.Errorf("type is required")
+		}
+		channel.Type = req.Type
+	} else if req.Type != "" && req.Type != channel.Type {
+		return fmt.Errorf("the type of a channel cannot be changed")
+	}
+	provider, ok := h.dispatcher.ProviderFor(channel.Type)
+	if !ok {
+		return fmt.Errorf("unsupported channel type %q", channel.Type)
+	}
+	if req.MinSeverity != "" {
+		if _, ok := severityRank[req.MinSeverity]; !ok {
+			return fmt.Errorf("min_severity must be info, warning or critical")
+		}
+	}
+	for _, pattern := range req.Events {
+		if pattern == "" || strings.ContainsAny(pattern, " /") {
+			return fmt.Errorf("invalid event pattern %q", pattern)
+		}
+	}
+
+	if len(req.Config) > 0 && string(req.Config) != "null" {
+		config, err := provider.Validate(req.Config)
+		if err != nil {
+			return err
+		}
+		if channel.Config, err = EncryptConfig(h.db, config); err != nil {
+			return fmt.Errorf("failed to store the configuration: %w", err)
+		}
+	} else if channel.ID == 0 {
+		return fmt.Errorf("config is required")
+	}
+
+	channel.Name = name
+	channel.MinSeverity = req.MinSeverity
+	channel.Events = nil
+	if len(req.Events) > 0 {
+		events, _ := json.Marshal(req.Events)
+		channel.Events = db.JSON(events)
+	}
+	if req.Enabled != nil {
+		channel.Enabled = *req.Enabled
+	}
+	return nil
+}
+
+// response redacts the configuration of a channel
+func (h *Handler) response(channel *db.NotificationChannel) channelResponse {
+	resp := channelResponse{NotificationChannel: channel, Config: map[string]interface{}{}}
+	if provider, ok := h.dispatcher.ProviderFor(channel.Type); ok {
+		if config, err := DecryptConfig(h.db, channel.Config); err == nil {
+			resp.Config = provider.Redact(config)
+		}
+	}
+	return resp
+}
+
+func (h *Handler) auditChange(c *gin.Context, desc string, channel *db.NotificationChannel) {
+	if userID, exists := c.Get("user_id"); exists {
+		username, _ := c.Get("username")
+		email, _ := c.Get("email")
+		audit.Log(c, audit.EventAuditConfigChanged, userID.(int), username.(string), email.(string),
+			desc,
+			map[string]interface{}{
+				"channel": channel.Name,
+				"type":    channel.Type,
+				"enabled": channel.Enabled,
+			})
+	}
+}
+
+// ListChannels handles GET /api/v1/notification-channels
+func (h *Handler) ListChannels(c *gin.Context) {
+	channels, err := h.db.ListNotificationChannels()
+	if err != nil {
+		log.Errorf("Failed to list notification channels: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list notification channels"})
+		return
+	}
+	responses := make([]channelResponse, 0, len(channels))
+	for _, channel := range channels {
+		responses = append(responses, h.response(channel))
+	}
+	c.JSON(http.StatusOK, responses)
+}
+
+// CreateChannel handles POST /api/v1/notification-channels
+func (h *Handler) CreateChannel(c *gin.Context) {
+	var req channelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	channel := &db.NotificationChannel{Enabled: true}
+	if err := h.apply(&req, channel); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.db.CreateNotificationChannel(channel); err != nil {
+		if strings.Contains(err.Error(), "UNIQUE") {
+			c.JSON(http.StatusConflict, gin.H{"error": "a channel with this name already exists"})
+			return
+		}
+		log.Errorf("Failed to create notification channel: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create notification channel"})
+		return
+	}
+
+	h.auditChange(c, fmt.Sprintf("Created %s notification channel %s", channel.Type, channel.Name), channel)
+	c.JSON(http.StatusCreated, h.response(channel))
+}
+
+// UpdateChannel handles PUT /api/v1/notification-channels/:id
+func (h *Handler) UpdateChannel(c *gin.Context) {
+	channel, ok := h.channel(c)
+	if !ok {
+		return
+	}
+
+	var req channelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.apply(&req, channel); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.db.UpdateNotificationChannel(channel); err != nil {
+		log.Errorf("Failed to update notification channel: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update notification channel"})
+		return
+	}
+
+	h.auditChange(c, fmt.Sprintf("Updated notification channel %s", channel.Name), channel)
+	c.JSON(http.StatusOK, h.response(channel))
+}
+
+// DeleteChannel handles DELETE /api/v1/notification-channels/:id
+func (h *Handler) DeleteChannel(c *gin.Context) {
+	channel, ok := h.channel(c)
+	if !ok {
+		return
+	}
+	if err := h.db.DeleteNotificationChannel(channel.ID); err != nil {
+		log.Errorf("Failed to delete notification channel: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete notification channel"})
+		return
+	}
+
+	h.auditChange(c, fmt.Sprintf("Deleted notification channel %s", channel.Name), channel)
+	c.JSON(http.StatusOK, gin.H{"message": "notification channel deleted"})
+}
+
+// TestChannel handles POST /api/v1/notification-channels/:id/test by delivering a test
+// event once, without retries
+func (h *Handler) TestChannel(c *gin.Context) {
+	channel, ok := h.channel(c)
+	if !ok {
+		return
+	}
+
+	username, _ := c.Get("username")
+	event := &Event{
+		Type:     "test",
+		Severity: SeverityInfo,
+		Title:    "Test notification from kubelens",
+		Message:  fmt.Sprintf("Sent by %v to check the %s channel.", username, channel.Name),
+	}
+	err := h.dispatcher.Send(c.Request.Context(), channel, event)
+	deliveryErr := ""
+	if err != nil {
+		deliveryErr = err.Error()
+	}
+	h.db.RecordNotificationDelivery(channel.ID, deliveryErr)
+
+	if err != nil {
+		c.JSON(http.StatusOK, gin.H{"ok": false, "error": deliveryErr})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"ok": true})
+}
+
+// channel loads the channel named in the route, writing the error response when it cannot
+func (h *Handler) channel(c *gin.Context) (*db.NotificationChannel, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid channel ID"})
+		return nil, false
+	}
+	channel, err := h.db.GetNotificationChannel(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	return channel, true
+}
@@ -0,0 +1,221 @@
+// Package notify delivers kubelens events (alerts, cluster events) to outbound
+// notification channels such as webhooks and email.
+package notify
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/sonnguyen/kubelens/internal/crypto"
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+// Severities, in increasing order
+const (
+	SeverityInfo     = "info"
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+)
+
+var severityRank = map[string]int{SeverityInfo: 0, SeverityWarning: 1, SeverityCritical: 2}
+
+const (
+	// deliveryAttempts is how many times a delivery is tried before it is given up
+	deliveryAttempts = 3
+	// deliveryTimeout bounds one delivery attempt
+	deliveryTimeout = 10 * time.Second
+)
+
+// retryDelay is the wait before the given retry (1-based); a variable so tests can shorten it
+var retryDelay = func(retry int) time.Duration {
+	return time.Duration(retry*retry) * 2 * time.Second
+}
+
+// Event is something kubelens notifies about
+type Event struct {
+	ID        string            `json:"id"`
+	Type      string            `json:"type"` // e.g. alert.firing, alert.resolved, test
+	Severity  string            `json:"severity"`
+	Title     string            `json:"title"`
+	Message   string            `json:"message"`
+	Cluster   string            `json:"cluster,omitempty"`
+	Namespace string            `json:"namespace,omitempty"`
+	Resource  string            `json:"resource,omitempty"` // <kind>/<name>
+	Labels    map[string]string `json:"labels,omitempty"`
+	Time      time.Time         `json:"time"`
+}
+
+// Provider delivers events to one type of channel
+type Provider interface {
+	// Validate checks a channel configuration and returns it normalized
+	Validate(config json.RawMessage) (json.RawMessage, error)
+	// Redact returns the configuration without its secrets, for display
+	Redact(config json.RawMessage) map[string]interface{}
+	// Send delivers an event
+	Send(ctx context.Context, config json.RawMessage, event *Event) error
+}
+
+// Dispatcher delivers events to the enabled channels that subscribe to them
+type Dispatcher struct {
+	db        *db.DB
+	providers map[string]Provider // by channel type
+	wg        sync.WaitGroup
+}
+
+// NewDispatcher creates a new dispatcher
+func NewDispatcher(database *db.DB) *Dispatcher {
+	return &Dispatcher{
+		db: database,
+		providers: map[string]Provider{
+			"webhook": webhookProvider{},
+			"email":   emailProvider{db: database},
+		},
+	}
+}
+
+// ProviderFor returns the provider of a channel type
+func (d *Dispatcher) ProviderFor(channelType string) (Provider, bool) {
+	p, ok := d.providers[channelType]
+	return p, ok
+}
+
+// Dispatch delivers an event in the background to every matching channel, retrying
+// failed deliveries
+func (d *Dispatcher) Dispatch(event *Event) {
+	prepare(event)
+
+	channels, err := d.db.ListEnabledNotificationChannels()
+	if err != nil {
+		log.Errorf("Failed to list notification channels: %v", err)
+		return
+	}
+	for _, channel := range channels {
+		if !Matches(channel, event) {
+			continue
+		}
+		d.wg.Add(1)
+		go func(channel *db.NotificationChannel) {
+			defer d.wg.Done()
+			d.deliver(channel, event)
+		}(channel)
+	}
+}
+
+// Wait blocks until the deliveries in progress are done
+func (d *Dispatcher) Wait() {
+	d.wg.Wait()
+}
+
+// deliver sends an event to a channel with retries and records the outcome
+func (d *Dispatcher) deliver(channel *db.NotificationChannel, event *Event) {
+	var err error
+	for attempt := 1; attempt <= deliveryAttempts; attempt++ {
+		if attempt > 1 {
+			time.Sleep(retryDelay(attempt - 1))
+		}
+		if err = d.Send(context.Background(), channel, event); err == nil {
+			break
+		}
+		log.Warnf("Delivery of %s to notification channel %s failed (attempt %d/%d): %v", event.Type, channel.Name, attempt, deliveryAttempts, err)
+	}
+
+	deliveryErr := ""
+	if err != nil {
+		deliveryErr = err.Error()
+	}
+	if recordErr := d.db.RecordNotificationDelivery(channel.ID, deliveryErr); recordErr != nil {
+		log.Warnf("Failed to record delivery to notification channel %s: %v", channel.Name, recordErr)
+	}
+}
+
+// Send delivers an event to a channel once
+func (d *Dispatcher) Send(ctx context.Context, channel *db.NotificationChannel, event *Event) error {
+	provider, ok := d.ProviderFor(channel.Type)
+	if !ok {
+		return fmt.Errorf("unsupported channel type %q", channel.Type)
+	}
+	config, err := DecryptConfig(d.db, channel.Config)
+	if err != nil {
+		return err
+	}
+	prepare(event)
+
+	ctx, cancel := context.WithTimeout(ctx, deliveryTimeout)
+	defer cancel()
+	return provider.Send(ctx, config, event)
+}
+
+// Matches reports whether a channel subscribes to an event: its type matches one of the
+// channel's patterns (all events when there are none) and it is severe enough
+func Matches(channel *db.NotificationChannel, event *Event) bool {
+	if channel.MinSeverity != "" && severityRank[event.Severity] < severityRank[channel.MinSeverity] {
+		return false
+	}
+
+	var patterns []string
+	if len(channel.Events) > 0 {
+		json.Unmarshal(channel.Events, &patterns)
+	}
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, event.Type); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// prepare fills in the ID, time and severity of an event
+func prepare(event *Event) {
+	if event.ID == "" {
+		b := make([]byte, 8)
+		rand.Read(b)
+		event.ID = hex.EncodeToString(b)
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now().UTC()
+	}
+	if event.Severity == "" {
+		event.Severity = SeverityInfo
+	}
+}
+
+// EncryptConfig encrypts a channel configuration for storage
+func EncryptConfig(database *db.DB, config json.RawMessage) (string, error) {
+	encryptor, err := encryptor(database)
+	if err != nil {
+		return "", err
+	}
+	return encryptor.Encrypt(config)
+}
+
+// DecryptConfig decrypts a stored channel configuration
+func DecryptConfig(database *db.DB, encrypted string) (json.RawMessage, error) {
+	encryptor, err := encryptor(database)
+	if err != nil {
+		return nil, err
+	}
+	config, err := encryptor.Decrypt(encrypted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt channel configuration: %w", err)
+	}
+	return config, nil
+}
+
+func encryptor(database *db.DB) (*crypto.Encryptor, error) {
+	key, err := database.GetOrCreateEncryptionKey()
+	if err != nil {
+		return nil, err
+	}
+	return crypto.NewEncryptor(key)
+}
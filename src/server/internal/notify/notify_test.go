@@ -0,0 +1,113 @@
+package notify
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+func newTestDB(t *testing.T) *db.DB {
+	t.Helper()
+	database, err := db.New(filepath.Join(t.TempDir(), "kubelens.db"))
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+	return database
+}
+
+func addChannel(t *testing.T, database *db.DB, name string, config map[string]interface{}, events ...string) *db.NotificationChannel {
+	t.Helper()
+	raw, _ := json.Marshal(config)
+	validated, err := webhookProvider{}.Validate(raw)
+	if err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	encrypted, err := EncryptConfig(database, validated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	channel := &db.NotificationChannel{Name: name, Type: "webhook", Enabled: true, Config: encrypted}
+	if len(events) > 0 {
+		patterns, _ := json.Marshal(events)
+		channel.Events = db.JSON(patterns)
+	}
+	if err := database.CreateNotificationChannel(channel); err != nil {
+		t.Fatal(err)
+	}
+	return channel
+}
+
+func TestWebhookDelivery(t *testing.T) {
+	retryDelay = func(int) time.Duration { return 0 }
+
+	var requests int32
+	var body []byte
+	var signature, timestamp string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The first attempt fails and is retried
+		if atomic.AddInt32(&requests, 1) == 1 {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		body, _ = io.ReadAll(r.Body)
+		signature, timestamp = r.Header.Get(SignatureHeader), r.Header.Get(TimestampHeader)
+	}))
+	defer server.Close()
+
+	database := newTestDB(t)
+	addChannel(t, database, "teams", map[string]interface{}{
+		"url":            server.URL + "/hook/token",
+		"signing_secret": "s3cret",
+		"template":       `{"text": {{json (printf "[%s] %s" (upper .Severity) .Title)}}}`,
+	}, "alert.*")
+	addChannel(t, database, "events-only", map[string]interface{}{"url": server.URL}, "event.*")
+
+	dispatcher := NewDispatcher(database)
+	dispatcher.Dispatch(&Event{Type: "alert.firing", Severity: SeverityCritical, Title: `Pod "web" crash looping`})
+	dispatcher.Wait()
+
+	if requests != 2 {
+		t.Fatalf("requests = %d, want a failed attempt and a retry to the matching channel only", requests)
+	}
+	if want := `{"text": "[CRITICAL] Pod \"web\" crash looping"}`; string(body) != want {
+		t.Errorf("payload = %s, want %s", body, want)
+	}
+	if signature != Sign("s3cret", timestamp, body) {
+		t.Errorf("signature %s does not match the payload", signature)
+	}
+
+	channels, _ := database.ListNotificationChannels()
+	for _, channel := range channels {
+		if channel.Name == "teams" && (channel.LastDeliveryAt == nil || channel.LastError != "") {
+			t.Errorf("teams delivery = %v %q, want recorded without error", channel.LastDeliveryAt, channel.LastError)
+		}
+	}
+}
+
+func TestWebhookValidate(t *testing.T) {
+	for _, config := range []string{
+		`{"url": "ftp://example.com"}`,
+		`{"url": "https://example.com", "method": "GET"}`,
+		`{"url": "https://example.com", "headers": {"X-Kubelens-Signature": "x"}}`,
+		`{"url": "https://example.com", "template": "{\"text\": {{.Title}}}"}`,
+		`{"url": "https://example.com", "template": "{{.Missing}}"}`,
+		`{"url": "https://example.com", "unknown": true}`,
+	} {
+		if _, err := (webhookProvider{}).Validate(json.RawMessage(config)); err == nil {
+			t.Errorf("Validate(%s) accepted an invalid configuration", config)
+		}
+	}
+
+	redacted := webhookProvider{}.Redact(json.RawMessage(`{"url": "https://discord.com/api/webhooks/123/token", "signing_secret": "x"}`))
+	if redacted["url"] != "https://discord.com/..." || redacted["signed"] != true {
+		t.Errorf("Redact() = %v, want the URL path hidden", redacted)
+	}
+}
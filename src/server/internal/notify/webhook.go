@@ -0,0 +1,211 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+const (
+	// SignatureHeader carries the HMAC-SHA256 signature of a webhook delivery:
+	// sha256=<hex of HMAC(secret, "<timestamp>.<body>")>
+	SignatureHeader = "X-Kubelens-Signature"
+	// TimestampHeader carries the unix time of a webhook delivery, part of the signature so
+	// that receivers can reject replays
+	TimestampHeader = "X-Kubelens-Timestamp"
+	// EventHeader carries the event type of a webhook delivery
+	EventHeader = "X-Kubelens-Event"
+	// DeliveryHeader carries the event ID of a webhook delivery
+	DeliveryHeader = "X-Kubelens-Delivery"
+
+	// maxTemplateSize bounds a payload template
+	maxTemplateSize = 16 << 10
+)
+
+// webhookConfig is the configuration of a webhook channel
+type webhookConfig struct {
+	URL     string            `json:"url"`
+	Method  string            `json:"method,omitempty"` // POST (default) or PUT
+	Headers map[string]string `json:"headers,omitempty"`
+	// SigningSecret, if set, signs every delivery (see SignatureHeader)
+	SigningSecret string `json:"signing_secret,omitempty"`
+	// Template is a Go text/template rendering the JSON payload from the Event; the json
+	// function quotes a value, e.g. {"text": {{json .Title}}}. Default: the Event as JSON.
+	Template string `json:"template,omitempty"`
+}
+
+// webhookProvider posts events to an HTTP endpoint
+type webhookProvider struct{}
+
+func parseWebhookConfig(raw json.RawMessage) (*webhookConfig, error) {
+	var config webhookConfig
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&config); err != nil {
+		return nil, fmt.Errorf("invalid webhook configuration: %v", err)
+	}
+	return &config, nil
+}
+
+// Validate checks the URL, method, headers and template of a webhook configuration
+func (webhookProvider) Validate(raw json.RawMessage) (json.RawMessage, error) {
+	config, err := parseWebhookConfig(raw)
+	if err != nil {
+		return nil, err
+	}
+	u, err := url.Parse(config.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("url must be an http(s) URL")
+	}
+	switch config.Method {
+	case "":
+		config.Method = http.MethodPost
+	case http.MethodPost, http.MethodPut:
+	default:
+		return nil, fmt.Errorf("method must be POST or PUT")
+	}
+	for name := range config.Headers {
+		if name == "" || strings.ContainsAny(name, " :\r\n") {
+			return nil, fmt.Errorf("invalid header name %q", name)
+		}
+		if strings.HasPrefix(http.CanonicalHeaderKey(name), "X-Kubelens-") {
+			return nil, fmt.Errorf("header %s is set by kubelens", name)
+		}
+	}
+	if len(config.Template) > maxTemplateSize {
+		return nil, fmt.Errorf("template exceeds %d KB", maxTemplateSize>>10)
+	}
+	if config.Template != "" {
+		// Render a sample event so that templates producing invalid JSON are rejected now
+		sample := &Event{ID: "0", Type: "test", Severity: SeverityInfo, Title: "Test", Message: "\"quoted\"", Time: time.Now()}
+		if _, err := renderPayload(config.Template, sample); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(config)
+}
+
+// Redact hides the URL path and query (they often hold a token), header values and the
+// signing secret
+func (webhookProvider) Redact(raw json.RawMessage) map[string]interface{} {
+	config, err := parseWebhookConfig(raw)
+	if err != nil {
+		return map[string]interface{}{}
+	}
+	redacted := map[string]interface{}{
+		"method":       config.Method,
+		"signed":       config.SigningSecret != "",
+		"template":     config.Template,
+		"header_names": headerNames(config.Headers),
+	}
+	if u, err := url.Parse(config.URL); err == nil {
+		redacted["url"] = u.Scheme + "://" + u.Host + "/..."
+	}
+	return redacted
+}
+
+// Send posts the rendered payload, signed if a secret is configured. Responses other
+// than 2xx are errors.
+func (webhookProvider) Send(ctx context.Context, raw json.RawMessage, event *Event) error {
+	config, err := parseWebhookConfig(raw)
+	if err != nil {
+		return err
+	}
+	var payload []byte
+	if config.Template != "" {
+		payload, err = renderPayload(config.Template, event)
+	} else {
+		payload, err = json.Marshal(event)
+	}
+	if err != nil {
+		return err
+	}
+
+	method := config.Method
+	if method == "" {
+		method = http.MethodPost
+	}
+	req, err := http.NewRequestWithContext(ctx, method, config.URL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "kubelens-webhook")
+	for name, value := range config.Headers {
+		req.Header.Set(name, value)
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(EventHeader, event.Type)
+	req.Header.Set(DeliveryHeader, event.ID)
+	if config.SigningSecret != "" {
+		req.Header.Set(SignatureHeader, Sign(config.SigningSecret, timestamp, payload))
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("webhook returned HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// Sign returns the signature header value of a webhook payload
+func Sign(secret, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// templateFuncs are the functions available to payload templates
+var templateFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+// renderPayload renders a payload template and checks that the result is JSON
+func renderPayload(text string, event *Event) ([]byte, error) {
+	tmpl, err := template.New("payload").Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, event); err != nil {
+		return nil, fmt.Errorf("template failed: %v", err)
+	}
+	if !json.Valid(buf.Bytes()) {
+		return nil, fmt.Errorf("template does not render valid JSON (use {{json .Field}} to quote values)")
+	}
+	return buf.Bytes(), nil
+}
+
+func headerNames(headers map[string]string) []string {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,219 @@
+// Package objectstore reads and writes the objects of S3-compatible buckets (S3, GCS through
+// its XML API with HMAC keys, MinIO), signing the requests with AWS Signature Version 4
+package objectstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+)
+
+// KeyPattern is what bucket names and object keys may hold. Keys made of these characters
+// need no escaping, so the path sent is the one signed.
+var KeyPattern = regexp.MustCompile(`^[A-Za-z0-9._/-]*$`)
+
+// S3Config locates a bucket and the credentials to access it
+type S3Config struct {
+	Endpoint        string // path-style when set, virtual-hosted AWS otherwise
+	Bucket          string
+	Region          string // default us-east-1, or auto with an endpoint
+	AccessKeyID     string // default AWS_ACCESS_KEY_ID (and AWS_SESSION_TOKEN)
+	SecretAccessKey string // default AWS_SECRET_ACCESS_KEY
+}
+
+// S3 is a client of one bucket
+type S3 struct {
+	endpoint        *url.URL
+	bucket          string
+	region          string
+	accessKeyID     string
+	secretAccessKey string
+	sessionToken    string
+	client          *http.Client
+}
+
+// Object is an object listed in a bucket
+type Object struct {
+	Key          string
+	Size         int64
+	LastModified time.Time
+}
+
+// Validate checks the bucket and endpoint of a configuration, leaving out the credentials
+func (cfg S3Config) Validate() error {
+	if cfg.Bucket == "" || !KeyPattern.MatchString(cfg.Bucket) || strings.Contains(cfg.Bucket, "/") {
+		return fmt.Errorf("bucket is required and must be a bucket name")
+	}
+	if cfg.Endpoint != "" {
+		u, err := url.Parse(cfg.Endpoint)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("endpoint must be an http(s) URL")
+		}
+	}
+	return nil
+}
+
+// NewS3 creates a client of the bucket of cfg
+func NewS3(cfg S3Config) (*S3, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	s := &S3{
+		bucket:          cfg.Bucket,
+		region:          cfg.Region,
+		accessKeyID:     cfg.AccessKeyID,
+		secretAccessKey: cfg.SecretAccessKey,
+		client:          &http.Client{Timeout: 5 * time.Minute},
+	}
+	if s.accessKeyID == "" {
+		s.accessKeyID = os.Getenv("AWS_ACCESS_KEY_ID")
+		s.secretAccessKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+		s.sessionToken = os.Getenv("AWS_SESSION_TOKEN")
+	}
+	if s.accessKeyID == "" || s.secretAccessKey == "" {
+		return nil, fmt.Errorf("access_key_id and secret_access_key are required (or AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY)")
+	}
+	if cfg.Endpoint != "" {
+		s.endpoint, _ = url.Parse(cfg.Endpoint)
+	}
+	if s.region == "" {
+		s.region = "us-east-1"
+		if s.endpoint != nil {
+			s.region = "auto"
+		}
+	}
+	return s, nil
+}
+
+// objectURL returns the URL of an object key, or of the bucket for an empty key
+func (s *S3) objectURL(key string) *url.URL {
+	if s.endpoint != nil {
+		u := *s.endpoint
+		u.Path = strings.TrimSuffix(u.Path, "/") + "/" + s.bucket + "/" + key
+		return &u
+	}
+	return &url.URL{Scheme: "https", Host: s.bucket + ".s3." + s.region + ".amazonaws.com", Path: "/" + key}
+}
+
+// Put writes an object
+func (s *S3) Put(ctx context.Context, key, contentType string, body []byte) error {
+	if !KeyPattern.MatchString(key) {
+		return fmt.Errorf("invalid object key %q", key)
+	}
+	header := http.Header{}
+	header.Set("Content-Type", contentType)
+	resp, err := s.do(ctx, http.MethodPut, key, "", header, body)
+	if err != nil {
+		return fmt.Errorf("upload of %s failed: %w", key, err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// Delete removes an object
+func (s *S3) Delete(ctx context.Context, key string) error {
+	if !KeyPattern.MatchString(key) {
+		return fmt.Errorf("invalid object key %q", key)
+	}
+	resp, err := s.do(ctx, http.MethodDelete, key, "", nil, nil)
+	if err != nil {
+		return fmt.Errorf("deletion of %s failed: %w", key, err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// List returns the objects whose key starts with prefix
+func (s *S3) List(ctx context.Context, prefix string) ([]Object, error) {
+	var objects []Object
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		// SigV4 wants spaces as %20; Encode sorts the parameters as it requires
+		resp, err := s.do(ctx, http.MethodGet, "", strings.ReplaceAll(query.Encode(), "+", "%20"), nil, nil)
+		if err != nil {
+			return nil, fmt.Errorf("listing of %s failed: %w", prefix, err)
+		}
+		var result struct {
+			Contents []struct {
+				Key          string
+				Size         int64
+				LastModified time.Time
+			}
+			IsTruncated           bool
+			NextContinuationToken string
+		}
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("invalid listing of %s: %w", prefix, err)
+		}
+		for _, content := range result.Contents {
+			objects = append(objects, Object{Key: content.Key, Size: content.Size, LastModified: content.LastModified})
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return objects, nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+// do sends a signed request. Responses other than 2xx are returned as errors.
+func (s *S3) do(ctx context.Context, method, key, rawQuery string, header http.Header, body []byte) (*http.Response, error) {
+	u := s.objectURL(key)
+	u.RawQuery = rawQuery
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	if err := s.sign(req, body, time.Now()); err != nil {
+		return nil, err
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		resp.Body.Close()
+		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(detail)))
+	}
+	return resp, nil
+}
+
+// sign adds the SigV4 Authorization header to a request, covering its host, content
+// and amz headers
+func (s *S3) sign(req *http.Request, body []byte, now time.Time) error {
+	payloadHash := sha256.Sum256(body)
+	req.Header.Set("X-Amz-Content-Sha256", hex.EncodeToString(payloadHash[:]))
+	creds := aws.Credentials{AccessKeyID: s.accessKeyID, SecretAccessKey: s.secretAccessKey, SessionToken: s.sessionToken}
+	// S3 signs the path as sent, without escaping it a second time
+	signer := v4.NewSigner(func(o *v4.SignerOptions) { o.DisableURIPathEscaping = true })
+	return signer.SignHTTP(req.Context(), creds, req, hex.EncodeToString(payloadHash[:]), "s3", s.region, now)
+}
+
+// Close releases the idle connections of the client
+func (s *S3) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
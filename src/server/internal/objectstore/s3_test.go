@@ -0,0 +1,102 @@
+package objectstore
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeBucket serves the objects of one bucket, listing them two per page
+func fakeBucket(t *testing.T, bucket string) *httptest.Server {
+	var mu sync.Mutex
+	objects := map[string][]byte{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/") {
+			http.Error(w, "unsigned request", http.StatusForbidden)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		key := strings.TrimPrefix(r.URL.Path, "/"+bucket+"/")
+		switch r.Method {
+		case http.MethodPut:
+			objects[key], _ = io.ReadAll(r.Body)
+		case http.MethodDelete:
+			delete(objects, key)
+		case http.MethodGet:
+			var keys []string
+			for k := range objects {
+				if strings.HasPrefix(k, r.URL.Query().Get("prefix")) && k > r.URL.Query().Get("continuation-token") {
+					keys = append(keys, k)
+				}
+			}
+			sort.Strings(keys)
+			fmt.Fprint(w, "<ListBucketResult>")
+			for i, k := range keys {
+				if i == 2 {
+					fmt.Fprintf(w, "<IsTruncated>true</IsTruncated><NextContinuationToken>%s</NextContinuationToken>", keys[1])
+					break
+				}
+				fmt.Fprintf(w, "<Contents><Key>%s</Key><Size>%d</Size><LastModified>2026-01-02T03:04:05.000Z</LastModified></Contents>", k, len(objects[k]))
+			}
+			fmt.Fprint(w, "</ListBucketResult>")
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestS3(t *testing.T) {
+	server := fakeBucket(t, "logs")
+	s, err := NewS3(S3Config{Endpoint: server.URL, Bucket: "logs", AccessKeyID: "AKID", SecretAccessKey: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	for _, key := range []string{"a/1", "a/2", "a/3", "b/1"} {
+		if err := s.Put(ctx, key, "text/plain", []byte(key)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Delete(ctx, "a/2"); err != nil {
+		t.Fatal(err)
+	}
+
+	objects, err := s.List(ctx, "a/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var keys []string
+	for _, object := range objects {
+		keys = append(keys, object.Key)
+	}
+	if strings.Join(keys, ",") != "a/1,a/3" {
+		t.Errorf("List() = %v, want a/1 and a/3", keys)
+	}
+	if want := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC); !objects[0].LastModified.Equal(want) || objects[0].Size != 3 {
+		t.Errorf("object = %+v", objects[0])
+	}
+
+	if err := s.Put(ctx, "a b", "text/plain", nil); err == nil {
+		t.Error("Put() of a key with a space succeeded, want an error")
+	}
+}
+
+func TestS3Validation(t *testing.T) {
+	for _, cfg := range []S3Config{
+		{Bucket: "", AccessKeyID: "AKID", SecretAccessKey: "secret"},
+		{Bucket: "a/b", AccessKeyID: "AKID", SecretAccessKey: "secret"},
+		{Bucket: "logs", Endpoint: "ftp://example.com", AccessKeyID: "AKID", SecretAccessKey: "secret"},
+	} {
+		if _, err := NewS3(cfg); err == nil {
+			t.Errorf("NewS3(%+v) succeeded, want an error", cfg)
+		}
+	}
+}
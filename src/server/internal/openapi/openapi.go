@@ -0,0 +1,274 @@
+// Package openapi builds an OpenAPI 3 document from the routes registered on the Gin
+// router. Handlers carry no annotations, so the document describes what the routing table
+// knows: paths, methods, path parameters, an operationId taken from the handler name
+// (ListPods, DescribeResource, ...) or from the method and path, and a tag from the
+// resource segment of the path.
+// Request and response bodies are described as free-form JSON.
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Version is the OpenAPI version of the generated document
+const Version = "3.0.3"
+
+// Document is an OpenAPI 3 document, limited to the parts the generator fills in
+type Document struct {
+	OpenAPI    string                           `json:"openapi"`
+	Info       Info                             `json:"info"`
+	Paths      map[string]map[string]*Operation `json:"paths"`
+	Components Components                       `json:"components"`
+	Security   []map[string][]string            `json:"security"`
+}
+
+// Info is the document metadata
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// Operation is one method on one path
+type Operation struct {
+	OperationID string                 `json:"operationId"`
+	Summary     string                 `json:"summary,omitempty"`
+	Tags        []string               `json:"tags,omitempty"`
+	Parameters  []Parameter            `json:"parameters,omitempty"`
+	RequestBody *RequestBody           `json:"requestBody,omitempty"`
+	Responses   map[string]Response    `json:"responses"`
+	Security    *[]map[string][]string `json:"security,omitempty"`
+}
+
+// Parameter is a path parameter
+type Parameter struct {
+	Name     string `json:"name"`
+	In       string `json:"in"`
+	Required bool   `json:"required"`
+	Schema   Schema `json:"schema"`
+}
+
+// RequestBody is the JSON body of a write operation
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response is one response of an operation
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType holds the schema of a body
+type MediaType struct {
+	Schema Schema `json:"schema"`
+}
+
+// Schema is a JSON schema, either inline or a reference to a component
+type Schema struct {
+	Ref        string            `json:"$ref,omitempty"`
+	Type       string            `json:"type,omitempty"`
+	Properties map[string]Schema `json:"properties,omitempty"`
+}
+
+// Components holds the shared schemas and security schemes
+type Components struct {
+	Schemas         map[string]Schema         `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes"`
+}
+
+// SecurityScheme describes how requests authenticate
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+}
+
+// Options configures Build
+type Options struct {
+	Title   string
+	Version string
+	// Public lists the paths, in OpenAPI form (/api/v1/setup/{id}), that need no token
+	Public []string
+}
+
+var (
+	pathParam     = regexp.MustCompile(`[:*](\w+)`)
+	handlerMethod = regexp.MustCompile(`\.(\w+)(-fm)?$`)
+)
+
+// Build generates the document for the given routes. Routes outside /api are skipped.
+// An operation is named after its handler when no other route has that name; otherwise,
+// as for closures, after its method and path (GET /api/v1/ws is GetWs). Operation IDs
+// are thus unique and do not depend on the order routes are registered in.
+func Build(routes gin.RoutesInfo, opts Options) *Document {
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    Info{Title: opts.Title, Version: opts.Version},
+		Paths:   make(map[string]map[string]*Operation),
+		Components: Components{
+			Schemas: map[string]Schema{
+				"Error": {Type: "object", Properties: map[string]Schema{"error": {Type: "string"}}},
+			},
+			SecuritySchemes: map[string]SecurityScheme{
+				"bearerAuth": {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+			},
+		},
+		Security: []map[string][]string{{"bearerAuth": {}}},
+	}
+
+	public := make(map[string]bool, len(opts.Public))
+	for _, p := range opts.Public {
+		public[p] = true
+	}
+
+	handlerNames := make(map[string]int)
+	for _, route := range routes {
+		if strings.HasPrefix(route.Path, "/api/") {
+			handlerNames[operationName(route.Handler)]++
+		}
+	}
+
+	for _, route := range routes {
+		if !strings.HasPrefix(route.Path, "/api/") {
+			continue
+		}
+		path := pathParam.ReplaceAllString(route.Path, "{$1}")
+
+		id := operationName(route.Handler)
+		if id == "" || handlerNames[id] > 1 {
+			id = nameFromPath(route.Method, path)
+		}
+
+		op := &Operation{
+			OperationID: id,
+			Summary:     summary(id),
+			Tags:        []string{tag(path)},
+			Responses: map[string]Response{
+				"200": {Description: "Success", Content: jsonContent(Schema{})},
+				"default": {
+					Description: "Error",
+					Content:     jsonContent(Schema{Ref: "#/components/schemas/Error"}),
+				},
+			},
+		}
+		for _, match := range pathParam.FindAllStringSubmatch(route.Path, -1) {
+			op.Parameters = append(op.Parameters, Parameter{Name: match[1], In: "path", Required: true, Schema: Schema{Type: "string"}})
+		}
+		switch route.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch:
+			op.RequestBody = &RequestBody{Content: jsonContent(Schema{})}
+		}
+		if public[path] {
+			op.Security = &[]map[string][]string{}
+		}
+
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = make(map[string]*Operation)
+		}
+		doc.Paths[path][strings.ToLower(route.Method)] = op
+	}
+
+	return doc
+}
+
+// Handler serves the document for a router. It is built on the first request, once every
+// route has been registered.
+func Handler(router *gin.Engine, opts Options) gin.HandlerFunc {
+	var (
+		once sync.Once
+		doc  *Document
+	)
+	return func(c *gin.Context) {
+		once.Do(func() {
+			doc = Build(router.Routes(), opts)
+		})
+		c.JSON(http.StatusOK, doc)
+	}
+}
+
+// WriteFile writes a document as indented JSON
+func WriteFile(path string, doc *Document) error {
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+func jsonContent(schema Schema) map[string]MediaType {
+	return map[string]MediaType{"application/json": {Schema: schema}}
+}
+
+// operationName returns the method name of a handler such as
+// github.com/sonnguyen/kubelens/internal/api.(*Handler).ListPods-fm, or "" for closures
+func operationName(handler string) string {
+	match := handlerMethod.FindStringSubmatch(handler)
+	if match == nil || strings.HasPrefix(match[1], "func") || !unicode.IsUpper(rune(match[1][0])) {
+		return ""
+	}
+	return match[1]
+}
+
+// nameFromPath names an operation without a named handler, e.g. GET /api/v1/ws is GetWs
+func nameFromPath(method, path string) string {
+	var b strings.Builder
+	b.WriteString(upperFirst(strings.ToLower(method)))
+	for _, segment := range strings.Split(strings.TrimPrefix(path, "/api/v1"), "/") {
+		segment = strings.Trim(segment, "{}")
+		for _, word := range strings.FieldsFunc(segment, func(r rune) bool { return r == '-' || r == '_' || r == '.' }) {
+			b.WriteString(upperFirst(word))
+		}
+	}
+	return b.String()
+}
+
+// tag groups operations by resource: the segment after the cluster and namespace
+func tag(path string) string {
+	segments := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	if len(segments) > 2 && segments[0] == "api" {
+		segments = segments[2:]
+	}
+	if len(segments) > 2 && segments[0] == "clusters" {
+		segments = segments[2:]
+		if len(segments) > 2 && segments[0] == "namespaces" {
+			segments = segments[2:]
+		}
+	}
+	if strings.HasPrefix(segments[0], "{") {
+		return "resources"
+	}
+	return segments[0]
+}
+
+// summary splits an operation ID into words: ListPods is "List pods"
+func summary(id string) string {
+	var words []string
+	start := 0
+	for i := 1; i < len(id); i++ {
+		if unicode.IsUpper(rune(id[i])) && !unicode.IsUpper(rune(id[i-1])) {
+			words = append(words, id[start:i])
+			start = i
+		}
+	}
+	words = append(words, id[start:])
+	for i := 1; i < len(words); i++ {
+		words[i] = strings.ToLower(words[i])
+	}
+	return strings.Join(words, " ")
+}
+
+func upperFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
@@ -0,0 +1,84 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type fakeHandler struct{}
+
+func (fakeHandler) ListPods(c *gin.Context)   {}
+func (fakeHandler) GetPod(c *gin.Context)     {}
+func (fakeHandler) ApplyPatch(c *gin.Context) {}
+
+func TestBuild(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := fakeHandler{}
+	router := gin.New()
+	router.GET("/health", func(c *gin.Context) {})
+	router.GET("/api/v1/clusters/:name/pods", h.ListPods)
+	router.GET("/api/v1/clusters/:name/namespaces/:namespace/pods", h.ListPods)
+	router.GET("/api/v1/clusters/:name/namespaces/:namespace/pods/:pod", h.GetPod)
+	router.PATCH("/api/v1/clusters/:name/resources/:resource/:resname", h.ApplyPatch)
+	router.GET("/api/v1/ws", func(c *gin.Context) {})
+	router.POST("/api/v1/setup", func(c *gin.Context) {})
+
+	doc := Build(router.Routes(), Options{Title: "test", Version: "1", Public: []string{"/api/v1/setup"}})
+
+	if _, ok := doc.Paths["/health"]; ok {
+		t.Error("routes outside /api must be skipped")
+	}
+
+	tests := []struct {
+		path, method, id, tag string
+		params                int
+	}{
+		{"/api/v1/clusters/{name}/pods", "get", "GetClustersNamePods", "pods", 1},
+		{"/api/v1/clusters/{name}/namespaces/{namespace}/pods", "get", "GetClustersNameNamespacesNamespacePods", "pods", 2},
+		{"/api/v1/clusters/{name}/namespaces/{namespace}/pods/{pod}", "get", "GetPod", "pods", 3},
+		{"/api/v1/clusters/{name}/resources/{resource}/{resname}", "patch", "ApplyPatch", "resources", 3},
+		{"/api/v1/ws", "get", "GetWs", "ws", 0},
+		{"/api/v1/setup", "post", "PostSetup", "setup", 0},
+	}
+	for _, tt := range tests {
+		op := doc.Paths[tt.path][tt.method]
+		if op == nil {
+			t.Errorf("%s %s missing from document", tt.method, tt.path)
+			continue
+		}
+		if op.OperationID != tt.id || op.Tags[0] != tt.tag || len(op.Parameters) != tt.params {
+			t.Errorf("%s %s = %s %v %d params, want %s [%s] %d params", tt.method, tt.path, op.OperationID, op.Tags, len(op.Parameters), tt.id, tt.tag, tt.params)
+		}
+	}
+
+	// Operation IDs do not depend on the order routes are registered in
+	routes := router.Routes()
+	reversed := make(gin.RoutesInfo, 0, len(routes))
+	for i := len(routes) - 1; i >= 0; i-- {
+		reversed = append(reversed, routes[i])
+	}
+	for path, methods := range Build(reversed, Options{}).Paths {
+		for method, op := range methods {
+			if want := doc.Paths[path][method].OperationID; op.OperationID != want {
+				t.Errorf("%s %s registered in reverse = %s, want %s", method, path, op.OperationID, want)
+			}
+		}
+	}
+
+	if doc.Paths["/api/v1/setup"]["post"].Security == nil {
+		t.Error("public operation should override the document security")
+	}
+	if doc.Paths["/api/v1/clusters/{name}/resources/{resource}/{resname}"]["patch"].RequestBody == nil {
+		t.Error("PATCH should have a request body")
+	}
+}
+
+func TestSummary(t *testing.T) {
+	if got := summary("ListPersistentVolumeClaims"); got != "List persistent volume claims" {
+		t.Errorf("summary() = %q", got)
+	}
+	if got := summary("GetCSR"); got != "Get csr" {
+		t.Errorf("summary() = %q", got)
+	}
+}
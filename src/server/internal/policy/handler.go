@@ -0,0 +1,220 @@
+package policy
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/sonnguyen/kubelens/internal/audit"
+)
+
+// Handler handles endpoint policy API requests
+type Handler struct {
+	policy *EndpointPolicy
+}
+
+// NewHandler creates a new endpoint policy handler
+func NewHandler(policy *EndpointPolicy) *Handler {
+	return &Handler{policy: policy}
+}
+
+// GetEndpointPolicy handles GET /api/v1/system/endpoint-policy
+func (h *Handler) GetEndpointPolicy(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"classes": h.policy.Status()})
+}
+
+// UpdateEndpointPolicy handles PUT /api/v1/system/endpoint-policy
+// Body: {"disabled": ["node_shell", "secret_reveal"]}
+func (h *Handler) UpdateEndpointPolicy(c *gin.Context) {
+	var req struct {
+		Disabled []string `json:"disabled"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.policy.SetDisabled(req.Disabled); err != nil {
+		if strings.HasPrefix(err.Error(), "unknown endpoint class") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		log.Errorf("Failed to update endpoint policy: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update endpoint policy"})
+		return
+	}
+
+	// Audit log
+	if userID, exists := c.Get("user_id"); exists {
+		username, _ := c.Get("username")
+		email, _ := c.Get("email")
+		audit.Log(c, audit.EventAuditConfigChanged, userID.(int), username.(string), email.(string),
+			"Updated endpoint policy",
+			map[string]interface{}{
+				"disabled": req.Disabled,
+			})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"classes": h.policy.Status()})
+}
+
+// MaintenanceHandler handles maintenance mode API requests
+type MaintenanceHandler struct {
+	mode *MaintenanceMode
+}
+
+// NewMaintenanceHandler creates a new maintenance mode handler
+func NewMaintenanceHandler(mode *MaintenanceMode) *MaintenanceHandler {
+	return &MaintenanceHandler{mode: mode}
+}
+
+// GetMaintenanceMode handles GET /api/v1/system/maintenance
+func (h *MaintenanceHandler) GetMaintenanceMode(c *gin.Context) {
+	c.JSON(http.StatusOK, h.mode.State())
+}
+
+// UpdateMaintenanceMode handles PUT /api/v1/system/maintenance
+// Body: {"enabled": true, "message": "Change freeze until Monday"}
+func (h *MaintenanceHandler) UpdateMaintenanceMode(c *gin.Context) {
+	var req struct {
+		Enabled *bool  `json:"enabled" binding:"required"`
+		Message string `json:"message"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.mode.Set(*req.Enabled, strings.TrimSpace(req.Message), c.GetString("email")); err != nil {
+		if h.mode.State().Locked {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		log.Errorf("Failed to update maintenance mode: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update maintenance mode"})
+		return
+	}
+
+	log.Warnf("Maintenance mode set to %v by %s", *req.Enabled, c.GetString("email"))
+
+	// Audit log
+	if userID, exists := c.Get("user_id"); exists {
+		username, _ := c.Get("username")
+		email, _ := c.Get("email")
+		description := "Disabled maintenance mode"
+		if *req.Enabled {
+			description = "Enabled maintenance mode"
+		}
+		audit.Log(c, audit.EventAuditConfigChanged, userID.(int), username.(string), email.(string),
+			description,
+			map[string]interface{}{
+				"enabled": *req.Enabled,
+				"message": req.Message,
+			})
+	}
+
+	c.JSON(http.StatusOK, h.mode.State())
+}
+
+// ShellPolicyHandler handles shell policy API requests
+type ShellPolicyHandler struct {
+	policy *ShellPolicy
+}
+
+// NewShellPolicyHandler creates a new shell policy handler
+func NewShellPolicyHandler(policy *ShellPolicy) *ShellPolicyHandler {
+	return &ShellPolicyHandler{policy: policy}
+}
+
+// GetShellPolicy handles GET /api/v1/system/shell-policy
+func (h *ShellPolicyHandler) GetShellPolicy(c *gin.Context) {
+	c.JSON(http.StatusOK, h.policy.Rules())
+}
+
+// UpdateShellPolicy handles PUT /api/v1/system/shell-policy
+// Body: {"disable_node_shell": true, "namespaces": ["team-*"], "require_mfa": true}
+func (h *ShellPolicyHandler) UpdateShellPolicy(c *gin.Context) {
+	var rules ShellRules
+	if err := c.ShouldBindJSON(&rules); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.policy.SetRules(rules); err != nil {
+		switch {
+		case strings.HasPrefix(err.Error(), "invalid"):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case strings.HasSuffix(err.Error(), "by the deployment configuration"):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			log.Errorf("Failed to update shell policy: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update shell policy"})
+		}
+		return
+	}
+
+	// Audit log
+	if userID, exists := c.Get("user_id"); exists {
+		username, _ := c.Get("username")
+		email, _ := c.Get("email")
+		audit.Log(c, audit.EventAuditConfigChanged, userID.(int), username.(string), email.(string),
+			"Updated shell policy",
+			map[string]interface{}{
+				"disable_node_shell": rules.DisableNodeShell,
+				"namespaces":         rules.Namespaces,
+				"pod_selector":       rules.PodSelector,
+				"shell_user":         rules.ShellUser,
+				"require_mfa":        rules.RequireMFA,
+			})
+	}
+
+	c.JSON(http.StatusOK, h.policy.Rules())
+}
+
+// StepUp handles POST /api/v1/auth/mfa/step-up: the user enters an MFA code again and gets
+// a token to open shells with (as ?step_up_token=) while the policy requires MFA
+// Body: {"token": "123456"}
+func (h *ShellPolicyHandler) StepUp(c *gin.Context) {
+	var req struct {
+		Token string `json:"token" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	enabled, err := h.policy.mfa.GetMFAStatus(uint(userID.(int)))
+	if err != nil {
+		log.Errorf("Failed to get MFA status: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to verify token"})
+		return
+	}
+	if !enabled {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "MFA is not enabled for this account"})
+		return
+	}
+	valid, err := h.policy.mfa.VerifyMFAToken(uint(userID.(int)), req.Token)
+	if err != nil {
+		log.Errorf("Failed to verify MFA token: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to verify token"})
+		return
+	}
+	username, _ := c.Get("username")
+	email, _ := c.Get("email")
+	if !valid {
+		audit.Log(c, audit.EventMFAFailed, userID.(int), username.(string), email.(string), "MFA step-up failed", nil)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
+		return
+	}
+
+	token, expires := h.policy.IssueStepUp(userID.(int))
+	audit.Log(c, audit.EventMFAVerified, userID.(int), username.(string), email.(string), "MFA step-up to open shells", nil)
+	c.JSON(http.StatusOK, gin.H{"step_up_token": token, "expires_at": expires.UTC()})
+}
@@ -0,0 +1,189 @@
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/sonnguyen/kubelens/internal/audit"
+)
+
+// maintenanceConfigKey is where maintenance mode set via the admin API is persisted
+const maintenanceConfigKey = "maintenance_mode"
+
+// defaultMaintenanceMessage is shown when maintenance mode is enabled without a message
+const defaultMaintenanceMessage = "Kubelens is in maintenance mode; changes to clusters are disabled"
+
+// readOnlyClusterRoutes are non-GET cluster routes that do not change cluster state
+// (relative to /clusters/:name), or only change kubelens' own cluster settings
+var readOnlyClusterRoutes = map[string]bool{
+	"/diff":                   true,
+	"/edit-sessions":          true,
+	"/enabled":                true,
+	"/impersonation":          true,
+	"/datasources/:type":      true,
+	"/datasources/:type/test": true,
+}
+
+// MaintenanceState is the API representation of maintenance mode
+type MaintenanceState struct {
+	Enabled   bool       `json:"enabled"`
+	Locked    bool       `json:"locked"` // enabled at deployment time, cannot be turned off via API
+	Message   string     `json:"message"`
+	EnabledBy string     `json:"enabled_by,omitempty"`
+	EnabledAt *time.Time `json:"enabled_at,omitempty"`
+}
+
+// MaintenanceMode is a global read-only switch. While it is on, requests that change
+// cluster state are rejected with 423 Locked; reads, logs and kubelens' own settings keep
+// working.
+type MaintenanceMode struct {
+	mu     sync.RWMutex
+	store  Store
+	locked bool
+	state  MaintenanceState
+}
+
+// NewMaintenanceMode creates the switch from the deployment configuration plus the state
+// previously set via the admin API
+func NewMaintenanceMode(store Store, deploymentEnabled bool, deploymentMessage string) *MaintenanceMode {
+	m := &MaintenanceMode{store: store, locked: deploymentEnabled}
+
+	if value, err := store.GetSystemConfig(maintenanceConfigKey); err == nil && value != "" {
+		if err := json.Unmarshal([]byte(value), &m.state); err != nil {
+			log.Warnf("Ignoring invalid stored maintenance mode: %v", err)
+			m.state = MaintenanceState{}
+		}
+	}
+
+	if deploymentEnabled {
+		if deploymentMessage != "" {
+			m.state.Message = deploymentMessage
+		}
+		log.Warn("Maintenance mode enabled by deployment config: changes to clusters are rejected")
+	} else if m.state.Enabled {
+		log.Warnf("Maintenance mode is enabled (by %s): changes to clusters are rejected", m.state.EnabledBy)
+	}
+	return m
+}
+
+// Enabled reports whether maintenance mode is on
+func (m *MaintenanceMode) Enabled() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.locked || m.state.Enabled
+}
+
+// State returns the current maintenance mode state
+func (m *MaintenanceMode) State() MaintenanceState {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	state := m.state
+	state.Enabled = m.locked || m.state.Enabled
+	state.Locked = m.locked
+	if state.Enabled && state.Message == "" {
+		state.Message = defaultMaintenanceMessage
+	}
+	return state
+}
+
+// Set turns maintenance mode on or off via the admin API. It cannot be turned off while
+// it is enabled by the deployment configuration.
+func (m *MaintenanceMode) Set(enabled bool, message, by string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.locked && !enabled {
+		return fmt.Errorf("maintenance mode is enabled by the deployment configuration")
+	}
+
+	state := MaintenanceState{Enabled: enabled, Message: message}
+	if enabled {
+		now := time.Now()
+		state.EnabledBy = by
+		state.EnabledAt = &now
+		if m.state.Enabled {
+			// Keep who enabled it when only the message changes
+			state.EnabledBy = m.state.EnabledBy
+			state.EnabledAt = m.state.EnabledAt
+		}
+	}
+
+	value, _ := json.Marshal(state)
+	if err := m.store.SetSystemConfig(maintenanceConfigKey, string(value)); err != nil {
+		return err
+	}
+	m.state = state
+	return nil
+}
+
+// jobRoutes are the routes outside /clusters/:name that start changes to clusters: drain
+// jobs and upgrade plans. Pausing and cancelling them stays possible.
+var jobRoutes = map[string]bool{
+	"POST /drains":                  true,
+	"POST /drains/:id/resume":       true,
+	"POST /upgrade/plans":           true,
+	"POST /upgrade/plans/:id/start": true,
+}
+
+// IsMutatingRequest reports whether a request changes cluster state: a non-GET request on
+// a cluster resource route, the removal of a cluster, an interactive shell or node drain,
+// or the start of a drain job or upgrade plan. fullPath is the matched route pattern.
+func IsMutatingRequest(method, fullPath string) bool {
+	for route := range jobRoutes {
+		if jobMethod, path, _ := strings.Cut(route, " "); method == jobMethod && strings.HasSuffix(fullPath, path) {
+			return true
+		}
+	}
+
+	if strings.HasSuffix(fullPath, "/clusters/:name") {
+		return method == http.MethodDelete
+	}
+	idx := strings.Index(fullPath, "/clusters/:name/")
+	if idx < 0 {
+		return false
+	}
+	rest := fullPath[idx+len("/clusters/:name"):]
+
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return strings.HasSuffix(rest, "/shell") || strings.HasSuffix(rest, "/drain")
+	}
+	return !readOnlyClusterRoutes[rest]
+}
+
+// Middleware rejects requests that change cluster state with 423 while maintenance mode
+// is on
+func (m *MaintenanceMode) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !m.Enabled() || !IsMutatingRequest(c.Request.Method, c.FullPath()) {
+			c.Next()
+			return
+		}
+
+		state := m.State()
+		if userID, exists := c.Get("user_id"); exists {
+			username, _ := c.Get("username")
+			email, _ := c.Get("email")
+			audit.Log(c, audit.EventSecPermissionDenied, userID.(int), username.(string), email.(string),
+				"Request blocked by maintenance mode",
+				map[string]interface{}{
+					"method": c.Request.Method,
+					"path":   c.Request.URL.Path,
+				})
+		}
+
+		c.JSON(http.StatusLocked, gin.H{
+			"error":       state.Message,
+			"maintenance": true,
+		})
+		c.Abort()
+	}
+}
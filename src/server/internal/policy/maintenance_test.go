@@ -0,0 +1,94 @@
+package policy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestIsMutatingRequest(t *testing.T) {
+	tests := []struct {
+		method, path string
+		want         bool
+	}{
+		{http.MethodGet, "/api/v1/clusters/:name/namespaces/:namespace/pods", false},
+		{http.MethodGet, "/api/v1/clusters/:name/namespaces/:namespace/pods/:pod/logs", false},
+		{http.MethodGet, "/api/v1/clusters/:name/namespaces/:namespace/pods/:pod/shell", true},
+		{http.MethodGet, "/api/v1/clusters/:name/nodes/:node/shell", true},
+		{http.MethodDelete, "/api/v1/clusters/:name/namespaces/:namespace/pods/:pod", true},
+		{http.MethodPatch, "/api/v1/clusters/:name/namespaces/:namespace/deployments/:deployment/scale", true},
+		{http.MethodPost, "/api/v1/clusters/:name/manifests", true},
+		{http.MethodPost, "/api/v1/clusters/:name/diff", false},
+		{http.MethodPost, "/api/v1/clusters/:name/edit-sessions", false},
+		{http.MethodPatch, "/api/v1/clusters/:name/enabled", false},
+		{http.MethodPut, "/api/v1/clusters/:name", false},
+		{http.MethodDelete, "/api/v1/clusters/:name", true},
+		{http.MethodGet, "/api/v1/clusters/:name/nodes/:node/drain", true},
+		{http.MethodPost, "/api/v1/drains", true},
+		{http.MethodPost, "/api/v1/drains/:id/resume", true},
+		{http.MethodPost, "/api/v1/drains/:id/cancel", false},
+		{http.MethodGet, "/api/v1/drains", false},
+		{http.MethodPost, "/api/v1/upgrade/prechecks", false},
+		{http.MethodPost, "/api/v1/upgrade/plans", true},
+		{http.MethodPost, "/api/v1/upgrade/plans/:id/start", true},
+		{http.MethodPost, "/api/v1/upgrade/plans/:id/pause", false},
+		{http.MethodPut, "/api/v1/system/maintenance", false},
+	}
+	for _, tt := range tests {
+		if got := IsMutatingRequest(tt.method, tt.path); got != tt.want {
+			t.Errorf("IsMutatingRequest(%s, %s) = %v, want %v", tt.method, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestMaintenanceMode(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := memoryStore{}
+
+	m := NewMaintenanceMode(store, false, "")
+	router := gin.New()
+	router.Use(m.Middleware())
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	router.GET("/api/v1/clusters/:name/namespaces/:namespace/pods", ok)
+	router.DELETE("/api/v1/clusters/:name/namespaces/:namespace/pods/:pod", ok)
+
+	request := func(method, path string) int {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		return w.Code
+	}
+	deletePod := func() int {
+		return request(http.MethodDelete, "/api/v1/clusters/prod/namespaces/default/pods/web-0")
+	}
+
+	if code := deletePod(); code != http.StatusOK {
+		t.Fatalf("delete with maintenance mode off = %d, want 200", code)
+	}
+
+	if err := m.Set(true, "Change freeze", "admin@kubelens.local"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if code := deletePod(); code != http.StatusLocked {
+		t.Errorf("delete in maintenance mode = %d, want 423", code)
+	}
+	if code := request(http.MethodGet, "/api/v1/clusters/prod/namespaces/default/pods"); code != http.StatusOK {
+		t.Errorf("list in maintenance mode = %d, want 200", code)
+	}
+
+	// Persisted state is restored on restart
+	restored := NewMaintenanceMode(store, false, "")
+	if state := restored.State(); !state.Enabled || state.Message != "Change freeze" || state.EnabledBy != "admin@kubelens.local" {
+		t.Errorf("restored state = %+v", state)
+	}
+
+	// Maintenance mode enabled by deployment config cannot be turned off
+	locked := NewMaintenanceMode(memoryStore{}, true, "")
+	if err := locked.Set(false, "", "admin@kubelens.local"); err == nil {
+		t.Error("Set(false) succeeded while locked by deployment config")
+	}
+	if state := locked.State(); !state.Enabled || !state.Locked || state.Message != defaultMaintenanceMessage {
+		t.Errorf("locked state = %+v", state)
+	}
+}
@@ -0,0 +1,207 @@
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/sonnguyen/kubelens/internal/audit"
+)
+
+// Endpoint classes that can be disabled fleet-wide regardless of user permissions
+const (
+	NodeShell       = "node_shell"
+	PodShell        = "pod_shell"
+	SecretReveal    = "secret_reveal"
+	CRDDelete       = "crd_delete"
+	NamespaceDelete = "namespace_delete"
+)
+
+// Classes describes every endpoint class that can be disabled
+var Classes = map[string]string{
+	NodeShell:       "Interactive shell on cluster nodes",
+	PodShell:        "Interactive shell (exec) into pod containers",
+	SecretReveal:    "Reading Secret values",
+	CRDDelete:       "Deleting CustomResourceDefinitions",
+	NamespaceDelete: "Deleting namespaces",
+}
+
+// systemConfigKey is where classes disabled via the admin API are persisted
+const systemConfigKey = "disabled_endpoints"
+
+// Store persists the admin-managed part of the policy
+type Store interface {
+	GetSystemConfig(key string) (string, error)
+	SetSystemConfig(key, value string) error
+}
+
+// ClassStatus is the API representation of one endpoint class
+type ClassStatus struct {
+	Class       string `json:"class"`
+	Description string `json:"description"`
+	Disabled    bool   `json:"disabled"`
+	Locked      bool   `json:"locked"` // disabled at deployment time, cannot be re-enabled via API
+}
+
+// EndpointPolicy tracks which endpoint classes are disabled
+type EndpointPolicy struct {
+	mu       sync.RWMutex
+	store    Store
+	locked   map[string]bool
+	disabled map[string]bool
+}
+
+// NewEndpointPolicy creates a policy from the classes disabled in the deployment
+// configuration plus those previously disabled via the admin API
+func NewEndpointPolicy(store Store, deploymentDisabled []string) (*EndpointPolicy, error) {
+	p := &EndpointPolicy{
+		store:    store,
+		locked:   make(map[string]bool),
+		disabled: make(map[string]bool),
+	}
+
+	for _, class := range deploymentDisabled {
+		class = strings.TrimSpace(class)
+		if class == "" {
+			continue
+		}
+		if _, ok := Classes[class]; !ok {
+			return nil, fmt.Errorf("unknown endpoint class in disabled_endpoints: %s", class)
+		}
+		p.locked[class] = true
+	}
+
+	if value, err := store.GetSystemConfig(systemConfigKey); err == nil && value != "" {
+		var classes []string
+		if err := json.Unmarshal([]byte(value), &classes); err != nil {
+			log.Warnf("Ignoring invalid stored endpoint policy: %v", err)
+		}
+		for _, class := range classes {
+			if _, ok := Classes[class]; ok {
+				p.disabled[class] = true
+			}
+		}
+	}
+
+	if len(p.locked) > 0 {
+		log.Infof("Endpoint classes disabled by deployment config: %s", strings.Join(sortedKeys(p.locked), ", "))
+	}
+	return p, nil
+}
+
+// IsDisabled reports whether an endpoint class is disabled
+func (p *EndpointPolicy) IsDisabled(class string) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.locked[class] || p.disabled[class]
+}
+
+// Require returns middleware that rejects requests with 403 when the class is disabled
+func (p *EndpointPolicy) Require(class string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !p.IsDisabled(class) {
+			c.Next()
+			return
+		}
+
+		p.mu.RLock()
+		source := "administrator"
+		if p.locked[class] {
+			source = "deployment configuration"
+		}
+		p.mu.RUnlock()
+
+		if userID, exists := c.Get("user_id"); exists {
+			username, _ := c.Get("username")
+			email, _ := c.Get("email")
+			audit.Log(c, audit.EventSecPermissionDenied, userID.(int), username.(string), email.(string),
+				fmt.Sprintf("Request blocked by endpoint policy: %s", class),
+				map[string]interface{}{
+					"policy": class,
+					"path":   c.Request.URL.Path,
+				})
+		}
+
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":  fmt.Sprintf("%s is disabled by policy", Classes[class]),
+			"policy": class,
+			"reason": fmt.Sprintf("disabled by %s", source),
+		})
+		c.Abort()
+	}
+}
+
+// Status returns the state of every endpoint class, sorted by class name
+func (p *EndpointPolicy) Status() []ClassStatus {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	result := make([]ClassStatus, 0, len(Classes))
+	for _, class := range sortedKeys(Classes) {
+		result = append(result, ClassStatus{
+			Class:       class,
+			Description: Classes[class],
+			Disabled:    p.locked[class] || p.disabled[class],
+			Locked:      p.locked[class],
+		})
+	}
+	return result
+}
+
+// SetDisabled replaces the set of classes disabled via the admin API.
+// Classes locked by the deployment configuration stay disabled.
+func (p *EndpointPolicy) SetDisabled(classes []string) error {
+	disabled := make(map[string]bool)
+	for _, class := range classes {
+		if _, ok := Classes[class]; !ok {
+			return fmt.Errorf("unknown endpoint class: %s", class)
+		}
+		disabled[class] = true
+	}
+
+	value, _ := json.Marshal(sortedKeys(disabled))
+	if err := p.store.SetSystemConfig(systemConfigKey, string(value)); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	p.disabled = disabled
+	p.mu.Unlock()
+	return nil
+}
+
+// SetClassDisabled disables or re-enables one class via the admin API. A class locked by
+// the deployment configuration cannot be re-enabled.
+func (p *EndpointPolicy) SetClassDisabled(class string, disabled bool) error {
+	p.mu.RLock()
+	if p.locked[class] && !disabled {
+		p.mu.RUnlock()
+		return fmt.Errorf("%s is disabled by the deployment configuration", class)
+	}
+	classes := make([]string, 0, len(p.disabled)+1)
+	for c := range p.disabled {
+		if c != class {
+			classes = append(classes, c)
+		}
+	}
+	p.mu.RUnlock()
+	if disabled {
+		classes = append(classes, class)
+	}
+	return p.SetDisabled(classes)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,77 @@
+package policy
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type memoryStore map[string]string
+
+func (m memoryStore) GetSystemConfig(key string) (string, error) {
+	if v, ok := m[key]; ok {
+		return v, nil
+	}
+	return "", errors.New("not found")
+}
+
+func (m memoryStore) SetSystemConfig(key, value string) error {
+	m[key] = value
+	return nil
+}
+
+func TestEndpointPolicy(t *testing.T) {
+	store := memoryStore{}
+
+	if _, err := NewEndpointPolicy(store, []string{"bogus"}); err == nil {
+		t.Fatal("expected error for unknown deployment class")
+	}
+
+	p, err := NewEndpointPolicy(store, []string{NodeShell})
+	if err != nil {
+		t.Fatalf("NewEndpointPolicy() error = %v", err)
+	}
+	if !p.IsDisabled(NodeShell) || p.IsDisabled(SecretReveal) {
+		t.Fatal("unexpected initial policy state")
+	}
+
+	// Admin API cannot re-enable a class locked by deployment config
+	if err := p.SetDisabled([]string{SecretReveal}); err != nil {
+		t.Fatalf("SetDisabled() error = %v", err)
+	}
+	if !p.IsDisabled(NodeShell) || !p.IsDisabled(SecretReveal) {
+		t.Fatal("expected node_shell and secret_reveal to be disabled")
+	}
+	if err := p.SetDisabled([]string{"bogus"}); err == nil {
+		t.Fatal("expected error for unknown class")
+	}
+
+	// Persisted admin state is restored on restart
+	restored, err := NewEndpointPolicy(store, nil)
+	if err != nil {
+		t.Fatalf("NewEndpointPolicy() error = %v", err)
+	}
+	if !restored.IsDisabled(SecretReveal) || restored.IsDisabled(NodeShell) {
+		t.Fatal("expected only secret_reveal to be restored")
+	}
+}
+
+func TestRequire(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	p, _ := NewEndpointPolicy(memoryStore{}, []string{CRDDelete})
+	router := gin.New()
+	router.DELETE("/crd", p.Require(CRDDelete), func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.DELETE("/ns", p.Require(NamespaceDelete), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	for path, want := range map[string]int{"/crd": http.StatusForbidden, "/ns": http.StatusOK} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, path, nil))
+		if w.Code != want {
+			t.Errorf("DELETE %s = %d, want %d", path, w.Code, want)
+		}
+	}
+}
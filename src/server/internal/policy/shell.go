@@ -0,0 +1,259 @@
+package policy
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/sonnguyen/kubelens/internal/audit"
+)
+
+// shellPolicyKey is where the shell rules are persisted
+const shellPolicyKey = "shell_policy"
+
+// StepUpTTL is how long an MFA re-prompt lets a user open shells
+const StepUpTTL = 5 * time.Minute
+
+// ShellRules restrict pod and node shells for everyone, on top of permissions
+type ShellRules struct {
+	// DisableNodeShell mirrors the node_shell endpoint class
+	DisableNodeShell bool `json:"disable_node_shell"`
+	// Namespaces (globs such as team-*) pod shells may be opened in; empty allows all
+	Namespaces []string `json:"namespaces"`
+	// PodSelector is a label selector the pods must match, e.g. kubelens.io/shell=allowed
+	PodSelector string `json:"pod_selector,omitempty"`
+	// ShellUser runs shells as this (unprivileged) user through su instead of the user of
+	// the container, which then needs su and to run as root
+	ShellUser string `json:"shell_user,omitempty"`
+	// RequireMFA makes users enter an MFA code again before opening a shell
+	RequireMFA bool `json:"require_mfa"`
+}
+
+// MFAVerifier checks the MFA codes of users
+type MFAVerifier interface {
+	GetMFAStatus(userID uint) (bool, error)
+	VerifyMFAToken(userID uint, token string) (bool, error)
+}
+
+// ShellDenied is the error of a shell refused by the shell rules
+type ShellDenied struct {
+	Rule   string // node_shell, namespace, pod_selector or mfa
+	Reason string
+}
+
+func (e *ShellDenied) Error() string {
+	return e.Reason
+}
+
+// ShellPolicy enforces the shell rules
+type ShellPolicy struct {
+	mu        sync.RWMutex
+	store     Store
+	endpoints *EndpointPolicy
+	mfa       MFAVerifier
+	secret    []byte
+	rules     ShellRules
+	selector  labels.Selector
+}
+
+// NewShellPolicy creates the shell policy with the rules saved via the admin API. Step-up
+// tokens are signed with secret.
+func NewShellPolicy(store Store, endpoints *EndpointPolicy, mfa MFAVerifier, secret string) *ShellPolicy {
+	p := &ShellPolicy{store: store, endpoints: endpoints, mfa: mfa, secret: []byte(secret), selector: labels.Everything()}
+	if value, err := store.GetSystemConfig(shellPolicyKey); err == nil && value != "" {
+		var rules ShellRules
+		if err := json.Unmarshal([]byte(value), &rules); err != nil {
+			log.Warnf("Ignoring invalid stored shell policy: %v", err)
+		} else if selector, err := validateShellRules(rules); err != nil {
+			log.Warnf("Ignoring invalid stored shell policy: %v", err)
+		} else {
+			p.rules, p.selector = rules, selector
+		}
+	}
+	return p
+}
+
+// validateShellRules checks shell rules and returns their pod selector
+func validateShellRules(rules ShellRules) (labels.Selector, error) {
+	for _, pattern := range rules.Namespaces {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid namespace pattern %q", pattern)
+		}
+	}
+	if strings.ContainsAny(rules.ShellUser, " \t'\"") {
+		return nil, fmt.Errorf("invalid shell user %q", rules.ShellUser)
+	}
+	selector, err := labels.Parse(rules.PodSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pod selector: %v", err)
+	}
+	return selector, nil
+}
+
+// Rules returns the shell rules
+func (p *ShellPolicy) Rules() ShellRules {
+	p.mu.RLock()
+	rules := p.rules
+	p.mu.RUnlock()
+	rules.DisableNodeShell = p.endpoints.IsDisabled(NodeShell)
+	if rules.Namespaces == nil {
+		rules.Namespaces = []string{}
+	}
+	return rules
+}
+
+// SetRules validates and saves the shell rules
+func (p *ShellPolicy) SetRules(rules ShellRules) error {
+	selector, err := validateShellRules(rules)
+	if err != nil {
+		return err
+	}
+	if err := p.endpoints.SetClassDisabled(NodeShell, rules.DisableNodeShell); err != nil {
+		return err
+	}
+	rules.DisableNodeShell = false // kept by the endpoint policy
+	value, _ := json.Marshal(rules)
+	if err := p.store.SetSystemConfig(shellPolicyKey, string(value)); err != nil {
+		return err
+	}
+	p.mu.Lock()
+	p.rules, p.selector = rules, selector
+	p.mu.Unlock()
+	return nil
+}
+
+// CheckPodShell returns a *ShellDenied error when the rules refuse a shell in a pod. A nil
+// policy allows every shell.
+func (p *ShellPolicy) CheckPodShell(c *gin.Context, namespace string, podLabels map[string]string) error {
+	if p == nil {
+		return nil
+	}
+	p.mu.RLock()
+	rules, selector := p.rules, p.selector
+	p.mu.RUnlock()
+
+	if len(rules.Namespaces) > 0 {
+		allowed := false
+		for _, pattern := range rules.Namespaces {
+			if matched, _ := path.Match(pattern, namespace); matched {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return &ShellDenied{Rule: "namespace", Reason: fmt.Sprintf("shells are not allowed in namespace %s", namespace)}
+		}
+	}
+	if !selector.Matches(labels.Set(podLabels)) {
+		return &ShellDenied{Rule: "pod_selector", Reason: fmt.Sprintf("shells are only allowed in pods matching %s", rules.PodSelector)}
+	}
+	return p.checkStepUp(c, rules)
+}
+
+// CheckNodeShell returns a *ShellDenied error when the rules refuse a node shell
+func (p *ShellPolicy) CheckNodeShell(c *gin.Context) error {
+	if p == nil {
+		return nil
+	}
+	if p.endpoints.IsDisabled(NodeShell) {
+		return &ShellDenied{Rule: NodeShell, Reason: "node shells are disabled by policy"}
+	}
+	p.mu.RLock()
+	rules := p.rules
+	p.mu.RUnlock()
+	return p.checkStepUp(c, rules)
+}
+
+// checkStepUp requires a valid step_up_token query parameter when the rules require MFA
+func (p *ShellPolicy) checkStepUp(c *gin.Context, rules ShellRules) error {
+	if !rules.RequireMFA {
+		return nil
+	}
+	userID, ok := c.Get("user_id")
+	if !ok || !p.validStepUp(c.Query("step_up_token"), userID.(int)) {
+		return &ShellDenied{Rule: "mfa", Reason: "enter your MFA code again to open a shell"}
+	}
+	return nil
+}
+
+// Command returns the command opening shell, run as the shell user of the rules if any
+func (p *ShellPolicy) Command(shell string, args ...string) []string {
+	if p == nil {
+		return append([]string{shell}, args...)
+	}
+	p.mu.RLock()
+	user := p.rules.ShellUser
+	p.mu.RUnlock()
+	if user == "" {
+		return append([]string{shell}, args...)
+	}
+	command := []string{"su", "-s", shell}
+	if len(args) > 0 && args[0] == "-l" {
+		command = append(command, "-l")
+	}
+	return append(command, user)
+}
+
+// Deny writes the response of a shell refused by the rules and audits it
+func Deny(c *gin.Context, err *ShellDenied) {
+	if userID, exists := c.Get("user_id"); exists {
+		username, _ := c.Get("username")
+		email, _ := c.Get("email")
+		audit.Log(c, audit.EventSecPermissionDenied, userID.(int), username.(string), email.(string),
+			fmt.Sprintf("Shell blocked by shell policy: %s", err.Reason),
+			map[string]interface{}{
+				"policy": "shell",
+				"rule":   err.Rule,
+				"path":   c.Request.URL.Path,
+			})
+	}
+	c.JSON(http.StatusForbidden, gin.H{
+		"error":        err.Reason,
+		"policy":       "shell",
+		"rule":         err.Rule,
+		"mfa_required": err.Rule == "mfa",
+	})
+}
+
+// IssueStepUp returns a token proving the user entered an MFA code, valid for StepUpTTL
+func (p *ShellPolicy) IssueStepUp(userID int) (string, time.Time) {
+	expires := time.Now().Add(StepUpTTL)
+	payload := base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d:%d", userID, expires.Unix())))
+	return payload + "." + p.sign(payload), expires
+}
+
+func (p *ShellPolicy) sign(payload string) string {
+	mac := hmac.New(sha256.New, p.secret)
+	mac.Write([]byte("shell-step-up:" + payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// validStepUp reports whether a step-up token was issued to a user and has not expired
+func (p *ShellPolicy) validStepUp(token string, userID int) bool {
+	payload, signature, found := strings.Cut(token, ".")
+	if !found || !hmac.Equal([]byte(signature), []byte(p.sign(payload))) {
+		return false
+	}
+	decoded, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return false
+	}
+	user, expiry, found := strings.Cut(string(decoded), ":")
+	if !found || user != strconv.Itoa(userID) {
+		return false
+	}
+	expires, err := strconv.ParseInt(expiry, 10, 64)
+	return err == nil && time.Now().Unix() < expires
+}
@@ -0,0 +1,99 @@
+package policy
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type fakeMFA struct{}
+
+func (fakeMFA) GetMFAStatus(userID uint) (bool, error) { return true, nil }
+
+func (fakeMFA) VerifyMFAToken(userID uint, token string) (bool, error) {
+	return token == "123456", nil
+}
+
+func shellContext(userID int, stepUp string) *gin.Context {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/shell?step_up_token="+stepUp, nil)
+	c.Set("user_id", userID)
+	return c
+}
+
+// deniedRule returns the rule refusing a shell, empty when it is allowed
+func deniedRule(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.(*ShellDenied).Rule
+}
+
+func TestShellPolicy(t *testing.T) {
+	store := memoryStore{}
+	endpoints, _ := NewEndpointPolicy(store, nil)
+	p := NewShellPolicy(store, endpoints, fakeMFA{}, "secret")
+	c := shellContext(1, "")
+
+	if err := p.CheckPodShell(c, "default", nil); err != nil {
+		t.Fatalf("CheckPodShell() without rules = %v", err)
+	}
+	if err := p.SetRules(ShellRules{PodSelector: "a in ("}); err == nil {
+		t.Fatal("expected error for an invalid pod selector")
+	}
+
+	if err := p.SetRules(ShellRules{
+		DisableNodeShell: true,
+		Namespaces:       []string{"team-*"},
+		PodSelector:      "shell=allowed",
+		ShellUser:        "nobody",
+	}); err != nil {
+		t.Fatalf("SetRules() error = %v", err)
+	}
+	for _, tc := range []struct {
+		namespace string
+		labels    map[string]string
+		want      string
+	}{
+		{"team-a", map[string]string{"shell": "allowed"}, ""},
+		{"kube-system", map[string]string{"shell": "allowed"}, "namespace"},
+		{"team-a", map[string]string{"app": "web"}, "pod_selector"},
+	} {
+		if got := deniedRule(p.CheckPodShell(c, tc.namespace, tc.labels)); got != tc.want {
+			t.Errorf("CheckPodShell(%s, %v) denied by %q, want %q", tc.namespace, tc.labels, got, tc.want)
+		}
+	}
+	if got := deniedRule(p.CheckNodeShell(c)); got != NodeShell || !endpoints.IsDisabled(NodeShell) {
+		t.Errorf("CheckNodeShell() denied by %q, want node_shell", got)
+	}
+	if got, want := p.Command("/bin/bash", "-l"), []string{"su", "-s", "/bin/bash", "-l", "nobody"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Command() = %v, want %v", got, want)
+	}
+
+	// Rules are restored on restart, the node shell state by the endpoint policy
+	restored := NewShellPolicy(store, endpoints, fakeMFA{}, "secret")
+	if rules := restored.Rules(); !rules.DisableNodeShell || rules.ShellUser != "nobody" {
+		t.Errorf("restored rules = %+v", rules)
+	}
+
+	// MFA re-prompt
+	if err := p.SetRules(ShellRules{RequireMFA: true}); err != nil {
+		t.Fatalf("SetRules() error = %v", err)
+	}
+	if got := deniedRule(p.CheckNodeShell(c)); got != "mfa" {
+		t.Errorf("CheckNodeShell() without step-up denied by %q, want mfa", got)
+	}
+	token, _ := p.IssueStepUp(1)
+	if err := p.CheckPodShell(shellContext(1, token), "default", nil); err != nil {
+		t.Errorf("CheckPodShell() with step-up = %v", err)
+	}
+	if got := deniedRule(p.CheckPodShell(shellContext(2, token), "default", nil)); got != "mfa" {
+		t.Errorf("step-up token of another user denied by %q, want mfa", got)
+	}
+	other := NewShellPolicy(store, endpoints, fakeMFA{}, "other")
+	if got := deniedRule(other.CheckPodShell(shellContext(1, token), "default", nil)); got != "mfa" {
+		t.Errorf("step-up token signed with another secret denied by %q, want mfa", got)
+	}
+}
@@ -0,0 +1,244 @@
+package search
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	// customInterval is how often the custom resources a cluster serves are discovered
+	customInterval = 5 * time.Minute
+	// maxCustomResources bounds the custom resources indexed per cluster, as each one is
+	// watched
+	maxCustomResources = 256
+)
+
+// builtinGroups are the API groups served by Kubernetes itself rather than by CRDs or
+// aggregated API servers
+var builtinGroups = map[string]bool{
+	"":                             true,
+	"apps":                         true,
+	"batch":                        true,
+	"autoscaling":                  true,
+	"policy":                       true,
+	"extensions":                   true,
+	"admissionregistration.k8s.io": true,
+	"apiextensions.k8s.io":         true,
+	"apiregistration.k8s.io":       true,
+	"authentication.k8s.io":        true,
+	"authorization.k8s.io":         true,
+	"certificates.k8s.io":          true,
+	"coordination.k8s.io":          true,
+	"discovery.k8s.io":             true,
+	"events.k8s.io":                true,
+	"flowcontrol.apiserver.k8s.io": true,
+	"internal.apiserver.k8s.io":    true,
+	"metrics.k8s.io":               true,
+	"networking.k8s.io":            true,
+	"node.k8s.io":                  true,
+	"rbac.authorization.k8s.io":    true,
+	"resource.k8s.io":              true,
+	"scheduling.k8s.io":            true,
+	"storage.k8s.io":               true,
+	"storagemigration.k8s.io":      true,
+}
+
+// customResource is a custom resource served by a cluster, in its preferred version
+type customResource struct {
+	gvr  schema.GroupVersionResource
+	kind string
+}
+
+// customInformer keeps the objects of a custom resource
+type customInformer struct {
+	customResource
+	informer cache.SharedIndexInformer
+	cancel   context.CancelFunc
+}
+
+// customResources discovers the custom resources a cluster serves that can be listed and
+// watched. Groups that fail discovery are left out.
+func customResources(client kubernetes.Interface) ([]customResource, error) {
+	lists, err := discovery.ServerPreferredResources(client.Discovery())
+	if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
+		return nil, err
+	}
+	lists = discovery.FilteredBy(discovery.SupportsAllVerbs{Verbs: []string{"list", "watch"}}, lists)
+
+	var resources []customResource
+	for _, list := range lists {
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil || builtinGroups[gv.Group] {
+			continue
+		}
+		for _, resource := range list.APIResources {
+			if strings.Contains(resource.Name, "/") {
+				continue
+			}
+			resources = append(resources, customResource{gvr: gv.WithResource(resource.Name), kind: resource.Kind})
+		}
+	}
+	sort.Slice(resources, func(i, j int) bool {
+		a, b := resources[i].gvr, resources[j].gvr
+		if a.Group != b.Group {
+			return a.Group < b.Group
+		}
+		return a.Resource < b.Resource
+	})
+	return resources, nil
+}
+
+// indexCustomResources discovers the custom resources of a cluster every customInterval
+// and keeps an informer on each one served until ctx is cancelled
+func (idx *clusterIndex) indexCustomResources(ctx context.Context, clusterName string, client dynamic.Interface) {
+	ticker := time.NewTicker(customInterval)
+	defer ticker.Stop()
+	defer func() {
+		idx.mu.Lock()
+		for gvr, custom := range idx.custom {
+			custom.cancel()
+			delete(idx.custom, gvr)
+		}
+		idx.mu.Unlock()
+	}()
+
+	for {
+		idx.discover(ctx, clusterName, client)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// discover starts informers on the custom resources newly served by a cluster and stops
+// those on the resources it no longer serves
+func (idx *clusterIndex) discover(ctx context.Context, clusterName string, client dynamic.Interface) {
+	resources, err := customResources(idx.client)
+	if err != nil {
+		log.Debugf("Failed to discover the custom resources of cluster %s: %v", clusterName, err)
+		return
+	}
+	if len(resources) > maxCustomResources {
+		log.Warnf("Cluster %s serves %d custom resources, only the first %d are indexed for search", clusterName, len(resources), maxCustomResources)
+		resources = resources[:maxCustomResources]
+	}
+
+	served := map[schema.GroupVersionResource]customResource{}
+	for _, resource := range resources {
+		served[resource.gvr] = resource
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	for gvr, custom := range idx.custom {
+		if _, ok := served[gvr]; !ok {
+			custom.cancel()
+			delete(idx.custom, gvr)
+		}
+	}
+	for gvr, resource := range served {
+		if _, ok := idx.custom[gvr]; ok {
+			continue
+		}
+		informer := dynamicinformer.NewFilteredDynamicInformer(client, gvr, metav1.NamespaceAll, 0, cache.Indexers{}, nil).Informer()
+		if err := informer.SetTransform(stripCustom); err != nil {
+			log.Warnf("Failed to set the search transform of %s in cluster %s: %v", gvr.GroupResource(), clusterName, err)
+		}
+		informerCtx, cancel := context.WithCancel(ctx)
+		idx.custom[gvr] = &customInformer{customResource: resource, informer: informer, cancel: cancel}
+		go informer.Run(informerCtx.Done())
+	}
+}
+
+// searchCustom returns the objects of the listed custom resources of a cluster matching
+// the query
+func (idx *clusterIndex) searchCustom(clusterName string, q Query) []Hit {
+	idx.mu.Lock()
+	customs := make([]*customInformer, 0, len(idx.custom))
+	for _, custom := range idx.custom {
+		customs = append(customs, custom)
+	}
+	idx.mu.Unlock()
+
+	var hits []Hit
+	for _, custom := range customs {
+		kind := strings.ToLower(custom.kind)
+		if !custom.informer.HasSynced() || !q.wantsKind(kind, custom.gvr.Resource, custom.gvr.Group) {
+			continue
+		}
+		for _, obj := range custom.informer.GetStore().List() {
+			u, ok := obj.(*unstructured.Unstructured)
+			if !ok {
+				continue
+			}
+			doc := Document{
+				Type:      kind,
+				Cluster:   clusterName,
+				Namespace: u.GetNamespace(),
+				Name:      u.GetName(),
+				Status:    customStatus(u),
+				Labels:    u.GetLabels(),
+				Group:     custom.gvr.Group,
+				Version:   custom.gvr.Version,
+				Resource:  custom.gvr.Resource,
+			}
+			if score := q.Score(&doc); score > 0 {
+				hits = append(hits, Hit{Document: doc, Score: score})
+			}
+		}
+	}
+	return hits
+}
+
+// stripCustom keeps the metadata search uses of a custom object, and its status as the
+// status phase
+func stripCustom(obj interface{}) (interface{}, error) {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return obj, nil
+	}
+	stripped := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	stripped.SetAPIVersion(u.GetAPIVersion())
+	stripped.SetKind(u.GetKind())
+	stripped.SetName(u.GetName())
+	stripped.SetNamespace(u.GetNamespace())
+	stripped.SetUID(u.GetUID())
+	stripped.SetResourceVersion(u.GetResourceVersion())
+	stripped.SetLabels(u.GetLabels())
+	if status := customStatus(u); status != "" {
+		stripped.Object["status"] = map[string]interface{}{"phase": status}
+	}
+	return stripped, nil
+}
+
+// customStatus returns Ready or NotReady after the Ready condition of a custom object, as
+// Strimzi, Crossplane, cert-manager and most operators set it, or else its status phase
+func customStatus(u *unstructured.Unstructured) string {
+	conditions, _, _ := unstructured.NestedSlice(u.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok || condition["type"] != "Ready" {
+			continue
+		}
+		if condition["status"] == "True" {
+			return "Ready"
+		}
+		return "NotReady"
+	}
+	phase, _, _ := unstructured.NestedString(u.Object, "status", "phase")
+	return phase
+}
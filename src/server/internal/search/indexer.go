@@ -0,0 +1,396 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/sonnguyen/kubelens/internal/cluster"
+	"github.com/sonnguyen/kubelens/internal/diagnostics"
+)
+
+// syncInterval is how often new clusters are indexed and removed ones dropped
+const syncInterval = 30 * time.Second
+
+// Indexer keeps the pods, deployments, services, nodes and custom resources of each
+// connected cluster in informer caches stripped down to what search needs
+type Indexer struct {
+	done chan bool
+
+	// clusterNames, client and dynamicClient resolve the connected clusters
+	clusterNames  func() []string
+	client        func(name string) (kubernetes.Interface, error)
+	dynamicClient func(name string) (dynamic.Interface, error)
+
+	mu       sync.RWMutex
+	clusters map[string]*clusterIndex
+}
+
+// clusterIndex holds the informer caches of a cluster
+type clusterIndex struct {
+	client kubernetes.Interface
+	cancel context.CancelFunc
+	stores map[string]cache.Store // by document type
+	// ready is set once every informer of a built-in resource has listed its objects
+	ready atomic.Bool
+
+	mu     sync.Mutex
+	custom map[schema.GroupVersionResource]*customInformer
+}
+
+// NewIndexer creates a new search indexer of the connected clusters
+func NewIndexer(clusterManager *cluster.Manager) *Indexer {
+	return &Indexer{
+		done:          make(chan bool),
+		clusterNames:  clusterManager.ClusterNames,
+		client:        clusterManager.GetClient,
+		dynamicClient: clusterManager.GetDynamicClient,
+		clusters:      map[string]*clusterIndex{},
+	}
+}
+
+// Start starts indexing the connected clusters
+func (x *Indexer) Start() {
+	go func() {
+		ticker := time.NewTicker(syncInterval)
+		defer ticker.Stop()
+
+		x.sync()
+		for {
+			select {
+			case <-ticker.C:
+				x.sync()
+			case <-x.done:
+				x.mu.Lock()
+				for name, idx := range x.clusters {
+					idx.cancel()
+					delete(x.clusters, name)
+				}
+				x.mu.Unlock()
+				return
+			}
+		}
+	}()
+
+	log.Info("✅ Search indexer started")
+}
+
+// Stop stops indexing
+func (x *Indexer) Stop() {
+	close(x.done)
+	log.Info("Search indexer stopped")
+}
+
+// Search returns the indexed resources of a cluster matching the query, unranked. ok is
+// false when the built-in resources of the cluster are not indexed yet; custom resources
+// are searched once listed.
+func (x *Indexer) Search(clusterName string, q Query) (hits []Hit, ok bool) {
+	x.mu.RLock()
+	idx, found := x.clusters[clusterName]
+	x.mu.RUnlock()
+	if !found || !idx.ready.Load() {
+		return nil, false
+	}
+
+	for t, store := range idx.stores {
+		if !q.WantsType(t) {
+			continue
+		}
+		for _, obj := range store.List() {
+			doc, known := document(clusterName, obj)
+			if !known {
+				continue
+			}
+			if score := q.Score(&doc); score > 0 {
+				hits = append(hits, Hit{Document: doc, Score: score})
+			}
+		}
+	}
+	return append(hits, idx.searchCustom(clusterName, q)...), true
+}
+
+// sync indexes every connected cluster not indexed yet, or whose client was replaced by a
+// reconnect, and drops the indexes of clusters that are gone
+func (x *Indexer) sync() {
+	clients := map[string]kubernetes.Interface{}
+	for _, name := range x.clusterNames() {
+		client, err := x.client(name)
+		if err != nil {
+			log.Debugf("Not indexing cluster %s for search: %v", name, err)
+			continue
+		}
+		clients[name] = client
+	}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	for name, idx := range x.clusters {
+		if client, ok := clients[name]; !ok || client != idx.client {
+			idx.cancel()
+			delete(x.clusters, name)
+			log.Infof("Stopped indexing cluster %s for search", name)
+		}
+	}
+	for name, client := range clients {
+		if _, ok := x.clusters[name]; ok {
+			continue
+		}
+		dynamicClient, err := x.dynamicClient(name)
+		if err != nil {
+			log.Debugf("Not indexing the custom resources of cluster %s for search: %v", name, err)
+		}
+		x.clusters[name] = index(name, client, dynamicClient)
+	}
+}
+
+// index starts the informers of a cluster. Their caches are ready once they have listed
+// every resource; they are then kept up to date by watches until cancelled. Custom
+// resources are indexed when dynamicClient is not nil.
+func index(clusterName string, client kubernetes.Interface, dynamicClient dynamic.Interface) *clusterIndex {
+	ctx, cancel := context.WithCancel(context.Background())
+	idx := &clusterIndex{
+		client: client,
+		cancel: cancel,
+		stores: map[string]cache.Store{},
+		custom: map[schema.GroupVersionResource]*customInformer{},
+	}
+
+	factory := informers.NewSharedInformerFactory(client, 0)
+	var synced []cache.InformerSynced
+	for t, informer := range map[string]cache.SharedIndexInformer{
+		"pod":        factory.Core().V1().Pods().Informer(),
+		"deployment": factory.Apps().V1().Deployments().Informer(),
+		"service":    factory.Core().V1().Services().Informer(),
+		"node":       factory.Core().V1().Nodes().Informer(),
+	} {
+		if err := informer.SetTransform(strip); err != nil {
+			log.Warnf("Failed to set the search transform of cluster %s: %v", clusterName, err)
+		}
+		idx.stores[t] = informer.GetStore()
+		synced = append(synced, informer.HasSynced)
+	}
+	factory.Start(ctx.Done())
+
+	go func() {
+		defer diagnostics.TrackWatch("search_index")()
+		started := time.Now()
+		if cache.WaitForCacheSync(ctx.Done(), synced...) {
+			idx.ready.Store(true)
+			log.Infof("Indexed cluster %s for search in %v", clusterName, time.Since(started).Round(time.Millisecond))
+		}
+		<-ctx.Done()
+		factory.Shutdown()
+	}()
+	if dynamicClient != nil {
+		go idx.indexCustomResources(ctx, clusterName, dynamicClient)
+	}
+	return idx
+}
+
+// strip drops from the objects kept by the informers all that search does not use
+func strip(obj interface{}) (interface{}, error) {
+	switch o := obj.(type) {
+	case *corev1.Pod:
+		return &corev1.Pod{
+			ObjectMeta: stripMeta(o.ObjectMeta),
+			Status: corev1.PodStatus{
+				Phase:                 o.Status.Phase,
+				Reason:                o.Status.Reason,
+				InitContainerStatuses: stripContainerStatuses(o.Status.InitContainerStatuses),
+				ContainerStatuses:     stripContainerStatuses(o.Status.ContainerStatuses),
+			},
+		}, nil
+	case *appsv1.Deployment:
+		return &appsv1.Deployment{
+			ObjectMeta: stripMeta(o.ObjectMeta),
+			Status:     appsv1.DeploymentStatus{AvailableReplicas: o.Status.AvailableReplicas},
+		}, nil
+	case *corev1.Service:
+		return &corev1.Service{ObjectMeta: stripMeta(o.ObjectMeta)}, nil
+	case *corev1.Node:
+		node := &corev1.Node{ObjectMeta: stripMeta(o.ObjectMeta)}
+		for _, condition := range o.Status.Conditions {
+			if condition.Type == corev1.NodeReady {
+				node.Status.Conditions = []corev1.NodeCondition{{Type: condition.Type, Status: condition.Status}}
+				break
+			}
+		}
+		return node, nil
+	}
+	return obj, nil
+}
+
+func stripMeta(meta metav1.ObjectMeta) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:              meta.Name,
+		Namespace:         meta.Namespace,
+		UID:               meta.UID,
+		ResourceVersion:   meta.ResourceVersion,
+		DeletionTimestamp: meta.DeletionTimestamp,
+		Labels:            meta.Labels,
+	}
+}
+
+// stripContainerStatuses keeps the reasons podStatus reads
+func stripContainerStatuses(statuses []corev1.ContainerStatus) []corev1.ContainerStatus {
+	if len(statuses) == 0 {
+		return nil
+	}
+	stripped := make([]corev1.ContainerStatus, len(statuses))
+	for i, status := range statuses {
+		if waiting := status.State.Waiting; waiting != nil {
+			stripped[i].State.Waiting = &corev1.ContainerStateWaiting{Reason: waiting.Reason}
+		}
+		if terminated := status.State.Terminated; terminated != nil {
+			stripped[i].State.Terminated = &corev1.ContainerStateTerminated{
+				Reason:   terminated.Reason,
+				ExitCode: terminated.ExitCode,
+				Signal:   terminated.Signal,
+			}
+		}
+	}
+	return stripped
+}
+
+// podStatus returns the status kubectl shows for a pod: the reason a container is
+// waiting or terminated (e.g. CrashLoopBackOff), prefixed with Init: for init containers,
+// Terminating while the pod is deleted, and the phase otherwise
+func podStatus(pod *corev1.Pod) string {
+	status := string(pod.Status.Phase)
+	if pod.Status.Reason != "" {
+		status = pod.Status.Reason
+	}
+
+	initializing := false
+	for i, container := range pod.Status.InitContainerStatuses {
+		state := container.State
+		if state.Terminated != nil && state.Terminated.ExitCode == 0 {
+			continue
+		}
+		initializing = true
+		switch {
+		case state.Terminated != nil && state.Terminated.Reason != "":
+			status = "Init:" + state.Terminated.Reason
+		case state.Terminated != nil && state.Terminated.Signal != 0:
+			status = fmt.Sprintf("Init:Signal:%d", state.Terminated.Signal)
+		case state.Terminated != nil:
+			status = fmt.Sprintf("Init:ExitCode:%d", state.Terminated.ExitCode)
+		case state.Waiting != nil && state.Waiting.Reason != "" && state.Waiting.Reason != "PodInitializing":
+			status = "Init:" + state.Waiting.Reason
+		default:
+			status = fmt.Sprintf("Init:%d/%d", i, len(pod.Status.InitContainerStatuses))
+		}
+		break
+	}
+	if !initializing {
+		for i := len(pod.Status.ContainerStatuses) - 1; i >= 0; i-- {
+			state := pod.Status.ContainerStatuses[i].State
+			switch {
+			case state.Waiting != nil && state.Waiting.Reason != "":
+				status = state.Waiting.Reason
+			case state.Terminated != nil && state.Terminated.Reason != "":
+				status = state.Terminated.Reason
+			case state.Terminated != nil && state.Terminated.Signal != 0:
+				status = fmt.Sprintf("Signal:%d", state.Terminated.Signal)
+			case state.Terminated != nil:
+				status = fmt.Sprintf("ExitCode:%d", state.Terminated.ExitCode)
+			default:
+				continue
+			}
+			break
+		}
+	}
+
+	if pod.DeletionTimestamp != nil {
+		status = "Terminating"
+	}
+	if status == "" {
+		status = "Unknown"
+	}
+	return status
+}
+
+// document summarizes a pod, deployment, service or node of a cluster
+func document(clusterName string, obj interface{}) (Document, bool) {
+	switch o := obj.(type) {
+	case *corev1.Pod:
+		return Document{Type: "pod", Cluster: clusterName, Namespace: o.Namespace, Name: o.Name, Status: podStatus(o), Labels: o.Labels}, true
+	case *appsv1.Deployment:
+		status := "Unavailable"
+		if o.Status.AvailableReplicas > 0 {
+			status = "Available"
+		}
+		return Document{Type: "deployment", Cluster: clusterName, Namespace: o.Namespace, Name: o.Name, Status: status, Labels: o.Labels}, true
+	case *corev1.Service:
+		return Document{Type: "service", Cluster: clusterName, Namespace: o.Namespace, Name: o.Name, Status: "Active", Labels: o.Labels}, true
+	case *corev1.Node:
+		status := "Unknown"
+		for _, condition := range o.Status.Conditions {
+			if condition.Type == corev1.NodeReady {
+				if condition.Status == corev1.ConditionTrue {
+					status = "Ready"
+				} else {
+					status = "NotReady"
+				}
+				break
+			}
+		}
+		return Document{Type: "node", Cluster: clusterName, Name: o.Name, Status: status, Labels: o.Labels}, true
+	}
+	return Document{}, false
+}
+
+// List lists the pods, deployments, services and nodes of a cluster the query can match
+// directly, for clusters that are not indexed or must be searched with the caller's own
+// permissions. Custom resources are only searched in the index. Resources the client
+// cannot list are left out.
+func List(ctx context.Context, client kubernetes.Interface, clusterName string, q Query) []Document {
+	var objects []interface{}
+	if q.WantsType("pod") {
+		if pods, err := client.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{}); err == nil {
+			for i := range pods.Items {
+				objects = append(objects, &pods.Items[i])
+			}
+		}
+	}
+	if q.WantsType("deployment") {
+		if deployments, err := client.AppsV1().Deployments(metav1.NamespaceAll).List(ctx, metav1.ListOptions{}); err == nil {
+			for i := range deployments.Items {
+				objects = append(objects, &deployments.Items[i])
+			}
+		}
+	}
+	if q.WantsType("service") {
+		if services, err := client.CoreV1().Services(metav1.NamespaceAll).List(ctx, metav1.ListOptions{}); err == nil {
+			for i := range services.Items {
+				objects = append(objects, &services.Items[i])
+			}
+		}
+	}
+	if q.WantsType("node") {
+		if nodes, err := client.CoreV1().Nodes().List(ctx, metav1.ListOptions{}); err == nil {
+			for i := range nodes.Items {
+				objects = append(objects, &nodes.Items[i])
+			}
+		}
+	}
+
+	docs := make([]Document, 0, len(objects))
+	for _, obj := range objects {
+		doc, _ := document(clusterName, obj)
+		docs = append(docs, doc)
+	}
+	return docs
+}
@@ -0,0 +1,331 @@
+// Package search keeps an in-memory index of the resources of every connected cluster,
+// fed by informers, and ranks the resources matching a global search query.
+package search
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// Scores of a query term, by where it matches. A document scores the sum over the terms
+// of the best match of each.
+const (
+	scoreExact       = 100 // the name is the term
+	scorePrefix      = 60  // the name starts with the term
+	scoreSegment     = 40  // a segment of the name (after - . _ or /) starts with the term
+	scoreContains    = 20  // the name contains the term
+	scoreNamespace   = 10  // the namespace contains the term
+	scoreDescription = 5   // the description contains the term
+)
+
+// typeOrder breaks ties between equally relevant documents: clusters and nodes before
+// workloads and custom resources, and those before the many pods they run
+var typeOrder = map[string]int{
+	"cluster":    0,
+	"node":       1,
+	"deployment": 2,
+	"service":    3,
+	"pod":        5,
+}
+
+// typeRank is the order of a type, custom resources coming just before pods
+func typeRank(t string) int {
+	if rank, ok := typeOrder[t]; ok {
+		return rank
+	}
+	return 4
+}
+
+// types maps the names a kind: filter accepts for built-in resources to document types
+var types = map[string]string{
+	"cluster": "cluster", "clusters": "cluster",
+	"node": "node", "nodes": "node", "no": "node",
+	"deployment": "deployment", "deployments": "deployment", "deploy": "deployment",
+	"service": "service", "services": "service", "svc": "service",
+	"pod": "pod", "pods": "pod", "po": "pod",
+}
+
+// Document is the searchable summary of a cluster or of one of its resources. The
+// Cluster of a cluster document is its own name. The Type of a custom resource is its
+// lower-cased kind, and its Group, Version and Resource are set.
+type Document struct {
+	Type        string
+	Cluster     string
+	Namespace   string
+	Name        string
+	Status      string
+	Description string
+	Labels      map[string]string
+	Group       string
+	Version     string
+	Resource    string
+}
+
+// ID identifies the document in search results
+func (d Document) ID() string {
+	switch {
+	case d.Type == "cluster":
+		return fmt.Sprintf("cluster-%s", d.Cluster)
+	case d.Namespace == "":
+		return fmt.Sprintf("%s-%s-%s", d.Type, d.Cluster, d.Name)
+	default:
+		return fmt.Sprintf("%s-%s-%s-%s", d.Type, d.Cluster, d.Namespace, d.Name)
+	}
+}
+
+// Hit is a document matching a query, with its relevance
+type Hit struct {
+	Document
+	Score int
+}
+
+// Query is a parsed search query. Its whitespace-separated terms must all match the
+// name, the namespace or the description of a document, ignoring case. Filters of the
+// form key:value narrow the documents down:
+//
+//	kind:pod            type of the resource (pod, deployment, service, node, cluster),
+//	                    or kind, plural or plural.group of a custom resource
+//	ns:prod             namespace (also namespace:)
+//	status:Running      status, e.g. CrashLoopBackOff for pods
+//	cluster:eu-1        cluster
+//	label:app=web       label selector, e.g. label:tier!=db,env (also l:)
+//
+// Values of kind, ns, status and cluster may list alternatives separated by commas, and
+// repeating one of these filters adds alternatives; label filters must all match.
+type Query struct {
+	terms      []string
+	types      []string
+	namespaces []string
+	statuses   []string
+	clusters   []string
+	selectors  []labels.Selector
+}
+
+// ParseQuery parses a search query
+func ParseQuery(q string) (Query, error) {
+	var query Query
+	for _, field := range strings.Fields(q) {
+		key, value, found := strings.Cut(field, ":")
+		if !found {
+			query.terms = append(query.terms, strings.ToLower(field))
+			continue
+		}
+		if value == "" {
+			return Query{}, fmt.Errorf("filter %q has no value", key)
+		}
+		switch strings.ToLower(key) {
+		case "kind", "type":
+			for _, name := range strings.Split(strings.ToLower(value), ",") {
+				if t, ok := types[name]; ok {
+					name = t
+				}
+				query.types = append(query.types, name)
+			}
+		case "ns", "namespace":
+			query.namespaces = append(query.namespaces, strings.Split(strings.ToLower(value), ",")...)
+		case "status":
+			query.statuses = append(query.statuses, strings.Split(strings.ToLower(value), ",")...)
+		case "cluster":
+			query.clusters = append(query.clusters, strings.Split(strings.ToLower(value), ",")...)
+		case "label", "l":
+			selector, err := labels.Parse(value)
+			if err != nil {
+				return Query{}, fmt.Errorf("invalid label selector %q: %v", value, err)
+			}
+			query.selectors = append(query.selectors, selector)
+		default:
+			return Query{}, fmt.Errorf("unknown filter %q", key)
+		}
+	}
+	return query, nil
+}
+
+// Empty reports whether the query has neither terms nor filters
+func (q Query) Empty() bool {
+	return len(q.terms) == 0 && len(q.types) == 0 && len(q.namespaces) == 0 &&
+		len(q.statuses) == 0 && len(q.clusters) == 0 && len(q.selectors) == 0
+}
+
+// WantsType reports whether documents of a built-in type can match the query
+func (q Query) WantsType(t string) bool {
+	return q.wantsKind(t, "", "")
+}
+
+// wantsKind reports whether documents of a type, and of a custom resource and its group
+// when set, can match the query
+func (q Query) wantsKind(t, resource, group string) bool {
+	if len(q.types) == 0 || contains(q.types, t) {
+		return true
+	}
+	if resource == "" {
+		return false
+	}
+	return contains(q.types, resource) || contains(q.types, resource+"."+group) || contains(q.types, t+"."+group)
+}
+
+// filter reports whether a document passes the filters of the query
+func (q Query) filter(d *Document) bool {
+	if !q.wantsKind(d.Type, d.Resource, d.Group) {
+		return false
+	}
+	if len(q.namespaces) > 0 && !contains(q.namespaces, strings.ToLower(d.Namespace)) {
+		return false
+	}
+	if len(q.statuses) > 0 && !contains(q.statuses, strings.ToLower(d.Status)) {
+		return false
+	}
+	if len(q.clusters) > 0 && !contains(q.clusters, strings.ToLower(d.Cluster)) {
+		return false
+	}
+	for _, selector := range q.selectors {
+		if !selector.Matches(labels.Set(d.Labels)) {
+			return false
+		}
+	}
+	return true
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Score returns the relevance of a document to the query, 0 when it does not match.
+// Documents passing the filters of a query without terms all score 1.
+func (q Query) Score(d *Document) int {
+	if q.Empty() || !q.filter(d) {
+		return 0
+	}
+	if len(q.terms) == 0 {
+		return 1
+	}
+	name := strings.ToLower(d.Name)
+	namespace := strings.ToLower(d.Namespace)
+	description := strings.ToLower(d.Description)
+
+	total := 0
+	for _, term := range q.terms {
+		score := 0
+		switch {
+		case name == term:
+			score = scoreExact
+		case strings.HasPrefix(name, term):
+			score = scorePrefix
+		case segmentPrefix(name, term):
+			score = scoreSegment
+		case strings.Contains(name, term):
+			score = scoreContains
+		case strings.Contains(namespace, term):
+			score = scoreNamespace
+		case strings.Contains(description, term):
+			score = scoreDescription
+		default:
+			return 0
+		}
+		total += score
+	}
+	return total
+}
+
+// Match returns the hits among documents, unranked
+func (q Query) Match(docs []Document) []Hit {
+	var hits []Hit
+	for i := range docs {
+		if score := q.Score(&docs[i]); score > 0 {
+			hits = append(hits, Hit{Document: docs[i], Score: score})
+		}
+	}
+	return hits
+}
+
+// segmentPrefix reports whether a segment of name after a separator starts with term
+func segmentPrefix(name, term string) bool {
+	for i := 0; i < len(name); {
+		j := strings.Index(name[i:], term)
+		if j < 0 {
+			return false
+		}
+		j += i
+		if j > 0 && strings.IndexByte("-._/", name[j-1]) >= 0 {
+			return true
+		}
+		i = j + 1
+	}
+	return false
+}
+
+// Facet is the number of hits of a type
+type Facet struct {
+	Type  string `json:"type"`
+	Count int    `json:"count"`
+}
+
+// Facets counts hits by type, clusters first and pods last
+func Facets(hits []Hit) []Facet {
+	counts := map[string]int{}
+	for i := range hits {
+		counts[hits[i].Type]++
+	}
+	facets := []Facet{}
+	for t, count := range counts {
+		facets = append(facets, Facet{Type: t, Count: count})
+	}
+	sort.Slice(facets, func(i, j int) bool {
+		if ri, rj := typeRank(facets[i].Type), typeRank(facets[j].Type); ri != rj {
+			return ri < rj
+		}
+		return facets[i].Type < facets[j].Type
+	})
+	return facets
+}
+
+// PerType keeps the page-th window of size hits of each type of ranked hits (pages start
+// at 1), in rank order
+func PerType(hits []Hit, page, size int) []Hit {
+	seen := map[string]int{}
+	kept := hits[:0:0]
+	for _, hit := range hits {
+		n := seen[hit.Type]
+		seen[hit.Type]++
+		if n >= (page-1)*size && n < page*size {
+			kept = append(kept, hit)
+		}
+	}
+	return kept
+}
+
+// Rank sorts hits best first and keeps at most limit of them (all when limit is 0).
+// Equally relevant hits are ordered by type, then shorter names first, then by name,
+// cluster and namespace.
+func Rank(hits []Hit, limit int) []Hit {
+	sort.Slice(hits, func(i, j int) bool {
+		a, b := &hits[i], &hits[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if ta, tb := typeRank(a.Type), typeRank(b.Type); ta != tb {
+			return ta < tb
+		}
+		if len(a.Name) != len(b.Name) {
+			return len(a.Name) < len(b.Name)
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		if a.Cluster != b.Cluster {
+			return a.Cluster < b.Cluster
+		}
+		return a.Namespace < b.Namespace
+	})
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits
+}
@@ -0,0 +1,275 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func mustParse(t *testing.T, query string) Query {
+	t.Helper()
+	q, err := ParseQuery(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return q
+}
+
+func TestQueryFilters(t *testing.T) {
+	docs := []Document{
+		{Type: "cluster", Cluster: "prod", Name: "prod", Labels: map[string]string{"env": "prod"}},
+		{Type: "pod", Cluster: "prod", Namespace: "shop", Name: "api-1", Status: "CrashLoopBackOff", Labels: map[string]string{"app": "api"}},
+		{Type: "pod", Cluster: "prod", Namespace: "shop", Name: "api-2", Status: "Running", Labels: map[string]string{"app": "api"}},
+		{Type: "pod", Cluster: "staging", Namespace: "shop", Name: "api-1", Status: "CrashLoopBackOff", Labels: map[string]string{"app": "api"}},
+		{Type: "deployment", Cluster: "prod", Namespace: "shop", Name: "api", Labels: map[string]string{"app": "api", "tier": "web"}},
+		{Type: "service", Cluster: "prod", Namespace: "kube-system", Name: "api"},
+		{Type: "service", Cluster: "prod", Namespace: "shop", Name: "api-fn", Group: "serving.knative.dev", Version: "v1", Resource: "services"},
+		{Type: "kafka", Cluster: "prod", Namespace: "data", Name: "my-kafka", Status: "Ready", Group: "kafka.strimzi.io", Version: "v1beta2", Resource: "kafkas"},
+	}
+	for _, tc := range []struct {
+		query string
+		want  int
+	}{
+		{"api", 6},
+		{"kind:pod", 3},
+		{"kind:po,svc api", 5},
+		{"kind:services.serving.knative.dev", 1},
+		{"kafka", 1},
+		{"kind:kafka status:ready", 1},
+		{"kind:kafkas.kafka.strimzi.io ns:data", 1},
+		{"kind:pod kind:deploy", 4},
+		{"kind:pod ns:shop status:crashloopbackoff", 2},
+		{"status:CrashLoopBackOff cluster:prod", 1},
+		{"label:app=api", 4},
+		{"label:app=api label:tier!=web", 3},
+		{"l:app,!tier kind:deployment", 0},
+		{"cluster:prod kind:cluster label:env=prod", 1},
+		{"NS:Kube-System", 1},
+	} {
+		if hits := mustParse(t, tc.query).Match(docs); len(hits) != tc.want {
+			t.Errorf("%q: got %d hits, want %d", tc.query, len(hits), tc.want)
+		}
+	}
+
+	for _, query := range []string{"owner:me", "ns:", "label:a=(b"} {
+		if _, err := ParseQuery(query); err == nil {
+			t.Errorf("%q: want a parse error", query)
+		}
+	}
+	if q := mustParse(t, "kind:pod"); q.WantsType("node") || !q.WantsType("pod") {
+		t.Error("kind:pod should only want pods")
+	}
+}
+
+func TestFacetsAndPerType(t *testing.T) {
+	var hits []Hit
+	for i := 0; i < 5; i++ {
+		hits = append(hits, Hit{Document: Document{Type: "pod", Name: fmt.Sprintf("api-%d", i)}, Score: 10})
+	}
+	hits = append(hits, Hit{Document: Document{Type: "deployment", Name: "api"}, Score: 5})
+	hits = Rank(hits, 0)
+
+	facets := Facets(hits)
+	if len(facets) != 2 || facets[0] != (Facet{Type: "deployment", Count: 1}) || facets[1] != (Facet{Type: "pod", Count: 5}) {
+		t.Errorf("got facets %+v", facets)
+	}
+
+	page := PerType(hits, 1, 2)
+	if len(page) != 3 || page[0].Name != "api-0" || page[1].Name != "api-1" || page[2].Type != "deployment" {
+		t.Errorf("got first page %+v", page)
+	}
+	page = PerType(hits, 2, 2)
+	if len(page) != 2 || page[0].Name != "api-2" || page[1].Name != "api-3" {
+		t.Errorf("got second page %+v", page)
+	}
+}
+
+func TestPodStatus(t *testing.T) {
+	waiting := func(reason string) corev1.ContainerStatus {
+		return corev1.ContainerStatus{State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: reason}}}
+	}
+	running := corev1.ContainerStatus{State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}}
+	now := metav1.Now()
+
+	for _, tc := range []struct {
+		name string
+		pod  corev1.Pod
+		want string
+	}{
+		{"phase", corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodRunning, ContainerStatuses: []corev1.ContainerStatus{running}}}, "Running"},
+		{"crash loop", corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodRunning, ContainerStatuses: []corev1.ContainerStatus{running, waiting("CrashLoopBackOff")}}}, "CrashLoopBackOff"},
+		{"init", corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodPending, InitContainerStatuses: []corev1.ContainerStatus{waiting("ImagePullBackOff")}}}, "Init:ImagePullBackOff"},
+		{"evicted", corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodFailed, Reason: "Evicted"}}, "Evicted"},
+		{"terminating", corev1.Pod{ObjectMeta: metav1.ObjectMeta{DeletionTimestamp: &now}, Status: corev1.PodStatus{Phase: corev1.PodRunning}}, "Terminating"},
+		{"empty", corev1.Pod{}, "Unknown"},
+	} {
+		pod, _ := strip(&tc.pod)
+		if got := podStatus(pod.(*corev1.Pod)); got != tc.want {
+			t.Errorf("%s: got %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestRank(t *testing.T) {
+	docs := []Document{
+		{Type: "pod", Cluster: "prod", Namespace: "shop", Name: "api-7d9f-abcde"},
+		{Type: "deployment", Cluster: "prod", Namespace: "shop", Name: "api"},
+		{Type: "service", Cluster: "prod", Namespace: "shop", Name: "payments-api"},
+		{Type: "pod", Cluster: "prod", Namespace: "api", Name: "worker-1"},
+		{Type: "service", Cluster: "prod", Namespace: "shop", Name: "rapid"},
+		{Type: "pod", Cluster: "prod", Namespace: "shop", Name: "web-1"},
+	}
+	hits := Rank(mustParse(t, "API").Match(docs), 0)
+
+	var got []string
+	for _, hit := range hits {
+		got = append(got, hit.Name)
+	}
+	want := []string{"api", "api-7d9f-abcde", "payments-api", "rapid", "worker-1"}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+
+	// Every term must match
+	if hits := mustParse(t, "api shop").Match(docs); len(hits) != 4 {
+		t.Errorf("got %d hits for two terms, want 4", len(hits))
+	}
+	if hits := Rank(mustParse(t, "api").Match(docs), 2); len(hits) != 2 {
+		t.Errorf("got %d hits, want the limit of 2", len(hits))
+	}
+	if id := hits[0].ID(); id != "deployment-prod-shop-api" {
+		t.Errorf("got id %q", id)
+	}
+}
+
+func TestIndexer(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "checkout-1", Namespace: "shop"},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "app", Image: "checkout:1"}}},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "checkout", Namespace: "shop"}},
+		&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+			Status: corev1.NodeStatus{Conditions: []corev1.NodeCondition{
+				{Type: corev1.NodeMemoryPressure, Status: corev1.ConditionFalse},
+				{Type: corev1.NodeReady, Status: corev1.ConditionTrue},
+			}},
+		},
+	)
+	client.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*metav1.APIResourceList{
+		{GroupVersion: "apps/v1", APIResources: []metav1.APIResource{
+			{Name: "deployments", Kind: "Deployment", Namespaced: true, Verbs: metav1.Verbs{"list", "watch"}},
+		}},
+		{GroupVersion: "kafka.strimzi.io/v1beta2", APIResources: []metav1.APIResource{
+			{Name: "kafkas", Kind: "Kafka", Namespaced: true, Verbs: metav1.Verbs{"get", "list", "watch"}},
+			{Name: "kafkas/status", Kind: "Kafka", Namespaced: true, Verbs: metav1.Verbs{"get", "list", "watch"}},
+			{Name: "kafkarebalances", Kind: "KafkaRebalance", Namespaced: true, Verbs: metav1.Verbs{"get"}},
+		}},
+	}
+	kafkas := schema.GroupVersionResource{Group: "kafka.strimzi.io", Version: "v1beta2", Resource: "kafkas"}
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{kafkas: "KafkaList"},
+		&unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "kafka.strimzi.io/v1beta2",
+			"kind":       "Kafka",
+			"metadata":   map[string]interface{}{"name": "checkout-events", "namespace": "data"},
+			"spec":       map[string]interface{}{"kafka": map[string]interface{}{"replicas": int64(3)}},
+			"status": map[string]interface{}{"conditions": []interface{}{
+				map[string]interface{}{"type": "Ready", "status": "True"},
+			}},
+		}},
+	)
+	x := &Indexer{
+		done:          make(chan bool),
+		clusterNames:  func() []string { return []string{"prod"} },
+		client:        func(string) (kubernetes.Interface, error) { return client, nil },
+		dynamicClient: func(string) (dynamic.Interface, error) { return dynamicClient, nil },
+		clusters:      map[string]*clusterIndex{},
+	}
+	x.Start()
+	defer x.Stop()
+
+	search := func(query string, want int) []Hit {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			hits, ok := x.Search("prod", mustParse(t, query))
+			if ok && len(hits) == want {
+				return Rank(hits, 0)
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("search %q: got %d hits (indexed: %v), want %d", query, len(hits), ok, want)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	hits := search("checkout", 3)
+	if hits[0].Type != "deployment" || hits[0].Status != "Unavailable" {
+		t.Errorf("got %+v first, want the deployment", hits[0])
+	}
+	if hits[1].Type != "kafka" || hits[1].Status != "Ready" || hits[1].Resource != "kafkas" || hits[1].Group != "kafka.strimzi.io" {
+		t.Errorf("got %+v second, want the Kafka cluster", hits[1])
+	}
+	if hits[2].Type != "pod" || hits[2].Status != "Running" {
+		t.Errorf("got %+v third, want the running pod", hits[2])
+	}
+	x.mu.RLock()
+	if n := len(x.clusters["prod"].custom); n != 1 {
+		t.Errorf("got %d custom resources indexed, want only kafkas", n)
+	}
+	x.mu.RUnlock()
+	if hits := search("node", 1); hits[0].Status != "Ready" {
+		t.Errorf("got node status %q, want Ready", hits[0].Status)
+	}
+
+	// The informers keep only what search needs
+	x.mu.RLock()
+	for _, obj := range x.clusters["prod"].stores["pod"].List() {
+		if pod := obj.(*corev1.Pod); len(pod.Spec.Containers) != 0 {
+			t.Errorf("pod spec was kept in the index")
+		}
+	}
+	x.mu.RUnlock()
+
+	// Changes reach the index through the watches
+	_, err := client.CoreV1().Services("shop").Create(context.Background(), &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "checkout", Namespace: "shop"},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	search("checkout", 4)
+	if err := client.CoreV1().Pods("shop").Delete(context.Background(), "checkout-1", metav1.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	search("checkout", 3)
+	if err := dynamicClient.Resource(kafkas).Namespace("data").Delete(context.Background(), "checkout-events", metav1.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	search("checkout", 2)
+
+	if _, ok := x.Search("staging", mustParse(t, "checkout")); ok {
+		t.Error("a cluster that is not connected was reported as indexed")
+	}
+}
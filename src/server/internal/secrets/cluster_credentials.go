@@ -0,0 +1,70 @@
+package secrets
+
+import (
+	"context"
+	"path"
+	"sync"
+	"time"
+)
+
+// credentialCacheTTL is how long cluster credentials read from Vault are reused, so that
+// listing clusters does not read every secret each time
+const credentialCacheTTL = 5 * time.Minute
+
+// ClusterCredentialStore keeps cluster credentials in Vault under a path prefix. It
+// implements db.CredentialStore.
+type ClusterCredentialStore struct {
+	vault  *Vault
+	prefix string
+
+	mu    sync.Mutex
+	cache map[string]cachedCredentials
+}
+
+type cachedCredentials struct {
+	data    map[string]string
+	fetched time.Time
+}
+
+// NewClusterCredentialStore stores credentials under prefix (e.g. kubelens) in the KV mount
+func NewClusterCredentialStore(vault *Vault, prefix string) *ClusterCredentialStore {
+	return &ClusterCredentialStore{vault: vault, prefix: prefix, cache: map[string]cachedCredentials{}}
+}
+
+// Get returns the credentials stored at a path
+func (s *ClusterCredentialStore) Get(name string) (map[string]string, error) {
+	s.mu.Lock()
+	cached, ok := s.cache[name]
+	s.mu.Unlock()
+	if ok && time.Since(cached.fetched) < credentialCacheTTL {
+		return cached.data, nil
+	}
+
+	data, err := s.vault.Get(context.Background(), path.Join(s.prefix, name))
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	s.cache[name] = cachedCredentials{data: data, fetched: time.Now()}
+	s.mu.Unlock()
+	return data, nil
+}
+
+// Put stores credentials at a path
+func (s *ClusterCredentialStore) Put(name string, data map[string]string) error {
+	if err := s.vault.Put(context.Background(), path.Join(s.prefix, name), data); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.cache[name] = cachedCredentials{data: data, fetched: time.Now()}
+	s.mu.Unlock()
+	return nil
+}
+
+// Delete removes the credentials stored at a path
+func (s *ClusterCredentialStore) Delete(name string) error {
+	s.mu.Lock()
+	delete(s.cache, name)
+	s.mu.Unlock()
+	return s.vault.Delete(context.Background(), path.Join(s.prefix, name))
+}
@@ -0,0 +1,347 @@
+// Package secrets sources kubelens' own secrets from HashiCorp Vault and keeps cluster
+// credentials there instead of the SQL database. It talks to Vault's HTTP API directly:
+// KV version 2 secrets, token or Kubernetes auth, and renewal of the auth token lease.
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// RefPrefix marks a configuration value that names a Vault secret instead of holding it:
+// vault:<path>#<key>, the path being relative to the KV mount (e.g. vault:kubelens/db#password)
+const RefPrefix = "vault:"
+
+// DefaultServiceAccountTokenPath is where the Kubernetes auth method reads the pod's token
+const DefaultServiceAccountTokenPath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+
+// minRenewRetry bounds how often a failed token renewal is retried
+const minRenewRetry = 15 * time.Second
+
+// ErrNotFound is returned for a secret that does not exist
+var ErrNotFound = errors.New("secret not found")
+
+// VaultOptions configures the Vault client
+type VaultOptions struct {
+	Address string
+	// Token authenticates directly; otherwise Role logs in with the Kubernetes auth method
+	Token string
+	Role  string
+	// AuthMount is the path of the Kubernetes auth method (default kubernetes)
+	AuthMount string
+	// ServiceAccountTokenPath is the JWT presented to the Kubernetes auth method
+	ServiceAccountTokenPath string
+	// KVMount is the path of the KV version 2 secrets engine (default secret)
+	KVMount string
+	// Namespace is the Vault Enterprise namespace, if any
+	Namespace string
+	// CACert is a PEM file trusted for the Vault server certificate
+	CACert string
+}
+
+// Vault is a Vault client logged in with a renewable token
+type Vault struct {
+	opts VaultOptions
+	http *http.Client
+
+	mu        sync.RWMutex
+	token     string
+	ttl       time.Duration
+	renewable bool
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// vaultAuth is the auth block of a login or renew response
+type vaultAuth struct {
+	ClientToken   string `json:"client_token"`
+	LeaseDuration int    `json:"lease_duration"`
+	Renewable     bool   `json:"renewable"`
+}
+
+// NewVault connects to Vault and logs in
+func NewVault(opts VaultOptions) (*Vault, error) {
+	if opts.Address == "" {
+		return nil, fmt.Errorf("vault address is required")
+	}
+	if opts.Token == "" && opts.Role == "" {
+		return nil, fmt.Errorf("a vault token or a Kubernetes auth role is required")
+	}
+	if opts.AuthMount == "" {
+		opts.AuthMount = "kubernetes"
+	}
+	if opts.ServiceAccountTokenPath == "" {
+		opts.ServiceAccountTokenPath = DefaultServiceAccountTokenPath
+	}
+	if opts.KVMount == "" {
+		opts.KVMount = "secret"
+	}
+	opts.Address = strings.TrimSuffix(opts.Address, "/")
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if opts.CACert != "" {
+		pem, err := os.ReadFile(opts.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read vault CA certificate: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", opts.CACert)
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	}
+
+	v := &Vault{
+		opts: opts,
+		http: &http.Client{Transport: transport, Timeout: 30 * time.Second},
+		done: make(chan struct{}),
+	}
+	if err := v.login(context.Background()); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// login obtains a token: the configured one (looked up for its TTL) or one issued by
+// the Kubernetes auth method
+func (v *Vault) login(ctx context.Context) error {
+	if v.opts.Token != "" {
+		v.setToken(v.opts.Token, 0, false)
+		var resp struct {
+			Data struct {
+				TTL       int  `json:"ttl"`
+				Renewable bool `json:"renewable"`
+			} `json:"data"`
+		}
+		if err := v.do(ctx, http.MethodGet, "auth/token/lookup-self", nil, &resp); err != nil {
+			return fmt.Errorf("vault token lookup failed: %w", err)
+		}
+		v.setToken(v.opts.Token, time.Duration(resp.Data.TTL)*time.Second, resp.Data.Renewable)
+		return nil
+	}
+
+	jwt, err := os.ReadFile(v.opts.ServiceAccountTokenPath)
+	if err != nil {
+		return fmt.Errorf("failed to read service account token: %w", err)
+	}
+	var resp struct {
+		Auth vaultAuth `json:"auth"`
+	}
+	body := map[string]string{"role": v.opts.Role, "jwt": strings.TrimSpace(string(jwt))}
+	if err := v.do(ctx, http.MethodPost, "auth/"+v.opts.AuthMount+"/login", body, &resp); err != nil {
+		return fmt.Errorf("vault kubernetes login failed: %w", err)
+	}
+	v.setToken(resp.Auth.ClientToken, time.Duration(resp.Auth.LeaseDuration)*time.Second, resp.Auth.Renewable)
+	return nil
+}
+
+func (v *Vault) setToken(token string, ttl time.Duration, renewable bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.token, v.ttl, v.renewable = token, ttl, renewable
+}
+
+// Start renews the token lease in the background, logging in again when the token
+// cannot be renewed any more
+func (v *Vault) Start() {
+	v.wg.Add(1)
+	go func() {
+		defer v.wg.Done()
+		for {
+			v.mu.RLock()
+			ttl := v.ttl
+			v.mu.RUnlock()
+			if ttl <= 0 {
+				// A token without expiry (e.g. a root token) needs no renewal
+				return
+			}
+
+			select {
+			case <-v.done:
+				return
+			case <-time.After(ttl * 2 / 3):
+			}
+			if err := v.renew(context.Background()); err != nil {
+				log.Warnf("Failed to renew vault token: %v", err)
+				// Retry well before the lease runs out
+				v.mu.Lock()
+				v.ttl = max(ttl/2, minRenewRetry)
+				v.mu.Unlock()
+			}
+		}
+	}()
+}
+
+// Stop stops the lease renewal
+func (v *Vault) Stop() {
+	close(v.done)
+	v.wg.Wait()
+}
+
+// renew extends the token lease, or logs in again for a token that is not renewable
+func (v *Vault) renew(ctx context.Context) error {
+	v.mu.RLock()
+	renewable := v.renewable
+	v.mu.RUnlock()
+
+	if renewable {
+		var resp struct {
+			Auth vaultAuth `json:"auth"`
+		}
+		err := v.do(ctx, http.MethodPost, "auth/token/renew-self", map[string]string{}, &resp)
+		if err == nil {
+			v.mu.Lock()
+			v.ttl = time.Duration(resp.Auth.LeaseDuration) * time.Second
+			v.mu.Unlock()
+			return nil
+		}
+		if v.opts.Role == "" {
+			return err
+		}
+		log.Warnf("Failed to renew vault token, logging in again: %v", err)
+	}
+	if v.opts.Role == "" {
+		return fmt.Errorf("vault token is not renewable and no Kubernetes auth role is configured")
+	}
+	return v.login(ctx)
+}
+
+// Get reads the data of a KV secret
+func (v *Vault) Get(ctx context.Context, path string) (map[string]string, error) {
+	var resp struct {
+		Data struct {
+			Data map[string]interface{} `json:"data"`
+		} `json:"data"`
+	}
+	if err := v.do(ctx, http.MethodGet, v.opts.KVMount+"/data/"+strings.Trim(path, "/"), nil, &resp); err != nil {
+		return nil, err
+	}
+	data := make(map[string]string, len(resp.Data.Data))
+	for key, value := range resp.Data.Data {
+		if s, ok := value.(string); ok {
+			data[key] = s
+		} else {
+			encoded, _ := json.Marshal(value)
+			data[key] = string(encoded)
+		}
+	}
+	return data, nil
+}
+
+// Put writes a new version of a KV secret
+func (v *Vault) Put(ctx context.Context, path string, data map[string]string) error {
+	return v.do(ctx, http.MethodPost, v.opts.KVMount+"/data/"+strings.Trim(path, "/"), map[string]interface{}{"data": data}, nil)
+}
+
+// Delete removes a KV secret with all its versions
+func (v *Vault) Delete(ctx context.Context, path string) error {
+	err := v.do(ctx, http.MethodDelete, v.opts.KVMount+"/metadata/"+strings.Trim(path, "/"), nil, nil)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+// Resolve returns value itself, or the secret it names when it is a vault:<path>#<key>
+// reference
+func (v *Vault) Resolve(ctx context.Context, value string) (string, error) {
+	if !strings.HasPrefix(value, RefPrefix) {
+		return value, nil
+	}
+	path, key, ok := strings.Cut(strings.TrimPrefix(value, RefPrefix), "#")
+	if !ok || path == "" || key == "" {
+		return "", fmt.Errorf("invalid vault reference %q, want vault:<path>#<key>", value)
+	}
+	data, err := v.Get(ctx, path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s from vault: %w", path, err)
+	}
+	secret, ok := data[key]
+	if !ok {
+		return "", fmt.Errorf("vault secret %s has no key %q", path, key)
+	}
+	return secret, nil
+}
+
+// ResolveEnv replaces an environment variable holding a vault reference with the secret
+// it names, for settings read straight from the environment (JWT_SECRET)
+func (v *Vault) ResolveEnv(ctx context.Context, name string) error {
+	value := os.Getenv(name)
+	if !strings.HasPrefix(value, RefPrefix) {
+		return nil
+	}
+	secret, err := v.Resolve(ctx, value)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return os.Setenv(name, secret)
+}
+
+// do sends a request to the Vault API and decodes the JSON response into out
+func (v *Vault) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, v.opts.Address+"/v1/"+path, reader)
+	if err != nil {
+		return err
+	}
+	v.mu.RLock()
+	token := v.token
+	v.mu.RUnlock()
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+	if v.opts.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.opts.Namespace)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := v.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	}
+	if resp.StatusCode >= 300 {
+		var failure struct {
+			Errors []string `json:"errors"`
+		}
+		if json.Unmarshal(data, &failure) == nil && len(failure.Errors) > 0 {
+			return fmt.Errorf("vault %s %s: %s", method, path, strings.Join(failure.Errors, "; "))
+		}
+		return fmt.Errorf("vault %s %s: %s", method, path, resp.Status)
+	}
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
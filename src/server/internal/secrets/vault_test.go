@@ -0,0 +1,100 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeVault serves the token and KV v2 endpoints of the Vault API from memory
+func fakeVault(t *testing.T, token string) (*httptest.Server, map[string]map[string]string) {
+	var mu sync.Mutex
+	kv := map[string]map[string]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.Header.Get("X-Vault-Token") != token {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"errors":["permission denied"]}`))
+			return
+		}
+		switch {
+		case r.URL.Path == "/v1/auth/token/lookup-self":
+			w.Write([]byte(`{"data":{"ttl":3600,"renewable":true}}`))
+		case strings.HasPrefix(r.URL.Path, "/v1/secret/data/"):
+			path := strings.TrimPrefix(r.URL.Path, "/v1/secret/data/")
+			if r.Method == http.MethodPost {
+				var body struct {
+					Data map[string]string `json:"data"`
+				}
+				json.NewDecoder(r.Body).Decode(&body)
+				kv[path] = body.Data
+				w.Write([]byte(`{"data":{"version":1}}`))
+				return
+			}
+			data, ok := kv[path]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`{"errors":[]}`))
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{"data": data}})
+		case strings.HasPrefix(r.URL.Path, "/v1/secret/metadata/") && r.Method == http.MethodDelete:
+			delete(kv, strings.TrimPrefix(r.URL.Path, "/v1/secret/metadata/"))
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, kv
+}
+
+func TestVault(t *testing.T) {
+	server, kv := fakeVault(t, "s.test")
+	kv["kubelens/db"] = map[string]string{"password": "hunter2"}
+
+	if _, err := NewVault(VaultOptions{Address: server.URL, Token: "wrong"}); err == nil {
+		t.Error("NewVault() with a rejected token succeeded")
+	}
+	vault, err := NewVault(VaultOptions{Address: server.URL, Token: "s.test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vault.ttl.Seconds() != 3600 || !vault.renewable {
+		t.Errorf("token ttl %v renewable %v, want the looked up lease", vault.ttl, vault.renewable)
+	}
+
+	ctx := context.Background()
+	for value, want := range map[string]string{
+		"plain":                      "plain",
+		"vault:kubelens/db#password": "hunter2",
+	} {
+		if got, err := vault.Resolve(ctx, value); err != nil || got != want {
+			t.Errorf("Resolve(%q) = %q, %v; want %q", value, got, err, want)
+		}
+	}
+	for _, value := range []string{"vault:kubelens/db#user", "vault:kubelens/missing#password", "vault:kubelens/db"} {
+		if _, err := vault.Resolve(ctx, value); err == nil {
+			t.Errorf("Resolve(%q) succeeded, want an error", value)
+		}
+	}
+
+	store := NewClusterCredentialStore(vault, "kubelens")
+	if err := store.Put("clusters/prod", map[string]string{"token": "abc"}); err != nil {
+		t.Fatal(err)
+	}
+	if kv["kubelens/clusters/prod"]["token"] != "abc" {
+		t.Errorf("stored secret = %v", kv["kubelens/clusters/prod"])
+	}
+	if err := store.Delete("clusters/prod"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get("clusters/prod"); err != ErrNotFound {
+		t.Errorf("Get() after Delete() = %v, want ErrNotFound", err)
+	}
+}
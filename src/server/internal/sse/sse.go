@@ -0,0 +1,106 @@
+// Package sse writes server-sent events, the fallback of streaming endpoints for clients
+// behind proxies that break WebSockets
+package sse
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// Heartbeat is how long a stream stays silent before a comment keeps it open through
+	// proxies
+	Heartbeat = 15 * time.Second
+	// writeWait is the time allowed to write an event to the client
+	writeWait = 10 * time.Second
+)
+
+// Requested reports whether a streaming endpoint should answer with server-sent events
+// instead of a WebSocket: with ?transport=sse or an Accept header of text/event-stream
+func Requested(r *http.Request) bool {
+	if transport := r.URL.Query().Get("transport"); transport != "" {
+		return transport == "sse"
+	}
+	return strings.Contains(r.Header.Get("Accept"), "text/event-stream")
+}
+
+// Writer writes server-sent events to a response. It is safe for concurrent use.
+type Writer struct {
+	mu         sync.Mutex
+	w          http.ResponseWriter
+	controller *http.ResponseController
+	lastWrite  time.Time
+}
+
+// Start answers a request with an event stream. Clients reconnect after retry when the
+// stream ends.
+func Start(w http.ResponseWriter, retry time.Duration) (*Writer, error) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no") // nginx would buffer the stream
+	w.WriteHeader(http.StatusOK)
+
+	sw := &Writer{w: w, controller: http.NewResponseController(w)}
+	return sw, sw.write(fmt.Sprintf("retry: %d\n\n", retry.Milliseconds()))
+}
+
+// Event writes an event. id and event may be empty; data may hold several lines, which the
+// client joins back.
+func (sw *Writer) Event(id, event string, data []byte) error {
+	var buf bytes.Buffer
+	if id != "" {
+		fmt.Fprintf(&buf, "id: %s\n", id)
+	}
+	if event != "" {
+		fmt.Fprintf(&buf, "event: %s\n", event)
+	}
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		buf.WriteString("data: ")
+		buf.Write(bytes.ReplaceAll(line, []byte("\r"), nil)) // a CR would end the line
+		buf.WriteByte('\n')
+	}
+	buf.WriteByte('\n')
+	return sw.write(buf.String())
+}
+
+// Comment writes a comment, which clients ignore
+func (sw *Writer) Comment(text string) error {
+	return sw.write(": " + text + "\n\n")
+}
+
+// KeepAlive writes a comment whenever the stream was silent for Heartbeat, until ctx ends
+func (sw *Writer) KeepAlive(ctx context.Context) {
+	ticker := time.NewTicker(Heartbeat / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			sw.mu.Lock()
+			silent := time.Since(sw.lastWrite) >= Heartbeat
+			sw.mu.Unlock()
+			if silent && sw.Comment("ping") != nil {
+				return
+			}
+		}
+	}
+}
+
+func (sw *Writer) write(s string) error {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+	// Streams outlive the server's write timeout, so every write gets its own deadline
+	sw.controller.SetWriteDeadline(time.Now().Add(writeWait))
+	if _, err := sw.w.Write([]byte(s)); err != nil {
+		return err
+	}
+	sw.lastWrite = time.Now()
+	return sw.controller.Flush()
+}
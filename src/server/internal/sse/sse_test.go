@@ -0,0 +1,45 @@
+package sse
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRequested(t *testing.T) {
+	for _, tc := range []struct {
+		url, accept string
+		want        bool
+	}{
+		{"/logs/stream", "", false},
+		{"/logs/stream?transport=sse", "", true},
+		{"/logs/stream", "text/event-stream", true},
+		{"/logs/stream?transport=ws", "text/event-stream", false},
+	} {
+		r := httptest.NewRequest("GET", tc.url, nil)
+		if tc.accept != "" {
+			r.Header.Set("Accept", tc.accept)
+		}
+		if got := Requested(r); got != tc.want {
+			t.Errorf("Requested(%s, Accept %q) = %v, want %v", tc.url, tc.accept, got, tc.want)
+		}
+	}
+}
+
+func TestWriter(t *testing.T) {
+	rec := httptest.NewRecorder()
+	stream, err := Start(rec, 3*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stream.Event("7", "log", []byte("first\r\nsecond\n"))
+	stream.Comment("ping")
+
+	want := "retry: 3000\n\nid: 7\nevent: log\ndata: first\ndata: second\ndata: \n\n: ping\n\n"
+	if got := rec.Body.String(); got != want {
+		t.Errorf("stream = %q, want %q", got, want)
+	}
+	if got := rec.Header().Get("Content-Type"); got != "text/event-stream" {
+		t.Errorf("Content-Type = %s", got)
+	}
+}
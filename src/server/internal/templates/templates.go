@@ -0,0 +1,280 @@
+// Package templates renders resource templates: parameterized multi-document manifests,
+// such as a Deployment with its Service and Ingress, that users instantiate with values
+// instead of writing the YAML themselves.
+//
+// A template is a Go text/template. Parameters are read from .Values and the namespace the
+// template is instantiated in from .Namespace:
+//
+//	apiVersion: apps/v1
+//	kind: Deployment
+//	metadata:
+//	  name: {{ .Values.name }}
+//	spec:
+//	  replicas: {{ .Values.replicas }}
+//	  ...
+//	{{- if .Values.ingress }}
+//	---
+//	apiVersion: networking.k8s.io/v1
+//	kind: Ingress
+//	...
+//	{{- end }}
+//
+// Values are checked against the declared parameters before rendering, and referring to an
+// undeclared value fails the render.
+package templates
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// Parameter types
+const (
+	TypeString  = "string"
+	TypeNumber  = "number"
+	TypeBoolean = "boolean"
+)
+
+// maxValueLength bounds the length of a string value
+const maxValueLength = 1024
+
+// parameterName is the form of parameter names, so that they can be written .Values.name
+var parameterName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Parameter is a value a template is instantiated with
+type Parameter struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description,omitempty"`
+	Type        string      `json:"type,omitempty"` // string (the default), number or boolean
+	Default     interface{} `json:"default,omitempty"`
+	Required    bool        `json:"required,omitempty"`
+	Pattern     string      `json:"pattern,omitempty"` // regular expression string values must match in full
+	Options     []string    `json:"options,omitempty"` // the values allowed, for a choice
+}
+
+// funcs are the functions available to templates
+var funcs = template.FuncMap{
+	// quote writes a string as a double-quoted YAML scalar
+	"quote": func(v interface{}) string {
+		return strconv.Quote(fmt.Sprint(v))
+	},
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+// ValidateParameters checks the parameters of a template: valid and unique names, known
+// types, patterns that compile and defaults that are valid values
+func ValidateParameters(params []Parameter) error {
+	seen := map[string]bool{}
+	for i := range params {
+		p := &params[i]
+		if !parameterName.MatchString(p.Name) {
+			return fmt.Errorf("parameter %q: names must be letters, digits and underscores", p.Name)
+		}
+		if seen[p.Name] {
+			return fmt.Errorf("parameter %q is declared twice", p.Name)
+		}
+		seen[p.Name] = true
+		switch p.Type {
+		case "":
+			p.Type = TypeString
+		case TypeString, TypeNumber, TypeBoolean:
+		default:
+			return fmt.Errorf("parameter %s: unknown type %q", p.Name, p.Type)
+		}
+		if p.Pattern != "" {
+			if p.Type != TypeString {
+				return fmt.Errorf("parameter %s: only string parameters take a pattern", p.Name)
+			}
+			if _, err := regexp.Compile(p.Pattern); err != nil {
+				return fmt.Errorf("parameter %s: invalid pattern: %v", p.Name, err)
+			}
+		}
+		if p.Default != nil {
+			if _, err := p.value(p.Default); err != nil {
+				return fmt.Errorf("parameter %s: invalid default: %v", p.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// value converts a given value to the parameter type and checks it. Numbers and booleans
+// may be given as strings, as forms submit them.
+func (p *Parameter) value(v interface{}) (interface{}, error) {
+	switch p.Type {
+	case TypeNumber:
+		switch n := v.(type) {
+		case float64:
+			return number(n), nil
+		case int:
+			return int64(n), nil
+		case string:
+			f, err := strconv.ParseFloat(strings.TrimSpace(n), 64)
+			if err != nil {
+				return nil, fmt.Errorf("%q is not a number", n)
+			}
+			return number(f), nil
+		}
+		return nil, fmt.Errorf("%v is not a number", v)
+	case TypeBoolean:
+		switch b := v.(type) {
+		case bool:
+			return b, nil
+		case string:
+			parsed, err := strconv.ParseBool(strings.TrimSpace(b))
+			if err != nil {
+				return nil, fmt.Errorf("%q is not true or false", b)
+			}
+			return parsed, nil
+		}
+		return nil, fmt.Errorf("%v is not true or false", v)
+	}
+
+	s, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("%v is not a string", v)
+	}
+	// A line break would let a value write its own YAML
+	if strings.ContainsAny(s, "\r\n") {
+		return nil, fmt.Errorf("values cannot span lines")
+	}
+	if len(s) > maxValueLength {
+		return nil, fmt.Errorf("values are at most %d characters", maxValueLength)
+	}
+	if p.Pattern != "" && !regexp.MustCompile(`^(?:`+p.Pattern+`)$`).MatchString(s) {
+		return nil, fmt.Errorf("%q does not match %s", s, p.Pattern)
+	}
+	if len(p.Options) > 0 && !contains(p.Options, s) {
+		return nil, fmt.Errorf("%q is not one of %s", s, strings.Join(p.Options, ", "))
+	}
+	return s, nil
+}
+
+// number keeps whole numbers as integers, so that they render as 1000000 rather than 1e+06
+func number(f float64) interface{} {
+	if f == math.Trunc(f) && math.Abs(f) < 1<<53 {
+		return int64(f)
+	}
+	return f
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Values checks given values against the parameters of a template and returns the values
+// to render it with: the given ones converted to their type, then the defaults. All the
+// problems found are reported together.
+func Values(params []Parameter, given map[string]interface{}) (map[string]interface{}, error) {
+	values := make(map[string]interface{}, len(params))
+	declared := make(map[string]bool, len(params))
+	var errs []error
+	for i := range params {
+		p := &params[i]
+		declared[p.Name] = true
+		v, ok := given[p.Name]
+		if !ok || v == nil || v == "" {
+			switch {
+			case p.Required:
+				errs = append(errs, fmt.Errorf("%s is required", p.Name))
+			case p.Default != nil:
+				values[p.Name], _ = p.value(p.Default)
+			default:
+				values[p.Name] = p.zero()
+			}
+			continue
+		}
+		converted, err := p.value(v)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", p.Name, err))
+			continue
+		}
+		values[p.Name] = converted
+	}
+	var unknown []string
+	for name := range given {
+		if !declared[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	for _, name := range unknown {
+		errs = append(errs, fmt.Errorf("unknown parameter %s", name))
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return values, nil
+}
+
+// zero is the value of an optional parameter left out without a default
+func (p *Parameter) zero() interface{} {
+	switch p.Type {
+	case TypeNumber:
+		return int64(0)
+	case TypeBoolean:
+		return false
+	}
+	return ""
+}
+
+// sample is a value of the parameter type, for checking a template
+func (p *Parameter) sample() interface{} {
+	if p.Default != nil {
+		v, _ := p.value(p.Default)
+		return v
+	}
+	if len(p.Options) > 0 {
+		return p.Options[0]
+	}
+	switch p.Type {
+	case TypeNumber:
+		return int64(1)
+	case TypeBoolean:
+		return true
+	}
+	return "sample"
+}
+
+// Render instantiates a template in a namespace with values checked by Values
+func Render(text string, namespace string, values map[string]interface{}) ([]byte, error) {
+	tmpl, err := template.New("template").Funcs(funcs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %v", err)
+	}
+	var buf bytes.Buffer
+	data := map[string]interface{}{"Values": values, "Namespace": namespace}
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("template failed: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Check renders a template with sample values for its parameters (their default, first
+// option or a value of their type), to catch syntax errors and undeclared values when it
+// is saved
+func Check(text string, params []Parameter) ([]byte, error) {
+	values := make(map[string]interface{}, len(params))
+	for i := range params {
+		values[params[i].Name] = params[i].sample()
+	}
+	return Render(text, "default", values)
+}
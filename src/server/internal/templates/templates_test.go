@@ -0,0 +1,53 @@
+package templates
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValuesAndRender(t *testing.T) {
+	params := []Parameter{
+		{Name: "name", Required: true},
+		{Name: "replicas", Type: TypeNumber, Default: float64(1)},
+		{Name: "size", Options: []string{"small", "large"}, Default: "small"},
+		{Name: "public", Type: TypeBoolean},
+	}
+	if err := ValidateParameters(params); err != nil {
+		t.Fatalf("ValidateParameters: %v", err)
+	}
+
+	values, err := Values(params, map[string]interface{}{"name": "api", "replicas": "1000000", "public": "true"})
+	if err != nil {
+		t.Fatalf("Values: %v", err)
+	}
+	out, err := Render("{{ .Namespace }}/{{ .Values.name }} {{ .Values.replicas }} {{ .Values.size }} {{ .Values.public }}", "shop", values)
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if got, want := string(out), "shop/api 1000000 small true"; got != want {
+		t.Errorf("Render = %q, want %q", got, want)
+	}
+
+	_, err = Values(params, map[string]interface{}{"replicas": "two", "size": "huge", "extra": 1})
+	if err == nil {
+		t.Fatal("Values accepted invalid values")
+	}
+	for _, want := range []string{"name is required", `replicas: "two" is not a number`, `size: "huge" is not one of`, "unknown parameter extra"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Values error %q does not report %q", err, want)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	params := []Parameter{{Name: "name"}}
+	if _, err := Check("name: {{ .Values.name }}", params); err != nil {
+		t.Errorf("Check: %v", err)
+	}
+	if _, err := Check("name: {{ .Values.other }}", params); err == nil {
+		t.Error("Check accepted an undeclared value")
+	}
+	if err := ValidateParameters([]Parameter{{Name: "port", Type: TypeNumber, Pattern: "[0-9]+"}}); err == nil {
+		t.Error("ValidateParameters accepted a pattern on a number")
+	}
+}
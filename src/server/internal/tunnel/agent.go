@@ -0,0 +1,226 @@
+package tunnel
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+
+	// maxAgentBackoff bounds the delay between reconnects of the agent
+	maxAgentBackoff = 30 * time.Second
+	// tokenCheckInterval is how often the agent looks for a rotated ServiceAccount token
+	tokenCheckInterval = time.Minute
+)
+
+// AgentConfig configures an agent
+type AgentConfig struct {
+	URL     string // base URL of kubelens, e.g. https://kubelens.example.com
+	Cluster string // name of the agent cluster in kubelens
+	Token   string // agent token shown when the cluster was added
+
+	// Server is the API server URL sent to kubelens; APIServerAddress is where the agent
+	// connects to it. They default to the in-cluster service.
+	Server           string
+	APIServerAddress string
+	// TokenFile and CAFile are the ServiceAccount credentials forwarded to kubelens
+	TokenFile string
+	CAFile    string
+
+	Version string
+}
+
+// Agent keeps a tunnel open from a cluster to kubelens
+type Agent struct {
+	cfg    AgentConfig
+	dialer *websocket.Dialer
+}
+
+// NewAgent creates an agent, filling in the in-cluster defaults
+func NewAgent(cfg AgentConfig) (*Agent, error) {
+	if cfg.URL == "" || cfg.Cluster == "" || cfg.Token == "" {
+		return nil, fmt.Errorf("kubelens URL, cluster and agent token are required")
+	}
+	u, err := url.Parse(cfg.URL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") {
+		return nil, fmt.Errorf("invalid kubelens URL %q", cfg.URL)
+	}
+	if cfg.Server == "" {
+		cfg.Server = "https://kubernetes.default.svc"
+	}
+	if cfg.APIServerAddress == "" {
+		host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+		if host == "" || port == "" {
+			return nil, fmt.Errorf("not running in a Kubernetes pod: set the API server address")
+		}
+		cfg.APIServerAddress = net.JoinHostPort(host, port)
+	}
+	if cfg.TokenFile == "" {
+		cfg.TokenFile = serviceAccountDir + "/token"
+	}
+	if cfg.CAFile == "" {
+		cfg.CAFile = serviceAccountDir + "/ca.crt"
+	}
+	return &Agent{
+		cfg:    cfg,
+		dialer: &websocket.Dialer{Proxy: http.ProxyFromEnvironment, HandshakeTimeout: 30 * time.Second},
+	}, nil
+}
+
+// Run keeps the control connection open until ctx is done, reconnecting with backoff
+func (a *Agent) Run(ctx context.Context) error {
+	backoff := time.Second
+	for {
+		started := time.Now()
+		err := a.session(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if time.Since(started) > time.Minute {
+			backoff = time.Second
+		}
+		log.Warnf("Tunnel to kubelens closed: %v; reconnecting in %v", err, backoff)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return nil
+		}
+		if backoff *= 2; backoff > maxAgentBackoff {
+			backoff = maxAgentBackoff
+		}
+	}
+}
+
+// session runs one control connection
+func (a *Agent) session(ctx context.Context) error {
+	token, err := os.ReadFile(a.cfg.TokenFile)
+	if err != nil {
+		return fmt.Errorf("failed to read ServiceAccount token: %w", err)
+	}
+	ca, err := os.ReadFile(a.cfg.CAFile)
+	if err != nil {
+		return fmt.Errorf("failed to read ServiceAccount CA: %w", err)
+	}
+
+	ws, err := a.dial(ctx, ConnectPath, "")
+	if err != nil {
+		return err
+	}
+	defer ws.Close()
+
+	var wmu sync.Mutex
+	send := func(msg Message) error {
+		wmu.Lock()
+		defer wmu.Unlock()
+		ws.SetWriteDeadline(time.Now().Add(writeWait))
+		return ws.WriteJSON(msg)
+	}
+	current := strings.TrimSpace(string(token))
+	if err := send(Message{
+		Type:    MessageHello,
+		Server:  a.cfg.Server,
+		CA:      base64.StdEncoding.EncodeToString(ca),
+		Token:   current,
+		Version: a.cfg.Version,
+	}); err != nil {
+		return err
+	}
+	log.Infof("Tunnel to %s open for cluster %s", a.cfg.URL, a.cfg.Cluster)
+
+	// Forward rotated ServiceAccount tokens, and close the connection when ctx is done
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(tokenCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				token, err := os.ReadFile(a.cfg.TokenFile)
+				if err != nil || strings.TrimSpace(string(token)) == current {
+					continue
+				}
+				current = strings.TrimSpace(string(token))
+				if err := send(Message{Type: MessageCredentials, Token: current}); err != nil {
+					ws.Close()
+					return
+				}
+			case <-ctx.Done():
+				ws.Close()
+				return
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	ws.SetPingHandler(func(data string) error {
+		ws.SetReadDeadline(time.Now().Add(pongWait))
+		wmu.Lock()
+		defer wmu.Unlock()
+		return ws.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(writeWait))
+	})
+	for {
+		ws.SetReadDeadline(time.Now().Add(pongWait))
+		var msg Message
+		if err := ws.ReadJSON(&msg); err != nil {
+			return err
+		}
+		if msg.Type == MessageDial && msg.ID != "" {
+			go a.relay(ctx, msg.ID)
+		}
+	}
+}
+
+// relay connects to the API server and opens the data connection of a dial request
+func (a *Agent) relay(ctx context.Context, id string) {
+	upstream, err := net.DialTimeout("tcp", a.cfg.APIServerAddress, 10*time.Second)
+	if err != nil {
+		log.Warnf("Failed to connect to the API server: %v", err)
+		return
+	}
+	ws, err := a.dial(ctx, DataPath, id)
+	if err != nil {
+		log.Warnf("Failed to open data connection: %v", err)
+		upstream.Close()
+		return
+	}
+	pipe(NewConn(ws), upstream)
+}
+
+// dial opens a WebSocket connection to kubelens
+func (a *Agent) dial(ctx context.Context, path, id string) (*websocket.Conn, error) {
+	u, _ := url.Parse(strings.TrimSuffix(a.cfg.URL, "/") + path)
+	if u.Scheme == "https" {
+		u.Scheme = "wss"
+	} else {
+		u.Scheme = "ws"
+	}
+	if id != "" {
+		u.RawQuery = url.Values{"id": {id}}.Encode()
+	}
+	header := http.Header{}
+	header.Set("Authorization", "Bearer "+a.cfg.Token)
+	header.Set(ClusterHeader, a.cfg.Cluster)
+
+	ws, resp, err := a.dialer.DialContext(ctx, u.String(), header)
+	if err != nil {
+		if resp != nil {
+			return nil, fmt.Errorf("kubelens refused the connection: %s", resp.Status)
+		}
+		return nil, err
+	}
+	return ws, nil
+}
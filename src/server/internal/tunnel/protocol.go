@@ -0,0 +1,67 @@
+// Package tunnel connects kubelens to clusters it cannot reach. An agent running in the
+// cluster dials out to kubelens over WebSocket and keeps a control connection open; for
+// every connection kubelens makes to the API server, the agent opens a data connection
+// and relays its bytes to the API server. TLS runs end to end between kubelens and the
+// API server, so the agent never sees the traffic in clear.
+package tunnel
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+const (
+	// ConnectPath is where agents open their control connection
+	ConnectPath = "/api/v1/agent/connect"
+	// DataPath is where agents open a data connection for a dial request
+	DataPath = "/api/v1/agent/tunnel"
+	// ClusterHeader names the cluster an agent serves; the agent token goes in the
+	// Authorization header as a bearer token
+	ClusterHeader = "X-Kubelens-Cluster"
+
+	// AgentTokenPrefix identifies agent tokens
+	AgentTokenPrefix = "kla_"
+
+	// Control message types
+	MessageHello       = "hello"       // agent → kubelens: API server address and credentials
+	MessageCredentials = "credentials" // agent → kubelens: a rotated ServiceAccount token
+	MessageDial        = "dial"        // kubelens → agent: open a data connection
+
+	// pingPeriod is how often each side pings the other on the control connection
+	pingPeriod = 30 * time.Second
+	// pongWait is how long a side waits for any message before giving up on the peer
+	pongWait = 3 * pingPeriod
+	// writeWait bounds a control message write
+	writeWait = 10 * time.Second
+)
+
+// Message is a control message. The API server fields are set on hello and credentials
+// messages; ID is set on dial messages and echoed by the data connection.
+type Message struct {
+	Type string `json:"type"`
+
+	Server  string `json:"server,omitempty"` // API server URL as seen from the cluster
+	CA      string `json:"ca,omitempty"`     // base64 CA bundle of the API server
+	Token   string `json:"token,omitempty"`  // ServiceAccount token of the agent
+	Version string `json:"version,omitempty"`
+
+	ID string `json:"id,omitempty"`
+}
+
+// GenerateAgentToken returns a random agent token and the hash stored for it
+func GenerateAgentToken() (string, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	token := AgentTokenPrefix + hex.EncodeToString(b)
+	return token, HashAgentToken(token), nil
+}
+
+// HashAgentToken hashes an agent token for storage and comparison
+func HashAgentToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
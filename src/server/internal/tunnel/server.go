@@ -0,0 +1,345 @@
+package tunnel
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/oauth2"
+
+	"github.com/sonnguyen/kubelens/internal/cluster"
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+// dialTimeout bounds how long a connection waits for the agent's data connection
+const dialTimeout = 15 * time.Second
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  32 * 1024,
+	WriteBufferSize: 32 * 1024,
+	// Agents are not browsers; they authenticate with their token
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// Server accepts agent connections and routes API server traffic of their clusters
+// through them. Each connected agent gets a listener on the loopback interface whose
+// connections are relayed to its API server, so every client-go feature (watches, exec,
+// port-forward) works through the tunnel.
+type Server struct {
+	db      *db.DB
+	manager *cluster.Manager
+
+	mu       sync.Mutex
+	sessions map[string]*session // by cluster
+}
+
+// session is the control connection of a connected agent
+type session struct {
+	cluster    string
+	ws         *websocket.Conn
+	listener   net.Listener
+	serverName string // API server host the certificate is checked against
+	ca         []byte
+
+	wmu sync.Mutex // serializes control writes
+
+	mu      sync.Mutex
+	token   string
+	pending map[string]chan net.Conn // dial requests by ID
+}
+
+// NewServer creates a tunnel server and makes it connect the manager's agent clusters
+func NewServer(database *db.DB, manager *cluster.Manager) *Server {
+	s := &Server{
+		db:       database,
+		manager:  manager,
+		sessions: map[string]*session{},
+	}
+	manager.SetAgentTunnels(s)
+	return s
+}
+
+// Connect serves the control connection of an agent
+func (s *Server) Connect(c *gin.Context) {
+	name, ok := s.authenticate(c)
+	if !ok {
+		return
+	}
+	ws, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		log.Errorf("Failed to upgrade agent connection of cluster %s: %v", name, err)
+		return
+	}
+
+	// The agent introduces its API server first
+	ws.SetReadDeadline(time.Now().Add(pongWait))
+	var hello Message
+	if err := ws.ReadJSON(&hello); err != nil || hello.Type != MessageHello {
+		log.Warnf("Agent of cluster %s sent no hello: %v", name, err)
+		ws.Close()
+		return
+	}
+	sess, err := newSession(name, ws, &hello)
+	if err != nil {
+		log.Warnf("Agent of cluster %s sent an invalid hello: %v", name, err)
+		ws.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.ClosePolicyViolation, err.Error()), time.Now().Add(writeWait))
+		ws.Close()
+		return
+	}
+	go s.accept(sess)
+
+	s.mu.Lock()
+	previous := s.sessions[name]
+	s.sessions[name] = sess
+	s.mu.Unlock()
+	if previous != nil {
+		previous.close()
+	}
+	log.Infof("Agent of cluster %s connected from %s (version %s)", name, c.ClientIP(), hello.Version)
+
+	status := "connected"
+	if err := s.ConnectCluster(name); err != nil {
+		log.Warnf("Failed to connect cluster %s through its agent: %v", name, err)
+		status = "error"
+	}
+	s.db.UpdateClusterStatus(name, status)
+
+	s.serve(sess)
+
+	s.mu.Lock()
+	current := s.sessions[name] == sess
+	if current {
+		delete(s.sessions, name)
+	}
+	s.mu.Unlock()
+	sess.close()
+	if current {
+		s.db.UpdateClusterStatus(name, "disconnected")
+		log.Infof("Agent of cluster %s disconnected", name)
+	}
+}
+
+// serve reads control messages until the agent goes away, pinging it meanwhile
+func (s *Server) serve(sess *session) {
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(pingPeriod)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				sess.wmu.Lock()
+				err := sess.ws.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeWait))
+				sess.wmu.Unlock()
+				if err != nil {
+					return
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	sess.ws.SetPongHandler(func(string) error {
+		return sess.ws.SetReadDeadline(time.Now().Add(pongWait))
+	})
+	for {
+		sess.ws.SetReadDeadline(time.Now().Add(pongWait))
+		var msg Message
+		if err := sess.ws.ReadJSON(&msg); err != nil {
+			return
+		}
+		if msg.Type == MessageCredentials && msg.Token != "" {
+			sess.mu.Lock()
+			sess.token = msg.Token
+			sess.mu.Unlock()
+			log.Debugf("Agent of cluster %s rotated its token", sess.cluster)
+		}
+	}
+}
+
+// Tunnel serves a data connection opened by an agent for a dial request
+func (s *Server) Tunnel(c *gin.Context) {
+	name, ok := s.authenticate(c)
+	if !ok {
+		return
+	}
+	s.mu.Lock()
+	sess := s.sessions[name]
+	s.mu.Unlock()
+	if sess == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "agent is not connected"})
+		return
+	}
+	id := c.Query("id")
+	sess.mu.Lock()
+	waiting, ok := sess.pending[id]
+	delete(sess.pending, id)
+	sess.mu.Unlock()
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "unknown dial request"})
+		return
+	}
+
+	ws, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		log.Errorf("Failed to upgrade tunnel connection of cluster %s: %v", name, err)
+		close(waiting)
+		return
+	}
+	waiting <- NewConn(ws)
+}
+
+// ConnectCluster adds a cluster to the manager through the tunnel of its agent
+func (s *Server) ConnectCluster(name string) error {
+	s.mu.Lock()
+	sess := s.sessions[name]
+	s.mu.Unlock()
+	if sess == nil {
+		return fmt.Errorf("the agent of cluster %s is not connected", name)
+	}
+	return s.manager.AddClusterThroughTunnel(name, "https://"+sess.listener.Addr().String(), sess.serverName, sess.ca, sess)
+}
+
+// authenticate checks the agent token against the enabled agent cluster it names
+func (s *Server) authenticate(c *gin.Context) (string, bool) {
+	name := c.GetHeader(ClusterHeader)
+	token := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if name == "" || !strings.HasPrefix(token, AgentTokenPrefix) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "agent token and cluster are required"})
+		return "", false
+	}
+
+	dbCluster, err := s.db.GetCluster(name)
+	if err != nil || dbCluster.AuthType != string(db.AuthTypeAgent) || !dbCluster.Enabled {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid agent token"})
+		return "", false
+	}
+	var cfg db.AgentAuthConfig
+	if err := json.Unmarshal(dbCluster.AuthConfig, &cfg); err != nil || cfg.TokenHash == "" ||
+		subtle.ConstantTimeCompare([]byte(HashAgentToken(token)), []byte(cfg.TokenHash)) != 1 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid agent token"})
+		return "", false
+	}
+	return name, true
+}
+
+// accept relays the connections made to the session's listener through the agent
+func (s *Server) accept(sess *session) {
+	for {
+		conn, err := sess.listener.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			remote, err := sess.dial()
+			if err != nil {
+				log.Warnf("Failed to open tunnel to cluster %s: %v", sess.cluster, err)
+				conn.Close()
+				return
+			}
+			pipe(conn, remote)
+		}()
+	}
+}
+
+func newSession(name string, ws *websocket.Conn, hello *Message) (*session, error) {
+	u, err := url.Parse(hello.Server)
+	if err != nil || u.Scheme != "https" || u.Hostname() == "" {
+		return nil, fmt.Errorf("server must be an https URL")
+	}
+	ca, err := base64.StdEncoding.DecodeString(hello.CA)
+	if err != nil || len(ca) == 0 {
+		return nil, fmt.Errorf("ca must be base64 encoded")
+	}
+	if hello.Token == "" {
+		return nil, fmt.Errorf("token is required")
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	return &session{
+		cluster:    name,
+		ws:         ws,
+		listener:   listener,
+		serverName: u.Hostname(),
+		ca:         ca,
+		token:      hello.Token,
+		pending:    map[string]chan net.Conn{},
+	}, nil
+}
+
+// Token returns the ServiceAccount token the agent last sent
+func (sess *session) Token() (*oauth2.Token, error) {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	return &oauth2.Token{AccessToken: sess.token, TokenType: "Bearer"}, nil
+}
+
+// dial asks the agent for a data connection and waits for it
+func (sess *session) dial() (net.Conn, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	id := hex.EncodeToString(b)
+	waiting := make(chan net.Conn, 1)
+	sess.mu.Lock()
+	sess.pending[id] = waiting
+	sess.mu.Unlock()
+
+	sess.wmu.Lock()
+	sess.ws.SetWriteDeadline(time.Now().Add(writeWait))
+	err := sess.ws.WriteJSON(Message{Type: MessageDial, ID: id})
+	sess.wmu.Unlock()
+	if err != nil {
+		sess.cancelDial(id)
+		return nil, fmt.Errorf("agent is not reachable: %w", err)
+	}
+
+	select {
+	case conn, ok := <-waiting:
+		if !ok {
+			return nil, fmt.Errorf("agent failed to open a data connection")
+		}
+		return conn, nil
+	case <-time.After(dialTimeout):
+		if !sess.cancelDial(id) {
+			// The data connection arrived meanwhile
+			if conn, ok := <-waiting; ok {
+				conn.Close()
+			}
+		}
+		return nil, fmt.Errorf("agent did not open a data connection within %v", dialTimeout)
+	}
+}
+
+// cancelDial forgets a dial request, reporting whether it was still waiting. A request
+// that is no longer waiting has been claimed by a data connection.
+func (sess *session) cancelDial(id string) bool {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	_, ok := sess.pending[id]
+	delete(sess.pending, id)
+	return ok
+}
+
+// close stops relaying new connections and drops the control connection
+func (sess *session) close() {
+	sess.listener.Close()
+	sess.ws.Close()
+}
@@ -0,0 +1,108 @@
+package tunnel
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/sonnguyen/kubelens/internal/cluster"
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+func TestAgentTunnel(t *testing.T) {
+	// The API server of the private cluster, reached only by the agent
+	var mu sync.Mutex
+	var authorization string
+	apiServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		authorization = r.Header.Get("Authorization")
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"major":"1","minor":"30","gitVersion":"v1.30.2"}`))
+	}))
+	defer apiServer.Close()
+
+	dir := t.TempDir()
+	tokenFile, caFile := filepath.Join(dir, "token"), filepath.Join(dir, "ca.crt")
+	os.WriteFile(tokenFile, []byte("sa-token\n"), 0600)
+	os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: apiServer.Certificate().Raw}), 0600)
+
+	database, err := db.New(filepath.Join(dir, "kubelens.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	token, tokenHash, err := GenerateAgentToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := database.CreateCluster(&db.Cluster{
+		Name: "edge", AuthType: "agent", AuthConfig: db.JSON(`{"token_hash":"` + tokenHash + `"}`), Enabled: true,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	manager := cluster.NewManager(database)
+	server := NewServer(database, manager)
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET(ConnectPath, server.Connect)
+	router.GET(DataPath, server.Tunnel)
+	kubelens := httptest.NewServer(router)
+	defer kubelens.Close()
+
+	if err := manager.AddClusterFromAgent("edge"); err == nil {
+		t.Fatal("cluster connected before its agent")
+	}
+
+	// A wrong token is refused
+	req, _ := http.NewRequest(http.MethodGet, kubelens.URL+ConnectPath, nil)
+	req.Header.Set("Authorization", "Bearer "+AgentTokenPrefix+"wrong")
+	req.Header.Set(ClusterHeader, "edge")
+	if resp, err := http.DefaultClient.Do(req); err != nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("wrong token: %v %v, want 401", resp, err)
+	}
+
+	agent, err := NewAgent(AgentConfig{
+		URL: kubelens.URL, Cluster: "edge", Token: token,
+		Server: "https://example.com", APIServerAddress: apiServer.Listener.Addr().String(),
+		TokenFile: tokenFile, CAFile: caFile,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go agent.Run(ctx)
+
+	var client kubernetes.Interface
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(50 * time.Millisecond) {
+		if client, err = manager.GetClient("edge"); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		t.Fatalf("cluster not connected through the agent: %v", err)
+	}
+	version, err := client.Discovery().ServerVersion()
+	if err != nil || version.GitVersion != "v1.30.2" {
+		t.Fatalf("version = %v, %v", version, err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if authorization != "Bearer sa-token" {
+		t.Errorf("API server saw Authorization %q, want the agent's ServiceAccount token", authorization)
+	}
+	if stored, _ := database.GetCluster("edge"); stored.Status != "connected" {
+		t.Errorf("status = %q, want connected", stored.Status)
+	}
+}
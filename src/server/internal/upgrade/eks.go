@@ -0,0 +1,162 @@
+package upgrade
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/sonnguyen/kubelens/internal/cluster"
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+const (
+	// eksNodegroupLabel names the EKS managed node group of a node
+	eksNodegroupLabel = "eks.amazonaws.com/nodegroup"
+	// defaultEKSPollInterval is how often a node group update is checked
+	defaultEKSPollInterval = 30 * time.Second
+)
+
+// eksAPI is the part of cluster.EKSAPI the node group upgrader uses
+type eksAPI interface {
+	Do(ctx context.Context, method, path string, in, out interface{}) error
+}
+
+// eksUpdate is an update of an EKS node group
+type eksUpdate struct {
+	ID     string `json:"id"`
+	Status string `json:"status"` // InProgress, Failed, Cancelled, Successful
+	Errors []struct {
+		ErrorMessage string `json:"errorMessage"`
+	} `json:"errors"`
+}
+
+// EKSNodeGroupUpgrader upgrades the EKS managed node groups of drained nodes to the
+// target version and waits for the update, for clusters added with the eks auth type.
+// EKS replaces every node of a group, so a group already at the target version (upgraded
+// for an earlier batch) is left as is. Nodes of other clusters, and self-managed or
+// Karpenter nodes, are not upgraded: the operator replaces them out of band.
+type EKSNodeGroupUpgrader struct {
+	db             *db.DB
+	clusterManager *cluster.Manager
+	newAPI         func(cfg db.EKSAuthConfig) eksAPI
+	pollInterval   time.Duration
+}
+
+// NewEKSNodeGroupUpgrader creates a node pool upgrader for EKS managed node groups
+func NewEKSNodeGroupUpgrader(database *db.DB, clusterManager *cluster.Manager) *EKSNodeGroupUpgrader {
+	return &EKSNodeGroupUpgrader{
+		db:             database,
+		clusterManager: clusterManager,
+		newAPI:         func(cfg db.EKSAuthConfig) eksAPI { return cluster.NewEKSAPI(cfg) },
+		pollInterval:   defaultEKSPollInterval,
+	}
+}
+
+// UpgradeNodes upgrades the managed node groups of the given nodes
+func (u *EKSNodeGroupUpgrader) UpgradeNodes(ctx context.Context, clusterName string, nodes []string, targetVersion string) error {
+	dbCluster, err := u.db.GetCluster(clusterName)
+	if err != nil {
+		return err
+	}
+	if dbCluster.AuthType != string(db.AuthTypeEKS) {
+		return nil
+	}
+	var cfg db.EKSAuthConfig
+	if err := json.Unmarshal(dbCluster.AuthConfig, &cfg); err != nil {
+		return fmt.Errorf("invalid eks auth config: %w", err)
+	}
+	major, minor, err := ParseMinorVersion(targetVersion)
+	if err != nil {
+		return err
+	}
+	version := fmt.Sprintf("%d.%d", major, minor)
+
+	client, err := u.clusterManager.GetClient(clusterName)
+	if err != nil {
+		return err
+	}
+	groups := map[string]bool{}
+	for _, name := range nodes {
+		node, err := client.CoreV1().Nodes().Get(ctx, name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if group := node.Labels[eksNodegroupLabel]; group != "" {
+			groups[group] = true
+		}
+	}
+	names := make([]string, 0, len(groups))
+	for group := range groups {
+		names = append(names, group)
+	}
+	sort.Strings(names)
+
+	api := u.newAPI(cfg)
+	for _, group := range names {
+		if err := u.upgradeNodegroup(ctx, api, cfg.ClusterName, group, version); err != nil {
+			return fmt.Errorf("node group %s: %w", group, err)
+		}
+	}
+	return nil
+}
+
+// upgradeNodegroup updates a node group to a Kubernetes version and waits until EKS has
+// replaced its nodes
+func (u *EKSNodeGroupUpgrader) upgradeNodegroup(ctx context.Context, api eksAPI, eksCluster, group, version string) error {
+	path := "/clusters/" + url.PathEscape(eksCluster) + "/node-groups/" + url.PathEscape(group)
+
+	var described struct {
+		Nodegroup struct {
+			Version string `json:"version"`
+		} `json:"nodegroup"`
+	}
+	if err := api.Do(ctx, http.MethodGet, path, nil, &described); err != nil {
+		return err
+	}
+	if described.Nodegroup.Version == version {
+		return nil
+	}
+
+	var result struct {
+		Update eksUpdate `json:"update"`
+	}
+	if err := api.Do(ctx, http.MethodPost, path+"/update-version", map[string]string{"version": version}, &result); err != nil {
+		return err
+	}
+	updateID := result.Update.ID
+	log.Infof("EKS node group %s of cluster %s updating to %s (update %s)", group, eksCluster, version, updateID)
+
+	for {
+		switch result.Update.Status {
+		case "Successful":
+			return nil
+		case "Failed", "Cancelled":
+			messages := make([]string, 0, len(result.Update.Errors))
+			for _, e := range result.Update.Errors {
+				messages = append(messages, e.ErrorMessage)
+			}
+			return fmt.Errorf("update %s %s: %s", updateID, strings.ToLower(result.Update.Status), strings.Join(messages, "; "))
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(u.pollInterval):
+		}
+		if err := api.Do(ctx, http.MethodGet, path+"/updates/"+url.PathEscape(updateID), nil, &result); err != nil {
+			return err
+		}
+	}
+}
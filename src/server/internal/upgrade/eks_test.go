@@ -0,0 +1,88 @@
+package upgrade
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/sonnguyen/kubelens/internal/cluster"
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+// fakeEKS answers the node group calls of the EKS API, "general" being at 1.29 and "gpu"
+// already at 1.30
+type fakeEKS struct {
+	calls []string
+}
+
+func (f *fakeEKS) Do(ctx context.Context, method, path string, in, out interface{}) error {
+	call := method + " " + path
+	if in != nil {
+		body, _ := json.Marshal(in)
+		call += " " + string(body)
+	}
+	f.calls = append(f.calls, call)
+
+	responses := map[string]string{
+		"/clusters/prod/node-groups/general":                `{"nodegroup":{"version":"1.29"}}`,
+		"/clusters/prod/node-groups/gpu":                    `{"nodegroup":{"version":"1.30"}}`,
+		"/clusters/prod/node-groups/general/update-version": `{"update":{"id":"u-1","status":"InProgress"}}`,
+		"/clusters/prod/node-groups/general/updates/u-1":    `{"update":{"id":"u-1","status":"Successful"}}`,
+	}
+	resp, ok := responses[path]
+	if !ok {
+		return fmt.Errorf("unexpected call %s", call)
+	}
+	return json.Unmarshal([]byte(resp), out)
+}
+
+func TestEKSNodeGroupUpgrader(t *testing.T) {
+	database, err := db.New(filepath.Join(t.TempDir(), "kubelens.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	if err := database.CreateCluster(&db.Cluster{Name: "prod", AuthType: string(db.AuthTypeEKS),
+		AuthConfig: db.JSON(`{"cluster_name":"prod","region":"eu-west-1"}`), Enabled: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	node := func(name, group string) *corev1.Node {
+		n := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{}}}
+		if group != "" {
+			n.Labels[eksNodegroupLabel] = group
+		}
+		return n
+	}
+	manager := cluster.NewManager(database)
+	manager.AddClusterFromClients("prod", fake.NewSimpleClientset(
+		node("ip-10-0-1-1", "general"), node("ip-10-0-1-2", "general"), node("ip-10-0-2-1", "gpu"), node("ip-10-0-3-1", ""),
+	), nil)
+
+	api := &fakeEKS{}
+	u := NewEKSNodeGroupUpgrader(database, manager)
+	u.newAPI = func(cfg db.EKSAuthConfig) eksAPI { return api }
+	u.pollInterval = 0
+
+	// Self-managed nodes and nodes already replaced are skipped
+	nodes := []string{"ip-10-0-1-1", "ip-10-0-1-2", "ip-10-0-2-1", "ip-10-0-3-1", "ip-10-0-9-9"}
+	if err := u.UpgradeNodes(context.Background(), "prod", nodes, "v1.30.2"); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"GET /clusters/prod/node-groups/general",
+		`POST /clusters/prod/node-groups/general/update-version {"version":"1.30"}`,
+		"GET /clusters/prod/node-groups/general/updates/u-1",
+		"GET /clusters/prod/node-groups/gpu",
+	}
+	if !reflect.DeepEqual(api.calls, want) {
+		t.Errorf("calls = %q, want %q", api.calls, want)
+	}
+}
@@ -0,0 +1,277 @@
+package upgrade
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/sonnguyen/kubelens/internal/audit"
+	"github.com/sonnguyen/kubelens/internal/auth"
+	"github.com/sonnguyen/kubelens/internal/cluster"
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+// Handler handles cluster upgrade API requests
+type Handler struct {
+	db             *db.DB
+	clusterManager *cluster.Manager
+	runner         *Runner
+}
+
+// NewHandler creates a new upgrade handler
+func NewHandler(database *db.DB, clusterManager *cluster.Manager, runner *Runner) *Handler {
+	return &Handler{
+		db:             database,
+		clusterManager: clusterManager,
+		runner:         runner,
+	}
+}
+
+// PlanRequest is the body for pre-checks and plan creation
+type PlanRequest struct {
+	Cluster             string `json:"cluster" binding:"required"`
+	TargetVersion       string `json:"target_version" binding:"required"`
+	BatchSize           int    `json:"batch_size"`
+	PauseBetweenBatches *bool  `json:"pause_between_batches"`
+	Force               bool   `json:"force"` // create the plan even if pre-checks fail
+}
+
+// prepare builds node batches for a request and runs the pre-checks against them, if the
+// caller's scope allows the action on the nodes of the cluster
+func (h *Handler) prepare(c *gin.Context, req *PlanRequest, action string) ([][]string, []PreCheck, string, bool) {
+	if !auth.CheckClusterScope(c, req.Cluster, "nodes", "", action) {
+		return nil, nil, "", false
+	}
+	client, err := h.clusterManager.GetClient(req.Cluster)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return nil, nil, "", false
+	}
+
+	nodes, err := client.CoreV1().Nodes().List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		log.Errorf("Failed to list nodes: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, nil, "", false
+	}
+
+	batches := BuildBatches(nodes.Items, req.BatchSize)
+	checks, currentVersion := RunPreChecks(context.Background(), client, req.TargetVersion, batches)
+	return batches, checks, currentVersion, true
+}
+
+// RunPreChecks handles POST /api/v1/upgrade/prechecks
+func (h *Handler) RunPreChecks(c *gin.Context) {
+	var req PlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	batches, checks, currentVersion, ok := h.prepare(c, &req, "read")
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"current_version": currentVersion,
+		"target_version":  req.TargetVersion,
+		"batches":         batches,
+		"checks":          checks,
+		"ready":           !HasFailures(checks),
+	})
+}
+
+// CreatePlan handles POST /api/v1/upgrade/plans
+func (h *Handler) CreatePlan(c *gin.Context) {
+	var req PlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.BatchSize < 1 {
+		req.BatchSize = 1
+	}
+
+	batches, checks, currentVersion, ok := h.prepare(c, &req, "update")
+	if !ok {
+		return
+	}
+	if len(batches) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cluster has no worker nodes to upgrade"})
+		return
+	}
+	if HasFailures(checks) && !req.Force {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":  "pre-checks failed; resolve them or set force to create the plan anyway",
+			"checks": checks,
+		})
+		return
+	}
+
+	pause := true
+	if req.PauseBetweenBatches != nil {
+		pause = *req.PauseBetweenBatches
+	}
+
+	plan := &db.UpgradePlan{
+		ClusterName:         req.Cluster,
+		CurrentVersion:      currentVersion,
+		TargetVersion:       req.TargetVersion,
+		BatchSize:           req.BatchSize,
+		PauseBetweenBatches: pause,
+		Status:              StatusPending,
+		Batches:             marshalJSON(batches),
+		PreChecks:           marshalJSON(checks),
+		Progress:            marshalJSON([]NodeProgress{}),
+		CreatedBy:           uint(c.GetInt("user_id")),
+	}
+	if err := h.db.CreateUpgradePlan(plan); err != nil {
+		log.Errorf("Failed to create upgrade plan: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create upgrade plan"})
+		return
+	}
+
+	h.audit(c, audit.EventAuditResourceCreated, plan, "created")
+	c.JSON(http.StatusCreated, plan)
+}
+
+// ListPlans handles GET /api/v1/upgrade/plans?cluster=
+func (h *Handler) ListPlans(c *gin.Context) {
+	clusterName := c.Query("cluster")
+	if clusterName == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cluster query parameter is required"})
+		return
+	}
+	if !auth.CheckClusterScope(c, clusterName, "nodes", "", "read") {
+		return
+	}
+
+	plans, err := h.db.ListUpgradePlans(clusterName)
+	if err != nil {
+		log.Errorf("Failed to list upgrade plans: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list upgrade plans"})
+		return
+	}
+
+	c.JSON(http.StatusOK, plans)
+}
+
+// GetPlan handles GET /api/v1/upgrade/plans/:id
+func (h *Handler) GetPlan(c *gin.Context) {
+	plan, ok := h.loadPlan(c, "read")
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"plan":    plan,
+		"running": h.runner.IsRunning(plan.ID),
+	})
+}
+
+// StartPlan handles POST /api/v1/upgrade/plans/:id/start (also resumes a paused plan)
+func (h *Handler) StartPlan(c *gin.Context) {
+	plan, ok := h.loadPlan(c, "update")
+	if !ok {
+		return
+	}
+
+	if err := h.runner.Start(plan); err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.audit(c, audit.EventAuditResourceUpdated, plan, "started")
+	c.JSON(http.StatusOK, gin.H{"message": "upgrade plan started", "plan": plan})
+}
+
+// PausePlan handles POST /api/v1/upgrade/plans/:id/pause
+func (h *Handler) PausePlan(c *gin.Context) {
+	plan, ok := h.loadPlan(c, "update")
+	if !ok {
+		return
+	}
+
+	requested, err := h.runner.RequestStop(plan.ID, StatusPaused)
+	if err != nil {
+		log.Errorf("Failed to pause upgrade plan: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to pause upgrade plan"})
+		return
+	}
+	if !requested {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("plan is %s, not running", plan.Status)})
+		return
+	}
+
+	h.audit(c, audit.EventAuditResourceUpdated, plan, "paused")
+	c.JSON(http.StatusOK, gin.H{"message": "upgrade plan pausing after the current node"})
+}
+
+// CancelPlan handles POST /api/v1/upgrade/plans/:id/cancel
+func (h *Handler) CancelPlan(c *gin.Context) {
+	plan, ok := h.loadPlan(c, "update")
+	if !ok {
+		return
+	}
+
+	switch plan.Status {
+	case StatusCompleted, StatusFailed, StatusCancelled:
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("plan is already %s", plan.Status)})
+		return
+	}
+
+	cancelled, err := h.runner.Cancel(plan.ID)
+	if err != nil {
+		log.Errorf("Failed to cancel upgrade plan: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to cancel upgrade plan"})
+		return
+	}
+	if !cancelled {
+		c.JSON(http.StatusConflict, gin.H{"error": "plan has already ended"})
+		return
+	}
+
+	h.audit(c, audit.EventAuditResourceUpdated, plan, "cancelled")
+	c.JSON(http.StatusOK, gin.H{"message": "upgrade plan cancelled"})
+}
+
+// loadPlan loads the plan named in the route if the caller's scope allows the action on
+// the nodes of its cluster, writing the error response when it cannot
+func (h *Handler) loadPlan(c *gin.Context, action string) (*db.UpgradePlan, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid upgrade plan ID"})
+		return nil, false
+	}
+
+	plan, err := h.db.GetUpgradePlan(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	if !auth.CheckClusterScope(c, plan.ClusterName, "nodes", "", action) {
+		return nil, false
+	}
+	return plan, true
+}
+
+func (h *Handler) audit(c *gin.Context, event string, plan *db.UpgradePlan, action string) {
+	if userID, exists := c.Get("user_id"); exists {
+		username, _ := c.Get("username")
+		email, _ := c.Get("email")
+
+		audit.Log(c, event, userID.(int), username.(string), email.(string),
+			fmt.Sprintf("Upgrade plan %d %s for cluster %s", plan.ID, action, plan.ClusterName),
+			map[string]interface{}{
+				"cluster_name":   plan.ClusterName,
+				"plan_id":        plan.ID,
+				"target_version": plan.TargetVersion,
+				"action":         action,
+			})
+	}
+}
@@ -0,0 +1,374 @@
+package upgrade
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Pre-check statuses
+const (
+	CheckPass = "pass"
+	CheckWarn = "warn"
+	CheckFail = "fail"
+)
+
+// maxCheckDetails bounds the number of items listed in a single check
+const maxCheckDetails = 50
+
+// PreCheck is the result of a single upgrade readiness check
+type PreCheck struct {
+	Name    string   `json:"name"`
+	Status  string   `json:"status"`
+	Message string   `json:"message"`
+	Details []string `json:"details,omitempty"`
+}
+
+// RemovedAPI is a group/version that stops being served in a Kubernetes minor release
+type RemovedAPI struct {
+	GroupVersion string
+	RemovedIn    int // minor version of 1.x
+	Resources    string
+}
+
+// RemovedAPIs lists API versions removed upstream (https://kubernetes.io/docs/reference/using-api/deprecation-guide/)
+var RemovedAPIs = []RemovedAPI{
+	{"admissionregistration.k8s.io/v1beta1", 22, "MutatingWebhookConfiguration, ValidatingWebhookConfiguration"},
+	{"apiextensions.k8s.io/v1beta1", 22, "CustomResourceDefinition"},
+	{"apiregistration.k8s.io/v1beta1", 22, "APIService"},
+	{"authentication.k8s.io/v1beta1", 22, "TokenReview"},
+	{"authorization.k8s.io/v1beta1", 22, "SubjectAccessReview"},
+	{"certificates.k8s.io/v1beta1", 22, "CertificateSigningRequest"},
+	{"coordination.k8s.io/v1beta1", 22, "Lease"},
+	{"extensions/v1beta1", 22, "Ingress"},
+	{"networking.k8s.io/v1beta1", 22, "Ingress, IngressClass"},
+	{"rbac.authorization.k8s.io/v1beta1", 22, "Role, ClusterRole, RoleBinding, ClusterRoleBinding"},
+	{"scheduling.k8s.io/v1beta1", 22, "PriorityClass"},
+	{"batch/v1beta1", 25, "CronJob"},
+	{"discovery.k8s.io/v1beta1", 25, "EndpointSlice"},
+	{"events.k8s.io/v1beta1", 25, "Event"},
+	{"autoscaling/v2beta1", 25, "HorizontalPodAutoscaler"},
+	{"policy/v1beta1", 25, "PodDisruptionBudget, PodSecurityPolicy"},
+	{"node.k8s.io/v1beta1", 25, "RuntimeClass"},
+	{"flowcontrol.apiserver.k8s.io/v1beta1", 26, "FlowSchema, PriorityLevelConfiguration"},
+	{"autoscaling/v2beta2", 26, "HorizontalPodAutoscaler"},
+	{"storage.k8s.io/v1beta1", 27, "CSIStorageCapacity"},
+	{"flowcontrol.apiserver.k8s.io/v1beta2", 29, "FlowSchema, PriorityLevelConfiguration"},
+	{"flowcontrol.apiserver.k8s.io/v1beta3", 32, "FlowSchema, PriorityLevelConfiguration"},
+}
+
+var versionPattern = regexp.MustCompile(`^v?(\d+)\.(\d+)`)
+
+// ParseMinorVersion extracts the major and minor version from strings like "v1.29.3-eks-abc" or "1.30"
+func ParseMinorVersion(version string) (int, int, error) {
+	m := versionPattern.FindStringSubmatch(version)
+	if m == nil {
+		return 0, 0, fmt.Errorf("invalid version: %s", version)
+	}
+	major, _ := strconv.Atoi(m[1])
+	minor, _ := strconv.Atoi(m[2])
+	return major, minor, nil
+}
+
+// RunPreChecks evaluates whether the cluster is ready to be upgraded to targetVersion
+// by draining the given node batches one at a time. It also returns the current server version.
+func RunPreChecks(ctx context.Context, client kubernetes.Interface, targetVersion string, batches [][]string) ([]PreCheck, string) {
+	checks := []PreCheck{}
+
+	currentVersion := ""
+	if info, err := client.Discovery().ServerVersion(); err == nil {
+		currentVersion = info.GitVersion
+	}
+
+	checks = append(checks, checkVersionSkew(currentVersion, targetVersion))
+	checks = append(checks, checkRemovedAPIs(client, currentVersion, targetVersion))
+	checks = append(checks, checkPDBs(ctx, client)...)
+	checks = append(checks, checkCapacity(ctx, client, batches))
+
+	return checks, currentVersion
+}
+
+// HasFailures reports whether any check failed
+func HasFailures(checks []PreCheck) bool {
+	for _, c := range checks {
+		if c.Status == CheckFail {
+			return true
+		}
+	}
+	return false
+}
+
+// checkVersionSkew ensures the target is at most one minor version ahead
+func checkVersionSkew(currentVersion, targetVersion string) PreCheck {
+	check := PreCheck{Name: "version-skew"}
+
+	curMajor, curMinor, err := ParseMinorVersion(currentVersion)
+	if err != nil {
+		check.Status = CheckWarn
+		check.Message = "Unable to determine current cluster version"
+		return check
+	}
+	tgtMajor, tgtMinor, err := ParseMinorVersion(targetVersion)
+	if err != nil {
+		check.Status = CheckFail
+		check.Message = err.Error()
+		return check
+	}
+
+	switch {
+	case tgtMajor != curMajor || tgtMinor < curMinor:
+		check.Status = CheckFail
+		check.Message = fmt.Sprintf("Target %s is not an upgrade from %s", targetVersion, currentVersion)
+	case tgtMinor-curMinor > 1:
+		check.Status = CheckFail
+		check.Message = fmt.Sprintf("Control plane can only be upgraded one minor version at a time (%d.%d -> %d.%d)", curMajor, curMinor, tgtMajor, tgtMinor)
+	default:
+		check.Status = CheckPass
+		check.Message = fmt.Sprintf("Upgrade %s -> %s is within supported skew", currentVersion, targetVersion)
+	}
+	return check
+}
+
+// checkRemovedAPIs flags API versions that are still served today but removed in the target
+func checkRemovedAPIs(client kubernetes.Interface, currentVersion, targetVersion string) PreCheck {
+	check := PreCheck{Name: "removed-apis"}
+
+	_, curMinor, err1 := ParseMinorVersion(currentVersion)
+	_, tgtMinor, err2 := ParseMinorVersion(targetVersion)
+	if err1 != nil || err2 != nil {
+		check.Status = CheckWarn
+		check.Message = "Unable to compare versions for removed APIs"
+		return check
+	}
+
+	groups, err := client.Discovery().ServerGroups()
+	if err != nil {
+		check.Status = CheckWarn
+		check.Message = fmt.Sprintf("Failed to discover served API versions: %v", err)
+		return check
+	}
+	served := make(map[string]bool)
+	for _, g := range groups.Groups {
+		for _, v := range g.Versions {
+			served[v.GroupVersion] = true
+		}
+	}
+
+	for _, api := range RemovedAPIs {
+		if api.RemovedIn > curMinor && api.RemovedIn <= tgtMinor && served[api.GroupVersion] {
+			check.Details = append(check.Details, fmt.Sprintf("%s (%s) is removed in 1.%d", api.GroupVersion, api.Resources, api.RemovedIn))
+		}
+	}
+
+	if len(check.Details) > 0 {
+		check.Status = CheckWarn
+		check.Message = "Clients and manifests using these API versions must be migrated before upgrading"
+	} else {
+		check.Status = CheckPass
+		check.Message = "No served API versions are removed in the target release"
+	}
+	return check
+}
+
+// checkPDBs reports PDBs that block eviction and replicated workloads without PDB coverage
+func checkPDBs(ctx context.Context, client kubernetes.Interface) []PreCheck {
+	blocking := PreCheck{Name: "pdb-blocking"}
+	coverage := PreCheck{Name: "pdb-coverage"}
+
+	pdbs, err := client.PolicyV1().PodDisruptionBudgets(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		blocking.Status = CheckWarn
+		blocking.Message = fmt.Sprintf("Failed to list PodDisruptionBudgets: %v", err)
+		return []PreCheck{blocking}
+	}
+
+	for _, pdb := range pdbs.Items {
+		if pdb.Status.ExpectedPods > 0 && pdb.Status.DisruptionsAllowed == 0 {
+			appendDetail(&blocking, fmt.Sprintf("%s/%s allows 0 disruptions (%d/%d healthy)",
+				pdb.Namespace, pdb.Name, pdb.Status.CurrentHealthy, pdb.Status.ExpectedPods))
+		}
+	}
+	if len(blocking.Details) > 0 {
+		blocking.Status = CheckFail
+		blocking.Message = "These PodDisruptionBudgets will block node drains"
+	} else {
+		blocking.Status = CheckPass
+		blocking.Message = "No PodDisruptionBudget currently blocks evictions"
+	}
+
+	deployments, err := client.AppsV1().Deployments(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err == nil {
+		for _, d := range deployments.Items {
+			if replicasOf(d.Spec.Replicas) > 1 && !coveredByPDB(pdbs.Items, d.Namespace, d.Spec.Template.Labels) {
+				appendDetail(&coverage, fmt.Sprintf("Deployment %s/%s", d.Namespace, d.Name))
+			}
+		}
+	}
+	statefulSets, err := client.AppsV1().StatefulSets(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err == nil {
+		for _, s := range statefulSets.Items {
+			if replicasOf(s.Spec.Replicas) > 1 && !coveredByPDB(pdbs.Items, s.Namespace, s.Spec.Template.Labels) {
+				appendDetail(&coverage, fmt.Sprintf("StatefulSet %s/%s", s.Namespace, s.Name))
+			}
+		}
+	}
+	if len(coverage.Details) > 0 {
+		coverage.Status = CheckWarn
+		coverage.Message = "Replicated workloads without a PodDisruptionBudget may lose all replicas during a drain"
+	} else {
+		coverage.Status = CheckPass
+		coverage.Message = "All replicated workloads are covered by a PodDisruptionBudget"
+	}
+
+	return []PreCheck{blocking, coverage}
+}
+
+// checkCapacity verifies the remaining nodes can absorb the pods of the largest batch
+func checkCapacity(ctx context.Context, client kubernetes.Interface, batches [][]string) PreCheck {
+	check := PreCheck{Name: "capacity-headroom"}
+
+	nodes, err := client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		check.Status = CheckWarn
+		check.Message = fmt.Sprintf("Failed to list nodes: %v", err)
+		return check
+	}
+	pods, err := client.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		check.Status = CheckWarn
+		check.Message = fmt.Sprintf("Failed to list pods: %v", err)
+		return check
+	}
+
+	allocatable := make(map[string]corev1.ResourceList)
+	totalCPU, totalMem := resource.Quantity{}, resource.Quantity{}
+	for _, n := range nodes.Items {
+		if n.Spec.Unschedulable {
+			continue
+		}
+		allocatable[n.Name] = n.Status.Allocatable
+		totalCPU.Add(*n.Status.Allocatable.Cpu())
+		totalMem.Add(*n.Status.Allocatable.Memory())
+	}
+
+	requestedCPU, requestedMem := resource.Quantity{}, resource.Quantity{}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		for _, container := range pod.Spec.Containers {
+			requestedCPU.Add(*container.Resources.Requests.Cpu())
+			requestedMem.Add(*container.Resources.Requests.Memory())
+		}
+	}
+
+	worstBatch := ""
+	worstFree := int64(-1)
+	for i, batch := range batches {
+		cpu, mem := totalCPU.DeepCopy(), totalMem.DeepCopy()
+		for _, name := range batch {
+			if alloc, ok := allocatable[name]; ok {
+				cpu.Sub(*alloc.Cpu())
+				mem.Sub(*alloc.Memory())
+			}
+		}
+		cpuFree := cpu.MilliValue() - requestedCPU.MilliValue()
+		memFree := mem.Value() - requestedMem.Value()
+		if cpuFree < 0 || memFree < 0 {
+			appendDetail(&check, fmt.Sprintf("batch %d %v: insufficient capacity (cpu headroom %dm, memory headroom %dMi)",
+				i+1, batch, cpuFree, memFree/(1024*1024)))
+		}
+		if worstFree < 0 || cpuFree < worstFree {
+			worstFree = cpuFree
+			worstBatch = fmt.Sprintf("%d", i+1)
+		}
+	}
+
+	if len(check.Details) > 0 {
+		check.Status = CheckFail
+		check.Message = "Remaining nodes cannot fit current pod requests while these batches are drained"
+		return check
+	}
+
+	check.Status = CheckPass
+	check.Message = fmt.Sprintf("Requests fit on remaining nodes for every batch (cpu requested %s of %s, memory requested %s of %s)",
+		requestedCPU.String(), totalCPU.String(), requestedMem.String(), totalMem.String())
+	if worstBatch != "" {
+		check.Details = []string{fmt.Sprintf("tightest batch: %s (%dm cpu headroom)", worstBatch, worstFree)}
+	}
+	return check
+}
+
+// BuildBatches groups worker nodes into batches of batchSize, sorted by name.
+// Control plane nodes are excluded as they are upgraded by the provider or kubeadm.
+func BuildBatches(nodes []corev1.Node, batchSize int) [][]string {
+	if batchSize < 1 {
+		batchSize = 1
+	}
+
+	names := []string{}
+	for _, n := range nodes {
+		if _, ok := n.Labels["node-role.kubernetes.io/control-plane"]; ok {
+			continue
+		}
+		if _, ok := n.Labels["node-role.kubernetes.io/master"]; ok {
+			continue
+		}
+		names = append(names, n.Name)
+	}
+	sort.Strings(names)
+
+	batches := [][]string{}
+	for i := 0; i < len(names); i += batchSize {
+		end := i + batchSize
+		if end > len(names) {
+			end = len(names)
+		}
+		batches = append(batches, names[i:end])
+	}
+	return batches
+}
+
+// coveredByPDB reports whether any PDB in the namespace selects the given pod labels
+func coveredByPDB(pdbs []policyv1.PodDisruptionBudget, namespace string, podLabels map[string]string) bool {
+	for _, pdb := range pdbs {
+		if pdb.Namespace != namespace || pdb.Spec.Selector == nil {
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+		if err != nil || selector.Empty() {
+			continue
+		}
+		if selector.Matches(labels.Set(podLabels)) {
+			return true
+		}
+	}
+	return false
+}
+
+// replicasOf returns the replica count, defaulting to 1 like the API server
+func replicasOf(replicas *int32) int32 {
+	if replicas == nil {
+		return 1
+	}
+	return *replicas
+}
+
+// appendDetail adds a detail line, bounded by maxCheckDetails
+func appendDetail(check *PreCheck, detail string) {
+	if len(check.Details) < maxCheckDetails {
+		check.Details = append(check.Details, detail)
+	} else if len(check.Details) == maxCheckDetails {
+		check.Details = append(check.Details, "...")
+	}
+}
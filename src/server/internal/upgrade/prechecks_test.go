@@ -0,0 +1,53 @@
+package upgrade
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCheckVersionSkew(t *testing.T) {
+	tests := []struct {
+		current string
+		target  string
+		want    string
+	}{
+		{"v1.29.3-eks-1234", "1.30", CheckPass},
+		{"v1.29.3", "v1.29.5", CheckPass},
+		{"v1.29.3", "1.31", CheckFail},
+		{"v1.29.3", "1.28", CheckFail},
+		{"v1.29.3", "latest", CheckFail},
+		{"", "1.30", CheckWarn},
+	}
+
+	for _, tt := range tests {
+		got := checkVersionSkew(tt.current, tt.target)
+		if got.Status != tt.want {
+			t.Errorf("checkVersionSkew(%q, %q) = %s (%s), want %s", tt.current, tt.target, got.Status, got.Message, tt.want)
+		}
+	}
+}
+
+func TestBuildBatches(t *testing.T) {
+	node := func(name string, labels map[string]string) corev1.Node {
+		return corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+	}
+	nodes := []corev1.Node{
+		node("worker-c", nil),
+		node("cp-1", map[string]string{"node-role.kubernetes.io/control-plane": ""}),
+		node("worker-a", nil),
+		node("worker-b", nil),
+	}
+
+	got := BuildBatches(nodes, 2)
+	want := [][]string{{"worker-a", "worker-b"}, {"worker-c"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("BuildBatches() = %v, want %v", got, want)
+	}
+
+	if got := BuildBatches(nodes, 0); len(got) != 3 {
+		t.Errorf("BuildBatches() with batch size 0 returned %d batches, want 3", len(got))
+	}
+}
@@ -0,0 +1,426 @@
+package upgrade
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/sonnguyen/kubelens/internal/cluster"
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+// Plan statuses
+const (
+	StatusPending   = "pending"
+	StatusRunning   = "running"
+	StatusPaused    = "paused"
+	StatusCompleted = "completed"
+	StatusFailed    = "failed"
+	StatusCancelled = "cancelled"
+)
+
+const (
+	// defaultDrainTimeout bounds how long a single node may take to empty
+	defaultDrainTimeout = 15 * time.Minute
+	// monitorInterval is how often running plans are checked for a server that stopped
+	monitorInterval = 30 * time.Second
+	// heartbeatInterval is how often a server renews its lease on the plans it runs and
+	// checks them for a pause or cancel asked of another server
+	heartbeatInterval = 10 * time.Second
+	// leaseTimeout is how long a lease lasts without renewal before the plan is paused
+	leaseTimeout = time.Minute
+)
+
+// errNotClaimed is returned by Start when another request or server changed the plan first
+var errNotClaimed = errors.New("plan is no longer pending or paused")
+
+// NodeProgress records the outcome of draining one node
+type NodeProgress struct {
+	Batch      int                  `json:"batch"`
+	Node       string               `json:"node"`
+	Status     string               `json:"status"` // drained, replaced, failed
+	Result     *cluster.DrainResult `json:"result,omitempty"`
+	Error      string               `json:"error,omitempty"`
+	FinishedAt time.Time            `json:"finished_at"`
+}
+
+// NodePoolUpgrader triggers a provider-side node upgrade (e.g. an EKS managed node
+// group or GKE node pool update) for nodes that have been drained, and returns once
+// the nodes are replaced. EKSNodeGroupUpgrader handles EKS managed node groups; for
+// other nodes the runner only cordons and drains, and the operator replaces the nodes
+// out of band before resuming.
+type NodePoolUpgrader interface {
+	UpgradeNodes(ctx context.Context, clusterName string, nodes []string, targetVersion string) error
+}
+
+// MaintenanceMode reports whether changes to clusters are suspended (policy.MaintenanceMode)
+type MaintenanceMode interface {
+	Enabled() bool
+}
+
+// Runner executes upgrade plans, one goroutine per running plan. Replicas sharing the
+// database share the plans: a plan is run by the server that claims it, which holds a
+// lease on it while it runs.
+type Runner struct {
+	db                *db.DB
+	clusterManager    *cluster.Manager
+	upgrader          NodePoolUpgrader
+	maintenance       MaintenanceMode
+	drainTimeout      time.Duration
+	owner             string // name of this server in the leases
+	heartbeatInterval time.Duration
+	leaseTimeout      time.Duration
+	done              chan struct{}
+
+	mu      sync.Mutex
+	running map[uint]*runningPlan
+}
+
+type runningPlan struct {
+	cancel     context.CancelFunc
+	stopStatus string
+	lost       bool // the lease was lost; the plan is no longer this server's to save
+}
+
+// NewRunner creates a plan runner. Plans whose server stopped renewing its lease are
+// marked paused by the monitor.
+func NewRunner(database *db.DB, clusterManager *cluster.Manager) *Runner {
+	return &Runner{
+		db:                database,
+		clusterManager:    clusterManager,
+		drainTimeout:      defaultDrainTimeout,
+		owner:             db.NewLeaseOwner(),
+		heartbeatInterval: heartbeatInterval,
+		leaseTimeout:      leaseTimeout,
+		done:              make(chan struct{}),
+		running:           make(map[uint]*runningPlan),
+	}
+}
+
+// SetNodePoolUpgrader registers a provider hook invoked after each batch is drained
+func (r *Runner) SetNodePoolUpgrader(upgrader NodePoolUpgrader) {
+	r.upgrader = upgrader
+}
+
+// SetMaintenanceMode makes the runner refuse to start plans while maintenance mode is on
+func (r *Runner) SetMaintenanceMode(maintenance MaintenanceMode) {
+	r.maintenance = maintenance
+}
+
+// StartMonitor begins checking for running plans whose server stopped
+func (r *Runner) StartMonitor() {
+	go func() {
+		ticker := time.NewTicker(monitorInterval)
+		defer ticker.Stop()
+
+		r.pauseStale()
+		for {
+			select {
+			case <-ticker.C:
+				r.pauseStale()
+			case <-r.done:
+				return
+			}
+		}
+	}()
+}
+
+// StopMonitor stops the monitor and pauses the plans running in this process
+func (r *Runner) StopMonitor() {
+	close(r.done)
+
+	r.mu.Lock()
+	for _, rp := range r.running {
+		rp.stopStatus = StatusPaused
+		rp.cancel()
+	}
+	r.mu.Unlock()
+}
+
+// pauseStale pauses the running plans whose server stopped renewing its lease, e.g.
+// because it was restarted
+func (r *Runner) pauseStale() {
+	if n, err := r.db.PauseStaleUpgradePlans(time.Now().Add(-r.leaseTimeout)); err != nil {
+		log.Errorf("Failed to pause interrupted upgrade plans: %v", err)
+	} else if n > 0 {
+		log.Warnf("Paused %d upgrade plans whose server stopped", n)
+	}
+}
+
+// Start runs (or resumes) a plan from its current batch. It fails with errNotClaimed
+// when another request or server started, paused or cancelled the plan first.
+func (r *Runner) Start(plan *db.UpgradePlan) error {
+	switch plan.Status {
+	case StatusPending, StatusPaused:
+	default:
+		return fmt.Errorf("plan is %s and cannot be started", plan.Status)
+	}
+	if r.maintenance != nil && r.maintenance.Enabled() {
+		return fmt.Errorf("maintenance mode is on; upgrade plans cannot be started")
+	}
+
+	r.mu.Lock()
+	if _, ok := r.running[plan.ID]; ok {
+		r.mu.Unlock()
+		return fmt.Errorf("plan is already running")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	rp := &runningPlan{cancel: cancel, stopStatus: StatusPaused}
+	r.running[plan.ID] = rp
+	r.mu.Unlock()
+
+	claimed, err := r.db.ClaimUpgradePlan(plan, r.owner, time.Now())
+	if err != nil || !claimed {
+		r.finish(plan.ID)
+		if err != nil {
+			return err
+		}
+		return errNotClaimed
+	}
+
+	go r.run(ctx, rp, plan)
+	return nil
+}
+
+// RequestStop stops a running plan, on whichever server runs it, leaving it with the
+// given status (paused or cancelled). It returns false if the plan is not running.
+func (r *Runner) RequestStop(planID uint, status string) (bool, error) {
+	requested, err := r.db.TransitionUpgradePlan(planID, []string{StatusRunning}, map[string]interface{}{"stop_request": status})
+	if err != nil || !requested {
+		return false, err
+	}
+	// A plan run here stops at once; one run elsewhere at the next heartbeat of its server
+	r.Stop(planID, status)
+	return true, nil
+}
+
+// Cancel cancels a pending or paused plan, or stops a running one as cancelled. It
+// returns false if the plan has ended.
+func (r *Runner) Cancel(planID uint) (bool, error) {
+	cancelled, err := r.db.TransitionUpgradePlan(planID, []string{StatusPending, StatusPaused}, map[string]interface{}{
+		"status":       StatusCancelled,
+		"completed_at": time.Now(),
+	})
+	if err != nil || cancelled {
+		return cancelled, err
+	}
+	return r.RequestStop(planID, StatusCancelled)
+}
+
+// Stop interrupts a plan running in this process, leaving it with the given status
+// (paused or cancelled). It returns false if the plan is not running in this process.
+func (r *Runner) Stop(planID uint, status string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rp, ok := r.running[planID]
+	if !ok {
+		return false
+	}
+	rp.stopStatus = status
+	rp.cancel()
+	return true
+}
+
+// IsRunning reports whether a plan is executing in this process
+func (r *Runner) IsRunning(planID uint) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.running[planID]
+	return ok
+}
+
+// heartbeat renews the lease on a running plan until ctx ends, stopping the plan when
+// another server asks for it to be paused or cancelled, or when the lease was lost
+func (r *Runner) heartbeat(ctx context.Context, rp *runningPlan, planID uint) {
+	ticker := time.NewTicker(r.heartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		stop, held, err := r.db.HeartbeatUpgradePlan(planID, r.owner, time.Now())
+		if err != nil {
+			log.Warnf("Failed to renew the lease on upgrade plan %d: %v", planID, err)
+			continue
+		}
+		if !held {
+			r.mu.Lock()
+			rp.lost = true
+			r.mu.Unlock()
+			rp.cancel()
+			return
+		}
+		if stop != "" {
+			r.Stop(planID, stop)
+			return
+		}
+	}
+}
+
+func (r *Runner) finish(planID uint) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if rp, ok := r.running[planID]; ok {
+		rp.cancel()
+		delete(r.running, planID)
+	}
+}
+
+// run drains batches starting at plan.CurrentBatch, persisting progress after each node
+func (r *Runner) run(ctx context.Context, rp *runningPlan, plan *db.UpgradePlan) {
+	defer r.finish(plan.ID)
+
+	var batches [][]string
+	if err := json.Unmarshal(plan.Batches, &batches); err != nil {
+		r.fail(plan, fmt.Sprintf("invalid plan batches: %v", err))
+		return
+	}
+	var progress []NodeProgress
+	if len(plan.Progress) > 0 {
+		_ = json.Unmarshal(plan.Progress, &progress)
+	}
+
+	client, err := r.clusterManager.GetClient(plan.ClusterName)
+	if err != nil {
+		r.fail(plan, err.Error())
+		return
+	}
+	go r.heartbeat(ctx, rp, plan.ID)
+
+	log.Infof("Upgrade plan %d for cluster %s running from batch %d/%d", plan.ID, plan.ClusterName, plan.CurrentBatch+1, len(batches))
+
+	for plan.CurrentBatch < len(batches) {
+		batch := batches[plan.CurrentBatch]
+
+		for _, node := range batch {
+			if r.interrupted(ctx, rp, plan) {
+				return
+			}
+
+			entry := NodeProgress{Batch: plan.CurrentBatch, Node: node, Status: "drained"}
+			if _, err := client.CoreV1().Nodes().Get(ctx, node, metav1.GetOptions{}); apierrors.IsNotFound(err) {
+				// Already replaced, by the node pool upgrade of an earlier batch
+				entry.Status = "replaced"
+				entry.FinishedAt = time.Now()
+				progress = append(progress, entry)
+				plan.Progress = marshalJSON(progress)
+				r.save(plan)
+				continue
+			}
+			result, err := cluster.DrainNode(ctx, client, node)
+			if err == nil {
+				entry.Result = result
+				err = cluster.WaitForNodeDrained(ctx, client, node, r.drainTimeout)
+			}
+			entry.FinishedAt = time.Now()
+			if err != nil {
+				entry.Status = "failed"
+				entry.Error = err.Error()
+			}
+			progress = append(progress, entry)
+			plan.Progress = marshalJSON(progress)
+
+			if err != nil {
+				if r.interrupted(ctx, rp, plan) {
+					return
+				}
+				// Leave the plan paused so the operator can resolve the blocker and resume
+				plan.Status = StatusPaused
+				plan.Error = fmt.Sprintf("node %s: %v", node, err)
+				r.save(plan)
+				log.Warnf("Upgrade plan %d paused: %s", plan.ID, plan.Error)
+				return
+			}
+			r.save(plan)
+		}
+
+		if r.upgrader != nil {
+			if err := r.upgrader.UpgradeNodes(ctx, plan.ClusterName, batch, plan.TargetVersion); err != nil {
+				if r.interrupted(ctx, rp, plan) {
+					return
+				}
+				plan.Status = StatusPaused
+				plan.Error = fmt.Sprintf("node pool upgrade for batch %d failed: %v", plan.CurrentBatch+1, err)
+				r.save(plan)
+				return
+			}
+		}
+
+		plan.CurrentBatch++
+		if plan.PauseBetweenBatches && plan.CurrentBatch < len(batches) {
+			plan.Status = StatusPaused
+			r.save(plan)
+			log.Infof("Upgrade plan %d paused after batch %d/%d", plan.ID, plan.CurrentBatch, len(batches))
+			return
+		}
+		r.save(plan)
+	}
+
+	now := time.Now()
+	plan.Status = StatusCompleted
+	plan.CompletedAt = &now
+	r.save(plan)
+	log.Infof("Upgrade plan %d for cluster %s completed", plan.ID, plan.ClusterName)
+}
+
+// interrupted persists the requested stop status if the plan context was cancelled
+func (r *Runner) interrupted(ctx context.Context, rp *runningPlan, plan *db.UpgradePlan) bool {
+	if ctx.Err() == nil {
+		return false
+	}
+
+	r.mu.Lock()
+	status, lost := rp.stopStatus, rp.lost
+	r.mu.Unlock()
+
+	if lost {
+		log.Warnf("Upgrade plan %d stopped: this server no longer holds its lease", plan.ID)
+		return true
+	}
+	plan.Status = status
+	if status == StatusCancelled {
+		now := time.Now()
+		plan.CompletedAt = &now
+	}
+	r.save(plan)
+	log.Infof("Upgrade plan %d %s", plan.ID, status)
+	return true
+}
+
+func (r *Runner) fail(plan *db.UpgradePlan, msg string) {
+	now := time.Now()
+	plan.Status = StatusFailed
+	plan.Error = msg
+	plan.CompletedAt = &now
+	r.save(plan)
+	log.Errorf("Upgrade plan %d failed: %s", plan.ID, msg)
+}
+
+// save saves a plan run by this server, releasing it once it stops running
+func (r *Runner) save(plan *db.UpgradePlan) {
+	if plan.Status != StatusRunning {
+		plan.Owner = ""
+	}
+	if saved, err := r.db.SaveUpgradePlan(plan, r.owner); err != nil {
+		log.Errorf("Failed to save upgrade plan %d: %v", plan.ID, err)
+	} else if !saved {
+		log.Warnf("Upgrade plan %d not saved: this server no longer holds its lease", plan.ID)
+	}
+}
+
+func marshalJSON(v interface{}) db.JSON {
+	data, _ := json.Marshal(v)
+	return db.JSON(data)
+}
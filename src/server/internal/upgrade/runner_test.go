@@ -0,0 +1,122 @@
+package upgrade
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/sonnguyen/kubelens/internal/cluster"
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+// newRunners returns two replicas sharing a database and a cluster "prod" whose node-1
+// never empties: the eviction of its pod is accepted but the pod stays
+func newRunners(t *testing.T) (*db.DB, *Runner, *Runner) {
+	database, err := db.New(filepath.Join(t.TempDir(), "kubelens.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	client := fake.NewSimpleClientset(
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop"}, Spec: corev1.PodSpec{NodeName: "node-1"}},
+	)
+	client.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return action.GetSubresource() == "eviction", nil, nil
+	})
+	manager := cluster.NewManager(database)
+	manager.AddClusterFromClients("prod", client, nil)
+
+	runners := make([]*Runner, 2)
+	for i := range runners {
+		runners[i] = NewRunner(database, manager)
+		runners[i].heartbeatInterval = 20 * time.Millisecond
+	}
+	return database, runners[0], runners[1]
+}
+
+func createPlan(t *testing.T, database *db.DB, plan *db.UpgradePlan) *db.UpgradePlan {
+	plan.ClusterName = "prod"
+	plan.TargetVersion = "1.30"
+	plan.Batches = marshalJSON([][]string{{"node-1"}})
+	if plan.Status == "" {
+		plan.Status = StatusPending
+	}
+	if err := database.CreateUpgradePlan(plan); err != nil {
+		t.Fatal(err)
+	}
+	return plan
+}
+
+// waitForStatus waits for the stored plan to reach a status and returns it
+func waitForStatus(t *testing.T, database *db.DB, id uint, status string) *db.UpgradePlan {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		plan, err := database.GetUpgradePlan(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if plan.Status == status {
+			return plan
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("upgrade plan %d is %s (%s), want %s", id, plan.Status, plan.Error, status)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestRunnerReplicas(t *testing.T) {
+	database, first, second := newRunners(t)
+
+	// Only one replica claims a plan
+	plan := createPlan(t, database, &db.UpgradePlan{})
+	stale := *plan
+	if err := first.Start(plan); err != nil {
+		t.Fatal(err)
+	}
+	if err := second.Start(&stale); !errors.Is(err, errNotClaimed) {
+		t.Errorf("second replica Start() = %v, want %v", err, errNotClaimed)
+	}
+
+	// The lease of a running plan is kept
+	second.pauseStale()
+	if plan, _ := database.GetUpgradePlan(plan.ID); plan.Status != StatusRunning || plan.Owner != first.owner {
+		t.Errorf("plan with a held lease = %s owned by %q, want running on the first replica", plan.Status, plan.Owner)
+	}
+
+	// A cancel asked of the other replica reaches the one running the plan
+	if cancelled, err := second.Cancel(plan.ID); err != nil || !cancelled {
+		t.Fatalf("Cancel() = %v, %v", cancelled, err)
+	}
+	if cancelled := waitForStatus(t, database, plan.ID, StatusCancelled); cancelled.Owner != "" || cancelled.CompletedAt == nil {
+		t.Errorf("cancelled plan = owner %q, completed at %v; want released and completed", cancelled.Owner, cancelled.CompletedAt)
+	}
+	if cancelled, err := second.Cancel(plan.ID); err != nil || cancelled {
+		t.Errorf("second Cancel() = %v, %v; want false", cancelled, err)
+	}
+
+	// A plan whose server stopped renewing its lease is paused by the others
+	heartbeat := time.Now().Add(-2 * leaseTimeout)
+	orphan := createPlan(t, database, &db.UpgradePlan{Status: StatusRunning, Owner: "gone", HeartbeatAt: &heartbeat})
+	second.pauseStale()
+	if paused := waitForStatus(t, database, orphan.ID, StatusPaused); paused.Owner != "" {
+		t.Errorf("stale plan owner = %q, want released", paused.Owner)
+	}
+
+	// A pending plan is cancelled at once
+	pending := createPlan(t, database, &db.UpgradePlan{})
+	if cancelled, err := first.Cancel(pending.ID); err != nil || !cancelled {
+		t.Fatalf("Cancel() of a pending plan = %v, %v", cancelled, err)
+	}
+	waitForStatus(t, database, pending.ID, StatusCancelled)
+}
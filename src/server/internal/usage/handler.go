@@ -0,0 +1,417 @@
+package usage
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+// maxTopClusters is the number of clusters listed per user
+const maxTopClusters = 5
+
+// Handler handles usage analytics API requests
+type Handler struct {
+	db *db.DB
+}
+
+// NewHandler creates a new usage handler
+func NewHandler(database *db.DB) *Handler {
+	return &Handler{db: database}
+}
+
+// Totals are aggregated usage counts
+type Totals struct {
+	Requests     int64 `json:"requests"`
+	Mutations    int64 `json:"mutations"`
+	ShellsOpened int64 `json:"shells_opened"`
+}
+
+func (t *Totals) add(r *db.UsageRollup) {
+	t.Requests += r.Requests
+	t.Mutations += r.Mutations
+	t.ShellsOpened += r.ShellsOpened
+}
+
+// ClusterUsage is the request count against one cluster
+type ClusterUsage struct {
+	Cluster  string `json:"cluster"`
+	Requests int64  `json:"requests"`
+}
+
+// UserUsage is the usage summary for one user
+type UserUsage struct {
+	UserID      uint           `json:"user_id"`
+	Username    string         `json:"username"`
+	Email       string         `json:"email"`
+	ActiveDays  int            `json:"active_days"`
+	TopClusters []ClusterUsage `json:"top_clusters"`
+	Totals
+}
+
+// GroupUsage is the usage summary for one group (users in several groups count in each)
+type GroupUsage struct {
+	GroupID     uint   `json:"group_id"`
+	Name        string `json:"name"`
+	Members     int    `json:"members"`
+	ActiveUsers int    `json:"active_users"`
+	Totals
+}
+
+// parseRange reads from/to (YYYY-MM-DD) query params, defaulting to the last 30 days
+func parseRange(c *gin.Context) (string, string, error) {
+	now := time.Now().UTC()
+	from := c.DefaultQuery("from", now.AddDate(0, 0, -29).Format(DayFormat))
+	to := c.DefaultQuery("to", now.Format(DayFormat))
+
+	if _, err := time.Parse(DayFormat, from); err != nil {
+		return "", "", fmt.Errorf("invalid from date, expected YYYY-MM-DD")
+	}
+	if _, err := time.Parse(DayFormat, to); err != nil {
+		return "", "", fmt.Errorf("invalid to date, expected YYYY-MM-DD")
+	}
+	if from > to {
+		return "", "", fmt.Errorf("from must not be after to")
+	}
+	return from, to, nil
+}
+
+// userSummaries aggregates rollups per user
+func (h *Handler) userSummaries(rollups []*db.UsageRollup) (map[uint]*UserUsage, error) {
+	users, err := h.db.ListAllUsers()
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := make(map[uint]*UserUsage, len(users))
+	for _, u := range users {
+		summaries[u.ID] = &UserUsage{UserID: u.ID, Username: u.Username, Email: u.Email, TopClusters: []ClusterUsage{}}
+	}
+
+	days := make(map[uint]map[string]bool)
+	clusters := make(map[uint]map[string]int64)
+	for _, r := range rollups {
+		s, ok := summaries[r.UserID]
+		if !ok {
+			// Deleted user
+			s = &UserUsage{UserID: r.UserID, TopClusters: []ClusterUsage{}}
+			summaries[r.UserID] = s
+		}
+		s.add(r)
+
+		if days[r.UserID] == nil {
+			days[r.UserID] = make(map[string]bool)
+			clusters[r.UserID] = make(map[string]int64)
+		}
+		days[r.UserID][r.Day] = true
+		if r.ClusterName != "" {
+			clusters[r.UserID][r.ClusterName] += r.Requests
+		}
+	}
+
+	for userID, s := range summaries {
+		s.ActiveDays = len(days[userID])
+		for cluster, requests := range clusters[userID] {
+			s.TopClusters = append(s.TopClusters, ClusterUsage{Cluster: cluster, Requests: requests})
+		}
+		sort.Slice(s.TopClusters, func(i, j int) bool { return s.TopClusters[i].Requests > s.TopClusters[j].Requests })
+		if len(s.TopClusters) > maxTopClusters {
+			s.TopClusters = s.TopClusters[:maxTopClusters]
+		}
+	}
+	return summaries, nil
+}
+
+// GetUserUsage handles GET /api/v1/admin/usage/users
+func (h *Handler) GetUserUsage(c *gin.Context) {
+	from, to, err := parseRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rollups, err := h.db.ListUsageRollups(from, to, 0)
+	if err != nil {
+		log.Errorf("Failed to list usage rollups: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load usage"})
+		return
+	}
+
+	summaries, err := h.userSummaries(rollups)
+	if err != nil {
+		log.Errorf("Failed to list users: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load usage"})
+		return
+	}
+
+	result := make([]*UserUsage, 0, len(summaries))
+	for _, s := range summaries {
+		result = append(result, s)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Requests > result[j].Requests })
+
+	c.JSON(http.StatusOK, gin.H{"from": from, "to": to, "users": result})
+}
+
+// GetGroupUsage handles GET /api/v1/admin/usage/groups
+func (h *Handler) GetGroupUsage(c *gin.Context) {
+	from, to, err := parseRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rollups, err := h.db.ListUsageRollups(from, to, 0)
+	if err != nil {
+		log.Errorf("Failed to list usage rollups: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load usage"})
+		return
+	}
+	groups, err := h.db.ListAllGroups()
+	if err != nil {
+		log.Errorf("Failed to list groups: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load usage"})
+		return
+	}
+	memberships, err := h.db.ListUserGroupMemberships()
+	if err != nil {
+		log.Errorf("Failed to list group memberships: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load usage"})
+		return
+	}
+
+	perUser := make(map[uint]*Totals)
+	for _, r := range rollups {
+		if perUser[r.UserID] == nil {
+			perUser[r.UserID] = &Totals{}
+		}
+		perUser[r.UserID].add(r)
+	}
+
+	summaries := make(map[uint]*GroupUsage, len(groups))
+	for _, g := range groups {
+		summaries[g.ID] = &GroupUsage{GroupID: g.ID, Name: g.Name}
+	}
+	for _, m := range memberships {
+		s, ok := summaries[m.GroupID]
+		if !ok {
+			continue
+		}
+		s.Members++
+		if t, ok := perUser[m.UserID]; ok {
+			s.ActiveUsers++
+			s.Requests += t.Requests
+			s.Mutations += t.Mutations
+			s.ShellsOpened += t.ShellsOpened
+		}
+	}
+
+	result := make([]*GroupUsage, 0, len(summaries))
+	for _, s := range summaries {
+		result = append(result, s)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Requests > result[j].Requests })
+
+	c.JSON(http.StatusOK, gin.H{"from": from, "to": to, "groups": result})
+}
+
+// GetDailyUsage handles GET /api/v1/admin/usage/daily?user_id=
+func (h *Handler) GetDailyUsage(c *gin.Context) {
+	from, to, err := parseRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var userID uint64
+	if v := c.Query("user_id"); v != "" {
+		if userID, err = strconv.ParseUint(v, 10, 32); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user_id"})
+			return
+		}
+	}
+
+	rollups, err := h.db.ListUsageRollups(from, to, uint(userID))
+	if err != nil {
+		log.Errorf("Failed to list usage rollups: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load usage"})
+		return
+	}
+
+	type dayUsage struct {
+		Day string `json:"day"`
+		Totals
+	}
+	byDay := []*dayUsage{}
+	for _, r := range rollups {
+		if len(byDay) == 0 || byDay[len(byDay)-1].Day != r.Day {
+			byDay = append(byDay, &dayUsage{Day: r.Day})
+		}
+		byDay[len(byDay)-1].add(r)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"from": from, "to": to, "days": byDay})
+}
+
+// ExportUsage handles GET /api/v1/admin/usage/export?format=csv|json
+func (h *Handler) ExportUsage(c *gin.Context) {
+	from, to, err := parseRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rollups, err := h.db.ListUsageRollups(from, to, 0)
+	if err != nil {
+		log.Errorf("Failed to list usage rollups: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load usage"})
+		return
+	}
+
+	usernames := make(map[uint]string)
+	if users, err := h.db.ListAllUsers(); err == nil {
+		for _, u := range users {
+			usernames[u.ID] = u.Username
+		}
+	}
+
+	filename := fmt.Sprintf("kubelens-usage-%s-to-%s", from, to)
+
+	if c.DefaultQuery("format", "csv") == "json" {
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s.json", filename))
+		c.JSON(http.StatusOK, rollups)
+		return
+	}
+
+	c.Header("Content-Type", "text/csv")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s.csv", filename))
+
+	w := csv.NewWriter(c.Writer)
+	_ = w.Write([]string{"day", "user_id", "username", "cluster", "requests", "mutations", "shells_opened"})
+	for _, r := range rollups {
+		_ = w.Write([]string{
+			r.Day,
+			strconv.FormatUint(uint64(r.UserID), 10),
+			usernames[r.UserID],
+			r.ClusterName,
+			strconv.FormatInt(r.Requests, 10),
+			strconv.FormatInt(r.Mutations, 10),
+			strconv.FormatInt(r.ShellsOpened, 10),
+		})
+	}
+	w.Flush()
+}
+
+// UnusedPrivilege flags an account whose rights exceed its observed usage
+type UnusedPrivilege struct {
+	UserID    uint       `json:"user_id"`
+	Username  string     `json:"username"`
+	Email     string     `json:"email"`
+	IsAdmin   bool       `json:"is_admin"`
+	CanWrite  bool       `json:"can_write"`
+	Requests  int64      `json:"requests"`
+	Mutations int64      `json:"mutations"`
+	LastLogin *time.Time `json:"last_login,omitempty"`
+	Finding   string     `json:"finding"` // inactive, write_unused
+}
+
+// GetUnusedPrivileges handles GET /api/v1/admin/usage/unused-privileges?days=30
+// It lists active users with no requests, and users with write rights who made no changes.
+func (h *Handler) GetUnusedPrivileges(c *gin.Context) {
+	days, err := strconv.Atoi(c.DefaultQuery("days", "30"))
+	if err != nil || days <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "days must be a positive integer"})
+		return
+	}
+
+	now := time.Now().UTC()
+	from := now.AddDate(0, 0, -(days - 1)).Format(DayFormat)
+	to := now.Format(DayFormat)
+
+	rollups, err := h.db.ListUsageRollups(from, to, 0)
+	if err != nil {
+		log.Errorf("Failed to list usage rollups: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load usage"})
+		return
+	}
+	perUser := make(map[uint]*Totals)
+	for _, r := range rollups {
+		if perUser[r.UserID] == nil {
+			perUser[r.UserID] = &Totals{}
+		}
+		perUser[r.UserID].add(r)
+	}
+
+	users, err := h.db.ListAllUsers()
+	if err != nil {
+		log.Errorf("Failed to list users: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load usage"})
+		return
+	}
+
+	findings := []UnusedPrivilege{}
+	for _, u := range users {
+		if !u.IsActive {
+			continue
+		}
+
+		canWrite := u.IsAdmin
+		if !canWrite {
+			permissions, err := h.db.GetUserPermissions(u.ID)
+			if err != nil {
+				log.Warnf("Failed to get permissions for user %d: %v", u.ID, err)
+				continue
+			}
+			canWrite = hasWriteAction(permissions)
+		}
+
+		totals := perUser[u.ID]
+		if totals == nil {
+			totals = &Totals{}
+		}
+
+		finding := ""
+		switch {
+		case totals.Requests == 0:
+			finding = "inactive"
+		case canWrite && totals.Mutations == 0:
+			finding = "write_unused"
+		default:
+			continue
+		}
+
+		findings = append(findings, UnusedPrivilege{
+			UserID:    u.ID,
+			Username:  u.Username,
+			Email:     u.Email,
+			IsAdmin:   u.IsAdmin,
+			CanWrite:  canWrite,
+			Requests:  totals.Requests,
+			Mutations: totals.Mutations,
+			LastLogin: u.LastLogin,
+			Finding:   finding,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"days": days, "users": findings})
+}
+
+// hasWriteAction reports whether any permission grants a mutating action
+func hasWriteAction(permissions []db.Permission) bool {
+	for _, p := range permissions {
+		for _, action := range p.Actions {
+			switch action {
+			case "create", "update", "delete", "manage", "*":
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,162 @@
+package usage
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/sonnguyen/kubelens/internal/db"
+)
+
+// DayFormat is the format of rollup days
+const DayFormat = "2006-01-02"
+
+// rollupKey identifies one rollup row
+type rollupKey struct {
+	day     string
+	userID  uint
+	cluster string
+}
+
+type rollupCounts struct {
+	requests  int64
+	mutations int64
+	shells    int64
+}
+
+// Tracker counts authenticated API requests in memory and periodically
+// flushes them into daily per-user, per-cluster rollups
+type Tracker struct {
+	db            *db.DB
+	flushInterval time.Duration
+	retentionDays int
+
+	mu      sync.Mutex
+	pending map[rollupKey]*rollupCounts
+	done    chan bool
+}
+
+// NewTracker creates a usage tracker
+func NewTracker(database *db.DB, flushInterval time.Duration, retentionDays int) *Tracker {
+	if flushInterval <= 0 {
+		flushInterval = time.Minute
+	}
+	return &Tracker{
+		db:            database,
+		flushInterval: flushInterval,
+		retentionDays: retentionDays,
+		pending:       make(map[rollupKey]*rollupCounts),
+		done:          make(chan bool),
+	}
+}
+
+// Middleware records usage after the request was handled. It reads the user
+// set by the auth middleware, so it can be installed ahead of it.
+func (t *Tracker) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		userID := c.GetInt("user_id")
+		if userID <= 0 || c.Request.Method == http.MethodOptions {
+			return
+		}
+		// Requests rejected by auth or permission checks are not usage
+		if status := c.Writer.Status(); status == http.StatusUnauthorized || status == http.StatusForbidden {
+			return
+		}
+
+		path := c.FullPath()
+		cluster := ""
+		if strings.HasPrefix(path, "/api/v1/clusters/:name/") {
+			cluster = c.Param("name")
+		}
+
+		counts := rollupCounts{requests: 1}
+		switch c.Request.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+			counts.mutations = 1
+		}
+		if strings.HasSuffix(path, "/shell") {
+			counts.shells = 1
+		}
+
+		t.record(rollupKey{day: time.Now().UTC().Format(DayFormat), userID: uint(userID), cluster: cluster}, counts)
+	}
+}
+
+func (t *Tracker) record(key rollupKey, counts rollupCounts) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	current, ok := t.pending[key]
+	if !ok {
+		current = &rollupCounts{}
+		t.pending[key] = current
+	}
+	current.requests += counts.requests
+	current.mutations += counts.mutations
+	current.shells += counts.shells
+}
+
+// Start starts the periodic flush and retention loop
+func (t *Tracker) Start() {
+	go func() {
+		flushTicker := time.NewTicker(t.flushInterval)
+		retentionTicker := time.NewTicker(24 * time.Hour)
+		defer flushTicker.Stop()
+		defer retentionTicker.Stop()
+
+		t.enforceRetention()
+		for {
+			select {
+			case <-flushTicker.C:
+				t.flush()
+			case <-retentionTicker.C:
+				t.enforceRetention()
+			case <-t.done:
+				return
+			}
+		}
+	}()
+
+	log.Infof("✅ Usage tracker started (flush interval: %v)", t.flushInterval)
+}
+
+// Stop flushes pending counts and stops the tracker
+func (t *Tracker) Stop() {
+	close(t.done)
+	t.flush()
+	log.Info("Usage tracker stopped")
+}
+
+// flush writes pending counts to the database. Counts that fail to write are kept for the next flush.
+func (t *Tracker) flush() {
+	t.mu.Lock()
+	pending := t.pending
+	t.pending = make(map[rollupKey]*rollupCounts)
+	t.mu.Unlock()
+
+	for key, counts := range pending {
+		if err := t.db.IncrementUsageRollup(key.day, key.userID, key.cluster, counts.requests, counts.mutations, counts.shells); err != nil {
+			log.Errorf("Failed to write usage rollup: %v", err)
+			t.record(key, *counts)
+		}
+	}
+}
+
+// enforceRetention deletes rollups older than the retention window
+func (t *Tracker) enforceRetention() {
+	if t.retentionDays <= 0 {
+		return
+	}
+	cutoff := time.Now().UTC().AddDate(0, 0, -t.retentionDays).Format(DayFormat)
+	if deleted, err := t.db.DeleteUsageRollupsBefore(cutoff); err != nil {
+		log.Errorf("Failed to delete old usage rollups: %v", err)
+	} else if deleted > 0 {
+		log.Infof("Deleted %d usage rollups older than %d days", deleted, t.retentionDays)
+	}
+}
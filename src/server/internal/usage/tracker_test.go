@@ -0,0 +1,55 @@
+package usage
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestTrackerMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tracker := NewTracker(nil, time.Minute, 0)
+	router := gin.New()
+	api := router.Group("/api/v1", tracker.Middleware(), func(c *gin.Context) {
+		if c.GetHeader("X-User") == "7" {
+			c.Set("user_id", 7)
+		}
+	})
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	api.GET("/clusters/:name/pods", ok)
+	api.DELETE("/clusters/:name/pods/:pod", ok)
+	api.GET("/clusters/:name/nodes/:node/shell", ok)
+	api.GET("/denied", func(c *gin.Context) { c.Status(http.StatusForbidden) })
+	api.GET("/me", ok)
+
+	requests := []struct{ method, path, user string }{
+		{http.MethodGet, "/api/v1/clusters/prod/pods", "7"},
+		{http.MethodDelete, "/api/v1/clusters/prod/pods/web", "7"},
+		{http.MethodGet, "/api/v1/clusters/prod/nodes/n1/shell", "7"},
+		{http.MethodGet, "/api/v1/denied", "7"},
+		{http.MethodGet, "/api/v1/me", "7"},
+		{http.MethodGet, "/api/v1/me", ""},
+	}
+	for _, r := range requests {
+		req := httptest.NewRequest(r.method, r.path, nil)
+		req.Header.Set("X-User", r.user)
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	day := time.Now().UTC().Format(DayFormat)
+	prod := tracker.pending[rollupKey{day: day, userID: 7, cluster: "prod"}]
+	if prod == nil || prod.requests != 3 || prod.mutations != 1 || prod.shells != 1 {
+		t.Errorf("prod counts = %+v, want 3 requests, 1 mutation, 1 shell", prod)
+	}
+	global := tracker.pending[rollupKey{day: day, userID: 7}]
+	if global == nil || global.requests != 1 {
+		t.Errorf("non-cluster counts = %+v, want 1 request", global)
+	}
+	if len(tracker.pending) != 2 {
+		t.Errorf("pending keys = %d, want 2", len(tracker.pending))
+	}
+}
@@ -1,7 +1,12 @@
 package ws
 
 import (
+	"encoding/json"
+	"fmt"
 	"net/http"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -40,6 +45,240 @@ type Client struct {
 
 	// Buffered channel of outbound messages
 	send chan []byte
+
+	// ID of the authenticated user that owns the connection
+	userID int
+
+	// scope limits the topics the owner may receive; nil when the owner is not restricted
+	scope Scope
+
+	// Negotiated protocol version and capabilities, guarded by mu. mu also serializes
+	// deliveries so sequence numbers reach the send channel in order.
+	mu           sync.Mutex
+	version      int
+	capabilities map[string]bool
+	seq          uint64
+
+	// Topic patterns the connection subscribed to, guarded by mu. A connection only receives
+	// the messages of the topics it subscribed to, and those without a topic.
+	topics map[string]bool
+
+	// resume is the cursor of the last message a reconnecting client received
+	resume string
+}
+
+// outbound is a message queued for delivery. It is encoded per client, according to
+// the protocol version that client negotiated.
+type outbound struct {
+	msgType string
+	topic   string
+	payload interface{} // []byte for untyped messages
+
+	// Set by the hub when it sends the message: its number, its number in the topic and
+	// the cursor clients resume from. userID is the user a message was sent to.
+	seq      uint64
+	topicSeq uint64
+	cursor   string
+	userID   int
+}
+
+// setProtocol records the result of a negotiation
+func (c *Client) setProtocol(version int, capabilities []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.version = version
+	c.capabilities = make(map[string]bool, len(capabilities))
+	for _, capability := range capabilities {
+		c.capabilities[capability] = true
+	}
+}
+
+// protocol returns the negotiated protocol version
+func (c *Client) protocol() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.version
+}
+
+// wants reports whether the client receives messages published on a topic: it subscribed
+// to the topic and its scope allows it. Messages without a topic go to every client.
+func (c *Client) wants(topic string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if topic == "" {
+		return true
+	}
+	if c.scope != nil && !allowedTopic(c.scope, topic) {
+		return false
+	}
+	for pattern := range c.topics {
+		if matchTopic(pattern, topic) {
+			return true
+		}
+	}
+	return false
+}
+
+// subscribe adds topic patterns to the subscriptions of the client. Patterns naming a
+// cluster or namespace outside the scope of the client are refused.
+func (c *Client) subscribe(patterns []string) error {
+	for _, pattern := range patterns {
+		if err := validatePattern(pattern); err != nil {
+			return err
+		}
+		if c.scope != nil && !allowedPattern(c.scope, pattern) {
+			return fmt.Errorf("no access to topic %q", pattern)
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.topics == nil {
+		c.topics = make(map[string]bool, len(patterns))
+	}
+	for _, pattern := range patterns {
+		if !c.topics[pattern] && len(c.topics) >= maxSubscriptions {
+			return fmt.Errorf("at most %d topic patterns can be subscribed to", maxSubscriptions)
+		}
+		c.topics[pattern] = true
+	}
+	return nil
+}
+
+// unsubscribe removes topic patterns from the subscriptions of the client. A client that
+// unsubscribes from everything receives only messages without a topic.
+func (c *Client) unsubscribe(patterns []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, pattern := range patterns {
+		delete(c.topics, pattern)
+	}
+}
+
+// subscriptions returns the topic patterns of the client, sorted
+func (c *Client) subscriptions() Subscription {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	topics := make([]string, 0, len(c.topics))
+	for pattern := range c.topics {
+		topics = append(topics, pattern)
+	}
+	sort.Strings(topics)
+	return Subscription{Topics: topics}
+}
+
+// batching reports whether several messages may share one frame. Legacy clients
+// always received batched frames, so they keep doing so.
+func (c *Client) batching() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.version == ProtocolV1 || c.capabilities[CapabilityBatch]
+}
+
+// deliver encodes a message for this client and queues it. It returns false when the
+// client's send buffer is full.
+func (c *Client) deliver(m outbound) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var data []byte
+	var err error
+	if c.version >= ProtocolV2 {
+		var payload json.RawMessage
+		if payload, err = encodePayload(m.payload); err == nil {
+			c.seq++
+			data, err = json.Marshal(Envelope{
+				Type:    m.msgType,
+				Version: c.version,
+				Topic:    m.topic,
+				Seq:      c.seq,
+				TopicSeq: m.topicSeq,
+				Cursor:   m.cursor,
+				Payload:  payload,
+			})
+		}
+	} else if raw, ok := m.payload.([]byte); ok {
+		data = raw
+	} else {
+		data, err = json.Marshal(m.payload)
+	}
+	if err != nil {
+		log.Errorf("Failed to encode WebSocket message %q: %v", m.msgType, err)
+		return true
+	}
+
+	select {
+	case c.send <- data:
+		return true
+	default:
+		return false
+	}
+}
+
+// handleControl processes protocol messages from the client. It returns false for
+// messages of legacy clients that are not part of the protocol.
+func (c *Client) handleControl(message []byte) bool {
+	var envelope Envelope
+	if err := json.Unmarshal(message, &envelope); err != nil || envelope.Type == "" {
+		if c.protocol() >= ProtocolV2 {
+			c.deliver(outbound{msgType: TypeError, payload: map[string]string{"error": "message is not a valid envelope"}})
+			return true
+		}
+		return false
+	}
+
+	switch envelope.Type {
+	case TypeHello:
+		var hello Hello
+		if len(envelope.Payload) > 0 {
+			json.Unmarshal(envelope.Payload, &hello)
+		}
+		if hello.Version == 0 {
+			hello.Version = envelope.Version
+		}
+		c.welcome(hello)
+		return true
+
+	case TypeSubscribe, TypeUnsubscribe:
+		var subscription Subscription
+		if len(envelope.Payload) > 0 {
+			json.Unmarshal(envelope.Payload, &subscription)
+		}
+		if envelope.Type == TypeUnsubscribe {
+			c.unsubscribe(subscription.Topics)
+		} else if err := c.subscribe(subscription.Topics); err != nil {
+			c.deliver(outbound{msgType: TypeError, payload: map[string]string{"error": err.Error()}})
+			return true
+		}
+		c.deliver(outbound{msgType: TypeSubscribed, payload: c.subscriptions()})
+		return true
+
+	case TypePing:
+		if c.protocol() >= ProtocolV2 {
+			c.deliver(outbound{msgType: TypePong, topic: envelope.Topic, payload: envelope.Payload})
+			return true
+		}
+	}
+
+	if c.protocol() >= ProtocolV2 {
+		c.deliver(outbound{msgType: TypeError, payload: map[string]string{"error": "unsupported message type: " + envelope.Type}})
+		return true
+	}
+	return false
+}
+
+// welcome negotiates the protocol from a client hello and answers with the result
+func (c *Client) welcome(hello Hello) {
+	version, capabilities := negotiate(hello.Version, hello.Capabilities)
+	c.setProtocol(version, capabilities)
+	c.deliver(outbound{msgType: TypeWelcome, payload: Welcome{
+		Version:            version,
+		Capabilities:       capabilities,
+		ServerCapabilities: serverCapabilities,
+		LatestVersion:      LatestProtocol,
+	}})
 }
 
 // readPump pumps messages from the websocket connection to the hub
@@ -65,8 +304,12 @@ func (c *Client) readPump() {
 			break
 		}
 
+		if c.handleControl(message) {
+			continue
+		}
+
 		// Echo message back for now (can be extended for specific commands)
-		c.hub.broadcast <- message
+		c.hub.broadcast <- outbound{msgType: TypeRaw, payload: message}
 	}
 }
 
@@ -95,10 +338,12 @@ func (c *Client) writePump() {
 			w.Write(message)
 
 			// Add queued messages to the current websocket message
-			n := len(c.send)
-			for i := 0; i < n; i++ {
-				w.Write([]byte{'\n'})
-				w.Write(<-c.send)
+			if c.batching() {
+				n := len(c.send)
+				for i := 0; i < n; i++ {
+					w.Write([]byte{'\n'})
+					w.Write(<-c.send)
+				}
 			}
 
 			if err := w.Close(); err != nil {
@@ -115,7 +360,14 @@ func (c *Client) writePump() {
 }
 
 // ServeWs handles websocket requests from the peer
-func ServeWs(hub *Hub, w http.ResponseWriter, r *http.Request) {
+// userID identifies the authenticated user so the hub can target messages at them, and
+// scope (nil for users without restrictions) limits the topics they receive.
+// Clients speak ProtocolV1 unless they negotiate a newer version, either with
+// ?protocol=2&capabilities=batch on the URL or with a hello message after connecting.
+// They receive the messages of the topics they subscribe to, with ?topics=events/prod,clusters
+// or with subscribe messages. Reconnecting clients pass the cursor of the last envelope they
+// received as ?since= to get the messages they missed.
+func ServeWs(hub *Hub, userID int, scope Scope, w http.ResponseWriter, r *http.Request) {
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		log.Errorf("Failed to upgrade connection: %v", err)
@@ -125,7 +377,23 @@ func ServeWs(hub *Hub, w http.ResponseWriter, r *http.Request) {
 	client := &Client{
 		hub:  hub,
 		conn: conn,
-		send: make(chan []byte, 256),
+		send:   make(chan []byte, 256),
+		userID: userID,
+		scope:   scope,
+		version: ProtocolV1,
+		resume:  r.URL.Query().Get("since"),
+	}
+
+	query := r.URL.Query()
+	if hello, ok := helloFromQuery(query.Get("protocol"), query.Get("capabilities")); ok {
+		client.welcome(hello)
+	}
+	if topics := query.Get("topics"); topics != "" {
+		if err := client.subscribe(strings.Split(topics, ",")); err != nil {
+			conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.ClosePolicyViolation, err.Error()))
+			conn.Close()
+			return
+		}
 	}
 
 	client.hub.register <- client
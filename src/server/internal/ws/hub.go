@@ -1,18 +1,27 @@
 package ws
 
 import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
 	"sync"
 
 	log "github.com/sirupsen/logrus"
 )
 
-// Hub maintains the set of active clients and broadcasts messages to the clients
+// replaySize is how many recent messages the hub keeps for clients that reconnect
+const replaySize = 1024
+
+// Hub maintains the set of active clients and sends them the messages published on the
+// topics they subscribed to
 type Hub struct {
 	// Registered clients
 	clients map[*Client]bool
 
-	// Inbound messages from the clients
-	broadcast chan []byte
+	// Messages to send to every client
+	broadcast chan outbound
 
 	// Register requests from the clients
 	register chan *Client
@@ -20,25 +29,50 @@ type Hub struct {
 	// Unregister requests from clients
 	unregister chan *Client
 
+	// epoch tells this hub's cursors from those of a previous process or another replica
+	epoch string
+	// seq numbers every message; topicSeqs numbers the messages of each topic, and those
+	// of a topic sent to each user
+	seq       uint64
+	topicSeqs map[string]uint64
+	// history holds the last replaySize messages, oldest first
+	history []outbound
+
+	// mu guards the clients and the numbering, so a message is numbered, kept and
+	// delivered before the next one and before a client registers
 	mu sync.RWMutex
 }
 
 // NewHub creates a new Hub
 func NewHub() *Hub {
+	b := make([]byte, 4)
+	rand.Read(b)
 	return &Hub{
-		broadcast:  make(chan []byte, 256),
+		broadcast:  make(chan outbound, 256),
 		register:   make(chan *Client),
 		unregister: make(chan *Client),
 		clients:    make(map[*Client]bool),
+		epoch:      hex.EncodeToString(b),
+		topicSeqs:  make(map[string]uint64),
 	}
 }
 
+// ClientCount returns the number of connected clients
+func (h *Hub) ClientCount() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.clients)
+}
+
 // Run starts the hub
 func (h *Hub) Run() {
 	for {
 		select {
 		case client := <-h.register:
 			h.mu.Lock()
+			if client.resume != "" {
+				h.replay(client)
+			}
 			h.clients[client] = true
 			h.mu.Unlock()
 			log.Infof("WebSocket client connected (total: %d)", len(h.clients))
@@ -53,22 +87,135 @@ func (h *Hub) Run() {
 			log.Infof("WebSocket client disconnected (total: %d)", len(h.clients))
 
 		case message := <-h.broadcast:
-			h.mu.RLock()
+			h.mu.Lock()
+			h.stamp(&message)
+			var slow []*Client
 			for client := range h.clients {
-				select {
-				case client.send <- message:
-				default:
-					close(client.send)
-					delete(h.clients, client)
+				if !client.wants(message.topic) {
+					continue
 				}
+				if !client.deliver(message) {
+					slow = append(slow, client)
+				}
+			}
+
+			// Drop clients that cannot keep up
+			for _, client := range slow {
+				close(client.send)
+				delete(h.clients, client)
 			}
-			h.mu.RUnlock()
+			h.mu.Unlock()
+		}
+	}
+}
+
+// stamp numbers a message and keeps it for replays. h.mu must be held.
+func (h *Hub) stamp(m *outbound) {
+	h.seq++
+	m.seq = h.seq
+	m.cursor = h.epoch + "-" + strconv.FormatUint(h.seq, 10)
+	if m.topic != "" {
+		// Messages sent to a user are numbered apart, so each user sees no gaps
+		key := m.topic
+		if m.userID != 0 {
+			key = fmt.Sprintf("%s@%d", m.topic, m.userID)
 		}
+		h.topicSeqs[key]++
+		m.topicSeq = h.topicSeqs[key]
+	}
+	if len(h.history) == replaySize {
+		h.history = append(h.history[:0], h.history[1:]...)
 	}
+	h.history = append(h.history, *m)
 }
 
-// Broadcast sends a message to all connected clients
+// replay delivers to a reconnecting client the messages it missed since its cursor, or a
+// reset message when they are no longer all kept. h.mu must be held.
+func (h *Hub) replay(client *Client) {
+	seq, err := h.parseCursor(client.resume)
+	if err != nil {
+		h.reset(client, err.Error())
+		return
+	}
+
+	var missed []outbound
+	for _, m := range h.history {
+		if m.seq <= seq {
+			continue
+		}
+		// Messages sent to a user reach their connections whatever the subscriptions
+		if (m.userID == 0 && client.wants(m.topic)) || (m.userID != 0 && m.userID == client.userID) {
+			missed = append(missed, m)
+		}
+	}
+	if len(missed) > cap(client.send)-len(client.send) {
+		h.reset(client, "too many messages were missed")
+		return
+	}
+	for _, m := range missed {
+		client.deliver(m)
+	}
+}
+
+// parseCursor returns the number of the message a cursor points at. Cursors of another
+// epoch, and those older than the history, cannot be resumed from.
+func (h *Hub) parseCursor(cursor string) (uint64, error) {
+	epoch, number, found := strings.Cut(cursor, "-")
+	if !found {
+		epoch, number = h.epoch, cursor
+	}
+	seq, err := strconv.ParseUint(number, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid cursor %q", cursor)
+	}
+	if epoch != h.epoch || seq > h.seq {
+		return 0, fmt.Errorf("the server restarted")
+	}
+	if oldest := h.seq - uint64(len(h.history)); seq < oldest {
+		return 0, fmt.Errorf("missed messages are no longer kept")
+	}
+	return seq, nil
+}
+
+// reset tells a client it cannot resume and must reload its state. Messages after the
+// cursor of the reset follow.
+func (h *Hub) reset(client *Client, reason string) {
+	client.deliver(outbound{msgType: TypeReset, payload: Reset{
+		Reason: reason,
+		Cursor: h.epoch + "-" + strconv.FormatUint(h.seq, 10),
+	}})
+}
+
+// Broadcast sends an untyped message without a topic to all connected clients
 func (h *Hub) Broadcast(message []byte) {
-	h.broadcast <- message
+	h.broadcast <- outbound{msgType: TypeRaw, payload: message}
+}
+
+// Publish sends a typed message on a topic to the connected clients subscribed to it
+func (h *Hub) Publish(topic, msgType string, payload interface{}) {
+	h.broadcast <- outbound{msgType: msgType, topic: topic, payload: payload}
+}
+
+// SendToUser sends an untyped message to all connections owned by the given user
+func (h *Hub) SendToUser(userID int, message []byte) {
+	h.PublishToUser(userID, "", TypeRaw, message)
 }
 
+// PublishToUser sends a typed message on a topic to all connections owned by the given user,
+// whatever their subscriptions. Legacy clients receive the bare payload, newer clients an
+// Envelope.
+func (h *Hub) PublishToUser(userID int, topic, msgType string, payload interface{}) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	message := outbound{msgType: msgType, topic: topic, payload: payload, userID: userID}
+	h.stamp(&message)
+	for client := range h.clients {
+		if client.userID != userID {
+			continue
+		}
+		if !client.deliver(message) {
+			log.Warnf("WebSocket send buffer full for user %d, dropping message", userID)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package ws
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// Protocol versions spoken on the hub websocket
+const (
+	// ProtocolV1 sends bare JSON payloads. Clients that do not negotiate get this version.
+	ProtocolV1 = 1
+
+	// ProtocolV2 wraps every message in an Envelope with a type, topic and sequence number
+	ProtocolV2 = 2
+
+	// LatestProtocol is the newest version the server speaks
+	LatestProtocol = ProtocolV2
+)
+
+// Capabilities a client can ask for during negotiation
+const (
+	// CapabilityBatch allows several envelopes in one frame, separated by newlines
+	CapabilityBatch = "batch"
+
+	// CapabilityTopics allows subscribing to topics instead of receiving every message
+	CapabilityTopics = "topics"
+)
+
+// serverCapabilities lists the capabilities this server supports
+var serverCapabilities = []string{CapabilityBatch, CapabilityTopics}
+
+// Control message types
+const (
+	TypeHello   = "hello"
+	TypeWelcome = "welcome"
+	TypePing    = "ping"
+	TypePong    = "pong"
+	TypeError   = "error"
+
+	// Topic subscriptions. The server answers both with a subscribed message listing the
+	// topic patterns of the connection.
+	TypeSubscribe   = "subscribe"
+	TypeUnsubscribe = "unsubscribe"
+	TypeSubscribed  = "subscribed"
+
+	// TypeReset tells a reconnecting client the messages it missed cannot be replayed, so
+	// it must reload its state
+	TypeReset = "reset"
+
+	// TypeRaw wraps payloads sent through the untyped Broadcast and SendToUser helpers
+	TypeRaw = "raw"
+)
+
+// Envelope is the ProtocolV2 wire format
+type Envelope struct {
+	Type    string `json:"type"`
+	Version int    `json:"version"`
+	Topic   string `json:"topic,omitempty"`
+	Seq     uint64 `json:"seq,omitempty"`
+	// TopicSeq numbers the messages of the topic, without gaps
+	TopicSeq uint64 `json:"topic_seq,omitempty"`
+	// Cursor identifies the message for ?since= when reconnecting
+	Cursor  string          `json:"cursor,omitempty"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// Reset is the payload of a reset message. Cursor is that of the last message sent before it.
+type Reset struct {
+	Reason string `json:"reason"`
+	Cursor string `json:"cursor"`
+}
+
+// Hello is the payload of a client hello
+type Hello struct {
+	Version      int      `json:"version"`
+	Capabilities []string `json:"capabilities"`
+}
+
+// Welcome is the payload the server answers a hello with
+type Welcome struct {
+	Version            int      `json:"version"`
+	Capabilities       []string `json:"capabilities"`
+	ServerCapabilities []string `json:"server_capabilities"`
+	LatestVersion      int      `json:"latest_version"`
+}
+
+// negotiate picks the protocol version and the capabilities both sides support.
+// Versions above LatestProtocol are downgraded and unknown capabilities are dropped.
+func negotiate(requested int, capabilities []string) (int, []string) {
+	version := requested
+	if version < ProtocolV1 {
+		version = ProtocolV1
+	}
+	if version > LatestProtocol {
+		version = LatestProtocol
+	}
+
+	agreed := []string{}
+	for _, want := range capabilities {
+		want = strings.TrimSpace(want)
+		for _, have := range serverCapabilities {
+			if want == have {
+				agreed = append(agreed, have)
+				break
+			}
+		}
+	}
+	return version, agreed
+}
+
+// helloFromQuery reads a hello from the connect URL: ?protocol=2&capabilities=batch
+// Browsers use this form so the first message can already be an envelope.
+func helloFromQuery(protocol, capabilities string) (Hello, bool) {
+	if protocol == "" {
+		return Hello{}, false
+	}
+	version, err := strconv.Atoi(protocol)
+	if err != nil {
+		return Hello{}, false
+	}
+
+	hello := Hello{Version: version}
+	if capabilities != "" {
+		hello.Capabilities = strings.Split(capabilities, ",")
+	}
+	return hello, true
+}
+
+// encodePayload marshals a payload for an envelope. Raw bytes that are not valid JSON
+// are sent as a JSON string.
+func encodePayload(payload interface{}) (json.RawMessage, error) {
+	if raw, ok := payload.([]byte); ok {
+		if json.Valid(raw) {
+			return json.RawMessage(raw), nil
+		}
+		return json.Marshal(string(raw))
+	}
+	return json.Marshal(payload)
+}
@@ -0,0 +1,286 @@
+package ws
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNegotiate(t *testing.T) {
+	version, capabilities := negotiate(7, []string{"batch", "telepathy"})
+	if version != LatestProtocol {
+		t.Errorf("version = %d, want %d", version, LatestProtocol)
+	}
+	if !reflect.DeepEqual(capabilities, []string{CapabilityBatch}) {
+		t.Errorf("capabilities = %v, want [batch]", capabilities)
+	}
+
+	if version, _ := negotiate(0, nil); version != ProtocolV1 {
+		t.Errorf("version = %d, want %d", version, ProtocolV1)
+	}
+}
+
+func TestClientDeliver(t *testing.T) {
+	legacy := &Client{send: make(chan []byte, 2), version: ProtocolV1}
+	legacy.deliver(outbound{msgType: "edit_session.deleted", topic: "edit_sessions", payload: map[string]string{"type": "edit_session.deleted"}})
+	if got := string(<-legacy.send); got != `{"type":"edit_session.deleted"}` {
+		t.Errorf("legacy message = %s, want bare payload", got)
+	}
+
+	client := &Client{send: make(chan []byte, 2), version: ProtocolV1}
+	client.setProtocol(ProtocolV2, nil)
+	client.deliver(outbound{msgType: TypeRaw, payload: []byte(`{"a":1}`)})
+	client.deliver(outbound{msgType: TypeRaw, payload: []byte("not json")})
+
+	for i, wantPayload := range []string{`{"a":1}`, `"not json"`} {
+		var envelope Envelope
+		if err := json.Unmarshal(<-client.send, &envelope); err != nil {
+			t.Fatalf("message %d is not an envelope: %v", i, err)
+		}
+		if envelope.Seq != uint64(i+1) || envelope.Version != ProtocolV2 || string(envelope.Payload) != wantPayload {
+			t.Errorf("envelope %d = %+v (payload %s), want seq %d and payload %s", i, envelope, envelope.Payload, i+1, wantPayload)
+		}
+	}
+
+	if client.batching() {
+		t.Error("v2 client without the batch capability should not get batched frames")
+	}
+}
+
+func TestTopics(t *testing.T) {
+	topic := Topic("events", "prod", "", "Node")
+	if topic != "events/prod/_/Node" {
+		t.Fatalf("Topic() = %s", topic)
+	}
+	for _, tc := range []struct {
+		pattern string
+		want    bool
+	}{
+		{"events", true},
+		{"events/prod", true},
+		{"events/*/_/Node", true},
+		{"events/prod/_/Node", true},
+		{"events/staging", false},
+		{"events/prod/_/Node/extra", false},
+		{"event", false},
+	} {
+		if got := matchTopic(tc.pattern, topic); got != tc.want {
+			t.Errorf("matchTopic(%s, %s) = %v, want %v", tc.pattern, topic, got, tc.want)
+		}
+	}
+}
+
+func TestClientSubscriptions(t *testing.T) {
+	client := &Client{send: make(chan []byte, 4), version: ProtocolV1}
+	if client.wants("events/prod/default/Pod") || !client.wants("") {
+		t.Error("a client that never subscribed should only receive messages without a topic")
+	}
+
+	client.handleControl([]byte(`{"type":"subscribe","payload":{"topics":["events/prod","clusters/*"]}}`))
+	var subscribed Subscription
+	if err := json.Unmarshal(<-client.send, &subscribed); err != nil || len(subscribed.Topics) != 2 {
+		t.Fatalf("subscribed = %+v (%v), want both patterns", subscribed, err)
+	}
+	for topic, want := range map[string]bool{
+		"events/prod/default/Pod":    true,
+		"events/staging/default/Pod": false,
+		"clusters/staging":           true,
+		"":                           true,
+	} {
+		if got := client.wants(topic); got != want {
+			t.Errorf("wants(%q) = %v, want %v", topic, got, want)
+		}
+	}
+
+	client.handleControl([]byte(`{"type":"unsubscribe","payload":{"topics":["events/prod"]}}`))
+	<-client.send
+	if client.wants("events/prod/default/Pod") {
+		t.Error("unsubscribed topic still received")
+	}
+
+	if err := client.subscribe([]string{"events/pro*"}); err == nil {
+		t.Error("expected error for a partial wildcard")
+	}
+}
+
+func TestHubFiltersTopics(t *testing.T) {
+	hub := NewHub()
+	go hub.Run()
+	none := &Client{hub: hub, send: make(chan []byte, 4), version: ProtocolV1}
+	all := &Client{hub: hub, send: make(chan []byte, 4), version: ProtocolV1}
+	all.subscribe([]string{"events"})
+	prod := &Client{hub: hub, send: make(chan []byte, 4), version: ProtocolV1}
+	prod.subscribe([]string{"events/prod"})
+	hub.register <- none
+	hub.register <- all
+	hub.register <- prod
+
+	hub.Publish("events/staging/default/Pod", "cluster_event", "staging")
+	hub.Publish("events/prod/default/Pod", "cluster_event", "prod")
+	hub.Broadcast([]byte(`"everyone"`))
+	if got := string(<-prod.send); got != `"prod"` {
+		t.Errorf("subscribed client got %s, want only the prod event", got)
+	}
+	if got := string(<-all.send) + string(<-all.send); got != `"staging""prod"` {
+		t.Errorf("client subscribed to every event got %s, want both events", got)
+	}
+	if got := string(<-none.send); got != `"everyone"` {
+		t.Errorf("client without subscriptions got %s, want only the message without a topic", got)
+	}
+}
+
+// teamScope allows reading anything in the team-a namespace of prod
+type teamScope struct{}
+
+func (teamScope) Allows(cluster, resource, namespace string) bool {
+	return cluster == "prod" && namespace == "team-a"
+}
+
+func (teamScope) AllowsSomewhere(cluster, resource string) bool {
+	return cluster == "prod"
+}
+
+func TestClientScope(t *testing.T) {
+	client := &Client{send: make(chan []byte, 4), version: ProtocolV1, scope: teamScope{}}
+	for pattern, want := range map[string]bool{
+		"events":             true,
+		"events/*/team-a":    true,
+		"events/prod":        true,
+		"events/prod/team-a": true,
+		"events/prod/team-b": false,
+		"events/prod/_/Node": false,
+		"events/staging":     false,
+		"clusters/staging":   false,
+		"*":                  true,
+	} {
+		if err := client.subscribe([]string{pattern}); (err == nil) != want {
+			t.Errorf("subscribe(%s) error = %v, want allowed %v", pattern, err, want)
+		}
+	}
+
+	for topic, want := range map[string]bool{
+		"events/prod/team-a/Pod":    true,
+		"events/prod/team-b/Pod":    false,
+		"events/prod/_/Node":        false,
+		"events/staging/team-a/Pod": false,
+		"clusters/prod":             true,
+		"clusters/staging":          false,
+		"edit_sessions":             false,
+		"":                          true,
+	} {
+		if got := client.wants(topic); got != want {
+			t.Errorf("wants(%q) = %v, want %v", topic, got, want)
+		}
+	}
+}
+
+func TestHubScopesClusterStatus(t *testing.T) {
+	hub := NewHub()
+	go hub.Run()
+	admin := &Client{hub: hub, send: make(chan []byte, 4), version: ProtocolV1}
+	admin.subscribe([]string{ClustersTopic})
+	team := &Client{hub: hub, send: make(chan []byte, 4), version: ProtocolV1, scope: teamScope{}}
+	team.subscribe([]string{ClustersTopic})
+	hub.register <- admin
+	hub.register <- team
+
+	hub.Publish(Topic(ClustersTopic, "staging"), "cluster_status", "staging")
+	hub.Publish(Topic(ClustersTopic, "prod"), "cluster_status", "prod")
+	if got := string(<-team.send); got != `"prod"` {
+		t.Errorf("scoped client got %s, want only the status of prod", got)
+	}
+	if got := string(<-admin.send) + string(<-admin.send); got != `"staging""prod"` {
+		t.Errorf("unrestricted client got %s, want both statuses", got)
+	}
+}
+
+func TestServeSSE(t *testing.T) {
+	hub := NewHub()
+	go hub.Run()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ServeSSE(hub, 1, nil, w, r)
+	}))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "?transport=sse&topics=clusters")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	for hub.ClientCount() == 0 {
+		time.Sleep(10 * time.Millisecond)
+	}
+	hub.Publish("events/prod/default/Pod", "cluster_event", "skipped")
+	hub.Publish("clusters/prod", "cluster_status", map[string]string{"status": "connected"})
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		data, found := strings.CutPrefix(scanner.Text(), "data: ")
+		if !found {
+			continue
+		}
+		var envelope Envelope
+		if err := json.Unmarshal([]byte(data), &envelope); err != nil {
+			t.Fatalf("event data %s is not an envelope: %v", data, err)
+		}
+		if envelope.Type != "cluster_status" || envelope.Topic != "clusters/prod" || envelope.Seq != 1 {
+			t.Errorf("envelope = %+v, want the cluster status only", envelope)
+		}
+		return
+	}
+	t.Fatal("stream ended without an event")
+}
+
+// envelopes waits for n envelopes queued for a client and decodes them
+func envelopes(t *testing.T, client *Client, n int) []Envelope {
+	t.Helper()
+	for deadline := time.Now().Add(time.Second); len(client.send) < n && time.Now().Before(deadline); {
+		time.Sleep(5 * time.Millisecond)
+	}
+	var got []Envelope
+	for len(client.send) > 0 {
+		var envelope Envelope
+		if err := json.Unmarshal(<-client.send, &envelope); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, envelope)
+	}
+	return got
+}
+
+func TestHubResume(t *testing.T) {
+	hub := NewHub()
+	go hub.Run()
+	first := &Client{hub: hub, send: make(chan []byte, 8), userID: 1, version: ProtocolV2}
+	first.subscribe([]string{"clusters"})
+	hub.register <- first
+
+	hub.Publish("clusters/prod", "cluster_status", "a")
+	hub.Publish("events/prod/default/Pod", "cluster_event", "skipped")
+	hub.Publish("clusters/prod", "cluster_status", "b")
+	hub.PublishToUser(2, "edit_sessions", "edit_session.deleted", "other user")
+	received := envelopes(t, first, 2)
+	if len(received) != 2 || received[1].TopicSeq != 2 || received[1].Cursor == "" {
+		t.Fatalf("received %+v, want both cluster statuses numbered in their topic", received)
+	}
+
+	// The client reconnects after the first status and gets the second one again
+	resumed := &Client{hub: hub, send: make(chan []byte, 8), userID: 1, version: ProtocolV2, resume: received[0].Cursor}
+	resumed.subscribe([]string{"clusters"})
+	hub.register <- resumed
+	if replayed := envelopes(t, resumed, 1); len(replayed) != 1 || replayed[0].Cursor != received[1].Cursor {
+		t.Errorf("replayed %+v, want the status after the cursor", replayed)
+	}
+
+	// A cursor of another process cannot be resumed from
+	restarted := &Client{hub: hub, send: make(chan []byte, 8), userID: 1, version: ProtocolV2, resume: "0000-1"}
+	hub.register <- restarted
+	if replayed := envelopes(t, restarted, 1); len(replayed) != 1 || replayed[0].Type != TypeReset {
+		t.Errorf("replayed %+v, want a reset", replayed)
+	}
+}
@@ -0,0 +1,70 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/sonnguyen/kubelens/internal/sse"
+)
+
+// ServeSSE streams the messages of the hub as server-sent events, for clients behind
+// proxies that break WebSockets. Each message is a ProtocolV2 Envelope in the data of a
+// default ("message") event, with its cursor as event ID so reconnecting clients resume
+// with Last-Event-ID. The stream cannot carry subscribe messages, so the topics are set
+// with ?topics= on the URL; clients reconnect to change them. scope limits the topics as for
+// ServeWs.
+func ServeSSE(hub *Hub, userID int, scope Scope, w http.ResponseWriter, r *http.Request) {
+	client := &Client{
+		hub:     hub,
+		send:    make(chan []byte, 256),
+		userID:  userID,
+		scope:   scope,
+		version: ProtocolV2,
+		resume:  r.Header.Get("Last-Event-ID"),
+	}
+	if since := r.URL.Query().Get("since"); since != "" {
+		client.resume = since
+	}
+	if topics := r.URL.Query().Get("topics"); topics != "" {
+		if err := client.subscribe(strings.Split(topics, ",")); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	stream, err := sse.Start(w, 3*time.Second)
+	if err != nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	go stream.KeepAlive(ctx)
+
+	hub.register <- client
+	for {
+		select {
+		case message, ok := <-client.send:
+			if !ok {
+				// The hub dropped the client
+				return
+			}
+			var envelope struct {
+				Cursor string `json:"cursor"`
+			}
+			json.Unmarshal(message, &envelope)
+			if err := stream.Event(envelope.Cursor, "", message); err != nil {
+				log.Debugf("Failed to write server-sent event: %v", err)
+				hub.unregister <- client
+				return
+			}
+		case <-ctx.Done():
+			hub.unregister <- client
+			return
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package ws
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Topics are paths of segments separated by slashes, from the most general:
+// events/<cluster>/<namespace>/<kind>. Clients subscribe to patterns of topics, in which *
+// matches any one segment and a pattern matches the topics below it, so events/prod gets
+// every event of the prod cluster and events/*/default/Pod the events of pods in default.
+
+// ClusterScoped is the namespace segment of topics about cluster-scoped objects
+const ClusterScoped = "_"
+
+// The first segments of the topics about clusters, which connections with a Scope only
+// receive for the clusters and namespaces it allows
+const (
+	// EventsTopic is the prefix of events/<cluster>/<namespace>/<kind>
+	EventsTopic = "events"
+	// ClustersTopic is the prefix of clusters/<cluster>, the status of each cluster
+	ClustersTopic = "clusters"
+)
+
+// maxSubscriptions bounds the topic patterns a connection subscribes to
+const maxSubscriptions = 256
+
+// topicResources maps the first segment of the topics about a cluster,
+// <prefix>/<cluster>/<namespace>/..., to the resource a connection must be allowed to read
+// to receive them: events for EventsTopic and any resource of the cluster for ClustersTopic
+var topicResources = map[string]string{
+	EventsTopic:   "events",
+	ClustersTopic: "",
+}
+
+// Scope is what the owner of a connection may read, for users restricted to some clusters
+// or namespaces or to the clusters of their organization. Connections with a scope only
+// receive the messages of the topics about clusters (see topicResources) it allows.
+type Scope interface {
+	// Allows reports whether a resource may be read in a namespace of a cluster, "" being
+	// the namespace of cluster-scoped objects
+	Allows(cluster, resource, namespace string) bool
+	// AllowsSomewhere reports whether a resource ("" for any) may be read in at least one
+	// namespace of a cluster
+	AllowsSomewhere(cluster, resource string) bool
+}
+
+// Subscription is the payload of subscribe and unsubscribe messages, and of the subscribed
+// message the server answers both with
+type Subscription struct {
+	Topics []string `json:"topics"`
+}
+
+// Topic builds a topic from its segments. Empty segments, such as the namespace of a
+// cluster-scoped object, become ClusterScoped.
+func Topic(segments ...string) string {
+	parts := make([]string, len(segments))
+	for i, segment := range segments {
+		if segment == "" {
+			segment = ClusterScoped
+		}
+		parts[i] = segment
+	}
+	return strings.Join(parts, "/")
+}
+
+// validatePattern checks a topic pattern a client subscribes to
+func validatePattern(pattern string) error {
+	if pattern == "" || len(pattern) > 512 {
+		return fmt.Errorf("invalid topic pattern %q", pattern)
+	}
+	for _, segment := range strings.Split(pattern, "/") {
+		if segment == "" || (segment != "*" && strings.Contains(segment, "*")) {
+			return fmt.Errorf("invalid topic pattern %q", pattern)
+		}
+	}
+	return nil
+}
+
+// allowedPattern reports whether a scope may subscribe to a pattern. Patterns naming a
+// cluster or namespace the scope does not allow are refused; wildcards are accepted and the
+// messages they match are checked one by one.
+func allowedPattern(scope Scope, pattern string) bool {
+	segments := strings.Split(pattern, "/")
+	resource, ok := topicResources[segments[0]]
+	if !ok || len(segments) < 2 || segments[1] == "*" {
+		return true
+	}
+	if len(segments) < 3 || segments[2] == "*" {
+		return scope.AllowsSomewhere(segments[1], resource)
+	}
+	return scope.Allows(segments[1], resource, topicNamespace(segments[2]))
+}
+
+// allowedTopic reports whether a scope may receive a message published on a topic. Topics
+// that are not about a cluster are not sent to connections with a scope.
+func allowedTopic(scope Scope, topic string) bool {
+	segments := strings.Split(topic, "/")
+	resource, ok := topicResources[segments[0]]
+	if !ok || len(segments) < 2 {
+		return false
+	}
+	if len(segments) < 3 {
+		return scope.AllowsSomewhere(segments[1], resource)
+	}
+	return scope.Allows(segments[1], resource, topicNamespace(segments[2]))
+}
+
+// topicNamespace returns the namespace of a topic segment, "" for ClusterScoped
+func topicNamespace(segment string) string {
+	if segment == ClusterScoped {
+		return ""
+	}
+	return segment
+}
+
+// matchTopic reports whether a topic matches a pattern
+func matchTopic(pattern, topic string) bool {
+	patternSegments := strings.Split(pattern, "/")
+	topicSegments := strings.Split(topic, "/")
+	if len(patternSegments) > len(topicSegments) {
+		return false
+	}
+	for i, segment := range patternSegments {
+		if segment != "*" && segment != topicSegments[i] {
+			return false
+		}
+	}
+	return true
+}
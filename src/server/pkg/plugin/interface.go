@@ -16,12 +16,24 @@ var HandshakeConfig = plugin.HandshakeConfig{
 
 // Metadata represents extension metadata
 type Metadata struct {
-	Name             string   `json:"name"`
-	Version          string   `json:"version"`
-	Description      string   `json:"description"`
-	Author           string   `json:"author"`
-	MinServerVersion string   `json:"min_server_version"`
-	Permissions      []string `json:"permissions"`
+	Name             string       `json:"name"`
+	Version          string       `json:"version"`
+	Description      string       `json:"description"`
+	Author           string       `json:"author"`
+	MinServerVersion string       `json:"min_server_version"`
+	Permissions      []string     `json:"permissions"`
+	ProxyRoutes      []ProxyRoute `json:"proxy_routes,omitempty"` // Routing rules for the HTTP proxy
+}
+
+// ProxyRoute declares how requests under an extension's proxy mount path are
+// forwarded to its HTTP endpoint. Paths are relative to the mount path. Paths that match
+// no route require authentication, so public paths must be declared.
+type ProxyRoute struct {
+	PathPrefix string `json:"path_prefix"`        // e.g. "/terminal"
+	Rewrite    string `json:"rewrite,omitempty"`  // Upstream replacement for PathPrefix, e.g. "/ws"
+	Auth       string `json:"auth,omitempty"`     // "public", "authenticated" (default) or "permission"
+	Resource   string `json:"resource,omitempty"` // Permission resource when Auth is "permission"
+	Action     string `json:"action,omitempty"`   // Permission action when Auth is "permission"
 }
 
 // UIMetadata represents UI assets and configuration